@@ -13,6 +13,47 @@
  * namespace is printed to file descriptor #4, which is then closed; the caller should
  * send SIGKILL to this process to stop the grain, then wait on the sandbox launcher.
  *
+ * If file descriptor #5 is open, it must be a cgroup (v2) directory, which the sandbox
+ * joins before anything else is done, so that the grain is subject to its resource
+ * limits. The sandbox's cgroup namespace is rooted there, so the grain can't see (or
+ * escape to) the rest of the hierarchy.
+ *
+ * If file descriptor #6 is open, it must be a regular file containing an array of
+ * struct sock_filter: extra seccomp rules for this grain, which are run ahead of the
+ * built-in filter (see filter.s), and fall through to it for system calls they don't
+ * decide. See internal/server/seccomp.
+ *
+ * Alternatively, to have a sandbox ready before it is needed, Tempest may invoke it as:
+ *
+ * tempest-sandbox-launcher --warm
+ *
+ * in which case it sets up what it can without knowing the grain -- its namespaces,
+ * including the network's loopback interface -- then waits to be claimed: it reads a
+ * claim from file descriptor #7 until EOF, and carries on as above. The claim consists
+ * of the following NUL-terminated strings:
+ *
+ *     <size of the seccomp layer, in bytes, in decimal>
+ *     <package-id>
+ *     <grain-id>
+ *     [ args... ]
+ *
+ * followed by the extra seccomp rules, in the format described above. File descriptors
+ * #5 and #6 aren't used; instead, if the grain has a cgroup, Tempest moves the launcher
+ * into it before sending the claim. See internal/server/container/pool.go.
+ *
+ * Finally, to run a helper program which handles untrusted input, such as those which
+ * render thumbnails (see internal/server/thumbnail), Tempest may invoke it as:
+ *
+ * tempest-sandbox-launcher --helper <program> [ args... ]
+ *
+ * in which case the program, whose path must be absolute, runs in namespaces of its
+ * own, like a grain's, with the built-in seccomp filter. Its root directory is an empty
+ * tmpfs, with a writable /tmp, a minimal /dev, and the directories of the host in
+ * helper_host_paths mounted read-only, for its executable and libraries. It keeps
+ * stdin, stdout and stderr, and gets an environment with just PATH and HOME. The
+ * launcher exits with the program's status once it exits, and the program is killed
+ * if the launcher is.
+ *
  * This program is written in C, rather than Go, because:
  *
  * 1. There have historically been many bugs and gotchas around multi-threaded
@@ -95,6 +136,14 @@
 #define PKG_ID_SIZE 32
 #define GRAIN_ID_SIZE 22
 
+#define CGROUP_FD 5
+#define SECCOMP_LAYER_FD 6
+#define CLAIM_FD 7
+
+/* Limits on the size of a claim (see above): */
+#define CLAIM_MAX_STRINGS 65536
+#define CLAIM_MAX_ARGS 256
+
 #define REQUIRE(condition) \
 	if (!(condition)) do { \
 		panic(__FILE__, __LINE__, #condition); \
@@ -139,13 +188,276 @@ static struct sock_fprog seccomp_fprog = (struct sock_fprog) {
 	.filter = &seccomp_filter[0],
 };
 
+/* The extra seccomp rules from fd #6 or the claim, if any, followed by a copy of
+   seccomp_filter. */
+static struct sock_filter seccomp_layered[BPF_MAXINSNS];
+
+/* Run the first layer_len instructions of seccomp_layered ahead of the built-in filter. */
+void layer_seccomp(size_t layer_len) {
+	size_t builtin_len = seccomp_fprog.len;
+	REQUIRE(layer_len + builtin_len <= BPF_MAXINSNS);
+	memcpy(&seccomp_layered[layer_len], seccomp_filter, sizeof seccomp_filter);
+	seccomp_fprog.len = layer_len + builtin_len;
+	seccomp_fprog.filter = &seccomp_layered[0];
+}
+
+/* A claim, as read from fd #7 in --warm mode. */
+static char claim_buf[CLAIM_MAX_STRINGS + sizeof seccomp_layered];
+static char *claim_argv[CLAIM_MAX_ARGS + 2];
+
+/* Read a claim from fd #7, returning the package & grain IDs, and the grain agent's
+   arguments, which start with its name. Any extra seccomp rules are loaded as from
+   fd #6. */
+void read_claim(const char **image_id, const char **sandbox_id, char ***agent_argv) {
+	size_t len = 0;
+	while(true) {
+		REQUIRE(len < sizeof claim_buf);
+		ssize_t n = read(CLAIM_FD, claim_buf + len, sizeof claim_buf - len);
+		REQUIRE(n >= 0);
+		if(n == 0) {
+			break;
+		}
+		len += n;
+	}
+	close(CLAIM_FD);
+
+	/* The seccomp layer is at the end, after the strings: */
+	char *end;
+	REQUIRE(memchr(claim_buf, '\0', len) != NULL);
+	errno = 0;
+	unsigned long layer_size = strtoul(claim_buf, &end, 10);
+	REQUIRE(errno == 0 && end != claim_buf && *end == '\0');
+	size_t strings_len = len - layer_size;
+	REQUIRE(layer_size < len && strings_len <= CLAIM_MAX_STRINGS);
+	REQUIRE(claim_buf[strings_len - 1] == '\0');
+	REQUIRE(layer_size % sizeof(struct sock_filter) == 0);
+	if(layer_size > 0) {
+		REQUIRE(layer_size <= sizeof seccomp_layered);
+		memcpy(seccomp_layered, &claim_buf[strings_len], layer_size);
+		layer_seccomp(layer_size / sizeof(struct sock_filter));
+	}
+
+	/* ...and the rest are strings: */
+	char *str = end + 1;
+	size_t n_strings = 0;
+	char *strings[CLAIM_MAX_ARGS + 2];
+	while(str < &claim_buf[strings_len]) {
+		REQUIRE(n_strings < CLAIM_MAX_ARGS + 2);
+		strings[n_strings++] = str;
+		str += strlen(str) + 1;
+	}
+	REQUIRE(n_strings >= 2);
+	require_valid_pkg_id(strings[0]);
+	require_valid_grain_id(strings[1]);
+	*image_id = strings[0];
+	*sandbox_id = strings[1];
+	claim_argv[0] = "tempest-grain-agent";
+	for(size_t i = 2; i < n_strings; i++) {
+		claim_argv[i - 1] = strings[i];
+	}
+	claim_argv[n_strings - 1] = NULL;
+	*agent_argv = claim_argv;
+}
+
+/* The paths of the host exposed to helpers (see above), if they exist. */
+static const char *helper_host_paths[] = {
+	"/bin",
+	"/lib",
+	"/lib32",
+	"/lib64",
+	"/usr",
+	"/etc/alternatives",
+	"/etc/fonts",
+	"/etc/ld.so.cache",
+	NULL,
+};
+
+/* Make path, on the host, available at the same path under CHROOT_MNT: symlinks are
+   copied, and directories and files are bind-mounted read-only. */
+void expose_host_path(const char *path) {
+	char target[4096];
+	REQUIRE(snprintf(target, sizeof target, "%s%s", CHROOT_MNT, path) < (int)sizeof target);
+	struct stat st;
+	if(lstat(path, &st) != 0) {
+		REQUIRE(errno == ENOENT);
+		errno = 0;
+		return;
+	}
+	if(S_ISLNK(st.st_mode)) {
+		char link[4096];
+		ssize_t n = readlink(path, link, sizeof link);
+		REQUIRE(n >= 0 && (size_t)n < sizeof link);
+		link[n] = '\0';
+		REQUIRE(symlink(link, target) == 0);
+		return;
+	}
+	if(S_ISDIR(st.st_mode)) {
+		REQUIRE(mkdir(target, 0755) == 0);
+	} else {
+		int fd = open(target, O_WRONLY | O_CREAT | O_EXCL | O_CLOEXEC, 0644);
+		REQUIRE(fd >= 0);
+		close(fd);
+	}
+	REQUIRE(mount(path, target, "", MS_BIND|MS_REC, "") == 0);
+	REQUIRE(mount("", target, "", MS_REMOUNT|MS_BIND|MS_RDONLY|MS_NOSUID|MS_NODEV, "") == 0);
+}
+
+/* Run a helper program in a sandbox, as described at the top of this file. argv holds
+   the program's path and its arguments. */
+int run_helper(char **argv) {
+	REQUIRE(argv[0] != NULL && argv[0][0] == '/');
+
+	struct rlimit limit = (struct rlimit) {
+		.rlim_cur = 1024,
+		.rlim_max = 4096,
+	};
+	REQUIRE(setrlimit(RLIMIT_NOFILE, &limit) == 0);
+
+	/* As for a grain, but there is no cgroup to join first. The network namespace
+	   doesn't even get a loopback interface. */
+	REQUIRE(unshare(
+		CLONE_NEWNS |
+		CLONE_FILES |
+		CLONE_FS |
+		CLONE_NEWCGROUP |
+		CLONE_NEWIPC |
+		CLONE_NEWNET |
+		CLONE_NEWPID |
+		CLONE_NEWUTS |
+		CLONE_SYSVSEM) == 0);
+	REQUIRE(mount("", "/", "", MS_REC|MS_PRIVATE, "") == 0);
+
+	/* Build the root: an empty tmpfs, with the host's paths, /tmp and /dev mounted
+	   in it, which is then made read-only. */
+	REQUIRE(mount("none", CHROOT_MNT, "tmpfs", MS_NODEV|MS_NOSUID, "size=1m,mode=0755") == 0);
+	REQUIRE(mkdir(CHROOT_MNT "/etc", 0755) == 0);
+	for(const char **path = helper_host_paths; *path != NULL; path++) {
+		expose_host_path(*path);
+	}
+	REQUIRE(mkdir(CHROOT_MNT "/tmp", 0755) == 0);
+	REQUIRE(mount("none", CHROOT_MNT "/tmp", "tmpfs", MS_NODEV|MS_NOSUID, "size=16m") == 0);
+	REQUIRE(mkdir(CHROOT_MNT "/dev", 0755) == 0);
+	REQUIRE(mount("none", CHROOT_MNT "/dev", "tmpfs", MS_NOSUID, "") == 0);
+	REQUIRE(mknod(CHROOT_MNT "/dev/null",    S_IFCHR|0666, makedev(1, 3)) == 0);
+	REQUIRE(mknod(CHROOT_MNT "/dev/zero",    S_IFCHR|0666, makedev(1, 5)) == 0);
+	REQUIRE(mknod(CHROOT_MNT "/dev/random",  S_IFCHR|0666, makedev(1, 8)) == 0);
+	REQUIRE(mknod(CHROOT_MNT "/dev/urandom", S_IFCHR|0666, makedev(1, 9)) == 0);
+	REQUIRE(mount("", CHROOT_MNT "/dev", "", MS_REMOUNT|MS_RDONLY|MS_NOSUID, "") == 0);
+	REQUIRE(mount("", CHROOT_MNT, "", MS_REMOUNT|MS_RDONLY|MS_NODEV|MS_NOSUID, "") == 0);
+
+	/* Close everything but stdin, stdout and stderr. */
+	long max_fds = sysconf(_SC_OPEN_MAX);
+	for(int i = 3; i < max_fds; i++) {
+		close(i);
+	}
+
+	REQUIRE(prctl(PR_CAP_AMBIENT, PR_CAP_AMBIENT_CLEAR_ALL, 0, 0, 0) == 0);
+	REQUIRE(prctl(PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0) == 0);
+
+	/* Swap in the new root, as for a grain; see the comments in main(). */
+	REQUIRE(chdir(CHROOT_MNT) == 0);
+	int old_root = open("/", O_RDONLY | O_DIRECTORY | O_CLOEXEC);
+	REQUIRE(old_root >= 0);
+	REQUIRE(syscall(SYS_pivot_root, CHROOT_MNT, CHROOT_MNT) == 0);
+	REQUIRE(fchdir(old_root) == 0);
+	REQUIRE(umount2(".", MNT_DETACH) == 0);
+	REQUIRE(chdir("/tmp") == 0);
+	REQUIRE(close(old_root) == 0);
+
+	REQUIRE(syscall(SYS_seccomp, SECCOMP_SET_MODE_FILTER, 0, &seccomp_fprog) == 0);
+
+	pid_t pid = fork();
+	REQUIRE(pid != -1);
+	if(pid == 0) {
+		/* We're pid 1 in the new pid namespace. Die with the launcher; Tempest
+		   also kills our process group if the helper takes too long. */
+		REQUIRE(prctl(PR_SET_PDEATHSIG, SIGKILL, 0, 0, 0) == 0);
+		char *const envp[] = {
+			"PATH=/usr/local/bin:/usr/bin:/bin",
+			"HOME=/tmp",
+			NULL,
+		};
+		execve(argv[0], argv, envp);
+		perror("execve");
+		_exit(127);
+	}
+	close(0);
+	close(1);
+	close(2);
+	int wstatus;
+	while(waitpid(pid, &wstatus, 0) == -1) {
+		REQUIRE(errno == EINTR);
+		errno = 0;
+	}
+	if(WIFSIGNALED(wstatus)) {
+		return 128 + WTERMSIG(wstatus);
+	}
+	return WEXITSTATUS(wstatus);
+}
+
 int main(int argc, char **argv) {
-	REQUIRE(argc >= 3);
-	require_valid_pkg_id(argv[1]);
-	require_valid_grain_id(argv[2]);
+	if(argc >= 3 && strcmp(argv[1], "--helper") == 0) {
+		return run_helper(&argv[2]);
+	}
+
+	const bool warm = argc == 2 && strcmp(argv[1], "--warm") == 0;
+	const char *image_id = NULL;
+	const char *sandbox_id = NULL;
+	char **agent_argv = NULL;
+	if(!warm) {
+		REQUIRE(argc >= 3);
+		require_valid_pkg_id(argv[1]);
+		require_valid_grain_id(argv[2]);
+		image_id = argv[1];
+		sandbox_id = argv[2];
+
+		/* Re-use the arguments in argv after the ones we used. Swap out the program
+		   name. */
+		argv[2] = "tempest-grain-agent";
+		agent_argv = &argv[2];
+	}
+
+	/* Join the grain's cgroup, if we were given one. This must come before unsharing
+	   the cgroup namespace below, which is rooted at our cgroup at that point, and
+	   before opening any files, which could otherwise take fd #5. Writing "0" to
+	   cgroup.procs moves the writer. */
+	if(!warm) {
+		struct stat st;
+		if(fstat(CGROUP_FD, &st) == 0) {
+			REQUIRE(S_ISDIR(st.st_mode));
+			int procs_fd = openat(CGROUP_FD, "cgroup.procs", O_WRONLY | O_CLOEXEC);
+			REQUIRE(procs_fd >= 0);
+			REQUIRE(write(procs_fd, "0", 1) == 1);
+			close(procs_fd);
+			close(CGROUP_FD);
+		} else {
+			REQUIRE(errno == EBADF);
+			errno = 0;
+		}
+	}
 
-	const char *image_id = argv[1];
-	const char *sandbox_id = argv[2];
+	/* Load the extra seccomp rules, if we were given any, in front of the built-in
+	   filter. Like the cgroup, this must come before opening any files. */
+	if(!warm) {
+		struct stat st;
+		if(fstat(SECCOMP_LAYER_FD, &st) == 0) {
+			REQUIRE(S_ISREG(st.st_mode));
+			REQUIRE(st.st_size % sizeof(struct sock_filter) == 0);
+			REQUIRE((size_t)st.st_size <= sizeof seccomp_layered);
+			size_t size = 0;
+			while(size < (size_t)st.st_size) {
+				ssize_t n = pread(SECCOMP_LAYER_FD, (char *)seccomp_layered + size,
+					st.st_size - size, size);
+				REQUIRE(n > 0);
+				size += n;
+			}
+			close(SECCOMP_LAYER_FD);
+			layer_seccomp(st.st_size / sizeof(struct sock_filter));
+		} else {
+			REQUIRE(errno == EBADF);
+			errno = 0;
+		}
+	}
 
 	/* Get an fd for the agent executable, which we'll execveat() once we're in the sandbox. */
 	int agent_fd = open(AGENT_PATH, O_RDONLY);
@@ -162,7 +474,8 @@ int main(int argc, char **argv) {
 	REQUIRE(setrlimit(RLIMIT_NOFILE, &limit) == 0);
 
 	/* Unshare basically all of the namespaces. We leave out user namespaces, since they
-	   aren't universally supported. */
+	   aren't universally supported. A warm sandbox doesn't know its cgroup yet, so it
+	   unshares the cgroup namespace once claimed. */
 	/* TODO: make sure we're cleaning up the resources for each of these:
 
 	   - [ ] files
@@ -179,7 +492,7 @@ int main(int argc, char **argv) {
 		CLONE_NEWNS |
 		CLONE_FILES |
 		CLONE_FS |
-		CLONE_NEWCGROUP |
+		(warm ? 0 : CLONE_NEWCGROUP) |
 		CLONE_NEWIPC |
 		CLONE_NEWNET |
 		CLONE_NEWPID |
@@ -213,6 +526,13 @@ int main(int argc, char **argv) {
 		close(sockfd);
 	}
 
+	/* That's all a warm sandbox can do before it's claimed by a grain. By the time the
+	   claim arrives, Tempest has moved us into the grain's cgroup, if it has one. */
+	if(warm) {
+		read_claim(&image_id, &sandbox_id, &agent_argv);
+		REQUIRE(unshare(CLONE_NEWCGROUP) == 0);
+	}
+
 	/* Mount the image read only, then mount the sandbox's storage in the image's /var. */
 	REQUIRE(chdir(IMAGE_DIR) == 0);
 	REQUIRE(mount(image_id, CHROOT_MNT, "", MS_BIND, "") == 0);
@@ -380,11 +700,6 @@ int main(int argc, char **argv) {
 		REQUIRE(setsid() != -1);
 		//REQUIRE(setpgid(0, 0) == 0);
 
-		// Re-use the arguments in argv after the ones we used. Swap out the program
-		// name.
-		argv[2] = "tempest-grain-agent";
-		char **const agent_argv = &argv[2];
-
 		char *const agent_envp[] = {NULL};
 		syscall(SYS_execveat, agent_fd, "", agent_argv, agent_envp, AT_EMPTY_PATH);
 
@@ -0,0 +1,34 @@
+// Code generated by capnpc-go. DO NOT EDIT.
+
+package apiversion
+
+import (
+	schemas "capnproto.org/go/capnp/v3/schemas"
+)
+
+const Since = uint64(0xc5c270cd9663cbdc)
+const Deprecated = uint64(0x9e1c161973af5d19)
+const RemovedIn = uint64(0xf6db81069caf38ad)
+const schema_a851def7c717654a = "x\xda2\xd8\"\xee\xc0b\xc8\xbb\x7f\x0f\x03S\xf0\x14" +
+	"\x0eV\xb6\xff\x92\xb1\xeb\x8b%\xc5d\xe61L\x14d" +
+	"e\xfa\xef\x95*~\xfc\xfb\xbd\xc0\x15\x0c\x0c\x8c\xc2\xae" +
+	"q\x8f\x18\x18\x83=\xe2\x98\x19\x19\x18\xff\xdf9\x9d<" +
+	"\xedl\xc1\xa1\xa3\x98\xca,\xe3v10\x06[@\x94" +
+	"\xad\xb5X?\x87\xad\xf1\xf67Le\x9aq\xb7\x18\x18" +
+	"\x83u@\xca\xfe\x93\x04\xcb\xff'\x16d\x96\xa5\x16\x15" +
+	"g2\xe5\xe7\xe9%'\x16\xe4\x15X\xa5\xa4\x16\x14\xa5" +
+	"&'\xb2\x97\xa4\xa60\xf200\xc1U0\xc2T\xd8" +
+	"[\x15g\xe6%\xa72r00a\xd1^\x94\x9a\x9b" +
+	"_\x96\x9a\xc2\xec\x99\x07R\x00\x18\x00O\xf2eu"
+
+func RegisterSchema(reg *schemas.Registry) {
+	reg.Register(&schemas.Schema{
+		String: schema_a851def7c717654a,
+		Nodes: []uint64{
+			0x9e1c161973af5d19,
+			0xc5c270cd9663cbdc,
+			0xf6db81069caf38ad,
+		},
+		Compressed: true,
+	})
+}
@@ -231,7 +231,7 @@ func (c ExternalApi_getSessions) Args() ExternalApi_getSessions_Params {
 
 // AllocResults allocates the results struct.
 func (c ExternalApi_getSessions) AllocResults() (Sessions, error) {
-	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 4})
 	return Sessions(r), err
 }
 
@@ -248,7 +248,7 @@ func (c ExternalApi_restore) Args() ExternalApi_restore_Params {
 
 // AllocResults allocates the results struct.
 func (c ExternalApi_restore) AllocResults() (ExternalApi_restore_Results, error) {
-	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 2})
 	return ExternalApi_restore_Results(r), err
 }
 
@@ -426,12 +426,12 @@ type ExternalApi_restore_Results capnp.Struct
 const ExternalApi_restore_Results_TypeID = 0xdf63aff4b8be1697
 
 func NewExternalApi_restore_Results(s *capnp.Segment) (ExternalApi_restore_Results, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
 	return ExternalApi_restore_Results(st), err
 }
 
 func NewRootExternalApi_restore_Results(s *capnp.Segment) (ExternalApi_restore_Results, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
 	return ExternalApi_restore_Results(st), err
 }
 
@@ -484,13 +484,36 @@ func (s ExternalApi_restore_Results) SetCap(c capnp.Client) error {
 	in := capnp.NewInterface(seg, seg.Message().CapTable().Add(c))
 	return capnp.Struct(s).SetPtr(0, in.ToPtr())
 }
+func (s ExternalApi_restore_Results) Error() (ApiError, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return ApiError(p.Struct()), err
+}
+
+func (s ExternalApi_restore_Results) HasError() bool {
+	return capnp.Struct(s).HasPtr(1)
+}
+
+func (s ExternalApi_restore_Results) SetError(v ApiError) error {
+	return capnp.Struct(s).SetPtr(1, capnp.Struct(v).ToPtr())
+}
+
+// NewError sets the error field to a newly
+// allocated ApiError struct, preferring placement in s's segment.
+func (s ExternalApi_restore_Results) NewError() (ApiError, error) {
+	ss, err := NewApiError(capnp.Struct(s).Segment())
+	if err != nil {
+		return ApiError{}, err
+	}
+	err = capnp.Struct(s).SetPtr(1, capnp.Struct(ss).ToPtr())
+	return ss, err
+}
 
 // ExternalApi_restore_Results_List is a list of ExternalApi_restore_Results.
 type ExternalApi_restore_Results_List = capnp.StructList[ExternalApi_restore_Results]
 
 // NewExternalApi_restore_Results creates a new list of ExternalApi_restore_Results.
 func NewExternalApi_restore_Results_List(s *capnp.Segment, sz int32) (ExternalApi_restore_Results_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2}, sz)
 	return capnp.StructList[ExternalApi_restore_Results](l), err
 }
 
@@ -504,6 +527,9 @@ func (f ExternalApi_restore_Results_Future) Struct() (ExternalApi_restore_Result
 func (p ExternalApi_restore_Results_Future) Cap() capnp.Client {
 	return p.Future.Field(0, nil).Client()
 }
+func (p ExternalApi_restore_Results_Future) Error() ApiError_Future {
+	return ApiError_Future{Future: p.Future.Field(1, nil)}
+}
 
 type ExternalApi_authenticator_Params capnp.Struct
 
@@ -661,12 +687,12 @@ type Sessions capnp.Struct
 const Sessions_TypeID = 0xd35dd79bdf18720b
 
 func NewSessions(s *capnp.Segment) (Sessions, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 4})
 	return Sessions(st), err
 }
 
 func NewRootSessions(s *capnp.Segment) (Sessions, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 4})
 	return Sessions(st), err
 }
 
@@ -738,12 +764,54 @@ func (s Sessions) SetUser(v UserSession) error {
 	return capnp.Struct(s).SetPtr(1, in.ToPtr())
 }
 
+func (s Sessions) Admin() AdminSession {
+	p, _ := capnp.Struct(s).Ptr(2)
+	return AdminSession(p.Interface().Client())
+}
+
+func (s Sessions) HasAdmin() bool {
+	return capnp.Struct(s).HasPtr(2)
+}
+
+func (s Sessions) SetAdmin(v AdminSession) error {
+	if !v.IsValid() {
+		return capnp.Struct(s).SetPtr(2, capnp.Ptr{})
+	}
+	seg := s.Segment()
+	in := capnp.NewInterface(seg, seg.Message().CapTable().Add(capnp.Client(v)))
+	return capnp.Struct(s).SetPtr(2, in.ToPtr())
+}
+
+func (s Sessions) Error() (ApiError, error) {
+	p, err := capnp.Struct(s).Ptr(3)
+	return ApiError(p.Struct()), err
+}
+
+func (s Sessions) HasError() bool {
+	return capnp.Struct(s).HasPtr(3)
+}
+
+func (s Sessions) SetError(v ApiError) error {
+	return capnp.Struct(s).SetPtr(3, capnp.Struct(v).ToPtr())
+}
+
+// NewError sets the error field to a newly
+// allocated ApiError struct, preferring placement in s's segment.
+func (s Sessions) NewError() (ApiError, error) {
+	ss, err := NewApiError(capnp.Struct(s).Segment())
+	if err != nil {
+		return ApiError{}, err
+	}
+	err = capnp.Struct(s).SetPtr(3, capnp.Struct(ss).ToPtr())
+	return ss, err
+}
+
 // Sessions_List is a list of Sessions.
 type Sessions_List = capnp.StructList[Sessions]
 
 // NewSessions creates a new list of Sessions.
 func NewSessions_List(s *capnp.Segment, sz int32) (Sessions_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2}, sz)
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 4}, sz)
 	return capnp.StructList[Sessions](l), err
 }
 
@@ -762,6 +830,14 @@ func (p Sessions_Future) User() UserSession {
 	return UserSession(p.Future.Field(1, nil).Client())
 }
 
+func (p Sessions_Future) Admin() AdminSession {
+	return AdminSession(p.Future.Field(2, nil).Client())
+}
+
+func (p Sessions_Future) Error() ApiError_Future {
+	return ApiError_Future{Future: p.Future.Field(3, nil)}
+}
+
 type Authenticator capnp.Client
 
 // Authenticator_TypeID is the unique identifier for the type Authenticator.
@@ -778,7 +854,7 @@ func (c Authenticator) SendEmailAuthToken(ctx context.Context, params func(Authe
 		},
 	}
 	if params != nil {
-		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 1}
+		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 2}
 		s.PlaceArgs = func(s capnp.Struct) error { return params(Authenticator_sendEmailAuthToken_Params(s)) }
 	}
 
@@ -910,7 +986,7 @@ func (c Authenticator_sendEmailAuthToken) Args() Authenticator_sendEmailAuthToke
 
 // AllocResults allocates the results struct.
 func (c Authenticator_sendEmailAuthToken) AllocResults() (Authenticator_sendEmailAuthToken_Results, error) {
-	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 0})
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 1})
 	return Authenticator_sendEmailAuthToken_Results(r), err
 }
 
@@ -929,12 +1005,12 @@ type Authenticator_sendEmailAuthToken_Params capnp.Struct
 const Authenticator_sendEmailAuthToken_Params_TypeID = 0xdc37537484ce90cc
 
 func NewAuthenticator_sendEmailAuthToken_Params(s *capnp.Segment) (Authenticator_sendEmailAuthToken_Params, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
 	return Authenticator_sendEmailAuthToken_Params(st), err
 }
 
 func NewRootAuthenticator_sendEmailAuthToken_Params(s *capnp.Segment) (Authenticator_sendEmailAuthToken_Params, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
 	return Authenticator_sendEmailAuthToken_Params(st), err
 }
 
@@ -988,12 +1064,30 @@ func (s Authenticator_sendEmailAuthToken_Params) SetAddress(v string) error {
 	return capnp.Struct(s).SetText(0, v)
 }
 
+func (s Authenticator_sendEmailAuthToken_Params) Locale() (string, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return p.Text(), err
+}
+
+func (s Authenticator_sendEmailAuthToken_Params) HasLocale() bool {
+	return capnp.Struct(s).HasPtr(1)
+}
+
+func (s Authenticator_sendEmailAuthToken_Params) LocaleBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return p.TextBytes(), err
+}
+
+func (s Authenticator_sendEmailAuthToken_Params) SetLocale(v string) error {
+	return capnp.Struct(s).SetText(1, v)
+}
+
 // Authenticator_sendEmailAuthToken_Params_List is a list of Authenticator_sendEmailAuthToken_Params.
 type Authenticator_sendEmailAuthToken_Params_List = capnp.StructList[Authenticator_sendEmailAuthToken_Params]
 
 // NewAuthenticator_sendEmailAuthToken_Params creates a new list of Authenticator_sendEmailAuthToken_Params.
 func NewAuthenticator_sendEmailAuthToken_Params_List(s *capnp.Segment, sz int32) (Authenticator_sendEmailAuthToken_Params_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2}, sz)
 	return capnp.StructList[Authenticator_sendEmailAuthToken_Params](l), err
 }
 
@@ -1011,12 +1105,12 @@ type Authenticator_sendEmailAuthToken_Results capnp.Struct
 const Authenticator_sendEmailAuthToken_Results_TypeID = 0x9d3fbd3710589c73
 
 func NewAuthenticator_sendEmailAuthToken_Results(s *capnp.Segment) (Authenticator_sendEmailAuthToken_Results, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
 	return Authenticator_sendEmailAuthToken_Results(st), err
 }
 
 func NewRootAuthenticator_sendEmailAuthToken_Results(s *capnp.Segment) (Authenticator_sendEmailAuthToken_Results, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
 	return Authenticator_sendEmailAuthToken_Results(st), err
 }
 
@@ -1052,13 +1146,36 @@ func (s Authenticator_sendEmailAuthToken_Results) Message() *capnp.Message {
 func (s Authenticator_sendEmailAuthToken_Results) Segment() *capnp.Segment {
 	return capnp.Struct(s).Segment()
 }
+func (s Authenticator_sendEmailAuthToken_Results) Error() (ApiError, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return ApiError(p.Struct()), err
+}
+
+func (s Authenticator_sendEmailAuthToken_Results) HasError() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s Authenticator_sendEmailAuthToken_Results) SetError(v ApiError) error {
+	return capnp.Struct(s).SetPtr(0, capnp.Struct(v).ToPtr())
+}
+
+// NewError sets the error field to a newly
+// allocated ApiError struct, preferring placement in s's segment.
+func (s Authenticator_sendEmailAuthToken_Results) NewError() (ApiError, error) {
+	ss, err := NewApiError(capnp.Struct(s).Segment())
+	if err != nil {
+		return ApiError{}, err
+	}
+	err = capnp.Struct(s).SetPtr(0, capnp.Struct(ss).ToPtr())
+	return ss, err
+}
 
 // Authenticator_sendEmailAuthToken_Results_List is a list of Authenticator_sendEmailAuthToken_Results.
 type Authenticator_sendEmailAuthToken_Results_List = capnp.StructList[Authenticator_sendEmailAuthToken_Results]
 
 // NewAuthenticator_sendEmailAuthToken_Results creates a new list of Authenticator_sendEmailAuthToken_Results.
 func NewAuthenticator_sendEmailAuthToken_Results_List(s *capnp.Segment, sz int32) (Authenticator_sendEmailAuthToken_Results_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0}, sz)
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
 	return capnp.StructList[Authenticator_sendEmailAuthToken_Results](l), err
 }
 
@@ -1069,6 +1186,9 @@ func (f Authenticator_sendEmailAuthToken_Results_Future) Struct() (Authenticator
 	p, err := f.Future.Ptr()
 	return Authenticator_sendEmailAuthToken_Results(p.Struct()), err
 }
+func (p Authenticator_sendEmailAuthToken_Results_Future) Error() ApiError_Future {
+	return ApiError_Future{Future: p.Future.Field(0, nil)}
+}
 
 type VisitorSession capnp.Client
 
@@ -1641,7 +1761,7 @@ func (c Package_Controller_create) Args() Package_Controller_create_Params {
 
 // AllocResults allocates the results struct.
 func (c Package_Controller_create) AllocResults() (Package_Controller_create_Results, error) {
-	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 3})
 	return Package_Controller_create_Results(r), err
 }
 
@@ -1750,12 +1870,12 @@ type Package_Controller_create_Results capnp.Struct
 const Package_Controller_create_Results_TypeID = 0xe8adb094ad307b8f
 
 func NewPackage_Controller_create_Results(s *capnp.Segment) (Package_Controller_create_Results, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 3})
 	return Package_Controller_create_Results(st), err
 }
 
 func NewRootPackage_Controller_create_Results(s *capnp.Segment) (Package_Controller_create_Results, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 3})
 	return Package_Controller_create_Results(st), err
 }
 
@@ -1833,12 +1953,36 @@ func (s Package_Controller_create_Results) NewView() (UiView, error) {
 	return ss, err
 }
 
+func (s Package_Controller_create_Results) Error() (ApiError, error) {
+	p, err := capnp.Struct(s).Ptr(2)
+	return ApiError(p.Struct()), err
+}
+
+func (s Package_Controller_create_Results) HasError() bool {
+	return capnp.Struct(s).HasPtr(2)
+}
+
+func (s Package_Controller_create_Results) SetError(v ApiError) error {
+	return capnp.Struct(s).SetPtr(2, capnp.Struct(v).ToPtr())
+}
+
+// NewError sets the error field to a newly
+// allocated ApiError struct, preferring placement in s's segment.
+func (s Package_Controller_create_Results) NewError() (ApiError, error) {
+	ss, err := NewApiError(capnp.Struct(s).Segment())
+	if err != nil {
+		return ApiError{}, err
+	}
+	err = capnp.Struct(s).SetPtr(2, capnp.Struct(ss).ToPtr())
+	return ss, err
+}
+
 // Package_Controller_create_Results_List is a list of Package_Controller_create_Results.
 type Package_Controller_create_Results_List = capnp.StructList[Package_Controller_create_Results]
 
 // NewPackage_Controller_create_Results creates a new list of Package_Controller_create_Results.
 func NewPackage_Controller_create_Results_List(s *capnp.Segment, sz int32) (Package_Controller_create_Results_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2}, sz)
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 3}, sz)
 	return capnp.StructList[Package_Controller_create_Results](l), err
 }
 
@@ -1852,6 +1996,9 @@ func (f Package_Controller_create_Results_Future) Struct() (Package_Controller_c
 func (p Package_Controller_create_Results_Future) View() UiView_Future {
 	return UiView_Future{Future: p.Future.Field(1, nil)}
 }
+func (p Package_Controller_create_Results_Future) Error() ApiError_Future {
+	return ApiError_Future{Future: p.Future.Field(2, nil)}
+}
 
 type Package_InstallStream capnp.Client
 
@@ -2101,7 +2248,7 @@ func (c Package_InstallStream_getPackage) Args() Package_InstallStream_getPackag
 
 // AllocResults allocates the results struct.
 func (c Package_InstallStream_getPackage) AllocResults() (Package_InstallStream_getPackage_Results, error) {
-	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 3})
 	return Package_InstallStream_getPackage_Results(r), err
 }
 
@@ -2185,12 +2332,12 @@ type Package_InstallStream_getPackage_Results capnp.Struct
 const Package_InstallStream_getPackage_Results_TypeID = 0xa8312a5c0aed89c6
 
 func NewPackage_InstallStream_getPackage_Results(s *capnp.Segment) (Package_InstallStream_getPackage_Results, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 3})
 	return Package_InstallStream_getPackage_Results(st), err
 }
 
 func NewRootPackage_InstallStream_getPackage_Results(s *capnp.Segment) (Package_InstallStream_getPackage_Results, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 3})
 	return Package_InstallStream_getPackage_Results(st), err
 }
 
@@ -2268,12 +2415,36 @@ func (s Package_InstallStream_getPackage_Results) NewPackage() (Package, error)
 	return ss, err
 }
 
+func (s Package_InstallStream_getPackage_Results) Error() (ApiError, error) {
+	p, err := capnp.Struct(s).Ptr(2)
+	return ApiError(p.Struct()), err
+}
+
+func (s Package_InstallStream_getPackage_Results) HasError() bool {
+	return capnp.Struct(s).HasPtr(2)
+}
+
+func (s Package_InstallStream_getPackage_Results) SetError(v ApiError) error {
+	return capnp.Struct(s).SetPtr(2, capnp.Struct(v).ToPtr())
+}
+
+// NewError sets the error field to a newly
+// allocated ApiError struct, preferring placement in s's segment.
+func (s Package_InstallStream_getPackage_Results) NewError() (ApiError, error) {
+	ss, err := NewApiError(capnp.Struct(s).Segment())
+	if err != nil {
+		return ApiError{}, err
+	}
+	err = capnp.Struct(s).SetPtr(2, capnp.Struct(ss).ToPtr())
+	return ss, err
+}
+
 // Package_InstallStream_getPackage_Results_List is a list of Package_InstallStream_getPackage_Results.
 type Package_InstallStream_getPackage_Results_List = capnp.StructList[Package_InstallStream_getPackage_Results]
 
 // NewPackage_InstallStream_getPackage_Results creates a new list of Package_InstallStream_getPackage_Results.
 func NewPackage_InstallStream_getPackage_Results_List(s *capnp.Segment, sz int32) (Package_InstallStream_getPackage_Results_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2}, sz)
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 3}, sz)
 	return capnp.StructList[Package_InstallStream_getPackage_Results](l), err
 }
 
@@ -2287,6 +2458,9 @@ func (f Package_InstallStream_getPackage_Results_Future) Struct() (Package_Insta
 func (p Package_InstallStream_getPackage_Results_Future) Package() Package_Future {
 	return Package_Future{Future: p.Future.Field(1, nil)}
 }
+func (p Package_InstallStream_getPackage_Results_Future) Error() ApiError_Future {
+	return ApiError_Future{Future: p.Future.Field(2, nil)}
+}
 
 type UserSession capnp.Client
 
@@ -2333,107 +2507,305 @@ func (c UserSession) ListPackages(ctx context.Context, params func(UserSession_l
 
 }
 
-func (c UserSession) WaitStreaming() error {
-	return capnp.Client(c).WaitStreaming()
-}
+func (c UserSession) Notifications(ctx context.Context, params func(UserSession_notifications_Params) error) (UserSession_notifications_Results_Future, capnp.ReleaseFunc) {
 
-// String returns a string that identifies this capability for debugging
-// purposes.  Its format should not be depended on: in particular, it
-// should not be used to compare clients.  Use IsSame to compare clients
-// for equality.
-func (c UserSession) String() string {
-	return "UserSession(" + capnp.Client(c).String() + ")"
-}
+	s := capnp.Send{
+		Method: capnp.Method{
+			InterfaceID:   0xf2c70d6545f83c8d,
+			MethodID:      2,
+			InterfaceName: "external.capnp:UserSession",
+			MethodName:    "notifications",
+		},
+	}
+	if params != nil {
+		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 1}
+		s.PlaceArgs = func(s capnp.Struct) error { return params(UserSession_notifications_Params(s)) }
+	}
 
-// AddRef creates a new Client that refers to the same capability as c.
-// If c is nil or has resolved to null, then AddRef returns nil.
-func (c UserSession) AddRef() UserSession {
-	return UserSession(capnp.Client(c).AddRef())
-}
+	ans, release := capnp.Client(c).SendCall(ctx, s)
+	return UserSession_notifications_Results_Future{Future: ans.Future()}, release
 
-// Release releases a capability reference.  If this is the last
-// reference to the capability, then the underlying resources associated
-// with the capability will be released.
-//
-// Release will panic if c has already been released, but not if c is
-// nil or resolved to null.
-func (c UserSession) Release() {
-	capnp.Client(c).Release()
 }
 
-// Resolve blocks until the capability is fully resolved or the Context
-// expires.
-func (c UserSession) Resolve(ctx context.Context) error {
-	return capnp.Client(c).Resolve(ctx)
-}
+func (c UserSession) MarkNotificationsRead(ctx context.Context, params func(UserSession_markNotificationsRead_Params) error) (UserSession_markNotificationsRead_Results_Future, capnp.ReleaseFunc) {
 
-func (c UserSession) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
-	return capnp.Client(c).EncodeAsPtr(seg)
-}
+	s := capnp.Send{
+		Method: capnp.Method{
+			InterfaceID:   0xf2c70d6545f83c8d,
+			MethodID:      3,
+			InterfaceName: "external.capnp:UserSession",
+			MethodName:    "markNotificationsRead",
+		},
+	}
+	if params != nil {
+		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 1}
+		s.PlaceArgs = func(s capnp.Struct) error { return params(UserSession_markNotificationsRead_Params(s)) }
+	}
 
-func (UserSession) DecodeFromPtr(p capnp.Ptr) UserSession {
-	return UserSession(capnp.Client{}.DecodeFromPtr(p))
-}
+	ans, release := capnp.Client(c).SendCall(ctx, s)
+	return UserSession_markNotificationsRead_Results_Future{Future: ans.Future()}, release
 
-// IsValid reports whether c is a valid reference to a capability.
-// A reference is invalid if it is nil, has resolved to null, or has
-// been released.
-func (c UserSession) IsValid() bool {
-	return capnp.Client(c).IsValid()
 }
 
-// IsSame reports whether c and other refer to a capability created by the
-// same call to NewClient.  This can return false negatives if c or other
-// are not fully resolved: use Resolve if this is an issue.  If either
-// c or other are released, then IsSame panics.
-func (c UserSession) IsSame(other UserSession) bool {
-	return capnp.Client(c).IsSame(capnp.Client(other))
-}
+func (c UserSession) UnreadNotificationCount(ctx context.Context, params func(UserSession_unreadNotificationCount_Params) error) (UserSession_unreadNotificationCount_Results_Future, capnp.ReleaseFunc) {
 
-// Update the flowcontrol.FlowLimiter used to manage flow control for
-// this client. This affects all future calls, but not calls already
-// waiting to send. Passing nil sets the value to flowcontrol.NopLimiter,
-// which is also the default.
-func (c UserSession) SetFlowLimiter(lim fc.FlowLimiter) {
-	capnp.Client(c).SetFlowLimiter(lim)
-}
+	s := capnp.Send{
+		Method: capnp.Method{
+			InterfaceID:   0xf2c70d6545f83c8d,
+			MethodID:      4,
+			InterfaceName: "external.capnp:UserSession",
+			MethodName:    "unreadNotificationCount",
+		},
+	}
+	if params != nil {
+		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 0}
+		s.PlaceArgs = func(s capnp.Struct) error { return params(UserSession_unreadNotificationCount_Params(s)) }
+	}
+
+	ans, release := capnp.Client(c).SendCall(ctx, s)
+	return UserSession_unreadNotificationCount_Results_Future{Future: ans.Future()}, release
 
-// Get the current flowcontrol.FlowLimiter used to manage flow control
-// for this client.
-func (c UserSession) GetFlowLimiter() fc.FlowLimiter {
-	return capnp.Client(c).GetFlowLimiter()
 }
 
-// A UserSession_Server is a UserSession with a local implementation.
-type UserSession_Server interface {
-	InstallPackage(context.Context, UserSession_installPackage) error
+func (c UserSession) StorageUsage(ctx context.Context, params func(UserSession_storageUsage_Params) error) (UserSession_storageUsage_Results_Future, capnp.ReleaseFunc) {
 
-	ListPackages(context.Context, UserSession_listPackages) error
-}
+	s := capnp.Send{
+		Method: capnp.Method{
+			InterfaceID:   0xf2c70d6545f83c8d,
+			MethodID:      5,
+			InterfaceName: "external.capnp:UserSession",
+			MethodName:    "storageUsage",
+		},
+	}
+	if params != nil {
+		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 0}
+		s.PlaceArgs = func(s capnp.Struct) error { return params(UserSession_storageUsage_Params(s)) }
+	}
 
-// UserSession_NewServer creates a new Server from an implementation of UserSession_Server.
-func UserSession_NewServer(s UserSession_Server) *server.Server {
-	c, _ := s.(server.Shutdowner)
-	return server.New(UserSession_Methods(nil, s), s, c)
-}
+	ans, release := capnp.Client(c).SendCall(ctx, s)
+	return UserSession_storageUsage_Results_Future{Future: ans.Future()}, release
 
-// UserSession_ServerToClient creates a new Client from an implementation of UserSession_Server.
-// The caller is responsible for calling Release on the returned Client.
-func UserSession_ServerToClient(s UserSession_Server) UserSession {
-	return UserSession(capnp.NewClient(UserSession_NewServer(s)))
 }
 
-// UserSession_Methods appends Methods to a slice that invoke the methods on s.
-// This can be used to create a more complicated Server.
-func UserSession_Methods(methods []server.Method, s UserSession_Server) []server.Method {
-	if cap(methods) == 0 {
-		methods = make([]server.Method, 0, 2)
-	}
+func (c UserSession) BackupGrain(ctx context.Context, params func(UserSession_backupGrain_Params) error) (UserSession_backupGrain_Results_Future, capnp.ReleaseFunc) {
 
-	methods = append(methods, server.Method{
+	s := capnp.Send{
 		Method: capnp.Method{
 			InterfaceID:   0xf2c70d6545f83c8d,
-			MethodID:      0,
+			MethodID:      6,
+			InterfaceName: "external.capnp:UserSession",
+			MethodName:    "backupGrain",
+		},
+	}
+	if params != nil {
+		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 2}
+		s.PlaceArgs = func(s capnp.Struct) error { return params(UserSession_backupGrain_Params(s)) }
+	}
+
+	ans, release := capnp.Client(c).SendCall(ctx, s)
+	return UserSession_backupGrain_Results_Future{Future: ans.Future()}, release
+
+}
+
+func (c UserSession) GrainLog(ctx context.Context, params func(UserSession_grainLog_Params) error) (UserSession_grainLog_Results_Future, capnp.ReleaseFunc) {
+
+	s := capnp.Send{
+		Method: capnp.Method{
+			InterfaceID:   0xf2c70d6545f83c8d,
+			MethodID:      7,
+			InterfaceName: "external.capnp:UserSession",
+			MethodName:    "grainLog",
+		},
+	}
+	if params != nil {
+		s.ArgsSize = capnp.ObjectSize{DataSize: 8, PointerCount: 2}
+		s.PlaceArgs = func(s capnp.Struct) error { return params(UserSession_grainLog_Params(s)) }
+	}
+
+	ans, release := capnp.Client(c).SendCall(ctx, s)
+	return UserSession_grainLog_Results_Future{Future: ans.Future()}, release
+
+}
+
+func (c UserSession) LoginSessions(ctx context.Context, params func(UserSession_loginSessions_Params) error) (UserSession_loginSessions_Results_Future, capnp.ReleaseFunc) {
+
+	s := capnp.Send{
+		Method: capnp.Method{
+			InterfaceID:   0xf2c70d6545f83c8d,
+			MethodID:      8,
+			InterfaceName: "external.capnp:UserSession",
+			MethodName:    "loginSessions",
+		},
+	}
+	if params != nil {
+		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 0}
+		s.PlaceArgs = func(s capnp.Struct) error { return params(UserSession_loginSessions_Params(s)) }
+	}
+
+	ans, release := capnp.Client(c).SendCall(ctx, s)
+	return UserSession_loginSessions_Results_Future{Future: ans.Future()}, release
+
+}
+
+func (c UserSession) RevokeLoginSession(ctx context.Context, params func(UserSession_revokeLoginSession_Params) error) (UserSession_revokeLoginSession_Results_Future, capnp.ReleaseFunc) {
+
+	s := capnp.Send{
+		Method: capnp.Method{
+			InterfaceID:   0xf2c70d6545f83c8d,
+			MethodID:      9,
+			InterfaceName: "external.capnp:UserSession",
+			MethodName:    "revokeLoginSession",
+		},
+	}
+	if params != nil {
+		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 1}
+		s.PlaceArgs = func(s capnp.Struct) error { return params(UserSession_revokeLoginSession_Params(s)) }
+	}
+
+	ans, release := capnp.Client(c).SendCall(ctx, s)
+	return UserSession_revokeLoginSession_Results_Future{Future: ans.Future()}, release
+
+}
+
+func (c UserSession) RevokeAllLoginSessions(ctx context.Context, params func(UserSession_revokeAllLoginSessions_Params) error) (UserSession_revokeAllLoginSessions_Results_Future, capnp.ReleaseFunc) {
+
+	s := capnp.Send{
+		Method: capnp.Method{
+			InterfaceID:   0xf2c70d6545f83c8d,
+			MethodID:      10,
+			InterfaceName: "external.capnp:UserSession",
+			MethodName:    "revokeAllLoginSessions",
+		},
+	}
+	if params != nil {
+		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 0}
+		s.PlaceArgs = func(s capnp.Struct) error { return params(UserSession_revokeAllLoginSessions_Params(s)) }
+	}
+
+	ans, release := capnp.Client(c).SendCall(ctx, s)
+	return UserSession_revokeAllLoginSessions_Results_Future{Future: ans.Future()}, release
+
+}
+
+func (c UserSession) WaitStreaming() error {
+	return capnp.Client(c).WaitStreaming()
+}
+
+// String returns a string that identifies this capability for debugging
+// purposes.  Its format should not be depended on: in particular, it
+// should not be used to compare clients.  Use IsSame to compare clients
+// for equality.
+func (c UserSession) String() string {
+	return "UserSession(" + capnp.Client(c).String() + ")"
+}
+
+// AddRef creates a new Client that refers to the same capability as c.
+// If c is nil or has resolved to null, then AddRef returns nil.
+func (c UserSession) AddRef() UserSession {
+	return UserSession(capnp.Client(c).AddRef())
+}
+
+// Release releases a capability reference.  If this is the last
+// reference to the capability, then the underlying resources associated
+// with the capability will be released.
+//
+// Release will panic if c has already been released, but not if c is
+// nil or resolved to null.
+func (c UserSession) Release() {
+	capnp.Client(c).Release()
+}
+
+// Resolve blocks until the capability is fully resolved or the Context
+// expires.
+func (c UserSession) Resolve(ctx context.Context) error {
+	return capnp.Client(c).Resolve(ctx)
+}
+
+func (c UserSession) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Client(c).EncodeAsPtr(seg)
+}
+
+func (UserSession) DecodeFromPtr(p capnp.Ptr) UserSession {
+	return UserSession(capnp.Client{}.DecodeFromPtr(p))
+}
+
+// IsValid reports whether c is a valid reference to a capability.
+// A reference is invalid if it is nil, has resolved to null, or has
+// been released.
+func (c UserSession) IsValid() bool {
+	return capnp.Client(c).IsValid()
+}
+
+// IsSame reports whether c and other refer to a capability created by the
+// same call to NewClient.  This can return false negatives if c or other
+// are not fully resolved: use Resolve if this is an issue.  If either
+// c or other are released, then IsSame panics.
+func (c UserSession) IsSame(other UserSession) bool {
+	return capnp.Client(c).IsSame(capnp.Client(other))
+}
+
+// Update the flowcontrol.FlowLimiter used to manage flow control for
+// this client. This affects all future calls, but not calls already
+// waiting to send. Passing nil sets the value to flowcontrol.NopLimiter,
+// which is also the default.
+func (c UserSession) SetFlowLimiter(lim fc.FlowLimiter) {
+	capnp.Client(c).SetFlowLimiter(lim)
+}
+
+// Get the current flowcontrol.FlowLimiter used to manage flow control
+// for this client.
+func (c UserSession) GetFlowLimiter() fc.FlowLimiter {
+	return capnp.Client(c).GetFlowLimiter()
+}
+
+// A UserSession_Server is a UserSession with a local implementation.
+type UserSession_Server interface {
+	InstallPackage(context.Context, UserSession_installPackage) error
+
+	ListPackages(context.Context, UserSession_listPackages) error
+
+	Notifications(context.Context, UserSession_notifications) error
+
+	MarkNotificationsRead(context.Context, UserSession_markNotificationsRead) error
+
+	UnreadNotificationCount(context.Context, UserSession_unreadNotificationCount) error
+
+	StorageUsage(context.Context, UserSession_storageUsage) error
+
+	BackupGrain(context.Context, UserSession_backupGrain) error
+
+	GrainLog(context.Context, UserSession_grainLog) error
+
+	LoginSessions(context.Context, UserSession_loginSessions) error
+
+	RevokeLoginSession(context.Context, UserSession_revokeLoginSession) error
+
+	RevokeAllLoginSessions(context.Context, UserSession_revokeAllLoginSessions) error
+}
+
+// UserSession_NewServer creates a new Server from an implementation of UserSession_Server.
+func UserSession_NewServer(s UserSession_Server) *server.Server {
+	c, _ := s.(server.Shutdowner)
+	return server.New(UserSession_Methods(nil, s), s, c)
+}
+
+// UserSession_ServerToClient creates a new Client from an implementation of UserSession_Server.
+// The caller is responsible for calling Release on the returned Client.
+func UserSession_ServerToClient(s UserSession_Server) UserSession {
+	return UserSession(capnp.NewClient(UserSession_NewServer(s)))
+}
+
+// UserSession_Methods appends Methods to a slice that invoke the methods on s.
+// This can be used to create a more complicated Server.
+func UserSession_Methods(methods []server.Method, s UserSession_Server) []server.Method {
+	if cap(methods) == 0 {
+		methods = make([]server.Method, 0, 11)
+	}
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0xf2c70d6545f83c8d,
+			MethodID:      0,
 			InterfaceName: "external.capnp:UserSession",
 			MethodName:    "installPackage",
 		},
@@ -2454,259 +2826,5779 @@ func UserSession_Methods(methods []server.Method, s UserSession_Server) []server
 		},
 	})
 
-	return methods
-}
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0xf2c70d6545f83c8d,
+			MethodID:      2,
+			InterfaceName: "external.capnp:UserSession",
+			MethodName:    "notifications",
+		},
+		Impl: func(ctx context.Context, call *server.Call) error {
+			return s.Notifications(ctx, UserSession_notifications{call})
+		},
+	})
 
-// UserSession_installPackage holds the state for a server call to UserSession.installPackage.
-// See server.Call for documentation.
-type UserSession_installPackage struct {
-	*server.Call
-}
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0xf2c70d6545f83c8d,
+			MethodID:      3,
+			InterfaceName: "external.capnp:UserSession",
+			MethodName:    "markNotificationsRead",
+		},
+		Impl: func(ctx context.Context, call *server.Call) error {
+			return s.MarkNotificationsRead(ctx, UserSession_markNotificationsRead{call})
+		},
+	})
 
-// Args returns the call's arguments.
-func (c UserSession_installPackage) Args() UserSession_installPackage_Params {
-	return UserSession_installPackage_Params(c.Call.Args())
-}
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0xf2c70d6545f83c8d,
+			MethodID:      4,
+			InterfaceName: "external.capnp:UserSession",
+			MethodName:    "unreadNotificationCount",
+		},
+		Impl: func(ctx context.Context, call *server.Call) error {
+			return s.UnreadNotificationCount(ctx, UserSession_unreadNotificationCount{call})
+		},
+	})
 
-// AllocResults allocates the results struct.
-func (c UserSession_installPackage) AllocResults() (UserSession_installPackage_Results, error) {
-	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 1})
-	return UserSession_installPackage_Results(r), err
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0xf2c70d6545f83c8d,
+			MethodID:      5,
+			InterfaceName: "external.capnp:UserSession",
+			MethodName:    "storageUsage",
+		},
+		Impl: func(ctx context.Context, call *server.Call) error {
+			return s.StorageUsage(ctx, UserSession_storageUsage{call})
+		},
+	})
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0xf2c70d6545f83c8d,
+			MethodID:      6,
+			InterfaceName: "external.capnp:UserSession",
+			MethodName:    "backupGrain",
+		},
+		Impl: func(ctx context.Context, call *server.Call) error {
+			return s.BackupGrain(ctx, UserSession_backupGrain{call})
+		},
+	})
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0xf2c70d6545f83c8d,
+			MethodID:      7,
+			InterfaceName: "external.capnp:UserSession",
+			MethodName:    "grainLog",
+		},
+		Impl: func(ctx context.Context, call *server.Call) error {
+			return s.GrainLog(ctx, UserSession_grainLog{call})
+		},
+	})
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0xf2c70d6545f83c8d,
+			MethodID:      8,
+			InterfaceName: "external.capnp:UserSession",
+			MethodName:    "loginSessions",
+		},
+		Impl: func(ctx context.Context, call *server.Call) error {
+			return s.LoginSessions(ctx, UserSession_loginSessions{call})
+		},
+	})
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0xf2c70d6545f83c8d,
+			MethodID:      9,
+			InterfaceName: "external.capnp:UserSession",
+			MethodName:    "revokeLoginSession",
+		},
+		Impl: func(ctx context.Context, call *server.Call) error {
+			return s.RevokeLoginSession(ctx, UserSession_revokeLoginSession{call})
+		},
+	})
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0xf2c70d6545f83c8d,
+			MethodID:      10,
+			InterfaceName: "external.capnp:UserSession",
+			MethodName:    "revokeAllLoginSessions",
+		},
+		Impl: func(ctx context.Context, call *server.Call) error {
+			return s.RevokeAllLoginSessions(ctx, UserSession_revokeAllLoginSessions{call})
+		},
+	})
+
+	return methods
+}
+
+// UserSession_installPackage holds the state for a server call to UserSession.installPackage.
+// See server.Call for documentation.
+type UserSession_installPackage struct {
+	*server.Call
+}
+
+// Args returns the call's arguments.
+func (c UserSession_installPackage) Args() UserSession_installPackage_Params {
+	return UserSession_installPackage_Params(c.Call.Args())
+}
+
+// AllocResults allocates the results struct.
+func (c UserSession_installPackage) AllocResults() (UserSession_installPackage_Results, error) {
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UserSession_installPackage_Results(r), err
+}
+
+// UserSession_listPackages holds the state for a server call to UserSession.listPackages.
+// See server.Call for documentation.
+type UserSession_listPackages struct {
+	*server.Call
+}
+
+// Args returns the call's arguments.
+func (c UserSession_listPackages) Args() UserSession_listPackages_Params {
+	return UserSession_listPackages_Params(c.Call.Args())
+}
+
+// AllocResults allocates the results struct.
+func (c UserSession_listPackages) AllocResults() (UserSession_listPackages_Results, error) {
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UserSession_listPackages_Results(r), err
+}
+
+// UserSession_notifications holds the state for a server call to UserSession.notifications.
+// See server.Call for documentation.
+type UserSession_notifications struct {
+	*server.Call
+}
+
+// Args returns the call's arguments.
+func (c UserSession_notifications) Args() UserSession_notifications_Params {
+	return UserSession_notifications_Params(c.Call.Args())
+}
+
+// AllocResults allocates the results struct.
+func (c UserSession_notifications) AllocResults() (UserSession_notifications_Results, error) {
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return UserSession_notifications_Results(r), err
+}
+
+// UserSession_markNotificationsRead holds the state for a server call to UserSession.markNotificationsRead.
+// See server.Call for documentation.
+type UserSession_markNotificationsRead struct {
+	*server.Call
+}
+
+// Args returns the call's arguments.
+func (c UserSession_markNotificationsRead) Args() UserSession_markNotificationsRead_Params {
+	return UserSession_markNotificationsRead_Params(c.Call.Args())
+}
+
+// AllocResults allocates the results struct.
+func (c UserSession_markNotificationsRead) AllocResults() (UserSession_markNotificationsRead_Results, error) {
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UserSession_markNotificationsRead_Results(r), err
+}
+
+// UserSession_unreadNotificationCount holds the state for a server call to UserSession.unreadNotificationCount.
+// See server.Call for documentation.
+type UserSession_unreadNotificationCount struct {
+	*server.Call
+}
+
+// Args returns the call's arguments.
+func (c UserSession_unreadNotificationCount) Args() UserSession_unreadNotificationCount_Params {
+	return UserSession_unreadNotificationCount_Params(c.Call.Args())
+}
+
+// AllocResults allocates the results struct.
+func (c UserSession_unreadNotificationCount) AllocResults() (UserSession_unreadNotificationCount_Results, error) {
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 8, PointerCount: 1})
+	return UserSession_unreadNotificationCount_Results(r), err
+}
+
+// UserSession_storageUsage holds the state for a server call to UserSession.storageUsage.
+// See server.Call for documentation.
+type UserSession_storageUsage struct {
+	*server.Call
+}
+
+// Args returns the call's arguments.
+func (c UserSession_storageUsage) Args() UserSession_storageUsage_Params {
+	return UserSession_storageUsage_Params(c.Call.Args())
+}
+
+// AllocResults allocates the results struct.
+func (c UserSession_storageUsage) AllocResults() (UserSession_storageUsage_Results, error) {
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 16, PointerCount: 2})
+	return UserSession_storageUsage_Results(r), err
+}
+
+// UserSession_backupGrain holds the state for a server call to UserSession.backupGrain.
+// See server.Call for documentation.
+type UserSession_backupGrain struct {
+	*server.Call
+}
+
+// Args returns the call's arguments.
+func (c UserSession_backupGrain) Args() UserSession_backupGrain_Params {
+	return UserSession_backupGrain_Params(c.Call.Args())
+}
+
+// AllocResults allocates the results struct.
+func (c UserSession_backupGrain) AllocResults() (UserSession_backupGrain_Results, error) {
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UserSession_backupGrain_Results(r), err
+}
+
+// UserSession_grainLog holds the state for a server call to UserSession.grainLog.
+// See server.Call for documentation.
+type UserSession_grainLog struct {
+	*server.Call
+}
+
+// Args returns the call's arguments.
+func (c UserSession_grainLog) Args() UserSession_grainLog_Params {
+	return UserSession_grainLog_Params(c.Call.Args())
+}
+
+// AllocResults allocates the results struct.
+func (c UserSession_grainLog) AllocResults() (UserSession_grainLog_Results, error) {
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return UserSession_grainLog_Results(r), err
+}
+
+// UserSession_loginSessions holds the state for a server call to UserSession.loginSessions.
+// See server.Call for documentation.
+type UserSession_loginSessions struct {
+	*server.Call
+}
+
+// Args returns the call's arguments.
+func (c UserSession_loginSessions) Args() UserSession_loginSessions_Params {
+	return UserSession_loginSessions_Params(c.Call.Args())
+}
+
+// AllocResults allocates the results struct.
+func (c UserSession_loginSessions) AllocResults() (UserSession_loginSessions_Results, error) {
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return UserSession_loginSessions_Results(r), err
+}
+
+// UserSession_revokeLoginSession holds the state for a server call to UserSession.revokeLoginSession.
+// See server.Call for documentation.
+type UserSession_revokeLoginSession struct {
+	*server.Call
+}
+
+// Args returns the call's arguments.
+func (c UserSession_revokeLoginSession) Args() UserSession_revokeLoginSession_Params {
+	return UserSession_revokeLoginSession_Params(c.Call.Args())
+}
+
+// AllocResults allocates the results struct.
+func (c UserSession_revokeLoginSession) AllocResults() (UserSession_revokeLoginSession_Results, error) {
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UserSession_revokeLoginSession_Results(r), err
+}
+
+// UserSession_revokeAllLoginSessions holds the state for a server call to UserSession.revokeAllLoginSessions.
+// See server.Call for documentation.
+type UserSession_revokeAllLoginSessions struct {
+	*server.Call
+}
+
+// Args returns the call's arguments.
+func (c UserSession_revokeAllLoginSessions) Args() UserSession_revokeAllLoginSessions_Params {
+	return UserSession_revokeAllLoginSessions_Params(c.Call.Args())
+}
+
+// AllocResults allocates the results struct.
+func (c UserSession_revokeAllLoginSessions) AllocResults() (UserSession_revokeAllLoginSessions_Results, error) {
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 8, PointerCount: 1})
+	return UserSession_revokeAllLoginSessions_Results(r), err
+}
+
+// UserSession_List is a list of UserSession.
+type UserSession_List = capnp.CapList[UserSession]
+
+// NewUserSession_List creates a new list of UserSession.
+func NewUserSession_List(s *capnp.Segment, sz int32) (UserSession_List, error) {
+	l, err := capnp.NewPointerList(s, sz)
+	return capnp.CapList[UserSession](l), err
+}
+
+type UserSession_installPackage_Params capnp.Struct
+
+// UserSession_installPackage_Params_TypeID is the unique identifier for the type UserSession_installPackage_Params.
+const UserSession_installPackage_Params_TypeID = 0xf8dcf7451554118b
+
+func NewUserSession_installPackage_Params(s *capnp.Segment) (UserSession_installPackage_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
+	return UserSession_installPackage_Params(st), err
+}
+
+func NewRootUserSession_installPackage_Params(s *capnp.Segment) (UserSession_installPackage_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
+	return UserSession_installPackage_Params(st), err
+}
+
+func ReadRootUserSession_installPackage_Params(msg *capnp.Message) (UserSession_installPackage_Params, error) {
+	root, err := msg.Root()
+	return UserSession_installPackage_Params(root.Struct()), err
+}
+
+func (s UserSession_installPackage_Params) String() string {
+	str, _ := text.Marshal(0xf8dcf7451554118b, capnp.Struct(s))
+	return str
+}
+
+func (s UserSession_installPackage_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UserSession_installPackage_Params) DecodeFromPtr(p capnp.Ptr) UserSession_installPackage_Params {
+	return UserSession_installPackage_Params(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UserSession_installPackage_Params) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UserSession_installPackage_Params) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UserSession_installPackage_Params) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UserSession_installPackage_Params) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+
+// UserSession_installPackage_Params_List is a list of UserSession_installPackage_Params.
+type UserSession_installPackage_Params_List = capnp.StructList[UserSession_installPackage_Params]
+
+// NewUserSession_installPackage_Params creates a new list of UserSession_installPackage_Params.
+func NewUserSession_installPackage_Params_List(s *capnp.Segment, sz int32) (UserSession_installPackage_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0}, sz)
+	return capnp.StructList[UserSession_installPackage_Params](l), err
+}
+
+// UserSession_installPackage_Params_Future is a wrapper for a UserSession_installPackage_Params promised by a client call.
+type UserSession_installPackage_Params_Future struct{ *capnp.Future }
+
+func (f UserSession_installPackage_Params_Future) Struct() (UserSession_installPackage_Params, error) {
+	p, err := f.Future.Ptr()
+	return UserSession_installPackage_Params(p.Struct()), err
+}
+
+type UserSession_installPackage_Results capnp.Struct
+
+// UserSession_installPackage_Results_TypeID is the unique identifier for the type UserSession_installPackage_Results.
+const UserSession_installPackage_Results_TypeID = 0xc4028bdb9c509747
+
+func NewUserSession_installPackage_Results(s *capnp.Segment) (UserSession_installPackage_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UserSession_installPackage_Results(st), err
+}
+
+func NewRootUserSession_installPackage_Results(s *capnp.Segment) (UserSession_installPackage_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UserSession_installPackage_Results(st), err
+}
+
+func ReadRootUserSession_installPackage_Results(msg *capnp.Message) (UserSession_installPackage_Results, error) {
+	root, err := msg.Root()
+	return UserSession_installPackage_Results(root.Struct()), err
+}
+
+func (s UserSession_installPackage_Results) String() string {
+	str, _ := text.Marshal(0xc4028bdb9c509747, capnp.Struct(s))
+	return str
+}
+
+func (s UserSession_installPackage_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UserSession_installPackage_Results) DecodeFromPtr(p capnp.Ptr) UserSession_installPackage_Results {
+	return UserSession_installPackage_Results(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UserSession_installPackage_Results) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UserSession_installPackage_Results) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UserSession_installPackage_Results) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UserSession_installPackage_Results) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s UserSession_installPackage_Results) Stream() Package_InstallStream {
+	p, _ := capnp.Struct(s).Ptr(0)
+	return Package_InstallStream(p.Interface().Client())
+}
+
+func (s UserSession_installPackage_Results) HasStream() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s UserSession_installPackage_Results) SetStream(v Package_InstallStream) error {
+	if !v.IsValid() {
+		return capnp.Struct(s).SetPtr(0, capnp.Ptr{})
+	}
+	seg := s.Segment()
+	in := capnp.NewInterface(seg, seg.Message().CapTable().Add(capnp.Client(v)))
+	return capnp.Struct(s).SetPtr(0, in.ToPtr())
+}
+
+// UserSession_installPackage_Results_List is a list of UserSession_installPackage_Results.
+type UserSession_installPackage_Results_List = capnp.StructList[UserSession_installPackage_Results]
+
+// NewUserSession_installPackage_Results creates a new list of UserSession_installPackage_Results.
+func NewUserSession_installPackage_Results_List(s *capnp.Segment, sz int32) (UserSession_installPackage_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return capnp.StructList[UserSession_installPackage_Results](l), err
+}
+
+// UserSession_installPackage_Results_Future is a wrapper for a UserSession_installPackage_Results promised by a client call.
+type UserSession_installPackage_Results_Future struct{ *capnp.Future }
+
+func (f UserSession_installPackage_Results_Future) Struct() (UserSession_installPackage_Results, error) {
+	p, err := f.Future.Ptr()
+	return UserSession_installPackage_Results(p.Struct()), err
+}
+func (p UserSession_installPackage_Results_Future) Stream() Package_InstallStream {
+	return Package_InstallStream(p.Future.Field(0, nil).Client())
+}
+
+type UserSession_listPackages_Params capnp.Struct
+
+// UserSession_listPackages_Params_TypeID is the unique identifier for the type UserSession_listPackages_Params.
+const UserSession_listPackages_Params_TypeID = 0xcc3b81b565529dc3
+
+func NewUserSession_listPackages_Params(s *capnp.Segment) (UserSession_listPackages_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UserSession_listPackages_Params(st), err
+}
+
+func NewRootUserSession_listPackages_Params(s *capnp.Segment) (UserSession_listPackages_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UserSession_listPackages_Params(st), err
+}
+
+func ReadRootUserSession_listPackages_Params(msg *capnp.Message) (UserSession_listPackages_Params, error) {
+	root, err := msg.Root()
+	return UserSession_listPackages_Params(root.Struct()), err
+}
+
+func (s UserSession_listPackages_Params) String() string {
+	str, _ := text.Marshal(0xcc3b81b565529dc3, capnp.Struct(s))
+	return str
+}
+
+func (s UserSession_listPackages_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UserSession_listPackages_Params) DecodeFromPtr(p capnp.Ptr) UserSession_listPackages_Params {
+	return UserSession_listPackages_Params(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UserSession_listPackages_Params) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UserSession_listPackages_Params) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UserSession_listPackages_Params) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UserSession_listPackages_Params) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s UserSession_listPackages_Params) Into() collection.Pusher {
+	p, _ := capnp.Struct(s).Ptr(0)
+	return collection.Pusher(p.Interface().Client())
+}
+
+func (s UserSession_listPackages_Params) HasInto() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s UserSession_listPackages_Params) SetInto(v collection.Pusher) error {
+	if !v.IsValid() {
+		return capnp.Struct(s).SetPtr(0, capnp.Ptr{})
+	}
+	seg := s.Segment()
+	in := capnp.NewInterface(seg, seg.Message().CapTable().Add(capnp.Client(v)))
+	return capnp.Struct(s).SetPtr(0, in.ToPtr())
+}
+
+// UserSession_listPackages_Params_List is a list of UserSession_listPackages_Params.
+type UserSession_listPackages_Params_List = capnp.StructList[UserSession_listPackages_Params]
+
+// NewUserSession_listPackages_Params creates a new list of UserSession_listPackages_Params.
+func NewUserSession_listPackages_Params_List(s *capnp.Segment, sz int32) (UserSession_listPackages_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return capnp.StructList[UserSession_listPackages_Params](l), err
+}
+
+// UserSession_listPackages_Params_Future is a wrapper for a UserSession_listPackages_Params promised by a client call.
+type UserSession_listPackages_Params_Future struct{ *capnp.Future }
+
+func (f UserSession_listPackages_Params_Future) Struct() (UserSession_listPackages_Params, error) {
+	p, err := f.Future.Ptr()
+	return UserSession_listPackages_Params(p.Struct()), err
+}
+func (p UserSession_listPackages_Params_Future) Into() collection.Pusher {
+	return collection.Pusher(p.Future.Field(0, nil).Client())
+}
+
+type UserSession_listPackages_Results capnp.Struct
+
+// UserSession_listPackages_Results_TypeID is the unique identifier for the type UserSession_listPackages_Results.
+const UserSession_listPackages_Results_TypeID = 0xc5ea967cde37a2fe
+
+func NewUserSession_listPackages_Results(s *capnp.Segment) (UserSession_listPackages_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UserSession_listPackages_Results(st), err
+}
+
+func NewRootUserSession_listPackages_Results(s *capnp.Segment) (UserSession_listPackages_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UserSession_listPackages_Results(st), err
+}
+
+func ReadRootUserSession_listPackages_Results(msg *capnp.Message) (UserSession_listPackages_Results, error) {
+	root, err := msg.Root()
+	return UserSession_listPackages_Results(root.Struct()), err
+}
+
+func (s UserSession_listPackages_Results) String() string {
+	str, _ := text.Marshal(0xc5ea967cde37a2fe, capnp.Struct(s))
+	return str
+}
+
+func (s UserSession_listPackages_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UserSession_listPackages_Results) DecodeFromPtr(p capnp.Ptr) UserSession_listPackages_Results {
+	return UserSession_listPackages_Results(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UserSession_listPackages_Results) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UserSession_listPackages_Results) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UserSession_listPackages_Results) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UserSession_listPackages_Results) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s UserSession_listPackages_Results) Error() (ApiError, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return ApiError(p.Struct()), err
+}
+
+func (s UserSession_listPackages_Results) HasError() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s UserSession_listPackages_Results) SetError(v ApiError) error {
+	return capnp.Struct(s).SetPtr(0, capnp.Struct(v).ToPtr())
+}
+
+// NewError sets the error field to a newly
+// allocated ApiError struct, preferring placement in s's segment.
+func (s UserSession_listPackages_Results) NewError() (ApiError, error) {
+	ss, err := NewApiError(capnp.Struct(s).Segment())
+	if err != nil {
+		return ApiError{}, err
+	}
+	err = capnp.Struct(s).SetPtr(0, capnp.Struct(ss).ToPtr())
+	return ss, err
+}
+
+// UserSession_listPackages_Results_List is a list of UserSession_listPackages_Results.
+type UserSession_listPackages_Results_List = capnp.StructList[UserSession_listPackages_Results]
+
+// NewUserSession_listPackages_Results creates a new list of UserSession_listPackages_Results.
+func NewUserSession_listPackages_Results_List(s *capnp.Segment, sz int32) (UserSession_listPackages_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return capnp.StructList[UserSession_listPackages_Results](l), err
+}
+
+// UserSession_listPackages_Results_Future is a wrapper for a UserSession_listPackages_Results promised by a client call.
+type UserSession_listPackages_Results_Future struct{ *capnp.Future }
+
+func (f UserSession_listPackages_Results_Future) Struct() (UserSession_listPackages_Results, error) {
+	p, err := f.Future.Ptr()
+	return UserSession_listPackages_Results(p.Struct()), err
+}
+func (p UserSession_listPackages_Results_Future) Error() ApiError_Future {
+	return ApiError_Future{Future: p.Future.Field(0, nil)}
+}
+
+type UserSession_notifications_Params capnp.Struct
+
+// UserSession_notifications_Params_TypeID is the unique identifier for the type UserSession_notifications_Params.
+const UserSession_notifications_Params_TypeID = 0x8aa84d2db3cf9162
+
+func NewUserSession_notifications_Params(s *capnp.Segment) (UserSession_notifications_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UserSession_notifications_Params(st), err
+}
+
+func NewRootUserSession_notifications_Params(s *capnp.Segment) (UserSession_notifications_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UserSession_notifications_Params(st), err
+}
+
+func ReadRootUserSession_notifications_Params(msg *capnp.Message) (UserSession_notifications_Params, error) {
+	root, err := msg.Root()
+	return UserSession_notifications_Params(root.Struct()), err
+}
+
+func (s UserSession_notifications_Params) String() string {
+	str, _ := text.Marshal(0x8aa84d2db3cf9162, capnp.Struct(s))
+	return str
+}
+
+func (s UserSession_notifications_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UserSession_notifications_Params) DecodeFromPtr(p capnp.Ptr) UserSession_notifications_Params {
+	return UserSession_notifications_Params(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UserSession_notifications_Params) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UserSession_notifications_Params) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UserSession_notifications_Params) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UserSession_notifications_Params) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s UserSession_notifications_Params) Into() collection.Pusher {
+	p, _ := capnp.Struct(s).Ptr(0)
+	return collection.Pusher(p.Interface().Client())
+}
+
+func (s UserSession_notifications_Params) HasInto() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s UserSession_notifications_Params) SetInto(v collection.Pusher) error {
+	if !v.IsValid() {
+		return capnp.Struct(s).SetPtr(0, capnp.Ptr{})
+	}
+	seg := s.Segment()
+	in := capnp.NewInterface(seg, seg.Message().CapTable().Add(capnp.Client(v)))
+	return capnp.Struct(s).SetPtr(0, in.ToPtr())
+}
+
+// UserSession_notifications_Params_List is a list of UserSession_notifications_Params.
+type UserSession_notifications_Params_List = capnp.StructList[UserSession_notifications_Params]
+
+// NewUserSession_notifications_Params creates a new list of UserSession_notifications_Params.
+func NewUserSession_notifications_Params_List(s *capnp.Segment, sz int32) (UserSession_notifications_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return capnp.StructList[UserSession_notifications_Params](l), err
+}
+
+// UserSession_notifications_Params_Future is a wrapper for a UserSession_notifications_Params promised by a client call.
+type UserSession_notifications_Params_Future struct{ *capnp.Future }
+
+func (f UserSession_notifications_Params_Future) Struct() (UserSession_notifications_Params, error) {
+	p, err := f.Future.Ptr()
+	return UserSession_notifications_Params(p.Struct()), err
+}
+func (p UserSession_notifications_Params_Future) Into() collection.Pusher {
+	return collection.Pusher(p.Future.Field(0, nil).Client())
+}
+
+type UserSession_notifications_Results capnp.Struct
+
+// UserSession_notifications_Results_TypeID is the unique identifier for the type UserSession_notifications_Results.
+const UserSession_notifications_Results_TypeID = 0xac86a563a19f9ce0
+
+func NewUserSession_notifications_Results(s *capnp.Segment) (UserSession_notifications_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return UserSession_notifications_Results(st), err
+}
+
+func NewRootUserSession_notifications_Results(s *capnp.Segment) (UserSession_notifications_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return UserSession_notifications_Results(st), err
+}
+
+func ReadRootUserSession_notifications_Results(msg *capnp.Message) (UserSession_notifications_Results, error) {
+	root, err := msg.Root()
+	return UserSession_notifications_Results(root.Struct()), err
+}
+
+func (s UserSession_notifications_Results) String() string {
+	str, _ := text.Marshal(0xac86a563a19f9ce0, capnp.Struct(s))
+	return str
+}
+
+func (s UserSession_notifications_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UserSession_notifications_Results) DecodeFromPtr(p capnp.Ptr) UserSession_notifications_Results {
+	return UserSession_notifications_Results(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UserSession_notifications_Results) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UserSession_notifications_Results) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UserSession_notifications_Results) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UserSession_notifications_Results) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s UserSession_notifications_Results) Subscription() util.Handle {
+	p, _ := capnp.Struct(s).Ptr(0)
+	return util.Handle(p.Interface().Client())
+}
+
+func (s UserSession_notifications_Results) HasSubscription() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s UserSession_notifications_Results) SetSubscription(v util.Handle) error {
+	if !v.IsValid() {
+		return capnp.Struct(s).SetPtr(0, capnp.Ptr{})
+	}
+	seg := s.Segment()
+	in := capnp.NewInterface(seg, seg.Message().CapTable().Add(capnp.Client(v)))
+	return capnp.Struct(s).SetPtr(0, in.ToPtr())
+}
+
+func (s UserSession_notifications_Results) Error() (ApiError, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return ApiError(p.Struct()), err
+}
+
+func (s UserSession_notifications_Results) HasError() bool {
+	return capnp.Struct(s).HasPtr(1)
+}
+
+func (s UserSession_notifications_Results) SetError(v ApiError) error {
+	return capnp.Struct(s).SetPtr(1, capnp.Struct(v).ToPtr())
+}
+
+// NewError sets the error field to a newly
+// allocated ApiError struct, preferring placement in s's segment.
+func (s UserSession_notifications_Results) NewError() (ApiError, error) {
+	ss, err := NewApiError(capnp.Struct(s).Segment())
+	if err != nil {
+		return ApiError{}, err
+	}
+	err = capnp.Struct(s).SetPtr(1, capnp.Struct(ss).ToPtr())
+	return ss, err
+}
+
+// UserSession_notifications_Results_List is a list of UserSession_notifications_Results.
+type UserSession_notifications_Results_List = capnp.StructList[UserSession_notifications_Results]
+
+// NewUserSession_notifications_Results creates a new list of UserSession_notifications_Results.
+func NewUserSession_notifications_Results_List(s *capnp.Segment, sz int32) (UserSession_notifications_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2}, sz)
+	return capnp.StructList[UserSession_notifications_Results](l), err
+}
+
+// UserSession_notifications_Results_Future is a wrapper for a UserSession_notifications_Results promised by a client call.
+type UserSession_notifications_Results_Future struct{ *capnp.Future }
+
+func (f UserSession_notifications_Results_Future) Struct() (UserSession_notifications_Results, error) {
+	p, err := f.Future.Ptr()
+	return UserSession_notifications_Results(p.Struct()), err
+}
+func (p UserSession_notifications_Results_Future) Subscription() util.Handle {
+	return util.Handle(p.Future.Field(0, nil).Client())
+}
+
+func (p UserSession_notifications_Results_Future) Error() ApiError_Future {
+	return ApiError_Future{Future: p.Future.Field(1, nil)}
+}
+
+type UserSession_markNotificationsRead_Params capnp.Struct
+
+// UserSession_markNotificationsRead_Params_TypeID is the unique identifier for the type UserSession_markNotificationsRead_Params.
+const UserSession_markNotificationsRead_Params_TypeID = 0x94274548df015436
+
+func NewUserSession_markNotificationsRead_Params(s *capnp.Segment) (UserSession_markNotificationsRead_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UserSession_markNotificationsRead_Params(st), err
+}
+
+func NewRootUserSession_markNotificationsRead_Params(s *capnp.Segment) (UserSession_markNotificationsRead_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UserSession_markNotificationsRead_Params(st), err
+}
+
+func ReadRootUserSession_markNotificationsRead_Params(msg *capnp.Message) (UserSession_markNotificationsRead_Params, error) {
+	root, err := msg.Root()
+	return UserSession_markNotificationsRead_Params(root.Struct()), err
+}
+
+func (s UserSession_markNotificationsRead_Params) String() string {
+	str, _ := text.Marshal(0x94274548df015436, capnp.Struct(s))
+	return str
+}
+
+func (s UserSession_markNotificationsRead_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UserSession_markNotificationsRead_Params) DecodeFromPtr(p capnp.Ptr) UserSession_markNotificationsRead_Params {
+	return UserSession_markNotificationsRead_Params(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UserSession_markNotificationsRead_Params) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UserSession_markNotificationsRead_Params) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UserSession_markNotificationsRead_Params) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UserSession_markNotificationsRead_Params) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s UserSession_markNotificationsRead_Params) Keys() (capnp.TextList, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return capnp.TextList(p.List()), err
+}
+
+func (s UserSession_markNotificationsRead_Params) HasKeys() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s UserSession_markNotificationsRead_Params) SetKeys(v capnp.TextList) error {
+	return capnp.Struct(s).SetPtr(0, v.ToPtr())
+}
+
+// NewKeys sets the keys field to a newly
+// allocated capnp.TextList, preferring placement in s's segment.
+func (s UserSession_markNotificationsRead_Params) NewKeys(n int32) (capnp.TextList, error) {
+	l, err := capnp.NewTextList(capnp.Struct(s).Segment(), n)
+	if err != nil {
+		return capnp.TextList{}, err
+	}
+	err = capnp.Struct(s).SetPtr(0, l.ToPtr())
+	return l, err
+}
+
+// UserSession_markNotificationsRead_Params_List is a list of UserSession_markNotificationsRead_Params.
+type UserSession_markNotificationsRead_Params_List = capnp.StructList[UserSession_markNotificationsRead_Params]
+
+// NewUserSession_markNotificationsRead_Params creates a new list of UserSession_markNotificationsRead_Params.
+func NewUserSession_markNotificationsRead_Params_List(s *capnp.Segment, sz int32) (UserSession_markNotificationsRead_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return capnp.StructList[UserSession_markNotificationsRead_Params](l), err
+}
+
+// UserSession_markNotificationsRead_Params_Future is a wrapper for a UserSession_markNotificationsRead_Params promised by a client call.
+type UserSession_markNotificationsRead_Params_Future struct{ *capnp.Future }
+
+func (f UserSession_markNotificationsRead_Params_Future) Struct() (UserSession_markNotificationsRead_Params, error) {
+	p, err := f.Future.Ptr()
+	return UserSession_markNotificationsRead_Params(p.Struct()), err
+}
+
+type UserSession_markNotificationsRead_Results capnp.Struct
+
+// UserSession_markNotificationsRead_Results_TypeID is the unique identifier for the type UserSession_markNotificationsRead_Results.
+const UserSession_markNotificationsRead_Results_TypeID = 0x9fd7a614223c08a3
+
+func NewUserSession_markNotificationsRead_Results(s *capnp.Segment) (UserSession_markNotificationsRead_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UserSession_markNotificationsRead_Results(st), err
+}
+
+func NewRootUserSession_markNotificationsRead_Results(s *capnp.Segment) (UserSession_markNotificationsRead_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UserSession_markNotificationsRead_Results(st), err
+}
+
+func ReadRootUserSession_markNotificationsRead_Results(msg *capnp.Message) (UserSession_markNotificationsRead_Results, error) {
+	root, err := msg.Root()
+	return UserSession_markNotificationsRead_Results(root.Struct()), err
+}
+
+func (s UserSession_markNotificationsRead_Results) String() string {
+	str, _ := text.Marshal(0x9fd7a614223c08a3, capnp.Struct(s))
+	return str
+}
+
+func (s UserSession_markNotificationsRead_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UserSession_markNotificationsRead_Results) DecodeFromPtr(p capnp.Ptr) UserSession_markNotificationsRead_Results {
+	return UserSession_markNotificationsRead_Results(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UserSession_markNotificationsRead_Results) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UserSession_markNotificationsRead_Results) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UserSession_markNotificationsRead_Results) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UserSession_markNotificationsRead_Results) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s UserSession_markNotificationsRead_Results) Error() (ApiError, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return ApiError(p.Struct()), err
+}
+
+func (s UserSession_markNotificationsRead_Results) HasError() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s UserSession_markNotificationsRead_Results) SetError(v ApiError) error {
+	return capnp.Struct(s).SetPtr(0, capnp.Struct(v).ToPtr())
+}
+
+// NewError sets the error field to a newly
+// allocated ApiError struct, preferring placement in s's segment.
+func (s UserSession_markNotificationsRead_Results) NewError() (ApiError, error) {
+	ss, err := NewApiError(capnp.Struct(s).Segment())
+	if err != nil {
+		return ApiError{}, err
+	}
+	err = capnp.Struct(s).SetPtr(0, capnp.Struct(ss).ToPtr())
+	return ss, err
+}
+
+// UserSession_markNotificationsRead_Results_List is a list of UserSession_markNotificationsRead_Results.
+type UserSession_markNotificationsRead_Results_List = capnp.StructList[UserSession_markNotificationsRead_Results]
+
+// NewUserSession_markNotificationsRead_Results creates a new list of UserSession_markNotificationsRead_Results.
+func NewUserSession_markNotificationsRead_Results_List(s *capnp.Segment, sz int32) (UserSession_markNotificationsRead_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return capnp.StructList[UserSession_markNotificationsRead_Results](l), err
+}
+
+// UserSession_markNotificationsRead_Results_Future is a wrapper for a UserSession_markNotificationsRead_Results promised by a client call.
+type UserSession_markNotificationsRead_Results_Future struct{ *capnp.Future }
+
+func (f UserSession_markNotificationsRead_Results_Future) Struct() (UserSession_markNotificationsRead_Results, error) {
+	p, err := f.Future.Ptr()
+	return UserSession_markNotificationsRead_Results(p.Struct()), err
+}
+func (p UserSession_markNotificationsRead_Results_Future) Error() ApiError_Future {
+	return ApiError_Future{Future: p.Future.Field(0, nil)}
+}
+
+type UserSession_unreadNotificationCount_Params capnp.Struct
+
+// UserSession_unreadNotificationCount_Params_TypeID is the unique identifier for the type UserSession_unreadNotificationCount_Params.
+const UserSession_unreadNotificationCount_Params_TypeID = 0xf70bdac9dae6ee62
+
+func NewUserSession_unreadNotificationCount_Params(s *capnp.Segment) (UserSession_unreadNotificationCount_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
+	return UserSession_unreadNotificationCount_Params(st), err
+}
+
+func NewRootUserSession_unreadNotificationCount_Params(s *capnp.Segment) (UserSession_unreadNotificationCount_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
+	return UserSession_unreadNotificationCount_Params(st), err
+}
+
+func ReadRootUserSession_unreadNotificationCount_Params(msg *capnp.Message) (UserSession_unreadNotificationCount_Params, error) {
+	root, err := msg.Root()
+	return UserSession_unreadNotificationCount_Params(root.Struct()), err
+}
+
+func (s UserSession_unreadNotificationCount_Params) String() string {
+	str, _ := text.Marshal(0xf70bdac9dae6ee62, capnp.Struct(s))
+	return str
+}
+
+func (s UserSession_unreadNotificationCount_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UserSession_unreadNotificationCount_Params) DecodeFromPtr(p capnp.Ptr) UserSession_unreadNotificationCount_Params {
+	return UserSession_unreadNotificationCount_Params(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UserSession_unreadNotificationCount_Params) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UserSession_unreadNotificationCount_Params) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UserSession_unreadNotificationCount_Params) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UserSession_unreadNotificationCount_Params) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+
+// UserSession_unreadNotificationCount_Params_List is a list of UserSession_unreadNotificationCount_Params.
+type UserSession_unreadNotificationCount_Params_List = capnp.StructList[UserSession_unreadNotificationCount_Params]
+
+// NewUserSession_unreadNotificationCount_Params creates a new list of UserSession_unreadNotificationCount_Params.
+func NewUserSession_unreadNotificationCount_Params_List(s *capnp.Segment, sz int32) (UserSession_unreadNotificationCount_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0}, sz)
+	return capnp.StructList[UserSession_unreadNotificationCount_Params](l), err
+}
+
+// UserSession_unreadNotificationCount_Params_Future is a wrapper for a UserSession_unreadNotificationCount_Params promised by a client call.
+type UserSession_unreadNotificationCount_Params_Future struct{ *capnp.Future }
+
+func (f UserSession_unreadNotificationCount_Params_Future) Struct() (UserSession_unreadNotificationCount_Params, error) {
+	p, err := f.Future.Ptr()
+	return UserSession_unreadNotificationCount_Params(p.Struct()), err
+}
+
+type UserSession_unreadNotificationCount_Results capnp.Struct
+
+// UserSession_unreadNotificationCount_Results_TypeID is the unique identifier for the type UserSession_unreadNotificationCount_Results.
+const UserSession_unreadNotificationCount_Results_TypeID = 0xbb0f592f14df4a7f
+
+func NewUserSession_unreadNotificationCount_Results(s *capnp.Segment) (UserSession_unreadNotificationCount_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1})
+	return UserSession_unreadNotificationCount_Results(st), err
+}
+
+func NewRootUserSession_unreadNotificationCount_Results(s *capnp.Segment) (UserSession_unreadNotificationCount_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1})
+	return UserSession_unreadNotificationCount_Results(st), err
+}
+
+func ReadRootUserSession_unreadNotificationCount_Results(msg *capnp.Message) (UserSession_unreadNotificationCount_Results, error) {
+	root, err := msg.Root()
+	return UserSession_unreadNotificationCount_Results(root.Struct()), err
+}
+
+func (s UserSession_unreadNotificationCount_Results) String() string {
+	str, _ := text.Marshal(0xbb0f592f14df4a7f, capnp.Struct(s))
+	return str
+}
+
+func (s UserSession_unreadNotificationCount_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UserSession_unreadNotificationCount_Results) DecodeFromPtr(p capnp.Ptr) UserSession_unreadNotificationCount_Results {
+	return UserSession_unreadNotificationCount_Results(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UserSession_unreadNotificationCount_Results) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UserSession_unreadNotificationCount_Results) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UserSession_unreadNotificationCount_Results) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UserSession_unreadNotificationCount_Results) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s UserSession_unreadNotificationCount_Results) Count() uint32 {
+	return capnp.Struct(s).Uint32(0)
+}
+
+func (s UserSession_unreadNotificationCount_Results) SetCount(v uint32) {
+	capnp.Struct(s).SetUint32(0, v)
+}
+
+func (s UserSession_unreadNotificationCount_Results) Error() (ApiError, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return ApiError(p.Struct()), err
+}
+
+func (s UserSession_unreadNotificationCount_Results) HasError() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s UserSession_unreadNotificationCount_Results) SetError(v ApiError) error {
+	return capnp.Struct(s).SetPtr(0, capnp.Struct(v).ToPtr())
+}
+
+// NewError sets the error field to a newly
+// allocated ApiError struct, preferring placement in s's segment.
+func (s UserSession_unreadNotificationCount_Results) NewError() (ApiError, error) {
+	ss, err := NewApiError(capnp.Struct(s).Segment())
+	if err != nil {
+		return ApiError{}, err
+	}
+	err = capnp.Struct(s).SetPtr(0, capnp.Struct(ss).ToPtr())
+	return ss, err
+}
+
+// UserSession_unreadNotificationCount_Results_List is a list of UserSession_unreadNotificationCount_Results.
+type UserSession_unreadNotificationCount_Results_List = capnp.StructList[UserSession_unreadNotificationCount_Results]
+
+// NewUserSession_unreadNotificationCount_Results creates a new list of UserSession_unreadNotificationCount_Results.
+func NewUserSession_unreadNotificationCount_Results_List(s *capnp.Segment, sz int32) (UserSession_unreadNotificationCount_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1}, sz)
+	return capnp.StructList[UserSession_unreadNotificationCount_Results](l), err
+}
+
+// UserSession_unreadNotificationCount_Results_Future is a wrapper for a UserSession_unreadNotificationCount_Results promised by a client call.
+type UserSession_unreadNotificationCount_Results_Future struct{ *capnp.Future }
+
+func (f UserSession_unreadNotificationCount_Results_Future) Struct() (UserSession_unreadNotificationCount_Results, error) {
+	p, err := f.Future.Ptr()
+	return UserSession_unreadNotificationCount_Results(p.Struct()), err
+}
+func (p UserSession_unreadNotificationCount_Results_Future) Error() ApiError_Future {
+	return ApiError_Future{Future: p.Future.Field(0, nil)}
+}
+
+type UserSession_storageUsage_Params capnp.Struct
+
+// UserSession_storageUsage_Params_TypeID is the unique identifier for the type UserSession_storageUsage_Params.
+const UserSession_storageUsage_Params_TypeID = 0xbb9ac592b82ecb7d
+
+func NewUserSession_storageUsage_Params(s *capnp.Segment) (UserSession_storageUsage_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
+	return UserSession_storageUsage_Params(st), err
+}
+
+func NewRootUserSession_storageUsage_Params(s *capnp.Segment) (UserSession_storageUsage_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
+	return UserSession_storageUsage_Params(st), err
+}
+
+func ReadRootUserSession_storageUsage_Params(msg *capnp.Message) (UserSession_storageUsage_Params, error) {
+	root, err := msg.Root()
+	return UserSession_storageUsage_Params(root.Struct()), err
+}
+
+func (s UserSession_storageUsage_Params) String() string {
+	str, _ := text.Marshal(0xbb9ac592b82ecb7d, capnp.Struct(s))
+	return str
+}
+
+func (s UserSession_storageUsage_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UserSession_storageUsage_Params) DecodeFromPtr(p capnp.Ptr) UserSession_storageUsage_Params {
+	return UserSession_storageUsage_Params(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UserSession_storageUsage_Params) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UserSession_storageUsage_Params) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UserSession_storageUsage_Params) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UserSession_storageUsage_Params) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+
+// UserSession_storageUsage_Params_List is a list of UserSession_storageUsage_Params.
+type UserSession_storageUsage_Params_List = capnp.StructList[UserSession_storageUsage_Params]
+
+// NewUserSession_storageUsage_Params creates a new list of UserSession_storageUsage_Params.
+func NewUserSession_storageUsage_Params_List(s *capnp.Segment, sz int32) (UserSession_storageUsage_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0}, sz)
+	return capnp.StructList[UserSession_storageUsage_Params](l), err
+}
+
+// UserSession_storageUsage_Params_Future is a wrapper for a UserSession_storageUsage_Params promised by a client call.
+type UserSession_storageUsage_Params_Future struct{ *capnp.Future }
+
+func (f UserSession_storageUsage_Params_Future) Struct() (UserSession_storageUsage_Params, error) {
+	p, err := f.Future.Ptr()
+	return UserSession_storageUsage_Params(p.Struct()), err
+}
+
+type UserSession_storageUsage_Results capnp.Struct
+
+// UserSession_storageUsage_Results_TypeID is the unique identifier for the type UserSession_storageUsage_Results.
+const UserSession_storageUsage_Results_TypeID = 0x95696a867ac7a014
+
+func NewUserSession_storageUsage_Results(s *capnp.Segment) (UserSession_storageUsage_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 2})
+	return UserSession_storageUsage_Results(st), err
+}
+
+func NewRootUserSession_storageUsage_Results(s *capnp.Segment) (UserSession_storageUsage_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 2})
+	return UserSession_storageUsage_Results(st), err
+}
+
+func ReadRootUserSession_storageUsage_Results(msg *capnp.Message) (UserSession_storageUsage_Results, error) {
+	root, err := msg.Root()
+	return UserSession_storageUsage_Results(root.Struct()), err
+}
+
+func (s UserSession_storageUsage_Results) String() string {
+	str, _ := text.Marshal(0x95696a867ac7a014, capnp.Struct(s))
+	return str
+}
+
+func (s UserSession_storageUsage_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UserSession_storageUsage_Results) DecodeFromPtr(p capnp.Ptr) UserSession_storageUsage_Results {
+	return UserSession_storageUsage_Results(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UserSession_storageUsage_Results) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UserSession_storageUsage_Results) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UserSession_storageUsage_Results) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UserSession_storageUsage_Results) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s UserSession_storageUsage_Results) Used() uint64 {
+	return capnp.Struct(s).Uint64(0)
+}
+
+func (s UserSession_storageUsage_Results) SetUsed(v uint64) {
+	capnp.Struct(s).SetUint64(0, v)
+}
+
+func (s UserSession_storageUsage_Results) Quota() uint64 {
+	return capnp.Struct(s).Uint64(8)
+}
+
+func (s UserSession_storageUsage_Results) SetQuota(v uint64) {
+	capnp.Struct(s).SetUint64(8, v)
+}
+
+func (s UserSession_storageUsage_Results) Grains() (GrainStorage_List, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return GrainStorage_List(p.List()), err
+}
+
+func (s UserSession_storageUsage_Results) HasGrains() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s UserSession_storageUsage_Results) SetGrains(v GrainStorage_List) error {
+	return capnp.Struct(s).SetPtr(0, v.ToPtr())
+}
+
+// NewGrains sets the grains field to a newly
+// allocated GrainStorage_List, preferring placement in s's segment.
+func (s UserSession_storageUsage_Results) NewGrains(n int32) (GrainStorage_List, error) {
+	l, err := NewGrainStorage_List(capnp.Struct(s).Segment(), n)
+	if err != nil {
+		return GrainStorage_List{}, err
+	}
+	err = capnp.Struct(s).SetPtr(0, l.ToPtr())
+	return l, err
+}
+func (s UserSession_storageUsage_Results) Error() (ApiError, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return ApiError(p.Struct()), err
+}
+
+func (s UserSession_storageUsage_Results) HasError() bool {
+	return capnp.Struct(s).HasPtr(1)
+}
+
+func (s UserSession_storageUsage_Results) SetError(v ApiError) error {
+	return capnp.Struct(s).SetPtr(1, capnp.Struct(v).ToPtr())
+}
+
+// NewError sets the error field to a newly
+// allocated ApiError struct, preferring placement in s's segment.
+func (s UserSession_storageUsage_Results) NewError() (ApiError, error) {
+	ss, err := NewApiError(capnp.Struct(s).Segment())
+	if err != nil {
+		return ApiError{}, err
+	}
+	err = capnp.Struct(s).SetPtr(1, capnp.Struct(ss).ToPtr())
+	return ss, err
+}
+
+// UserSession_storageUsage_Results_List is a list of UserSession_storageUsage_Results.
+type UserSession_storageUsage_Results_List = capnp.StructList[UserSession_storageUsage_Results]
+
+// NewUserSession_storageUsage_Results creates a new list of UserSession_storageUsage_Results.
+func NewUserSession_storageUsage_Results_List(s *capnp.Segment, sz int32) (UserSession_storageUsage_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 16, PointerCount: 2}, sz)
+	return capnp.StructList[UserSession_storageUsage_Results](l), err
+}
+
+// UserSession_storageUsage_Results_Future is a wrapper for a UserSession_storageUsage_Results promised by a client call.
+type UserSession_storageUsage_Results_Future struct{ *capnp.Future }
+
+func (f UserSession_storageUsage_Results_Future) Struct() (UserSession_storageUsage_Results, error) {
+	p, err := f.Future.Ptr()
+	return UserSession_storageUsage_Results(p.Struct()), err
+}
+func (p UserSession_storageUsage_Results_Future) Error() ApiError_Future {
+	return ApiError_Future{Future: p.Future.Field(1, nil)}
+}
+
+type UserSession_backupGrain_Params capnp.Struct
+
+// UserSession_backupGrain_Params_TypeID is the unique identifier for the type UserSession_backupGrain_Params.
+const UserSession_backupGrain_Params_TypeID = 0x98774497e4bebb38
+
+func NewUserSession_backupGrain_Params(s *capnp.Segment) (UserSession_backupGrain_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return UserSession_backupGrain_Params(st), err
+}
+
+func NewRootUserSession_backupGrain_Params(s *capnp.Segment) (UserSession_backupGrain_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return UserSession_backupGrain_Params(st), err
+}
+
+func ReadRootUserSession_backupGrain_Params(msg *capnp.Message) (UserSession_backupGrain_Params, error) {
+	root, err := msg.Root()
+	return UserSession_backupGrain_Params(root.Struct()), err
+}
+
+func (s UserSession_backupGrain_Params) String() string {
+	str, _ := text.Marshal(0x98774497e4bebb38, capnp.Struct(s))
+	return str
+}
+
+func (s UserSession_backupGrain_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UserSession_backupGrain_Params) DecodeFromPtr(p capnp.Ptr) UserSession_backupGrain_Params {
+	return UserSession_backupGrain_Params(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UserSession_backupGrain_Params) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UserSession_backupGrain_Params) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UserSession_backupGrain_Params) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UserSession_backupGrain_Params) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s UserSession_backupGrain_Params) GrainId() (string, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.Text(), err
+}
+
+func (s UserSession_backupGrain_Params) HasGrainId() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s UserSession_backupGrain_Params) GrainIdBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s UserSession_backupGrain_Params) SetGrainId(v string) error {
+	return capnp.Struct(s).SetText(0, v)
+}
+
+func (s UserSession_backupGrain_Params) Into() util.ByteStream {
+	p, _ := capnp.Struct(s).Ptr(1)
+	return util.ByteStream(p.Interface().Client())
+}
+
+func (s UserSession_backupGrain_Params) HasInto() bool {
+	return capnp.Struct(s).HasPtr(1)
+}
+
+func (s UserSession_backupGrain_Params) SetInto(v util.ByteStream) error {
+	if !v.IsValid() {
+		return capnp.Struct(s).SetPtr(1, capnp.Ptr{})
+	}
+	seg := s.Segment()
+	in := capnp.NewInterface(seg, seg.Message().CapTable().Add(capnp.Client(v)))
+	return capnp.Struct(s).SetPtr(1, in.ToPtr())
+}
+
+// UserSession_backupGrain_Params_List is a list of UserSession_backupGrain_Params.
+type UserSession_backupGrain_Params_List = capnp.StructList[UserSession_backupGrain_Params]
+
+// NewUserSession_backupGrain_Params creates a new list of UserSession_backupGrain_Params.
+func NewUserSession_backupGrain_Params_List(s *capnp.Segment, sz int32) (UserSession_backupGrain_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2}, sz)
+	return capnp.StructList[UserSession_backupGrain_Params](l), err
+}
+
+// UserSession_backupGrain_Params_Future is a wrapper for a UserSession_backupGrain_Params promised by a client call.
+type UserSession_backupGrain_Params_Future struct{ *capnp.Future }
+
+func (f UserSession_backupGrain_Params_Future) Struct() (UserSession_backupGrain_Params, error) {
+	p, err := f.Future.Ptr()
+	return UserSession_backupGrain_Params(p.Struct()), err
+}
+func (p UserSession_backupGrain_Params_Future) Into() util.ByteStream {
+	return util.ByteStream(p.Future.Field(1, nil).Client())
+}
+
+type UserSession_backupGrain_Results capnp.Struct
+
+// UserSession_backupGrain_Results_TypeID is the unique identifier for the type UserSession_backupGrain_Results.
+const UserSession_backupGrain_Results_TypeID = 0xfa22789bb720a24b
+
+func NewUserSession_backupGrain_Results(s *capnp.Segment) (UserSession_backupGrain_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UserSession_backupGrain_Results(st), err
+}
+
+func NewRootUserSession_backupGrain_Results(s *capnp.Segment) (UserSession_backupGrain_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UserSession_backupGrain_Results(st), err
+}
+
+func ReadRootUserSession_backupGrain_Results(msg *capnp.Message) (UserSession_backupGrain_Results, error) {
+	root, err := msg.Root()
+	return UserSession_backupGrain_Results(root.Struct()), err
+}
+
+func (s UserSession_backupGrain_Results) String() string {
+	str, _ := text.Marshal(0xfa22789bb720a24b, capnp.Struct(s))
+	return str
+}
+
+func (s UserSession_backupGrain_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UserSession_backupGrain_Results) DecodeFromPtr(p capnp.Ptr) UserSession_backupGrain_Results {
+	return UserSession_backupGrain_Results(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UserSession_backupGrain_Results) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UserSession_backupGrain_Results) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UserSession_backupGrain_Results) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UserSession_backupGrain_Results) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s UserSession_backupGrain_Results) Error() (ApiError, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return ApiError(p.Struct()), err
+}
+
+func (s UserSession_backupGrain_Results) HasError() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s UserSession_backupGrain_Results) SetError(v ApiError) error {
+	return capnp.Struct(s).SetPtr(0, capnp.Struct(v).ToPtr())
+}
+
+// NewError sets the error field to a newly
+// allocated ApiError struct, preferring placement in s's segment.
+func (s UserSession_backupGrain_Results) NewError() (ApiError, error) {
+	ss, err := NewApiError(capnp.Struct(s).Segment())
+	if err != nil {
+		return ApiError{}, err
+	}
+	err = capnp.Struct(s).SetPtr(0, capnp.Struct(ss).ToPtr())
+	return ss, err
+}
+
+// UserSession_backupGrain_Results_List is a list of UserSession_backupGrain_Results.
+type UserSession_backupGrain_Results_List = capnp.StructList[UserSession_backupGrain_Results]
+
+// NewUserSession_backupGrain_Results creates a new list of UserSession_backupGrain_Results.
+func NewUserSession_backupGrain_Results_List(s *capnp.Segment, sz int32) (UserSession_backupGrain_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return capnp.StructList[UserSession_backupGrain_Results](l), err
+}
+
+// UserSession_backupGrain_Results_Future is a wrapper for a UserSession_backupGrain_Results promised by a client call.
+type UserSession_backupGrain_Results_Future struct{ *capnp.Future }
+
+func (f UserSession_backupGrain_Results_Future) Struct() (UserSession_backupGrain_Results, error) {
+	p, err := f.Future.Ptr()
+	return UserSession_backupGrain_Results(p.Struct()), err
+}
+func (p UserSession_backupGrain_Results_Future) Error() ApiError_Future {
+	return ApiError_Future{Future: p.Future.Field(0, nil)}
+}
+
+type UserSession_grainLog_Params capnp.Struct
+
+// UserSession_grainLog_Params_TypeID is the unique identifier for the type UserSession_grainLog_Params.
+const UserSession_grainLog_Params_TypeID = 0xa0512876e6a3a9ca
+
+func NewUserSession_grainLog_Params(s *capnp.Segment) (UserSession_grainLog_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 2})
+	return UserSession_grainLog_Params(st), err
+}
+
+func NewRootUserSession_grainLog_Params(s *capnp.Segment) (UserSession_grainLog_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 2})
+	return UserSession_grainLog_Params(st), err
+}
+
+func ReadRootUserSession_grainLog_Params(msg *capnp.Message) (UserSession_grainLog_Params, error) {
+	root, err := msg.Root()
+	return UserSession_grainLog_Params(root.Struct()), err
+}
+
+func (s UserSession_grainLog_Params) String() string {
+	str, _ := text.Marshal(0xa0512876e6a3a9ca, capnp.Struct(s))
+	return str
+}
+
+func (s UserSession_grainLog_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UserSession_grainLog_Params) DecodeFromPtr(p capnp.Ptr) UserSession_grainLog_Params {
+	return UserSession_grainLog_Params(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UserSession_grainLog_Params) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UserSession_grainLog_Params) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UserSession_grainLog_Params) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UserSession_grainLog_Params) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s UserSession_grainLog_Params) GrainId() (string, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.Text(), err
+}
+
+func (s UserSession_grainLog_Params) HasGrainId() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s UserSession_grainLog_Params) GrainIdBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s UserSession_grainLog_Params) SetGrainId(v string) error {
+	return capnp.Struct(s).SetText(0, v)
+}
+
+func (s UserSession_grainLog_Params) Into() util.ByteStream {
+	p, _ := capnp.Struct(s).Ptr(1)
+	return util.ByteStream(p.Interface().Client())
+}
+
+func (s UserSession_grainLog_Params) HasInto() bool {
+	return capnp.Struct(s).HasPtr(1)
+}
+
+func (s UserSession_grainLog_Params) SetInto(v util.ByteStream) error {
+	if !v.IsValid() {
+		return capnp.Struct(s).SetPtr(1, capnp.Ptr{})
+	}
+	seg := s.Segment()
+	in := capnp.NewInterface(seg, seg.Message().CapTable().Add(capnp.Client(v)))
+	return capnp.Struct(s).SetPtr(1, in.ToPtr())
+}
+
+func (s UserSession_grainLog_Params) Follow() bool {
+	return capnp.Struct(s).Bit(0)
+}
+
+func (s UserSession_grainLog_Params) SetFollow(v bool) {
+	capnp.Struct(s).SetBit(0, v)
+}
+
+// UserSession_grainLog_Params_List is a list of UserSession_grainLog_Params.
+type UserSession_grainLog_Params_List = capnp.StructList[UserSession_grainLog_Params]
+
+// NewUserSession_grainLog_Params creates a new list of UserSession_grainLog_Params.
+func NewUserSession_grainLog_Params_List(s *capnp.Segment, sz int32) (UserSession_grainLog_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 2}, sz)
+	return capnp.StructList[UserSession_grainLog_Params](l), err
+}
+
+// UserSession_grainLog_Params_Future is a wrapper for a UserSession_grainLog_Params promised by a client call.
+type UserSession_grainLog_Params_Future struct{ *capnp.Future }
+
+func (f UserSession_grainLog_Params_Future) Struct() (UserSession_grainLog_Params, error) {
+	p, err := f.Future.Ptr()
+	return UserSession_grainLog_Params(p.Struct()), err
+}
+func (p UserSession_grainLog_Params_Future) Into() util.ByteStream {
+	return util.ByteStream(p.Future.Field(1, nil).Client())
+}
+
+type UserSession_grainLog_Results capnp.Struct
+
+// UserSession_grainLog_Results_TypeID is the unique identifier for the type UserSession_grainLog_Results.
+const UserSession_grainLog_Results_TypeID = 0xea5be3ab2a30eb36
+
+func NewUserSession_grainLog_Results(s *capnp.Segment) (UserSession_grainLog_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return UserSession_grainLog_Results(st), err
+}
+
+func NewRootUserSession_grainLog_Results(s *capnp.Segment) (UserSession_grainLog_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return UserSession_grainLog_Results(st), err
+}
+
+func ReadRootUserSession_grainLog_Results(msg *capnp.Message) (UserSession_grainLog_Results, error) {
+	root, err := msg.Root()
+	return UserSession_grainLog_Results(root.Struct()), err
+}
+
+func (s UserSession_grainLog_Results) String() string {
+	str, _ := text.Marshal(0xea5be3ab2a30eb36, capnp.Struct(s))
+	return str
+}
+
+func (s UserSession_grainLog_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UserSession_grainLog_Results) DecodeFromPtr(p capnp.Ptr) UserSession_grainLog_Results {
+	return UserSession_grainLog_Results(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UserSession_grainLog_Results) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UserSession_grainLog_Results) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UserSession_grainLog_Results) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UserSession_grainLog_Results) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s UserSession_grainLog_Results) Subscription() util.Handle {
+	p, _ := capnp.Struct(s).Ptr(0)
+	return util.Handle(p.Interface().Client())
+}
+
+func (s UserSession_grainLog_Results) HasSubscription() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s UserSession_grainLog_Results) SetSubscription(v util.Handle) error {
+	if !v.IsValid() {
+		return capnp.Struct(s).SetPtr(0, capnp.Ptr{})
+	}
+	seg := s.Segment()
+	in := capnp.NewInterface(seg, seg.Message().CapTable().Add(capnp.Client(v)))
+	return capnp.Struct(s).SetPtr(0, in.ToPtr())
+}
+
+func (s UserSession_grainLog_Results) Error() (ApiError, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return ApiError(p.Struct()), err
+}
+
+func (s UserSession_grainLog_Results) HasError() bool {
+	return capnp.Struct(s).HasPtr(1)
+}
+
+func (s UserSession_grainLog_Results) SetError(v ApiError) error {
+	return capnp.Struct(s).SetPtr(1, capnp.Struct(v).ToPtr())
+}
+
+// NewError sets the error field to a newly
+// allocated ApiError struct, preferring placement in s's segment.
+func (s UserSession_grainLog_Results) NewError() (ApiError, error) {
+	ss, err := NewApiError(capnp.Struct(s).Segment())
+	if err != nil {
+		return ApiError{}, err
+	}
+	err = capnp.Struct(s).SetPtr(1, capnp.Struct(ss).ToPtr())
+	return ss, err
+}
+
+// UserSession_grainLog_Results_List is a list of UserSession_grainLog_Results.
+type UserSession_grainLog_Results_List = capnp.StructList[UserSession_grainLog_Results]
+
+// NewUserSession_grainLog_Results creates a new list of UserSession_grainLog_Results.
+func NewUserSession_grainLog_Results_List(s *capnp.Segment, sz int32) (UserSession_grainLog_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2}, sz)
+	return capnp.StructList[UserSession_grainLog_Results](l), err
+}
+
+// UserSession_grainLog_Results_Future is a wrapper for a UserSession_grainLog_Results promised by a client call.
+type UserSession_grainLog_Results_Future struct{ *capnp.Future }
+
+func (f UserSession_grainLog_Results_Future) Struct() (UserSession_grainLog_Results, error) {
+	p, err := f.Future.Ptr()
+	return UserSession_grainLog_Results(p.Struct()), err
+}
+func (p UserSession_grainLog_Results_Future) Subscription() util.Handle {
+	return util.Handle(p.Future.Field(0, nil).Client())
+}
+
+func (p UserSession_grainLog_Results_Future) Error() ApiError_Future {
+	return ApiError_Future{Future: p.Future.Field(1, nil)}
+}
+
+type UserSession_loginSessions_Params capnp.Struct
+
+// UserSession_loginSessions_Params_TypeID is the unique identifier for the type UserSession_loginSessions_Params.
+const UserSession_loginSessions_Params_TypeID = 0xf6526d2e88594427
+
+func NewUserSession_loginSessions_Params(s *capnp.Segment) (UserSession_loginSessions_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
+	return UserSession_loginSessions_Params(st), err
+}
+
+func NewRootUserSession_loginSessions_Params(s *capnp.Segment) (UserSession_loginSessions_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
+	return UserSession_loginSessions_Params(st), err
+}
+
+func ReadRootUserSession_loginSessions_Params(msg *capnp.Message) (UserSession_loginSessions_Params, error) {
+	root, err := msg.Root()
+	return UserSession_loginSessions_Params(root.Struct()), err
+}
+
+func (s UserSession_loginSessions_Params) String() string {
+	str, _ := text.Marshal(0xf6526d2e88594427, capnp.Struct(s))
+	return str
+}
+
+func (s UserSession_loginSessions_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UserSession_loginSessions_Params) DecodeFromPtr(p capnp.Ptr) UserSession_loginSessions_Params {
+	return UserSession_loginSessions_Params(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UserSession_loginSessions_Params) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UserSession_loginSessions_Params) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UserSession_loginSessions_Params) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UserSession_loginSessions_Params) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+
+// UserSession_loginSessions_Params_List is a list of UserSession_loginSessions_Params.
+type UserSession_loginSessions_Params_List = capnp.StructList[UserSession_loginSessions_Params]
+
+// NewUserSession_loginSessions_Params creates a new list of UserSession_loginSessions_Params.
+func NewUserSession_loginSessions_Params_List(s *capnp.Segment, sz int32) (UserSession_loginSessions_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0}, sz)
+	return capnp.StructList[UserSession_loginSessions_Params](l), err
+}
+
+// UserSession_loginSessions_Params_Future is a wrapper for a UserSession_loginSessions_Params promised by a client call.
+type UserSession_loginSessions_Params_Future struct{ *capnp.Future }
+
+func (f UserSession_loginSessions_Params_Future) Struct() (UserSession_loginSessions_Params, error) {
+	p, err := f.Future.Ptr()
+	return UserSession_loginSessions_Params(p.Struct()), err
+}
+
+type UserSession_loginSessions_Results capnp.Struct
+
+// UserSession_loginSessions_Results_TypeID is the unique identifier for the type UserSession_loginSessions_Results.
+const UserSession_loginSessions_Results_TypeID = 0x8e2e8721731ec4d5
+
+func NewUserSession_loginSessions_Results(s *capnp.Segment) (UserSession_loginSessions_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return UserSession_loginSessions_Results(st), err
+}
+
+func NewRootUserSession_loginSessions_Results(s *capnp.Segment) (UserSession_loginSessions_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return UserSession_loginSessions_Results(st), err
+}
+
+func ReadRootUserSession_loginSessions_Results(msg *capnp.Message) (UserSession_loginSessions_Results, error) {
+	root, err := msg.Root()
+	return UserSession_loginSessions_Results(root.Struct()), err
+}
+
+func (s UserSession_loginSessions_Results) String() string {
+	str, _ := text.Marshal(0x8e2e8721731ec4d5, capnp.Struct(s))
+	return str
+}
+
+func (s UserSession_loginSessions_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UserSession_loginSessions_Results) DecodeFromPtr(p capnp.Ptr) UserSession_loginSessions_Results {
+	return UserSession_loginSessions_Results(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UserSession_loginSessions_Results) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UserSession_loginSessions_Results) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UserSession_loginSessions_Results) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UserSession_loginSessions_Results) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s UserSession_loginSessions_Results) Sessions() (LoginSession_List, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return LoginSession_List(p.List()), err
+}
+
+func (s UserSession_loginSessions_Results) HasSessions() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s UserSession_loginSessions_Results) SetSessions(v LoginSession_List) error {
+	return capnp.Struct(s).SetPtr(0, v.ToPtr())
+}
+
+// NewSessions sets the sessions field to a newly
+// allocated LoginSession_List, preferring placement in s's segment.
+func (s UserSession_loginSessions_Results) NewSessions(n int32) (LoginSession_List, error) {
+	l, err := NewLoginSession_List(capnp.Struct(s).Segment(), n)
+	if err != nil {
+		return LoginSession_List{}, err
+	}
+	err = capnp.Struct(s).SetPtr(0, l.ToPtr())
+	return l, err
+}
+func (s UserSession_loginSessions_Results) Error() (ApiError, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return ApiError(p.Struct()), err
+}
+
+func (s UserSession_loginSessions_Results) HasError() bool {
+	return capnp.Struct(s).HasPtr(1)
+}
+
+func (s UserSession_loginSessions_Results) SetError(v ApiError) error {
+	return capnp.Struct(s).SetPtr(1, capnp.Struct(v).ToPtr())
+}
+
+// NewError sets the error field to a newly
+// allocated ApiError struct, preferring placement in s's segment.
+func (s UserSession_loginSessions_Results) NewError() (ApiError, error) {
+	ss, err := NewApiError(capnp.Struct(s).Segment())
+	if err != nil {
+		return ApiError{}, err
+	}
+	err = capnp.Struct(s).SetPtr(1, capnp.Struct(ss).ToPtr())
+	return ss, err
+}
+
+// UserSession_loginSessions_Results_List is a list of UserSession_loginSessions_Results.
+type UserSession_loginSessions_Results_List = capnp.StructList[UserSession_loginSessions_Results]
+
+// NewUserSession_loginSessions_Results creates a new list of UserSession_loginSessions_Results.
+func NewUserSession_loginSessions_Results_List(s *capnp.Segment, sz int32) (UserSession_loginSessions_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2}, sz)
+	return capnp.StructList[UserSession_loginSessions_Results](l), err
+}
+
+// UserSession_loginSessions_Results_Future is a wrapper for a UserSession_loginSessions_Results promised by a client call.
+type UserSession_loginSessions_Results_Future struct{ *capnp.Future }
+
+func (f UserSession_loginSessions_Results_Future) Struct() (UserSession_loginSessions_Results, error) {
+	p, err := f.Future.Ptr()
+	return UserSession_loginSessions_Results(p.Struct()), err
+}
+func (p UserSession_loginSessions_Results_Future) Error() ApiError_Future {
+	return ApiError_Future{Future: p.Future.Field(1, nil)}
+}
+
+type UserSession_revokeLoginSession_Params capnp.Struct
+
+// UserSession_revokeLoginSession_Params_TypeID is the unique identifier for the type UserSession_revokeLoginSession_Params.
+const UserSession_revokeLoginSession_Params_TypeID = 0xbfe69a92117e181a
+
+func NewUserSession_revokeLoginSession_Params(s *capnp.Segment) (UserSession_revokeLoginSession_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UserSession_revokeLoginSession_Params(st), err
+}
+
+func NewRootUserSession_revokeLoginSession_Params(s *capnp.Segment) (UserSession_revokeLoginSession_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UserSession_revokeLoginSession_Params(st), err
+}
+
+func ReadRootUserSession_revokeLoginSession_Params(msg *capnp.Message) (UserSession_revokeLoginSession_Params, error) {
+	root, err := msg.Root()
+	return UserSession_revokeLoginSession_Params(root.Struct()), err
+}
+
+func (s UserSession_revokeLoginSession_Params) String() string {
+	str, _ := text.Marshal(0xbfe69a92117e181a, capnp.Struct(s))
+	return str
+}
+
+func (s UserSession_revokeLoginSession_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UserSession_revokeLoginSession_Params) DecodeFromPtr(p capnp.Ptr) UserSession_revokeLoginSession_Params {
+	return UserSession_revokeLoginSession_Params(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UserSession_revokeLoginSession_Params) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UserSession_revokeLoginSession_Params) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UserSession_revokeLoginSession_Params) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UserSession_revokeLoginSession_Params) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s UserSession_revokeLoginSession_Params) Id() (string, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.Text(), err
+}
+
+func (s UserSession_revokeLoginSession_Params) HasId() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s UserSession_revokeLoginSession_Params) IdBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s UserSession_revokeLoginSession_Params) SetId(v string) error {
+	return capnp.Struct(s).SetText(0, v)
+}
+
+// UserSession_revokeLoginSession_Params_List is a list of UserSession_revokeLoginSession_Params.
+type UserSession_revokeLoginSession_Params_List = capnp.StructList[UserSession_revokeLoginSession_Params]
+
+// NewUserSession_revokeLoginSession_Params creates a new list of UserSession_revokeLoginSession_Params.
+func NewUserSession_revokeLoginSession_Params_List(s *capnp.Segment, sz int32) (UserSession_revokeLoginSession_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return capnp.StructList[UserSession_revokeLoginSession_Params](l), err
+}
+
+// UserSession_revokeLoginSession_Params_Future is a wrapper for a UserSession_revokeLoginSession_Params promised by a client call.
+type UserSession_revokeLoginSession_Params_Future struct{ *capnp.Future }
+
+func (f UserSession_revokeLoginSession_Params_Future) Struct() (UserSession_revokeLoginSession_Params, error) {
+	p, err := f.Future.Ptr()
+	return UserSession_revokeLoginSession_Params(p.Struct()), err
+}
+
+type UserSession_revokeLoginSession_Results capnp.Struct
+
+// UserSession_revokeLoginSession_Results_TypeID is the unique identifier for the type UserSession_revokeLoginSession_Results.
+const UserSession_revokeLoginSession_Results_TypeID = 0xb6d9268918b91cbe
+
+func NewUserSession_revokeLoginSession_Results(s *capnp.Segment) (UserSession_revokeLoginSession_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UserSession_revokeLoginSession_Results(st), err
+}
+
+func NewRootUserSession_revokeLoginSession_Results(s *capnp.Segment) (UserSession_revokeLoginSession_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UserSession_revokeLoginSession_Results(st), err
+}
+
+func ReadRootUserSession_revokeLoginSession_Results(msg *capnp.Message) (UserSession_revokeLoginSession_Results, error) {
+	root, err := msg.Root()
+	return UserSession_revokeLoginSession_Results(root.Struct()), err
+}
+
+func (s UserSession_revokeLoginSession_Results) String() string {
+	str, _ := text.Marshal(0xb6d9268918b91cbe, capnp.Struct(s))
+	return str
+}
+
+func (s UserSession_revokeLoginSession_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UserSession_revokeLoginSession_Results) DecodeFromPtr(p capnp.Ptr) UserSession_revokeLoginSession_Results {
+	return UserSession_revokeLoginSession_Results(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UserSession_revokeLoginSession_Results) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UserSession_revokeLoginSession_Results) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UserSession_revokeLoginSession_Results) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UserSession_revokeLoginSession_Results) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s UserSession_revokeLoginSession_Results) Error() (ApiError, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return ApiError(p.Struct()), err
+}
+
+func (s UserSession_revokeLoginSession_Results) HasError() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s UserSession_revokeLoginSession_Results) SetError(v ApiError) error {
+	return capnp.Struct(s).SetPtr(0, capnp.Struct(v).ToPtr())
+}
+
+// NewError sets the error field to a newly
+// allocated ApiError struct, preferring placement in s's segment.
+func (s UserSession_revokeLoginSession_Results) NewError() (ApiError, error) {
+	ss, err := NewApiError(capnp.Struct(s).Segment())
+	if err != nil {
+		return ApiError{}, err
+	}
+	err = capnp.Struct(s).SetPtr(0, capnp.Struct(ss).ToPtr())
+	return ss, err
+}
+
+// UserSession_revokeLoginSession_Results_List is a list of UserSession_revokeLoginSession_Results.
+type UserSession_revokeLoginSession_Results_List = capnp.StructList[UserSession_revokeLoginSession_Results]
+
+// NewUserSession_revokeLoginSession_Results creates a new list of UserSession_revokeLoginSession_Results.
+func NewUserSession_revokeLoginSession_Results_List(s *capnp.Segment, sz int32) (UserSession_revokeLoginSession_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return capnp.StructList[UserSession_revokeLoginSession_Results](l), err
+}
+
+// UserSession_revokeLoginSession_Results_Future is a wrapper for a UserSession_revokeLoginSession_Results promised by a client call.
+type UserSession_revokeLoginSession_Results_Future struct{ *capnp.Future }
+
+func (f UserSession_revokeLoginSession_Results_Future) Struct() (UserSession_revokeLoginSession_Results, error) {
+	p, err := f.Future.Ptr()
+	return UserSession_revokeLoginSession_Results(p.Struct()), err
+}
+func (p UserSession_revokeLoginSession_Results_Future) Error() ApiError_Future {
+	return ApiError_Future{Future: p.Future.Field(0, nil)}
+}
+
+type UserSession_revokeAllLoginSessions_Params capnp.Struct
+
+// UserSession_revokeAllLoginSessions_Params_TypeID is the unique identifier for the type UserSession_revokeAllLoginSessions_Params.
+const UserSession_revokeAllLoginSessions_Params_TypeID = 0xe28488d79a9f50c4
+
+func NewUserSession_revokeAllLoginSessions_Params(s *capnp.Segment) (UserSession_revokeAllLoginSessions_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
+	return UserSession_revokeAllLoginSessions_Params(st), err
+}
+
+func NewRootUserSession_revokeAllLoginSessions_Params(s *capnp.Segment) (UserSession_revokeAllLoginSessions_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
+	return UserSession_revokeAllLoginSessions_Params(st), err
+}
+
+func ReadRootUserSession_revokeAllLoginSessions_Params(msg *capnp.Message) (UserSession_revokeAllLoginSessions_Params, error) {
+	root, err := msg.Root()
+	return UserSession_revokeAllLoginSessions_Params(root.Struct()), err
+}
+
+func (s UserSession_revokeAllLoginSessions_Params) String() string {
+	str, _ := text.Marshal(0xe28488d79a9f50c4, capnp.Struct(s))
+	return str
+}
+
+func (s UserSession_revokeAllLoginSessions_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UserSession_revokeAllLoginSessions_Params) DecodeFromPtr(p capnp.Ptr) UserSession_revokeAllLoginSessions_Params {
+	return UserSession_revokeAllLoginSessions_Params(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UserSession_revokeAllLoginSessions_Params) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UserSession_revokeAllLoginSessions_Params) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UserSession_revokeAllLoginSessions_Params) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UserSession_revokeAllLoginSessions_Params) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+
+// UserSession_revokeAllLoginSessions_Params_List is a list of UserSession_revokeAllLoginSessions_Params.
+type UserSession_revokeAllLoginSessions_Params_List = capnp.StructList[UserSession_revokeAllLoginSessions_Params]
+
+// NewUserSession_revokeAllLoginSessions_Params creates a new list of UserSession_revokeAllLoginSessions_Params.
+func NewUserSession_revokeAllLoginSessions_Params_List(s *capnp.Segment, sz int32) (UserSession_revokeAllLoginSessions_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0}, sz)
+	return capnp.StructList[UserSession_revokeAllLoginSessions_Params](l), err
+}
+
+// UserSession_revokeAllLoginSessions_Params_Future is a wrapper for a UserSession_revokeAllLoginSessions_Params promised by a client call.
+type UserSession_revokeAllLoginSessions_Params_Future struct{ *capnp.Future }
+
+func (f UserSession_revokeAllLoginSessions_Params_Future) Struct() (UserSession_revokeAllLoginSessions_Params, error) {
+	p, err := f.Future.Ptr()
+	return UserSession_revokeAllLoginSessions_Params(p.Struct()), err
+}
+
+type UserSession_revokeAllLoginSessions_Results capnp.Struct
+
+// UserSession_revokeAllLoginSessions_Results_TypeID is the unique identifier for the type UserSession_revokeAllLoginSessions_Results.
+const UserSession_revokeAllLoginSessions_Results_TypeID = 0x890c5c08a8a480be
+
+func NewUserSession_revokeAllLoginSessions_Results(s *capnp.Segment) (UserSession_revokeAllLoginSessions_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1})
+	return UserSession_revokeAllLoginSessions_Results(st), err
+}
+
+func NewRootUserSession_revokeAllLoginSessions_Results(s *capnp.Segment) (UserSession_revokeAllLoginSessions_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1})
+	return UserSession_revokeAllLoginSessions_Results(st), err
+}
+
+func ReadRootUserSession_revokeAllLoginSessions_Results(msg *capnp.Message) (UserSession_revokeAllLoginSessions_Results, error) {
+	root, err := msg.Root()
+	return UserSession_revokeAllLoginSessions_Results(root.Struct()), err
+}
+
+func (s UserSession_revokeAllLoginSessions_Results) String() string {
+	str, _ := text.Marshal(0x890c5c08a8a480be, capnp.Struct(s))
+	return str
+}
+
+func (s UserSession_revokeAllLoginSessions_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UserSession_revokeAllLoginSessions_Results) DecodeFromPtr(p capnp.Ptr) UserSession_revokeAllLoginSessions_Results {
+	return UserSession_revokeAllLoginSessions_Results(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UserSession_revokeAllLoginSessions_Results) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UserSession_revokeAllLoginSessions_Results) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UserSession_revokeAllLoginSessions_Results) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UserSession_revokeAllLoginSessions_Results) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s UserSession_revokeAllLoginSessions_Results) Count() uint32 {
+	return capnp.Struct(s).Uint32(0)
+}
+
+func (s UserSession_revokeAllLoginSessions_Results) SetCount(v uint32) {
+	capnp.Struct(s).SetUint32(0, v)
+}
+
+func (s UserSession_revokeAllLoginSessions_Results) Error() (ApiError, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return ApiError(p.Struct()), err
+}
+
+func (s UserSession_revokeAllLoginSessions_Results) HasError() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s UserSession_revokeAllLoginSessions_Results) SetError(v ApiError) error {
+	return capnp.Struct(s).SetPtr(0, capnp.Struct(v).ToPtr())
+}
+
+// NewError sets the error field to a newly
+// allocated ApiError struct, preferring placement in s's segment.
+func (s UserSession_revokeAllLoginSessions_Results) NewError() (ApiError, error) {
+	ss, err := NewApiError(capnp.Struct(s).Segment())
+	if err != nil {
+		return ApiError{}, err
+	}
+	err = capnp.Struct(s).SetPtr(0, capnp.Struct(ss).ToPtr())
+	return ss, err
+}
+
+// UserSession_revokeAllLoginSessions_Results_List is a list of UserSession_revokeAllLoginSessions_Results.
+type UserSession_revokeAllLoginSessions_Results_List = capnp.StructList[UserSession_revokeAllLoginSessions_Results]
+
+// NewUserSession_revokeAllLoginSessions_Results creates a new list of UserSession_revokeAllLoginSessions_Results.
+func NewUserSession_revokeAllLoginSessions_Results_List(s *capnp.Segment, sz int32) (UserSession_revokeAllLoginSessions_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1}, sz)
+	return capnp.StructList[UserSession_revokeAllLoginSessions_Results](l), err
+}
+
+// UserSession_revokeAllLoginSessions_Results_Future is a wrapper for a UserSession_revokeAllLoginSessions_Results promised by a client call.
+type UserSession_revokeAllLoginSessions_Results_Future struct{ *capnp.Future }
+
+func (f UserSession_revokeAllLoginSessions_Results_Future) Struct() (UserSession_revokeAllLoginSessions_Results, error) {
+	p, err := f.Future.Ptr()
+	return UserSession_revokeAllLoginSessions_Results(p.Struct()), err
+}
+func (p UserSession_revokeAllLoginSessions_Results_Future) Error() ApiError_Future {
+	return ApiError_Future{Future: p.Future.Field(0, nil)}
+}
+
+type UiView capnp.Struct
+
+// UiView_TypeID is the unique identifier for the type UiView.
+const UiView_TypeID = 0x9efbad5f3a5b9820
+
+func NewUiView(s *capnp.Segment) (UiView, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 5})
+	return UiView(st), err
+}
+
+func NewRootUiView(s *capnp.Segment) (UiView, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 5})
+	return UiView(st), err
+}
+
+func ReadRootUiView(msg *capnp.Message) (UiView, error) {
+	root, err := msg.Root()
+	return UiView(root.Struct()), err
+}
+
+func (s UiView) String() string {
+	str, _ := text.Marshal(0x9efbad5f3a5b9820, capnp.Struct(s))
+	return str
+}
+
+func (s UiView) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UiView) DecodeFromPtr(p capnp.Ptr) UiView {
+	return UiView(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UiView) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UiView) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UiView) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UiView) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s UiView) Title() (string, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return p.Text(), err
+}
+
+func (s UiView) HasTitle() bool {
+	return capnp.Struct(s).HasPtr(1)
+}
+
+func (s UiView) TitleBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return p.TextBytes(), err
+}
+
+func (s UiView) SetTitle(v string) error {
+	return capnp.Struct(s).SetText(1, v)
+}
+
+func (s UiView) SessionToken() (string, error) {
+	p, err := capnp.Struct(s).Ptr(2)
+	return p.Text(), err
+}
+
+func (s UiView) HasSessionToken() bool {
+	return capnp.Struct(s).HasPtr(2)
+}
+
+func (s UiView) SessionTokenBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(2)
+	return p.TextBytes(), err
+}
+
+func (s UiView) SetSessionToken(v string) error {
+	return capnp.Struct(s).SetText(2, v)
+}
+
+func (s UiView) Subdomain() (string, error) {
+	p, err := capnp.Struct(s).Ptr(3)
+	return p.Text(), err
+}
+
+func (s UiView) HasSubdomain() bool {
+	return capnp.Struct(s).HasPtr(3)
+}
+
+func (s UiView) SubdomainBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(3)
+	return p.TextBytes(), err
+}
+
+func (s UiView) SetSubdomain(v string) error {
+	return capnp.Struct(s).SetText(3, v)
+}
+
+func (s UiView) ViewInfo() (grain.UiView_ViewInfo, error) {
+	p, err := capnp.Struct(s).Ptr(4)
+	return grain.UiView_ViewInfo(p.Struct()), err
+}
+
+func (s UiView) HasViewInfo() bool {
+	return capnp.Struct(s).HasPtr(4)
+}
+
+func (s UiView) SetViewInfo(v grain.UiView_ViewInfo) error {
+	return capnp.Struct(s).SetPtr(4, capnp.Struct(v).ToPtr())
+}
+
+// NewViewInfo sets the viewInfo field to a newly
+// allocated grain.UiView_ViewInfo struct, preferring placement in s's segment.
+func (s UiView) NewViewInfo() (grain.UiView_ViewInfo, error) {
+	ss, err := grain.NewUiView_ViewInfo(capnp.Struct(s).Segment())
+	if err != nil {
+		return grain.UiView_ViewInfo{}, err
+	}
+	err = capnp.Struct(s).SetPtr(4, capnp.Struct(ss).ToPtr())
+	return ss, err
+}
+
+func (s UiView) Controller() UiView_Controller {
+	p, _ := capnp.Struct(s).Ptr(0)
+	return UiView_Controller(p.Interface().Client())
+}
+
+func (s UiView) HasController() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s UiView) SetController(v UiView_Controller) error {
+	if !v.IsValid() {
+		return capnp.Struct(s).SetPtr(0, capnp.Ptr{})
+	}
+	seg := s.Segment()
+	in := capnp.NewInterface(seg, seg.Message().CapTable().Add(capnp.Client(v)))
+	return capnp.Struct(s).SetPtr(0, in.ToPtr())
+}
+
+func (s UiView) Trashed() bool {
+	return capnp.Struct(s).Bit(0)
+}
+
+func (s UiView) SetTrashed(v bool) {
+	capnp.Struct(s).SetBit(0, v)
+}
+
+func (s UiView) StorageBytes() uint64 {
+	return capnp.Struct(s).Uint64(8)
+}
+
+func (s UiView) SetStorageBytes(v uint64) {
+	capnp.Struct(s).SetUint64(8, v)
+}
+
+// UiView_List is a list of UiView.
+type UiView_List = capnp.StructList[UiView]
+
+// NewUiView creates a new list of UiView.
+func NewUiView_List(s *capnp.Segment, sz int32) (UiView_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 16, PointerCount: 5}, sz)
+	return capnp.StructList[UiView](l), err
+}
+
+// UiView_Future is a wrapper for a UiView promised by a client call.
+type UiView_Future struct{ *capnp.Future }
+
+func (f UiView_Future) Struct() (UiView, error) {
+	p, err := f.Future.Ptr()
+	return UiView(p.Struct()), err
+}
+func (p UiView_Future) ViewInfo() grain.UiView_ViewInfo_Future {
+	return grain.UiView_ViewInfo_Future{Future: p.Future.Field(4, nil)}
+}
+func (p UiView_Future) Controller() UiView_Controller {
+	return UiView_Controller(p.Future.Field(0, nil).Client())
+}
+
+type UiView_Controller capnp.Client
+
+// UiView_Controller_TypeID is the unique identifier for the type UiView_Controller.
+const UiView_Controller_TypeID = 0xcc45c4dfa8fbffba
+
+func (c UiView_Controller) MakeSharingToken(ctx context.Context, params func(UiView_Controller_makeSharingToken_Params) error) (UiView_Controller_makeSharingToken_Results_Future, capnp.ReleaseFunc) {
+
+	s := capnp.Send{
+		Method: capnp.Method{
+			InterfaceID:   0xcc45c4dfa8fbffba,
+			MethodID:      0,
+			InterfaceName: "external.capnp:UiView.Controller",
+			MethodName:    "makeSharingToken",
+		},
+	}
+	if params != nil {
+		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 2}
+		s.PlaceArgs = func(s capnp.Struct) error { return params(UiView_Controller_makeSharingToken_Params(s)) }
+	}
+
+	ans, release := capnp.Client(c).SendCall(ctx, s)
+	return UiView_Controller_makeSharingToken_Results_Future{Future: ans.Future()}, release
+
+}
+
+func (c UiView_Controller) SetTitle(ctx context.Context, params func(UiView_Controller_setTitle_Params) error) (UiView_Controller_setTitle_Results_Future, capnp.ReleaseFunc) {
+
+	s := capnp.Send{
+		Method: capnp.Method{
+			InterfaceID:   0xcc45c4dfa8fbffba,
+			MethodID:      1,
+			InterfaceName: "external.capnp:UiView.Controller",
+			MethodName:    "setTitle",
+		},
+	}
+	if params != nil {
+		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 1}
+		s.PlaceArgs = func(s capnp.Struct) error { return params(UiView_Controller_setTitle_Params(s)) }
+	}
+
+	ans, release := capnp.Client(c).SendCall(ctx, s)
+	return UiView_Controller_setTitle_Results_Future{Future: ans.Future()}, release
+
+}
+
+func (c UiView_Controller) MoveToTrash(ctx context.Context, params func(UiView_Controller_moveToTrash_Params) error) (UiView_Controller_moveToTrash_Results_Future, capnp.ReleaseFunc) {
+
+	s := capnp.Send{
+		Method: capnp.Method{
+			InterfaceID:   0xcc45c4dfa8fbffba,
+			MethodID:      2,
+			InterfaceName: "external.capnp:UiView.Controller",
+			MethodName:    "moveToTrash",
+		},
+	}
+	if params != nil {
+		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 0}
+		s.PlaceArgs = func(s capnp.Struct) error { return params(UiView_Controller_moveToTrash_Params(s)) }
+	}
+
+	ans, release := capnp.Client(c).SendCall(ctx, s)
+	return UiView_Controller_moveToTrash_Results_Future{Future: ans.Future()}, release
+
+}
+
+func (c UiView_Controller) RestoreFromTrash(ctx context.Context, params func(UiView_Controller_restoreFromTrash_Params) error) (UiView_Controller_restoreFromTrash_Results_Future, capnp.ReleaseFunc) {
+
+	s := capnp.Send{
+		Method: capnp.Method{
+			InterfaceID:   0xcc45c4dfa8fbffba,
+			MethodID:      3,
+			InterfaceName: "external.capnp:UiView.Controller",
+			MethodName:    "restoreFromTrash",
+		},
+	}
+	if params != nil {
+		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 0}
+		s.PlaceArgs = func(s capnp.Struct) error { return params(UiView_Controller_restoreFromTrash_Params(s)) }
+	}
+
+	ans, release := capnp.Client(c).SendCall(ctx, s)
+	return UiView_Controller_restoreFromTrash_Results_Future{Future: ans.Future()}, release
+
+}
+
+func (c UiView_Controller) Purge(ctx context.Context, params func(UiView_Controller_purge_Params) error) (UiView_Controller_purge_Results_Future, capnp.ReleaseFunc) {
+
+	s := capnp.Send{
+		Method: capnp.Method{
+			InterfaceID:   0xcc45c4dfa8fbffba,
+			MethodID:      4,
+			InterfaceName: "external.capnp:UiView.Controller",
+			MethodName:    "purge",
+		},
+	}
+	if params != nil {
+		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 0}
+		s.PlaceArgs = func(s capnp.Struct) error { return params(UiView_Controller_purge_Params(s)) }
+	}
+
+	ans, release := capnp.Client(c).SendCall(ctx, s)
+	return UiView_Controller_purge_Results_Future{Future: ans.Future()}, release
+
+}
+
+func (c UiView_Controller) WaitStreaming() error {
+	return capnp.Client(c).WaitStreaming()
+}
+
+// String returns a string that identifies this capability for debugging
+// purposes.  Its format should not be depended on: in particular, it
+// should not be used to compare clients.  Use IsSame to compare clients
+// for equality.
+func (c UiView_Controller) String() string {
+	return "UiView_Controller(" + capnp.Client(c).String() + ")"
+}
+
+// AddRef creates a new Client that refers to the same capability as c.
+// If c is nil or has resolved to null, then AddRef returns nil.
+func (c UiView_Controller) AddRef() UiView_Controller {
+	return UiView_Controller(capnp.Client(c).AddRef())
+}
+
+// Release releases a capability reference.  If this is the last
+// reference to the capability, then the underlying resources associated
+// with the capability will be released.
+//
+// Release will panic if c has already been released, but not if c is
+// nil or resolved to null.
+func (c UiView_Controller) Release() {
+	capnp.Client(c).Release()
+}
+
+// Resolve blocks until the capability is fully resolved or the Context
+// expires.
+func (c UiView_Controller) Resolve(ctx context.Context) error {
+	return capnp.Client(c).Resolve(ctx)
+}
+
+func (c UiView_Controller) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Client(c).EncodeAsPtr(seg)
+}
+
+func (UiView_Controller) DecodeFromPtr(p capnp.Ptr) UiView_Controller {
+	return UiView_Controller(capnp.Client{}.DecodeFromPtr(p))
+}
+
+// IsValid reports whether c is a valid reference to a capability.
+// A reference is invalid if it is nil, has resolved to null, or has
+// been released.
+func (c UiView_Controller) IsValid() bool {
+	return capnp.Client(c).IsValid()
+}
+
+// IsSame reports whether c and other refer to a capability created by the
+// same call to NewClient.  This can return false negatives if c or other
+// are not fully resolved: use Resolve if this is an issue.  If either
+// c or other are released, then IsSame panics.
+func (c UiView_Controller) IsSame(other UiView_Controller) bool {
+	return capnp.Client(c).IsSame(capnp.Client(other))
+}
+
+// Update the flowcontrol.FlowLimiter used to manage flow control for
+// this client. This affects all future calls, but not calls already
+// waiting to send. Passing nil sets the value to flowcontrol.NopLimiter,
+// which is also the default.
+func (c UiView_Controller) SetFlowLimiter(lim fc.FlowLimiter) {
+	capnp.Client(c).SetFlowLimiter(lim)
+}
+
+// Get the current flowcontrol.FlowLimiter used to manage flow control
+// for this client.
+func (c UiView_Controller) GetFlowLimiter() fc.FlowLimiter {
+	return capnp.Client(c).GetFlowLimiter()
+}
+
+// A UiView_Controller_Server is a UiView_Controller with a local implementation.
+type UiView_Controller_Server interface {
+	MakeSharingToken(context.Context, UiView_Controller_makeSharingToken) error
+
+	SetTitle(context.Context, UiView_Controller_setTitle) error
+
+	MoveToTrash(context.Context, UiView_Controller_moveToTrash) error
+
+	RestoreFromTrash(context.Context, UiView_Controller_restoreFromTrash) error
+
+	Purge(context.Context, UiView_Controller_purge) error
+}
+
+// UiView_Controller_NewServer creates a new Server from an implementation of UiView_Controller_Server.
+func UiView_Controller_NewServer(s UiView_Controller_Server) *server.Server {
+	c, _ := s.(server.Shutdowner)
+	return server.New(UiView_Controller_Methods(nil, s), s, c)
+}
+
+// UiView_Controller_ServerToClient creates a new Client from an implementation of UiView_Controller_Server.
+// The caller is responsible for calling Release on the returned Client.
+func UiView_Controller_ServerToClient(s UiView_Controller_Server) UiView_Controller {
+	return UiView_Controller(capnp.NewClient(UiView_Controller_NewServer(s)))
+}
+
+// UiView_Controller_Methods appends Methods to a slice that invoke the methods on s.
+// This can be used to create a more complicated Server.
+func UiView_Controller_Methods(methods []server.Method, s UiView_Controller_Server) []server.Method {
+	if cap(methods) == 0 {
+		methods = make([]server.Method, 0, 5)
+	}
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0xcc45c4dfa8fbffba,
+			MethodID:      0,
+			InterfaceName: "external.capnp:UiView.Controller",
+			MethodName:    "makeSharingToken",
+		},
+		Impl: func(ctx context.Context, call *server.Call) error {
+			return s.MakeSharingToken(ctx, UiView_Controller_makeSharingToken{call})
+		},
+	})
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0xcc45c4dfa8fbffba,
+			MethodID:      1,
+			InterfaceName: "external.capnp:UiView.Controller",
+			MethodName:    "setTitle",
+		},
+		Impl: func(ctx context.Context, call *server.Call) error {
+			return s.SetTitle(ctx, UiView_Controller_setTitle{call})
+		},
+	})
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0xcc45c4dfa8fbffba,
+			MethodID:      2,
+			InterfaceName: "external.capnp:UiView.Controller",
+			MethodName:    "moveToTrash",
+		},
+		Impl: func(ctx context.Context, call *server.Call) error {
+			return s.MoveToTrash(ctx, UiView_Controller_moveToTrash{call})
+		},
+	})
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0xcc45c4dfa8fbffba,
+			MethodID:      3,
+			InterfaceName: "external.capnp:UiView.Controller",
+			MethodName:    "restoreFromTrash",
+		},
+		Impl: func(ctx context.Context, call *server.Call) error {
+			return s.RestoreFromTrash(ctx, UiView_Controller_restoreFromTrash{call})
+		},
+	})
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0xcc45c4dfa8fbffba,
+			MethodID:      4,
+			InterfaceName: "external.capnp:UiView.Controller",
+			MethodName:    "purge",
+		},
+		Impl: func(ctx context.Context, call *server.Call) error {
+			return s.Purge(ctx, UiView_Controller_purge{call})
+		},
+	})
+
+	return methods
+}
+
+// UiView_Controller_makeSharingToken holds the state for a server call to UiView_Controller.makeSharingToken.
+// See server.Call for documentation.
+type UiView_Controller_makeSharingToken struct {
+	*server.Call
+}
+
+// Args returns the call's arguments.
+func (c UiView_Controller_makeSharingToken) Args() UiView_Controller_makeSharingToken_Params {
+	return UiView_Controller_makeSharingToken_Params(c.Call.Args())
+}
+
+// AllocResults allocates the results struct.
+func (c UiView_Controller_makeSharingToken) AllocResults() (UiView_Controller_makeSharingToken_Results, error) {
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return UiView_Controller_makeSharingToken_Results(r), err
+}
+
+// UiView_Controller_setTitle holds the state for a server call to UiView_Controller.setTitle.
+// See server.Call for documentation.
+type UiView_Controller_setTitle struct {
+	*server.Call
+}
+
+// Args returns the call's arguments.
+func (c UiView_Controller_setTitle) Args() UiView_Controller_setTitle_Params {
+	return UiView_Controller_setTitle_Params(c.Call.Args())
+}
+
+// AllocResults allocates the results struct.
+func (c UiView_Controller_setTitle) AllocResults() (UiView_Controller_setTitle_Results, error) {
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UiView_Controller_setTitle_Results(r), err
+}
+
+// UiView_Controller_moveToTrash holds the state for a server call to UiView_Controller.moveToTrash.
+// See server.Call for documentation.
+type UiView_Controller_moveToTrash struct {
+	*server.Call
+}
+
+// Args returns the call's arguments.
+func (c UiView_Controller_moveToTrash) Args() UiView_Controller_moveToTrash_Params {
+	return UiView_Controller_moveToTrash_Params(c.Call.Args())
+}
+
+// AllocResults allocates the results struct.
+func (c UiView_Controller_moveToTrash) AllocResults() (UiView_Controller_moveToTrash_Results, error) {
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UiView_Controller_moveToTrash_Results(r), err
+}
+
+// UiView_Controller_restoreFromTrash holds the state for a server call to UiView_Controller.restoreFromTrash.
+// See server.Call for documentation.
+type UiView_Controller_restoreFromTrash struct {
+	*server.Call
+}
+
+// Args returns the call's arguments.
+func (c UiView_Controller_restoreFromTrash) Args() UiView_Controller_restoreFromTrash_Params {
+	return UiView_Controller_restoreFromTrash_Params(c.Call.Args())
+}
+
+// AllocResults allocates the results struct.
+func (c UiView_Controller_restoreFromTrash) AllocResults() (UiView_Controller_restoreFromTrash_Results, error) {
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UiView_Controller_restoreFromTrash_Results(r), err
+}
+
+// UiView_Controller_purge holds the state for a server call to UiView_Controller.purge.
+// See server.Call for documentation.
+type UiView_Controller_purge struct {
+	*server.Call
+}
+
+// Args returns the call's arguments.
+func (c UiView_Controller_purge) Args() UiView_Controller_purge_Params {
+	return UiView_Controller_purge_Params(c.Call.Args())
+}
+
+// AllocResults allocates the results struct.
+func (c UiView_Controller_purge) AllocResults() (UiView_Controller_purge_Results, error) {
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UiView_Controller_purge_Results(r), err
+}
+
+// UiView_Controller_List is a list of UiView_Controller.
+type UiView_Controller_List = capnp.CapList[UiView_Controller]
+
+// NewUiView_Controller_List creates a new list of UiView_Controller.
+func NewUiView_Controller_List(s *capnp.Segment, sz int32) (UiView_Controller_List, error) {
+	l, err := capnp.NewPointerList(s, sz)
+	return capnp.CapList[UiView_Controller](l), err
+}
+
+type UiView_Controller_makeSharingToken_Params capnp.Struct
+
+// UiView_Controller_makeSharingToken_Params_TypeID is the unique identifier for the type UiView_Controller_makeSharingToken_Params.
+const UiView_Controller_makeSharingToken_Params_TypeID = 0xb0d3e2aa469b06cd
+
+func NewUiView_Controller_makeSharingToken_Params(s *capnp.Segment) (UiView_Controller_makeSharingToken_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return UiView_Controller_makeSharingToken_Params(st), err
+}
+
+func NewRootUiView_Controller_makeSharingToken_Params(s *capnp.Segment) (UiView_Controller_makeSharingToken_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return UiView_Controller_makeSharingToken_Params(st), err
+}
+
+func ReadRootUiView_Controller_makeSharingToken_Params(msg *capnp.Message) (UiView_Controller_makeSharingToken_Params, error) {
+	root, err := msg.Root()
+	return UiView_Controller_makeSharingToken_Params(root.Struct()), err
+}
+
+func (s UiView_Controller_makeSharingToken_Params) String() string {
+	str, _ := text.Marshal(0xb0d3e2aa469b06cd, capnp.Struct(s))
+	return str
+}
+
+func (s UiView_Controller_makeSharingToken_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UiView_Controller_makeSharingToken_Params) DecodeFromPtr(p capnp.Ptr) UiView_Controller_makeSharingToken_Params {
+	return UiView_Controller_makeSharingToken_Params(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UiView_Controller_makeSharingToken_Params) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UiView_Controller_makeSharingToken_Params) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UiView_Controller_makeSharingToken_Params) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UiView_Controller_makeSharingToken_Params) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s UiView_Controller_makeSharingToken_Params) Permissions() (capnp.BitList, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return capnp.BitList(p.List()), err
+}
+
+func (s UiView_Controller_makeSharingToken_Params) HasPermissions() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s UiView_Controller_makeSharingToken_Params) SetPermissions(v capnp.BitList) error {
+	return capnp.Struct(s).SetPtr(0, v.ToPtr())
+}
+
+// NewPermissions sets the permissions field to a newly
+// allocated capnp.BitList, preferring placement in s's segment.
+func (s UiView_Controller_makeSharingToken_Params) NewPermissions(n int32) (capnp.BitList, error) {
+	l, err := capnp.NewBitList(capnp.Struct(s).Segment(), n)
+	if err != nil {
+		return capnp.BitList{}, err
+	}
+	err = capnp.Struct(s).SetPtr(0, l.ToPtr())
+	return l, err
+}
+func (s UiView_Controller_makeSharingToken_Params) Note() (string, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return p.Text(), err
+}
+
+func (s UiView_Controller_makeSharingToken_Params) HasNote() bool {
+	return capnp.Struct(s).HasPtr(1)
+}
+
+func (s UiView_Controller_makeSharingToken_Params) NoteBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return p.TextBytes(), err
+}
+
+func (s UiView_Controller_makeSharingToken_Params) SetNote(v string) error {
+	return capnp.Struct(s).SetText(1, v)
+}
+
+// UiView_Controller_makeSharingToken_Params_List is a list of UiView_Controller_makeSharingToken_Params.
+type UiView_Controller_makeSharingToken_Params_List = capnp.StructList[UiView_Controller_makeSharingToken_Params]
+
+// NewUiView_Controller_makeSharingToken_Params creates a new list of UiView_Controller_makeSharingToken_Params.
+func NewUiView_Controller_makeSharingToken_Params_List(s *capnp.Segment, sz int32) (UiView_Controller_makeSharingToken_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2}, sz)
+	return capnp.StructList[UiView_Controller_makeSharingToken_Params](l), err
+}
+
+// UiView_Controller_makeSharingToken_Params_Future is a wrapper for a UiView_Controller_makeSharingToken_Params promised by a client call.
+type UiView_Controller_makeSharingToken_Params_Future struct{ *capnp.Future }
+
+func (f UiView_Controller_makeSharingToken_Params_Future) Struct() (UiView_Controller_makeSharingToken_Params, error) {
+	p, err := f.Future.Ptr()
+	return UiView_Controller_makeSharingToken_Params(p.Struct()), err
+}
+
+type UiView_Controller_makeSharingToken_Results capnp.Struct
+
+// UiView_Controller_makeSharingToken_Results_TypeID is the unique identifier for the type UiView_Controller_makeSharingToken_Results.
+const UiView_Controller_makeSharingToken_Results_TypeID = 0xab5851e986c119a6
+
+func NewUiView_Controller_makeSharingToken_Results(s *capnp.Segment) (UiView_Controller_makeSharingToken_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return UiView_Controller_makeSharingToken_Results(st), err
+}
+
+func NewRootUiView_Controller_makeSharingToken_Results(s *capnp.Segment) (UiView_Controller_makeSharingToken_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return UiView_Controller_makeSharingToken_Results(st), err
+}
+
+func ReadRootUiView_Controller_makeSharingToken_Results(msg *capnp.Message) (UiView_Controller_makeSharingToken_Results, error) {
+	root, err := msg.Root()
+	return UiView_Controller_makeSharingToken_Results(root.Struct()), err
+}
+
+func (s UiView_Controller_makeSharingToken_Results) String() string {
+	str, _ := text.Marshal(0xab5851e986c119a6, capnp.Struct(s))
+	return str
+}
+
+func (s UiView_Controller_makeSharingToken_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UiView_Controller_makeSharingToken_Results) DecodeFromPtr(p capnp.Ptr) UiView_Controller_makeSharingToken_Results {
+	return UiView_Controller_makeSharingToken_Results(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UiView_Controller_makeSharingToken_Results) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UiView_Controller_makeSharingToken_Results) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UiView_Controller_makeSharingToken_Results) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UiView_Controller_makeSharingToken_Results) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s UiView_Controller_makeSharingToken_Results) Token() (string, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.Text(), err
+}
+
+func (s UiView_Controller_makeSharingToken_Results) HasToken() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s UiView_Controller_makeSharingToken_Results) TokenBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s UiView_Controller_makeSharingToken_Results) SetToken(v string) error {
+	return capnp.Struct(s).SetText(0, v)
+}
+
+func (s UiView_Controller_makeSharingToken_Results) Error() (ApiError, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return ApiError(p.Struct()), err
+}
+
+func (s UiView_Controller_makeSharingToken_Results) HasError() bool {
+	return capnp.Struct(s).HasPtr(1)
+}
+
+func (s UiView_Controller_makeSharingToken_Results) SetError(v ApiError) error {
+	return capnp.Struct(s).SetPtr(1, capnp.Struct(v).ToPtr())
+}
+
+// NewError sets the error field to a newly
+// allocated ApiError struct, preferring placement in s's segment.
+func (s UiView_Controller_makeSharingToken_Results) NewError() (ApiError, error) {
+	ss, err := NewApiError(capnp.Struct(s).Segment())
+	if err != nil {
+		return ApiError{}, err
+	}
+	err = capnp.Struct(s).SetPtr(1, capnp.Struct(ss).ToPtr())
+	return ss, err
+}
+
+// UiView_Controller_makeSharingToken_Results_List is a list of UiView_Controller_makeSharingToken_Results.
+type UiView_Controller_makeSharingToken_Results_List = capnp.StructList[UiView_Controller_makeSharingToken_Results]
+
+// NewUiView_Controller_makeSharingToken_Results creates a new list of UiView_Controller_makeSharingToken_Results.
+func NewUiView_Controller_makeSharingToken_Results_List(s *capnp.Segment, sz int32) (UiView_Controller_makeSharingToken_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2}, sz)
+	return capnp.StructList[UiView_Controller_makeSharingToken_Results](l), err
+}
+
+// UiView_Controller_makeSharingToken_Results_Future is a wrapper for a UiView_Controller_makeSharingToken_Results promised by a client call.
+type UiView_Controller_makeSharingToken_Results_Future struct{ *capnp.Future }
+
+func (f UiView_Controller_makeSharingToken_Results_Future) Struct() (UiView_Controller_makeSharingToken_Results, error) {
+	p, err := f.Future.Ptr()
+	return UiView_Controller_makeSharingToken_Results(p.Struct()), err
+}
+func (p UiView_Controller_makeSharingToken_Results_Future) Error() ApiError_Future {
+	return ApiError_Future{Future: p.Future.Field(1, nil)}
+}
+
+type UiView_Controller_setTitle_Params capnp.Struct
+
+// UiView_Controller_setTitle_Params_TypeID is the unique identifier for the type UiView_Controller_setTitle_Params.
+const UiView_Controller_setTitle_Params_TypeID = 0xb717412d49a9861d
+
+func NewUiView_Controller_setTitle_Params(s *capnp.Segment) (UiView_Controller_setTitle_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UiView_Controller_setTitle_Params(st), err
+}
+
+func NewRootUiView_Controller_setTitle_Params(s *capnp.Segment) (UiView_Controller_setTitle_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UiView_Controller_setTitle_Params(st), err
+}
+
+func ReadRootUiView_Controller_setTitle_Params(msg *capnp.Message) (UiView_Controller_setTitle_Params, error) {
+	root, err := msg.Root()
+	return UiView_Controller_setTitle_Params(root.Struct()), err
+}
+
+func (s UiView_Controller_setTitle_Params) String() string {
+	str, _ := text.Marshal(0xb717412d49a9861d, capnp.Struct(s))
+	return str
+}
+
+func (s UiView_Controller_setTitle_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UiView_Controller_setTitle_Params) DecodeFromPtr(p capnp.Ptr) UiView_Controller_setTitle_Params {
+	return UiView_Controller_setTitle_Params(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UiView_Controller_setTitle_Params) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UiView_Controller_setTitle_Params) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UiView_Controller_setTitle_Params) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UiView_Controller_setTitle_Params) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s UiView_Controller_setTitle_Params) Title() (string, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.Text(), err
+}
+
+func (s UiView_Controller_setTitle_Params) HasTitle() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s UiView_Controller_setTitle_Params) TitleBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s UiView_Controller_setTitle_Params) SetTitle(v string) error {
+	return capnp.Struct(s).SetText(0, v)
+}
+
+// UiView_Controller_setTitle_Params_List is a list of UiView_Controller_setTitle_Params.
+type UiView_Controller_setTitle_Params_List = capnp.StructList[UiView_Controller_setTitle_Params]
+
+// NewUiView_Controller_setTitle_Params creates a new list of UiView_Controller_setTitle_Params.
+func NewUiView_Controller_setTitle_Params_List(s *capnp.Segment, sz int32) (UiView_Controller_setTitle_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return capnp.StructList[UiView_Controller_setTitle_Params](l), err
+}
+
+// UiView_Controller_setTitle_Params_Future is a wrapper for a UiView_Controller_setTitle_Params promised by a client call.
+type UiView_Controller_setTitle_Params_Future struct{ *capnp.Future }
+
+func (f UiView_Controller_setTitle_Params_Future) Struct() (UiView_Controller_setTitle_Params, error) {
+	p, err := f.Future.Ptr()
+	return UiView_Controller_setTitle_Params(p.Struct()), err
+}
+
+type UiView_Controller_setTitle_Results capnp.Struct
+
+// UiView_Controller_setTitle_Results_TypeID is the unique identifier for the type UiView_Controller_setTitle_Results.
+const UiView_Controller_setTitle_Results_TypeID = 0x86a151ee10ce7362
+
+func NewUiView_Controller_setTitle_Results(s *capnp.Segment) (UiView_Controller_setTitle_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UiView_Controller_setTitle_Results(st), err
+}
+
+func NewRootUiView_Controller_setTitle_Results(s *capnp.Segment) (UiView_Controller_setTitle_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UiView_Controller_setTitle_Results(st), err
+}
+
+func ReadRootUiView_Controller_setTitle_Results(msg *capnp.Message) (UiView_Controller_setTitle_Results, error) {
+	root, err := msg.Root()
+	return UiView_Controller_setTitle_Results(root.Struct()), err
+}
+
+func (s UiView_Controller_setTitle_Results) String() string {
+	str, _ := text.Marshal(0x86a151ee10ce7362, capnp.Struct(s))
+	return str
+}
+
+func (s UiView_Controller_setTitle_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UiView_Controller_setTitle_Results) DecodeFromPtr(p capnp.Ptr) UiView_Controller_setTitle_Results {
+	return UiView_Controller_setTitle_Results(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UiView_Controller_setTitle_Results) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UiView_Controller_setTitle_Results) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UiView_Controller_setTitle_Results) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UiView_Controller_setTitle_Results) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s UiView_Controller_setTitle_Results) Error() (ApiError, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return ApiError(p.Struct()), err
+}
+
+func (s UiView_Controller_setTitle_Results) HasError() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s UiView_Controller_setTitle_Results) SetError(v ApiError) error {
+	return capnp.Struct(s).SetPtr(0, capnp.Struct(v).ToPtr())
+}
+
+// NewError sets the error field to a newly
+// allocated ApiError struct, preferring placement in s's segment.
+func (s UiView_Controller_setTitle_Results) NewError() (ApiError, error) {
+	ss, err := NewApiError(capnp.Struct(s).Segment())
+	if err != nil {
+		return ApiError{}, err
+	}
+	err = capnp.Struct(s).SetPtr(0, capnp.Struct(ss).ToPtr())
+	return ss, err
+}
+
+// UiView_Controller_setTitle_Results_List is a list of UiView_Controller_setTitle_Results.
+type UiView_Controller_setTitle_Results_List = capnp.StructList[UiView_Controller_setTitle_Results]
+
+// NewUiView_Controller_setTitle_Results creates a new list of UiView_Controller_setTitle_Results.
+func NewUiView_Controller_setTitle_Results_List(s *capnp.Segment, sz int32) (UiView_Controller_setTitle_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return capnp.StructList[UiView_Controller_setTitle_Results](l), err
+}
+
+// UiView_Controller_setTitle_Results_Future is a wrapper for a UiView_Controller_setTitle_Results promised by a client call.
+type UiView_Controller_setTitle_Results_Future struct{ *capnp.Future }
+
+func (f UiView_Controller_setTitle_Results_Future) Struct() (UiView_Controller_setTitle_Results, error) {
+	p, err := f.Future.Ptr()
+	return UiView_Controller_setTitle_Results(p.Struct()), err
+}
+func (p UiView_Controller_setTitle_Results_Future) Error() ApiError_Future {
+	return ApiError_Future{Future: p.Future.Field(0, nil)}
+}
+
+type UiView_Controller_moveToTrash_Params capnp.Struct
+
+// UiView_Controller_moveToTrash_Params_TypeID is the unique identifier for the type UiView_Controller_moveToTrash_Params.
+const UiView_Controller_moveToTrash_Params_TypeID = 0xd307970aa6710f91
+
+func NewUiView_Controller_moveToTrash_Params(s *capnp.Segment) (UiView_Controller_moveToTrash_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
+	return UiView_Controller_moveToTrash_Params(st), err
+}
+
+func NewRootUiView_Controller_moveToTrash_Params(s *capnp.Segment) (UiView_Controller_moveToTrash_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
+	return UiView_Controller_moveToTrash_Params(st), err
+}
+
+func ReadRootUiView_Controller_moveToTrash_Params(msg *capnp.Message) (UiView_Controller_moveToTrash_Params, error) {
+	root, err := msg.Root()
+	return UiView_Controller_moveToTrash_Params(root.Struct()), err
+}
+
+func (s UiView_Controller_moveToTrash_Params) String() string {
+	str, _ := text.Marshal(0xd307970aa6710f91, capnp.Struct(s))
+	return str
+}
+
+func (s UiView_Controller_moveToTrash_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UiView_Controller_moveToTrash_Params) DecodeFromPtr(p capnp.Ptr) UiView_Controller_moveToTrash_Params {
+	return UiView_Controller_moveToTrash_Params(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UiView_Controller_moveToTrash_Params) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UiView_Controller_moveToTrash_Params) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UiView_Controller_moveToTrash_Params) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UiView_Controller_moveToTrash_Params) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+
+// UiView_Controller_moveToTrash_Params_List is a list of UiView_Controller_moveToTrash_Params.
+type UiView_Controller_moveToTrash_Params_List = capnp.StructList[UiView_Controller_moveToTrash_Params]
+
+// NewUiView_Controller_moveToTrash_Params creates a new list of UiView_Controller_moveToTrash_Params.
+func NewUiView_Controller_moveToTrash_Params_List(s *capnp.Segment, sz int32) (UiView_Controller_moveToTrash_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0}, sz)
+	return capnp.StructList[UiView_Controller_moveToTrash_Params](l), err
+}
+
+// UiView_Controller_moveToTrash_Params_Future is a wrapper for a UiView_Controller_moveToTrash_Params promised by a client call.
+type UiView_Controller_moveToTrash_Params_Future struct{ *capnp.Future }
+
+func (f UiView_Controller_moveToTrash_Params_Future) Struct() (UiView_Controller_moveToTrash_Params, error) {
+	p, err := f.Future.Ptr()
+	return UiView_Controller_moveToTrash_Params(p.Struct()), err
+}
+
+type UiView_Controller_moveToTrash_Results capnp.Struct
+
+// UiView_Controller_moveToTrash_Results_TypeID is the unique identifier for the type UiView_Controller_moveToTrash_Results.
+const UiView_Controller_moveToTrash_Results_TypeID = 0xf9a8c59a6b33263e
+
+func NewUiView_Controller_moveToTrash_Results(s *capnp.Segment) (UiView_Controller_moveToTrash_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UiView_Controller_moveToTrash_Results(st), err
+}
+
+func NewRootUiView_Controller_moveToTrash_Results(s *capnp.Segment) (UiView_Controller_moveToTrash_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UiView_Controller_moveToTrash_Results(st), err
+}
+
+func ReadRootUiView_Controller_moveToTrash_Results(msg *capnp.Message) (UiView_Controller_moveToTrash_Results, error) {
+	root, err := msg.Root()
+	return UiView_Controller_moveToTrash_Results(root.Struct()), err
+}
+
+func (s UiView_Controller_moveToTrash_Results) String() string {
+	str, _ := text.Marshal(0xf9a8c59a6b33263e, capnp.Struct(s))
+	return str
+}
+
+func (s UiView_Controller_moveToTrash_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UiView_Controller_moveToTrash_Results) DecodeFromPtr(p capnp.Ptr) UiView_Controller_moveToTrash_Results {
+	return UiView_Controller_moveToTrash_Results(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UiView_Controller_moveToTrash_Results) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UiView_Controller_moveToTrash_Results) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UiView_Controller_moveToTrash_Results) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UiView_Controller_moveToTrash_Results) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s UiView_Controller_moveToTrash_Results) Error() (ApiError, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return ApiError(p.Struct()), err
+}
+
+func (s UiView_Controller_moveToTrash_Results) HasError() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s UiView_Controller_moveToTrash_Results) SetError(v ApiError) error {
+	return capnp.Struct(s).SetPtr(0, capnp.Struct(v).ToPtr())
+}
+
+// NewError sets the error field to a newly
+// allocated ApiError struct, preferring placement in s's segment.
+func (s UiView_Controller_moveToTrash_Results) NewError() (ApiError, error) {
+	ss, err := NewApiError(capnp.Struct(s).Segment())
+	if err != nil {
+		return ApiError{}, err
+	}
+	err = capnp.Struct(s).SetPtr(0, capnp.Struct(ss).ToPtr())
+	return ss, err
+}
+
+// UiView_Controller_moveToTrash_Results_List is a list of UiView_Controller_moveToTrash_Results.
+type UiView_Controller_moveToTrash_Results_List = capnp.StructList[UiView_Controller_moveToTrash_Results]
+
+// NewUiView_Controller_moveToTrash_Results creates a new list of UiView_Controller_moveToTrash_Results.
+func NewUiView_Controller_moveToTrash_Results_List(s *capnp.Segment, sz int32) (UiView_Controller_moveToTrash_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return capnp.StructList[UiView_Controller_moveToTrash_Results](l), err
+}
+
+// UiView_Controller_moveToTrash_Results_Future is a wrapper for a UiView_Controller_moveToTrash_Results promised by a client call.
+type UiView_Controller_moveToTrash_Results_Future struct{ *capnp.Future }
+
+func (f UiView_Controller_moveToTrash_Results_Future) Struct() (UiView_Controller_moveToTrash_Results, error) {
+	p, err := f.Future.Ptr()
+	return UiView_Controller_moveToTrash_Results(p.Struct()), err
+}
+func (p UiView_Controller_moveToTrash_Results_Future) Error() ApiError_Future {
+	return ApiError_Future{Future: p.Future.Field(0, nil)}
+}
+
+type UiView_Controller_restoreFromTrash_Params capnp.Struct
+
+// UiView_Controller_restoreFromTrash_Params_TypeID is the unique identifier for the type UiView_Controller_restoreFromTrash_Params.
+const UiView_Controller_restoreFromTrash_Params_TypeID = 0xf327200c58db8db0
+
+func NewUiView_Controller_restoreFromTrash_Params(s *capnp.Segment) (UiView_Controller_restoreFromTrash_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
+	return UiView_Controller_restoreFromTrash_Params(st), err
+}
+
+func NewRootUiView_Controller_restoreFromTrash_Params(s *capnp.Segment) (UiView_Controller_restoreFromTrash_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
+	return UiView_Controller_restoreFromTrash_Params(st), err
+}
+
+func ReadRootUiView_Controller_restoreFromTrash_Params(msg *capnp.Message) (UiView_Controller_restoreFromTrash_Params, error) {
+	root, err := msg.Root()
+	return UiView_Controller_restoreFromTrash_Params(root.Struct()), err
+}
+
+func (s UiView_Controller_restoreFromTrash_Params) String() string {
+	str, _ := text.Marshal(0xf327200c58db8db0, capnp.Struct(s))
+	return str
+}
+
+func (s UiView_Controller_restoreFromTrash_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UiView_Controller_restoreFromTrash_Params) DecodeFromPtr(p capnp.Ptr) UiView_Controller_restoreFromTrash_Params {
+	return UiView_Controller_restoreFromTrash_Params(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UiView_Controller_restoreFromTrash_Params) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UiView_Controller_restoreFromTrash_Params) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UiView_Controller_restoreFromTrash_Params) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UiView_Controller_restoreFromTrash_Params) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+
+// UiView_Controller_restoreFromTrash_Params_List is a list of UiView_Controller_restoreFromTrash_Params.
+type UiView_Controller_restoreFromTrash_Params_List = capnp.StructList[UiView_Controller_restoreFromTrash_Params]
+
+// NewUiView_Controller_restoreFromTrash_Params creates a new list of UiView_Controller_restoreFromTrash_Params.
+func NewUiView_Controller_restoreFromTrash_Params_List(s *capnp.Segment, sz int32) (UiView_Controller_restoreFromTrash_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0}, sz)
+	return capnp.StructList[UiView_Controller_restoreFromTrash_Params](l), err
+}
+
+// UiView_Controller_restoreFromTrash_Params_Future is a wrapper for a UiView_Controller_restoreFromTrash_Params promised by a client call.
+type UiView_Controller_restoreFromTrash_Params_Future struct{ *capnp.Future }
+
+func (f UiView_Controller_restoreFromTrash_Params_Future) Struct() (UiView_Controller_restoreFromTrash_Params, error) {
+	p, err := f.Future.Ptr()
+	return UiView_Controller_restoreFromTrash_Params(p.Struct()), err
+}
+
+type UiView_Controller_restoreFromTrash_Results capnp.Struct
+
+// UiView_Controller_restoreFromTrash_Results_TypeID is the unique identifier for the type UiView_Controller_restoreFromTrash_Results.
+const UiView_Controller_restoreFromTrash_Results_TypeID = 0xdde2a201a7d44f5a
+
+func NewUiView_Controller_restoreFromTrash_Results(s *capnp.Segment) (UiView_Controller_restoreFromTrash_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UiView_Controller_restoreFromTrash_Results(st), err
+}
+
+func NewRootUiView_Controller_restoreFromTrash_Results(s *capnp.Segment) (UiView_Controller_restoreFromTrash_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UiView_Controller_restoreFromTrash_Results(st), err
+}
+
+func ReadRootUiView_Controller_restoreFromTrash_Results(msg *capnp.Message) (UiView_Controller_restoreFromTrash_Results, error) {
+	root, err := msg.Root()
+	return UiView_Controller_restoreFromTrash_Results(root.Struct()), err
+}
+
+func (s UiView_Controller_restoreFromTrash_Results) String() string {
+	str, _ := text.Marshal(0xdde2a201a7d44f5a, capnp.Struct(s))
+	return str
+}
+
+func (s UiView_Controller_restoreFromTrash_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UiView_Controller_restoreFromTrash_Results) DecodeFromPtr(p capnp.Ptr) UiView_Controller_restoreFromTrash_Results {
+	return UiView_Controller_restoreFromTrash_Results(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UiView_Controller_restoreFromTrash_Results) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UiView_Controller_restoreFromTrash_Results) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UiView_Controller_restoreFromTrash_Results) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UiView_Controller_restoreFromTrash_Results) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s UiView_Controller_restoreFromTrash_Results) Error() (ApiError, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return ApiError(p.Struct()), err
+}
+
+func (s UiView_Controller_restoreFromTrash_Results) HasError() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s UiView_Controller_restoreFromTrash_Results) SetError(v ApiError) error {
+	return capnp.Struct(s).SetPtr(0, capnp.Struct(v).ToPtr())
+}
+
+// NewError sets the error field to a newly
+// allocated ApiError struct, preferring placement in s's segment.
+func (s UiView_Controller_restoreFromTrash_Results) NewError() (ApiError, error) {
+	ss, err := NewApiError(capnp.Struct(s).Segment())
+	if err != nil {
+		return ApiError{}, err
+	}
+	err = capnp.Struct(s).SetPtr(0, capnp.Struct(ss).ToPtr())
+	return ss, err
+}
+
+// UiView_Controller_restoreFromTrash_Results_List is a list of UiView_Controller_restoreFromTrash_Results.
+type UiView_Controller_restoreFromTrash_Results_List = capnp.StructList[UiView_Controller_restoreFromTrash_Results]
+
+// NewUiView_Controller_restoreFromTrash_Results creates a new list of UiView_Controller_restoreFromTrash_Results.
+func NewUiView_Controller_restoreFromTrash_Results_List(s *capnp.Segment, sz int32) (UiView_Controller_restoreFromTrash_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return capnp.StructList[UiView_Controller_restoreFromTrash_Results](l), err
+}
+
+// UiView_Controller_restoreFromTrash_Results_Future is a wrapper for a UiView_Controller_restoreFromTrash_Results promised by a client call.
+type UiView_Controller_restoreFromTrash_Results_Future struct{ *capnp.Future }
+
+func (f UiView_Controller_restoreFromTrash_Results_Future) Struct() (UiView_Controller_restoreFromTrash_Results, error) {
+	p, err := f.Future.Ptr()
+	return UiView_Controller_restoreFromTrash_Results(p.Struct()), err
+}
+func (p UiView_Controller_restoreFromTrash_Results_Future) Error() ApiError_Future {
+	return ApiError_Future{Future: p.Future.Field(0, nil)}
+}
+
+type UiView_Controller_purge_Params capnp.Struct
+
+// UiView_Controller_purge_Params_TypeID is the unique identifier for the type UiView_Controller_purge_Params.
+const UiView_Controller_purge_Params_TypeID = 0xe54d6605c26011a9
+
+func NewUiView_Controller_purge_Params(s *capnp.Segment) (UiView_Controller_purge_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
+	return UiView_Controller_purge_Params(st), err
+}
+
+func NewRootUiView_Controller_purge_Params(s *capnp.Segment) (UiView_Controller_purge_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
+	return UiView_Controller_purge_Params(st), err
+}
+
+func ReadRootUiView_Controller_purge_Params(msg *capnp.Message) (UiView_Controller_purge_Params, error) {
+	root, err := msg.Root()
+	return UiView_Controller_purge_Params(root.Struct()), err
+}
+
+func (s UiView_Controller_purge_Params) String() string {
+	str, _ := text.Marshal(0xe54d6605c26011a9, capnp.Struct(s))
+	return str
+}
+
+func (s UiView_Controller_purge_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UiView_Controller_purge_Params) DecodeFromPtr(p capnp.Ptr) UiView_Controller_purge_Params {
+	return UiView_Controller_purge_Params(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UiView_Controller_purge_Params) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UiView_Controller_purge_Params) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UiView_Controller_purge_Params) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UiView_Controller_purge_Params) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+
+// UiView_Controller_purge_Params_List is a list of UiView_Controller_purge_Params.
+type UiView_Controller_purge_Params_List = capnp.StructList[UiView_Controller_purge_Params]
+
+// NewUiView_Controller_purge_Params creates a new list of UiView_Controller_purge_Params.
+func NewUiView_Controller_purge_Params_List(s *capnp.Segment, sz int32) (UiView_Controller_purge_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0}, sz)
+	return capnp.StructList[UiView_Controller_purge_Params](l), err
+}
+
+// UiView_Controller_purge_Params_Future is a wrapper for a UiView_Controller_purge_Params promised by a client call.
+type UiView_Controller_purge_Params_Future struct{ *capnp.Future }
+
+func (f UiView_Controller_purge_Params_Future) Struct() (UiView_Controller_purge_Params, error) {
+	p, err := f.Future.Ptr()
+	return UiView_Controller_purge_Params(p.Struct()), err
+}
+
+type UiView_Controller_purge_Results capnp.Struct
+
+// UiView_Controller_purge_Results_TypeID is the unique identifier for the type UiView_Controller_purge_Results.
+const UiView_Controller_purge_Results_TypeID = 0xa4bc2673be08fc37
+
+func NewUiView_Controller_purge_Results(s *capnp.Segment) (UiView_Controller_purge_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UiView_Controller_purge_Results(st), err
+}
+
+func NewRootUiView_Controller_purge_Results(s *capnp.Segment) (UiView_Controller_purge_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UiView_Controller_purge_Results(st), err
+}
+
+func ReadRootUiView_Controller_purge_Results(msg *capnp.Message) (UiView_Controller_purge_Results, error) {
+	root, err := msg.Root()
+	return UiView_Controller_purge_Results(root.Struct()), err
+}
+
+func (s UiView_Controller_purge_Results) String() string {
+	str, _ := text.Marshal(0xa4bc2673be08fc37, capnp.Struct(s))
+	return str
+}
+
+func (s UiView_Controller_purge_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UiView_Controller_purge_Results) DecodeFromPtr(p capnp.Ptr) UiView_Controller_purge_Results {
+	return UiView_Controller_purge_Results(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UiView_Controller_purge_Results) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UiView_Controller_purge_Results) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UiView_Controller_purge_Results) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UiView_Controller_purge_Results) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s UiView_Controller_purge_Results) Error() (ApiError, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return ApiError(p.Struct()), err
+}
+
+func (s UiView_Controller_purge_Results) HasError() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s UiView_Controller_purge_Results) SetError(v ApiError) error {
+	return capnp.Struct(s).SetPtr(0, capnp.Struct(v).ToPtr())
+}
+
+// NewError sets the error field to a newly
+// allocated ApiError struct, preferring placement in s's segment.
+func (s UiView_Controller_purge_Results) NewError() (ApiError, error) {
+	ss, err := NewApiError(capnp.Struct(s).Segment())
+	if err != nil {
+		return ApiError{}, err
+	}
+	err = capnp.Struct(s).SetPtr(0, capnp.Struct(ss).ToPtr())
+	return ss, err
+}
+
+// UiView_Controller_purge_Results_List is a list of UiView_Controller_purge_Results.
+type UiView_Controller_purge_Results_List = capnp.StructList[UiView_Controller_purge_Results]
+
+// NewUiView_Controller_purge_Results creates a new list of UiView_Controller_purge_Results.
+func NewUiView_Controller_purge_Results_List(s *capnp.Segment, sz int32) (UiView_Controller_purge_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return capnp.StructList[UiView_Controller_purge_Results](l), err
+}
+
+// UiView_Controller_purge_Results_Future is a wrapper for a UiView_Controller_purge_Results promised by a client call.
+type UiView_Controller_purge_Results_Future struct{ *capnp.Future }
+
+func (f UiView_Controller_purge_Results_Future) Struct() (UiView_Controller_purge_Results, error) {
+	p, err := f.Future.Ptr()
+	return UiView_Controller_purge_Results(p.Struct()), err
+}
+func (p UiView_Controller_purge_Results_Future) Error() ApiError_Future {
+	return ApiError_Future{Future: p.Future.Field(0, nil)}
+}
+
+type UiView_Keyring capnp.Client
+
+// UiView_Keyring_TypeID is the unique identifier for the type UiView_Keyring.
+const UiView_Keyring_TypeID = 0xe38a747a26bc9a79
+
+func (c UiView_Keyring) Attach(ctx context.Context, params func(UiView_Keyring_attach_Params) error) (UiView_Keyring_attach_Results_Future, capnp.ReleaseFunc) {
+
+	s := capnp.Send{
+		Method: capnp.Method{
+			InterfaceID:   0xe38a747a26bc9a79,
+			MethodID:      0,
+			InterfaceName: "external.capnp:UiView.Keyring",
+			MethodName:    "attach",
+		},
+	}
+	if params != nil {
+		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 1}
+		s.PlaceArgs = func(s capnp.Struct) error { return params(UiView_Keyring_attach_Params(s)) }
+	}
+
+	ans, release := capnp.Client(c).SendCall(ctx, s)
+	return UiView_Keyring_attach_Results_Future{Future: ans.Future()}, release
+
+}
+
+func (c UiView_Keyring) Sync(ctx context.Context, params func(collection.Puller_sync_Params) error) (collection.Puller_sync_Results_Future, capnp.ReleaseFunc) {
+
+	s := capnp.Send{
+		Method: capnp.Method{
+			InterfaceID:   0x88031edd45a595a4,
+			MethodID:      0,
+			InterfaceName: "collection.capnp:Puller",
+			MethodName:    "sync",
+		},
+	}
+	if params != nil {
+		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 1}
+		s.PlaceArgs = func(s capnp.Struct) error { return params(collection.Puller_sync_Params(s)) }
+	}
+
+	ans, release := capnp.Client(c).SendCall(ctx, s)
+	return collection.Puller_sync_Results_Future{Future: ans.Future()}, release
+
+}
+
+func (c UiView_Keyring) Key(ctx context.Context, params func(collection.Puller_key_Params) error) (collection.Puller_key_Results_Future, capnp.ReleaseFunc) {
+
+	s := capnp.Send{
+		Method: capnp.Method{
+			InterfaceID:   0x88031edd45a595a4,
+			MethodID:      1,
+			InterfaceName: "collection.capnp:Puller",
+			MethodName:    "key",
+		},
+	}
+	if params != nil {
+		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 1}
+		s.PlaceArgs = func(s capnp.Struct) error { return params(collection.Puller_key_Params(s)) }
+	}
+
+	ans, release := capnp.Client(c).SendCall(ctx, s)
+	return collection.Puller_key_Results_Future{Future: ans.Future()}, release
+
+}
+
+func (c UiView_Keyring) WaitStreaming() error {
+	return capnp.Client(c).WaitStreaming()
+}
+
+// String returns a string that identifies this capability for debugging
+// purposes.  Its format should not be depended on: in particular, it
+// should not be used to compare clients.  Use IsSame to compare clients
+// for equality.
+func (c UiView_Keyring) String() string {
+	return "UiView_Keyring(" + capnp.Client(c).String() + ")"
+}
+
+// AddRef creates a new Client that refers to the same capability as c.
+// If c is nil or has resolved to null, then AddRef returns nil.
+func (c UiView_Keyring) AddRef() UiView_Keyring {
+	return UiView_Keyring(capnp.Client(c).AddRef())
+}
+
+// Release releases a capability reference.  If this is the last
+// reference to the capability, then the underlying resources associated
+// with the capability will be released.
+//
+// Release will panic if c has already been released, but not if c is
+// nil or resolved to null.
+func (c UiView_Keyring) Release() {
+	capnp.Client(c).Release()
+}
+
+// Resolve blocks until the capability is fully resolved or the Context
+// expires.
+func (c UiView_Keyring) Resolve(ctx context.Context) error {
+	return capnp.Client(c).Resolve(ctx)
+}
+
+func (c UiView_Keyring) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Client(c).EncodeAsPtr(seg)
+}
+
+func (UiView_Keyring) DecodeFromPtr(p capnp.Ptr) UiView_Keyring {
+	return UiView_Keyring(capnp.Client{}.DecodeFromPtr(p))
+}
+
+// IsValid reports whether c is a valid reference to a capability.
+// A reference is invalid if it is nil, has resolved to null, or has
+// been released.
+func (c UiView_Keyring) IsValid() bool {
+	return capnp.Client(c).IsValid()
+}
+
+// IsSame reports whether c and other refer to a capability created by the
+// same call to NewClient.  This can return false negatives if c or other
+// are not fully resolved: use Resolve if this is an issue.  If either
+// c or other are released, then IsSame panics.
+func (c UiView_Keyring) IsSame(other UiView_Keyring) bool {
+	return capnp.Client(c).IsSame(capnp.Client(other))
+}
+
+// Update the flowcontrol.FlowLimiter used to manage flow control for
+// this client. This affects all future calls, but not calls already
+// waiting to send. Passing nil sets the value to flowcontrol.NopLimiter,
+// which is also the default.
+func (c UiView_Keyring) SetFlowLimiter(lim fc.FlowLimiter) {
+	capnp.Client(c).SetFlowLimiter(lim)
+}
+
+// Get the current flowcontrol.FlowLimiter used to manage flow control
+// for this client.
+func (c UiView_Keyring) GetFlowLimiter() fc.FlowLimiter {
+	return capnp.Client(c).GetFlowLimiter()
+}
+
+// A UiView_Keyring_Server is a UiView_Keyring with a local implementation.
+type UiView_Keyring_Server interface {
+	Attach(context.Context, UiView_Keyring_attach) error
+
+	Sync(context.Context, collection.Puller_sync) error
+
+	Key(context.Context, collection.Puller_key) error
+}
+
+// UiView_Keyring_NewServer creates a new Server from an implementation of UiView_Keyring_Server.
+func UiView_Keyring_NewServer(s UiView_Keyring_Server) *server.Server {
+	c, _ := s.(server.Shutdowner)
+	return server.New(UiView_Keyring_Methods(nil, s), s, c)
+}
+
+// UiView_Keyring_ServerToClient creates a new Client from an implementation of UiView_Keyring_Server.
+// The caller is responsible for calling Release on the returned Client.
+func UiView_Keyring_ServerToClient(s UiView_Keyring_Server) UiView_Keyring {
+	return UiView_Keyring(capnp.NewClient(UiView_Keyring_NewServer(s)))
+}
+
+// UiView_Keyring_Methods appends Methods to a slice that invoke the methods on s.
+// This can be used to create a more complicated Server.
+func UiView_Keyring_Methods(methods []server.Method, s UiView_Keyring_Server) []server.Method {
+	if cap(methods) == 0 {
+		methods = make([]server.Method, 0, 3)
+	}
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0xe38a747a26bc9a79,
+			MethodID:      0,
+			InterfaceName: "external.capnp:UiView.Keyring",
+			MethodName:    "attach",
+		},
+		Impl: func(ctx context.Context, call *server.Call) error {
+			return s.Attach(ctx, UiView_Keyring_attach{call})
+		},
+	})
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0x88031edd45a595a4,
+			MethodID:      0,
+			InterfaceName: "collection.capnp:Puller",
+			MethodName:    "sync",
+		},
+		Impl: func(ctx context.Context, call *server.Call) error {
+			return s.Sync(ctx, collection.Puller_sync{call})
+		},
+	})
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0x88031edd45a595a4,
+			MethodID:      1,
+			InterfaceName: "collection.capnp:Puller",
+			MethodName:    "key",
+		},
+		Impl: func(ctx context.Context, call *server.Call) error {
+			return s.Key(ctx, collection.Puller_key{call})
+		},
+	})
+
+	return methods
+}
+
+// UiView_Keyring_attach holds the state for a server call to UiView_Keyring.attach.
+// See server.Call for documentation.
+type UiView_Keyring_attach struct {
+	*server.Call
+}
+
+// Args returns the call's arguments.
+func (c UiView_Keyring_attach) Args() UiView_Keyring_attach_Params {
+	return UiView_Keyring_attach_Params(c.Call.Args())
+}
+
+// AllocResults allocates the results struct.
+func (c UiView_Keyring_attach) AllocResults() (UiView_Keyring_attach_Results, error) {
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UiView_Keyring_attach_Results(r), err
+}
+
+// UiView_Keyring_List is a list of UiView_Keyring.
+type UiView_Keyring_List = capnp.CapList[UiView_Keyring]
+
+// NewUiView_Keyring_List creates a new list of UiView_Keyring.
+func NewUiView_Keyring_List(s *capnp.Segment, sz int32) (UiView_Keyring_List, error) {
+	l, err := capnp.NewPointerList(s, sz)
+	return capnp.CapList[UiView_Keyring](l), err
+}
+
+type UiView_Keyring_attach_Params capnp.Struct
+
+// UiView_Keyring_attach_Params_TypeID is the unique identifier for the type UiView_Keyring_attach_Params.
+const UiView_Keyring_attach_Params_TypeID = 0xa97e44e1d89b7811
+
+func NewUiView_Keyring_attach_Params(s *capnp.Segment) (UiView_Keyring_attach_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UiView_Keyring_attach_Params(st), err
+}
+
+func NewRootUiView_Keyring_attach_Params(s *capnp.Segment) (UiView_Keyring_attach_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UiView_Keyring_attach_Params(st), err
+}
+
+func ReadRootUiView_Keyring_attach_Params(msg *capnp.Message) (UiView_Keyring_attach_Params, error) {
+	root, err := msg.Root()
+	return UiView_Keyring_attach_Params(root.Struct()), err
+}
+
+func (s UiView_Keyring_attach_Params) String() string {
+	str, _ := text.Marshal(0xa97e44e1d89b7811, capnp.Struct(s))
+	return str
+}
+
+func (s UiView_Keyring_attach_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UiView_Keyring_attach_Params) DecodeFromPtr(p capnp.Ptr) UiView_Keyring_attach_Params {
+	return UiView_Keyring_attach_Params(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UiView_Keyring_attach_Params) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UiView_Keyring_attach_Params) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UiView_Keyring_attach_Params) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UiView_Keyring_attach_Params) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s UiView_Keyring_attach_Params) Controller() UiView_Controller {
+	p, _ := capnp.Struct(s).Ptr(0)
+	return UiView_Controller(p.Interface().Client())
+}
+
+func (s UiView_Keyring_attach_Params) HasController() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s UiView_Keyring_attach_Params) SetController(v UiView_Controller) error {
+	if !v.IsValid() {
+		return capnp.Struct(s).SetPtr(0, capnp.Ptr{})
+	}
+	seg := s.Segment()
+	in := capnp.NewInterface(seg, seg.Message().CapTable().Add(capnp.Client(v)))
+	return capnp.Struct(s).SetPtr(0, in.ToPtr())
+}
+
+// UiView_Keyring_attach_Params_List is a list of UiView_Keyring_attach_Params.
+type UiView_Keyring_attach_Params_List = capnp.StructList[UiView_Keyring_attach_Params]
+
+// NewUiView_Keyring_attach_Params creates a new list of UiView_Keyring_attach_Params.
+func NewUiView_Keyring_attach_Params_List(s *capnp.Segment, sz int32) (UiView_Keyring_attach_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return capnp.StructList[UiView_Keyring_attach_Params](l), err
+}
+
+// UiView_Keyring_attach_Params_Future is a wrapper for a UiView_Keyring_attach_Params promised by a client call.
+type UiView_Keyring_attach_Params_Future struct{ *capnp.Future }
+
+func (f UiView_Keyring_attach_Params_Future) Struct() (UiView_Keyring_attach_Params, error) {
+	p, err := f.Future.Ptr()
+	return UiView_Keyring_attach_Params(p.Struct()), err
+}
+func (p UiView_Keyring_attach_Params_Future) Controller() UiView_Controller {
+	return UiView_Controller(p.Future.Field(0, nil).Client())
+}
+
+type UiView_Keyring_attach_Results capnp.Struct
+
+// UiView_Keyring_attach_Results_TypeID is the unique identifier for the type UiView_Keyring_attach_Results.
+const UiView_Keyring_attach_Results_TypeID = 0xbcc07e9ef0112f5c
+
+func NewUiView_Keyring_attach_Results(s *capnp.Segment) (UiView_Keyring_attach_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UiView_Keyring_attach_Results(st), err
+}
+
+func NewRootUiView_Keyring_attach_Results(s *capnp.Segment) (UiView_Keyring_attach_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return UiView_Keyring_attach_Results(st), err
+}
+
+func ReadRootUiView_Keyring_attach_Results(msg *capnp.Message) (UiView_Keyring_attach_Results, error) {
+	root, err := msg.Root()
+	return UiView_Keyring_attach_Results(root.Struct()), err
+}
+
+func (s UiView_Keyring_attach_Results) String() string {
+	str, _ := text.Marshal(0xbcc07e9ef0112f5c, capnp.Struct(s))
+	return str
+}
+
+func (s UiView_Keyring_attach_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (UiView_Keyring_attach_Results) DecodeFromPtr(p capnp.Ptr) UiView_Keyring_attach_Results {
+	return UiView_Keyring_attach_Results(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s UiView_Keyring_attach_Results) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s UiView_Keyring_attach_Results) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s UiView_Keyring_attach_Results) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s UiView_Keyring_attach_Results) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s UiView_Keyring_attach_Results) Error() (ApiError, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return ApiError(p.Struct()), err
+}
+
+func (s UiView_Keyring_attach_Results) HasError() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s UiView_Keyring_attach_Results) SetError(v ApiError) error {
+	return capnp.Struct(s).SetPtr(0, capnp.Struct(v).ToPtr())
+}
+
+// NewError sets the error field to a newly
+// allocated ApiError struct, preferring placement in s's segment.
+func (s UiView_Keyring_attach_Results) NewError() (ApiError, error) {
+	ss, err := NewApiError(capnp.Struct(s).Segment())
+	if err != nil {
+		return ApiError{}, err
+	}
+	err = capnp.Struct(s).SetPtr(0, capnp.Struct(ss).ToPtr())
+	return ss, err
+}
+
+// UiView_Keyring_attach_Results_List is a list of UiView_Keyring_attach_Results.
+type UiView_Keyring_attach_Results_List = capnp.StructList[UiView_Keyring_attach_Results]
+
+// NewUiView_Keyring_attach_Results creates a new list of UiView_Keyring_attach_Results.
+func NewUiView_Keyring_attach_Results_List(s *capnp.Segment, sz int32) (UiView_Keyring_attach_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return capnp.StructList[UiView_Keyring_attach_Results](l), err
+}
+
+// UiView_Keyring_attach_Results_Future is a wrapper for a UiView_Keyring_attach_Results promised by a client call.
+type UiView_Keyring_attach_Results_Future struct{ *capnp.Future }
+
+func (f UiView_Keyring_attach_Results_Future) Struct() (UiView_Keyring_attach_Results, error) {
+	p, err := f.Future.Ptr()
+	return UiView_Keyring_attach_Results(p.Struct()), err
+}
+func (p UiView_Keyring_attach_Results_Future) Error() ApiError_Future {
+	return ApiError_Future{Future: p.Future.Field(0, nil)}
+}
+
+type Notification capnp.Struct
+
+// Notification_TypeID is the unique identifier for the type Notification.
+const Notification_TypeID = 0xf02dc32fb84dbea9
+
+func NewNotification(s *capnp.Segment) (Notification, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 4})
+	return Notification(st), err
+}
+
+func NewRootNotification(s *capnp.Segment) (Notification, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 4})
+	return Notification(st), err
+}
+
+func ReadRootNotification(msg *capnp.Message) (Notification, error) {
+	root, err := msg.Root()
+	return Notification(root.Struct()), err
+}
+
+func (s Notification) String() string {
+	str, _ := text.Marshal(0xf02dc32fb84dbea9, capnp.Struct(s))
+	return str
+}
+
+func (s Notification) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (Notification) DecodeFromPtr(p capnp.Ptr) Notification {
+	return Notification(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s Notification) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s Notification) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s Notification) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s Notification) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s Notification) GrainId() (string, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.Text(), err
+}
+
+func (s Notification) HasGrainId() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s Notification) GrainIdBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s Notification) SetGrainId(v string) error {
+	return capnp.Struct(s).SetText(0, v)
+}
+
+func (s Notification) GrainTitle() (string, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return p.Text(), err
+}
+
+func (s Notification) HasGrainTitle() bool {
+	return capnp.Struct(s).HasPtr(1)
+}
+
+func (s Notification) GrainTitleBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return p.TextBytes(), err
+}
+
+func (s Notification) SetGrainTitle(v string) error {
+	return capnp.Struct(s).SetText(1, v)
+}
+
+func (s Notification) Path() (string, error) {
+	p, err := capnp.Struct(s).Ptr(2)
+	return p.Text(), err
+}
+
+func (s Notification) HasPath() bool {
+	return capnp.Struct(s).HasPtr(2)
+}
+
+func (s Notification) PathBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(2)
+	return p.TextBytes(), err
+}
+
+func (s Notification) SetPath(v string) error {
+	return capnp.Struct(s).SetText(2, v)
+}
+
+func (s Notification) Text() (string, error) {
+	p, err := capnp.Struct(s).Ptr(3)
+	return p.Text(), err
+}
+
+func (s Notification) HasText() bool {
+	return capnp.Struct(s).HasPtr(3)
+}
+
+func (s Notification) TextBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(3)
+	return p.TextBytes(), err
+}
+
+func (s Notification) SetText(v string) error {
+	return capnp.Struct(s).SetText(3, v)
+}
+
+func (s Notification) Time() int64 {
+	return int64(capnp.Struct(s).Uint64(0))
+}
+
+func (s Notification) SetTime(v int64) {
+	capnp.Struct(s).SetUint64(0, uint64(v))
+}
+
+func (s Notification) Read() bool {
+	return capnp.Struct(s).Bit(64)
+}
+
+func (s Notification) SetRead(v bool) {
+	capnp.Struct(s).SetBit(64, v)
+}
+
+// Notification_List is a list of Notification.
+type Notification_List = capnp.StructList[Notification]
+
+// NewNotification creates a new list of Notification.
+func NewNotification_List(s *capnp.Segment, sz int32) (Notification_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 16, PointerCount: 4}, sz)
+	return capnp.StructList[Notification](l), err
+}
+
+// Notification_Future is a wrapper for a Notification promised by a client call.
+type Notification_Future struct{ *capnp.Future }
+
+func (f Notification_Future) Struct() (Notification, error) {
+	p, err := f.Future.Ptr()
+	return Notification(p.Struct()), err
+}
+
+type Credential capnp.Struct
+
+// Credential_TypeID is the unique identifier for the type Credential.
+const Credential_TypeID = 0xae7109d77a5c5672
+
+func NewCredential(s *capnp.Segment) (Credential, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return Credential(st), err
+}
+
+func NewRootCredential(s *capnp.Segment) (Credential, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return Credential(st), err
+}
+
+func ReadRootCredential(msg *capnp.Message) (Credential, error) {
+	root, err := msg.Root()
+	return Credential(root.Struct()), err
+}
+
+func (s Credential) String() string {
+	str, _ := text.Marshal(0xae7109d77a5c5672, capnp.Struct(s))
+	return str
+}
+
+func (s Credential) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (Credential) DecodeFromPtr(p capnp.Ptr) Credential {
+	return Credential(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s Credential) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s Credential) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s Credential) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s Credential) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s Credential) Type() (string, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.Text(), err
+}
+
+func (s Credential) HasType() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s Credential) TypeBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s Credential) SetType(v string) error {
+	return capnp.Struct(s).SetText(0, v)
+}
+
+func (s Credential) ScopedId() (string, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return p.Text(), err
+}
+
+func (s Credential) HasScopedId() bool {
+	return capnp.Struct(s).HasPtr(1)
+}
+
+func (s Credential) ScopedIdBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return p.TextBytes(), err
+}
+
+func (s Credential) SetScopedId(v string) error {
+	return capnp.Struct(s).SetText(1, v)
+}
+
+// Credential_List is a list of Credential.
+type Credential_List = capnp.StructList[Credential]
+
+// NewCredential creates a new list of Credential.
+func NewCredential_List(s *capnp.Segment, sz int32) (Credential_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2}, sz)
+	return capnp.StructList[Credential](l), err
+}
+
+// Credential_Future is a wrapper for a Credential promised by a client call.
+type Credential_Future struct{ *capnp.Future }
+
+func (f Credential_Future) Struct() (Credential, error) {
+	p, err := f.Future.Ptr()
+	return Credential(p.Struct()), err
+}
+
+type Account capnp.Struct
+
+// Account_TypeID is the unique identifier for the type Account.
+const Account_TypeID = 0x850e687fe8633795
+
+func NewAccount(s *capnp.Segment) (Account, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 3})
+	return Account(st), err
+}
+
+func NewRootAccount(s *capnp.Segment) (Account, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 3})
+	return Account(st), err
+}
+
+func ReadRootAccount(msg *capnp.Message) (Account, error) {
+	root, err := msg.Root()
+	return Account(root.Struct()), err
+}
+
+func (s Account) String() string {
+	str, _ := text.Marshal(0x850e687fe8633795, capnp.Struct(s))
+	return str
+}
+
+func (s Account) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (Account) DecodeFromPtr(p capnp.Ptr) Account {
+	return Account(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s Account) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s Account) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s Account) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s Account) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s Account) Id() (string, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.Text(), err
+}
+
+func (s Account) HasId() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s Account) IdBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s Account) SetId(v string) error {
+	return capnp.Struct(s).SetText(0, v)
+}
+
+func (s Account) Role() (string, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return p.Text(), err
+}
+
+func (s Account) HasRole() bool {
+	return capnp.Struct(s).HasPtr(1)
+}
+
+func (s Account) RoleBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return p.TextBytes(), err
+}
+
+func (s Account) SetRole(v string) error {
+	return capnp.Struct(s).SetText(1, v)
+}
+
+func (s Account) Deactivated() bool {
+	return capnp.Struct(s).Bit(0)
+}
+
+func (s Account) SetDeactivated(v bool) {
+	capnp.Struct(s).SetBit(0, v)
+}
+
+func (s Account) Credentials() (Credential_List, error) {
+	p, err := capnp.Struct(s).Ptr(2)
+	return Credential_List(p.List()), err
+}
+
+func (s Account) HasCredentials() bool {
+	return capnp.Struct(s).HasPtr(2)
+}
+
+func (s Account) SetCredentials(v Credential_List) error {
+	return capnp.Struct(s).SetPtr(2, v.ToPtr())
+}
+
+// NewCredentials sets the credentials field to a newly
+// allocated Credential_List, preferring placement in s's segment.
+func (s Account) NewCredentials(n int32) (Credential_List, error) {
+	l, err := NewCredential_List(capnp.Struct(s).Segment(), n)
+	if err != nil {
+		return Credential_List{}, err
+	}
+	err = capnp.Struct(s).SetPtr(2, l.ToPtr())
+	return l, err
+}
+func (s Account) GrainCount() uint32 {
+	return capnp.Struct(s).Uint32(4)
+}
+
+func (s Account) SetGrainCount(v uint32) {
+	capnp.Struct(s).SetUint32(4, v)
+}
+
+func (s Account) StorageBytes() uint64 {
+	return capnp.Struct(s).Uint64(8)
+}
+
+func (s Account) SetStorageBytes(v uint64) {
+	capnp.Struct(s).SetUint64(8, v)
+}
+
+// Account_List is a list of Account.
+type Account_List = capnp.StructList[Account]
+
+// NewAccount creates a new list of Account.
+func NewAccount_List(s *capnp.Segment, sz int32) (Account_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 16, PointerCount: 3}, sz)
+	return capnp.StructList[Account](l), err
+}
+
+// Account_Future is a wrapper for a Account promised by a client call.
+type Account_Future struct{ *capnp.Future }
+
+func (f Account_Future) Struct() (Account, error) {
+	p, err := f.Future.Ptr()
+	return Account(p.Struct()), err
+}
+
+type AdminSession capnp.Client
+
+// AdminSession_TypeID is the unique identifier for the type AdminSession.
+const AdminSession_TypeID = 0x9d05d974c6d66002
+
+func (c AdminSession) ListAccounts(ctx context.Context, params func(AdminSession_listAccounts_Params) error) (AdminSession_listAccounts_Results_Future, capnp.ReleaseFunc) {
+
+	s := capnp.Send{
+		Method: capnp.Method{
+			InterfaceID:   0x9d05d974c6d66002,
+			MethodID:      0,
+			InterfaceName: "external.capnp:AdminSession",
+			MethodName:    "listAccounts",
+		},
+	}
+	if params != nil {
+		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 1}
+		s.PlaceArgs = func(s capnp.Struct) error { return params(AdminSession_listAccounts_Params(s)) }
+	}
+
+	ans, release := capnp.Client(c).SendCall(ctx, s)
+	return AdminSession_listAccounts_Results_Future{Future: ans.Future()}, release
+
+}
+
+func (c AdminSession) SetRole(ctx context.Context, params func(AdminSession_setRole_Params) error) (AdminSession_setRole_Results_Future, capnp.ReleaseFunc) {
+
+	s := capnp.Send{
+		Method: capnp.Method{
+			InterfaceID:   0x9d05d974c6d66002,
+			MethodID:      1,
+			InterfaceName: "external.capnp:AdminSession",
+			MethodName:    "setRole",
+		},
+	}
+	if params != nil {
+		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 2}
+		s.PlaceArgs = func(s capnp.Struct) error { return params(AdminSession_setRole_Params(s)) }
+	}
+
+	ans, release := capnp.Client(c).SendCall(ctx, s)
+	return AdminSession_setRole_Results_Future{Future: ans.Future()}, release
+
+}
+
+func (c AdminSession) SetDeactivated(ctx context.Context, params func(AdminSession_setDeactivated_Params) error) (AdminSession_setDeactivated_Results_Future, capnp.ReleaseFunc) {
+
+	s := capnp.Send{
+		Method: capnp.Method{
+			InterfaceID:   0x9d05d974c6d66002,
+			MethodID:      2,
+			InterfaceName: "external.capnp:AdminSession",
+			MethodName:    "setDeactivated",
+		},
+	}
+	if params != nil {
+		s.ArgsSize = capnp.ObjectSize{DataSize: 8, PointerCount: 1}
+		s.PlaceArgs = func(s capnp.Struct) error { return params(AdminSession_setDeactivated_Params(s)) }
+	}
+
+	ans, release := capnp.Client(c).SendCall(ctx, s)
+	return AdminSession_setDeactivated_Results_Future{Future: ans.Future()}, release
+
+}
+
+func (c AdminSession) CreateInvite(ctx context.Context, params func(AdminSession_createInvite_Params) error) (AdminSession_createInvite_Results_Future, capnp.ReleaseFunc) {
+
+	s := capnp.Send{
+		Method: capnp.Method{
+			InterfaceID:   0x9d05d974c6d66002,
+			MethodID:      3,
+			InterfaceName: "external.capnp:AdminSession",
+			MethodName:    "createInvite",
+		},
+	}
+	if params != nil {
+		s.ArgsSize = capnp.ObjectSize{DataSize: 16, PointerCount: 2}
+		s.PlaceArgs = func(s capnp.Struct) error { return params(AdminSession_createInvite_Params(s)) }
+	}
+
+	ans, release := capnp.Client(c).SendCall(ctx, s)
+	return AdminSession_createInvite_Results_Future{Future: ans.Future()}, release
+
+}
+
+func (c AdminSession) ListInvites(ctx context.Context, params func(AdminSession_listInvites_Params) error) (AdminSession_listInvites_Results_Future, capnp.ReleaseFunc) {
+
+	s := capnp.Send{
+		Method: capnp.Method{
+			InterfaceID:   0x9d05d974c6d66002,
+			MethodID:      4,
+			InterfaceName: "external.capnp:AdminSession",
+			MethodName:    "listInvites",
+		},
+	}
+	if params != nil {
+		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 1}
+		s.PlaceArgs = func(s capnp.Struct) error { return params(AdminSession_listInvites_Params(s)) }
+	}
+
+	ans, release := capnp.Client(c).SendCall(ctx, s)
+	return AdminSession_listInvites_Results_Future{Future: ans.Future()}, release
+
+}
+
+func (c AdminSession) DeleteInvite(ctx context.Context, params func(AdminSession_deleteInvite_Params) error) (AdminSession_deleteInvite_Results_Future, capnp.ReleaseFunc) {
+
+	s := capnp.Send{
+		Method: capnp.Method{
+			InterfaceID:   0x9d05d974c6d66002,
+			MethodID:      5,
+			InterfaceName: "external.capnp:AdminSession",
+			MethodName:    "deleteInvite",
+		},
+	}
+	if params != nil {
+		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 1}
+		s.PlaceArgs = func(s capnp.Struct) error { return params(AdminSession_deleteInvite_Params(s)) }
+	}
+
+	ans, release := capnp.Client(c).SendCall(ctx, s)
+	return AdminSession_deleteInvite_Results_Future{Future: ans.Future()}, release
+
+}
+
+func (c AdminSession) WaitStreaming() error {
+	return capnp.Client(c).WaitStreaming()
+}
+
+// String returns a string that identifies this capability for debugging
+// purposes.  Its format should not be depended on: in particular, it
+// should not be used to compare clients.  Use IsSame to compare clients
+// for equality.
+func (c AdminSession) String() string {
+	return "AdminSession(" + capnp.Client(c).String() + ")"
+}
+
+// AddRef creates a new Client that refers to the same capability as c.
+// If c is nil or has resolved to null, then AddRef returns nil.
+func (c AdminSession) AddRef() AdminSession {
+	return AdminSession(capnp.Client(c).AddRef())
+}
+
+// Release releases a capability reference.  If this is the last
+// reference to the capability, then the underlying resources associated
+// with the capability will be released.
+//
+// Release will panic if c has already been released, but not if c is
+// nil or resolved to null.
+func (c AdminSession) Release() {
+	capnp.Client(c).Release()
+}
+
+// Resolve blocks until the capability is fully resolved or the Context
+// expires.
+func (c AdminSession) Resolve(ctx context.Context) error {
+	return capnp.Client(c).Resolve(ctx)
+}
+
+func (c AdminSession) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Client(c).EncodeAsPtr(seg)
+}
+
+func (AdminSession) DecodeFromPtr(p capnp.Ptr) AdminSession {
+	return AdminSession(capnp.Client{}.DecodeFromPtr(p))
+}
+
+// IsValid reports whether c is a valid reference to a capability.
+// A reference is invalid if it is nil, has resolved to null, or has
+// been released.
+func (c AdminSession) IsValid() bool {
+	return capnp.Client(c).IsValid()
+}
+
+// IsSame reports whether c and other refer to a capability created by the
+// same call to NewClient.  This can return false negatives if c or other
+// are not fully resolved: use Resolve if this is an issue.  If either
+// c or other are released, then IsSame panics.
+func (c AdminSession) IsSame(other AdminSession) bool {
+	return capnp.Client(c).IsSame(capnp.Client(other))
+}
+
+// Update the flowcontrol.FlowLimiter used to manage flow control for
+// this client. This affects all future calls, but not calls already
+// waiting to send. Passing nil sets the value to flowcontrol.NopLimiter,
+// which is also the default.
+func (c AdminSession) SetFlowLimiter(lim fc.FlowLimiter) {
+	capnp.Client(c).SetFlowLimiter(lim)
+}
+
+// Get the current flowcontrol.FlowLimiter used to manage flow control
+// for this client.
+func (c AdminSession) GetFlowLimiter() fc.FlowLimiter {
+	return capnp.Client(c).GetFlowLimiter()
+}
+
+// A AdminSession_Server is a AdminSession with a local implementation.
+type AdminSession_Server interface {
+	ListAccounts(context.Context, AdminSession_listAccounts) error
+
+	SetRole(context.Context, AdminSession_setRole) error
+
+	SetDeactivated(context.Context, AdminSession_setDeactivated) error
+
+	CreateInvite(context.Context, AdminSession_createInvite) error
+
+	ListInvites(context.Context, AdminSession_listInvites) error
+
+	DeleteInvite(context.Context, AdminSession_deleteInvite) error
+}
+
+// AdminSession_NewServer creates a new Server from an implementation of AdminSession_Server.
+func AdminSession_NewServer(s AdminSession_Server) *server.Server {
+	c, _ := s.(server.Shutdowner)
+	return server.New(AdminSession_Methods(nil, s), s, c)
+}
+
+// AdminSession_ServerToClient creates a new Client from an implementation of AdminSession_Server.
+// The caller is responsible for calling Release on the returned Client.
+func AdminSession_ServerToClient(s AdminSession_Server) AdminSession {
+	return AdminSession(capnp.NewClient(AdminSession_NewServer(s)))
+}
+
+// AdminSession_Methods appends Methods to a slice that invoke the methods on s.
+// This can be used to create a more complicated Server.
+func AdminSession_Methods(methods []server.Method, s AdminSession_Server) []server.Method {
+	if cap(methods) == 0 {
+		methods = make([]server.Method, 0, 6)
+	}
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0x9d05d974c6d66002,
+			MethodID:      0,
+			InterfaceName: "external.capnp:AdminSession",
+			MethodName:    "listAccounts",
+		},
+		Impl: func(ctx context.Context, call *server.Call) error {
+			return s.ListAccounts(ctx, AdminSession_listAccounts{call})
+		},
+	})
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0x9d05d974c6d66002,
+			MethodID:      1,
+			InterfaceName: "external.capnp:AdminSession",
+			MethodName:    "setRole",
+		},
+		Impl: func(ctx context.Context, call *server.Call) error {
+			return s.SetRole(ctx, AdminSession_setRole{call})
+		},
+	})
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0x9d05d974c6d66002,
+			MethodID:      2,
+			InterfaceName: "external.capnp:AdminSession",
+			MethodName:    "setDeactivated",
+		},
+		Impl: func(ctx context.Context, call *server.Call) error {
+			return s.SetDeactivated(ctx, AdminSession_setDeactivated{call})
+		},
+	})
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0x9d05d974c6d66002,
+			MethodID:      3,
+			InterfaceName: "external.capnp:AdminSession",
+			MethodName:    "createInvite",
+		},
+		Impl: func(ctx context.Context, call *server.Call) error {
+			return s.CreateInvite(ctx, AdminSession_createInvite{call})
+		},
+	})
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0x9d05d974c6d66002,
+			MethodID:      4,
+			InterfaceName: "external.capnp:AdminSession",
+			MethodName:    "listInvites",
+		},
+		Impl: func(ctx context.Context, call *server.Call) error {
+			return s.ListInvites(ctx, AdminSession_listInvites{call})
+		},
+	})
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0x9d05d974c6d66002,
+			MethodID:      5,
+			InterfaceName: "external.capnp:AdminSession",
+			MethodName:    "deleteInvite",
+		},
+		Impl: func(ctx context.Context, call *server.Call) error {
+			return s.DeleteInvite(ctx, AdminSession_deleteInvite{call})
+		},
+	})
+
+	return methods
+}
+
+// AdminSession_listAccounts holds the state for a server call to AdminSession.listAccounts.
+// See server.Call for documentation.
+type AdminSession_listAccounts struct {
+	*server.Call
+}
+
+// Args returns the call's arguments.
+func (c AdminSession_listAccounts) Args() AdminSession_listAccounts_Params {
+	return AdminSession_listAccounts_Params(c.Call.Args())
+}
+
+// AllocResults allocates the results struct.
+func (c AdminSession_listAccounts) AllocResults() (AdminSession_listAccounts_Results, error) {
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return AdminSession_listAccounts_Results(r), err
+}
+
+// AdminSession_setRole holds the state for a server call to AdminSession.setRole.
+// See server.Call for documentation.
+type AdminSession_setRole struct {
+	*server.Call
+}
+
+// Args returns the call's arguments.
+func (c AdminSession_setRole) Args() AdminSession_setRole_Params {
+	return AdminSession_setRole_Params(c.Call.Args())
+}
+
+// AllocResults allocates the results struct.
+func (c AdminSession_setRole) AllocResults() (AdminSession_setRole_Results, error) {
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return AdminSession_setRole_Results(r), err
+}
+
+// AdminSession_setDeactivated holds the state for a server call to AdminSession.setDeactivated.
+// See server.Call for documentation.
+type AdminSession_setDeactivated struct {
+	*server.Call
+}
+
+// Args returns the call's arguments.
+func (c AdminSession_setDeactivated) Args() AdminSession_setDeactivated_Params {
+	return AdminSession_setDeactivated_Params(c.Call.Args())
+}
+
+// AllocResults allocates the results struct.
+func (c AdminSession_setDeactivated) AllocResults() (AdminSession_setDeactivated_Results, error) {
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return AdminSession_setDeactivated_Results(r), err
+}
+
+// AdminSession_createInvite holds the state for a server call to AdminSession.createInvite.
+// See server.Call for documentation.
+type AdminSession_createInvite struct {
+	*server.Call
+}
+
+// Args returns the call's arguments.
+func (c AdminSession_createInvite) Args() AdminSession_createInvite_Params {
+	return AdminSession_createInvite_Params(c.Call.Args())
+}
+
+// AllocResults allocates the results struct.
+func (c AdminSession_createInvite) AllocResults() (AdminSession_createInvite_Results, error) {
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 3})
+	return AdminSession_createInvite_Results(r), err
+}
+
+// AdminSession_listInvites holds the state for a server call to AdminSession.listInvites.
+// See server.Call for documentation.
+type AdminSession_listInvites struct {
+	*server.Call
+}
+
+// Args returns the call's arguments.
+func (c AdminSession_listInvites) Args() AdminSession_listInvites_Params {
+	return AdminSession_listInvites_Params(c.Call.Args())
+}
+
+// AllocResults allocates the results struct.
+func (c AdminSession_listInvites) AllocResults() (AdminSession_listInvites_Results, error) {
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return AdminSession_listInvites_Results(r), err
+}
+
+// AdminSession_deleteInvite holds the state for a server call to AdminSession.deleteInvite.
+// See server.Call for documentation.
+type AdminSession_deleteInvite struct {
+	*server.Call
+}
+
+// Args returns the call's arguments.
+func (c AdminSession_deleteInvite) Args() AdminSession_deleteInvite_Params {
+	return AdminSession_deleteInvite_Params(c.Call.Args())
+}
+
+// AllocResults allocates the results struct.
+func (c AdminSession_deleteInvite) AllocResults() (AdminSession_deleteInvite_Results, error) {
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return AdminSession_deleteInvite_Results(r), err
+}
+
+// AdminSession_List is a list of AdminSession.
+type AdminSession_List = capnp.CapList[AdminSession]
+
+// NewAdminSession_List creates a new list of AdminSession.
+func NewAdminSession_List(s *capnp.Segment, sz int32) (AdminSession_List, error) {
+	l, err := capnp.NewPointerList(s, sz)
+	return capnp.CapList[AdminSession](l), err
+}
+
+type AdminSession_listAccounts_Params capnp.Struct
+
+// AdminSession_listAccounts_Params_TypeID is the unique identifier for the type AdminSession_listAccounts_Params.
+const AdminSession_listAccounts_Params_TypeID = 0xe4e4568978138bb8
+
+func NewAdminSession_listAccounts_Params(s *capnp.Segment) (AdminSession_listAccounts_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return AdminSession_listAccounts_Params(st), err
+}
+
+func NewRootAdminSession_listAccounts_Params(s *capnp.Segment) (AdminSession_listAccounts_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return AdminSession_listAccounts_Params(st), err
+}
+
+func ReadRootAdminSession_listAccounts_Params(msg *capnp.Message) (AdminSession_listAccounts_Params, error) {
+	root, err := msg.Root()
+	return AdminSession_listAccounts_Params(root.Struct()), err
+}
+
+func (s AdminSession_listAccounts_Params) String() string {
+	str, _ := text.Marshal(0xe4e4568978138bb8, capnp.Struct(s))
+	return str
+}
+
+func (s AdminSession_listAccounts_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (AdminSession_listAccounts_Params) DecodeFromPtr(p capnp.Ptr) AdminSession_listAccounts_Params {
+	return AdminSession_listAccounts_Params(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s AdminSession_listAccounts_Params) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s AdminSession_listAccounts_Params) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s AdminSession_listAccounts_Params) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s AdminSession_listAccounts_Params) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s AdminSession_listAccounts_Params) Into() collection.Pusher {
+	p, _ := capnp.Struct(s).Ptr(0)
+	return collection.Pusher(p.Interface().Client())
+}
+
+func (s AdminSession_listAccounts_Params) HasInto() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s AdminSession_listAccounts_Params) SetInto(v collection.Pusher) error {
+	if !v.IsValid() {
+		return capnp.Struct(s).SetPtr(0, capnp.Ptr{})
+	}
+	seg := s.Segment()
+	in := capnp.NewInterface(seg, seg.Message().CapTable().Add(capnp.Client(v)))
+	return capnp.Struct(s).SetPtr(0, in.ToPtr())
+}
+
+// AdminSession_listAccounts_Params_List is a list of AdminSession_listAccounts_Params.
+type AdminSession_listAccounts_Params_List = capnp.StructList[AdminSession_listAccounts_Params]
+
+// NewAdminSession_listAccounts_Params creates a new list of AdminSession_listAccounts_Params.
+func NewAdminSession_listAccounts_Params_List(s *capnp.Segment, sz int32) (AdminSession_listAccounts_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return capnp.StructList[AdminSession_listAccounts_Params](l), err
+}
+
+// AdminSession_listAccounts_Params_Future is a wrapper for a AdminSession_listAccounts_Params promised by a client call.
+type AdminSession_listAccounts_Params_Future struct{ *capnp.Future }
+
+func (f AdminSession_listAccounts_Params_Future) Struct() (AdminSession_listAccounts_Params, error) {
+	p, err := f.Future.Ptr()
+	return AdminSession_listAccounts_Params(p.Struct()), err
+}
+func (p AdminSession_listAccounts_Params_Future) Into() collection.Pusher {
+	return collection.Pusher(p.Future.Field(0, nil).Client())
+}
+
+type AdminSession_listAccounts_Results capnp.Struct
+
+// AdminSession_listAccounts_Results_TypeID is the unique identifier for the type AdminSession_listAccounts_Results.
+const AdminSession_listAccounts_Results_TypeID = 0xe085e7b10c307cde
+
+func NewAdminSession_listAccounts_Results(s *capnp.Segment) (AdminSession_listAccounts_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return AdminSession_listAccounts_Results(st), err
+}
+
+func NewRootAdminSession_listAccounts_Results(s *capnp.Segment) (AdminSession_listAccounts_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return AdminSession_listAccounts_Results(st), err
+}
+
+func ReadRootAdminSession_listAccounts_Results(msg *capnp.Message) (AdminSession_listAccounts_Results, error) {
+	root, err := msg.Root()
+	return AdminSession_listAccounts_Results(root.Struct()), err
+}
+
+func (s AdminSession_listAccounts_Results) String() string {
+	str, _ := text.Marshal(0xe085e7b10c307cde, capnp.Struct(s))
+	return str
+}
+
+func (s AdminSession_listAccounts_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (AdminSession_listAccounts_Results) DecodeFromPtr(p capnp.Ptr) AdminSession_listAccounts_Results {
+	return AdminSession_listAccounts_Results(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s AdminSession_listAccounts_Results) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s AdminSession_listAccounts_Results) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s AdminSession_listAccounts_Results) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s AdminSession_listAccounts_Results) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s AdminSession_listAccounts_Results) Error() (ApiError, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return ApiError(p.Struct()), err
+}
+
+func (s AdminSession_listAccounts_Results) HasError() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s AdminSession_listAccounts_Results) SetError(v ApiError) error {
+	return capnp.Struct(s).SetPtr(0, capnp.Struct(v).ToPtr())
+}
+
+// NewError sets the error field to a newly
+// allocated ApiError struct, preferring placement in s's segment.
+func (s AdminSession_listAccounts_Results) NewError() (ApiError, error) {
+	ss, err := NewApiError(capnp.Struct(s).Segment())
+	if err != nil {
+		return ApiError{}, err
+	}
+	err = capnp.Struct(s).SetPtr(0, capnp.Struct(ss).ToPtr())
+	return ss, err
+}
+
+// AdminSession_listAccounts_Results_List is a list of AdminSession_listAccounts_Results.
+type AdminSession_listAccounts_Results_List = capnp.StructList[AdminSession_listAccounts_Results]
+
+// NewAdminSession_listAccounts_Results creates a new list of AdminSession_listAccounts_Results.
+func NewAdminSession_listAccounts_Results_List(s *capnp.Segment, sz int32) (AdminSession_listAccounts_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return capnp.StructList[AdminSession_listAccounts_Results](l), err
+}
+
+// AdminSession_listAccounts_Results_Future is a wrapper for a AdminSession_listAccounts_Results promised by a client call.
+type AdminSession_listAccounts_Results_Future struct{ *capnp.Future }
+
+func (f AdminSession_listAccounts_Results_Future) Struct() (AdminSession_listAccounts_Results, error) {
+	p, err := f.Future.Ptr()
+	return AdminSession_listAccounts_Results(p.Struct()), err
+}
+func (p AdminSession_listAccounts_Results_Future) Error() ApiError_Future {
+	return ApiError_Future{Future: p.Future.Field(0, nil)}
+}
+
+type AdminSession_setRole_Params capnp.Struct
+
+// AdminSession_setRole_Params_TypeID is the unique identifier for the type AdminSession_setRole_Params.
+const AdminSession_setRole_Params_TypeID = 0x8965c7443ba16da4
+
+func NewAdminSession_setRole_Params(s *capnp.Segment) (AdminSession_setRole_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return AdminSession_setRole_Params(st), err
+}
+
+func NewRootAdminSession_setRole_Params(s *capnp.Segment) (AdminSession_setRole_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return AdminSession_setRole_Params(st), err
+}
+
+func ReadRootAdminSession_setRole_Params(msg *capnp.Message) (AdminSession_setRole_Params, error) {
+	root, err := msg.Root()
+	return AdminSession_setRole_Params(root.Struct()), err
+}
+
+func (s AdminSession_setRole_Params) String() string {
+	str, _ := text.Marshal(0x8965c7443ba16da4, capnp.Struct(s))
+	return str
+}
+
+func (s AdminSession_setRole_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (AdminSession_setRole_Params) DecodeFromPtr(p capnp.Ptr) AdminSession_setRole_Params {
+	return AdminSession_setRole_Params(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s AdminSession_setRole_Params) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s AdminSession_setRole_Params) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s AdminSession_setRole_Params) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s AdminSession_setRole_Params) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s AdminSession_setRole_Params) AccountId() (string, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.Text(), err
+}
+
+func (s AdminSession_setRole_Params) HasAccountId() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s AdminSession_setRole_Params) AccountIdBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s AdminSession_setRole_Params) SetAccountId(v string) error {
+	return capnp.Struct(s).SetText(0, v)
+}
+
+func (s AdminSession_setRole_Params) Role() (string, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return p.Text(), err
+}
+
+func (s AdminSession_setRole_Params) HasRole() bool {
+	return capnp.Struct(s).HasPtr(1)
+}
+
+func (s AdminSession_setRole_Params) RoleBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return p.TextBytes(), err
+}
+
+func (s AdminSession_setRole_Params) SetRole(v string) error {
+	return capnp.Struct(s).SetText(1, v)
+}
+
+// AdminSession_setRole_Params_List is a list of AdminSession_setRole_Params.
+type AdminSession_setRole_Params_List = capnp.StructList[AdminSession_setRole_Params]
+
+// NewAdminSession_setRole_Params creates a new list of AdminSession_setRole_Params.
+func NewAdminSession_setRole_Params_List(s *capnp.Segment, sz int32) (AdminSession_setRole_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2}, sz)
+	return capnp.StructList[AdminSession_setRole_Params](l), err
+}
+
+// AdminSession_setRole_Params_Future is a wrapper for a AdminSession_setRole_Params promised by a client call.
+type AdminSession_setRole_Params_Future struct{ *capnp.Future }
+
+func (f AdminSession_setRole_Params_Future) Struct() (AdminSession_setRole_Params, error) {
+	p, err := f.Future.Ptr()
+	return AdminSession_setRole_Params(p.Struct()), err
+}
+
+type AdminSession_setRole_Results capnp.Struct
+
+// AdminSession_setRole_Results_TypeID is the unique identifier for the type AdminSession_setRole_Results.
+const AdminSession_setRole_Results_TypeID = 0x85321f85ba1cf627
+
+func NewAdminSession_setRole_Results(s *capnp.Segment) (AdminSession_setRole_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return AdminSession_setRole_Results(st), err
+}
+
+func NewRootAdminSession_setRole_Results(s *capnp.Segment) (AdminSession_setRole_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return AdminSession_setRole_Results(st), err
+}
+
+func ReadRootAdminSession_setRole_Results(msg *capnp.Message) (AdminSession_setRole_Results, error) {
+	root, err := msg.Root()
+	return AdminSession_setRole_Results(root.Struct()), err
+}
+
+func (s AdminSession_setRole_Results) String() string {
+	str, _ := text.Marshal(0x85321f85ba1cf627, capnp.Struct(s))
+	return str
+}
+
+func (s AdminSession_setRole_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (AdminSession_setRole_Results) DecodeFromPtr(p capnp.Ptr) AdminSession_setRole_Results {
+	return AdminSession_setRole_Results(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s AdminSession_setRole_Results) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s AdminSession_setRole_Results) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s AdminSession_setRole_Results) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s AdminSession_setRole_Results) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s AdminSession_setRole_Results) Error() (ApiError, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return ApiError(p.Struct()), err
+}
+
+func (s AdminSession_setRole_Results) HasError() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s AdminSession_setRole_Results) SetError(v ApiError) error {
+	return capnp.Struct(s).SetPtr(0, capnp.Struct(v).ToPtr())
+}
+
+// NewError sets the error field to a newly
+// allocated ApiError struct, preferring placement in s's segment.
+func (s AdminSession_setRole_Results) NewError() (ApiError, error) {
+	ss, err := NewApiError(capnp.Struct(s).Segment())
+	if err != nil {
+		return ApiError{}, err
+	}
+	err = capnp.Struct(s).SetPtr(0, capnp.Struct(ss).ToPtr())
+	return ss, err
+}
+
+// AdminSession_setRole_Results_List is a list of AdminSession_setRole_Results.
+type AdminSession_setRole_Results_List = capnp.StructList[AdminSession_setRole_Results]
+
+// NewAdminSession_setRole_Results creates a new list of AdminSession_setRole_Results.
+func NewAdminSession_setRole_Results_List(s *capnp.Segment, sz int32) (AdminSession_setRole_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return capnp.StructList[AdminSession_setRole_Results](l), err
+}
+
+// AdminSession_setRole_Results_Future is a wrapper for a AdminSession_setRole_Results promised by a client call.
+type AdminSession_setRole_Results_Future struct{ *capnp.Future }
+
+func (f AdminSession_setRole_Results_Future) Struct() (AdminSession_setRole_Results, error) {
+	p, err := f.Future.Ptr()
+	return AdminSession_setRole_Results(p.Struct()), err
+}
+func (p AdminSession_setRole_Results_Future) Error() ApiError_Future {
+	return ApiError_Future{Future: p.Future.Field(0, nil)}
+}
+
+type AdminSession_setDeactivated_Params capnp.Struct
+
+// AdminSession_setDeactivated_Params_TypeID is the unique identifier for the type AdminSession_setDeactivated_Params.
+const AdminSession_setDeactivated_Params_TypeID = 0xd1a7b9909662bd69
+
+func NewAdminSession_setDeactivated_Params(s *capnp.Segment) (AdminSession_setDeactivated_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1})
+	return AdminSession_setDeactivated_Params(st), err
+}
+
+func NewRootAdminSession_setDeactivated_Params(s *capnp.Segment) (AdminSession_setDeactivated_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1})
+	return AdminSession_setDeactivated_Params(st), err
+}
+
+func ReadRootAdminSession_setDeactivated_Params(msg *capnp.Message) (AdminSession_setDeactivated_Params, error) {
+	root, err := msg.Root()
+	return AdminSession_setDeactivated_Params(root.Struct()), err
+}
+
+func (s AdminSession_setDeactivated_Params) String() string {
+	str, _ := text.Marshal(0xd1a7b9909662bd69, capnp.Struct(s))
+	return str
+}
+
+func (s AdminSession_setDeactivated_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (AdminSession_setDeactivated_Params) DecodeFromPtr(p capnp.Ptr) AdminSession_setDeactivated_Params {
+	return AdminSession_setDeactivated_Params(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s AdminSession_setDeactivated_Params) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s AdminSession_setDeactivated_Params) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s AdminSession_setDeactivated_Params) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s AdminSession_setDeactivated_Params) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s AdminSession_setDeactivated_Params) AccountId() (string, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.Text(), err
+}
+
+func (s AdminSession_setDeactivated_Params) HasAccountId() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s AdminSession_setDeactivated_Params) AccountIdBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s AdminSession_setDeactivated_Params) SetAccountId(v string) error {
+	return capnp.Struct(s).SetText(0, v)
+}
+
+func (s AdminSession_setDeactivated_Params) Deactivated() bool {
+	return capnp.Struct(s).Bit(0)
+}
+
+func (s AdminSession_setDeactivated_Params) SetDeactivated(v bool) {
+	capnp.Struct(s).SetBit(0, v)
+}
+
+// AdminSession_setDeactivated_Params_List is a list of AdminSession_setDeactivated_Params.
+type AdminSession_setDeactivated_Params_List = capnp.StructList[AdminSession_setDeactivated_Params]
+
+// NewAdminSession_setDeactivated_Params creates a new list of AdminSession_setDeactivated_Params.
+func NewAdminSession_setDeactivated_Params_List(s *capnp.Segment, sz int32) (AdminSession_setDeactivated_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1}, sz)
+	return capnp.StructList[AdminSession_setDeactivated_Params](l), err
+}
+
+// AdminSession_setDeactivated_Params_Future is a wrapper for a AdminSession_setDeactivated_Params promised by a client call.
+type AdminSession_setDeactivated_Params_Future struct{ *capnp.Future }
+
+func (f AdminSession_setDeactivated_Params_Future) Struct() (AdminSession_setDeactivated_Params, error) {
+	p, err := f.Future.Ptr()
+	return AdminSession_setDeactivated_Params(p.Struct()), err
+}
+
+type AdminSession_setDeactivated_Results capnp.Struct
+
+// AdminSession_setDeactivated_Results_TypeID is the unique identifier for the type AdminSession_setDeactivated_Results.
+const AdminSession_setDeactivated_Results_TypeID = 0xceccc4ee36076403
+
+func NewAdminSession_setDeactivated_Results(s *capnp.Segment) (AdminSession_setDeactivated_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return AdminSession_setDeactivated_Results(st), err
+}
+
+func NewRootAdminSession_setDeactivated_Results(s *capnp.Segment) (AdminSession_setDeactivated_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return AdminSession_setDeactivated_Results(st), err
+}
+
+func ReadRootAdminSession_setDeactivated_Results(msg *capnp.Message) (AdminSession_setDeactivated_Results, error) {
+	root, err := msg.Root()
+	return AdminSession_setDeactivated_Results(root.Struct()), err
+}
+
+func (s AdminSession_setDeactivated_Results) String() string {
+	str, _ := text.Marshal(0xceccc4ee36076403, capnp.Struct(s))
+	return str
+}
+
+func (s AdminSession_setDeactivated_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (AdminSession_setDeactivated_Results) DecodeFromPtr(p capnp.Ptr) AdminSession_setDeactivated_Results {
+	return AdminSession_setDeactivated_Results(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s AdminSession_setDeactivated_Results) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s AdminSession_setDeactivated_Results) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s AdminSession_setDeactivated_Results) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s AdminSession_setDeactivated_Results) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s AdminSession_setDeactivated_Results) Error() (ApiError, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return ApiError(p.Struct()), err
+}
+
+func (s AdminSession_setDeactivated_Results) HasError() bool {
+	return capnp.Struct(s).HasPtr(0)
 }
 
-// UserSession_listPackages holds the state for a server call to UserSession.listPackages.
-// See server.Call for documentation.
-type UserSession_listPackages struct {
-	*server.Call
+func (s AdminSession_setDeactivated_Results) SetError(v ApiError) error {
+	return capnp.Struct(s).SetPtr(0, capnp.Struct(v).ToPtr())
 }
 
-// Args returns the call's arguments.
-func (c UserSession_listPackages) Args() UserSession_listPackages_Params {
-	return UserSession_listPackages_Params(c.Call.Args())
+// NewError sets the error field to a newly
+// allocated ApiError struct, preferring placement in s's segment.
+func (s AdminSession_setDeactivated_Results) NewError() (ApiError, error) {
+	ss, err := NewApiError(capnp.Struct(s).Segment())
+	if err != nil {
+		return ApiError{}, err
+	}
+	err = capnp.Struct(s).SetPtr(0, capnp.Struct(ss).ToPtr())
+	return ss, err
 }
 
-// AllocResults allocates the results struct.
-func (c UserSession_listPackages) AllocResults() (UserSession_listPackages_Results, error) {
-	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 0})
-	return UserSession_listPackages_Results(r), err
+// AdminSession_setDeactivated_Results_List is a list of AdminSession_setDeactivated_Results.
+type AdminSession_setDeactivated_Results_List = capnp.StructList[AdminSession_setDeactivated_Results]
+
+// NewAdminSession_setDeactivated_Results creates a new list of AdminSession_setDeactivated_Results.
+func NewAdminSession_setDeactivated_Results_List(s *capnp.Segment, sz int32) (AdminSession_setDeactivated_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return capnp.StructList[AdminSession_setDeactivated_Results](l), err
 }
 
-// UserSession_List is a list of UserSession.
-type UserSession_List = capnp.CapList[UserSession]
+// AdminSession_setDeactivated_Results_Future is a wrapper for a AdminSession_setDeactivated_Results promised by a client call.
+type AdminSession_setDeactivated_Results_Future struct{ *capnp.Future }
 
-// NewUserSession_List creates a new list of UserSession.
-func NewUserSession_List(s *capnp.Segment, sz int32) (UserSession_List, error) {
-	l, err := capnp.NewPointerList(s, sz)
-	return capnp.CapList[UserSession](l), err
+func (f AdminSession_setDeactivated_Results_Future) Struct() (AdminSession_setDeactivated_Results, error) {
+	p, err := f.Future.Ptr()
+	return AdminSession_setDeactivated_Results(p.Struct()), err
+}
+func (p AdminSession_setDeactivated_Results_Future) Error() ApiError_Future {
+	return ApiError_Future{Future: p.Future.Field(0, nil)}
 }
 
-type UserSession_installPackage_Params capnp.Struct
+type AdminSession_createInvite_Params capnp.Struct
 
-// UserSession_installPackage_Params_TypeID is the unique identifier for the type UserSession_installPackage_Params.
-const UserSession_installPackage_Params_TypeID = 0xf8dcf7451554118b
+// AdminSession_createInvite_Params_TypeID is the unique identifier for the type AdminSession_createInvite_Params.
+const AdminSession_createInvite_Params_TypeID = 0xb3e01d65b61c465c
 
-func NewUserSession_installPackage_Params(s *capnp.Segment) (UserSession_installPackage_Params, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
-	return UserSession_installPackage_Params(st), err
+func NewAdminSession_createInvite_Params(s *capnp.Segment) (AdminSession_createInvite_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 2})
+	return AdminSession_createInvite_Params(st), err
 }
 
-func NewRootUserSession_installPackage_Params(s *capnp.Segment) (UserSession_installPackage_Params, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
-	return UserSession_installPackage_Params(st), err
+func NewRootAdminSession_createInvite_Params(s *capnp.Segment) (AdminSession_createInvite_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 2})
+	return AdminSession_createInvite_Params(st), err
 }
 
-func ReadRootUserSession_installPackage_Params(msg *capnp.Message) (UserSession_installPackage_Params, error) {
+func ReadRootAdminSession_createInvite_Params(msg *capnp.Message) (AdminSession_createInvite_Params, error) {
 	root, err := msg.Root()
-	return UserSession_installPackage_Params(root.Struct()), err
+	return AdminSession_createInvite_Params(root.Struct()), err
 }
 
-func (s UserSession_installPackage_Params) String() string {
-	str, _ := text.Marshal(0xf8dcf7451554118b, capnp.Struct(s))
+func (s AdminSession_createInvite_Params) String() string {
+	str, _ := text.Marshal(0xb3e01d65b61c465c, capnp.Struct(s))
 	return str
 }
 
-func (s UserSession_installPackage_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+func (s AdminSession_createInvite_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
 	return capnp.Struct(s).EncodeAsPtr(seg)
 }
 
-func (UserSession_installPackage_Params) DecodeFromPtr(p capnp.Ptr) UserSession_installPackage_Params {
-	return UserSession_installPackage_Params(capnp.Struct{}.DecodeFromPtr(p))
+func (AdminSession_createInvite_Params) DecodeFromPtr(p capnp.Ptr) AdminSession_createInvite_Params {
+	return AdminSession_createInvite_Params(capnp.Struct{}.DecodeFromPtr(p))
 }
 
-func (s UserSession_installPackage_Params) ToPtr() capnp.Ptr {
+func (s AdminSession_createInvite_Params) ToPtr() capnp.Ptr {
 	return capnp.Struct(s).ToPtr()
 }
-func (s UserSession_installPackage_Params) IsValid() bool {
+func (s AdminSession_createInvite_Params) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
 
-func (s UserSession_installPackage_Params) Message() *capnp.Message {
+func (s AdminSession_createInvite_Params) Message() *capnp.Message {
 	return capnp.Struct(s).Message()
 }
 
-func (s UserSession_installPackage_Params) Segment() *capnp.Segment {
+func (s AdminSession_createInvite_Params) Segment() *capnp.Segment {
 	return capnp.Struct(s).Segment()
 }
+func (s AdminSession_createInvite_Params) Role() (string, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.Text(), err
+}
 
-// UserSession_installPackage_Params_List is a list of UserSession_installPackage_Params.
-type UserSession_installPackage_Params_List = capnp.StructList[UserSession_installPackage_Params]
+func (s AdminSession_createInvite_Params) HasRole() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
 
-// NewUserSession_installPackage_Params creates a new list of UserSession_installPackage_Params.
-func NewUserSession_installPackage_Params_List(s *capnp.Segment, sz int32) (UserSession_installPackage_Params_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0}, sz)
-	return capnp.StructList[UserSession_installPackage_Params](l), err
+func (s AdminSession_createInvite_Params) RoleBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.TextBytes(), err
 }
 
-// UserSession_installPackage_Params_Future is a wrapper for a UserSession_installPackage_Params promised by a client call.
-type UserSession_installPackage_Params_Future struct{ *capnp.Future }
+func (s AdminSession_createInvite_Params) SetRole(v string) error {
+	return capnp.Struct(s).SetText(0, v)
+}
 
-func (f UserSession_installPackage_Params_Future) Struct() (UserSession_installPackage_Params, error) {
+func (s AdminSession_createInvite_Params) MaxUses() uint32 {
+	return capnp.Struct(s).Uint32(0)
+}
+
+func (s AdminSession_createInvite_Params) SetMaxUses(v uint32) {
+	capnp.Struct(s).SetUint32(0, v)
+}
+
+func (s AdminSession_createInvite_Params) Expires() int64 {
+	return int64(capnp.Struct(s).Uint64(8))
+}
+
+func (s AdminSession_createInvite_Params) SetExpires(v int64) {
+	capnp.Struct(s).SetUint64(8, uint64(v))
+}
+
+func (s AdminSession_createInvite_Params) Note() (string, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return p.Text(), err
+}
+
+func (s AdminSession_createInvite_Params) HasNote() bool {
+	return capnp.Struct(s).HasPtr(1)
+}
+
+func (s AdminSession_createInvite_Params) NoteBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return p.TextBytes(), err
+}
+
+func (s AdminSession_createInvite_Params) SetNote(v string) error {
+	return capnp.Struct(s).SetText(1, v)
+}
+
+// AdminSession_createInvite_Params_List is a list of AdminSession_createInvite_Params.
+type AdminSession_createInvite_Params_List = capnp.StructList[AdminSession_createInvite_Params]
+
+// NewAdminSession_createInvite_Params creates a new list of AdminSession_createInvite_Params.
+func NewAdminSession_createInvite_Params_List(s *capnp.Segment, sz int32) (AdminSession_createInvite_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 16, PointerCount: 2}, sz)
+	return capnp.StructList[AdminSession_createInvite_Params](l), err
+}
+
+// AdminSession_createInvite_Params_Future is a wrapper for a AdminSession_createInvite_Params promised by a client call.
+type AdminSession_createInvite_Params_Future struct{ *capnp.Future }
+
+func (f AdminSession_createInvite_Params_Future) Struct() (AdminSession_createInvite_Params, error) {
 	p, err := f.Future.Ptr()
-	return UserSession_installPackage_Params(p.Struct()), err
+	return AdminSession_createInvite_Params(p.Struct()), err
 }
 
-type UserSession_installPackage_Results capnp.Struct
+type AdminSession_createInvite_Results capnp.Struct
 
-// UserSession_installPackage_Results_TypeID is the unique identifier for the type UserSession_installPackage_Results.
-const UserSession_installPackage_Results_TypeID = 0xc4028bdb9c509747
+// AdminSession_createInvite_Results_TypeID is the unique identifier for the type AdminSession_createInvite_Results.
+const AdminSession_createInvite_Results_TypeID = 0xdbb3121eba48f6e4
 
-func NewUserSession_installPackage_Results(s *capnp.Segment) (UserSession_installPackage_Results, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
-	return UserSession_installPackage_Results(st), err
+func NewAdminSession_createInvite_Results(s *capnp.Segment) (AdminSession_createInvite_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 3})
+	return AdminSession_createInvite_Results(st), err
 }
 
-func NewRootUserSession_installPackage_Results(s *capnp.Segment) (UserSession_installPackage_Results, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
-	return UserSession_installPackage_Results(st), err
+func NewRootAdminSession_createInvite_Results(s *capnp.Segment) (AdminSession_createInvite_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 3})
+	return AdminSession_createInvite_Results(st), err
 }
 
-func ReadRootUserSession_installPackage_Results(msg *capnp.Message) (UserSession_installPackage_Results, error) {
+func ReadRootAdminSession_createInvite_Results(msg *capnp.Message) (AdminSession_createInvite_Results, error) {
 	root, err := msg.Root()
-	return UserSession_installPackage_Results(root.Struct()), err
+	return AdminSession_createInvite_Results(root.Struct()), err
 }
 
-func (s UserSession_installPackage_Results) String() string {
-	str, _ := text.Marshal(0xc4028bdb9c509747, capnp.Struct(s))
+func (s AdminSession_createInvite_Results) String() string {
+	str, _ := text.Marshal(0xdbb3121eba48f6e4, capnp.Struct(s))
 	return str
 }
 
-func (s UserSession_installPackage_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+func (s AdminSession_createInvite_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
 	return capnp.Struct(s).EncodeAsPtr(seg)
 }
 
-func (UserSession_installPackage_Results) DecodeFromPtr(p capnp.Ptr) UserSession_installPackage_Results {
-	return UserSession_installPackage_Results(capnp.Struct{}.DecodeFromPtr(p))
+func (AdminSession_createInvite_Results) DecodeFromPtr(p capnp.Ptr) AdminSession_createInvite_Results {
+	return AdminSession_createInvite_Results(capnp.Struct{}.DecodeFromPtr(p))
 }
 
-func (s UserSession_installPackage_Results) ToPtr() capnp.Ptr {
+func (s AdminSession_createInvite_Results) ToPtr() capnp.Ptr {
 	return capnp.Struct(s).ToPtr()
 }
-func (s UserSession_installPackage_Results) IsValid() bool {
+func (s AdminSession_createInvite_Results) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
 
-func (s UserSession_installPackage_Results) Message() *capnp.Message {
+func (s AdminSession_createInvite_Results) Message() *capnp.Message {
 	return capnp.Struct(s).Message()
 }
 
-func (s UserSession_installPackage_Results) Segment() *capnp.Segment {
+func (s AdminSession_createInvite_Results) Segment() *capnp.Segment {
 	return capnp.Struct(s).Segment()
 }
-func (s UserSession_installPackage_Results) Stream() Package_InstallStream {
-	p, _ := capnp.Struct(s).Ptr(0)
-	return Package_InstallStream(p.Interface().Client())
+func (s AdminSession_createInvite_Results) Id() (string, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.Text(), err
 }
 
-func (s UserSession_installPackage_Results) HasStream() bool {
+func (s AdminSession_createInvite_Results) HasId() bool {
 	return capnp.Struct(s).HasPtr(0)
 }
 
-func (s UserSession_installPackage_Results) SetStream(v Package_InstallStream) error {
-	if !v.IsValid() {
-		return capnp.Struct(s).SetPtr(0, capnp.Ptr{})
+func (s AdminSession_createInvite_Results) IdBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s AdminSession_createInvite_Results) SetId(v string) error {
+	return capnp.Struct(s).SetText(0, v)
+}
+
+func (s AdminSession_createInvite_Results) Url() (string, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return p.Text(), err
+}
+
+func (s AdminSession_createInvite_Results) HasUrl() bool {
+	return capnp.Struct(s).HasPtr(1)
+}
+
+func (s AdminSession_createInvite_Results) UrlBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return p.TextBytes(), err
+}
+
+func (s AdminSession_createInvite_Results) SetUrl(v string) error {
+	return capnp.Struct(s).SetText(1, v)
+}
+
+func (s AdminSession_createInvite_Results) Error() (ApiError, error) {
+	p, err := capnp.Struct(s).Ptr(2)
+	return ApiError(p.Struct()), err
+}
+
+func (s AdminSession_createInvite_Results) HasError() bool {
+	return capnp.Struct(s).HasPtr(2)
+}
+
+func (s AdminSession_createInvite_Results) SetError(v ApiError) error {
+	return capnp.Struct(s).SetPtr(2, capnp.Struct(v).ToPtr())
+}
+
+// NewError sets the error field to a newly
+// allocated ApiError struct, preferring placement in s's segment.
+func (s AdminSession_createInvite_Results) NewError() (ApiError, error) {
+	ss, err := NewApiError(capnp.Struct(s).Segment())
+	if err != nil {
+		return ApiError{}, err
 	}
-	seg := s.Segment()
-	in := capnp.NewInterface(seg, seg.Message().CapTable().Add(capnp.Client(v)))
-	return capnp.Struct(s).SetPtr(0, in.ToPtr())
+	err = capnp.Struct(s).SetPtr(2, capnp.Struct(ss).ToPtr())
+	return ss, err
 }
 
-// UserSession_installPackage_Results_List is a list of UserSession_installPackage_Results.
-type UserSession_installPackage_Results_List = capnp.StructList[UserSession_installPackage_Results]
+// AdminSession_createInvite_Results_List is a list of AdminSession_createInvite_Results.
+type AdminSession_createInvite_Results_List = capnp.StructList[AdminSession_createInvite_Results]
 
-// NewUserSession_installPackage_Results creates a new list of UserSession_installPackage_Results.
-func NewUserSession_installPackage_Results_List(s *capnp.Segment, sz int32) (UserSession_installPackage_Results_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
-	return capnp.StructList[UserSession_installPackage_Results](l), err
+// NewAdminSession_createInvite_Results creates a new list of AdminSession_createInvite_Results.
+func NewAdminSession_createInvite_Results_List(s *capnp.Segment, sz int32) (AdminSession_createInvite_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 3}, sz)
+	return capnp.StructList[AdminSession_createInvite_Results](l), err
 }
 
-// UserSession_installPackage_Results_Future is a wrapper for a UserSession_installPackage_Results promised by a client call.
-type UserSession_installPackage_Results_Future struct{ *capnp.Future }
+// AdminSession_createInvite_Results_Future is a wrapper for a AdminSession_createInvite_Results promised by a client call.
+type AdminSession_createInvite_Results_Future struct{ *capnp.Future }
 
-func (f UserSession_installPackage_Results_Future) Struct() (UserSession_installPackage_Results, error) {
+func (f AdminSession_createInvite_Results_Future) Struct() (AdminSession_createInvite_Results, error) {
 	p, err := f.Future.Ptr()
-	return UserSession_installPackage_Results(p.Struct()), err
+	return AdminSession_createInvite_Results(p.Struct()), err
 }
-func (p UserSession_installPackage_Results_Future) Stream() Package_InstallStream {
-	return Package_InstallStream(p.Future.Field(0, nil).Client())
+func (p AdminSession_createInvite_Results_Future) Error() ApiError_Future {
+	return ApiError_Future{Future: p.Future.Field(2, nil)}
 }
 
-type UserSession_listPackages_Params capnp.Struct
+type AdminSession_listInvites_Params capnp.Struct
 
-// UserSession_listPackages_Params_TypeID is the unique identifier for the type UserSession_listPackages_Params.
-const UserSession_listPackages_Params_TypeID = 0xcc3b81b565529dc3
+// AdminSession_listInvites_Params_TypeID is the unique identifier for the type AdminSession_listInvites_Params.
+const AdminSession_listInvites_Params_TypeID = 0x9b5f616a2bd490cf
 
-func NewUserSession_listPackages_Params(s *capnp.Segment) (UserSession_listPackages_Params, error) {
+func NewAdminSession_listInvites_Params(s *capnp.Segment) (AdminSession_listInvites_Params, error) {
 	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
-	return UserSession_listPackages_Params(st), err
+	return AdminSession_listInvites_Params(st), err
 }
 
-func NewRootUserSession_listPackages_Params(s *capnp.Segment) (UserSession_listPackages_Params, error) {
+func NewRootAdminSession_listInvites_Params(s *capnp.Segment) (AdminSession_listInvites_Params, error) {
 	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
-	return UserSession_listPackages_Params(st), err
+	return AdminSession_listInvites_Params(st), err
 }
 
-func ReadRootUserSession_listPackages_Params(msg *capnp.Message) (UserSession_listPackages_Params, error) {
+func ReadRootAdminSession_listInvites_Params(msg *capnp.Message) (AdminSession_listInvites_Params, error) {
 	root, err := msg.Root()
-	return UserSession_listPackages_Params(root.Struct()), err
+	return AdminSession_listInvites_Params(root.Struct()), err
 }
 
-func (s UserSession_listPackages_Params) String() string {
-	str, _ := text.Marshal(0xcc3b81b565529dc3, capnp.Struct(s))
+func (s AdminSession_listInvites_Params) String() string {
+	str, _ := text.Marshal(0x9b5f616a2bd490cf, capnp.Struct(s))
 	return str
 }
 
-func (s UserSession_listPackages_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+func (s AdminSession_listInvites_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
 	return capnp.Struct(s).EncodeAsPtr(seg)
 }
 
-func (UserSession_listPackages_Params) DecodeFromPtr(p capnp.Ptr) UserSession_listPackages_Params {
-	return UserSession_listPackages_Params(capnp.Struct{}.DecodeFromPtr(p))
+func (AdminSession_listInvites_Params) DecodeFromPtr(p capnp.Ptr) AdminSession_listInvites_Params {
+	return AdminSession_listInvites_Params(capnp.Struct{}.DecodeFromPtr(p))
 }
 
-func (s UserSession_listPackages_Params) ToPtr() capnp.Ptr {
+func (s AdminSession_listInvites_Params) ToPtr() capnp.Ptr {
 	return capnp.Struct(s).ToPtr()
 }
-func (s UserSession_listPackages_Params) IsValid() bool {
+func (s AdminSession_listInvites_Params) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
 
-func (s UserSession_listPackages_Params) Message() *capnp.Message {
+func (s AdminSession_listInvites_Params) Message() *capnp.Message {
 	return capnp.Struct(s).Message()
 }
 
-func (s UserSession_listPackages_Params) Segment() *capnp.Segment {
+func (s AdminSession_listInvites_Params) Segment() *capnp.Segment {
 	return capnp.Struct(s).Segment()
 }
-func (s UserSession_listPackages_Params) Into() collection.Pusher {
+func (s AdminSession_listInvites_Params) Into() collection.Pusher {
 	p, _ := capnp.Struct(s).Ptr(0)
 	return collection.Pusher(p.Interface().Client())
 }
 
-func (s UserSession_listPackages_Params) HasInto() bool {
+func (s AdminSession_listInvites_Params) HasInto() bool {
 	return capnp.Struct(s).HasPtr(0)
 }
 
-func (s UserSession_listPackages_Params) SetInto(v collection.Pusher) error {
+func (s AdminSession_listInvites_Params) SetInto(v collection.Pusher) error {
 	if !v.IsValid() {
 		return capnp.Struct(s).SetPtr(0, capnp.Ptr{})
 	}
@@ -2715,671 +8607,687 @@ func (s UserSession_listPackages_Params) SetInto(v collection.Pusher) error {
 	return capnp.Struct(s).SetPtr(0, in.ToPtr())
 }
 
-// UserSession_listPackages_Params_List is a list of UserSession_listPackages_Params.
-type UserSession_listPackages_Params_List = capnp.StructList[UserSession_listPackages_Params]
+// AdminSession_listInvites_Params_List is a list of AdminSession_listInvites_Params.
+type AdminSession_listInvites_Params_List = capnp.StructList[AdminSession_listInvites_Params]
 
-// NewUserSession_listPackages_Params creates a new list of UserSession_listPackages_Params.
-func NewUserSession_listPackages_Params_List(s *capnp.Segment, sz int32) (UserSession_listPackages_Params_List, error) {
+// NewAdminSession_listInvites_Params creates a new list of AdminSession_listInvites_Params.
+func NewAdminSession_listInvites_Params_List(s *capnp.Segment, sz int32) (AdminSession_listInvites_Params_List, error) {
 	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
-	return capnp.StructList[UserSession_listPackages_Params](l), err
+	return capnp.StructList[AdminSession_listInvites_Params](l), err
 }
 
-// UserSession_listPackages_Params_Future is a wrapper for a UserSession_listPackages_Params promised by a client call.
-type UserSession_listPackages_Params_Future struct{ *capnp.Future }
+// AdminSession_listInvites_Params_Future is a wrapper for a AdminSession_listInvites_Params promised by a client call.
+type AdminSession_listInvites_Params_Future struct{ *capnp.Future }
 
-func (f UserSession_listPackages_Params_Future) Struct() (UserSession_listPackages_Params, error) {
+func (f AdminSession_listInvites_Params_Future) Struct() (AdminSession_listInvites_Params, error) {
 	p, err := f.Future.Ptr()
-	return UserSession_listPackages_Params(p.Struct()), err
+	return AdminSession_listInvites_Params(p.Struct()), err
 }
-func (p UserSession_listPackages_Params_Future) Into() collection.Pusher {
+func (p AdminSession_listInvites_Params_Future) Into() collection.Pusher {
 	return collection.Pusher(p.Future.Field(0, nil).Client())
 }
 
-type UserSession_listPackages_Results capnp.Struct
+type AdminSession_listInvites_Results capnp.Struct
 
-// UserSession_listPackages_Results_TypeID is the unique identifier for the type UserSession_listPackages_Results.
-const UserSession_listPackages_Results_TypeID = 0xc5ea967cde37a2fe
+// AdminSession_listInvites_Results_TypeID is the unique identifier for the type AdminSession_listInvites_Results.
+const AdminSession_listInvites_Results_TypeID = 0xdc2bc5bb59170547
 
-func NewUserSession_listPackages_Results(s *capnp.Segment) (UserSession_listPackages_Results, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
-	return UserSession_listPackages_Results(st), err
+func NewAdminSession_listInvites_Results(s *capnp.Segment) (AdminSession_listInvites_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return AdminSession_listInvites_Results(st), err
 }
 
-func NewRootUserSession_listPackages_Results(s *capnp.Segment) (UserSession_listPackages_Results, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
-	return UserSession_listPackages_Results(st), err
+func NewRootAdminSession_listInvites_Results(s *capnp.Segment) (AdminSession_listInvites_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return AdminSession_listInvites_Results(st), err
 }
 
-func ReadRootUserSession_listPackages_Results(msg *capnp.Message) (UserSession_listPackages_Results, error) {
+func ReadRootAdminSession_listInvites_Results(msg *capnp.Message) (AdminSession_listInvites_Results, error) {
 	root, err := msg.Root()
-	return UserSession_listPackages_Results(root.Struct()), err
+	return AdminSession_listInvites_Results(root.Struct()), err
 }
 
-func (s UserSession_listPackages_Results) String() string {
-	str, _ := text.Marshal(0xc5ea967cde37a2fe, capnp.Struct(s))
+func (s AdminSession_listInvites_Results) String() string {
+	str, _ := text.Marshal(0xdc2bc5bb59170547, capnp.Struct(s))
 	return str
 }
 
-func (s UserSession_listPackages_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+func (s AdminSession_listInvites_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
 	return capnp.Struct(s).EncodeAsPtr(seg)
 }
 
-func (UserSession_listPackages_Results) DecodeFromPtr(p capnp.Ptr) UserSession_listPackages_Results {
-	return UserSession_listPackages_Results(capnp.Struct{}.DecodeFromPtr(p))
+func (AdminSession_listInvites_Results) DecodeFromPtr(p capnp.Ptr) AdminSession_listInvites_Results {
+	return AdminSession_listInvites_Results(capnp.Struct{}.DecodeFromPtr(p))
 }
 
-func (s UserSession_listPackages_Results) ToPtr() capnp.Ptr {
+func (s AdminSession_listInvites_Results) ToPtr() capnp.Ptr {
 	return capnp.Struct(s).ToPtr()
 }
-func (s UserSession_listPackages_Results) IsValid() bool {
+func (s AdminSession_listInvites_Results) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
 
-func (s UserSession_listPackages_Results) Message() *capnp.Message {
+func (s AdminSession_listInvites_Results) Message() *capnp.Message {
 	return capnp.Struct(s).Message()
 }
 
-func (s UserSession_listPackages_Results) Segment() *capnp.Segment {
+func (s AdminSession_listInvites_Results) Segment() *capnp.Segment {
 	return capnp.Struct(s).Segment()
 }
+func (s AdminSession_listInvites_Results) Error() (ApiError, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return ApiError(p.Struct()), err
+}
 
-// UserSession_listPackages_Results_List is a list of UserSession_listPackages_Results.
-type UserSession_listPackages_Results_List = capnp.StructList[UserSession_listPackages_Results]
+func (s AdminSession_listInvites_Results) HasError() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
 
-// NewUserSession_listPackages_Results creates a new list of UserSession_listPackages_Results.
-func NewUserSession_listPackages_Results_List(s *capnp.Segment, sz int32) (UserSession_listPackages_Results_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0}, sz)
-	return capnp.StructList[UserSession_listPackages_Results](l), err
+func (s AdminSession_listInvites_Results) SetError(v ApiError) error {
+	return capnp.Struct(s).SetPtr(0, capnp.Struct(v).ToPtr())
 }
 
-// UserSession_listPackages_Results_Future is a wrapper for a UserSession_listPackages_Results promised by a client call.
-type UserSession_listPackages_Results_Future struct{ *capnp.Future }
+// NewError sets the error field to a newly
+// allocated ApiError struct, preferring placement in s's segment.
+func (s AdminSession_listInvites_Results) NewError() (ApiError, error) {
+	ss, err := NewApiError(capnp.Struct(s).Segment())
+	if err != nil {
+		return ApiError{}, err
+	}
+	err = capnp.Struct(s).SetPtr(0, capnp.Struct(ss).ToPtr())
+	return ss, err
+}
 
-func (f UserSession_listPackages_Results_Future) Struct() (UserSession_listPackages_Results, error) {
+// AdminSession_listInvites_Results_List is a list of AdminSession_listInvites_Results.
+type AdminSession_listInvites_Results_List = capnp.StructList[AdminSession_listInvites_Results]
+
+// NewAdminSession_listInvites_Results creates a new list of AdminSession_listInvites_Results.
+func NewAdminSession_listInvites_Results_List(s *capnp.Segment, sz int32) (AdminSession_listInvites_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return capnp.StructList[AdminSession_listInvites_Results](l), err
+}
+
+// AdminSession_listInvites_Results_Future is a wrapper for a AdminSession_listInvites_Results promised by a client call.
+type AdminSession_listInvites_Results_Future struct{ *capnp.Future }
+
+func (f AdminSession_listInvites_Results_Future) Struct() (AdminSession_listInvites_Results, error) {
 	p, err := f.Future.Ptr()
-	return UserSession_listPackages_Results(p.Struct()), err
+	return AdminSession_listInvites_Results(p.Struct()), err
+}
+func (p AdminSession_listInvites_Results_Future) Error() ApiError_Future {
+	return ApiError_Future{Future: p.Future.Field(0, nil)}
 }
 
-type UiView capnp.Struct
+type AdminSession_deleteInvite_Params capnp.Struct
 
-// UiView_TypeID is the unique identifier for the type UiView.
-const UiView_TypeID = 0x9efbad5f3a5b9820
+// AdminSession_deleteInvite_Params_TypeID is the unique identifier for the type AdminSession_deleteInvite_Params.
+const AdminSession_deleteInvite_Params_TypeID = 0xfe19ccb225acacfb
 
-func NewUiView(s *capnp.Segment) (UiView, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 5})
-	return UiView(st), err
+func NewAdminSession_deleteInvite_Params(s *capnp.Segment) (AdminSession_deleteInvite_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return AdminSession_deleteInvite_Params(st), err
 }
 
-func NewRootUiView(s *capnp.Segment) (UiView, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 5})
-	return UiView(st), err
+func NewRootAdminSession_deleteInvite_Params(s *capnp.Segment) (AdminSession_deleteInvite_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return AdminSession_deleteInvite_Params(st), err
 }
 
-func ReadRootUiView(msg *capnp.Message) (UiView, error) {
+func ReadRootAdminSession_deleteInvite_Params(msg *capnp.Message) (AdminSession_deleteInvite_Params, error) {
 	root, err := msg.Root()
-	return UiView(root.Struct()), err
+	return AdminSession_deleteInvite_Params(root.Struct()), err
 }
 
-func (s UiView) String() string {
-	str, _ := text.Marshal(0x9efbad5f3a5b9820, capnp.Struct(s))
+func (s AdminSession_deleteInvite_Params) String() string {
+	str, _ := text.Marshal(0xfe19ccb225acacfb, capnp.Struct(s))
 	return str
 }
 
-func (s UiView) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+func (s AdminSession_deleteInvite_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
 	return capnp.Struct(s).EncodeAsPtr(seg)
 }
 
-func (UiView) DecodeFromPtr(p capnp.Ptr) UiView {
-	return UiView(capnp.Struct{}.DecodeFromPtr(p))
+func (AdminSession_deleteInvite_Params) DecodeFromPtr(p capnp.Ptr) AdminSession_deleteInvite_Params {
+	return AdminSession_deleteInvite_Params(capnp.Struct{}.DecodeFromPtr(p))
 }
 
-func (s UiView) ToPtr() capnp.Ptr {
+func (s AdminSession_deleteInvite_Params) ToPtr() capnp.Ptr {
 	return capnp.Struct(s).ToPtr()
 }
-func (s UiView) IsValid() bool {
+func (s AdminSession_deleteInvite_Params) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
 
-func (s UiView) Message() *capnp.Message {
+func (s AdminSession_deleteInvite_Params) Message() *capnp.Message {
 	return capnp.Struct(s).Message()
 }
 
-func (s UiView) Segment() *capnp.Segment {
+func (s AdminSession_deleteInvite_Params) Segment() *capnp.Segment {
 	return capnp.Struct(s).Segment()
 }
-func (s UiView) Title() (string, error) {
-	p, err := capnp.Struct(s).Ptr(1)
+func (s AdminSession_deleteInvite_Params) Id() (string, error) {
+	p, err := capnp.Struct(s).Ptr(0)
 	return p.Text(), err
 }
 
-func (s UiView) HasTitle() bool {
-	return capnp.Struct(s).HasPtr(1)
+func (s AdminSession_deleteInvite_Params) HasId() bool {
+	return capnp.Struct(s).HasPtr(0)
 }
 
-func (s UiView) TitleBytes() ([]byte, error) {
-	p, err := capnp.Struct(s).Ptr(1)
+func (s AdminSession_deleteInvite_Params) IdBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(0)
 	return p.TextBytes(), err
 }
 
-func (s UiView) SetTitle(v string) error {
-	return capnp.Struct(s).SetText(1, v)
+func (s AdminSession_deleteInvite_Params) SetId(v string) error {
+	return capnp.Struct(s).SetText(0, v)
 }
 
-func (s UiView) SessionToken() (string, error) {
-	p, err := capnp.Struct(s).Ptr(2)
-	return p.Text(), err
-}
+// AdminSession_deleteInvite_Params_List is a list of AdminSession_deleteInvite_Params.
+type AdminSession_deleteInvite_Params_List = capnp.StructList[AdminSession_deleteInvite_Params]
 
-func (s UiView) HasSessionToken() bool {
-	return capnp.Struct(s).HasPtr(2)
+// NewAdminSession_deleteInvite_Params creates a new list of AdminSession_deleteInvite_Params.
+func NewAdminSession_deleteInvite_Params_List(s *capnp.Segment, sz int32) (AdminSession_deleteInvite_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return capnp.StructList[AdminSession_deleteInvite_Params](l), err
 }
 
-func (s UiView) SessionTokenBytes() ([]byte, error) {
-	p, err := capnp.Struct(s).Ptr(2)
-	return p.TextBytes(), err
-}
+// AdminSession_deleteInvite_Params_Future is a wrapper for a AdminSession_deleteInvite_Params promised by a client call.
+type AdminSession_deleteInvite_Params_Future struct{ *capnp.Future }
 
-func (s UiView) SetSessionToken(v string) error {
-	return capnp.Struct(s).SetText(2, v)
+func (f AdminSession_deleteInvite_Params_Future) Struct() (AdminSession_deleteInvite_Params, error) {
+	p, err := f.Future.Ptr()
+	return AdminSession_deleteInvite_Params(p.Struct()), err
 }
 
-func (s UiView) Subdomain() (string, error) {
-	p, err := capnp.Struct(s).Ptr(3)
-	return p.Text(), err
+type AdminSession_deleteInvite_Results capnp.Struct
+
+// AdminSession_deleteInvite_Results_TypeID is the unique identifier for the type AdminSession_deleteInvite_Results.
+const AdminSession_deleteInvite_Results_TypeID = 0xace73109a97b2651
+
+func NewAdminSession_deleteInvite_Results(s *capnp.Segment) (AdminSession_deleteInvite_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return AdminSession_deleteInvite_Results(st), err
 }
 
-func (s UiView) HasSubdomain() bool {
-	return capnp.Struct(s).HasPtr(3)
+func NewRootAdminSession_deleteInvite_Results(s *capnp.Segment) (AdminSession_deleteInvite_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return AdminSession_deleteInvite_Results(st), err
 }
 
-func (s UiView) SubdomainBytes() ([]byte, error) {
-	p, err := capnp.Struct(s).Ptr(3)
-	return p.TextBytes(), err
+func ReadRootAdminSession_deleteInvite_Results(msg *capnp.Message) (AdminSession_deleteInvite_Results, error) {
+	root, err := msg.Root()
+	return AdminSession_deleteInvite_Results(root.Struct()), err
 }
 
-func (s UiView) SetSubdomain(v string) error {
-	return capnp.Struct(s).SetText(3, v)
+func (s AdminSession_deleteInvite_Results) String() string {
+	str, _ := text.Marshal(0xace73109a97b2651, capnp.Struct(s))
+	return str
 }
 
-func (s UiView) ViewInfo() (grain.UiView_ViewInfo, error) {
-	p, err := capnp.Struct(s).Ptr(4)
-	return grain.UiView_ViewInfo(p.Struct()), err
+func (s AdminSession_deleteInvite_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
 }
 
-func (s UiView) HasViewInfo() bool {
-	return capnp.Struct(s).HasPtr(4)
+func (AdminSession_deleteInvite_Results) DecodeFromPtr(p capnp.Ptr) AdminSession_deleteInvite_Results {
+	return AdminSession_deleteInvite_Results(capnp.Struct{}.DecodeFromPtr(p))
 }
 
-func (s UiView) SetViewInfo(v grain.UiView_ViewInfo) error {
-	return capnp.Struct(s).SetPtr(4, capnp.Struct(v).ToPtr())
+func (s AdminSession_deleteInvite_Results) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s AdminSession_deleteInvite_Results) IsValid() bool {
+	return capnp.Struct(s).IsValid()
 }
 
-// NewViewInfo sets the viewInfo field to a newly
-// allocated grain.UiView_ViewInfo struct, preferring placement in s's segment.
-func (s UiView) NewViewInfo() (grain.UiView_ViewInfo, error) {
-	ss, err := grain.NewUiView_ViewInfo(capnp.Struct(s).Segment())
-	if err != nil {
-		return grain.UiView_ViewInfo{}, err
-	}
-	err = capnp.Struct(s).SetPtr(4, capnp.Struct(ss).ToPtr())
-	return ss, err
+func (s AdminSession_deleteInvite_Results) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
 }
 
-func (s UiView) Controller() UiView_Controller {
-	p, _ := capnp.Struct(s).Ptr(0)
-	return UiView_Controller(p.Interface().Client())
+func (s AdminSession_deleteInvite_Results) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s AdminSession_deleteInvite_Results) Error() (ApiError, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return ApiError(p.Struct()), err
 }
 
-func (s UiView) HasController() bool {
+func (s AdminSession_deleteInvite_Results) HasError() bool {
 	return capnp.Struct(s).HasPtr(0)
 }
 
-func (s UiView) SetController(v UiView_Controller) error {
-	if !v.IsValid() {
-		return capnp.Struct(s).SetPtr(0, capnp.Ptr{})
+func (s AdminSession_deleteInvite_Results) SetError(v ApiError) error {
+	return capnp.Struct(s).SetPtr(0, capnp.Struct(v).ToPtr())
+}
+
+// NewError sets the error field to a newly
+// allocated ApiError struct, preferring placement in s's segment.
+func (s AdminSession_deleteInvite_Results) NewError() (ApiError, error) {
+	ss, err := NewApiError(capnp.Struct(s).Segment())
+	if err != nil {
+		return ApiError{}, err
 	}
-	seg := s.Segment()
-	in := capnp.NewInterface(seg, seg.Message().CapTable().Add(capnp.Client(v)))
-	return capnp.Struct(s).SetPtr(0, in.ToPtr())
+	err = capnp.Struct(s).SetPtr(0, capnp.Struct(ss).ToPtr())
+	return ss, err
 }
 
-// UiView_List is a list of UiView.
-type UiView_List = capnp.StructList[UiView]
+// AdminSession_deleteInvite_Results_List is a list of AdminSession_deleteInvite_Results.
+type AdminSession_deleteInvite_Results_List = capnp.StructList[AdminSession_deleteInvite_Results]
 
-// NewUiView creates a new list of UiView.
-func NewUiView_List(s *capnp.Segment, sz int32) (UiView_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 5}, sz)
-	return capnp.StructList[UiView](l), err
+// NewAdminSession_deleteInvite_Results creates a new list of AdminSession_deleteInvite_Results.
+func NewAdminSession_deleteInvite_Results_List(s *capnp.Segment, sz int32) (AdminSession_deleteInvite_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return capnp.StructList[AdminSession_deleteInvite_Results](l), err
 }
 
-// UiView_Future is a wrapper for a UiView promised by a client call.
-type UiView_Future struct{ *capnp.Future }
+// AdminSession_deleteInvite_Results_Future is a wrapper for a AdminSession_deleteInvite_Results promised by a client call.
+type AdminSession_deleteInvite_Results_Future struct{ *capnp.Future }
 
-func (f UiView_Future) Struct() (UiView, error) {
+func (f AdminSession_deleteInvite_Results_Future) Struct() (AdminSession_deleteInvite_Results, error) {
 	p, err := f.Future.Ptr()
-	return UiView(p.Struct()), err
+	return AdminSession_deleteInvite_Results(p.Struct()), err
 }
-func (p UiView_Future) ViewInfo() grain.UiView_ViewInfo_Future {
-	return grain.UiView_ViewInfo_Future{Future: p.Future.Field(4, nil)}
-}
-func (p UiView_Future) Controller() UiView_Controller {
-	return UiView_Controller(p.Future.Field(0, nil).Client())
+func (p AdminSession_deleteInvite_Results_Future) Error() ApiError_Future {
+	return ApiError_Future{Future: p.Future.Field(0, nil)}
 }
 
-type UiView_Controller capnp.Client
-
-// UiView_Controller_TypeID is the unique identifier for the type UiView_Controller.
-const UiView_Controller_TypeID = 0xcc45c4dfa8fbffba
-
-func (c UiView_Controller) MakeSharingToken(ctx context.Context, params func(UiView_Controller_makeSharingToken_Params) error) (UiView_Controller_makeSharingToken_Results_Future, capnp.ReleaseFunc) {
-
-	s := capnp.Send{
-		Method: capnp.Method{
-			InterfaceID:   0xcc45c4dfa8fbffba,
-			MethodID:      0,
-			InterfaceName: "external.capnp:UiView.Controller",
-			MethodName:    "makeSharingToken",
-		},
-	}
-	if params != nil {
-		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 2}
-		s.PlaceArgs = func(s capnp.Struct) error { return params(UiView_Controller_makeSharingToken_Params(s)) }
-	}
-
-	ans, release := capnp.Client(c).SendCall(ctx, s)
-	return UiView_Controller_makeSharingToken_Results_Future{Future: ans.Future()}, release
+type InviteRedemption capnp.Struct
 
-}
+// InviteRedemption_TypeID is the unique identifier for the type InviteRedemption.
+const InviteRedemption_TypeID = 0xc9902241830433de
 
-func (c UiView_Controller) WaitStreaming() error {
-	return capnp.Client(c).WaitStreaming()
+func NewInviteRedemption(s *capnp.Segment) (InviteRedemption, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1})
+	return InviteRedemption(st), err
 }
 
-// String returns a string that identifies this capability for debugging
-// purposes.  Its format should not be depended on: in particular, it
-// should not be used to compare clients.  Use IsSame to compare clients
-// for equality.
-func (c UiView_Controller) String() string {
-	return "UiView_Controller(" + capnp.Client(c).String() + ")"
+func NewRootInviteRedemption(s *capnp.Segment) (InviteRedemption, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1})
+	return InviteRedemption(st), err
 }
 
-// AddRef creates a new Client that refers to the same capability as c.
-// If c is nil or has resolved to null, then AddRef returns nil.
-func (c UiView_Controller) AddRef() UiView_Controller {
-	return UiView_Controller(capnp.Client(c).AddRef())
+func ReadRootInviteRedemption(msg *capnp.Message) (InviteRedemption, error) {
+	root, err := msg.Root()
+	return InviteRedemption(root.Struct()), err
 }
 
-// Release releases a capability reference.  If this is the last
-// reference to the capability, then the underlying resources associated
-// with the capability will be released.
-//
-// Release will panic if c has already been released, but not if c is
-// nil or resolved to null.
-func (c UiView_Controller) Release() {
-	capnp.Client(c).Release()
+func (s InviteRedemption) String() string {
+	str, _ := text.Marshal(0xc9902241830433de, capnp.Struct(s))
+	return str
 }
 
-// Resolve blocks until the capability is fully resolved or the Context
-// expires.
-func (c UiView_Controller) Resolve(ctx context.Context) error {
-	return capnp.Client(c).Resolve(ctx)
+func (s InviteRedemption) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
 }
 
-func (c UiView_Controller) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
-	return capnp.Client(c).EncodeAsPtr(seg)
+func (InviteRedemption) DecodeFromPtr(p capnp.Ptr) InviteRedemption {
+	return InviteRedemption(capnp.Struct{}.DecodeFromPtr(p))
 }
 
-func (UiView_Controller) DecodeFromPtr(p capnp.Ptr) UiView_Controller {
-	return UiView_Controller(capnp.Client{}.DecodeFromPtr(p))
+func (s InviteRedemption) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
 }
-
-// IsValid reports whether c is a valid reference to a capability.
-// A reference is invalid if it is nil, has resolved to null, or has
-// been released.
-func (c UiView_Controller) IsValid() bool {
-	return capnp.Client(c).IsValid()
+func (s InviteRedemption) IsValid() bool {
+	return capnp.Struct(s).IsValid()
 }
 
-// IsSame reports whether c and other refer to a capability created by the
-// same call to NewClient.  This can return false negatives if c or other
-// are not fully resolved: use Resolve if this is an issue.  If either
-// c or other are released, then IsSame panics.
-func (c UiView_Controller) IsSame(other UiView_Controller) bool {
-	return capnp.Client(c).IsSame(capnp.Client(other))
+func (s InviteRedemption) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
 }
 
-// Update the flowcontrol.FlowLimiter used to manage flow control for
-// this client. This affects all future calls, but not calls already
-// waiting to send. Passing nil sets the value to flowcontrol.NopLimiter,
-// which is also the default.
-func (c UiView_Controller) SetFlowLimiter(lim fc.FlowLimiter) {
-	capnp.Client(c).SetFlowLimiter(lim)
+func (s InviteRedemption) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
 }
-
-// Get the current flowcontrol.FlowLimiter used to manage flow control
-// for this client.
-func (c UiView_Controller) GetFlowLimiter() fc.FlowLimiter {
-	return capnp.Client(c).GetFlowLimiter()
+func (s InviteRedemption) AccountId() (string, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.Text(), err
 }
 
-// A UiView_Controller_Server is a UiView_Controller with a local implementation.
-type UiView_Controller_Server interface {
-	MakeSharingToken(context.Context, UiView_Controller_makeSharingToken) error
+func (s InviteRedemption) HasAccountId() bool {
+	return capnp.Struct(s).HasPtr(0)
 }
 
-// UiView_Controller_NewServer creates a new Server from an implementation of UiView_Controller_Server.
-func UiView_Controller_NewServer(s UiView_Controller_Server) *server.Server {
-	c, _ := s.(server.Shutdowner)
-	return server.New(UiView_Controller_Methods(nil, s), s, c)
+func (s InviteRedemption) AccountIdBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.TextBytes(), err
 }
 
-// UiView_Controller_ServerToClient creates a new Client from an implementation of UiView_Controller_Server.
-// The caller is responsible for calling Release on the returned Client.
-func UiView_Controller_ServerToClient(s UiView_Controller_Server) UiView_Controller {
-	return UiView_Controller(capnp.NewClient(UiView_Controller_NewServer(s)))
+func (s InviteRedemption) SetAccountId(v string) error {
+	return capnp.Struct(s).SetText(0, v)
 }
 
-// UiView_Controller_Methods appends Methods to a slice that invoke the methods on s.
-// This can be used to create a more complicated Server.
-func UiView_Controller_Methods(methods []server.Method, s UiView_Controller_Server) []server.Method {
-	if cap(methods) == 0 {
-		methods = make([]server.Method, 0, 1)
-	}
-
-	methods = append(methods, server.Method{
-		Method: capnp.Method{
-			InterfaceID:   0xcc45c4dfa8fbffba,
-			MethodID:      0,
-			InterfaceName: "external.capnp:UiView.Controller",
-			MethodName:    "makeSharingToken",
-		},
-		Impl: func(ctx context.Context, call *server.Call) error {
-			return s.MakeSharingToken(ctx, UiView_Controller_makeSharingToken{call})
-		},
-	})
-
-	return methods
+func (s InviteRedemption) Time() int64 {
+	return int64(capnp.Struct(s).Uint64(0))
 }
 
-// UiView_Controller_makeSharingToken holds the state for a server call to UiView_Controller.makeSharingToken.
-// See server.Call for documentation.
-type UiView_Controller_makeSharingToken struct {
-	*server.Call
+func (s InviteRedemption) SetTime(v int64) {
+	capnp.Struct(s).SetUint64(0, uint64(v))
 }
 
-// Args returns the call's arguments.
-func (c UiView_Controller_makeSharingToken) Args() UiView_Controller_makeSharingToken_Params {
-	return UiView_Controller_makeSharingToken_Params(c.Call.Args())
-}
+// InviteRedemption_List is a list of InviteRedemption.
+type InviteRedemption_List = capnp.StructList[InviteRedemption]
 
-// AllocResults allocates the results struct.
-func (c UiView_Controller_makeSharingToken) AllocResults() (UiView_Controller_makeSharingToken_Results, error) {
-	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 1})
-	return UiView_Controller_makeSharingToken_Results(r), err
+// NewInviteRedemption creates a new list of InviteRedemption.
+func NewInviteRedemption_List(s *capnp.Segment, sz int32) (InviteRedemption_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1}, sz)
+	return capnp.StructList[InviteRedemption](l), err
 }
 
-// UiView_Controller_List is a list of UiView_Controller.
-type UiView_Controller_List = capnp.CapList[UiView_Controller]
+// InviteRedemption_Future is a wrapper for a InviteRedemption promised by a client call.
+type InviteRedemption_Future struct{ *capnp.Future }
 
-// NewUiView_Controller_List creates a new list of UiView_Controller.
-func NewUiView_Controller_List(s *capnp.Segment, sz int32) (UiView_Controller_List, error) {
-	l, err := capnp.NewPointerList(s, sz)
-	return capnp.CapList[UiView_Controller](l), err
+func (f InviteRedemption_Future) Struct() (InviteRedemption, error) {
+	p, err := f.Future.Ptr()
+	return InviteRedemption(p.Struct()), err
 }
 
-type UiView_Controller_makeSharingToken_Params capnp.Struct
-
-// UiView_Controller_makeSharingToken_Params_TypeID is the unique identifier for the type UiView_Controller_makeSharingToken_Params.
-const UiView_Controller_makeSharingToken_Params_TypeID = 0xb0d3e2aa469b06cd
+type Invite capnp.Struct
 
-func NewUiView_Controller_makeSharingToken_Params(s *capnp.Segment) (UiView_Controller_makeSharingToken_Params, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
-	return UiView_Controller_makeSharingToken_Params(st), err
+// Invite_TypeID is the unique identifier for the type Invite.
+const Invite_TypeID = 0x928ddb974d0fd599
+
+func NewInvite(s *capnp.Segment) (Invite, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 5})
+	return Invite(st), err
 }
 
-func NewRootUiView_Controller_makeSharingToken_Params(s *capnp.Segment) (UiView_Controller_makeSharingToken_Params, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
-	return UiView_Controller_makeSharingToken_Params(st), err
+func NewRootInvite(s *capnp.Segment) (Invite, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 5})
+	return Invite(st), err
 }
 
-func ReadRootUiView_Controller_makeSharingToken_Params(msg *capnp.Message) (UiView_Controller_makeSharingToken_Params, error) {
+func ReadRootInvite(msg *capnp.Message) (Invite, error) {
 	root, err := msg.Root()
-	return UiView_Controller_makeSharingToken_Params(root.Struct()), err
+	return Invite(root.Struct()), err
 }
 
-func (s UiView_Controller_makeSharingToken_Params) String() string {
-	str, _ := text.Marshal(0xb0d3e2aa469b06cd, capnp.Struct(s))
+func (s Invite) String() string {
+	str, _ := text.Marshal(0x928ddb974d0fd599, capnp.Struct(s))
 	return str
 }
 
-func (s UiView_Controller_makeSharingToken_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+func (s Invite) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
 	return capnp.Struct(s).EncodeAsPtr(seg)
 }
 
-func (UiView_Controller_makeSharingToken_Params) DecodeFromPtr(p capnp.Ptr) UiView_Controller_makeSharingToken_Params {
-	return UiView_Controller_makeSharingToken_Params(capnp.Struct{}.DecodeFromPtr(p))
+func (Invite) DecodeFromPtr(p capnp.Ptr) Invite {
+	return Invite(capnp.Struct{}.DecodeFromPtr(p))
 }
 
-func (s UiView_Controller_makeSharingToken_Params) ToPtr() capnp.Ptr {
+func (s Invite) ToPtr() capnp.Ptr {
 	return capnp.Struct(s).ToPtr()
 }
-func (s UiView_Controller_makeSharingToken_Params) IsValid() bool {
+func (s Invite) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
 
-func (s UiView_Controller_makeSharingToken_Params) Message() *capnp.Message {
+func (s Invite) Message() *capnp.Message {
 	return capnp.Struct(s).Message()
 }
 
-func (s UiView_Controller_makeSharingToken_Params) Segment() *capnp.Segment {
+func (s Invite) Segment() *capnp.Segment {
 	return capnp.Struct(s).Segment()
 }
-func (s UiView_Controller_makeSharingToken_Params) Permissions() (capnp.BitList, error) {
+func (s Invite) Id() (string, error) {
 	p, err := capnp.Struct(s).Ptr(0)
-	return capnp.BitList(p.List()), err
+	return p.Text(), err
 }
 
-func (s UiView_Controller_makeSharingToken_Params) HasPermissions() bool {
+func (s Invite) HasId() bool {
 	return capnp.Struct(s).HasPtr(0)
 }
 
-func (s UiView_Controller_makeSharingToken_Params) SetPermissions(v capnp.BitList) error {
-	return capnp.Struct(s).SetPtr(0, v.ToPtr())
+func (s Invite) IdBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.TextBytes(), err
 }
 
-// NewPermissions sets the permissions field to a newly
-// allocated capnp.BitList, preferring placement in s's segment.
-func (s UiView_Controller_makeSharingToken_Params) NewPermissions(n int32) (capnp.BitList, error) {
-	l, err := capnp.NewBitList(capnp.Struct(s).Segment(), n)
-	if err != nil {
-		return capnp.BitList{}, err
-	}
-	err = capnp.Struct(s).SetPtr(0, l.ToPtr())
-	return l, err
+func (s Invite) SetId(v string) error {
+	return capnp.Struct(s).SetText(0, v)
 }
-func (s UiView_Controller_makeSharingToken_Params) Note() (string, error) {
+
+func (s Invite) Role() (string, error) {
 	p, err := capnp.Struct(s).Ptr(1)
 	return p.Text(), err
 }
 
-func (s UiView_Controller_makeSharingToken_Params) HasNote() bool {
+func (s Invite) HasRole() bool {
 	return capnp.Struct(s).HasPtr(1)
 }
 
-func (s UiView_Controller_makeSharingToken_Params) NoteBytes() ([]byte, error) {
+func (s Invite) RoleBytes() ([]byte, error) {
 	p, err := capnp.Struct(s).Ptr(1)
 	return p.TextBytes(), err
 }
 
-func (s UiView_Controller_makeSharingToken_Params) SetNote(v string) error {
+func (s Invite) SetRole(v string) error {
 	return capnp.Struct(s).SetText(1, v)
 }
 
-// UiView_Controller_makeSharingToken_Params_List is a list of UiView_Controller_makeSharingToken_Params.
-type UiView_Controller_makeSharingToken_Params_List = capnp.StructList[UiView_Controller_makeSharingToken_Params]
+func (s Invite) MaxUses() uint32 {
+	return capnp.Struct(s).Uint32(0)
+}
 
-// NewUiView_Controller_makeSharingToken_Params creates a new list of UiView_Controller_makeSharingToken_Params.
-func NewUiView_Controller_makeSharingToken_Params_List(s *capnp.Segment, sz int32) (UiView_Controller_makeSharingToken_Params_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2}, sz)
-	return capnp.StructList[UiView_Controller_makeSharingToken_Params](l), err
+func (s Invite) SetMaxUses(v uint32) {
+	capnp.Struct(s).SetUint32(0, v)
 }
 
-// UiView_Controller_makeSharingToken_Params_Future is a wrapper for a UiView_Controller_makeSharingToken_Params promised by a client call.
-type UiView_Controller_makeSharingToken_Params_Future struct{ *capnp.Future }
+func (s Invite) Expires() int64 {
+	return int64(capnp.Struct(s).Uint64(8))
+}
 
-func (f UiView_Controller_makeSharingToken_Params_Future) Struct() (UiView_Controller_makeSharingToken_Params, error) {
+func (s Invite) SetExpires(v int64) {
+	capnp.Struct(s).SetUint64(8, uint64(v))
+}
+
+func (s Invite) Note() (string, error) {
+	p, err := capnp.Struct(s).Ptr(2)
+	return p.Text(), err
+}
+
+func (s Invite) HasNote() bool {
+	return capnp.Struct(s).HasPtr(2)
+}
+
+func (s Invite) NoteBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(2)
+	return p.TextBytes(), err
+}
+
+func (s Invite) SetNote(v string) error {
+	return capnp.Struct(s).SetText(2, v)
+}
+
+func (s Invite) Created() int64 {
+	return int64(capnp.Struct(s).Uint64(16))
+}
+
+func (s Invite) SetCreated(v int64) {
+	capnp.Struct(s).SetUint64(16, uint64(v))
+}
+
+func (s Invite) CreatedBy() (string, error) {
+	p, err := capnp.Struct(s).Ptr(3)
+	return p.Text(), err
+}
+
+func (s Invite) HasCreatedBy() bool {
+	return capnp.Struct(s).HasPtr(3)
+}
+
+func (s Invite) CreatedByBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(3)
+	return p.TextBytes(), err
+}
+
+func (s Invite) SetCreatedBy(v string) error {
+	return capnp.Struct(s).SetText(3, v)
+}
+
+func (s Invite) Redemptions() (InviteRedemption_List, error) {
+	p, err := capnp.Struct(s).Ptr(4)
+	return InviteRedemption_List(p.List()), err
+}
+
+func (s Invite) HasRedemptions() bool {
+	return capnp.Struct(s).HasPtr(4)
+}
+
+func (s Invite) SetRedemptions(v InviteRedemption_List) error {
+	return capnp.Struct(s).SetPtr(4, v.ToPtr())
+}
+
+// NewRedemptions sets the redemptions field to a newly
+// allocated InviteRedemption_List, preferring placement in s's segment.
+func (s Invite) NewRedemptions(n int32) (InviteRedemption_List, error) {
+	l, err := NewInviteRedemption_List(capnp.Struct(s).Segment(), n)
+	if err != nil {
+		return InviteRedemption_List{}, err
+	}
+	err = capnp.Struct(s).SetPtr(4, l.ToPtr())
+	return l, err
+}
+func (s Invite) Usable() bool {
+	return capnp.Struct(s).Bit(32)
+}
+
+func (s Invite) SetUsable(v bool) {
+	capnp.Struct(s).SetBit(32, v)
+}
+
+// Invite_List is a list of Invite.
+type Invite_List = capnp.StructList[Invite]
+
+// NewInvite creates a new list of Invite.
+func NewInvite_List(s *capnp.Segment, sz int32) (Invite_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 24, PointerCount: 5}, sz)
+	return capnp.StructList[Invite](l), err
+}
+
+// Invite_Future is a wrapper for a Invite promised by a client call.
+type Invite_Future struct{ *capnp.Future }
+
+func (f Invite_Future) Struct() (Invite, error) {
 	p, err := f.Future.Ptr()
-	return UiView_Controller_makeSharingToken_Params(p.Struct()), err
+	return Invite(p.Struct()), err
 }
 
-type UiView_Controller_makeSharingToken_Results capnp.Struct
+type GrainStorage capnp.Struct
 
-// UiView_Controller_makeSharingToken_Results_TypeID is the unique identifier for the type UiView_Controller_makeSharingToken_Results.
-const UiView_Controller_makeSharingToken_Results_TypeID = 0xab5851e986c119a6
+// GrainStorage_TypeID is the unique identifier for the type GrainStorage.
+const GrainStorage_TypeID = 0xa71468e4258a20d9
 
-func NewUiView_Controller_makeSharingToken_Results(s *capnp.Segment) (UiView_Controller_makeSharingToken_Results, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
-	return UiView_Controller_makeSharingToken_Results(st), err
+func NewGrainStorage(s *capnp.Segment) (GrainStorage, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1})
+	return GrainStorage(st), err
 }
 
-func NewRootUiView_Controller_makeSharingToken_Results(s *capnp.Segment) (UiView_Controller_makeSharingToken_Results, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
-	return UiView_Controller_makeSharingToken_Results(st), err
+func NewRootGrainStorage(s *capnp.Segment) (GrainStorage, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1})
+	return GrainStorage(st), err
 }
 
-func ReadRootUiView_Controller_makeSharingToken_Results(msg *capnp.Message) (UiView_Controller_makeSharingToken_Results, error) {
+func ReadRootGrainStorage(msg *capnp.Message) (GrainStorage, error) {
 	root, err := msg.Root()
-	return UiView_Controller_makeSharingToken_Results(root.Struct()), err
+	return GrainStorage(root.Struct()), err
 }
 
-func (s UiView_Controller_makeSharingToken_Results) String() string {
-	str, _ := text.Marshal(0xab5851e986c119a6, capnp.Struct(s))
+func (s GrainStorage) String() string {
+	str, _ := text.Marshal(0xa71468e4258a20d9, capnp.Struct(s))
 	return str
 }
 
-func (s UiView_Controller_makeSharingToken_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+func (s GrainStorage) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
 	return capnp.Struct(s).EncodeAsPtr(seg)
 }
 
-func (UiView_Controller_makeSharingToken_Results) DecodeFromPtr(p capnp.Ptr) UiView_Controller_makeSharingToken_Results {
-	return UiView_Controller_makeSharingToken_Results(capnp.Struct{}.DecodeFromPtr(p))
+func (GrainStorage) DecodeFromPtr(p capnp.Ptr) GrainStorage {
+	return GrainStorage(capnp.Struct{}.DecodeFromPtr(p))
 }
 
-func (s UiView_Controller_makeSharingToken_Results) ToPtr() capnp.Ptr {
+func (s GrainStorage) ToPtr() capnp.Ptr {
 	return capnp.Struct(s).ToPtr()
 }
-func (s UiView_Controller_makeSharingToken_Results) IsValid() bool {
+func (s GrainStorage) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
 
-func (s UiView_Controller_makeSharingToken_Results) Message() *capnp.Message {
+func (s GrainStorage) Message() *capnp.Message {
 	return capnp.Struct(s).Message()
 }
 
-func (s UiView_Controller_makeSharingToken_Results) Segment() *capnp.Segment {
+func (s GrainStorage) Segment() *capnp.Segment {
 	return capnp.Struct(s).Segment()
 }
-func (s UiView_Controller_makeSharingToken_Results) Token() (string, error) {
+func (s GrainStorage) GrainId() (string, error) {
 	p, err := capnp.Struct(s).Ptr(0)
 	return p.Text(), err
 }
 
-func (s UiView_Controller_makeSharingToken_Results) HasToken() bool {
+func (s GrainStorage) HasGrainId() bool {
 	return capnp.Struct(s).HasPtr(0)
 }
 
-func (s UiView_Controller_makeSharingToken_Results) TokenBytes() ([]byte, error) {
+func (s GrainStorage) GrainIdBytes() ([]byte, error) {
 	p, err := capnp.Struct(s).Ptr(0)
 	return p.TextBytes(), err
 }
 
-func (s UiView_Controller_makeSharingToken_Results) SetToken(v string) error {
+func (s GrainStorage) SetGrainId(v string) error {
 	return capnp.Struct(s).SetText(0, v)
 }
 
-// UiView_Controller_makeSharingToken_Results_List is a list of UiView_Controller_makeSharingToken_Results.
-type UiView_Controller_makeSharingToken_Results_List = capnp.StructList[UiView_Controller_makeSharingToken_Results]
-
-// NewUiView_Controller_makeSharingToken_Results creates a new list of UiView_Controller_makeSharingToken_Results.
-func NewUiView_Controller_makeSharingToken_Results_List(s *capnp.Segment, sz int32) (UiView_Controller_makeSharingToken_Results_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
-	return capnp.StructList[UiView_Controller_makeSharingToken_Results](l), err
+func (s GrainStorage) Bytes() uint64 {
+	return capnp.Struct(s).Uint64(0)
 }
 
-// UiView_Controller_makeSharingToken_Results_Future is a wrapper for a UiView_Controller_makeSharingToken_Results promised by a client call.
-type UiView_Controller_makeSharingToken_Results_Future struct{ *capnp.Future }
-
-func (f UiView_Controller_makeSharingToken_Results_Future) Struct() (UiView_Controller_makeSharingToken_Results, error) {
-	p, err := f.Future.Ptr()
-	return UiView_Controller_makeSharingToken_Results(p.Struct()), err
+func (s GrainStorage) SetBytes(v uint64) {
+	capnp.Struct(s).SetUint64(0, v)
 }
 
-type UiView_Keyring capnp.Client
-
-// UiView_Keyring_TypeID is the unique identifier for the type UiView_Keyring.
-const UiView_Keyring_TypeID = 0xe38a747a26bc9a79
-
-func (c UiView_Keyring) Attach(ctx context.Context, params func(UiView_Keyring_attach_Params) error) (UiView_Keyring_attach_Results_Future, capnp.ReleaseFunc) {
-
-	s := capnp.Send{
-		Method: capnp.Method{
-			InterfaceID:   0xe38a747a26bc9a79,
-			MethodID:      0,
-			InterfaceName: "external.capnp:UiView.Keyring",
-			MethodName:    "attach",
-		},
-	}
-	if params != nil {
-		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 1}
-		s.PlaceArgs = func(s capnp.Struct) error { return params(UiView_Keyring_attach_Params(s)) }
-	}
-
-	ans, release := capnp.Client(c).SendCall(ctx, s)
-	return UiView_Keyring_attach_Results_Future{Future: ans.Future()}, release
+// GrainStorage_List is a list of GrainStorage.
+type GrainStorage_List = capnp.StructList[GrainStorage]
 
+// NewGrainStorage creates a new list of GrainStorage.
+func NewGrainStorage_List(s *capnp.Segment, sz int32) (GrainStorage_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1}, sz)
+	return capnp.StructList[GrainStorage](l), err
 }
 
-func (c UiView_Keyring) Sync(ctx context.Context, params func(collection.Puller_sync_Params) error) (collection.Puller_sync_Results_Future, capnp.ReleaseFunc) {
+// GrainStorage_Future is a wrapper for a GrainStorage promised by a client call.
+type GrainStorage_Future struct{ *capnp.Future }
 
-	s := capnp.Send{
-		Method: capnp.Method{
-			InterfaceID:   0x88031edd45a595a4,
-			MethodID:      0,
-			InterfaceName: "collection.capnp:Puller",
-			MethodName:    "sync",
-		},
-	}
-	if params != nil {
-		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 1}
-		s.PlaceArgs = func(s capnp.Struct) error { return params(collection.Puller_sync_Params(s)) }
-	}
+func (f GrainStorage_Future) Struct() (GrainStorage, error) {
+	p, err := f.Future.Ptr()
+	return GrainStorage(p.Struct()), err
+}
 
-	ans, release := capnp.Client(c).SendCall(ctx, s)
-	return collection.Puller_sync_Results_Future{Future: ans.Future()}, release
+type ExternalRpcApi capnp.Client
 
-}
+// ExternalRpcApi_TypeID is the unique identifier for the type ExternalRpcApi.
+const ExternalRpcApi_TypeID = 0xdeeabb33aef9b2db
 
-func (c UiView_Keyring) Key(ctx context.Context, params func(collection.Puller_key_Params) error) (collection.Puller_key_Results_Future, capnp.ReleaseFunc) {
+func (c ExternalRpcApi) Login(ctx context.Context, params func(ExternalRpcApi_login_Params) error) (ExternalRpcApi_login_Results_Future, capnp.ReleaseFunc) {
 
 	s := capnp.Send{
 		Method: capnp.Method{
-			InterfaceID:   0x88031edd45a595a4,
-			MethodID:      1,
-			InterfaceName: "collection.capnp:Puller",
-			MethodName:    "key",
+			InterfaceID:   0xdeeabb33aef9b2db,
+			MethodID:      0,
+			InterfaceName: "external.capnp:ExternalRpcApi",
+			MethodName:    "login",
 		},
 	}
 	if params != nil {
 		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 1}
-		s.PlaceArgs = func(s capnp.Struct) error { return params(collection.Puller_key_Params(s)) }
+		s.PlaceArgs = func(s capnp.Struct) error { return params(ExternalRpcApi_login_Params(s)) }
 	}
 
 	ans, release := capnp.Client(c).SendCall(ctx, s)
-	return collection.Puller_key_Results_Future{Future: ans.Future()}, release
+	return ExternalRpcApi_login_Results_Future{Future: ans.Future()}, release
 
 }
 
-func (c UiView_Keyring) WaitStreaming() error {
+func (c ExternalRpcApi) WaitStreaming() error {
 	return capnp.Client(c).WaitStreaming()
 }
 
@@ -3387,14 +9295,14 @@ func (c UiView_Keyring) WaitStreaming() error {
 // purposes.  Its format should not be depended on: in particular, it
 // should not be used to compare clients.  Use IsSame to compare clients
 // for equality.
-func (c UiView_Keyring) String() string {
-	return "UiView_Keyring(" + capnp.Client(c).String() + ")"
+func (c ExternalRpcApi) String() string {
+	return "ExternalRpcApi(" + capnp.Client(c).String() + ")"
 }
 
 // AddRef creates a new Client that refers to the same capability as c.
 // If c is nil or has resolved to null, then AddRef returns nil.
-func (c UiView_Keyring) AddRef() UiView_Keyring {
-	return UiView_Keyring(capnp.Client(c).AddRef())
+func (c ExternalRpcApi) AddRef() ExternalRpcApi {
+	return ExternalRpcApi(capnp.Client(c).AddRef())
 }
 
 // Release releases a capability reference.  If this is the last
@@ -3403,28 +9311,28 @@ func (c UiView_Keyring) AddRef() UiView_Keyring {
 //
 // Release will panic if c has already been released, but not if c is
 // nil or resolved to null.
-func (c UiView_Keyring) Release() {
+func (c ExternalRpcApi) Release() {
 	capnp.Client(c).Release()
 }
 
 // Resolve blocks until the capability is fully resolved or the Context
 // expires.
-func (c UiView_Keyring) Resolve(ctx context.Context) error {
+func (c ExternalRpcApi) Resolve(ctx context.Context) error {
 	return capnp.Client(c).Resolve(ctx)
 }
 
-func (c UiView_Keyring) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+func (c ExternalRpcApi) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
 	return capnp.Client(c).EncodeAsPtr(seg)
 }
 
-func (UiView_Keyring) DecodeFromPtr(p capnp.Ptr) UiView_Keyring {
-	return UiView_Keyring(capnp.Client{}.DecodeFromPtr(p))
+func (ExternalRpcApi) DecodeFromPtr(p capnp.Ptr) ExternalRpcApi {
+	return ExternalRpcApi(capnp.Client{}.DecodeFromPtr(p))
 }
 
 // IsValid reports whether c is a valid reference to a capability.
 // A reference is invalid if it is nil, has resolved to null, or has
 // been released.
-func (c UiView_Keyring) IsValid() bool {
+func (c ExternalRpcApi) IsValid() bool {
 	return capnp.Client(c).IsValid()
 }
 
@@ -3432,7 +9340,7 @@ func (c UiView_Keyring) IsValid() bool {
 // same call to NewClient.  This can return false negatives if c or other
 // are not fully resolved: use Resolve if this is an issue.  If either
 // c or other are released, then IsSame panics.
-func (c UiView_Keyring) IsSame(other UiView_Keyring) bool {
+func (c ExternalRpcApi) IsSame(other ExternalRpcApi) bool {
 	return capnp.Client(c).IsSame(capnp.Client(other))
 }
 
@@ -3440,166 +9348,215 @@ func (c UiView_Keyring) IsSame(other UiView_Keyring) bool {
 // this client. This affects all future calls, but not calls already
 // waiting to send. Passing nil sets the value to flowcontrol.NopLimiter,
 // which is also the default.
-func (c UiView_Keyring) SetFlowLimiter(lim fc.FlowLimiter) {
+func (c ExternalRpcApi) SetFlowLimiter(lim fc.FlowLimiter) {
 	capnp.Client(c).SetFlowLimiter(lim)
 }
 
 // Get the current flowcontrol.FlowLimiter used to manage flow control
 // for this client.
-func (c UiView_Keyring) GetFlowLimiter() fc.FlowLimiter {
+func (c ExternalRpcApi) GetFlowLimiter() fc.FlowLimiter {
 	return capnp.Client(c).GetFlowLimiter()
 }
 
-// A UiView_Keyring_Server is a UiView_Keyring with a local implementation.
-type UiView_Keyring_Server interface {
-	Attach(context.Context, UiView_Keyring_attach) error
-
-	Sync(context.Context, collection.Puller_sync) error
-
-	Key(context.Context, collection.Puller_key) error
+// A ExternalRpcApi_Server is a ExternalRpcApi with a local implementation.
+type ExternalRpcApi_Server interface {
+	Login(context.Context, ExternalRpcApi_login) error
 }
 
-// UiView_Keyring_NewServer creates a new Server from an implementation of UiView_Keyring_Server.
-func UiView_Keyring_NewServer(s UiView_Keyring_Server) *server.Server {
+// ExternalRpcApi_NewServer creates a new Server from an implementation of ExternalRpcApi_Server.
+func ExternalRpcApi_NewServer(s ExternalRpcApi_Server) *server.Server {
 	c, _ := s.(server.Shutdowner)
-	return server.New(UiView_Keyring_Methods(nil, s), s, c)
+	return server.New(ExternalRpcApi_Methods(nil, s), s, c)
 }
 
-// UiView_Keyring_ServerToClient creates a new Client from an implementation of UiView_Keyring_Server.
+// ExternalRpcApi_ServerToClient creates a new Client from an implementation of ExternalRpcApi_Server.
 // The caller is responsible for calling Release on the returned Client.
-func UiView_Keyring_ServerToClient(s UiView_Keyring_Server) UiView_Keyring {
-	return UiView_Keyring(capnp.NewClient(UiView_Keyring_NewServer(s)))
+func ExternalRpcApi_ServerToClient(s ExternalRpcApi_Server) ExternalRpcApi {
+	return ExternalRpcApi(capnp.NewClient(ExternalRpcApi_NewServer(s)))
 }
 
-// UiView_Keyring_Methods appends Methods to a slice that invoke the methods on s.
+// ExternalRpcApi_Methods appends Methods to a slice that invoke the methods on s.
 // This can be used to create a more complicated Server.
-func UiView_Keyring_Methods(methods []server.Method, s UiView_Keyring_Server) []server.Method {
+func ExternalRpcApi_Methods(methods []server.Method, s ExternalRpcApi_Server) []server.Method {
 	if cap(methods) == 0 {
-		methods = make([]server.Method, 0, 3)
+		methods = make([]server.Method, 0, 1)
 	}
-
-	methods = append(methods, server.Method{
-		Method: capnp.Method{
-			InterfaceID:   0xe38a747a26bc9a79,
-			MethodID:      0,
-			InterfaceName: "external.capnp:UiView.Keyring",
-			MethodName:    "attach",
-		},
-		Impl: func(ctx context.Context, call *server.Call) error {
-			return s.Attach(ctx, UiView_Keyring_attach{call})
-		},
-	})
-
-	methods = append(methods, server.Method{
-		Method: capnp.Method{
-			InterfaceID:   0x88031edd45a595a4,
-			MethodID:      0,
-			InterfaceName: "collection.capnp:Puller",
-			MethodName:    "sync",
-		},
-		Impl: func(ctx context.Context, call *server.Call) error {
-			return s.Sync(ctx, collection.Puller_sync{call})
-		},
-	})
-
-	methods = append(methods, server.Method{
-		Method: capnp.Method{
-			InterfaceID:   0x88031edd45a595a4,
-			MethodID:      1,
-			InterfaceName: "collection.capnp:Puller",
-			MethodName:    "key",
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0xdeeabb33aef9b2db,
+			MethodID:      0,
+			InterfaceName: "external.capnp:ExternalRpcApi",
+			MethodName:    "login",
 		},
 		Impl: func(ctx context.Context, call *server.Call) error {
-			return s.Key(ctx, collection.Puller_key{call})
+			return s.Login(ctx, ExternalRpcApi_login{call})
 		},
 	})
 
 	return methods
 }
 
-// UiView_Keyring_attach holds the state for a server call to UiView_Keyring.attach.
+// ExternalRpcApi_login holds the state for a server call to ExternalRpcApi.login.
 // See server.Call for documentation.
-type UiView_Keyring_attach struct {
+type ExternalRpcApi_login struct {
 	*server.Call
 }
 
 // Args returns the call's arguments.
-func (c UiView_Keyring_attach) Args() UiView_Keyring_attach_Params {
-	return UiView_Keyring_attach_Params(c.Call.Args())
+func (c ExternalRpcApi_login) Args() ExternalRpcApi_login_Params {
+	return ExternalRpcApi_login_Params(c.Call.Args())
 }
 
 // AllocResults allocates the results struct.
-func (c UiView_Keyring_attach) AllocResults() (UiView_Keyring_attach_Results, error) {
-	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 0})
-	return UiView_Keyring_attach_Results(r), err
+func (c ExternalRpcApi_login) AllocResults() (ExternalRpcApi_login_Results, error) {
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return ExternalRpcApi_login_Results(r), err
 }
 
-// UiView_Keyring_List is a list of UiView_Keyring.
-type UiView_Keyring_List = capnp.CapList[UiView_Keyring]
+// ExternalRpcApi_List is a list of ExternalRpcApi.
+type ExternalRpcApi_List = capnp.CapList[ExternalRpcApi]
 
-// NewUiView_Keyring_List creates a new list of UiView_Keyring.
-func NewUiView_Keyring_List(s *capnp.Segment, sz int32) (UiView_Keyring_List, error) {
+// NewExternalRpcApi_List creates a new list of ExternalRpcApi.
+func NewExternalRpcApi_List(s *capnp.Segment, sz int32) (ExternalRpcApi_List, error) {
 	l, err := capnp.NewPointerList(s, sz)
-	return capnp.CapList[UiView_Keyring](l), err
+	return capnp.CapList[ExternalRpcApi](l), err
 }
 
-type UiView_Keyring_attach_Params capnp.Struct
+type ExternalRpcApi_login_Params capnp.Struct
 
-// UiView_Keyring_attach_Params_TypeID is the unique identifier for the type UiView_Keyring_attach_Params.
-const UiView_Keyring_attach_Params_TypeID = 0xa97e44e1d89b7811
+// ExternalRpcApi_login_Params_TypeID is the unique identifier for the type ExternalRpcApi_login_Params.
+const ExternalRpcApi_login_Params_TypeID = 0xa118bfbae000f5a8
 
-func NewUiView_Keyring_attach_Params(s *capnp.Segment) (UiView_Keyring_attach_Params, error) {
+func NewExternalRpcApi_login_Params(s *capnp.Segment) (ExternalRpcApi_login_Params, error) {
 	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
-	return UiView_Keyring_attach_Params(st), err
+	return ExternalRpcApi_login_Params(st), err
 }
 
-func NewRootUiView_Keyring_attach_Params(s *capnp.Segment) (UiView_Keyring_attach_Params, error) {
+func NewRootExternalRpcApi_login_Params(s *capnp.Segment) (ExternalRpcApi_login_Params, error) {
 	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
-	return UiView_Keyring_attach_Params(st), err
+	return ExternalRpcApi_login_Params(st), err
 }
 
-func ReadRootUiView_Keyring_attach_Params(msg *capnp.Message) (UiView_Keyring_attach_Params, error) {
+func ReadRootExternalRpcApi_login_Params(msg *capnp.Message) (ExternalRpcApi_login_Params, error) {
 	root, err := msg.Root()
-	return UiView_Keyring_attach_Params(root.Struct()), err
+	return ExternalRpcApi_login_Params(root.Struct()), err
 }
 
-func (s UiView_Keyring_attach_Params) String() string {
-	str, _ := text.Marshal(0xa97e44e1d89b7811, capnp.Struct(s))
+func (s ExternalRpcApi_login_Params) String() string {
+	str, _ := text.Marshal(0xa118bfbae000f5a8, capnp.Struct(s))
 	return str
 }
 
-func (s UiView_Keyring_attach_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+func (s ExternalRpcApi_login_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
 	return capnp.Struct(s).EncodeAsPtr(seg)
 }
 
-func (UiView_Keyring_attach_Params) DecodeFromPtr(p capnp.Ptr) UiView_Keyring_attach_Params {
-	return UiView_Keyring_attach_Params(capnp.Struct{}.DecodeFromPtr(p))
+func (ExternalRpcApi_login_Params) DecodeFromPtr(p capnp.Ptr) ExternalRpcApi_login_Params {
+	return ExternalRpcApi_login_Params(capnp.Struct{}.DecodeFromPtr(p))
 }
 
-func (s UiView_Keyring_attach_Params) ToPtr() capnp.Ptr {
+func (s ExternalRpcApi_login_Params) ToPtr() capnp.Ptr {
 	return capnp.Struct(s).ToPtr()
 }
-func (s UiView_Keyring_attach_Params) IsValid() bool {
+func (s ExternalRpcApi_login_Params) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
 
-func (s UiView_Keyring_attach_Params) Message() *capnp.Message {
+func (s ExternalRpcApi_login_Params) Message() *capnp.Message {
 	return capnp.Struct(s).Message()
 }
 
-func (s UiView_Keyring_attach_Params) Segment() *capnp.Segment {
+func (s ExternalRpcApi_login_Params) Segment() *capnp.Segment {
 	return capnp.Struct(s).Segment()
 }
-func (s UiView_Keyring_attach_Params) Controller() UiView_Controller {
+func (s ExternalRpcApi_login_Params) Token() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return []byte(p.Data()), err
+}
+
+func (s ExternalRpcApi_login_Params) HasToken() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s ExternalRpcApi_login_Params) SetToken(v []byte) error {
+	return capnp.Struct(s).SetData(0, v)
+}
+
+// ExternalRpcApi_login_Params_List is a list of ExternalRpcApi_login_Params.
+type ExternalRpcApi_login_Params_List = capnp.StructList[ExternalRpcApi_login_Params]
+
+// NewExternalRpcApi_login_Params creates a new list of ExternalRpcApi_login_Params.
+func NewExternalRpcApi_login_Params_List(s *capnp.Segment, sz int32) (ExternalRpcApi_login_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return capnp.StructList[ExternalRpcApi_login_Params](l), err
+}
+
+// ExternalRpcApi_login_Params_Future is a wrapper for a ExternalRpcApi_login_Params promised by a client call.
+type ExternalRpcApi_login_Params_Future struct{ *capnp.Future }
+
+func (f ExternalRpcApi_login_Params_Future) Struct() (ExternalRpcApi_login_Params, error) {
+	p, err := f.Future.Ptr()
+	return ExternalRpcApi_login_Params(p.Struct()), err
+}
+
+type ExternalRpcApi_login_Results capnp.Struct
+
+// ExternalRpcApi_login_Results_TypeID is the unique identifier for the type ExternalRpcApi_login_Results.
+const ExternalRpcApi_login_Results_TypeID = 0xaf3be100c4965fdf
+
+func NewExternalRpcApi_login_Results(s *capnp.Segment) (ExternalRpcApi_login_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return ExternalRpcApi_login_Results(st), err
+}
+
+func NewRootExternalRpcApi_login_Results(s *capnp.Segment) (ExternalRpcApi_login_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return ExternalRpcApi_login_Results(st), err
+}
+
+func ReadRootExternalRpcApi_login_Results(msg *capnp.Message) (ExternalRpcApi_login_Results, error) {
+	root, err := msg.Root()
+	return ExternalRpcApi_login_Results(root.Struct()), err
+}
+
+func (s ExternalRpcApi_login_Results) String() string {
+	str, _ := text.Marshal(0xaf3be100c4965fdf, capnp.Struct(s))
+	return str
+}
+
+func (s ExternalRpcApi_login_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (ExternalRpcApi_login_Results) DecodeFromPtr(p capnp.Ptr) ExternalRpcApi_login_Results {
+	return ExternalRpcApi_login_Results(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s ExternalRpcApi_login_Results) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s ExternalRpcApi_login_Results) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s ExternalRpcApi_login_Results) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s ExternalRpcApi_login_Results) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s ExternalRpcApi_login_Results) Api() ExternalApi {
 	p, _ := capnp.Struct(s).Ptr(0)
-	return UiView_Controller(p.Interface().Client())
+	return ExternalApi(p.Interface().Client())
 }
 
-func (s UiView_Keyring_attach_Params) HasController() bool {
+func (s ExternalRpcApi_login_Results) HasApi() bool {
 	return capnp.Struct(s).HasPtr(0)
 }
 
-func (s UiView_Keyring_attach_Params) SetController(v UiView_Controller) error {
+func (s ExternalRpcApi_login_Results) SetApi(v ExternalApi) error {
 	if !v.IsValid() {
 		return capnp.Struct(s).SetPtr(0, capnp.Ptr{})
 	}
@@ -3608,257 +9565,699 @@ func (s UiView_Keyring_attach_Params) SetController(v UiView_Controller) error {
 	return capnp.Struct(s).SetPtr(0, in.ToPtr())
 }
 
-// UiView_Keyring_attach_Params_List is a list of UiView_Keyring_attach_Params.
-type UiView_Keyring_attach_Params_List = capnp.StructList[UiView_Keyring_attach_Params]
+// ExternalRpcApi_login_Results_List is a list of ExternalRpcApi_login_Results.
+type ExternalRpcApi_login_Results_List = capnp.StructList[ExternalRpcApi_login_Results]
 
-// NewUiView_Keyring_attach_Params creates a new list of UiView_Keyring_attach_Params.
-func NewUiView_Keyring_attach_Params_List(s *capnp.Segment, sz int32) (UiView_Keyring_attach_Params_List, error) {
+// NewExternalRpcApi_login_Results creates a new list of ExternalRpcApi_login_Results.
+func NewExternalRpcApi_login_Results_List(s *capnp.Segment, sz int32) (ExternalRpcApi_login_Results_List, error) {
 	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
-	return capnp.StructList[UiView_Keyring_attach_Params](l), err
+	return capnp.StructList[ExternalRpcApi_login_Results](l), err
 }
 
-// UiView_Keyring_attach_Params_Future is a wrapper for a UiView_Keyring_attach_Params promised by a client call.
-type UiView_Keyring_attach_Params_Future struct{ *capnp.Future }
+// ExternalRpcApi_login_Results_Future is a wrapper for a ExternalRpcApi_login_Results promised by a client call.
+type ExternalRpcApi_login_Results_Future struct{ *capnp.Future }
 
-func (f UiView_Keyring_attach_Params_Future) Struct() (UiView_Keyring_attach_Params, error) {
+func (f ExternalRpcApi_login_Results_Future) Struct() (ExternalRpcApi_login_Results, error) {
 	p, err := f.Future.Ptr()
-	return UiView_Keyring_attach_Params(p.Struct()), err
+	return ExternalRpcApi_login_Results(p.Struct()), err
 }
-func (p UiView_Keyring_attach_Params_Future) Controller() UiView_Controller {
-	return UiView_Controller(p.Future.Field(0, nil).Client())
+func (p ExternalRpcApi_login_Results_Future) Api() ExternalApi {
+	return ExternalApi(p.Future.Field(0, nil).Client())
 }
 
-type UiView_Keyring_attach_Results capnp.Struct
+type LoginSession capnp.Struct
 
-// UiView_Keyring_attach_Results_TypeID is the unique identifier for the type UiView_Keyring_attach_Results.
-const UiView_Keyring_attach_Results_TypeID = 0xbcc07e9ef0112f5c
+// LoginSession_TypeID is the unique identifier for the type LoginSession.
+const LoginSession_TypeID = 0xbd449380bdbe9d70
 
-func NewUiView_Keyring_attach_Results(s *capnp.Segment) (UiView_Keyring_attach_Results, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
-	return UiView_Keyring_attach_Results(st), err
+func NewLoginSession(s *capnp.Segment) (LoginSession, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 3})
+	return LoginSession(st), err
 }
 
-func NewRootUiView_Keyring_attach_Results(s *capnp.Segment) (UiView_Keyring_attach_Results, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
-	return UiView_Keyring_attach_Results(st), err
+func NewRootLoginSession(s *capnp.Segment) (LoginSession, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 3})
+	return LoginSession(st), err
 }
 
-func ReadRootUiView_Keyring_attach_Results(msg *capnp.Message) (UiView_Keyring_attach_Results, error) {
+func ReadRootLoginSession(msg *capnp.Message) (LoginSession, error) {
 	root, err := msg.Root()
-	return UiView_Keyring_attach_Results(root.Struct()), err
+	return LoginSession(root.Struct()), err
 }
 
-func (s UiView_Keyring_attach_Results) String() string {
-	str, _ := text.Marshal(0xbcc07e9ef0112f5c, capnp.Struct(s))
+func (s LoginSession) String() string {
+	str, _ := text.Marshal(0xbd449380bdbe9d70, capnp.Struct(s))
 	return str
 }
 
-func (s UiView_Keyring_attach_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+func (s LoginSession) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
 	return capnp.Struct(s).EncodeAsPtr(seg)
 }
 
-func (UiView_Keyring_attach_Results) DecodeFromPtr(p capnp.Ptr) UiView_Keyring_attach_Results {
-	return UiView_Keyring_attach_Results(capnp.Struct{}.DecodeFromPtr(p))
+func (LoginSession) DecodeFromPtr(p capnp.Ptr) LoginSession {
+	return LoginSession(capnp.Struct{}.DecodeFromPtr(p))
 }
 
-func (s UiView_Keyring_attach_Results) ToPtr() capnp.Ptr {
+func (s LoginSession) ToPtr() capnp.Ptr {
 	return capnp.Struct(s).ToPtr()
 }
-func (s UiView_Keyring_attach_Results) IsValid() bool {
+func (s LoginSession) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
 
-func (s UiView_Keyring_attach_Results) Message() *capnp.Message {
+func (s LoginSession) Message() *capnp.Message {
 	return capnp.Struct(s).Message()
 }
 
-func (s UiView_Keyring_attach_Results) Segment() *capnp.Segment {
+func (s LoginSession) Segment() *capnp.Segment {
 	return capnp.Struct(s).Segment()
 }
+func (s LoginSession) Id() (string, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.Text(), err
+}
 
-// UiView_Keyring_attach_Results_List is a list of UiView_Keyring_attach_Results.
-type UiView_Keyring_attach_Results_List = capnp.StructList[UiView_Keyring_attach_Results]
+func (s LoginSession) HasId() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
 
-// NewUiView_Keyring_attach_Results creates a new list of UiView_Keyring_attach_Results.
-func NewUiView_Keyring_attach_Results_List(s *capnp.Segment, sz int32) (UiView_Keyring_attach_Results_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0}, sz)
-	return capnp.StructList[UiView_Keyring_attach_Results](l), err
+func (s LoginSession) IdBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.TextBytes(), err
 }
 
-// UiView_Keyring_attach_Results_Future is a wrapper for a UiView_Keyring_attach_Results promised by a client call.
-type UiView_Keyring_attach_Results_Future struct{ *capnp.Future }
+func (s LoginSession) SetId(v string) error {
+	return capnp.Struct(s).SetText(0, v)
+}
 
-func (f UiView_Keyring_attach_Results_Future) Struct() (UiView_Keyring_attach_Results, error) {
+func (s LoginSession) UserAgent() (string, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return p.Text(), err
+}
+
+func (s LoginSession) HasUserAgent() bool {
+	return capnp.Struct(s).HasPtr(1)
+}
+
+func (s LoginSession) UserAgentBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return p.TextBytes(), err
+}
+
+func (s LoginSession) SetUserAgent(v string) error {
+	return capnp.Struct(s).SetText(1, v)
+}
+
+func (s LoginSession) RemoteAddr() (string, error) {
+	p, err := capnp.Struct(s).Ptr(2)
+	return p.Text(), err
+}
+
+func (s LoginSession) HasRemoteAddr() bool {
+	return capnp.Struct(s).HasPtr(2)
+}
+
+func (s LoginSession) RemoteAddrBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(2)
+	return p.TextBytes(), err
+}
+
+func (s LoginSession) SetRemoteAddr(v string) error {
+	return capnp.Struct(s).SetText(2, v)
+}
+
+func (s LoginSession) Started() int64 {
+	return int64(capnp.Struct(s).Uint64(0))
+}
+
+func (s LoginSession) SetStarted(v int64) {
+	capnp.Struct(s).SetUint64(0, uint64(v))
+}
+
+func (s LoginSession) LastSeen() int64 {
+	return int64(capnp.Struct(s).Uint64(8))
+}
+
+func (s LoginSession) SetLastSeen(v int64) {
+	capnp.Struct(s).SetUint64(8, uint64(v))
+}
+
+func (s LoginSession) Current() bool {
+	return capnp.Struct(s).Bit(128)
+}
+
+func (s LoginSession) SetCurrent(v bool) {
+	capnp.Struct(s).SetBit(128, v)
+}
+
+// LoginSession_List is a list of LoginSession.
+type LoginSession_List = capnp.StructList[LoginSession]
+
+// NewLoginSession creates a new list of LoginSession.
+func NewLoginSession_List(s *capnp.Segment, sz int32) (LoginSession_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 24, PointerCount: 3}, sz)
+	return capnp.StructList[LoginSession](l), err
+}
+
+// LoginSession_Future is a wrapper for a LoginSession promised by a client call.
+type LoginSession_Future struct{ *capnp.Future }
+
+func (f LoginSession_Future) Struct() (LoginSession, error) {
 	p, err := f.Future.Ptr()
-	return UiView_Keyring_attach_Results(p.Struct()), err
+	return LoginSession(p.Struct()), err
+}
+
+type ApiError capnp.Struct
+
+// ApiError_TypeID is the unique identifier for the type ApiError.
+const ApiError_TypeID = 0xb6b67a93f0f993f6
+
+func NewApiError(s *capnp.Segment) (ApiError, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 3})
+	return ApiError(st), err
+}
+
+func NewRootApiError(s *capnp.Segment) (ApiError, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 3})
+	return ApiError(st), err
+}
+
+func ReadRootApiError(msg *capnp.Message) (ApiError, error) {
+	root, err := msg.Root()
+	return ApiError(root.Struct()), err
+}
+
+func (s ApiError) String() string {
+	str, _ := text.Marshal(0xb6b67a93f0f993f6, capnp.Struct(s))
+	return str
 }
 
-const schema_9498f3818bafa387 = "x\xda\x94W}p\x15\xd5\x15?g\xf7=^BI" +
-	"_.\x1b\xc4\x97\x97\xf7\x1e2PJ*)\x1f\xdah" +
-	"\xc0IHMc\xac\x9d\xc9&\x806\x85\xe2\x92\\\xc2" +
-	"\xc2\xfb\x08\xbb\x1b\x09\x88\xa4\xe9\x88\x06\xa8\xd58X\xbe" +
-	"\x99Ri\xc5:A\xc6\x99N\x1d\x8c\x1d\xa5RG\x07" +
-	"\xac\xa9\x7f(\xd4\x96\x01,\x05f\x98qhm\xed0" +
-	"t;\xf7\xee\xbb\xbb\xfb>\x02t\xf8'\xec;\xf7\x9e" +
-	"s~\xe7w~\xe7\xdc\xd9=\xa5\x0d\x819e\xb5s" +
-	"Aj\x7f@\x0a\x8e\xb3\xe5\x8d\xe4\xc8\xb9\xf9\xa7\x9e\x02" +
-	"\x12G\x80 \x86\x00\xe6ad\x05\x02*e\x91z@" +
-	"{\xd3\x8b\x17\x8f\x9ez\xe3\xf0 \x90(\x02\x04\xd8\xef" +
-	"\xb3\"\x06B\xc0>\xd7W{\xd7P\xf5\xf5g\x9d_" +
-	"\x9c\xa3\x91H\x1b;:\x9d\x1f\x8dW\x9e~)\x11?" +
-	"\xf0<\x90\xc9\xb2}\xf2\xa1\xb2\x05\xafY\x0f}\x06\x80" +
-	"\xf3Z\"\xd5\xa8|?\x12\x02P\x16G\x9a\x95\x81\xc8" +
-	"d\x00\xdb\xdc\xfbHy\xed\x9b\xf5\xfb\x80\xc4\x84\xa3\xb5" +
-	"\x91c\xcc\xd1\x94\x9d?\xa8[>|m?\x900\xda" +
-	"O\xbf\xf8\xea\xb6\x81\x7f\xec\xdc\x0e\xc1 ;\xafE^" +
-	"S\xf4\xc8\x0c\x80y\x03\x91\x04\x02\xda\x9f\xd7\xbf~\x81" +
-	"\xeeo=\x00$,{\xc6\x80\xca\x81\xca\xcb\xcap%" +
-	";\xf3re\xb3r\x8a\xfde\xffa\xcb\x95\xf1K\xab" +
-	"\xe7\x1c\x022\x9d\xa5 1\xa7oW\x1ec)\x8cV" +
-	"\xae\x03\xb4I\xdf\x9eO\xce\xde\xbf\xe9e?<\xb3\xa2" +
-	"\x1c\x9e{\xa3,\xc7_E\xde~\xea\x92\xfa\xc8+@" +
-	"\xeep\x0d\x96E\xff\xc4\x0c\xd6r\x83c\xa3#O\xd6" +
-	"\xcd\x7ft\xd8\xb9\x81\xa75\x14\xed`i\x9d\x1c\xb7\xe7" +
-	";\xbf>\xf7\xd1\x91\xecQ\xee\xfc\x89\xe8\xfb\xec\xe8P" +
-	"\x949_~zQKz\xb2\xfe[\x1f\xf4X\xf5c" +
-	"~\xf4o\x1f~rwW\xf2\x8d\x82$\xafD\xaf*" +
-	"\xff\x89\xb2$\xbf\x886+\xb1\xaa\x10\xc0\xf5\xd2\xc6\x9f" +
-	"\x1e\xfe\xd6\xe1\x11u*\xba\x11\x06\xd95\xa8\x90*\xe6" +
-	"f\xe97\xc9\xe7\xfb7\xbd5\xe2\x8bpm\xd5j\xe6" +
-	"\xa6yG\xeb\xde?o\x93\xde\xf1WxY\xd5\xf3\xec" +
-	"h\xaa\x8a%\xf7\xd6K\xfb\x06?|\xa5\xe7xA\x1c" +
-	"\xcfT\x9dVv1\xef\xca\x0bU\xef*\x93b\x0c\xec" +
-	"\xff\xfe\xa2\xf6\xaf\x1b\x7fv\xf9\xb8/\x9d\xebN:\xbf" +
-	"\xdf\xd7F\x7f30\xff\x84\xdf\xcf\xa5\xaa\x0d\xcc\xcf\x17" +
-	"\xdc\xcfQ\xfb\xda\xa13\xef4\x9d\x00r\x9b\xec\xd1\x01" +
-	"p\xde\xb2\xd8xTR\xeczE\x8f5+\xbb\xb8\xa3" +
-	"\xaf\x18\xb7\x9f\xd9\xf3\xf1\xb2\x8f\xf2\xf8\xc2\x00V\x06b" +
-	"\xc7\x94-\xdc~s\x8c%\xdfw\xf0\x83\x8f\x1f\xde\xbd" +
-	"\xe5\x94\xc3\x00\x1e\xd4\xd9\xd8Q\x16\xd4\x89\xe7>x\xd2" +
-	"j\xaf\xfd\xd4\xe1\xa3\x13\xd4(\xfb\x09\x95\xb31\x16\xd4" +
-	"\x8e\xdb~\xf7\xfa?_\xed<\xe3\x8f\x1a\xe3\x1d\xbcu" +
-	"\xe2\xcc\xe0\xef?\x1f]r\xe9Qz\xdeo0+\xbe" +
-	"\x95\x19\xdc\xc7\x0d\xd6\xef\x1e\xf9\xda\x06k\xeb\xf9\xfc\xb4" +
-	"\x14-~UI\xc5yV\xf1fe(\xce\xfa\xc3m" +
-	"\xa0\"Y\xbd\x17?\xaa\x8c\xc6g\x00(W\xe2,\xab" +
-	"g\x1f\x9f=\xbc\xfd\xc8\xf0E S]j\xa9\x09\xee" +
-	"YK0\x83g\x16|\xd9D\xcb\xde\xbdZP\xb87" +
-	"\x13\xa7\x95\xf7\x12\xec\xce\xe3\x89\xa7\x95\xd8\x14\x86\xe7\xe0" +
-	"7\xb6\x9fy|\xfd\xf7\xfeU\xd0\xc7\xc1)\x13Q\x99" +
-	"\xc4l\x142\xa5Y\xb9\x97[o#\x8b&5\xfd\xfb" +
-	"\xd3/}e\xbec\xcaV\x04;\xfb\xef\xb2M\xfb," +
-	"j\xa4\xb5d\xa0\xa6S\xebI\xf7\xd4-\xd1M\xdd\xca" +
-	"\x18\xed\xd44\xf5L\xba\xe61\x9d\xae3\xa7\xb5Q\xb3" +
-	"7\x94\xb4L5 \x07\x00\x02\x08@\xca\xe6\x02\xa8%" +
-	"2\xaa\x15\x12&\xb8\x15\x12\x0fC@$\x80\x05\x977" +
-	"e\xff\xbf\xb0G\xaf\xe9\xa6V\xd6\x899\xad5\xa1\x19" +
-	"Z\xca\xbc\xa1\xbdAM+c\xd0i\xad\xcc\x14sB" +
-	"i\x03P'\xc8\xa8\xde.\xa1mZ\xbdF\xd7\xfa6" +
-	"\x0a\xb8\x12\xcb@\xc22_\x18r\xf6\xdaV\xads\x8d" +
-	"\xd6MkZ\xd2\xa6\xa5%\x93\xedV\xd8\xa0Z\xaa\x15" +
-	"Q\x0d\xc8A\x00\x97\x87($\x89\x90\x0e\x90Hi\xc8" +
-	"\xee\xa6\x16?\x0cr7m@5\x80h/?\xff\xc7" +
-	"\x99\xeb\xeey\xf8$\x00\xb8\x8eJ\xb2\x8e\x16\xf6Z\xab" +
-	"h\xda\xd2;5+c\xd4\x984\xdd\xd5\x94\xd2\xf4$" +
-	"\xfb\xbc(\xb3\x86\xa69\xb0I\xcb\x04q0{nq" +
-	"B_\xa2\xd3uj\x09\xfa\xdb\xad\xb4\xc3G\xd2\xd2F" +
-	"\xfb\xdb\x99\xb4ed\x92I\x90\xa9\xd1\xff]\xba\xde\xd0" +
-	"\xd3\xddj\x85\x1c\xc0\x00\x87\xe5\x89\x0e\x00u\xa3\x8c\xea" +
-	"\xa0\x84\xe5X\xc1\x04\x87lfU\xfb\x91\x8c\xeaO$" +
-	"$(U\xa0\x04@\xb6\xac\x06P\x07eT\xb7KH" +
-	"$\xb9\x02e\x002\xc4@}NFu\xaf\x84D\x0e" +
-	"T`\x00\x80\xecz\x10@\xdd)\xa3zPB\xbb\xd3" +
-	"\xe7\x1e\x89\x17\xa7S\xfb\x84\xa5[I\x8a\x13@\xc2\x09" +
-	"\x80\xb6\xe9\x94z\x11\x84Y\xde\xde\xe7\xde\x15]\x99\x94" +
-	"\xa6\x03z\xdf\x18\x99Z\xd2+3\x00\x80\xe56\xbdp" +
-	"ha\xf3\xdd?\x1ca\xd7\x96\xfbj)\xe5C\x1cf" +
-	"\x18{5\x14\x8a\x81b\x94\x11\xb2\x1b$R\x16\xb2E" +
-	"\x19P\xd4A\xa6\xe9\x06lE\xef\xeeqc\xf1\x84\xd1" +
-	"\xa4Fp\xa0\x9b\xba\xe5SK\\2\xce\xac\x04P\xa7" +
-	"\xc9\xa8\xcef\x08ga\x9f\xd5\x08\xa0~]F\xf5." +
-	"\x09e\xbdK\xa4\xda\xdf\xe3\xdc\x83\xe5\xfeN\xceIS" +
-	"t\xc2bN\x88\x9al\x95k4\xcb\xd2:W\xb1^" +
-	"\x08i\xa9\x9c^\xe8\xf0\xf5\xc2\x8d+T\x90n\xd6\x87" +
-	"\xa0\x155jR\xda\x1a\xda\xbeJc.\xfd|\xc51" +
-	"\x85\xc0\xca\xa9\xee-i\x0b\xebg\xd9\xd7\xfb\xffG4" +
-	"\\\x0aL\xc8\x81\x7fE\x16\xe9\xfb}\xf0/\xac\x06P" +
-	"\x17\xc8\xa8> \xa1\xddC\x8d\x94n\x9a:\x842i" +
-	"\x13\xbf\x0a\xd8*#\"H\xec\xcfp:c\xd1\x82\xf0" +
-	"K\x8a\xa8\x91\xe6\xefl\x11H^\x17\xbb\xe9&x\xbe" +
-	"\x1e7\xc5\x1a\x82b\xe1#d.H$\x18r\x844" +
-	"\x97\x8c\xc1<2\xfa\x00\xe94\xa8fQ\xe1\xdd\x8f\xc2" +
-	"\\\x8f\x84.\x07\x192w\xca\xa8\xde#\xe57\xa7\xd6" +
-	"i\xe9\x99tK\x1aB]\xb4\x0fK@\xc2\x92[\xa6" +
-	"`\x1b5\xc3\xac\x07\x0a\xe2]lR\xb7\xd2\xba\xd3@" +
-	"\xb9m\x93\xcb\xa2:\x8fE\xf5&o4$\xde\xd2\x9a" +
-	"\xc7X)\xbf\"r\x8f\xce\xe0\x9d\xc0\xe1\x15[2\x8a" +
-	"\xdd\x83\xa8+@\"-!DwOF\xb10\x90\xfb" +
-	"\x1aA\"sB(\xb9+\x1e\x8a]\x81L7@\"" +
-	"1.\xfb<\x15\xce\x99\x06\xec\xcf\xce\xa2\x06\xb4\x05\x0f" +
-	" \xc1\x99\x90[\xba\xf1E\xa0H\xea\xa6\x90\x0fsL" +
-	"\xf9\x1f\xcb\xbe\xde)\xb5\x1f\xb7\xea,nK%\x0c\xeb" +
-	"i+\x83\xc4\xfe\xec\xce\xa9\x17\xafM\xef\xdb\x01\x00\x0d" +
-	"H0\xa1\x06$\xf4\x7f$8\x83M\x16Dv\x10Q" +
-	"-\x97\x91S!W\x85H\x91\xc1Y\xd0\x93\x00\x1e\xab" +
-	"\xc5\x0a\x8db\x0d'd\xab\xa3\xb8\xa2qQt.\x0b" +
-	"\xcc\x0f\x94\xa8\xa7X\x08\xf8\xad>:7\x16\xd3\xd4j" +
-	"OS\xfb\x1fsZ\x0d\x89\xb7\x8d;)\x84{M\xae" +
-	"}\xee\x8e\x95\x97Y\xf0V\xa5>\x0b}\xc1\xc1\x9b\x8e" +
-	"\xf8\"5k\xf4\xb8\xde\xafuu\x19\xd44\xc7\xd4\xcc" +
-	"b+\x10\xa7\x8d\x9c\xbb\x8eM\xf5\xee\x0cuj=8" +
-	"1 \x03\xe2\xc4\"\xa9\x8e-c\x82\x8e\xe0\xbf\xd8\xf0" +
-	"\x0d\x94<\xb6#\xf1^xct\xa8+\x1a\x09\xae\x1a" +
-	"\x1e[\xc4c\x0e\xc5\x8b\x87\x90:\xae\x81\xf5\x8e\xb0d" +
-	"\xb7\xab\x83/\xfc\xb2\xe9/qy\xd0\xc7d\xf7\xd3\x8d" +
-	"\x98\xec[\xe0\xdd\x98P\xd4\xba\xde\xa9)_\xaf\xbc}" +
-	"\xba\xb4\xc3\xf7H.5r\xd6+[\xf0\x02\x12\x9c\x19" +
-	"~r>\xe8\xf1\xd0%\xe7\x1c6\x86g\xcb\xa8.\x90" +
-	"\xd0Nii}%5-g\xa1y\xff\xec\x05}\xf5" +
-	"\xcc\xe5\x9b\xc5\xa4\xcf\x1b\xd2n<7!j\xe1\x18\xf0" +
-	"\xaaw\xb3m\xa4\xba\xe86\x12f\xc3'\x17\xba\xa2\x1b" +
-	"\x97\xabN\xb23\xd3Jx=\xc5K\x03\xc5;\x95\xcc" +
-	"\xd9\x00\x12\x99\xc9DW<)Q\xbc:Il5H" +
-	"dR\xc8\x16\x83\x01\xb2%i@[\x08\x1e\x84\x99\xe4" +
-	"\xe5\xea\x84<\x16\x08\xe8m~\xe2\x89\xed{w\x09f" +
-	"9@\x15\x1f\xaf7\x18Wb\xcd\xf8_\x00\x00\x00\xff" +
-	"\xff@\x95Dp"
+func (s ApiError) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (ApiError) DecodeFromPtr(p capnp.Ptr) ApiError {
+	return ApiError(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s ApiError) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s ApiError) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s ApiError) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s ApiError) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s ApiError) Code() (string, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.Text(), err
+}
+
+func (s ApiError) HasCode() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s ApiError) CodeBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s ApiError) SetCode(v string) error {
+	return capnp.Struct(s).SetText(0, v)
+}
+
+func (s ApiError) Params() (capnp.TextList, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return capnp.TextList(p.List()), err
+}
+
+func (s ApiError) HasParams() bool {
+	return capnp.Struct(s).HasPtr(1)
+}
+
+func (s ApiError) SetParams(v capnp.TextList) error {
+	return capnp.Struct(s).SetPtr(1, v.ToPtr())
+}
+
+// NewParams sets the params field to a newly
+// allocated capnp.TextList, preferring placement in s's segment.
+func (s ApiError) NewParams(n int32) (capnp.TextList, error) {
+	l, err := capnp.NewTextList(capnp.Struct(s).Segment(), n)
+	if err != nil {
+		return capnp.TextList{}, err
+	}
+	err = capnp.Struct(s).SetPtr(1, l.ToPtr())
+	return l, err
+}
+func (s ApiError) Retryable() bool {
+	return capnp.Struct(s).Bit(0)
+}
+
+func (s ApiError) SetRetryable(v bool) {
+	capnp.Struct(s).SetBit(0, v)
+}
+
+func (s ApiError) Description() (string, error) {
+	p, err := capnp.Struct(s).Ptr(2)
+	return p.Text(), err
+}
+
+func (s ApiError) HasDescription() bool {
+	return capnp.Struct(s).HasPtr(2)
+}
+
+func (s ApiError) DescriptionBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(2)
+	return p.TextBytes(), err
+}
+
+func (s ApiError) SetDescription(v string) error {
+	return capnp.Struct(s).SetText(2, v)
+}
+
+// ApiError_List is a list of ApiError.
+type ApiError_List = capnp.StructList[ApiError]
+
+// NewApiError creates a new list of ApiError.
+func NewApiError_List(s *capnp.Segment, sz int32) (ApiError_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 3}, sz)
+	return capnp.StructList[ApiError](l), err
+}
+
+// ApiError_Future is a wrapper for a ApiError promised by a client call.
+type ApiError_Future struct{ *capnp.Future }
+
+func (f ApiError_Future) Struct() (ApiError, error) {
+	p, err := f.Future.Ptr()
+	return ApiError(p.Struct()), err
+}
+
+const schema_9498f3818bafa387 = "x\xda\xbc[{|\x14U\x96>\xa7*\xa1\x13\x87L" +
+	"\xa7\xec\xec\x88\x0e\x10\x86\x05\x192\xc2 \x0c\xe2\xa2n" +
+	"H$b\x1c\xd9M%\xf8\x80\x81\x0d\x95\xeeJRI" +
+	"wWSU\x9d\x07\x18\xa3\xb3\x82\x04\x97\xf1\x89\x08\x8a" +
+	"\x8a\x82\x92\x11\x14pT\xde\xa3\x0c\x8c\x8b\x8b\x0fF]" +
+	"\x07\x14AD\x04wq\x1dG\x1d]ej\x7f\xf7V" +
+	"\xdd\xaa\x9b\xee\xce\x03\x19\xf7\x97\x7f\xd2\xd5\xb7\xee=\xf7" +
+	"<\xbe\xf3\x9dso\x8f\xe9\xf7\xf7\x93\xb2.\xcc\xab\xde" +
+	"\x0aB\xd5-9\xd9\xfd\xec%\x13\xc2\xc7\xdb\xeb\xbf?" +
+	"\x1f\xe4 \x0a\xf6\xad\x8f>u\xdb\xcd\x7f\xbe\xef\x1e\xc8" +
+	"\x16\x03\x00\xa1%\xb3\xb6\x00\x8e[2\xeb:\x04\xb4G" +
+	"|1p\xcb\xfc\xc2\xb1\xf3A\x1a\x88\x00\xd9\x18\x00\x18" +
+	"\x87\xd55\x08\x18\xca\xab.\x06\xb4k\xccW\xf2?\x96" +
+	"W.\x00\xe9G\xde\x80Q\xd5w\x91\x01\x97\xd1\x01\xe2" +
+	"\x0d\xd2\xfa#\x97\xec_\x00\xd2`o\xc0,g\x06\x8d" +
+	"\x0e\xb8\xf1\xd1\xe3[\xf6o}r!H?D\x80," +
+	"\xf2}G\xb5\x81\x90e\xef\xb8i\xd5\x9a\x9c\x99\xfd;" +
+	"@\xfe!z\xef&\xab\xff@\xde\xed\xa8n\x06\xb4W" +
+	"\xc5V^2\xf9E\xb5\xc3\x15O \x03>\xa8\x9eA" +
+	"\x06\xfc\x89\x0e\xa8\xb9\xf3\xd5\xa7GM]\xb3\xc8\x99\xdc" +
+	"\x99A\x9e\xfdK2`\xd6l\xb2\xfa\x9b\xbb\x06\x9b?" +
+	"\xbau\xf4\xaf\xdc\x01t\x86\xdd\xb3\x17\x91\x01o\xce&" +
+	"3\x1ci\x99\xf0\xb3;\x8bN\xdd\xce\xcfP\xa6T\x92" +
+	"\x01\xb2BfX\xf6fp\xea\xd2\xb7\x17\xdfE\x94)" +
+	"r\xca\xcc&\xcaL*\x1b\x01\xc7%\x15\x1b\x01\xed\xc1" +
+	"\xe7\x1dx\xbcp\xf0\xca\xbb@:G\xb4_\xbe:\xef" +
+	"\xd2\x8d\xd6\xd5G\x01p\xdc\xe2p\x11\x86V\x84\xc9\x0b" +
+	"\xcb\xc2SB\xbb\xc3\xe7\x00\xd8\x17M\xc3CW\x96\x8d" +
+	"\xb8\x87_\xf8\x99\xf0N\xb2\xf0\xee0Y\xb8\xe0\xe1\x17" +
+	"\xe7.h\xd0\x96\x10\xf5\x08L\xf6\xcf\xc3ts\x18\xf9" +
+	"\x10\xd0\xbex\xeb\x8e\xa3K'7\xdf\xc7o\xee\x83\x88" +
+	"A\xd5\x13!\x9b{\xf5\x8e7~\xd2\xa0T\xdf\xcf\x9b" +
+	"w\xaa:\x97\x0c\x98\xae\x925\x84\xd9\xff\xf9{k\x7f" +
+	"\xf6\x0a\x90\x82\xdc\xde\x00C/\xa8G\x00\xc7\xedVo" +
+	"\xc5\xd0\x86\xda\x00\x80m>p}\xfe\x84\xed\xc5+@" +
+	"\x1a\xe4M\xb5\xac\x96\x8a\xdbYK\xa6\x1ar\xdf/&" +
+	"V\xaf\xfb\xfa\xc1T\xa7\xa3z\xdaS\xbb1\xb4\xafv" +
+	"\x04\xc0\xb8\x13\xb5\xb7#\xa0\xfdh\xce\xa5C\x0b\x1e{" +
+	"\xeb!~\xf7\xf3\xeb_\"\xd3-\xab'\xd3\xbd\xd4\xf9" +
+	"\xe8\xb1\xa6\x1f\xcb\x0f3\xe7\xa0\x9b\xdb\\Om\xbf\xbb" +
+	"\xfe)\xc0\xaf\xd7|~x\xcbo\x07\xac\xe4\xfdN\xa3" +
+	"_k\x1a\x99\xe0\x93\xe2M\xc7\xd4\x07+V\xa6m\xad" +
+	"C\xfb(\xb4D#R\xdd\xa9M\x09\xbd@\xfe\xb3'" +
+	"|\x93\xb3\xc3<\x7f\xdb*\xde\xcd;5\xaa\xa8g\xe8" +
+	"l\xfb\x87,\x1a~\xb4\xbe\xe0q\xb2;\xe4vGF" +
+	"\x86\xde\xd4\x8e\x00\x86\xf6kD\xe5\xbf\xef8y\xd6\xcc" +
+	"\xa2\x0b\xd7\x804\x1c\xc1\x09\xb9q\xe3\x1b\xa8\x9e\xca\x1a" +
+	"\x9e\x02\xb4\xa5\x96\xfb\xff\xf8\xde\xe4\x1b;\xf9\x809\xd8" +
+	"@\x03\xe6D\x03Y\xea\xb1s_XpB\xbe\xfe\x09" +
+	"W\x16\xba\xf1\xbcF\x1a\x15?j$K\x1c~\xe0\xa1" +
+	"\x95\xe1\xd5\x0b\xd6\xf2fok\xa4>\xbd\x98\x0e\x90\xcf" +
+	"\x9f\xd7\x99{\xe1\x87ky\xb3\x9ft\x06\x9cj$K" +
+	"\xec\xdc\xb7\xed\x96\x89\x97\xcc^\xe7\xc8@crPt" +
+	"\x06\x89I\xe3\xda\x99s\xdf\xca\x9d\xf3$HA~\x9b" +
+	"d\x8dPn\xf4\x0f\x04\x17\xa2\xcd\x80\xffs\xa8\xfa\xde" +
+	"]\xef]\xf2\x14\xb7\x87X\x94\xee\xa15J\x16x\xb9" +
+	"\xdf\xfdW\xfc\xfa\xc8\xeb\xeb\xf9=,\x8bR\xf3\xae#" +
+	"\xef\xdb3\xaf\x18\xf8\xac:\xe8\xf0\xd3 \x0f\xf4\x9d{" +
+	"P\x8c:\xf7\xc8\x18q\xee/\xee\xfe\xea\x93\xbb\xe7>" +
+	"\xfbl\xaa\xc6)\x88\x9d\x8a\xed$\x9b\xa1\x03w\x0c\xdc" +
+	"<\xa0\xe3\xfc\xfd\xcf\xf2\xaet\"\xbe\x91\xcc\xf4U\x9c" +
+	"\x083hAg\xf9\xa8\x92s\x9e\xe3\x8d{\xaeN\xd5" +
+	"1R'\x03\xaa\x0fL+\x8f\x9f\xa3=\xc7AT\xb9" +
+	"\xfeK\xa2\x8e\xf6\xab\x0e\x15\xfctzpk\x17\x88\x1a" +
+	"\xaf\x1f \xef\x96\xebd#/\x7f\xf0\xda\x1f\xc7G\xa2" +
+	"[\xd3\xdcl\xb5\xfeih\x83N\x84]\xa7O\x09\xbd" +
+	"I\xfe\xb3\xdb\xfec\xf4\xa6\xbbv/\xdf\xca-\xb4]" +
+	"\xa7^\xb6G\x0f\x00\x9e\xca-\xfd\xd5\x93\x17=\xb9M" +
+	"\x1e\xea/\xb6N\xa7:\xd9L\x17\x9b\xf9S\xe9\x93\x07" +
+	"o|~\x1b\xef;\xe7&\x1a\xc8\x80\xe1\x09\xb2\x93\xc4" +
+	"\x8a\x1d\xdbo\xba{\xf2\xf6T\xb0\xa2J+K\x90\x98" +
+	".OP\xe4?o\xc0\x8d\xd2]\xcb\x8f\xfd\x96\xd7\xda" +
+	"\xca9\xbf&Sm\x98C\xa6\x9a\xb2\xb4\xe2\x81\xb7o" +
+	"\x13v\xf1\x03\xf6\xcd\xa1\xc8\xff\x1e\x1d\xf0\xfc\xe3+\x16" +
+	"\xbe\xf6Dbw\xda\xce\xd18\x10\xca3\xa8\xc7\x18/" +
+	"\x86:\xc8\x7f\xf6_\x1f\x99\xf0\xee\x0d\xf7~\xb4\x9b\x9f" +
+	"m\x8eA\xb7\xd6f\x90\xd9\xde\x1d\x97\xf5\xaf%C\xef" +
+	"\xd8\x93)\xc0\xc6\xad0\x042r\xa5A\x94\xf0\xbb\x15" +
+	"\x95\xea37_\xb2\x97\x9f\x0aM\xaa\xc5<\x93L\xb5" +
+	"\xc5\xfez\xcd\xa1]e{A\xfa\x81\xe8\xc3\x12\xc1i" +
+	"\xf3,\x0c\xcd7\xc9\x0b7\x9bS0$[D41" +
+	"\x12\xb8\xe8\xe3]{_\xe1\xa3\xe5\x1f\xac\xe5\xd4\xc4\x16" +
+	"\x99O\xdb^s\xef\x1d\x9b\x1f\xdfG|\xd5\x1b\xa1Y" +
+	"T\x15\xad\x16\x11\xe9\xce\xe0\x9c\xc7\xceZ\x1ax\xdd\xf1" +
+	"0j\xd7\x83\xd6#\xe4\xfb\x93V\x00\xd0\xfe\x9e1\xe0" +
+	"\xd0\xfdo\xcdz=%\xaa\xc8\xc0\xd0>kgh\xbf" +
+	"Ea\xc4\"\xee\xdc\xb2\xea\x95\xb7\xae[\xde\xb1\xdf\x01" +
+	"\x10:Wgr\x0bq\xc6\xa3_\\\xb9e\xf0\xd9O" +
+	"\xbf\xed\x0aJ\xa1eY\x92\xfaqg\x92@\xcb\x94\xec" +
+	"s\xa6o\xdd\xfd\x93w\xf8\x9d\x944Q%Om\"" +
+	";\xd9{\xc7+\xb7XU\x13\xdeqA\x9c\x06]\xac" +
+	"i\x0b\xb5B\x13\xd9\xc8\x8c\x7f~\xe3q|\xe4\xc8A" +
+	">T\xf67Q\xec9Igx{\xe3WO\x8e\xdb" +
+	"\xfa\xd1\xbbiF\xcfk\xfe\x140$5O\x09\x8do" +
+	"&Z]\xfa\x83\x1d\x9b>{*|\x88\x07\xa9A\xcd" +
+	"\x14\x9fG6\x93\xa5\xde\xbdaL\xff\x0d\x1f\xce?\xcc" +
+	"\x0b;\xbf\x99\xeefI3YjW\xc5C\xcb\xdfZ" +
+	"x\xcb\x11.X\x9ei\xa6\x10\xb2\xa7\x99(\xf5\xc3\x87" +
+	"\xf7]{b\xb6\xfa>\xef\x07\xeb\x9c\x09\xb6\xd3\x09Z" +
+	"\x97o;\x7f\xae\xb5\xe8\xfdT?\x08\xbd\xd7\xfci\xe8" +
+	"$\x912t\xa2yJHj!\xe9\xd8\xcb\xd7\x19\x80" +
+	"\xaf\xbceKHn\x19\x01\x10\x8a\xb5\x10\xd17\xdd\x16" +
+	"j\xe9\xb8\xf6\xe8Q^\xf4}-T\xcf\x07[\xc8\xca" +
+	"\x9d\xd2\xec\x9d\xd9\xb5S?\xe0\xfc\xa1\xac\x95\xe6e\xb9" +
+	"\x95\x88~\xfb\xbc1\xeb\xeeY\xbf\xee8HC\xfd$" +
+	"\xd1JE/k%\x96\xbc\xe8\xbf\xc6\x14=\xf1\xfe/" +
+	">\xe2\xb5w\xb0\xd5I\x12\xadD\x84\xce\x1dS7\xfd" +
+	"\xf4w\xa3>I\xcd\xb6\xd4\xa5\xca\xe7\x92@\x9f:\x97" +
+	"\x06\xfa\xe2K\xbf,S\xf3^\xfc4\xcdb+\xe6\x1d" +
+	"\x08u\xce#3\xaf\x9e\xf7\xa2\x10J\xb6\x11\xb3\xad_" +
+	"\xfc\xf6\xf5\xfd\x87\x8c\xf83'\xf9\xac6\xaa\xf49m" +
+	"D\xf2\x85?\xb9\xe7\xd0\xbc\xd6\xa9_\xa4q\x9ck\xda" +
+	"\xce\xc6\x90J\xe6\x08)mSB\x1dt\xb6\x11\x93\xa7" +
+	"/\x1c\x1d\xab\xfc\x823\xe1\x9c6'\xe8\xe9l5\x1f" +
+	"\x1f;\xb0\xe7\xc0\xf7\xfe\xc2}\xaf\xb4QoK\xd2\xef" +
+	"o\x93\xa6\xfd]\xd9_\xde\xf9\x92\xfb~z\xdb\"\x12" +
+	"\x0b\xffx\xfe\xb8\xc6\xe5\xbb\xd7|\xc5;jy\x1b\x85" +
+	"\xaf\xe9m\xc4\x04?\x7fd\xc8s\xf7\xb7\x0c\xfd_\xde" +
+	";Z\xdb(J\xcc\xa7\x03\xbe^\xbbv\xf8\xc6\xbd\xe7" +
+	"\xfe\x957\xe2jG\xb8\x0dm\xc5`\xf7\xe9\xef\x98\xad" +
+	"\xb6X\xaa\x11W\xa28:\xac$\xe2\x89\x89%\xc5\xe1" +
+	"\xb0\x9e\x8c[\xf2\x001\x0b \x0b\x01\xa4e\xe7\x01\xc8" +
+	"\xf7\x88(?,\xa0\x84X\x80\xe4\xe1\x8a\"\x00\xf9>" +
+	"\x11\xe5U\x02\xa2P\x80\x02\x80\xb4\xb2\x06@~XD" +
+	"y\xad\x80\x92(\x14\xa0\x08 u\x92\x87kD\x94w" +
+	"\x09(ea\x01f\x01H/\xcc\x00\x90\x9f\x17Q\xde" +
+	"+\xa0\x94\x8d\x05\x98\x0d \xedi\x00\x90\xff]D\xf9" +
+	"\x0d\x01E-\x82\xfdA\xc0\xfe\x80AC\x8f\xaa\xec\x83" +
+	"\x1dQ\x95\xb0\xa55)\x10\xb0\xd4\x08\"\x08\x88\x80v" +
+	"\xd8P#j\xdc\xd2 \xa0DM\xfc>`\x85\x88\x98" +
+	"\xef\xb3\x01@\xf2\xd0\xae3\x14-~\xb9\x9e\x041n" +
+	"a\x0e\x08\x98\x03h\x9b\x96n(uj)\x04[-" +
+	"\xd5\xc4\\\x100\x17\xd0SL\x16SL$\xa6\xc5\xab" +
+	"T\xd3\xd4\xf4\xf8hS\xb5*\xf5\xa8:\xacR5\x93" +
+	"\x81\xa8e\xcaY\x9e\xb6\xf2\xc6\x02\xc89\"\xca\x05\x02" +
+	"\x16\xaa\x86\xa1\x1b\x98\xef\x93\x01@\xcc\xe7&\xcfv'" +
+	"\xbfF\xbbVS\x9bG_\xae\xc7-C\x8fFU\x83" +
+	"\xac0M\xb3\xdc%\xa2\x16~\xeb%\x98\xfc\xd7j\xa6" +
+	"f\xe9\x06\xdbA\x93\xa66\x9b\xbd\xcaOG\xa1\xe4C" +
+	"\x11 J\x19&/s?\x97$\xb4\xd1u\xaa\xe5." +
+	"b\x0e\xab(T\x0c%fz\xe3\xfb\xb1\xfd\x9a\xaa'" +
+	"\x89\xa16\xe9\x8djI4z\xb5^\xe7i\xd8\xf4\xf7" +
+	"\x9d\xe3\x896\x92\x886LDy\x8c\x80\xcc\x0fG\x91" +
+	"g?\x16Q\xfe\x99\x80\x85\xd4s\x99a\xfb\xac\x99\x8c" +
+	"\x96\xadP\x0cE\x8cuY\xbd\xd2_\xc9\x0b\x83\x0bI" +
+	"\x18\\ \xa2|\xb1\x80\xb6\xe2\x84N9`7\xce\x9b" +
+	"fvN\x0dq\xdd\xd2j\xb5\xb0b9\x8a\xa3z\x03" +
+	"\xde.E\xae]f\x0a\x18\xd4\xe2\x96\x8e\x92}\xf4\x82" +
+	"\xa1\xc7\xbf\x1e\xde\xb2\x14\x00&\xa1\x84\x85r\x96\x80\xfc" +
+	"C\x09G\xc89\x88\x88\xe4ED9_D*K\xbe" +
+	"\x8f\xbf)\x06\xcd$Y4\x83]L*\x8c\xa7\x9a\xab" +
+	"\\\xd5L\xe6TSB,s\xa9\x88\xf2\x95\x02\xda\xa6" +
+	"\xfb:\x00\xf8\xe1\xe9\xb1='<\xfbl0\xde\xdb\x0c" +
+	"\x95\xc4\xafc\xafX\xd7(!\xf6\xea/\xa2<\x80\xac" +
+	"o%\x8dHk\xa5\x0aX\x8by `\x1e`\x1a\xf4" +
+	"\x95\x17\xc6\x9b4K\x95\x87yS\x9c$\xc8w\\D" +
+	"\xf93n_\x7f\"\x86\xf8o\x11\xe5/}\xe4\xfb\xbc" +
+	"\x14@\xfeDD\xf9\x1b\x82|\xe8 \xdfW\xe4\xe1g" +
+	"\"V\"A>\xc1A\xbeS\xe4\xed/E\xac\xca\"" +
+	"O\xb3\x05\x0a}!D2\xf6\x1b\x11\xabr\xc8\xe3~" +
+	"b\x01\xf6\x03\x08ec%@U\x16\x8aX\x95O\x9e" +
+	"\x07\xb2\x0ah\xc1\x96\x875\x00U\xfd\xc9\xf3\x1f\x93\xe7" +
+	"9C\x0a0\x07 4\x1c'\x02T\x0d!\xcf/\xc0" +
+	"\xeeA\xb4=\xa6\xb4\\c\xaa&\x8b\x94v\xb5%\xa1" +
+	"\x19\xaa\x89\xd9 `6`0\xae[\xfe\xe0\xb0\xa1*" +
+	"\x04k\xdd/m\xf7s)`\xab\xe7\xd8\x04~c\x09" +
+	"\x82\xbfz\x9c\xc3_\x8f\x14;\x06.N\x9aJMT" +
+	"\xf5P\x9b\x19@t\x0dP\xa1\x84\x1b\x95:uty" +
+	"\xdc\xb4\x94h\xb4\xca\x0a\x1a\xaa\x12\xab@\x94\xb3\xc4l" +
+	"\x00\x8fZ\"+R%i\x06\x08Rn\xc0\xaeS-" +
+	"\xfa2\x88u\xea$\x94\xb3\x10\xed\xea\xf7_\x1d\xd9|" +
+	"\xf1u/\x03@\x8f\xf0\x13S\x8c\xc6\x7f\xe2c\xafR" +
+	"U\"=\xc5\xdf0\x01\x83\x8dj\xab\xb7M\xa2\x83\xef" +
+	"\xf7\x12Bn\x8e\xb9\xc6T\xeaT/\x82\xe4|or" +
+	"\x85L>SD\xb9\x9es4\x95\x04\xd0l\x11\xe5\xa8" +
+	"\xefh\xdaD\x009\"\xa2|\x13\xe7hmd`\x8b" +
+	"\x88\xf2-\x02\x06\x93\xa6\x1aaI\xacpNR\xb7\x14" +
+	"\xf6\xa9\x98\xa6?\xce<^S\xe04\xe3\x8f\xdfY\x8d" +
+	"\x12nL&\xa6\x90\x99\x19\xda\xf3\xc0P\xea#\xb6\xb7" +
+	"\xafQE>\x90\xb6S\x99\xca}?u\x91\xcd\xb7^" +
+	"\xe6\x84\xd3\x05\xb3\xa3\x9ai\x95\xd3\xd85\x87\x15;\x96" +
+	"\xfb\xce\x80\xd3k\xa7\xa5\x08&\xa4\x0a\x16\xd0\xf4\xb8<" +
+	"\x80\xfa-\xe3\xdb\xc8j\x06iC\x03\x88\xe8\xf7\x07\x91" +
+	"\xf51\xa5e\xa5 \xa2\xe0\x95l\xc8\x8a;\xa9m." +
+	"\x88(z}\x07d\xc5\x94\xa4\x92\xa9\xb2\xbcV\x19\xb2" +
+	"\"J\x9aZ\x03\"f{<\x11YWE\x1a\xdf\x00" +
+	"\xa2MtV\x12\x0e\xeb\x10L\xc6-\xb3\xddM{\xb6" +
+	"\xa9Z\x93\x09\xc3\x82b\xad\x89D\xb9\x1b\xed\xe5q\x08" +
+	"\x12\xfd\xdaL\xd5\x84~\x99vD\x8d\xaa\xfe\x97\x15\x88" +
+	"i\x91V\x92\xb4\xea\x091\x0b+\x96NHM<R" +
+	"\x16S\xb4(y<MoT\xe3~0|Kz\xc3" +
+	"\xc0\xfb\x9aBJ\xa1\x88\xd5\xb8\x02:w\x06WE\xe5" +
+	"\x96\xda\x8c`\x81\xa8\x1a\xed?W[\x0d-^'\x0f" +
+	"\x14\xb30\x8b\xae\xfc\x0c\xa1\xa5\xbf\x11Q~^\xc0|" +
+	"\xd7[\xb7\x13i6\xb9\xfc\x95\x85\xe1\x0b\x0d\x1c\x7f\x15" +
+	"D'\x0c\xf7T\xfa\xfcU\x12\xb3\x1c\xbc\xdfGR\xe3" +
+	"k\"\xca\xef\x08\x88\xd9\x0e\xd1\xddO\xa2\xe2\x0d\x11\xe5" +
+	"\xc3\x04\xeb\x91b\xbdt\x90L\xf9\x8e\x88\xf2q\x01\xed" +
+	"0''J\xfe\x86\x1c\xa7+\xb4\x083\xf4\xc0\xd7\xcd" +
+	"\xad\xd3 H4\xea?N\xd6D\xf4\x98\xa2\x01\xfa\xcf" +
+	"\x08\x9f+\x8f\xd7\xea\x00\x80\xf9\xb6zlM\xc9\x94\xf1" +
+	"\xff\xb2\xcd\xd5i\xbbe(f=\xc7\xaa{a\xc6}" +
+	"GS\x9f3\x9c!\x89\xe5\x97\xa2\xc8q\xb5^\xe7Q" +
+	"\xb5\xfe\xde\xdceD\xc1\x93D\x94\xaf\xe6`\xa7\x9c\xe0" +
+	"\xc0d\x11\xe5\x0a\x1fN\xa7\x128\xbdRDyZ\x9f" +
+	"\xa1\xa8\xb8V\x8fF\xf5\xe6\xb4$\x96JN*\x13a" +
+	"\xc2O(y\xf2D\xecf\xfb\x165\\*/\x11R" +
+	"c(H\x82\xc8O\x87\xac\x1b\x82\xac\xb7-I\xcbA" +
+	"\x90\xf2\x026\x8b3d\x81&\xaa\xf1IX\x81\xe9\xd2" +
+	"\xa6\x17\x1e\x89\xa4ArT\xb1c\xb2ok0&;" +
+	"M\x0cU\x96n\x04\x94:\xb5\x9b\xc4\x90\x89\xca\xa7\x1a" +
+	"\xa3\xb0\xa6G\x17Lc\x0e\x848\x8cf\xac\x80\xcf\xb9" +
+	"\x9c\x93\x9c\x97\xc9IJ}'\x91\x04\xe6%c}/" +
+	"\xe18U{\xc2\x99\x1c\xf3\xf9N\x02QC\xdf\xfd\xd9" +
+	"Q\xbf\x8bD\xa3\x15\xcbR\xc2\xf5\xc4[\x02)9l" +
+	"\x06\xc7e{\x06\x87\xf4\xf0L3qLiT\xab\xea" +
+	"\x15\xb2$\x0f\xc2\xdd\xd6ZR\xc6b\xcb\xe2\xd1\xa6\xef" +
+	"\x95n\xb7%O\xc6\xc2\xa2!S\xcd5\x96\xab\xb9\xcc" +
+	"d\x8d\x196\xb4\x04\x04\xc9,(\xd9\x87Kg\xcf^" +
+	";\xec\xb3\xfb\x18V\xf6U\xb0.\x8c\x82%6\x92\xd6" +
+	"\xfev\xe8\x95\xb1\x04w\xa1!-r.w[\x1a\x0a" +
+	"Fy\x85\x14e2\xcbU\xbeB\x82Vk\x82K\x0d" +
+	"a=\xa1F\xca#\x00\x90V\x84\xf6\x08X\x99\x1a\x03" +
+	"C\xfd-\x07\x94\x84\x86\x92\xdfB\xff\xf6\xae\xe7\x12\xed" +
+	".F\xaf\xc9TM\x16q\xd5dB5b\x9aiv" +
+	"-7\xd0\xe1\xe1]\x8a\x97\x9e\xad\xcc\xb8\x0d\xb52c" +
+	"\xfc\x99I\xb9\xc7\xc9K}N.\x09\xe8\x92\xf2R\x97" +
+	"\x94'8R\x1e#o\xd7\x8b([\xc2\x19T_\xe9" +
+	"\xc9 \xa1\x95\x11w\xeb\xbdz\x98\xe8Jz\x83\x9f\xee" +
+	"Z+\xfdB\xc1k\xd0\xddL\xd4}\x93\x88\xf2\xbf\x09" +
+	"\x18\x0c\xeb\x11o\xed\xe2\x04UIj\x9dc\xa8\x96\xd1" +
+	"\xaa\xd4D\x01\xfdJ.\xa2\xd2 \xb4\x80\xb0\xde>\xb5" +
+	";\x9c\xae\x0f\xdf\xf2\x19V\xa9\x16\x9eQ\xce\xe9C3" +
+	"\xcd\xf3\xb6\xee\x12q\x17b\xc5&\xee\x9f\xa1\xef\xa0\xf0" +
+	"\xc4\x96\xcd\xcb^\xc8\xb0\xdfd\xdcP\x95\x08O\x8d." +
+	"'\xbd\"\x07\\D\xeb;ls\x09\xa9\xe8SH%" +
+	"\xf2y\x04;MEv\x17B\x92\xc6\x82 e\x07\x9c" +
+	"\xf6_7\xc4\xa1\xbb\xea\xd6-\xc0*\xc4\xac4\xc3\xb0" +
+	",\xcdY\xc6\x09A/\xf8zQB\x8d\x8fs)\xa6" +
+	"\"Ma=^\x1e\x87@Dm\xf1Z\xbb}K\xb7" +
+	"\x95\xaa\x19\xfc[p\x1d\xcf\x99\xdd\xd2\xaf\xe7\xfe9\x09" +
+	"\xc5\x07D\x94\xd7pDc5I\xf3\xabD\x94\xd7\x0b" +
+	"\x88nU\xb1\xae\xd4\xed\x9f\xff\x86\xeb\x9fo \x88\xbf" +
+	"^Dy\x1bi\"\xdd\xe4\x94\x15\x9bK\xfd\xea\x85\xa3" +
+	")v\xd2T\x8d\x92:5\x0ehq\x0d\x9b\x98n\xa9" +
+	"%\x11\x10#\x86\x07L\xa6\xa5\x18|\xa7'\xaa\x98V" +
+	"\x95\xaa\xc6\x01\x80=k\x0f'\x0dC\x8d[i\xf4\xb7" +
+	"\x8f1^\xa1\x04S\xcb\xf3\xf3|U\xf3R\xf74\xb1" +
+	"\xe6\xd0\xbc\xae\xe4\xaek\xf3o\xa2?k\xb1I\xe9 " +
+	"J\xfe\x0d\x97n\x8aw/\xc0\xc5\x84F\xe2\xa3?\x8d" +
+	"\x0fv\x03\x08\xd91\xa8$\xd7\x80 \x95\x07\xd0\xbf~" +
+	"\x83\xec\xbcP\xba\xac\x14\x04\xe9\xc2\x00\x0a\xde\xb1<\xb2" +
+	"\x93>i\xb8\x01\x824\x88\xb6\xab\xaaT\x96\xbf&a" +
+	"\xbb\xdb\xc4\x9c\x846\x83\x15(\xa4\xc0\xd25\xf626" +
+	"g5\x93\x91\\\xf3\x8c\x8bi\xd6\x88sRb\xa5\xdb" +
+	"\xd4\xd3\xe3\xd0M\x1b\xbc\xcf]pK\x8b\xa9\x9e[\xf5" +
+	"\x04%]\xb6\xf3]\xf7rx\xd6.ePCZ\"" +
+	"\x01 ^Q@\xbd\x82]\x11Av\xdfE\xbas\x11" +
+	"\x08\xd2\xe2\x00\xfa76\x90]?\x93n\xbe\x8a\xf6u" +
+	"\xd8A;\xb2\xf3?I\xab\xa1}\x1dvn\x89\xec\x04" +
+	"[\x92\x17\xd1\xbe\x0e;\x8aEv\xc5G\xbal,\x88" +
+	"6\xe3Q\xc8\x88\x14\xd9\xbc\xcd\xf2\x1c\x00\xd81\xbdI" +
+	"\x9d\xa6O3 \xa0\x98\xf5\xb6\xebaW\xa0\xa1\xc7\xa6" +
+	"\x912\x1f\xa0\x90Vz\x15\xd8\x0bIb\x1d!\xa7\x1f" +
+	"\xe4\xe7\xab3L\xd2=\xad\xc1z\xf9\xbd8\x1d\xc9\x06" +
+	"cD\x94/\xcd\xect\xdd\x1c\x15\xf6\xce\x16\x98\xe6\x14" +
+	"\xd3+\xc5\xf8l\xc6\xc0\xa2\x8a\x1diTP\xa7\xf2\xc8" +
+	"Xi&2V\xc4\xd3F\xd6\xcb\x1d\xcb\xd3F\x17\xee" +
+	"cc}\xda\xd8\xde\xe4\xa4l\x94\xfck:\x8e\xab\x92" +
+	"./y\xec\x1d\x8e\xbb\xc5\x8eB\xb4\x8a\x92\x7f/\xee" +
+	"4\x8b\xa0^\xebh7$O\x83W\xfb\xd5So\xa5" +
+	"\xf7\xd0\xbe\x97\xde\x81\xa4\x11=\xed\xd2\xb3\xdb\x9e\xf1\x99" +
+	"\xc2fv_\x9b\x9d\x0c\xd0zk\x90O\xe4\x1a!J" +
+	"$b\xa8\xa6\xe9\x91\xf2\xa8\x1eV2p\xd4\xeeK." +
+	"\x16\xfa,\xf2\xcf\xf8D95[V\x16\xd2\xca\xd1a" +
+	"\x93\xecZ\xa3w\xc3N\x1a\x0bb!-*3\xd1\xc8" +
+	"LGy\x99(\xf1\xd0^\xba\x11\x81\xb0\x92\xc0\xb3\xb3" +
+	"D@<\xfbL\\\xa2\xc4\xc1\x11\xf3\xcc\x8b\xfe\xd38" +
+	"\xeafUI&\xce\xdc}\xcd\x91QB\x83\xeb\x13\xa5" +
+	"p\x09\x94\xfc;\xa5\xdd\xf0\x1f\x8f\x1f\x17R\x82\xec\x97" +
+	"\x08\xec\xd2'\xb2\x1b|\x924\x91\x96\x08\xc5\x0e\x87v" +
+	"\xcf\xdcV-Y]vp\xb0\xb8\x90K\xc4\xde\xa3\x9e" +
+	"\x121w\xb9)\xad\xb1_Q\xec@\x0fy\x95\xbb\xbe" +
+	"\x93;\x83\xbb\xaf\x9ckt\xe9\xec\xdb\x0c\xbe\xa0\x90\x02" +
+	"X\xa6\x93\xea.\x0d\xa5\x19\\&\x89)q\xadV5" +
+	"-\xa7E\xfe\xd2{\xc7\xb4\x86\x91\xd5\xf3\x99uSz" +
+	"o\x9e<\xdd\x1c\xa3w\xef_\xdf\xf5\x01\xbfw\x87\xbe" +
+	"\x9b\x03\xb4\xee\x1a\xbf\xee\x19\xde\xe9\x95o}\xc7\xf8\xa2" +
+	"\xbec|\x90T\x9f]\x9d\xe3\xf4z\xab\x99\xce\x0a\xbc" +
+	"\xbe\xd6\xffk\x8f\x91\xc5\x97W\xfe\xa7V\x88\xa5\x99*" +
+	"\xc4\x19\x99*\xc4\"\xfe\x8a\x95\xcb\x19:\x8b\xb8\xb2\xd1" +
+	"=wZW\xc4\x95\x8d\xd9\x93\x9c\x0aq\x03y\xb8V" +
+	"DySz\x87\xdd\xb9\x1c5M\xb3@\xf4\xd3K0" +
+	"\xa1X\xf5\xde\x07Km\xb12RzrN\x9f\xce\xb0" +
+	"\x84TC\x88N\xd7\xe1\x02\x0a)\xecn\x1c\xb2\x8b\xba" +
+	"!\x19\xe7\x82\x10*\xc7\x00\xfa\x97d\x91]\xbc\x0d]" +
+	"\x86\x0d \x84\xc6#\xa9\xac\xd8\xcf&\x90]%\x0f\x8d" +
+	"D\x83\xf2h\xf6\xab\x04d\x17\xf4C\x12n\xa4D\x9a" +
+	"\xdd\xd5Cv'Z:\xb5\x93\x1e\x90\xb2K\xcd\xc8~" +
+	"\xb0 \x9d G\xaa\xfd\xbc\x9f' \xbb\x8b'\xed#" +
+	"d=\xe0\xdd\xedGv\xcdQ\xdaL\xe8}\x8ew_" +
+	"\x10\xd9\xcf6\xa4\x95D\xac\\\xef\xb62\xb2\xcb\xdeR" +
+	"\xc7r\x10\xf1,\xef\x92(\xb2\x1f\x93H\xc9- \xda" +
+	"\xac\xc0\x05\x17\xfc&\xa1\xcd*#\x08\x92\xdah\x12\xda" +
+	"\xac{\x0e\x85\xb4\x7fn\xb3\xa37d\x0d\xa6Bz\xf8" +
+	"f\xb3\xce\x93\x98\xd2z\x02\xd6\xb6\x09\x92\xbe\x8d\xcd\x0e" +
+	"\xf2!\xa0hq\x9b\x05\x0c\x00\xd8\xec\xfe\x0f\x14\xd2t" +
+	"e\xb3\xb2\x1eY\x0e\x13\xf5\xb8\xcdR\x1b\xb2\xdcV\xec" +
+	"$\xb7L\x87\xc2}\xe0(\x99R\xa2\xd8\x1d\x0e\xa1\x7f" +
+	"(\xc6\xae\xa0sWTY\xa6r\xb0\xaa\x0f\x15u\xa6" +
+	"\xdc\xcc\xcb\xd1\xef4\xda{n)\x93\x09N{hh" +
+	"\xb0\xdd\x9ff\xb1r\xa6\x8d\xac\xde\xeew\x9c\xe9\xa1`" +
+	"\xefG-\x19\xb2b\xe6\xf6\xd0\xff\x0d\x00\xd5lr\xd8"
 
 func RegisterSchema(reg *schemas.Registry) {
 	reg.Register(&schemas.Schema{
 		String: schema_9498f3818bafa387,
 		Nodes: []uint64{
+			0x850e687fe8633795,
+			0x85321f85ba1cf627,
+			0x86a151ee10ce7362,
 			0x86d93be2b0117c03,
 			0x88aebbd9bae8a37e,
+			0x890c5c08a8a480be,
+			0x8965c7443ba16da4,
+			0x8aa84d2db3cf9162,
+			0x8e2e8721731ec4d5,
 			0x8ffd2a91343778e2,
+			0x928ddb974d0fd599,
 			0x92a11e1fa7da1a1e,
+			0x94274548df015436,
+			0x95696a867ac7a014,
+			0x98774497e4bebb38,
+			0x9b5f616a2bd490cf,
+			0x9d05d974c6d66002,
 			0x9d3fbd3710589c73,
 			0x9efbad5f3a5b9820,
+			0x9fd7a614223c08a3,
+			0xa0512876e6a3a9ca,
+			0xa118bfbae000f5a8,
 			0xa1509e65e6b83ff0,
+			0xa4bc2673be08fc37,
+			0xa71468e4258a20d9,
 			0xa8312a5c0aed89c6,
 			0xa97e44e1d89b7811,
 			0xab5851e986c119a6,
+			0xac86a563a19f9ce0,
+			0xace73109a97b2651,
 			0xad603b3a84bcd1c2,
+			0xae7109d77a5c5672,
+			0xaf3be100c4965fdf,
 			0xb0d3e2aa469b06cd,
+			0xb3e01d65b61c465c,
+			0xb6b67a93f0f993f6,
+			0xb6d9268918b91cbe,
+			0xb717412d49a9861d,
 			0xb769176e4954da5f,
+			0xbb0f592f14df4a7f,
 			0xbb6c6435d8d0e5cd,
+			0xbb9ac592b82ecb7d,
 			0xbcae36ae8e420009,
 			0xbcc07e9ef0112f5c,
+			0xbd449380bdbe9d70,
+			0xbfe69a92117e181a,
 			0xc4028bdb9c509747,
 			0xc570abd0889da7c0,
 			0xc5ea967cde37a2fe,
+			0xc9902241830433de,
 			0xcc3b81b565529dc3,
 			0xcc45c4dfa8fbffba,
+			0xceccc4ee36076403,
+			0xd1a7b9909662bd69,
+			0xd307970aa6710f91,
 			0xd35dd79bdf18720b,
 			0xd9899a57d7cea478,
+			0xdbb3121eba48f6e4,
+			0xdc2bc5bb59170547,
 			0xdc37537484ce90cc,
+			0xdde2a201a7d44f5a,
+			0xdeeabb33aef9b2db,
 			0xdf63aff4b8be1697,
+			0xe085e7b10c307cde,
+			0xe28488d79a9f50c4,
 			0xe36560e956d1a0e7,
 			0xe38a747a26bc9a79,
 			0xe44c74b23c0d4ccd,
+			0xe4e4568978138bb8,
+			0xe54d6605c26011a9,
 			0xe8adb094ad307b8f,
+			0xea5be3ab2a30eb36,
+			0xf02dc32fb84dbea9,
 			0xf2c70d6545f83c8d,
+			0xf327200c58db8db0,
 			0xf64d797bdf942b88,
+			0xf6526d2e88594427,
+			0xf70bdac9dae6ee62,
 			0xf8dcf7451554118b,
+			0xf9a8c59a6b33263e,
+			0xfa22789bb720a24b,
+			0xfe19ccb225acacfb,
 		},
 		Compressed: true,
 	})
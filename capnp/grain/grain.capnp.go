@@ -9487,353 +9487,362 @@ func (f MainView_drop_Results_Future) Struct() (MainView_drop_Results, error) {
 	return MainView_drop_Results(p.Struct()), err
 }
 
-const schema_c8d91463cfc4fb4a = "x\xda\xdcz{x\x14\xe5\xb9\xf8\xfb\xced\xd9\xc4&" +
-	"M\x86\x09\x92HH\xb8\xc4\x1f1\x95@\x12@\xa5\xd2" +
-	"\xdc\xa4\x98\x08\x9aI\x80\x02?\xe9\xc3\xec\xee$\x19\xb3" +
-	"\xd9]fw\x09\xa1E\xc4\x03G\xa9x\xaa\x14O\x95" +
-	"G\xbc\xa0\x9c\x8a\x85\xb6\xb6P\xc1j\x85V+`\xf1" +
-	"\x82\x88b\x0f\xad`\xb5\xa8\xa0\xa2V\xc5\x0bs\x9e\xf7" +
-	"\x9b\xf9fg7\x9b\x0b>\xe7\xe9\x1f\xe7\x0f\x1f\xc3\xec" +
-	"\xfb}\xdf{\xbfO\x9c&\xd5fT\xe6\x0c\xaf\x00\xa1" +
-	"\xb5@\xf0\x0c17\xd6\xce]\x7f\xfd\x03KW\x81\x94" +
-	"-\x98M_<\xf5\xbc?\xff\xc8^\x00\x94O\x9f\xff" +
-	"\x8e\xfc\xd5\xf9^\x00\xf9\xcc\xf9+\x00\xcd\xd7\xc7.l" +
-	":=\xea\x8d\xd5 \x8d@\x00\x0fz\x01\xaa+\x87/" +
-	"@@y\xda\xf0\x1a@\xf3\xa8z\xf9ss\x1a\x8f\xdc" +
-	"\x04R\xa1\x05\x90\x87\xd5\x0b\x87\xd7\x13\x80\xc6\x00\x9e\xff" +
-	"\xde$y\xf7\xd65\xb7\x802\x06\x11 \x83nX=" +
-	"\xdc \x80\xdb\x19\xc0\xb5/\x89\xc3\x1f\x884\xadu\xdf" +
-	"\xb0k\xf82\x02x\x9a\x01D\x97\xef9\x95s\x8f\xb2" +
-	"\x16\xa4a\x0e\xc0\x9b\xc3\xcf#\x80S\x0c\xa0\xd4W\xf7" +
-	"\xee\xc1\xc9\xc1\xb5\xf4\x84h\xae<\x95\x13\\\x13.{" +
-	"\x1c\x86\x09^\x04\xa8\x96\x0aZ\x08tdA7\xa0y" +
-	"\xf5\xea9\x1d\xbb\x0f\xed\xbd\xd5z,\x83\xae\xea\xa1\xdf" +
-	"3\xcc\x7f\xbf\xfap\xe3\x1d\x1fW\xfc\x18$\x99\xa3\xa9" +
-	"\x15T\xd1/\xd7\xff\xf9\x8d\x97\x1e\xda?d\x9d\x1b\xc1" +
-	"Y\xf4\x13\xcas\x0a\xe8\xfd\xc9\x9f\xe7o|\xebH\xde" +
-	":\x0bAvi\xbc`(\x1d=9\xaf\xf0\x96\xdd\xb7" +
-	"\x8d\xbb\x93.5\xffq\xbc\xfd\x9d;_\xd9\xfe\x17\x8b" +
-	"\x8d\xf2\xc2\x82\xcfe\xbd\x80\xfe\xd2\xac[~\xf1\xbb\xb1" +
-	"O>\xf7\xfe]n2W\x17\x94\xd33\xb72\x80\xc2" +
-	"\xc0\x97\xcb_\x1f\x7fx\x83\x1b`[\xc1\x18\x02\xd8\xc1" +
-	"\x00^\xdc\xbf\xf8\xbb\xe2\xb8#\x1b@*p\x84u\xa4" +
-	"\x80\x09\xebM\x06\xb0\xf1\xa3\xe8_\xdb\xfes\xd8F\x90" +
-	"\x8a\x1c\x80\xc9\x85\xbf&\x80\xe9\x85\x04p\xf7\x90k\xfe" +
-	"\xf2\xb9P\xb7\xd1\x127c\xc2\x1d\x85MDI\xcf\xa8" +
-	"\x93\xb86\xf2\xdb{,\xf6XGW\x16\xb2\xbboe" +
-	"GG]\xfdX\xd9\xfcG\xbd\xf7\x82t>\x9a\x1d#" +
-	"\xdb[\x8f\xed\xfer\x07xD\"p[\xe1k\xf2\xae" +
-	"B\xfakG\xe1/\x01\xcdu_\xe54\xbe?%x" +
-	"\xaf\xadU\x16\xb3/\xf8\x11]\x16\xbf\xe0\x1f\x80\xe6\xe8" +
-	"\x85\xef\x1e[\x97{\xe8^7\xcb\xf5\x11\xec\xb5\xf8\x08" +
-	"zm\xe9O\xbfw\xa6h|\xf9&7:w\x8c\xb8" +
-	"\x8e\x00\xeeg\x00\xd7\xde\xdc\xf5\xb7\xf9So\xda\xe4\x12" +
-	"\xf4\x9e\x11\xeb\x88\x92\xd8\xc6?\x95\x9e\xcd\xbe\xe6\x01P" +
-	"\xb2QL(\xbfG \xfc\x1e\x19\xb1_~b\xc4p" +
-	"\x00y\xdf\x08\xc2\xf4\xc4\xd9?\xceZ2\xe1\xb3\x07A" +
-	"\xca\x16\x93\x0ceV\xd1qy~\x11\x01jE3\xe4" +
-	";\xe8/3R\xd69=k\xeb\xe4\xcd\xae7W\x17" +
-	"m\xa27\xff\xb6~\xcds\xbf95u3(2\x19" +
-	"\x81g\x08\xe1\x1b/\"\xc5\xac^Y\xf4c\x044\x9f" +
-	"Y\xbf\xae\xec\xa7\xe6\xc7\x0f\xb9)*,fZ6\xba" +
-	"\x98(\xba\xe5\xbe\xe2\xf6}\xdb\xbe\xb3\xc5%\x9b\xbab" +
-	"\x1f\xdd.]\xff\xc5C/oP\x7f\x0eR\xa1\x98 " +
-	"\x0f\xb0z|\xf1P\x94\xa7\x15\x13a\x97\x15\xcf\x90U" +
-	"\xfa\xcb\xd4\xae-[\xbdb\xcc\xd6mn\xde6\x163" +
-	"=S\xd8C;g\xdctt\xc3\xd2\xcf\x7f\x99B3" +
-	"\xe4\xa1\xbc\xb8\xf8\xa0\xbc\x9c\xdd\xd7S<C\xdeW\xec" +
-	"\x95\xf7\x15\xe7\x9a\xafl\xbfg\xd1\xd6O\xdex\xc4}" +
-	"\xe3\x9ebfu\x07\xd8\x8d\xcbj\x16|\xfc\xf0\xf3\x17" +
-	"ow1\xe6T\xf1\x06B}\xc4=;\xc7\xbe\xfc\xa6" +
-	"n\xff\xe2\x11\xe8\xa7\xa3\xd6\xd1\xb7\x8b\xc9`O\x8c\xbf" +
-	"~\xdf\x86\xc3'\xb7\xbb\xa8\x9eU\xc2\xe4\xe8\xe8\x98\x92" +
-	"\x8d\xe8\x92\xa3\x87\xd0\x9bV\xf2kyz\xc9p\x06\xfd" +
-	"=b\xef\xcd?x\xf6\xb9\xeeW\xbf\xf1\xa8\xdb\x91\xed" +
-	"\x18\xf5\x07\xe6eF\x11\x8e\x91gJ\x17\x1c\x9e\x96\xb9" +
-	"\xd3\xb2\x0d\xf6\xd0\x85\xa3\x1f\xa6\x87<?\xbbd\xe5\x17" +
-	"O\x87w\xb9q\x1c6\x9a\xe18z4\xe1x\xd1\x97" +
-	"g\xdf\xbbj\xc2\xe1].\xf2V\x8e>HGk\xae" +
-	"|\xf7\xea\xab\x1e\x98\xf2\x18H\x178G\xe3\xa3\xa7\xd2" +
-	"\xd1\xe5\xec\xe8\xb3\xb7\xcc/\xac\x9fZ\xf88W\x0cB" +
-	"\xbe\xfa\xe8h\x1f)\xc6\xdb\xa3\x19\xe6\x8e?S\x0a\xd1" +
-	"%\xde9\x82\x17\x05\xcc\xac\x9e3v(]\xb7p," +
-	"]\xa7\xbd\xf5P\xdd\x8c\xc9\xdf\x7f<\xc5\xdfd\x12K" +
-	"\xee\x1f{\\\xde6\x96\xee\xdf2\xf6\x19\xbax\xcaG" +
-	"\xfe\x05#k\xbf\xf3d/\xdd>u\xe1k\xf2\x99\x0b" +
-	"\x09\xf2\x9f\x17\xce\x10\xe4\x9eq\xa46/5\xdd\xf5\xab" +
-	"}\xa3\xe6\xec\xee\x05\xad\x8e\xfb\x83\xac\x8fc~l\xdc" +
-	"\x0c\xf9v\x06\xfc\xad\xa6-\xb9\x95\xff8\xba\x1b\xa41" +
-	"\x9c\x99=\xe3\xae#\x8e|\xb9\xf7x\xf9\x8dow\xff" +
-	"!\x8dri\xe3\x1e\x96\xbb\xd8E\xfa\xb8\x9b\xe4\xac\xb2" +
-	"\xe1\xb2T\x96k\x063\x7f\xf2\xea\x89\xc5\xc5O\xd9\x82" +
-	"#\x7fR\x9dUF\x0c\x92\x87\x95\x91\x89.\xf8\xef\xbf" +
-	"\xbf\x13\x88\xd4<\x9b\xaa\x07\xf4\xaa\xbc\xafl\x93\xfcb" +
-	"\x19\x9d9P\xc6\xb8\xf9\xac\x10)\x7f\xfe\x1a\xed`\x9a" +
-	"\xf7/+\xdf/O/gvU\xee\x15\xe5\x91\x15^" +
-	"ydE\xae\xf9\xed\xed\xbe\x93W\x8b\x8b\x0f\xba\xa4;" +
-	"\xacb?\xd1\xf2A\xe1%\xabvl\xbc\xe7\xa0[\xef" +
-	"\xb3*X\xec\x93*H\xa7\xbe\xda\xf0\xfa\xdcOW\xfd" +
-	"\xbfC6\xee\x02\x8b\x9e\x15V\xf4\xac y\xcd+\xf9" +
-	"\xd1c\x0d\xff\xb5\xee\xb0[\xb56V0[\xdc\xcc\x00" +
-	"\xfe\xf9\xe4\xe1\x80?\xd8\xf9\x8a\xfb\x09\x9c\xc0\xa2g\xce" +
-	"\x04\x16^\x8f\xdf}\xfb\x87\x0f\xca\xaf%=1\x81E" +
-	"\xcfi\x13\xe8\x06G\x0bR\xe5\xb6c\xc2\x06\xf9\x89\x09" +
-	"\xe3(FL\xf0\xa2|\xebD\x12\xdc\xc2\x8fVM]" +
-	"\xf5\xe4\xcf\xdfr#\x14\x9f\xc80^9\x91\xae\xeb\x0c" +
-	"\xbd\xd6\xfd\xfc\xfc\x8e\xb7]\xf6xl\xe2c\xc4\x8d\xef" +
-	"\x9c\xbc\xe1\x83_^t\xf9\xbb\xee\xa3/Nl\xa2\xa3" +
-	"G\xd9\xd1y\x97\xde4\xf7om7\x9ft\xd32\xad" +
-	"r\x03\x01\xcc\xaa$Z*\x9f\xc9\xaa\x9fy\xfat\x12" +
-	"@W%\xbb\xa1\x87\x01\\Y\xf5\xe9\x85\xbf\xdf\xb3\xff" +
-	"=\x90\xb23\xdc\xb4T\xdfUy\x01\xca[*\xbd\x00" +
-	"\xad\x0fV\x8a\xd8\xfa\xabJ\x01\x01\xcc\xb9\x8f\xce\xbd\xaa" +
-	"K{\xe7}\xb7\xd1\xdf_y#]\xb8\x8d]x\xf9" +
-	"F|5?$\x7f`\xd9'\x93\xed\x81J\x16\xf4w" +
-	"\x1c?1\xf9\xf7\x1f\x8e\xfa\x00\x94\"t\x85\x81\xe9\x82" +
-	"W$\xcfQ\xb9\x00\xe5}\xf4^\xf5\xd3\x95%\xa4W" +
-	"{fO\xc9\x9a[\xf0\x93\x0f\xac\xd8\xcb\xf8\xe2\xa9f" +
-	"\x89\xc5\xa1U=\x7fzw\xd1\xc5\xa7]\x1c;]U" +
-	"O\xbf|\xf1\xda\x9a\x9b\xdf\xdc|\xc5\xe9T\xcde1" +
-	"\xf3H\xd5A\xf9\xcd*\xfa\xebX\x15\x85\xc4\x87;f" +
-	"\xb6f~\x7f\xc5G\xa0\x8c@G\xd2OT3\xd1\xec" +
-	"\xab&C\x08\x9f\xbfc\xde{uU\x9f\xf4\x92\xb46" +
-	"\xe9\xd7r\xd7$fX\x93\xbc\xf4\x1f\x80y\xec\xc4\x7f" +
-	"\x94}w\xf5\xb7?q\xeb\x8d2\x899\xb5\x85\x93H" +
-	"Zo\xfe\xec\xaa\x1b\x9e\x92\xce|\xe2\xe6\xdd\xb1I," +
-	"\xc2\x9e\x9aD\xbc+\xeb\xb8\xeb\xde\x05\x17/\xfb\x8c\xbb" +
-	".FY\xcedR\xde\xeaa\x93\x19S>]\xb4e" +
-	"\xfd\xd5\x9dg?s\x99N\xe5\x14\x96N\x8c\xdd\xe3=" +
-	"\xf8\xa7\xbd\x87\xcf\xb8\x982r\x0a\x0bfo\xfd\xe2\x9e" +
-	"\xec\xd7.\x9d\xf6\x85;7\xc8\x9ab\x19\xd5\x14b\xc4" +
-	"\xfc\xc7\x8d\x1fN\x19\xf5\xf8\x17\xaeK=\x970\x99\xfd" +
-	"\xff'.[xz\xf2\xe2\xb3\xaeKO\xd1s&\x98" +
-	"p\x95\xd9n\xa8z\xa8\xc2\xaf\x0a\x91Pdj\xab\xbf" +
-	"C\x0b\xc4\x83z\xa8\xbdY\xcb5\xf4p\xa0\x19Q\xc9" +
-	"G\x013\xa4\xc9S\x01P\x94\xc6W\x01 J\x17\xd6" +
-	"\x03J#\x9b\x00P\x90\x0a\xa7\x02\xd4t\x84\xe3F\xb0" +
-	"\xa7$\xa0\xea\xc1\x9e\x15]\xe1P\xac#\xd8c\xaa\xa1" +
-	"P\\\x0d\x06{\x00\xa0\xa6[\xd3:\x83=\xces\x19" +
-	"\xec9-\x1a\xd5\xc3\xa1\x86p(\xa6-\x8dU\xa8\xfe" +
-	"\x98\xbeD\x8f\xf5\x946\xab\x86*vE\x95\x0c1\x03" +
-	" \x03\x01\xa4\x9c*\x00%SD%_\xc0\x12m\x89" +
-	"\x16\x8aa\x9eyUp\xed\x96w~\xbb\xfbg\x84Q" +
-	"\x1e`\xca\xddj(\x10\x8d\x85\x8d\xae\xba\x88^\x11\xd0" +
-	"\x82ZL\x0b\xb0\x8b\xbb\xa2\xe0\xbexL\xe2b\xaf\xa1" +
-	"\xb5\xe1PtyC\x00\x1c\xda\xebb\x8bGZ\xa0)" +
-	"\xec\xabhP\x83A\x9f\xea\xef\xac0\xe2\xa1\xd2\x16\xad" +
-	"$\x1a\x0f\xc6\x1c\xbc\x11\xa5\x9c\x1f\x01(y\"*E" +
-	"\x02\x9a~5\xe4\xd7\x82\xdf\x8dc,nh-\xf1P" +
-	"\x94\x10\x07\x01\xb1_\xdc\xdb\xb5Xc@\x0b\xc5\xf4X" +
-	"Oc\xa0\xb4\xa5FKz\x83H\xb8 A\x82\xa8\x07" +
-	"0\x07\x04\xccq])\xd2\x95\xb3T=4W\xd7\xba" +
-	"+\x02F8\xc2\xf9\x90\xc4\x88&\x00%[D\xa5@" +
-	"@3\xec\xbbN\xf3\xc7\x1a\x03\x8c\x01\x88\x89\xf04 " +
-	"C\xa2\xf6?*\xc2!\xad\xb5#\x8c1%\x93\x9e\xc8" +
-	"G\x01@\xba\xa8\x1c@)\x15Q\x99(\xa0\x84\x98\x8f" +
-	"\"\x00\xd3(\xa5LDe\x92\x80\xb9\xdd\x1dZ\x08=" +
-	" \xa0\x07\xb0$\x1aT\xfd\x9d\x98\x05\x02f\xf5\xcb!" +
-	"\xfefi\x0b\xe3\x0dF\x93I\x9f\xa33\xc2\xdb\xb5\x18" +
-	"\xfd\xbf1\xd4\x16.m.a\x0cH\x86K\xbeS]" +
-	"\xa2\xf5\x12h\xaa\"\xc6\xc2\x9dZh0\xfc\xee\x85\x98" +
-	"\xe0B\xac9\xdc\xad\x195\xbe\xf0\xd2\xd9j;\xd9[" +
-	"_\x8f\xe9\xb1\xa0\x86\xd9 `v\x9f\x8f\x19\x1a\x11\xc0" +
-	"\x18\x91\x9b\x8a\xb8[\xd1\xfdj\x04\x87f\x88\x80I\xd2" +
-	"L\x7fW\xb3jx\xd5dc\xfc\xfa\xaa\xd2\xa1\x1az" +
-	"\xa8}\xa6\x1e\xea$y4k\xb1\x90\xdae\xcbML" +
-	"F\xb7\xdcF\xf7\x0a\x01s\x09\x08%\xf3\xae\xdb\xc7\xdc" +
-	"\xb9}\xce\x87\xef\x00@-JX\xa2d\x08\xe8\xfe(" +
-	"\xe1p\x85\xac\x0e\x9bE\xc4<\xb3\xf1\xf8\x0f.1\x1e" +
-	"]x\x0bsX.L<\x84\x09\x91\x98\x82MK8" +
-	"\xa8\xd5E\xa3z{\xa8K\x0b\xc5\xd2\x0a\x7fL\x02)" +
-	"\xef\x12\xd58w\x9c\x9c\xf8\x99\x82S:w\xe8\x0f\x86" +
-	"\xa3\\\xa7\xa3\x00\xc9\xb0\xb6\xee\x84\xb4\xeek\xda\xda4" +
-	"\xc3>\x9a\x96\x93\xf5\x09\xc1\xaf\x88Zp(%\xc2b" +
-	"\x0a&L3g\xd0?\xc8R*\xe6DE\xcd \xad" +
-	"\xccv.\x9c\xbe\x00@\xb9BD\xa5\xd9\xb6b\xfa8" +
-	"\xcb\x00Pf\x8a\xa8t\x08(\x09\x82e\xef\x1a=\xbd" +
-	"HD%(\xa0\xa9\xdbN\x0c\xc4\xc6\x80\xa3\xc6~C" +
-	"c\x9f\xa1D\x0d6\x06\xa2\xf8M`\x8c\xa2\x9f\xbf\x09" +
-	"\xb8\"b\x84\xdb\xf4\xa0\x86y\xe6\xfb_\xa21\xeb\x8f" +
-	"/\xbc\x94\xea\xeb=i\x18\xd7\x16\x0f\xb6\xe9\xc1`\x8b" +
-	"\xb68\xaeEc\x09g\x97\xe7\x90\xa0\x92 \xaf\xb5\xb1" +
-	"\xe5$h\x9b\x00\x94\x0e\x11\x95U.\x12V\x12\xb17" +
-	"\x88\xa8\xac\x15P\x12E\xcbe\xad\xf1\x01(7\x8b\xa8" +
-	"\xac\xefeK\x86\xb68\xae\x1bZ\x00\x9b5\xa3K\x8f" +
-	"Fuo8\xe4\x90\x85\x16Yf@\x8b\xfa\x0d=\x12" +
-	"\x031l`\x9e9\xaf\xe8\x99'\x1f\xc8}\xfe\x14\xa7" +
-	"-\xa0G#A\xb5\xa7\x11\xbc\xa1\xb60\xe6\x99'\xbf" +
-	"\xf5\xc6\xef\xb36\xb5n\x1e8\xceEcjO]\xb7" +
-	"\xda\x99\xde\xa4\xa6&\x14\xa1\xa6C\x0d\x05\x82dT\xaf" +
-	"\xd7/Z\xb4\xb5\xf4\xe3;\xd3jdB\xcblf\xba" +
-	"\xf4\xac\x97\x8397=\xf3\xa4\x09\xd2\x11C\xf3\xab1" +
-	"-\xd0\x1c\xf7\x05\xf5h\x87%\xb9T\xaf\xe9\x0e7\x14" +
-	"\xc1\x10]\x95>6\x99<$\x03sR\x8e\xd2\x92?" +
-	"\xa9\x15Q\x99\xe9\x92x#9\xb2+ETf\x0b\x88" +
-	"\x02\xba\x8aJIi\x02\xc1\xf2;\xc9\x8e\x84\xf8\xefO" +
-	"\xbc\x80R\xe2q\x9b@\x1e\x8d\xc0e\xb2B\xaa\xcb\xf1" +
-	"\xea\xa1N\x86\xba\xe3\x10$\\fr\x0f\x045\x96\x0f" +
-	"R2D\x0f\x80S\x83#oTI\xd2:\x10\xa4\x1c" +
-	"\xaf\xc9\xbd\x16r\xb7\x85\xb1Zd\xf7:u\xa9\x0b\x8b" +
-	"\xde,'*\xda\x8dp<\x14\xa8\xe3\xb9\x17)\x8e7" +
-	"\x18\x8b\x0e\xca\xdd\xd8\x02R\x8a\x1c>\xef \x96n\x17" +
-	"Q\xd9\xed\xe2\xf3\x13\xa4\x1a;ET\x9e\">[\x86" +
-	"\xb5\x87\x84\xb7[D\xe5\xcfdX\x82eX\xfb\xc8\x8b" +
-	"\xec\x15Q9$\xa0\x94!\xe6c\x06\x80\xf4\"E\xc1" +
-	"?\x8b\xa8\xbc*\xa0\xe4\xc9\xc8G\x0f\x80\xf42\xd9\xe5" +
-	"!\x11\x95\xd7\x05\x94\x86x\xf2q\x08\x80t\x94 _" +
-	"\x15Q\xf9\xbb\x80f<\xaa\x19\xe4\xc2HJy\xe6K" +
-	"\xd2\xc2\xdf\xbc\xff\xe2\xae\xf5\xb6\x0cW\xf8-_\x81R" +
-	"\xa2\xde\xe7\xf2\xb3h\x9b\x0d\xde\x9e\x88\xe6d\x1f\xf6\xd7" +
-	"f\xb02\x07\x1c\x0a\x02\xd9{I\x98\xd8\xe1\xb2\xff\xfe" +
-	"M\xbb$\xa6\xfa\x1a\xfb\xc8\xd0\x12,N\x18Y\xef\x08" +
-	"tnF\xd6o\x96\xddK\xd2b_\xe9-vZy" +
-	"\x89\xc7\xd5\xc3@\xde\xc1\x96\xa41 H\x1e\xaf\xd7\x88" +
-	"\x87j\xb1\x19\xb1\x9f\xac\xcaN>s\x0d\xb5\xeb\x1c\x93" +
-	"*fAu\x91H\xb3fD\xf5h\x8c4=\x81\x13" +
-	"\xafW\x917\x96$\xa9\x1c\xa0.\x1b\xeb\x8aP\x1a\xef" +
-	"\xcd\xa5d\x8e\xc7f\xa7\x8d\xc743\xddGFD]" +
-	"\x06J\xe83\xeb\"\x91k(\xc1\x01o\xac1\xd0\x9f" +
-	"\xe3\xedP\x0d\x8d\xe4\xe7\x98D\x9aT&_\xc0\xdc%" +
-	"\xba\xd6\x8dR\xa2\xfd\xf05\xbc\"w\xed\x83\x8a\x03\x1c" +
-	"\x9dL\x07\x9d\x8b|\x89|\xdb\xb1\xd0\xca\xeb\x00\x94\x89" +
-	"\"*\x97\x0b\xbd\xe3\xcf\xbf}z\xa8~\xff\x87\xbb\x9c" +
-	"\xd8\x1b\x0a\xc7\xf46\xdd\xafBn\xccR\xbf\x03\xb5k" +
-	"v\xfee\xe4\xea\xb3\xe9\xa9\xe978\xf7\xca\x8c\x91\xe7" +
-	"\x1f%,\x01a\xce,\xd1D\xc7\xf2\xdc9Q\xcdP" +
-	"\x0a\x1cr\xee\"\xddY/\xa2r\x9f\x80\x9c\x9a\x8d\xe4" +
-	"\x1c\xee\x16Qy\x88\"9Z\x0eg3\x01\xde'\xa2" +
-	"\xb2\xd5\xe5p\xb6\xdc\x08\xa0<$\xa2\xb2\xdd\xe5p\x1e" +
-	"!\xc8\xad\"*{]\x0e\xe7i\x82|JD\xe5\x05" +
-	"\x01K\xd4H\xc4\x95\xc4\xa8\x91\xc8\\RK\x10\xc3!" +
-	"\xcc\x04\x013!5]\x0fw\x874\xa31\xa0\xa1]" +
-	"\xc7\x01\xff\xa5\x84\xdc\x94\x93 \xe4%H\x05d\xa9B" +
-	"\xd8\xd0\xdb\xf5\x90\x1aD+%K\x1ct\x186$\x0d" +
-	"\x93\xdb\xb5\x18E\x1a-\xc0\xb3\x90p(\xead\x92\xe9" +
-	"\x12\xda\xd9NB[Z\x7fxQ\xeb'\x1f\xde\x90\x94" +
-	"\xd0\xba>&%\xb4\xae\xbc\xe6kd\xd8\xb6#\x18\xc8" +
-	"\xac\x1a\xd4D\x01\xe5\xd6\xe3\x96tzL\xb6v\xb1\x88" +
-	"\xca\xa5\x02\x9a\xect\xa0A\x05teh\xb9A=\xd4" +
-	"\x89\x92+@&\xeb\xec\x90~,\xb0Ek'\xdfc" +
-	"P\xb0\xe4\xc9v\xafr\xd3\xed\xa4bv\x1d\xc9\x8bv" +
-	"\x17\xf6M\xe9\xb0\xafra\x9fZX9>\xca*\xac" +
-	"J\x82\xaaO\x0b\xa6\xc9N\x06\x97\xc0\xf1\xaa\xceeH" +
-	"\x84\xd1\x9d\"*\x0f\xba0\xba\xbf>aI<ro" +
-	"&\x07\xf2\xa0\x88\xca\xaf\\\x86\xb4\xcd\xb0mf\xa7\xcb" +
-	"\x90v\xf8\xecd\xe0\x05\x97!\x1d\xa8J\xc4\xf8\x7fM" +
-	"\x90fI\xb9\x16\x8d\xd9\x0e\xcd\xb15wp\xfef\xff" +
-	"\xc19]\x8f\x035\x92g\xb6i\xa2k\xd6*]T" +
-	"\x0fB\x0e\x9e5-\x0eHM\x89\xfe\xcf\x0a\xab%\x12" +
-	"3#\x9a\xa1\x87\x03\xba\x9f\x88\xceM\x0c\x9d\x0117" +
-	"5\xe2\xd9\xf2c\x8d\x0bB\x9eb\xde(Gd/\x12" +
-	"\x7f_\x10Q9\xe9\x12\xd9\xdb\xc4\xdf\xbf\x8b\xd8\x82\xae" +
-	"2\xe6\xabu\x00-(bk\x11&\xea\x18\xb9\x10\x0d" +
-	"\x80\xd6\x02\xfa>\x89\xbegd0\xac\xe5J\xf4\x01\xb4" +
-	"N\xa4\xef3\xe9\xbb\xc7\xc3D'7b\x13@\xeb\x95" +
-	"\xf4}6}\x1f2\x84\xe5]\xb2\x82-\x00\xad\xcd\xf4" +
-	"\xfdZ\xfa\xee\xf5\xe6\xb3Y\xee|\\\x00\xd0:\x8f\xbe" +
-	"\xc7P@\"\x9cy#p\x17Ey\x89\x86\xaf-\x07" +
-	"#\x1c\xd4\\?;\x93\x0c\xdb%\x06\xb4\x90N\x9e\x0d" +
-	"\xb9k\xc3^\x05V\x97\x1a\xf3w\xb4h\x8b\xa1\x84\xe4" +
-	"\x1e\xedS\xe4\x16\xe05mm\xe0M\xf2\xc2\xa9`j" +
-	"$2\x9b\xfc\xb9\xa5\xa6i-\xae\xd1\x1f\x06\xa4`X" +
-	"\xb3iK\x89\xef\xfb\xbf\xfb\x9c\xab*\xebV\xce\xee\x89" +
-	"\x80\xe8&\xea[\x1b\xf6\xber\xde_/}\x8b\xbf\x90" +
-	"\\\xe2\xb8\xbc\xb9\xb8\x94%:\xa5,\xd1\xe1c\x06\xe4" +
-	"s;Y\xc2\x87A\x90s\xd0\x8b\xe8\xcc\x84\x907\xa9" +
-	"e\xc4\x16\x10\xa43^\x14\x9c\x062\xf2v\xb0t\xaa" +
-	"\x0a\x04\xe9\x98\x17E\xa7\xeb\x8d|2#\xbd\\\x0f\x82" +
-	"\xb4\xcf\x8b\x1eg(\x8d|\xe4(=\xb1\x0c\x04i\x87" +
-	"\x17\x878=|\xe4\x03ri\x0b\xdd\xb9\xd1\x8b^\xa7" +
-	"1\x8f\xbc\xa7-\xdd\xde\x04\x82\xb4\xc6\x8b\x19\xcet\x06" +
-	"\xf9$BZ~\x1d\x08R\xdc\x8b\x99\xcej\x05\xf2\xc1" +
-	"\xae\xa4\xd39\x95\x15;,\xa8!\x8fj\xb9\x14\xd6j" +
-	"\xd1\x8c\xe9\xda\xec\xf0\x9c\xa8\x06h\xd4\xda\x19y-\xae" +
-	"\xb0\x8d\xbe\x16M\x9er@\x0d\xffT\xc2z+\xb5h" +
-	"\x86#\x1a\xebv\xb1hg\xfa\x83\xaa\xdeEj\x93k" +
-	"\x9f\xe4\xb92\xd8Ya\x8a\x98\x12\xd1\x0d\x12\xc9(\x1f" +
-	"y \xdf;\x90\xa4\x05 HY\x8c\x00\xd6\xf1\x02\xb1" +
-	"KK\xben\x80\xc6rj\xa0D\xde\xa8\xcb%\xdc\xe9" +
-	"\xe9L\xf64_\x85@\xbe4!U\xd6\x03\xd4]\x8c" +
-	"u\x97\xa34\x8bT\x84/\x8d _\xce\x90\xea(S" +
-	"\xbe\x1c\xebf\xa2\xb4\xd0\xbb\xc2n\xfa\xf1\xb8\xef4\xf3" +
-	"\xdc\xc9r\xd2\xc7Z\xcc\xa5\xa4\xfe\\\x0e\xb0\x94.\x91" +
-	"\x00\xc3 R\xed\xe4\xec\x86\xcb\xdbp\xa2\x98\xab\xce\x1f" +
-	"\x93\xae\xce\xdf\x94\xae9E\xce3 \xa2\x12\xf9\x9aM" +
-	"\x9cAvi\x98\xb0(\xf3\xf1^\xa1\xb5\x91\xac\xd2\xe7" +
-	"\xaeR\xfa\xe45m\xcc\xb5\xdbP\xdb|\x89<\x15\xc9" +
-	"u#JO7%\xd2T\xc9\x83\xf9\xe8A\x94\x0e\xd4" +
-	"'B\xae\x9d\x98\xf6\xf6bK4\xc3\xd7\xdca\xa8 " +
-	"F\xd3\xfd\xec\x14\xb6^\xf2\xb8\xbd\x7fO\xeb\xdb9\xaf" +
-	"\xc2\xbeh8\xa8\xc5\x98\xff\xb4'\x1e+\x02Z\x9bJ" +
-	"\xe9S\xea\x04DHM\xc0\x80\x19\xd7D\xa6\xe1|\x7f" +
-	"\x05\xf9\xee\x84\xbc\x18\xd7\x014\xc4\x10\x1bV!\xcaw" +
-	"0G\xc8g\xd3\xc8W\x10\xe45\xb8\x1f\xa0\xe16\xc4" +
-	"\x86\xfb\x10\xe5G\x90<\"\x9f\xcc\"\xdfd\x907S" +
-	"|kx\x10\xb1a;\xa2\xfc4\x92o\xe4[\x1c\xc8" +
-	"g\xc0\xf2.\x8av\x0d;\x11\x1b\xf6\"\xcaG\x90\xbc" +
-	"$\x9fy#\x1f\xd6\xca\x07\xb0\x1e\x80 \x1a^E\x94" +
-	"\xdfF\xf2\x97|\xc7\x04\xf9\xb0N>\x8a\xe5\x00\x04\xd1" +
-	"p\x02Q>\x83\xe49\xf9\xee\x18\xf29\xa1|\x8a\xdd" +
-	"u\x02\xb1\xe13D9K ?\xc9WD\x90/\x05" +
-	"\xc9_1\xbc\xbeDl\xc8\x16P\x1e)\x90\xa7\xe5\x93" +
-	"t\xe4\xdbZ\xb2$\xd0\x8b\xd9\x026\x14\x09(\x8f\x17" +
-	"\xbc\x98\xe5\x8c\xa0\x91\xaf\xea\xc8\xa3\x85\x0d\x00\x0d\xa5\x02" +
-	"6L\x12P\x9e.x\xf1<g\xe8\x8e|5M\xbe" +
-	"L0\x00\x1a.\x15\xb0\xe1J\x01\xe5\xf9\x82\x17\xbf\xe1" +
-	"lb \xdf)\x93g\x09M\xe0@\x98<\x9bF^" +
-	"\xd0b\x07\xf7\x13\xce\xd8\xcd\xed<\x92>\xd6\xa2s^" +
-	"HM\xc7\xfb8\xd2\xd7=\xbc\xb4\x80\xafsn\xae\xae" +
-	"\x01v\x9f\xcb\xb9T\xcf:\x983I\x9euP\x8f\xd8" +
-	"s\xces#\xc8\xee\x15\x00\x9e\x1br\xee\xa6\xca\xa0\x1e" +
-	"\xe2\xcdF\xe4\xddF1\xd6sN\x17\xf0I(\x94\xb0" +
-	"\x1a\xfa\xdc\xa8Lte\xfb\x00\x19\xa8\xe9s\x0e\x85!" +
-	"\x9f8\xf4S\xd9\xba\xa6k\xa9\x13\xe9\x81\x9ba\xe9\x82" +
-	"\xa2\xe1n\xa8\x88\xc9\xb5\xe6\x98\x94\xb1+}\x1c\xbf " +
-	"Qk\xa6\x84\xbf\x01\xc6\x13\x03\x0cMy{o\xa0\xf7" +
-	"]c\xdf\xe4\xf7\x07W\xd9\xf63#\xb7\xa7\xbb}M" +
-	",\x9dA\x14k\xdf\x17\x1cU\xb6,\xbbs\xd5\xee\xc1" +
-	"M\xe4\xac\xfc\x901\xba\xc6\x12\x9d\x9b\xce\xeb\xd2\xd5\xf4" +
-	"\x94{L\xb2\xa6e\xbc\x10\x9d\x0d\xb9L\xae\xbc\x953" +
-	"\xa8|#)\x9f\xa0\xbaP\xd4\xba\x95Lto\xb7e" +
-	"5\xb9Vk\xb3|&\x9fw\x03\x80\xc9\xa6\xcc\xbe\xf0" +
-	"R\xf0\xceV\xdb\x95<\x16M\xf9r/\xf2+\xa4\xc5" +
-	">\x10$\x9db(\xdfcA\xbe\x81)-\xa44v" +
-	"\x0eEN\xbe\x9e\x87|\xdfTj\\\x07\x824\x9d\xe2" +
-	"%\xdf\xe9D\xbe\x1a+]v#\x08R%K\x7f\x19" +
-	"B,a\xaaE\x93w\xbaA\x0c\x87\xac\x7f2\xd6\"" +
-	"oR\xa0\xfd\x95M\x1c\xd0\x16\x05\xa4K\x9c\xd3wb" +
-	"\xd3Y\xc2\x82\xc1\xf5\x8c\xe6\xea \xa6\xeb\xd3\xf2\xe6\x91" +
-	"\xb3\xf84\x88\x86g\xda^\x9c\xad<\x83i}9\xe3" +
-	"\xcb\x81\x0c\xca\xd7\xb7A\x0f2U\x1d\xd4\x80\xe8\x7f}" +
-	"9\xc7\xb5\xb4`M\xba\xbf\xc6\xd6B\x97\x1e\xd2\xbb\xe2" +
-	"]\x89\xfd\xa5\xd6\xa0*\xfa;\x9b\x11\xad\xe6P\xf1\xce" +
-	"\xc0\x91\xe9\xbdFa\xfdXw\x7f\xcd\xd2\x01pI\xd3" +
-	"\x00L\xdd7\xc9H\xd7 uwG\xc5\x10\xab\xfa\xf3" +
-	"Y\x7f\x89\xbd\xbe\x9c\xb4t\xa95\x9d\xb6\xfaK\xf4u" +
-	"eKb<=R\xf8\xca\xb4{\xddk\xa6\x02(\xab" +
-	"DTn\x13\x10\xedj\xe1\xd6e\x00\xcaZ\xbb\xae\xc8" +
-	"@\xab?\xb5y\x9d\xdd\xff~J\xc0\xdcP8\xa4\xc1" +
-	"\x10S\x0d\x06\xeb\xfc~-\x0a\x18\x85!5F8\xa8" +
-	"5\x06\xd0\x0b\x02z\x01M5`\xe91\xd4X\x9a\x9c" +
-	"\xea\xa5\x0c\xad+\xbcD\xeb\xb793\x98\x8d\x90\xd4\xc9" +
-	"\xaf\xb7\xcf\xfd\x08\xdb<8 \xc1%\x0c\xed\x0a\x0dY" +
-	"\xc9\x95'f Z\x93\xff*\xd7\xe4_\xe0\x93\x7fW" +
-	")H\xec\x12\x10\xa5.\x0a\x19A\x11\x95\xa5\x02\xe6\xd9" +
-	"M\xce8\xc9 \"\xa2\xf2\xc3~\xea\xa8\x81\x0a\xa54" +
-	"\xb5\x905hNm\xec\xa7\x8b@\xbc_\xe1Xa\x7f" +
-	"\x13nD\xa9\xb1<\xb1\xab\xc1\xfa\xb7\x88\x922\xd5\xae" +
-	"\x86\xe7\xf59\x8f\xca\x8d\xa8\xb1\x0e\x1b!\x90\xf0<\x80" +
-	"\x9a\x90\xd6=[\xf5\xf5\xaa\xd6\xech\xd4\xaa\x95X\xad" +
-	"W\xc4f\xd1\xd3\xdbC\xa7\xcfU\xd2\x0c\xa7\xaal\xcf" +
-	"V\xeb\xf2l\xd36\xd9\x94]+`\xc9\xe2\xb8f\xf4" +
-	"\xf4\xdd\xa9;\xa7P\xda\x7fsa\xa0\x1d\x87\x98\xae\x05" +
-	"\x1a\xfav\x03\xbd\xe7\xb9<3\xcaw\xee[NJ\xf6" +
-	"C\x11\x95\x9b]\xe4\xae\xaeO\xd84\xef\xb9\xbb7N" +
-	"\x9c\x9e\xfb\xed\x06\x80r\x9b\x88\xca\xdd\xae\x9e\xbb\xbb\xa7" +
-	"\xf0/\x9a\x81\xa7\xef\x9b\xf7\xbd\x85\xd9\xc7JS_\xaa" +
-	"\x9e~#!\x15\x9a\x92\x04'\x9b\xfe\xbf\xb6\xe9\xd3\xc7" +
-	"$\x9d/\x08\x0c\x823\x0e\xcf\xff'\x00\x00\xff\xffi" +
-	"}\x82A"
+const schema_c8d91463cfc4fb4a = "x\xda\xdcz{xT\xd5\xb5\xf8Z\xfb$\x99\xc4\x86" +
+	"&\x87\x13\xaai\xa1\x89\x88\xbf\"\x15* >\xa86" +
+	"/,&\x82\xe6$@\x91\x1f\xaf33;\x99Cf" +
+	"\xceL\xce\x9c<\x06y\x88?\xf8)\x8a\xb7\x85\xd2[" +
+	"\xe1\x13\xac(\xb7b\xa1\xd6\x16*X\xa9\xd8J\x05," +
+	">\x10\x10\xf4\xd2\x0aV+\x0aU|\xd6\x07\x9c\xfb\xed" +
+	"=g\x9f93\x99\xbc\xfc\xfa\xf5\x8f\xfb\xf9\xa9\x93}" +
+	"\xd6\xde{\xbd\xd7\xdak\xad\xcb\xaa.\xaa\xcc\x19=\xe0" +
+	"\xc9'\x804\xae\xce\xcf\xcd\xb3\xd7UN[\xbd\xe8\x81" +
+	"\xce\xa5 \x17\x12\xbb\xee\xf3\xa7\x9f\x0f\x94\x1c\xdd\x03\x80" +
+	"\xcau\xb3\xdfV\xd4\xd9>\x00e\xf2\xec\xc5\x80\xf6k" +
+	"\x17\xcd\xaa;S\xfe\xfa2\x90\xbf\x81\x00\xb9\xe8\x03\x18" +
+	"\xbbl\xf6\x0c\x04TV\xce\xae\x00\xb4\x8fi\xd7<7" +
+	"\xb5\xf6\xe8\xed \x97&\x01\x8aq\xec\xa3\xb3\xab\x19\xc0" +
+	"\x0e\x0e\xf0\xfc\x0f.Wvm^~\x17\xa8C\x11\x01" +
+	"r\xd8\x09Gg\x9b\x0c\xe0\x0d\x0e0\xf3%\xe9\xfc\x07" +
+	"bu+\xbc'\x0c\x983\x9f\x01\x94\xcea\x00\xf1\x85" +
+	"O\x9d\x1e\xb0^]\x01\xf2 \x17\xe0\xea9\xe71\x80" +
+	"*\x0e0\xcc_\xf5\xce\x81q\xe1\x15\xec\x0a\xc9^r" +
+	"z@xyt\xf8\x130\x88\xf8\x10`,\x9d\xd3\xc0" +
+	"@[\xe7t\x00\xda7.\x9b\x1a\xdaup\xcf\xdd\xc9" +
+	"\xcbr\xd8Q\xfb\xd9\xf7\x1c\xfb\xff\xdfx\xb8\xf6'\x1f" +
+	"\x8e\xfa!\xc8\x8a@s\xc7\x9c1\xec\xcb\xa2?\xbf\xfe" +
+	"\xd2C\xfb\xf2Vy\x11\xbc\x9f}Be\x13\xbf\x7f\xdc" +
+	"g%\xeb\xde<Z\xbc*\x89 ?t\xef\x9c\x81l" +
+	"\xeb\xa9\xe9\xa5w\xed\xfa\xd1\xb7\xeea\x87\xda\x7f?\xd1" +
+	"\xfc\xf6=/o}5\xc9F\xe5\xd19\x9f);\xe7" +
+	"\xb0_;\x92\xa7\xfc\xf2w\x17=\xf9\xdc\xbbk\xbcd" +
+	"\x1e\x9d3\x82]s\x9c\x03\x94\x06\xbfX\xf8\xda\xc8\xc3" +
+	"k\xbd\x008w(\x03(\x98\xcb\x00^\xdc\xd7\xfa}" +
+	"\xe9[G\xd7\x82|\x81+\xac\x91s\xb9\xb0\xae\xe6\x00" +
+	"\xeb>\x88\xff\xa5\xe9?\x07\xad\x03y\xb0\x0b\xb0|\xee" +
+	"\xaf\x19\xc0\x1a\x0epo\xdeM\xaf~F\xaa\xd6%\xc5" +
+	"\xcd\x99prn\x1d\xa3$Q~\x0aW\xc4~\xbb>" +
+	"\xc9\x9e\xe4\xd6C\xc9\xb3\x8f\xf3\xad\xe57>>\xfc\xe6" +
+	"\xc7|\xf7\x81\xfc5\xb4CC\x9a\x1b\x8f\xef\xfab\x1b" +
+	"\xe4J\x8c@\xd4^Q\x06h\xecW\x81\xf6\x08\xa0\xbd" +
+	"\xea\xec\x80\xdaw\xaf\x08\xdf\xe7hU\x92\xd9\xda\x9d\xec" +
+	"\xb0\xbd\xda\xdf\x01\xed\x0bg\xbds|U\xd1\xc1\xfb\xbc" +
+	",\xdf\xe9\xe7\xb7\xed\xf5\xb3\xdb:\x7f\xfa\x83O\x07\x8f" +
+	"\x1c\xb1\xc1\x8b\xceI\xff<\x06\xf0\x11\x07\x98yG\xe4" +
+	"\xaf7\x8f\xbf}\x83G\xd0\x83\x02\xab\x18%\xd6\xba?" +
+	"\x0d;Wx\xd3\x03\xa0\x16\xa2\x94R\xfe\\\xc2\xf0\xcb" +
+	"\x0d\xecS\xe4\xc0\xf9\x00\xca\x90\x00\xc3\xf4\xads\x7f\x9c" +
+	"\xdc\xfe\x9d\x7f>\x08r\xa1\x94f(\xf7\x07N([" +
+	"8\xe0\x8e\xc0D\xe5$\xfbe\xc7\x86\xb7\\W\xb0y" +
+	"\xdcF\xcf\x9dG\x03\x1b\xd8\x9d\x7f]\xbd\xfc\xb9\xdf\x9c" +
+	"\x1e\xbf\x11T\x05\x19\xbey\x0c\xdf\xbd\x01\xa6\x98c\x0f" +
+	"\x05~\x88\x80\xf63\xabW\x0d\xff\xa9\xfd\xe1C^\x8a" +
+	"\"\x94kY\x1be\x14\xdd\xf5\xb3o6\xef\xdd\xf2\xbd" +
+	"M\x1e\xd9\xfc\x84\xfa\xd9\xe9\xf2\xa2\xcf\x1f:\xb4V\xfb" +
+	"\x05\xc8\xa5R\x8a<\xc0\xb1K\xe8@TVRF\xd8" +
+	"\xddt\xa2\xb2\x8d\xfd\xb2\xe9\xcc\xe1\xcb\x16\x0f\xdd\xbc\xc5" +
+	"\xcb\xdbu\x94\xeb\xd9F~\xd1\xf6\x89\xb7\x1f[\xdb\xf9" +
+	"\xd9#\x194C1*\xbb\xe9\x01\xe5E~\xde~:" +
+	"Q\x19\xd2\xe4S\x864\x15\xd9/o]?w\xf3\xc7" +
+	"\xaf?\xea=qP\x13\xb7\xba\x0b\x9b\xd8\x89\xf3+f" +
+	"|\xf8\xf0\xf3\x97n\xf50\xa6\xaai-C\xfd\x1b\xeb" +
+	"\xb7_t\xe8\x0d\xdd\xf9\x92K\xd8\xa7\xd1\xc9\xad\xd76" +
+	"1\x83}k\xe4\xa2\xbdk\x0f\x9f\xda\xea\xa1\xfa\xfe&" +
+	".GW\xc7\xd4BD\x8f\x1cs\x19z+\x9b~\xad" +
+	"\xaci:\x9fC\xff\x00\x01\xed;ny\xf6\xb9\x8e#" +
+	"_y\xcc\xeb\xc8\x0aB\x7f\xe0^&\xc4p\x8c=3" +
+	"l\xc6\xe1k\xf3\xb7'm\x83_\x94\x08=\xcc.\xca" +
+	"\xfd\xf9\x95K>\xdf\x1d\xdd\xe1\xc5Q\x0fq\x1c\xdbB" +
+	"\x0c\xc7K\xbe8\xf7\x8f\x1b\xbesx\x87\x87\xbcC\xa1" +
+	"\x03lk\xc5\xf5\xef\xdcx\xc3\x03W<\x0e\xf2\xd7\xdd" +
+	"\xad{C\xe3\xd9\xd6\x17\xf9\xd6g\xef\xba\xb9\xb4z|" +
+	"\xe9\x13B1\x18\xf2cG\xeb~\xa6\x18\xd7\xea\x1cs" +
+	"\xd7\x9f\xa9\xa5\xe8\x11\xefT\xe2C\x82\xf9c7\xcd\x1b" +
+	"\xc8\x8e{t\x1e;\x8e\xbe\xf9P\xd5\xc4q\xb3\x9f\xc8" +
+	"\xf07\xf9\x8c%\x1f\xcd;\xa1`\x0b;\xff\xec\xbcg" +
+	"\xd8\xc1W|\x10\x981\xa4\xf2{Ov\xd1\xed\xaa\xc8" +
+	"+\xca\xe4\x08\x83\xac\x8dL$\xca~\x83\xa9\xcdKu" +
+	"k~\xb5\xb7|\xea\xae.\xd0\xdb\x8c?(;\x0d\xee" +
+	"\xc7\x8c\x89\xca\x1b\x1c\xf8\xdbu\x9b\x8aF\xff\xfd\xd8." +
+	"\x90\x87\x0af\xee7\xe61\x8e|\xb1\xe7\xc4\x88\xdbN" +
+	"v\xfc!\x8br\xed0\x1eV\x9e\xe2\x07\xed4nW" +
+	"fE\xcfWh\xb4\xc8\x0e\xe7\xff\xf8\xc8[\xad\xdf|" +
+	"\xda\x11\x1c\xf3'cgE\x19\x83\x14=\xcaLt\xc6" +
+	"\x7f\xff\xed\xed`\xac\xe2\xd9L=`\xb7*Cb\x1b" +
+	"\x94\x8bcl\xcf\x851\xce\xcdgIl\xc4\xf37\xd1" +
+	"\x03Y\xee\xbf\xbbu\x9f\xb2\xa6\x95\xdbU\xabORF" +
+	"\xb6\xfb\x94\x91\xedE\xf6w\xb7\xfaO\xdd(\xb5\x1e\xf0" +
+	"H\xf7\xe2\xf6}\x8c\x96\xf7J\xaf\\\xbam\xdd\xfa\x03" +
+	"^\xbd/m\xe7\xb1\xef\xc2v\xa6Sg\xd7\xbe6\xed" +
+	"\x93\xa5\xff\xe7\xa0\x83;\xf30c\xab\xda\xb9\x1b\x9b\xdc" +
+	"\xce\xe45\xbd\xec\xce\xc7k\xfek\xd5a\xafjmi" +
+	"\xe7\xb6\xb8\x8d\x03|\xf4\xe4\xe1` \xdc\xf2\xb2\xf7\x0a" +
+	"\xb9\x83G\xcf!\x1d<\xbc\x9e\xb8w\xe5\xfb\x0f*\xaf" +
+	"\xa4]\xd1\xc1\xa3\xe7\xe4\x8e\x0e\xf0hA\xa6\xdcvw" +
+	"\xacU\xf6w|\x8b9\xce\x0e\x1f*\xeb:\x99\xe0f" +
+	"}\xb0t\xfc\xd2'\x7f\xf1\xa6\x17\xa1e\x9d\xc9x\xdf" +
+	"\xc9\x8ek1^\xe9x\xfe\xe6\xd0I\x8f=\x9e\xe9|" +
+	"\x9cq\xe3{\xa7n}\xef\x91K\xaey\xc7\xbb\xf5x" +
+	"g\x1d\xdbz\x9ao\x9d~\xd5\xed\xd3\xfe\xdat\xc7)" +
+	"/-\x93\x13k\x19\xc0\xac\x04\xa3e\xf43\x05\xd5\x93" +
+	"\xce\x9cI\x03X\x98\xe0',\xe7\x00\xd7\x8f\xf9\xe4\xe2" +
+	"\xdf?\xb5\xef\x1f \x17\xe6xi\x19\xbb)\xf1uT" +
+	"v$|\x00\x8d[\x13\x126\xeeJ\x10\x04\xb0\xa7=" +
+	"6\xed\x86\x08}\xfb]\xaf\xd1?\x9a\xb8\x8d\x1d\xb8\x93" +
+	"\x1fx\xcd:<Rb(\xef%\xed\x93\xcb\xf6X\x82" +
+	"\x07\xfdm'\xde\x1a\xf7\xfb\xf7\xcb\xdf\x03u0z\xc2" +
+	"\xc0u\xc4'\x01\x8c\xdd\x9d\x98\x81\xca\xd1\x04\x0f\x82\x89" +
+	"2\x04\xb4\x9f\x9arE\xc1\xb4\x0b~\xfc^2\xf6r" +
+	"\xbe\x0c\xba\x85'\x16\x07\x97&\xfe\xf4\xce\xdcK\xcfx" +
+	"8\x86\xb7T\xb3/\x9f\xbf\xb2\xfc\x8e76N8\x93" +
+	"\xa9\xb9<f\x9e\x9c\x7f@\xf9h>\xfbuf>\x0b" +
+	"\x89\x0f\x87&5\xe6\xcf^\xfc\x01\xa8\xdf@W\xd2\xfb" +
+	"o\xe1\xa29z\x0b3\x84\xe8\xd7\xb6M\xffG\xd5\x98" +
+	"\x8f\xbbH\xbam\xc1\xaf\x95\x85\x0b\xd8Q\x89\x05>\xf6" +
+	"/\x80}\xfc\xad\xff\x18\xfe\xfde\xdf\xfd\xd8\xab7\xda" +
+	"\x02\xee\xd4\"\x0b\x98\xb4\xde\xf8\xf9\x0d\xb7>-\x7f\xfa" +
+	"\xb1\x97wg\x16\xf0\x08{v\x01\xe3\xdd\xf0\xd0\x9a\xfb" +
+	"f\\:\xff\x9f\xc2uq\xca\x86,d\xca;\xf6\xe2" +
+	"\x85\x9c)\x9f\xcc\xdd\xb4\xfa\xc6\x96s\xff\xf4\xfa\xfdE" +
+	"<\x9d\xb8\xe8)\xdf\x81?\xed9\xfc\xa9\x87)#\x17" +
+	"\xf1`\xf6\xe6/\xd7\x17\xber\xd5\xb5\x9f{s\x83\xd2" +
+	"EI\xa3Z\xc4\x18q\xf3\x13\xe6\x82+\xca\x9f\xf8\xdc" +
+	"\x1b\xd9\x17s\x99\xfd\xdf\x9dW\xcf:3\xae\xf5\x9c\xe7" +
+	"\xd0\xb3\xec:\xbb\x8f\xff\x94\xdb\xcd\xa6\xa6\x1b\xa3\x02\x1a" +
+	"\x89\x19\xb1\xf1\x8d\x81\x10\x0d\xb6\x85u\xa3\xb9\x9e\x16\x99" +
+	"z4X\x8f\xa8\x96 \xc1\x1cy\xdcx\x00\x94\xe4\x91" +
+	"c\x00\x10\xe5\x8b\xab\x01\xe5!u\x00H\xe4\xd2\xf1\x00" +
+	"\x15\xa1h\x9b\x19N\x94\x055=\x9cX\x1c\x89\x1aV" +
+	"(\x9c\xb05\xc3h\xd3\xc2\xe1\x04\x00TtP\xda\x12" +
+	"N\xb8\xd7\xe5\xf0\xebh<\xaeG\x8d\x9a\xa8a\xd1N" +
+	"k\x94\x16\xb0\xf4v\xddJ\x0c\xab\xd7LM\x8a\xc4\xd5" +
+	"\x1c)\x07 \x07\x01\xe4\x01c\x00\xd4|\x09\xd5\x12\x82" +
+	"e\xb4\x9d\x1a\x16\x16\xdb7\x84Wlz\xfb\xb7\xbb~" +
+	"\x0e\x80X\x0c\x98q\xb6f\x04\xe3V\xd4\x8cT\xc5\xf4" +
+	"QA\x1a\xa6\x16\x0d\xf2\x83#q\xf0\x1e<4u\xb0" +
+	"\xcf\xa4M8\x10=\x1e\x13\x00\x07v98\xc9#\x1a" +
+	"\xac\x8b\xfaG\xd5h\xe1\xb0_\x0b\xb4\x8c2\xdb\x8ca" +
+	"\x0d\xb4,\xde\x16\xb6\\\xbc\x11\xe5\x01w\x02\xa8\xc5\x12" +
+	"\xaa\x83\x09\xda\x01\xcd\x08\xd0\xf0\xf7\xdb\xd0j3iC" +
+	"\x9b\x11\x07@\x04\x82\xd8#\xee\xcd\xd4\xaa\x0dR\xc3\xd2" +
+	"\xadDmpXC\x05M\xbb\x83\x91\xf0\xf5\x14\x09\x92" +
+	"\x1e\xc4\x01@p\x80\xe7H\x89\x1d9Y\xd3\x8di:" +
+	"\xed\x18\x154\xa31\xc1\x874F\xd4\x01\xa8\x85\x12\xaa" +
+	"\x17\x10\xb4\xa3\xfey4`\xd5\x069\x03\x10S!\xac" +
+	"W\x86\xc4\x9d?FE\x0d\xda\x18\x8a\xa2\xa5\xe6\xb3+" +
+	"J\x90\x00\xc8\x97\x8c\x00P\x87I\xa8^FPF," +
+	"A\x09\x80k\x94:\\B\xf5r\x82E\x1d!j`" +
+	".\x10\xcc\x05,\x8b\x87\xb5@\x0b\x16\x00\xc1\x82\x1e9" +
+	"$\xee\x1c\xd6\xc0y\x83\xf1t\xd2\xa7\xea\x9c\xf0fj" +
+	"\xb1\xff\xd7\x1aM\xd1a\xf5e\x9c\x01\xe9p\xe9gj" +
+	"\xed\xb4\x8b@3\x15\xd1\x8a\xb6P\xa3/\xfc\xee\x82\x18" +
+	"\xf1 V\x1f\xed\xa0f\x85?\xda9Ekf\xf6\xd6" +
+	"\xdde\xba\x15\xa6X\x08\x04\x0b\xbb\xbd\xcc\xa4\x8c\x00\xce" +
+	"\x88\xa2L\xc4\xbd\x8a\x1e\xd0b80G\x02\xc4\x81\xbd" +
+	"\x9eU\xaf\x99>-\x12\xff\xd7\xa8JH3u\xa3y" +
+	"\x92n\xb40y\xd4S\xcb\xd0\"\x8e\xdc\xa4ttG" +
+	"8\xe8N X\xc4\x80P\xb6\xd7\xac\x1cz\xcf\xd6\xa9" +
+	"\xef\xbf\x0d\x00\x95(c\x99\x9aC\xd0\xbb(\xe3\xf9j" +
+	"\x0e\"b\xbd\x84Xl\xd7\x9e\xb8\xe5J\xf3\xb1Yw" +
+	"q\x87\xe5\xc1$\x97a\xc2H\xcc\xc0\xa6!\x1a\xa6U" +
+	"\xf1\xb8\xdelD\xa8ae\x15\xfe\xd0\x14R\xbev\xcd" +
+	"\xec?Nn\x8c\xcd\xc0)\x9b;\x0c\x84\xa3q\xa1\xd3" +
+	"q\x80tXGw\x0c\xdaqSS\x135\x9d\xadY" +
+	"9Y\x9d\x12\xfc\xe2x\x12\x0e\xe5T\xe8\xcc\xc0\x84k" +
+	"\xe6D\xf6\x07\xb3\x94QS\xe3\x125\x99V\x16\xba\x07" +
+	"^7\x03@\x9d \xa1Z\xefX1[\x9cl\x02\xa8" +
+	"\x93$TC\x04eB\x92\xf6N\xd9\xd5s%T\xc3" +
+	"\x04m\xddqb \xd5\x06]5\x0e\x98\x94/C\x99" +
+	"\x16\xae\x0d\xc6\xf1\xab\xc0\x19\xc5>\x7f\x15pq\xcc\x8c" +
+	"6\xe9a\x8a\xc5\xf6\xbb_\xa09\xf9\x8f/\xbc\x94\xe9" +
+	"\xebs\xb30\xae\xa9-\xdc\xa4\x87\xc3\x0d\xb4\xb5\x8d\xc6" +
+	"\xad\x94\xb3+vI\xd0\x98 g:\xd8\x0a\x12\xe8\x06" +
+	"\x005$\xa1\xba\xd4C\xc2\x12F\xec\xad\x12\xaa+\x08" +
+	"\xca\x92\x94tY\xcb\xfd\x00\xea\x1d\x12\xaa\xab\xbb\xd8\x92" +
+	"I[\xdbt\x93\x06\xb1\x9e\x9a\x11=\x1e\xd7}Q\xc3" +
+	"%\x0b\x93d\xd9A\x1a\x0f\x98z\xcc\x02)jb\xb1" +
+	"=}\xf03O>P\xf4\xfciA[P\x8f\xc7\xc2" +
+	"Z\xa2\x16|FS\x14\x8b\xedS\xdf~\xfd\xf7\x05\x1b" +
+	"\x1a7\xf6\x1e\xe7\xe2\x96\x96\xa8\xea\xd0Z\xb2\x9b\xd4\xf8" +
+	"\x94\"T\x844#\x18fF\xf5Z\xf5\xdc\xb9\x9b\x87" +
+	"}xOV\x8dLi\x99\xc3L\x8f\x9euq0\xfd" +
+	"\xd3\xb3\xdc,A:f\xd2\x80f\xd1`}\x9b?\xac" +
+	"\xc7CI\xc9ezMo\xb8a\x11\x0c\xd1S\x0d\xc0" +
+	":[\x84d\xe0N\xcaUZ\xe6O*%T'y" +
+	"$^\xcb\x1c\xd9\xf5\x12\xaaS\x08\"A\xcf\xc3SV" +
+	"\xeb\x80$\xfdN\xba#a\xfc\x0f\xa4n@9u\xb9" +
+	"C\xa0\x88F\x00\x90\x8e\xb8\xd7\xe5\xf8t\xa3\x85\xa3\xee" +
+	":\x04\x19\xe7\xdb\xc2\x03AE\xd2\x07\xa99R.\x80" +
+	"\xfbNGQ\xcc\x92\xe5U@\xe4\x01>[x-\x14" +
+	"n\x0b\xadJ\xe4\xe7\xbaoW\x80\x1eX\xce\xa8h6" +
+	"\xa3mF\xb0J\xe4^Lq|a+\xde'w\xe3" +
+	"\x08H\x1d\xec\xf2y\x1bc\xe9V\x09\xd5]\x1e>\xef" +
+	"d\xaa\xb1]B\xf5i\xc6\xe7\xa4a=\xc5\x84\xb7K" +
+	"B\xf5\xcf\xcc\xb0H\xd2\xb0\xf62/\xb2GB\xf5 " +
+	"A9G*\xc1\x1c\x00\xf9E\x16\x05\xff,\xa1z\x84" +
+	"\xa0\x9c\x9bS\x82\xb9\x00\xf2!f\x97\x07%T_#" +
+	"(\xe7\xe5\x96`\x1e\x80|\x8cA\x1e\x91P\xfd\x1bA" +
+	"\xbb-NM\xe6\xc2\x00\x00\x8b\xed\x97\xe4Y\xbfy\xf7" +
+	"\xc5\x1d\xab\x1d\x19.\x0e$}\x05\xca\xa9\x9a\x80\x90_" +
+	"\x92\xb6)\xe0K\xc4\xa8\x9b}8\xab\xf5\x90\xcc\x1cp" +
+	" \x10f\xefeQ\xc6\x0e\x8f\xfd\xf7l\xdae\x96\xe6" +
+	"\xaf\xed&CK\xb18ed]#P\xff\x8c\xac\xc7" +
+	",\xbb\x8b\xa4\xa5\xee\xd2[lI\xe6%\xb9\x9e:\x07" +
+	"\x8a*\xb7,\x0f\x05\"\xe7\xfa|f\x9bQ\x89\xf5\x88" +
+	"=dUN\xf2Ydj\x91~&U\xdc\x82\xaab" +
+	"\xb1zj\xc6\xf5\xb8\xc54=\x85\x93x\xd3\xa2(>" +
+	"\xc9\xf2\x08\x80\xaaB\xac\x1a\x8c\xf2H_\x11K\xe6D" +
+	"lvK}\\3\xb3-r\"\xaarPF\xbf]" +
+	"\x15\x8b\xdd\xc4\x12\x1c\xf0Y\xb5\xc1\x9e\x1coH3)" +
+	"\x93\x9fk\x12YR\x99\x12\x82E\xed:\xed@9U" +
+	"\xa2\xf8\x12^Q\xb8\xf6>\xc5\x01\x81N\xbe\x8b\xce%" +
+	"\xfeT\xbe\xedZ\xe8\xe8y\x00\xeae\x12\xaa\xd7\x90\xae" +
+	"\xf1\xe7\xff}r\xb0z\xdf\xfb;\xdc\xd8kD-\xbd" +
+	"I\x0fhPd%\xd5o\x7f\xe5\xf2\xed\xaf\x0eYv" +
+	".;5=\x06\xe7.\x991\x8a\xfc\xa3\x8c' \xdc" +
+	"\x99\xa5\x0a\xed8\xa2hj\x9c\x9a\xea\x05.9k\x98" +
+	"\xee\xac\x96P\xfd\x19AA\xcd:\xe6\x1c\xee\x95P}" +
+	"\x88ErL:\x9c\x8d\x0c\xf0g\x12\xaa\x9b=\x0eg" +
+	"\xd3m\x00\xeaC\x12\xaa[=\x0e\xe7Q\x06\xb9YB" +
+	"u\x8f\xc7\xe1\xecf\x90OK\xa8\xbe@\xb0L\x8b\xc5" +
+	"<I\x8c\x16\x8bMcj\x09R\xd4\xc0| \x98\x0f" +
+	"\x99\xe9z\xb4\xc3\xa0fm\x90\xa2\xf3\x8e\x03\xf1\xa5\x8c" +
+	"\xb9)7A(N\x91\x0a\xc8\x16\xed\xa8\xa97\xeb\x86" +
+	"\x16\xc6dJ\x96\xda\xe82,/\x0b\x93\x9b\xa9\xc5\"" +
+	"\x0d\x0d\x8a,$j\xc4\xddL2[B;\xc5Mh" +
+	"\x87U\x1f\x9e\xdb\xf8\xf1\xfb\xb7\xa6%\xb4\x9e\xc5\xb4\x84" +
+	"\xd6\x93\xd7|\x89\x0c\xdbq\x04\xbd\x99U\x8d\x96z@" +
+	"y\xf5\xb8!\x9b\x1e3[\xbbTB\xf5*\x826\xdf" +
+	"\x1d\xac\xd1\x00=\x19ZQX7ZP\xf6\x04\xc8t" +
+	"\x9d\xcd\xeb\xc1\x02\x1bh3\xf3=&\x0b\x96Q\xa3\xab" +
+	"\xeeJ\x99N\xcar\xde\x91\xe2\xd1\xee\xc1\xbe.\x1b\xf6" +
+	"c<\xd8g>\xac\\\x1f\x05\xfcaU\x16\xd6\xfc4" +
+	"\x9c%;\xe9[\x02'^u\x1eCb\x18\xdd#\xa1" +
+	"\xfa\xa0\x07\xa3\xfb\xabS\x96$\"\xf7F\xe6@\x1e\x94" +
+	"P\xfd\x95\xc7\x90\xb6\x98\x8e\xcdl\xf7\x18\xd26\xbf\x93" +
+	"\x0c\xbc\xe01\xa4\xfdcR1\xfe\xdf\x13\xa4yRN" +
+	"\xe3\x96\xe3\xd0\\[\xf3\x06\xe7\xaf\xf6\x1c\x9c\xb3\xd58" +
+	"\x902y\x16\xda6z\xfa\xb1\xf2%\xd5@\x06\xe09" +
+	";\xc9\x01\xb9.U\xffY\x9c,\x89Xv\x8c\xb2\xd2" +
+	"\x9a\x1e\x00\x00,J5\xa6\x01\xb1(3\xe29\xf2c" +
+	"\xff\xa9e\xc8\xb3\x98W\xee\x8a\xecE\xc6\xdf\x17$T" +
+	"OyDv\x92\xf1\xf7o\x126\xa0\xe7\x19sv\x15" +
+	"@\x03J\xd88\x18S\xef\x18\xa5\x14M\x80\xc6\x0b\xd8" +
+	"\xfa\xe5l='\x87c\xad\x8cF?@\xe3el}" +
+	"\x12[\xcf\xcd\xe5\xa2Sj\xb1\x0e\xa0\xf1z\xb6>\x85" +
+	"\xad\xe7\xe5\xf1\xbcKQ\xb1\x01\xa0\xb1\x9e\xad\xcfd\xeb" +
+	">_\x09\xef\xf7\xde\x8c3\x00\x1a\xa7\xb3u\x0b\x09\xda" +
+	"1\xc7\x1b\x81\xf7QT\x9c*\x0a;r0\xa3a\xea" +
+	"\xf9\xecv;\x92\x9f\xed 5t\xe6\xd9P\xb86\xec" +
+	"\xf2\xc0\x8ahV \xd4@[\xa1\x8c\xc9=\xde\xad\xc8" +
+	"\x93\x80755\x81\x8f\x9a=\x80i\xb1\xd8\x14\xe6\xcf" +
+	"\x93j\x9a\xd5\xe2j\x03Q@\x16\x0c+6l*\xf3" +
+	"\xcf\xfe\xddgBUy\xb5rJ\"\x06\x92\x97\xa8o" +
+	"\xaf\xdd\xf3\xf2y\x7f\xb9\xeaMqC\xfa\x13\xc7\xe3\xcd" +
+	"\xa5N\x9e\xe8\x0c\xe3\x89\x8ehE\xa0\xe8\xed)2>" +
+	"\x0cD\x19\x80>D\xb7o\x84\xa2\x90\xad 6\x00\x91" +
+	"?\xf5!q\x8b\xcc(J\xc6\xf2\xe91@\xe4\xe3>" +
+	"\x94\xdc\xca8\x8a\xee\x8d|\xa8\x1a\x88\xbc\xd7\x87\xb9n" +
+	"\xe3\x1aE[R\xde9\x1f\x88\xbc\xcd\x87yn\x9d\x1f" +
+	"E\x13]\xde\xc4\xce\\\xe7C\x9f[\xbcGQ\xf7\x96" +
+	"W\xd6\x01\x91\x97\xfb0\xc7\xed\xe0\xa0\xe8V\xc8\x0b\xe7" +
+	"\x01\x91\xdb|\x98\xef\x8e_\xa0h\xfe\xca:\xdb\xa7\xf1" +
+	"\xc7\x0e\x0fj(\xa2Z\x11\x0bk\x95h[:\x9d\x12" +
+	"\x9d\x1a\xa7\x80f\xa5\x93\x91W\xe2b\xc7\xe8+\xd1\x16" +
+	")\x07T\x88\xa52^[\xa9D;\x1a\xa3\xbc\xda\xc5" +
+	"\xa3\x9d\x1d\x08kz\x84\xa9M\x91\xb3S\xe4\xca\xe0d" +
+	"\x85\x19bJE7H%\xa3\xa2-\x82b6A\x96" +
+	"g\x00\x91\x0b|\xb6\xa8x\x81\x14\xa1\xe9\xc7\xf5RX" +
+	"\xce\x0c\x94(\x0auE\x0cwvu>\xbfZ\x8cK" +
+	"\xa0\x18\xac\x90GW\x03T]\x8aU\xd7\xa0<\xd9\x87" +
+	"\xa9\xc1\x12\x14\x03\x1cr\x15\xcb\x94\xaf\xc1\xaaI(\xcf" +
+	"\xf2-v\x8a~\"\xee\xbb\xc5<o\xb2\x9c\xb6X\x89" +
+	"E,\xa9\xef\xcf\x06\x9e\xd2\xa5\x12`\xe8C\xaa\x9d\x9e" +
+	"\xdd\x08y\x9bn\x14\xf3\xbc\xf3\x87f{\xe7o\xc8V" +
+	"\x9cb\xce3(\xa1\x1a\xfb\x92E\x9c>Vi\xb8\xb0" +
+	"X\xe6\xe3\x9b@\x9b\x98\xac\xb2\xe7\xaer\xf6\xe45k" +
+	"\xccu\xcaP[\xfc\xa9<\x15\x99\xebF\x94w\xd7\xa5" +
+	"\xd2T9\x17K0\x17Q\xde_\x9d\x0a\xb9Nb\xda" +
+	"\xd5\x8b\xb5S\xd3_\x1f25\x90\xe2\xd9>\xbb\x0f[" +
+	"\x1f\xf3\xb8]\xbfg\xf5\xed\x82WQ\x7f<\xca:0" +
+	"\x90\xeax,\x0e\xd2&\x8d\xa5O\x99\x1d\x10\x92\x99\x80" +
+	"\x017\xae\xcb\xb8\x86\x8b\x19\x17\x14\xf3\x15J+\xaeR" +
+	"\x12xe\xcd\x11\xc4\x9a\xb7\x10\x95O\xb9+\x14\x1dl" +
+	"\x14\x83\x0a\xcai\xdc\xa7|\x8aW\xd6L\"X3\x93" +
+	"\xa0\x12!\xcc+\x8a\x0e.\x8a\x89\x07E#u\x005" +
+	"s\x09\xd6\xc4\x08*K\x08\xf3\x8fb\xda\x03E\xafX" +
+	"i#\x0d\x005\x16\xc1\x9a\xa5\x04\x95\x9f\x10\xe6)E" +
+	"o\x1cESWYN\xaa\x01\x18D\xcdj\x82\xcaF" +
+	"\xc2|\xa6\x98EA\xd1\xd4S\xd6\x90\x11\x00\x0c\xa2\xe6" +
+	"A\x82\xca6\xc2\xbc\xa7\x981C\xd1OT6\xf1\xb3" +
+	"\x1e$X\xb3\x95\xa0\xb2\x9b0_)FIP\x0c\x0f" +
+	");8^\xdb\x09\xd6\xec!\xa8\x1c%\xcc\xdb\x8a\x8e" +
+	";\x8a\xa9.e?\xbfq\x0f\xc1\x9a#\x04\x95\x93\xc4" +
+	"\x87\x05n\xab\x1a\xc5H\x8fr\x8c\xac\x05\xa8y\x8d`" +
+	"\xcd{\x04\x15\x94|x\x9e\xdb\x9cG1\xc2\xa6|D" +
+	"L\x80\x9a\x0f\x09\xd6\xe4H\xa8\x0c\x92|\xf8\x15wb" +
+	"\x03\xc5\xec\x99R \xd5\x81\x0ba\x8b\x8c\x1a\xc5\xa3\x16" +
+	"Cj>\x12\xbbt\xd6#\xf1\xd2\xaf\x0d^\x0fj1" +
+	"\xc1\xaaJ\xb4\xb7\\\xf5\xc8\xbdyK^\xfd\x18\xd8\xbb" +
+	"\xb4j\x02b!\xc0h\x9cGl\xe7-\xac\xe7\x1b\xcd" +
+	"\xe5qK\xb3\xf4@9O\x16\x0d\xab\\\x8f\x97\x1bQ" +
+	"\xab<\xde\x16\x8bEM\x8b\x06\xbf[\x1e\xa7f;-" +
+	"\xd7\xad\xf2&3\x1a)\xb7B\xb4\x9c\xebZ\xb9n\xc4" +
+	"\xad\"\xaa\x05G\x01\xc8\xf9\x08D\xf8+\xb7\xfd\xe7u" +
+	"bi\x8b\x95\xe8\xd2@2\x9f\x05\x00Yh\xb9\xdcK" +
+	"\xcbe\x04\xab\xaerh\xb9\x13\xed\x094\x10\xd6LZ" +
+	"\x9e\xab\xf1\xfd\xf1r\xdd\xe0H\xc6\xb4@\x8b\xd6L\xcb" +
+	"#\x9a\xa17\xd1\xb8\xc5\xf1\xe5\xe8b\xff\xd1\x15/)" +
+	"\x00\xe8\xff\xbei:\x05\xec\xe8\xcf\xbe\xcc@\xd2\x97=" +
+	"i\x81\xa4O\x978m\xdd\xfe\x11\xe4\x94F\x00\xfb\x87" +
+	"\x9c\xb7\x86\xd4\xa7\x8bDm\x15EqU\xb2\x12\xfd:" +
+	"@4~\xa1\x8c\x97\x0c\xfaGe\xaa\x08\xdd\x0dHo" +
+	"5\xae~\xbc\x83E\x83\xa5\x87\x87\xbc\xa7\x99\x98\xd9\x80" +
+	"\xef\xbd\xf6\x97-\x070\xbd\xf5#)\xfdi=4\xa3" +
+	"\xcb\xcc\x16G\xceH=\xad3\xa2}/\xdd\x98^z" +
+	"\xc4\xa2\x9a\xd9\xdb\xfd\x9e.w\xfa\xfd}{\xc8\xf70" +
+	"\x12\xe04\xb3\xbbk\xd0\xba}7\xde\xad\xb8\xe0\x98\xba" +
+	"i\xfe=Kw\xf5\xad\x01\x99L\x879\xa3+\xea\xb5" +
+	"L:\xe7e+a\xb0T\xeb\xf2dsP\xbc\xbb\xa7" +
+	"@\x11\x97\xab\xa8\\\xf5)\xbdJK\x9f\xd83X\xa2" +
+	"\x1dj>z\x07\xfe\x0a\xea<\xd3\xc6\x05~[\xb4\xf7" +
+	"\x01\xc0\xe6Mu\x7f\xb4\x13|S\xb4f\xb5\x98'\x0f" +
+	"b\xde\x19\xc5\x11r\xab\x1f\x88\xac\xfb05\xda\x83b" +
+	"(U\x9e\xc5\xb2\xf6\xa9,I\x10\x13\x8b(Fp\xe5" +
+	"Z\xd6\x7f\xb9\x8e\xa5\x06b\xcc\x15\xc5\xb4\xb0|\xf5m" +
+	"@\xe4\xd1<\xdb\xe7\x08\xf1\xfc\xb0\x12mQ\xd8gU" +
+	"\xc2\xe4\x9f\x9c\xb5(j2\xe8\xac\xf2\x06\x0b:\xa2\x80" +
+	"l\xef\x84\xec\x85\xe7l\x960\xa3o%\xb2i:H" +
+	"\xd9\xca\xd2\xa2V\xe6\xce\x82\xf5\xa1\xbe\x9b\xb5\xf4\xe8(" +
+	"O_*}n\xb7\xb67\x83\xf2wo\xd0}\xcc\xcc" +
+	"\xfb\xd4\x0f\xfb\x97\xcf\"yf4\x92\x8d\xfd/1\xa4" +
+	"\x11\xd1\x0d=\xd2\x16I\x8dk5\x865)\xc0\xba4" +
+	"\xc9Z\xd87\xb7\x07\x8f^\x07\xd0\xa3\xa0\xd2\xac\xbb\xa7" +
+	"\xdap/\xb8d\xa9wf\x8e\xd7\xe4d\xab\x07{\x8b" +
+	"\xc1\x92\xc1\x8b\x1c%\xbc\x9c\xc6o_\xc8\xb4\xb43\xd9" +
+	"\x8cO\x96\xd3\xd8\xea\x92\x86T7~\x089k;\xa5" +
+	"\xfd\xe5\xac\xb3\xbdTB\xf5G\x04\xd1y\x1c\xdd=\x1f" +
+	"@]\xe1<\xa3r0Y\x8e\xdb\xb8\xca)\xf7?\xcd" +
+	"\xa6J\xa2\x06\x85<[\x0b\x87\xab\x02\x01\x1a\x07\x8cC" +
+	"^\x05\xab=\xd5\x06\xd1\x07\x04}\x80\xb6\x16L\xea1" +
+	"T$59\xd3K\x994\x12m\xa7=\xd6\xa2\xfa2" +
+	"\x00\x93\xd9\xe8\xf6u;\x0e\xe2\x98\x87\x00dp)C" +
+	"\x9b@\x91\xbf0\x8b\xa5\x1cD\xceFm\x8cg\xd0\x81" +
+	"\x88A\x07\xcf\xcb\x97\xb1\x8b \xca\x11\x162\xc2\x12\xaa" +
+	"\x9d\x04\x8b\x9d\x9an\x1b\x93ALBuA\x0f\xcf\xc6" +
+	"\xde\xde\x85Y\x9e~\xc9\xbezf\x1f#[\x04\x12\xe5" +
+	"\x19\xd7\x0a{j\xe8#\xca\xb5#R\xa3)\xbc\\\x8d" +
+	"(\xab\xe3\x9d\xc7\xff\xf4n\xdboE1\xcd\x0a9\x08" +
+	"\x81\x8c\xe7\x01T\x18\xb4c\x8a\xe6\xef\xf28u\xa2Q" +
+	"#-KV\x9aY\x1b$\xb7\x1e{\xa6\xc2\xf4\x0e\xa2" +
+	"\xa4\xf70\xc68\x9e\xad\xd2\xe3\xd9\xae\xdd\xe0P6\x93" +
+	"`Yk\x1b5\x13\xdd\x17&\xfb\x15J{\xae\xa5\xf4" +
+	"6\xd2a\xe9\xac\x97\xd2\xad\x1b\xe8\xda\xbe\x16\x99Q\x89" +
+	"{\xdeB\xa6d\x0b$T\xef\xf0\x90\xbb\xac:e\xd3" +
+	"\xa2\xc5\xe0\x1d\xb0q[\x0c+M\x00\xf5G\x12\xaa\xf7" +
+	"zZ\x0c\xde\x12\xca\xbf\xa9\xe5\x9f\xbdM\xd0\xfd\xd0i" +
+	"7\x13\\\xdd\xa9z\xf6\x01\x8cLh\x96$\xb8\xd9\xf4" +
+	"\xff\xb6\xc1\xa6n\x06\x07\xc4<D\x1f8\xe3\xf2\xfc\x7f" +
+	"\x06\x00F\x8d\x17Z"
 
 func RegisterSchema(reg *schemas.Registry) {
 	reg.Register(&schemas.Schema{
@@ -11,7 +11,7 @@ import (
 
 // Constants defined in settings.capnp.
 var (
-	AdminSettings = Setting_List(capnp.MustUnmarshalRoot(x_df25727aa20cb09f[0:1024]).List())
+	AdminSettings = Setting_List(capnp.MustUnmarshalRoot(x_df25727aa20cb09f[0:7824]).List())
 )
 
 type Setting capnp.Struct
@@ -150,41 +150,143 @@ func (p Setting_Future) Default() schema.Value_Future {
 	return schema.Value_Future{Future: p.Future.Field(2, nil)}
 }
 
-const schema_df25727aa20cb09f = "x\xdaL\x92=h\x14]\x14\x86\xcf{\xef\xee\x97f" +
-	">w\x87I\xa3\x08\x015(\x16\xf9\xc1 \x12\x90d" +
-	"\x92\x9d\x90\xd1\xdd\xcc\xdc{g\xd5\x04a\x1c\xb2\xab\x06" +
-	"\x92\xcd\xb2;\x82\xa6\x11,\x05\x1b\x11\x8b\x84(\x88\xad" +
-	"\xb8\xa5\x7f\x9d\x8d\x85\x88\xa4\x11D\x91@\x0a#\x84\xa8" +
-	"\x9d\xa0\x8c\xcc\x8fl\xba\xe7<\xef9s\x18\xce\x1dz" +
-	"\x8d\xf1\xdc\xf0\xff\xbb=\xc4\xc4\xc5\xfc\x7f\xd1\xcb\xa9#" +
-	"\xbfo\x9f\\\xbbOz!\x17=\xech\x8fVZ\xfd" +
-	"_\x88`|\xe6\xdf\x8cm\xdeC\xa4\xb68\x87\xcc1" +
-	"\x10E\xbb\xb5\x07\xab\xcf\xd6\x7f~$\xbd\x80nw>" +
-	"n3\xb6\xf3/\x8c\x1f\xf9\x98v\xf2O\xe9k\xd4\xae" +
-	"\x87\xe1B\xe3J\x9b\x0d\xcc\x07\xcdFs4\xa8--" +
-	"4T=\x0c\x0b\xb1u\x01\xec#\xb8\x1c(v?K" +
-	"\xb1\xa4a\x1c\x859\xc4\xf5;\xab\xe2\x1e\x07\x91\xbe~" +
-	"W<N\xe0\xc9\x9c\xe8$\xf0\xfc\x8cx\xc5!\xde0" +
-	"\xe8\x9bRlq\x88\xef\x0c\xfa\x9f9\x09\x0e\xa5\x81\xc1" +
-	"\xd8\x8f[\xea \xe2f\xa3\x1f+\xeaX\x8a\xc3\x90j" +
-	"$\xc5\xd3\x90j<E\x1b-UN\xb1\x8a\x96\xba\x90" +
-	"`dNV,\xbfdKX\x93\x9e#g\xfd*\x97" +
-	"eh\xc4\xb2`F\xc1w\xa5c\x9f+Y\x90]o" +
-	"UL\xe2v\xda8a*\xcb\xaf\xca2\x11\xc554" +
-	"\"\x1d\x1b\xd1\xd50l\x8e\x0e\x0e.\xb2\xe5\xf9`q" +
-	"\xa0\x1d4j\xedp\xb9\xb54\xb0\x80\xe5h\xda\xf3\\" +
-	"\xdfu$\xc1\xeb\x8e\x1c\xe0\xa7\x86\x92D\xf9\xaeC\\" +
-	"\xee\x89\x0e\xf5\x8c\x8c\x9c\xc8\xb2I\x0b\xd2\xf3\xa7\xec\xb2" +
-	"\x95\xac\xcb\xecY\x8b\xc6f\x13\x9bHU\xf1\\\x7f\xda" +
-	"Q\xd9\x82\xb4\xee.L\xeb\xaa\xb2\xa8O\xce\x98\x95=" +
-	"3\xae\xa9\xa8O\x9dwd)q\xff\xce\x8b\xec\xbcj" +
-	",\x15. 4\x9e#\xca\xc5w\xb2\x8e\x13\x89q\x0e" +
-	"Qf\xd0\x81^\xc4\xd2\x8ee\x89C\xb8\x0c:c\xbd" +
-	"`Dze\x82HLs\x08\x8f\xa1\xd0\x08\x96\xea\xd9" +
-	"?\xa2\x10\xdeh\xd6Q\x8c.\xbd\xfd\xb5\xb9s\xbd\xfd" +
-	">~$E\xc2\xcdZ\xfdrpm1D1Z\xd3" +
-	":\x1f6>\x1d~\x97%\x7f\x03\x00\x00\xff\xff\xe1\x87" +
-	"\xba\x1a"
+const schema_df25727aa20cb09f = "x\xdamW\x7fh\x1b\xf7\x15\xbf\xafN\xd2\xc9v:" +
+	"%s\x0a\xddh\x91\xdb\xa5$+\xb3#)\x8e\xeb\xb6" +
+	"+\xf6Y\xfa\xda\xbe\xfaN'\x7f\xdf)\xb1K\xcaE" +
+	"\xb6\x95D\xc1\x964I\xd9\x9c\xd0\xd16l\xac5\x1d" +
+	"\x94\xd0\x8c\xe2.\xdb\x12\xf2G\x09\x0b\xcbB\xfeX\xb2" +
+	"\x05\xb2\xb1A\x07a\x840\x18)\x19kG\xfeHK" +
+	"G\xbb\xc2\xa0\x19\x19\xb7\xf7\xee{\x8e$'\x7f\x08\xde" +
+	"\xe7\xbd\xf7}\xef}\xdf\xaf\xaf.\xf9pd4\x9cz" +
+	"\xe83M\x09M\xef\x89D\xbd\xdf\x8eo\xb9\xbb2\xf4" +
+	"\xeeO\x94M\xf1\xb0\xf7\xf3s\x1bN\x1d\xa9?\xf9\x0f" +
+	"Ea\xbd\x7fW?\xe9\xfdX\xd5\x14\x05n\xa9*\x13" +
+	"\xe1\x10S\x14\xef\xb3\x85\x9f\xad\xfe\xe6\xc4\x17\x1f\xa06" +
+	"kiGH\xad7\xd5{\xa9\xf7\x99^\xa2v\xf6\xfe" +
+	"J\xb9\xed5J\xcdf\xb9\xb2\xbf\x11\x1a\x98/\xd6*" +
+	"\xb5g\x8b\x0bK\xe5\x0a 3N\xdc<c\xec+\x0a" +
+	"\xcb\xab\x8cml\x99U\x88\xa9\xa4\xd8\x8f\xb4\xcc(S" +
+	"{/\xab\xab\xf0G\xf4\x0e\xd7\xd4\x10\xeb\xfd\xb7z\x0c" +
+	"\xbeD\x84\x1eX\xf8E\x08\x87}\xf2\xa1\xf0\x0b\xb0\x11" +
+	"Ix\x14C\xec\xdd\x89\x82aBYB\xd3a\x01\x0e" +
+	"\xa1\xbd\x84\x96PV#\xf42\xa17\xc2G\xe1Mi" +
+	"\xe2x\xf8\x08\xbc#\xc9\x93\xc8=-\xc9\xb3H\x9e\x93" +
+	"\xe4\xc5\xf0\x19\xb8B'\xaf\xd2\xc9\x1b\xe1c\xf0!\xa1" +
+	"O\x09\xdd\x09\xaf\x8a\x08\x82\x0d\x11\x04_\x8b\x1c\x83>" +
+	"B\xdf\"\xf4Ld\x15F\x09\x99\x84f#+\xb07" +
+	"\xe2\xdb+G\x0e\xc2\"\x09\x96I\xf0\xc3\xc8'\xf0\x16" +
+	"\xa1\x9f\x12\xfaS\xe4\x0b\xb8&\xd5nD\xfe\x09\xb7H" +
+	"\xf09\x09\x1e\x8e\x1e\x85G\xa2\x88\xb6D\xe9\xa2\xd1U" +
+	"\xf86\xa1IB/!Z T#\xf4\xfd\xa8\x80W" +
+	"\xa3\xbe\x897\x90|S\x92\xc7\xa3uxG\x92'\x91" +
+	"<-\xc9\xb3\xa8pN\x92\x17\x91\xfb;2\xf2>\x19" +
+	"\xb9\x11=\x027\x09\xdd&\xf4\x9f\xe8%\xb8K(\xa6" +
+	"Q(\xda\x0a<\xaa\xf9\x87\x9e\xd4\xe6`\x9b$S\xda" +
+	"\x1f`X\xa3\xdc\x93N\x01\xd1\x1e)(i\xab\xb0(" +
+	"\xc9C\xda\x19x\x99t^'\x9d\xe3\x1a\x86$\x05'" +
+	"\xb5\xf3\xf0\x9e$\x7f\xad\x09\xb8 \xc9\xcbh\xfd\x8a$" +
+	"\xff\xac\x9d\x82kt\xf2&\x9d\xfcX;\x0a\x9f\x12\xfa" +
+	"\x92P$v\x14b1D\x9bc\x88\x1e\x8f\x1d\x83m" +
+	"1\x19\x12\x0a\x06I0J\x02+v\x06\x1cB{\x09" +
+	"-\xc5\xceC\x93\xd0\xab\x84~\x1c\xbb\x04o\xcbC'" +
+	"\xf0\xfciI\x9e\x8d\x9d\x82\x0b\x92\xbc\x8c\xa6\xae\x90\xfa" +
+	"UR\xbf\x11\xab\xc3MB\xb7\x09\xb1.\x01\xe1.\xd9" +
+	"\x8e]\xab\xb0Y\x92\x8fu\x9d\x87-HB\xb2\x0bu" +
+	"\x9eG\x94%\x94'\xf4\x12\xaa-\x10\xaa\x11z\xad\xeb" +
+	"(\xfc\x80\xd0[\x84Nt\xd5\xe1\x17\x84~I\xe8b" +
+	"\xd7\x0a\\!t\x95\xd0\x0d<\xf7!\xa1O\x09\xddA" +
+	"\xcd\xbb\x84b\xddT\x95\xee\x83\xf0H\xb7\xef\xfa\xf1\xee" +
+	"U\xd8&\xc9T\xf7\x0a\x0cwSUHg\x1a\xd1\x0c" +
+	"\xa1\x05B\xdf\xe9\xbe\x04\xcbR\xed5<\xf1:\x09\xde" +
+	"&\xc1\xc9\xeec\xf0\x1e\xa1\x0b\x84~\x8f\xb2\xf7\x09\xfd" +
+	"\x95\xd0G\xe8\xe6\x16\xa1\xcf\x09\xfd\x0f\x0d\x86{\xe4\xd5" +
+	"{V`\xb3$\x1f\xeb9\x02}=4\x07=tu" +
+	"D\xa3R` iJ\xb2\xd0s\x1e\xf6H\xb2\xd4s" +
+	"\x1dj>\xe9\xe9\x19\x8b\xbbYC0\x9eql1\xeb" +
+	"\x16Ta\xb2\x0dJ\x08\x7f\xb8\x17\x8e0\xef@\xb3Y" +
+	"k<\xbb}{\xb88\xbfT\xea\xffn2=P\xac" +
+	"\x95\x07\x16K\xcdF\xa92_?\\k\x0eT\xeb\xfb" +
+	"\xb7/\x94\xeb#\xa5\xf9f\xb5~8\xb0\x98\x03\xe6\xe6" +
+	"\x85\xbd\xcb\xc8r&\xc8\xa0\xe4sKWT\xc3\xf7\xe0" +
+	"\x8d\xe9\xc0\xdd\x820\x15\\C\x81\xc7M\xec\xba\xef\x10" +
+	"\xfd-\x86\xaa\xf3\xc5\xc5\x81F\xb1\xb2\xd0@\xbbK\x03" +
+	"eV\xf5L{\xc2\x1d\xb7\x85\xa5\xa8\xba\xd3:\xf3T" +
+	"\xbcYZnz\x93\x8e\x93w\xf3\xb6PX\x9b\xec\xeb" +
+	"\xeap\xd2\x97\x00\x8a\x14U\xb4\x89\x9e\xd0\x06\x07w\x04" +
+	"\xb2\x0cF\xe9\xb8\xe3\x86\xc9\xfdX\x02\xee\x14WFf" +
+	"}\xae\xcftD\x01\x1c\x9eu\x19^l\xc6\xe0 U" +
+	"E>\xe3\x9a\x068\x8c\xe7\xc8\xbb\xd32\xe0Z\xfa\x0c" +
+	"s'\xb9\x9e\xe5\xc2\x8d\x83\xf1\"o9\x1f{%\x95" +
+	"\x1c\x1c\xde\xf9\xf4\x90\xd44\xb2&\xe3\xaecX\xdc." +
+	"t\x86\x9f^j\xb35fgg]0\xd4vK_" +
+	"e\xf2\x82\xae\xe0:\xcb>\xc0\xc6=\x85\xdd\xc2`N" +
+	"\xe0E-<Hc\xd2\x06\xe6\xe0m,\xc3\x01%\xb8" +
+	"\x088@\xce\x95\xb8\xabO\xacsk\xe9F\xce\xcd\xd8" +
+	"\xb9\x90\xc3s\x8e\x0b<S\x10\x863\x8bY\x88\x9bF" +
+	"f\xb6\xd5F\x1f0o_\xbd\x88\xedS\xacD\xe7K" +
+	"T\xceF\xdf\xd6J\xb5R\xda\xfa\\_u\xee 6" +
+	"N\x7f\xa3>\x7f\x8f5Wl\x94\xfa\x0f\xd5\xcb}[" +
+	"\x1b\xa5Eu\xdfVoBHG\xa1\xdc:G\x09{" +
+	"\xcd\x91\xa7\xe7\x0d\x0a\xc5\x09\xad\x0b\xc5\xd4:B9\xc5" +
+	"\xbc\x85\xd2\xbe\xe2\xa1\xc5f\x7f\xa4\xdd'\xf5\xd9\\u" +
+	"\xf9\xb9\xbe\xb5@\xdb\xe2\xd4P\xc3\x13|\x9c\x0b\xc1\x05" +
+	"#\x9bh\xb2\xadg\xe7\xbc\x06\x9d\xa9\xd6\xcb\x8a\xb6\xbf" +
+	"\\\xf1\xf2\\X\x06\x80\xc1\xec\x1c\xf8\xdaj+\x82M" +
+	"l\xd5\x9b\xabW\xbf\xd7\xc0G\x99\xf57\xab\xb5\xf2|" +
+	"\xe3yu\xdb71\x973.\xdd\x93\xe5\xb0\x90\xd3\x05" +
+	"\x0e\xaa\x03\x9d\xd9\x06KV(\xe8o\x89[\xfd.q" +
+	"\x01\xb8\x92\x109\xdd\xe2m::(\x09\xd8m\x8bl" +
+	"\x8b7.l\x85Y-\x8c\x19S\x12~\xceZN_" +
+	"\xf0\x1a\xcdb\xbd\xd9\\l\xe0\x88z8\xba\x86\xe9f" +
+	"q$Lc\x17\x17\xb3\xed\xf3\xd7Xj\xd6\x02\x05\xd3" +
+	"f\x13X,\xa1;|DvR\xfb-vJo8" +
+	"/\xd8\x89\xc1\xbc\xc8>\x93\xd6mK\xd1\xb0\xd6\x01g" +
+	"\xc65rXu\xcb\xc8M\xb8\xd2:\x0d\x91\xd2\x1ea" +
+	"z(\x9d\x1a\x1cL&)B\xc1\xf3X\x19\xdd\x09\x19" +
+	"6\x9a\x16\x86\xa5\xd3B\xc3\x05#\xe74\x902\x92\xe2" +
+	"u\x85\xca\x9d\xfb\x05\x06\xb6X\\\xec\xd2\xcd\xf6\x19L" +
+	"-y\x16w\x84\x91\x01WI8\xf6\x14\x97\x01:\x93" +
+	"\x05k,\x87%3\xdd|v\x1c\xbb/aYzN" +
+	"&\xd9)\x88\x9c\xf4\x0d-LI\xd6D\xe0\xd6\xe7d" +
+	"\x04gY\xecXC7]\xcdq\xcc\xf6\xed\x94J\x1f" +
+	"\x90J\x98K\\\x0e~.\x95\xf6\xb0\x86\xb0'8\xf6" +
+	"\x19\x86\xcd\xc6\xf4\x0c\x86\x95m\x93\xa7\x13\x8b\xb4A[" +
+	"*\x82g\x0d\xc0\x98\x98\\\xbf\xf7\xf8\x94g\x1cm\xa5" +
+	"\xbd\xa0O\xa7\x93\x07Z\x1a\xb8\x9d\xfc\xb5\x11\xc7\xcd\xd2" +
+	"\xb1wSC\xc3\x07\x82\xe9\x04\xce\xa4\xb6\xa5'f:" +
+	"W\xc5\x13Zz\x10\xad\xe9\x96\x89{.\xcf\\\xcc\xa5" +
+	"\x9e\xd5\x9d\x11\xbd\xb5Z}!\xe4]F\xb9\xc0\xb95" +
+	"X\xb6\xc5\xc7n\xc6\xfb\xeb\x0eV`L+8m'" +
+	"2\xd8m\x99)\x17\xa6\xf8\xee\x8e\xcc\xecX\xc2}\x80" +
+	"[5\x87\xed\x8a\xd1\x14D[b\xff{\xefI\x0b\x15" +
+	"k\xb5\xfere\xa1\xb4\x1c<3#\xfe;S\xf5\xb2" +
+	"|\x17\xce\xa2]\xc0\xff\xcb\xbe/\x1c*\xe1\x82c3" +
+	"\x81\xf7r\xa7\x0b\xb6\xea\xe82\x08|3\x89\xc5 \xa3" +
+	"\xfb\xbd\x93\xe0\xf7\xf5\xce\x014g\xd9\xae\x9e\xc90\xbb" +
+	"\x90\xa3\xed:\x9e\xe0\xb4\x83;\xa7\xdbWz\x80\x0f\xa9" +
+	"!\xbc\xe0\xb9H*\xa8\xeb\x08\x1d&qM\xd0\x0c9" +
+	"\x98\xf3\xfbKG\x0f%6\x1a\xde\xdeWh\xef\x8at" +
+	"jH*\xf8SJ\x0f\x82\xec\xac\xce\x04&\x03\x0dK" +
+	"g3\xee8\x8e^\x01\xe7\x05:GYj\x98\xb6\x92" +
+	"\xc8Lu4\x86?1\xc1\xd2\x9eP\xe2\x98I\x99G" +
+	"\xc9\xc2\x9b[\xf4W\x03\xdd\xaar;\xac\xe9\xe6Y\xc1" +
+	"\xdd\xcd\x8d\x89\xc9\x8ehp\x12\x92\xc9@%\x8f\x8d\x01" +
+	"\xf7\x07\x8c\x9d\x98L\x0fbO\xe4\xb2c\xf6\x0c\x96c" +
+	"\x16\x0bb\x9a\xeeH\xbe\xf5@H\x03F\x96\x99\x0fz" +
+	"\x02\xd1\xc9\xce\xa5\x96\x81\xbcm\xfb\xcb\x86uT)\xdd" +
+	"\xbe\xa5)\xc5\xeb\x9e\xe2\xd6\xa3>f\xdacTM\xbc" +
+	"|\xc7(\xac\x0d\xe5\x9a\\V\x1b\xff\x7f\x05[P\xf2" +
+	"w\xd0\x1cd\xf366\xd4:\xbe2\"\xf8DGA" +
+	"\x85w\xa8\xd1_*6\x9a\xfd\x0aK\xb5\xe9\x8d\x15p" +
+	"!8\xeb\x0e\xe7\xf1\x153f:=ac\xe2\x9c\xbb" +
+	"S\x09>K\xd9i\x97\x85hMr\xc7]S\xe1L" +
+	"\xa6r\xedS\x93\x05\x9f\x9a0\"\x19\xf8\x919\xbdA" +
+	"\x0d+J\x18\xffZn\xe2O)\xca\xf4\xa8\xca\xa6\xcd" +
+	"\x10\xdb\xc4\xd8fFL\x83\x98Yd\xe6\x91\x19\x0am" +
+	"f!dZc\xc8\x9cD\xa6\x13b\xf1\x0a\xbe\xa5\xc1" +
+	"\xf5X\xbcy\xb8V\xc2\x0f\xd6\xbdW\xef|\xf4\xaf\xe5" +
+	"\xc65\xfa`\xdd\xa8\xb0W\x82'\x1c%\xefn8\xf7" +
+	"\xb7\xeb7\xbf\xf1\x97@\xf2\x7f\x82\x16\x9cZ"
 
 func RegisterSchema(reg *schemas.Registry) {
 	reg.Register(&schemas.Schema{
@@ -198,44 +300,248 @@ func RegisterSchema(reg *schemas.Registry) {
 }
 
 var x_df25727aa20cb09f = []byte{
-	0, 0, 0, 0, 127, 0, 0, 0,
-	1, 0, 0, 0, 39, 1, 0, 0,
-	48, 0, 0, 0, 0, 0, 3, 0,
-	141, 0, 0, 0, 154, 0, 0, 0,
-	148, 0, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 209, 3, 0, 0,
+	1, 0, 0, 0, 135, 7, 0, 0,
+	64, 1, 0, 0, 0, 0, 3, 0,
+	189, 3, 0, 0, 154, 0, 0, 0,
+	196, 3, 0, 0, 3, 0, 1, 0,
+	208, 3, 0, 0, 2, 0, 1, 0,
+	241, 3, 0, 0, 146, 0, 0, 0,
+	248, 3, 0, 0, 3, 0, 1, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
-	157, 0, 0, 0, 146, 0, 0, 0,
-	164, 0, 0, 0, 3, 0, 1, 0,
+	1, 4, 0, 0, 90, 0, 0, 0,
+	4, 4, 0, 0, 3, 0, 1, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
-	173, 0, 0, 0, 90, 0, 0, 0,
-	176, 0, 0, 0, 3, 0, 1, 0,
+	13, 4, 0, 0, 74, 0, 0, 0,
+	16, 4, 0, 0, 3, 0, 1, 0,
+	28, 4, 0, 0, 2, 0, 1, 0,
+	53, 4, 0, 0, 90, 0, 0, 0,
+	56, 4, 0, 0, 3, 0, 1, 0,
+	68, 4, 0, 0, 2, 0, 1, 0,
+	81, 4, 0, 0, 82, 0, 0, 0,
+	84, 4, 0, 0, 3, 0, 1, 0,
+	96, 4, 0, 0, 2, 0, 1, 0,
+	109, 4, 0, 0, 90, 0, 0, 0,
+	112, 4, 0, 0, 3, 0, 1, 0,
+	124, 4, 0, 0, 2, 0, 1, 0,
+	137, 4, 0, 0, 130, 0, 0, 0,
+	140, 4, 0, 0, 3, 0, 1, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
-	185, 0, 0, 0, 74, 0, 0, 0,
-	188, 0, 0, 0, 3, 0, 1, 0,
-	200, 0, 0, 0, 2, 0, 1, 0,
-	225, 0, 0, 0, 82, 0, 0, 0,
-	228, 0, 0, 0, 3, 0, 1, 0,
-	240, 0, 0, 0, 2, 0, 1, 0,
-	253, 0, 0, 0, 90, 0, 0, 0,
-	0, 1, 0, 0, 3, 0, 1, 0,
-	12, 1, 0, 0, 2, 0, 1, 0,
-	25, 1, 0, 0, 130, 0, 0, 0,
-	28, 1, 0, 0, 3, 0, 1, 0,
+	149, 4, 0, 0, 122, 0, 0, 0,
+	152, 4, 0, 0, 3, 0, 1, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
-	37, 1, 0, 0, 122, 0, 0, 0,
-	40, 1, 0, 0, 3, 0, 1, 0,
+	161, 4, 0, 0, 130, 0, 0, 0,
+	164, 4, 0, 0, 3, 0, 1, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
-	49, 1, 0, 0, 82, 0, 0, 0,
-	52, 1, 0, 0, 3, 0, 1, 0,
+	173, 4, 0, 0, 130, 0, 0, 0,
+	176, 4, 0, 0, 3, 0, 1, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
-	61, 1, 0, 0, 82, 0, 0, 0,
-	64, 1, 0, 0, 3, 0, 1, 0,
+	185, 4, 0, 0, 170, 0, 0, 0,
+	192, 4, 0, 0, 3, 0, 1, 0,
+	204, 4, 0, 0, 2, 0, 1, 0,
+	217, 4, 0, 0, 146, 0, 0, 0,
+	224, 4, 0, 0, 3, 0, 1, 0,
+	236, 4, 0, 0, 2, 0, 1, 0,
+	249, 4, 0, 0, 154, 0, 0, 0,
+	0, 5, 0, 0, 3, 0, 1, 0,
+	12, 5, 0, 0, 2, 0, 1, 0,
+	25, 5, 0, 0, 146, 0, 0, 0,
+	32, 5, 0, 0, 3, 0, 1, 0,
+	44, 5, 0, 0, 2, 0, 1, 0,
+	57, 5, 0, 0, 154, 0, 0, 0,
+	64, 5, 0, 0, 3, 0, 1, 0,
+	76, 5, 0, 0, 2, 0, 1, 0,
+	89, 5, 0, 0, 138, 0, 0, 0,
+	96, 5, 0, 0, 3, 0, 1, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
-	73, 1, 0, 0, 114, 0, 0, 0,
-	76, 1, 0, 0, 3, 0, 1, 0,
+	105, 5, 0, 0, 106, 0, 0, 0,
+	108, 5, 0, 0, 3, 0, 1, 0,
+	120, 5, 0, 0, 2, 0, 1, 0,
+	133, 5, 0, 0, 234, 0, 0, 0,
+	144, 5, 0, 0, 3, 0, 1, 0,
+	156, 5, 0, 0, 2, 0, 1, 0,
+	197, 5, 0, 0, 242, 0, 0, 0,
+	208, 5, 0, 0, 3, 0, 1, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
-	85, 1, 0, 0, 114, 0, 0, 0,
-	88, 1, 0, 0, 3, 0, 1, 0,
+	217, 5, 0, 0, 226, 0, 0, 0,
+	228, 5, 0, 0, 3, 0, 1, 0,
+	240, 5, 0, 0, 2, 0, 1, 0,
+	21, 6, 0, 0, 130, 0, 0, 0,
+	24, 6, 0, 0, 3, 0, 1, 0,
+	36, 6, 0, 0, 2, 0, 1, 0,
+	53, 6, 0, 0, 154, 0, 0, 0,
+	60, 6, 0, 0, 3, 0, 1, 0,
+	72, 6, 0, 0, 2, 0, 1, 0,
+	93, 6, 0, 0, 154, 0, 0, 0,
+	100, 6, 0, 0, 3, 0, 1, 0,
+	112, 6, 0, 0, 2, 0, 1, 0,
+	125, 6, 0, 0, 82, 0, 0, 0,
+	128, 6, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	137, 6, 0, 0, 82, 0, 0, 0,
+	140, 6, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	149, 6, 0, 0, 114, 0, 0, 0,
+	152, 6, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	161, 6, 0, 0, 114, 0, 0, 0,
+	164, 6, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	173, 6, 0, 0, 82, 0, 0, 0,
+	176, 6, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	185, 6, 0, 0, 114, 0, 0, 0,
+	188, 6, 0, 0, 3, 0, 1, 0,
+	200, 6, 0, 0, 2, 0, 1, 0,
+	217, 6, 0, 0, 122, 0, 0, 0,
+	220, 6, 0, 0, 3, 0, 1, 0,
+	232, 6, 0, 0, 2, 0, 1, 0,
+	245, 6, 0, 0, 186, 0, 0, 0,
+	252, 6, 0, 0, 3, 0, 1, 0,
+	8, 7, 0, 0, 2, 0, 1, 0,
+	21, 7, 0, 0, 138, 0, 0, 0,
+	28, 7, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	37, 7, 0, 0, 98, 0, 0, 0,
+	40, 7, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	49, 7, 0, 0, 194, 0, 0, 0,
+	56, 7, 0, 0, 3, 0, 1, 0,
+	68, 7, 0, 0, 2, 0, 1, 0,
+	85, 7, 0, 0, 194, 0, 0, 0,
+	92, 7, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	101, 7, 0, 0, 154, 0, 0, 0,
+	108, 7, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	117, 7, 0, 0, 170, 0, 0, 0,
+	124, 7, 0, 0, 3, 0, 1, 0,
+	136, 7, 0, 0, 2, 0, 1, 0,
+	149, 7, 0, 0, 114, 0, 0, 0,
+	152, 7, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	161, 7, 0, 0, 178, 0, 0, 0,
+	168, 7, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	177, 7, 0, 0, 82, 0, 0, 0,
+	180, 7, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	189, 7, 0, 0, 98, 0, 0, 0,
+	192, 7, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	201, 7, 0, 0, 162, 0, 0, 0,
+	208, 7, 0, 0, 3, 0, 1, 0,
+	220, 7, 0, 0, 2, 0, 1, 0,
+	233, 7, 0, 0, 130, 0, 0, 0,
+	236, 7, 0, 0, 3, 0, 1, 0,
+	248, 7, 0, 0, 2, 0, 1, 0,
+	5, 8, 0, 0, 130, 0, 0, 0,
+	8, 8, 0, 0, 3, 0, 1, 0,
+	20, 8, 0, 0, 2, 0, 1, 0,
+	33, 8, 0, 0, 146, 0, 0, 0,
+	40, 8, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	49, 8, 0, 0, 130, 0, 0, 0,
+	52, 8, 0, 0, 3, 0, 1, 0,
+	64, 8, 0, 0, 2, 0, 1, 0,
+	77, 8, 0, 0, 170, 0, 0, 0,
+	84, 8, 0, 0, 3, 0, 1, 0,
+	96, 8, 0, 0, 2, 0, 1, 0,
+	109, 8, 0, 0, 178, 0, 0, 0,
+	116, 8, 0, 0, 3, 0, 1, 0,
+	128, 8, 0, 0, 2, 0, 1, 0,
+	141, 8, 0, 0, 186, 0, 0, 0,
+	148, 8, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	157, 8, 0, 0, 146, 0, 0, 0,
+	164, 8, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	173, 8, 0, 0, 162, 0, 0, 0,
+	180, 8, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	189, 8, 0, 0, 130, 0, 0, 0,
+	192, 8, 0, 0, 3, 0, 1, 0,
+	204, 8, 0, 0, 2, 0, 1, 0,
+	217, 8, 0, 0, 114, 0, 0, 0,
+	220, 8, 0, 0, 3, 0, 1, 0,
+	232, 8, 0, 0, 2, 0, 1, 0,
+	1, 9, 0, 0, 82, 0, 0, 0,
+	4, 9, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	13, 9, 0, 0, 154, 0, 0, 0,
+	20, 9, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	29, 9, 0, 0, 178, 0, 0, 0,
+	36, 9, 0, 0, 3, 0, 1, 0,
+	48, 9, 0, 0, 2, 0, 1, 0,
+	61, 9, 0, 0, 178, 0, 0, 0,
+	68, 9, 0, 0, 3, 0, 1, 0,
+	80, 9, 0, 0, 2, 0, 1, 0,
+	93, 9, 0, 0, 154, 0, 0, 0,
+	100, 9, 0, 0, 3, 0, 1, 0,
+	112, 9, 0, 0, 2, 0, 1, 0,
+	129, 9, 0, 0, 130, 0, 0, 0,
+	132, 9, 0, 0, 3, 0, 1, 0,
+	144, 9, 0, 0, 2, 0, 1, 0,
+	157, 9, 0, 0, 114, 0, 0, 0,
+	160, 9, 0, 0, 3, 0, 1, 0,
+	172, 9, 0, 0, 2, 0, 1, 0,
+	185, 9, 0, 0, 138, 0, 0, 0,
+	192, 9, 0, 0, 3, 0, 1, 0,
+	204, 9, 0, 0, 2, 0, 1, 0,
+	217, 9, 0, 0, 154, 0, 0, 0,
+	224, 9, 0, 0, 3, 0, 1, 0,
+	236, 9, 0, 0, 2, 0, 1, 0,
+	249, 9, 0, 0, 114, 0, 0, 0,
+	252, 9, 0, 0, 3, 0, 1, 0,
+	8, 10, 0, 0, 2, 0, 1, 0,
+	21, 10, 0, 0, 106, 0, 0, 0,
+	24, 10, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	33, 10, 0, 0, 154, 0, 0, 0,
+	40, 10, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	49, 10, 0, 0, 138, 0, 0, 0,
+	56, 10, 0, 0, 3, 0, 1, 0,
+	68, 10, 0, 0, 2, 0, 1, 0,
+	81, 10, 0, 0, 138, 0, 0, 0,
+	88, 10, 0, 0, 3, 0, 1, 0,
+	100, 10, 0, 0, 2, 0, 1, 0,
+	113, 10, 0, 0, 186, 0, 0, 0,
+	120, 10, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	129, 10, 0, 0, 154, 0, 0, 0,
+	136, 10, 0, 0, 3, 0, 1, 0,
+	148, 10, 0, 0, 2, 0, 1, 0,
+	161, 10, 0, 0, 146, 0, 0, 0,
+	168, 10, 0, 0, 3, 0, 1, 0,
+	180, 10, 0, 0, 2, 0, 1, 0,
+	193, 10, 0, 0, 154, 0, 0, 0,
+	200, 10, 0, 0, 3, 0, 1, 0,
+	212, 10, 0, 0, 2, 0, 1, 0,
+	225, 10, 0, 0, 106, 0, 0, 0,
+	228, 10, 0, 0, 3, 0, 1, 0,
+	240, 10, 0, 0, 2, 0, 1, 0,
+	253, 10, 0, 0, 138, 0, 0, 0,
+	4, 11, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	13, 11, 0, 0, 138, 0, 0, 0,
+	20, 11, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	29, 11, 0, 0, 122, 0, 0, 0,
+	32, 11, 0, 0, 3, 0, 1, 0,
+	44, 11, 0, 0, 2, 0, 1, 0,
+	61, 11, 0, 0, 122, 0, 0, 0,
+	64, 11, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	73, 11, 0, 0, 122, 0, 0, 0,
+	76, 11, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	85, 11, 0, 0, 178, 0, 0, 0,
+	92, 11, 0, 0, 3, 0, 1, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	101, 11, 0, 0, 210, 0, 0, 0,
+	112, 11, 0, 0, 3, 0, 1, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	65, 67, 77, 69, 95, 68, 73, 82,
 	69, 67, 84, 79, 82, 89, 95, 85,
@@ -244,8 +550,17 @@ var x_df25727aa20cb09f = []byte{
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 122, 1, 0, 0,
+	104, 116, 116, 112, 115, 58, 47, 47,
+	97, 99, 109, 101, 45, 118, 48, 50,
+	46, 97, 112, 105, 46, 108, 101, 116,
+	115, 101, 110, 99, 114, 121, 112, 116,
+	46, 111, 114, 103, 47, 100, 105, 114,
+	101, 99, 116, 111, 114, 121, 0, 0,
 	65, 67, 77, 69, 95, 68, 78, 83,
-	95, 80, 82, 79, 73, 86, 68, 69,
+	95, 80, 82, 79, 86, 73, 68, 69,
 	82, 0, 0, 0, 0, 0, 0, 0,
 	12, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
@@ -270,6 +585,16 @@ var x_df25727aa20cb09f = []byte{
 	111, 99, 97, 108, 46, 115, 97, 110,
 	100, 115, 116, 111, 114, 109, 46, 105,
 	111, 0, 0, 0, 0, 0, 0, 0,
+	76, 79, 71, 95, 70, 79, 82, 77,
+	65, 84, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 42, 0, 0, 0,
+	116, 101, 120, 116, 0, 0, 0, 0,
 	72, 84, 84, 80, 95, 80, 79, 82,
 	84, 0, 0, 0, 0, 0, 0, 0,
 	12, 0, 0, 0, 0, 0, 0, 0,
@@ -302,6 +627,170 @@ var x_df25727aa20cb09f = []byte{
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
+	84, 82, 85, 83, 84, 69, 68, 95,
+	80, 82, 79, 88, 73, 69, 83, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	82, 80, 67, 95, 76, 73, 83, 84,
+	69, 78, 95, 80, 79, 82, 84, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	72, 84, 84, 80, 95, 77, 65, 88,
+	95, 72, 69, 65, 68, 69, 82, 95,
+	83, 73, 90, 69, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 66, 0, 0, 0,
+	49, 48, 52, 56, 53, 55, 54, 0,
+	72, 84, 84, 80, 95, 73, 68, 76,
+	69, 95, 84, 73, 77, 69, 79, 85,
+	84, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 26, 0, 0, 0,
+	50, 109, 0, 0, 0, 0, 0, 0,
+	72, 84, 84, 80, 95, 77, 65, 88,
+	95, 66, 79, 68, 89, 95, 83, 73,
+	90, 69, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 18, 0, 0, 0,
+	48, 0, 0, 0, 0, 0, 0, 0,
+	72, 84, 84, 80, 95, 82, 69, 65,
+	68, 95, 84, 73, 77, 69, 79, 85,
+	84, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 18, 0, 0, 0,
+	48, 0, 0, 0, 0, 0, 0, 0,
+	72, 84, 84, 80, 95, 87, 82, 73,
+	84, 69, 95, 84, 73, 77, 69, 79,
+	85, 84, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 18, 0, 0, 0,
+	48, 0, 0, 0, 0, 0, 0, 0,
+	72, 84, 84, 80, 95, 72, 79, 83,
+	84, 95, 76, 73, 77, 73, 84, 83,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	72, 83, 84, 83, 95, 77, 65, 88,
+	95, 65, 71, 69, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 18, 0, 0, 0,
+	48, 0, 0, 0, 0, 0, 0, 0,
+	77, 65, 73, 78, 95, 67, 79, 78,
+	84, 69, 78, 84, 95, 83, 69, 67,
+	85, 82, 73, 84, 89, 95, 80, 79,
+	76, 73, 67, 89, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 218, 1, 0, 0,
+	102, 114, 97, 109, 101, 45, 97, 110,
+	99, 101, 115, 116, 111, 114, 115, 32,
+	39, 110, 111, 110, 101, 39, 59, 32,
+	111, 98, 106, 101, 99, 116, 45, 115,
+	114, 99, 32, 39, 110, 111, 110, 101,
+	39, 59, 32, 98, 97, 115, 101, 45,
+	117, 114, 105, 32, 39, 115, 101, 108,
+	102, 39, 0, 0, 0, 0, 0, 0,
+	71, 82, 65, 73, 78, 95, 67, 79,
+	78, 84, 69, 78, 84, 95, 83, 69,
+	67, 85, 82, 73, 84, 89, 95, 80,
+	79, 76, 73, 67, 89, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	65, 80, 73, 95, 67, 79, 78, 84,
+	69, 78, 84, 95, 83, 69, 67, 85,
+	82, 73, 84, 89, 95, 80, 79, 76,
+	73, 67, 89, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 162, 1, 0, 0,
+	100, 101, 102, 97, 117, 108, 116, 45,
+	115, 114, 99, 32, 39, 110, 111, 110,
+	101, 39, 59, 32, 115, 97, 110, 100,
+	98, 111, 120, 59, 32, 102, 114, 97,
+	109, 101, 45, 97, 110, 99, 101, 115,
+	116, 111, 114, 115, 32, 39, 110, 111,
+	110, 101, 39, 0, 0, 0, 0, 0,
+	82, 69, 70, 69, 82, 82, 69, 82,
+	95, 80, 79, 76, 73, 67, 89, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 98, 0, 0, 0,
+	115, 97, 109, 101, 45, 111, 114, 105,
+	103, 105, 110, 0, 0, 0, 0, 0,
+	80, 69, 82, 77, 73, 83, 83, 73,
+	79, 78, 83, 95, 80, 79, 76, 73,
+	67, 89, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 154, 0, 0, 0,
+	98, 114, 111, 119, 115, 105, 110, 103,
+	45, 116, 111, 112, 105, 99, 115, 61,
+	40, 41, 0, 0, 0, 0, 0, 0,
+	77, 65, 88, 95, 71, 82, 65, 73,
+	78, 95, 82, 69, 81, 85, 69, 83,
+	84, 83, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 18, 0, 0, 0,
+	48, 0, 0, 0, 0, 0, 0, 0,
 	83, 77, 84, 80, 95, 72, 79, 83,
 	84, 0, 0, 0, 0, 0, 0, 0,
 	12, 0, 0, 0, 0, 0, 0, 0,
@@ -326,4 +815,467 @@ var x_df25727aa20cb09f = []byte{
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0,
+	83, 77, 84, 80, 95, 70, 82, 79,
+	77, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	83, 77, 84, 80, 95, 83, 69, 67,
+	85, 82, 73, 84, 89, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 74, 0, 0, 0,
+	115, 116, 97, 114, 116, 116, 108, 115,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	69, 77, 65, 73, 76, 95, 68, 69,
+	76, 73, 86, 69, 82, 89, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 42, 0, 0, 0,
+	115, 109, 116, 112, 0, 0, 0, 0,
+	69, 77, 65, 73, 76, 95, 76, 79,
+	71, 73, 78, 95, 82, 65, 84, 69,
+	95, 76, 73, 77, 73, 84, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 18, 0, 0, 0,
+	53, 0, 0, 0, 0, 0, 0, 0,
+	83, 77, 84, 80, 95, 76, 73, 83,
+	84, 69, 78, 95, 80, 79, 82, 84,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	77, 65, 73, 76, 95, 68, 79, 77,
+	65, 73, 78, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	77, 65, 88, 95, 73, 78, 67, 79,
+	77, 73, 78, 71, 95, 69, 77, 65,
+	73, 76, 95, 83, 73, 90, 69, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 74, 0, 0, 0,
+	50, 54, 50, 49, 52, 52, 48, 48,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	82, 69, 80, 76, 73, 67, 65, 84,
+	73, 79, 78, 95, 80, 82, 73, 77,
+	65, 82, 89, 95, 85, 82, 76, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	82, 69, 80, 76, 73, 67, 65, 84,
+	73, 79, 78, 95, 83, 69, 67, 82,
+	69, 84, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	82, 69, 80, 76, 73, 67, 65, 84,
+	73, 79, 78, 95, 73, 78, 84, 69,
+	82, 86, 65, 76, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 26, 0, 0, 0,
+	49, 109, 0, 0, 0, 0, 0, 0,
+	77, 69, 84, 82, 73, 67, 83, 95,
+	84, 79, 75, 69, 78, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	84, 72, 85, 77, 66, 78, 65, 73,
+	76, 95, 80, 68, 70, 95, 67, 79,
+	77, 77, 65, 78, 68, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	84, 85, 82, 78, 95, 85, 82, 76,
+	83, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	84, 85, 82, 78, 95, 83, 69, 67,
+	82, 69, 84, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	84, 85, 82, 78, 95, 67, 82, 69,
+	68, 69, 78, 84, 73, 65, 76, 95,
+	84, 84, 76, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 34, 0, 0, 0,
+	49, 50, 104, 0, 0, 0, 0, 0,
+	84, 85, 82, 78, 95, 82, 65, 84,
+	69, 95, 76, 73, 77, 73, 84, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 26, 0, 0, 0,
+	54, 48, 0, 0, 0, 0, 0, 0,
+	83, 69, 83, 83, 73, 79, 78, 95,
+	66, 65, 67, 75, 69, 78, 68, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 50, 0, 0, 0,
+	108, 111, 99, 97, 108, 0, 0, 0,
+	83, 69, 83, 83, 73, 79, 78, 95,
+	82, 69, 68, 73, 83, 95, 85, 82,
+	76, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	83, 69, 83, 83, 73, 79, 78, 95,
+	77, 65, 88, 95, 65, 71, 69, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 42, 0, 0, 0,
+	55, 50, 48, 104, 0, 0, 0, 0,
+	83, 69, 83, 83, 73, 79, 78, 95,
+	73, 68, 76, 69, 95, 84, 73, 77,
+	69, 79, 85, 84, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 42, 0, 0, 0,
+	49, 54, 56, 104, 0, 0, 0, 0,
+	71, 82, 65, 73, 78, 95, 83, 69,
+	83, 83, 73, 79, 78, 95, 77, 65,
+	88, 95, 65, 71, 69, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 34, 0, 0, 0,
+	50, 52, 104, 0, 0, 0, 0, 0,
+	83, 65, 77, 76, 95, 73, 68, 80,
+	95, 77, 69, 84, 65, 68, 65, 84,
+	65, 95, 70, 73, 76, 69, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	83, 65, 77, 76, 95, 83, 80, 95,
+	69, 78, 84, 73, 84, 89, 95, 73,
+	68, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	83, 65, 77, 76, 95, 78, 65, 77,
+	69, 95, 65, 84, 84, 82, 73, 66,
+	85, 84, 69, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	83, 65, 77, 76, 95, 67, 76, 79,
+	67, 75, 95, 83, 75, 69, 87, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 26, 0, 0, 0,
+	51, 109, 0, 0, 0, 0, 0, 0,
+	65, 80, 80, 95, 73, 78, 68, 69,
+	88, 95, 85, 82, 76, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 250, 0, 0, 0,
+	104, 116, 116, 112, 115, 58, 47, 47,
+	97, 112, 112, 45, 105, 110, 100, 101,
+	120, 46, 115, 97, 110, 100, 115, 116,
+	111, 114, 109, 46, 105, 111, 0, 0,
+	68, 69, 86, 95, 71, 82, 79, 85,
+	80, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	85, 83, 69, 82, 95, 83, 84, 79,
+	82, 65, 71, 69, 95, 81, 85, 79,
+	84, 65, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	83, 84, 79, 82, 65, 71, 69, 95,
+	83, 67, 65, 78, 95, 73, 78, 84,
+	69, 82, 86, 65, 76, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 26, 0, 0, 0,
+	49, 104, 0, 0, 0, 0, 0, 0,
+	68, 69, 77, 79, 95, 65, 67, 67,
+	79, 85, 78, 84, 95, 76, 73, 70,
+	69, 84, 73, 77, 69, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 18, 0, 0, 0,
+	48, 0, 0, 0, 0, 0, 0, 0,
+	68, 69, 77, 79, 95, 83, 84, 79,
+	82, 65, 71, 69, 95, 81, 85, 79,
+	84, 65, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 82, 0, 0, 0,
+	49, 48, 52, 56, 53, 55, 54, 48,
+	48, 0, 0, 0, 0, 0, 0, 0,
+	84, 82, 65, 83, 72, 95, 82, 69,
+	84, 69, 78, 84, 73, 79, 78, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 42, 0, 0, 0,
+	55, 50, 48, 104, 0, 0, 0, 0,
+	76, 79, 71, 95, 82, 69, 84, 69,
+	78, 84, 73, 79, 78, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 50, 0, 0, 0,
+	50, 49, 54, 48, 104, 0, 0, 0,
+	76, 79, 71, 73, 78, 95, 82, 65,
+	84, 69, 95, 76, 73, 77, 73, 84,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 26, 0, 0, 0,
+	51, 48, 0, 0, 0, 0, 0, 0,
+	76, 79, 71, 73, 78, 95, 77, 65,
+	88, 95, 70, 65, 73, 76, 85, 82,
+	69, 83, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 18, 0, 0, 0,
+	53, 0, 0, 0, 0, 0, 0, 0,
+	76, 79, 71, 73, 78, 95, 76, 79,
+	67, 75, 79, 85, 84, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 26, 0, 0, 0,
+	49, 109, 0, 0, 0, 0, 0, 0,
+	71, 82, 65, 73, 78, 95, 67, 71,
+	82, 79, 85, 80, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	71, 82, 65, 73, 78, 95, 77, 69,
+	77, 79, 82, 89, 95, 76, 73, 77,
+	73, 84, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	71, 82, 65, 73, 78, 95, 67, 80,
+	85, 95, 87, 69, 73, 71, 72, 84,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 34, 0, 0, 0,
+	49, 48, 48, 0, 0, 0, 0, 0,
+	71, 82, 65, 73, 78, 95, 80, 73,
+	68, 83, 95, 76, 73, 77, 73, 84,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 42, 0, 0, 0,
+	49, 48, 50, 52, 0, 0, 0, 0,
+	83, 65, 78, 68, 66, 79, 88, 95,
+	83, 89, 83, 67, 65, 76, 76, 95,
+	80, 79, 76, 73, 67, 89, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	71, 82, 65, 73, 78, 95, 73, 68,
+	76, 69, 95, 84, 73, 77, 69, 79,
+	85, 84, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 34, 0, 0, 0,
+	49, 53, 109, 0, 0, 0, 0, 0,
+	83, 65, 78, 68, 66, 79, 88, 95,
+	80, 79, 79, 76, 95, 83, 73, 90,
+	69, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 18, 0, 0, 0,
+	50, 0, 0, 0, 0, 0, 0, 0,
+	77, 65, 88, 95, 71, 82, 65, 73,
+	78, 95, 76, 79, 71, 95, 83, 73,
+	90, 69, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 66, 0, 0, 0,
+	49, 48, 52, 56, 53, 55, 54, 0,
+	66, 76, 79, 66, 95, 83, 84, 79,
+	82, 65, 71, 69, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 50, 0, 0, 0,
+	108, 111, 99, 97, 108, 0, 0, 0,
+	66, 76, 79, 66, 95, 83, 84, 79,
+	82, 65, 71, 69, 95, 68, 73, 82,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	66, 76, 79, 66, 95, 83, 51, 95,
+	69, 78, 68, 80, 79, 73, 78, 84,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	66, 76, 79, 66, 95, 83, 51, 95,
+	82, 69, 71, 73, 79, 78, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 82, 0, 0, 0,
+	117, 115, 45, 101, 97, 115, 116, 45,
+	49, 0, 0, 0, 0, 0, 0, 0,
+	66, 76, 79, 66, 95, 83, 51, 95,
+	66, 85, 67, 75, 69, 84, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	66, 76, 79, 66, 95, 83, 51, 95,
+	80, 82, 69, 70, 73, 88, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	66, 76, 79, 66, 95, 83, 51, 95,
+	65, 67, 67, 69, 83, 83, 95, 75,
+	69, 89, 95, 73, 68, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	66, 76, 79, 66, 95, 83, 51, 95,
+	83, 69, 67, 82, 69, 84, 95, 65,
+	67, 67, 69, 83, 83, 95, 75, 69,
+	89, 0, 0, 0, 0, 0, 0, 0,
+	12, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0,
 }
@@ -0,0 +1,601 @@
+// Code generated by capnpc-go. DO NOT EDIT.
+
+package thumbnail
+
+import (
+	capnp "capnproto.org/go/capnp/v3"
+	text "capnproto.org/go/capnp/v3/encoding/text"
+	fc "capnproto.org/go/capnp/v3/flowcontrol"
+	schemas "capnproto.org/go/capnp/v3/schemas"
+	server "capnproto.org/go/capnp/v3/server"
+	context "context"
+)
+
+type Thumbnailer capnp.Client
+
+// Thumbnailer_TypeID is the unique identifier for the type Thumbnailer.
+const Thumbnailer_TypeID = 0x83568095e1ad3c40
+
+func (c Thumbnailer) Accepts(ctx context.Context, params func(Thumbnailer_accepts_Params) error) (Thumbnailer_accepts_Results_Future, capnp.ReleaseFunc) {
+
+	s := capnp.Send{
+		Method: capnp.Method{
+			InterfaceID:   0x83568095e1ad3c40,
+			MethodID:      0,
+			InterfaceName: "thumbnail.capnp:Thumbnailer",
+			MethodName:    "accepts",
+		},
+	}
+	if params != nil {
+		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 1}
+		s.PlaceArgs = func(s capnp.Struct) error { return params(Thumbnailer_accepts_Params(s)) }
+	}
+
+	ans, release := capnp.Client(c).SendCall(ctx, s)
+	return Thumbnailer_accepts_Results_Future{Future: ans.Future()}, release
+
+}
+
+func (c Thumbnailer) Thumbnail(ctx context.Context, params func(Thumbnailer_thumbnail_Params) error) (Thumbnailer_thumbnail_Results_Future, capnp.ReleaseFunc) {
+
+	s := capnp.Send{
+		Method: capnp.Method{
+			InterfaceID:   0x83568095e1ad3c40,
+			MethodID:      1,
+			InterfaceName: "thumbnail.capnp:Thumbnailer",
+			MethodName:    "thumbnail",
+		},
+	}
+	if params != nil {
+		s.ArgsSize = capnp.ObjectSize{DataSize: 8, PointerCount: 2}
+		s.PlaceArgs = func(s capnp.Struct) error { return params(Thumbnailer_thumbnail_Params(s)) }
+	}
+
+	ans, release := capnp.Client(c).SendCall(ctx, s)
+	return Thumbnailer_thumbnail_Results_Future{Future: ans.Future()}, release
+
+}
+
+func (c Thumbnailer) WaitStreaming() error {
+	return capnp.Client(c).WaitStreaming()
+}
+
+// String returns a string that identifies this capability for debugging
+// purposes.  Its format should not be depended on: in particular, it
+// should not be used to compare clients.  Use IsSame to compare clients
+// for equality.
+func (c Thumbnailer) String() string {
+	return "Thumbnailer(" + capnp.Client(c).String() + ")"
+}
+
+// AddRef creates a new Client that refers to the same capability as c.
+// If c is nil or has resolved to null, then AddRef returns nil.
+func (c Thumbnailer) AddRef() Thumbnailer {
+	return Thumbnailer(capnp.Client(c).AddRef())
+}
+
+// Release releases a capability reference.  If this is the last
+// reference to the capability, then the underlying resources associated
+// with the capability will be released.
+//
+// Release will panic if c has already been released, but not if c is
+// nil or resolved to null.
+func (c Thumbnailer) Release() {
+	capnp.Client(c).Release()
+}
+
+// Resolve blocks until the capability is fully resolved or the Context
+// expires.
+func (c Thumbnailer) Resolve(ctx context.Context) error {
+	return capnp.Client(c).Resolve(ctx)
+}
+
+func (c Thumbnailer) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Client(c).EncodeAsPtr(seg)
+}
+
+func (Thumbnailer) DecodeFromPtr(p capnp.Ptr) Thumbnailer {
+	return Thumbnailer(capnp.Client{}.DecodeFromPtr(p))
+}
+
+// IsValid reports whether c is a valid reference to a capability.
+// A reference is invalid if it is nil, has resolved to null, or has
+// been released.
+func (c Thumbnailer) IsValid() bool {
+	return capnp.Client(c).IsValid()
+}
+
+// IsSame reports whether c and other refer to a capability created by the
+// same call to NewClient.  This can return false negatives if c or other
+// are not fully resolved: use Resolve if this is an issue.  If either
+// c or other are released, then IsSame panics.
+func (c Thumbnailer) IsSame(other Thumbnailer) bool {
+	return capnp.Client(c).IsSame(capnp.Client(other))
+}
+
+// Update the flowcontrol.FlowLimiter used to manage flow control for
+// this client. This affects all future calls, but not calls already
+// waiting to send. Passing nil sets the value to flowcontrol.NopLimiter,
+// which is also the default.
+func (c Thumbnailer) SetFlowLimiter(lim fc.FlowLimiter) {
+	capnp.Client(c).SetFlowLimiter(lim)
+}
+
+// Get the current flowcontrol.FlowLimiter used to manage flow control
+// for this client.
+func (c Thumbnailer) GetFlowLimiter() fc.FlowLimiter {
+	return capnp.Client(c).GetFlowLimiter()
+}
+
+// A Thumbnailer_Server is a Thumbnailer with a local implementation.
+type Thumbnailer_Server interface {
+	Accepts(context.Context, Thumbnailer_accepts) error
+
+	Thumbnail(context.Context, Thumbnailer_thumbnail) error
+}
+
+// Thumbnailer_NewServer creates a new Server from an implementation of Thumbnailer_Server.
+func Thumbnailer_NewServer(s Thumbnailer_Server) *server.Server {
+	c, _ := s.(server.Shutdowner)
+	return server.New(Thumbnailer_Methods(nil, s), s, c)
+}
+
+// Thumbnailer_ServerToClient creates a new Client from an implementation of Thumbnailer_Server.
+// The caller is responsible for calling Release on the returned Client.
+func Thumbnailer_ServerToClient(s Thumbnailer_Server) Thumbnailer {
+	return Thumbnailer(capnp.NewClient(Thumbnailer_NewServer(s)))
+}
+
+// Thumbnailer_Methods appends Methods to a slice that invoke the methods on s.
+// This can be used to create a more complicated Server.
+func Thumbnailer_Methods(methods []server.Method, s Thumbnailer_Server) []server.Method {
+	if cap(methods) == 0 {
+		methods = make([]server.Method, 0, 2)
+	}
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0x83568095e1ad3c40,
+			MethodID:      0,
+			InterfaceName: "thumbnail.capnp:Thumbnailer",
+			MethodName:    "accepts",
+		},
+		Impl: func(ctx context.Context, call *server.Call) error {
+			return s.Accepts(ctx, Thumbnailer_accepts{call})
+		},
+	})
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0x83568095e1ad3c40,
+			MethodID:      1,
+			InterfaceName: "thumbnail.capnp:Thumbnailer",
+			MethodName:    "thumbnail",
+		},
+		Impl: func(ctx context.Context, call *server.Call) error {
+			return s.Thumbnail(ctx, Thumbnailer_thumbnail{call})
+		},
+	})
+
+	return methods
+}
+
+// Thumbnailer_accepts holds the state for a server call to Thumbnailer.accepts.
+// See server.Call for documentation.
+type Thumbnailer_accepts struct {
+	*server.Call
+}
+
+// Args returns the call's arguments.
+func (c Thumbnailer_accepts) Args() Thumbnailer_accepts_Params {
+	return Thumbnailer_accepts_Params(c.Call.Args())
+}
+
+// AllocResults allocates the results struct.
+func (c Thumbnailer_accepts) AllocResults() (Thumbnailer_accepts_Results, error) {
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 8, PointerCount: 0})
+	return Thumbnailer_accepts_Results(r), err
+}
+
+// Thumbnailer_thumbnail holds the state for a server call to Thumbnailer.thumbnail.
+// See server.Call for documentation.
+type Thumbnailer_thumbnail struct {
+	*server.Call
+}
+
+// Args returns the call's arguments.
+func (c Thumbnailer_thumbnail) Args() Thumbnailer_thumbnail_Params {
+	return Thumbnailer_thumbnail_Params(c.Call.Args())
+}
+
+// AllocResults allocates the results struct.
+func (c Thumbnailer_thumbnail) AllocResults() (Thumbnailer_thumbnail_Results, error) {
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return Thumbnailer_thumbnail_Results(r), err
+}
+
+// Thumbnailer_List is a list of Thumbnailer.
+type Thumbnailer_List = capnp.CapList[Thumbnailer]
+
+// NewThumbnailer_List creates a new list of Thumbnailer.
+func NewThumbnailer_List(s *capnp.Segment, sz int32) (Thumbnailer_List, error) {
+	l, err := capnp.NewPointerList(s, sz)
+	return capnp.CapList[Thumbnailer](l), err
+}
+
+type Thumbnailer_accepts_Params capnp.Struct
+
+// Thumbnailer_accepts_Params_TypeID is the unique identifier for the type Thumbnailer_accepts_Params.
+const Thumbnailer_accepts_Params_TypeID = 0xf23c8b500a686d69
+
+func NewThumbnailer_accepts_Params(s *capnp.Segment) (Thumbnailer_accepts_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return Thumbnailer_accepts_Params(st), err
+}
+
+func NewRootThumbnailer_accepts_Params(s *capnp.Segment) (Thumbnailer_accepts_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return Thumbnailer_accepts_Params(st), err
+}
+
+func ReadRootThumbnailer_accepts_Params(msg *capnp.Message) (Thumbnailer_accepts_Params, error) {
+	root, err := msg.Root()
+	return Thumbnailer_accepts_Params(root.Struct()), err
+}
+
+func (s Thumbnailer_accepts_Params) String() string {
+	str, _ := text.Marshal(0xf23c8b500a686d69, capnp.Struct(s))
+	return str
+}
+
+func (s Thumbnailer_accepts_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (Thumbnailer_accepts_Params) DecodeFromPtr(p capnp.Ptr) Thumbnailer_accepts_Params {
+	return Thumbnailer_accepts_Params(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s Thumbnailer_accepts_Params) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s Thumbnailer_accepts_Params) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s Thumbnailer_accepts_Params) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s Thumbnailer_accepts_Params) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s Thumbnailer_accepts_Params) MimeType() (string, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.Text(), err
+}
+
+func (s Thumbnailer_accepts_Params) HasMimeType() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s Thumbnailer_accepts_Params) MimeTypeBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s Thumbnailer_accepts_Params) SetMimeType(v string) error {
+	return capnp.Struct(s).SetText(0, v)
+}
+
+// Thumbnailer_accepts_Params_List is a list of Thumbnailer_accepts_Params.
+type Thumbnailer_accepts_Params_List = capnp.StructList[Thumbnailer_accepts_Params]
+
+// NewThumbnailer_accepts_Params creates a new list of Thumbnailer_accepts_Params.
+func NewThumbnailer_accepts_Params_List(s *capnp.Segment, sz int32) (Thumbnailer_accepts_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return capnp.StructList[Thumbnailer_accepts_Params](l), err
+}
+
+// Thumbnailer_accepts_Params_Future is a wrapper for a Thumbnailer_accepts_Params promised by a client call.
+type Thumbnailer_accepts_Params_Future struct{ *capnp.Future }
+
+func (f Thumbnailer_accepts_Params_Future) Struct() (Thumbnailer_accepts_Params, error) {
+	p, err := f.Future.Ptr()
+	return Thumbnailer_accepts_Params(p.Struct()), err
+}
+
+type Thumbnailer_accepts_Results capnp.Struct
+
+// Thumbnailer_accepts_Results_TypeID is the unique identifier for the type Thumbnailer_accepts_Results.
+const Thumbnailer_accepts_Results_TypeID = 0xd96e10f6fc9d42d2
+
+func NewThumbnailer_accepts_Results(s *capnp.Segment) (Thumbnailer_accepts_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 0})
+	return Thumbnailer_accepts_Results(st), err
+}
+
+func NewRootThumbnailer_accepts_Results(s *capnp.Segment) (Thumbnailer_accepts_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 0})
+	return Thumbnailer_accepts_Results(st), err
+}
+
+func ReadRootThumbnailer_accepts_Results(msg *capnp.Message) (Thumbnailer_accepts_Results, error) {
+	root, err := msg.Root()
+	return Thumbnailer_accepts_Results(root.Struct()), err
+}
+
+func (s Thumbnailer_accepts_Results) String() string {
+	str, _ := text.Marshal(0xd96e10f6fc9d42d2, capnp.Struct(s))
+	return str
+}
+
+func (s Thumbnailer_accepts_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (Thumbnailer_accepts_Results) DecodeFromPtr(p capnp.Ptr) Thumbnailer_accepts_Results {
+	return Thumbnailer_accepts_Results(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s Thumbnailer_accepts_Results) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s Thumbnailer_accepts_Results) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s Thumbnailer_accepts_Results) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s Thumbnailer_accepts_Results) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s Thumbnailer_accepts_Results) Accepted() bool {
+	return capnp.Struct(s).Bit(0)
+}
+
+func (s Thumbnailer_accepts_Results) SetAccepted(v bool) {
+	capnp.Struct(s).SetBit(0, v)
+}
+
+// Thumbnailer_accepts_Results_List is a list of Thumbnailer_accepts_Results.
+type Thumbnailer_accepts_Results_List = capnp.StructList[Thumbnailer_accepts_Results]
+
+// NewThumbnailer_accepts_Results creates a new list of Thumbnailer_accepts_Results.
+func NewThumbnailer_accepts_Results_List(s *capnp.Segment, sz int32) (Thumbnailer_accepts_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 0}, sz)
+	return capnp.StructList[Thumbnailer_accepts_Results](l), err
+}
+
+// Thumbnailer_accepts_Results_Future is a wrapper for a Thumbnailer_accepts_Results promised by a client call.
+type Thumbnailer_accepts_Results_Future struct{ *capnp.Future }
+
+func (f Thumbnailer_accepts_Results_Future) Struct() (Thumbnailer_accepts_Results, error) {
+	p, err := f.Future.Ptr()
+	return Thumbnailer_accepts_Results(p.Struct()), err
+}
+
+type Thumbnailer_thumbnail_Params capnp.Struct
+
+// Thumbnailer_thumbnail_Params_TypeID is the unique identifier for the type Thumbnailer_thumbnail_Params.
+const Thumbnailer_thumbnail_Params_TypeID = 0xb9abdb9df18a2da7
+
+func NewThumbnailer_thumbnail_Params(s *capnp.Segment) (Thumbnailer_thumbnail_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 2})
+	return Thumbnailer_thumbnail_Params(st), err
+}
+
+func NewRootThumbnailer_thumbnail_Params(s *capnp.Segment) (Thumbnailer_thumbnail_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 2})
+	return Thumbnailer_thumbnail_Params(st), err
+}
+
+func ReadRootThumbnailer_thumbnail_Params(msg *capnp.Message) (Thumbnailer_thumbnail_Params, error) {
+	root, err := msg.Root()
+	return Thumbnailer_thumbnail_Params(root.Struct()), err
+}
+
+func (s Thumbnailer_thumbnail_Params) String() string {
+	str, _ := text.Marshal(0xb9abdb9df18a2da7, capnp.Struct(s))
+	return str
+}
+
+func (s Thumbnailer_thumbnail_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (Thumbnailer_thumbnail_Params) DecodeFromPtr(p capnp.Ptr) Thumbnailer_thumbnail_Params {
+	return Thumbnailer_thumbnail_Params(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s Thumbnailer_thumbnail_Params) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s Thumbnailer_thumbnail_Params) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s Thumbnailer_thumbnail_Params) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s Thumbnailer_thumbnail_Params) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s Thumbnailer_thumbnail_Params) MimeType() (string, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.Text(), err
+}
+
+func (s Thumbnailer_thumbnail_Params) HasMimeType() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s Thumbnailer_thumbnail_Params) MimeTypeBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s Thumbnailer_thumbnail_Params) SetMimeType(v string) error {
+	return capnp.Struct(s).SetText(0, v)
+}
+
+func (s Thumbnailer_thumbnail_Params) Content() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return []byte(p.Data()), err
+}
+
+func (s Thumbnailer_thumbnail_Params) HasContent() bool {
+	return capnp.Struct(s).HasPtr(1)
+}
+
+func (s Thumbnailer_thumbnail_Params) SetContent(v []byte) error {
+	return capnp.Struct(s).SetData(1, v)
+}
+
+func (s Thumbnailer_thumbnail_Params) Size() uint32 {
+	return capnp.Struct(s).Uint32(0)
+}
+
+func (s Thumbnailer_thumbnail_Params) SetSize(v uint32) {
+	capnp.Struct(s).SetUint32(0, v)
+}
+
+// Thumbnailer_thumbnail_Params_List is a list of Thumbnailer_thumbnail_Params.
+type Thumbnailer_thumbnail_Params_List = capnp.StructList[Thumbnailer_thumbnail_Params]
+
+// NewThumbnailer_thumbnail_Params creates a new list of Thumbnailer_thumbnail_Params.
+func NewThumbnailer_thumbnail_Params_List(s *capnp.Segment, sz int32) (Thumbnailer_thumbnail_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 2}, sz)
+	return capnp.StructList[Thumbnailer_thumbnail_Params](l), err
+}
+
+// Thumbnailer_thumbnail_Params_Future is a wrapper for a Thumbnailer_thumbnail_Params promised by a client call.
+type Thumbnailer_thumbnail_Params_Future struct{ *capnp.Future }
+
+func (f Thumbnailer_thumbnail_Params_Future) Struct() (Thumbnailer_thumbnail_Params, error) {
+	p, err := f.Future.Ptr()
+	return Thumbnailer_thumbnail_Params(p.Struct()), err
+}
+
+type Thumbnailer_thumbnail_Results capnp.Struct
+
+// Thumbnailer_thumbnail_Results_TypeID is the unique identifier for the type Thumbnailer_thumbnail_Results.
+const Thumbnailer_thumbnail_Results_TypeID = 0xd424363319981217
+
+func NewThumbnailer_thumbnail_Results(s *capnp.Segment) (Thumbnailer_thumbnail_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return Thumbnailer_thumbnail_Results(st), err
+}
+
+func NewRootThumbnailer_thumbnail_Results(s *capnp.Segment) (Thumbnailer_thumbnail_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return Thumbnailer_thumbnail_Results(st), err
+}
+
+func ReadRootThumbnailer_thumbnail_Results(msg *capnp.Message) (Thumbnailer_thumbnail_Results, error) {
+	root, err := msg.Root()
+	return Thumbnailer_thumbnail_Results(root.Struct()), err
+}
+
+func (s Thumbnailer_thumbnail_Results) String() string {
+	str, _ := text.Marshal(0xd424363319981217, capnp.Struct(s))
+	return str
+}
+
+func (s Thumbnailer_thumbnail_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (Thumbnailer_thumbnail_Results) DecodeFromPtr(p capnp.Ptr) Thumbnailer_thumbnail_Results {
+	return Thumbnailer_thumbnail_Results(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s Thumbnailer_thumbnail_Results) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s Thumbnailer_thumbnail_Results) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s Thumbnailer_thumbnail_Results) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s Thumbnailer_thumbnail_Results) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s Thumbnailer_thumbnail_Results) Png() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return []byte(p.Data()), err
+}
+
+func (s Thumbnailer_thumbnail_Results) HasPng() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s Thumbnailer_thumbnail_Results) SetPng(v []byte) error {
+	return capnp.Struct(s).SetData(0, v)
+}
+
+// Thumbnailer_thumbnail_Results_List is a list of Thumbnailer_thumbnail_Results.
+type Thumbnailer_thumbnail_Results_List = capnp.StructList[Thumbnailer_thumbnail_Results]
+
+// NewThumbnailer_thumbnail_Results creates a new list of Thumbnailer_thumbnail_Results.
+func NewThumbnailer_thumbnail_Results_List(s *capnp.Segment, sz int32) (Thumbnailer_thumbnail_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return capnp.StructList[Thumbnailer_thumbnail_Results](l), err
+}
+
+// Thumbnailer_thumbnail_Results_Future is a wrapper for a Thumbnailer_thumbnail_Results promised by a client call.
+type Thumbnailer_thumbnail_Results_Future struct{ *capnp.Future }
+
+func (f Thumbnailer_thumbnail_Results_Future) Struct() (Thumbnailer_thumbnail_Results, error) {
+	p, err := f.Future.Ptr()
+	return Thumbnailer_thumbnail_Results(p.Struct()), err
+}
+
+const schema_95a1419c502e713a = "x\xda\x94\x90\xb1k\xd4`\x18\xc6\x9f\xe7\xfbr&\xca" +
+	"\x1dm\xbc\x93*\x14\x04\xb9I\xf0\xb0\x14\x1cJ\xa1\xf5" +
+	"P\x10Q\xc8w\x1e\"r\x11\xd2\xf3\xc3\x9e$1&" +
+	"\xb9A\x97\x0a\x0e\x82\xce\x05\x05;8H\x07\x11\xe7\x1b" +
+	"\x1c\xdd\\\x14\x17q\xd2\xff\xa0\x82N\xea'\xd1\xbb " +
+	"E\xa5%S\xde\xef\xc7\xef}\x9e\xf7\xf8\xeb\x99ek" +
+	"\xae\xb69\x82\xb8\x908\x95=fy\xf1\xf9\xc7\xf5;" +
+	"\x17\xef\xc2\x9d\x96f\xe1f\xcb{|\xf2\xc9:\xc0\xfa" +
+	"\xa8\xf7\x09\xac\xbf\xec\xdd\xab\xef\xf5m\xc0l\x1e\xbb\xbf" +
+	"\xb5\xf1\xe1\xd9\x08j\x96\x04*\xc2\x06\xe6\xb7z\xd7\x09" +
+	"\xd6\xbf\xf7^\x80ff\xff\xc3C\xf3'\x9a\xef\xe0\xce" +
+	"\x16\x00\x0b\xe0\x91\x9f\x16\xc0S\x7f\x094o\xdb\x1b\xdf" +
+	"\xbeN\xc7\xef\xc7\x0a\xab\x00^\xf9+\x05\xf0\xe6\x170" +
+	"\x88V\xf7y\x0f\x16?\xffi\xf8\xe2_.\x00^Y" +
+	"\x82\xd9\xc5\xf7\xc3\xe4\xab\xc3h%\x0e\x06\"l\xf5\x83" +
+	"$N\x16\xba\xe3\x81\x1d\xeaT9\xb2\x02\x94\x0b9\x89" +
+	"\xe6\xce\xb5!\xc9\xb2-'\xa5\xdc\x03\x1d\xc8\xb5\xa0\xdf" +
+	"\xd7I\x9e\x95n0\xf4\xc8\xf2\xd7\xda\xbe*\xd4ik" +
+	"\xf2\x186\xbd`*\x0d\xa2LU\xa5\x05X\x04\xdc\xd3" +
+	"g\x01uJRy\x82.\xd9`1<\xdf\x06\xd4\x19" +
+	"I\xd5\x15\xa4hP\x00\xae:\x0a\xa8s\x92\xea\x92\xa0" +
+	"\x89\x06\x91\xee\xdeJ4\x00V!X\x05\xd7\xfa7\xe2" +
+	"\\\xc79k\x10\xac\x81S\xd9\xe0\xb6\xa6\x03A\x07;" +
+	"\xce\xd7\xd1\x87\xb3a\x98g\xca*\x03\xd6\x8e\x00\xca\x91" +
+	"T\x0dA;\x89\xafM\xfc\xffW\x8e\xcf\xd4\xec\xe8l" +
+	"ho\x13\x16\x8d\xab\x92\xea\xa0\xa0\xf9\xcd\xe9\xab\x00H" +
+	"\x08r\xa7b/H\x03\x19\xfd\xd3\xfb\x97\x03\xfd\x1c\x00" +
+	"\x8d\x94\xd8\xab"
+
+func RegisterSchema(reg *schemas.Registry) {
+	reg.Register(&schemas.Schema{
+		String: schema_95a1419c502e713a,
+		Nodes: []uint64{
+			0x83568095e1ad3c40,
+			0xb9abdb9df18a2da7,
+			0xd424363319981217,
+			0xd96e10f6fc9d42d2,
+			0xf23c8b500a686d69,
+		},
+		Compressed: true,
+	})
+}
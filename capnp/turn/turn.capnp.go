@@ -0,0 +1,404 @@
+// Code generated by capnpc-go. DO NOT EDIT.
+
+package turn
+
+import (
+	capnp "capnproto.org/go/capnp/v3"
+	text "capnproto.org/go/capnp/v3/encoding/text"
+	fc "capnproto.org/go/capnp/v3/flowcontrol"
+	schemas "capnproto.org/go/capnp/v3/schemas"
+	server "capnproto.org/go/capnp/v3/server"
+	context "context"
+)
+
+type TurnServer capnp.Client
+
+// TurnServer_TypeID is the unique identifier for the type TurnServer.
+const TurnServer_TypeID = 0xf370fc76bc2a5d3d
+
+func (c TurnServer) Credentials(ctx context.Context, params func(TurnServer_credentials_Params) error) (TurnServer_credentials_Results_Future, capnp.ReleaseFunc) {
+
+	s := capnp.Send{
+		Method: capnp.Method{
+			InterfaceID:   0xf370fc76bc2a5d3d,
+			MethodID:      0,
+			InterfaceName: "turn.capnp:TurnServer",
+			MethodName:    "credentials",
+		},
+	}
+	if params != nil {
+		s.ArgsSize = capnp.ObjectSize{DataSize: 0, PointerCount: 0}
+		s.PlaceArgs = func(s capnp.Struct) error { return params(TurnServer_credentials_Params(s)) }
+	}
+
+	ans, release := capnp.Client(c).SendCall(ctx, s)
+	return TurnServer_credentials_Results_Future{Future: ans.Future()}, release
+
+}
+
+func (c TurnServer) WaitStreaming() error {
+	return capnp.Client(c).WaitStreaming()
+}
+
+// String returns a string that identifies this capability for debugging
+// purposes.  Its format should not be depended on: in particular, it
+// should not be used to compare clients.  Use IsSame to compare clients
+// for equality.
+func (c TurnServer) String() string {
+	return "TurnServer(" + capnp.Client(c).String() + ")"
+}
+
+// AddRef creates a new Client that refers to the same capability as c.
+// If c is nil or has resolved to null, then AddRef returns nil.
+func (c TurnServer) AddRef() TurnServer {
+	return TurnServer(capnp.Client(c).AddRef())
+}
+
+// Release releases a capability reference.  If this is the last
+// reference to the capability, then the underlying resources associated
+// with the capability will be released.
+//
+// Release will panic if c has already been released, but not if c is
+// nil or resolved to null.
+func (c TurnServer) Release() {
+	capnp.Client(c).Release()
+}
+
+// Resolve blocks until the capability is fully resolved or the Context
+// expires.
+func (c TurnServer) Resolve(ctx context.Context) error {
+	return capnp.Client(c).Resolve(ctx)
+}
+
+func (c TurnServer) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Client(c).EncodeAsPtr(seg)
+}
+
+func (TurnServer) DecodeFromPtr(p capnp.Ptr) TurnServer {
+	return TurnServer(capnp.Client{}.DecodeFromPtr(p))
+}
+
+// IsValid reports whether c is a valid reference to a capability.
+// A reference is invalid if it is nil, has resolved to null, or has
+// been released.
+func (c TurnServer) IsValid() bool {
+	return capnp.Client(c).IsValid()
+}
+
+// IsSame reports whether c and other refer to a capability created by the
+// same call to NewClient.  This can return false negatives if c or other
+// are not fully resolved: use Resolve if this is an issue.  If either
+// c or other are released, then IsSame panics.
+func (c TurnServer) IsSame(other TurnServer) bool {
+	return capnp.Client(c).IsSame(capnp.Client(other))
+}
+
+// Update the flowcontrol.FlowLimiter used to manage flow control for
+// this client. This affects all future calls, but not calls already
+// waiting to send. Passing nil sets the value to flowcontrol.NopLimiter,
+// which is also the default.
+func (c TurnServer) SetFlowLimiter(lim fc.FlowLimiter) {
+	capnp.Client(c).SetFlowLimiter(lim)
+}
+
+// Get the current flowcontrol.FlowLimiter used to manage flow control
+// for this client.
+func (c TurnServer) GetFlowLimiter() fc.FlowLimiter {
+	return capnp.Client(c).GetFlowLimiter()
+}
+
+// A TurnServer_Server is a TurnServer with a local implementation.
+type TurnServer_Server interface {
+	Credentials(context.Context, TurnServer_credentials) error
+}
+
+// TurnServer_NewServer creates a new Server from an implementation of TurnServer_Server.
+func TurnServer_NewServer(s TurnServer_Server) *server.Server {
+	c, _ := s.(server.Shutdowner)
+	return server.New(TurnServer_Methods(nil, s), s, c)
+}
+
+// TurnServer_ServerToClient creates a new Client from an implementation of TurnServer_Server.
+// The caller is responsible for calling Release on the returned Client.
+func TurnServer_ServerToClient(s TurnServer_Server) TurnServer {
+	return TurnServer(capnp.NewClient(TurnServer_NewServer(s)))
+}
+
+// TurnServer_Methods appends Methods to a slice that invoke the methods on s.
+// This can be used to create a more complicated Server.
+func TurnServer_Methods(methods []server.Method, s TurnServer_Server) []server.Method {
+	if cap(methods) == 0 {
+		methods = make([]server.Method, 0, 1)
+	}
+
+	methods = append(methods, server.Method{
+		Method: capnp.Method{
+			InterfaceID:   0xf370fc76bc2a5d3d,
+			MethodID:      0,
+			InterfaceName: "turn.capnp:TurnServer",
+			MethodName:    "credentials",
+		},
+		Impl: func(ctx context.Context, call *server.Call) error {
+			return s.Credentials(ctx, TurnServer_credentials{call})
+		},
+	})
+
+	return methods
+}
+
+// TurnServer_credentials holds the state for a server call to TurnServer.credentials.
+// See server.Call for documentation.
+type TurnServer_credentials struct {
+	*server.Call
+}
+
+// Args returns the call's arguments.
+func (c TurnServer_credentials) Args() TurnServer_credentials_Params {
+	return TurnServer_credentials_Params(c.Call.Args())
+}
+
+// AllocResults allocates the results struct.
+func (c TurnServer_credentials) AllocResults() (TurnServer_credentials_Results, error) {
+	r, err := c.Call.AllocResults(capnp.ObjectSize{DataSize: 8, PointerCount: 3})
+	return TurnServer_credentials_Results(r), err
+}
+
+// TurnServer_List is a list of TurnServer.
+type TurnServer_List = capnp.CapList[TurnServer]
+
+// NewTurnServer_List creates a new list of TurnServer.
+func NewTurnServer_List(s *capnp.Segment, sz int32) (TurnServer_List, error) {
+	l, err := capnp.NewPointerList(s, sz)
+	return capnp.CapList[TurnServer](l), err
+}
+
+type TurnServer_credentials_Params capnp.Struct
+
+// TurnServer_credentials_Params_TypeID is the unique identifier for the type TurnServer_credentials_Params.
+const TurnServer_credentials_Params_TypeID = 0xca79c6857959d5b2
+
+func NewTurnServer_credentials_Params(s *capnp.Segment) (TurnServer_credentials_Params, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
+	return TurnServer_credentials_Params(st), err
+}
+
+func NewRootTurnServer_credentials_Params(s *capnp.Segment) (TurnServer_credentials_Params, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0})
+	return TurnServer_credentials_Params(st), err
+}
+
+func ReadRootTurnServer_credentials_Params(msg *capnp.Message) (TurnServer_credentials_Params, error) {
+	root, err := msg.Root()
+	return TurnServer_credentials_Params(root.Struct()), err
+}
+
+func (s TurnServer_credentials_Params) String() string {
+	str, _ := text.Marshal(0xca79c6857959d5b2, capnp.Struct(s))
+	return str
+}
+
+func (s TurnServer_credentials_Params) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (TurnServer_credentials_Params) DecodeFromPtr(p capnp.Ptr) TurnServer_credentials_Params {
+	return TurnServer_credentials_Params(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s TurnServer_credentials_Params) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s TurnServer_credentials_Params) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s TurnServer_credentials_Params) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s TurnServer_credentials_Params) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+
+// TurnServer_credentials_Params_List is a list of TurnServer_credentials_Params.
+type TurnServer_credentials_Params_List = capnp.StructList[TurnServer_credentials_Params]
+
+// NewTurnServer_credentials_Params creates a new list of TurnServer_credentials_Params.
+func NewTurnServer_credentials_Params_List(s *capnp.Segment, sz int32) (TurnServer_credentials_Params_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 0}, sz)
+	return capnp.StructList[TurnServer_credentials_Params](l), err
+}
+
+// TurnServer_credentials_Params_Future is a wrapper for a TurnServer_credentials_Params promised by a client call.
+type TurnServer_credentials_Params_Future struct{ *capnp.Future }
+
+func (f TurnServer_credentials_Params_Future) Struct() (TurnServer_credentials_Params, error) {
+	p, err := f.Future.Ptr()
+	return TurnServer_credentials_Params(p.Struct()), err
+}
+
+type TurnServer_credentials_Results capnp.Struct
+
+// TurnServer_credentials_Results_TypeID is the unique identifier for the type TurnServer_credentials_Results.
+const TurnServer_credentials_Results_TypeID = 0xa8076225287a3a49
+
+func NewTurnServer_credentials_Results(s *capnp.Segment) (TurnServer_credentials_Results, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 3})
+	return TurnServer_credentials_Results(st), err
+}
+
+func NewRootTurnServer_credentials_Results(s *capnp.Segment) (TurnServer_credentials_Results, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 3})
+	return TurnServer_credentials_Results(st), err
+}
+
+func ReadRootTurnServer_credentials_Results(msg *capnp.Message) (TurnServer_credentials_Results, error) {
+	root, err := msg.Root()
+	return TurnServer_credentials_Results(root.Struct()), err
+}
+
+func (s TurnServer_credentials_Results) String() string {
+	str, _ := text.Marshal(0xa8076225287a3a49, capnp.Struct(s))
+	return str
+}
+
+func (s TurnServer_credentials_Results) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (TurnServer_credentials_Results) DecodeFromPtr(p capnp.Ptr) TurnServer_credentials_Results {
+	return TurnServer_credentials_Results(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s TurnServer_credentials_Results) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s TurnServer_credentials_Results) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s TurnServer_credentials_Results) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s TurnServer_credentials_Results) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s TurnServer_credentials_Results) Urls() (capnp.TextList, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return capnp.TextList(p.List()), err
+}
+
+func (s TurnServer_credentials_Results) HasUrls() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s TurnServer_credentials_Results) SetUrls(v capnp.TextList) error {
+	return capnp.Struct(s).SetPtr(0, v.ToPtr())
+}
+
+// NewUrls sets the urls field to a newly
+// allocated capnp.TextList, preferring placement in s's segment.
+func (s TurnServer_credentials_Results) NewUrls(n int32) (capnp.TextList, error) {
+	l, err := capnp.NewTextList(capnp.Struct(s).Segment(), n)
+	if err != nil {
+		return capnp.TextList{}, err
+	}
+	err = capnp.Struct(s).SetPtr(0, l.ToPtr())
+	return l, err
+}
+func (s TurnServer_credentials_Results) Username() (string, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return p.Text(), err
+}
+
+func (s TurnServer_credentials_Results) HasUsername() bool {
+	return capnp.Struct(s).HasPtr(1)
+}
+
+func (s TurnServer_credentials_Results) UsernameBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return p.TextBytes(), err
+}
+
+func (s TurnServer_credentials_Results) SetUsername(v string) error {
+	return capnp.Struct(s).SetText(1, v)
+}
+
+func (s TurnServer_credentials_Results) Credential() (string, error) {
+	p, err := capnp.Struct(s).Ptr(2)
+	return p.Text(), err
+}
+
+func (s TurnServer_credentials_Results) HasCredential() bool {
+	return capnp.Struct(s).HasPtr(2)
+}
+
+func (s TurnServer_credentials_Results) CredentialBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(2)
+	return p.TextBytes(), err
+}
+
+func (s TurnServer_credentials_Results) SetCredential(v string) error {
+	return capnp.Struct(s).SetText(2, v)
+}
+
+func (s TurnServer_credentials_Results) Expires() int64 {
+	return int64(capnp.Struct(s).Uint64(0))
+}
+
+func (s TurnServer_credentials_Results) SetExpires(v int64) {
+	capnp.Struct(s).SetUint64(0, uint64(v))
+}
+
+// TurnServer_credentials_Results_List is a list of TurnServer_credentials_Results.
+type TurnServer_credentials_Results_List = capnp.StructList[TurnServer_credentials_Results]
+
+// NewTurnServer_credentials_Results creates a new list of TurnServer_credentials_Results.
+func NewTurnServer_credentials_Results_List(s *capnp.Segment, sz int32) (TurnServer_credentials_Results_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 3}, sz)
+	return capnp.StructList[TurnServer_credentials_Results](l), err
+}
+
+// TurnServer_credentials_Results_Future is a wrapper for a TurnServer_credentials_Results promised by a client call.
+type TurnServer_credentials_Results_Future struct{ *capnp.Future }
+
+func (f TurnServer_credentials_Results_Future) Struct() (TurnServer_credentials_Results, error) {
+	p, err := f.Future.Ptr()
+	return TurnServer_credentials_Results(p.Struct()), err
+}
+
+const schema_ad59ac5d4c7a4c47 = "x\xda\x94\x91?\xab\x13A\x14\xc5\xcf\x99\xd9u\x05\x9f" +
+	"\x7f\x86\x84gi\xa3 \xafx(v\x0fD\xb1y\xf8" +
+	"H\xb1\x93\xd8D\xdc\x85I\x9c\"\xb0Y\x96\x99\xdd\x90" +
+	"\x04\xc54\x01\x0b?\x88\x856i\xb5\xd5\xc2\xde\xcf\xe0" +
+	"\x07\xb0\x15F6\xb0\xb1\xf5q\xe1r9\xe7W\x9c\xcb" +
+	"y\xf0\xed\xf6\xd3\xe8\xe1\xf5\xe4\x0b\xc4hs5\xbe\x12" +
+	"\x9e\x9f\xad\xef\xdf\x9b$\x1f\xa1\x8fI \x96\x09\xf0h" +
+	"\x96\x0d\x09\xf6\x9a\xec\x17\x18v?\xc7\xab\xed\xf7\xd5\x0f" +
+	"\xa8c\x02Q\xeb\x9b\xfc\xa2\xf5\xe7y\x02\x86\xc7\xd9\xc9" +
+	"\xd7\xc5\x9f\xea7\xd45\x19\xce\x07\xebA\xf6i\xfc\x19" +
+	"`O\xe7\xbb\xfd>\xef\xad\xf2\x04\xe1R\xf3$\xd4\x8d" +
+	"+O\xa7\xa6\x8a\xca\xea\xecE\xe3\xca\x91u\x0b\xebN" +
+	"\xa7\xce\xbe\xb6e=3\x85\xbf;\xb4\xbe)jz}" +
+	"KF@D@\x99\x13@\xbf\x92\xd4KAE\xf6\xd9" +
+	"\x8a\xcd\x05\xa0kI\xbd\x11TB\xf4)\x00\xf5\xf6%" +
+	"\xa0\xdfH\xea\xf7\x82\x94}J@m\x9f\x01z#\xa9" +
+	"?\x08\xdel\\\xe1y\x03L%y\x04\xd1\x9e\xa1\xf1" +
+	"\xd6\x95fn\x01\xec\xb5#0t\x89 M\xd1\x89\xef" +
+	"\xec\xb2\x9a9\xeb\x19C0\x06\xff\xe7\x9b\xd483\xf7" +
+	"@*\xa3\x03\xce\x0e\xbf\xb3\xe7u$c\xe0\xd0\x08\xbb" +
+	"\xf2\x94\x9a@\xfe\x0b\x92\x98\xc2\xa7\xe4\xdf\x01\x00\x02\x01" +
+	"\x8b\xbe"
+
+func RegisterSchema(reg *schemas.Registry) {
+	reg.Register(&schemas.Schema{
+		String: schema_ad59ac5d4c7a4c47,
+		Nodes: []uint64{
+			0xa8076225287a3a49,
+			0xca79c6857959d5b2,
+			0xf370fc76bc2a5d3d,
+		},
+		Compressed: true,
+	})
+}
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/alecthomas/kong"
 	buildtool "sandstorm.org/go/tempest/internal/build-tool"
@@ -13,74 +16,116 @@ const DefaultConfigPath = "./config.toml"
 const DefaultDownloadsFilePath = "./internal/build-tool/downloads.toml"
 
 var CLI struct {
-	BootstrapBinaryen  struct{} `cmd:"" help:"Bootstrap Binaryen (wasm-opt)"`
-	BootstrapBison     struct{} `cmd:"" help:"Bootstrap Bison"`
-	BootstrapBpfAsm    struct{} `cmd:"" help:"Bootstrap bpf_asm" name:"bootstrap-bpf_asm"`
-	BootstrapCapnProto struct{} `cmd:"" help:"Bootstrap Cap'n Proto" name:"bootstrap-capnproto"`
-	BootstrapFlex      struct{} `cmd:"" help:"Bootstrap Flex"`
-	BootstrapGoCapnp   struct{} `cmd:"" help:"Bootstrap go-capnp"`
-	BootstrapTinygo    struct{} `cmd:"" help:"Bootstrap TinyGo"`
-
-	GenerateCapnp struct{} `cmd:"" help:"Generate Go files from Cap'n Proto files"`
-
-	Config        string `default:"./config.toml" help:"path to the config file"`
-	DownloadsFile string `default:"./internal/build-tool/downloads.toml" help:"path to the downloads information file"`
-	Verbose       bool   `help:"verbose output"`
+	AssembleBpf struct {
+		Input  string `arg:"" help:"preprocessed BPF assembly"`
+		Output string `arg:"" help:"C header to write, as with bpf_asm -c"`
+	} `cmd:"" help:"Assemble a seccomp filter without bpf_asm"`
+
+	Bootstrap struct {
+		Tool string `arg:"" enum:"${bootstrap_tools}" help:"tool to bootstrap: ${enum}"`
+	} `cmd:"" help:"Download and build a tool into the toolchain directory"`
+
+	Gc struct {
+		DryRun bool `help:"list the directories that would be removed, without removing them"`
+		Keep   int  `default:"0" help:"also keep this many of the newest unused versions of each tool"`
+	} `cmd:"" help:"Remove toolchain versions not referenced by toolchain.toml"`
+
+	Generate struct {
+		Force bool `help:"run generators even if their inputs have not changed"`
+
+		All   struct{} `cmd:"" help:"Run every generator enabled in config.toml"`
+		Bpf   struct{} `cmd:"" help:"Assemble the sandbox launcher's seccomp filter"`
+		Capnp struct {
+			Check bool `help:"check that the generated Go files match the Cap'n Proto files, without changing them"`
+		} `cmd:"" help:"Generate Go files from Cap'n Proto files, if they have changed"`
+		Config struct{} `cmd:"" help:"Generate Go and C files from config.json"`
+		Web    struct{} `cmd:"" help:"Compile the web UI to WebAssembly and hash its static assets"`
+	} `cmd:"" help:"Generate code"`
+
+	GenerateCapnp struct {
+		Watch bool `help:"watch the Cap'n Proto directories and regenerate files as they change"`
+	} `cmd:"" help:"Generate Go files from Cap'n Proto files"`
+
+	PublishCapnpModule struct {
+		Version string `help:"commit the module in its git repository and tag it with this version, e.g., v0.1.0"`
+	} `cmd:"" help:"Regenerate the Cap'n Proto schemas and assemble them into a standalone Go module"`
+
+	UpdateDownloads struct {
+		Tool    string `arg:"" help:"tool released on GitHub: binaryen, go-capnp or tinygo"`
+		Prefer  bool   `help:"also make the release the tool's PreferredVersion"`
+		Version string `help:"version to add, rather than the latest release"`
+	} `cmd:"" help:"Add the files of a GitHub release of a tool to downloads.toml"`
+
+	ValidateDownloads struct{} `cmd:"" help:"Check that downloads.toml has a file for every version and platform"`
+
+	Config               string `default:"./config.toml" help:"path to the config file"`
+	DownloadsFile        string `default:"./internal/build-tool/downloads.toml" help:"path to the downloads information file"`
+	Json                 bool   `help:"print the bootstrap statistics as JSON on standard output"`
+	PreferSystem         bool   `env:"TEMPEST_PREFER_SYSTEM_TOOLS" help:"use capnp, bison, flex and tinygo from PATH if they are at least the MinimumVersion in the config file, rather than download them"`
+	Profile              string `env:"TEMPEST_TOOLCHAIN_PROFILE" help:"toolchain profile from [build-tool.profiles] in the config file"`
+	RedownloadOnMismatch bool   `help:"quarantine downloads which don't match downloads.toml and download them again, from a mirror if there is one, without asking"`
+	Verbose              bool   `help:"verbose output"`
+	Wait                 bool   `default:"true" negatable:"" help:"wait for other build-tools using the same toolchain directories, rather than fail"`
 }
 
 func main() {
-	context := kong.Parse(&CLI)
+	if len(os.Args) > 1 && os.Args[1] == buildtool.SandboxInitCommand {
+		buildtool.SandboxInit(os.Args[2:])
+	}
+	context := kong.Parse(&CLI, kong.Vars{
+		"bootstrap_tools": strings.Join(buildtool.BootstrapperCommands(), ","),
+	})
 
-	config, err := loadConfiguration(&CLI.Config, &CLI.DownloadsFile)
+	config, downloadsFile, err := loadConfiguration(&CLI.Config, &CLI.DownloadsFile, CLI.Profile)
 	if err != nil {
 		log.Fatal(err)
 	}
+	config.PreferSystem = CLI.PreferSystem
+	config.RedownloadOnMismatch = CLI.RedownloadOnMismatch
+	config.WaitForLocks = CLI.Wait
+	config.Stats = new(buildtool.BootstrapStats)
 
 	switch context.Command() {
-	case "bootstrap-binaryen":
-		messages, err := buildtool.BootstrapBinaryen(config)
+	case "assemble-bpf <input> <output>":
+		messages, err := generate.AssembleBpf(CLI.AssembleBpf.Input, CLI.AssembleBpf.Output)
 		logMessages(CLI.Verbose, messages)
 		if err != nil {
 			log.Fatal(err)
 		}
-	case "bootstrap-bison":
-		messages, err := buildtool.BootstrapBison(config)
+	case "bootstrap <tool>":
+		messages, err := buildtool.Bootstrap(config, CLI.Bootstrap.Tool)
 		logMessages(CLI.Verbose, messages)
 		if err != nil {
 			log.Fatal(err)
 		}
-		break
-	case "bootstrap-bpf_asm":
-		messages, err := buildtool.BootstrapBpfAsm(config)
-		logMessages(CLI.Verbose, messages)
+	case "gc":
+		messages, err := buildtool.CollectGarbage(config, CLI.Gc.Keep, CLI.Gc.DryRun)
+		logMessages(true, messages)
 		if err != nil {
 			log.Fatal(err)
 		}
-		break
-	case "bootstrap-capnproto":
-		messages, err := buildtool.BootstrapCapnProto(config)
-		logMessages(CLI.Verbose, messages)
+	case "generate all":
+		messages, err := generate.GenerateAll(config, CLI.Generate.Force)
+		logMessages(true, messages)
 		if err != nil {
 			log.Fatal(err)
 		}
-		break
-	case "bootstrap-flex":
-		messages, err := buildtool.BootstrapFlex(config)
-		logMessages(CLI.Verbose, messages)
-		if err != nil {
-			log.Fatal(err)
+	case "generate capnp":
+		var messages []string
+		var err error
+		if CLI.Generate.Capnp.Check {
+			messages, err = generate.CheckCapnp(config)
+		} else {
+			messages, err = generate.Generate(config, []string{"capnp"}, CLI.Generate.Force)
 		}
-		break
-	case "bootstrap-go-capnp":
-		messages, err := buildtool.BootstrapGoCapnp(config)
-		logMessages(CLI.Verbose, messages)
+		logMessages(true, messages)
 		if err != nil {
 			log.Fatal(err)
 		}
-		break
-	case "bootstrap-tinygo":
-		messages, err := buildtool.BootstrapTinyGo(config)
-		logMessages(CLI.Verbose, messages)
+	case "generate bpf", "generate config", "generate web":
+		name := strings.TrimPrefix(context.Command(), "generate ")
+		messages, err := generate.Generate(config, []string{name}, CLI.Generate.Force)
+		logMessages(true, messages)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -90,15 +135,64 @@ func main() {
 		if err != nil {
 			log.Fatal(err)
 		}
+		if CLI.GenerateCapnp.Watch {
+			messages, err = generate.WatchCapnp(config)
+			logMessages(CLI.Verbose, messages)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+	case "publish-capnp-module":
+		messages, err := generate.PublishCapnpModule(config, CLI.PublishCapnpModule.Version)
+		logMessages(true, messages)
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "update-downloads <tool>":
+		messages, err := buildtool.UpdateDownloads(config, downloadsFile, CLI.DownloadsFile, CLI.UpdateDownloads.Tool, CLI.UpdateDownloads.Version, CLI.UpdateDownloads.Prefer)
+		logMessages(true, messages)
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "validate-downloads":
+		messages, err := buildtool.ValidateDownloads(downloadsFile)
+		logMessages(true, messages)
+		if err != nil {
+			log.Fatal(err)
+		}
 	}
+
+	reportStats(config.Stats, CLI.Json)
 }
 
-func loadConfiguration(configFileFlag *string, downloadsFileFlag *string) (*buildtool.RuntimeConfigBuildTool, error) {
+// reportStats logs a table of how long each bootstrap step took, how often
+// the download cache was used, and how much was downloaded and installed,
+// and, if asJson is set, also prints the statistics as JSON on standard
+// output.
+func reportStats(stats *buildtool.BootstrapStats, asJson bool) {
+	logMessages(true, stats.SummaryTable())
+	if asJson {
+		output, err := json.MarshalIndent(map[string]any{"tools": stats.Report()}, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(string(output))
+	}
+}
+
+func loadConfiguration(configFileFlag *string, downloadsFileFlag *string, profile string) (*buildtool.RuntimeConfigBuildTool, *buildtool.DownloadsTomlTopLevel, error) {
 	// Config file
 	configFilePath := selectConfigFile(configFileFlag)
-	configFile, err := buildtool.ReadConfigFile(configFilePath)
+	configFile, messages, err := buildtool.ReadConfigFile(configFilePath)
+	// Warnings are always shown, since they mean config.toml may not be
+	// read the way its author intended.
+	logMessages(true, messages)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = buildtool.ApplyProfile(configFile, profile)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Downloads file
@@ -110,19 +204,20 @@ func loadConfiguration(configFileFlag *string, downloadsFileFlag *string) (*buil
 		}
 	}
 	var downloadsFile *buildtool.DownloadsTomlTopLevel
-	downloadsFile, err = buildtool.ReadDownloadsFile(downloadsFileFlag)
+	downloadsFile, messages, err = buildtool.ReadDownloadsFile(downloadsFileFlag)
+	logMessages(true, messages)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Runtime configuration
 	var config *buildtool.RuntimeConfigBuildTool
 	config, err = buildtool.BuildConfiguration(configFile, downloadsFile)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return config, err
+	return config, downloadsFile, err
 }
 
 func logMessages(writeOutput bool, messages []string) {
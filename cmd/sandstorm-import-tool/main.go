@@ -30,6 +30,9 @@ func main() {
 	case "export":
 		util.Chkfatal(legacy.Export(*mongoPort, *passwdFile, *snapshotDir))
 	case "import":
-		util.Chkfatal(legacy.Import(*sqlitePath, *snapshotDir))
+		report, err := legacy.Import(*sqlitePath, *snapshotDir)
+		util.Chkfatal(err)
+		_, err = report.WriteTo(os.Stdout)
+		util.Chkfatal(err)
 	}
 }
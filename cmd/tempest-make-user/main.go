@@ -13,6 +13,8 @@ var (
 	typ      = flag.String("type", "", "credential type to use")
 	scopedID = flag.String("id", "", "type-specific credential id")
 	roleStr  = flag.String("role", string(types.RoleUser), "role the user should have")
+	scopeStr = flag.String("admin-scopes", "",
+		"comma-separated admin scopes or presets to grant, e.g. \"support\"; admins have every scope")
 )
 
 func main() {
@@ -22,6 +24,8 @@ func main() {
 	if !role.IsValid() {
 		panic("Invalid role: " + role)
 	}
+	scopes, err := types.ParseAdminScopes(*scopeStr)
+	util.Chkfatal(err)
 
 	db, err := database.Open()
 	util.Chkfatal(err)
@@ -41,5 +45,8 @@ func main() {
 			ScopedID: *scopedID,
 		},
 	}))
+	if role != types.RoleAdmin && len(scopes) > 0 {
+		util.Chkfatal(tx.SetAdminScopes(accountID, scopes))
+	}
 	util.Chkfatal(tx.Commit())
 }
@@ -0,0 +1,21 @@
+// Command tempest-promote-standby promotes a warm standby server to a primary.
+//
+// After running this, restart tempest; it will start as a normal server using
+// the most recently replicated copy of the primary's data, even if
+// REPLICATION_PRIMARY_URL is still set.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"sandstorm.org/go/tempest/internal/server/replication"
+)
+
+func main() {
+	if err := replication.Promote(); err != nil {
+		fmt.Fprintln(os.Stderr, "Promoting standby:", err)
+		os.Exit(1)
+	}
+	fmt.Println("Standby promoted; restart tempest to start serving as the primary.")
+}
@@ -1,9 +1,51 @@
 package main
 
 import (
+	"os"
+
 	servermain "sandstorm.org/go/tempest/internal/server/main"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "demo" {
+		servermain.Demo(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "export-grain" {
+		servermain.ExportGrain(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "backup" {
+		servermain.Backup(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "restore" {
+		servermain.Restore(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "rpc-token" {
+		servermain.RPCToken(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "admin" {
+		servermain.Admin(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "dev" {
+		servermain.Dev(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "self-test" {
+		servermain.SelfTest(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "migrate-from-sandstorm" {
+		servermain.MigrateFromSandstorm(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "--validate-config" {
+		servermain.ValidateConfig(os.Args[2:])
+		return
+	}
 	servermain.Main()
 }
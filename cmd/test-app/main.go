@@ -1,11 +1,25 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"slices"
+
+	"sandstorm.org/go/tempest/pkg/exp/appenv"
 )
 
+// permissions are the names of the permissions in sandstorm-pkgdef.capnp.
+var permissions = []string{"editor"}
+
 func main() {
+	// Checked once, at startup, since the environment can't change.
+	envProblems := appenv.CheckEnviron(os.LookupEnv)
+	for _, problem := range envProblems {
+		log.Println("Environment does not conform:", problem)
+	}
+
 	http.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
 		log.Println("Incoming request.")
 		log.Println("method = ", req.Method)
@@ -22,5 +36,18 @@ func main() {
 		w.Header().Set("Content-Type", "text/plain")
 		w.Write([]byte("Test!"))
 	})
+	// /conformance reports whether Tempest gave the app everything in the
+	// appenv contract, with a 500 status if it didn't.
+	http.HandleFunc("/conformance", func(w http.ResponseWriter, req *http.Request) {
+		problems := slices.Concat(envProblems, appenv.CheckRequest(req, permissions))
+		w.Header().Set("Content-Type", "text/plain")
+		if len(problems) > 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		fmt.Fprintf(w, "appenv version %d: %d problems\n", appenv.Version, len(problems))
+		for _, problem := range problems {
+			fmt.Fprintln(w, problem)
+		}
+	})
 	panic(http.ListenAndServe(":8000", nil))
 }
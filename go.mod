@@ -13,9 +13,12 @@ require (
 	capnproto.org/go/capnp/v3 v3.0.0-alpha-29.0.20230703112659-e4708d081d4b
 	github.com/BurntSushi/toml v0.3.1
 	github.com/alecthomas/kong v1.8.0
+	github.com/aws/aws-sdk-go v1.39.0
+	github.com/fsnotify/fsnotify v1.5.4
 	github.com/go-acme/lego/v4 v4.8.0
 	github.com/gobwas/ws v1.1.0
 	github.com/gorilla/mux v1.8.0
+	github.com/klauspost/compress v1.18.0
 	github.com/mattn/go-sqlite3 v1.14.16
 	github.com/schollz/progressbar/v3 v3.18.0
 	github.com/stretchr/testify v1.9.0
@@ -24,6 +27,7 @@ require (
 	github.com/xi2/xz v0.0.0-20171230120015-48954b6210f8
 	golang.org/x/crypto v0.5.0
 	golang.org/x/exp v0.0.0-20230321023759-10a507213a29
+	golang.org/x/net v0.5.0
 	golang.org/x/sys v0.29.0
 	zenhack.net/go/jsapi v0.0.0-20230418065259-200f45ece3f9
 	zenhack.net/go/tea v0.0.0-20230524023758-356c069b5d8c
@@ -53,7 +57,6 @@ require (
 	github.com/akamai/AkamaiOPEN-edgegrid-golang v1.2.1 // indirect
 	github.com/aliyun/alibaba-cloud-sdk-go v1.61.1755 // indirect
 	github.com/andres-erbsen/clock v0.0.0-20160526145045-9e14626cd129 // indirect
-	github.com/aws/aws-sdk-go v1.39.0 // indirect
 	github.com/boombuler/barcode v1.0.1-0.20190219062509-6c824513bacc // indirect
 	github.com/cenkalti/backoff/v4 v4.2.0 // indirect
 	github.com/cloudflare/cloudflare-go v0.49.0 // indirect
@@ -64,7 +67,6 @@ require (
 	github.com/dnsimple/dnsimple-go v0.71.1 // indirect
 	github.com/exoscale/egoscale v0.90.0 // indirect
 	github.com/fatih/structs v1.1.0 // indirect
-	github.com/fsnotify/fsnotify v1.5.4 // indirect
 	github.com/go-errors/errors v1.0.1 // indirect
 	github.com/go-resty/resty/v2 v2.1.1-0.20191201195748-d7b97669fe48 // indirect
 	github.com/gobwas/httphead v0.1.0 // indirect
@@ -87,7 +89,6 @@ require (
 	github.com/jmespath/go-jmespath v0.4.0 // indirect
 	github.com/json-iterator/go v1.1.12 // indirect
 	github.com/k0kubun/go-ansi v0.0.0-20180517002512-3bf9e2903213 // indirect
-	github.com/klauspost/compress v1.13.6 // indirect
 	github.com/kolo/xmlrpc v0.0.0-20200310150728-e0350524596b // indirect
 	github.com/labbsr0x/bindman-dns-webhook v1.0.2 // indirect
 	github.com/labbsr0x/goh v1.0.1 // indirect
@@ -140,7 +141,6 @@ require (
 	go.opencensus.io v0.24.0 // indirect
 	go.uber.org/ratelimit v0.2.0 // indirect
 	golang.org/x/mod v0.6.0 // indirect
-	golang.org/x/net v0.5.0 // indirect
 	golang.org/x/oauth2 v0.4.0 // indirect
 	golang.org/x/sync v0.1.0 // indirect
 	golang.org/x/term v0.28.0 // indirect
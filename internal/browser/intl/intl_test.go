@@ -32,3 +32,24 @@ func TestL10NFmt(t *testing.T) {
 	assert.Equal(t, "Until next time!", english.Fmt("Until next time!"))
 	assert.Equal(t, "Auf Wiedersehen!", german.Fmt("Until next time!"))
 }
+
+func TestNegotiate(t *testing.T) {
+	available := []string{"en", "de", "pt-BR", "pt-PT", "zh-Hant"}
+	cases := []struct {
+		preferred []string
+		want      string
+	}{
+		{[]string{"de"}, "de"},
+		{[]string{"DE-de"}, "de"},
+		{[]string{"fr", "de"}, "de"},
+		{[]string{"zh-Hant-TW"}, "zh-Hant"},
+		{[]string{"pt"}, "pt-BR"},
+		{[]string{"pt-PT"}, "pt-PT"},
+		{[]string{"de-u-co-phonebk"}, "de"},
+		{[]string{"fr", "*"}, "en"},
+		{nil, "en"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, Negotiate(c.preferred, available, "en"), c.preferred)
+	}
+}
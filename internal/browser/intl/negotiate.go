@@ -0,0 +1,84 @@
+package intl
+
+import "strings"
+
+// DefaultLocale is the locale of the format strings in the source code, used
+// when none of the user's preferred locales are available.
+const DefaultLocale = "en"
+
+// Localizations maps each available locale, as a BCP 47 language tag, to its
+// localization.
+var Localizations = map[string]L10N{
+	DefaultLocale: {},
+}
+
+// AvailableLocales returns the locales in Localizations.
+func AvailableLocales() []string {
+	ret := make([]string, 0, len(Localizations))
+	for locale := range Localizations {
+		ret = append(ret, locale)
+	}
+	return ret
+}
+
+// Negotiate picks the available locale which best matches the user's
+// preferred locales, most preferred first, e.g. from navigator.languages or
+// Accept-Language. For each preferred locale in turn, it tries:
+//
+//  1. an exact match, ignoring case,
+//  2. the locale with subtags removed from the end, so "zh-Hant-TW" falls
+//     back to "zh-Hant" and then "zh", and
+//  3. any available locale for the same language, so "pt" matches "pt-BR".
+//
+// If nothing matches, it returns fallback.
+func Negotiate(preferred []string, available []string, fallback string) string {
+	for _, want := range preferred {
+		want = strings.TrimSpace(want)
+		if want == "" || want == "*" {
+			continue
+		}
+		for tag := want; tag != ""; tag = parentTag(tag) {
+			for _, have := range available {
+				if strings.EqualFold(tag, have) {
+					return have
+				}
+			}
+		}
+		language := primaryLanguage(want)
+		bestMatch := ""
+		for _, have := range available {
+			// Prefer the shortest, i.e. most generic, match, and
+			// the first of equal length, so the result doesn't
+			// depend on the order of available.
+			if strings.EqualFold(primaryLanguage(have), language) &&
+				(bestMatch == "" || len(have) < len(bestMatch) ||
+					len(have) == len(bestMatch) && have < bestMatch) {
+				bestMatch = have
+			}
+		}
+		if bestMatch != "" {
+			return bestMatch
+		}
+	}
+	return fallback
+}
+
+// parentTag removes the last subtag from tag, returning "" if there is only
+// one. Single-character subtags are removed along with the subtag after
+// them, since they introduce extensions, e.g. "de-u-co-phonebk".
+func parentTag(tag string) string {
+	i := strings.LastIndex(tag, "-")
+	if i < 0 {
+		return ""
+	}
+	tag = tag[:i]
+	if j := strings.LastIndex(tag, "-"); j >= 0 && len(tag)-j == 2 {
+		tag = tag[:j]
+	}
+	return tag
+}
+
+func primaryLanguage(tag string) string {
+	language, _, _ := strings.Cut(tag, "-")
+	return language
+}
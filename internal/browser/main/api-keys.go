@@ -0,0 +1,251 @@
+package browsermain
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"slices"
+
+	"sandstorm.org/go/tempest/internal/common/types"
+	"zenhack.net/go/tea"
+	"zenhack.net/go/tea/vdom"
+	"zenhack.net/go/tea/vdom/builder"
+	"zenhack.net/go/tea/vdom/events"
+	"zenhack.net/go/util/maybe"
+)
+
+// APIKeyForm holds the inputs of the form for making an API key.
+type APIKeyForm struct {
+	NameInput   string
+	Grains      []types.GrainID
+	AccountRead bool
+}
+
+// fetchAPIKeys fetches the user's API keys, and sends HaveAPIKeys. See the
+// server's api-keys.go for the protocol.
+func fetchAPIKeys(ctx context.Context, send func(Msg)) {
+	var keys []types.APIKey
+	if err := fetchJSON(ctx, "/api-keys", "API keys", &keys); err != nil {
+		send(NewError{Err: err})
+		return
+	}
+	send(HaveAPIKeys{Keys: keys})
+}
+
+// HaveAPIKeys delivers the user's API keys, oldest first.
+type HaveAPIKeys struct {
+	Keys []types.APIKey
+}
+
+func (msg HaveAPIKeys) Update(m *Model) Cmd {
+	m.APIKeys = maybe.New(msg.Keys)
+	return nil
+}
+
+// EditAPIKeyName, ToggleAPIKeyGrain and ToggleAPIKeyAccountRead change the
+// inputs of the form for making an API key.
+type EditAPIKeyName struct {
+	NewValue string
+}
+
+type ToggleAPIKeyGrain struct {
+	GrainID types.GrainID
+}
+
+type ToggleAPIKeyAccountRead struct{}
+
+func (msg EditAPIKeyName) Update(m *Model) Cmd {
+	m.APIKeyForm.NameInput = msg.NewValue
+	return nil
+}
+
+func (msg ToggleAPIKeyGrain) Update(m *Model) Cmd {
+	form := &m.APIKeyForm
+	if i := slices.Index(form.Grains, msg.GrainID); i >= 0 {
+		form.Grains = slices.Delete(form.Grains, i, i+1)
+	} else {
+		form.Grains = append(form.Grains, msg.GrainID)
+	}
+	return nil
+}
+
+func (ToggleAPIKeyAccountRead) Update(m *Model) Cmd {
+	m.APIKeyForm.AccountRead = !m.APIKeyForm.AccountRead
+	return nil
+}
+
+// CreateAPIKey asks the server for a new API key, as described by the form.
+type CreateAPIKey struct{}
+
+func (CreateAPIKey) Update(m *Model) Cmd {
+	form := m.APIKeyForm
+	req := types.NewAPIKey{
+		Name:        form.NameInput,
+		Grains:      slices.Clone(form.Grains),
+		AccountRead: form.AccountRead,
+	}
+	return func(ctx context.Context, send func(Msg)) {
+		var offer types.APIKeyOffer
+		if err := sendJSON(ctx, http.MethodPost, "/api-keys", "API key", req, &offer); err != nil {
+			send(NewError{Err: err})
+			return
+		}
+		send(HaveAPIKey{Offer: offer})
+	}
+}
+
+// HaveAPIKey delivers an API key which was just made, with the key itself;
+// the server doesn't keep it, so this is the only time the user may copy it.
+type HaveAPIKey struct {
+	Offer types.APIKeyOffer
+}
+
+func (msg HaveAPIKey) Update(m *Model) Cmd {
+	m.NewAPIKey = msg.Offer.Token
+	m.APIKeyForm = APIKeyForm{}
+	// If the list hasn't been fetched, it will include the key when it
+	// is.
+	if keys, ok := m.APIKeys.Get(); ok {
+		m.APIKeys = maybe.New(append(keys, msg.Offer.Key))
+	}
+	return nil
+}
+
+// RevokeAPIKey asks the server to revoke an API key, so that scripts can no
+// longer use it.
+type RevokeAPIKey struct {
+	ID string
+}
+
+func (msg RevokeAPIKey) Update(m *Model) Cmd {
+	return func(ctx context.Context, send func(Msg)) {
+		if err := sendJSON(ctx, http.MethodDelete, "/api-keys/"+msg.ID, "API key", nil, nil); err != nil {
+			send(NewError{Err: err})
+			return
+		}
+		send(APIKeyRevoked(msg))
+	}
+}
+
+// APIKeyRevoked reports that the server revoked an API key.
+type APIKeyRevoked struct {
+	ID string
+}
+
+func (msg APIKeyRevoked) Update(m *Model) Cmd {
+	if keys, ok := m.APIKeys.Get(); ok {
+		m.APIKeys = maybe.New(slices.DeleteFunc(slices.Clone(keys), func(k types.APIKey) bool {
+			return k.ID == msg.ID
+		}))
+	}
+	return nil
+}
+
+// viewAPIKeys renders the API keys page: a form for making keys, the key last
+// made, and the list of the user's keys, which may be revoked.
+func (m Model) viewAPIKeys(ms tea.MessageSender[Model]) vdom.VNode {
+	keys, ok := m.APIKeys.Get()
+	if !ok {
+		return t(m.L10N, "Loading...")
+	}
+	apiHost := m.ServerAddr.Subdomain("api")
+	form := m.APIKeyForm
+	kids := []vdom.VNode{
+		h("h2", nil, nil, t(m.L10N, "API keys")),
+		h("p", nil, nil,
+			t(m.L10N, "API keys let your scripts use %0 on your behalf, with the grains you choose and, if you allow it, read your account.",
+				apiHost.String()),
+		),
+		h("label", a{"for": "api-key-name"}, nil,
+			t(m.L10N, "Name, to remind you what it's for"),
+		),
+		h("input", a{
+			"name":  "api-key-name",
+			"value": form.NameInput,
+		}, e{
+			"input": events.OnInput(func(value string) {
+				ms.Send(EditAPIKeyName{NewValue: value})
+			}),
+		}),
+	}
+	grainIDs := make([]types.GrainID, 0, len(m.Grains))
+	for id, grain := range m.Grains {
+		if !grain.Trashed {
+			grainIDs = append(grainIDs, id)
+		}
+	}
+	slices.SortFunc(grainIDs, func(x, y types.GrainID) int {
+		return cmp.Or(cmp.Compare(m.Grains[x].Title, m.Grains[y].Title), cmp.Compare(x, y))
+	})
+	for _, id := range grainIDs {
+		attrs := a{"type": "checkbox"}
+		if slices.Contains(form.Grains, id) {
+			attrs["checked"] = "checked"
+		}
+		kids = append(kids, h("label", a{"class": "api-keys__scope"}, nil,
+			h("input", attrs, e{"click": ms.Event(ToggleAPIKeyGrain{GrainID: id})}),
+			builder.T(m.Grains[id].Title),
+		))
+	}
+	accountAttrs := a{"type": "checkbox"}
+	if form.AccountRead {
+		accountAttrs["checked"] = "checked"
+	}
+	submitAttrs := a{"type": "submit"}
+	if len(form.Grains) == 0 && !form.AccountRead {
+		submitAttrs["disabled"] = "disabled"
+	}
+	kids = append(kids,
+		h("label", a{"class": "api-keys__scope"}, nil,
+			h("input", accountAttrs, e{"click": ms.Event(ToggleAPIKeyAccountRead{})}),
+			t(m.L10N, "Read your account and list your grains"),
+		),
+		h("button", submitAttrs,
+			e{"click": ms.Event(CreateAPIKey{})},
+			t(m.L10N, "Make API key"),
+		),
+	)
+	if m.NewAPIKey != "" {
+		kids = append(kids, h("div", a{"class": "api-keys__new"}, nil,
+			h("p", nil, nil,
+				t(m.L10N, "Copy your new API key now; it won't be shown again.")),
+			h("pre", nil, nil, builder.T(m.NewAPIKey)),
+		))
+	}
+
+	var items []vdom.VNode
+	for _, key := range keys {
+		name := builder.T(key.Name)
+		if key.Name == "" {
+			name = t(m.L10N, "Unnamed key")
+		}
+		created := key.Created.Local().Format("2006-01-02")
+		detail := t(m.L10N, "Created %0, never used", created)
+		if !key.LastUsed.IsZero() {
+			detail = t(m.L10N, "Created %0, last used %1",
+				created, key.LastUsed.Local().Format("2006-01-02 15:04"))
+		}
+		var scopes []vdom.VNode
+		for _, id := range key.Grains {
+			title := string(id)
+			if grain, ok := m.Grains[id]; ok {
+				title = grain.Title
+			}
+			scopes = append(scopes, h("li", nil, nil, builder.T(title)))
+		}
+		if key.AccountRead {
+			scopes = append(scopes, h("li", nil, nil, t(m.L10N, "Read your account")))
+		}
+		items = append(items, h("li", a{"class": "api-keys__item"}, nil,
+			h("p", nil, nil, name),
+			h("p", a{"class": "api-keys__detail"}, nil, detail),
+			h("ul", nil, nil, scopes...),
+			h("button", nil,
+				e{"click": ms.Event(RevokeAPIKey{ID: key.ID})},
+				t(m.L10N, "Revoke"),
+			),
+		))
+	}
+	kids = append(kids, h("ul", a{"class": "api-keys__list"}, nil, items...))
+	return h("div", a{"class": "api-keys"}, nil, kids...)
+}
@@ -0,0 +1,197 @@
+package browsermain
+
+import (
+	"context"
+	"net/http"
+	"syscall/js"
+
+	"sandstorm.org/go/tempest/internal/common/types"
+	"zenhack.net/go/tea"
+	"zenhack.net/go/tea/vdom"
+	"zenhack.net/go/tea/vdom/builder"
+	"zenhack.net/go/util/maybe"
+)
+
+// TemplateRenderRequested is sent when a grain asks, through the postMessage
+// API's renderTemplate, for an API token to offer the user. Like
+// PowerboxRequested, main sends this for "message" events which are such
+// requests. See the server's api-tokens.go for the protocol.
+type TemplateRenderRequested struct {
+	Request types.NewAPIToken
+	reply   grainReply
+}
+
+// renderTemplateFromEvent decodes a "message" event, if it is a
+// renderTemplate request.
+func renderTemplateFromEvent(event js.Value) (TemplateRenderRequested, bool) {
+	data := event.Get("data")
+	if data.Type() != js.TypeObject {
+		return TemplateRenderRequested{}, false
+	}
+	req := data.Get("renderTemplate")
+	if req.Type() != js.TypeObject {
+		return TemplateRenderRequested{}, false
+	}
+	msg := TemplateRenderRequested{
+		reply: grainReply{
+			window: event.Get("source"),
+			origin: event.Get("origin").String(),
+			rpcID:  req.Get("rpcId"),
+		},
+	}
+	if template := req.Get("template"); template.Type() == js.TypeString {
+		msg.Request.Template = template.String()
+	}
+	if petname := req.Get("petname"); petname.Type() == js.TypeString {
+		msg.Request.Petname = petname.String()
+	}
+	// Sandstorm's roleAssignment is a union of {allAccess}, {roleId} and
+	// {none}; allAccess is the default.
+	if roles := req.Get("roleAssignment"); roles.Type() == js.TypeObject {
+		if roleID := roles.Get("roleId"); roleID.Type() == js.TypeNumber {
+			id := roleID.Int()
+			msg.Request.RoleID = &id
+		} else if !roles.Get("none").IsUndefined() {
+			msg.Request.NoAccess = true
+		}
+	}
+	return msg, true
+}
+
+func (msg TemplateRenderRequested) Update(m *Model) Cmd {
+	grainID, ok := m.grainByOrigin(msg.reply.origin)
+	if !ok {
+		return nil
+	}
+	return func(ctx context.Context, send func(Msg)) {
+		var offer types.APITokenOffer
+		err := sendJSON(ctx, http.MethodPost, "/grain-api-tokens/"+string(grainID), "grain", msg.Request, &offer)
+		if err != nil {
+			msg.reply.send(map[string]any{"error": err.Error()})
+			send(NewError{Err: err})
+			return
+		}
+		msg.reply.send(map[string]any{"uri": offer.URL})
+		send(HaveGrainAPIToken{
+			GrainID: grainID,
+			Token:   offer.Token,
+		})
+	}
+}
+
+// HaveGrainAPITokens delivers the API tokens for a grain which the user may
+// see, for its sharing dialog.
+type HaveGrainAPITokens struct {
+	GrainID types.GrainID
+	Tokens  []types.APIToken
+}
+
+func (msg HaveGrainAPITokens) Update(m *Model) Cmd {
+	grain, ok := m.OpenGrains[msg.GrainID]
+	if !ok {
+		// Closed while loading.
+		return nil
+	}
+	grain.APITokens = maybe.New(msg.Tokens)
+	m.OpenGrains[msg.GrainID] = grain
+	return nil
+}
+
+// HaveGrainAPIToken delivers an API token which was just made.
+type HaveGrainAPIToken struct {
+	GrainID types.GrainID
+	Token   types.APIToken
+}
+
+func (msg HaveGrainAPIToken) Update(m *Model) Cmd {
+	grain, ok := m.OpenGrains[msg.GrainID]
+	if !ok {
+		return nil
+	}
+	// If the list hasn't been fetched, it will include the token when it
+	// is.
+	if tokens, ok := grain.APITokens.Get(); ok {
+		grain.APITokens = maybe.New(append(tokens, msg.Token))
+		m.OpenGrains[msg.GrainID] = grain
+	}
+	return nil
+}
+
+// RevokeGrainAPIToken asks the server to revoke an API token, so that
+// clients can no longer use it.
+type RevokeGrainAPIToken struct {
+	GrainID types.GrainID
+	TokenID string
+}
+
+func (msg RevokeGrainAPIToken) Update(m *Model) Cmd {
+	return func(ctx context.Context, send func(Msg)) {
+		path := "/grain-api-tokens/" + string(msg.GrainID) + "/" + msg.TokenID
+		if err := sendJSON(ctx, http.MethodDelete, path, "API token", nil, nil); err != nil {
+			send(NewError{Err: err})
+			return
+		}
+		send(GrainAPITokenRevoked(msg))
+	}
+}
+
+// GrainAPITokenRevoked reports that the server revoked an API token.
+type GrainAPITokenRevoked struct {
+	GrainID types.GrainID
+	TokenID string
+}
+
+func (msg GrainAPITokenRevoked) Update(m *Model) Cmd {
+	grain, ok := m.OpenGrains[msg.GrainID]
+	if !ok {
+		return nil
+	}
+	if tokens, ok := grain.APITokens.Get(); ok {
+		var remaining []types.APIToken
+		for _, t := range tokens {
+			if t.ID != msg.TokenID {
+				remaining = append(remaining, t)
+			}
+		}
+		grain.APITokens = maybe.New(remaining)
+	}
+	m.OpenGrains[msg.GrainID] = grain
+	return nil
+}
+
+// viewGrainAPITokens renders the part of the sharing dialog listing the
+// grain's API tokens, which may be revoked. Tokens are made by the grain
+// itself, so there is nothing to show if it has none.
+func (m Model) viewGrainAPITokens(ms tea.MessageSender[Model], id types.GrainID, grain OpenGrain) vdom.VNode {
+	tokens, _ := grain.APITokens.Get()
+	if len(tokens) == 0 {
+		return h("section", a{"class": "grain-api-tokens"}, nil)
+	}
+	var items []vdom.VNode
+	for _, token := range tokens {
+		petname := builder.T(token.Petname)
+		if token.Petname == "" {
+			petname = t(m.L10N, "Unnamed token")
+		}
+		itemKids := []vdom.VNode{
+			h("p", nil, nil, petname),
+			h("p", a{"class": "grain-api-tokens__created"}, nil,
+				t(m.L10N, "Created %0", token.Created.Local().Format("2006-01-02")),
+			),
+		}
+		if !token.Mine {
+			itemKids = append(itemKids, h("p", nil, nil,
+				t(m.L10N, "Made by another user"),
+			))
+		}
+		itemKids = append(itemKids, h("button", nil,
+			e{"click": ms.Event(RevokeGrainAPIToken{GrainID: id, TokenID: token.ID})},
+			t(m.L10N, "Revoke"),
+		))
+		items = append(items, h("li", a{"class": "grain-api-tokens__item"}, nil, itemKids...))
+	}
+	return h("section", a{"class": "grain-api-tokens"}, nil,
+		h("h2", nil, nil, t(m.L10N, "API tokens")),
+		h("ul", a{"class": "grain-api-tokens__list"}, nil, items...),
+	)
+}
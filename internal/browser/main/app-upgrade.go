@@ -0,0 +1,202 @@
+package browsermain
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"sandstorm.org/go/tempest/internal/common/apierror"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"zenhack.net/go/tea"
+	"zenhack.net/go/tea/vdom"
+	"zenhack.net/go/tea/vdom/builder"
+)
+
+// fetchGrainUpgrades fetches the upgrades available for the user's grains.
+func fetchGrainUpgrades(ctx context.Context, send func(Msg)) {
+	var upgrades []types.GrainUpgrade
+	if err := fetchJSON(ctx, "/grain-upgrades", "grain", &upgrades); err != nil {
+		send(NewError{Err: err})
+		return
+	}
+	send(HaveGrainUpgrades{Upgrades: upgrades})
+}
+
+// HaveGrainUpgrades delivers the upgrades available for the user's grains.
+type HaveGrainUpgrades struct {
+	Upgrades []types.GrainUpgrade
+}
+
+func (msg HaveGrainUpgrades) Update(m *Model) Cmd {
+	m.GrainUpgrades = make(map[types.GrainID]types.GrainUpgrade, len(msg.Upgrades))
+	for _, u := range msg.Upgrades {
+		m.GrainUpgrades[u.GrainID] = u
+	}
+	return nil
+}
+
+// UpgradeGrain asks the server to upgrade a grain to the newest installed
+// version of its app. Only its owner may.
+type UpgradeGrain struct {
+	GrainID types.GrainID
+}
+
+func (msg UpgradeGrain) Update(m *Model) Cmd {
+	grainIDs := []types.GrainID{msg.GrainID}
+	return m.upgradeGrains(grainIDs, func(ctx context.Context) ([]types.GrainUpgradeResult, error) {
+		var result types.GrainUpgradeResult
+		err := sendJSON(ctx, http.MethodPost, "/grain-upgrades/"+string(msg.GrainID), "grain", nil, &result)
+		return []types.GrainUpgradeResult{result}, err
+	})
+}
+
+// UpgradeAppGrains asks the server to upgrade all of the user's grains of an
+// app to its newest installed version.
+type UpgradeAppGrains struct {
+	AppID string
+}
+
+func (msg UpgradeAppGrains) Update(m *Model) Cmd {
+	var grainIDs []types.GrainID
+	for id, u := range m.GrainUpgrades {
+		if u.AppID == msg.AppID {
+			grainIDs = append(grainIDs, id)
+		}
+	}
+	return m.upgradeGrains(grainIDs, func(ctx context.Context) ([]types.GrainUpgradeResult, error) {
+		var results []types.GrainUpgradeResult
+		path := "/grain-upgrades?appId=" + url.QueryEscape(msg.AppID)
+		err := sendJSON(ctx, http.MethodPost, path, "grain", nil, &results)
+		return results, err
+	})
+}
+
+// upgradeGrains returns a command which marks the grains as upgrading, makes
+// the request to upgrade them, and sends GrainsUpgraded.
+func (m *Model) upgradeGrains(
+	grainIDs []types.GrainID,
+	request func(context.Context) ([]types.GrainUpgradeResult, error),
+) Cmd {
+	for _, id := range grainIDs {
+		if m.Upgrading[id] {
+			return nil
+		}
+	}
+	for _, id := range grainIDs {
+		m.Upgrading[id] = true
+	}
+	return func(ctx context.Context, send func(Msg)) {
+		results, err := request(ctx)
+		if err != nil {
+			send(NewError{Err: err})
+			results = nil
+		}
+		send(GrainsUpgraded{GrainIDs: grainIDs, Results: results})
+	}
+}
+
+// GrainsUpgraded reports that the server has finished upgrading grains.
+// Results has an entry for each grain it upgraded, or tried to.
+type GrainsUpgraded struct {
+	GrainIDs []types.GrainID
+	Results  []types.GrainUpgradeResult
+}
+
+func (msg GrainsUpgraded) Update(m *Model) Cmd {
+	for _, id := range msg.GrainIDs {
+		delete(m.Upgrading, id)
+	}
+	for _, r := range msg.Results {
+		if r.RolledBack {
+			m.Errors = append(m.Errors, apierror.New(apierror.CodeUpgradeFailed,
+				"grain was rolled back after failing to start", m.Grains[r.GrainID].Title))
+		}
+	}
+	var grainID types.GrainID
+	if m.CurrentFocus == FocusGrainDetails {
+		grainID = m.FocusedGrain
+	}
+	return func(ctx context.Context, send func(Msg)) {
+		fetchGrainUpgrades(ctx, send)
+		if grainID == "" {
+			return
+		}
+		// The upgrade is in the timeline.
+		var timeline []types.GrainEvent
+		if err := fetchJSON(ctx, "/grain-timeline/"+string(grainID), "grain", &timeline); err != nil {
+			send(NewError{Err: err})
+			return
+		}
+		send(HaveGrainTimeline{GrainID: grainID, Timeline: timeline})
+	}
+}
+
+// viewGrainUpgrade renders the part of a grain's details panel offering to
+// upgrade it, if a newer version of its app is installed.
+func (m Model) viewGrainUpgrade(ms tea.MessageSender[Model], id types.GrainID) vdom.VNode {
+	u, ok := m.GrainUpgrades[id]
+	if !ok {
+		return dummyNode
+	}
+	return h("section", a{"class": "grain-upgrade"}, nil,
+		t(m.L10N, "Version %0 of %1 is installed.", u.ToMarketingVersion, u.AppTitle),
+		m.viewUpgradeButton(ms, m.Upgrading[id], UpgradeGrain{GrainID: id}),
+	)
+}
+
+// viewAppUpgrades renders the apps which the user's grains may be upgraded to
+// newer versions of, each with a button to upgrade all of them.
+func (m Model) viewAppUpgrades(ms tea.MessageSender[Model]) vdom.VNode {
+	type appUpgrades struct {
+		u         types.GrainUpgrade
+		count     int
+		upgrading bool
+	}
+	var (
+		apps  = make(map[string]*appUpgrades)
+		order []string
+	)
+	for id, u := range m.GrainUpgrades {
+		app, ok := apps[u.AppID]
+		if !ok {
+			app = &appUpgrades{u: u}
+			apps[u.AppID] = app
+			order = append(order, u.AppID)
+		}
+		app.count++
+		app.upgrading = app.upgrading || m.Upgrading[id]
+	}
+	if len(order) == 0 {
+		return dummyNode
+	}
+	sort.Strings(order)
+	var items []vdom.VNode
+	for _, appID := range order {
+		app := apps[appID]
+		items = append(items, h("li", a{"class": "app-upgrades__item"}, nil,
+			// TODO: figure out how translation
+			// should work for app-provided strings.
+			h("strong", nil, nil, builder.T(app.u.AppTitle)),
+			h("span", a{"class": "app-upgrades__text"}, nil,
+				t(m.L10N, "%0 of your grains can be upgraded to version %1.",
+					strconv.Itoa(app.count), app.u.ToMarketingVersion),
+			),
+			m.viewUpgradeButton(ms, app.upgrading, UpgradeAppGrains{AppID: appID}),
+		))
+	}
+	return h("section", a{"class": "app-upgrades"}, nil,
+		h("h2", nil, nil, t(m.L10N, "Updates")),
+		h("ul", a{"class": "app-upgrades__list"}, nil, items...),
+	)
+}
+
+// viewUpgradeButton renders a button which sends msg, disabled while the
+// grains it upgrades are upgrading.
+func (m Model) viewUpgradeButton(ms tea.MessageSender[Model], upgrading bool, msg Msg) vdom.VNode {
+	if upgrading {
+		return h("button", a{"disabled": "disabled"}, nil, t(m.L10N, "Upgrading..."))
+	}
+	return h("button", nil, e{"click": ms.Event(msg)}, t(m.L10N, "Upgrade"))
+}
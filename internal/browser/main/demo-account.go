@@ -0,0 +1,31 @@
+package browsermain
+
+import (
+	"context"
+	"time"
+
+	"sandstorm.org/go/tempest/internal/common/types"
+)
+
+// fetchDemoAccount asks the server whether the user's account is a demo
+// account, and sends HaveDemoAccount. Failures are ignored; the shell works
+// the same either way.
+func fetchDemoAccount(ctx context.Context, send func(Msg)) {
+	var demo types.DemoAccount
+	if err := fetchJSON(ctx, "/demo-account", "account", &demo); err != nil {
+		println("fetching /demo-account: " + err.Error())
+		return
+	}
+	send(HaveDemoAccount{Expires: demo.Expires})
+}
+
+// HaveDemoAccount delivers when the user's account expires, if it is a demo
+// account, or else the zero time.
+type HaveDemoAccount struct {
+	Expires time.Time
+}
+
+func (msg HaveDemoAccount) Update(m *Model) Cmd {
+	m.DemoExpires = msg.Expires
+	return nil
+}
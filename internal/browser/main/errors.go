@@ -0,0 +1,28 @@
+package browsermain
+
+import (
+	"sandstorm.org/go/tempest/internal/browser/intl"
+	"sandstorm.org/go/tempest/internal/common/apierror"
+	"zenhack.net/go/tea/vdom"
+	"zenhack.net/go/tea/vdom/builder"
+)
+
+// viewError renders an error notice. Errors from the server get a localized
+// message, falling back to the server's English message for internal errors
+// and codes this version of the shell doesn't know.
+func viewError(l10n intl.L10N, err error) vdom.VNode {
+	apiErr := apierror.FromError(err)
+	nodes := []vdom.VNode{builder.T(apiErr.Localize(l10n))}
+	if apiErr.Retryable {
+		nodes = append(nodes, h("span", a{"class": "error-notice__hint"}, nil,
+			t(l10n, "This may be temporary; please try again."),
+		))
+	}
+	if apiErr.Code == apierror.CodeNotLoggedIn {
+		// Reloading the shell starts a new login session.
+		nodes = append(nodes, h("a", a{"class": "error-notice__action", "href": "/"}, nil,
+			t(l10n, "Log in"),
+		))
+	}
+	return h("div", a{"class": "error-notice error-notice--" + string(apiErr.Code)}, nil, nodes...)
+}
@@ -0,0 +1,97 @@
+package browsermain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"syscall/js"
+
+	"sandstorm.org/go/tempest/internal/common/apierror"
+)
+
+// fetchJSON GETs path from the server with the browser's fetch API, sending
+// our cookies, and decodes the JSON response into v. what names the resource,
+// for the error if it isn't found, e.g. "grain".
+func fetchJSON(ctx context.Context, path, what string, v any) error {
+	return sendJSON(ctx, http.MethodGet, path, what, nil, v)
+}
+
+// sendJSON is like fetchJSON, but with the given method, sending body, if not
+// nil, as JSON. If v is nil, the response body is ignored.
+func sendJSON(ctx context.Context, method, path, what string, body, v any) error {
+	init := map[string]any{
+		"method":      method,
+		"credentials": "same-origin",
+	}
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		init["body"] = string(buf)
+		init["headers"] = map[string]any{"Content-Type": "application/json"}
+	}
+	type result struct {
+		body string
+		err  error
+	}
+	// Exactly one of the callbacks below sends a result.
+	results := make(chan result, 1)
+	var onResponse, onBody, onError js.Func
+	onError = js.FuncOf(func(this js.Value, args []js.Value) any {
+		results <- result{err: errors.New(args[0].Call("toString").String())}
+		return nil
+	})
+	onBody = js.FuncOf(func(this js.Value, args []js.Value) any {
+		results <- result{body: args[0].String()}
+		return nil
+	})
+	onResponse = js.FuncOf(func(this js.Value, args []js.Value) any {
+		resp := args[0]
+		if !resp.Get("ok").Bool() {
+			results <- result{err: httpStatusError(resp.Get("status").Int(), what)}
+			return nil
+		}
+		resp.Call("text").Call("then", onBody, onError)
+		return nil
+	})
+	release := func() {
+		onResponse.Release()
+		onBody.Release()
+		onError.Release()
+	}
+	js.Global().
+		Call("fetch", path, init).
+		Call("then", onResponse, onError)
+
+	select {
+	case r := <-results:
+		release()
+		if r.err != nil || v == nil {
+			return r.err
+		}
+		return json.Unmarshal([]byte(r.body), v)
+	case <-ctx.Done():
+		// The callbacks may still be called; release them after.
+		go func() {
+			<-results
+			release()
+		}()
+		return ctx.Err()
+	}
+}
+
+// httpStatusError converts an unsuccessful HTTP status to an error which
+// viewError can display.
+func httpStatusError(status int, what string) error {
+	switch status {
+	case http.StatusUnauthorized:
+		return apierror.New(apierror.CodeNotLoggedIn, "not logged in")
+	case http.StatusNotFound:
+		return apierror.New(apierror.CodeNotFound, what+" not found", what)
+	default:
+		return errors.New("server returned HTTP status " + strconv.Itoa(status))
+	}
+}
@@ -0,0 +1,215 @@
+package browsermain
+
+import (
+	"context"
+
+	"sandstorm.org/go/tempest/capnp/external"
+	"sandstorm.org/go/tempest/internal/common/apierror"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"zenhack.net/go/tea"
+	"zenhack.net/go/tea/vdom"
+	"zenhack.net/go/tea/vdom/builder"
+	"zenhack.net/go/tea/vdom/events"
+)
+
+// EditGrainTitle updates the title input in a grain's details panel.
+type EditGrainTitle struct {
+	GrainID  types.GrainID
+	NewValue string
+}
+
+func (msg EditGrainTitle) Update(m *Model) Cmd {
+	grain, ok := m.OpenGrains[msg.GrainID]
+	if !ok {
+		return nil
+	}
+	grain.TitleInput = msg.NewValue
+	m.OpenGrains[msg.GrainID] = grain
+	return nil
+}
+
+// RenameGrain asks the server to give a grain the title in its details
+// panel. Only its owner may.
+type RenameGrain struct {
+	GrainID types.GrainID
+}
+
+func (msg RenameGrain) Update(m *Model) Cmd {
+	title := m.OpenGrains[msg.GrainID].TitleInput
+	return m.callGrainController(msg.GrainID,
+		func(ctx context.Context, ctrl external.UiView_Controller) error {
+			fut, rel := ctrl.SetTitle(ctx, func(p external.UiView_Controller_setTitle_Params) error {
+				return p.SetTitle(title)
+			})
+			defer rel()
+			_, err := apierror.Check(fut.Struct())
+			return err
+		},
+		GrainTitleChanged{GrainID: msg.GrainID, Title: title},
+	)
+}
+
+// GrainTitleChanged reports that the server renamed a grain.
+type GrainTitleChanged struct {
+	GrainID types.GrainID
+	Title   string
+}
+
+func (msg GrainTitleChanged) Update(m *Model) Cmd {
+	grain, ok := m.Grains[msg.GrainID]
+	if ok {
+		grain.Title = msg.Title
+		m.Grains[msg.GrainID] = grain
+	}
+	return nil
+}
+
+// TrashGrain asks the server to move a grain to the trash, or if Trashed is
+// false, to take it out. Only its owner may.
+type TrashGrain struct {
+	GrainID types.GrainID
+	Trashed bool
+}
+
+func (msg TrashGrain) Update(m *Model) Cmd {
+	return m.callGrainController(msg.GrainID,
+		func(ctx context.Context, ctrl external.UiView_Controller) error {
+			if msg.Trashed {
+				fut, rel := ctrl.MoveToTrash(ctx, nil)
+				defer rel()
+				_, err := apierror.Check(fut.Struct())
+				return err
+			}
+			fut, rel := ctrl.RestoreFromTrash(ctx, nil)
+			defer rel()
+			_, err := apierror.Check(fut.Struct())
+			return err
+		},
+		GrainTrashedChanged{GrainID: msg.GrainID, Trashed: msg.Trashed},
+	)
+}
+
+// GrainTrashedChanged reports that the server moved a grain to the trash, or
+// took it out.
+type GrainTrashedChanged struct {
+	GrainID types.GrainID
+	Trashed bool
+}
+
+func (msg GrainTrashedChanged) Update(m *Model) Cmd {
+	grain, ok := m.Grains[msg.GrainID]
+	if !ok {
+		return nil
+	}
+	grain.Trashed = msg.Trashed
+	m.Grains[msg.GrainID] = grain
+	if !msg.Trashed {
+		return nil
+	}
+	// Grains in the trash can't be opened, so close it if it is.
+	focused := m.CurrentFocus.HasGrain() && m.FocusedGrain == msg.GrainID
+	CloseGrain{ID: msg.GrainID}.Update(m)
+	if !focused {
+		return nil
+	}
+	return func(context.Context, func(Msg)) {
+		navigate("#/grains")
+	}
+}
+
+// PurgeGrain asks the server to delete a grain in the trash for good. Only its
+// owner may.
+type PurgeGrain struct {
+	GrainID types.GrainID
+}
+
+func (msg PurgeGrain) Update(m *Model) Cmd {
+	return m.callGrainController(msg.GrainID,
+		func(ctx context.Context, ctrl external.UiView_Controller) error {
+			fut, rel := ctrl.Purge(ctx, nil)
+			defer rel()
+			_, err := apierror.Check(fut.Struct())
+			return err
+		},
+		RemoveGrain{ID: msg.GrainID},
+	)
+}
+
+// callGrainController returns a command which calls the controller of the
+// grain's UiView, and sends done if that succeeds.
+func (m *Model) callGrainController(
+	grainID types.GrainID,
+	call func(context.Context, external.UiView_Controller) error,
+	done Msg,
+) Cmd {
+	grain, ok := m.Grains[grainID]
+	if !ok {
+		return nil
+	}
+	ctrl := grain.Controller.AddRef()
+	return func(ctx context.Context, send func(Msg)) {
+		defer ctrl.Release()
+		if err := call(ctx, ctrl); err != nil {
+			send(NewError{Err: err})
+			return
+		}
+		send(done)
+	}
+}
+
+// viewGrainTrash renders the grains in the user's trash, which they may
+// restore or delete for good.
+func (m Model) viewGrainTrash(ms tea.MessageSender[Model], grains []types.GrainID) vdom.VNode {
+	if len(grains) == 0 {
+		return dummyNode
+	}
+	var items []vdom.VNode
+	for _, id := range grains {
+		items = append(items, h("li", a{"class": "grain-trash__item"}, nil,
+			builder.T(m.Grains[id].Title),
+			viewGrainSize(m.Grains[id]),
+			h("button", nil,
+				e{"click": ms.Event(TrashGrain{GrainID: id, Trashed: false})},
+				t(m.L10N, "Restore"),
+			),
+			h("button", nil,
+				e{"click": ms.Event(PurgeGrain{GrainID: id})},
+				t(m.L10N, "Delete forever"),
+			),
+		))
+	}
+	return h("section", a{"class": "grain-trash"}, nil,
+		h("h2", nil, nil, t(m.L10N, "Trash")),
+		h("ul", a{"class": "grain-trash__list"}, nil, items...),
+	)
+}
+
+// viewGrainLifecycle renders the part of a grain's details panel for renaming
+// it and moving it to the trash.
+func (m Model) viewGrainLifecycle(ms tea.MessageSender[Model], id types.GrainID) vdom.VNode {
+	title := m.OpenGrains[id].TitleInput
+	submitAttrs := a{"type": "submit"}
+	if title == "" || title == m.Grains[id].Title {
+		submitAttrs["disabled"] = "disabled"
+	}
+	return h("section", a{"class": "grain-lifecycle"}, nil,
+		h("label", a{"for": "grain-title"}, nil, t(m.L10N, "Title")),
+		h("input", a{
+			"name":  "grain-title",
+			"value": title,
+		}, e{
+			"input": events.OnInput(func(value string) {
+				ms.Send(EditGrainTitle{GrainID: id, NewValue: value})
+			}),
+		}),
+		h("button",
+			submitAttrs,
+			e{"click": ms.Event(RenameGrain{GrainID: id})},
+			t(m.L10N, "Rename"),
+		),
+		h("button", nil,
+			e{"click": ms.Event(TrashGrain{GrainID: id, Trashed: true})},
+			t(m.L10N, "Move to trash"),
+		),
+	)
+}
@@ -45,6 +45,8 @@ func uiViewToGrain(view external.UiView) (Grain, error) {
 			SessionToken: sessionToken,
 			Subdomain:    subdomain,
 			Controller:   view.Controller().AddRef(),
+			Trashed:      view.Trashed(),
+			StorageBytes: int64(view.StorageBytes()),
 		}
 	})
 }
@@ -0,0 +1,42 @@
+package browsermain
+
+import (
+	"syscall/js"
+
+	"sandstorm.org/go/tempest/internal/browser/intl"
+)
+
+// localeStorageKey is the localStorage key for the user's chosen locale.
+const localeStorageKey = "tempest-locale"
+
+// negotiateLocale picks the shell's locale. A locale the user has chosen
+// wins; before they have chosen one, it is negotiated from the browser's
+// settings, so that it follows them if they change.
+func negotiateLocale() string {
+	window := js.Global().Get("window")
+	var preferred []string
+	if storage := window.Get("localStorage"); storage.Truthy() {
+		if chosen := storage.Call("getItem", localeStorageKey); chosen.Truthy() {
+			preferred = append(preferred, chosen.String())
+		}
+	}
+	navigator := window.Get("navigator")
+	if languages := navigator.Get("languages"); languages.Truthy() {
+		for i := 0; i < languages.Length(); i++ {
+			preferred = append(preferred, languages.Index(i).String())
+		}
+	} else if language := navigator.Get("language"); language.Truthy() {
+		preferred = append(preferred, language.String())
+	}
+	return intl.Negotiate(preferred, intl.AvailableLocales(), intl.DefaultLocale)
+}
+
+// localeCookieName is the cookie which tells the server the shell's locale,
+// so that it can write emails and error pages in it too.
+const localeCookieName = "tempest-locale"
+
+// setLocaleCookie tells the server the shell's locale.
+func setLocaleCookie(locale string) {
+	js.Global().Get("document").Set("cookie",
+		localeCookieName+"="+locale+"; path=/; max-age=31536000; SameSite=Lax")
+}
@@ -0,0 +1,199 @@
+package browsermain
+
+import (
+	"context"
+	"syscall/js"
+	"time"
+
+	"sandstorm.org/go/tempest/capnp/external"
+	"sandstorm.org/go/tempest/internal/common/apierror"
+	"zenhack.net/go/tea"
+	"zenhack.net/go/tea/vdom"
+	"zenhack.net/go/tea/vdom/builder"
+	"zenhack.net/go/util/maybe"
+)
+
+// An ActiveSession is one of the user's login sessions, i.e. a browser or
+// device they are logged in on; see external.LoginSession.
+type ActiveSession struct {
+	ID         string
+	UserAgent  string
+	RemoteAddr string
+	Started    time.Time
+	LastSeen   time.Time
+	Current    bool // Whether this is the session the shell is using.
+}
+
+// userSession returns a reference to the user's UserSession, if they are
+// logged in. The caller must release it.
+func (m *Model) userSession() (external.UserSession, bool) {
+	res, ok := m.LoginSessions.Get()
+	if !ok {
+		return external.UserSession{}, false
+	}
+	sessions, err := res.Get()
+	if err != nil {
+		return external.UserSession{}, false
+	}
+	return sessions.User.AddRef(), true
+}
+
+// fetchActiveSessions returns a command which fetches the user's login
+// sessions, or nil if they aren't logged in yet.
+func (m *Model) fetchActiveSessions() Cmd {
+	user, ok := m.userSession()
+	if !ok {
+		return nil
+	}
+	return func(ctx context.Context, send func(Msg)) {
+		defer user.Release()
+		loadActiveSessions(ctx, user, send)
+	}
+}
+
+// loadActiveSessions fetches the user's login sessions, and sends
+// HaveActiveSessions.
+func loadActiveSessions(ctx context.Context, user external.UserSession, send func(Msg)) {
+	fut, rel := user.LoginSessions(ctx, nil)
+	defer rel()
+	res, err := apierror.Check(fut.Struct())
+	if err != nil {
+		send(NewError{Err: err})
+		return
+	}
+	list, err := res.Sessions()
+	if err != nil {
+		send(NewError{Err: err})
+		return
+	}
+	sessions := make([]ActiveSession, list.Len())
+	for i := range sessions {
+		s := list.At(i)
+		id, _ := s.Id()
+		userAgent, _ := s.UserAgent()
+		remoteAddr, _ := s.RemoteAddr()
+		sessions[i] = ActiveSession{
+			ID:         id,
+			UserAgent:  userAgent,
+			RemoteAddr: remoteAddr,
+			Started:    time.Unix(0, s.Started()),
+			LastSeen:   time.Unix(0, s.LastSeen()),
+			Current:    s.Current(),
+		}
+	}
+	send(HaveActiveSessions{Sessions: sessions})
+}
+
+// HaveActiveSessions delivers the user's login sessions, most recently used
+// first.
+type HaveActiveSessions struct {
+	Sessions []ActiveSession
+}
+
+func (msg HaveActiveSessions) Update(m *Model) Cmd {
+	m.ActiveSessions = maybe.New(msg.Sessions)
+	return nil
+}
+
+// RevokeActiveSession asks the server to log out one of the user's login
+// sessions.
+type RevokeActiveSession struct {
+	ID string
+}
+
+func (msg RevokeActiveSession) Update(m *Model) Cmd {
+	user, ok := m.userSession()
+	if !ok {
+		return nil
+	}
+	current := false
+	if sessions, ok := m.ActiveSessions.Get(); ok {
+		for _, s := range sessions {
+			if s.ID == msg.ID {
+				current = s.Current
+			}
+		}
+	}
+	return func(ctx context.Context, send func(Msg)) {
+		defer user.Release()
+		fut, rel := user.RevokeLoginSession(ctx, func(p external.UserSession_revokeLoginSession_Params) error {
+			return p.SetId(msg.ID)
+		})
+		defer rel()
+		if _, err := apierror.Check(fut.Struct()); err != nil {
+			send(NewError{Err: err})
+			return
+		}
+		if current {
+			// We're logged out; start over at the login form.
+			js.Global().Get("location").Call("reload")
+			return
+		}
+		loadActiveSessions(ctx, user, send)
+	}
+}
+
+// RevokeAllSessions asks the server to log out all of the user's login
+// sessions, including this one.
+type RevokeAllSessions struct{}
+
+func (RevokeAllSessions) Update(m *Model) Cmd {
+	user, ok := m.userSession()
+	if !ok {
+		return nil
+	}
+	return func(ctx context.Context, send func(Msg)) {
+		defer user.Release()
+		fut, rel := user.RevokeAllLoginSessions(ctx, nil)
+		defer rel()
+		if _, err := apierror.Check(fut.Struct()); err != nil {
+			send(NewError{Err: err})
+			return
+		}
+		js.Global().Get("location").Call("reload")
+	}
+}
+
+// viewActiveSessions renders the user's login sessions, each with a button
+// to log it out, and a button to log out all of them.
+func (m Model) viewActiveSessions(ms tea.MessageSender[Model]) vdom.VNode {
+	sessions, ok := m.ActiveSessions.Get()
+	if !ok {
+		return t(m.L10N, "Loading...")
+	}
+	var items []vdom.VNode
+	for _, s := range sessions {
+		userAgent := builder.T(s.UserAgent)
+		if s.UserAgent == "" {
+			userAgent = t(m.L10N, "Unknown browser")
+		}
+		kids := []vdom.VNode{
+			h("span", a{"class": "login-sessions__agent"}, nil, userAgent),
+			h("span", a{"class": "login-sessions__detail"}, nil,
+				t(m.L10N, "Last used %0 from %1",
+					s.LastSeen.Local().Format("2006-01-02 15:04"),
+					s.RemoteAddr,
+				),
+			),
+		}
+		if s.Current {
+			kids = append(kids, h("strong", nil, nil, t(m.L10N, "This browser")))
+		}
+		kids = append(kids, h("button", nil,
+			e{"click": ms.Event(RevokeActiveSession{ID: s.ID})},
+			t(m.L10N, "Log out"),
+		))
+		items = append(items, h("li", a{"class": "login-sessions__item"}, nil, kids...))
+	}
+	return h("div", nil, nil,
+		h("h2", nil, nil, t(m.L10N, "Sessions")),
+		h("p", nil, nil,
+			t(m.L10N, "These are the browsers and devices you are logged in on. If you don't recognize one, log it out."),
+		),
+		h("ul", a{"class": "login-sessions"}, nil, items...),
+		h("button", nil,
+			e{"click": ms.Event(RevokeAllSessions{})},
+			t(m.L10N, "Log out everywhere"),
+		),
+	)
+}
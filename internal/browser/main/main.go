@@ -9,6 +9,7 @@ import (
 	"capnproto.org/go/capnp/v3/rpc/transport"
 	"sandstorm.org/go/tempest/capnp/collection"
 	"sandstorm.org/go/tempest/capnp/external"
+	"sandstorm.org/go/tempest/internal/common/apierror"
 	"sandstorm.org/go/tempest/internal/common/types"
 	"zenhack.net/go/tea"
 	"zenhack.net/go/tea/vdom"
@@ -63,6 +64,15 @@ func Main() {
 			app.SendMessage(navigateMessage())
 			return nil
 		}))
+	js.Global().Call("addEventListener", "message",
+		js.FuncOf(func(this js.Value, args []js.Value) any {
+			if msg, ok := powerboxRequestFromEvent(args[0]); ok {
+				app.SendMessage(msg)
+			} else if msg, ok := renderTemplateFromEvent(args[0]); ok {
+				app.SendMessage(msg)
+			}
+			return nil
+		}))
 	go app.Run(ctx, body)
 
 	conn, api := getCapnpApi(ctx)
@@ -80,7 +90,11 @@ func Main() {
 	// there and then drop this for improved latency.
 	viewsRes, err := viewsFut.Struct()
 	if err != nil {
-		app.SendMessage(NewError{Err: err})
+		// If getSessions failed, e.g. because we aren't logged in,
+		// there are no views either; that's reported below.
+		if _, sessErr := apierror.Check(sessionsFut.Struct()); sessErr == nil {
+			app.SendMessage(NewError{Err: err})
+		}
 	} else {
 		go func() {
 			syncFut, rel := viewsRes.Views().Sync(ctx, func(p collection.Puller_sync_Params) error {
@@ -97,7 +111,7 @@ func Main() {
 		}()
 	}
 
-	res, err := sessionsFut.Struct()
+	res, err := apierror.Check(sessionsFut.Struct())
 	if err != nil {
 		app.SendMessage(LoginSessionResult{Result: orerr.New(Sessions{}, err)})
 	} else {
@@ -3,6 +3,8 @@ package browsermain
 import (
 	"context"
 	"errors"
+	"net/http"
+	"strconv"
 	"strings"
 	"syscall/js"
 
@@ -10,6 +12,7 @@ import (
 	"sandstorm.org/go/tempest/capnp/collection"
 	"sandstorm.org/go/tempest/capnp/external"
 	"sandstorm.org/go/tempest/capnp/util"
+	"sandstorm.org/go/tempest/internal/common/apierror"
 	"sandstorm.org/go/tempest/internal/common/types"
 	"sandstorm.org/go/tempest/pkg/exp/util/bytestream"
 	"zenhack.net/go/jsapi/streams"
@@ -49,6 +52,24 @@ type RemovePackage struct {
 
 type ClearPackages struct{}
 
+type UpsertNotification struct {
+	ID           types.ID[external.Notification]
+	Notification external.Notification
+}
+
+type RemoveNotification struct {
+	ID types.ID[external.Notification]
+}
+
+type ClearNotifications struct{}
+
+// MarkNotificationsRead asks the server to mark notifications read, or all
+// of them if IDs is empty. The changes come back via the notifications
+// subscription.
+type MarkNotificationsRead struct {
+	IDs []types.ID[external.Notification]
+}
+
 type SpawnGrain struct {
 	Index int
 	PkgID types.ID[external.Package]
@@ -58,10 +79,6 @@ type CloseGrain struct {
 	ID types.GrainID
 }
 
-type ShareGrain struct {
-	ID types.GrainID
-}
-
 type EditEmailLogin struct {
 	NewValue string
 }
@@ -140,6 +157,52 @@ func (ClearPackages) Update(m *Model) Cmd {
 	return nil
 }
 
+func (msg UpsertNotification) Update(m *Model) Cmd {
+	m.Notifications[msg.ID] = msg.Notification
+	return nil
+}
+
+func (msg RemoveNotification) Update(m *Model) Cmd {
+	delete(m.Notifications, msg.ID)
+	return nil
+}
+
+func (ClearNotifications) Update(m *Model) Cmd {
+	m.Notifications = make(map[types.ID[external.Notification]]external.Notification)
+	return nil
+}
+
+func (msg MarkNotificationsRead) Update(m *Model) Cmd {
+	res, ok := m.LoginSessions.Get()
+	if !ok {
+		return nil
+	}
+	sessions, err := res.Get()
+	if err != nil {
+		return nil
+	}
+	user := sessions.User.AddRef()
+	return func(ctx context.Context, sendMsg func(Msg)) {
+		defer user.Release()
+		fut, rel := user.MarkNotificationsRead(ctx, func(p external.UserSession_markNotificationsRead_Params) error {
+			keys, err := p.NewKeys(int32(len(msg.IDs)))
+			if err != nil {
+				return err
+			}
+			for i, id := range msg.IDs {
+				if err := keys.Set(i, string(id)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		defer rel()
+		if _, err := apierror.Check(fut.Struct()); err != nil {
+			sendMsg(NewError{Err: err})
+		}
+	}
+}
+
 func (msg CloseGrain) Update(m *Model) Cmd {
 	g, ok := m.OpenGrains[msg.ID]
 	if ok {
@@ -181,7 +244,7 @@ func (msg SpawnGrain) Update(m *Model) Cmd {
 				})
 			})
 			defer rel()
-			res, err := fut.Struct()
+			res, err := apierror.Check(fut.Struct())
 			throw(err)
 
 			id, err := res.Id()
@@ -215,7 +278,15 @@ func (msg LoginSessionResult) Update(m *Model) Cmd {
 	if err != nil {
 		return nil
 	}
+	// The sessions page can't be loaded until we're logged in:
+	loadSessions := m.CurrentFocus == FocusSessions
 	return func(ctx context.Context, sendMsg func(Msg)) {
+		go subscribeNotifications(ctx, sess.User, sendMsg)
+		go fetchDemoAccount(ctx, sendMsg)
+		if loadSessions {
+			go loadActiveSessions(ctx, sess.User, sendMsg)
+		}
+
 		// TODO: there's no actual reason to wait for the result before doing all this:
 		pusher := collection.Pusher_ServerToClient(pusher[types.ID[external.Package], external.Package]{
 			sendMsg: sendMsg,
@@ -226,13 +297,31 @@ func (msg LoginSessionResult) Update(m *Model) Cmd {
 			return nil
 		})
 		defer rel()
-		_, err := ret.Struct()
+		_, err := apierror.Check(ret.Struct())
 		if err != nil {
 			println("listPackages(): " + err.Error())
 		}
 	}
 }
 
+// subscribeNotifications keeps the model's notifications in sync with the
+// server, until ctx is done.
+func subscribeNotifications(ctx context.Context, user external.UserSession, sendMsg func(Msg)) {
+	fut, rel := user.Notifications(ctx, func(p external.UserSession_notifications_Params) error {
+		return p.SetInto(collection.Pusher_ServerToClient(pusher[types.ID[external.Notification], external.Notification]{
+			sendMsg: sendMsg,
+			hooks:   notificationPusher{},
+		}))
+	})
+	// Releasing the results drops the subscription, so hold on to them:
+	defer rel()
+	if _, err := apierror.Check(fut.Struct()); err != nil {
+		println("notifications(): " + err.Error())
+		return
+	}
+	<-ctx.Done()
+}
+
 func (msg EditEmailLogin) Update(m *Model) Cmd {
 	m.LoginForm.EmailInput = msg.NewValue
 	return nil
@@ -246,6 +335,7 @@ func (msg EditEmailToken) Update(m *Model) Cmd {
 func (msg SubmitEmailLogin) Update(m *Model) Cmd {
 	api := m.API
 	address := m.LoginForm.EmailInput
+	locale := m.Locale
 	m.LoginForm.TokenSent = true
 	m.LoginForm.EmailInput = ""
 	return func(ctx context.Context, sendMsg func(Msg)) {
@@ -253,9 +343,12 @@ func (msg SubmitEmailLogin) Update(m *Model) Cmd {
 		defer rel()
 		sendFut, rel := authFut.Authenticator().
 			SendEmailAuthToken(ctx, func(p external.Authenticator_sendEmailAuthToken_Params) error {
-				return p.SetAddress(address)
+				if err := p.SetAddress(address); err != nil {
+					return err
+				}
+				return p.SetLocale(locale)
 			})
-		if _, err := sendFut.Struct(); err != nil {
+		if _, err := apierror.Check(sendFut.Struct()); err != nil {
 			sendMsg(NewError{Err: err})
 		}
 	}
@@ -282,7 +375,7 @@ func (msg NewAppPkgFile) Update(m *Model) Cmd {
 	return func(ctx context.Context, sendMsg func(Msg)) {
 		if !ok {
 			sendMsg(NewError{
-				Err: errors.New("No login session yet; can't install app"),
+				Err: apierror.New(apierror.CodeNotLoggedIn, "No login session yet; can't install app"),
 			})
 			return
 		}
@@ -310,16 +403,15 @@ func (msg NewAppPkgFile) Update(m *Model) Cmd {
 		defer rel()
 		wc := bytestream.ToWriteCloser(ctx, util.ByteStream(stream))
 		_, err = msg.Reader.WriteTo(wc)
-		if err != nil {
-			sendMsg(NewError{Err: err})
-			return
+		if err == nil {
+			err = wc.Close()
 		}
-		err = wc.Close()
-		if err != nil {
-			sendMsg(NewError{Err: err})
-			return
+		pkgRes, pkgErr := apierror.Check(pkgFut.Struct())
+		if err == nil || errors.As(pkgErr, new(*apierror.Error)) {
+			// If installation failed, e.g. because we're out of
+			// storage, getPackage says why, and writing fails too.
+			err = pkgErr
 		}
-		pkgRes, err := pkgFut.Struct()
 		if err != nil {
 			sendMsg(NewError{Err: err})
 			return
@@ -346,46 +438,214 @@ func (msg NewAppPkgFile) Update(m *Model) Cmd {
 	}
 }
 
-func (msg ShareGrain) Update(m *Model) Cmd {
-	// TODO: present a UI of some kind; right now we just fetch the token
-	// and then log it.
-	ctrl := m.Grains[msg.ID].Controller.AddRef()
-	return func(ctx context.Context, sendMsg func(Msg)) {
-		defer ctrl.Release()
-		fut, rel := ctrl.MakeSharingToken(
-			ctx,
-			func(p external.UiView_Controller_makeSharingToken_Params) error {
-				// TODO: fill in permissions and note.
-				return nil
-
-			},
-		)
-		defer rel()
-		res, err := fut.Struct()
+// HaveGrainTimeline delivers a grain's timeline, for its details panel.
+type HaveGrainTimeline struct {
+	GrainID  types.GrainID
+	Timeline []types.GrainEvent
+}
+
+func (msg HaveGrainTimeline) Update(m *Model) Cmd {
+	grain, ok := m.OpenGrains[msg.GrainID]
+	if !ok {
+		// Closed while loading.
+		return nil
+	}
+	grain.Timeline = maybe.New(msg.Timeline)
+	m.OpenGrains[msg.GrainID] = grain
+	return nil
+}
+
+// HaveGrainEmbeds delivers a grain's embeds, for its sharing dialog.
+type HaveGrainEmbeds struct {
+	GrainID types.GrainID
+	List    types.GrainEmbedList
+}
+
+func (msg HaveGrainEmbeds) Update(m *Model) Cmd {
+	grain, ok := m.OpenGrains[msg.GrainID]
+	if !ok {
+		// Closed while loading.
+		return nil
+	}
+	grain.Embeds = maybe.New(msg.List)
+	if len(grain.EmbedForm.Permissions) != len(msg.List.PermissionTitles) {
+		// Default to all permissions, as for the owner.
+		grain.EmbedForm.Permissions = make([]bool, len(msg.List.PermissionTitles))
+		for i := range grain.EmbedForm.Permissions {
+			grain.EmbedForm.Permissions[i] = true
+		}
+	}
+	m.OpenGrains[msg.GrainID] = grain
+	return nil
+}
+
+// EditEmbedOrigins, EditEmbedDays and EditEmbedNote change the inputs of a
+// grain's embed form.
+type EditEmbedOrigins struct {
+	GrainID  types.GrainID
+	NewValue string
+}
+
+type EditEmbedDays struct {
+	GrainID  types.GrainID
+	NewValue string
+}
+
+type EditEmbedNote struct {
+	GrainID  types.GrainID
+	NewValue string
+}
+
+// ToggleEmbedPermission toggles whether an embed created with a grain's
+// embed form will have the permission with the given index.
+type ToggleEmbedPermission struct {
+	GrainID types.GrainID
+	Index   int
+}
+
+func (msg EditEmbedOrigins) Update(m *Model) Cmd {
+	grain, ok := m.OpenGrains[msg.GrainID]
+	if !ok {
+		return nil
+	}
+	grain.EmbedForm.OriginsInput = msg.NewValue
+	m.OpenGrains[msg.GrainID] = grain
+	return nil
+}
+
+func (msg EditEmbedDays) Update(m *Model) Cmd {
+	grain, ok := m.OpenGrains[msg.GrainID]
+	if !ok {
+		return nil
+	}
+	grain.EmbedForm.DaysInput = msg.NewValue
+	m.OpenGrains[msg.GrainID] = grain
+	return nil
+}
+
+func (msg EditEmbedNote) Update(m *Model) Cmd {
+	grain, ok := m.OpenGrains[msg.GrainID]
+	if !ok {
+		return nil
+	}
+	grain.EmbedForm.NoteInput = msg.NewValue
+	m.OpenGrains[msg.GrainID] = grain
+	return nil
+}
+
+func (msg ToggleEmbedPermission) Update(m *Model) Cmd {
+	grain, ok := m.OpenGrains[msg.GrainID]
+	if !ok {
+		return nil
+	}
+	perms := append([]bool(nil), grain.EmbedForm.Permissions...)
+	if msg.Index < len(perms) {
+		perms[msg.Index] = !perms[msg.Index]
+	}
+	grain.EmbedForm.Permissions = perms
+	m.OpenGrains[msg.GrainID] = grain
+	return nil
+}
+
+// CreateGrainEmbed asks the server for a new embed, as described by the
+// grain's embed form.
+type CreateGrainEmbed struct {
+	GrainID types.GrainID
+}
+
+func (msg CreateGrainEmbed) Update(m *Model) Cmd {
+	grain, ok := m.OpenGrains[msg.GrainID]
+	if !ok {
+		return nil
+	}
+	form := grain.EmbedForm
+	req := types.NewGrainEmbed{
+		Permissions:    form.Permissions,
+		FrameAncestors: strings.Fields(form.OriginsInput),
+		Note:           form.NoteInput,
+	}
+	if form.DaysInput != "" {
+		days, err := strconv.Atoi(form.DaysInput)
 		if err != nil {
-			sendMsg(NewError{Err: err})
-			return
+			m.Errors = append(m.Errors, err)
+			return nil
 		}
-		token, err := res.Token()
+		req.Days = days
+	}
+	return func(ctx context.Context, send func(Msg)) {
+		var embed types.GrainEmbed
+		err := sendJSON(ctx, http.MethodPost, "/grain-embeds/"+string(msg.GrainID), "grain", req, &embed)
 		if err != nil {
-			sendMsg(NewError{Err: err})
+			send(NewError{Err: err})
 			return
 		}
-		sendMsg(HaveSharingToken{
-			GrainID: msg.ID,
-			Token:   token,
+		send(HaveGrainEmbed{
+			GrainID: msg.GrainID,
+			Embed:   embed,
 		})
 	}
 }
 
-type HaveSharingToken struct {
+// HaveGrainEmbed delivers an embed which was just created.
+type HaveGrainEmbed struct {
+	GrainID types.GrainID
+	Embed   types.GrainEmbed
+}
+
+func (msg HaveGrainEmbed) Update(m *Model) Cmd {
+	grain, ok := m.OpenGrains[msg.GrainID]
+	if !ok {
+		return nil
+	}
+	if list, ok := grain.Embeds.Get(); ok {
+		list.Embeds = append([]types.GrainEmbed{msg.Embed}, list.Embeds...)
+		grain.Embeds = maybe.New(list)
+	}
+	grain.EmbedForm.OriginsInput = ""
+	grain.EmbedForm.NoteInput = ""
+	m.OpenGrains[msg.GrainID] = grain
+	return nil
+}
+
+// RevokeGrainEmbed asks the server to delete an embed, so that sites using
+// it can no longer show the grain.
+type RevokeGrainEmbed struct {
+	GrainID types.GrainID
+	EmbedID string
+}
+
+func (msg RevokeGrainEmbed) Update(m *Model) Cmd {
+	return func(ctx context.Context, send func(Msg)) {
+		path := "/grain-embeds/" + string(msg.GrainID) + "/" + msg.EmbedID
+		if err := sendJSON(ctx, http.MethodDelete, path, "embed", nil, nil); err != nil {
+			send(NewError{Err: err})
+			return
+		}
+		send(GrainEmbedRevoked(msg))
+	}
+}
+
+// GrainEmbedRevoked reports that the server deleted an embed.
+type GrainEmbedRevoked struct {
 	GrainID types.GrainID
-	Token   string
+	EmbedID string
 }
 
-func (msg HaveSharingToken) Update(m *Model) Cmd {
-	grain := m.OpenGrains[msg.GrainID]
-	grain.SharingToken = msg.Token
+func (msg GrainEmbedRevoked) Update(m *Model) Cmd {
+	grain, ok := m.OpenGrains[msg.GrainID]
+	if !ok {
+		return nil
+	}
+	if list, ok := grain.Embeds.Get(); ok {
+		var remaining []types.GrainEmbed
+		for _, e := range list.Embeds {
+			if e.ID != msg.EmbedID {
+				remaining = append(remaining, e)
+			}
+		}
+		list.Embeds = remaining
+		grain.Embeds = maybe.New(list)
+	}
 	m.OpenGrains[msg.GrainID] = grain
 	return nil
 }
@@ -397,14 +657,93 @@ func (msg Navigate) Update(m *Model) Cmd {
 		m.CurrentFocus = InitialFocus
 	} else if loc == "apps" {
 		m.CurrentFocus = FocusApps
+		return fetchGrainUpgrades
 	} else if loc == "grains" {
 		m.CurrentFocus = FocusGrainList
+	} else if loc == "notifications" {
+		m.CurrentFocus = FocusNotifications
+	} else if loc == "sessions" {
+		m.CurrentFocus = FocusSessions
+		return m.fetchActiveSessions()
+	} else if loc == "api-keys" {
+		m.CurrentFocus = FocusAPIKeys
+		m.NewAPIKey = ""
+		return fetchAPIKeys
 	} else if eatPrefix(&loc, "grain/") {
 		m.FocusGrain(types.GrainID(strings.Split(loc, "/")[0]))
 	} else if eatPrefix(&loc, "share-grain/") {
 		grainID := types.GrainID(strings.Split(loc, "/")[0])
 		m.FocusGrain(grainID)
 		m.CurrentFocus = FocusShareGrain
+		return func(ctx context.Context, send func(Msg)) {
+			var links types.ShareLinkList
+			err := fetchJSON(ctx, "/grain-share-links/"+string(grainID), "grain", &links)
+			if err != nil {
+				send(NewError{Err: err})
+				return
+			}
+			send(HaveGrainShareLinks{
+				GrainID: grainID,
+				List:    links,
+			})
+			var list types.GrainEmbedList
+			err = fetchJSON(ctx, "/grain-embeds/"+string(grainID), "grain", &list)
+			if err != nil {
+				send(NewError{Err: err})
+				return
+			}
+			send(HaveGrainEmbeds{
+				GrainID: grainID,
+				List:    list,
+			})
+			var tokens []types.APIToken
+			err = fetchJSON(ctx, "/grain-api-tokens/"+string(grainID), "grain", &tokens)
+			if err != nil {
+				send(NewError{Err: err})
+				return
+			}
+			send(HaveGrainAPITokens{
+				GrainID: grainID,
+				Tokens:  tokens,
+			})
+			// Only the owner may see the members, and who shared
+			// with whom.
+			var members []types.GrainMember
+			if fetchJSON(ctx, "/grain-members/"+string(grainID), "grain", &members) != nil {
+				return
+			}
+			var graph types.ShareGraph
+			err = fetchJSON(ctx, "/grain-share-graph/"+string(grainID), "grain", &graph)
+			if err != nil {
+				send(NewError{Err: err})
+				return
+			}
+			send(HaveGrainMembers{
+				GrainID: grainID,
+				Members: members,
+				Graph:   graph,
+			})
+		}
+	} else if eatPrefix(&loc, "grain-details/") {
+		grainID := types.GrainID(strings.Split(loc, "/")[0])
+		m.FocusGrain(grainID)
+		m.CurrentFocus = FocusGrainDetails
+		grain := m.OpenGrains[grainID]
+		grain.TitleInput = m.Grains[grainID].Title
+		m.OpenGrains[grainID] = grain
+		return func(ctx context.Context, send func(Msg)) {
+			var timeline []types.GrainEvent
+			err := fetchJSON(ctx, "/grain-timeline/"+string(grainID), "grain", &timeline)
+			if err != nil {
+				send(NewError{Err: err})
+				return
+			}
+			send(HaveGrainTimeline{
+				GrainID:  grainID,
+				Timeline: timeline,
+			})
+			fetchGrainUpgrades(ctx, send)
+		}
 	} else if eatPrefix(&loc, "shared/") {
 		m.CurrentFocus = FocusLoadShared
 		api := m.API.AddRef()
@@ -427,6 +766,12 @@ func (msg Navigate) Update(m *Model) Cmd {
 				getFut, rel := util.Getter(restoreFut.Cap()).Get(ctx, nil)
 				defer rel()
 				getterValue, err := getFut.Value().Struct()
+				if err != nil {
+					// If restore failed, e.g. because the link
+					// expired, it says why, and so fails Get.
+					_, restoreErr := apierror.Check(restoreFut.Struct())
+					throw(restoreErr)
+				}
 				throw(err)
 				kv := util.KeyValue(getterValue)
 				key, err := kv.Key()
@@ -445,7 +790,7 @@ func (msg Navigate) Update(m *Model) Cmd {
 					})
 					defer rel()
 					go func() {
-						if _, err = attachFut.Struct(); err == nil {
+						if _, err = apierror.Check(attachFut.Struct()); err == nil {
 							return
 						}
 						send(NewError{
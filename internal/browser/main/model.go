@@ -3,6 +3,7 @@ package browsermain
 import (
 	"net/url"
 	"syscall/js"
+	"time"
 
 	"sandstorm.org/go/tempest/capnp/external"
 	"sandstorm.org/go/tempest/internal/browser/intl"
@@ -13,7 +14,10 @@ import (
 )
 
 type Model struct {
-	L10N intl.L10N
+	// The shell's locale, as a BCP 47 language tag, and its localization.
+	// Grains are told the locale too, so that they can match it.
+	Locale string
+	L10N   intl.L10N
 
 	ServerAddr   ServerAddr
 	CurrentFocus Focus
@@ -27,6 +31,30 @@ type Model struct {
 	OpenGrains map[types.GrainID]OpenGrain
 	Packages   map[types.ID[external.Package]]external.Package
 
+	// The user's notifications of activity in grains.
+	Notifications map[types.ID[external.Notification]]external.Notification
+
+	// Newer versions of their apps which the user's grains may be
+	// upgraded to, fetched when the apps page or a grain's details panel
+	// is opened, and the grains being upgraded.
+	GrainUpgrades map[types.GrainID]types.GrainUpgrade
+	Upgrading     map[types.GrainID]bool
+
+	// The user's login sessions, fetched when the sessions page is
+	// opened.
+	ActiveSessions maybe.Maybe[[]ActiveSession]
+
+	// The user's API keys, fetched when the API keys page is opened, the
+	// form for making them, and the key last made, which is only shown
+	// until the page is left.
+	APIKeys    maybe.Maybe[[]types.APIKey]
+	APIKeyForm APIKeyForm
+	NewAPIKey  string
+
+	// When the user's account expires, if it is a demo account; the zero
+	// time otherwise.
+	DemoExpires time.Time
+
 	// Keeps track of the order we need to display grain iframes in.
 	// Grain iframes must never change order or be detached from the
 	// DOM, or they will reload the page within them, losing state.
@@ -39,6 +67,10 @@ type Model struct {
 	LoginSessions maybe.Maybe[orerr.OrErr[Sessions]]
 
 	LoginForm LoginForm
+
+	// Set while a grain is waiting for the user to answer its powerbox
+	// request.
+	Powerbox maybe.Maybe[PowerboxPicker]
 }
 
 type Sessions struct {
@@ -58,7 +90,11 @@ const (
 	FocusApps
 	FocusOpenGrain
 	FocusShareGrain
+	FocusGrainDetails
 	FocusLoadShared
+	FocusNotifications
+	FocusSessions
+	FocusAPIKeys
 
 	InitialFocus = FocusGrainList
 )
@@ -68,25 +104,76 @@ type Grain struct {
 	SessionToken string
 	Subdomain    string
 	Controller   external.UiView_Controller
+	Trashed      bool
+
+	// Disk space used by the grain, in bytes, as last measured; zero if
+	// the user doesn't own it.
+	StorageBytes int64
 }
 
 type OpenGrain struct {
 	DomIndex     int
 	SharingToken string
+
+	// The contents of the title input in the details panel.
+	TitleInput string
+
+	// The grain's timeline, most recent event first, fetched when the
+	// details panel is opened.
+	Timeline maybe.Maybe[[]types.GrainEvent]
+
+	// The grain's embeds, fetched when the sharing dialog is opened.
+	Embeds    maybe.Maybe[types.GrainEmbedList]
+	EmbedForm EmbedForm
+
+	// The grain's API tokens which the user may see, fetched when the
+	// sharing dialog is opened.
+	APITokens maybe.Maybe[[]types.APIToken]
+
+	// The grain's share links which the user may see, and if they own
+	// it, its members, fetched when the sharing dialog is opened.
+	ShareLinks    maybe.Maybe[types.ShareLinkList]
+	ShareLinkForm ShareLinkForm
+	Members       maybe.Maybe[[]types.GrainMember]
+	ShareGraph    maybe.Maybe[types.ShareGraph]
+}
+
+// Model for the sharing dialog's form for making share links.
+type ShareLinkForm struct {
+	RoleID    int    // Indexes ShareLinkList.RoleTitles
+	DaysInput string // How long the link lasts, in days; empty for ever
+	NoteInput string
+	SingleUse bool
+}
+
+// Model for the sharing dialog's form for embedding a grain in other sites.
+type EmbedForm struct {
+	OriginsInput string // Space-separated origins allowed to embed the grain
+	DaysInput    string // How long the embed lasts, in days
+	NoteInput    string
+	Permissions  []bool // Indexed like GrainEmbedList.PermissionTitles
 }
 
 func initModel(api external.ExternalApi) Model {
 	loc := js.Global().Get("window").Get("location")
+	locale := negotiateLocale()
+	js.Global().Get("document").Get("documentElement").Set("lang", locale)
+	setLocaleCookie(locale)
 	return Model{
+		Locale:       locale,
+		L10N:         intl.Localizations[locale],
 		CurrentFocus: InitialFocus,
 		ServerAddr: ServerAddr{
 			TLS:  loc.Get("protocol").String() == "https:",
 			Host: loc.Get("host").String(),
 		},
-		Grains:     make(map[types.GrainID]Grain),
-		OpenGrains: make(map[types.GrainID]OpenGrain),
-		Packages:   make(map[types.ID[external.Package]]external.Package),
-		API:        api,
+		Grains:        make(map[types.GrainID]Grain),
+		OpenGrains:    make(map[types.GrainID]OpenGrain),
+		Packages:      make(map[types.ID[external.Package]]external.Package),
+		Notifications: make(map[types.ID[external.Notification]]external.Notification),
+		GrainUpgrades: make(map[types.GrainID]types.GrainUpgrade),
+		Upgrading:     make(map[types.GrainID]bool),
+		API:           api,
 	}
 }
 
@@ -0,0 +1,33 @@
+package browsermain
+
+import (
+	"sandstorm.org/go/tempest/capnp/external"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"zenhack.net/go/util/exn"
+)
+
+var _ pusherHooks[types.ID[external.Notification], external.Notification] = notificationPusher{}
+
+type notificationPusher struct {
+}
+
+func (notificationPusher) Upsert(id types.ID[external.Notification], n external.Notification) (Msg, error) {
+	return exn.Try(func(throw exn.Thrower) Msg {
+		// Copy over to a new message to avoid early release:
+		dst, err := cloneStruct(n)
+		throw(err)
+
+		return UpsertNotification{
+			ID:           id,
+			Notification: dst,
+		}
+	})
+}
+
+func (notificationPusher) Remove(id types.ID[external.Notification]) Msg {
+	return RemoveNotification{ID: id}
+}
+
+func (notificationPusher) Clear() Msg {
+	return ClearNotifications{}
+}
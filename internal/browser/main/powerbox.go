@@ -0,0 +1,424 @@
+package browsermain
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"syscall/js"
+
+	"sandstorm.org/go/tempest/internal/common/types"
+	"zenhack.net/go/tea"
+	"zenhack.net/go/tea/vdom"
+	"zenhack.net/go/tea/vdom/builder"
+	"zenhack.net/go/tea/vdom/events"
+	"zenhack.net/go/util/maybe"
+)
+
+// Model for the dialog in which the user chooses a capability to fulfill a
+// grain's powerbox request. See the server's powerbox.go for the protocol.
+type PowerboxPicker struct {
+	GrainID types.GrainID // The grain making the request.
+
+	// The request as recorded by the server, with the options to choose
+	// from.
+	Request maybe.Maybe[types.PowerboxRequest]
+
+	// The form for granting an HTTP API, if the request asks for one: its
+	// URL, which starts as the one the grain suggested, and the credential
+	// to send with requests, if any.
+	HTTPAPIURLInput      string
+	HTTPAPIUsernameInput string
+	HTTPAPISecretInput   string
+
+	reply grainReply
+}
+
+// grainReply is where to send the answer to a grain's request through the
+// postMessage API, such as a powerbox request.
+type grainReply struct {
+	window js.Value // The requesting grain's window.
+	origin string   // Its origin, so only it can read the reply.
+	rpcID  js.Value // Echoed back, as the postMessage API requires.
+}
+
+// send posts the answer to the grain's window. fields is the reply's content,
+// e.g. the token or an error.
+func (r grainReply) send(fields map[string]any) {
+	fields["rpcId"] = r.rpcID
+	r.window.Call("postMessage", fields, r.origin)
+}
+
+// PowerboxRequested is sent when a grain makes a powerbox request through
+// the postMessage API. We don't send this one explicitly from UI code;
+// instead main listens for "message" events, and sends this for those which
+// are powerbox requests.
+type PowerboxRequested struct {
+	Query     []string
+	SaveLabel string
+	reply     grainReply
+}
+
+// powerboxRequestFromEvent decodes a "message" event, if it is a powerbox
+// request.
+func powerboxRequestFromEvent(event js.Value) (PowerboxRequested, bool) {
+	data := event.Get("data")
+	if data.Type() != js.TypeObject {
+		return PowerboxRequested{}, false
+	}
+	req := data.Get("powerboxRequest")
+	if req.Type() != js.TypeObject {
+		return PowerboxRequested{}, false
+	}
+	msg := PowerboxRequested{
+		reply: grainReply{
+			window: event.Get("source"),
+			origin: event.Get("origin").String(),
+			rpcID:  req.Get("rpcId"),
+		},
+	}
+	if query := req.Get("query"); query.Type() == js.TypeObject {
+		for i := 0; i < query.Length(); i++ {
+			msg.Query = append(msg.Query, query.Index(i).String())
+		}
+	}
+	if label := req.Get("saveLabel"); label.Type() == js.TypeObject {
+		if text := label.Get("defaultText"); text.Type() == js.TypeString {
+			msg.SaveLabel = text.String()
+		}
+	}
+	return msg, true
+}
+
+// grainByOrigin returns the ID of the open grain whose iframe has the given
+// origin. Only grains we have open may make requests through the postMessage
+// API, and we identify them by origin, since each grain's iframe has its own.
+func (m Model) grainByOrigin(origin string) (types.GrainID, bool) {
+	for id := range m.OpenGrains {
+		u := m.ServerAddr.Subdomain("ui-" + m.Grains[id].Subdomain)
+		if u.Scheme+"://"+u.Host == origin {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+func (msg PowerboxRequested) Update(m *Model) Cmd {
+	grainID, ok := m.grainByOrigin(msg.reply.origin)
+	if !ok {
+		return nil
+	}
+	// One request at a time:
+	cancelOld := CancelPowerboxRequest{}.Update(m)
+	m.Powerbox = maybe.New(PowerboxPicker{
+		GrainID: grainID,
+		reply:   msg.reply,
+	})
+	req := types.NewPowerboxRequest{
+		GrainID:   grainID,
+		Query:     msg.Query,
+		SaveLabel: msg.SaveLabel,
+	}
+	return func(ctx context.Context, send func(Msg)) {
+		if cancelOld != nil {
+			cancelOld(ctx, send)
+		}
+		var resp types.PowerboxRequest
+		err := sendJSON(ctx, http.MethodPost, "/powerbox/requests", "grain", req, &resp)
+		if err != nil {
+			msg.reply.send(map[string]any{"error": err.Error()})
+			send(ClosePowerbox{})
+			send(NewError{Err: err})
+			return
+		}
+		send(HavePowerboxRequest{Request: resp})
+	}
+}
+
+// HavePowerboxRequest delivers the server's record of the open powerbox
+// request.
+type HavePowerboxRequest struct {
+	Request types.PowerboxRequest
+}
+
+func (msg HavePowerboxRequest) Update(m *Model) Cmd {
+	picker, ok := m.Powerbox.Get()
+	if !ok {
+		return nil
+	}
+	picker.Request = maybe.New(msg.Request)
+	for _, option := range msg.Request.Options {
+		if option.HTTPAPI != "" {
+			picker.HTTPAPIURLInput = option.HTTPAPI
+			break
+		}
+	}
+	m.Powerbox = maybe.New(picker)
+	return nil
+}
+
+// EditPowerboxHTTPAPIURL changes the URL in the form for granting an HTTP
+// API.
+type EditPowerboxHTTPAPIURL struct {
+	NewValue string
+}
+
+func (msg EditPowerboxHTTPAPIURL) Update(m *Model) Cmd {
+	if picker, ok := m.Powerbox.Get(); ok {
+		picker.HTTPAPIURLInput = msg.NewValue
+		m.Powerbox = maybe.New(picker)
+	}
+	return nil
+}
+
+// EditPowerboxHTTPAPIUsername changes the username in the form for granting
+// an HTTP API.
+type EditPowerboxHTTPAPIUsername struct {
+	NewValue string
+}
+
+func (msg EditPowerboxHTTPAPIUsername) Update(m *Model) Cmd {
+	if picker, ok := m.Powerbox.Get(); ok {
+		picker.HTTPAPIUsernameInput = msg.NewValue
+		m.Powerbox = maybe.New(picker)
+	}
+	return nil
+}
+
+// EditPowerboxHTTPAPISecret changes the password or token in the form for
+// granting an HTTP API.
+type EditPowerboxHTTPAPISecret struct {
+	NewValue string
+}
+
+func (msg EditPowerboxHTTPAPISecret) Update(m *Model) Cmd {
+	if picker, ok := m.Powerbox.Get(); ok {
+		picker.HTTPAPISecretInput = msg.NewValue
+		m.Powerbox = maybe.New(picker)
+	}
+	return nil
+}
+
+// httpAuthorization returns the Authorization header for the credential in
+// the form for granting an HTTP API: HTTP Basic Auth if there is a username,
+// and otherwise the secret as a bearer token, if there is one.
+func (picker PowerboxPicker) httpAuthorization() string {
+	if picker.HTTPAPIUsernameInput != "" {
+		return "Basic " + base64.StdEncoding.EncodeToString(
+			[]byte(picker.HTTPAPIUsernameInput+":"+picker.HTTPAPISecretInput))
+	}
+	if picker.HTTPAPISecretInput != "" {
+		return "Bearer " + picker.HTTPAPISecretInput
+	}
+	return ""
+}
+
+// ChoosePowerboxOption fulfills the open powerbox request with the chosen
+// grain, user, access to the network, permission to listen on it, the
+// thumbnail service or the TURN relay, or if HTTPAPI is true, the HTTP API in
+// the picker's form.
+type ChoosePowerboxOption struct {
+	GrainID     types.GrainID
+	Network     bool
+	IPInterface bool
+	Thumbnailer bool
+	TURN        bool
+	AccountID   types.AccountID
+	HTTPAPI     bool
+}
+
+func (msg ChoosePowerboxOption) Update(m *Model) Cmd {
+	picker, ok := m.Powerbox.Get()
+	if !ok {
+		return nil
+	}
+	req, ok := picker.Request.Get()
+	if !ok {
+		return nil
+	}
+	m.Powerbox = maybe.Maybe[PowerboxPicker]{}
+	choice := types.PowerboxChoice{
+		GrainID:     msg.GrainID,
+		Network:     msg.Network,
+		IPInterface: msg.IPInterface,
+		Thumbnailer: msg.Thumbnailer,
+		TURN:        msg.TURN,
+		AccountID:   msg.AccountID,
+	}
+	if msg.HTTPAPI {
+		choice.HTTPAPI = picker.HTTPAPIURLInput
+		choice.HTTPAuthorization = picker.httpAuthorization()
+	}
+	return func(ctx context.Context, send func(Msg)) {
+		var claim types.PowerboxClaim
+		err := sendJSON(ctx, http.MethodPost, "/powerbox/requests/"+req.ID, "powerbox request",
+			choice, &claim)
+		if err != nil {
+			picker.reply.send(map[string]any{"error": err.Error()})
+			send(NewError{Err: err})
+			return
+		}
+		picker.reply.send(map[string]any{"token": claim.Token})
+	}
+}
+
+// CancelPowerboxRequest closes the powerbox picker without choosing anything.
+type CancelPowerboxRequest struct{}
+
+func (CancelPowerboxRequest) Update(m *Model) Cmd {
+	picker, ok := m.Powerbox.Get()
+	if !ok {
+		return nil
+	}
+	m.Powerbox = maybe.Maybe[PowerboxPicker]{}
+	picker.reply.send(map[string]any{"error": "User canceled request"})
+	req, ok := picker.Request.Get()
+	if !ok {
+		return nil
+	}
+	return func(ctx context.Context, send func(Msg)) {
+		// Not a problem if this fails; the request will expire.
+		sendJSON(ctx, http.MethodDelete, "/powerbox/requests/"+req.ID, "powerbox request", nil, nil)
+	}
+}
+
+// ClosePowerbox closes the powerbox picker, once the request has been
+// answered.
+type ClosePowerbox struct{}
+
+func (ClosePowerbox) Update(m *Model) Cmd {
+	m.Powerbox = maybe.Maybe[PowerboxPicker]{}
+	return nil
+}
+
+// viewPowerboxPicker renders the dialog for choosing a grain or user to
+// fulfill a powerbox request.
+func (m Model) viewPowerboxPicker(ms tea.MessageSender[Model], picker PowerboxPicker) vdom.VNode {
+	closeBtn := h("button",
+		a{"class": "close-button"},
+		e{"click": ms.Event(CancelPowerboxRequest{})},
+		t(m.L10N, "cancel"),
+	)
+	kids := []vdom.VNode{
+		h("h2", nil, nil,
+			t(m.L10N, "%0 is asking you to choose something to share with it", m.Grains[picker.GrainID].Title),
+		),
+	}
+	req, ok := picker.Request.Get()
+	if !ok {
+		kids = append(kids, t(m.L10N, "Loading..."))
+	} else if len(req.Options) == 0 {
+		kids = append(kids, h("p", nil, nil,
+			t(m.L10N, "You have nothing which matches this request."),
+		))
+	} else {
+		var (
+			items   []vdom.VNode
+			httpAPI bool
+		)
+		for _, option := range req.Options {
+			if option.HTTPAPI != "" {
+				httpAPI = true
+				continue
+			}
+			title := builder.T(option.Title)
+			if option.Network {
+				title = t(m.L10N, "Access to the network")
+			} else if option.IPInterface {
+				title = t(m.L10N, "Accepting connections from the network")
+			} else if option.Thumbnailer {
+				title = t(m.L10N, "Making thumbnails of images and documents")
+			} else if option.TURN {
+				title = t(m.L10N, "Relaying video and voice calls")
+			} else if option.AccountID != "" {
+				name := title
+				if option.Title == "" {
+					name = t(m.L10N, "Unknown user")
+				}
+				title = h("span", a{"class": "powerbox-picker__user"}, nil,
+					h("img", a{
+						"class": "powerbox-picker__avatar",
+						"src":   option.Picture,
+						"alt":   "",
+					}, nil),
+					name,
+				)
+			}
+			items = append(items, h("li", nil, nil,
+				h("button",
+					a{"class": "powerbox-picker__option"},
+					e{"click": ms.Event(ChoosePowerboxOption{
+						GrainID:     option.GrainID,
+						Network:     option.Network,
+						IPInterface: option.IPInterface,
+						Thumbnailer: option.Thumbnailer,
+						TURN:        option.TURN,
+						AccountID:   option.AccountID,
+					})},
+					title,
+				),
+			))
+		}
+		if len(items) > 0 {
+			kids = append(kids, h("ul", a{"class": "powerbox-picker__options"}, nil, items...))
+		}
+		if httpAPI {
+			kids = append(kids, m.viewPowerboxHTTPAPIForm(ms, picker))
+		}
+	}
+	return viewModal(h("div", a{"class": "powerbox-picker"}, nil, kids...), closeBtn)
+}
+
+// viewPowerboxHTTPAPIForm renders the form for granting the requesting grain
+// access to an HTTP API.
+func (m Model) viewPowerboxHTTPAPIForm(ms tea.MessageSender[Model], picker PowerboxPicker) vdom.VNode {
+	submitAttrs := a{"type": "submit"}
+	if picker.HTTPAPIURLInput == "" {
+		submitAttrs["disabled"] = "disabled"
+	}
+	return h("section", a{"class": "powerbox-picker__http-api"}, nil,
+		h("p", nil, nil,
+			t(m.L10N, "It asks to use a service on the web. Tempest will make requests to it on the grain's behalf, "+
+				"without showing it the credentials you give."),
+		),
+		h("label", a{"for": "powerbox-http-api-url"}, nil, t(m.L10N, "URL")),
+		h("input", a{
+			"name":  "powerbox-http-api-url",
+			"type":  "url",
+			"value": picker.HTTPAPIURLInput,
+		}, e{
+			"input": events.OnInput(func(value string) {
+				ms.Send(EditPowerboxHTTPAPIURL{NewValue: value})
+			}),
+		}),
+		h("label", a{"for": "powerbox-http-api-username"}, nil,
+			t(m.L10N, "Username, if the service asks for one"),
+		),
+		h("input", a{
+			"name":         "powerbox-http-api-username",
+			"autocomplete": "off",
+			"value":        picker.HTTPAPIUsernameInput,
+		}, e{
+			"input": events.OnInput(func(value string) {
+				ms.Send(EditPowerboxHTTPAPIUsername{NewValue: value})
+			}),
+		}),
+		h("label", a{"for": "powerbox-http-api-secret"}, nil,
+			t(m.L10N, "Password or access token, if the service needs one"),
+		),
+		h("input", a{
+			"name":         "powerbox-http-api-secret",
+			"type":         "password",
+			"autocomplete": "off",
+			"value":        picker.HTTPAPISecretInput,
+		}, e{
+			"input": events.OnInput(func(value string) {
+				ms.Send(EditPowerboxHTTPAPISecret{NewValue: value})
+			}),
+		}),
+		h("button",
+			submitAttrs,
+			e{"click": ms.Event(ChoosePowerboxOption{HTTPAPI: true})},
+			t(m.L10N, "Allow"),
+		),
+	)
+}
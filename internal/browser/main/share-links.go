@@ -0,0 +1,504 @@
+package browsermain
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"sandstorm.org/go/tempest/internal/common/types"
+	"zenhack.net/go/tea"
+	"zenhack.net/go/tea/vdom"
+	"zenhack.net/go/tea/vdom/builder"
+	"zenhack.net/go/tea/vdom/events"
+	"zenhack.net/go/util/maybe"
+)
+
+// HaveGrainShareLinks delivers the share links for a grain which the user
+// may see, and the roles they may grant, for its sharing dialog.
+type HaveGrainShareLinks struct {
+	GrainID types.GrainID
+	List    types.ShareLinkList
+}
+
+func (msg HaveGrainShareLinks) Update(m *Model) Cmd {
+	grain, ok := m.OpenGrains[msg.GrainID]
+	if !ok {
+		// Closed while loading.
+		return nil
+	}
+	grain.ShareLinks = maybe.New(msg.List)
+	if grain.ShareLinkForm.RoleID >= len(msg.List.RoleTitles) {
+		grain.ShareLinkForm.RoleID = 0
+	}
+	m.OpenGrains[msg.GrainID] = grain
+	return nil
+}
+
+// EditShareLinkRole, EditShareLinkDays, EditShareLinkNote and
+// ToggleShareLinkSingleUse change the inputs of a grain's share link form.
+type EditShareLinkRole struct {
+	GrainID types.GrainID
+	RoleID  int
+}
+
+type EditShareLinkDays struct {
+	GrainID  types.GrainID
+	NewValue string
+}
+
+type EditShareLinkNote struct {
+	GrainID  types.GrainID
+	NewValue string
+}
+
+type ToggleShareLinkSingleUse struct {
+	GrainID types.GrainID
+}
+
+func (msg EditShareLinkRole) Update(m *Model) Cmd {
+	grain, ok := m.OpenGrains[msg.GrainID]
+	if !ok {
+		return nil
+	}
+	grain.ShareLinkForm.RoleID = msg.RoleID
+	m.OpenGrains[msg.GrainID] = grain
+	return nil
+}
+
+func (msg EditShareLinkDays) Update(m *Model) Cmd {
+	grain, ok := m.OpenGrains[msg.GrainID]
+	if !ok {
+		return nil
+	}
+	grain.ShareLinkForm.DaysInput = msg.NewValue
+	m.OpenGrains[msg.GrainID] = grain
+	return nil
+}
+
+func (msg EditShareLinkNote) Update(m *Model) Cmd {
+	grain, ok := m.OpenGrains[msg.GrainID]
+	if !ok {
+		return nil
+	}
+	grain.ShareLinkForm.NoteInput = msg.NewValue
+	m.OpenGrains[msg.GrainID] = grain
+	return nil
+}
+
+func (msg ToggleShareLinkSingleUse) Update(m *Model) Cmd {
+	grain, ok := m.OpenGrains[msg.GrainID]
+	if !ok {
+		return nil
+	}
+	grain.ShareLinkForm.SingleUse = !grain.ShareLinkForm.SingleUse
+	m.OpenGrains[msg.GrainID] = grain
+	return nil
+}
+
+// ShareGrain asks the server for a new share link, as described by the
+// grain's share link form.
+type ShareGrain struct {
+	ID types.GrainID
+}
+
+func (msg ShareGrain) Update(m *Model) Cmd {
+	grain, ok := m.OpenGrains[msg.ID]
+	if !ok {
+		return nil
+	}
+	form := grain.ShareLinkForm
+	req := types.NewShareLink{
+		RoleID:    form.RoleID,
+		Note:      form.NoteInput,
+		SingleUse: form.SingleUse,
+	}
+	if form.DaysInput != "" {
+		days, err := strconv.Atoi(form.DaysInput)
+		if err != nil {
+			m.Errors = append(m.Errors, err)
+			return nil
+		}
+		req.Days = days
+	}
+	return func(ctx context.Context, send func(Msg)) {
+		var offer types.ShareLinkOffer
+		err := sendJSON(ctx, http.MethodPost, "/grain-share-links/"+string(msg.ID), "grain", req, &offer)
+		if err != nil {
+			send(NewError{Err: err})
+			return
+		}
+		send(HaveSharingToken{
+			GrainID: msg.ID,
+			Offer:   offer,
+		})
+	}
+}
+
+// HaveSharingToken delivers a share link which was just made, with the
+// token which goes in its URL; the server doesn't keep the token, so this
+// is the only time the user may copy it.
+type HaveSharingToken struct {
+	GrainID types.GrainID
+	Offer   types.ShareLinkOffer
+}
+
+func (msg HaveSharingToken) Update(m *Model) Cmd {
+	grain, ok := m.OpenGrains[msg.GrainID]
+	if !ok {
+		return nil
+	}
+	grain.SharingToken = msg.Offer.Token
+	grain.ShareLinkForm = ShareLinkForm{}
+	// If the list hasn't been fetched, it will include the link when it
+	// is.
+	if list, ok := grain.ShareLinks.Get(); ok {
+		list.Links = append(list.Links, msg.Offer.Link)
+		grain.ShareLinks = maybe.New(list)
+	}
+	m.OpenGrains[msg.GrainID] = grain
+	return nil
+}
+
+// RevokeShareLink asks the server to revoke a share link, so that it can no
+// longer be opened. Users who already opened it keep their access.
+type RevokeShareLink struct {
+	GrainID types.GrainID
+	LinkID  string
+}
+
+func (msg RevokeShareLink) Update(m *Model) Cmd {
+	return func(ctx context.Context, send func(Msg)) {
+		path := "/grain-share-links/" + string(msg.GrainID) + "/" + msg.LinkID
+		if err := sendJSON(ctx, http.MethodDelete, path, "share link", nil, nil); err != nil {
+			send(NewError{Err: err})
+			return
+		}
+		send(ShareLinkRevoked(msg))
+	}
+}
+
+// ShareLinkRevoked reports that the server revoked a share link.
+type ShareLinkRevoked struct {
+	GrainID types.GrainID
+	LinkID  string
+}
+
+func (msg ShareLinkRevoked) Update(m *Model) Cmd {
+	grain, ok := m.OpenGrains[msg.GrainID]
+	if !ok {
+		return nil
+	}
+	if list, ok := grain.ShareLinks.Get(); ok {
+		var remaining []types.ShareLink
+		for _, l := range list.Links {
+			if l.ID != msg.LinkID {
+				remaining = append(remaining, l)
+			}
+		}
+		list.Links = remaining
+		grain.ShareLinks = maybe.New(list)
+	}
+	m.OpenGrains[msg.GrainID] = grain
+	return nil
+}
+
+// HaveGrainMembers delivers the members of a grain, and its share graph, for
+// its sharing dialog. Only its owner may see them.
+type HaveGrainMembers struct {
+	GrainID types.GrainID
+	Members []types.GrainMember
+	Graph   types.ShareGraph
+}
+
+func (msg HaveGrainMembers) Update(m *Model) Cmd {
+	grain, ok := m.OpenGrains[msg.GrainID]
+	if !ok {
+		return nil
+	}
+	grain.Members = maybe.New(msg.Members)
+	grain.ShareGraph = maybe.New(msg.Graph)
+	m.OpenGrains[msg.GrainID] = grain
+	return nil
+}
+
+// RemoveGrainMember asks the server to revoke a member's access to a grain,
+// and that of those who had it only through them.
+type RemoveGrainMember struct {
+	GrainID   types.GrainID
+	AccountID types.AccountID
+}
+
+func (msg RemoveGrainMember) Update(m *Model) Cmd {
+	return func(ctx context.Context, send func(Msg)) {
+		path := "/grain-members/" + string(msg.GrainID) + "/" + string(msg.AccountID)
+		var revoked []types.AccountID
+		if err := sendJSON(ctx, http.MethodDelete, path, "member", nil, &revoked); err != nil {
+			send(NewError{Err: err})
+			return
+		}
+		send(GrainMembersRemoved{
+			GrainID:    msg.GrainID,
+			AccountIDs: revoked,
+		})
+		// Others may have lost permissions and shares, and the
+		// revoked users' links were revoked.
+		var links types.ShareLinkList
+		err := fetchJSON(ctx, "/grain-share-links/"+string(msg.GrainID), "grain", &links)
+		if err != nil {
+			send(NewError{Err: err})
+			return
+		}
+		send(HaveGrainShareLinks{
+			GrainID: msg.GrainID,
+			List:    links,
+		})
+		var graph types.ShareGraph
+		err = fetchJSON(ctx, "/grain-share-graph/"+string(msg.GrainID), "grain", &graph)
+		if err != nil {
+			send(NewError{Err: err})
+			return
+		}
+		var members []types.GrainMember
+		err = fetchJSON(ctx, "/grain-members/"+string(msg.GrainID), "grain", &members)
+		if err != nil {
+			send(NewError{Err: err})
+			return
+		}
+		send(HaveGrainMembers{
+			GrainID: msg.GrainID,
+			Members: members,
+			Graph:   graph,
+		})
+	}
+}
+
+// GrainMembersRemoved reports that the server revoked members' access.
+type GrainMembersRemoved struct {
+	GrainID    types.GrainID
+	AccountIDs []types.AccountID
+}
+
+func (msg GrainMembersRemoved) Update(m *Model) Cmd {
+	grain, ok := m.OpenGrains[msg.GrainID]
+	if !ok {
+		return nil
+	}
+	if members, ok := grain.Members.Get(); ok {
+		var remaining []types.GrainMember
+		for _, member := range members {
+			if !slices.Contains(msg.AccountIDs, member.AccountID) {
+				remaining = append(remaining, member)
+			}
+		}
+		grain.Members = maybe.New(remaining)
+	}
+	m.OpenGrains[msg.GrainID] = grain
+	return nil
+}
+
+// viewGrainShareLinks renders the part of the sharing dialog for share
+// links: a form for making them, the link last made, and the list of the
+// grain's links, which may be revoked.
+func (m Model) viewGrainShareLinks(ms tea.MessageSender[Model], id types.GrainID, grain OpenGrain) vdom.VNode {
+	list, ok := grain.ShareLinks.Get()
+	if !ok {
+		return h("section", a{"class": "grain-share-links"}, nil,
+			t(m.L10N, "Loading..."))
+	}
+	roleTitle := func(roleID int) vdom.VNode {
+		if roleID < len(list.RoleTitles) && list.RoleTitles[roleID] != "" {
+			return builder.T(list.RoleTitles[roleID])
+		}
+		return t(m.L10N, "Role %0", strconv.Itoa(roleID))
+	}
+	form := grain.ShareLinkForm
+	kids := []vdom.VNode{
+		h("h2", nil, nil, t(m.L10N, "Share with other users")),
+	}
+	if len(list.RoleTitles) == 0 {
+		kids = append(kids, h("p", nil, nil,
+			t(m.L10N, "This grain's app doesn't support sharing."),
+		))
+	}
+	for i := range list.RoleTitles {
+		attrs := a{"type": "radio", "name": "share-link-role"}
+		if i == form.RoleID {
+			attrs["checked"] = "checked"
+		}
+		kids = append(kids, h("label", a{"class": "grain-share-links__role"}, nil,
+			h("input", attrs,
+				e{"click": ms.Event(EditShareLinkRole{GrainID: id, RoleID: i})}),
+			roleTitle(i),
+		))
+	}
+	singleUseAttrs := a{"type": "checkbox"}
+	if form.SingleUse {
+		singleUseAttrs["checked"] = "checked"
+	}
+	submitAttrs := a{"type": "submit"}
+	if len(list.RoleTitles) == 0 {
+		submitAttrs["disabled"] = "disabled"
+	}
+	kids = append(kids,
+		h("label", a{"for": "share-link-days"}, nil,
+			t(m.L10N, "Days until the link stops working; leave empty to keep it working"),
+		),
+		h("input", a{
+			"name":  "share-link-days",
+			"type":  "number",
+			"min":   "1",
+			"value": form.DaysInput,
+		}, e{
+			"input": events.OnInput(func(value string) {
+				ms.Send(EditShareLinkDays{GrainID: id, NewValue: value})
+			}),
+		}),
+		h("label", a{"for": "share-link-note"}, nil,
+			t(m.L10N, "Note, to remind you who it's for"),
+		),
+		h("input", a{
+			"name":  "share-link-note",
+			"value": form.NoteInput,
+		}, e{
+			"input": events.OnInput(func(value string) {
+				ms.Send(EditShareLinkNote{GrainID: id, NewValue: value})
+			}),
+		}),
+		h("label", nil, nil,
+			h("input", singleUseAttrs,
+				e{"click": ms.Event(ToggleShareLinkSingleUse{GrainID: id})}),
+			t(m.L10N, "Stop working once opened"),
+		),
+		h("button",
+			submitAttrs,
+			e{"click": ms.Event(ShareGrain{ID: id})},
+			t(m.L10N, "Generate sharing link"),
+		),
+	)
+	if grain.SharingToken != "" {
+		rootUrl := m.ServerAddr.Root()
+		link := rootUrl.String() + "/#/shared/" + grain.SharingToken
+		kids = append(kids, h("div", a{"class": "grain-share-links__new"}, nil,
+			h("p", nil, nil,
+				t(m.L10N, "Copy the below link and share it to grant access to this grain.")),
+			h("a",
+				a{"href": link},
+				nil,
+				builder.T(link)),
+		))
+	}
+
+	var items []vdom.VNode
+	for _, link := range list.Links {
+		var terms []string
+		if !link.Expires.IsZero() {
+			terms = append(terms, m.L10N.Fmt("until %0", link.Expires.Local().Format("2006-01-02")))
+		}
+		if link.SingleUse {
+			terms = append(terms, m.L10N.Fmt("single use"))
+		}
+		itemKids := []vdom.VNode{
+			h("p", nil, nil, roleTitle(link.RoleID)),
+			h("p", a{"class": "grain-share-links__created"}, nil,
+				t(m.L10N, "Created %0, opened %1 times",
+					link.Created.Local().Format("2006-01-02"),
+					strconv.Itoa(link.Uses),
+				),
+			),
+		}
+		if len(terms) > 0 {
+			itemKids = append(itemKids, h("p", nil, nil, builder.T(strings.Join(terms, ", "))))
+		}
+		if link.Note != "" {
+			itemKids = append(itemKids, h("p", nil, nil, builder.T(link.Note)))
+		}
+		if !link.Mine {
+			itemKids = append(itemKids, h("p", nil, nil,
+				t(m.L10N, "Made by another user"),
+			))
+		}
+		itemKids = append(itemKids, h("button", nil,
+			e{"click": ms.Event(RevokeShareLink{GrainID: id, LinkID: link.ID})},
+			t(m.L10N, "Revoke"),
+		))
+		items = append(items, h("li", a{"class": "grain-share-links__item"}, nil, itemKids...))
+	}
+	if len(items) > 0 {
+		kids = append(kids, h("ul", a{"class": "grain-share-links__list"}, nil, items...))
+	}
+	return h("section", a{"class": "grain-share-links"}, nil, kids...)
+}
+
+// viewGrainMembers renders the part of the sharing dialog listing the users
+// with access to the grain, who shared it with them, and whom revoking their
+// access would cut off too. Only the owner sees it.
+func (m Model) viewGrainMembers(ms tea.MessageSender[Model], id types.GrainID, grain OpenGrain) vdom.VNode {
+	members, _ := grain.Members.Get()
+	if len(members) == 0 {
+		return h("section", a{"class": "grain-members"}, nil)
+	}
+	var roleTitles []string
+	if list, ok := grain.ShareLinks.Get(); ok {
+		roleTitles = list.RoleTitles
+	}
+	graph, _ := grain.ShareGraph.Get()
+	names := map[types.AccountID]string{}
+	for _, member := range members {
+		var creds []string
+		for _, cred := range member.Credentials {
+			creds = append(creds, cred.ScopedID)
+		}
+		names[member.AccountID] = strings.Join(creds, ", ")
+	}
+	name := func(accountID types.AccountID) vdom.VNode {
+		if accountID == graph.Owner {
+			return t(m.L10N, "you")
+		} else if names[accountID] == "" {
+			return t(m.L10N, "Unknown user")
+		}
+		return builder.T(names[accountID])
+	}
+	var items []vdom.VNode
+	for _, member := range members {
+		itemKids := []vdom.VNode{h("p", nil, nil, name(member.AccountID))}
+		var sharedBy []vdom.VNode
+		for _, s := range graph.Shares {
+			if s.AccountID == member.AccountID {
+				if len(sharedBy) > 0 {
+					sharedBy = append(sharedBy, builder.T(", "))
+				}
+				sharedBy = append(sharedBy, name(s.SharedBy))
+			}
+		}
+		if len(sharedBy) > 0 {
+			itemKids = append(itemKids, h("p", a{"class": "grain-members__shared-by"}, nil,
+				append([]vdom.VNode{t(m.L10N, "Shared by ")}, sharedBy...)...,
+			))
+		}
+		if member.RoleID != nil && *member.RoleID < len(roleTitles) {
+			itemKids = append(itemKids, h("p", nil, nil, builder.T(roleTitles[*member.RoleID])))
+		}
+		if !member.Joined.IsZero() {
+			itemKids = append(itemKids, h("p", a{"class": "grain-members__joined"}, nil,
+				t(m.L10N, "Joined %0", member.Joined.Local().Format("2006-01-02")),
+			))
+		}
+		if n := len(graph.Revoked(member.AccountID)) - 1; n > 0 {
+			itemKids = append(itemKids, h("p", a{"class": "grain-members__downstream"}, nil,
+				t(m.L10N, "Revoking their access also revokes that of %0 users they shared with",
+					strconv.Itoa(n)),
+			))
+		}
+		itemKids = append(itemKids, h("button", nil,
+			e{"click": ms.Event(RemoveGrainMember{GrainID: id, AccountID: member.AccountID})},
+			t(m.L10N, "Revoke access"),
+		))
+		items = append(items, h("li", a{"class": "grain-members__item"}, nil, itemKids...))
+	}
+	return h("section", a{"class": "grain-members"}, nil,
+		h("h2", nil, nil, t(m.L10N, "Users with access")),
+		h("ul", a{"class": "grain-members__list"}, nil, items...),
+	)
+}
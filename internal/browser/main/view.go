@@ -1,10 +1,15 @@
 package browsermain
 
 import (
+	"html"
+	"strconv"
 	"strings"
 	"syscall/js"
+	"time"
 
+	"sandstorm.org/go/tempest/capnp/external"
 	"sandstorm.org/go/tempest/internal/browser/intl"
+	"sandstorm.org/go/tempest/internal/common/apierror"
 	"sandstorm.org/go/tempest/internal/common/types"
 	"zenhack.net/go/jsapi/streams"
 	"zenhack.net/go/tea"
@@ -32,7 +37,7 @@ var dummyNode = h("div", a{"class": "dummy-node"}, nil)
 
 func (m Model) pageTitle() string {
 	switch m.CurrentFocus {
-	case FocusOpenGrain, FocusShareGrain:
+	case FocusOpenGrain, FocusShareGrain, FocusGrainDetails:
 		return "Tempest - " + m.Grains[m.FocusedGrain].Title
 	case FocusGrainList:
 		return "Tempest - Grains"
@@ -40,6 +45,12 @@ func (m Model) pageTitle() string {
 		return "Tempest - Apps"
 	case FocusLoadShared:
 		return "Tempest - Loading Shared Grain"
+	case FocusNotifications:
+		return "Tempest - Notifications"
+	case FocusSessions:
+		return "Tempest - Sessions"
+	case FocusAPIKeys:
+		return "Tempest - API keys"
 	default:
 		return "Tempest"
 	}
@@ -57,10 +68,12 @@ func (m Model) View(ms tea.MessageSender[Model]) vdom.VNode {
 	session, loginReady := m.LoginSessions.Get()
 	if !loginReady {
 		content = t(m.L10N, "Loading...")
-	} else if session.Err() != nil {
-		// TODO: deferrentiate between disconnects/failures. Or maybe just
-		// tweak the API to return all this info in-band?
-		content = viewLoginForm(m.L10N, m.LoginForm, ms)
+	} else if err := session.Err(); err != nil {
+		if apierror.FromError(err).Code == apierror.CodeNotLoggedIn {
+			content = viewLoginForm(m.L10N, m.LoginForm, ms)
+		} else {
+			content = viewError(m.L10N, err)
+		}
 	} else {
 		switch m.CurrentFocus {
 		case FocusGrainList:
@@ -68,14 +81,24 @@ func (m Model) View(ms tea.MessageSender[Model]) vdom.VNode {
 			slices.SortOn(kvs, func(kv maps.KV[types.GrainID, Grain]) string {
 				return kv.Value.Title
 			})
-			var grainNodes []vdom.VNode
+			var (
+				grainNodes []vdom.VNode
+				trashed    []types.GrainID
+			)
 			for _, kv := range kvs {
+				if kv.Value.Trashed {
+					trashed = append(trashed, kv.Key)
+					continue
+				}
 				grainNodes = append(
 					grainNodes,
 					viewGrain(ms, kv.Key, kv.Value),
 				)
 			}
-			content = viewNavLinks(grainNodes...)
+			content = h("div", nil, nil,
+				viewNavLinks(grainNodes...),
+				m.viewGrainTrash(ms, trashed),
+			)
 		case FocusApps:
 			content = m.viewApps(ms)
 		case FocusOpenGrain:
@@ -84,11 +107,22 @@ func (m Model) View(ms tea.MessageSender[Model]) vdom.VNode {
 			}
 		case FocusShareGrain:
 			content = m.viewShareGrainDialog(ms)
+		case FocusGrainDetails:
+			content = m.viewGrainDetails(ms)
 		case FocusLoadShared:
 			content = t(m.L10N, "Loading...")
+		case FocusNotifications:
+			content = m.viewNotifications(ms)
+		case FocusSessions:
+			content = m.viewActiveSessions(ms)
+		case FocusAPIKeys:
+			content = m.viewAPIKeys(ms)
 		default:
 			panic("Unknown focus value")
 		}
+		if picker, ok := m.Powerbox.Get(); ok {
+			content = m.viewPowerboxPicker(ms, picker)
+		}
 	}
 	keys := maps.Keys(m.OpenGrains)
 	slices.SortOn(keys, func(k types.GrainID) string {
@@ -128,6 +162,13 @@ func (m Model) View(ms tea.MessageSender[Model]) vdom.VNode {
 					h("a", a{"href": "#/grains"}, nil,
 						t(m.L10N, "Grains"),
 					),
+					m.viewNotificationsLink(),
+					h("a", a{"href": "#/sessions"}, nil,
+						t(m.L10N, "Sessions"),
+					),
+					h("a", a{"href": "#/api-keys"}, nil,
+						t(m.L10N, "API keys"),
+					),
 				),
 				h("h2", nil, nil, t(m.L10N, "Grains")),
 				h("nav", nil, nil,
@@ -138,12 +179,16 @@ func (m Model) View(ms tea.MessageSender[Model]) vdom.VNode {
 		),
 	}
 
+	if !m.DemoExpires.IsZero() {
+		mainUiNodes = append([]vdom.VNode{
+			h("p", a{"class": "demo-notice"}, nil,
+				t(m.L10N, "This is a demo account. It will be deleted, with its grains, at %0.",
+					m.DemoExpires.Local().Format("2006-01-02 15:04")),
+			),
+		}, mainUiNodes...)
+	}
 	for _, e := range m.Errors {
-		mainUiNodes = append(
-			mainUiNodes,
-			// TODO: figure out how translating the error should work.
-			h("div", a{"class": "error-notice"}, nil, builder.T(e.Error())),
-		)
+		mainUiNodes = append(mainUiNodes, viewError(m.L10N, e))
 	}
 
 	return h("body", nil, nil,
@@ -151,8 +196,9 @@ func (m Model) View(ms tea.MessageSender[Model]) vdom.VNode {
 	)
 }
 
-// viewShareGrainDialog renders the dialog for generating a sharing link.
-// Right now this is a simple button, which is just a placeholder.
+// viewShareGrainDialog renders the dialog for sharing the grain: through
+// share links, embeds and API tokens, and with its owner, the list of users
+// with access.
 func (m Model) viewShareGrainDialog(ms tea.MessageSender[Model]) vdom.VNode {
 	id := m.FocusedGrain
 	grain := m.OpenGrains[id]
@@ -166,26 +212,278 @@ func (m Model) viewShareGrainDialog(ms tea.MessageSender[Model]) vdom.VNode {
 		e{"click": &onClose},
 		t(m.L10N, "cancel sharing"),
 	)
-	var content vdom.VNode
-	if grain.SharingToken == "" {
-		content = h("button", nil,
-			e{"click": ms.Event(ShareGrain{ID: id})},
-			t(m.L10N, "Generate sharing link"))
-	} else {
-		rootUrl := m.ServerAddr.Root()
-		link := rootUrl.String() + "/#/shared/" + grain.SharingToken
-		content = h("div", nil, nil,
+	content := h("div", nil, nil,
+		m.viewGrainShareLinks(ms, id, grain),
+		m.viewGrainMembers(ms, id, grain),
+		m.viewGrainEmbeds(ms, id, grain),
+		m.viewGrainAPITokens(ms, id, grain),
+	)
+	return viewModal(content, closeBtn)
+}
+
+// viewGrainEmbeds renders the part of the sharing dialog for embedding the
+// grain in other websites: a form for creating embeds, and the list of the
+// grain's embeds, which may be revoked.
+func (m Model) viewGrainEmbeds(ms tea.MessageSender[Model], id types.GrainID, grain OpenGrain) vdom.VNode {
+	list, ok := grain.Embeds.Get()
+	if !ok {
+		return h("section", a{"class": "grain-embeds"}, nil,
+			t(m.L10N, "Loading..."))
+	}
+	form := grain.EmbedForm
+	kids := []vdom.VNode{
+		h("h2", nil, nil, t(m.L10N, "Embed in other websites")),
+		h("label", a{"for": "embed-origins"}, nil,
+			t(m.L10N, "Websites allowed to embed this grain, separated by spaces"),
+		),
+		h("input", a{
+			"name":        "embed-origins",
+			"placeholder": m.L10N.Fmt("e.g. https://example.com"),
+			"value":       form.OriginsInput,
+		}, e{
+			"input": events.OnInput(func(value string) {
+				ms.Send(EditEmbedOrigins{GrainID: id, NewValue: value})
+			}),
+		}),
+		h("label", a{"for": "embed-days"}, nil,
+			t(m.L10N, "Days until the embed stops working"),
+		),
+		h("input", a{
+			"name":        "embed-days",
+			"type":        "number",
+			"min":         "1",
+			"placeholder": "30",
+			"value":       form.DaysInput,
+		}, e{
+			"input": events.OnInput(func(value string) {
+				ms.Send(EditEmbedDays{GrainID: id, NewValue: value})
+			}),
+		}),
+		h("label", a{"for": "embed-note"}, nil,
+			t(m.L10N, "Note, to remind you where it's used"),
+		),
+		h("input", a{
+			"name":  "embed-note",
+			"value": form.NoteInput,
+		}, e{
+			"input": events.OnInput(func(value string) {
+				ms.Send(EditEmbedNote{GrainID: id, NewValue: value})
+			}),
+		}),
+	}
+	for i, title := range list.PermissionTitles {
+		attrs := a{"type": "checkbox"}
+		if i < len(form.Permissions) && form.Permissions[i] {
+			attrs["checked"] = "checked"
+		}
+		kids = append(kids, h("label", a{"class": "grain-embeds__permission"}, nil,
+			h("input", attrs,
+				e{"click": ms.Event(ToggleEmbedPermission{GrainID: id, Index: i})}),
+			builder.T(title),
+		))
+	}
+	submitAttrs := a{"type": "submit"}
+	if strings.TrimSpace(form.OriginsInput) == "" {
+		submitAttrs["disabled"] = "disabled"
+	}
+	kids = append(kids, h("button",
+		submitAttrs,
+		e{"click": ms.Event(CreateGrainEmbed{GrainID: id})},
+		t(m.L10N, "Create embed"),
+	))
+
+	var items []vdom.VNode
+	for _, embed := range list.Embeds {
+		snippet := `<iframe src="` + html.EscapeString(embed.URL) + `"></iframe>`
+		itemKids := []vdom.VNode{
 			h("p", nil, nil,
-				t(m.L10N, "Copy the below link and share it to grant access to this grain.")),
-			h("a",
-				a{"href": link},
-				nil,
-				builder.T(link)),
+				t(m.L10N, "For %0, until %1",
+					strings.Join(embed.FrameAncestors, ", "),
+					embed.Expires.Local().Format("2006-01-02"),
+				),
+			),
+		}
+		if embed.Note != "" {
+			itemKids = append(itemKids, h("p", nil, nil, builder.T(embed.Note)))
+		}
+		itemKids = append(itemKids,
+			h("code", a{"class": "grain-embeds__snippet"}, nil, builder.T(snippet)),
+			h("button", nil,
+				e{"click": ms.Event(RevokeGrainEmbed{GrainID: id, EmbedID: embed.ID})},
+				t(m.L10N, "Revoke"),
+			),
 		)
+		items = append(items, h("li", a{"class": "grain-embeds__item"}, nil, itemKids...))
+	}
+	if len(items) > 0 {
+		kids = append(kids, h("ul", a{"class": "grain-embeds__list"}, nil, items...))
+	}
+	return h("section", a{"class": "grain-embeds"}, nil, kids...)
+}
+
+// grainEventLabels are the descriptions of the events in a grain's timeline.
+var grainEventLabels = map[types.GrainEventKind]intl.L10NString{
+	types.GrainCreated:   "Created",
+	types.GrainStarted:   "Started",
+	types.GrainCrashed:   "Stopped unexpectedly",
+	types.GrainRestarted: "Restarted after stopping unexpectedly",
+	types.GrainShared:    "Shared",
+	types.GrainJoined:    "Joined by a user with a sharing link",
+	types.GrainEmbedded:  "Embedded in other websites",
+	types.GrainBackedUp:  "Backed up",
+	types.GrainRestored:  "Restored from a backup",
+	types.GrainMigrated:  "Updated to a new app version",
+
+	types.GrainUpgradeRolledBack: "Returned to the old app version after the new one failed to start",
+
+	types.GrainJobDisabled:   "Scheduled job disabled after repeated failures",
+	types.GrainMemberRemoved: "A user's access was revoked",
+}
+
+// viewGrainDetails renders the details panel for the focused grain, which
+// shows its timeline, and lets its owner rename it or move it to the trash.
+func (m Model) viewGrainDetails(ms tea.MessageSender[Model]) vdom.VNode {
+	id := m.FocusedGrain
+	onClose := func(e vdom.Event) any {
+		navigate("#/grain/" + string(id))
+		return nil
+	}
+	closeBtn := h("button",
+		a{"class": "close-button"},
+		e{"click": &onClose},
+		t(m.L10N, "close details"),
+	)
+	timeline, ok := m.OpenGrains[id].Timeline.Get()
+	var eventList vdom.VNode
+	if !ok {
+		eventList = t(m.L10N, "Loading...")
+	} else if len(timeline) == 0 {
+		eventList = t(m.L10N, "Nothing has happened to this grain yet.")
+	} else {
+		var items []vdom.VNode
+		for _, event := range timeline {
+			var label vdom.VNode
+			if f, ok := grainEventLabels[event.Kind]; ok {
+				label = t(m.L10N, f)
+			} else {
+				// From a newer server; better than nothing.
+				label = builder.T(string(event.Kind))
+			}
+			kids := []vdom.VNode{
+				h("time",
+					a{
+						"class":    "grain-timeline__time",
+						"datetime": event.Time.Format(time.RFC3339),
+					},
+					nil,
+					builder.T(event.Time.Local().Format("2006-01-02 15:04")),
+				),
+				label,
+			}
+			if event.Detail != "" {
+				kids = append(kids, h("span", a{"class": "grain-timeline__detail"}, nil,
+					builder.T(event.Detail),
+				))
+			}
+			items = append(items, h("li", a{"class": "grain-timeline__event"}, nil, kids...))
+		}
+		eventList = h("ol", a{"class": "grain-timeline"}, nil, items...)
 	}
+	content := h("div", nil, nil,
+		h("h2", nil, nil, builder.T(m.Grains[id].Title)),
+		m.viewGrainLifecycle(ms, id),
+		m.viewGrainUpgrade(ms, id),
+		h("h3", nil, nil, t(m.L10N, "What happened to this grain")),
+		eventList,
+	)
 	return viewModal(content, closeBtn)
 }
 
+// unreadNotifications returns the number of the user's notifications which
+// they haven't read.
+func (m Model) unreadNotifications() int {
+	count := 0
+	for _, n := range m.Notifications {
+		if !n.Read() {
+			count++
+		}
+	}
+	return count
+}
+
+// viewNotificationsLink renders the sidebar link to the notifications page,
+// with a badge showing how many are unread.
+func (m Model) viewNotificationsLink() vdom.VNode {
+	kids := []vdom.VNode{t(m.L10N, "Notifications")}
+	if unread := m.unreadNotifications(); unread > 0 {
+		kids = append(kids, h("span", a{"class": "unread-badge"}, nil,
+			builder.T(strconv.Itoa(unread)),
+		))
+	}
+	return h("a", a{"href": "#/notifications"}, nil, kids...)
+}
+
+// viewNotifications renders the user's notifications, most recent first.
+// Following one marks it read.
+func (m Model) viewNotifications(ms tea.MessageSender[Model]) vdom.VNode {
+	kvs := maps.Items(m.Notifications)
+	slices.SortOn(kvs, func(kv maps.KV[types.ID[external.Notification], external.Notification]) int64 {
+		return -kv.Value.Time()
+	})
+	var items []vdom.VNode
+	for _, kv := range kvs {
+		n := kv.Value
+		grainID, err := n.GrainId()
+		if err != nil {
+			println("grainId: " + err.Error())
+			continue
+		}
+		grainTitle, _ := n.GrainTitle()
+		text, _ := n.Text()
+		when := time.Unix(0, n.Time())
+		class := "notifications__item"
+		if !n.Read() {
+			class += " notifications__item--unread"
+		}
+		// TODO: open the grain at the notification's path, once grains
+		// can be opened anywhere but the root.
+		items = append(items, h("li", a{"class": class}, nil,
+			h("time",
+				a{
+					"class":    "notifications__time",
+					"datetime": when.Format(time.RFC3339),
+				},
+				nil,
+				builder.T(when.Local().Format("2006-01-02 15:04")),
+			),
+			h("a",
+				a{"href": "#/grain/" + grainID},
+				e{"click": ms.Event(MarkNotificationsRead{
+					IDs: []types.ID[external.Notification]{kv.Key},
+				})},
+				builder.T(grainTitle),
+			),
+			// TODO: figure out how translation
+			// should work for app-provided strings.
+			h("span", a{"class": "notifications__text"}, nil, builder.T(text)),
+		))
+	}
+	if len(items) == 0 {
+		return h("div", nil, nil,
+			h("h2", nil, nil, t(m.L10N, "Notifications")),
+			t(m.L10N, "You have no notifications."),
+		)
+	}
+	return h("div", nil, nil,
+		h("h2", nil, nil, t(m.L10N, "Notifications")),
+		h("button", nil,
+			e{"click": ms.Event(MarkNotificationsRead{})},
+			t(m.L10N, "Mark all as read"),
+		),
+		h("ol", a{"class": "notifications"}, nil, items...),
+	)
+}
+
 // viewModal renders a modal dialog; the argument is centered over a semi-transparent
 // background covering the parent element.
 func viewModal(dialog, closeBtn vdom.VNode) vdom.VNode {
@@ -287,6 +585,7 @@ func (m Model) viewApps(ms tea.MessageSender[Model]) vdom.VNode {
 			a{"type": "file", "name": "package"},
 			e{"change": &onPkgChange},
 		),
+		m.viewAppUpgrades(ms),
 		h("ul", nil, nil, appItems...),
 	)
 }
@@ -402,6 +701,12 @@ func viewOpenGrainMenu(l10n intl.L10N, ms tea.MessageSender[Model], id types.Gra
 			"share",
 			"#/share-grain/"+string(id),
 		),
+		viewOpenGrainMenuItem(
+			l10n,
+			"Details",
+			"details",
+			"#/grain-details/"+string(id),
+		),
 	)
 }
 
@@ -418,13 +723,39 @@ func viewOpenGrainMenuItem(l10n intl.L10N, title intl.L10NString, name, href str
 func viewGrain(ms tea.MessageSender[Model], id types.GrainID, grain Grain) vdom.VNode {
 	return h("a", a{"href": "#/grain/" + string(id)}, nil,
 		builder.T(grain.Title),
+		viewGrainSize(grain),
+	)
+}
+
+// viewGrainSize renders the disk space used by the grain, if the user owns
+// it, so that they can see which of their grains use the most.
+func viewGrainSize(grain Grain) vdom.VNode {
+	if grain.StorageBytes <= 0 {
+		return dummyNode
+	}
+	return h("span", a{"class": "grain-size"}, nil,
+		builder.T(formatStorage(grain.StorageBytes)),
 	)
 }
 
+// formatStorage formats a number of bytes for people, e.g. "1.5 MiB".
+func formatStorage(n int64) string {
+	const units = "KMGTPE"
+	if n < 1024 {
+		return strconv.FormatInt(n, 10) + " B"
+	}
+	v, i := float64(n)/1024, 0
+	for v >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	return strconv.FormatFloat(v, 'f', 1, 64) + " " + units[i:i+1] + "iB"
+}
+
 // HasGrain returns whether or not the focus should display the current grain's iframe.
 func (f Focus) HasGrain() bool {
 	switch f {
-	case FocusOpenGrain, FocusShareGrain:
+	case FocusOpenGrain, FocusShareGrain, FocusGrainDetails:
 		return true
 	default:
 		return false
@@ -437,6 +768,7 @@ func viewGrainIframe(m Model, id types.GrainID) vdom.VNode {
 	qv := grainUrl.Query()
 	qv.Set("sandstorm-sid", grain.SessionToken)
 	qv.Set("path", "/")
+	qv.Set("lang", m.Locale)
 	grainUrl.Path = "/_sandstorm-init"
 	grainUrl.RawQuery = qv.Encode()
 	class := "grain-iframe"
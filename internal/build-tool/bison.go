@@ -17,250 +17,63 @@
 package buildtool
 
 import (
-	"bytes"
-	"fmt"
-	"log"
 	"os"
-	"os/exec"
-	"path/filepath"
-	"strings"
-	"text/template"
 )
 
-type bisonConfig struct {
-	downloadFile        string
-	downloadUrl         string
-	executable          string
-	expectedFileSize    int64
-	expectedSha256      string
-	toolchainDir        string
-	toolchainExecutable string
-	toolchainVersion    string
-	version             string
-	versionedDir        string
+func init() {
+	registerBootstrapper(&archiveTool{
+		command: "bison",
+		config: func(buildToolConfig *RuntimeConfigBuildTool) *runtimeConfigTool {
+			return buildToolConfig.Bison
+		},
+		downloads: func(downloads *DownloadsTomlTopLevel) *DownloadsTomlTool {
+			return &downloads.Bison
+		},
+		toolchainToml: func(toolchainToml *ToolchainTomlTopLevel) **ToolchainTomlTool {
+			return &toolchainToml.Bison
+		},
+		executable: "tests/bison",
+		build:      buildBison,
+		system: &systemTool{
+			executable:  "bison",
+			versionArgs: []string{"--version"},
+		},
+	})
 }
 
-// text/template uses these struct fields from a separate package, so they must be in PascalCase.
-type bisonDownloadUrlTemplateValues struct {
-	Filename string
-}
-
-// text/template uses these struct fields from a separate package, so they must be in PascalCase.
-type bisonFilenameTemplateValues struct {
-	Version string
-}
-
-func BootstrapBison(buildToolConfig *RuntimeConfigBuildTool) ([]string, error) {
-	messages := make([]string, 0, 5)
-	bisonConfig, err := getBisonConfig(buildToolConfig)
-	if err != nil {
-		messages = append(messages, "Failed to get Bison configuration")
-		return messages, err
-	}
-	if bisonConfig.executable != "" {
-		executableExists, err := fileExistsAtPath(bisonConfig.executable)
-		if err != nil {
-			log.Printf("fileExistsAtPath err\n")
-			return messages, err
-		}
-		if executableExists {
-			messages = append(messages, fmt.Sprintf("Skipping download and installation of Bison because %s (from config.toml) exists", bisonConfig.executable))
-			return messages, nil
-		} else {
-			err = fmt.Errorf("User-specified Bison executable %s does not exist.", bisonConfig.executable)
-			return messages, err
-		}
-	}
-	if bisonConfig.toolchainExecutable != "" {
-		executableExists, err := fileExistsAtPath(bisonConfig.toolchainExecutable)
-		if err != nil {
-			log.Printf("fileExistsAtPath err\n")
-			return messages, err
-		}
-		if executableExists {
-			if bisonConfig.version == bisonConfig.toolchainVersion {
-				messages = append(messages, fmt.Sprintf("Skipping download and installation of Bison because %s (from toolchain) exists", bisonConfig.toolchainExecutable))
-				return messages, nil
-			} else {
-				messages = append(messages, fmt.Sprintf("The toolchain executable does not match the desired version.  Continuing."))
-			}
-		}
-	}
-	err = ensureDownloadDirExists(buildToolConfig.Directories.DownloadDir)
-	if err != nil {
-		return messages, err
-	}
-	downloadPath := filepath.Join(buildToolConfig.Directories.DownloadDir, bisonConfig.downloadFile)
-	downloadPathExists, err := fileExistsAtPath(downloadPath)
-	if err != nil {
-		return messages, err
-	}
-	if downloadPathExists {
-		messages = append(messages, fmt.Sprintf("Skipping Bison download because %s exists", downloadPath))
-	} else {
-		err := downloadUrlToDir(bisonConfig.downloadUrl, buildToolConfig.Directories.DownloadDir, downloadPath)
-		if err != nil {
-			return messages, err
-		}
-	}
-	err = verifyFileSize(bisonConfig.expectedFileSize, downloadPath)
-	if err != nil {
-		return messages, err
-	}
-	err = verifySha256(bisonConfig.expectedSha256, downloadPath)
+func buildBison(b *toolBuild) ([]string, error) {
+	stopTimer := b.startStep(stepConfigure)
+	err := configureBison(b.tool.toolchainDir, b.buildToolConfig.sandbox, b.buildToolConfig.static)
+	stopTimer()
 	if err != nil {
-		return messages, err
-	}
-	messages = append(messages, fmt.Sprintf("%s has the correct SHA-256", downloadPath))
-	filterBisonTarXz := filterBisonTarXzFactory(bisonConfig.versionedDir)
-	transformBisonTarXz := transformBisonTarXzFactory(buildToolConfig.Directories.ToolChainDir)
-	err = extractTarXz(downloadPath, filterBisonTarXz, transformBisonTarXz)
-	if err != nil {
-		messages = append(messages, fmt.Sprintf("Failed to extract %s", downloadPath))
-		return messages, err
+		return nil, err
 	}
-	err = configureBison(bisonConfig.toolchainDir)
+	stopTimer = b.startStep(stepMake)
+	err = makeBison(b.tool.toolchainDir, b.buildToolConfig.sandbox, b.tool.jobs)
+	stopTimer()
+	return nil, err
+}
+
+func configureBison(bisonDir string, sandbox *runtimeConfigSandbox, static *runtimeConfigStatic) error {
+	cmd, err := sandbox.command(bisonDir, "./configure")
 	if err != nil {
-		return messages, err
+		return err
 	}
-	err = makeBison(bisonConfig.toolchainDir)
-	if err != nil {
-		return messages, err
+	if static.enabled {
+		cmd.Env = append(cmd.Env, "LDFLAGS=-static")
+		cmd.Env = append(cmd.Env, static.compilerEnv()...)
 	}
-	toolchainTomlExecutable := filepath.Join(bisonConfig.versionedDir, "tests", "bison")
-	err = updateBisonToolchainToml(buildToolConfig.Directories.ToolChainDir, toolchainTomlExecutable, bisonConfig.version)
-	return messages, err
-}
-
-func configureBison(bisonDir string) error {
-	cmd := exec.Command("./configure")
-	cmd.Dir = bisonDir
-	cmd.Env = append(cmd.Env, os.Environ()...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
 
-func filterBisonTarXz(versionedDir string, filePath string) bool {
-	acceptable := strings.HasPrefix(filePath, versionedDir)
-	if !acceptable {
-		// TODO: Figure out how to get this in the messages slice.
-		log.Printf("Rejecting file with invalid prefix: %s\n", filePath)
-	}
-	return acceptable
-}
-
-func filterBisonTarXzFactory(versionedDir string) fileFilter {
-	versionedDir = ensureTrailingSlash(versionedDir)
-	return func(filePath string) bool {
-		return filterBisonTarXz(versionedDir, filePath)
-	}
-}
-
-/**
- * getBisonConfig populates templates from the runtime configuration with
- * appropriate values.
- */
-func getBisonConfig(buildToolConfig *RuntimeConfigBuildTool) (*bisonConfig, error) {
-	if buildToolConfig.Bison == nil {
-		return nil, fmt.Errorf("buildToolConfig.Bison is nil")
-	}
-	if buildToolConfig.Directories == nil {
-		return nil, fmt.Errorf("buildToolConfig.Directories is nil")
-	}
-	// Version
-	version := buildToolConfig.Bison.version
-	// Download File
-	filenameValues := bisonFilenameTemplateValues{
-		version,
-	}
-	filenameTemplate, err := template.New("filename").Parse(buildToolConfig.Bison.filenameTemplate)
-	if err != nil {
-		return nil, err
-	}
-	var filenameBuffer bytes.Buffer
-	err = filenameTemplate.Execute(&filenameBuffer, filenameValues)
+func makeBison(bisonDir string, sandbox *runtimeConfigSandbox, jobs int) error {
+	cmd, err := sandbox.command(bisonDir, "make", makeJobsArg(jobs))
 	if err != nil {
-		return nil, err
-	}
-	downloadFile := filenameBuffer.String()
-
-	// Download URL
-	downloadUrlValues := bisonDownloadUrlTemplateValues{
-		downloadFile,
-	}
-	downloadUrlTemplate, err := template.New("downloadUrl").Parse(buildToolConfig.Bison.downloadUrlTemplate)
-	if err != nil {
-		return nil, err
-	}
-	var downloadUrlBuffer bytes.Buffer
-	err = downloadUrlTemplate.Execute(&downloadUrlBuffer, downloadUrlValues)
-	if err != nil {
-		return nil, err
+		return err
 	}
-	downloadUrl := downloadUrlBuffer.String()
-	downloadFileInfo := buildToolConfig.Bison.files[downloadFile]
-	if downloadFileInfo == (runtimeConfigFile{}) {
-		return nil, fmt.Errorf("File size and SHA-256 not found in downloads.toml for %s", downloadFile)
-	}
-	// Bison executable
-	executable := buildToolConfig.Bison.Executable
-	// Expected file size and SHA-256
-	expectedFileSize := downloadFileInfo.size
-	expectedSha256 := downloadFileInfo.sha256
-	// Toolchain directory
-	toolchainDir := buildToolConfig.Bison.toolchainDir
-	// Toolchain executable
-	toolchainExecutable := buildToolConfig.Bison.ToolChainExecutable
-	// Toolchain version
-	toolchainVersion := buildToolConfig.Bison.toolchainVersion
-	versionedDir := buildToolConfig.Bison.versionedDir
-
-	bisonConfig := new(bisonConfig)
-	bisonConfig.downloadFile = downloadFile
-	bisonConfig.downloadUrl = downloadUrl
-	bisonConfig.executable = executable
-	bisonConfig.expectedFileSize = expectedFileSize
-	bisonConfig.expectedSha256 = expectedSha256
-	bisonConfig.toolchainDir = toolchainDir
-	bisonConfig.toolchainExecutable = toolchainExecutable
-	bisonConfig.toolchainVersion = toolchainVersion
-	bisonConfig.version = version
-	bisonConfig.versionedDir = versionedDir
-	return bisonConfig, nil
-}
-
-func makeBison(bisonDir string) error {
-	cmd := exec.Command("make")
-	cmd.Dir = bisonDir
-	cmd.Env = append(cmd.Env, os.Environ()...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
-
-func transformBisonTarXz(toolchainDir string, filePath string) string {
-	return filepath.Join(toolchainDir, filePath)
-}
-
-func transformBisonTarXzFactory(toolchainDir string) fileTransformer {
-	return func(filePath string) string {
-		return transformBisonTarXz(toolchainDir, filePath)
-	}
-}
-
-func updateBisonToolchainToml(toolchainDir string, executable string, version string) error {
-	toolchainTomlTopLevel, err := ReadToolchainToml(toolchainDir)
-	if err != nil {
-		if !os.IsNotExist(err) {
-			return err
-		}
-		toolchainTomlTopLevel = new(ToolchainTomlTopLevel)
-	}
-	if toolchainTomlTopLevel.Bison == nil {
-		toolchainTomlTopLevel.Bison = new(ToolchainTomlTool)
-	}
-	toolchainTomlTopLevel.Bison.Executable = executable
-	toolchainTomlTopLevel.Bison.Version = version
-	return WriteToolchainToml(toolchainDir, toolchainTomlTopLevel)
-}
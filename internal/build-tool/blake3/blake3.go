@@ -0,0 +1,288 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package blake3 implements the BLAKE3 hash function, following the
+// reference implementation in the BLAKE3 paper.  It only supports the default
+// 256-bit hash, without keying or key derivation, which is all that verifying
+// downloads needs.  It is portable rather than fast.
+package blake3
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+// Size is the size of a BLAKE3 hash in bytes.
+const Size = 32
+
+const (
+	blockLen = 64
+	chunkLen = 1024
+
+	flagChunkStart = 1 << 0
+	flagChunkEnd   = 1 << 1
+	flagParent     = 1 << 2
+	flagRoot       = 1 << 3
+)
+
+var iv = [8]uint32{
+	0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a,
+	0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19,
+}
+
+var msgPermutation = [16]int{2, 6, 3, 10, 7, 0, 4, 13, 1, 11, 12, 5, 9, 14, 15, 8}
+
+func g(state *[16]uint32, a, b, c, d int, mx, my uint32) {
+	state[a] += state[b] + mx
+	state[d] = bits.RotateLeft32(state[d]^state[a], -16)
+	state[c] += state[d]
+	state[b] = bits.RotateLeft32(state[b]^state[c], -12)
+	state[a] += state[b] + my
+	state[d] = bits.RotateLeft32(state[d]^state[a], -8)
+	state[c] += state[d]
+	state[b] = bits.RotateLeft32(state[b]^state[c], -7)
+}
+
+func round(state *[16]uint32, m *[16]uint32) {
+	// Columns
+	g(state, 0, 4, 8, 12, m[0], m[1])
+	g(state, 1, 5, 9, 13, m[2], m[3])
+	g(state, 2, 6, 10, 14, m[4], m[5])
+	g(state, 3, 7, 11, 15, m[6], m[7])
+	// Diagonals
+	g(state, 0, 5, 10, 15, m[8], m[9])
+	g(state, 1, 6, 11, 12, m[10], m[11])
+	g(state, 2, 7, 8, 13, m[12], m[13])
+	g(state, 3, 4, 9, 14, m[14], m[15])
+}
+
+func compress(chainingValue *[8]uint32, blockWords *[16]uint32, counter uint64, blockLen uint32, flags uint32) [16]uint32 {
+	state := [16]uint32{
+		chainingValue[0], chainingValue[1], chainingValue[2], chainingValue[3],
+		chainingValue[4], chainingValue[5], chainingValue[6], chainingValue[7],
+		iv[0], iv[1], iv[2], iv[3],
+		uint32(counter), uint32(counter >> 32), blockLen, flags,
+	}
+	m := *blockWords
+	for i := 0; i < 7; i++ {
+		round(&state, &m)
+		if i < 6 {
+			var permuted [16]uint32
+			for j, k := range msgPermutation {
+				permuted[j] = m[k]
+			}
+			m = permuted
+		}
+	}
+	for i := 0; i < 8; i++ {
+		state[i] ^= state[i+8]
+		state[i+8] ^= chainingValue[i]
+	}
+	return state
+}
+
+func first8(words [16]uint32) [8]uint32 {
+	var ret [8]uint32
+	copy(ret[:], words[:8])
+	return ret
+}
+
+func wordsFromBlock(block *[blockLen]byte) [16]uint32 {
+	var words [16]uint32
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint32(block[4*i:])
+	}
+	return words
+}
+
+// An output is the state just before the last compression of a node, which
+// gives either its chaining value or, for the root, the hash.
+type output struct {
+	inputChainingValue [8]uint32
+	blockWords         [16]uint32
+	counter            uint64
+	blockLen           uint32
+	flags              uint32
+}
+
+func (o *output) chainingValue() [8]uint32 {
+	return first8(compress(&o.inputChainingValue, &o.blockWords, o.counter, o.blockLen, o.flags))
+}
+
+func (o *output) rootHash() [Size]byte {
+	words := compress(&o.inputChainingValue, &o.blockWords, 0, o.blockLen, o.flags|flagRoot)
+	var ret [Size]byte
+	for i := 0; i < Size/4; i++ {
+		binary.LittleEndian.PutUint32(ret[4*i:], words[i])
+	}
+	return ret
+}
+
+func parentOutput(left, right [8]uint32) output {
+	o := output{
+		inputChainingValue: iv,
+		blockLen:           blockLen,
+		flags:              flagParent,
+	}
+	copy(o.blockWords[:8], left[:])
+	copy(o.blockWords[8:], right[:])
+	return o
+}
+
+type chunkState struct {
+	chainingValue    [8]uint32
+	chunkCounter     uint64
+	block            [blockLen]byte
+	blockLen         int
+	blocksCompressed int
+}
+
+func newChunkState(chunkCounter uint64) chunkState {
+	return chunkState{
+		chainingValue: iv,
+		chunkCounter:  chunkCounter,
+	}
+}
+
+func (c *chunkState) len() int {
+	return blockLen*c.blocksCompressed + c.blockLen
+}
+
+func (c *chunkState) startFlag() uint32 {
+	if c.blocksCompressed == 0 {
+		return flagChunkStart
+	}
+	return 0
+}
+
+func (c *chunkState) update(input []byte) {
+	for len(input) > 0 {
+		// Only compress a full block once more input arrives, since the
+		// last block of the chunk is compressed with different flags.
+		if c.blockLen == blockLen {
+			blockWords := wordsFromBlock(&c.block)
+			c.chainingValue = first8(compress(&c.chainingValue, &blockWords, c.chunkCounter, blockLen, c.startFlag()))
+			c.blocksCompressed++
+			c.block = [blockLen]byte{}
+			c.blockLen = 0
+		}
+		n := copy(c.block[c.blockLen:], input)
+		c.blockLen += n
+		input = input[n:]
+	}
+}
+
+func (c *chunkState) output() output {
+	return output{
+		inputChainingValue: c.chainingValue,
+		blockWords:         wordsFromBlock(&c.block),
+		counter:            c.chunkCounter,
+		blockLen:           uint32(c.blockLen),
+		flags:              c.startFlag() | flagChunkEnd,
+	}
+}
+
+// Hasher computes a BLAKE3 hash.  It implements hash.Hash.
+type Hasher struct {
+	chunk chunkState
+	// The chaining values of complete subtrees, which are merged as
+	// chunks are added.  54 is enough for the maximum input of 2^64
+	// bytes.
+	stack    [54][8]uint32
+	stackLen int
+}
+
+var _ hash.Hash = (*Hasher)(nil)
+
+// New returns a Hasher for the default 256-bit BLAKE3 hash.
+func New() *Hasher {
+	return &Hasher{chunk: newChunkState(0)}
+}
+
+// Sum256 returns the BLAKE3 hash of data.
+func Sum256(data []byte) [Size]byte {
+	h := New()
+	h.Write(data)
+	o := h.rootOutput()
+	return o.rootHash()
+}
+
+func (h *Hasher) addChunkChainingValue(chainingValue [8]uint32, totalChunks uint64) {
+	// Each trailing zero bit in the chunk count is a subtree which is now
+	// complete, and can be merged with its left sibling on the stack.
+	for totalChunks&1 == 0 {
+		h.stackLen--
+		parent := parentOutput(h.stack[h.stackLen], chainingValue)
+		chainingValue = parent.chainingValue()
+		totalChunks >>= 1
+	}
+	h.stack[h.stackLen] = chainingValue
+	h.stackLen++
+}
+
+// Write adds more data to the hash.  It never returns an error.
+func (h *Hasher) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		// As with blocks, only finish a full chunk once more input
+		// arrives, since the root chunk is finished differently.
+		if h.chunk.len() == chunkLen {
+			chunkOutput := h.chunk.output()
+			totalChunks := h.chunk.chunkCounter + 1
+			h.addChunkChainingValue(chunkOutput.chainingValue(), totalChunks)
+			h.chunk = newChunkState(totalChunks)
+		}
+		want := chunkLen - h.chunk.len()
+		if want > len(p) {
+			want = len(p)
+		}
+		h.chunk.update(p[:want])
+		p = p[want:]
+	}
+	return n, nil
+}
+
+func (h *Hasher) rootOutput() output {
+	o := h.chunk.output()
+	for i := h.stackLen - 1; i >= 0; i-- {
+		o = parentOutput(h.stack[i], o.chainingValue())
+	}
+	return o
+}
+
+// Sum appends the hash of the data written so far to b.  It does not change
+// the state of the Hasher.
+func (h *Hasher) Sum(b []byte) []byte {
+	o := h.rootOutput()
+	sum := o.rootHash()
+	return append(b, sum[:]...)
+}
+
+// Reset resets the Hasher to its initial state.
+func (h *Hasher) Reset() {
+	*h = Hasher{chunk: newChunkState(0)}
+}
+
+// Size returns the size of the hash, in bytes.
+func (h *Hasher) Size() int {
+	return Size
+}
+
+// BlockSize returns the size of a BLAKE3 block, in bytes.
+func (h *Hasher) BlockSize() int {
+	return blockLen
+}
@@ -0,0 +1,114 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blake3
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testVectors is the official test_vectors.json from the BLAKE3 repository.
+// Only the default hash mode is supported, so keyed_hash and derive_key are
+// unused.
+type testVectors struct {
+	Cases []struct {
+		InputLen int    `json:"input_len"`
+		Hash     string `json:"hash"`
+	} `json:"cases"`
+}
+
+func readTestVectors(t *testing.T) testVectors {
+	contents, err := os.ReadFile(filepath.Join("testdata", "test_vectors.json"))
+	require.NoError(t, err)
+	var vectors testVectors
+	require.NoError(t, json.Unmarshal(contents, &vectors))
+	require.NotEmpty(t, vectors.Cases)
+	return vectors
+}
+
+// testInput returns the input of a test vector: a repeating sequence of the
+// bytes 0 to 250.
+func testInput(length int) []byte {
+	input := make([]byte, length)
+	for i := range input {
+		input[i] = byte(i % 251)
+	}
+	return input
+}
+
+// wantHash returns the default-length hash from a test vector's extended
+// output.
+func wantHash(t *testing.T, extended string) []byte {
+	output, err := hex.DecodeString(extended)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(output), Size)
+	return output[:Size]
+}
+
+func TestSum256(t *testing.T) {
+	for _, c := range readTestVectors(t).Cases {
+		t.Run(strconv.Itoa(c.InputLen), func(t *testing.T) {
+			sum := Sum256(testInput(c.InputLen))
+			assert.Equal(t, hex.EncodeToString(wantHash(t, c.Hash)), hex.EncodeToString(sum[:]))
+		})
+	}
+}
+
+// TestStreaming writes each test vector's input in pieces of various sizes,
+// so that writes end within and exactly on block and chunk boundaries.
+func TestStreaming(t *testing.T) {
+	pieceSizes := []int{1, 7, blockLen - 1, blockLen, blockLen + 1, chunkLen - 1, chunkLen, chunkLen + 1, 3*chunkLen + 5}
+	for _, c := range readTestVectors(t).Cases {
+		input := testInput(c.InputLen)
+		want := hex.EncodeToString(wantHash(t, c.Hash))
+		for _, pieceSize := range pieceSizes {
+			t.Run(strconv.Itoa(c.InputLen)+"/"+strconv.Itoa(pieceSize), func(t *testing.T) {
+				var h hash.Hash = New()
+				for rest := input; len(rest) > 0; {
+					n := min(pieceSize, len(rest))
+					written, err := h.Write(rest[:n])
+					require.NoError(t, err)
+					require.Equal(t, n, written)
+					rest = rest[n:]
+				}
+				assert.Equal(t, want, hex.EncodeToString(h.Sum(nil)))
+				// Sum doesn't change the state.
+				assert.Equal(t, want, hex.EncodeToString(h.Sum(nil)))
+			})
+		}
+	}
+}
+
+func TestReset(t *testing.T) {
+	vectors := readTestVectors(t)
+	last := vectors.Cases[len(vectors.Cases)-1]
+	h := New()
+	h.Write(testInput(5000))
+	h.Reset()
+	h.Write(testInput(last.InputLen))
+	assert.Equal(t, hex.EncodeToString(wantHash(t, last.Hash)), hex.EncodeToString(h.Sum(nil)))
+	assert.Equal(t, Size, h.Size())
+	assert.Equal(t, blockLen, h.BlockSize())
+}
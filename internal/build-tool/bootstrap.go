@@ -0,0 +1,390 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildtool
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// A Bootstrapper installs a tool in the toolchain directory, downloading and
+// building it if need be, and records it in toolchain.toml.  Each tool
+// registers its Bootstrapper from an init function, and
+// `build-tool bootstrap <name>` runs it.
+type Bootstrapper interface {
+	// Command is the tool's name on the command line, e.g., "capnproto".
+	Command() string
+	// Bootstrap installs the tool, unless config.toml names an executable
+	// or the toolchain already has the configured version.
+	Bootstrap(buildToolConfig *RuntimeConfigBuildTool) ([]string, error)
+}
+
+var bootstrappers = make(map[string]Bootstrapper)
+
+func registerBootstrapper(bootstrapper Bootstrapper) {
+	command := bootstrapper.Command()
+	if _, exists := bootstrappers[command]; exists {
+		panic("Bootstrapper registered twice: " + command)
+	}
+	bootstrappers[command] = bootstrapper
+}
+
+// BootstrapperCommands returns the names of the tools which can be
+// bootstrapped, sorted.
+func BootstrapperCommands() []string {
+	commands := make([]string, 0, len(bootstrappers))
+	for command := range bootstrappers {
+		commands = append(commands, command)
+	}
+	slices.Sort(commands)
+	return commands
+}
+
+// Bootstrap runs the Bootstrapper for the tool named command.
+func Bootstrap(buildToolConfig *RuntimeConfigBuildTool, command string) ([]string, error) {
+	bootstrapper, ok := bootstrappers[command]
+	if !ok {
+		return nil, fmt.Errorf("Unknown tool %q; expected one of: %s", command, strings.Join(BootstrapperCommands(), ", "))
+	}
+	return bootstrapper.Bootstrap(buildToolConfig)
+}
+
+// archiveTool is the Bootstrapper for a tool which is downloaded as an archive
+// listed in downloads.toml, and configured by a runtimeConfigTool.  Prebuilt
+// tools are extracted as they are; the others are extracted, patched and then
+// built in place.  Each tool only describes how it differs.
+type archiveTool struct {
+	command string
+	// config returns the tool's configuration, e.g., buildToolConfig.Bison.
+	config func(buildToolConfig *RuntimeConfigBuildTool) *runtimeConfigTool
+	// downloads returns the tool's table in downloads.toml, e.g.,
+	// &downloads.Bison.
+	downloads func(downloads *DownloadsTomlTopLevel) *DownloadsTomlTool
+	// toolchainToml returns the tool's table in toolchain.toml, e.g.,
+	// &toolchainToml.Bison.
+	toolchainToml func(toolchainToml *ToolchainTomlTopLevel) **ToolchainTomlTool
+	// platform maps GOOS and GOARCH to the Arch and Os in the tool's
+	// filename template.  If it is nil, they are used as they are.
+	platform func(goos string, goarch string) (arch string, os string)
+	// archiveDir returns the top-level directory in the archive, whose
+	// contents are extracted into the versioned directory.  If it is nil,
+	// the archive's directory is the versioned directory's name.
+	archiveDir func(tool *runtimeConfigTool) string
+	// executable is the path of the tool's executable within the
+	// versioned directory, e.g., "tests/bison".
+	executable string
+	// build builds the tool once it has been extracted and patched.  It is
+	// nil for prebuilt tools.
+	build func(b *toolBuild) ([]string, error)
+	// system, if not nil, is how to find the tool installed on the
+	// system, which --prefer-system uses instead of downloading it.
+	system *systemTool
+}
+
+// toolBuild is passed to an archiveTool's build function.
+type toolBuild struct {
+	buildToolConfig *RuntimeConfigBuildTool
+	tool            *runtimeConfigTool
+	// modules, if build sets it, is recorded as the tool's Go modules in
+	// toolchain.toml.
+	modules []string
+}
+
+// startStep starts timing a step of the build.  See
+// BootstrapStats.startStep.
+func (b *toolBuild) startStep(step string) func() {
+	return b.buildToolConfig.Stats.startStep(b.tool.Name, step)
+}
+
+// text/template uses these struct fields from a separate package, so they must be in PascalCase.
+type toolTemplateValues struct {
+	Arch     string
+	Filename string
+	Os       string
+	Version  string
+}
+
+func (a *archiveTool) Command() string {
+	return a.command
+}
+
+func (a *archiveTool) Bootstrap(buildToolConfig *RuntimeConfigBuildTool) ([]string, error) {
+	messages := make([]string, 0, 5)
+	if buildToolConfig.Directories == nil {
+		return messages, fmt.Errorf("buildToolConfig.Directories is nil")
+	}
+	tool := a.config(buildToolConfig)
+	if tool == nil {
+		return messages, fmt.Errorf("No configuration for %s", a.command)
+	}
+	downloadFile, downloadUrl, err := a.getDownload(tool)
+	if err != nil {
+		messages = append(messages, fmt.Sprintf("Failed to get %s configuration", tool.Name))
+		return messages, err
+	}
+	downloadFileInfo := tool.files[downloadFile]
+	patches, err := readPatches(tool.patchDir)
+	if err != nil {
+		return messages, err
+	}
+	if tool.Executable != "" {
+		executableExists, err := fileExistsAtPath(tool.Executable)
+		if err != nil {
+			return messages, err
+		}
+		if !executableExists {
+			return messages, fmt.Errorf("User-specified %s executable %s does not exist.", tool.Name, tool.Executable)
+		}
+		messages = append(messages, fmt.Sprintf("Skipping download and installation of %s because %s (from config.toml) exists", tool.Name, tool.Executable))
+		return messages, nil
+	}
+	if buildToolConfig.PreferSystem && a.system != nil {
+		found, systemMessages, err := a.useSystemTool(buildToolConfig, tool, patches)
+		messages = append(messages, systemMessages...)
+		if err != nil || found {
+			return messages, err
+		}
+	}
+	if tool.ToolChainExecutable != "" {
+		executableExists, err := fileExistsAtPath(tool.ToolChainExecutable)
+		if err != nil {
+			return messages, err
+		}
+		if executableExists {
+			if tool.version == tool.toolchainVersion && slices.Equal(patchIds(patches), tool.toolchainPatches) {
+				messages = append(messages, fmt.Sprintf("Skipping download and installation of %s because %s (from toolchain) exists", tool.Name, tool.ToolChainExecutable))
+				return messages, nil
+			}
+			messages = append(messages, "The toolchain executable does not match the desired version.  Continuing.")
+		}
+	}
+	unlock, err := lockToolchainDir(buildToolConfig, tool.versionedDir)
+	if err != nil {
+		return messages, err
+	}
+	defer unlock()
+	err = ensureDownloadDirExists(buildToolConfig.Directories.DownloadDir)
+	if err != nil {
+		return messages, err
+	}
+	downloadPath := filepath.Join(buildToolConfig.Directories.DownloadDir, downloadFile)
+	stopTimer := buildToolConfig.Stats.startStep(tool.Name, stepDownload)
+	assetUrl, assetMessages, err := buildToolConfig.github.releaseAssetUrl(tool, downloadFile)
+	stopTimer()
+	messages = append(messages, assetMessages...)
+	if err != nil {
+		return messages, err
+	}
+	downloadUrls := []string{downloadUrl}
+	if assetUrl != "" {
+		downloadUrls = []string{assetUrl, downloadUrl}
+	}
+	values := a.templateValues(tool.version, runtime.GOOS, runtime.GOARCH)
+	values.Filename = downloadFile
+	mirrorUrls, err := expandMirrorUrlTemplates(tool.mirrorUrlTemplates, values)
+	if err != nil {
+		return messages, err
+	}
+	downloadMessages, err := downloadAndVerify(buildToolConfig, tool.Name, append(downloadUrls, mirrorUrls...), downloadPath, downloadFileInfo)
+	messages = append(messages, downloadMessages...)
+	if err != nil {
+		return messages, err
+	}
+	build := &toolBuild{
+		buildToolConfig: buildToolConfig,
+		tool:            tool,
+	}
+	executable := filepath.Join(tool.toolchainDir, a.executable)
+	if a.build == nil {
+		extractMessages, err := a.extractPrebuilt(build, downloadPath, executable)
+		messages = append(messages, extractMessages...)
+		if err != nil {
+			return messages, err
+		}
+	} else {
+		// An earlier build, with other patches or interrupted, leaves
+		// files behind which the patches don't expect, so start from a
+		// clean tree.
+		err = os.RemoveAll(tool.toolchainDir)
+		if err != nil {
+			return messages, err
+		}
+		extractMessages, err := a.extractArchive(build, downloadPath)
+		messages = append(messages, extractMessages...)
+		if err != nil {
+			messages = append(messages, fmt.Sprintf("Failed to extract %s", downloadPath))
+			return messages, err
+		}
+		patchMessages, err := applyPatches(patches, tool.toolchainDir)
+		messages = append(messages, patchMessages...)
+		if err != nil {
+			return messages, err
+		}
+		buildMessages, err := a.build(build)
+		messages = append(messages, buildMessages...)
+		if err != nil {
+			return messages, err
+		}
+	}
+	toolchainTool := &ToolchainTomlTool{
+		Executable: filepath.Join(tool.versionedDir, a.executable),
+		Modules:    build.modules,
+		Patches:    patchIds(patches),
+		Version:    tool.version,
+	}
+	buildToolConfig.Stats.recordDiskUsage(tool.Name, tool.toolchainDir)
+	err = a.updateToolchainToml(buildToolConfig.Directories.ToolChainDir, toolchainTool)
+	return messages, err
+}
+
+// extractPrebuilt extracts a prebuilt tool, unless its executable is already
+// there.
+func (a *archiveTool) extractPrebuilt(build *toolBuild, downloadPath string, executable string) ([]string, error) {
+	messages := make([]string, 0, 1)
+	executableExists, err := fileExistsAtPath(executable)
+	if err != nil {
+		return messages, err
+	}
+	if executableExists {
+		messages = append(messages, fmt.Sprintf("Refusing to install %s because %s exists", build.tool.Name, executable))
+	} else {
+		extractMessages, err := a.extractArchive(build, downloadPath)
+		messages = append(messages, extractMessages...)
+		if err != nil {
+			messages = append(messages, fmt.Sprintf("Failed to extract %s", downloadPath))
+			return messages, err
+		}
+	}
+	// Update the modified time of the executable.
+	// This is a hack to satisfy `make`.
+	// The Makefile looks at the executable.  If its modified time is
+	// current, then make will not invoke the target.  If its modified time
+	// is not updated, then make will extract the tool every time the
+	// target is invoked.
+	executableExists, err = fileExistsAtPath(executable)
+	if err != nil {
+		return messages, err
+	}
+	if executableExists {
+		err = setFileModifiedTimeToNow(executable)
+	}
+	return messages, err
+}
+
+// extractArchive extracts the contents of the archive's top-level directory
+// into the tool's versioned directory, after checking there's room for them.
+func (a *archiveTool) extractArchive(build *toolBuild, downloadPath string) ([]string, error) {
+	archiveDir := build.tool.versionedDir
+	if a.archiveDir != nil {
+		archiveDir = a.archiveDir(build.tool)
+	}
+	prefix := ensureTrailingSlash(archiveDir)
+	destinationDir := build.tool.toolchainDir
+	downloadFile := filepath.Base(downloadPath)
+	messages, err := ensureExtractSpace(destinationDir, downloadFile, build.tool.files[downloadFile])
+	if err != nil {
+		messages = append(messages, fmt.Sprintf("Not enough disk space to extract %s", downloadFile))
+		return messages, err
+	}
+	filter := func(filePath string) bool {
+		acceptable := strings.HasPrefix(filePath, prefix)
+		if !acceptable {
+			// TODO: Figure out how to get this in the messages slice.
+			log.Printf("Rejecting file with invalid prefix: %s\n", filePath)
+		}
+		return acceptable
+	}
+	transform := func(filePath string) string {
+		return filepath.Join(destinationDir, strings.TrimPrefix(filePath, prefix))
+	}
+	stopTimer := build.startStep(stepExtract)
+	defer stopTimer()
+	return messages, extractTarArchive(downloadPath, build.tool.archiveFormat, filter, transform)
+}
+
+// templateValues returns the values for the tool's filename template; the
+// download URL template also gets the Filename.
+func (a *archiveTool) templateValues(version string, goos string, goarch string) toolTemplateValues {
+	values := toolTemplateValues{
+		Arch:    goarch,
+		Os:      goos,
+		Version: version,
+	}
+	if a.platform != nil {
+		values.Arch, values.Os = a.platform(goos, goarch)
+	}
+	return values
+}
+
+// getDownload populates the tool's filename and download URL templates.
+func (a *archiveTool) getDownload(tool *runtimeConfigTool) (string, string, error) {
+	values := a.templateValues(tool.version, runtime.GOOS, runtime.GOARCH)
+	downloadFile, err := executeToolTemplate("filename", tool.filenameTemplate, values)
+	if err != nil {
+		return "", "", err
+	}
+	values.Filename = downloadFile
+	downloadUrl, err := executeToolTemplate("downloadUrl", tool.downloadUrlTemplate, values)
+	if err != nil {
+		return "", "", err
+	}
+	if tool.files[downloadFile] == (runtimeConfigFile{}) {
+		return "", "", fmt.Errorf("File size and digests not found in downloads.toml for %s", downloadFile)
+	}
+	return downloadFile, downloadUrl, nil
+}
+
+func executeToolTemplate(name string, text string, values any) (string, error) {
+	parsedTemplate, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buffer bytes.Buffer
+	err = parsedTemplate.Execute(&buffer, values)
+	if err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+// updateToolchainToml records the installed tool in toolchain.toml, keeping
+// its Modules if toolchainTool leaves them unset.  Patches are always
+// replaced, so that they are cleared once a tool's patches are removed.
+func (a *archiveTool) updateToolchainToml(toolchainDir string, toolchainTool *ToolchainTomlTool) error {
+	return UpdateToolchainToml(toolchainDir, func(toolchainToml *ToolchainTomlTopLevel) {
+		entry := a.toolchainToml(toolchainToml)
+		if *entry == nil {
+			*entry = new(ToolchainTomlTool)
+		}
+		(*entry).Executable = toolchainTool.Executable
+		if toolchainTool.Modules != nil {
+			(*entry).Modules = toolchainTool.Modules
+		}
+		(*entry).Patches = nil
+		if len(toolchainTool.Patches) > 0 {
+			(*entry).Patches = toolchainTool.Patches
+		}
+		(*entry).Version = toolchainTool.Version
+	})
+}
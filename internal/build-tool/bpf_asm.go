@@ -20,7 +20,6 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
 )
 
@@ -29,13 +28,27 @@ type bpfAsmConfig struct {
 	executable          string
 	flexExecutable      string
 	makePath            string
+	sandbox             *runtimeConfigSandbox
+	static              *runtimeConfigStatic
 	toolchainDir        string
 	toolchainExecutable string
 	toolchainVersion    string
 	version             string
 }
 
-func BootstrapBpfAsm(buildToolConfig *RuntimeConfigBuildTool) ([]string, error) {
+// bpfAsmBootstrapper builds bpf_asm from part of the Linux source, rather than
+// from an archive of its own, so it isn't an archiveTool.
+type bpfAsmBootstrapper struct{}
+
+func init() {
+	registerBootstrapper(bpfAsmBootstrapper{})
+}
+
+func (bpfAsmBootstrapper) Command() string {
+	return "bpf_asm"
+}
+
+func (bpfAsmBootstrapper) Bootstrap(buildToolConfig *RuntimeConfigBuildTool) ([]string, error) {
 	messages := make([]string, 0, 5)
 	bpfAsmConfig, err := getBpfAsmConfig(buildToolConfig)
 	if err != nil {
@@ -71,9 +84,14 @@ func BootstrapBpfAsm(buildToolConfig *RuntimeConfigBuildTool) ([]string, error)
 			}
 		}
 	}
+	unlock, err := lockToolchainDir(buildToolConfig, bpfAsmConfig.toolchainDir)
+	if err != nil {
+		return messages, err
+	}
+	defer unlock()
 	var downloadMessages []string
 	var downloadPath string
-	downloadPath, downloadMessages, err = downloadAndVerifyLinuxTarball(buildToolConfig)
+	downloadPath, downloadMessages, err = downloadAndVerifyLinuxTarball(buildToolConfig, "bpf_asm")
 	if err != nil {
 		messages = append(messages, downloadMessages[:]...)
 		return messages, err
@@ -83,17 +101,30 @@ func BootstrapBpfAsm(buildToolConfig *RuntimeConfigBuildTool) ([]string, error)
 	desiredPrefixes = append(desiredPrefixes, "linux-"+buildToolConfig.linux.version+"/tools/build/")
 	desiredPrefixes = append(desiredPrefixes, "linux-"+buildToolConfig.linux.version+"/tools/scripts/")
 	commonPrefix := "linux-" + buildToolConfig.linux.version
+	// The Linux source's ExtractedSize is that of the directories above.
+	downloadFile := filepath.Base(downloadPath)
+	spaceMessages, err := ensureExtractSpace(bpfAsmConfig.toolchainDir, downloadFile, buildToolConfig.linux.files[downloadFile])
+	messages = append(messages, spaceMessages...)
+	if err != nil {
+		messages = append(messages, "Not enough disk space to extract and build bpf_asm")
+		return messages, err
+	}
 	filterLinuxTarXz := filterLinuxTarXzFactory(desiredPrefixes)
 	transformLinuxTarXz := transformLinuxTarXzFactory(bpfAsmConfig.toolchainDir, len(commonPrefix))
-	err = extractTarXz(downloadPath, filterLinuxTarXz, transformLinuxTarXz)
+	stopTimer := buildToolConfig.Stats.startStep("bpf_asm", stepExtract)
+	err = extractTarArchive(downloadPath, buildToolConfig.linux.archiveFormat, filterLinuxTarXz, transformLinuxTarXz)
+	stopTimer()
 	if err != nil {
 		messages = append(messages, fmt.Sprintf("Failed to extract %s", downloadPath))
 		return messages, err
 	}
+	stopTimer = buildToolConfig.Stats.startStep("bpf_asm", stepMake)
 	err = makeBpfAsm(bpfAsmConfig)
+	stopTimer()
 	if err != nil {
 		return messages, err
 	}
+	buildToolConfig.Stats.recordDiskUsage("bpf_asm", bpfAsmConfig.toolchainDir)
 	toolchainTomlExecutable, err := filepath.Rel(buildToolConfig.Directories.ToolChainDir, filepath.Join(bpfAsmConfig.makePath, "bpf_asm"))
 	if err != nil {
 		return messages, err
@@ -151,6 +182,8 @@ func getBpfAsmConfig(buildToolConfig *RuntimeConfigBuildTool) (*bpfAsmConfig, er
 	bpfAsmConfig.executable = executable
 	bpfAsmConfig.flexExecutable = flexExecutable
 	bpfAsmConfig.makePath = makePath
+	bpfAsmConfig.sandbox = buildToolConfig.sandbox
+	bpfAsmConfig.static = buildToolConfig.static
 	bpfAsmConfig.toolchainDir = toolchainDir
 	bpfAsmConfig.toolchainExecutable = toolchainExecutable
 	bpfAsmConfig.toolchainVersion = toolchainVersion
@@ -159,8 +192,10 @@ func getBpfAsmConfig(buildToolConfig *RuntimeConfigBuildTool) (*bpfAsmConfig, er
 }
 
 func makeBpfAsm(config *bpfAsmConfig) error {
-	cmd := exec.Command("make")
-	cmd.Dir = config.makePath
+	cmd, err := config.sandbox.command(config.makePath, "make")
+	if err != nil {
+		return err
+	}
 	lex := config.flexExecutable
 	if lex != "flex" {
 		lexVar := "LEX=" + lex
@@ -172,24 +207,25 @@ func makeBpfAsm(config *bpfAsmConfig) error {
 		cmd.Args = append(cmd.Args, yaccVar)
 	}
 	cmd.Args = append(cmd.Args, "bpf_asm")
-	cmd.Env = append(cmd.Env, os.Environ()...)
+	if config.static.enabled {
+		if config.static.cc != "" {
+			cmd.Args = append(cmd.Args, "CC="+config.static.cc)
+		}
+		// The Makefile appends to LDFLAGS, so this goes in the environment
+		// rather than on the command line.
+		cmd.Env = append(cmd.Env, "LDFLAGS=-static")
+	}
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
 
 func updateBpfAsmToolchainToml(toolchainDir string, executable string, version string) error {
-	toolchainTomlTopLevel, err := ReadToolchainToml(toolchainDir)
-	if err != nil {
-		if !os.IsNotExist(err) {
-			return err
+	return UpdateToolchainToml(toolchainDir, func(toolchainToml *ToolchainTomlTopLevel) {
+		if toolchainToml.BpfAsm == nil {
+			toolchainToml.BpfAsm = new(ToolchainTomlTool)
 		}
-		toolchainTomlTopLevel = new(ToolchainTomlTopLevel)
-	}
-	if toolchainTomlTopLevel.BpfAsm == nil {
-		toolchainTomlTopLevel.BpfAsm = new(ToolchainTomlTool)
-	}
-	toolchainTomlTopLevel.BpfAsm.Executable = executable
-	toolchainTomlTopLevel.BpfAsm.Version = version
-	return WriteToolchainToml(toolchainDir, toolchainTomlTopLevel)
+		toolchainToml.BpfAsm.Executable = executable
+		toolchainToml.BpfAsm.Version = version
+	})
 }
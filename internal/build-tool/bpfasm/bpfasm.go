@@ -0,0 +1,534 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bpfasm is an assembler for classic BPF, accepting the syntax of the
+// Linux kernel's bpf_asm (tools/bpf/bpf_exp.y) and producing the same output
+// as bpf_asm -c.  It supports what a seccomp filter can use; the packet
+// ancillary data extensions, e.g., "ld #proto", are not supported.
+package bpfasm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Instruction classes, sizes, modes and operations, from linux/bpf_common.h.
+const (
+	classLd   = 0x00
+	classLdx  = 0x01
+	classSt   = 0x02
+	classStx  = 0x03
+	classAlu  = 0x04
+	classJmp  = 0x05
+	classRet  = 0x06
+	classMisc = 0x07
+
+	sizeW = 0x00
+	sizeH = 0x08
+	sizeB = 0x10
+
+	modeImm = 0x00
+	modeAbs = 0x20
+	modeInd = 0x40
+	modeMem = 0x60
+	modeLen = 0x80
+	modeMsh = 0xa0
+
+	aluAdd = 0x00
+	aluSub = 0x10
+	aluMul = 0x20
+	aluDiv = 0x30
+	aluOr  = 0x40
+	aluAnd = 0x50
+	aluLsh = 0x60
+	aluRsh = 0x70
+	aluNeg = 0x80
+	aluMod = 0x90
+	aluXor = 0xa0
+
+	jmpJa   = 0x00
+	jmpJeq  = 0x10
+	jmpJgt  = 0x20
+	jmpJge  = 0x30
+	jmpJset = 0x40
+
+	srcK = 0x00
+	srcX = 0x08
+	srcA = 0x10 // Only for ret.
+
+	miscTax = 0x00
+	miscTxa = 0x80
+
+	// memWords is the number of scratch memory words, M[0] to M[15].
+	memWords = 16
+)
+
+// An Instruction is a classic BPF instruction, laid out as struct
+// sock_filter.
+type Instruction struct {
+	Code uint16
+	Jt   uint8
+	Jf   uint8
+	K    uint32
+}
+
+// An Error is a syntax error, with the position in the source.
+type Error struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+}
+
+// A jumpTarget is a label an instruction jumps to, resolved once every label
+// is known.
+type jumpTarget struct {
+	label string
+	file  string
+	line  int
+}
+
+type assembler struct {
+	program []Instruction
+	labels  map[string]int
+	// Indexed by instruction; nil where there is no jump.
+	jt []*jumpTarget
+	jf []*jumpTarget
+	ja []*jumpTarget
+
+	file string
+	line int
+}
+
+var (
+	lineMarkerPattern = regexp.MustCompile(`^#\s*(?:line\s+)?(\d+)\s+"([^"]*)"`)
+	labelPattern      = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*:`)
+	blockComment      = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	mshPattern        = regexp.MustCompile(`^4\*\(\[(.+)\]&0xf\)$`)
+)
+
+// Assemble assembles source, whose name is used in errors.  The source
+// should already have been run through the C preprocessor; its line markers
+// are used to report errors against the original file.
+func Assemble(name string, source io.Reader) ([]Instruction, error) {
+	text, err := io.ReadAll(source)
+	if err != nil {
+		return nil, err
+	}
+	// Keep the line numbers of what follows a block comment.
+	withoutComments := blockComment.ReplaceAllStringFunc(string(text), func(comment string) string {
+		return strings.Repeat("\n", strings.Count(comment, "\n"))
+	})
+	a := &assembler{
+		labels: make(map[string]int),
+		file:   name,
+	}
+	scanner := bufio.NewScanner(strings.NewReader(withoutComments))
+	for scanner.Scan() {
+		a.line++
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			// A line marker from the preprocessor, which gives the
+			// position of the next line.
+			if match := lineMarkerPattern.FindStringSubmatch(line); match != nil {
+				next, _ := strconv.Atoi(match[1])
+				a.line = next - 1
+				a.file = match[2]
+			}
+			continue
+		}
+		line, _, _ = strings.Cut(line, ";")
+		line = strings.TrimSpace(line)
+		for {
+			match := labelPattern.FindStringSubmatch(line)
+			if match == nil {
+				break
+			}
+			if _, ok := a.labels[match[1]]; ok {
+				return nil, a.errorf("label %s is defined more than once", match[1])
+			}
+			a.labels[match[1]] = len(a.program)
+			line = strings.TrimSpace(line[len(match[0]):])
+		}
+		if line == "" {
+			continue
+		}
+		err = a.instruction(line)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+	err = a.resolveJumps()
+	if err != nil {
+		return nil, err
+	}
+	return a.program, nil
+}
+
+// WriteC writes program in the format of bpf_asm -c, one struct sock_filter
+// initializer per line.
+func WriteC(w io.Writer, program []Instruction) error {
+	for _, instruction := range program {
+		_, err := fmt.Fprintf(w, "{ 0x%x, %d, %d, 0x%08x },\n", instruction.Code, instruction.Jt, instruction.Jf, instruction.K)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *assembler) errorf(format string, args ...any) error {
+	return &Error{File: a.file, Line: a.line, Message: fmt.Sprintf(format, args...)}
+}
+
+func (a *assembler) emit(code uint16, k uint32) {
+	a.program = append(a.program, Instruction{Code: code, K: k})
+	a.jt = append(a.jt, nil)
+	a.jf = append(a.jf, nil)
+	a.ja = append(a.ja, nil)
+}
+
+func (a *assembler) target(label string) *jumpTarget {
+	return &jumpTarget{label: label, file: a.file, line: a.line}
+}
+
+func (a *assembler) instruction(line string) error {
+	fields := strings.Fields(line)
+	mnemonic := fields[0]
+	// Spaces within an operand, e.g., "[x + 4]", don't matter.
+	rest := strings.Join(fields[1:], "")
+	var operands []string
+	if rest != "" {
+		operands = strings.Split(rest, ",")
+	}
+	switch mnemonic {
+	case "ld", "ldh", "ldb":
+		size := map[string]uint16{"ld": sizeW, "ldh": sizeH, "ldb": sizeB}[mnemonic]
+		return a.load(mnemonic, classLd, size, operands)
+	case "ldi":
+		return a.loadImmediate(mnemonic, classLd, operands)
+	case "ldx", "ldxb":
+		size := map[string]uint16{"ldx": sizeW, "ldxb": sizeB}[mnemonic]
+		return a.load(mnemonic, classLdx, size, operands)
+	case "ldxi":
+		return a.loadImmediate(mnemonic, classLdx, operands)
+	case "st", "stx":
+		if len(operands) != 1 {
+			return a.errorf("%s takes one operand", mnemonic)
+		}
+		index, ok, err := a.memory(operands[0])
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return a.errorf("%s needs a scratch memory operand, M[k]", mnemonic)
+		}
+		class := uint16(classSt)
+		if mnemonic == "stx" {
+			class = classStx
+		}
+		a.emit(class, index)
+	case "jmp", "ja":
+		if len(operands) != 1 {
+			return a.errorf("%s takes one label", mnemonic)
+		}
+		a.emit(classJmp|jmpJa, 0)
+		a.ja[len(a.program)-1] = a.target(operands[0])
+	case "jeq", "jgt", "jge", "jset":
+		op := map[string]uint16{"jeq": jmpJeq, "jgt": jmpJgt, "jge": jmpJge, "jset": jmpJset}[mnemonic]
+		return a.conditionalJump(mnemonic, op, false, operands)
+	case "jneq", "jne", "jlt", "jle":
+		// These are the opposites of jeq, jge and jgt, jumping when the
+		// comparison is false.
+		op := map[string]uint16{"jneq": jmpJeq, "jne": jmpJeq, "jlt": jmpJge, "jle": jmpJgt}[mnemonic]
+		return a.conditionalJump(mnemonic, op, true, operands)
+	case "add", "sub", "mul", "div", "mod", "and", "or", "xor", "lsh", "rsh":
+		op := map[string]uint16{
+			"add": aluAdd, "sub": aluSub, "mul": aluMul, "div": aluDiv, "mod": aluMod,
+			"and": aluAnd, "or": aluOr, "xor": aluXor, "lsh": aluLsh, "rsh": aluRsh,
+		}[mnemonic]
+		if len(operands) != 1 {
+			return a.errorf("%s takes one operand", mnemonic)
+		}
+		if isRegister(operands[0], "x") {
+			a.emit(classAlu|op|srcX, 0)
+			return nil
+		}
+		k, err := a.immediate(operands[0])
+		if err != nil {
+			return err
+		}
+		if k == 0 && (op == aluDiv || op == aluMod) {
+			return a.errorf("%s by zero", mnemonic)
+		}
+		a.emit(classAlu|op|srcK, k)
+	case "neg":
+		if len(operands) != 0 {
+			return a.errorf("neg takes no operands")
+		}
+		a.emit(classAlu|aluNeg, 0)
+	case "tax", "txa":
+		if len(operands) != 0 {
+			return a.errorf("%s takes no operands", mnemonic)
+		}
+		if mnemonic == "tax" {
+			a.emit(classMisc|miscTax, 0)
+		} else {
+			a.emit(classMisc|miscTxa, 0)
+		}
+	case "ret":
+		if len(operands) != 1 {
+			return a.errorf("ret takes one operand")
+		}
+		switch {
+		case isRegister(operands[0], "a"):
+			a.emit(classRet|srcA, 0)
+		case isRegister(operands[0], "x"):
+			a.emit(classRet|srcX, 0)
+		default:
+			k, err := a.immediate(operands[0])
+			if err != nil {
+				return err
+			}
+			a.emit(classRet|srcK, k)
+		}
+	default:
+		return a.errorf("unknown instruction %q", mnemonic)
+	}
+	return nil
+}
+
+// load assembles ld, ldh, ldb, ldx and ldxb, whose addressing modes depend
+// on the size and class.
+func (a *assembler) load(mnemonic string, class uint16, size uint16, operands []string) error {
+	if len(operands) != 1 {
+		return a.errorf("%s takes one operand", mnemonic)
+	}
+	operand := operands[0]
+	if match := mshPattern.FindStringSubmatch(operand); match != nil {
+		// 4*([k]&0xf), for the IP header length.
+		if class != classLdx {
+			return a.errorf("%s does not support 4*([k]&0xf)", mnemonic)
+		}
+		k, err := a.number(match[1])
+		if err != nil {
+			return err
+		}
+		a.emit(classLdx|sizeB|modeMsh, k)
+		return nil
+	}
+	if size == sizeB && class == classLdx {
+		return a.errorf("ldxb only supports 4*([k]&0xf)")
+	}
+	if size == sizeW {
+		if operand == "#len" || operand == "#pktlen" {
+			a.emit(class|sizeW|modeLen, 0)
+			return nil
+		}
+		if index, ok, err := a.memory(operand); err != nil {
+			return err
+		} else if ok {
+			a.emit(class|modeMem, index)
+			return nil
+		}
+		if strings.HasPrefix(operand, "#") {
+			k, err := a.immediate(operand)
+			if err != nil {
+				return err
+			}
+			a.emit(class|modeImm, k)
+			return nil
+		}
+	}
+	if class == classLd && strings.HasPrefix(operand, "[") && strings.HasSuffix(operand, "]") {
+		address := operand[1 : len(operand)-1]
+		if address == "x" || address == "%x" {
+			a.emit(classLd|size|modeInd, 0)
+			return nil
+		}
+		if offset, ok := strings.CutPrefix(address, "x+"); ok {
+			k, err := a.number(offset)
+			if err != nil {
+				return err
+			}
+			a.emit(classLd|size|modeInd, k)
+			return nil
+		}
+		if offset, ok := strings.CutPrefix(address, "%x+"); ok {
+			k, err := a.number(offset)
+			if err != nil {
+				return err
+			}
+			a.emit(classLd|size|modeInd, k)
+			return nil
+		}
+		k, err := a.number(address)
+		if err != nil {
+			return err
+		}
+		a.emit(classLd|size|modeAbs, k)
+		return nil
+	}
+	return a.errorf("unsupported operand %q for %s", operand, mnemonic)
+}
+
+func (a *assembler) loadImmediate(mnemonic string, class uint16, operands []string) error {
+	if len(operands) != 1 {
+		return a.errorf("%s takes one operand", mnemonic)
+	}
+	k, err := a.immediate(operands[0])
+	if err != nil {
+		return err
+	}
+	a.emit(class|modeImm, k)
+	return nil
+}
+
+// conditionalJump assembles a comparison of A with #k or x.  With one label
+// the jump is taken when the comparison is true, or with inverted when it is
+// false; otherwise it falls through.  With two labels, which inverted
+// instructions don't accept, they are the true and false targets.
+func (a *assembler) conditionalJump(mnemonic string, op uint16, inverted bool, operands []string) error {
+	if len(operands) != 2 && (inverted || len(operands) != 3) {
+		if inverted {
+			return a.errorf("%s takes a value and one label", mnemonic)
+		}
+		return a.errorf("%s takes a value and one or two labels", mnemonic)
+	}
+	var code uint16
+	var k uint32
+	if isRegister(operands[0], "x") {
+		code = classJmp | op | srcX
+	} else {
+		var err error
+		k, err = a.immediate(operands[0])
+		if err != nil {
+			return err
+		}
+		code = classJmp | op | srcK
+	}
+	a.emit(code, k)
+	index := len(a.program) - 1
+	switch {
+	case inverted:
+		a.jf[index] = a.target(operands[1])
+	case len(operands) == 2:
+		a.jt[index] = a.target(operands[1])
+	default:
+		a.jt[index] = a.target(operands[1])
+		a.jf[index] = a.target(operands[2])
+	}
+	return nil
+}
+
+// resolveJumps fills in jump offsets.  Classic BPF only jumps forward, and
+// conditional jumps only as far as 255 instructions.
+func (a *assembler) resolveJumps() error {
+	offset := func(index int, target *jumpTarget, limit int) (int, error) {
+		labelIndex, ok := a.labels[target.label]
+		if !ok {
+			return 0, &Error{File: target.file, Line: target.line, Message: fmt.Sprintf("undefined label %s", target.label)}
+		}
+		if labelIndex >= len(a.program) {
+			return 0, &Error{File: target.file, Line: target.line, Message: fmt.Sprintf("label %s is not followed by an instruction", target.label)}
+		}
+		result := labelIndex - (index + 1)
+		if result < 0 {
+			return 0, &Error{File: target.file, Line: target.line, Message: fmt.Sprintf("jump to %s is backward", target.label)}
+		}
+		if result > limit {
+			return 0, &Error{File: target.file, Line: target.line, Message: fmt.Sprintf("jump to %s is %d instructions, more than %d", target.label, result, limit)}
+		}
+		return result, nil
+	}
+	for index := range a.program {
+		if target := a.jt[index]; target != nil {
+			jt, err := offset(index, target, 0xff)
+			if err != nil {
+				return err
+			}
+			a.program[index].Jt = uint8(jt)
+		}
+		if target := a.jf[index]; target != nil {
+			jf, err := offset(index, target, 0xff)
+			if err != nil {
+				return err
+			}
+			a.program[index].Jf = uint8(jf)
+		}
+		if target := a.ja[index]; target != nil {
+			k, err := offset(index, target, 0x7fffffff)
+			if err != nil {
+				return err
+			}
+			a.program[index].K = uint32(k)
+		}
+	}
+	return nil
+}
+
+// memory parses a scratch memory operand, M[k].
+func (a *assembler) memory(operand string) (uint32, bool, error) {
+	inner, ok := strings.CutPrefix(operand, "M[")
+	if !ok || !strings.HasSuffix(inner, "]") {
+		return 0, false, nil
+	}
+	index, err := a.number(inner[:len(inner)-1])
+	if err != nil {
+		return 0, true, err
+	}
+	if index >= memWords {
+		return 0, true, a.errorf("scratch memory M[%d] is out of range; there are %d words", index, memWords)
+	}
+	return index, true, nil
+}
+
+// immediate parses #k.
+func (a *assembler) immediate(operand string) (uint32, error) {
+	number, ok := strings.CutPrefix(operand, "#")
+	if !ok {
+		return 0, a.errorf("expected #k, found %q", operand)
+	}
+	return a.number(number)
+}
+
+// number parses a decimal, hexadecimal (0x), octal (0) or binary (0b)
+// number.  Negative numbers are stored in two's complement.
+func (a *assembler) number(text string) (uint32, error) {
+	value, err := strconv.ParseInt(text, 0, 64)
+	if err != nil {
+		return 0, a.errorf("invalid number %q", text)
+	}
+	if value < -(1<<31) || value > 1<<32-1 {
+		return 0, a.errorf("%s does not fit in 32 bits", text)
+	}
+	return uint32(value), nil
+}
+
+func isRegister(operand string, register string) bool {
+	return operand == register || operand == "%"+register
+}
@@ -0,0 +1,186 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bpfasm
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assembleString assembles source, and returns the output of WriteC.
+func assembleString(t *testing.T, source string) (string, error) {
+	program, err := Assemble("test.s", strings.NewReader(source))
+	if err != nil {
+		return "", err
+	}
+	var buffer bytes.Buffer
+	require.NoError(t, WriteC(&buffer, program))
+	return buffer.String(), nil
+}
+
+// TestAssembleFilter checks the sandbox's seccomp filter against the output
+// of bpf_asm -c.  testdata/filter_preproc.s is c/filter.s preprocessed on
+// x86_64 without line markers, and testdata/filter.h is bpf_asm's output for
+// it.  After changing c/filter.s, regenerate them, with bpf_asm from
+// `make toolchain-bpf_asm`:
+//
+//	cpp -P -I _build c/filter.s -o testdata/filter_preproc.s
+//	bpf_asm -c < testdata/filter_preproc.s > testdata/filter.h
+//
+// With BPF_ASM set to bpf_asm's path, the assembler is also compared with
+// bpf_asm directly.
+func TestAssembleFilter(t *testing.T) {
+	source, err := os.ReadFile(filepath.Join("testdata", "filter_preproc.s"))
+	require.NoError(t, err)
+	want, err := os.ReadFile(filepath.Join("testdata", "filter.h"))
+	require.NoError(t, err)
+	got, err := assembleString(t, string(source))
+	require.NoError(t, err)
+	assert.Equal(t, string(want), got)
+
+	bpfAsm := os.Getenv("BPF_ASM")
+	if bpfAsm == "" {
+		return
+	}
+	cmd := exec.Command(bpfAsm, "-c")
+	cmd.Stdin = bytes.NewReader(source)
+	output, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, string(output), got, "output differs from %s", bpfAsm)
+}
+
+func TestAssembleJumps(t *testing.T) {
+	cases := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name: "one label",
+			source: `
+				jeq #1, yes
+				ret #0
+			yes:	ret #1
+			`,
+			want: "{ 0x15, 1, 0, 0x00000001 },\n" +
+				"{ 0x6, 0, 0, 0x00000000 },\n" +
+				"{ 0x6, 0, 0, 0x00000001 },\n",
+		},
+		{
+			name: "two labels",
+			source: `
+				jgt x, yes, no
+			no:	ret #0
+			yes:	ret #1
+			`,
+			want: "{ 0x2d, 1, 0, 0x00000000 },\n" +
+				"{ 0x6, 0, 0, 0x00000000 },\n" +
+				"{ 0x6, 0, 0, 0x00000001 },\n",
+		},
+		{
+			name: "inverted",
+			source: `
+				jne #7, no
+				jlt #3, no
+				ret #1
+			no:	ret #0
+			`,
+			want: "{ 0x15, 0, 2, 0x00000007 },\n" +
+				"{ 0x35, 0, 1, 0x00000003 },\n" +
+				"{ 0x6, 0, 0, 0x00000001 },\n" +
+				"{ 0x6, 0, 0, 0x00000000 },\n",
+		},
+		{
+			name: "several labels on one instruction",
+			source: `
+				jmp b
+			a: b:	ret #0
+			`,
+			want: "{ 0x5, 0, 0, 0x00000000 },\n" +
+				"{ 0x6, 0, 0, 0x00000000 },\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := assembleString(t, c.source)
+			require.NoError(t, err)
+			assert.Equal(t, c.want, got)
+		})
+	}
+}
+
+// padding returns n instructions which don't jump.
+func padding(n int) string {
+	return strings.Repeat("ld [0]\n", n)
+}
+
+func TestAssembleJumpLimits(t *testing.T) {
+	// Conditional jumps reach at most 255 instructions.
+	got, err := assembleString(t, "jeq #0, far\n"+padding(255)+"far: ret #0\n")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(got, "{ 0x15, 255, 0, 0x00000000 },\n"), got)
+
+	_, err = assembleString(t, "jeq #0, far\n"+padding(256)+"far: ret #0\n")
+	assert.EqualError(t, err, "test.s:1: jump to far is 256 instructions, more than 255")
+
+	// Unconditional jumps go further.
+	got, err = assembleString(t, "jmp far\n"+padding(1000)+"far: ret #0\n")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(got, "{ 0x5, 0, 0, 0x000003e8 },\n"), got)
+}
+
+func TestAssembleErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		source string
+		err    string
+	}{
+		{"undefined label", "ret #0\njeq #1, nowhere\nret #1\n", "test.s:2: undefined label nowhere"},
+		{"duplicate label", "a: ret #0\na: ret #1\n", "test.s:2: label a is defined more than once"},
+		{"backward jump", "back: ld [0]\njmp back\n", "test.s:2: jump to back is backward"},
+		{"label at end", "jmp end\nend:\n", "test.s:1: label end is not followed by an instruction"},
+		{"two labels on inverted jump", "jne #1, a, b\na: b: ret #0\n", "test.s:1: jne takes a value and one label"},
+		{"missing label", "jeq #1\nret #0\n", "test.s:1: jeq takes a value and one or two labels"},
+		{"unknown instruction", "nop\n", `test.s:1: unknown instruction "nop"`},
+		{"division by zero", "div #0\n", "test.s:1: div by zero"},
+		{"scratch memory", "st M[16]\n", "test.s:1: scratch memory M[16] is out of range; there are 16 words"},
+		{"too big", "ret #0x100000000\n", "test.s:1: 0x100000000 does not fit in 32 bits"},
+		{
+			"line markers",
+			"# 1 \"filter.s\"\n# 20 \"filter.s\"\nret #0\njmp missing\n",
+			"filter.s:21: undefined label missing",
+		},
+		{
+			"block comments",
+			"/* a\n comment */ ret #0\njmp missing\n",
+			"test.s:3: undefined label missing",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := assembleString(t, c.source)
+			assert.EqualError(t, err, c.err)
+		})
+	}
+}
@@ -0,0 +1,238 @@
+{ 0x20, 0, 0, 0x00000004 },
+{ 0x15, 0, 159, 0xc000003e },
+{ 0x20, 0, 0, 0x00000000 },
+{ 0x15, 156, 0, 0x0000002b },
+{ 0x15, 155, 0, 0x00000120 },
+{ 0x15, 154, 0, 0x00000015 },
+{ 0x15, 153, 0, 0x00000025 },
+{ 0x15, 152, 0, 0x00000031 },
+{ 0x15, 151, 0, 0x0000000c },
+{ 0x15, 150, 0, 0x00000050 },
+{ 0x15, 149, 0, 0x0000005a },
+{ 0x15, 148, 0, 0x00000003 },
+{ 0x15, 147, 0, 0x000000e5 },
+{ 0x15, 146, 0, 0x000000e4 },
+{ 0x15, 145, 0, 0x000000e6 },
+{ 0x15, 144, 0, 0x0000002a },
+{ 0x15, 143, 0, 0x00000055 },
+{ 0x15, 142, 0, 0x00000020 },
+{ 0x15, 141, 0, 0x00000021 },
+{ 0x15, 140, 0, 0x00000124 },
+{ 0x15, 139, 0, 0x000000d5 },
+{ 0x15, 138, 0, 0x00000123 },
+{ 0x15, 137, 0, 0x000000e9 },
+{ 0x15, 136, 0, 0x00000119 },
+{ 0x15, 135, 0, 0x000000e8 },
+{ 0x15, 134, 0, 0x0000011c },
+{ 0x15, 133, 0, 0x00000122 },
+{ 0x15, 132, 0, 0x0000003b },
+{ 0x15, 131, 0, 0x00000142 },
+{ 0x15, 130, 0, 0x0000003c },
+{ 0x15, 129, 0, 0x000000e7 },
+{ 0x15, 128, 0, 0x0000010d },
+{ 0x15, 127, 0, 0x00000051 },
+{ 0x15, 126, 0, 0x0000005b },
+{ 0x15, 125, 0, 0x0000010c },
+{ 0x15, 124, 0, 0x00000048 },
+{ 0x15, 123, 0, 0x0000004b },
+{ 0x15, 122, 0, 0x00000049 },
+{ 0x15, 121, 0, 0x00000039 },
+{ 0x15, 120, 0, 0x00000005 },
+{ 0x15, 119, 0, 0x0000008a },
+{ 0x15, 118, 0, 0x0000004a },
+{ 0x15, 117, 0, 0x0000004d },
+{ 0x15, 116, 0, 0x000000ca },
+{ 0x15, 115, 0, 0x0000004f },
+{ 0x15, 114, 0, 0x0000004e },
+{ 0x15, 113, 0, 0x000000d9 },
+{ 0x15, 112, 0, 0x0000006c },
+{ 0x15, 111, 0, 0x0000006b },
+{ 0x15, 110, 0, 0x00000068 },
+{ 0x15, 109, 0, 0x00000073 },
+{ 0x15, 108, 0, 0x00000024 },
+{ 0x15, 107, 0, 0x00000034 },
+{ 0x15, 106, 0, 0x00000079 },
+{ 0x15, 105, 0, 0x0000006f },
+{ 0x15, 104, 0, 0x00000027 },
+{ 0x15, 103, 0, 0x0000006e },
+{ 0x15, 102, 0, 0x0000013e },
+{ 0x15, 101, 0, 0x00000076 },
+{ 0x15, 100, 0, 0x00000078 },
+{ 0x15, 99, 0, 0x00000061 },
+{ 0x15, 98, 0, 0x00000062 },
+{ 0x15, 97, 0, 0x0000007c },
+{ 0x15, 96, 0, 0x00000033 },
+{ 0x15, 95, 0, 0x00000037 },
+{ 0x15, 94, 0, 0x000000ba },
+{ 0x15, 93, 0, 0x00000060 },
+{ 0x15, 92, 0, 0x00000066 },
+{ 0x15, 91, 0, 0x000000fe },
+{ 0x15, 90, 0, 0x000000fd },
+{ 0x15, 89, 0, 0x00000126 },
+{ 0x15, 88, 0, 0x000000ff },
+{ 0x15, 87, 0, 0x0000003e },
+{ 0x15, 86, 0, 0x00000056 },
+{ 0x15, 85, 0, 0x00000109 },
+{ 0x15, 84, 0, 0x00000032 },
+{ 0x15, 83, 0, 0x00000008 },
+{ 0x15, 82, 0, 0x00000006 },
+{ 0x15, 81, 0, 0x00000053 },
+{ 0x15, 80, 0, 0x00000102 },
+{ 0x15, 79, 0, 0x00000019 },
+{ 0x15, 78, 0, 0x0000001a },
+{ 0x15, 77, 0, 0x0000000b },
+{ 0x15, 76, 0, 0x00000023 },
+{ 0x15, 75, 0, 0x00000106 },
+{ 0x15, 74, 0, 0x00000002 },
+{ 0x15, 73, 0, 0x00000101 },
+{ 0x15, 72, 0, 0x00000022 },
+{ 0x15, 71, 0, 0x00000016 },
+{ 0x15, 70, 0, 0x00000125 },
+{ 0x15, 69, 0, 0x00000007 },
+{ 0x15, 68, 0, 0x0000010f },
+{ 0x15, 67, 0, 0x00000011 },
+{ 0x15, 66, 0, 0x0000012e },
+{ 0x15, 65, 0, 0x0000010e },
+{ 0x15, 64, 0, 0x00000012 },
+{ 0x15, 63, 0, 0x00000000 },
+{ 0x15, 62, 0, 0x00000013 },
+{ 0x15, 61, 0, 0x00000059 },
+{ 0x15, 60, 0, 0x0000010b },
+{ 0x15, 59, 0, 0x00000052 },
+{ 0x15, 58, 0, 0x00000108 },
+{ 0x15, 57, 0, 0x00000054 },
+{ 0x15, 56, 0, 0x0000000d },
+{ 0x15, 55, 0, 0x0000007f },
+{ 0x15, 54, 0, 0x0000000e },
+{ 0x15, 53, 0, 0x00000081 },
+{ 0x15, 52, 0, 0x0000000f },
+{ 0x15, 51, 0, 0x00000082 },
+{ 0x15, 50, 0, 0x00000080 },
+{ 0x15, 49, 0, 0x000000cc },
+{ 0x15, 48, 0, 0x000000cb },
+{ 0x15, 47, 0, 0x00000017 },
+{ 0x15, 46, 0, 0x00000028 },
+{ 0x15, 45, 0, 0x000000da },
+{ 0x15, 44, 0, 0x00000026 },
+{ 0x15, 43, 0, 0x000000a0 },
+{ 0x15, 42, 0, 0x00000070 },
+{ 0x15, 41, 0, 0x00000030 },
+{ 0x15, 40, 0, 0x00000083 },
+{ 0x15, 39, 0, 0x0000011a },
+{ 0x15, 38, 0, 0x00000121 },
+{ 0x15, 37, 0, 0x00000004 },
+{ 0x15, 36, 0, 0x00000089 },
+{ 0x15, 35, 0, 0x00000058 },
+{ 0x15, 34, 0, 0x0000010a },
+{ 0x15, 33, 0, 0x00000063 },
+{ 0x15, 32, 0, 0x000000ea },
+{ 0x15, 31, 0, 0x000000de },
+{ 0x15, 30, 0, 0x000000e2 },
+{ 0x15, 29, 0, 0x000000e1 },
+{ 0x15, 28, 0, 0x000000e0 },
+{ 0x15, 27, 0, 0x000000df },
+{ 0x15, 26, 0, 0x0000011b },
+{ 0x15, 25, 0, 0x0000011f },
+{ 0x15, 24, 0, 0x0000011e },
+{ 0x15, 23, 0, 0x00000064 },
+{ 0x15, 22, 0, 0x000000c8 },
+{ 0x15, 21, 0, 0x0000004c },
+{ 0x15, 20, 0, 0x0000005f },
+{ 0x15, 19, 0, 0x0000003f },
+{ 0x15, 18, 0, 0x00000057 },
+{ 0x15, 17, 0, 0x00000107 },
+{ 0x15, 16, 0, 0x00000084 },
+{ 0x15, 15, 0, 0x00000118 },
+{ 0x15, 14, 0, 0x000000eb },
+{ 0x15, 13, 0, 0x0000003a },
+{ 0x15, 12, 0, 0x0000003d },
+{ 0x15, 11, 0, 0x00000001 },
+{ 0x15, 10, 0, 0x00000014 },
+{ 0x15, 9, 0, 0x0000009e },
+{ 0x15, 8, 0, 0x0000001c },
+{ 0x15, 7, 0, 0x00000009 },
+{ 0x15, 6, 0, 0x0000000a },
+{ 0x15, 5, 0, 0x0000002d },
+{ 0x15, 4, 0, 0x0000002f },
+{ 0x15, 3, 0, 0x0000002e },
+{ 0x15, 2, 0, 0x0000002c },
+{ 0x15, 1, 0, 0x00000036 },
+{ 0x5, 0, 0, 0x00000002 },
+{ 0x6, 0, 0, 0x7fff0000 },
+{ 0x6, 0, 0, 0x00050026 },
+{ 0x15, 59, 0, 0x00000038 },
+{ 0x15, 21, 0, 0x00000010 },
+{ 0x15, 36, 0, 0x00000029 },
+{ 0x15, 35, 0, 0x00000035 },
+{ 0x15, 70, 0, 0x00000018 },
+{ 0x15, 69, 0, 0x000000dd },
+{ 0x15, 67, 0, 0x0000005c },
+{ 0x15, 66, 0, 0x000000a1 },
+{ 0x15, 65, 0, 0x0000005d },
+{ 0x15, 64, 0, 0x00000104 },
+{ 0x15, 63, 0, 0x0000005e },
+{ 0x15, 62, 0, 0x000000a5 },
+{ 0x15, 59, 0, 0x000000bf },
+{ 0x15, 58, 0, 0x000000bc },
+{ 0x15, 57, 0, 0x000000c2 },
+{ 0x15, 56, 0, 0x000000c5 },
+{ 0x15, 55, 0, 0x000000c1 },
+{ 0x15, 54, 0, 0x000000be },
+{ 0x15, 53, 0, 0x000000c4 },
+{ 0x15, 52, 0, 0x000000c7 },
+{ 0x15, 49, 0, 0x0000009d },
+{ 0x15, 52, 0, 0x00000065 },
+{ 0x6, 0, 0, 0x00050026 },
+{ 0x20, 0, 0, 0x0000001c },
+{ 0x15, 0, 45, 0x00000000 },
+{ 0x20, 0, 0, 0x00000018 },
+{ 0x15, 39, 0, 0x00005451 },
+{ 0x15, 38, 0, 0x00005450 },
+{ 0x15, 37, 0, 0x00005452 },
+{ 0x15, 36, 0, 0x00005421 },
+{ 0x15, 35, 0, 0x0000541b },
+{ 0x15, 34, 0, 0x00005460 },
+{ 0x15, 41, 0, 0xc0045877 },
+{ 0x15, 40, 0, 0xc0045878 },
+{ 0x15, 38, 0, 0xc020660b },
+{ 0x15, 37, 0, 0x40049409 },
+{ 0x15, 36, 0, 0x4020940d },
+{ 0x15, 35, 0, 0xc0189436 },
+{ 0x6, 0, 0, 0x00050019 },
+{ 0x20, 0, 0, 0x00000014 },
+{ 0x15, 0, 28, 0x00000000 },
+{ 0x20, 0, 0, 0x00000010 },
+{ 0x15, 3, 0, 0x00000002 },
+{ 0x15, 2, 0, 0x0000000a },
+{ 0x15, 1, 0, 0x00000001 },
+{ 0x6, 0, 0, 0x00050061 },
+{ 0x20, 0, 0, 0x0000001c },
+{ 0x15, 0, 20, 0x00000000 },
+{ 0x20, 0, 0, 0x00000018 },
+{ 0x54, 0, 0, 0x0000000f },
+{ 0x15, 2, 0, 0x00000001 },
+{ 0x15, 1, 0, 0x00000002 },
+{ 0x6, 0, 0, 0x0005000d },
+{ 0x20, 0, 0, 0x00000024 },
+{ 0x15, 0, 15, 0x00000000 },
+{ 0x20, 0, 0, 0x00000020 },
+{ 0x15, 9, 0, 0x00000000 },
+{ 0x15, 8, 0, 0x00000006 },
+{ 0x15, 7, 0, 0x00000011 },
+{ 0x6, 0, 0, 0x0005005d },
+{ 0x20, 0, 0, 0x00000014 },
+{ 0x15, 0, 12, 0x00000000 },
+{ 0x20, 0, 0, 0x00000010 },
+{ 0x44, 0, 0, 0x813dcfff },
+{ 0x15, 0, 9, 0x813dcfff },
+{ 0x5, 0, 0, 0x00000000 },
+{ 0x6, 0, 0, 0x7fff0000 },
+{ 0x6, 0, 0, 0x00000000 },
+{ 0x6, 0, 0, 0x0005000d },
+{ 0x6, 0, 0, 0x00050061 },
+{ 0x6, 0, 0, 0x00050016 },
+{ 0x6, 0, 0, 0x00050026 },
+{ 0x6, 0, 0, 0x0005005f },
+{ 0x6, 0, 0, 0x0005005f },
+{ 0x6, 0, 0, 0x00050001 },
+{ 0x6, 0, 0, 0x00050000 },
@@ -0,0 +1,246 @@
+start:
+    ld [4]
+    jne #0xc000003e, enosys_near
+    ld [0]
+    jeq #43, allow_near
+    jeq #288, allow_near
+    jeq #21, allow_near
+    jeq #37, allow_near
+    jeq #49, allow_near
+    jeq #12, allow_near
+    jeq #80, allow_near
+    jeq #90, allow_near
+    jeq #3, allow_near
+    jeq #229, allow_near
+    jeq #228, allow_near
+    jeq #230, allow_near
+    jeq #42, allow_near
+    jeq #85, allow_near
+    jeq #32, allow_near
+    jeq #33, allow_near
+    jeq #292, allow_near
+    jeq #213, allow_near
+    jeq #291, allow_near
+    jeq #233, allow_near
+    jeq #281, allow_near
+    jeq #232, allow_near
+    jeq #284, allow_near
+    jeq #290, allow_near
+    jeq #59, allow_near
+    jeq #322, allow_near
+    jeq #60, allow_near
+    jeq #231, allow_near
+    jeq #269, allow_near
+    jeq #81, allow_near
+    jeq #91, allow_near
+    jeq #268, allow_near
+    jeq #72, allow_near
+    jeq #75, allow_near
+    jeq #73, allow_near
+    jeq #57, allow_near
+    jeq #5, allow_near
+    jeq #138, allow_near
+    jeq #74, allow_near
+    jeq #77, allow_near
+    jeq #202, allow_near
+    jeq #79, allow_near
+    jeq #78, allow_near
+    jeq #217, allow_near
+    jeq #108, allow_near
+    jeq #107, allow_near
+    jeq #104, allow_near
+    jeq #115, allow_near
+    jeq #36, allow_near
+    jeq #52, allow_near
+    jeq #121, allow_near
+    jeq #111, allow_near
+    jeq #39, allow_near
+    jeq #110, allow_near
+    jeq #318, allow_near
+    jeq #118, allow_near
+    jeq #120, allow_near
+    jeq #97, allow_near
+    jeq #98, allow_near
+    jeq #124, allow_near
+    jeq #51, allow_near
+    jeq #55, allow_near
+    jeq #186, allow_near
+    jeq #96, allow_near
+    jeq #102, allow_near
+    jeq #254, allow_near
+    jeq #253, allow_near
+    jeq #294, allow_near
+    jeq #255, allow_near
+    jeq #62, allow_near
+    jeq #86, allow_near
+    jeq #265, allow_near
+    jeq #50, allow_near
+    jeq #8, allow_near
+    jeq #6, allow_near
+    jeq #83, allow_near
+    jeq #258, allow_near
+    jeq #25, allow_near
+    jeq #26, allow_near
+    jeq #11, allow_near
+    jeq #35, allow_near
+    jeq #262, allow_near
+    jeq #2, allow_near
+    jeq #257, allow_near
+    jeq #34, allow_near
+    jeq #22, allow_near
+    jeq #293, allow_near
+    jeq #7, allow_near
+    jeq #271, allow_near
+    jeq #17, allow_near
+    jeq #302, allow_near
+    jeq #270, allow_near
+    jeq #18, allow_near
+    jeq #0, allow_near
+    jeq #19, allow_near
+    jeq #89, allow_near
+    jeq #267, allow_near
+    jeq #82, allow_near
+    jeq #264, allow_near
+    jeq #84, allow_near
+    jeq #13, allow_near
+    jeq #127, allow_near
+    jeq #14, allow_near
+    jeq #129, allow_near
+    jeq #15, allow_near
+    jeq #130, allow_near
+    jeq #128, allow_near
+    jeq #204, allow_near
+    jeq #203, allow_near
+    jeq #23, allow_near
+    jeq #40, allow_near
+    jeq #218, allow_near
+    jeq #38, allow_near
+    jeq #160, allow_near
+    jeq #112, allow_near
+    jeq #48, allow_near
+    jeq #131, allow_near
+    jeq #282, allow_near
+    jeq #289, allow_near
+    jeq #4, allow_near
+    jeq #137, allow_near
+    jeq #88, allow_near
+    jeq #266, allow_near
+    jeq #99, allow_near
+    jeq #234, allow_near
+    jeq #222, allow_near
+    jeq #226, allow_near
+    jeq #225, allow_near
+    jeq #224, allow_near
+    jeq #223, allow_near
+    jeq #283, allow_near
+    jeq #287, allow_near
+    jeq #286, allow_near
+    jeq #100, allow_near
+    jeq #200, allow_near
+    jeq #76, allow_near
+    jeq #95, allow_near
+    jeq #63, allow_near
+    jeq #87, allow_near
+    jeq #263, allow_near
+    jeq #132, allow_near
+    jeq #280, allow_near
+    jeq #235, allow_near
+    jeq #58, allow_near
+    jeq #61, allow_near
+    jeq #1, allow_near
+    jeq #20, allow_near
+    jeq #158, allow_near
+    jeq #28, allow_near
+    jeq #9, allow_near
+    jeq #10, allow_near
+    jeq #45, allow_near
+    jeq #47, allow_near
+    jeq #46, allow_near
+    jeq #44, allow_near
+    jeq #54, allow_near
+    jmp skip_near
+allow_near: ret #0x7fff0000
+enosys_near: ret #0x50026
+skip_near:
+    jeq #56, sys_clone
+    jeq #16, sys_ioctl
+    jeq #41, sys_socket
+    jeq #53, sys_socket
+    jeq #24, noop
+    jeq #221, noop
+    jeq #92, eperm
+    jeq #161, eperm
+    jeq #93, eperm
+    jeq #260, eperm
+    jeq #94, eperm
+    jeq #165, eperm
+    jeq #191, enotsup
+    jeq #188, enotsup
+    jeq #194, enotsup
+    jeq #197, enotsup
+    jeq #193, enotsup
+    jeq #190, enotsup
+    jeq #196, enotsup
+    jeq #199, enotsup
+    jeq #157, einval
+    jeq #101, eperm
+    ret #0x50026
+sys_ioctl:
+    ld [28]
+    jne #0, einval
+    ld [24]
+    jeq #0x5451, allow
+    jeq #0x5450, allow
+    jeq #0x5452, allow
+    jeq #0x5421, allow
+    jeq #0x541b, allow
+    jeq #0x5460, allow
+    jeq #0xc0045877, eperm
+    jeq #0xc0045878, eperm
+    jeq #0xc020660b, eopnotsupp
+    jeq #0x40049409, eopnotsupp
+    jeq #0x4020940d, eopnotsupp
+    jeq #0xc0189436, eopnotsupp
+    ret #0x50019
+sys_socket:
+    ld [20]
+    jne #0, eafnosupport
+    ld [16]
+    jeq #0x2, socket_type
+    jeq #0xa, socket_type
+    jeq #0x1, socket_type
+    ret #0x50061
+socket_type:
+    ld [28]
+    jne #0, eacces
+    ld [24]
+    and #0xf
+    jeq #0x1, socket_protocol
+    jeq #0x2, socket_protocol
+    ret #0x5000d
+socket_protocol:
+    ld [36]
+    jne #0, einval
+    ld [32]
+    jeq #0, allow
+    jeq #0x6, allow
+    jeq #0x11, allow
+    ret #0x5005d
+sys_clone:
+    ld [20]
+    jne #0, eperm
+    ld [16]
+    or #0x813dcfff
+    jne #0x813dcfff, eperm
+    jmp allow
+allow: ret #0x7fff0000
+kill: ret #0x0
+eacces: ret #0x5000d
+eafnosupport: ret #0x50061
+einval: ret #0x50016
+enosys: ret #0x50026
+enotsup: ret #0x5005f
+eopnotsupp: ret #0x5005f
+eperm: ret #0x50001
+noop:
+    ret #0x50000
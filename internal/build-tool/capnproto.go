@@ -17,253 +17,78 @@
 package buildtool
 
 import (
-	"bytes"
-	"fmt"
-	"log"
 	"os"
-	"os/exec"
-	"path/filepath"
-	"strings"
-	"text/template"
 )
 
-type capnProtoConfig struct {
-	downloadFile        string
-	downloadUrl         string
-	executable          string
-	expectedFileSize    int64
-	expectedSha256      string
-	tarGzDir            string
-	toolchainDir        string
-	toolchainExecutable string
-	toolchainVersion    string
-	version             string
-	versionedDir        string
+func init() {
+	registerBootstrapper(&archiveTool{
+		command: "capnproto",
+		config: func(buildToolConfig *RuntimeConfigBuildTool) *runtimeConfigTool {
+			return buildToolConfig.CapnProto
+		},
+		downloads: func(downloads *DownloadsTomlTopLevel) *DownloadsTomlTool {
+			return &downloads.CapnProto
+		},
+		toolchainToml: func(toolchainToml *ToolchainTomlTopLevel) **ToolchainTomlTool {
+			return &toolchainToml.CapnProto
+		},
+		archiveDir: func(tool *runtimeConfigTool) string {
+			return "capnproto-c++-" + tool.version
+		},
+		executable: "capnp",
+		build:      buildCapnProto,
+		system: &systemTool{
+			executable:  "capnp",
+			versionArgs: []string{"--version"},
+		},
+	})
 }
 
-// text/template uses these struct fields from a separate package, so they must be in PascalCase.
-type capnProtoDownloadUrlTemplateValues struct {
-	Filename string
-}
-
-// text/template uses these struct fields from a separate package, so they must be in PascalCase.
-type capnProtoFilenameTemplateValues struct {
-	Version string
-}
-
-func BootstrapCapnProto(buildToolConfig *RuntimeConfigBuildTool) ([]string, error) {
-	messages := make([]string, 0, 5)
-	capnProtoConfig, err := getCapnProtoConfig(buildToolConfig)
-	if err != nil {
-		messages = append(messages, "Failed to get Cap'n Proto configuration")
-		return messages, err
-	}
-	if capnProtoConfig.executable != "" {
-		executableExists, err := fileExistsAtPath(capnProtoConfig.executable)
-		if err != nil {
-			log.Printf("fileExistsAtPath err\n")
-			return messages, err
-		}
-		if executableExists {
-			messages = append(messages, fmt.Sprintf("Skipping download and installation of Cap'n Proto because %s (from config.toml) exists", capnProtoConfig.executable))
-			return messages, nil 
-		} else {
-			err = fmt.Errorf("User-specified Cap'n Proto executable %s does not exist.", capnProtoConfig.executable)
-			return messages, err
-		}
-	}
-	if capnProtoConfig.toolchainExecutable != "" {
-		executableExists, err := fileExistsAtPath(capnProtoConfig.toolchainExecutable)
-		if err != nil {
-			log.Printf("fileExistsAtPath err\n")
-			return messages, err
-		}
-		if executableExists {
-			if capnProtoConfig.version == capnProtoConfig.toolchainVersion {
-				messages = append(messages, fmt.Sprintf("Skipping download and installation of Cap'n Proto because %s (from toolchain) exists", capnProtoConfig.toolchainExecutable))
-				return messages, nil
-			} else {
-				messages = append(messages, fmt.Sprintf("The toolchain executable does not match the desired version.  Contining."))
-			}
-		}
-	}
-	err = ensureDownloadDirExists(buildToolConfig.Directories.DownloadDir)
-	if err != nil {
-		return messages, err
-	}
-	downloadPath := filepath.Join(buildToolConfig.Directories.DownloadDir, capnProtoConfig.downloadFile)
-	downloadPathExists, err := fileExistsAtPath(downloadPath)
-	if err != nil {
-		return messages, err
-	}
-	if downloadPathExists {
-		messages = append(messages, fmt.Sprintf("Skipping Cap'n Proto download because %s exists", downloadPath))
-	} else {
-		err := downloadUrlToDir(capnProtoConfig.downloadUrl, buildToolConfig.Directories.DownloadDir, downloadPath)
-		if err != nil {
-			return messages, err
-		}
-	}
-	err = verifyFileSize(capnProtoConfig.expectedFileSize, downloadPath)
-	if err != nil {
-		return messages, err
-	}
-	err = verifySha256(capnProtoConfig.expectedSha256, downloadPath)
-	if err != nil {
-		return messages, err
-	}
-	messages = append(messages, fmt.Sprintf("%s has the correct SHA-256", downloadPath))
-	filterCapnProtoTarGz := filterCapnProtoTarGzFactory(capnProtoConfig.tarGzDir)
-	transformCapnProtoTarGz := transformCapnProtoTarGzFactory(capnProtoConfig.toolchainDir, len(capnProtoConfig.tarGzDir))
-	err = extractTarGz(downloadPath, filterCapnProtoTarGz, transformCapnProtoTarGz)
-	if err != nil {
-		messages = append(messages, fmt.Sprintf("Failed to extract %s", downloadPath))
-		return messages, err
-	}
-	err = configureCapnProto(capnProtoConfig.toolchainDir)
+func buildCapnProto(b *toolBuild) ([]string, error) {
+	messages := make([]string, 0, 1)
+	stopTimer := b.startStep(stepConfigure)
+	err := configureCapnProto(b.tool.toolchainDir, b.buildToolConfig.sandbox, b.buildToolConfig.static)
+	stopTimer()
 	if err != nil {
 		messages = append(messages, "Failed while running ./configure for Cap'n Proto")
 		return messages, err
 	}
-	err = makeCapnProto(capnProtoConfig.toolchainDir)
+	stopTimer = b.startStep(stepMake)
+	err = makeCapnProto(b.tool.toolchainDir, b.buildToolConfig.sandbox, b.tool.jobs, b.buildToolConfig.static)
+	stopTimer()
 	if err != nil {
 		messages = append(messages, "Failed while running make for Cap'n Proto")
 		return messages, err
 	}
-	toolchainTomlExecutable := filepath.Join(capnProtoConfig.versionedDir, "capnp")
-	err = updateCapnProtoToolchainToml(buildToolConfig.Directories.ToolChainDir, toolchainTomlExecutable, capnProtoConfig.version)
-	return messages, err
+	return messages, nil
 }
 
-func configureCapnProto(capnProtoDir string) error {
-	cmd := exec.Command("./configure")
-	cmd.Dir = capnProtoDir
-	cmd.Env = append(cmd.Env, os.Environ()...)
+func configureCapnProto(capnProtoDir string, sandbox *runtimeConfigSandbox, static *runtimeConfigStatic) error {
+	cmd, err := sandbox.command(capnProtoDir, "./configure")
+	if err != nil {
+		return err
+	}
+	if static.enabled {
+		cmd.Args = append(cmd.Args, "--disable-shared")
+		cmd.Env = append(cmd.Env, static.compilerEnv()...)
+	}
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
 
-func filterCapnProtoTarGz(tarGzDir string, filePath string) bool {
-	acceptable := strings.HasPrefix(filePath, tarGzDir)
-	if !acceptable {
-		// TODO: Figure out how to get this in the messages slice.
-		log.Printf("Rejecting file with invalid prefix: %s\n", filePath)
-	}
-	return acceptable
-}
-
-func filterCapnProtoTarGzFactory(tarGzDir string) fileFilter {
-	tarGzDir = ensureTrailingSlash(tarGzDir)
-	return func(filePath string) bool {
-		return filterCapnProtoTarGz(tarGzDir, filePath)
-	}
-}
-
-func getCapnProtoConfig(buildToolConfig *RuntimeConfigBuildTool) (*capnProtoConfig, error) {
-	if buildToolConfig.CapnProto == nil {
-		return nil, fmt.Errorf("buildToolConfig.CapnProto is nil")
-	}
-	// Version
-	version := buildToolConfig.CapnProto.version
-	// Download File
-	filenameValues := capnProtoFilenameTemplateValues{
-		version,
-	}
-	filenameTemplate, err := template.New("filename").Parse(buildToolConfig.CapnProto.filenameTemplate)
-	if err != nil {
-		return nil, err
-	}
-	var filenameBuffer bytes.Buffer
-	err = filenameTemplate.Execute(&filenameBuffer, filenameValues)
-	if err != nil {
-		return nil, err
-	}
-	downloadFile := filenameBuffer.String()
-
-	// Download URL
-	downloadUrlValues := capnProtoDownloadUrlTemplateValues{
-		downloadFile,
-	}
-	downloadUrlTemplate, err := template.New("downloadUrl").Parse(buildToolConfig.CapnProto.downloadUrlTemplate)
-	if err != nil {
-		return nil, err
-	}
-	var downloadUrlBuffer bytes.Buffer
-	err = downloadUrlTemplate.Execute(&downloadUrlBuffer, downloadUrlValues)
+func makeCapnProto(capnProtoDir string, sandbox *runtimeConfigSandbox, jobs int, static *runtimeConfigStatic) error {
+	cmd, err := sandbox.command(capnProtoDir, "make", makeJobsArg(jobs))
 	if err != nil {
-		return nil, err
+		return err
 	}
-	downloadUrl := downloadUrlBuffer.String()
-	downloadFileInfo := buildToolConfig.CapnProto.files[downloadFile]
-	if downloadFileInfo == (runtimeConfigFile{}) {
-		return nil, fmt.Errorf("File size and SHA-256 not found in downloads.toml for %s", downloadFile)
+	if static.enabled {
+		// Cap'n Proto links with libtool, which only passes -static on to
+		// the compiler as -all-static.
+		cmd.Args = append(cmd.Args, "LDFLAGS=-all-static")
 	}
-	// Expected file size and SHA-256
-	expectedFileSize := downloadFileInfo.size
-	expectedSha256 := downloadFileInfo.sha256
-	// TarGz directory
-	tarGzDir := "capnproto-c++-" + version
-	// capnp executable
-	executable := buildToolConfig.CapnProto.Executable
-	// Toolchain directory
-	toolchainDir := buildToolConfig.CapnProto.toolchainDir
-	// Toolchain executable
-	toolchainExecutable := buildToolConfig.CapnProto.ToolChainExecutable
-	// Toolchain version
-	toolchainVersion := buildToolConfig.CapnProto.toolchainVersion
-	// Versioned directory
-	versionedDir := buildToolConfig.CapnProto.versionedDir
-
-	capnProtoConfig := new(capnProtoConfig)
-	capnProtoConfig.downloadFile = downloadFile
-	capnProtoConfig.downloadUrl = downloadUrl
-	capnProtoConfig.executable = executable
-	capnProtoConfig.expectedFileSize = expectedFileSize
-	capnProtoConfig.expectedSha256 = expectedSha256
-	capnProtoConfig.tarGzDir = tarGzDir
-	capnProtoConfig.toolchainDir = toolchainDir
-	capnProtoConfig.toolchainExecutable = toolchainExecutable
-	capnProtoConfig.toolchainVersion = toolchainVersion
-	capnProtoConfig.version = version
-	capnProtoConfig.versionedDir = versionedDir
-	return capnProtoConfig, nil
-}
-
-func makeCapnProto(capnProtoDir string) error {
-	cmd := exec.Command("make")
 	cmd.Args = append(cmd.Args, "check")
-	cmd.Dir = capnProtoDir
-	cmd.Env = append(cmd.Env, os.Environ()...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
-
-func transformCapnProtoTarGz(toolchainDir string, filePath string, prefixLength int) string {
-	maxLength := min(len(filePath), prefixLength)
-	return filepath.Join(toolchainDir, filePath[maxLength:])
-}
-
-func transformCapnProtoTarGzFactory(destinationDir string, prefixLength int) fileTransformer {
-	destinationDir = ensureTrailingSlash(destinationDir)
-	return func(filePath string) string {
-		return transformCapnProtoTarGz(destinationDir, filePath, prefixLength)
-	}
-}
-
-func updateCapnProtoToolchainToml(toolchainDir string, executable string, version string) error {
-	toolchainTomlTopLevel, err := ReadToolchainToml(toolchainDir)
-	if err != nil {
-		if !os.IsNotExist(err) {
-			return err
-		}
-		toolchainTomlTopLevel = new(ToolchainTomlTopLevel)
-	}
-	if toolchainTomlTopLevel.CapnProto == nil {
-		toolchainTomlTopLevel.CapnProto = new(ToolchainTomlTool)
-	}
-	toolchainTomlTopLevel.CapnProto.Executable = executable
-	toolchainTomlTopLevel.CapnProto.Version = version
-	return WriteToolchainToml(toolchainDir, toolchainTomlTopLevel)
-}
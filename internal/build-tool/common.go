@@ -18,51 +18,207 @@ package buildtool
 
 import (
 	"archive/tar"
+	"compress/bzip2"
 	"compress/gzip"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"io/fs"
 	"net/http"
 	"os"
+	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/BurntSushi/toml"
+	"github.com/klauspost/compress/zstd"
 	"github.com/schollz/progressbar/v3"
 	"github.com/xi2/xz"
+	"golang.org/x/sys/unix"
+	"sandstorm.org/go/tempest/internal/build-tool/blake3"
 )
 
-func downloadUrlToDir(downloadUrl string, downloadDir string, downloadPath string) error {
-	tempFile, err := os.CreateTemp(downloadDir, "download-")
+// httpCacheFileName is the file in the download directory which records the
+// ETag and Last-Modified headers returned for each download, so that later
+// runs can make conditional requests.
+const httpCacheFileName = "http-cache.toml"
+
+type httpCacheEntry struct {
+	ETag         string `toml:"ETag"`
+	LastModified string `toml:"LastModified"`
+}
+
+// downloadUrlToDir downloads downloadUrl to downloadPath.  If downloadPath
+// already exists, the request is conditional on the file having changed
+// since it was downloaded, and the existing file is kept if it hasn't.  The
+// existing file is also kept if the server can't be reached, so bootstraps
+// work offline; callers verify the file's checksum either way.  Requests to
+// GitHub carry github's token, if there is one.  It returns the number of
+// bytes downloaded, which is zero if the existing file was kept.
+//
+// Before downloading, it checks that downloadDir has room for
+// expectedFileSize bytes, the size from downloads.toml, or for the response's
+// Content-Length if expectedFileSize is zero because the size isn't known yet.
+func downloadUrlToDir(github *runtimeConfigGitHub, downloadUrl string, downloadDir string, downloadPath string, expectedFileSize int64) (int64, []string, error) {
+	messages := make([]string, 0, 1)
+	cacheFilePath := filepath.Join(downloadDir, httpCacheFileName)
+	cacheKey := filepath.Base(downloadPath)
+
+	request, err := http.NewRequest(http.MethodGet, downloadUrl, nil)
 	if err != nil {
-		return err
+		return 0, messages, err
+	}
+	github.authorize(request)
+	existingFile, err := os.Stat(downloadPath)
+	if err != nil && !os.IsNotExist(err) {
+		return 0, messages, err
+	}
+	if existingFile != nil {
+		cache, err := readHttpCache(cacheFilePath)
+		if err != nil {
+			return 0, messages, err
+		}
+		entry := cache[cacheKey]
+		if entry.ETag != "" {
+			request.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			request.Header.Set("If-Modified-Since", entry.LastModified)
+		} else if entry.ETag == "" {
+			// Downloaded before validators were recorded; the file can't
+			// be older than the copy on the server was when we fetched it.
+			request.Header.Set("If-Modified-Since", existingFile.ModTime().UTC().Format(http.TimeFormat))
+		}
 	}
-	defer tempFile.Close()
 
-	response, err := http.Get(downloadUrl)
+	response, err := http.DefaultClient.Do(request)
 	if err != nil {
-		return err
+		if existingFile != nil {
+			messages = append(messages, fmt.Sprintf("Could not check %s for changes (%v); using %s", downloadUrl, err, downloadPath))
+			return 0, messages, nil
+		}
+		return 0, messages, err
 	}
 	defer response.Body.Close()
 
+	if response.StatusCode == http.StatusNotModified && existingFile != nil {
+		messages = append(messages, fmt.Sprintf("Skipping download because %s has not changed since %s was downloaded", downloadUrl, downloadPath))
+		return 0, messages, nil
+	}
 	if response.StatusCode != http.StatusOK {
-		return fmt.Errorf("GET %s => %s", downloadUrl, response.Status)
+		err = fmt.Errorf("GET %s => %s", downloadUrl, response.Status)
+		if existingFile != nil {
+			messages = append(messages, fmt.Sprintf("Could not check %s for changes (%v); using %s", downloadUrl, err, downloadPath))
+			return 0, messages, nil
+		}
+		return 0, messages, err
+	}
+
+	neededSpace := expectedFileSize
+	if neededSpace <= 0 {
+		neededSpace = response.ContentLength
+	}
+	if neededSpace > 0 {
+		err = ensureFreeSpace(downloadDir, neededSpace)
+		if err != nil {
+			return 0, messages, err
+		}
+	}
+
+	tempFile, err := os.CreateTemp(downloadDir, "download-")
+	if err != nil {
+		return 0, messages, err
 	}
+	defer os.Remove(tempFile.Name())
+	defer tempFile.Close()
 
 	progressBar := progressbar.DefaultBytes(
 		response.ContentLength,
 		fmt.Sprintf("Downloading %s", downloadUrl),
 	)
 
-	_, err = io.Copy(io.MultiWriter(tempFile, progressBar), response.Body)
+	downloaded, err := io.Copy(io.MultiWriter(tempFile, progressBar), response.Body)
 	if err != nil {
-		return err
+		return 0, messages, err
 	}
 	err = os.Rename(tempFile.Name(), downloadPath)
-	return err
+	if err != nil {
+		return 0, messages, err
+	}
+	messages = append(messages, fmt.Sprintf("Downloaded %s to %s", downloadUrl, downloadPath))
+	err = writeHttpCacheEntry(cacheFilePath, cacheKey, httpCacheEntry{
+		ETag:         response.Header.Get("ETag"),
+		LastModified: response.Header.Get("Last-Modified"),
+	})
+	return downloaded, messages, err
+}
+
+func readHttpCache(cacheFilePath string) (map[string]httpCacheEntry, error) {
+	cache := make(map[string]httpCacheEntry)
+	_, err := toml.DecodeFile(cacheFilePath, &cache)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return cache, nil
+}
+
+func writeHttpCacheEntry(cacheFilePath string, cacheKey string, entry httpCacheEntry) error {
+	doc, err := readTomlDocument(cacheFilePath,
+		"# This file is managed by the Tempest build-tool.",
+		"# HTTP validators for conditional downloads; see internal/build-tool/common.go",
+	)
+	if err != nil {
+		return err
+	}
+	if err = doc.setString(cacheKey, "ETag", entry.ETag); err != nil {
+		return err
+	}
+	if err = doc.setString(cacheKey, "LastModified", entry.LastModified); err != nil {
+		return err
+	}
+	return doc.writeFile(cacheFilePath)
+}
+
+// ensureFreeSpace returns an error if the file system containing dir has
+// fewer than needed bytes available.  dir need not exist yet, in which case
+// its nearest existing parent is checked.
+func ensureFreeSpace(dir string, needed int64) error {
+	var stat unix.Statfs_t
+	checkDir := dir
+	for {
+		err := unix.Statfs(checkDir, &stat)
+		if err == nil {
+			break
+		}
+		parent := filepath.Dir(checkDir)
+		if !errors.Is(err, unix.ENOENT) || parent == checkDir {
+			return err
+		}
+		checkDir = parent
+	}
+	available := int64(stat.Bavail) * int64(stat.Bsize)
+	if available < needed {
+		return fmt.Errorf("Not enough free space in %s: %s needed, but only %s available", dir, formatBytes(needed), formatBytes(available))
+	}
+	return nil
+}
+
+// ensureExtractSpace returns an error if dir doesn't have room for the files
+// extracted from file, according to its ExtractedSize in downloads.toml.  If
+// that isn't known, it only says so.
+func ensureExtractSpace(dir string, fileName string, file runtimeConfigFile) ([]string, error) {
+	messages := make([]string, 0, 1)
+	if file.extractedSize <= 0 {
+		messages = append(messages, fmt.Sprintf("downloads.toml has no ExtractedSize for %s; not checking for free space in %s", fileName, dir))
+		return messages, nil
+	}
+	return messages, ensureFreeSpace(dir, file.extractedSize)
 }
 
 func envMap() map[string]string {
@@ -167,34 +323,103 @@ func extractTar(tarReader *tar.Reader, fileName string, filter fileFilter, trans
 	return nil
 }
 
-func extractTarGz(tgzArchive string, filter fileFilter, transform fileTransformer) error {
-	tgzFile, err := os.Open(tgzArchive)
-	if err != nil {
-		return err
+// Archive formats, for a tool's ArchiveFormat in downloads.toml.
+const (
+	archiveFormatTarBz2 = "tar.bz2"
+	archiveFormatTarGz  = "tar.gz"
+	archiveFormatTarXz  = "tar.xz"
+	archiveFormatTarZst = "tar.zst"
+)
+
+// archiveFormatExtensions maps file name extensions to archive formats.
+var archiveFormatExtensions = []struct {
+	extension string
+	format    string
+}{
+	{".tar.bz2", archiveFormatTarBz2},
+	{".tbz2", archiveFormatTarBz2},
+	{".tar.gz", archiveFormatTarGz},
+	{".tgz", archiveFormatTarGz},
+	{".tar.xz", archiveFormatTarXz},
+	{".txz", archiveFormatTarXz},
+	{".tar.zst", archiveFormatTarZst},
+	{".tzst", archiveFormatTarZst},
+}
+
+// archiveFormatOf returns format if it is set, and otherwise the format
+// given by the archive's file name extension.
+func archiveFormatOf(archive string, format string) (string, error) {
+	if format != "" {
+		if _, ok := archiveDecompressors[format]; !ok {
+			return "", fmt.Errorf("Unknown ArchiveFormat %q; expected one of %s, %s, %s or %s", format, archiveFormatTarBz2, archiveFormatTarGz, archiveFormatTarXz, archiveFormatTarZst)
+		}
+		return format, nil
 	}
-	defer tgzFile.Close()
-	gzipReader, err := gzip.NewReader(tgzFile)
-	if err != nil {
-		return err
+	for _, formatExtension := range archiveFormatExtensions {
+		if strings.HasSuffix(archive, formatExtension.extension) {
+			return formatExtension.format, nil
+		}
 	}
-	tarReader := tar.NewReader(gzipReader)
-	err = extractTar(tarReader, tgzArchive, filter, transform)
-	return err
+	return "", fmt.Errorf("%s: Unknown archive format; set ArchiveFormat in downloads.toml", filepath.Base(archive))
+}
+
+// archiveDecompressors return a reader of the tar file within a compressed
+// archive, and a function to release the decompressor's resources.
+var archiveDecompressors = map[string]func(io.Reader) (io.Reader, func(), error){
+	archiveFormatTarBz2: func(r io.Reader) (io.Reader, func(), error) {
+		return bzip2.NewReader(r), func() {}, nil
+	},
+	archiveFormatTarGz: func(r io.Reader) (io.Reader, func(), error) {
+		gzipReader, err := gzip.NewReader(r)
+		return gzipReader, func() {}, err
+	},
+	archiveFormatTarXz: func(r io.Reader) (io.Reader, func(), error) {
+		xzReader, err := xz.NewReader(r, 0)
+		return xzReader, func() {}, err
+	},
+	archiveFormatTarZst: func(r io.Reader) (io.Reader, func(), error) {
+		zstdReader, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		return zstdReader, zstdReader.Close, nil
+	},
 }
 
-func extractTarXz(txzArchive string, filter fileFilter, transform fileTransformer) error {
-	txzFile, err := os.Open(txzArchive)
+// extractTarArchive extracts a compressed tar archive.  format is the
+// tool's ArchiveFormat; if it is empty, the format is taken from the
+// archive's file name extension.
+func extractTarArchive(archive string, format string, filter fileFilter, transform fileTransformer) error {
+	format, err := archiveFormatOf(archive, format)
 	if err != nil {
 		return err
 	}
-	defer txzFile.Close()
-	xzReader, err := xz.NewReader(txzFile, 0)
+	archiveFile, err := os.Open(archive)
 	if err != nil {
 		return err
 	}
-	tarReader := tar.NewReader(xzReader)
-	err = extractTar(tarReader, txzArchive, filter, transform)
-	return err
+	defer archiveFile.Close()
+	decompressor, closeDecompressor, err := archiveDecompressors[format](archiveFile)
+	if err != nil {
+		return fmt.Errorf("%s: %w", archive, err)
+	}
+	defer closeDecompressor()
+	tarReader := tar.NewReader(decompressor)
+	return extractTar(tarReader, archive, filter, transform)
+}
+
+// formatBytes formats a size in bytes for humans, e.g., "1.5 GiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
 }
 
 // Return true if a file exists at the path and the file is not a directory.
@@ -215,6 +440,25 @@ func fileExistsAtPath(filePath string) (bool, error) {
 	return fileInfo != nil && !fileInfo.IsDir(), nil
 }
 
+// makeJobsArg returns the make argument to run the given number of jobs in
+// parallel.
+func makeJobsArg(jobs int) string {
+	return "-j" + strconv.Itoa(max(jobs, 1))
+}
+
+// compilerEnv returns environment variables selecting the compilers for a
+// static build, if any are configured.
+func (s *runtimeConfigStatic) compilerEnv() []string {
+	var env []string
+	if s.cc != "" {
+		env = append(env, "CC="+s.cc)
+	}
+	if s.cxx != "" {
+		env = append(env, "CXX="+s.cxx)
+	}
+	return env
+}
+
 func setFileModifiedTimeToNow(filePath string) error {
 	now := time.Now().Local()
 	err := os.Chtimes(filePath, time.Time{}, now)
@@ -230,23 +474,78 @@ func verifyFileSize(expectedFileSize int64, pathToVerify string) error {
 	if fileSize == expectedFileSize {
 		return nil
 	}
-	return fmt.Errorf("%s: Expected size %d found size %d", pathToVerify, expectedFileSize, fileSize)
+	return &mismatchError{pathToVerify, fmt.Sprintf("size %d", expectedFileSize), fmt.Sprintf("size %d", fileSize)}
+}
+
+// mismatchError reports a downloaded file whose size or digest is not the
+// one in downloads.toml.
+type mismatchError struct {
+	path     string
+	expected string
+	found    string
+}
+
+func (e *mismatchError) Error() string {
+	return fmt.Sprintf("%s: Expected %s found %s", e.path, e.expected, e.found)
+}
+
+// fileDigests holds the expected digests of a downloaded file, in hex.  Empty
+// digests are not checked, but at least one must be set.
+type fileDigests struct {
+	sha256 string
+	sha512 string
+	blake3 string
 }
 
-func verifySha256(expectedSha256 string, pathToVerify string) error {
+// A digestAlgorithm is a hash function which downloads.toml may give a digest
+// for.
+type digestAlgorithm struct {
+	name     string
+	size     int
+	newHash  func() hash.Hash
+	expected func(digests fileDigests) string
+}
+
+var digestAlgorithms = []digestAlgorithm{
+	{"SHA-256", sha256.Size, sha256.New, func(digests fileDigests) string { return digests.sha256 }},
+	{"SHA-512", sha512.Size, sha512.New, func(digests fileDigests) string { return digests.sha512 }},
+	{"BLAKE3", blake3.Size, func() hash.Hash { return blake3.New() }, func(digests fileDigests) string { return digests.blake3 }},
+}
+
+// verifyDigests checks the file at pathToVerify against every digest which is
+// set in expectedDigests, reading the file once.
+func verifyDigests(expectedDigests fileDigests, pathToVerify string) ([]string, error) {
+	messages := make([]string, 0)
+	algorithms := make([]digestAlgorithm, 0, len(digestAlgorithms))
+	hashes := make([]hash.Hash, 0, len(digestAlgorithms))
+	writers := make([]io.Writer, 0, len(digestAlgorithms))
+	for _, algorithm := range digestAlgorithms {
+		if algorithm.expected(expectedDigests) != "" {
+			hasher := algorithm.newHash()
+			algorithms = append(algorithms, algorithm)
+			hashes = append(hashes, hasher)
+			writers = append(writers, hasher)
+		}
+	}
+	if len(algorithms) == 0 {
+		return messages, fmt.Errorf("%s: No SHA-256, SHA-512 or BLAKE3 digest to verify against", pathToVerify)
+	}
+
 	fileToVerify, err := os.Open(pathToVerify)
 	if err != nil {
-		return err
+		return messages, err
 	}
 	defer fileToVerify.Close()
-
-	hash := sha256.New()
-	if _, err := io.Copy(hash, fileToVerify); err != nil {
-		return err
+	if _, err := io.Copy(io.MultiWriter(writers...), fileToVerify); err != nil {
+		return messages, err
 	}
-	sha256String := hex.EncodeToString(hash.Sum(nil))
-	if sha256String == expectedSha256 {
-		return nil
+	for i, algorithm := range algorithms {
+		expected := strings.ToLower(algorithm.expected(expectedDigests))
+		found := hex.EncodeToString(hashes[i].Sum(nil))
+		if found != expected {
+			return messages, &mismatchError{pathToVerify, algorithm.name + " " + expected, algorithm.name + " " + found}
+		}
+		messages = append(messages, fmt.Sprintf("%s has the correct %s", pathToVerify, algorithm.name))
 	}
-	return fmt.Errorf("%s: Expected SHA-256 %s found SHA-256 %s", pathToVerify, expectedSha256, sha256String)
+	return messages, nil
 }
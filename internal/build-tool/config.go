@@ -19,14 +19,15 @@ package buildtool
 import (
 	"bytes"
 	"fmt"
+	"go/token"
 	"log"
 	"os"
+	"os/exec"
 	"os/user"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"text/template"
-
-	"github.com/BurntSushi/toml"
 )
 
 // Config file types
@@ -36,6 +37,8 @@ import (
 // the config file.
 
 type ConfigTomlTopLevel struct {
+	SchemaVersion int
+
 	Tempest   ConfigTomlTempest   `toml:"tempest"`
 	BuildTool ConfigTomlBuildTool `toml:"build-tool"`
 }
@@ -50,6 +53,8 @@ type ConfigTomlBuildTool struct {
 	DownloadDirTemplate  string
 	DownloadUserAgent    string
 	DownloadsFile        string
+	Jobs                 int
+	PatchDir             string
 	ToolChainDirTemplate string
 
 	Binaryen  ConfigTomlTool     `toml:"binaryen"`
@@ -58,16 +63,61 @@ type ConfigTomlBuildTool struct {
 	CapnProto ConfigTomlTool     `toml:"capnproto"`
 	Flex      ConfigTomlTool     `toml:"flex"`
 	Generate  ConfigTomlGenerate `toml:"generate"`
+	GitHub    ConfigTomlGitHub   `toml:"github"`
 	Go        ConfigTomlGo       `toml:"go"`
 	GoCapnp   ConfigTomlTool     `toml:"go-capnp"`
 	Linux     ConfigTomlLinux    `toml:"linux"`
+	Sandbox   ConfigTomlSandbox  `toml:"sandbox"`
+	Static    ConfigTomlStatic   `toml:"static"`
 	TinyGo    ConfigTomlTool     `toml:"tinygo"`
+
+	PublishCapnp ConfigTomlPublishCapnp `toml:"publish-capnp"`
+
+	Profiles map[string]ConfigTomlProfile `toml:"profiles"`
+}
+
+// ConfigTomlPublishCapnp configures publish-capnp-module, which assembles the
+// generated Go code for the schemas in SourceDir into a standalone module.
+type ConfigTomlPublishCapnp struct {
+	ModulePath string
+	OutputDir  string
+	SourceDir  string
+}
+
+// ConfigTomlProfile is a named toolchain, selected with --profile, which
+// overrides the toolchain directory and tool versions.  Versions is keyed by
+// the tool's table name, e.g., "capnproto".
+type ConfigTomlProfile struct {
+	ToolChainDirTemplate string
+	Versions             map[string]string `toml:"versions"`
+}
+
+// ConfigTomlGitHub names the environment variable holding a GitHub token,
+// e.g., GITHUB_TOKEN, rather than holding the token itself, so that it
+// isn't committed along with config.toml.
+type ConfigTomlGitHub struct {
+	ApiUrl   string
+	TokenEnv string
+}
+
+type ConfigTomlSandbox struct {
+	Mode string
+	Path string
+}
+
+type ConfigTomlStatic struct {
+	Enabled bool
+	CC      string
+	CXX     string
 }
 
 type ConfigTomlTool struct {
-	DownloadUrl string
-	Executable  string
-	Version     string
+	DownloadUrl     string
+	Executable      string
+	Jobs            int
+	MinimumVersion  string
+	PrefetchModules bool
+	Version         string
 }
 
 type ConfigTomlBpfAsm struct {
@@ -75,15 +125,65 @@ type ConfigTomlBpfAsm struct {
 	GoPath     string
 }
 
+// ConfigTomlGenerate configures the generators run by `generate`.  Each
+// generator's table has an Enabled field; generators are enabled unless
+// Enabled is set to false.
 type ConfigTomlGenerate struct {
-	Capnp ConfigTomlGenerateCapnp `toml:"capnp"`
+	Bpf    ConfigTomlGenerateBpf    `toml:"bpf"`
+	Capnp  ConfigTomlGenerateCapnp  `toml:"capnp"`
+	Config ConfigTomlGenerateConfig `toml:"config"`
+	Web    ConfigTomlGenerateWeb    `toml:"web"`
+}
+
+// The assemblers for the seccomp filter: the one in the bpfasm package, or
+// the Linux kernel's bpf_asm, which needs Bison, Flex and the kernel sources.
+const (
+	BpfAssemblerBuiltin = "builtin"
+	BpfAssemblerBpfAsm  = "bpf_asm"
+)
+
+type ConfigTomlGenerateBpf struct {
+	Assembler string
+	Enabled   *bool
+	Source    string
 }
 
 type ConfigTomlGenerateCapnp struct {
-	CapnpDirs      []string
+	CapnpDirs []string
+	Enabled   *bool
+	// ImportPaths rewrites the import paths in the generated code, so that
+	// the schemas can be generated for another module without editing
+	// their $Go.import annotations.  Each key is an import path, or a
+	// prefix of one, and is replaced by its value; the longest match wins.
+	ImportPaths map[string]string `toml:"import-paths"`
+	// OutputDir, if set, is where the generated code goes, in the same
+	// directory structure as the schemas, rather than next to them.
+	OutputDir string
+	// Packages overrides the $Go.package annotations of schemas, keyed by
+	// the schema's path, e.g., "capnp/grain.capnp".
+	Packages       map[string]string `toml:"packages"`
 	StdDirTemplate string
 }
 
+type ConfigTomlGenerateConfig struct {
+	Enabled *bool
+}
+
+// The compilers for the web UI: TinyGo, whose output is much smaller, or the
+// Go toolchain, which builds faster and supports all of Go.
+const (
+	WebCompilerGo     = "go"
+	WebCompilerTinyGo = "tinygo"
+)
+
+type ConfigTomlGenerateWeb struct {
+	BrotliExecutable string
+	Compiler         string
+	Enabled          *bool
+	OutputDir        string
+	Package          string
+}
+
 type ConfigTomlGo struct {
 	Executable     string
 	GoPathTemplate string
@@ -127,28 +227,67 @@ type RuntimeConfigBuildTool struct {
 	CapnProto *runtimeConfigTool
 	Flex      *runtimeConfigTool
 	Generate  *runtimeConfigGenerate
+	github    *runtimeConfigGitHub
 	GoCapnp   *runtimeConfigTool
 	linux     *runtimeConfigLinux
+	sandbox   *runtimeConfigSandbox
+	static    *runtimeConfigStatic
 	TinyGo    *runtimeConfigTool
+
+	PublishCapnp *runtimeConfigPublishCapnp
+
+	// RedownloadOnMismatch is whether to quarantine a download which
+	// doesn't match downloads.toml and download it again, without asking.
+	RedownloadOnMismatch bool
+
+	// PreferSystem is whether to use tools found in PATH, if they are
+	// recent enough, rather than download them.
+	PreferSystem bool
+
+	// WaitForLocks is whether to wait for other build-tools using the same
+	// toolchain directories, rather than fail.  It defaults to true.
+	WaitForLocks bool
+
+	// Stats, if not nil, records how long each step of each bootstrap
+	// takes, and how much is downloaded and installed.
+	Stats *BootstrapStats
+}
+
+// runtimeConfigStatic configures static linking of the native tools built
+// from source.
+type runtimeConfigStatic struct {
+	enabled bool
+	cc      string // C compiler, e.g., "musl-gcc", or empty for the default
+	cxx     string // C++ compiler, or empty for the default
 }
 
 type runtimeConfigTool struct {
-	downloadUrlTemplate string // from config.toml or downloads.toml
-	Executable          string // from config.toml or empty
-	filenameTemplate    string // from downloads.toml
+	archiveFormat       string                       // from downloads.toml, or empty to use the file name's extension
+	downloadUrlTemplate string                       // from config.toml or downloads.toml
+	Executable          string                       // from config.toml or empty
+	filenameTemplate    string                       // from downloads.toml
 	files               map[string]runtimeConfigFile // from downloads.toml
-	Name                string // Tool name, suitable for display, e.g., "Bison"
-	Prefix              string // Tool prefix, e.g., "bison-"
+	githubRepo          string                       // from downloads.toml, unless config.toml sets the download URL
+	githubTagTemplate   string                       // from downloads.toml
+	jobs                int                          // number of parallel make jobs
+	minimumVersion      string                       // from config.toml, for --prefer-system
+	mirrorUrlTemplates  []string                     // from downloads.toml
+	modCache            *runtimeConfigModCache       // Go tools: from downloads.toml, or nil
+	Name                string                       // Tool name, suitable for display, e.g., "Bison"
+	patchDir            string                       // e.g., "internal/build-tool/bison/patches"
+	prefetchModules     bool                         // Go tools: fetch modules into the toolchain's module cache
+	Prefix              string                       // Tool prefix, e.g., "bison-"
 	// NB!
 	// toolchainDir is the directory that might exist in toolchain, and is
 	// formed by combining the tool's prefix with the desired version.
 	// This means that it may not have the same version as
 	// toolchainVersion.
 	toolchainDir        string
-	ToolChainExecutable string // the Bison executable that might exist in /toolchain
-	toolchainVersion    string // the version of Bison that might exist in /toolchain
-	version             string // from config.toml or from downloads.toml
-	versionedDir        string // e.g., "bison-3.8.2"
+	ToolChainExecutable string   // the Bison executable that might exist in /toolchain
+	toolchainPatches    []string // the patches applied to the Bison that might exist in /toolchain
+	toolchainVersion    string   // the version of Bison that might exist in /toolchain
+	version             string   // from config.toml or from downloads.toml
+	versionedDir        string   // e.g., "bison-3.8.2"
 }
 
 type runtimeConfigBpfAsm struct {
@@ -173,6 +312,7 @@ type runtimeConfigDirectories struct {
 	BuildDir       string
 	DownloadDir    string
 	IncrementalDir string
+	PatchDir       string
 	ToolChainDir   string
 }
 
@@ -182,17 +322,59 @@ type runtimeConfigExecutables struct {
 }
 
 type runtimeConfigFile struct {
-	sha256 string
-	size   int64
+	digests       fileDigests
+	size          int64
+	extractedSize int64
+}
+
+// runtimeConfigModCache locates the archives of a Go tool's module cache.
+type runtimeConfigModCache struct {
+	archiveFormat       string
+	downloadUrlTemplate string
+	filenameTemplate    string
+	files               map[string]runtimeConfigFile
+	mirrorUrlTemplates  []string
 }
 
 type runtimeConfigGenerate struct {
-	Capnp *runtimeConfigGenerateCapnp
+	Bpf    *runtimeConfigGenerateBpf
+	Capnp  *runtimeConfigGenerateCapnp
+	Config *runtimeConfigGenerateConfig
+	Web    *runtimeConfigGenerateWeb
+}
+
+type runtimeConfigGenerateBpf struct {
+	Assembler string
+	Enabled   bool
+	Source    string
+}
+
+type runtimeConfigPublishCapnp struct {
+	ModulePath string // empty if not configured
+	OutputDir  string
+	SourceDir  string
 }
 
 type runtimeConfigGenerateCapnp struct {
-	CapnpDirs []string
-	StdDir    string
+	CapnpDirs   []string
+	Enabled     bool
+	ImportPaths map[string]string
+	OutputDir   string // empty to generate next to the schemas
+	Packages    map[string]string
+	StdDir      string
+}
+
+type runtimeConfigGenerateConfig struct {
+	Enabled bool
+}
+
+type runtimeConfigGenerateWeb struct {
+	BrotliExecutable string
+	Compiler         string
+	Enabled          bool
+	GoExecutable     string
+	OutputDir        string
+	Package          string
 }
 
 type runtimeConfigGoCapnp struct {
@@ -207,9 +389,11 @@ type runtimeConfigGoCapnp struct {
 }
 
 type runtimeConfigLinux struct {
+	archiveFormat       string
 	downloadUrlTemplate string
 	filenameTemplate    string
 	files               map[string]runtimeConfigFile
+	mirrorUrlTemplates  []string
 	toolchainVersion    string
 	version             string
 }
@@ -219,6 +403,14 @@ func BuildConfiguration(configFile *ConfigTomlTopLevel, downloadsFile *Downloads
 	var err error
 	// Top-level
 	config.downloadUserAgent = configFile.BuildTool.DownloadUserAgent
+	config.WaitForLocks = true
+	jobs := configFile.BuildTool.Jobs
+	if jobs < 0 {
+		return nil, fmt.Errorf("[build-tool].Jobs must not be negative")
+	}
+	if jobs == 0 {
+		jobs = runtime.NumCPU()
+	}
 	// Directories
 	config.Directories = new(runtimeConfigDirectories)
 	buildDir, err := buildDirWithHomeTemplate("BuildDir", configFile.BuildTool.BuildDirTemplate)
@@ -246,6 +438,14 @@ func BuildConfiguration(configFile *ConfigTomlTopLevel, downloadsFile *Downloads
 	if err != nil {
 		return nil, err
 	}
+	patchDir := configFile.BuildTool.PatchDir
+	if patchDir == "" {
+		patchDir = "internal/build-tool"
+	}
+	config.Directories.PatchDir, err = filepath.Abs(patchDir)
+	if err != nil {
+		return nil, err
+	}
 	var toolchainToml *ToolchainTomlTopLevel
 	toolchainToml, err = ReadToolchainToml(config.Directories.ToolChainDir)
 	if err != nil {
@@ -254,6 +454,19 @@ func BuildConfiguration(configFile *ConfigTomlTopLevel, downloadsFile *Downloads
 		}
 		toolchainToml = new(ToolchainTomlTopLevel)
 	}
+	config.static = &runtimeConfigStatic{
+		enabled: configFile.BuildTool.Static.Enabled,
+		cc:      configFile.BuildTool.Static.CC,
+		cxx:     configFile.BuildTool.Static.CXX,
+	}
+	config.sandbox, err = newSandboxRuntimeConfig(&configFile.BuildTool.Sandbox)
+	if err != nil {
+		return nil, err
+	}
+	config.github, err = newGitHubRuntimeConfig(&configFile.BuildTool.GitHub)
+	if err != nil {
+		return nil, err
+	}
 	config.Executables = new(runtimeConfigExecutables)
 	err = populateExecutablesRuntimeConfig(config, configFile, toolchainToml)
 	if err != nil {
@@ -263,15 +476,16 @@ func BuildConfiguration(configFile *ConfigTomlTopLevel, downloadsFile *Downloads
 	config.Binaryen = new(runtimeConfigTool)
 	config.Binaryen.Name = "Binaryen"
 	config.Binaryen.Prefix = "binaryen-version_"
-	err = populateToolRuntimeConfig(config.Binaryen, config.Directories, &configFile.BuildTool.Binaryen, &downloadsFile.Binaryen, toolchainToml.Binaryen)
+	err = populateToolRuntimeConfig(config.Binaryen, config.Directories, jobs, &configFile.BuildTool.Binaryen, &downloadsFile.Binaryen, toolchainToml.Binaryen)
 	if err != nil {
 		return nil, err
 	}
 	// Bison
 	config.Bison = new(runtimeConfigTool)
 	config.Bison.Name = "Bison"
+	config.Bison.patchDir = filepath.Join(config.Directories.PatchDir, "bison", "patches")
 	config.Bison.Prefix = "bison-"
-	err = populateToolRuntimeConfig(config.Bison, config.Directories, &configFile.BuildTool.Bison, &downloadsFile.Bison, toolchainToml.Bison)
+	err = populateToolRuntimeConfig(config.Bison, config.Directories, jobs, &configFile.BuildTool.Bison, &downloadsFile.Bison, toolchainToml.Bison)
 	if err != nil {
 		return nil, err
 	}
@@ -284,16 +498,18 @@ func BuildConfiguration(configFile *ConfigTomlTopLevel, downloadsFile *Downloads
 	// Cap'n Proto
 	config.CapnProto = new(runtimeConfigTool)
 	config.CapnProto.Name = "Cap'n Proto"
+	config.CapnProto.patchDir = filepath.Join(config.Directories.PatchDir, "capnproto", "patches")
 	config.CapnProto.Prefix = "capnp-"
-	err = populateToolRuntimeConfig(config.CapnProto, config.Directories, &configFile.BuildTool.CapnProto, &downloadsFile.CapnProto, toolchainToml.CapnProto)
+	err = populateToolRuntimeConfig(config.CapnProto, config.Directories, jobs, &configFile.BuildTool.CapnProto, &downloadsFile.CapnProto, toolchainToml.CapnProto)
 	if err != nil {
 		return nil, err
 	}
 	// Flex
 	config.Flex = new(runtimeConfigTool)
 	config.Flex.Name = "Flex"
+	config.Flex.patchDir = filepath.Join(config.Directories.PatchDir, "flex", "patches")
 	config.Flex.Prefix = "flex-"
-	err = populateToolRuntimeConfig(config.Flex, config.Directories, &configFile.BuildTool.Flex, &downloadsFile.Flex, toolchainToml.Flex)
+	err = populateToolRuntimeConfig(config.Flex, config.Directories, jobs, &configFile.BuildTool.Flex, &downloadsFile.Flex, toolchainToml.Flex)
 	if err != nil {
 		return nil, err
 	}
@@ -303,7 +519,7 @@ func BuildConfiguration(configFile *ConfigTomlTopLevel, downloadsFile *Downloads
 	config.GoCapnp = new(runtimeConfigTool)
 	config.GoCapnp.Name = "go-capnp"
 	config.GoCapnp.Prefix = "go-capnp-"
-	err = populateToolRuntimeConfig(config.GoCapnp, config.Directories, &configFile.BuildTool.GoCapnp, &downloadsFile.GoCapnp, toolchainToml.GoCapnp)
+	err = populateToolRuntimeConfig(config.GoCapnp, config.Directories, jobs, &configFile.BuildTool.GoCapnp, &downloadsFile.GoCapnp, toolchainToml.GoCapnp)
 	if err != nil {
 		return nil, err
 	}
@@ -315,6 +531,41 @@ func BuildConfiguration(configFile *ConfigTomlTopLevel, downloadsFile *Downloads
 	if err != nil {
 		return nil, err
 	}
+	// Generate BPF
+	config.Generate.Bpf = new(runtimeConfigGenerateBpf)
+	config.Generate.Bpf.Assembler = configFile.BuildTool.Generate.Bpf.Assembler
+	switch config.Generate.Bpf.Assembler {
+	case "":
+		config.Generate.Bpf.Assembler = BpfAssemblerBuiltin
+	case BpfAssemblerBuiltin, BpfAssemblerBpfAsm:
+	default:
+		return nil, fmt.Errorf("[build-tool.generate.bpf] Assembler must be %q or %q", BpfAssemblerBuiltin, BpfAssemblerBpfAsm)
+	}
+	config.Generate.Bpf.Enabled = generatorEnabled(configFile.BuildTool.Generate.Bpf.Enabled)
+	config.Generate.Bpf.Source = configFile.BuildTool.Generate.Bpf.Source
+	if config.Generate.Bpf.Source == "" {
+		config.Generate.Bpf.Source = "c/filter.s"
+	}
+	// Generate config
+	config.Generate.Config = new(runtimeConfigGenerateConfig)
+	config.Generate.Config.Enabled = generatorEnabled(configFile.BuildTool.Generate.Config.Enabled)
+	// Generate web UI
+	config.Generate.Web = new(runtimeConfigGenerateWeb)
+	err = populateGenerateWebRuntimeConfig(config.Generate.Web, config.Executables, &configFile.BuildTool.Generate.Web)
+	if err != nil {
+		return nil, err
+	}
+	// Publish Cap'n Proto module
+	config.PublishCapnp = new(runtimeConfigPublishCapnp)
+	config.PublishCapnp.ModulePath = configFile.BuildTool.PublishCapnp.ModulePath
+	config.PublishCapnp.OutputDir = configFile.BuildTool.PublishCapnp.OutputDir
+	if config.PublishCapnp.OutputDir == "" {
+		config.PublishCapnp.OutputDir = filepath.Join(config.Directories.BuildDir, "capnp-module")
+	}
+	config.PublishCapnp.SourceDir = configFile.BuildTool.PublishCapnp.SourceDir
+	if config.PublishCapnp.SourceDir == "" {
+		config.PublishCapnp.SourceDir = "capnp"
+	}
 	// Linux
 	config.linux = new(runtimeConfigLinux)
 	err = populateLinuxRuntimeConfig(config.linux, &configFile.BuildTool.Linux, &downloadsFile.Linux)
@@ -325,7 +576,7 @@ func BuildConfiguration(configFile *ConfigTomlTopLevel, downloadsFile *Downloads
 	config.TinyGo = new(runtimeConfigTool)
 	config.TinyGo.Name = "TinyGo"
 	config.TinyGo.Prefix = "tinygo-"
-	err = populateToolRuntimeConfig(config.TinyGo, config.Directories, &configFile.BuildTool.TinyGo, &downloadsFile.TinyGo, toolchainToml.TinyGo)
+	err = populateToolRuntimeConfig(config.TinyGo, config.Directories, jobs, &configFile.BuildTool.TinyGo, &downloadsFile.TinyGo, toolchainToml.TinyGo)
 	if err != nil {
 		return nil, err
 	}
@@ -426,7 +677,7 @@ func getGoPath(config *RuntimeConfigBuildTool, configFile *ConfigTomlTopLevel) (
 	return goPath, nil
 }
 
-func populateToolRuntimeConfig(runtimeConfig *runtimeConfigTool, directories *runtimeConfigDirectories, configFile *ConfigTomlTool, downloadsFile *DownloadsTomlTool, toolChainTool *ToolchainTomlTool) error {
+func populateToolRuntimeConfig(runtimeConfig *runtimeConfigTool, directories *runtimeConfigDirectories, defaultJobs int, configFile *ConfigTomlTool, downloadsFile *DownloadsTomlTool, toolChainTool *ToolchainTomlTool) error {
 	// First, get the version.
 	if configFile.Version != "" {
 		runtimeConfig.version = configFile.Version
@@ -443,34 +694,68 @@ func populateToolRuntimeConfig(runtimeConfig *runtimeConfigTool, directories *ru
 		runtimeConfig.downloadUrlTemplate = configFile.DownloadUrl
 	} else {
 		runtimeConfig.downloadUrlTemplate = downloadsFile.DownloadUrlTemplate
+		// A download URL from config.toml is usually a mirror, which
+		// shouldn't be bypassed.
+		runtimeConfig.githubRepo = downloadsFile.GitHubRepo
+		runtimeConfig.githubTagTemplate = downloadsFile.GitHubTagTemplate
 	}
 
+	if configFile.Jobs < 0 {
+		return fmt.Errorf("%s: Jobs must not be negative", runtimeConfig.Name)
+	} else if configFile.Jobs > 0 {
+		runtimeConfig.jobs = configFile.Jobs
+	} else {
+		runtimeConfig.jobs = defaultJobs
+	}
+
+	runtimeConfig.minimumVersion = configFile.MinimumVersion
+	runtimeConfig.prefetchModules = configFile.PrefetchModules
+
 	if configFile.Executable != "" {
 		runtimeConfig.Executable = configFile.Executable
 	} else {
 		// There is no executable
 		runtimeConfig.Executable = ""
 	}
+	runtimeConfig.archiveFormat = downloadsFile.ArchiveFormat
 	runtimeConfig.filenameTemplate = downloadsFile.FilenameTemplate
-	runtimeConfig.files = make(map[string]runtimeConfigFile)
-	for fileName, fileStruct := range downloadsFile.Files {
-		runtimeConfig.files[fileName] = runtimeConfigFile{
-			fileStruct.Sha256,
-			fileStruct.Size,
+	runtimeConfig.files = runtimeConfigFiles(downloadsFile.Files)
+	runtimeConfig.mirrorUrlTemplates = downloadsFile.MirrorUrlTemplates
+	if downloadsFile.ModCache != nil {
+		runtimeConfig.modCache = &runtimeConfigModCache{
+			archiveFormat:       downloadsFile.ModCache.ArchiveFormat,
+			downloadUrlTemplate: downloadsFile.ModCache.DownloadUrlTemplate,
+			filenameTemplate:    downloadsFile.ModCache.FilenameTemplate,
+			files:               runtimeConfigFiles(downloadsFile.ModCache.Files),
+			mirrorUrlTemplates:  downloadsFile.ModCache.MirrorUrlTemplates,
 		}
 	}
 	runtimeConfig.toolchainDir = filepath.Join(directories.ToolChainDir, runtimeConfig.versionedDir)
 	if toolChainTool == nil {
 		runtimeConfig.ToolChainExecutable = ""
+		runtimeConfig.toolchainPatches = nil
 		runtimeConfig.toolchainVersion = ""
 	} else {
 		runtimeConfig.toolchainVersion = toolChainTool.Version
-		runtimeConfig.ToolChainExecutable = filepath.Join(directories.ToolChainDir, toolChainTool.Executable)
+		runtimeConfig.ToolChainExecutable = toolchainExecutable(directories.ToolChainDir, toolChainTool.Executable)
+		runtimeConfig.toolchainPatches = toolChainTool.Patches
 	}
 
 	return nil
 }
 
+func runtimeConfigFiles(files map[string]DownloadsTomlFile) map[string]runtimeConfigFile {
+	ret := make(map[string]runtimeConfigFile, len(files))
+	for fileName, fileStruct := range files {
+		ret[fileName] = runtimeConfigFile{
+			fileStruct.digests(),
+			fileStruct.Size,
+			fileStruct.ExtractedSize,
+		}
+	}
+	return ret
+}
+
 func populateBpfAsmRuntimeConfig(runtimeConfig *runtimeConfigBpfAsm, directories *runtimeConfigDirectories, configFile *ConfigTomlBpfAsm, toolchainToml *ToolchainTomlTopLevel, configFileLinux *ConfigTomlLinux, downloadsFileLinux *DownloadsTomlTool) error {
 	// Version
 	if configFileLinux.Version != "" {
@@ -523,6 +808,23 @@ func populateExecutablesRuntimeConfig(config *RuntimeConfigBuildTool, configFile
 
 func populateGenerateCapnpRuntimeConfig(runtimeConfig *runtimeConfigGenerateCapnp, directories *runtimeConfigDirectories, configFile *ConfigTomlGenerateCapnp, goCapnpVersion string) error {
 	runtimeConfig.CapnpDirs = configFile.CapnpDirs
+	runtimeConfig.Enabled = generatorEnabled(configFile.Enabled)
+	for from, to := range configFile.ImportPaths {
+		if !isImportPath(from) || !isImportPath(to) {
+			return fmt.Errorf("[build-tool.generate.capnp.import-paths] %q = %q is not a rewrite from one import path to another", from, to)
+		}
+	}
+	runtimeConfig.ImportPaths = configFile.ImportPaths
+	if configFile.OutputDir != "" {
+		runtimeConfig.OutputDir = filepath.Clean(configFile.OutputDir)
+	}
+	runtimeConfig.Packages = make(map[string]string, len(configFile.Packages))
+	for schemaPath, packageName := range configFile.Packages {
+		if !token.IsIdentifier(packageName) {
+			return fmt.Errorf("[build-tool.generate.capnp.packages] %q is not a Go package name", packageName)
+		}
+		runtimeConfig.Packages[filepath.Clean(schemaPath)] = packageName
+	}
 	//	incrementalDir :=
 	stdDirTemplate := configFile.StdDirTemplate
 	if stdDirTemplate == "" {
@@ -547,13 +849,62 @@ func populateGenerateCapnpRuntimeConfig(runtimeConfig *runtimeConfigGenerateCapn
 	return nil
 }
 
+// isImportPath is a loose check that path looks like a Go import path, to
+// catch mistakes such as quoting it twice.
+func isImportPath(path string) bool {
+	return path != "" &&
+		!strings.ContainsAny(path, "\"' \t\\") &&
+		!strings.HasPrefix(path, "/") &&
+		!strings.HasSuffix(path, "/")
+}
+
+func populateGenerateWebRuntimeConfig(runtimeConfig *runtimeConfigGenerateWeb, executables *runtimeConfigExecutables, configFile *ConfigTomlGenerateWeb) error {
+	runtimeConfig.Compiler = configFile.Compiler
+	switch runtimeConfig.Compiler {
+	case "":
+		runtimeConfig.Compiler = WebCompilerTinyGo
+	case WebCompilerGo, WebCompilerTinyGo:
+	default:
+		return fmt.Errorf("[build-tool.generate.web] Compiler must be %q or %q", WebCompilerTinyGo, WebCompilerGo)
+	}
+	runtimeConfig.Enabled = generatorEnabled(configFile.Enabled)
+	// Brotli is optional; without it, assets are only precompressed with
+	// gzip.
+	runtimeConfig.BrotliExecutable = configFile.BrotliExecutable
+	if runtimeConfig.BrotliExecutable == "" {
+		if path, err := exec.LookPath("brotli"); err == nil {
+			runtimeConfig.BrotliExecutable = path
+		}
+	}
+	// Without a configured or toolchain Go, use the one in PATH.
+	runtimeConfig.GoExecutable = executables.goExecutable
+	if runtimeConfig.GoExecutable == "" {
+		runtimeConfig.GoExecutable = "go"
+	}
+	runtimeConfig.OutputDir = configFile.OutputDir
+	if runtimeConfig.OutputDir == "" {
+		runtimeConfig.OutputDir = "internal/server/embed"
+	}
+	runtimeConfig.Package = configFile.Package
+	if runtimeConfig.Package == "" {
+		runtimeConfig.Package = "./cmd/webui"
+	}
+	return nil
+}
+
+func generatorEnabled(enabled *bool) bool {
+	return enabled == nil || *enabled
+}
+
 func populateLinuxRuntimeConfig(runtimeConfig *runtimeConfigLinux, configFile *ConfigTomlLinux, downloadsFile *DownloadsTomlTool) error {
 	if configFile.DownloadUrl != "" {
 		runtimeConfig.downloadUrlTemplate = configFile.DownloadUrl
 	} else {
 		runtimeConfig.downloadUrlTemplate = downloadsFile.DownloadUrlTemplate
 	}
+	runtimeConfig.archiveFormat = downloadsFile.ArchiveFormat
 	runtimeConfig.filenameTemplate = downloadsFile.FilenameTemplate
+	runtimeConfig.mirrorUrlTemplates = downloadsFile.MirrorUrlTemplates
 	if configFile.Version != "" {
 		runtimeConfig.version = configFile.Version
 	} else {
@@ -565,15 +916,54 @@ func populateLinuxRuntimeConfig(runtimeConfig *runtimeConfigLinux, configFile *C
 	runtimeConfig.files = make(map[string]runtimeConfigFile)
 	for fileName, fileStruct := range downloadsFile.Files {
 		runtimeConfig.files[fileName] = runtimeConfigFile{
-			fileStruct.Sha256,
+			fileStruct.digests(),
 			fileStruct.Size,
+			fileStruct.ExtractedSize,
+		}
+	}
+	return nil
+}
+
+// ApplyProfile applies the named profile from [build-tool.profiles] to
+// configFile.  An empty name selects the default toolchain, leaving
+// configFile unchanged.
+func ApplyProfile(configFile *ConfigTomlTopLevel, name string) error {
+	if name == "" {
+		return nil
+	}
+	profile, ok := configFile.BuildTool.Profiles[name]
+	if !ok {
+		return fmt.Errorf("No [build-tool.profiles.%s] in config.toml", name)
+	}
+	if profile.ToolChainDirTemplate == "" {
+		// Sharing the default toolchain directory would let the profiles
+		// overwrite each other's toolchain.toml.
+		return fmt.Errorf("[build-tool.profiles.%s] must set ToolChainDirTemplate", name)
+	}
+	configFile.BuildTool.ToolChainDirTemplate = profile.ToolChainDirTemplate
+	versions := map[string]*string{
+		"binaryen":  &configFile.BuildTool.Binaryen.Version,
+		"bison":     &configFile.BuildTool.Bison.Version,
+		"capnproto": &configFile.BuildTool.CapnProto.Version,
+		"flex":      &configFile.BuildTool.Flex.Version,
+		"go-capnp":  &configFile.BuildTool.GoCapnp.Version,
+		"linux":     &configFile.BuildTool.Linux.Version,
+		"tinygo":    &configFile.BuildTool.TinyGo.Version,
+	}
+	for tool, version := range profile.Versions {
+		field, ok := versions[tool]
+		if !ok {
+			return fmt.Errorf("[build-tool.profiles.%s.versions] has unknown tool %q", name, tool)
 		}
+		*field = version
 	}
 	return nil
 }
 
-func ReadConfigFile(configFilePath *string) (*ConfigTomlTopLevel, error) {
+// ReadConfigFile reads config.toml, migrating it from older layouts, and
+// returns warnings about the migration and unknown keys.
+func ReadConfigFile(configFilePath *string) (*ConfigTomlTopLevel, []string, error) {
 	config := new(ConfigTomlTopLevel)
-	_, err := toml.DecodeFile(*configFilePath, config)
-	return config, err
+	messages, err := decodeVersionedToml(*configFilePath, ConfigTomlSchemaVersion, configTomlMigrations, config)
+	return config, messages, err
 }
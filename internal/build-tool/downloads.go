@@ -17,10 +17,17 @@
 package buildtool
 
 import (
-	"github.com/BurntSushi/toml"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
 )
 
 type DownloadsTomlTopLevel struct {
+	SchemaVersion int
+
 	Binaryen  DownloadsTomlTool `toml:"binaryen"`
 	Bison     DownloadsTomlTool `toml:"bison"`
 	CapnProto DownloadsTomlTool `toml:"capnproto"`
@@ -31,22 +38,270 @@ type DownloadsTomlTopLevel struct {
 }
 
 type DownloadsTomlTool struct {
+	// ArchiveFormat is "tar.bz2", "tar.gz", "tar.xz" or "tar.zst".  It
+	// is only needed if the format isn't clear from the file names'
+	// extensions.
+	ArchiveFormat       string
 	DownloadUrlTemplate string
 	FilenameTemplate    string
 	Files               map[string]DownloadsTomlFile
-	PreferredVersion    string
+	// MirrorUrlTemplates are expanded like DownloadUrlTemplate.  When a
+	// download doesn't match downloads.toml and is downloaded again, the
+	// first mirror is used.
+	MirrorUrlTemplates []string
+	// GitHubRepo, e.g., "tinygo-org/tinygo", is set for tools released on
+	// GitHub.  With a token, bootstraps download the release's assets
+	// through the GitHub API, and update-downloads lists them.
+	// GitHubTagTemplate expands to the release's tag.
+	GitHubRepo        string
+	GitHubTagTemplate string
+	// Platforms lists the "os/arch" pairs, using Go's GOOS and GOARCH
+	// names, for which the tool publishes binaries.  Tools built from
+	// source leave it empty.
+	Platforms        []string
+	PreferredVersion string
+	// ModCache, for Go tools, describes archives of a Go module cache
+	// holding the dependencies of each version, so that the tool can be
+	// built without network access.  Its PreferredVersion is unused.
+	ModCache *DownloadsTomlTool `toml:"modcache"`
 }
 
+// DownloadsTomlFile describes one artifact.  Together, each tool's files form
+// a matrix of versions and platforms; Os and Arch are empty for source
+// archives.
 type DownloadsTomlFile struct {
+	Version string
+	Os      string
+	Arch    string
+	// At least one digest is required; all which are given are verified.
+	// Some upstreams only publish SHA-512.
 	Sha256 string `toml:"SHA-256"`
+	Sha512 string `toml:"SHA-512"`
+	Blake3 string `toml:"BLAKE3"`
 	Size   int64
+	// ExtractedSize is how many bytes the files the build-tool extracts
+	// from the archive take, so that it can check there is room in
+	// ToolChainDir before extracting them.  It is optional.
+	ExtractedSize int64
+}
+
+func (file DownloadsTomlFile) digests() fileDigests {
+	return fileDigests{
+		sha256: file.Sha256,
+		sha512: file.Sha512,
+		blake3: file.Blake3,
+	}
 }
 
-func ReadDownloadsFile(downloadsFilePath *string) (*DownloadsTomlTopLevel, error) {
+// ReadDownloadsFile reads downloads.toml, migrating it from older layouts,
+// and returns warnings about the migration and unknown keys.
+func ReadDownloadsFile(downloadsFilePath *string) (*DownloadsTomlTopLevel, []string, error) {
 	downloads := new(DownloadsTomlTopLevel)
-	_, err := toml.DecodeFile(*downloadsFilePath, downloads)
+	messages, err := decodeVersionedToml(*downloadsFilePath, DownloadsTomlSchemaVersion, downloadsTomlMigrations, downloads)
+	if err != nil {
+		return nil, messages, err
+	}
+	return downloads, messages, nil
+}
+
+// downloadsToolTemplates expands a tool's filename and download URL
+// templates, using the same values as the tool's bootstrap.
+type downloadsToolTemplates struct {
+	name              string
+	tool              *DownloadsTomlTool
+	filenameValues    func(version string, goos string, goarch string) any
+	downloadUrlValues func(filename string, version string) (any, error)
+}
+
+func getDownloadsToolTemplates(downloads *DownloadsTomlTopLevel) []downloadsToolTemplates {
+	toolTemplates := make([]downloadsToolTemplates, 0, len(bootstrappers)+1)
+	for _, command := range BootstrapperCommands() {
+		tool, ok := bootstrappers[command].(*archiveTool)
+		if !ok {
+			continue
+		}
+		toolDownloads := tool.downloads(downloads)
+		toolTemplates = append(toolTemplates, downloadsToolTemplates{
+			command,
+			toolDownloads,
+			func(version string, goos string, goarch string) any {
+				return tool.templateValues(version, goos, goarch)
+			},
+			func(filename string, version string) (any, error) {
+				values := tool.templateValues(version, "", "")
+				values.Filename = filename
+				return values, nil
+			},
+		})
+		if toolDownloads.ModCache != nil {
+			// The preferred version needs a module cache too.
+			modCache := *toolDownloads.ModCache
+			modCache.PreferredVersion = toolDownloads.PreferredVersion
+			toolTemplates = append(toolTemplates, downloadsToolTemplates{
+				command + ".modcache",
+				&modCache,
+				func(version string, goos string, goarch string) any {
+					return toolTemplateValues{Version: version}
+				},
+				func(filename string, version string) (any, error) {
+					return toolTemplateValues{Filename: filename, Version: version}, nil
+				},
+			})
+		}
+	}
+	// Linux isn't a tool in its own right, but bpf_asm is built from it.
+	toolTemplates = append(toolTemplates, downloadsToolTemplates{
+		"linux",
+		&downloads.Linux,
+		func(version string, goos string, goarch string) any {
+			return linuxFilenameTemplateValues{version}
+		},
+		func(filename string, version string) (any, error) {
+			majorVersion, err := getLinuxMajorVersion(version)
+			return linuxDownloadUrlTemplateValues{filename, majorVersion}, err
+		},
+	})
+	return toolTemplates
+}
+
+// ValidateDownloads checks that every version and platform declared in
+// downloads.toml has a file with a size and SHA-256, and that each tool's
+// templates expand to that file's name and a download URL.
+func ValidateDownloads(downloads *DownloadsTomlTopLevel) ([]string, error) {
+	messages := make([]string, 0, 10)
+	problems := 0
+	for _, toolTemplates := range getDownloadsToolTemplates(downloads) {
+		toolProblems := validateDownloadsTool(toolTemplates)
+		for _, problem := range toolProblems {
+			messages = append(messages, fmt.Sprintf("[%s] %s", toolTemplates.name, problem))
+		}
+		if len(toolProblems) == 0 {
+			messages = append(messages, fmt.Sprintf("[%s] OK", toolTemplates.name))
+		}
+		problems += len(toolProblems)
+	}
+	if problems > 0 {
+		return messages, fmt.Errorf("Found %d problems in downloads.toml", problems)
+	}
+	return messages, nil
+}
+
+// validateDownloadsDigests checks that a file has at least one digest, and
+// that each digest it has is valid hex of the right length.
+func validateDownloadsDigests(fileName string, file DownloadsTomlFile) []string {
+	problems := make([]string, 0)
+	digests := file.digests()
+	found := false
+	for _, algorithm := range digestAlgorithms {
+		expected := algorithm.expected(digests)
+		if expected == "" {
+			continue
+		}
+		found = true
+		if digest, err := hex.DecodeString(expected); err != nil || len(digest) != algorithm.size {
+			problems = append(problems, fmt.Sprintf("%s: %s is invalid", fileName, algorithm.name))
+		}
+	}
+	if !found {
+		problems = append(problems, fmt.Sprintf("%s: SHA-256, SHA-512 or BLAKE3 is required", fileName))
+	}
+	return problems
+}
+
+func validateDownloadsTool(toolTemplates downloadsToolTemplates) []string {
+	tool := toolTemplates.tool
+	problems := make([]string, 0)
+	filenameTemplate, err := template.New("filename").Parse(tool.FilenameTemplate)
+	if err != nil {
+		return append(problems, fmt.Sprintf("FilenameTemplate: %v", err))
+	}
+	downloadUrlTemplate, err := template.New("downloadUrl").Parse(tool.DownloadUrlTemplate)
 	if err != nil {
-		return nil, err
+		return append(problems, fmt.Sprintf("DownloadUrlTemplate: %v", err))
+	}
+	for _, mirrorUrlTemplate := range tool.MirrorUrlTemplates {
+		_, err = template.New("mirrorUrl").Parse(mirrorUrlTemplate)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("MirrorUrlTemplates: %v", err))
+		}
+	}
+	if tool.PreferredVersion == "" {
+		problems = append(problems, "PreferredVersion is missing")
+	}
+	for _, platform := range tool.Platforms {
+		goos, goarch, found := strings.Cut(platform, "/")
+		if !found || goos == "" || goarch == "" {
+			problems = append(problems, fmt.Sprintf("Platform %q is not of the form os/arch", platform))
+		}
+	}
+
+	// Every cell of the version x platform matrix needs a file.
+	platforms := tool.Platforms
+	if len(platforms) == 0 {
+		platforms = []string{"/"}
+	}
+	versions := []string{tool.PreferredVersion}
+	fileNames := make([]string, 0, len(tool.Files))
+	for fileName, file := range tool.Files {
+		fileNames = append(fileNames, fileName)
+		if !slices.Contains(versions, file.Version) {
+			versions = append(versions, file.Version)
+		}
+	}
+	slices.Sort(fileNames)
+	slices.Sort(versions)
+	for _, version := range versions {
+		if version == "" {
+			continue
+		}
+		for _, platform := range platforms {
+			found := false
+			for _, file := range tool.Files {
+				if file.Version == version && file.Os+"/"+file.Arch == platform {
+					found = true
+					break
+				}
+			}
+			if !found && platform == "/" {
+				problems = append(problems, fmt.Sprintf("No file for version %s", version))
+			} else if !found {
+				problems = append(problems, fmt.Sprintf("No file for version %s on %s", version, platform))
+			}
+		}
+	}
+
+	for _, fileName := range fileNames {
+		file := tool.Files[fileName]
+		if file.Version == "" {
+			problems = append(problems, fmt.Sprintf("%s: Version is missing", fileName))
+			continue
+		}
+		if len(tool.Platforms) == 0 && (file.Os != "" || file.Arch != "") {
+			problems = append(problems, fmt.Sprintf("%s: Os and Arch are set, but the tool has no Platforms", fileName))
+		} else if len(tool.Platforms) > 0 && !slices.Contains(tool.Platforms, file.Os+"/"+file.Arch) {
+			problems = append(problems, fmt.Sprintf("%s: %s/%s is not one of the tool's Platforms", fileName, file.Os, file.Arch))
+		}
+		problems = append(problems, validateDownloadsDigests(fileName, file)...)
+		if _, err := archiveFormatOf(fileName, tool.ArchiveFormat); err != nil {
+			problems = append(problems, err.Error())
+		}
+		if file.Size <= 0 {
+			problems = append(problems, fmt.Sprintf("%s: Size is missing", fileName))
+		}
+		var filenameBuffer bytes.Buffer
+		err = filenameTemplate.Execute(&filenameBuffer, toolTemplates.filenameValues(file.Version, file.Os, file.Arch))
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: FilenameTemplate: %v", fileName, err))
+		} else if filenameBuffer.String() != fileName {
+			problems = append(problems, fmt.Sprintf("%s: FilenameTemplate expands to %s", fileName, filenameBuffer.String()))
+		}
+		downloadUrlValues, err := toolTemplates.downloadUrlValues(fileName, file.Version)
+		if err == nil {
+			err = downloadUrlTemplate.Execute(new(bytes.Buffer), downloadUrlValues)
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: DownloadUrlTemplate: %v", fileName, err))
+		}
 	}
-	return downloads, nil
+	return problems
 }
@@ -0,0 +1,211 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildtool
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// gcToolPrefixes are the prefixes of the versioned directories the
+// build-tool (and the Makefile, for Go itself) creates in the toolchain
+// directory.  Only directories named with one of these prefixes followed by a
+// version number are ever removed.
+var gcToolPrefixes = []string{
+	"binaryen-version_",
+	"bison-",
+	"bpf_asm-",
+	"capnp-",
+	"capnproto-",
+	"flex-",
+	"go-",
+	"go-capnp-",
+	"tinygo-",
+}
+
+// CollectGarbage removes versioned tool directories from the toolchain
+// directory which toolchain.toml doesn't refer to, except for the keep newest
+// versions of each tool.  Directories locked by another build-tool are kept.
+// With dryRun, it only lists what it would remove.
+func CollectGarbage(buildToolConfig *RuntimeConfigBuildTool, keep int, dryRun bool) ([]string, error) {
+	messages := make([]string, 0, 10)
+	if keep < 0 {
+		return messages, fmt.Errorf("--keep must not be negative")
+	}
+	toolChainDir := buildToolConfig.Directories.ToolChainDir
+	toolchainTomlExists, err := fileExistsAtPath(toolchainTomlFilePathWithToolchainDir(toolChainDir))
+	if err != nil {
+		return messages, err
+	}
+	if !toolchainTomlExists {
+		// Without toolchain.toml, everything would look unused.
+		return messages, fmt.Errorf("No toolchain.toml in %s; not removing anything", toolChainDir)
+	}
+	// Hold the toolchain.toml lock throughout, so that a tool which is
+	// installed after toolchain.toml is read is still locked when we get to
+	// its directory.
+	unlockToolchainToml, err := lockToolchainToml(toolChainDir, buildToolConfig.WaitForLocks)
+	if err != nil {
+		return messages, err
+	}
+	defer unlockToolchainToml()
+	toolchainToml, err := ReadToolchainToml(toolChainDir)
+	if err != nil {
+		return messages, err
+	}
+	referenced := make(map[string]bool)
+	for _, tool := range []*ToolchainTomlTool{
+		toolchainToml.Binaryen,
+		toolchainToml.Bison,
+		toolchainToml.BpfAsm,
+		toolchainToml.CapnProto,
+		toolchainToml.Flex,
+		toolchainToml.Go,
+		toolchainToml.GoCapnp,
+		toolchainToml.TinyGo,
+	} {
+		if tool == nil || tool.Executable == "" {
+			continue
+		}
+		topDir, _, _ := strings.Cut(filepath.ToSlash(filepath.Clean(tool.Executable)), "/")
+		referenced[topDir] = true
+	}
+
+	entries, err := os.ReadDir(toolChainDir)
+	if err != nil {
+		return messages, err
+	}
+	unreferenced := make(map[string][]string)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		prefix := gcToolPrefix(entry.Name())
+		if prefix == "" {
+			continue
+		}
+		if referenced[entry.Name()] {
+			messages = append(messages, fmt.Sprintf("Keeping %s (in toolchain.toml)", entry.Name()))
+			continue
+		}
+		unreferenced[prefix] = append(unreferenced[prefix], entry.Name())
+	}
+
+	prefixes := make([]string, 0, len(unreferenced))
+	for prefix := range unreferenced {
+		prefixes = append(prefixes, prefix)
+	}
+	slices.Sort(prefixes)
+	for _, prefix := range prefixes {
+		dirs := unreferenced[prefix]
+		// Newest first.
+		slices.SortFunc(dirs, func(a, b string) int {
+			return compareVersions(b[len(prefix):], a[len(prefix):])
+		})
+		for index, dir := range dirs {
+			if index < keep {
+				messages = append(messages, fmt.Sprintf("Keeping %s (one of the %d newest)", dir, keep))
+				continue
+			}
+			dirPath := filepath.Join(toolChainDir, dir)
+			if dryRun {
+				messages = append(messages, fmt.Sprintf("Would remove %s", dirPath))
+				continue
+			}
+			// Never wait: a locked directory is being installed, and
+			// will probably be referenced by toolchain.toml soon.
+			unlock, err := lockFile(toolChainDir, dir, false)
+			if errors.Is(err, ErrLocked) {
+				messages = append(messages, fmt.Sprintf("Keeping %s (in use by another build-tool)", dir))
+				continue
+			} else if err != nil {
+				return messages, err
+			}
+			err = removeAllWritable(dirPath)
+			unlock()
+			if err != nil {
+				return messages, err
+			}
+			messages = append(messages, fmt.Sprintf("Removed %s", dirPath))
+		}
+	}
+	return messages, nil
+}
+
+// gcToolPrefix returns the longest prefix in gcToolPrefixes that name starts
+// with, if it is followed by a version number, or "" otherwise.
+func gcToolPrefix(name string) string {
+	result := ""
+	for _, prefix := range gcToolPrefixes {
+		if len(prefix) > len(result) && strings.HasPrefix(name, prefix) {
+			rest := name[len(prefix):]
+			if rest != "" && rest[0] >= '0' && rest[0] <= '9' {
+				result = prefix
+			}
+		}
+	}
+	return result
+}
+
+// compareVersions compares dotted version strings numerically where
+// possible, so that "1.10.0" sorts after "1.9.2".
+func compareVersions(a string, b string) int {
+	aParts := strings.FieldsFunc(a, isVersionSeparator)
+	bParts := strings.FieldsFunc(b, isVersionSeparator)
+	for index := 0; index < len(aParts) && index < len(bParts); index++ {
+		aNumber, aErr := strconv.Atoi(aParts[index])
+		bNumber, bErr := strconv.Atoi(bParts[index])
+		var result int
+		if aErr == nil && bErr == nil {
+			result = aNumber - bNumber
+		} else {
+			result = strings.Compare(aParts[index], bParts[index])
+		}
+		if result != 0 {
+			return result
+		}
+	}
+	return len(aParts) - len(bParts)
+}
+
+func isVersionSeparator(r rune) bool {
+	return r == '.' || r == '-' || r == '_' || r == '+'
+}
+
+// removeAllWritable is os.RemoveAll, but first makes directories writable;
+// Go's module cache, for one, is read-only.
+func removeAllWritable(dirPath string) error {
+	err := filepath.WalkDir(dirPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return os.Chmod(path, 0750)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dirPath)
+}
@@ -0,0 +1,218 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	buildtool "sandstorm.org/go/tempest/internal/build-tool"
+	"sandstorm.org/go/tempest/internal/config/configgen"
+)
+
+// A generatorPlan is what a generator reads and writes, and how to run it.
+type generatorPlan struct {
+	inputFiles  []string
+	settings    map[string]string // other inputs, e.g., tool paths
+	outputFiles []string
+	run         func() ([]string, error)
+}
+
+type generator struct {
+	name    string
+	enabled bool
+	plan    func(buildToolConfig *buildtool.RuntimeConfigBuildTool) (*generatorPlan, error)
+}
+
+// GeneratorNames lists the generators run by GenerateAll, in order.
+var GeneratorNames = []string{"config", "capnp", "bpf", "web"}
+
+func getGenerators(buildToolConfig *buildtool.RuntimeConfigBuildTool) ([]generator, error) {
+	if buildToolConfig.Generate == nil {
+		return nil, fmt.Errorf("buildToolConfig.Generate is nil")
+	}
+	return []generator{
+		{"config", buildToolConfig.Generate.Config.Enabled, planConfig},
+		{"capnp", buildToolConfig.Generate.Capnp.Enabled, planCapnp},
+		{"bpf", buildToolConfig.Generate.Bpf.Enabled, planBpf},
+		{"web", buildToolConfig.Generate.Web.Enabled, planWeb},
+	}, nil
+}
+
+// GenerateAll runs every generator enabled in config.toml.
+func GenerateAll(buildToolConfig *buildtool.RuntimeConfigBuildTool, force bool) ([]string, error) {
+	return Generate(buildToolConfig, nil, force)
+}
+
+// Generate runs the named generators, or all enabled generators if names is
+// empty.  Naming a generator runs it even if config.toml disables it.  Unless
+// force is set, a generator whose inputs haven't changed since it last ran,
+// and whose outputs still exist, is skipped.  The messages end with a summary
+// of what was regenerated and why.
+func Generate(buildToolConfig *buildtool.RuntimeConfigBuildTool, names []string, force bool) ([]string, error) {
+	messages := make([]string, 0, 10)
+	summary := make([]string, 0, len(GeneratorNames))
+	generators, err := getGenerators(buildToolConfig)
+	if err != nil {
+		return messages, err
+	}
+	for _, name := range names {
+		if !slices.Contains(GeneratorNames, name) {
+			return messages, fmt.Errorf("Unknown generator %q; expected one of %s", name, strings.Join(GeneratorNames, ", "))
+		}
+	}
+	incrementalDir := buildToolConfig.Directories.IncrementalDir
+	for _, generator := range generators {
+		if len(names) == 0 && !generator.enabled {
+			summary = append(summary, fmt.Sprintf("%s: skipped, disabled in config.toml", generator.name))
+			continue
+		}
+		if len(names) > 0 && !slices.Contains(names, generator.name) {
+			continue
+		}
+		plan, err := generator.plan(buildToolConfig)
+		if err != nil {
+			messages = append(messages, fmt.Sprintf("Failed to get the %s generator's configuration", generator.name))
+			return append(messages, summary...), err
+		}
+		current, err := currentGenerateRecord(plan.inputFiles, plan.settings)
+		if err != nil {
+			return append(messages, summary...), err
+		}
+		previous, err := readGenerateRecord(incrementalDir, generator.name)
+		if err != nil {
+			return append(messages, summary...), err
+		}
+		reasons := staleReasons(previous, current, plan.outputFiles)
+		if force {
+			reasons = append([]string{"--force"}, reasons...)
+		}
+		if len(reasons) == 0 {
+			summary = append(summary, fmt.Sprintf("%s: up to date", generator.name))
+			continue
+		}
+		runMessages, err := plan.run()
+		messages = append(messages, runMessages...)
+		if err != nil {
+			summary = append(summary, fmt.Sprintf("%s: failed", generator.name))
+			return append(messages, summary...), err
+		}
+		err = writeGenerateRecord(incrementalDir, generator.name, current)
+		if err != nil {
+			return append(messages, summary...), err
+		}
+		summary = append(summary, fmt.Sprintf("%s: regenerated because %s", generator.name, strings.Join(reasons, ", ")))
+	}
+	return append(messages, summary...), nil
+}
+
+func planBpf(buildToolConfig *buildtool.RuntimeConfigBuildTool) (*generatorPlan, error) {
+	config, err := getGenerateBpfConfig(buildToolConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &generatorPlan{
+		inputFiles: []string{config.source, config.constantsHeader},
+		settings: map[string]string{
+			"assembler": config.assembler,
+			"bpf_asm":   config.bpfAsmExecutable,
+		},
+		outputFiles: []string{config.output},
+		run: func() ([]string, error) {
+			return generateBpf(config)
+		},
+	}, nil
+}
+
+func planCapnp(buildToolConfig *buildtool.RuntimeConfigBuildTool) (*generatorPlan, error) {
+	config, err := getGenerateCapnpConfig(buildToolConfig)
+	if err != nil {
+		return nil, err
+	}
+	capnpFilepaths, err := getGlobbedCapnpFilePaths(config)
+	if err != nil {
+		return nil, err
+	}
+	outputFiles := make([]string, 0, len(capnpFilepaths))
+	for _, capnpFilepath := range capnpFilepaths {
+		outputFiles = append(outputFiles, capnpGoFilepath(config, capnpFilepath))
+	}
+	return &generatorPlan{
+		inputFiles: capnpFilepaths,
+		settings: map[string]string{
+			"capnp":        config.capnpExecutable,
+			"go-capnp":     config.goCapnpExecutable,
+			"import-paths": formatSettingsMap(config.importPaths),
+			"output-dir":   config.outputDir,
+			"packages":     formatSettingsMap(config.packages),
+			"std-dir":      config.stdDir,
+		},
+		outputFiles: outputFiles,
+		run: func() ([]string, error) {
+			return generateCapnpFiles(config, capnpFilepaths)
+		},
+	}, nil
+}
+
+func planConfig(buildToolConfig *buildtool.RuntimeConfigBuildTool) (*generatorPlan, error) {
+	return &generatorPlan{
+		inputFiles:  []string{configJsonPath},
+		outputFiles: []string{configgen.GoPath, configgen.CPath},
+		run: func() ([]string, error) {
+			return GenerateConfig(buildToolConfig)
+		},
+	}, nil
+}
+
+func planWeb(buildToolConfig *buildtool.RuntimeConfigBuildTool) (*generatorPlan, error) {
+	config, err := getGenerateWebConfig(buildToolConfig)
+	if err != nil {
+		return nil, err
+	}
+	goFiles, err := webGoFiles(config)
+	if err != nil {
+		return nil, err
+	}
+	assets, err := webAssets(config.outputDir)
+	if err != nil {
+		return nil, err
+	}
+	inputFiles := append(goFiles, assets...)
+	inputFiles = append(inputFiles, config.wasmExecJs)
+	return &generatorPlan{
+		inputFiles: inputFiles,
+		settings: map[string]string{
+			"brotli":   config.brotliExecutable,
+			"compiler": config.compiler,
+			"go":       config.goExecutable,
+			"package":  config.packagePath,
+			"tinygo":   config.tinyGoExecutable,
+			"wasm-opt": config.wasmOptExecutable,
+		},
+		outputFiles: []string{
+			filepath.Join(config.outputDir, "webui.wasm"),
+			filepath.Join(config.outputDir, "wasm_exec.js"),
+			filepath.Join(config.outputDir, webAssetManifestName),
+			filepath.Join(config.outputDir, hashedAssetsDir, hashedAssetManifestName),
+		},
+		run: func() ([]string, error) {
+			return generateWeb(config)
+		},
+	}, nil
+}
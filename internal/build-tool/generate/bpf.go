@@ -0,0 +1,155 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	buildtool "sandstorm.org/go/tempest/internal/build-tool"
+	"sandstorm.org/go/tempest/internal/build-tool/bpfasm"
+)
+
+type generateBpfConfig struct {
+	assembler        string
+	bpfAsmExecutable string
+	buildDir         string
+	constantsHeader  string
+	output           string
+	preprocessed     string
+	source           string
+}
+
+// GenerateBpf assembles the sandbox launcher's seccomp filter.  The source is
+// run through the C preprocessor, with the build directory's constants.h
+// available, and then the configured assembler, producing bpf_filter.h in the
+// build directory.
+func GenerateBpf(buildToolConfig *buildtool.RuntimeConfigBuildTool) ([]string, error) {
+	messages := make([]string, 0, 2)
+	config, err := getGenerateBpfConfig(buildToolConfig)
+	if err != nil {
+		messages = append(messages, "Failed to get the Generate BPF configuration")
+		return messages, err
+	}
+	return generateBpf(config)
+}
+
+func generateBpf(config *generateBpfConfig) ([]string, error) {
+	messages := make([]string, 0, 2)
+	cmd := exec.Command("cpp", "-I", config.buildDir, config.source, "-o", config.preprocessed)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	if err != nil {
+		messages = append(messages, "Failed to preprocess "+config.source)
+		return messages, err
+	}
+	if config.assembler == buildtool.BpfAssemblerBuiltin {
+		assembleMessages, err := AssembleBpf(config.preprocessed, config.output)
+		messages = append(messages, assembleMessages...)
+		return messages, err
+	}
+	preprocessed, err := os.Open(config.preprocessed)
+	if err != nil {
+		return messages, err
+	}
+	defer preprocessed.Close()
+	var output bytes.Buffer
+	cmd = exec.Command(config.bpfAsmExecutable, "-c")
+	cmd.Stdin = preprocessed
+	cmd.Stdout = &output
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	if err != nil {
+		messages = append(messages, "Failed to assemble "+config.preprocessed)
+		return messages, err
+	}
+	// Only replace the output once it is complete, so that a failure
+	// doesn't leave a truncated header behind.
+	err = os.WriteFile(config.output, output.Bytes(), 0644)
+	if err != nil {
+		return messages, err
+	}
+	messages = append(messages, fmt.Sprintf("Assembled %s into %s", config.source, config.output))
+	return messages, nil
+}
+
+// AssembleBpf assembles the preprocessed seccomp filter in input with the
+// bpfasm package, writing the output of bpf_asm -c to output.
+func AssembleBpf(input string, output string) ([]string, error) {
+	messages := make([]string, 0, 1)
+	source, err := os.Open(input)
+	if err != nil {
+		return messages, err
+	}
+	defer source.Close()
+	program, err := bpfasm.Assemble(input, source)
+	if err != nil {
+		messages = append(messages, "Failed to assemble "+input)
+		return messages, err
+	}
+	var buffer bytes.Buffer
+	err = bpfasm.WriteC(&buffer, program)
+	if err != nil {
+		return messages, err
+	}
+	// As with bpf_asm, only replace the output once it is complete.
+	err = os.WriteFile(output, buffer.Bytes(), 0644)
+	if err != nil {
+		return messages, err
+	}
+	messages = append(messages, fmt.Sprintf("Assembled %s into %s (%d instructions)", input, output, len(program)))
+	return messages, nil
+}
+
+func getGenerateBpfConfig(buildToolConfig *buildtool.RuntimeConfigBuildTool) (*generateBpfConfig, error) {
+	if buildToolConfig.BpfAsm == nil {
+		return nil, fmt.Errorf("buildToolConfig.BpfAsm is nil")
+	}
+	if buildToolConfig.Directories == nil {
+		return nil, fmt.Errorf("buildToolConfig.Directories is nil")
+	}
+	if buildToolConfig.Generate == nil || buildToolConfig.Generate.Bpf == nil {
+		return nil, fmt.Errorf("buildToolConfig.Generate.Bpf is nil")
+	}
+	// bpf_asm executable, which the builtin assembler doesn't need
+	assembler := buildToolConfig.Generate.Bpf.Assembler
+	bpfAsmExecutable := ""
+	if assembler == buildtool.BpfAssemblerBpfAsm {
+		if buildToolConfig.BpfAsm.Executable != "" {
+			bpfAsmExecutable = buildToolConfig.BpfAsm.Executable
+		} else if buildToolConfig.BpfAsm.ToolChainExecutable != "" {
+			bpfAsmExecutable = buildToolConfig.BpfAsm.ToolChainExecutable
+		} else {
+			return nil, fmt.Errorf("Unable to find bpf_asm executable")
+		}
+	}
+	buildDir := buildToolConfig.Directories.BuildDir
+
+	result := new(generateBpfConfig)
+	result.assembler = assembler
+	result.bpfAsmExecutable = bpfAsmExecutable
+	result.buildDir = buildDir
+	result.constantsHeader = filepath.Join(buildDir, "constants.h")
+	result.output = filepath.Join(buildDir, "bpf_filter.h")
+	result.preprocessed = filepath.Join(buildDir, "filter_preproc.s")
+	result.source = buildToolConfig.Generate.Bpf.Source
+	return result, nil
+}
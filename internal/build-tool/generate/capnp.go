@@ -22,6 +22,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+
 	buildtool "sandstorm.org/go/tempest/internal/build-tool"
 )
 
@@ -29,7 +31,10 @@ type generateCapnpConfig struct {
 	capnpDirs         []string
 	capnpExecutable   string
 	goCapnpExecutable string
+	importPaths       map[string]string
 	incrementalDir    string
+	outputDir         string
+	packages          map[string]string // by cleaned schema path
 	stdDir            string
 }
 
@@ -41,6 +46,37 @@ func GenerateCapnp(buildToolConfig *buildtool.RuntimeConfigBuildTool) ([]string,
 		return messages, err
 	}
 	capnpFilepaths, err := getGlobbedCapnpFilePaths(config)
+	if err != nil {
+		return messages, err
+	}
+	messages = append(messages, unusedPackageOverrides(config, capnpFilepaths)...)
+	generateMessages, err := generateCapnpFiles(config, capnpFilepaths)
+	messages = append(messages, generateMessages...)
+	return messages, err
+}
+
+// unusedPackageOverrides warns about package overrides for schemas which
+// don't exist, which are probably misspelled.
+func unusedPackageOverrides(config *generateCapnpConfig, capnpFilepaths []string) []string {
+	messages := make([]string, 0)
+	for schemaPath := range config.packages {
+		found := false
+		for _, capnpFilepath := range capnpFilepaths {
+			if filepath.Clean(capnpFilepath) == schemaPath {
+				found = true
+				break
+			}
+		}
+		if !found {
+			messages = append(messages, fmt.Sprintf("Warning: [build-tool.generate.capnp.packages] names %s, which is not in CapnpDirs", schemaPath))
+		}
+	}
+	sort.Strings(messages)
+	return messages
+}
+
+func generateCapnpFiles(config *generateCapnpConfig, capnpFilepaths []string) ([]string, error) {
+	messages := make([]string, 0, 5)
 	for _, capnpFilepath := range capnpFilepaths {
 		cgr, err := codeGeneratorRequestWithCapnp(config, capnpFilepath)
 		if err != nil {
@@ -116,6 +152,9 @@ func getGenerateCapnpConfig(buildToolConfig *buildtool.RuntimeConfigBuildTool) (
 	result.capnpDirs = capnpDirs
 	result.capnpExecutable = capnpExecutable
 	result.goCapnpExecutable = goCapnpExecutable
+	result.importPaths = buildToolConfig.Generate.Capnp.ImportPaths
+	result.outputDir = buildToolConfig.Generate.Capnp.OutputDir
+	result.packages = buildToolConfig.Generate.Capnp.Packages
 	result.stdDir = stdDir
 	return result, nil
 }
@@ -133,10 +172,7 @@ func getGlobbedCapnpFilePaths(config *generateCapnpConfig) ([]string, error) {
 }
 
 func writeGoCapnpFileWithCGR(config *generateCapnpConfig, capnpFilepath string, codeGeneratorRequest []byte) error {
-	capnpDirectory := filepath.Dir(capnpFilepath)
-	capnpFilename := filepath.Base(capnpFilepath)
-	capnpBase := capnpFilename[:len(capnpFilename) - len(".capnp")]
-	outputDirectory := filepath.Join(capnpDirectory, capnpBase)
+	outputDirectory := capnpOutputDirectory(config, capnpFilepath)
 	err := os.MkdirAll(outputDirectory, 0755)
 	if err != nil {
 		return err
@@ -150,5 +186,12 @@ func writeGoCapnpFileWithCGR(config *generateCapnpConfig, capnpFilepath string,
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	err = cmd.Run()
-	return err
+	if err != nil {
+		return err
+	}
+	packageName := config.packages[filepath.Clean(capnpFilepath)]
+	if packageName == "" && len(config.importPaths) == 0 {
+		return nil
+	}
+	return rewriteGeneratedGo(capnpGoFilepath(config, capnpFilepath), packageName, config.importPaths)
 }
@@ -0,0 +1,104 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	buildtool "sandstorm.org/go/tempest/internal/build-tool"
+)
+
+// ErrCapnpDrift is returned by CheckCapnp when the generated Go code doesn't
+// match the schemas.
+var ErrCapnpDrift = errors.New("generated Go code is out of date with the Cap'n Proto schemas")
+
+// CheckCapnp regenerates the Go code for the schemas into a temporary
+// directory, and compares it with the generated code in the tree, without
+// changing it.  It returns ErrCapnpDrift, and says which files differ, if
+// they don't match, e.g., because a schema was edited without regenerating
+// its code, or the generated code was edited by hand.
+func CheckCapnp(buildToolConfig *buildtool.RuntimeConfigBuildTool) ([]string, error) {
+	messages := make([]string, 0, 5)
+	config, err := getGenerateCapnpConfig(buildToolConfig)
+	if err != nil {
+		messages = append(messages, "Failed to get the Generate Cap'n Proto configuration")
+		return messages, err
+	}
+	capnpFilepaths, err := getGlobbedCapnpFilePaths(config)
+	if err != nil {
+		return messages, err
+	}
+	tempDir, err := os.MkdirTemp("", "tempest-capnp-check-")
+	if err != nil {
+		return messages, err
+	}
+	defer os.RemoveAll(tempDir)
+	checkConfig := *config
+	checkConfig.outputDir = filepath.Join(tempDir, config.outputDir)
+	generateMessages, err := generateCapnpFiles(&checkConfig, capnpFilepaths)
+	messages = append(messages, generateMessages...)
+	if err != nil {
+		return messages, err
+	}
+	drifted := 0
+	for _, capnpFilepath := range capnpFilepaths {
+		generatedPath := capnpGoFilepath(config, capnpFilepath)
+		expected, err := os.ReadFile(capnpGoFilepath(&checkConfig, capnpFilepath))
+		if err != nil {
+			return messages, err
+		}
+		found, err := os.ReadFile(generatedPath)
+		if os.IsNotExist(err) {
+			messages = append(messages, fmt.Sprintf("%s is missing; it is generated from %s", generatedPath, capnpFilepath))
+			drifted++
+			continue
+		}
+		if err != nil {
+			return messages, err
+		}
+		if line := firstDifferingLine(expected, found); line != 0 {
+			messages = append(messages, fmt.Sprintf("%s differs from the code generated from %s, starting at line %d", generatedPath, capnpFilepath, line))
+			drifted++
+		}
+	}
+	if drifted > 0 {
+		messages = append(messages, fmt.Sprintf("%d of %d generated files are out of date; run build-tool generate capnp to update them", drifted, len(capnpFilepaths)))
+		return messages, ErrCapnpDrift
+	}
+	messages = append(messages, fmt.Sprintf("The generated code for all %d schemas is up to date", len(capnpFilepaths)))
+	return messages, nil
+}
+
+// firstDifferingLine returns the 1-based number of the first line which
+// differs between a and b, or 0 if they are the same.
+func firstDifferingLine(a []byte, b []byte) int {
+	if bytes.Equal(a, b) {
+		return 0
+	}
+	aLines := bytes.SplitAfter(a, []byte("\n"))
+	bLines := bytes.SplitAfter(b, []byte("\n"))
+	for i := 0; i < min(len(aLines), len(bLines)); i++ {
+		if !bytes.Equal(aLines[i], bLines[i]) {
+			return i + 1
+		}
+	}
+	return min(len(aLines), len(bLines)) + 1
+}
@@ -0,0 +1,68 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"encoding/json"
+	"os"
+
+	buildtool "sandstorm.org/go/tempest/internal/build-tool"
+	"sandstorm.org/go/tempest/internal/config/configgen"
+)
+
+const configJsonPath = "config.json"
+
+// configJson holds the settings written to config.json by ./configure that
+// the generated files use.
+type configJson struct {
+	Prefix        string
+	Libexecdir    string
+	Localstatedir string
+}
+
+// GenerateConfig writes the Go and C files holding the installation paths
+// chosen by ./configure, as internal/make does.
+func GenerateConfig(buildToolConfig *buildtool.RuntimeConfigBuildTool) ([]string, error) {
+	messages := make([]string, 0, 2)
+	data, err := os.ReadFile(configJsonPath)
+	if os.IsNotExist(err) {
+		messages = append(messages, "config.json does not exist; run ./configure first")
+		return messages, err
+	}
+	if err != nil {
+		return messages, err
+	}
+	var config configJson
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		messages = append(messages, "Failed to parse config.json")
+		return messages, err
+	}
+	paths := configgen.Paths{
+		Prefix:        config.Prefix,
+		Libexecdir:    config.Libexecdir,
+		Localstatedir: config.Localstatedir,
+	}
+	for _, file := range paths.Files() {
+		err = os.WriteFile(file.Path, []byte(file.Content), 0600)
+		if err != nil {
+			return messages, err
+		}
+		messages = append(messages, "Wrote "+file.Path)
+	}
+	return messages, nil
+}
@@ -0,0 +1,131 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/BurntSushi/toml"
+)
+
+// A generateRecord is what a generator's inputs were when it last ran, kept
+// in the incremental directory so that unchanged outputs aren't regenerated.
+type generateRecord struct {
+	// Inputs maps each input file, or "setting:<name>" for other inputs
+	// such as the path of a tool, to the SHA-256 of its contents.
+	Inputs map[string]string
+}
+
+func generateRecordPath(incrementalDir string, generatorName string) string {
+	return filepath.Join(incrementalDir, "generate-"+generatorName+".toml")
+}
+
+func readGenerateRecord(incrementalDir string, generatorName string) (*generateRecord, error) {
+	record := new(generateRecord)
+	_, err := toml.DecodeFile(generateRecordPath(incrementalDir, generatorName), record)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return record, err
+}
+
+func writeGenerateRecord(incrementalDir string, generatorName string, record *generateRecord) error {
+	err := os.MkdirAll(incrementalDir, 0755)
+	if err != nil {
+		return err
+	}
+	fp, err := os.Create(generateRecordPath(incrementalDir, generatorName))
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+	return toml.NewEncoder(fp).Encode(record)
+}
+
+// currentGenerateRecord hashes the given input files and settings.
+func currentGenerateRecord(inputFiles []string, settings map[string]string) (*generateRecord, error) {
+	record := &generateRecord{Inputs: make(map[string]string)}
+	for _, inputFile := range inputFiles {
+		hash, err := hashFile(inputFile)
+		if err != nil {
+			return nil, err
+		}
+		record.Inputs[inputFile] = hash
+	}
+	for name, value := range settings {
+		hash := sha256.Sum256([]byte(value))
+		record.Inputs["setting:"+name] = hex.EncodeToString(hash[:])
+	}
+	return record, nil
+}
+
+// staleReasons explains why a generator needs to run, or returns nothing if
+// its outputs are up to date.
+func staleReasons(previous *generateRecord, current *generateRecord, outputFiles []string) []string {
+	if previous == nil {
+		return []string{"no record of a previous run"}
+	}
+	reasons := make([]string, 0)
+	for _, outputFile := range outputFiles {
+		if _, err := os.Stat(outputFile); err != nil {
+			reasons = append(reasons, outputFile+" is missing")
+		}
+	}
+	names := make([]string, 0, len(current.Inputs))
+	for name := range current.Inputs {
+		names = append(names, name)
+	}
+	for name := range previous.Inputs {
+		if _, ok := current.Inputs[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		previousHash, wasInput := previous.Inputs[name]
+		currentHash, isInput := current.Inputs[name]
+		switch {
+		case !wasInput:
+			reasons = append(reasons, name+" was added")
+		case !isInput:
+			reasons = append(reasons, name+" was removed")
+		case previousHash != currentHash:
+			reasons = append(reasons, name+" changed")
+		}
+	}
+	return reasons
+}
+
+func hashFile(filePath string) (string, error) {
+	fp, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("reading generator input: %w", err)
+	}
+	defer fp.Close()
+	hash := sha256.New()
+	_, err = io.Copy(hash, fp)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,290 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	buildtool "sandstorm.org/go/tempest/internal/build-tool"
+)
+
+const (
+	capnpGoModule = "capnproto.org/go/capnp/v3"
+	licensePath   = "license.txt"
+	tempestGoMod  = "go.mod"
+)
+
+var moduleVersionPattern = regexp.MustCompile(`^v[0-9]+\.[0-9]+\.[0-9]+(-[0-9A-Za-z.-]+)?$`)
+
+// goModInfo is what publish-capnp-module needs from Tempest's go.mod.
+type goModInfo struct {
+	module    string
+	goVersion string
+	requires  map[string]string
+}
+
+// PublishCapnpModule regenerates the Go code for the schemas in
+// [build-tool.publish-capnp] SourceDir, and assembles it into a standalone
+// module in OutputDir, along with the schemas, a go.mod and the license.
+// Import paths, including the schemas' $Go.import annotations, are rewritten
+// from Tempest's to ModulePath.
+//
+// If version is set, OutputDir is also committed to a git repository and
+// tagged with version, ready to push.  Anything in OutputDir other than .git
+// is replaced.
+func PublishCapnpModule(buildToolConfig *buildtool.RuntimeConfigBuildTool, version string) ([]string, error) {
+	messages := make([]string, 0, 10)
+	if buildToolConfig.PublishCapnp == nil {
+		return messages, fmt.Errorf("buildToolConfig.PublishCapnp is nil")
+	}
+	publishConfig := buildToolConfig.PublishCapnp
+	if publishConfig.ModulePath == "" {
+		return messages, fmt.Errorf("[build-tool.publish-capnp] ModulePath is not set in config.toml")
+	}
+	if version != "" && !moduleVersionPattern.MatchString(version) {
+		return messages, fmt.Errorf("Version %q is not a module version, e.g., v1.2.3", version)
+	}
+	goMod, err := readGoModInfo(tempestGoMod)
+	if err != nil {
+		return messages, err
+	}
+	capnpVersion, ok := goMod.requires[capnpGoModule]
+	if !ok {
+		return messages, fmt.Errorf("%s does not require %s", tempestGoMod, capnpGoModule)
+	}
+	sourceImportPath := goMod.module + "/" + filepath.ToSlash(filepath.Clean(publishConfig.SourceDir))
+
+	// Regenerate, so that what is published matches the schemas.
+	capnpConfig, err := getGenerateCapnpConfig(buildToolConfig)
+	if err != nil {
+		messages = append(messages, "Failed to get the Generate Cap'n Proto configuration")
+		return messages, err
+	}
+	capnpFilepaths, err := filepath.Glob(filepath.Join(publishConfig.SourceDir, "*.capnp"))
+	if err != nil {
+		return messages, err
+	}
+	if len(capnpFilepaths) == 0 {
+		return messages, fmt.Errorf("No .capnp files in %s", publishConfig.SourceDir)
+	}
+	generateMessages, err := generateCapnpFiles(capnpConfig, capnpFilepaths)
+	messages = append(messages, generateMessages...)
+	if err != nil {
+		return messages, err
+	}
+
+	outputDir := publishConfig.OutputDir
+	err = clearModuleDir(outputDir)
+	if err != nil {
+		return messages, err
+	}
+	rewrite := func(content []byte) []byte {
+		return bytes.ReplaceAll(content,
+			[]byte(`"`+sourceImportPath+"/"),
+			[]byte(`"`+publishConfig.ModulePath+"/"))
+	}
+	for _, capnpFilepath := range capnpFilepaths {
+		capnpFilename := filepath.Base(capnpFilepath)
+		capnpBase := strings.TrimSuffix(capnpFilename, ".capnp")
+		err = copyRewritten(capnpFilepath, filepath.Join(outputDir, capnpFilename), rewrite, goMod.module)
+		if err != nil {
+			return messages, err
+		}
+		goFilepaths, err := filepath.Glob(filepath.Join(publishConfig.SourceDir, capnpBase, "*.go"))
+		if err != nil {
+			return messages, err
+		}
+		for _, goFilepath := range goFilepaths {
+			err = copyRewritten(goFilepath, filepath.Join(outputDir, capnpBase, filepath.Base(goFilepath)), rewrite, goMod.module)
+			if err != nil {
+				return messages, err
+			}
+		}
+	}
+	goModContent := fmt.Sprintf("module %s\n\ngo %s\n\nrequire %s %s\n",
+		publishConfig.ModulePath, goMod.goVersion, capnpGoModule, capnpVersion)
+	err = os.WriteFile(filepath.Join(outputDir, "go.mod"), []byte(goModContent), 0644)
+	if err != nil {
+		return messages, err
+	}
+	license, err := os.ReadFile(licensePath)
+	if err != nil {
+		return messages, err
+	}
+	err = os.WriteFile(filepath.Join(outputDir, "LICENSE"), license, 0644)
+	if err != nil {
+		return messages, err
+	}
+	revision := tempestRevision()
+	readme := fmt.Sprintf("# %s\n\n"+
+		"Go code generated from the Cap'n Proto schemas of Tempest, for use by\n"+
+		"programs which talk to it.  Do not edit; this module is generated from\n"+
+		"Tempest's %s directory (revision %s) by `build-tool publish-capnp-module`.\n",
+		publishConfig.ModulePath, filepath.ToSlash(publishConfig.SourceDir), revision)
+	err = os.WriteFile(filepath.Join(outputDir, "README.md"), []byte(readme), 0644)
+	if err != nil {
+		return messages, err
+	}
+	messages = append(messages, fmt.Sprintf("Assembled %s in %s from %d schemas", publishConfig.ModulePath, outputDir, len(capnpFilepaths)))
+
+	if version == "" {
+		return messages, nil
+	}
+	tagMessages, err := commitAndTagModule(outputDir, version, revision)
+	messages = append(messages, tagMessages...)
+	return messages, err
+}
+
+// readGoModInfo reads the module path, go version and requirements from a
+// go.mod file.
+func readGoModInfo(path string) (*goModInfo, error) {
+	fp, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+	result := &goModInfo{requires: make(map[string]string)}
+	inRequireBlock := false
+	scanner := bufio.NewScanner(fp)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "//")
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case inRequireBlock && fields[0] == ")":
+			inRequireBlock = false
+		case inRequireBlock && len(fields) >= 2:
+			result.requires[fields[0]] = fields[1]
+		case fields[0] == "module" && len(fields) == 2:
+			result.module = fields[1]
+		case fields[0] == "go" && len(fields) == 2:
+			result.goVersion = fields[1]
+		case fields[0] == "require" && len(fields) == 2 && fields[1] == "(":
+			inRequireBlock = true
+		case fields[0] == "require" && len(fields) >= 3:
+			result.requires[fields[1]] = fields[2]
+		}
+	}
+	err = scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+	if result.module == "" || result.goVersion == "" {
+		return nil, fmt.Errorf("%s has no module or go directive", path)
+	}
+	return result, nil
+}
+
+// clearModuleDir creates dir, or removes everything in it except the .git
+// directory, so that files from removed schemas don't linger.
+func clearModuleDir(dir string) error {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Name() == ".git" {
+			continue
+		}
+		err = os.RemoveAll(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyRewritten copies source to destination with rewrite applied, and
+// fails if the result still refers to Tempest's module, which the published
+// module can't import.
+func copyRewritten(source string, destination string, rewrite func([]byte) []byte, tempestModule string) error {
+	content, err := os.ReadFile(source)
+	if err != nil {
+		return err
+	}
+	content = rewrite(content)
+	if bytes.Contains(content, []byte(`"`+tempestModule+"/")) {
+		return fmt.Errorf("%s refers to %s outside of the published directory", source, tempestModule)
+	}
+	err = os.MkdirAll(filepath.Dir(destination), 0755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(destination, content, 0644)
+}
+
+// tempestRevision describes the checked out revision of Tempest, or returns
+// "unknown" outside of a git repository.
+func tempestRevision() string {
+	output, err := exec.Command("git", "describe", "--always", "--dirty").Output()
+	if err != nil {
+		return "unknown"
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// commitAndTagModule commits everything in dir, creating the repository if
+// needed, and tags the commit with version.
+func commitAndTagModule(dir string, version string, revision string) ([]string, error) {
+	messages := make([]string, 0, 2)
+	git := func(args ...string) ([]byte, error) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Stderr = os.Stderr
+		return cmd.Output()
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); os.IsNotExist(err) {
+		_, err = git("init", "--quiet")
+		if err != nil {
+			messages = append(messages, "Failed to create a git repository in "+dir)
+			return messages, err
+		}
+	}
+	_, err := git("add", "--all")
+	if err != nil {
+		return messages, err
+	}
+	status, err := git("status", "--porcelain")
+	if err != nil {
+		return messages, err
+	}
+	if len(status) > 0 {
+		_, err = git("commit", "--quiet", "--message", "Generate from Tempest "+revision)
+		if err != nil {
+			messages = append(messages, "Failed to commit in "+dir)
+			return messages, err
+		}
+	}
+	_, err = git("tag", "--annotate", "--message", version, version)
+	if err != nil {
+		messages = append(messages, fmt.Sprintf("Failed to tag %s; does the tag already exist?", version))
+		return messages, err
+	}
+	messages = append(messages, fmt.Sprintf("Tagged %s in %s; push it with git push --follow-tags", version, dir))
+	return messages, nil
+}
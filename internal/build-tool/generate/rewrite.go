@@ -0,0 +1,104 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// capnpOutputDirectory returns the directory the Go code for a schema is
+// generated into: a directory named after the schema, next to it or in the
+// same place under the configured OutputDir.
+func capnpOutputDirectory(config *generateCapnpConfig, capnpFilepath string) string {
+	capnpBase := strings.TrimSuffix(filepath.Base(capnpFilepath), ".capnp")
+	return filepath.Join(config.outputDir, filepath.Dir(capnpFilepath), capnpBase)
+}
+
+// capnpGoFilepath returns the path of the Go file generated from a schema.
+func capnpGoFilepath(config *generateCapnpConfig, capnpFilepath string) string {
+	return filepath.Join(capnpOutputDirectory(config, capnpFilepath), filepath.Base(capnpFilepath)+".go")
+}
+
+// rewriteImportPath applies the longest of the rewrites which matches path,
+// either exactly or as a prefix ending at a slash.
+func rewriteImportPath(path string, rewrites map[string]string) (string, bool) {
+	bestFrom := ""
+	for from := range rewrites {
+		if len(from) > len(bestFrom) &&
+			(path == from || strings.HasPrefix(path, from+"/")) {
+			bestFrom = from
+		}
+	}
+	if bestFrom == "" {
+		return path, false
+	}
+	return rewrites[bestFrom] + path[len(bestFrom):], true
+}
+
+// rewriteGeneratedGo overrides the package clause of a Go file generated by
+// capnpc-go, unless packageName is empty, and rewrites its imports.  The
+// generated code always names its imports, so renaming a package doesn't
+// break the files which import it.
+func rewriteGeneratedGo(goFilepath string, packageName string, importPaths map[string]string) error {
+	fileSet := token.NewFileSet()
+	file, err := parser.ParseFile(fileSet, goFilepath, nil, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	if packageName != "" {
+		file.Name.Name = packageName
+	}
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", goFilepath, err)
+		}
+		if rewritten, ok := rewriteImportPath(path, importPaths); ok {
+			spec.Path.Value = strconv.Quote(rewritten)
+		}
+	}
+	var buffer bytes.Buffer
+	err = format.Node(&buffer, fileSet, file)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(goFilepath, buffer.Bytes(), 0644)
+}
+
+// formatSettingsMap formats a map for generatorPlan.settings, in a stable
+// order, so that changing any of it regenerates the outputs.
+func formatSettingsMap(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+m[key])
+	}
+	return strings.Join(pairs, ",")
+}
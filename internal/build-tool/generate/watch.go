@@ -0,0 +1,200 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"bufio"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	buildtool "sandstorm.org/go/tempest/internal/build-tool"
+)
+
+// Editors often save a file with several writes, or by writing a temporary
+// file and renaming it.  Wait this long after the last event for a file
+// before regenerating it.
+const watchSettleTime = 200 * time.Millisecond
+
+// WatchCapnp watches the configured CapnpDirs and regenerates the Go code for
+// each .capnp file whenever it changes.  It only returns if the watch cannot
+// be set up or fails.
+//
+// The changed file is regenerated together with the files which import it,
+// directly or indirectly, since their generated code refers to its Go types
+// and package by name.  Other files are left alone.  Compile errors are
+// printed and the watch continues.
+func WatchCapnp(buildToolConfig *buildtool.RuntimeConfigBuildTool) ([]string, error) {
+	messages := make([]string, 0, 5)
+	config, err := getGenerateCapnpConfig(buildToolConfig)
+	if err != nil {
+		messages = append(messages, "Failed to get the Generate Cap'n Proto configuration")
+		return messages, err
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		messages = append(messages, "Failed to create a file system watcher")
+		return messages, err
+	}
+	defer watcher.Close()
+	for _, dir := range config.capnpDirs {
+		err = watcher.Add(dir)
+		if err != nil {
+			messages = append(messages, "Failed to watch directory "+dir)
+			return messages, err
+		}
+	}
+	log.Printf("Watching %d directories for changes to .capnp files\n", len(config.capnpDirs))
+
+	pending := make(map[string]time.Time)
+	ticker := time.NewTicker(watchSettleTime / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return messages, nil
+			}
+			if filepath.Ext(event.Name) != ".capnp" {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+				pending[event.Name] = time.Now()
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return messages, nil
+			}
+			messages = append(messages, "File system watcher failed")
+			return messages, err
+		case now := <-ticker.C:
+			changed := make([]string, 0, len(pending))
+			for capnpFilepath, changedAt := range pending {
+				if now.Sub(changedAt) < watchSettleTime {
+					continue
+				}
+				delete(pending, capnpFilepath)
+				changed = append(changed, capnpFilepath)
+			}
+			if len(changed) == 0 {
+				continue
+			}
+			affected, err := affectedCapnpFiles(config, changed)
+			if err != nil {
+				log.Printf("Failed to find the files importing %s: %v\n", strings.Join(changed, ", "), err)
+				affected = changed
+			}
+			for _, capnpFilepath := range affected {
+				regenerateCapnpFile(config, capnpFilepath)
+			}
+		}
+	}
+}
+
+// regenerateCapnpFile regenerates the Go code for a single .capnp file,
+// logging the result.  Compile errors from capnp are written to stderr.
+func regenerateCapnpFile(config *generateCapnpConfig, capnpFilepath string) {
+	if _, err := os.Stat(capnpFilepath); err != nil {
+		// Renamed away or deleted; there's nothing to generate.
+		return
+	}
+	start := time.Now()
+	cgr, err := codeGeneratorRequestWithCapnp(config, capnpFilepath)
+	if err != nil {
+		log.Printf("Failed to compile %s: %v\n", capnpFilepath, err)
+		return
+	}
+	err = writeGoCapnpFileWithCGR(config, capnpFilepath, cgr)
+	if err != nil {
+		log.Printf("Failed to generate Go code for %s: %v\n", capnpFilepath, err)
+		return
+	}
+	log.Printf("Regenerated %s in %v\n", capnpFilepath, time.Since(start).Round(time.Millisecond))
+}
+
+// capnpImportPattern matches the file named by an import, e.g.
+// `using Util = import "util.capnp";`.
+var capnpImportPattern = regexp.MustCompile(`\bimport\s+"([^"]+)"`)
+
+// capnpImports returns the cleaned paths of the files which capnpFilepath
+// imports, resolved as capnp does: paths starting with a slash against the
+// import paths, and others against the directory of the importing file.
+func capnpImports(config *generateCapnpConfig, capnpFilepath string) ([]string, error) {
+	file, err := os.Open(capnpFilepath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	imports := make([]string, 0, 5)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		for _, match := range capnpImportPattern.FindAllStringSubmatch(line, -1) {
+			name := match[1]
+			if !strings.HasPrefix(name, "/") {
+				imports = append(imports, filepath.Join(filepath.Dir(capnpFilepath), name))
+				continue
+			}
+			for _, dir := range []string{config.stdDir, "capnp"} {
+				imports = append(imports, filepath.Join(dir, name))
+			}
+		}
+	}
+	return imports, scanner.Err()
+}
+
+// affectedCapnpFiles returns the changed files together with the files in
+// CapnpDirs which import them, directly or indirectly, sorted.  The import
+// graph is read afresh each time, since the changes may have altered it.
+func affectedCapnpFiles(config *generateCapnpConfig, changed []string) ([]string, error) {
+	capnpFilepaths, err := getGlobbedCapnpFilePaths(config)
+	if err != nil {
+		return nil, err
+	}
+	importers := make(map[string][]string)
+	for _, capnpFilepath := range capnpFilepaths {
+		imports, err := capnpImports(config, capnpFilepath)
+		if err != nil {
+			return nil, err
+		}
+		for _, imported := range imports {
+			importers[imported] = append(importers[imported], capnpFilepath)
+		}
+	}
+	affected := make(map[string]bool)
+	queue := append([]string(nil), changed...)
+	for len(queue) > 0 {
+		capnpFilepath := filepath.Clean(queue[0])
+		queue = queue[1:]
+		if affected[capnpFilepath] {
+			continue
+		}
+		affected[capnpFilepath] = true
+		queue = append(queue, importers[capnpFilepath]...)
+	}
+	result := make([]string, 0, len(affected))
+	for capnpFilepath := range affected {
+		result = append(result, capnpFilepath)
+	}
+	sort.Strings(result)
+	return result, nil
+}
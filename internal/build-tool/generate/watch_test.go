@@ -0,0 +1,52 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAffectedCapnpFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"util.capnp":    "using Go = import \"/go.capnp\";\n",
+		"grain.capnp":   "using Util = import \"util.capnp\"; # not import \"other.capnp\"\n",
+		"session.capnp": "using Grain = import \"/grain.capnp\";\n",
+		"other.capnp":   "struct Other {}\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	config := &generateCapnpConfig{capnpDirs: []string{dir}, stdDir: dir}
+
+	affected, err := affectedCapnpFiles(config, []string{filepath.Join(dir, "util.capnp")})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "grain.capnp"),
+		filepath.Join(dir, "session.capnp"),
+		filepath.Join(dir, "util.capnp"),
+	}, affected)
+
+	affected, err = affectedCapnpFiles(config, []string{filepath.Join(dir, "other.capnp")})
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "other.capnp")}, affected)
+}
@@ -0,0 +1,400 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	buildtool "sandstorm.org/go/tempest/internal/build-tool"
+)
+
+// webAssetManifestName is written to the web UI's output directory, and
+// lists the SHA-256 of each static asset, in sha256sum format, so that
+// deployments and caches can tell which assets changed.
+const webAssetManifestName = "assets.sha256"
+
+// The web UI's bundle, JavaScript and CSS are also written to hashedAssetsDir
+// with the start of their SHA-256 in their names, so that the server can let
+// browsers cache them for good, and precompressed next to that with gzip and,
+// if it is available, Brotli.  hashedAssetManifestName, in hashedAssetsDir,
+// maps the assets' usual names to their hashed ones.
+const (
+	hashedAssetsDir         = "assets"
+	hashedAssetManifestName = "manifest.json"
+)
+
+// hashedAssetExtensions are the extensions of the assets in the output
+// directory which are written to hashedAssetsDir.
+var hashedAssetExtensions = []string{".css", ".js", ".wasm"}
+
+type generateWebConfig struct {
+	brotliExecutable  string // empty if Brotli isn't available
+	buildDir          string
+	compiler          string
+	goExecutable      string
+	outputDir         string
+	packagePath       string
+	tinyGoExecutable  string
+	wasmExecJs        string // the compiler's copy, which matches its output
+	wasmOptExecutable string
+}
+
+// GenerateWeb compiles the web UI to WebAssembly, copies it and the matching
+// wasm_exec.js next to the other static assets, writes the asset manifest,
+// and writes the hashed and precompressed assets.
+func GenerateWeb(buildToolConfig *buildtool.RuntimeConfigBuildTool) ([]string, error) {
+	messages := make([]string, 0, 2)
+	config, err := getGenerateWebConfig(buildToolConfig)
+	if err != nil {
+		messages = append(messages, "Failed to get the Generate web configuration")
+		return messages, err
+	}
+	return generateWeb(config)
+}
+
+func generateWeb(config *generateWebConfig) ([]string, error) {
+	messages := make([]string, 0, 4)
+	err := os.MkdirAll(config.buildDir, 0755)
+	if err != nil {
+		return messages, err
+	}
+	buildPath := filepath.Join(config.buildDir, "webui.wasm")
+	var cmd *exec.Cmd
+	if config.compiler == buildtool.WebCompilerTinyGo {
+		cmd = exec.Command(config.tinyGoExecutable, "build",
+			"-target", "wasm",
+			"-panic", "trap",
+			"-no-debug",
+			"-o="+buildPath,
+			config.packagePath,
+		)
+		cmd.Env = append(os.Environ(), "WASMOPT="+config.wasmOptExecutable)
+	} else {
+		cmd = exec.Command(config.goExecutable, "build", "-o", buildPath, config.packagePath)
+		cmd.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm")
+	}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	if err != nil {
+		messages = append(messages, fmt.Sprintf("Failed to compile %s with %s", config.packagePath, config.compiler))
+		return messages, err
+	}
+	err = copyFile(buildPath, filepath.Join(config.outputDir, "webui.wasm"))
+	if err != nil {
+		return messages, err
+	}
+	err = copyFile(config.wasmExecJs, filepath.Join(config.outputDir, "wasm_exec.js"))
+	if err != nil {
+		return messages, err
+	}
+	info, err := os.Stat(buildPath)
+	if err != nil {
+		return messages, err
+	}
+	messages = append(messages, fmt.Sprintf("Built webui.wasm, %d bytes, with %s", info.Size(), config.compiler))
+	err = writeWebAssetManifest(config.outputDir)
+	if err != nil {
+		messages = append(messages, "Failed to write "+webAssetManifestName)
+		return messages, err
+	}
+	err = writeHashedWebAssets(config)
+	if err != nil {
+		messages = append(messages, "Failed to write the hashed assets")
+		return messages, err
+	}
+	if config.brotliExecutable == "" {
+		messages = append(messages, "Unable to find brotli; the assets are only precompressed with gzip")
+	}
+	return messages, nil
+}
+
+func getGenerateWebConfig(buildToolConfig *buildtool.RuntimeConfigBuildTool) (*generateWebConfig, error) {
+	if buildToolConfig.Directories == nil {
+		return nil, fmt.Errorf("buildToolConfig.Directories is nil")
+	}
+	if buildToolConfig.Generate == nil || buildToolConfig.Generate.Web == nil {
+		return nil, fmt.Errorf("buildToolConfig.Generate.Web is nil")
+	}
+	web := buildToolConfig.Generate.Web
+	result := new(generateWebConfig)
+	result.brotliExecutable = web.BrotliExecutable
+	result.buildDir = buildToolConfig.Directories.BuildDir
+	result.compiler = web.Compiler
+	result.goExecutable = web.GoExecutable
+	result.outputDir = web.OutputDir
+	result.packagePath = web.Package
+	var err error
+	if web.Compiler == buildtool.WebCompilerTinyGo {
+		if buildToolConfig.TinyGo == nil || buildToolConfig.Binaryen == nil {
+			return nil, fmt.Errorf("buildToolConfig.TinyGo or buildToolConfig.Binaryen is nil")
+		}
+		// TinyGo executable
+		if buildToolConfig.TinyGo.Executable != "" {
+			result.tinyGoExecutable = buildToolConfig.TinyGo.Executable
+		} else if buildToolConfig.TinyGo.ToolChainExecutable != "" {
+			result.tinyGoExecutable = buildToolConfig.TinyGo.ToolChainExecutable
+		} else {
+			return nil, fmt.Errorf("Unable to find TinyGo executable")
+		}
+		// wasm-opt executable
+		if buildToolConfig.Binaryen.Executable != "" {
+			result.wasmOptExecutable = buildToolConfig.Binaryen.Executable
+		} else if buildToolConfig.Binaryen.ToolChainExecutable != "" {
+			result.wasmOptExecutable = buildToolConfig.Binaryen.ToolChainExecutable
+		} else {
+			return nil, fmt.Errorf("Unable to find wasm-opt executable")
+		}
+		result.wasmExecJs, err = findTinyGoWasmExecJs(result.tinyGoExecutable)
+	} else {
+		result.wasmExecJs, err = findGoWasmExecJs(result.goExecutable)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// findTinyGoWasmExecJs finds the wasm_exec.js which comes with TinyGo.  It
+// is in targets/ of a TinyGo release, and in <prefix>/lib/tinygo/targets, or
+// similar, of a system installation.
+func findTinyGoWasmExecJs(tinyGoExecutable string) (string, error) {
+	if !filepath.IsAbs(tinyGoExecutable) {
+		path, err := exec.LookPath(tinyGoExecutable)
+		if err != nil {
+			return "", err
+		}
+		tinyGoExecutable = path
+	}
+	prefix := filepath.Dir(filepath.Dir(tinyGoExecutable))
+	candidates := []string{
+		filepath.Join(prefix, "targets", "wasm_exec.js"),
+	}
+	for _, libDir := range []string{"lib", "lib32", "lib64", "share"} {
+		candidates = append(candidates, filepath.Join(prefix, libDir, "tinygo", "targets", "wasm_exec.js"))
+	}
+	return firstExistingFile(candidates, "TinyGo's wasm_exec.js")
+}
+
+// findGoWasmExecJs finds the wasm_exec.js which comes with Go.  Go 1.24 moved
+// it from misc/wasm to lib/wasm.
+func findGoWasmExecJs(goExecutable string) (string, error) {
+	output, err := exec.Command(goExecutable, "env", "GOROOT").Output()
+	if err != nil {
+		return "", fmt.Errorf("Unable to find GOROOT: %w", err)
+	}
+	goRoot := strings.TrimSpace(string(output))
+	candidates := []string{
+		filepath.Join(goRoot, "lib", "wasm", "wasm_exec.js"),
+		filepath.Join(goRoot, "misc", "wasm", "wasm_exec.js"),
+	}
+	return firstExistingFile(candidates, "Go's wasm_exec.js")
+}
+
+func firstExistingFile(candidates []string, description string) (string, error) {
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("Unable to find %s in %s", description, strings.Join(candidates, ", "))
+}
+
+// webGoFiles lists the main module's source files which the web UI is built
+// from, so that changes to them rebuild it.  Changes to other modules show up
+// in go.mod and go.sum.
+func webGoFiles(config *generateWebConfig) ([]string, error) {
+	args := []string{"list", "-deps", "-f", `{{if and .Module .Module.Main}}{{$dir := .Dir}}{{range .GoFiles}}{{$dir}}/{{.}}
+{{end}}{{range .EmbedFiles}}{{$dir}}/{{.}}
+{{end}}{{end}}`}
+	if config.compiler == buildtool.WebCompilerTinyGo {
+		args = append(args, "-tags", "tinygo")
+	}
+	args = append(args, config.packagePath)
+	cmd := exec.Command(config.goExecutable, args...)
+	cmd.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm")
+	cmd.Stderr = os.Stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to list the packages in %s: %w", config.packagePath, err)
+	}
+	workingDir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	result := []string{"go.mod", "go.sum"}
+	for _, line := range strings.Split(string(output), "\n") {
+		if line == "" {
+			continue
+		}
+		relativePath, err := filepath.Rel(workingDir, line)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, relativePath)
+	}
+	slices.Sort(result[2:])
+	return result, nil
+}
+
+// webAssets lists the files in the web UI's output directory which are
+// served as they are, i.e., everything but the Go code which embeds them,
+// and the files this generator writes.
+func webAssets(outputDir string) ([]string, error) {
+	generated := []string{"webui.wasm", "wasm_exec.js", webAssetManifestName}
+	hashedDir := filepath.Join(outputDir, hashedAssetsDir)
+	result := make([]string, 0)
+	err := filepath.WalkDir(outputDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path == hashedDir {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		name := entry.Name()
+		if filepath.Ext(name) == ".go" || strings.HasPrefix(name, ".") {
+			return nil
+		}
+		if filepath.Dir(path) == filepath.Clean(outputDir) && slices.Contains(generated, name) {
+			return nil
+		}
+		result = append(result, path)
+		return nil
+	})
+	return result, err
+}
+
+// writeWebAssetManifest hashes the static assets, including the compiled web
+// UI, into the output directory's asset manifest.
+func writeWebAssetManifest(outputDir string) error {
+	assets, err := webAssets(outputDir)
+	if err != nil {
+		return err
+	}
+	assets = append(assets, filepath.Join(outputDir, "wasm_exec.js"), filepath.Join(outputDir, "webui.wasm"))
+	slices.Sort(assets)
+	var manifest bytes.Buffer
+	for _, asset := range assets {
+		content, err := os.ReadFile(asset)
+		if err != nil {
+			return err
+		}
+		hash := sha256.Sum256(content)
+		relativePath, err := filepath.Rel(outputDir, asset)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&manifest, "%s  %s\n", hex.EncodeToString(hash[:]), filepath.ToSlash(relativePath))
+	}
+	return os.WriteFile(filepath.Join(outputDir, webAssetManifestName), manifest.Bytes(), 0644)
+}
+
+// writeHashedWebAssets replaces hashedAssetsDir with copies of the assets
+// with hashedAssetExtensions at the top of the output directory, named
+// <name>.<hash><extension>, their precompressed versions, and the manifest of
+// their names.
+func writeHashedWebAssets(config *generateWebConfig) error {
+	hashedDir := filepath.Join(config.outputDir, hashedAssetsDir)
+	err := os.RemoveAll(hashedDir)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(hashedDir, 0755)
+	if err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(config.outputDir)
+	if err != nil {
+		return err
+	}
+	manifest := make(map[string]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		extension := filepath.Ext(name)
+		if entry.IsDir() || !slices.Contains(hashedAssetExtensions, extension) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(config.outputDir, name))
+		if err != nil {
+			return err
+		}
+		hash := sha256.Sum256(content)
+		hashedName := strings.TrimSuffix(name, extension) + "." + hex.EncodeToString(hash[:8]) + extension
+		hashedPath := filepath.Join(hashedDir, hashedName)
+		err = os.WriteFile(hashedPath, content, 0644)
+		if err != nil {
+			return err
+		}
+		err = writeGzipFile(hashedPath+".gz", content)
+		if err != nil {
+			return err
+		}
+		if config.brotliExecutable != "" {
+			cmd := exec.Command(config.brotliExecutable, "--best", "--force", "--output="+hashedPath+".br", hashedPath)
+			cmd.Stderr = os.Stderr
+			err = cmd.Run()
+			if err != nil {
+				return fmt.Errorf("Unable to compress %s with Brotli: %w", hashedName, err)
+			}
+		}
+		manifest[name] = hashedName
+	}
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(hashedDir, hashedAssetManifestName), append(content, '\n'), 0644)
+}
+
+func writeGzipFile(path string, content []byte) error {
+	var compressed bytes.Buffer
+	writer, err := gzip.NewWriterLevel(&compressed, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(content)
+	if err != nil {
+		return err
+	}
+	err = writer.Close()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, compressed.Bytes(), 0644)
+}
+
+func copyFile(source string, destination string) error {
+	content, err := os.ReadFile(source)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(destination, content, 0644)
+}
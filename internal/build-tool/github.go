@@ -0,0 +1,181 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildtool
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+	defaultGitHubApiUrl   = "https://api.github.com"
+	defaultGitHubTokenEnv = "GITHUB_TOKEN"
+)
+
+// runtimeConfigGitHub holds what is needed to make authenticated requests to
+// GitHub, which rate-limits anonymous downloads.  The token is never read
+// from config.toml, only from the environment variable it names.
+type runtimeConfigGitHub struct {
+	apiUrl string
+	token  string
+}
+
+func newGitHubRuntimeConfig(configFile *ConfigTomlGitHub) (*runtimeConfigGitHub, error) {
+	github := &runtimeConfigGitHub{apiUrl: defaultGitHubApiUrl}
+	if configFile.ApiUrl != "" {
+		apiUrl, err := url.Parse(configFile.ApiUrl)
+		if err != nil || (apiUrl.Scheme != "https" && apiUrl.Scheme != "http") || apiUrl.Host == "" {
+			return nil, fmt.Errorf("[build-tool.github].ApiUrl %q is not an http or https URL", configFile.ApiUrl)
+		}
+		github.apiUrl = strings.TrimSuffix(configFile.ApiUrl, "/")
+	}
+	tokenEnv := configFile.TokenEnv
+	if tokenEnv == "" {
+		tokenEnv = defaultGitHubTokenEnv
+	}
+	github.token = os.Getenv(tokenEnv)
+	return github, nil
+}
+
+// authorize adds the token to requests to GitHub, and to nothing else, so
+// that it isn't sent to mirrors configured in config.toml.  The HTTP client
+// drops the header if GitHub redirects to another domain.  It does nothing
+// if github is nil or there is no token.
+func (github *runtimeConfigGitHub) authorize(request *http.Request) {
+	if github == nil || github.token == "" {
+		return
+	}
+	apiUrl, err := url.Parse(github.apiUrl)
+	if err != nil {
+		return
+	}
+	host := request.URL.Hostname()
+	if host != "github.com" && host != apiUrl.Hostname() {
+		return
+	}
+	request.Header.Set("Authorization", "Bearer "+github.token)
+	if host == apiUrl.Hostname() && strings.Contains(request.URL.Path, "/releases/assets/") {
+		// Without this, the API returns the asset's metadata rather
+		// than its contents.
+		request.Header.Set("Accept", "application/octet-stream")
+	}
+}
+
+type githubRelease struct {
+	TagName string        `json:"tag_name"`
+	Assets  []githubAsset `json:"assets"`
+}
+
+type githubAsset struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	// Url is the asset's API URL, from which authenticated requests
+	// download it.
+	Url                string `json:"url"`
+	BrowserDownloadUrl string `json:"browser_download_url"`
+	// Digest is "sha256:" followed by the hex SHA-256, for assets
+	// uploaded since GitHub started recording them, or empty.
+	Digest string `json:"digest"`
+}
+
+// asset returns the release's asset with the given name, or nil.
+func (release *githubRelease) asset(name string) *githubAsset {
+	for index := range release.Assets {
+		if release.Assets[index].Name == name {
+			return &release.Assets[index]
+		}
+	}
+	return nil
+}
+
+// sha256 returns the hex SHA-256 from the asset's digest, or an empty string
+// if GitHub didn't record one.
+func (asset *githubAsset) sha256() string {
+	digest, found := strings.CutPrefix(asset.Digest, "sha256:")
+	if !found {
+		return ""
+	}
+	return digest
+}
+
+// release fetches the metadata of the release of repo, e.g.,
+// "tinygo-org/tinygo", with the given tag, or of the latest release if tag
+// is empty.
+func (github *runtimeConfigGitHub) release(repo string, tag string) (*githubRelease, error) {
+	releaseUrl := github.apiUrl + "/repos/" + repo + "/releases/latest"
+	if tag != "" {
+		releaseUrl = github.apiUrl + "/repos/" + repo + "/releases/tags/" + url.PathEscape(tag)
+	}
+	request, err := http.NewRequest(http.MethodGet, releaseUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	github.authorize(request)
+	request.Header.Set("Accept", "application/vnd.github+json")
+	request.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		if response.Header.Get("X-RateLimit-Remaining") == "0" {
+			return nil, fmt.Errorf("GET %s => %s; set a token in the environment to raise GitHub's rate limit", releaseUrl, response.Status)
+		}
+		return nil, fmt.Errorf("GET %s => %s", releaseUrl, response.Status)
+	}
+	release := new(githubRelease)
+	err = json.NewDecoder(response.Body).Decode(release)
+	if err != nil {
+		return nil, fmt.Errorf("GET %s: %w", releaseUrl, err)
+	}
+	return release, nil
+}
+
+// releaseAssetUrl returns the API URL of a tool's release asset, for
+// authenticated downloads.  It returns an empty string, and a message saying
+// why, if the tool isn't released on GitHub, there is no token, or the asset
+// isn't in the release; the caller then downloads from the tool's
+// DownloadUrlTemplate.
+func (github *runtimeConfigGitHub) releaseAssetUrl(tool *runtimeConfigTool, downloadFile string) (string, []string, error) {
+	messages := make([]string, 0, 1)
+	if github == nil || github.token == "" || tool.githubRepo == "" {
+		return "", messages, nil
+	}
+	tag, err := executeToolTemplate("githubTag", tool.githubTagTemplate, toolTemplateValues{Version: tool.version})
+	if err != nil {
+		return "", messages, err
+	}
+	release, err := github.release(tool.githubRepo, tag)
+	if err != nil {
+		messages = append(messages, fmt.Sprintf("Could not list the assets of %s %s (%v); downloading without the GitHub API", tool.githubRepo, tag, err))
+		return "", messages, nil
+	}
+	asset := release.asset(downloadFile)
+	if asset == nil {
+		messages = append(messages, fmt.Sprintf("%s is not an asset of %s %s; downloading without the GitHub API", downloadFile, tool.githubRepo, tag))
+		return "", messages, nil
+	}
+	if size := tool.files[downloadFile].size; size != 0 && asset.Size != size {
+		return "", messages, fmt.Errorf("%s in %s %s is %d bytes, but downloads.toml says %d", downloadFile, tool.githubRepo, tag, asset.Size, size)
+	}
+	return asset.Url, messages, nil
+}
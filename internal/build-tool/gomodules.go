@@ -0,0 +1,196 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildtool
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// goModCacheDirName is the module cache, in the toolchain directory, used by
+// Go tools built with PrefetchModules.  Copying the toolchain directory to a
+// machine without network access lets the tools be built there too.
+const goModCacheDirName = "gomodcache"
+
+// goModCacheArchiveDir is the top-level directory of module cache archives
+// listed in downloads.toml.  Only its cache/download directory, which holds
+// the modules' zip files and go.mod files, is extracted, since the go command
+// rebuilds the rest of the module cache from it.  The archives can be made
+// with, e.g.:
+//
+//	GOMODCACHE=$PWD/gomodcache go mod download
+//	tar czf go-capnp-3.1.0-alpha.1-modcache.tar.gz gomodcache/cache/download
+const goModCacheArchiveDir = "gomodcache/cache/download/"
+
+// goModDownload is the subset of `go mod download -json` output we use.
+type goModDownload struct {
+	Path    string
+	Version string
+	Sum     string
+	Error   string
+}
+
+// prefetchGoModules downloads the dependencies of the module in moduleDir
+// into modCacheDir, and checks them against the module's go.sum.  It returns
+// the modules as "path@version hash" strings, for toolchain.toml.  If offline
+// is set, the modules must already be in modCacheDir, and are only checked.
+func prefetchGoModules(goExecutable string, moduleDir string, modCacheDir string, offline bool) ([]string, error) {
+	env := append(environWithout("GOFLAGS", "GOMODCACHE"),
+		"GOMODCACHE="+modCacheDir,
+		"GOFLAGS=-mod=mod",
+	)
+	if offline {
+		env = append(env, "GOPROXY=off")
+	}
+	var stdout bytes.Buffer
+	cmd := exec.Command(goExecutable, "mod", "download", "-json")
+	cmd.Dir = moduleDir
+	cmd.Env = env
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	// go mod download exits with an error if any module failed, but the
+	// JSON output says which, so decode it before checking err.
+	modules := make([]string, 0)
+	problems := make([]error, 0)
+	decoder := json.NewDecoder(&stdout)
+	for {
+		var download goModDownload
+		decodeErr := decoder.Decode(&download)
+		if decodeErr == io.EOF {
+			break
+		}
+		if decodeErr != nil {
+			return nil, decodeErr
+		}
+		if download.Error != "" {
+			problems = append(problems, fmt.Errorf("%s@%s: %s", download.Path, download.Version, download.Error))
+			continue
+		}
+		modules = append(modules, download.Path+"@"+download.Version+" "+download.Sum)
+	}
+	if len(problems) > 0 {
+		return nil, errors.Join(problems...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cmd = exec.Command(goExecutable, "mod", "verify")
+	cmd.Dir = moduleDir
+	cmd.Env = env
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("go mod verify failed for modules in %s: %w", modCacheDir, err)
+	}
+	slices.Sort(modules)
+	return modules, nil
+}
+
+// offlineGoEnv returns environment variables which make the go command use
+// only the modules already in modCacheDir.
+func offlineGoEnv(modCacheDir string) []string {
+	return []string{
+		"GOMODCACHE=" + modCacheDir,
+		"GOFLAGS=-mod=readonly",
+		"GOPROXY=off",
+	}
+}
+
+// environWithout returns the build-tool's environment without the given
+// variables, so that they can be set for a command without the build-tool's
+// values taking effect instead.
+func environWithout(keys ...string) []string {
+	env := make([]string, 0, len(os.Environ()))
+	for _, envLine := range os.Environ() {
+		key, _, _ := strings.Cut(envLine, "=")
+		if !slices.Contains(keys, key) {
+			env = append(env, envLine)
+		}
+	}
+	return env
+}
+
+// downloadGoModCache downloads the archive of the tool's module cache listed
+// in downloads.toml, verifies it, and extracts it into modCacheDir.  It
+// returns false if downloads.toml has no archive for the tool's version.
+func downloadGoModCache(b *toolBuild, modCacheDir string) (bool, []string, error) {
+	messages := make([]string, 0, 2)
+	modCache := b.tool.modCache
+	if modCache == nil {
+		return false, messages, nil
+	}
+	values := toolTemplateValues{Version: b.tool.version}
+	downloadFile, err := executeToolTemplate("filename", modCache.filenameTemplate, values)
+	if err != nil {
+		return false, messages, err
+	}
+	downloadFileInfo, ok := modCache.files[downloadFile]
+	if !ok {
+		return false, messages, nil
+	}
+	values.Filename = downloadFile
+	downloadUrl, err := executeToolTemplate("downloadUrl", modCache.downloadUrlTemplate, values)
+	if err != nil {
+		return false, messages, err
+	}
+	buildToolConfig := b.buildToolConfig
+	err = ensureDownloadDirExists(buildToolConfig.Directories.DownloadDir)
+	if err != nil {
+		return false, messages, err
+	}
+	downloadPath := filepath.Join(buildToolConfig.Directories.DownloadDir, downloadFile)
+	mirrorUrls, err := expandMirrorUrlTemplates(modCache.mirrorUrlTemplates, values)
+	if err != nil {
+		return false, messages, err
+	}
+	downloadMessages, err := downloadAndVerify(buildToolConfig, b.tool.Name, append([]string{downloadUrl}, mirrorUrls...), downloadPath, downloadFileInfo)
+	messages = append(messages, downloadMessages...)
+	if err != nil {
+		return false, messages, err
+	}
+	spaceMessages, err := ensureExtractSpace(modCacheDir, downloadFile, downloadFileInfo)
+	messages = append(messages, spaceMessages...)
+	if err != nil {
+		messages = append(messages, fmt.Sprintf("Not enough disk space to extract %s", downloadFile))
+		return false, messages, err
+	}
+	filter := func(filePath string) bool {
+		return strings.HasPrefix(filePath, goModCacheArchiveDir)
+	}
+	transform := func(filePath string) string {
+		return filepath.Join(modCacheDir, "cache", "download", strings.TrimPrefix(filePath, goModCacheArchiveDir))
+	}
+	stopTimer := b.startStep(stepExtract)
+	err = extractTarArchive(downloadPath, modCache.archiveFormat, filter, transform)
+	stopTimer()
+	if err != nil {
+		messages = append(messages, fmt.Sprintf("Failed to extract %s", downloadPath))
+		return false, messages, err
+	}
+	return true, messages, nil
+}
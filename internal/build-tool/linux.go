@@ -25,10 +25,11 @@ import (
 )
 
 type linuxConfig struct {
-	downloadFile     string
-	downloadUrl      string
-	expectedFileSize int64
-	expectedSha256   string
+	downloadFile string
+	// downloadUrls are the download URL followed by any mirrors.
+	downloadUrls []string
+	// downloadFileInfo is the file's size and digests from downloads.toml.
+	downloadFileInfo runtimeConfigFile
 }
 
 // text/template uses these struct fields from a separate package, so they must be in PascalCase.
@@ -42,7 +43,9 @@ type linuxFilenameTemplateValues struct {
 	Version string
 }
 
-func downloadAndVerifyLinuxTarball(buildToolConfig *RuntimeConfigBuildTool) (string, []string, error) {
+// downloadAndVerifyLinuxTarball downloads the Linux source, which tool is
+// built from, and returns its path.
+func downloadAndVerifyLinuxTarball(buildToolConfig *RuntimeConfigBuildTool, tool string) (string, []string, error) {
 	messages := make([]string, 0, 5)
 	linuxConfig, err := getLinuxConfig(buildToolConfig)
 	if err != nil {
@@ -54,27 +57,11 @@ func downloadAndVerifyLinuxTarball(buildToolConfig *RuntimeConfigBuildTool) (str
 		return "", messages, err
 	}
 	downloadPath := filepath.Join(buildToolConfig.Directories.DownloadDir, linuxConfig.downloadFile)
-	downloadPathExists, err := fileExistsAtPath(downloadPath)
+	downloadMessages, err := downloadAndVerify(buildToolConfig, tool, linuxConfig.downloadUrls, downloadPath, linuxConfig.downloadFileInfo)
+	messages = append(messages, downloadMessages...)
 	if err != nil {
 		return "", messages, err
 	}
-	if downloadPathExists {
-		messages = append(messages, fmt.Sprintf("Skipping Linux download because %s exists", downloadPath))
-	} else {
-		err := downloadUrlToDir(linuxConfig.downloadUrl, buildToolConfig.Directories.DownloadDir, downloadPath)
-		if err != nil {
-			return "", messages, err
-		}
-	}
-	err = verifyFileSize(linuxConfig.expectedFileSize, downloadPath)
-	if err != nil {
-		return "", messages, err
-	}
-	err = verifySha256(linuxConfig.expectedSha256, downloadPath)
-	if err != nil {
-		return "", messages, err
-	}
-	messages = append(messages, fmt.Sprintf("%s has the correct SHA-256", downloadPath))
 	return downloadPath, messages, err
 }
 
@@ -100,6 +87,16 @@ func filterLinuxTarXzFactory(desiredPrefixes []string) fileFilter {
 	}
 }
 
+// getLinuxMajorVersion returns the major version, e.g., "6" for "6.13.8", as
+// used in kernel.org download URLs.
+func getLinuxMajorVersion(version string) (string, error) {
+	majorVersion, _, found := strings.Cut(version, ".")
+	if !found {
+		return "", fmt.Errorf("Unable to extract major version from Linux version: %s", version)
+	}
+	return majorVersion, nil
+}
+
 /**
  * getLinuxConfig populates templates from the runtime configuration with
  * appropriate values.
@@ -127,9 +124,9 @@ func getLinuxConfig(buildToolConfig *RuntimeConfigBuildTool) (*linuxConfig, erro
 	downloadFile := filenameBuffer.String()
 
 	// Download URL
-	majorVersion, _, found := strings.Cut(buildToolConfig.linux.version, ".")
-	if !found {
-		return nil, fmt.Errorf("Unable to extract major version from Linux version: %s", buildToolConfig.linux.version)
+	majorVersion, err := getLinuxMajorVersion(buildToolConfig.linux.version)
+	if err != nil {
+		return nil, err
 	}
 	downloadUrlValues := linuxDownloadUrlTemplateValues{
 		downloadFile,
@@ -145,19 +142,19 @@ func getLinuxConfig(buildToolConfig *RuntimeConfigBuildTool) (*linuxConfig, erro
 		return nil, err
 	}
 	downloadUrl := downloadUrlBuffer.String()
+	mirrorUrls, err := expandMirrorUrlTemplates(buildToolConfig.linux.mirrorUrlTemplates, downloadUrlValues)
+	if err != nil {
+		return nil, err
+	}
 	downloadFileInfo := buildToolConfig.linux.files[downloadFile]
 	if downloadFileInfo == (runtimeConfigFile{}) {
-		return nil, fmt.Errorf("File size and SHA-256 not found in downloads.toml for %s", downloadFile)
+		return nil, fmt.Errorf("File size and digests not found in downloads.toml for %s", downloadFile)
 	}
-	// Expected file size and SHA-256
-	expectedFileSize := downloadFileInfo.size
-	expectedSha256 := downloadFileInfo.sha256
 
 	linuxConfig := new(linuxConfig)
 	linuxConfig.downloadFile = downloadFile
-	linuxConfig.downloadUrl = downloadUrl
-	linuxConfig.expectedFileSize = expectedFileSize
-	linuxConfig.expectedSha256 = expectedSha256
+	linuxConfig.downloadUrls = append([]string{downloadUrl}, mirrorUrls...)
+	linuxConfig.downloadFileInfo = downloadFileInfo
 	return linuxConfig, nil
 }
 
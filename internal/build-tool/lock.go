@@ -0,0 +1,99 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildtool
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+// Lock files live in their own directory within the toolchain directory, so
+// that they aren't mistaken for tools.  They are never removed: removing a
+// lock file which another build-tool has open, waiting for the lock, would
+// let a third build-tool lock a new file of the same name at the same time.
+const lockDirName = ".locks"
+
+// ErrLocked is returned by --no-wait runs when another build-tool holds a
+// lock.
+var ErrLocked = errors.New("locked by another build-tool")
+
+// lockToolchainDir takes the advisory lock for a versioned directory in the
+// toolchain directory, which must be held while the directory is being
+// downloaded into, built or removed.  It returns a function which releases
+// the lock.
+func lockToolchainDir(buildToolConfig *RuntimeConfigBuildTool, versionedDir string) (func(), error) {
+	return lockFile(buildToolConfig.Directories.ToolChainDir, filepath.Base(versionedDir), buildToolConfig.WaitForLocks)
+}
+
+// lockToolchainToml takes the advisory lock for toolchain.toml, which must be
+// held while reading it to update it.  Writes are atomic, so readers need
+// not take the lock.
+func lockToolchainToml(toolchainDir string, wait bool) (func(), error) {
+	return lockFile(toolchainDir, toolchainTomlFileName, wait)
+}
+
+// lockFile takes an exclusive flock(2) on the lock file for name in
+// toolchainDir.  If another process holds it, lockFile waits for it to be
+// released, or, if wait is false, fails with ErrLocked.  The lock is also
+// released if the build-tool exits or crashes.
+func lockFile(toolchainDir string, name string, wait bool) (func(), error) {
+	lockDir := filepath.Join(toolchainDir, lockDirName)
+	err := os.MkdirAll(lockDir, 0750)
+	if err != nil {
+		return nil, err
+	}
+	lockPath := filepath.Join(lockDir, name+".lock")
+	file, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0640)
+	if err != nil {
+		return nil, err
+	}
+	fd := int(file.Fd())
+	err = unix.Flock(fd, unix.LOCK_EX|unix.LOCK_NB)
+	if errors.Is(err, unix.EWOULDBLOCK) {
+		if !wait {
+			file.Close()
+			return nil, fmt.Errorf("%s: %w", lockPath, ErrLocked)
+		}
+		// Logged now rather than returned with the messages, since the
+		// wait may be long.
+		log.Printf("Waiting for another build-tool to release %s", lockPath)
+		err = flockRetryingInterrupts(fd, unix.LOCK_EX)
+	}
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: %w", lockPath, err)
+	}
+	unlock := func() {
+		// Closing the file releases the lock.
+		file.Close()
+	}
+	return unlock, nil
+}
+
+func flockRetryingInterrupts(fd int, how int) error {
+	for {
+		err := unix.Flock(fd, how)
+		if !errors.Is(err, unix.EINTR) {
+			return err
+		}
+	}
+}
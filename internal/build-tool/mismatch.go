@@ -0,0 +1,150 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildtool
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// quarantineDirName is the directory in the download directory to which
+// downloads that don't match downloads.toml are moved, so that they are
+// neither used nor lost.  It is never cleaned up automatically.
+const quarantineDirName = "quarantine"
+
+// downloadAndVerify downloads a file from the first of downloadUrls, and
+// checks it against its size and digests in downloads.toml.  If it doesn't
+// match, and the user agrees, the file is quarantined and downloaded once
+// more, from the next URL if there is one, so that a corrupt download or a
+// bad mirror doesn't leave the user stuck.  If the second copy doesn't match
+// either, the error says whether upstream changed the file or the copies
+// differ.
+func downloadAndVerify(buildToolConfig *RuntimeConfigBuildTool, toolName string, downloadUrls []string, downloadPath string, file runtimeConfigFile) ([]string, error) {
+	messages, err := downloadAndVerifyOnce(buildToolConfig, toolName, downloadUrls[0], downloadPath, file)
+	var mismatch *mismatchError
+	if !errors.As(err, &mismatch) {
+		return messages, err
+	}
+	if !buildToolConfig.RedownloadOnMismatch && !confirmRedownload(downloadPath, err) {
+		messages = append(messages, fmt.Sprintf("Run with --redownload-on-mismatch to quarantine %s and download it again", downloadPath))
+		return messages, err
+	}
+	quarantinePath, err := quarantineDownload(buildToolConfig.Directories.DownloadDir, downloadPath)
+	if err != nil {
+		return messages, err
+	}
+	messages = append(messages, fmt.Sprintf("%s; moved it to %s", mismatch, quarantinePath))
+	retryUrl := downloadUrls[0]
+	if len(downloadUrls) > 1 {
+		retryUrl = downloadUrls[1]
+	}
+	retryMessages, err := downloadAndVerifyOnce(buildToolConfig, toolName, retryUrl, downloadPath, file)
+	messages = append(messages, retryMessages...)
+	if !errors.As(err, &mismatch) {
+		if err == nil {
+			messages = append(messages, fmt.Sprintf("The copy of %s from %s matches downloads.toml; the first copy, from %s, was bad", filepath.Base(downloadPath), retryUrl, downloadUrls[0]))
+		}
+		return messages, err
+	}
+
+	// Both copies are wrong.  If they are the same, upstream changed the
+	// file; downloads.toml shouldn't be updated without finding out why.
+	retryQuarantinePath, err := quarantineDownload(buildToolConfig.Directories.DownloadDir, downloadPath)
+	if err != nil {
+		return messages, err
+	}
+	firstSha256, firstSize, err := sha256File(quarantinePath)
+	if err != nil {
+		return messages, err
+	}
+	retrySha256, retrySize, err := sha256File(retryQuarantinePath)
+	if err != nil {
+		return messages, err
+	}
+	if firstSha256 == retrySha256 {
+		return messages, fmt.Errorf("%s has changed upstream: the copies from %s and %s both have SHA-256 %s and size %d, which don't match downloads.toml (%s).  Check why before updating downloads.toml; the copies are in %s", filepath.Base(downloadPath), downloadUrls[0], retryUrl, retrySha256, retrySize, mismatch.expected, filepath.Dir(quarantinePath))
+	}
+	return messages, fmt.Errorf("%s: the copies from %s (SHA-256 %s, size %d) and %s (SHA-256 %s, size %d) differ from each other and from downloads.toml; the download may be corrupted in transit.  The copies are in %s", filepath.Base(downloadPath), downloadUrls[0], firstSha256, firstSize, retryUrl, retrySha256, retrySize, filepath.Dir(quarantinePath))
+}
+
+// expandMirrorUrlTemplates expands a tool's MirrorUrlTemplates with the same
+// values as its DownloadUrlTemplate.
+func expandMirrorUrlTemplates(templates []string, values any) ([]string, error) {
+	mirrorUrls := make([]string, 0, len(templates))
+	for _, text := range templates {
+		mirrorUrl, err := executeToolTemplate("mirrorUrl", text, values)
+		if err != nil {
+			return nil, err
+		}
+		mirrorUrls = append(mirrorUrls, mirrorUrl)
+	}
+	return mirrorUrls, nil
+}
+
+func downloadAndVerifyOnce(buildToolConfig *RuntimeConfigBuildTool, toolName string, downloadUrl string, downloadPath string, file runtimeConfigFile) ([]string, error) {
+	messages := make([]string, 0, 2)
+	stopTimer := buildToolConfig.Stats.startStep(toolName, stepDownload)
+	downloaded, downloadMessages, err := downloadUrlToDir(buildToolConfig.github, downloadUrl, buildToolConfig.Directories.DownloadDir, downloadPath, file.size)
+	stopTimer()
+	messages = append(messages, downloadMessages...)
+	if err != nil {
+		return messages, err
+	}
+	buildToolConfig.Stats.recordDownload(toolName, downloaded)
+	stopTimer = buildToolConfig.Stats.startStep(toolName, stepVerify)
+	defer stopTimer()
+	err = verifyFileSize(file.size, downloadPath)
+	if err != nil {
+		return messages, err
+	}
+	digestMessages, err := verifyDigests(file.digests, downloadPath)
+	messages = append(messages, digestMessages...)
+	return messages, err
+}
+
+// quarantineDownload moves a download into the quarantine directory, under a
+// name with the time, so that earlier quarantined copies are kept.
+func quarantineDownload(downloadDir string, downloadPath string) (string, error) {
+	quarantineDir := filepath.Join(downloadDir, quarantineDirName)
+	err := os.MkdirAll(quarantineDir, 0750)
+	if err != nil {
+		return "", err
+	}
+	quarantinePath := filepath.Join(quarantineDir, filepath.Base(downloadPath)+"."+time.Now().UTC().Format("20060102T150405.000000000Z"))
+	return quarantinePath, os.Rename(downloadPath, quarantinePath)
+}
+
+// confirmRedownload asks whether to quarantine the file and download it
+// again, if standard input is a terminal.  Otherwise, e.g., in CI, it
+// returns false.
+func confirmRedownload(downloadPath string, mismatch error) bool {
+	_, err := unix.IoctlGetTermios(int(os.Stdin.Fd()), unix.TCGETS)
+	if err != nil {
+		return false
+	}
+	fmt.Fprintf(os.Stderr, "%v\nQuarantine %s and download it again? [y/N] ", mismatch, filepath.Base(downloadPath))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,103 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildtool
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// A toolPatch is a fix to an upstream source tree, kept in the tool's
+// patches directory, e.g., internal/build-tool/flex/patches/.  Patches are
+// applied in file name order with patch -p1 from the top of the extracted
+// tree, so a "0001-" style prefix sets their order.
+type toolPatch struct {
+	name   string
+	path   string
+	sha256 string
+}
+
+// id identifies the patch's name and contents, as recorded in toolchain.toml.
+func (p toolPatch) id() string {
+	return p.name + "@" + p.sha256[:16]
+}
+
+// readPatches returns the patches in patchDir, which need not exist.
+func readPatches(patchDir string) ([]toolPatch, error) {
+	if patchDir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(patchDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	patches := make([]toolPatch, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".patch") {
+			continue
+		}
+		patchPath := filepath.Join(patchDir, entry.Name())
+		contents, err := os.ReadFile(patchPath)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(contents)
+		patches = append(patches, toolPatch{
+			name:   entry.Name(),
+			path:   patchPath,
+			sha256: hex.EncodeToString(sum[:]),
+		})
+	}
+	slices.SortFunc(patches, func(a, b toolPatch) int {
+		return strings.Compare(a.name, b.name)
+	})
+	return patches, nil
+}
+
+func patchIds(patches []toolPatch) []string {
+	ids := make([]string, 0, len(patches))
+	for _, patch := range patches {
+		ids = append(ids, patch.id())
+	}
+	return ids
+}
+
+// applyPatches applies patches to the source tree in sourceDir.
+func applyPatches(patches []toolPatch, sourceDir string) ([]string, error) {
+	messages := make([]string, 0, len(patches))
+	for _, patch := range patches {
+		cmd := exec.Command("patch", "-p1", "--batch", "--forward", "--input", patch.path)
+		cmd.Dir = sourceDir
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		err := cmd.Run()
+		if err != nil {
+			return messages, fmt.Errorf("Failed to apply %s to %s: %w", patch.path, sourceDir, err)
+		}
+		messages = append(messages, fmt.Sprintf("Applied %s", patch.path))
+	}
+	return messages, nil
+}
@@ -0,0 +1,276 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildtool
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// ./configure and make for the tools built from source run in a sandbox, so
+// that nothing from the host environment except the compilers and the other
+// programs on a pinned PATH can affect the result.  The sandbox is a set of
+// new Linux namespaces, created without privileges through a user namespace:
+// a network namespace with only a loopback interface, a mount namespace with
+// a private, empty /tmp, and new IPC and UTS namespaces.  The commands also
+// get a minimal environment rather than the build-tool's.
+//
+// The build-tool sets up the namespaces by running itself with
+// SandboxInitCommand, which mounts /tmp, brings up the loopback interface
+// and then executes the sandboxed command.
+
+// SandboxInitCommand is the hidden first argument with which the build-tool
+// runs itself to finish setting up a sandbox.  main must call SandboxInit
+// when it sees it, before parsing any other arguments.
+const SandboxInitCommand = "__sandbox-init"
+
+const (
+	// sandboxModeAuto sandboxes commands if the kernel allows
+	// unprivileged user namespaces, and otherwise runs them with only the
+	// minimal environment.
+	sandboxModeAuto = "auto"
+	// sandboxModeRequired fails builds which can't be sandboxed.
+	sandboxModeRequired = "required"
+	// sandboxModeOff runs commands directly, with the build-tool's
+	// environment, as before sandboxing was added.
+	sandboxModeOff = "off"
+)
+
+const defaultSandboxPath = "/usr/bin:/bin"
+
+// sandboxHostname is the hostname within the sandbox, so that it can't end
+// up in the tools.
+const sandboxHostname = "localhost"
+
+type runtimeConfigSandbox struct {
+	mode string
+	path string // PATH within the sandbox
+
+	probeOnce sync.Once
+	probeErr  error // why namespaces can't be used, if they can't
+}
+
+func newSandboxRuntimeConfig(configToml *ConfigTomlSandbox) (*runtimeConfigSandbox, error) {
+	sandbox := &runtimeConfigSandbox{
+		mode: configToml.Mode,
+		path: configToml.Path,
+	}
+	switch sandbox.mode {
+	case "":
+		sandbox.mode = sandboxModeAuto
+	case sandboxModeAuto, sandboxModeRequired, sandboxModeOff:
+	default:
+		return nil, fmt.Errorf("[build-tool.sandbox].Mode must be %q, %q or %q, not %q", sandboxModeAuto, sandboxModeRequired, sandboxModeOff, sandbox.mode)
+	}
+	if sandbox.path == "" {
+		sandbox.path = defaultSandboxPath
+	}
+	for _, dir := range filepath.SplitList(sandbox.path) {
+		if !filepath.IsAbs(dir) {
+			return nil, fmt.Errorf("[build-tool.sandbox].Path must only contain absolute directories, not %q", dir)
+		}
+	}
+	return sandbox, nil
+}
+
+// environment returns the whole environment of sandboxed commands.  Callers
+// may append more variables.
+func (s *runtimeConfigSandbox) environment() []string {
+	return []string{
+		"PATH=" + s.path,
+		"HOME=/tmp",
+		"TMPDIR=/tmp",
+		"LC_ALL=C",
+		"TZ=UTC",
+	}
+}
+
+// command returns a command which runs name with args in dir, in the
+// sandbox if it is enabled.  Its Env is set, and callers should append to it
+// rather than replace it.  If the sandbox is required but not available,
+// command returns an error.
+func (s *runtimeConfigSandbox) command(dir string, name string, args ...string) (*exec.Cmd, error) {
+	if s.mode == sandboxModeOff {
+		cmd := exec.Command(name, args...)
+		cmd.Dir = dir
+		cmd.Env = os.Environ()
+		return cmd, nil
+	}
+	if err := s.probe(); err != nil {
+		if s.mode == sandboxModeRequired {
+			return nil, fmt.Errorf("cannot sandbox %s: %w", name, err)
+		}
+		// name is found on the pinned PATH here, as it is in the
+		// sandbox, rather than on the build-tool's PATH.
+		path, err := lookPathIn(name, s.path)
+		if err != nil {
+			return nil, err
+		}
+		cmd := exec.Command(path, args...)
+		cmd.Args[0] = name
+		cmd.Dir = dir
+		cmd.Env = s.environment()
+		return cmd, nil
+	}
+	cmd, err := sandboxInitCommand(append([]string{name}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Dir = dir
+	cmd.Env = s.environment()
+	return cmd, nil
+}
+
+// probe checks, once, that the sandbox's namespaces can be set up, by
+// running SandboxInit without a command.  In auto mode, it logs why not.
+func (s *runtimeConfigSandbox) probe() error {
+	s.probeOnce.Do(func() {
+		cmd, err := sandboxInitCommand()
+		if err == nil {
+			cmd.Env = s.environment()
+			var output []byte
+			output, err = cmd.CombinedOutput()
+			if err != nil && len(output) > 0 {
+				err = fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+			}
+		}
+		s.probeErr = err
+		if err != nil && s.mode == sandboxModeAuto {
+			log.Printf("Running ./configure and make without a sandbox: %v", err)
+		}
+	})
+	return s.probeErr
+}
+
+// sandboxInitCommand returns a command which runs the build-tool with
+// SandboxInitCommand and args in new namespaces.  The user namespace maps
+// only the build-tool's user and group, so that files written by the
+// sandboxed command belong to them.  SandboxInit is given the capabilities
+// it needs to set up the other namespaces, and drops them before running the
+// sandboxed command.
+func sandboxInitCommand(args ...string) (*exec.Cmd, error) {
+	self, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(self, append([]string{SandboxInitCommand}, args...)...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags: syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWNET |
+			syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS,
+		UidMappings: []syscall.SysProcIDMap{
+			{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1},
+		},
+		GidMappings: []syscall.SysProcIDMap{
+			{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1},
+		},
+		GidMappingsEnableSetgroups: false,
+		AmbientCaps:                []uintptr{unix.CAP_SYS_ADMIN, unix.CAP_NET_ADMIN},
+	}
+	return cmd, nil
+}
+
+// SandboxInit finishes setting up a sandbox from inside its namespaces, and
+// then executes args, found on the sandbox's PATH.  With no args, it exits
+// once the sandbox is set up.  It never returns.
+func SandboxInit(args []string) {
+	err := setUpSandbox()
+	if err == nil && len(args) > 0 {
+		var path string
+		path, err = exec.LookPath(args[0])
+		if err == nil {
+			err = syscall.Exec(path, args, os.Environ())
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "build-tool sandbox: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func setUpSandbox() error {
+	// Keep the mounts below out of the build-tool's mount namespace.
+	err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, "")
+	if err != nil {
+		return fmt.Errorf("making mounts private: %w", err)
+	}
+	for _, tmp := range []string{"/tmp", "/var/tmp"} {
+		if _, err := os.Stat(tmp); os.IsNotExist(err) {
+			continue
+		}
+		err = unix.Mount("tmpfs", tmp, "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, "mode=1777")
+		if err != nil {
+			return fmt.Errorf("mounting %s: %w", tmp, err)
+		}
+	}
+	err = unix.Sethostname([]byte(sandboxHostname))
+	if err != nil {
+		return fmt.Errorf("setting hostname: %w", err)
+	}
+	// Cap'n Proto's tests talk to themselves over localhost.
+	err = bringUpLoopback()
+	if err != nil {
+		return fmt.Errorf("bringing up loopback interface: %w", err)
+	}
+	err = unix.Prctl(unix.PR_CAP_AMBIENT, unix.PR_CAP_AMBIENT_CLEAR_ALL, 0, 0, 0)
+	if err != nil {
+		return fmt.Errorf("dropping capabilities: %w", err)
+	}
+	return nil
+}
+
+func bringUpLoopback() error {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+	ifreq, err := unix.NewIfreq("lo")
+	if err != nil {
+		return err
+	}
+	err = unix.IoctlIfreq(fd, unix.SIOCGIFFLAGS, ifreq)
+	if err != nil {
+		return err
+	}
+	ifreq.SetUint16(ifreq.Uint16() | unix.IFF_UP)
+	return unix.IoctlIfreq(fd, unix.SIOCSIFFLAGS, ifreq)
+}
+
+// lookPathIn is exec.LookPath with the given PATH rather than the
+// build-tool's.
+func lookPathIn(name string, path string) (string, error) {
+	if strings.Contains(name, "/") {
+		return name, nil
+	}
+	for _, dir := range filepath.SplitList(path) {
+		candidate := filepath.Join(dir, name)
+		info, err := os.Stat(candidate)
+		if err == nil && info.Mode().IsRegular() && info.Mode().Perm()&0111 != 0 {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("%s: %w in %s", name, exec.ErrNotFound, path)
+}
@@ -0,0 +1,169 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildtool
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Schema versions of the TOML files read by the build-tool.  Bump a version
+// whenever a layout change would make an older file decode differently, and
+// add a migration from the previous version.
+const (
+	ConfigTomlSchemaVersion    = 1
+	DownloadsTomlSchemaVersion = 2
+)
+
+// Files without a SchemaVersion key predate schema versioning, and have the
+// first layout.
+const firstSchemaVersion = 1
+
+// A tomlMigration rewrites a decoded document from schema version from to
+// from+1, and returns warnings about anything it could not migrate exactly.
+type tomlMigration struct {
+	from    int
+	migrate func(doc map[string]any) []string
+}
+
+var configTomlMigrations = []tomlMigration{}
+
+var downloadsTomlMigrations = []tomlMigration{
+	{1, migrateDownloadsTomlV1},
+}
+
+// decodeVersionedToml decodes the TOML file at filePath into v, migrating it
+// first if it has an older SchemaVersion than current.  It returns warnings
+// about the migration, and about keys which v has no place for, which are
+// usually misspelled or from a newer layout.
+func decodeVersionedToml(filePath string, current int, migrations []tomlMigration, v any) ([]string, error) {
+	messages := make([]string, 0)
+	doc := make(map[string]any)
+	_, err := toml.DecodeFile(filePath, &doc)
+	if err != nil {
+		return messages, err
+	}
+	version := firstSchemaVersion
+	switch value := doc["SchemaVersion"].(type) {
+	case nil:
+		messages = append(messages, fmt.Sprintf("%s has no SchemaVersion; assuming %d", filePath, firstSchemaVersion))
+	case int64:
+		version = int(value)
+	default:
+		return messages, fmt.Errorf("%s: SchemaVersion must be an integer", filePath)
+	}
+	if version > current {
+		return messages, fmt.Errorf("%s has SchemaVersion %d, but this build-tool only understands up to %d; update Tempest", filePath, version, current)
+	}
+	if version < firstSchemaVersion {
+		return messages, fmt.Errorf("%s: SchemaVersion %d is invalid", filePath, version)
+	}
+	if version < current {
+		for from := version; from < current; from++ {
+			migrated := false
+			for _, migration := range migrations {
+				if migration.from == from {
+					for _, warning := range migration.migrate(doc) {
+						messages = append(messages, fmt.Sprintf("%s: %s", filePath, warning))
+					}
+					migrated = true
+					break
+				}
+			}
+			if !migrated {
+				return messages, fmt.Errorf("%s: no migration from SchemaVersion %d", filePath, from)
+			}
+		}
+		messages = append(messages, fmt.Sprintf("%s was migrated from SchemaVersion %d to %d; update the file and set SchemaVersion = %d to silence this warning", filePath, version, current, current))
+		doc["SchemaVersion"] = int64(current)
+	}
+
+	// Re-encode the migrated document, so that it decodes into v exactly
+	// as a file in the current layout would.
+	var buffer bytes.Buffer
+	err = toml.NewEncoder(&buffer).Encode(doc)
+	if err != nil {
+		return messages, err
+	}
+	metadata, err := toml.Decode(buffer.String(), v)
+	if err != nil {
+		return messages, fmt.Errorf("%s: %w", filePath, err)
+	}
+	for _, key := range undecodedTomlKeys(metadata) {
+		messages = append(messages, fmt.Sprintf("%s: unknown key %s is ignored", filePath, key))
+	}
+	return messages, nil
+}
+
+// undecodedTomlKeys returns the keys which weren't decoded, leaving out keys
+// within tables which are themselves undecoded.
+func undecodedTomlKeys(metadata toml.MetaData) []string {
+	result := make([]string, 0)
+	for _, key := range metadata.Undecoded() {
+		name := key.String()
+		reported := false
+		for _, parent := range result {
+			if strings.HasPrefix(name, parent+".") {
+				reported = true
+				break
+			}
+		}
+		if !reported {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// migrateDownloadsTomlV1 adds Version to the files of tools with a single
+// file, which in SchemaVersion 1 was the tool's PreferredVersion.  Tools with
+// several files, for other versions or platforms, are only warned about, since
+// their Version, Os and Arch can't be worked out.
+func migrateDownloadsTomlV1(doc map[string]any) []string {
+	warnings := make([]string, 0)
+	toolNames := make([]string, 0, len(doc))
+	for toolName := range doc {
+		toolNames = append(toolNames, toolName)
+	}
+	slices.Sort(toolNames)
+	for _, toolName := range toolNames {
+		tool, ok := doc[toolName].(map[string]any)
+		if !ok {
+			continue
+		}
+		files, _ := tool["files"].(map[string]any)
+		if len(files) > 1 {
+			warnings = append(warnings, fmt.Sprintf("[%s] has several files; add Version to each, and Os, Arch and the tool's Platforms for binaries", toolName))
+			continue
+		}
+		for _, fileValue := range files {
+			file, ok := fileValue.(map[string]any)
+			if !ok {
+				continue
+			}
+			preferredVersion, ok := tool["PreferredVersion"].(string)
+			if _, hasVersion := file["Version"]; ok && !hasVersion {
+				file["Version"] = preferredVersion
+			}
+		}
+	}
+	return warnings
+}
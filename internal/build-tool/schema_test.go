@@ -0,0 +1,88 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildtool
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadConfigFileUnversioned(t *testing.T) {
+	path := filepath.Join("testdata", "config-unversioned.toml")
+	config, messages, err := ReadConfigFile(&path)
+	require.NoError(t, err)
+	assert.Equal(t, "sandstorm", config.Tempest.User)
+	assert.Equal(t, 4, config.BuildTool.Jobs)
+	assert.Equal(t, "1.1.0", config.BuildTool.CapnProto.Version)
+	assert.Equal(t, []string{
+		path + " has no SchemaVersion; assuming 1",
+		path + ": unknown key build-tool.UnknownSetting is ignored",
+	}, messages)
+}
+
+func TestReadDownloadsFileMigratesV1(t *testing.T) {
+	path := filepath.Join("testdata", "downloads-v1.toml")
+	downloads, messages, err := ReadDownloadsFile(&path)
+	require.NoError(t, err)
+	assert.Equal(t, DownloadsTomlSchemaVersion, downloads.SchemaVersion)
+
+	// A tool's only file gets the tool's PreferredVersion.
+	bison := downloads.Bison.Files["bison-3.8.2.tar.xz"]
+	assert.Equal(t, "3.8.2", bison.Version)
+	assert.Equal(t, int64(2817324), bison.Size)
+
+	// With several files, the version can't be worked out.
+	for name, file := range downloads.TinyGo.Files {
+		assert.Empty(t, file.Version, name)
+	}
+	assert.Equal(t, []string{
+		path + ": [tinygo] has several files; add Version to each, and Os, Arch and the tool's Platforms for binaries",
+		path + " was migrated from SchemaVersion 1 to 2; update the file and set SchemaVersion = 2 to silence this warning",
+	}, messages)
+}
+
+func TestDecodeVersionedToml(t *testing.T) {
+	dir := t.TempDir()
+	cases := []struct {
+		name     string
+		contents string
+		err      string
+	}{
+		{"newer", "SchemaVersion = 3\n", "has SchemaVersion 3, but this build-tool only understands up to 2"},
+		{"invalid", "SchemaVersion = 0\n", "SchemaVersion 0 is invalid"},
+		{"not-integer", "SchemaVersion = \"2\"\n", "SchemaVersion must be an integer"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path := filepath.Join(dir, c.name+".toml")
+			require.NoError(t, os.WriteFile(path, []byte(c.contents), 0644))
+			_, _, err := ReadDownloadsFile(&path)
+			assert.ErrorContains(t, err, c.err)
+		})
+	}
+
+	// A migration which is missing is an error, rather than being skipped.
+	path := filepath.Join(dir, "missing-migration.toml")
+	require.NoError(t, os.WriteFile(path, []byte("SchemaVersion = 1\n"), 0644))
+	var v map[string]any
+	_, err := decodeVersionedToml(path, 2, nil, &v)
+	assert.ErrorContains(t, err, "no migration from SchemaVersion 1")
+}
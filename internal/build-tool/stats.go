@@ -0,0 +1,236 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildtool
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// The steps of a bootstrap which are timed, in the order they run.  Not every
+// tool has every step; Binaryen and TinyGo, for example, are downloaded
+// prebuilt.
+const (
+	stepDownload  = "download"
+	stepVerify    = "verify"
+	stepExtract   = "extract"
+	stepConfigure = "configure"
+	stepMake      = "make"
+)
+
+var timedSteps = []string{stepDownload, stepVerify, stepExtract, stepConfigure, stepMake}
+
+// BootstrapStats records, for each tool bootstrapped, the wall-clock time
+// taken by each step, how often the download cache was used, how much was
+// downloaded, and how much disk space the installed tool takes, so that
+// maintainers can see where bootstrap time and space go.  Nothing is sent
+// anywhere; the build-tool only prints the summary.  A nil *BootstrapStats
+// records nothing.
+type BootstrapStats struct {
+	tools []toolStats
+}
+
+type toolStats struct {
+	tool            string
+	steps           map[string]time.Duration
+	cacheHits       int
+	cacheMisses     int
+	bytesDownloaded int64
+	diskBytes       int64
+}
+
+// ToolStatsReport holds the statistics of one tool, with times in seconds,
+// for the --json output.
+type ToolStatsReport struct {
+	Tool            string             `json:"tool"`
+	StepSeconds     map[string]float64 `json:"stepSeconds"`
+	TotalSeconds    float64            `json:"totalSeconds"`
+	CacheHits       int                `json:"cacheHits"`
+	CacheMisses     int                `json:"cacheMisses"`
+	BytesDownloaded int64              `json:"bytesDownloaded"`
+	DiskBytes       int64              `json:"diskBytes"`
+}
+
+// startStep starts timing a step of bootstrapping tool, and returns a
+// function which stops the timer.  A step which runs more than once, such as
+// downloading go-capnp and then its Go modules, is added up.
+func (s *BootstrapStats) startStep(tool string, step string) func() {
+	if s == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		s.get(tool).steps[step] += time.Since(start)
+	}
+}
+
+// recordDownload records a download of a file for tool.  bytesDownloaded is
+// zero if the copy in the download directory was used instead, which counts
+// as a cache hit.
+func (s *BootstrapStats) recordDownload(tool string, bytesDownloaded int64) {
+	if s == nil {
+		return
+	}
+	stats := s.get(tool)
+	if bytesDownloaded == 0 {
+		stats.cacheHits++
+	} else {
+		stats.cacheMisses++
+		stats.bytesDownloaded += bytesDownloaded
+	}
+}
+
+// recordDiskUsage records the size of the directory a tool was installed
+// into.  Failures to measure it are ignored, since the statistics are only
+// informational.
+func (s *BootstrapStats) recordDiskUsage(tool string, dirPath string) {
+	if s == nil {
+		return
+	}
+	size, err := dirSize(dirPath)
+	if err != nil {
+		return
+	}
+	s.get(tool).diskBytes += size
+}
+
+// get returns the statistics of tool, adding them if need be.
+func (s *BootstrapStats) get(tool string) *toolStats {
+	for i := range s.tools {
+		if s.tools[i].tool == tool {
+			return &s.tools[i]
+		}
+	}
+	s.tools = append(s.tools, toolStats{
+		tool:  tool,
+		steps: make(map[string]time.Duration),
+	})
+	return &s.tools[len(s.tools)-1]
+}
+
+func (t *toolStats) total() time.Duration {
+	var total time.Duration
+	for _, elapsed := range t.steps {
+		total += elapsed
+	}
+	return total
+}
+
+// Report returns the statistics of each tool, in the order they were
+// bootstrapped.  Tools which were already installed are left out.
+func (s *BootstrapStats) Report() []ToolStatsReport {
+	if s == nil {
+		return nil
+	}
+	report := make([]ToolStatsReport, 0, len(s.tools))
+	for _, tool := range s.tools {
+		toolReport := ToolStatsReport{
+			Tool:            tool.tool,
+			StepSeconds:     make(map[string]float64, len(tool.steps)),
+			TotalSeconds:    roundSeconds(tool.total()),
+			CacheHits:       tool.cacheHits,
+			CacheMisses:     tool.cacheMisses,
+			BytesDownloaded: tool.bytesDownloaded,
+			DiskBytes:       tool.diskBytes,
+		}
+		for step, elapsed := range tool.steps {
+			toolReport.StepSeconds[step] = roundSeconds(elapsed)
+		}
+		report = append(report, toolReport)
+	}
+	return report
+}
+
+// SummaryTable returns the statistics as the lines of a table, with a row
+// for each tool, and a row of totals if there is more than one.  It returns
+// nil if nothing was bootstrapped.
+func (s *BootstrapStats) SummaryTable() []string {
+	if s == nil || len(s.tools) == 0 {
+		return nil
+	}
+	var buffer bytes.Buffer
+	writer := tabwriter.NewWriter(&buffer, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(writer, "tool\t%s\ttotal\tcache hits\tcache misses\tdownloaded\tdisk\t\n", strings.Join(timedSteps, "\t"))
+	totals := toolStats{
+		tool:  "all",
+		steps: make(map[string]time.Duration),
+	}
+	for _, tool := range s.tools {
+		writeSummaryRow(writer, &tool)
+		for step, elapsed := range tool.steps {
+			totals.steps[step] += elapsed
+		}
+		totals.cacheHits += tool.cacheHits
+		totals.cacheMisses += tool.cacheMisses
+		totals.bytesDownloaded += tool.bytesDownloaded
+		totals.diskBytes += tool.diskBytes
+	}
+	if len(s.tools) > 1 {
+		writeSummaryRow(writer, &totals)
+	}
+	writer.Flush()
+	return strings.Split(strings.TrimRight(buffer.String(), "\n"), "\n")
+}
+
+func writeSummaryRow(writer *tabwriter.Writer, tool *toolStats) {
+	fmt.Fprintf(writer, "%s\t", tool.tool)
+	for _, step := range timedSteps {
+		elapsed, ok := tool.steps[step]
+		if !ok {
+			fmt.Fprint(writer, "-\t")
+			continue
+		}
+		fmt.Fprintf(writer, "%.1fs\t", elapsed.Seconds())
+	}
+	fmt.Fprintf(writer, "%.1fs\t%d\t%d\t%s\t%s\t\n",
+		tool.total().Seconds(),
+		tool.cacheHits,
+		tool.cacheMisses,
+		formatBytes(tool.bytesDownloaded),
+		formatBytes(tool.diskBytes),
+	)
+}
+
+// roundSeconds converts a duration to seconds, to the nearest millisecond.
+func roundSeconds(d time.Duration) float64 {
+	return d.Round(time.Millisecond).Seconds()
+}
+
+// dirSize returns the total size of the regular files in a directory tree.
+func dirSize(dirPath string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dirPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
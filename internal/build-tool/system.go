@@ -0,0 +1,108 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildtool
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// systemTool describes how to find a tool installed on the system, for
+// --prefer-system.
+type systemTool struct {
+	// executable is looked up in PATH.
+	executable string
+	// versionArgs make the executable print its version, which is the
+	// first dotted number in the output.
+	versionArgs []string
+}
+
+var systemToolVersionRegexp = regexp.MustCompile(`\d+\.\d+(\.\d+)*`)
+
+// useSystemTool records the system's copy of the tool in toolchain.toml, if
+// there is one in PATH and its version is at least the tool's
+// MinimumVersion, or its version if no minimum is configured.  It returns
+// false, with a message saying why, if the tool should be downloaded
+// instead.
+func (a *archiveTool) useSystemTool(buildToolConfig *RuntimeConfigBuildTool, tool *runtimeConfigTool, patches []toolPatch) (bool, []string, error) {
+	messages := make([]string, 0, 1)
+	executable, err := exec.LookPath(a.system.executable)
+	if err != nil {
+		messages = append(messages, fmt.Sprintf("No %s in PATH; downloading %s", a.system.executable, tool.Name))
+		return false, messages, nil
+	}
+	executable, err = filepath.Abs(executable)
+	if err != nil {
+		return false, messages, err
+	}
+	version, err := systemToolVersion(executable, a.system.versionArgs)
+	if err != nil {
+		messages = append(messages, fmt.Sprintf("Could not get the version of %s (%v); downloading %s", executable, err, tool.Name))
+		return false, messages, nil
+	}
+	minimumVersion := tool.minimumVersion
+	if minimumVersion == "" {
+		minimumVersion = tool.version
+	}
+	if compareVersions(version, minimumVersion) < 0 {
+		messages = append(messages, fmt.Sprintf("%s is %s %s, older than %s; downloading %s", executable, tool.Name, version, minimumVersion, tool.Name))
+		return false, messages, nil
+	}
+	if len(patches) > 0 {
+		messages = append(messages, fmt.Sprintf("%s lacks the patches in %s; downloading %s", executable, tool.patchDir, tool.Name))
+		return false, messages, nil
+	}
+	toolchainTool := &ToolchainTomlTool{
+		Executable: executable,
+		Version:    version,
+	}
+	if tool.patchDir != "" {
+		toolchainTool.Patches = []string{}
+	}
+	err = a.updateToolchainToml(buildToolConfig.Directories.ToolChainDir, toolchainTool)
+	if err != nil {
+		return false, messages, err
+	}
+	messages = append(messages, fmt.Sprintf("Using %s %s from %s", tool.Name, version, executable))
+	return true, messages, nil
+}
+
+func systemToolVersion(executable string, versionArgs []string) (string, error) {
+	output, err := exec.Command(executable, versionArgs...).Output()
+	if err != nil {
+		return "", err
+	}
+	firstLine, _, _ := strings.Cut(string(output), "\n")
+	version := systemToolVersionRegexp.FindString(firstLine)
+	if version == "" {
+		return "", fmt.Errorf("no version in %q", firstLine)
+	}
+	return version, nil
+}
+
+// toolchainExecutable returns the path of an executable recorded in
+// toolchain.toml, which is relative to the toolchain directory unless it is
+// a system tool.
+func toolchainExecutable(toolChainDir string, executable string) string {
+	if filepath.IsAbs(executable) {
+		return executable
+	}
+	return filepath.Join(toolChainDir, executable)
+}
@@ -0,0 +1,299 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildtool
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// tomlDocument is a TOML file held as lines of text, so that individual keys
+// can be updated without re-encoding the whole file.  Comments, blank lines,
+// key order and formatting of everything that isn't touched are preserved.
+//
+// Only keys with single-line values can be updated; that covers everything
+// the build-tool writes.
+type tomlDocument struct {
+	lines []string
+}
+
+// readTomlDocument reads the TOML file at filePath.  If the file does not
+// exist, the returned document contains the given header lines.
+func readTomlDocument(filePath string, header ...string) (*tomlDocument, error) {
+	contents, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return &tomlDocument{lines: header}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimSuffix(string(contents), "\n")
+	if text == "" {
+		return &tomlDocument{}, nil
+	}
+	return &tomlDocument{lines: strings.Split(text, "\n")}, nil
+}
+
+// setString sets key in table to the string value.  Use an empty table for
+// top-level keys.
+func (doc *tomlDocument) setString(table string, key string, value string) error {
+	return doc.setValue(table, key, tomlQuoteString(value))
+}
+
+// setValue sets key in table to value, which must already be encoded as TOML.
+// An existing key keeps its position and any trailing comment.  A new key is
+// added after the last key in the table, and a new table is added at the end
+// of the document.
+func (doc *tomlDocument) setValue(table string, key string, value string) error {
+	currentTable := ""
+	tableFound := table == ""
+	insertAt := -1
+	if tableFound {
+		insertAt = 0
+	}
+	for index, line := range doc.lines {
+		trimmed := strings.TrimSpace(line)
+		if header, ok := tomlTableHeader(trimmed); ok {
+			currentTable = header
+			if currentTable == table {
+				tableFound = true
+				insertAt = index + 1
+			}
+			continue
+		}
+		if currentTable != table || trimmed == "" || trimmed[0] == '#' {
+			continue
+		}
+		lineKey, valueStart, ok := tomlKeyOf(line)
+		if !ok {
+			continue
+		}
+		insertAt = index + 1
+		if lineKey != key {
+			continue
+		}
+		valueEnd, err := tomlValueEnd(line, valueStart)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", table, key, err)
+		}
+		doc.lines[index] = line[:valueStart] + value + line[valueEnd:]
+		return nil
+	}
+
+	newLine := tomlQuoteKey(key) + " = " + value
+	if !tableFound {
+		if len(doc.lines) > 0 && strings.TrimSpace(doc.lines[len(doc.lines)-1]) != "" {
+			doc.lines = append(doc.lines, "")
+		}
+		doc.lines = append(doc.lines, "["+tomlQuoteKey(table)+"]", newLine)
+		return nil
+	}
+	doc.lines = append(doc.lines[:insertAt], append([]string{newLine}, doc.lines[insertAt:]...)...)
+	return nil
+}
+
+// deleteKey removes key from table, if it is there.
+func (doc *tomlDocument) deleteKey(table string, key string) {
+	currentTable := ""
+	for index, line := range doc.lines {
+		trimmed := strings.TrimSpace(line)
+		if header, ok := tomlTableHeader(trimmed); ok {
+			currentTable = header
+			continue
+		}
+		if currentTable != table || trimmed == "" || trimmed[0] == '#' {
+			continue
+		}
+		if lineKey, _, ok := tomlKeyOf(line); ok && lineKey == key {
+			doc.lines = append(doc.lines[:index], doc.lines[index+1:]...)
+			return
+		}
+	}
+}
+
+// addTable adds a new table, with lines already encoded as "key = value",
+// after the last table whose name starts with after, or at the end of the
+// document if there is none.  name is written as it should appear in the
+// table's header, e.g., `tool.files."name.tar.gz"`.
+func (doc *tomlDocument) addTable(name string, after string, lines []string) {
+	insertAt := len(doc.lines)
+	inTable := false
+	for index, line := range doc.lines {
+		trimmed := strings.TrimSpace(line)
+		if header, ok := tomlTableHeader(trimmed); ok {
+			inTable = after != "" && strings.HasPrefix(header, after)
+			if inTable {
+				insertAt = index + 1
+			}
+			continue
+		}
+		if inTable && trimmed != "" && trimmed[0] != '#' {
+			insertAt = index + 1
+		}
+	}
+	table := append([]string{"", "[" + name + "]"}, lines...)
+	if insertAt == 0 || strings.TrimSpace(doc.lines[insertAt-1]) == "" {
+		table = table[1:]
+	}
+	if insertAt < len(doc.lines) && strings.TrimSpace(doc.lines[insertAt]) != "" {
+		table = append(table, "")
+	}
+	doc.lines = append(doc.lines[:insertAt], append(table, doc.lines[insertAt:]...)...)
+}
+
+// writeFile writes the document to filePath.  The file is replaced
+// atomically, so an interrupted write can't leave it truncated.
+func (doc *tomlDocument) writeFile(filePath string) error {
+	tempFile, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+	text := strings.Join(doc.lines, "\n")
+	if text != "" {
+		text += "\n"
+	}
+	if _, err = tempFile.WriteString(text); err != nil {
+		tempFile.Close()
+		return err
+	}
+	if err = tempFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tempFile.Name(), filePath)
+}
+
+// tomlTableHeader returns the name of the table declared by a [table] line.
+// Array-of-tables headers are reported too, so that keys in them are never
+// mistaken for keys in the preceding table.
+func tomlTableHeader(trimmed string) (string, bool) {
+	if !strings.HasPrefix(trimmed, "[") {
+		return "", false
+	}
+	end := strings.Index(trimmed, "]")
+	if end < 0 {
+		return "", false
+	}
+	name := strings.TrimSpace(trimmed[1:end])
+	if strings.HasPrefix(name, "[") {
+		return "[" + strings.TrimSpace(name[1:]) + "]", true
+	}
+	return tomlUnquoteKey(name), true
+}
+
+// tomlKeyOf returns the key on a "key = value" line, and the offset at which
+// the value starts.
+func tomlKeyOf(line string) (string, int, bool) {
+	equals := strings.Index(line, "=")
+	if equals < 0 {
+		return "", 0, false
+	}
+	key := tomlUnquoteKey(strings.TrimSpace(line[:equals]))
+	valueStart := equals + 1
+	for valueStart < len(line) && (line[valueStart] == ' ' || line[valueStart] == '\t') {
+		valueStart++
+	}
+	return key, valueStart, true
+}
+
+// tomlValueEnd returns the offset just past the single-line value starting at
+// line[start].
+func tomlValueEnd(line string, start int) (int, error) {
+	rest := line[start:]
+	switch {
+	case strings.HasPrefix(rest, `"""`), strings.HasPrefix(rest, "'''"):
+		return 0, fmt.Errorf("multi-line strings can't be updated")
+	case strings.HasPrefix(rest, `"`):
+		for index := 1; index < len(rest); index++ {
+			switch rest[index] {
+			case '\\':
+				index++
+			case '"':
+				return start + index + 1, nil
+			}
+		}
+		return 0, fmt.Errorf("unterminated string")
+	case strings.HasPrefix(rest, "'"):
+		end := strings.Index(rest[1:], "'")
+		if end < 0 {
+			return 0, fmt.Errorf("unterminated string")
+		}
+		return start + end + 2, nil
+	case strings.HasPrefix(rest, "["), strings.HasPrefix(rest, "{"):
+		opening := strings.Count(rest, "[") + strings.Count(rest, "{")
+		closing := strings.Count(rest, "]") + strings.Count(rest, "}")
+		if opening != closing {
+			return 0, fmt.Errorf("multi-line values can't be updated")
+		}
+	}
+	end := len(line)
+	if comment := strings.Index(rest, "#"); comment >= 0 {
+		end = start + comment
+	}
+	return len(strings.TrimRight(line[:end], " \t")), nil
+}
+
+func tomlQuoteString(value string) string {
+	var builder strings.Builder
+	builder.WriteByte('"')
+	for _, r := range value {
+		switch {
+		case r == '"' || r == '\\':
+			builder.WriteByte('\\')
+			builder.WriteRune(r)
+		case r == '\n':
+			builder.WriteString(`\n`)
+		case r == '\t':
+			builder.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&builder, `\u%04X`, r)
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	builder.WriteByte('"')
+	return builder.String()
+}
+
+func tomlQuoteStringArray(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, tomlQuoteString(value))
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+func tomlQuoteKey(key string) string {
+	for _, r := range key {
+		if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return tomlQuoteString(key)
+		}
+	}
+	if key == "" {
+		return `""`
+	}
+	return key
+}
+
+func tomlUnquoteKey(key string) string {
+	if len(key) >= 2 && (key[0] == '"' || key[0] == '\'') && key[len(key)-1] == key[0] {
+		return key[1 : len(key)-1]
+	}
+	return key
+}
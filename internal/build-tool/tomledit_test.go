@@ -0,0 +1,184 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildtool
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tomlEditSample = `# Top-level comment.
+SchemaVersion = 2 # trailing comment
+
+# The first tool.
+[bison]
+PreferredVersion = "3.8.2" # keep me
+Platforms = ["linux/amd64"]
+
+[bison.files."bison-3.8.2.tar.xz"]
+Version = "3.8.2"
+Size = 2817324
+
+[[mirror]]
+PreferredVersion = "in an array table"
+
+[flex]
+# Comment inside a table.
+PreferredVersion = '2.6.4'
+`
+
+// editTomlSample writes tomlEditSample to a file, applies edit to it, and
+// returns the file's contents afterwards.
+func editTomlSample(t *testing.T, edit func(doc *tomlDocument)) string {
+	path := filepath.Join(t.TempDir(), "sample.toml")
+	require.NoError(t, os.WriteFile(path, []byte(tomlEditSample), 0644))
+	doc, err := readTomlDocument(path)
+	require.NoError(t, err)
+	edit(doc)
+	require.NoError(t, doc.writeFile(path))
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var decoded map[string]any
+	_, err = toml.Decode(string(contents), &decoded)
+	require.NoError(t, err, "edited document is not valid TOML")
+	return string(contents)
+}
+
+func TestTomlDocumentUnchanged(t *testing.T) {
+	assert.Equal(t, tomlEditSample, editTomlSample(t, func(doc *tomlDocument) {}))
+}
+
+func TestTomlDocumentSetExisting(t *testing.T) {
+	got := editTomlSample(t, func(doc *tomlDocument) {
+		require.NoError(t, doc.setString("bison", "PreferredVersion", "3.8.3"))
+		require.NoError(t, doc.setValue("bison", "Platforms", tomlQuoteStringArray([]string{"linux/amd64", "linux/arm64"})))
+		require.NoError(t, doc.setString("flex", "PreferredVersion", "2.6.5"))
+		require.NoError(t, doc.setValue("", "SchemaVersion", "3"))
+	})
+	assert.Equal(t, `# Top-level comment.
+SchemaVersion = 3 # trailing comment
+
+# The first tool.
+[bison]
+PreferredVersion = "3.8.3" # keep me
+Platforms = ["linux/amd64", "linux/arm64"]
+
+[bison.files."bison-3.8.2.tar.xz"]
+Version = "3.8.2"
+Size = 2817324
+
+[[mirror]]
+PreferredVersion = "in an array table"
+
+[flex]
+# Comment inside a table.
+PreferredVersion = "2.6.5"
+`, got)
+}
+
+func TestTomlDocumentAddKeys(t *testing.T) {
+	got := editTomlSample(t, func(doc *tomlDocument) {
+		require.NoError(t, doc.setString("bison", "FilenameTemplate", "bison-{{ .Version }}.tar.xz"))
+		require.NoError(t, doc.setString("", "Note", "top\tlevel"))
+		require.NoError(t, doc.setString("tinygo", "PreferredVersion", "0.37.0"))
+	})
+	assert.Equal(t, `# Top-level comment.
+SchemaVersion = 2 # trailing comment
+Note = "top\tlevel"
+
+# The first tool.
+[bison]
+PreferredVersion = "3.8.2" # keep me
+Platforms = ["linux/amd64"]
+FilenameTemplate = "bison-{{ .Version }}.tar.xz"
+
+[bison.files."bison-3.8.2.tar.xz"]
+Version = "3.8.2"
+Size = 2817324
+
+[[mirror]]
+PreferredVersion = "in an array table"
+
+[flex]
+# Comment inside a table.
+PreferredVersion = '2.6.4'
+
+[tinygo]
+PreferredVersion = "0.37.0"
+`, got)
+}
+
+func TestTomlDocumentAddTable(t *testing.T) {
+	got := editTomlSample(t, func(doc *tomlDocument) {
+		doc.addTable(`bison.files."bison-3.8.3.tar.xz"`, "bison.files.", []string{
+			`Version = "3.8.3"`,
+			`Size = 1`,
+		})
+		doc.addTable("capnproto", "", []string{`PreferredVersion = "1.1.0"`})
+	})
+	assert.Equal(t, `# Top-level comment.
+SchemaVersion = 2 # trailing comment
+
+# The first tool.
+[bison]
+PreferredVersion = "3.8.2" # keep me
+Platforms = ["linux/amd64"]
+
+[bison.files."bison-3.8.2.tar.xz"]
+Version = "3.8.2"
+Size = 2817324
+
+[bison.files."bison-3.8.3.tar.xz"]
+Version = "3.8.3"
+Size = 1
+
+[[mirror]]
+PreferredVersion = "in an array table"
+
+[flex]
+# Comment inside a table.
+PreferredVersion = '2.6.4'
+
+[capnproto]
+PreferredVersion = "1.1.0"
+`, got)
+}
+
+func TestTomlDocumentUnsupportedValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "multiline.toml")
+	require.NoError(t, os.WriteFile(path, []byte("[tool]\nText = \"\"\"\nline\n\"\"\"\nList = [\n  \"a\",\n]\n"), 0644))
+	doc, err := readTomlDocument(path)
+	require.NoError(t, err)
+	assert.ErrorContains(t, doc.setString("tool", "Text", "x"), "multi-line strings")
+	assert.ErrorContains(t, doc.setValue("tool", "List", "[]"), "multi-line values")
+}
+
+func TestReadTomlDocumentMissing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "new.toml")
+	doc, err := readTomlDocument(path, "# Header.")
+	require.NoError(t, err)
+	require.NoError(t, doc.setString("tool", "Version", "1"))
+	require.NoError(t, doc.writeFile(path))
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "# Header.\n\n[tool]\nVersion = \"1\"\n", string(contents))
+}
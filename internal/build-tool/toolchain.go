@@ -19,10 +19,13 @@ package buildtool
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 
 	"github.com/BurntSushi/toml"
 )
 
+const toolchainTomlFileName = "toolchain.toml"
+
 type ToolchainTomlTopLevel struct {
 	Binaryen  *ToolchainTomlTool `toml:"binaryen"`
 	Bison     *ToolchainTomlTool `toml:"bison"`
@@ -35,8 +38,12 @@ type ToolchainTomlTopLevel struct {
 }
 
 type ToolchainTomlTool struct {
-	Executable string `toml:"Executable,omitempty"`
-	Version    string `toml:"Version,omitempty"`
+	// Executable is relative to the toolchain directory, or absolute for
+	// tools found in PATH with --prefer-system.
+	Executable string   `toml:"Executable,omitempty"`
+	Modules    []string `toml:"Modules,omitempty"`
+	Patches    []string `toml:"Patches,omitempty"`
+	Version    string   `toml:"Version,omitempty"`
 }
 
 func ReadToolchainToml(toolchainDir string) (*ToolchainTomlTopLevel, error) {
@@ -49,19 +56,97 @@ func ReadToolchainToml(toolchainDir string) (*ToolchainTomlTopLevel, error) {
 	return toolchainToml, nil
 }
 
-func WriteToolchainToml(toolchainDir string, toolchainTomlTopLevel *ToolchainTomlTopLevel) error {
+// UpdateToolchainToml updates toolchain.toml, calling update with its current
+// contents, or empty ones if there is no file yet.  Only the tables which
+// update changes are rewritten, and only the keys being set, so comments and
+// anything else added to an existing file are kept.  The toolchain.toml lock
+// is held from the read to the write, always waiting for it since it is only
+// held briefly, so that concurrent updates of different tools are not lost;
+// the file is replaced atomically, so that readers never see it half-written.
+func UpdateToolchainToml(toolchainDir string, update func(toolchainToml *ToolchainTomlTopLevel)) error {
+	unlock, err := lockToolchainToml(toolchainDir, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	toolchainToml, err := ReadToolchainToml(toolchainDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		toolchainToml = new(ToolchainTomlTopLevel)
+	}
+	tools := []struct {
+		table string
+		tool  **ToolchainTomlTool
+	}{
+		{"binaryen", &toolchainToml.Binaryen},
+		{"bison", &toolchainToml.Bison},
+		{"bpf-asm", &toolchainToml.BpfAsm},
+		{"capnproto", &toolchainToml.CapnProto},
+		{"flex", &toolchainToml.Flex},
+		{"go", &toolchainToml.Go},
+		{"go-capnp", &toolchainToml.GoCapnp},
+		{"tinygo", &toolchainToml.TinyGo},
+	}
+	// update replaces fields rather than modifying them in place, so
+	// shallow copies are enough to tell what it changed.
+	before := make([]*ToolchainTomlTool, len(tools))
+	for index, entry := range tools {
+		if *entry.tool != nil {
+			tool := **entry.tool
+			before[index] = &tool
+		}
+	}
+	update(toolchainToml)
+
 	toolchainTomlFilePath := toolchainTomlFilePathWithToolchainDir(toolchainDir)
-	fp, err := os.Create(toolchainTomlFilePath)
+	doc, err := readTomlDocument(toolchainTomlFilePath,
+		"# This file is managed by the Tempest build-tool.",
+		"# See internal/build-tool/toolchain.go",
+	)
 	if err != nil {
 		return err
 	}
-	defer fp.Close()
-	fp.WriteString("# This file is managed by the Tempest build-tool.\n")
-	fp.WriteString("# See internal/build-tool/toolchain.go\n")
-	fp.WriteString("\n")
-	return toml.NewEncoder(fp).Encode(toolchainTomlTopLevel)
+	for index, entry := range tools {
+		if *entry.tool == nil || reflect.DeepEqual(before[index], *entry.tool) {
+			continue
+		}
+		if err = setToolchainTomlTool(doc, entry.table, *entry.tool); err != nil {
+			return err
+		}
+	}
+	return doc.writeFile(toolchainTomlFilePath)
+}
+
+// setToolchainTomlTool sets the keys of tool which aren't empty in its table.
+// Patches lists every patch applied to the tool, so it is removed when empty.
+func setToolchainTomlTool(doc *tomlDocument, table string, tool *ToolchainTomlTool) error {
+	if tool.Executable != "" {
+		if err := doc.setString(table, "Executable", tool.Executable); err != nil {
+			return err
+		}
+	}
+	if tool.Modules != nil {
+		if err := doc.setValue(table, "Modules", tomlQuoteStringArray(tool.Modules)); err != nil {
+			return err
+		}
+	}
+	if len(tool.Patches) > 0 {
+		if err := doc.setValue(table, "Patches", tomlQuoteStringArray(tool.Patches)); err != nil {
+			return err
+		}
+	} else {
+		doc.deleteKey(table, "Patches")
+	}
+	if tool.Version != "" {
+		if err := doc.setString(table, "Version", tool.Version); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func toolchainTomlFilePathWithToolchainDir(toolchainDir string) string {
-	return filepath.Join(toolchainDir, "toolchain.toml")
+	return filepath.Join(toolchainDir, toolchainTomlFileName)
 }
@@ -0,0 +1,136 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildtool
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateToolchainTomlConcurrently(t *testing.T) {
+	dir := t.TempDir()
+	const updates = 20
+	var wg sync.WaitGroup
+	for _, tool := range []string{"bison", "flex"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 1; i <= updates; i++ {
+				err := UpdateToolchainToml(dir, func(toolchainToml *ToolchainTomlTopLevel) {
+					entry := &ToolchainTomlTool{Executable: tool, Version: strconv.Itoa(i)}
+					if tool == "bison" {
+						toolchainToml.Bison = entry
+					} else {
+						toolchainToml.Flex = entry
+					}
+				})
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	toolchainToml, err := ReadToolchainToml(dir)
+	require.NoError(t, err)
+	require.NotNil(t, toolchainToml.Bison)
+	require.NotNil(t, toolchainToml.Flex)
+	assert.Equal(t, strconv.Itoa(updates), toolchainToml.Bison.Version)
+	assert.Equal(t, strconv.Itoa(updates), toolchainToml.Flex.Version)
+}
+
+func TestUpdateToolchainTomlOnlyChangedTables(t *testing.T) {
+	dir := t.TempDir()
+	path := toolchainTomlFilePathWithToolchainDir(dir)
+	require.NoError(t, os.WriteFile(path, []byte(`# Kept.
+[bison]
+Executable = 'bison-3.8.2/bin/bison' # also kept
+Version = "3.8.2"
+`), 0644))
+	err := UpdateToolchainToml(dir, func(toolchainToml *ToolchainTomlTopLevel) {
+		toolchainToml.Flex = &ToolchainTomlTool{Executable: "flex-2.6.4/bin/flex", Version: "2.6.4"}
+	})
+	require.NoError(t, err)
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `# Kept.
+[bison]
+Executable = 'bison-3.8.2/bin/bison' # also kept
+Version = "3.8.2"
+
+[flex]
+Executable = "flex-2.6.4/bin/flex"
+Version = "2.6.4"
+`, string(contents))
+}
+
+// A tool built with patches which have since been removed is rebuilt once,
+// without them, and then left alone.
+func TestRemovedPatchesClearedFromToolchainToml(t *testing.T) {
+	dir := t.TempDir()
+	path := toolchainTomlFilePathWithToolchainDir(dir)
+	require.NoError(t, os.WriteFile(path, []byte(`[bison]
+Executable = "bison-3.8.2/tests/bison"
+Patches = ["0001-old.patch:0123456789abcdef"]
+Version = "3.8.2"
+`), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "bison-3.8.2", "tests"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bison-3.8.2", "tests", "bison"), nil, 0755))
+
+	bison := bootstrappers["bison"].(*archiveTool)
+	patches, err := readPatches(filepath.Join(dir, "no-patches"))
+	require.NoError(t, err)
+	err = bison.updateToolchainToml(dir, &ToolchainTomlTool{
+		Executable: "bison-3.8.2/tests/bison",
+		Patches:    patchIds(patches),
+		Version:    "3.8.2",
+	})
+	require.NoError(t, err)
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `[bison]
+Executable = "bison-3.8.2/tests/bison"
+Version = "3.8.2"
+`, string(contents))
+
+	toolchainToml, err := ReadToolchainToml(dir)
+	require.NoError(t, err)
+	directories := &runtimeConfigDirectories{DownloadDir: filepath.Join(dir, "downloads"), ToolChainDir: dir}
+	tool := &runtimeConfigTool{
+		Name:     "Bison",
+		Prefix:   "bison-",
+		patchDir: filepath.Join(dir, "no-patches"),
+	}
+	err = populateToolRuntimeConfig(tool, directories, 1, &ConfigTomlTool{}, &DownloadsTomlTool{
+		DownloadUrlTemplate: "https://example.com/{{ .Filename }}",
+		FilenameTemplate:    "bison-{{ .Version }}.tar.xz",
+		PreferredVersion:    "3.8.2",
+		Files: map[string]DownloadsTomlFile{
+			"bison-3.8.2.tar.xz": {Version: "3.8.2", Sha256: "00", Size: 1},
+		},
+	}, toolchainToml.Bison)
+	require.NoError(t, err)
+	messages, err := bison.Bootstrap(&RuntimeConfigBuildTool{Directories: directories, Bison: tool})
+	require.NoError(t, err)
+	assert.Contains(t, messages, "Skipping download and installation of Bison because "+
+		filepath.Join(dir, "bison-3.8.2", "tests", "bison")+" (from toolchain) exists")
+}
@@ -0,0 +1,217 @@
+// Tempest
+// Copyright (c) 2025 Sandstorm Development Team and contributors
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildtool
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// UpdateDownloadsCommands returns the tools which update-downloads can
+// update, i.e., those released on GitHub.
+func UpdateDownloadsCommands(downloads *DownloadsTomlTopLevel) []string {
+	commands := make([]string, 0, 3)
+	for _, toolTemplates := range getDownloadsToolTemplates(downloads) {
+		if toolTemplates.tool.GitHubRepo != "" {
+			commands = append(commands, toolTemplates.name)
+		}
+	}
+	return commands
+}
+
+// UpdateDownloads adds the files of a GitHub release of a tool to
+// downloads.toml, for every platform the tool is built for.  The release is
+// the one for version, or the latest if version is empty.  Sizes and
+// SHA-256 digests come from the release's asset list when GitHub recorded
+// them; other files are downloaded and hashed.  Files already in
+// downloads.toml are checked rather than replaced.  If prefer is set, the
+// release also becomes the tool's PreferredVersion.
+func UpdateDownloads(buildToolConfig *RuntimeConfigBuildTool, downloads *DownloadsTomlTopLevel, downloadsFilePath string, command string, version string, prefer bool) ([]string, error) {
+	messages := make([]string, 0, 5)
+	var toolTemplates *downloadsToolTemplates
+	for _, candidate := range getDownloadsToolTemplates(downloads) {
+		if candidate.name == command {
+			toolTemplates = &candidate
+			break
+		}
+	}
+	if toolTemplates == nil || toolTemplates.tool.GitHubRepo == "" {
+		return messages, fmt.Errorf("%s is not released on GitHub; update-downloads supports %s", command, strings.Join(UpdateDownloadsCommands(downloads), ", "))
+	}
+	tool := toolTemplates.tool
+	github := buildToolConfig.github
+	if github.token == "" {
+		messages = append(messages, "No GitHub token in the environment; requests are subject to GitHub's anonymous rate limit")
+	}
+
+	release := new(githubRelease)
+	var err error
+	if version == "" {
+		release, err = github.release(tool.GitHubRepo, "")
+		if err != nil {
+			return messages, err
+		}
+		version, err = versionFromTag(tool.GitHubTagTemplate, release.TagName)
+		if err != nil {
+			return messages, err
+		}
+		messages = append(messages, fmt.Sprintf("The latest release of %s is %s", tool.GitHubRepo, version))
+	} else {
+		tag, err := executeToolTemplate("githubTag", tool.GitHubTagTemplate, toolTemplateValues{Version: version})
+		if err != nil {
+			return messages, err
+		}
+		release, err = github.release(tool.GitHubRepo, tag)
+		if err != nil {
+			// Some tools, e.g., go-capnp, are only tagged, and
+			// their files are downloaded from the tag's archive.
+			messages = append(messages, fmt.Sprintf("No release of %s: %v", tool.GitHubRepo, err))
+			release = new(githubRelease)
+		}
+	}
+
+	doc, err := readTomlDocument(downloadsFilePath)
+	if err != nil {
+		return messages, err
+	}
+	platforms := tool.Platforms
+	if len(platforms) == 0 {
+		platforms = []string{"/"}
+	}
+	for _, platform := range platforms {
+		goos, goarch, _ := strings.Cut(platform, "/")
+		fileName, err := executeToolTemplate("filename", tool.FilenameTemplate, toolTemplates.filenameValues(version, goos, goarch))
+		if err != nil {
+			return messages, err
+		}
+		file, fileMessages, err := releaseFile(buildToolConfig, toolTemplates, release, fileName, version)
+		messages = append(messages, fileMessages...)
+		if err != nil {
+			return messages, err
+		}
+		file.Os, file.Arch = goos, goarch
+		if existing, ok := tool.Files[fileName]; ok {
+			if existing.Sha256 != "" && !strings.EqualFold(existing.Sha256, file.Sha256) || existing.Size != file.Size {
+				return messages, fmt.Errorf("%s: downloads.toml has SHA-256 %s and size %d, but the release has SHA-256 %s and size %d", fileName, existing.Sha256, existing.Size, file.Sha256, file.Size)
+			}
+			messages = append(messages, fmt.Sprintf("%s is already in downloads.toml", fileName))
+			continue
+		}
+		lines := []string{"Version = " + tomlQuoteString(version)}
+		if platform != "/" {
+			lines = append(lines, "Os = "+tomlQuoteString(goos), "Arch = "+tomlQuoteString(goarch))
+		}
+		lines = append(lines, "SHA-256 = "+tomlQuoteString(file.Sha256), fmt.Sprintf("Size = %d", file.Size))
+		doc.addTable(command+".files."+tomlQuoteString(fileName), command+".files.", lines)
+		messages = append(messages, fmt.Sprintf("Added %s", fileName))
+	}
+	if prefer {
+		err = doc.setString(command, "PreferredVersion", version)
+		if err != nil {
+			return messages, err
+		}
+		messages = append(messages, fmt.Sprintf("%s's PreferredVersion is now %s", command, version))
+	}
+	return messages, doc.writeFile(downloadsFilePath)
+}
+
+// releaseFile returns the size and SHA-256 of one of a release's files,
+// downloading it if its asset has no digest or it isn't an asset.
+func releaseFile(buildToolConfig *RuntimeConfigBuildTool, toolTemplates *downloadsToolTemplates, release *githubRelease, fileName string, version string) (DownloadsTomlFile, []string, error) {
+	messages := make([]string, 0, 2)
+	file := DownloadsTomlFile{Version: version}
+	asset := release.asset(fileName)
+	if asset != nil && asset.sha256() != "" {
+		file.Sha256 = asset.sha256()
+		file.Size = asset.Size
+		return file, messages, nil
+	}
+	var downloadUrl string
+	if asset != nil {
+		downloadUrl = asset.BrowserDownloadUrl
+		if buildToolConfig.github.token != "" {
+			downloadUrl = asset.Url
+		}
+	} else {
+		downloadUrlValues, err := toolTemplates.downloadUrlValues(fileName, version)
+		if err != nil {
+			return file, messages, err
+		}
+		downloadUrl, err = executeToolTemplate("downloadUrl", toolTemplates.tool.DownloadUrlTemplate, downloadUrlValues)
+		if err != nil {
+			return file, messages, err
+		}
+	}
+	// The file is downloaded to the download directory, where the next
+	// bootstrap will find it.
+	downloadDir := buildToolConfig.Directories.DownloadDir
+	err := ensureDownloadDirExists(downloadDir)
+	if err != nil {
+		return file, messages, err
+	}
+	downloadPath := filepath.Join(downloadDir, fileName)
+	var expectedFileSize int64
+	if asset != nil {
+		expectedFileSize = asset.Size
+	}
+	_, downloadMessages, err := downloadUrlToDir(buildToolConfig.github, downloadUrl, downloadDir, downloadPath, expectedFileSize)
+	messages = append(messages, downloadMessages...)
+	if err != nil {
+		return file, messages, err
+	}
+	file.Sha256, file.Size, err = sha256File(downloadPath)
+	if err != nil {
+		return file, messages, err
+	}
+	if asset != nil && asset.Size != file.Size {
+		return file, messages, fmt.Errorf("%s: downloaded %d bytes, but the release says %d", fileName, file.Size, asset.Size)
+	}
+	return file, messages, nil
+}
+
+// versionFromTag returns the version whose tag, from tagTemplate, is tag.
+func versionFromTag(tagTemplate string, tag string) (string, error) {
+	const marker = "\x00"
+	pattern, err := executeToolTemplate("githubTag", tagTemplate, toolTemplateValues{Version: marker})
+	if err != nil {
+		return "", err
+	}
+	prefix, suffix, found := strings.Cut(pattern, marker)
+	if !found || !strings.HasPrefix(tag, prefix) || !strings.HasSuffix(tag, suffix) || len(tag) <= len(prefix)+len(suffix) {
+		return "", fmt.Errorf("Tag %q does not match GitHubTagTemplate %q", tag, tagTemplate)
+	}
+	return tag[len(prefix) : len(tag)-len(suffix)], nil
+}
+
+func sha256File(filePath string) (string, int64, error) {
+	fileToHash, err := os.Open(filePath)
+	if err != nil {
+		return "", 0, err
+	}
+	defer fileToHash.Close()
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, fileToHash)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), size, nil
+}
@@ -15,12 +15,12 @@ type UserSession_credential UserSession
 const UserSession_TypeID = 0xd1dfacd26b39f071
 
 func NewUserSession(s *capnp.Segment) (UserSession, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 3})
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 3})
 	return UserSession(st), err
 }
 
 func NewRootUserSession(s *capnp.Segment) (UserSession, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 3})
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 3})
 	return UserSession(st), err
 }
 
@@ -118,12 +118,28 @@ func (s UserSession_credential) SetScopedId(v string) error {
 	return capnp.Struct(s).SetText(1, v)
 }
 
+func (s UserSession) Started() int64 {
+	return int64(capnp.Struct(s).Uint64(0))
+}
+
+func (s UserSession) SetStarted(v int64) {
+	capnp.Struct(s).SetUint64(0, uint64(v))
+}
+
+func (s UserSession) Renewed() int64 {
+	return int64(capnp.Struct(s).Uint64(8))
+}
+
+func (s UserSession) SetRenewed(v int64) {
+	capnp.Struct(s).SetUint64(8, uint64(v))
+}
+
 // UserSession_List is a list of UserSession.
 type UserSession_List = capnp.StructList[UserSession]
 
 // NewUserSession creates a new list of UserSession.
 func NewUserSession_List(s *capnp.Segment, sz int32) (UserSession_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 3}, sz)
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 16, PointerCount: 3}, sz)
 	return capnp.StructList[UserSession](l), err
 }
 
@@ -152,12 +168,12 @@ type GrainSession capnp.Struct
 const GrainSession_TypeID = 0xad4ba7eaf776f958
 
 func NewGrainSession(s *capnp.Segment) (GrainSession, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 3})
 	return GrainSession(st), err
 }
 
 func NewRootGrainSession(s *capnp.Segment) (GrainSession, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 3})
 	return GrainSession(st), err
 }
 
@@ -224,12 +240,38 @@ func (s GrainSession) SetSessionId(v []byte) error {
 	return capnp.Struct(s).SetData(1, v)
 }
 
+func (s GrainSession) AccountId() (string, error) {
+	p, err := capnp.Struct(s).Ptr(2)
+	return p.Text(), err
+}
+
+func (s GrainSession) HasAccountId() bool {
+	return capnp.Struct(s).HasPtr(2)
+}
+
+func (s GrainSession) AccountIdBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(2)
+	return p.TextBytes(), err
+}
+
+func (s GrainSession) SetAccountId(v string) error {
+	return capnp.Struct(s).SetText(2, v)
+}
+
+func (s GrainSession) Expires() int64 {
+	return int64(capnp.Struct(s).Uint64(0))
+}
+
+func (s GrainSession) SetExpires(v int64) {
+	capnp.Struct(s).SetUint64(0, uint64(v))
+}
+
 // GrainSession_List is a list of GrainSession.
 type GrainSession_List = capnp.StructList[GrainSession]
 
 // NewGrainSession creates a new list of GrainSession.
 func NewGrainSession_List(s *capnp.Segment, sz int32) (GrainSession_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2}, sz)
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 3}, sz)
 	return capnp.StructList[GrainSession](l), err
 }
 
@@ -241,27 +283,34 @@ func (f GrainSession_Future) Struct() (GrainSession, error) {
 	return GrainSession(p.Struct()), err
 }
 
-const schema_bbb10ce386c6624a = "x\xda|\x8f\xb1K\xebP\x1c\x85\xcf\xb9\xb7}}C" +
-	"\x83\x0di\x07AAD\x87\x8a-\x8a\x8b:\x88\xb8H" +
-	"\xebb\x14A\xdcbr\x91\xd0\x92\xc4\xa6X\\t\xd2" +
-	"\xcd\xc1\xc1\xbf\xc1E\x07\x17\x17\x9d\x1d\xbaYp\x14\x07" +
-	"7Ap\x13\x07\x89\xa4VZ\x8a\xb8]\x0e\xe7\xdc\xef" +
-	"\xf7en\x17\x13\xd3\x9a/ \xcc\xa1\xe4\xbfh\xf3c" +
-	"\xef\xfd\xe5|\xe5\x12\xba\xc6\xa8\xbc}w\xfc\x9c\xbe\xba" +
-	"AR\xa4\x00c\x98-#\xcf\xf85\xce\x06\x18\xed\xbe" +
-	"\xcdUZ\x17O\xf7}]\x197\x8e\xd84N\xdb\xdd" +
-	"\x93v\xf7\xbay\xb6\xf0y\xd0|\x85>\xd83\xcc\xc9" +
-	"\x14\x81\x99\x07\x8e\x124\x1e\xd9@!\xb2}\xbf\xe2\xaa" +
-	"\xa2-\xac\xc0\x0b\xe6\x97k\x96\xeb\xad\xab0t}z" +
-	"\xab\xa4\xf9_&\x80\x04\x01=\xbf\x04\x98c\x92\xe6\x94" +
-	"\xa0Nf\x19\x87\x855\xc0\x9c\x944g\x05\x0fw\xe2" +
-	"q\xc9a\x1a\x82i0\x0a\xdb\xffx%\xd0\xa1\x06A" +
-	"\x0d\xec\xc3m\x84\xaa\xf6M\xf3\x80\x0e\x8ed\xef\xfd\xf9" +
-	"-\x88\x8c\xc8R\x00z.\xa6e%\xcd\x11\xc1\xc8\xae" +
-	")Gyu\x17\xd2\xaa\xfe\x89\x92\xfd\xa8bg\x9ar" +
-	"\xadj\xaf\xe0\xc4o\x82\xe5\xae\xe0@}?P];" +
-	"\xdb\x0f\x94Sr\x00\xfcd_\x01\x00\x00\xff\xff\xac\x9e" +
-	"{\xb4"
+const schema_bbb10ce386c6624a = "x\xda\x94\x90\xb1k\x13a\x18\xc6\x9f\xe7\xfd\x12\xd3!" +
+	"\xc1\x1e\x89\x88\xab\xb8(Z*.\xea \xe2\"\xa9\x0a" +
+	"}\xad\x85h\x12\xf0\xbc\xfb\xd0\xa3\xf1\xee\xbc\xbb\xda\x0a" +
+	"b6\xff\x83\xe2\xa4\xe0\xe4\xa2C\x17E\xc4\xd5!\x9b" +
+	"\xf9\x07\x1c\x1c\x04Ap\x13\x079\xf9\xda\xd4\x96\xe8\"" +
+	"\xdf\xf2\xf2\xe3y\xbf\xf7\xc73\xff\xec\xe0\xf9\xca\xc9\xc6" +
+	"\x937\x90\xa5\xceLu_\xd9\xf9y\xff\xc7\xd7\x17\x97" +
+	"^A\x1bd\xb9p\xeb\xc3\xe3\xcf\xf5\xcdw\xa8\x9a\x1a" +
+	"\xd0\xdc\xec\x8e\x9b\xef\xbbnz\xdb\xfd\x02\x96\xf7\xbe\x9f" +
+	"Y\x19\xbf\xfc\xf4\xd1\x85e:\xbc\xd1\x1b5\x9f\xf7\xdc" +
+	"\xf4\xb4\xe7\xc2\xafG\x1b\xe7~=\x1a}\x83\x1e\xa2\xec" +
+	"\xae\x1e05\x02\xa7\xfa\xfd\xc3\x04\x9b\xb6\xbf\x86\xf2?" +
+	"\xde\xb8\x0c\x92d%\xb2s\x81\xf8i\x9c\x9e\xbd\x98\xf9" +
+	"Q\xbcd\xf3<J\x18/\x92:k*@\x85\x80\xe7" +
+	"_\x00\xb4g\xa8w\x84\x1e\xd9\xa2\x83\xf6*\xa0\xa1\xa1" +
+	"\xa6BO\xa4E\x01\xbc\xbb\x0e\x0e\x0cu]H\xd3\xa2" +
+	"\x01\xbcU\xb7\x9d\x1a\xeaC\xe1\xf0\xb6\xbb\xd2\x0eY\x87" +
+	"\xb0\x0e\x96\xf9\xd6\xc1\xb8\x0d\x86l@\xd8\x00K?\x08" +
+	"\x92\xd5\xb8\xd8b\x93\xdc\xd0\xae\xa7QfsV!\xac" +
+	"\x82S\xee\xcb\xb9\xcd\xb6\xd5c`\xe2NrOs\x9e" +
+	"\x7f\x032;\xb1\xbc\xe2,/\x1bjGH\xd9\xb6\\" +
+	"v\x96\x8b\x86\xda\x13z\x86-V\x00\xef\xba\x83\xd7\x0c" +
+	"\xf5\xa6\xb0\x0c2\x1b\xda\xb8\x88`\xfc\xc1\xbf\xbc\x87y" +
+	"\xe1g\x85\x0dw\x1c\x87\x99\x8d\xed\x9a\x0d\xffr6\xd3" +
+	"\xces\x93\xafk\x91?\xd0\x99?\xb5\x1f=\x06\xe8\x11" +
+	"C\x9d\xdfS\xfb\x89\x05@\x8f\x1b\xeai\xe1\xfe\xe2A" +
+	"jw\xab\x0c\x92\xd4\x86\xed\x10\xc0\x0e\xfb=\x00\x11_" +
+	"\xb3\xcb"
 
 func RegisterSchema(reg *schemas.Registry) {
 	reg.Register(&schemas.Schema{
@@ -15,15 +15,18 @@ type LaunchCommand_Which uint16
 const (
 	LaunchCommand_Which_continueGrain LaunchCommand_Which = 0
 	LaunchCommand_Which_initGrain     LaunchCommand_Which = 1
+	LaunchCommand_Which_upgradeGrain  LaunchCommand_Which = 2
 )
 
 func (w LaunchCommand_Which) String() string {
-	const s = "continueGraininitGrain"
+	const s = "continueGraininitGrainupgradeGrain"
 	switch w {
 	case LaunchCommand_Which_continueGrain:
 		return s[0:13]
 	case LaunchCommand_Which_initGrain:
 		return s[13:22]
+	case LaunchCommand_Which_upgradeGrain:
+		return s[22:34]
 
 	}
 	return "LaunchCommand_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
@@ -95,6 +98,18 @@ func (s LaunchCommand) SetInitGrain(v uint32) {
 	capnp.Struct(s).SetUint32(4, v)
 }
 
+func (s LaunchCommand) UpgradeGrain() uint32 {
+	if capnp.Struct(s).Uint16(0) != 2 {
+		panic("Which() != upgradeGrain")
+	}
+	return capnp.Struct(s).Uint32(4)
+}
+
+func (s LaunchCommand) SetUpgradeGrain(v uint32) {
+	capnp.Struct(s).SetUint16(0, 2)
+	capnp.Struct(s).SetUint32(4, v)
+}
+
 // LaunchCommand_List is a list of LaunchCommand.
 type LaunchCommand_List = capnp.StructList[LaunchCommand]
 
@@ -112,18 +127,20 @@ func (f LaunchCommand_Future) Struct() (LaunchCommand, error) {
 	return LaunchCommand(p.Struct()), err
 }
 
-const schema_d7e3ca8e87d116b7 = "x\xda\x12Hv`1\xe4\xdd\xcf\xc8\xc0\x14(\xc2\xca" +
+const schema_d7e3ca8e87d116b7 = "x\xda\x13(v`1\xe4\xdd\xcf\xc8\xc0\x14(\xc2\xca" +
 	"\xf6?\xa6\xe2@\xfb5/\xb3'\x0c\x81B\x8c\x8c\xff" +
-	"\xb7\x8b]l\xef;\xf5\xf8:\x83\x0b;\x13\x03\x83\xe1" +
-	"Q&F\xc1\x8b\xec\x0c\x0c\x82g\xcb\x19t\xff\xa7\x17" +
-	"%f\xe6\xe9&\xa63\xa7\xe6\x95\xe8%'\x16\xe4\x15" +
-	"X\xf9$\x96\xe6%g8\xe7\xe7\xe6&\xe6\xa50\x04" +
-	"02\x06r0\xb3\xf0\xfc\xff\xcf\xc2\xc8\xc0 \xa8Y" +
-	"\xc4\xc0\x10\xa8\xc1\xcc\x18h\xc2\xc4(\xcb\xf8\xef?\xa3" +
-	"\x08#H\xd80\x88\x81!\xd0\x80\x991\xd0\x86\x89\xf1" +
-	"\x7fr~^If^i*\x83\xbc;\xc8t\x06\xb6" +
-	"\xff\x99y\x99% 6\x03c\x1e#\x07\x03\x13#\x07" +
-	"\x03# \x00\x00\xff\xff\x14\x002,"
+	"\xb7\x8b]l\xef;\xf5\xf8:\x83\x0b;3\x03\x83\xe1" +
+	"Q&F\xc1\x8b\xec\x0c\x0c\x82g\xd73\xe8\xfeO/" +
+	"J\xcc\xcc\xd3MLgN\xcd+\xd1KN,\xc8+" +
+	"\xb0\xf2I,\xcdK\xcep\xce\xcf\xcdM\xccKa\x08" +
+	"`d\x0c\xe4af\xe1\xf9\xff\x9f\x85\x11\xa8\xc9\xb5\x88" +
+	"\x81!\xd0\x85\x9910\x80\x89Q\x96\xf1\xdf\x7fF\x11" +
+	"F\x90\xb0o\x10P\xd8\x07(\x1c\x01\x14f\xfa\x0b\x12" +
+	"f\x02\x0a\x87f\x01\x85C\x80\xc2\x09L\x8c\xff\x93\xf3" +
+	"\xf3J2\xf3JS\x19\xe4\xddA\x962\xb0\xfd\xcf\xcc" +
+	"\xcb,\x01\xb1\x19\x18\xf3\x189\x18\x98\x80\x98\xf1\x7fi" +
+	"\x01\xd0I)\xa9\xee\x0c\xfc E0a\x00>o=" +
+	"}"
 
 func RegisterSchema(reg *schemas.Registry) {
 	reg.Register(&schemas.Schema{
@@ -16,15 +16,36 @@ type SystemObjectId_Which uint16
 const (
 	SystemObjectId_Which_emailLoginToken SystemObjectId_Which = 0
 	SystemObjectId_Which_sharingToken    SystemObjectId_Which = 1
+	SystemObjectId_Which_ipNetwork       SystemObjectId_Which = 2
+	SystemObjectId_Which_rpcLogin        SystemObjectId_Which = 3
+	SystemObjectId_Which_identity        SystemObjectId_Which = 4
+	SystemObjectId_Which_httpApi         SystemObjectId_Which = 5
+	SystemObjectId_Which_ipInterface     SystemObjectId_Which = 6
+	SystemObjectId_Which_thumbnailer     SystemObjectId_Which = 7
+	SystemObjectId_Which_turn            SystemObjectId_Which = 8
 )
 
 func (w SystemObjectId_Which) String() string {
-	const s = "emailLoginTokensharingToken"
+	const s = "emailLoginTokensharingTokenipNetworkrpcLoginidentityhttpApiipInterfacethumbnailerturn"
 	switch w {
 	case SystemObjectId_Which_emailLoginToken:
 		return s[0:15]
 	case SystemObjectId_Which_sharingToken:
 		return s[15:27]
+	case SystemObjectId_Which_ipNetwork:
+		return s[27:36]
+	case SystemObjectId_Which_rpcLogin:
+		return s[36:44]
+	case SystemObjectId_Which_identity:
+		return s[44:52]
+	case SystemObjectId_Which_httpApi:
+		return s[52:59]
+	case SystemObjectId_Which_ipInterface:
+		return s[59:70]
+	case SystemObjectId_Which_thumbnailer:
+		return s[70:81]
+	case SystemObjectId_Which_turn:
+		return s[81:85]
 
 	}
 	return "SystemObjectId_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
@@ -182,6 +203,101 @@ func (s SystemObjectId_sharingToken) SetGrainId(v string) error {
 	return capnp.Struct(s).SetText(2, v)
 }
 
+func (s SystemObjectId) SetIpNetwork() {
+	capnp.Struct(s).SetUint16(0, 2)
+
+}
+
+func (s SystemObjectId) RpcLogin() (string, error) {
+	if capnp.Struct(s).Uint16(0) != 3 {
+		panic("Which() != rpcLogin")
+	}
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.Text(), err
+}
+
+func (s SystemObjectId) HasRpcLogin() bool {
+	if capnp.Struct(s).Uint16(0) != 3 {
+		return false
+	}
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s SystemObjectId) RpcLoginBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s SystemObjectId) SetRpcLogin(v string) error {
+	capnp.Struct(s).SetUint16(0, 3)
+	return capnp.Struct(s).SetText(0, v)
+}
+
+func (s SystemObjectId) Identity() (string, error) {
+	if capnp.Struct(s).Uint16(0) != 4 {
+		panic("Which() != identity")
+	}
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.Text(), err
+}
+
+func (s SystemObjectId) HasIdentity() bool {
+	if capnp.Struct(s).Uint16(0) != 4 {
+		return false
+	}
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s SystemObjectId) IdentityBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s SystemObjectId) SetIdentity(v string) error {
+	capnp.Struct(s).SetUint16(0, 4)
+	return capnp.Struct(s).SetText(0, v)
+}
+
+func (s SystemObjectId) HttpApi() (string, error) {
+	if capnp.Struct(s).Uint16(0) != 5 {
+		panic("Which() != httpApi")
+	}
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.Text(), err
+}
+
+func (s SystemObjectId) HasHttpApi() bool {
+	if capnp.Struct(s).Uint16(0) != 5 {
+		return false
+	}
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s SystemObjectId) HttpApiBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s SystemObjectId) SetHttpApi(v string) error {
+	capnp.Struct(s).SetUint16(0, 5)
+	return capnp.Struct(s).SetText(0, v)
+}
+
+func (s SystemObjectId) SetIpInterface() {
+	capnp.Struct(s).SetUint16(0, 6)
+
+}
+
+func (s SystemObjectId) SetThumbnailer() {
+	capnp.Struct(s).SetUint16(0, 7)
+
+}
+
+func (s SystemObjectId) SetTurn() {
+	capnp.Struct(s).SetUint16(0, 8)
+
+}
+
 // SystemObjectId_List is a list of SystemObjectId.
 type SystemObjectId_List = capnp.StructList[SystemObjectId]
 
@@ -210,26 +326,37 @@ func (f SystemObjectId_sharingToken_Future) Struct() (SystemObjectId_sharingToke
 	return SystemObjectId_sharingToken(p.Struct()), err
 }
 
-const schema_a9980bd0b9075eb0 = "x\xda\\\x90\xbdJ+Q\x14\x85\xd7:g\xee\x9d\x0b" +
-	"wb\x1c\x12\xd1BllT0\x18\xb0\x10+\x15S" +
-	"$\x08\xe6\xa0\xa0\x88\x8a\x93\xcc\x90L4gBfP" +
-	"\xac\x04_\xc1BK\xdb4\xfe\x81\x85o\xe13\xf8\x06" +
-	"j\xadG\xe2_\xc4n\xaf\xbd\x8b\xfd}\xab\xff|\xce" +
-	"\xca\xa7\x06\x05\x84\x1a\xfa\xf3\xd7\x94f6\xa6\xf6GN" +
-	"\x9f\xa0\x86Is1\x7f\xb26zSx\xc6\x80\xb4\x09" +
-	"\xe4\x1fK\x04\xdd\x97K\xfc8\xa9\x14i\xae\xb6\xed\xbb" +
-	"\xfb\xffg\x1d\x14\xa4-\x80L\x87\x0f\x99[\xda@\xe6" +
-	"\x9a\x07\x984\xf1a\x9c\x04\xcd\\\xd5\xf2Z\xba5\xbb" +
-	"\xf2\x9e\x96+\x8d\xa0\x9a\x14\xfd\\\\\xf7\xda\xa1\xae\xad" +
-	"F\xbb\x81\x06\x94#- K\x02n\xa1\x02\xa8EI" +
-	"\xb5#\xe8\x92Y\x0a\xc0\xdd\x9a\x00\xd4\xba\xa4\xf2\x05]" +
-	"!\xb2\x94\x80\xeb-\x00jSR\xd5\x05M+h7" +
-	"\xc38\x0eaG:f\x1fX\x96$!\xbacZG" +
-	"I@\x07\x82\x0exTk{\xa1.\xfa_\xf9\x9bS" +
-	"\xfc\xe6\xb4\x93\xa2_&\xd5?i9\xc6X]\xb8\xf1" +
-	"c@\x8dI\xaai\xc1\x14_\x0d\xd9k\xcf\xcd7 " +
-	"L\xd0\xf4\xc2\xbd\xa5\xa8\xc6P\x7f\xc8\xf5\xfe|*#" +
-	"\xdd\xdd\xbf\x05\x00\x00\xff\xff\xf3\x1cd@"
+const schema_a9980bd0b9075eb0 = "x\xda\x8d\x91\xbdn\x13A\x14\x85\xef\x9d]\xef\x1a\xb0" +
+	"1\x96\x0d\x15\xa2\x81\x8a\"\x0a\xa2AT\x04\xc5\x85-" +
+	"\x04Y\x1b\xc9\x16\x02\xc4\xda\x1e\xecI\xe2\xd9\xd5\xee$" +
+	"(U$^\x81\x02Z\xca\x14@D\xc7;P\xf0\x08" +
+	"@ED\x90\xf8ID\x02\x0e\xc3Y~\xe2\x88\x0am" +
+	"\xb3\xf7;\xe7\xce\xdc9w\xfa\xe5\x89K\xee\xb9\xa2\xbf" +
+	"N\xa2\x99\xcfy\xb6q\xe1\xc6\xf4\xf2\xa9\x87\x9f)8" +
+	"\xc9l\x9f\xce<h\x9f~^\xfbB\xc7\x1d\x9f\x89\xce" +
+	"o\xb4\x1bL\\\xd9n?\xa3\x03bP\x84u\xfd\xb6" +
+	"\xff\xe2\xd5\x91GkTs\xfcCD\x95\xc7\x9d\xb7\x95" +
+	"'\x1d\x1fMk\x1d\xcbd\xff\xfb{o\xd3\x95\xd4\xc8" +
+	"\xd1T\xcf\x0dc\x1d_l\xfd\xaa\xaeu\xe7e\xcf\xd4" +
+	"\xfbS\xe90L\x94\x1e\\\x8f\x16\xa4&\x0a\x0a\x8eK" +
+	"Te\x0cW\xaeuQ\xcf:\x1c\xdc\x11\\f\xae\xb2" +
+	"\x00\xbcu\x16\xb0\x03\xd8\x07\x14\xa2\xca\x0e`x\x19\xf0" +
+	"&\xe0P\xb0\x8de2Ri\xaa\xc8\x8ft\xcaG\x89" +
+	"\xe7\x1c\xc6y\"\xfb-\xe9\xc8H.\xa0(\x10\xaf\x0e" +
+	"\x92P\xe9z\xffo\xbd?\xa7\xf8wN\x1f\x83\xce1" +
+	"\x07g\x1c\xb7`\xad\x9b\x0d\xf7\xe1>\xae\xdc\xc4\x95;" +
+	"\x82\x8b\xfc\xc3\"\xb1\xfd\xa8\xcb\xdb\xf3$\x8ab\xcfV" +
+	"\x19\x8f)\xbfi\xc2\xfa\x1a\xd6MX\x9d1h\x0et" +
+	"\xa3\x01\xfa\x0et\x0b\xd4\xfd\x0e\xea\x81~\xca\xe8G\xd0" +
+	"1h\xee\x1b(\x12/\xeff\xef\xdbr\xb8\xc9\xa0\xde" +
+	".h\x1et/\xcbg\xecp+\x9fa\x7f\x078[" +
+	"T\x8e\xc1[.C8\x96\x09\xf9\xaf\x10\x0eC(2" +
+	"\xb2\x83\x19\x02\xb2d+G\xa1Z\xbc\x12\x0dX\xe9\xdf" +
+	"\xe9O\x82\xf8\xb3\x13*e\xdc\xaa\xf8\xaa4\xf7\xa2\x84" +
+	"x\x81<\x9b\xc4=4),k\xd2\xa0\xfaR\x1be" +
+	"V\x0e\xb0\xd5\xa11\xf1L\xac&\x9e\xb8\xae\x8dL\xee" +
+	"\x92\x1f\xf6$\xce1\xc3\xa5QW\x87\xd8\xd3\xa2L\xc8" +
+	"+\x99\xa5D\x93\xf7\x13\\\xef\xc12"
 
 func RegisterSchema(reg *schemas.Registry) {
 	reg.Register(&schemas.Schema{
@@ -0,0 +1,176 @@
+// Package apierror defines the errors returned to the shell by the server's
+// APIs.
+//
+// Cap'n Proto exceptions only carry a type and a reason string, so the
+// shell-facing methods which can fail with an Error return it in an `error`
+// result, an external.ApiError, instead; see Encode and Check. The browser
+// displays a localized message chosen by the Code. Other failures are raised
+// as exceptions, which FromError treats as internal errors.
+package apierror
+
+import (
+	"errors"
+
+	"sandstorm.org/go/tempest/capnp/external"
+)
+
+// A Code identifies a kind of error. Codes are stable, since the browser uses
+// them as keys for localized messages.
+type Code string
+
+const (
+	// Something unexpected went wrong on the server.
+	CodeInternal Code = "internal"
+
+	// The caller needs to log in first.
+	CodeNotLoggedIn Code = "not-logged-in"
+
+	// The caller is logged in, but isn't allowed to do this.
+	CodePermissionDenied Code = "permission-denied"
+
+	// The object doesn't exist, or no longer does. Params[0] describes
+	// what wasn't found.
+	CodeNotFound Code = "not-found"
+
+	// An argument was invalid. Params[0] names the argument.
+	CodeInvalidArgument Code = "invalid-argument"
+
+	// The server doesn't support this yet.
+	CodeUnimplemented Code = "unimplemented"
+
+	// A service the server depends on, e.g., the mail server, failed.
+	// Params[0] names the service.
+	CodeUnavailable Code = "unavailable"
+
+	// The caller has done this too often recently, and should wait before
+	// trying again.
+	CodeRateLimited Code = "rate-limited"
+
+	// The caller's grains already use all of their storage quota.
+	CodeQuotaExceeded Code = "quota-exceeded"
+
+	// A grain's new app version failed to start after upgrading, so the
+	// grain was rolled back to the old one. Params[0] is the grain's
+	// title.
+	CodeUpgradeFailed Code = "upgrade-failed"
+)
+
+// An Error is an error returned to the shell.
+type Error struct {
+	Code Code
+
+	// Params are substituted into the localized message, as %0, %1 and so
+	// on; see intl.L10N.Fmt. Each code documents its params.
+	Params []string
+
+	// Retryable is true if trying again later might succeed.
+	Retryable bool
+
+	// Message describes the error in English, for logs and for clients
+	// which don't know the code.
+	Message string
+}
+
+// New returns an Error with the given code, message and params.
+func New(code Code, message string, params ...string) *Error {
+	return &Error{
+		Code:    code,
+		Params:  params,
+		Message: message,
+	}
+}
+
+// WithRetry returns a copy of e which may be retried.
+func (e *Error) WithRetry() *Error {
+	ret := *e
+	ret.Retryable = true
+	return &ret
+}
+
+// Error returns the message.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// FromError returns the Error wrapped by err. If there is none, it returns an
+// internal error with err's message.
+func FromError(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return New(CodeInternal, err.Error())
+}
+
+// Encode stores e in to, e.g. the `error` result of a method.
+func (e *Error) Encode(to external.ApiError) error {
+	if err := to.SetCode(string(e.Code)); err != nil {
+		return err
+	}
+	params, err := to.NewParams(int32(len(e.Params)))
+	if err != nil {
+		return err
+	}
+	for i, p := range e.Params {
+		if err := params.Set(i, p); err != nil {
+			return err
+		}
+	}
+	to.SetRetryable(e.Retryable)
+	return to.SetDescription(e.Message)
+}
+
+// Decode returns the Error stored in from.
+func Decode(from external.ApiError) (*Error, error) {
+	code, err := from.Code()
+	if err != nil {
+		return nil, err
+	}
+	list, err := from.Params()
+	if err != nil {
+		return nil, err
+	}
+	var params []string
+	for i := 0; i < list.Len(); i++ {
+		p, err := list.At(i)
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, p)
+	}
+	msg, err := from.Description()
+	if err != nil {
+		return nil, err
+	}
+	return &Error{
+		Code:      Code(code),
+		Params:    params,
+		Retryable: from.Retryable(),
+		Message:   msg,
+	}, nil
+}
+
+// Results are the results of a method with an `error` result.
+type Results interface {
+	HasError() bool
+	Error() (external.ApiError, error)
+}
+
+// Check returns res and err, unless res holds an error, which it returns
+// instead. It is meant to wrap a future's Struct method:
+//
+//	res, err := apierror.Check(fut.Struct())
+func Check[R Results](res R, err error) (R, error) {
+	if err != nil || !res.HasError() {
+		return res, err
+	}
+	from, err := res.Error()
+	if err != nil {
+		return res, err
+	}
+	e, err := Decode(from)
+	if err != nil {
+		return res, err
+	}
+	return res, e
+}
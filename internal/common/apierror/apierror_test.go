@@ -0,0 +1,83 @@
+package apierror
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"capnproto.org/go/capnp/v3"
+	"github.com/stretchr/testify/require"
+	"sandstorm.org/go/tempest/capnp/external"
+	"sandstorm.org/go/tempest/internal/browser/intl"
+)
+
+func TestRoundTrip(t *testing.T) {
+	cases := []*Error{
+		New(CodeNotLoggedIn, "you are not logged in"),
+		New(CodeNotFound, "no such grain", "grain"),
+		New(CodeInvalidArgument, "bad value: a&b=c [x]", "actionIndex", "7").WithRetry(),
+	}
+	for _, c := range cases {
+		testCase := c
+		t.Run(string(c.Code), func(t *testing.T) {
+			_, seg := capnp.NewSingleSegmentMessage(nil)
+			res, err := external.NewRootAdminSession_setRole_Results(seg)
+			require.NoError(t, err)
+			to, err := res.NewError()
+			require.NoError(t, err)
+			require.NoError(t, testCase.Encode(to))
+
+			_, err = Check(res, nil)
+			var got *Error
+			require.ErrorAs(t, err, &got)
+			require.Equal(t, testCase.Code, got.Code)
+			require.Equal(t, testCase.Params, got.Params)
+			require.Equal(t, testCase.Retryable, got.Retryable)
+			require.Equal(t, testCase.Message, got.Message)
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	_, seg := capnp.NewSingleSegmentMessage(nil)
+	res, err := external.NewRootAdminSession_setRole_Results(seg)
+	require.NoError(t, err)
+	_, err = Check(res, nil)
+	require.NoError(t, err, "results without an error")
+
+	failed := errors.New("rpc: disconnected")
+	_, err = Check(res, failed)
+	require.Same(t, failed, err)
+}
+
+func TestFromErrorWrapped(t *testing.T) {
+	e := New(CodeUnavailable, "sending mail", "mail")
+	require.Same(t, e, FromError(fmt.Errorf("wrapped: %w", e)))
+}
+
+func TestFromErrorUntyped(t *testing.T) {
+	got := FromError(errors.New("something broke"))
+	require.Equal(t, CodeInternal, got.Code)
+	require.Equal(t, "something broke", got.Message)
+	require.False(t, got.Retryable)
+}
+
+func TestLocalize(t *testing.T) {
+	german := intl.L10N{FmtStrings: map[intl.L10NString]intl.L10NString{
+		Messages[CodeNotFound]:        "%0 nicht gefunden.",
+		"Something went wrong: %0":    "Etwas ist schiefgegangen: %0",
+		Messages[CodeNotLoggedIn]:     "Bitte melden Sie sich an.",
+		Messages[CodeInvalidArgument]: "Ungültiger Wert: %0",
+	}}
+	require.Equal(t, "grain nicht gefunden.",
+		New(CodeNotFound, "no such grain", "grain").Localize(german))
+	require.Equal(t, "Bitte melden Sie sich an.",
+		New(CodeNotLoggedIn, "not logged in").Localize(german))
+	require.Equal(t, "Tempest received an invalid title.",
+		New(CodeInvalidArgument, "empty title", "title").Localize(intl.L10N{}))
+	// Too few params, and internal errors, fall back to the message.
+	require.Equal(t, "Etwas ist schiefgegangen: bad value",
+		New(CodeInvalidArgument, "bad value").Localize(german))
+	require.Equal(t, "Something went wrong: disk full",
+		New(CodeInternal, "disk full").Localize(intl.L10N{}))
+}
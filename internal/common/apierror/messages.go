@@ -0,0 +1,45 @@
+package apierror
+
+import "sandstorm.org/go/tempest/internal/browser/intl"
+
+// Messages are the messages shown to users for each Code, by the browser
+// and on the server's error pages. %0, %1 and so on are replaced by the
+// error's params.
+var Messages = map[Code]intl.L10NString{
+	CodeNotLoggedIn:      "You need to log in to do that.",
+	CodePermissionDenied: "You don't have permission to do that.",
+	CodeNotFound:         "The %0 could not be found. It may have expired or been deleted.",
+	CodeInvalidArgument:  "Tempest received an invalid %0.",
+	CodeUnimplemented:    "Tempest doesn't support that yet.",
+	CodeUnavailable:      "Tempest could not reach its %0 service.",
+	CodeRateLimited:      "You're doing that too often; please wait a while.",
+	CodeQuotaExceeded:    "You've used all of your storage. Delete some grains to make room.",
+	CodeUpgradeFailed:    "The new version of the app failed to start, so %0 was left at the old version.",
+}
+
+// Localize returns the error's message for users, in l10n's locale. Internal
+// errors, codes without a message, and errors with too few params for their
+// message fall back to the English Message.
+func (e *Error) Localize(l10n intl.L10N) string {
+	if f, ok := Messages[e.Code]; ok && len(e.Params) >= paramsNeeded(f) {
+		return l10n.Fmt(f, e.Params...)
+	}
+	return l10n.Fmt("Something went wrong: %0", e.Message)
+}
+
+// paramsNeeded returns how many params the format string f uses, so that a
+// peer sending too few doesn't make intl.L10N.Fmt panic.
+func paramsNeeded(f intl.L10NString) int {
+	n := 0
+	for i := 0; i+1 < len(f); i++ {
+		if f[i] != '%' {
+			continue
+		}
+		c := f[i+1]
+		if c >= '0' && c <= '9' && int(c-'0')+1 > n {
+			n = int(c-'0') + 1
+		}
+		i++
+	}
+	return n
+}
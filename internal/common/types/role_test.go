@@ -38,3 +38,30 @@ func TestRoleEncompasses(t *testing.T) {
 		})
 	}
 }
+
+func TestAdminScopesAllows(t *testing.T) {
+	support := AdminScopes{AdminScopeUsers, AdminScopeAudit}
+	require.True(t, support.Allows(AdminScopeUsers, true))
+	require.True(t, support.Allows(AdminScopeInfrastructure, false),
+		"Audit should allow reading everything")
+	require.False(t, support.Allows(AdminScopeInfrastructure, true),
+		"Audit should not allow changing anything")
+
+	moderator := AdminScopes{AdminScopeApps}
+	require.True(t, moderator.Allows(AdminScopeApps, true))
+	require.False(t, moderator.Allows(AdminScopeUsers, false))
+	require.False(t, AdminScopes(nil).Allows(AdminScopeApps, false))
+}
+
+func TestParseAdminScopes(t *testing.T) {
+	scopes, err := ParseAdminScopes("support, infrastructure,audit")
+	require.NoError(t, err)
+	require.Equal(t, AdminScopes{AdminScopeUsers, AdminScopeAudit, AdminScopeInfrastructure}, scopes)
+
+	scopes, err = ParseAdminScopes("")
+	require.NoError(t, err)
+	require.Empty(t, scopes)
+
+	_, err = ParseAdminScopes("users,root")
+	require.Error(t, err)
+}
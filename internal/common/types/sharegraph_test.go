@@ -0,0 +1,43 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShareGraphRevoked(t *testing.T) {
+	share := func(from, to AccountID) GrainShare {
+		return GrainShare{SharedBy: from, AccountID: to}
+	}
+	// The owner shared with alice and bob, who each shared with carol;
+	// alice also shared with dave, and dave and erin with each other.
+	// frank got access before the grain was imported.
+	g := ShareGraph{
+		Owner:   "owner",
+		Members: []AccountID{"alice", "bob", "carol", "dave", "erin", "frank"},
+		Shares: []GrainShare{
+			share("owner", "alice"),
+			share("owner", "bob"),
+			share("alice", "carol"),
+			share("bob", "carol"),
+			share("alice", "dave"),
+			share("dave", "erin"),
+			share("erin", "dave"),
+		},
+	}
+	// carol still has access through bob, but dave and erin only
+	// share with each other:
+	require.Equal(t, []AccountID{"alice", "dave", "erin"}, g.Revoked("alice"))
+	require.Equal(t, []AccountID{"bob"}, g.Revoked("bob"))
+	require.Equal(t, []AccountID{"carol"}, g.Revoked("carol"))
+	require.Equal(t, []AccountID{"erin"}, g.Revoked("erin"))
+	require.Equal(t, []AccountID{"frank"}, g.Revoked("frank"))
+	require.Nil(t, g.Revoked("owner"))
+	require.Nil(t, g.Revoked("nobody"))
+
+	// frank's shares count, though no one shared with frank, so now erin
+	// and dave keep their access:
+	g.Shares = append(g.Shares, share("frank", "erin"))
+	require.Equal(t, []AccountID{"alice"}, g.Revoked("alice"))
+}
@@ -2,6 +2,13 @@
 // that are used in multiple subsystems.
 package types
 
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
 // An ID is a string identifier. The type parameter is a phantom type, to
 // prevent mixing up identifiers for different types of objects.
 type ID[T any] string
@@ -42,6 +49,15 @@ const (
 
 	// Email login.
 	EmailCredential CredentialType = "email"
+
+	// SAML single sign-on. The scoped ID is the subject's NameID, or the
+	// value of the attribute configured by SAML_NAME_ATTRIBUTE.
+	SAMLCredential CredentialType = "saml"
+
+	// Temporary demo accounts, made for visitors who want to try the
+	// server. The scoped ID is random; the account can only be used
+	// through the session started when it was made.
+	DemoCredential CredentialType = "demo"
 )
 
 type Role string
@@ -81,3 +97,508 @@ func (r Role) Encompasses(other Role) bool {
 		panic("impossible")
 	}
 }
+
+// An AdminScope is an area of server administration. Accounts with RoleAdmin
+// have every scope; other accounts may be granted some of them, so that e.g.
+// support staff can manage users without being able to change the server.
+type AdminScope string
+
+const (
+	// Accounts, and which admin scopes they have.
+	AdminScopeUsers AdminScope = "users"
+	// Installed apps, and their use of deprecated APIs.
+	AdminScopeApps AdminScope = "apps"
+	// The server itself: grain start times, metrics and the like.
+	AdminScopeInfrastructure AdminScope = "infrastructure"
+	// Read-only access to every other scope.
+	AdminScopeAudit AdminScope = "audit"
+)
+
+// AllAdminScopes lists every AdminScope, in the order they are displayed.
+var AllAdminScopes = []AdminScope{
+	AdminScopeUsers,
+	AdminScopeApps,
+	AdminScopeInfrastructure,
+	AdminScopeAudit,
+}
+
+// AdminScopePresets are common sets of scopes, for granting by name.
+var AdminScopePresets = map[string][]AdminScope{
+	"support":   {AdminScopeUsers, AdminScopeAudit},
+	"moderator": {AdminScopeApps, AdminScopeAudit},
+	"billing":   {AdminScopeAudit},
+}
+
+func (s AdminScope) IsValid() bool {
+	for _, scope := range AllAdminScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// AdminScopes is the set of scopes an account has.
+type AdminScopes []AdminScope
+
+// Has returns true if scope is in the set.
+func (s AdminScopes) Has(scope AdminScope) bool {
+	for _, have := range s {
+		if have == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows returns true if the scopes allow access to scope. The audit scope
+// allows access to everything, but only if write is false.
+func (s AdminScopes) Allows(scope AdminScope, write bool) bool {
+	return s.Has(scope) || (!write && s.Has(AdminScopeAudit))
+}
+
+// ParseAdminScopes parses a comma-separated list of scope and preset names,
+// e.g. "support,infrastructure".
+func ParseAdminScopes(str string) (AdminScopes, error) {
+	var ret AdminScopes
+	add := func(scope AdminScope) {
+		if !ret.Has(scope) {
+			ret = append(ret, scope)
+		}
+	}
+	for _, name := range strings.Split(str, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if preset, ok := AdminScopePresets[name]; ok {
+			for _, scope := range preset {
+				add(scope)
+			}
+		} else if scope := AdminScope(name); scope.IsValid() {
+			add(scope)
+		} else {
+			return nil, fmt.Errorf("unknown admin scope %q", name)
+		}
+	}
+	return ret, nil
+}
+
+// A GrainEventKind is a kind of notable event in a grain's life, as shown in
+// the grain's timeline.
+type GrainEventKind string
+
+const (
+	GrainCreated   GrainEventKind = "created"
+	GrainStarted   GrainEventKind = "started"
+	GrainCrashed   GrainEventKind = "crashed"
+	GrainRestarted GrainEventKind = "restarted" // Started after crashing
+	GrainShared    GrainEventKind = "shared"
+	GrainJoined    GrainEventKind = "joined"   // A user opened a share link
+	GrainEmbedded  GrainEventKind = "embedded" // Embed URL created
+	GrainBackedUp  GrainEventKind = "backed-up"
+	GrainRestored  GrainEventKind = "restored" // Created from a backup
+
+	// The owner revoked a member's access.
+	GrainMemberRemoved GrainEventKind = "member-removed"
+
+	// A job the grain scheduled was disabled after failing repeatedly;
+	// the detail is the job's name.
+	GrainJobDisabled GrainEventKind = "job-disabled"
+
+	// The grain was upgraded to a newer version of its app; the detail is
+	// the new version's appMarketingVersion.
+	GrainMigrated GrainEventKind = "migrated"
+
+	// The grain failed to start after being upgraded, and was returned to
+	// the version of its app it had before; the detail is the
+	// appMarketingVersion it failed to start with.
+	GrainUpgradeRolledBack GrainEventKind = "upgrade-rolled-back"
+)
+
+// A GrainEvent is an entry in a grain's timeline. The JSON encoding is what
+// the server sends to the browser.
+type GrainEvent struct {
+	GrainID GrainID        `json:"-"`
+	Kind    GrainEventKind `json:"kind"`
+	Time    time.Time      `json:"time"`
+
+	// Detail adds to the Kind, e.g. the app version a grain migrated to.
+	// It may be empty.
+	Detail string `json:"detail,omitempty"`
+}
+
+// A GrainUpgrade is a newer version of a grain's app, which the grain's owner
+// may upgrade it to. The JSON encoding is what the server sends to the
+// browser.
+type GrainUpgrade struct {
+	GrainID  GrainID `json:"grainId"`
+	AppID    string  `json:"appId"`
+	AppTitle string  `json:"appTitle"`
+
+	// The appVersions of the package the grain runs, and of the newest
+	// installed package of the same app, from their manifests.
+	FromVersion uint32 `json:"fromVersion"`
+	ToVersion   uint32 `json:"toVersion"`
+
+	// The newer package's appMarketingVersion, for display.
+	ToMarketingVersion string `json:"toMarketingVersion"`
+
+	// The ID of the newer package.
+	ToPackageID string `json:"-"`
+}
+
+// A GrainUpgradeResult is the outcome of upgrading a grain, as the server
+// sends it to the browser. If the new version failed to start, the grain is
+// rolled back to the version it had before.
+type GrainUpgradeResult struct {
+	GrainID    GrainID `json:"grainId"`
+	RolledBack bool    `json:"rolledBack,omitempty"`
+}
+
+// A GrainEmbed lets an external website show a grain in an iframe, through a
+// signed URL which stops working when the embed expires or is deleted. The
+// JSON encoding is what the server sends to the browser.
+type GrainEmbed struct {
+	ID      string  `json:"id"`
+	GrainID GrainID `json:"-"`
+
+	// Permissions are those of the grain's web sessions opened through
+	// the embed, indexed like the grain's permission definitions.
+	Permissions []bool `json:"permissions"`
+
+	// FrameAncestors are the origins, e.g. "https://example.com", of the
+	// sites allowed to show the grain in a frame.
+	FrameAncestors []string `json:"frameAncestors"`
+
+	Note    string    `json:"note,omitempty"`
+	Created time.Time `json:"created"`
+	Expires time.Time `json:"expires"`
+
+	// URL is the signed URL to put in the iframe. It isn't stored, but
+	// filled in by the server when sending embeds to the browser.
+	URL string `json:"url,omitempty"`
+}
+
+// GrainEmbedList is what the server sends the browser for a grain's sharing
+// dialog: the grain's embeds, and the titles of the permissions they may
+// grant.
+type GrainEmbedList struct {
+	Embeds           []GrainEmbed `json:"embeds"`
+	PermissionTitles []string     `json:"permissionTitles"`
+}
+
+// An APIToken lets external clients use a grain's HTTP API on the api host.
+// Grains offer them through the postMessage API's renderTemplate, as in
+// Sandstorm; the token itself is only shown to the user then, and the JSON
+// encoding, which is what the server sends to the browser, leaves it out.
+type APIToken struct {
+	ID        string    `json:"id"`
+	GrainID   GrainID   `json:"-"`
+	AccountID AccountID `json:"-"` // The user who made the token.
+
+	// Petname is the grain's description of what the token is for.
+	Petname string `json:"petname"`
+
+	// Permissions are those of the API sessions opened with the token,
+	// indexed like the grain's permission definitions.
+	Permissions []bool    `json:"permissions"`
+	Created     time.Time `json:"created"`
+
+	// Whether the user viewing the list made the token. Filled in by the
+	// server when sending tokens to the browser.
+	Mine bool `json:"mine"`
+}
+
+// NewAPIToken is a request from the browser to make an API token, when a
+// grain asks for one with renderTemplate.
+type NewAPIToken struct {
+	Petname string `json:"petname"`
+
+	// RoleID, if not nil, is the index of the role, among those the
+	// grain's app defines, whose permissions the token grants. Otherwise
+	// it grants all of the user's permissions, unless NoAccess is set, in
+	// which case it grants none.
+	RoleID   *int `json:"roleId,omitempty"`
+	NoAccess bool `json:"noAccess,omitempty"`
+
+	// Template is the text to show the user, in which $API_TOKEN and
+	// $API_HOST are replaced by the token and the api host's origin.
+	Template string `json:"template"`
+}
+
+// APITokenOffer is the server's response to a NewAPIToken: the token, and the
+// URL of a page showing the rendered template, which the grain shows the user
+// in an iframe without being able to read it.
+type APITokenOffer struct {
+	Token APIToken `json:"token"`
+	URL   string   `json:"url"`
+}
+
+// An APIKey lets a user's scripts use the api host on their behalf, without
+// logging in: the APIs of the grains it names, with the user's permissions on
+// each, and, if AccountRead is set, the endpoints which read the user's
+// account. As with an APIToken, the key itself is only shown to the user when
+// it is made.
+type APIKey struct {
+	ID        string    `json:"id"`
+	AccountID AccountID `json:"-"` // The user who made the key.
+
+	Name        string    `json:"name"`
+	Grains      []GrainID `json:"grains"`
+	AccountRead bool      `json:"accountRead"`
+	Created     time.Time `json:"created"`
+
+	// LastUsed is the zero time if the key hasn't been used. It is only
+	// recorded to the minute.
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+// NewAPIKey is a request from the browser to make an APIKey.
+type NewAPIKey struct {
+	Name        string    `json:"name"`
+	Grains      []GrainID `json:"grains"`
+	AccountRead bool      `json:"accountRead"`
+}
+
+// APIKeyOffer is the server's response to a NewAPIKey: the key's details,
+// and the key itself, to show the user.
+type APIKeyOffer struct {
+	Key   APIKey `json:"key"`
+	Token string `json:"token"`
+}
+
+// DemoAccount describes whether the user's account is a demo account, which
+// is deleted, with its grains, when it expires.
+type DemoAccount struct {
+	// When the account expires; the zero time if it isn't a demo account.
+	Expires time.Time `json:"expires"`
+}
+
+// A ShareLink lets whoever opens it join a grain, with the permissions of one
+// of the roles its app defines, as a GrainMember. The link's token is a
+// sharing token; like an APIToken's, it is only shown when the link is made.
+type ShareLink struct {
+	ID        string    `json:"id"`
+	GrainID   GrainID   `json:"-"`
+	AccountID AccountID `json:"-"` // The user who made the link.
+
+	// RoleID is the index of the role among those the grain's app
+	// defines. Permissions are those the link grants: the role's, less
+	// any which the user who made the link didn't have.
+	RoleID      int    `json:"roleId"`
+	Permissions []bool `json:"permissions"`
+
+	Note    string    `json:"note,omitempty"`
+	Created time.Time `json:"created"`
+
+	// Expires is the zero time if the link doesn't expire.
+	Expires time.Time `json:"expires"`
+
+	// A single-use link is revoked when it is first used.
+	SingleUse bool `json:"singleUse"`
+
+	// Uses is the number of times the link has been opened.
+	Uses int `json:"uses"`
+
+	// Whether the user viewing the list made the link. Filled in by the
+	// server when sending links to the browser.
+	Mine bool `json:"mine"`
+}
+
+// NewShareLink is a request from the browser to make a ShareLink.
+type NewShareLink struct {
+	RoleID    int    `json:"roleId"`
+	Note      string `json:"note"`
+	SingleUse bool   `json:"singleUse"`
+
+	// Days is how long the link lasts; zero means it doesn't expire.
+	Days int `json:"days"`
+}
+
+// ShareLinkOffer is the server's response to a NewShareLink: the link, and
+// its token, which goes in the URL the user shares.
+type ShareLinkOffer struct {
+	Link  ShareLink `json:"link"`
+	Token string    `json:"token"`
+}
+
+// ShareLinkList is what the server sends the browser for a grain's sharing
+// dialog: the share links the user may see, and the titles of the roles they
+// may grant.
+type ShareLinkList struct {
+	Links      []ShareLink `json:"links"`
+	RoleTitles []string    `json:"roleTitles"`
+}
+
+// A GrainMember is a user with access to a grain they don't own, e.g.
+// because they opened a ShareLink. Its owner may revoke their access.
+type GrainMember struct {
+	AccountID AccountID `json:"accountId"`
+
+	// The member's login credentials, by which the owner may know them.
+	Credentials []Credential `json:"credentials"`
+
+	// Permissions are the member's permissions on the grain: the union of
+	// those of each link they have joined through.
+	Permissions []bool `json:"permissions"`
+
+	// The role, link and user through which the member last joined. If
+	// they got access some other way, e.g. in Sandstorm before the grain
+	// was imported, RoleID is nil, the others are empty, and Joined is
+	// the zero time.
+	RoleID      *int      `json:"roleId,omitempty"`
+	ShareLinkID string    `json:"shareLinkId,omitempty"`
+	SharedBy    AccountID `json:"sharedBy,omitempty"`
+	Joined      time.Time `json:"joined"`
+}
+
+// A GrainShare is an edge of a grain's ShareGraph: one user gave another
+// access to the grain, through share links or API tokens they made.
+type GrainShare struct {
+	SharedBy  AccountID `json:"sharedBy"`
+	AccountID AccountID `json:"accountId"` // The user who got access.
+
+	// The link through which the user last got access from SharedBy, and
+	// its role; empty and nil if it was through an API token.
+	ShareLinkID string `json:"shareLinkId,omitempty"`
+	RoleID      *int   `json:"roleId,omitempty"`
+
+	// Permissions are the union of those granted each time.
+	Permissions []bool    `json:"permissions"`
+	Created     time.Time `json:"created"`
+}
+
+// A ShareGraph records who shared a grain with whom, like Sandstorm's
+// "petname graph", so that revoking a user's access also revokes that of
+// those who got it only through them; see Revoked.
+type ShareGraph struct {
+	Owner AccountID `json:"owner"`
+
+	// Members are the users other than the owner with access to the
+	// grain. Those with no shares to them, e.g. because they got access
+	// in Sandstorm before the grain was imported, got it from the owner.
+	Members []AccountID  `json:"members"`
+	Shares  []GrainShare `json:"shares"`
+}
+
+// Revoked returns the members who lose access to the grain if the owner
+// revokes that of the given member: the member, and those whom the owner's
+// shares no longer reach, in the order of g.Members. It returns nil if the
+// account isn't a member.
+func (g ShareGraph) Revoked(accountID AccountID) []AccountID {
+	if !slices.Contains(g.Members, accountID) {
+		return nil
+	}
+	reached := map[AccountID]bool{g.Owner: true}
+	hasShares := map[AccountID]bool{}
+	for _, s := range g.Shares {
+		hasShares[s.AccountID] = true
+	}
+	frontier := []AccountID{g.Owner}
+	for _, m := range g.Members {
+		if m != accountID && !hasShares[m] {
+			reached[m] = true
+			frontier = append(frontier, m)
+		}
+	}
+	for len(frontier) > 0 {
+		from := frontier[len(frontier)-1]
+		frontier = frontier[:len(frontier)-1]
+		for _, s := range g.Shares {
+			if s.SharedBy == from && s.AccountID != accountID && !reached[s.AccountID] {
+				reached[s.AccountID] = true
+				frontier = append(frontier, s.AccountID)
+			}
+		}
+	}
+	var ret []AccountID
+	for _, m := range g.Members {
+		if !reached[m] {
+			ret = append(ret, m)
+		}
+	}
+	return ret
+}
+
+// RestoredGrain is the server's response to the upload of a grain backup.
+type RestoredGrain struct {
+	GrainID GrainID `json:"grainId"`
+}
+
+// InstalledApp is the server's response to a request to install an app from
+// the app index.
+type InstalledApp struct {
+	AppID     string `json:"appId"`
+	PackageID string `json:"packageId"`
+}
+
+// NewGrainEmbed is a request from the browser to create a GrainEmbed.
+type NewGrainEmbed struct {
+	Permissions    []bool   `json:"permissions"`
+	FrameAncestors []string `json:"frameAncestors"`
+	Note           string   `json:"note"`
+
+	// Days is how long the embed lasts; zero means the server's default.
+	Days int `json:"days"`
+}
+
+// NewPowerboxRequest is sent by the browser when a grain makes a powerbox
+// request through the postMessage API.
+type NewPowerboxRequest struct {
+	GrainID GrainID `json:"grainId"`
+
+	// Query is the grain's list of packed, base64-encoded
+	// PowerboxDescriptors, describing the capabilities it wants.
+	Query     []string `json:"query"`
+	SaveLabel string   `json:"saveLabel"`
+}
+
+// PowerboxRequest is the server's response to a NewPowerboxRequest: the ID
+// of the request, and the options which the user may choose from to fulfill
+// it.
+type PowerboxRequest struct {
+	ID      string           `json:"id"`
+	Options []PowerboxOption `json:"options"`
+}
+
+// A PowerboxOption is a grain which may be chosen to fulfill a powerbox
+// request, or if Network is true, access to the network, or if IPInterface is
+// true, permission to listen on it, or if Thumbnailer is true, the thumbnail
+// service, or if TURN is true, the TURN relay, none of which has a title, or
+// if AccountID is set, the identity of that user, whose display name is the
+// title and whose avatar is at the URL Picture, or if HTTPAPI is set, access
+// to the HTTP API at that URL, as the grain suggested it.
+type PowerboxOption struct {
+	GrainID     GrainID   `json:"grainId,omitempty"`
+	Network     bool      `json:"network,omitempty"`
+	IPInterface bool      `json:"ipInterface,omitempty"`
+	Thumbnailer bool      `json:"thumbnailer,omitempty"`
+	TURN        bool      `json:"turn,omitempty"`
+	AccountID   AccountID `json:"accountId,omitempty"`
+	HTTPAPI     string    `json:"httpApi,omitempty"`
+	Title       string    `json:"title"`
+	Picture     string    `json:"picture,omitempty"`
+}
+
+// PowerboxChoice is sent by the browser to fulfill a powerbox request with
+// the option the user chose. For an HTTP API, HTTPAPI is the URL the user
+// approved, which needn't be the one suggested, and HTTPAuthorization is the
+// Authorization header to send with requests to it, if any.
+type PowerboxChoice struct {
+	GrainID           GrainID   `json:"grainId,omitempty"`
+	Network           bool      `json:"network,omitempty"`
+	IPInterface       bool      `json:"ipInterface,omitempty"`
+	Thumbnailer       bool      `json:"thumbnailer,omitempty"`
+	TURN              bool      `json:"turn,omitempty"`
+	AccountID         AccountID `json:"accountId,omitempty"`
+	HTTPAPI           string    `json:"httpApi,omitempty"`
+	HTTPAuthorization string    `json:"httpAuthorization,omitempty"`
+}
+
+// PowerboxClaim is the server's response to a PowerboxChoice. The browser
+// passes the token to the requesting grain, which exchanges it for the
+// capability with SessionContext.claimRequest.
+type PowerboxClaim struct {
+	Token string `json:"token"`
+}
@@ -0,0 +1,64 @@
+// Package configgen generates the source files holding the installation
+// paths chosen by ./configure: internal/config/config.go for the Go code, and
+// c/config.h for the C code.  It is shared by internal/make and the
+// build-tool's `generate config`, so that both write the same files.
+package configgen
+
+import "fmt"
+
+// Paths of the generated files, relative to the root of the repository.
+const (
+	GoPath = "internal/config/config.go"
+	CPath  = "c/config.h"
+)
+
+// Paths are the installation paths which the generated files define.
+type Paths struct {
+	Prefix        string
+	Libexecdir    string
+	Localstatedir string
+}
+
+// A File is a generated file, and its contents.
+type File struct {
+	Path    string
+	Content string
+}
+
+// Files returns the generated files for the paths.
+func (p Paths) Files() []File {
+	return []File{
+		{Path: GoPath, Content: p.GoSrc()},
+		{Path: CPath, Content: p.CSrc()},
+	}
+}
+
+// GoSrc returns the contents of internal/config/config.go.
+func (p Paths) GoSrc() string {
+	return fmt.Sprintf(`package config
+
+const (
+	Prefix = %q
+	Libexecdir = %q
+	Localstatedir = %q
+)
+`,
+		p.Prefix,
+		p.Libexecdir,
+		p.Localstatedir,
+	)
+}
+
+// CSrc returns the contents of c/config.h.
+func (p Paths) CSrc() string {
+	return fmt.Sprintf(`
+#pragma once
+#define PREFIX %q
+#define LIBEXECDIR %q
+#define LOCALSTATEDIR %q
+`,
+		p.Prefix,
+		p.Libexecdir,
+		p.Localstatedir,
+	)
+}
@@ -6,4 +6,8 @@ const (
 	TempDir     = Localstatedir + "/tmp/tempest"
 	PackagesDir = Localstatedir + "/sandstorm/apps"
 	GrainsDir   = Localstatedir + "/sandstorm/grains"
+	ACMEDir     = Localstatedir + "/sandstorm/acme"
+	BlobsDir    = Localstatedir + "/sandstorm/blobs"
+	DevSocket   = Localstatedir + "/sandstorm/dev.sock"
+	AdminSocket = Localstatedir + "/sandstorm/admin.sock"
 )
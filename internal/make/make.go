@@ -16,6 +16,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"sandstorm.org/go/tempest/internal/config/configgen"
 )
 
 type Config struct {
@@ -97,32 +99,13 @@ func (c *Config) ParseFlags(args []string, name string, errorHandling flag.Error
 	}
 }
 
-func (c Config) GoSrc() string {
-	return fmt.Sprintf(`package config
-
-const (
-	Prefix = %q
-	Libexecdir = %q
-	Localstatedir = %q
-)
-`,
-		c.Prefix,
-		c.Libexecdir,
-		c.Localstatedir,
-	)
-}
-
-func (c Config) CSrc() string {
-	return fmt.Sprintf(`
-#pragma once
-#define PREFIX %q
-#define LIBEXECDIR %q
-#define LOCALSTATEDIR %q
-`,
-		c.Prefix,
-		c.Libexecdir,
-		c.Localstatedir,
-	)
+// paths returns the installation paths written to the generated config files.
+func (c Config) paths() configgen.Paths {
+	return configgen.Paths{
+		Prefix:        c.Prefix,
+		Libexecdir:    c.Libexecdir,
+		Localstatedir: c.Localstatedir,
+	}
 }
 
 func chkfatal(err error) {
@@ -170,22 +153,10 @@ func buildC() error {
 func buildConfig(r *BuildRecord) {
 	if r.IsModified("./config.json") {
 		cfg := readConfig()
-		files := []struct {
-			path    string
-			content string
-		}{
-			{
-				path:    "./internal/config/config.go",
-				content: cfg.GoSrc(),
-			},
-			{
-				path:    "./c/config.h",
-				content: cfg.CSrc(),
-			},
-		}
-		for _, f := range files {
-			chkfatal(os.WriteFile(f.path, []byte(f.content), 0600))
-			r.RecordFile(f.path)
+		for _, f := range cfg.paths().Files() {
+			path := "./" + f.Path
+			chkfatal(os.WriteFile(path, []byte(f.Content), 0600))
+			r.RecordFile(path)
 		}
 	}
 }
@@ -375,6 +346,7 @@ func buildGo(r *BuildRecord) error {
 		{"sandstorm-import-tool", false},
 		{"tempest", false},
 		{"tempest-make-user", false},
+		{"tempest-promote-standby", false},
 		{"tempest-grain-agent", true},
 		{"test-app", true},
 	}
@@ -452,6 +424,10 @@ func run(args ...string) {
 		installExe(c, "tempest-sandbox-launcher", c.Libexecdir+"/tempest",
 			"cap_sys_admin,cap_net_admin,cap_mknod+ep")
 		installExe(c, "tempest-grain-agent", c.Libexecdir+"/tempest", "")
+		if _, err := os.Stat("_build/test-app.spk"); err == nil {
+			// Used by `tempest demo`.
+			installExe(c, "test-app.spk", c.Libexecdir+"/tempest", "")
+		}
 		chkfatal(os.MkdirAll(c.Localstatedir+"/sandstorm/mnt", 0755))
 	case "dev":
 		run("install")
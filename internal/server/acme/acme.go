@@ -1,65 +1,104 @@
-// Package acme supports obtaining TLS certificates via ACME/Let's Encrypt.
+// Package acme obtains and renews TLS certificates via ACME, e.g. from Let's
+// Encrypt.
+//
+// Tempest serves grains from subdomains of its root domain, so it needs a
+// wildcard certificate, which ACME CAs only issue via the DNS-01 challenge:
+// proving control of the domain by publishing a TXT record. Publishing the
+// record is up to a DNS provider driver; any of lego's drivers may be used,
+// and others may be added with RegisterProvider.
 package acme
 
 import (
 	"crypto"
-	"errors"
+	"fmt"
 
+	"github.com/go-acme/lego/v4/certcrypto"
 	"github.com/go-acme/lego/v4/challenge"
 	"github.com/go-acme/lego/v4/lego"
 	"github.com/go-acme/lego/v4/providers/dns"
 	"github.com/go-acme/lego/v4/registration"
-	"sandstorm.org/go/tempest/internal/server/settings"
-	"zenhack.net/go/util/exn"
 )
 
-var (
-	ErrNoProvider = errors.New("no dns01 challenge provider set")
-)
+// LetsEncrypt is the directory URL of Let's Encrypt's production CA.
+const LetsEncrypt = lego.LEDirectoryProduction
+
+// A ProviderFunc creates a DNS provider driver. Like lego's drivers, it
+// should read its settings, e.g. API credentials, from the environment.
+type ProviderFunc func() (challenge.Provider, error)
+
+// Drivers registered by RegisterProvider, by name.
+var providers = map[string]ProviderFunc{}
+
+// RegisterProvider makes a DNS provider driver available to NewProvider
+// under the given name, taking precedence over any of lego's drivers with
+// the same name. It should be called from an init function.
+func RegisterProvider(name string, f ProviderFunc) {
+	if _, ok := providers[name]; ok {
+		panic(fmt.Sprintf("acme: DNS provider %q registered twice", name))
+	}
+	providers[name] = f
+}
 
-func ConfigFromSettings(src settings.Source) (*Config, error) {
-	return exn.Try(func(throw exn.Thrower) *Config {
-		providerName := src.GetString("ACME_DNS_PROVIDER")
-		if providerName == "" {
-			throw(ErrNoProvider)
-		}
-		// XXX: this will always pull provider-specific options from the
-		// environment, even if src is not settings.Environ. Maybe we should
-		// pull this function out into a parameter we can dependency-inject:
-		provider, err := dns.NewDNSChallengeProviderByName(providerName)
-		throw(err)
-		return &Config{
-			User: User{
-				Email: src.GetString("ACME_EMAIL"),
-				// TODO: fill in other fields.
-			},
-			Directory: src.GetString("ACME_DIRECTORY_URL"),
-			Provider:  provider,
-		}
-	})
+// NewProvider creates the DNS provider driver with the given name: one
+// registered with RegisterProvider, or one of lego's; see
+// https://go-acme.github.io/lego/dns/ for the latter.
+func NewProvider(name string) (challenge.Provider, error) {
+	if f, ok := providers[name]; ok {
+		return f()
+	}
+	return dns.NewDNSChallengeProviderByName(name)
 }
 
+// Config configures a Manager.
 type Config struct {
-	User
+	// Email address of the ACME account, to which the CA sends notices,
+	// e.g. about expiring certificates. May be empty.
+	Email string
+
+	// URL of the CA's ACME directory.
 	Directory string
-	Provider  challenge.Provider
+
+	// The DNS provider driver which publishes DNS-01 challenges. If this
+	// is nil, ACME is disabled.
+	Provider challenge.Provider
+
+	// The domains the certificate must cover. The first is the
+	// certificate's common name, and names the files it is stored in.
+	Domains []string
+}
+
+// Enabled reports whether certificates should be obtained via ACME.
+func (c Config) Enabled() bool {
+	return c.Provider != nil
 }
 
-func (c *Config) ToLego() *lego.Config {
-	ret := lego.NewConfig(&c.User)
+// Domains returns the domains of Tempest's certificate, given its root
+// domain: the root domain, and the wildcard covering its subdomains.
+func Domains(rootDomain string) []string {
+	return []string{rootDomain, "*." + rootDomain}
+}
+
+// toLego returns the lego configuration for acting as user.
+func (c Config) toLego(user *User) *lego.Config {
+	ret := lego.NewConfig(user)
 	ret.CADirURL = c.Directory
+	ret.Certificate.KeyType = certcrypto.EC256
 	return ret
 }
 
-func (c *Config) ToClient() (*lego.Client, error) {
-	client, err := lego.NewClient(c.ToLego())
+// newClient returns a lego client acting as user.
+func (c Config) newClient(user *User) (*lego.Client, error) {
+	client, err := lego.NewClient(c.toLego(user))
 	if err != nil {
 		return nil, err
 	}
-	client.Challenge.SetDNS01Provider(c.Provider)
+	if err = client.Challenge.SetDNS01Provider(c.Provider); err != nil {
+		return nil, err
+	}
 	return client, nil
 }
 
+// A User is an ACME account.
 type User struct {
 	Email        string
 	Registration *registration.Resource
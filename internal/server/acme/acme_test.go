@@ -0,0 +1,114 @@
+package acme
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"io/fs"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+// selfSigned returns a PEM certificate for domains, expiring at notAfter,
+// and its key.
+func selfSigned(t *testing.T, domains []string, notAfter time.Time) (certPEM, keyPEM []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		DNSNames:     domains,
+		NotBefore:    notAfter.Add(-90 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestDirStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "acme")
+	store := DirStore(dir)
+	_, err := store.Load("account.key")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	require.NoError(t, store.Save("account.key", []byte("one")))
+	require.NoError(t, store.Save("account.key", []byte("two")))
+	data, err := store.Load("account.key")
+	require.NoError(t, err)
+	assert.Equal(t, "two", string(data))
+
+	fi, err := os.Stat(filepath.Join(dir, "account.key"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files are left behind")
+}
+
+func TestNeedsRenewal(t *testing.T) {
+	lg := slog.New(slog.HandlerOptions{}.NewTextHandler(io.Discard))
+	domains := Domains("example.com")
+	now := time.Unix(1700000000, 0)
+	newManager := func(certDomains []string, notAfter time.Time) *Manager {
+		store := DirStore(t.TempDir())
+		if certDomains != nil {
+			certPEM, keyPEM := selfSigned(t, certDomains, notAfter)
+			require.NoError(t, store.Save("example.com.crt", certPEM))
+			require.NoError(t, store.Save("example.com.key", keyPEM))
+		}
+		m := NewManager(lg, Config{Domains: domains}, store)
+		m.now = func() time.Time { return now }
+		return m
+	}
+
+	m := newManager(nil, time.Time{})
+	assert.True(t, m.needsRenewal(), "no certificate")
+	_, err := m.GetCertificate(nil)
+	assert.ErrorIs(t, err, ErrNoCertificate)
+
+	m = newManager(domains, now.Add(60*24*time.Hour))
+	assert.False(t, m.needsRenewal())
+	cert, err := m.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, domains, cert.Leaf.DNSNames)
+
+	m = newManager(domains, now.Add(29*24*time.Hour))
+	assert.True(t, m.needsRenewal(), "expiring soon")
+
+	m = newManager([]string{"example.com"}, now.Add(60*24*time.Hour))
+	assert.True(t, m.needsRenewal(), "doesn't cover subdomains")
+}
+
+type fakeProvider struct{}
+
+func (fakeProvider) Present(domain, token, keyAuth string) error { return nil }
+func (fakeProvider) CleanUp(domain, token, keyAuth string) error { return nil }
+
+func TestRegisterProvider(t *testing.T) {
+	RegisterProvider("tempest-test", func() (challenge.Provider, error) {
+		return fakeProvider{}, nil
+	})
+	p, err := NewProvider("tempest-test")
+	require.NoError(t, err)
+	assert.Equal(t, fakeProvider{}, p)
+
+	_, err = NewProvider("no-such-provider")
+	assert.Error(t, err)
+	assert.Panics(t, func() {
+		RegisterProvider("tempest-test", nil)
+	})
+}
@@ -0,0 +1,266 @@
+package acme
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io/fs"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-acme/lego/v4/certificate"
+	"github.com/go-acme/lego/v4/registration"
+	"golang.org/x/exp/slog"
+)
+
+// ErrNoCertificate is returned by GetCertificate before the first
+// certificate has been obtained.
+var ErrNoCertificate = errors.New("acme: no certificate obtained yet")
+
+const (
+	// Certificates are renewed this long before they expire. Let's
+	// Encrypt's are valid for 90 days, and it recommends renewing them
+	// after 60.
+	renewBefore = 30 * 24 * time.Hour
+
+	// How often to check whether the certificate needs renewing.
+	checkInterval = 12 * time.Hour
+
+	// How long to wait before trying again after failing to obtain a
+	// certificate. This is well within the CA's rate limits.
+	retryInterval = time.Hour
+)
+
+// Names of the items in the Store.
+const (
+	accountKeyName = "account.key"
+	accountRegName = "account.json"
+)
+
+// A Manager keeps a certificate for the configured domains, obtaining it
+// via ACME and renewing it before it expires. Its GetCertificate method is
+// suitable for tls.Config.
+type Manager struct {
+	log   *slog.Logger
+	cfg   Config
+	store Store
+	now   func() time.Time
+
+	cert atomic.Pointer[tls.Certificate]
+}
+
+// NewManager returns a Manager which keeps its account and certificate in
+// store. If a certificate was stored previously, it is used until Serve
+// replaces it.
+func NewManager(lg *slog.Logger, cfg Config, store Store) *Manager {
+	m := &Manager{
+		log:   lg,
+		cfg:   cfg,
+		store: store,
+		now:   time.Now,
+	}
+	cert, err := m.loadCertificate()
+	if err == nil {
+		m.cert.Store(cert)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		lg.Error("Loading stored TLS certificate; obtaining a new one", "error", err)
+	}
+	return m
+}
+
+// GetCertificate returns the current certificate, whatever the client asks
+// for; the certificate covers every name we serve.
+func (m *Manager) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cert := m.cert.Load()
+	if cert == nil {
+		return nil, ErrNoCertificate
+	}
+	return cert, nil
+}
+
+// Serve obtains a certificate if there is none, and renews it when it is
+// close to expiring, until ctx is canceled.
+func (m *Manager) Serve(ctx context.Context) {
+	for {
+		wait := checkInterval
+		if m.needsRenewal() {
+			if err := m.obtain(); err != nil {
+				m.log.Error("Obtaining TLS certificate via ACME",
+					"domains", m.cfg.Domains,
+					"error", err,
+				)
+				wait = retryInterval
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// needsRenewal reports whether a new certificate is needed: there is none,
+// it is close to expiring, or it doesn't cover the configured domains, which
+// may have changed since it was obtained.
+func (m *Manager) needsRenewal() bool {
+	cert := m.cert.Load()
+	if cert == nil {
+		return true
+	}
+	leaf := cert.Leaf
+	if m.now().After(leaf.NotAfter.Add(-renewBefore)) {
+		return true
+	}
+	for _, domain := range m.cfg.Domains {
+		// VerifyHostname doesn't match a wildcard name itself against
+		// a wildcard certificate, so check for a name it covers.
+		if rest, ok := strings.CutPrefix(domain, "*."); ok {
+			domain = "x." + rest
+		}
+		if leaf.VerifyHostname(domain) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// obtain obtains a new certificate from the CA, and stores it.
+func (m *Manager) obtain() error {
+	user, err := m.account()
+	if err != nil {
+		return err
+	}
+	client, err := m.cfg.newClient(user)
+	if err != nil {
+		return err
+	}
+	m.log.Info("Obtaining TLS certificate via ACME", "domains", m.cfg.Domains)
+	res, err := client.Certificate.Obtain(certificate.ObtainRequest{
+		Domains: m.cfg.Domains,
+		Bundle:  true,
+	})
+	if err != nil {
+		return err
+	}
+	cert, err := parseCertificate(res.Certificate, res.PrivateKey)
+	if err != nil {
+		return err
+	}
+	// Store the key first, so that a crash in between leaves a
+	// mismatched pair, which is discarded on loading, rather than a
+	// certificate whose key is lost.
+	name := m.cfg.Domains[0]
+	if err = m.store.Save(name+".key", res.PrivateKey); err != nil {
+		return err
+	}
+	if err = m.store.Save(name+".crt", res.Certificate); err != nil {
+		return err
+	}
+	m.cert.Store(cert)
+	m.log.Info("Obtained TLS certificate via ACME",
+		"domains", m.cfg.Domains,
+		"expires", cert.Leaf.NotAfter,
+	)
+	return nil
+}
+
+// loadCertificate loads the stored certificate.
+func (m *Manager) loadCertificate() (*tls.Certificate, error) {
+	name := m.cfg.Domains[0]
+	certPEM, err := m.store.Load(name + ".crt")
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := m.store.Load(name + ".key")
+	if err != nil {
+		return nil, err
+	}
+	return parseCertificate(certPEM, keyPEM)
+}
+
+// parseCertificate parses a PEM certificate bundle and its key, filling in
+// Leaf.
+func parseCertificate(certPEM, keyPEM []byte) (*tls.Certificate, error) {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+	return &cert, nil
+}
+
+// account returns the ACME account, creating and registering it with the
+// CA the first time.
+func (m *Manager) account() (*User, error) {
+	user := &User{Email: m.cfg.Email}
+	var err error
+	if user.key, err = m.accountKey(); err != nil {
+		return nil, err
+	}
+	data, err := m.store.Load(accountRegName)
+	if err == nil {
+		user.Registration = &registration.Resource{}
+		if err = json.Unmarshal(data, user.Registration); err != nil {
+			return nil, err
+		}
+		return user, nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	client, err := m.cfg.newClient(user)
+	if err != nil {
+		return nil, err
+	}
+	m.log.Info("Registering ACME account",
+		"directory", m.cfg.Directory,
+		"email", m.cfg.Email,
+	)
+	user.Registration, err = client.Registration.Register(registration.RegisterOptions{
+		TermsOfServiceAgreed: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	data, err = json.Marshal(user.Registration)
+	if err != nil {
+		return nil, err
+	}
+	return user, m.store.Save(accountRegName, data)
+}
+
+// accountKey returns the ACME account's private key, generating it the
+// first time.
+func (m *Manager) accountKey() (*ecdsa.PrivateKey, error) {
+	data, err := m.store.Load(accountKeyName)
+	if err == nil {
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("acme: account key is not PEM encoded")
+		}
+		return x509.ParseECPrivateKey(block.Bytes)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	data = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	return key, m.store.Save(accountKeyName, data)
+}
@@ -0,0 +1,47 @@
+package acme
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// A Store persists the ACME account's key and registration, and the
+// certificates obtained, so they survive restarts. Load returns an error
+// satisfying errors.Is(err, fs.ErrNotExist) if nothing is saved under name.
+type Store interface {
+	Load(name string) ([]byte, error)
+	Save(name string, data []byte) error
+}
+
+// A DirStore is a Store which keeps each item in a file in the directory.
+// Since the items include private keys, the files are only accessible by
+// their owner.
+type DirStore string
+
+func (d DirStore) Load(name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(string(d), name))
+}
+
+// Save replaces the item atomically, so that a crash doesn't leave it half
+// written.
+func (d DirStore) Save(name string, data []byte) error {
+	if err := os.MkdirAll(string(d), 0700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(string(d), name+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filepath.Join(string(d), name))
+}
@@ -0,0 +1,230 @@
+// Package apiversion tracks the lifecycle of the methods in the Cap'n Proto
+// interfaces that Tempest exposes to apps.
+//
+// Methods are annotated in the schemas with the API level at which they were
+// introduced and, if applicable, when they were deprecated and when we plan to
+// remove them; see apiversion.capnp. These annotations are collected into
+// Methods when the package is initialized. Server code calls Recorder.Record
+// whenever an app invokes one of these methods, so that we can warn about (and
+// report on) apps which rely on interfaces slated for removal.
+package apiversion
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/schemas"
+	"capnproto.org/go/capnp/v3/std/capnp/schema"
+	"golang.org/x/exp/slog"
+	"sandstorm.org/go/tempest/capnp/apiversion"
+	"sandstorm.org/go/tempest/capnp/grain"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"zenhack.net/go/util"
+)
+
+// A Level identifies a version of the app-facing API. Levels only ever
+// increase; each time we add, deprecate, or remove methods we bump
+// CurrentLevel.
+type Level int
+
+const (
+	// LevelSandstorm is the API as implemented by Sandstorm, which Tempest
+	// aims to be compatible with.
+	LevelSandstorm Level = 0
+
+	// CurrentLevel is the API level implemented by this version of Tempest.
+	CurrentLevel Level = LevelSandstorm
+)
+
+// MethodInfo records versioning metadata for a method.
+type MethodInfo struct {
+	Interface string // e.g. "SandstormApi"
+	Method    string // e.g. "save"
+
+	// Level at which the method was introduced.
+	Since Level
+
+	// If true, apps should stop calling the method.
+	Deprecated bool
+
+	// Level at which we plan to remove the method, if any. Only meaningful
+	// if Deprecated is true.
+	RemovedIn Level
+
+	// What apps should do instead, for use in log messages and reports.
+	Note string
+}
+
+// SlatedForRemoval returns true if the method is deprecated and there is a
+// plan to remove it.
+func (m MethodInfo) SlatedForRemoval() bool {
+	return m.Deprecated && m.RemovedIn > 0
+}
+
+// Methods lists all methods with versioning metadata, as recorded by the
+// annotations in apiversion.capnp. Methods not listed here are assumed to be
+// stable and available since LevelSandstorm.
+var Methods []MethodInfo
+
+// schemaFiles lists the schema files whose interfaces are exposed to apps, by
+// the ID of any node in the file and the function registering its schema.
+var schemaFiles = []struct {
+	id       uint64
+	register func(*schemas.Registry)
+}{
+	{grain.SandstormApi_TypeID, grain.RegisterSchema},
+}
+
+func init() {
+	reg := &schemas.Registry{}
+	for _, f := range schemaFiles {
+		f.register(reg)
+		data, err := reg.Find(f.id)
+		util.Chkfatal(err)
+		msg, err := capnp.Unmarshal(data)
+		util.Chkfatal(err)
+		msg.ResetReadLimit(math.MaxUint64)
+		req, err := schema.ReadRootCodeGeneratorRequest(msg)
+		util.Chkfatal(err)
+		nodes, err := req.Nodes()
+		util.Chkfatal(err)
+		for i := 0; i < nodes.Len(); i++ {
+			if node := nodes.At(i); node.Which() == schema.Node_Which_interface {
+				Methods = append(Methods, interfaceMethods(node)...)
+			}
+		}
+	}
+	sort.Slice(Methods, func(i, j int) bool {
+		if Methods[i].Interface != Methods[j].Interface {
+			return Methods[i].Interface < Methods[j].Interface
+		}
+		return Methods[i].Method < Methods[j].Method
+	})
+}
+
+// interfaceMethods returns the metadata for those methods of the interface
+// which have versioning annotations.
+func interfaceMethods(node schema.Node) []MethodInfo {
+	displayName, err := node.DisplayName()
+	util.Chkfatal(err)
+	iface := displayName[node.DisplayNamePrefixLength():]
+	methods, err := node.Interface().Methods()
+	util.Chkfatal(err)
+	var ret []MethodInfo
+	for i := 0; i < methods.Len(); i++ {
+		method := methods.At(i)
+		annotations, err := method.Annotations()
+		util.Chkfatal(err)
+		if annotations.Len() == 0 {
+			continue
+		}
+		name, err := method.Name()
+		util.Chkfatal(err)
+		m := MethodInfo{Interface: iface, Method: name}
+		annotated := false
+		for j := 0; j < annotations.Len(); j++ {
+			a := annotations.At(j)
+			value, err := a.Value()
+			util.Chkfatal(err)
+			switch a.Id() {
+			case apiversion.Since:
+				m.Since = Level(value.Uint32())
+			case apiversion.Deprecated:
+				m.Deprecated = true
+				m.Note, err = value.Text()
+				util.Chkfatal(err)
+			case apiversion.RemovedIn:
+				m.RemovedIn = Level(value.Uint32())
+			default:
+				continue
+			}
+			annotated = true
+		}
+		if annotated {
+			ret = append(ret, m)
+		}
+	}
+	return ret
+}
+
+type methodKey struct {
+	iface, method string
+}
+
+var methodsByName = func() map[methodKey]MethodInfo {
+	ret := make(map[methodKey]MethodInfo, len(Methods))
+	for _, m := range Methods {
+		ret[methodKey{m.Interface, m.Method}] = m
+	}
+	return ret
+}()
+
+// Lookup returns the metadata for a method.
+func Lookup(iface, method string) MethodInfo {
+	m, ok := methodsByName[methodKey{iface, method}]
+	if !ok {
+		return MethodInfo{
+			Interface: iface,
+			Method:    method,
+			Since:     LevelSandstorm,
+		}
+	}
+	return m
+}
+
+// A Recorder keeps track of which deprecated methods each grain has used.
+type Recorder struct {
+	Log *slog.Logger
+
+	// Save, if not nil, is called for every use of a deprecated method.
+	Save func(grainID types.GrainID, m MethodInfo) error
+
+	mu     sync.Mutex
+	warned map[usageKey]struct{}
+}
+
+type usageKey struct {
+	grainID types.GrainID
+	method  methodKey
+}
+
+// Record notes that the grain has called the given method. If the method is
+// deprecated, a warning is logged (once per grain and method, to avoid
+// flooding the logs) and the use is passed to r.Save. Returns the method's
+// metadata.
+func (r *Recorder) Record(grainID types.GrainID, iface, method string) MethodInfo {
+	m := Lookup(iface, method)
+	if !m.Deprecated {
+		return m
+	}
+	key := usageKey{grainID: grainID, method: methodKey{iface, method}}
+	r.mu.Lock()
+	if r.warned == nil {
+		r.warned = make(map[usageKey]struct{})
+	}
+	_, warned := r.warned[key]
+	r.warned[key] = struct{}{}
+	r.mu.Unlock()
+	if !warned {
+		r.Log.Warn("Grain called deprecated API method",
+			"grainID", grainID,
+			"interface", iface,
+			"method", method,
+			"slated-for-removal", m.SlatedForRemoval(),
+			"note", m.Note,
+		)
+	}
+	if r.Save != nil {
+		if err := r.Save(grainID, m); err != nil {
+			r.Log.Error("Failed to record use of deprecated API method",
+				"error", err,
+				"grainID", grainID,
+				"interface", iface,
+				"method", method,
+			)
+		}
+	}
+	return m
+}
@@ -0,0 +1,52 @@
+package apiversion
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/slog"
+	"sandstorm.org/go/tempest/internal/common/types"
+)
+
+func TestLookup(t *testing.T) {
+	m := Lookup("SandstormApi", "deprecatedPublish")
+	assert.True(t, m.Deprecated)
+	assert.True(t, m.SlatedForRemoval())
+
+	m = Lookup("SandstormApi", "save")
+	assert.False(t, m.Deprecated)
+	assert.Equal(t, LevelSandstorm, m.Since)
+}
+
+func TestRecorder(t *testing.T) {
+	var saved []string
+	r := &Recorder{
+		Log: slog.New(slog.HandlerOptions{}.NewTextHandler(os.Stderr)),
+		Save: func(grainID types.GrainID, m MethodInfo) error {
+			saved = append(saved, string(grainID)+" "+m.Method)
+			return nil
+		},
+	}
+	r.Record("grain1", "SandstormApi", "save")
+	r.Record("grain1", "SandstormApi", "deprecatedPublish")
+	r.Record("grain1", "SandstormApi", "deprecatedPublish")
+	assert.Equal(t, []string{
+		"grain1 deprecatedPublish",
+		"grain1 deprecatedPublish",
+	}, saved)
+}
+
+// Make sure no method is listed twice, and removal levels make sense.
+func TestMethodsConsistent(t *testing.T) {
+	seen := make(map[methodKey]bool)
+	for _, m := range Methods {
+		key := methodKey{m.Interface, m.Method}
+		assert.False(t, seen[key], "duplicate entry for %v", key)
+		seen[key] = true
+		if m.RemovedIn > 0 {
+			assert.True(t, m.Deprecated, "%v slated for removal but not deprecated", key)
+			assert.Greater(t, m.RemovedIn, m.Since)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Package appindex fetches and caches the Sandstorm app index, the catalog
+// behind the app market, so that users can find apps and install them by ID
+// instead of uploading packages themselves.
+//
+// The index is a JSON file at <url>/apps/index.json; each app's current
+// package is at <url>/packages/<package id>, and its icon at
+// <url>/images/<image id>.
+package appindex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultURL is the app index used by Sandstorm's app market.
+const DefaultURL = "https://app-index.sandstorm.io"
+
+// TTL is how long a fetched index is used before it is fetched again.
+const TTL = time.Hour
+
+// ErrNotFound is returned by Lookup for apps which aren't in the index.
+var ErrNotFound = errors.New("appindex: no such app")
+
+// An App is an entry in the index. Only the fields Tempest uses are decoded.
+type App struct {
+	AppID            string   `json:"appId"`
+	Name             string   `json:"name"`
+	Version          string   `json:"version"`
+	VersionNumber    uint32   `json:"versionNumber"`
+	PackageID        string   `json:"packageId"`
+	ImageID          string   `json:"imageId"`
+	ShortDescription string   `json:"shortDescription"`
+	Categories       []string `json:"categories"`
+	Author           struct {
+		Name string `json:"name"`
+	} `json:"author"`
+}
+
+// matches reports whether the app matches a search query: whether each word
+// of the query appears, ignoring case, in its name, description, author or
+// categories.
+func (a App) matches(query string) bool {
+	text := strings.ToLower(strings.Join(append([]string{
+		a.Name,
+		a.ShortDescription,
+		a.Author.Name,
+	}, a.Categories...), "\n"))
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// An Index is a cached copy of the app index at a URL.
+type Index struct {
+	url    string
+	client *http.Client
+	now    func() time.Time
+
+	mu      sync.Mutex
+	apps    []App
+	fetched time.Time
+}
+
+// New returns an Index for the app index at url, e.g. DefaultURL.
+func New(url string) *Index {
+	return &Index{
+		url:    strings.TrimSuffix(url, "/"),
+		client: http.DefaultClient,
+		now:    time.Now,
+	}
+}
+
+// Apps returns every app in the index, fetching it if the cached copy is
+// older than TTL. If fetching fails, a stale copy is returned instead, if
+// there is one.
+func (ix *Index) Apps(ctx context.Context) ([]App, error) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if ix.apps != nil && ix.now().Sub(ix.fetched) < TTL {
+		return ix.apps, nil
+	}
+	apps, err := ix.fetch(ctx)
+	if err != nil {
+		if ix.apps != nil {
+			return ix.apps, nil
+		}
+		return nil, err
+	}
+	ix.apps = apps
+	ix.fetched = ix.now()
+	return apps, nil
+}
+
+// Search returns the apps matching query; see App.matches. An empty query
+// matches every app.
+func (ix *Index) Search(ctx context.Context, query string) ([]App, error) {
+	apps, err := ix.Apps(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ret := []App{}
+	for _, app := range apps {
+		if app.matches(query) {
+			ret = append(ret, app)
+		}
+	}
+	return ret, nil
+}
+
+// Lookup returns the app with the given ID.
+func (ix *Index) Lookup(ctx context.Context, appID string) (App, error) {
+	apps, err := ix.Apps(ctx)
+	if err != nil {
+		return App{}, err
+	}
+	for _, app := range apps {
+		if app.AppID == appID {
+			return app, nil
+		}
+	}
+	return App{}, ErrNotFound
+}
+
+// FetchPackage downloads the app's current package. The caller must close
+// it, and is responsible for checking that it is signed by the app's key and
+// has the expected package ID.
+func (ix *Index) FetchPackage(ctx context.Context, app App) (io.ReadCloser, error) {
+	return ix.get(ctx, "/packages/"+app.PackageID)
+}
+
+// ImageURL returns the URL of the app's icon.
+func (ix *Index) ImageURL(app App) string {
+	return ix.url + "/images/" + app.ImageID
+}
+
+func (ix *Index) fetch(ctx context.Context) ([]App, error) {
+	body, err := ix.get(ctx, "/apps/index.json")
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	var index struct {
+		Apps []App `json:"apps"`
+	}
+	if err = json.NewDecoder(body).Decode(&index); err != nil {
+		return nil, fmt.Errorf("appindex: decoding index: %w", err)
+	}
+	if index.Apps == nil {
+		index.Apps = []App{}
+	}
+	return index.Apps, nil
+}
+
+func (ix *Index) get(ctx context.Context, path string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ix.url+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := ix.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("appindex: fetching %s: %s", req.URL, resp.Status)
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,125 @@
+package appindex
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testIndex = `{"apps": [
+	{
+		"appId": "nn7axgy3y8kvd0m1mtk3cwca34t916p5d7m4j1j2e874nuz3t8y0",
+		"name": "Filedrop",
+		"version": "1.0.6",
+		"versionNumber": 6,
+		"packageId": "d13f67230d0a4f9a63fc2c0bba87fc52",
+		"shortDescription": "File transfer",
+		"categories": ["Productivity"],
+		"author": {"name": "Alice"}
+	},
+	{
+		"appId": "6ee9j59rp4mepat8pz2pt1sq97xmname4kvhzh4m3uzwrvf1hu10",
+		"name": "Yet Another TODO",
+		"version": "0.1.2",
+		"packageId": "346bff4bc867afd54320af244dc64e9c",
+		"shortDescription": "Task list",
+		"categories": ["Productivity"],
+		"author": {"name": "Bob"}
+	}
+]}`
+
+// newTestServer serves testIndex and a package, counting index fetches.
+func newTestServer(t *testing.T, fetches *int) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/apps/index.json", func(w http.ResponseWriter, req *http.Request) {
+		*fetches++
+		io.WriteString(w, testIndex)
+	})
+	mux.HandleFunc("/packages/d13f67230d0a4f9a63fc2c0bba87fc52", func(w http.ResponseWriter, req *http.Request) {
+		io.WriteString(w, "spk")
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSearch(t *testing.T) {
+	var fetches int
+	ix := New(newTestServer(t, &fetches).URL)
+	ctx := context.Background()
+
+	apps, err := ix.Search(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, apps, 2)
+
+	apps, err = ix.Search(ctx, "todo BOB")
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	assert.Equal(t, "Yet Another TODO", apps[0].Name)
+
+	apps, err = ix.Search(ctx, "productivity file")
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	assert.Equal(t, "Filedrop", apps[0].Name)
+
+	apps, err = ix.Search(ctx, "nothing matches")
+	require.NoError(t, err)
+	assert.Empty(t, apps)
+
+	assert.Equal(t, 1, fetches, "index is cached between searches")
+}
+
+func TestCache(t *testing.T) {
+	var fetches int
+	srv := newTestServer(t, &fetches)
+	ix := New(srv.URL)
+	now := time.Unix(1700000000, 0)
+	ix.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err := ix.Apps(ctx)
+	require.NoError(t, err)
+	now = now.Add(TTL)
+	_, err = ix.Apps(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, fetches, "index is fetched again after TTL")
+
+	// A stale index is better than none:
+	srv.Close()
+	now = now.Add(TTL)
+	apps, err := ix.Apps(ctx)
+	require.NoError(t, err)
+	assert.Len(t, apps, 2)
+}
+
+func TestLookupAndFetchPackage(t *testing.T) {
+	var fetches int
+	ix := New(newTestServer(t, &fetches).URL + "/")
+	ctx := context.Background()
+
+	app, err := ix.Lookup(ctx, "nn7axgy3y8kvd0m1mtk3cwca34t916p5d7m4j1j2e874nuz3t8y0")
+	require.NoError(t, err)
+	assert.Equal(t, uint32(6), app.VersionNumber)
+	assert.Equal(t, "Alice", app.Author.Name)
+
+	body, err := ix.FetchPackage(ctx, app)
+	require.NoError(t, err)
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	assert.Equal(t, "spk", string(data))
+
+	_, err = ix.Lookup(ctx, "nonexistent")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	other, err := ix.Lookup(ctx, "6ee9j59rp4mepat8pz2pt1sq97xmname4kvhzh4m3uzwrvf1hu10")
+	require.NoError(t, err)
+	_, err = ix.FetchPackage(ctx, other)
+	assert.Error(t, err, "missing packages are an error")
+}
@@ -0,0 +1,89 @@
+// Package blobstore stores bulk data which Tempest keeps whole, rather than
+// reading and writing in place: the archives of installed packages, and
+// grains' backups.
+//
+// By default, blobs are files in a directory on the server's disk. Large
+// deployments may keep them in an S3-compatible object store instead, so
+// that they needn't grow the server's disk with the number of apps and
+// backups. Packages are still unpacked to the local disk to run grains, but
+// the unpacked copy is only a cache, which is recreated from the archive if
+// it is missing.
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"time"
+
+	"sandstorm.org/go/tempest/internal/common/types"
+)
+
+// ErrNotFound is returned when reading a blob which doesn't exist.
+var ErrNotFound = errors.New("blobstore: no such blob")
+
+// A Store holds blobs, named by keys, which are slash-separated paths as
+// accepted by fs.ValidPath.
+type Store interface {
+	// Put stores the contents of r as the blob named key, replacing any
+	// blob already stored under that name. A blob which is being put
+	// doesn't appear to readers until Put returns successfully.
+	Put(ctx context.Context, key string, r io.Reader) error
+
+	// Get opens the blob named key for reading, or returns ErrNotFound.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete deletes the blob named key. Deleting a blob which doesn't
+	// exist is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// Backends, for Config.Backend:
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+// Config configures where blobs are stored.
+type Config struct {
+	// One of the Backend* constants. If empty, BackendLocal is used.
+	Backend string
+
+	// The directory in which blobs are stored, for BackendLocal.
+	Dir string
+
+	// The object store, for BackendS3.
+	S3 S3Config
+}
+
+// Open returns the store which cfg describes.
+func (cfg Config) Open() (Store, error) {
+	switch cfg.Backend {
+	case "", BackendLocal:
+		return NewLocal(cfg.Dir)
+	case BackendS3:
+		return NewS3(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown blob storage backend %q", cfg.Backend)
+	}
+}
+
+// checkKey returns an error if key isn't a valid name for a blob.
+func checkKey(key string) error {
+	if key == "." || !fs.ValidPath(key) {
+		return fmt.Errorf("blobstore: invalid key %q", key)
+	}
+	return nil
+}
+
+// PackageKey returns the key of the archive of the package with the given ID.
+func PackageKey(pkgID string) string {
+	return "packages/" + pkgID + ".spk"
+}
+
+// BackupKey returns the key of a backup of the grain taken at the given time.
+func BackupKey(grainID types.GrainID, at time.Time) string {
+	return "backups/" + string(grainID) + "/" + at.UTC().Format("20060102T150405Z") + ".zip"
+}
@@ -0,0 +1,61 @@
+package blobstore
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocal(t *testing.T) {
+	ctx := context.Background()
+	store, err := Config{Dir: t.TempDir()}.Open()
+	require.NoError(t, err)
+
+	_, err = store.Get(ctx, "packages/missing.spk")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, store.Put(ctx, "packages/a.spk", strings.NewReader("first")))
+	require.NoError(t, store.Put(ctx, "packages/a.spk", strings.NewReader("second")))
+	r, err := store.Get(ctx, "packages/a.spk")
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+
+	require.NoError(t, store.Delete(ctx, "packages/a.spk"))
+	_, err = store.Get(ctx, "packages/a.spk")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, store.Delete(ctx, "packages/a.spk"))
+
+	for _, key := range []string{"", ".", "../escape", "/abs", "a//b", "a/"} {
+		assert.Error(t, store.Put(ctx, key, strings.NewReader("x")), key)
+		_, err := store.Get(ctx, key)
+		assert.Error(t, err, key)
+	}
+}
+
+func TestConfigOpen(t *testing.T) {
+	_, err := Config{Backend: "floppy"}.Open()
+	assert.Error(t, err)
+	_, err = Config{Backend: BackendS3}.Open()
+	assert.Error(t, err, "a bucket is required")
+	store, err := Config{Backend: BackendS3, S3: S3Config{
+		Endpoint: "http://localhost:9000",
+		Region:   "us-east-1",
+		Bucket:   "tempest",
+	}}.Open()
+	require.NoError(t, err)
+	assert.IsType(t, &S3{}, store)
+}
+
+func TestBackupKey(t *testing.T) {
+	at := time.Date(2024, 3, 1, 12, 30, 45, 0, time.FixedZone("", 3600))
+	assert.Equal(t, "backups/abc/20240301T113045Z.zip", BackupKey("abc", at))
+	assert.NoError(t, checkKey(BackupKey("abc", at)))
+}
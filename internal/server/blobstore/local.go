@@ -0,0 +1,88 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Local stores blobs as files under a directory.
+type Local struct {
+	dir string
+}
+
+// NewLocal returns a store which keeps blobs under dir, creating it if need
+// be.
+func NewLocal(dir string) (*Local, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Local{dir: dir}, nil
+}
+
+func (l *Local) path(key string) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(key)), nil
+}
+
+// Put implements Store.Put. The blob is written to a temporary file, which
+// is renamed into place once complete.
+func (l *Local) Put(ctx context.Context, key string, r io.Reader) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".put-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = io.Copy(f, r)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Get implements Store.Get.
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Delete implements Store.Delete.
+func (l *Local) Delete(ctx context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,121 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// S3Config configures an S3-compatible object store.
+type S3Config struct {
+	// URL of the service, e.g. https://s3.us-west-002.backblazeb2.com. If
+	// empty, Amazon's endpoint for Region is used.
+	Endpoint string
+
+	Region string
+	Bucket string
+
+	// Blobs' keys are prefixed with this in the bucket, so that it may
+	// be shared with other things.
+	Prefix string
+
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// S3 stores blobs as objects in an S3-compatible object store.
+type S3 struct {
+	client   *s3.S3
+	uploader *s3manager.Uploader
+	bucket   string
+	prefix   string
+}
+
+// NewS3 returns a store which keeps blobs in the bucket cfg describes. It
+// doesn't contact the service; errors there show up when the store is used.
+func NewS3(cfg S3Config) (*S3, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("blobstore: no S3 bucket given")
+	}
+	awsCfg := aws.NewConfig().
+		WithRegion(cfg.Region).
+		WithCredentials(credentials.NewStaticCredentials(cfg.AccessKeyID, cfg.SecretAccessKey, ""))
+	if cfg.Endpoint != "" {
+		// Other services than Amazon's generally don't support
+		// virtual-hosted buckets.
+		awsCfg = awsCfg.WithEndpoint(cfg.Endpoint).WithS3ForcePathStyle(true)
+	}
+	sess, err := session.NewSession(awsCfg)
+	if err != nil {
+		return nil, err
+	}
+	client := s3.New(sess)
+	return &S3{
+		client:   client,
+		uploader: s3manager.NewUploaderWithClient(client),
+		bucket:   cfg.Bucket,
+		prefix:   cfg.Prefix,
+	}, nil
+}
+
+func (s *S3) objectKey(key string) (*string, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	return aws.String(s.prefix + key), nil
+}
+
+// Put implements Store.Put. Large blobs are uploaded in parts, so r needn't
+// be held in memory; the object appears once all of them are uploaded.
+func (s *S3) Put(ctx context.Context, key string, r io.Reader) error {
+	objKey, err := s.objectKey(key)
+	if err != nil {
+		return err
+	}
+	_, err = s.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket: aws.String(s.bucket),
+		Key:    objKey,
+		Body:   r,
+	})
+	return err
+}
+
+// Get implements Store.Get.
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	objKey, err := s.objectKey(key)
+	if err != nil {
+		return nil, err
+	}
+	out, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    objKey,
+	})
+	var aerr awserr.Error
+	if errors.As(err, &aerr) && aerr.Code() == s3.ErrCodeNoSuchKey {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return out.Body, nil
+}
+
+// Delete implements Store.Delete. S3 doesn't report whether the object
+// existed.
+func (s *S3) Delete(ctx context.Context, key string) error {
+	objKey, err := s.objectKey(key)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    objKey,
+	})
+	return err
+}
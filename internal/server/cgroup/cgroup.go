@@ -0,0 +1,262 @@
+// Package cgroup places grains in cgroup v2 cgroups, limiting the memory, CPU
+// time and processes each may use.
+//
+// Tempest doesn't manage the cgroup hierarchy as a whole; it is given a
+// cgroup, e.g. by systemd with Delegate=yes, in which it creates a child for
+// each running grain. Tempest's user must own that cgroup, and Tempest itself
+// must run in it or below it, but not in it directly: cgroup v2 doesn't allow
+// processes in a cgroup whose controllers are enabled for its children.
+package cgroup
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The controllers Setup enables for grains' cgroups.
+var controllers = []string{"cpu", "memory", "pids"}
+
+// DefaultCPUWeight is the kernel's default cpu.weight. Weights are relative:
+// a grain with twice the weight of another gets twice the CPU time when they
+// compete for it.
+const DefaultCPUWeight = 100
+
+// Limits are the resource limits of a grain. Zero fields are unlimited, or
+// for CPUWeight, the default.
+type Limits struct {
+	MemoryMax int64 // Bytes.
+	CPUWeight int   // 1 to 10000.
+	PidsMax   int64 // Processes, including threads.
+}
+
+// Override returns l, with any non-zero fields of o in place of its own.
+func (l Limits) Override(o Limits) Limits {
+	if o.MemoryMax != 0 {
+		l.MemoryMax = o.MemoryMax
+	}
+	if o.CPUWeight != 0 {
+		l.CPUWeight = o.CPUWeight
+	}
+	if o.PidsMax != 0 {
+		l.PidsMax = o.PidsMax
+	}
+	return l
+}
+
+// Validate returns an error if any of the limits is out of range.
+func (l Limits) Validate() error {
+	switch {
+	case l.MemoryMax < 0:
+		return errors.New("memory limit must not be negative")
+	case l.CPUWeight < 0 || l.CPUWeight > 10000:
+		return errors.New("CPU weight must be between 1 and 10000")
+	case l.PidsMax < 0:
+		return errors.New("process limit must not be negative")
+	}
+	return nil
+}
+
+// Config configures grains' cgroups.
+type Config struct {
+	// Path of the cgroup in which grains' cgroups are created. If this
+	// is empty, grains are not placed in cgroups.
+	Root string
+
+	// Limits of grains which don't have their own.
+	Defaults Limits
+}
+
+// Enabled reports whether grains are placed in cgroups.
+func (c Config) Enabled() bool {
+	return c.Root != ""
+}
+
+// Setup enables the controllers limits need in the children of root.
+func Setup(root string) error {
+	enable := "+" + strings.Join(controllers, " +")
+	if err := writeFile(filepath.Join(root, "cgroup.subtree_control"), enable); err != nil {
+		return fmt.Errorf("enabling cgroup controllers in %s: %w", root, err)
+	}
+	return nil
+}
+
+// A Group is a grain's cgroup.
+type Group struct {
+	dir string
+	f   *os.File
+
+	// OOM kills before Create; see OOMKills.
+	oomKillsBefore int
+}
+
+// Create creates the cgroup called name below root, or reuses it if it is
+// left over from a grain which didn't shut down cleanly, and applies limits
+// to it.
+func Create(root, name string, limits Limits) (*Group, error) {
+	dir := filepath.Join(root, name)
+	if err := os.Mkdir(dir, 0755); err != nil && !errors.Is(err, os.ErrExist) {
+		return nil, err
+	}
+	weight := limits.CPUWeight
+	if weight == 0 {
+		weight = DefaultCPUWeight
+	}
+	files := []struct {
+		name, value string
+		optional    bool // Missing if the kernel lacks the feature.
+	}{
+		{name: "memory.max", value: limitValue(limits.MemoryMax)},
+		// Otherwise the memory limit could be evaded by swapping.
+		{name: "memory.swap.max", value: "0", optional: true},
+		// Kill the whole grain on running out of memory, rather than
+		// leave it without whichever process the kernel chooses.
+		{name: "memory.oom.group", value: "1"},
+		{name: "cpu.weight", value: strconv.Itoa(weight)},
+		{name: "pids.max", value: limitValue(limits.PidsMax)},
+	}
+	for _, file := range files {
+		err := writeFile(filepath.Join(dir, file.name), file.value)
+		if file.optional && errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("setting %s of cgroup %s: %w", file.name, dir, err)
+		}
+	}
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	g := &Group{dir: dir, f: f}
+	g.oomKillsBefore, _ = g.countOOMKills()
+	return g, nil
+}
+
+// writeFile writes value to a cgroup's file, which must already exist; the
+// kernel creates them along with the cgroup.
+func writeFile(path, value string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteString(value)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// limitValue formats a limit for a cgroup's file, in which "max" is
+// unlimited.
+func limitValue(limit int64) string {
+	if limit == 0 {
+		return "max"
+	}
+	return strconv.FormatInt(limit, 10)
+}
+
+// Dir returns the cgroup's directory, open for passing to the sandbox
+// launcher, which joins it.
+func (g *Group) Dir() *os.File {
+	return g.f
+}
+
+// AddProcess moves the process with the given pid into the cgroup. Its
+// children, started afterwards, are in it too.
+func (g *Group) AddProcess(pid int) error {
+	return writeFile(filepath.Join(g.dir, "cgroup.procs"), strconv.Itoa(pid))
+}
+
+// OOMKills returns the number of times processes in the cgroup were killed
+// for exceeding its memory limit, since Create.
+func (g *Group) OOMKills() (int, error) {
+	n, err := g.countOOMKills()
+	return n - g.oomKillsBefore, err
+}
+
+// countOOMKills returns the kernel's count of OOM kills in the cgroup.
+func (g *Group) countOOMKills() (int, error) {
+	value, err := readKey(filepath.Join(g.dir, "memory.events"), "oom_kill")
+	if err != nil || value == "" {
+		return 0, err
+	}
+	return strconv.Atoi(value)
+}
+
+// readKey returns the value of key in a cgroup's file of "key value" lines,
+// such as memory.events, or "" if it isn't there.
+func readKey(path, key string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		k, value, _ := strings.Cut(scanner.Text(), " ")
+		if k == key {
+			return value, nil
+		}
+	}
+	return "", nil
+}
+
+// How long Freeze waits for a cgroup's processes to freeze, and how often it
+// checks.
+var (
+	freezeTimeout  = 10 * time.Second
+	freezeInterval = 10 * time.Millisecond
+)
+
+// Freeze freezes the processes in the cgroup called name below root, e.g. so
+// that a grain's files can be copied while it makes no changes, and returns a
+// function which thaws them. If there is no such cgroup, because the grain
+// isn't running, it returns a nil thaw.
+func Freeze(root, name string) (thaw func() error, err error) {
+	dir := filepath.Join(root, name)
+	freeze := filepath.Join(dir, "cgroup.freeze")
+	err = writeFile(freeze, "1")
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	thaw = func() error {
+		err := writeFile(freeze, "0")
+		if errors.Is(err, os.ErrNotExist) {
+			// The grain has since exited.
+			return nil
+		}
+		return err
+	}
+	// Freezing is asynchronous; it is done when cgroup.events says so.
+	deadline := time.Now().Add(freezeTimeout)
+	for {
+		frozen, err := readKey(filepath.Join(dir, "cgroup.events"), "frozen")
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		} else if err != nil {
+			thaw()
+			return nil, err
+		}
+		if frozen == "1" {
+			return thaw, nil
+		}
+		if time.Now().After(deadline) {
+			thaw()
+			return nil, fmt.Errorf("timed out freezing cgroup %s", dir)
+		}
+		time.Sleep(freezeInterval)
+	}
+}
+
+// Remove removes the cgroup. It fails if any processes are still in it.
+func (g *Group) Remove() error {
+	g.f.Close()
+	return os.Remove(g.dir)
+}
@@ -0,0 +1,106 @@
+package cgroup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCgroup creates the files the kernel would in a new cgroup.
+func fakeCgroup(t *testing.T, dir string, names ...string) {
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	for _, name := range names {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0644))
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestOverride(t *testing.T) {
+	defaults := Limits{MemoryMax: 1 << 30, CPUWeight: 100, PidsMax: 512}
+	assert.Equal(t, defaults, defaults.Override(Limits{}))
+	assert.Equal(t,
+		Limits{MemoryMax: 1 << 30, CPUWeight: 50, PidsMax: 1024},
+		defaults.Override(Limits{CPUWeight: 50, PidsMax: 1024}))
+	assert.Error(t, Limits{CPUWeight: 10001}.Validate())
+	assert.Error(t, Limits{MemoryMax: -1}.Validate())
+	assert.NoError(t, defaults.Validate())
+}
+
+func TestSetup(t *testing.T) {
+	root := t.TempDir()
+	assert.Error(t, Setup(root), "not a cgroup")
+	fakeCgroup(t, root, "cgroup.subtree_control")
+	require.NoError(t, Setup(root))
+	assert.Equal(t, "+cpu +memory +pids", readFile(t, filepath.Join(root, "cgroup.subtree_control")))
+}
+
+func TestCreate(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "grain1")
+	fakeCgroup(t, dir, "memory.max", "memory.oom.group", "cpu.weight", "pids.max", "memory.events",
+		"cgroup.procs")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "memory.events"),
+		[]byte("low 0\nhigh 0\nmax 3\noom 1\noom_kill 1\n"), 0644))
+
+	g, err := Create(root, "grain1", Limits{MemoryMax: 256 << 20})
+	require.NoError(t, err)
+	assert.Equal(t, "268435456", readFile(t, filepath.Join(dir, "memory.max")))
+	assert.Equal(t, "1", readFile(t, filepath.Join(dir, "memory.oom.group")))
+	assert.Equal(t, "100", readFile(t, filepath.Join(dir, "cpu.weight")))
+	assert.Equal(t, "max", readFile(t, filepath.Join(dir, "pids.max")))
+	fi, err := g.Dir().Stat()
+	require.NoError(t, err)
+	assert.True(t, fi.IsDir())
+	require.NoError(t, g.AddProcess(1234))
+	assert.Equal(t, "1234", readFile(t, filepath.Join(dir, "cgroup.procs")))
+
+	n, err := g.OOMKills()
+	require.NoError(t, err)
+	assert.Equal(t, 0, n, "kills from before Create aren't counted")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "memory.events"),
+		[]byte("low 0\nhigh 0\nmax 5\noom 2\noom_kill 3\n"), 0644))
+	n, err = g.OOMKills()
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	_, err = Create(root, "grain2", Limits{})
+	assert.Error(t, err, "the kernel didn't create the files")
+}
+
+func TestFreeze(t *testing.T) {
+	root := t.TempDir()
+	thaw, err := Freeze(root, "stopped")
+	require.NoError(t, err)
+	assert.Nil(t, thaw, "there is nothing to freeze")
+
+	dir := filepath.Join(root, "grain1")
+	fakeCgroup(t, dir, "cgroup.freeze", "cgroup.events")
+	events := filepath.Join(dir, "cgroup.events")
+	require.NoError(t, os.WriteFile(events, []byte("populated 1\nfrozen 0\n"), 0644))
+	go func() {
+		// The kernel reports the cgroup frozen a little later:
+		time.Sleep(5 * freezeInterval)
+		os.WriteFile(events, []byte("populated 1\nfrozen 1\n"), 0644)
+	}()
+	thaw, err = Freeze(root, "grain1")
+	require.NoError(t, err)
+	assert.Equal(t, "1", readFile(t, filepath.Join(dir, "cgroup.freeze")))
+	require.NoError(t, thaw())
+	assert.Equal(t, "0", readFile(t, filepath.Join(dir, "cgroup.freeze")))
+
+	defer func(timeout time.Duration) { freezeTimeout = timeout }(freezeTimeout)
+	freezeTimeout = 5 * freezeInterval
+	require.NoError(t, os.WriteFile(events, []byte("populated 1\nfrozen 0\n"), 0644))
+	_, err = Freeze(root, "grain1")
+	assert.Error(t, err)
+	assert.Equal(t, "0", readFile(t, filepath.Join(dir, "cgroup.freeze")), "thawed after timing out")
+}
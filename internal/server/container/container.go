@@ -3,10 +3,12 @@ package container
 
 import (
 	"context"
+	"errors"
 	"io"
 	"os"
 	"os/exec"
 	"strconv"
+	"time"
 
 	"capnproto.org/go/capnp/v3"
 	"capnproto.org/go/capnp/v3/rpc"
@@ -17,8 +19,10 @@ import (
 	"sandstorm.org/go/tempest/capnp/grain"
 	"sandstorm.org/go/tempest/internal/common/types"
 	"sandstorm.org/go/tempest/internal/config"
+	"sandstorm.org/go/tempest/internal/server/cgroup"
 	"sandstorm.org/go/tempest/internal/server/database"
 	"sandstorm.org/go/tempest/internal/server/logging"
+	"sandstorm.org/go/tempest/internal/server/seccomp"
 	"zenhack.net/go/util"
 	"zenhack.net/go/util/exn"
 )
@@ -26,8 +30,37 @@ import (
 // A Container is a reference to a running container/sandboxed grain.
 type Container struct {
 	Bootstrap capnp.Client       // Bootstrap interface for the Container.
+	PackageID string             // ID of the package the grain is running.
+	Timing    StartTiming        // How long it took to start the container.
 	cancel    context.CancelFunc // cancel causes the container to shut down.
 	exited    <-chan struct{}    // closed when the container has exited.
+	crashed   <-chan struct{}    // closed if the grain exited on its own.
+}
+
+// StartTiming records how long each phase of starting a container took. The
+// app's own initialization is not included, since the container doesn't know
+// when the app is ready; callers should measure that from Ready().
+type StartTiming struct {
+	// When Start was called.
+	Started time.Time
+
+	// Time spent looking up the grain's package in the database, and
+	// unpacking it again if need be; see Command.EnsurePackage.
+	Lookup time.Duration
+
+	// Time spent by the sandbox launcher setting up the sandbox, including
+	// mounting the package image, up until the grain agent was started.
+	// For a warm sandbox, this starts when it was claimed.
+	Sandbox time.Duration
+
+	// Whether the grain was started in a warm sandbox; see Pool.
+	Warm bool
+}
+
+// Ready returns the time at which the grain agent was started, and the app
+// began initializing.
+func (t StartTiming) Ready() time.Time {
+	return t.Started.Add(t.Lookup + t.Sandbox)
 }
 
 // Kill forcably shuts down the container. (Note: we do not provide a way
@@ -45,6 +78,17 @@ func (c Container) Wait() {
 	<-c.exited
 }
 
+// Exited returns a channel which is closed when the container has shut down.
+func (c Container) Exited() <-chan struct{} {
+	return c.exited
+}
+
+// Crashed returns a channel which is closed if the grain exits without Kill()
+// being called. The container then shuts down as if it had been killed.
+func (c Container) Crashed() <-chan struct{} {
+	return c.crashed
+}
+
 // A Command specifies a task to start in a container.
 type Command struct {
 	Log *slog.Logger
@@ -58,6 +102,26 @@ type Command struct {
 
 	// Args will be passed to the grain agent as extra arguments.
 	Args []string
+
+	// Cgroups configures the cgroup the grain is placed in, if enabled.
+	// The grain's own limits, if it has any, override the defaults.
+	Cgroups cgroup.Config
+
+	// Seccomp gives the grain extra system call rules, on top of the
+	// sandbox's built-in filter.
+	Seccomp seccomp.Policy
+
+	// Pool, if not nil, supplies warm sandboxes, which start faster.
+	Pool *Pool
+
+	// EnsurePackage, if not nil, is called with the ID of the grain's
+	// package before starting it, to unpack the package if it is missing
+	// from config.PackagesDir.
+	EnsurePackage func(ctx context.Context, pkgID string) error
+
+	// Output, if not nil, is also sent the output of the launcher, the
+	// grain agent and the app, and closed when it ends.
+	Output io.WriteCloser
 }
 
 // Start starts the container. It will shut down when ctx is canceled or
@@ -66,18 +130,38 @@ func (cmd Command) Start(ctx context.Context) (Container, error) {
 	cmd.Log.Info("Starting grain",
 		"grainID", cmd.GrainID,
 	)
+	started := time.Now()
 	return exn.Try(func(throw exn.Thrower) Container {
 		tx, err := cmd.DB.Begin()
 		throw(err)
 		defer tx.Rollback()
 		pkgID, err := tx.GrainPackageID(cmd.GrainID)
 		throw(err)
+		limits := cmd.Cgroups.Defaults
+		if cmd.Cgroups.Enabled() {
+			override, err := tx.GrainResourceLimits(cmd.GrainID)
+			throw(err)
+			limits = limits.Override(override)
+		}
+		var appID string
+		if !cmd.Seccomp.Empty() {
+			appID, err = tx.PackageAppID(pkgID)
+			throw(err)
+		}
 		throw(tx.Commit())
+		if cmd.EnsurePackage != nil {
+			throw(cmd.EnsurePackage(ctx, pkgID))
+		}
+		lookup := time.Since(started)
 		ret, err := pkgCommand{
 			Command: cmd,
 			PkgID:   pkgID,
+			Limits:  limits,
+			AppID:   appID,
 		}.Start(ctx)
 		throw(err)
+		ret.Timing.Started = started
+		ret.Timing.Lookup = lookup
 		return ret
 	})
 }
@@ -86,7 +170,9 @@ func (cmd Command) Start(ctx context.Context) (Container, error) {
 // up in the database is unnecessary.
 type pkgCommand struct {
 	Command
-	PkgID string
+	PkgID  string
+	Limits cgroup.Limits // Used if cmd.Cgroups is enabled.
+	AppID  string        // Used to look up cmd.Seccomp's rules.
 }
 
 // Start is like Command.Start
@@ -94,56 +180,57 @@ func (cmd pkgCommand) Start(ctx context.Context) (Container, error) {
 	// See the comments at the top of sandbox-launcher.c for the details
 	// of how the sandbox launcher is supposed to be used.
 	ctx, cancel := context.WithCancel(ctx)
-	// RPC socket:
-	fds, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_STREAM, 0)
-	if err != nil {
-		cmd.Api.Release()
-		return Container{}, err
-	}
-
-	grainSock := os.NewFile(uintptr(fds[0]), "grain api socket")
-	defer grainSock.Close()
-	supervisorSock := os.NewFile(uintptr(fds[1]), "supervisor api socket")
-
-	// Pipe to communicate the grain's PID:
-	pidR, pidW, err := os.Pipe()
-	if err != nil {
-		supervisorSock.Close()
-		return Container{}, err
-	}
-	defer pidR.Close()
-
-	args := append([]string{
-		cmd.PkgID,
-		string(cmd.GrainID),
-	}, cmd.Args...)
-	osCmd := exec.Command(
-		config.Libexecdir+"/tempest/tempest-sandbox-launcher",
-		args...,
-	)
-
-	// TODO(soon) capture/log stdout/stderr
-	osCmd.Stdout = os.Stdout
-	osCmd.Stderr = os.Stderr
-
-	osCmd.ExtraFiles = []*os.File{grainSock, pidW}
-	err = osCmd.Start()
-	pidW.Close() // Close this now, so when the child closes it we hit EOF.
+	prog, err := cmd.seccompProg()
 	if err != nil {
-		cmd.Log.Error("Starting sandbox launcher failed",
+		cmd.Log.Error("Compiling grain's system call rules failed",
 			"error", err,
 			"grainID", cmd.GrainID,
 		)
 		cmd.Api.Release()
-		supervisorSock.Close()
 		return Container{}, err
 	}
+	var group *cgroup.Group
+	if cmd.Cgroups.Enabled() {
+		group, err = cgroup.Create(cmd.Cgroups.Root, string(cmd.GrainID), cmd.Limits)
+		if err != nil {
+			cmd.Log.Error("Creating grain's cgroup failed",
+				"error", err,
+				"grainID", cmd.GrainID,
+			)
+			cmd.Api.Release()
+			return Container{}, err
+		}
+	}
+	launched := time.Now()
+	l, warm := cmd.claimWarm(group, prog)
+	if !warm {
+		l, err = cmd.startCold(group, prog)
+		if err != nil {
+			if group != nil {
+				group.Remove()
+			}
+			cmd.Log.Error("Starting sandbox launcher failed",
+				"error", err,
+				"grainID", cmd.GrainID,
+			)
+			cmd.Api.Release()
+			return Container{}, err
+		}
+	}
+	// The output of the launcher, the grain agent and the app goes to the
+	// log, one record per line, tagged with the grain, and to cmd.Output.
+	go l.logOutput(cmd.Log.With("grainID", cmd.GrainID), cmd.Output)
+	osCmd := l.cmd
+	supervisorSock := l.supervisorSock
 	cmd.Log.Debug("Started launcher proccess",
 		"launcher-pid", osCmd.Process.Pid,
 		"grainID", cmd.GrainID,
+		"warm", warm,
 	)
 
-	pidBuf, err := io.ReadAll(pidR)
+	pidBuf, err := io.ReadAll(l.pidR)
+	l.pidR.Close()
+	sandboxTime := time.Since(launched)
 	launcherPid := osCmd.Process.Pid
 	if err != nil {
 		cmd.Log.Error("Failed to read grain pid",
@@ -168,6 +255,9 @@ func (cmd pkgCommand) Start(ctx context.Context) (Container, error) {
 		util.Must(osCmd.Process.Wait())
 		return Container{}, err
 	}
+	if cmd.Pool != nil {
+		cmd.Pool.record(warm, sandboxTime)
+	}
 	grainProc, err := os.FindProcess(grainPid)
 	util.Chkfatal(err) // Can't fail on unix
 	cmd.Log.Debug("Started grain process",
@@ -175,6 +265,7 @@ func (cmd pkgCommand) Start(ctx context.Context) (Container, error) {
 		"packageId", cmd.PkgID,
 		"launcher-pid", launcherPid,
 		"grain-pid", grainPid,
+		"sandbox-setup-time", sandboxTime,
 	)
 	trans := transport.NewStream(supervisorSock)
 	options := &rpc.Options{
@@ -183,11 +274,29 @@ func (cmd pkgCommand) Start(ctx context.Context) (Container, error) {
 	conn := rpc.NewConn(trans, options)
 	grainBootstrap := conn.Bootstrap(ctx)
 	exited := make(chan struct{})
+	crashed := make(chan struct{})
+	go func() {
+		select {
+		case <-conn.Done():
+			// The grain hung up without being asked to shut down, so
+			// it has exited; clean up as if it had been killed.
+			if ctx.Err() == nil {
+				cmd.Log.Warn("Grain exited unexpectedly",
+					"grainID", cmd.GrainID,
+					"grain-pid", grainPid,
+				)
+				close(crashed)
+				cancel()
+			}
+		case <-ctx.Done():
+		}
+	}()
 	go func() {
 		<-ctx.Done()
 		// I(isd) don't see a sensible behavior if we fail to shut down the
-		// container, so panic I guess.
-		if err := grainProc.Kill(); err != nil {
+		// container, so panic I guess. If the grain has crashed, it is
+		// already gone, which is fine.
+		if err := grainProc.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
 			logging.Panic(cmd.Log, "Failed to kill grain",
 				"error", err,
 				"grainID", cmd.GrainID,
@@ -209,12 +318,199 @@ func (cmd pkgCommand) Start(ctx context.Context) (Container, error) {
 		cmd.Log.Debug("Wait()ed for launcher",
 			"pid", launcherPid,
 		)
+		if group != nil {
+			cmd.cleanUpCgroup(group)
+		}
 		<-conn.Done()
 		close(exited)
 	}()
 	return Container{
 		Bootstrap: grainBootstrap,
-		cancel:    cancel,
-		exited:    exited,
+		PackageID: cmd.PkgID,
+		Timing: StartTiming{
+			Started: launched,
+			Sandbox: sandboxTime,
+			Warm:    warm,
+		},
+		cancel:  cancel,
+		exited:  exited,
+		crashed: crashed,
 	}, nil
 }
+
+// seccompProg returns the grain's extra system call rules, compiled for the
+// launcher, or nil if it has none.
+func (cmd pkgCommand) seccompProg() ([]byte, error) {
+	rules := cmd.Seccomp.Rules(cmd.AppID, cmd.PkgID)
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	prog, err := seccomp.Compile(rules)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Log.Info("Applying extra system call rules",
+		"grainID", cmd.GrainID,
+		"appID", cmd.AppID,
+		"rules", len(rules),
+	)
+	return prog, nil
+}
+
+// seccompLayer returns a file containing prog, to pass to the launcher.
+func seccompLayer(prog []byte) (*os.File, error) {
+	fd, err := unix.MemfdCreate("seccomp layer", unix.MFD_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+	f := os.NewFile(uintptr(fd), "seccomp layer")
+	if _, err = f.Write(prog); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// startCold starts a sandbox launcher for the grain, which joins group, if
+// it isn't nil, and runs prog ahead of its own seccomp filter.
+func (cmd pkgCommand) startCold(group *cgroup.Group, prog []byte) (*launcher, error) {
+	// fds #5 and #6 are closed in the launcher unless they're used
+	// below.
+	extra := []*os.File{nil, nil}
+	if group != nil {
+		// The launcher joins the cgroup passed as fd #5.
+		extra[0] = group.Dir()
+	}
+	if prog != nil {
+		layer, err := seccompLayer(prog)
+		if err != nil {
+			return nil, err
+		}
+		defer layer.Close()
+		// The launcher runs the rules passed as fd #6 ahead of its
+		// own filter.
+		extra[1] = layer
+	}
+	args := append([]string{
+		cmd.PkgID,
+		string(cmd.GrainID),
+	}, cmd.Args...)
+	return startLauncher(args, extra...)
+}
+
+// claimWarm claims a warm sandbox from cmd.Pool for the grain, if one is
+// ready, returning false if not.
+func (cmd pkgCommand) claimWarm(group *cgroup.Group, prog []byte) (*launcher, bool) {
+	if cmd.Pool == nil {
+		return nil, false
+	}
+	l, err := cmd.Pool.claim(cmd.PkgID, cmd.GrainID, cmd.Args, group, prog)
+	if err != nil {
+		cmd.Log.Warn("Claiming a warm sandbox failed; starting a cold one",
+			"error", err,
+			"grainID", cmd.GrainID,
+		)
+		return nil, false
+	}
+	return l, l != nil
+}
+
+// A launcher is a running sandbox launcher, and our ends of the files it was
+// started with.
+type launcher struct {
+	cmd            *exec.Cmd
+	supervisorSock *os.File // Our end of the RPC socket, fd #3.
+	pidR           *os.File // The grain's pid is written to fd #4.
+	outR           *os.File // The launcher's stdout and stderr.
+	claimW         *os.File // Written to fd #7 in --warm mode; otherwise nil.
+}
+
+// startLauncher starts the sandbox launcher with the given arguments, passing
+// extra as fds #5 onwards.
+func startLauncher(args []string, extra ...*os.File) (*launcher, error) {
+	// RPC socket:
+	fds, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_STREAM, 0)
+	if err != nil {
+		return nil, err
+	}
+	grainSock := os.NewFile(uintptr(fds[0]), "grain api socket")
+	defer grainSock.Close()
+	l := &launcher{
+		supervisorSock: os.NewFile(uintptr(fds[1]), "supervisor api socket"),
+	}
+
+	// Pipe to communicate the grain's PID. We close our copy of the
+	// write end once the launcher has it, so when the launcher closes it
+	// we hit EOF.
+	pidR, pidW, err := os.Pipe()
+	if err != nil {
+		l.close()
+		return nil, err
+	}
+	defer pidW.Close()
+	l.pidR = pidR
+
+	outR, outW, err := os.Pipe()
+	if err != nil {
+		l.close()
+		return nil, err
+	}
+	defer outW.Close()
+	l.outR = outR
+
+	l.cmd = exec.Command(config.Libexecdir+"/tempest/tempest-sandbox-launcher", args...)
+	l.cmd.Stdout = outW
+	l.cmd.Stderr = outW
+	l.cmd.ExtraFiles = append([]*os.File{grainSock, pidW}, extra...)
+	if err = l.cmd.Start(); err != nil {
+		l.close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// logOutput logs the launcher's output to lg, one record per line, and
+// copies it to output if that is not nil, until it exits.
+func (l *launcher) logOutput(lg *slog.Logger, output io.WriteCloser) {
+	out := logging.NewLineWriter(lg, slog.LevelInfo, "Grain output")
+	var r io.Reader = l.outR
+	if output != nil {
+		r = io.TeeReader(r, output)
+		defer output.Close()
+	}
+	io.Copy(out, r)
+	out.Close()
+	l.outR.Close()
+}
+
+// close closes our ends of the launcher's files.
+func (l *launcher) close() {
+	l.supervisorSock.Close()
+	l.pidR.Close()
+	l.outR.Close()
+	l.claimW.Close()
+}
+
+// cleanUpCgroup reports whether the grain ran out of memory, which would
+// otherwise look like any other crash, and removes its cgroup. The grain
+// must have exited.
+func (cmd pkgCommand) cleanUpCgroup(group *cgroup.Group) {
+	if kills, err := group.OOMKills(); err != nil {
+		cmd.Log.Warn("Reading grain's OOM kills",
+			"error", err,
+			"grainID", cmd.GrainID,
+		)
+	} else if kills > 0 {
+		cmd.Log.Warn("Grain was killed for running out of memory",
+			"grainID", cmd.GrainID,
+			"memory-limit", cmd.Limits.MemoryMax,
+			"oom-kills", kills,
+		)
+	}
+	if err := group.Remove(); err != nil {
+		cmd.Log.Warn("Removing grain's cgroup",
+			"error", err,
+			"grainID", cmd.GrainID,
+		)
+	}
+}
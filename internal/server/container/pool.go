@@ -0,0 +1,192 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slog"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/cgroup"
+)
+
+// Much of the time it takes to start a grain is spent by the sandbox launcher
+// setting up namespaces, which doesn't depend on the grain. A Pool keeps a
+// few launchers which have done that much ready, in --warm mode, so that a
+// grain can claim one rather than starting a launcher from scratch. See the
+// comments at the top of sandbox-launcher.c.
+//
+// A warm launcher isn't in the grain's cgroup when started, since the grain
+// isn't known yet; it is moved there when claimed, before it has started
+// anything which would need to be in it.
+
+// How long to wait before trying again, after a warm launcher fails to start.
+const (
+	minPoolBackoff = time.Second
+	maxPoolBackoff = time.Minute
+)
+
+// A Pool keeps warm sandboxes ready for grains to claim.
+type Pool struct {
+	log   *slog.Logger
+	ready chan *launcher
+
+	mu         sync.Mutex
+	cold, warm StartStats
+}
+
+// StartStats summarizes the time taken to set up sandboxes.
+type StartStats struct {
+	Starts  int           // How many grains were started.
+	Sandbox time.Duration // Total time taken to set up their sandboxes.
+}
+
+// Mean returns the mean time taken to set up a sandbox, or zero if none were.
+func (s StartStats) Mean() time.Duration {
+	if s.Starts == 0 {
+		return 0
+	}
+	return s.Sandbox / time.Duration(s.Starts)
+}
+
+// NewPool returns a pool which keeps size warm sandboxes ready, once Serve is
+// called. size must be positive.
+func NewPool(lg *slog.Logger, size int) *Pool {
+	// One of the sandboxes waits in Serve, to be sent on ready.
+	return &Pool{
+		log:   lg,
+		ready: make(chan *launcher, size-1),
+	}
+}
+
+// Serve keeps the pool filled until ctx is canceled, then shuts down any warm
+// sandboxes which weren't claimed.
+func (p *Pool) Serve(ctx context.Context) {
+	defer func() {
+		for {
+			select {
+			case l := <-p.ready:
+				p.discard(l)
+			default:
+				return
+			}
+		}
+	}()
+	backoff := minPoolBackoff
+	for {
+		l, err := startWarm()
+		if err != nil {
+			p.log.Error("Starting warm sandbox failed",
+				"error", err,
+				"retry-in", backoff,
+			)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(2*backoff, maxPoolBackoff)
+			continue
+		}
+		backoff = minPoolBackoff
+		select {
+		case <-ctx.Done():
+			p.discard(l)
+			return
+		case p.ready <- l:
+		}
+	}
+}
+
+// startWarm starts a launcher in --warm mode.
+func startWarm() (*launcher, error) {
+	claimR, claimW, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	defer claimR.Close()
+	// fds #5 and #6 are unused in --warm mode; the claim goes to fd #7.
+	l, err := startLauncher([]string{"--warm"}, nil, nil, claimR)
+	if err != nil {
+		claimW.Close()
+		return nil, err
+	}
+	l.claimW = claimW
+	return l, nil
+}
+
+// claim claims a warm sandbox for the grain, moving it into group, if that
+// isn't nil, and telling it which grain to run. It returns nil if no warm
+// sandbox is ready.
+func (p *Pool) claim(
+	pkgID string,
+	grainID types.GrainID,
+	args []string,
+	group *cgroup.Group,
+	prog []byte,
+) (*launcher, error) {
+	var l *launcher
+	select {
+	case l = <-p.ready:
+	default:
+		return nil, nil
+	}
+	err := func() error {
+		if group != nil {
+			if err := group.AddProcess(l.cmd.Process.Pid); err != nil {
+				return err
+			}
+		}
+		_, err := l.claimW.Write(encodeClaim(pkgID, grainID, args, prog))
+		return errors.Join(err, l.claimW.Close())
+	}()
+	if err != nil {
+		p.discard(l)
+		return nil, err
+	}
+	l.claimW = nil
+	return l, nil
+}
+
+// encodeClaim encodes a claim, in the format read by the launcher.
+func encodeClaim(pkgID string, grainID types.GrainID, args []string, prog []byte) []byte {
+	var buf []byte
+	for _, s := range append([]string{
+		strconv.Itoa(len(prog)),
+		pkgID,
+		string(grainID),
+	}, args...) {
+		buf = append(buf, s...)
+		buf = append(buf, 0)
+	}
+	return append(buf, prog...)
+}
+
+// discard shuts down a warm sandbox which won't be used.
+func (p *Pool) discard(l *launcher) {
+	l.close()
+	l.cmd.Process.Kill()
+	l.cmd.Wait()
+}
+
+// record records the time taken to set up a sandbox.
+func (p *Pool) record(warm bool, sandbox time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := &p.cold
+	if warm {
+		s = &p.warm
+	}
+	s.Starts++
+	s.Sandbox += sandbox
+}
+
+// Stats returns statistics on grains started in cold and warm sandboxes.
+func (p *Pool) Stats() (cold, warm StartStats) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.cold, p.warm
+}
@@ -4,6 +4,10 @@
 // SQL strewn throughout the codebase. This package provides some wrapper
 // objects for the database & transactions, which have methods for the
 // queries we want to support.
+//
+// The schema is versioned, and databases are brought up to date by
+// migrations when opened; see migrations.go. Grains' own data stays on disk,
+// outside the database.
 package database
 
 import (
@@ -18,8 +22,14 @@ const (
 	DBPath = config.Localstatedir + "/sandstorm/sandstorm.sqlite3"
 )
 
+// Open opens the database at DBPath.
 func Open() (DB, error) {
-	sqlDB, err := sql.Open("sqlite3", DBPath)
+	return OpenPath(DBPath)
+}
+
+// OpenPath opens the database at the given path, initializing it if needed.
+func OpenPath(path string) (DB, error) {
+	sqlDB, err := sql.Open("sqlite3", path)
 	if err != nil {
 		return DB{}, err
 	}
@@ -29,6 +39,7 @@ func Open() (DB, error) {
 // Wrapper object around a SQL database.
 type DB struct {
 	sqlDB *sql.DB
+	wal   *WAL
 }
 
 // Transaction
@@ -54,5 +65,16 @@ func (tx Tx) Rollback() error {
 
 // Closes the underlying database
 func (db DB) Close() error {
+	if db.wal != nil {
+		db.wal.conn.Close()
+	}
 	return db.sqlDB.Close()
 }
+
+// Snapshot writes a consistent copy of the database to the file at path,
+// which must not already exist. It is safe to call while other transactions
+// are in progress.
+func (db DB) Snapshot(path string) error {
+	_, err := db.sqlDB.Exec(`VACUUM INTO ?`, path)
+	return err
+}
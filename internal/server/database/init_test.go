@@ -2,10 +2,12 @@ package database
 
 import (
 	"database/sql"
+	"fmt"
 	"testing"
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Try initializing the database, make sure it succeeds
@@ -16,3 +18,80 @@ func TestInit(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NoError(t, db.Close())
 }
+
+// Take a snapshot of a database, and make sure the copy can be opened.
+func TestSnapshot(t *testing.T) {
+	sqlDB, err := sql.Open("sqlite3", ":memory:")
+	assert.NoError(t, err)
+	db, err := InitDB(sqlDB)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	path := t.TempDir() + "/snapshot.sqlite3"
+	assert.NoError(t, db.Snapshot(path))
+
+	copyDB, err := sql.Open("sqlite3", path)
+	assert.NoError(t, err)
+	defer copyDB.Close()
+	var n int
+	assert.NoError(t, copyDB.QueryRow(
+		`SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'grains'`,
+	).Scan(&n))
+	assert.Equal(t, 1, n)
+}
+
+func openSQLTestDB(t *testing.T, path string) *sql.DB {
+	sqlDB, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	return sqlDB
+}
+
+func userVersion(t *testing.T, sqlDB *sql.DB) int {
+	var version int
+	require.NoError(t, sqlDB.QueryRow("PRAGMA user_version").Scan(&version))
+	return version
+}
+
+// Migrate a new database, then check that migrating it again does nothing.
+func TestMigrate(t *testing.T) {
+	sqlDB := openSQLTestDB(t, t.TempDir()+"/db.sqlite3")
+	require.NoError(t, migrate(sqlDB))
+	assert.Equal(t, len(migrations), userVersion(t, sqlDB))
+	require.NoError(t, migrate(sqlDB))
+	assert.Equal(t, len(migrations), userVersion(t, sqlDB))
+
+	var n int
+	require.NoError(t, sqlDB.QueryRow(
+		`SELECT count(*) FROM sqlite_master WHERE type = 'index' AND name = 'grainsByOwner'`,
+	).Scan(&n))
+	assert.Equal(t, 1, n)
+}
+
+// A database from before schema versions were recorded has the tables of
+// the first version, and version 0; migrating it must keep its data.
+func TestMigrateUnversioned(t *testing.T) {
+	sqlDB := openSQLTestDB(t, t.TempDir()+"/db.sqlite3")
+	tx, err := sqlDB.Begin()
+	require.NoError(t, err)
+	require.NoError(t, createSchema(tx))
+	_, err = tx.Exec(`INSERT INTO accounts (id, role, profile) VALUES ('alice', 'user', x'')`)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	require.Equal(t, 0, userVersion(t, sqlDB))
+
+	require.NoError(t, migrate(sqlDB))
+	assert.Equal(t, len(migrations), userVersion(t, sqlDB))
+	var n int
+	require.NoError(t, sqlDB.QueryRow(`SELECT count(*) FROM accounts`).Scan(&n))
+	assert.Equal(t, 1, n)
+}
+
+// A database written by a newer version of Tempest must not be opened.
+func TestMigrateNewer(t *testing.T) {
+	sqlDB := openSQLTestDB(t, t.TempDir()+"/db.sqlite3")
+	require.NoError(t, migrate(sqlDB))
+	_, err := sqlDB.Exec(fmt.Sprintf("PRAGMA user_version = %d", len(migrations)+1))
+	require.NoError(t, err)
+	assert.ErrorContains(t, migrate(sqlDB), "newer")
+}
@@ -0,0 +1,273 @@
+package legacy
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"zenhack.net/go/util/exn"
+)
+
+// apiToken is the subset of a document in Sandstorm's apiTokens collection
+// which we can map to Tempest.
+type apiToken struct {
+	// ID is the base64-encoded SHA-256 hash of the token; Sandstorm never
+	// stores the token itself.
+	ID      string
+	GrainID types.GrainID
+	Petname string
+	Expires time.Time
+
+	Revoked bool
+	Trashed bool
+
+	// ParentToken is the ID of the token this one was derived from, e.g.
+	// the sharing link a user opened. Its permissions limit this token's.
+	ParentToken string
+
+	// OwnerType is the key of the token's owner union: "webkey", "user",
+	// "grain", "clientPowerboxRequest", etc. Tokens without an owner are
+	// webkeys.
+	OwnerType      string
+	ForSharing     bool
+	OwnerAccountID types.AccountID
+
+	// HasObjectID is set for tokens to capabilities other than a grain's
+	// UiView, e.g. from the powerbox.
+	HasObjectID    bool
+	HasFrontendRef bool
+
+	RoleAssignment bson.Raw
+}
+
+// live reports whether t can still be used.
+func (t apiToken) live() bool {
+	expired := !t.Expires.IsZero() && t.Expires.Before(time.Now())
+	return !t.Revoked && !t.Trashed && !expired
+}
+
+func decodeAPIToken(raw bson.Raw) (apiToken, error) {
+	return exn.Try(func(throw exn.Thrower) (ret apiToken) {
+		elts, err := raw.Elements()
+		throw(err)
+
+		ret.OwnerType = "webkey"
+		for _, e := range elts {
+			switch e.Key() {
+			case "_id":
+				ret.ID = e.Value().StringValue()
+			case "grainId":
+				ret.GrainID = types.GrainID(e.Value().StringValue())
+			case "petname":
+				ret.Petname = e.Value().StringValue()
+			case "expires":
+				ret.Expires = e.Value().Time()
+			case "revoked":
+				ret.Revoked = e.Value().Boolean()
+			case "trashed":
+				ret.Trashed = true
+			case "parentToken":
+				ret.ParentToken = e.Value().StringValue()
+			case "objectId":
+				ret.HasObjectID = true
+			case "frontendRef":
+				ret.HasFrontendRef = true
+			case "roleAssignment":
+				ret.RoleAssignment = e.Value().Document()
+			case "owner":
+				oelts, err := e.Value().Document().Elements()
+				throw(err)
+				for _, oe := range oelts {
+					ret.OwnerType = oe.Key()
+					switch oe.Key() {
+					case "webkey":
+						if v, err := oe.Value().Document().LookupErr("forSharing"); err == nil {
+							ret.ForSharing = v.Boolean()
+						}
+					case "user":
+						if v, err := oe.Value().Document().LookupErr("accountId"); err == nil {
+							ret.OwnerAccountID = types.AccountID(v.StringValue())
+						}
+					}
+				}
+			}
+		}
+		return
+	})
+}
+
+// importAPITokens imports sharing links, and the grains which have been
+// shared with each user. Tokens for the other kinds of Sandstorm
+// capabilities, e.g. powerbox grants, are reported.
+func (im *importer) importAPITokens() error {
+	return exn.Try0(func(throw exn.Thrower) {
+		_, err := os.Stat(filepath.Join(im.snapshotDir, "apiTokens"))
+		if os.IsNotExist(err) {
+			// Mongo has no collection until a token is created.
+			return
+		}
+		throw(err)
+		var tokens []apiToken
+		throw(im.eachEntry("apiTokens", func(raw bson.Raw) {
+			t, err := decodeAPIToken(raw)
+			throw(err)
+			tokens = append(tokens, t)
+		}))
+		byID := make(map[string]apiToken, len(tokens))
+		for _, t := range tokens {
+			byID[t.ID] = t
+		}
+
+		for _, t := range tokens {
+			if !t.live() {
+				continue
+			}
+			if reason := im.unsupportedToken(t); reason != "" {
+				im.report.skip("apiTokens", t.ID, "%s", reason)
+				continue
+			}
+			perms, err := im.tokenPermissions(byID, t)
+			if err != nil {
+				im.report.skip("apiTokens", t.ID, "%v", err)
+				continue
+			}
+			if t.OwnerType == "user" {
+				entry := keyringEntry{t.OwnerAccountID, t.GrainID}
+				if im.attached[entry] {
+					im.report.skip("apiTokens", t.ID,
+						"account %s already has access to grain %s; only one grant per grain is imported",
+						t.OwnerAccountID, t.GrainID)
+					continue
+				}
+				throw(im.tx.AccountKeyring(t.OwnerAccountID).AttachGrain(t.GrainID, perms))
+				im.attached[entry] = true
+				im.report.imported("shared grains")
+				continue
+			}
+
+			hash, err := base64.StdEncoding.DecodeString(t.ID)
+			if err != nil || len(hash) != sha256.Size {
+				im.report.skip("apiTokens", t.ID, "ID is not a base64-encoded SHA-256 hash")
+				continue
+			}
+			note := t.Petname
+			if note == "" {
+				note = "Imported from Sandstorm"
+			}
+			expires := t.Expires
+			if expires.IsZero() {
+				expires = time.Unix(math.MaxInt64, 0) // never
+			}
+			throw(im.tx.AddSharingTokenHash([sha256.Size]byte(hash), t.GrainID, perms, note, expires))
+			im.report.imported("sharing links")
+		}
+	})
+}
+
+// unsupportedToken returns why t can't be imported, or "" if it can.
+func (im *importer) unsupportedToken(t apiToken) string {
+	switch {
+	case t.GrainID == "":
+		return "not a grain token, e.g. an app or admin token"
+	case t.HasObjectID || t.HasFrontendRef:
+		return "capabilities other than a grain's UI are not supported"
+	case t.OwnerType == "webkey" && !t.ForSharing:
+		return "HTTP API webkeys are not supported"
+	case t.OwnerType == "user" && t.OwnerAccountID == "":
+		return "owned by an identity rather than an account"
+	case t.OwnerType != "webkey" && t.OwnerType != "user":
+		return fmt.Sprintf("tokens owned by %s are not supported", t.OwnerType)
+	}
+	if _, ok := im.grains[t.GrainID]; !ok {
+		return fmt.Sprintf("grain %s was not imported", t.GrainID)
+	}
+	if t.OwnerType == "user" && !im.accounts[t.OwnerAccountID] {
+		return fmt.Sprintf("account %s was not imported", t.OwnerAccountID)
+	}
+	return ""
+}
+
+// maxTokenDepth bounds the chains of parent tokens which tokenPermissions
+// follows, in case they are cyclic.
+const maxTokenDepth = 64
+
+// tokenPermissions returns the permissions which t grants on its grain: those
+// of its role assignment, limited by those of its parent tokens.
+func (im *importer) tokenPermissions(byID map[string]apiToken, t apiToken) ([]bool, error) {
+	grain := im.grains[t.GrainID]
+	var ret []bool
+	for depth := 0; ; depth++ {
+		if depth == maxTokenDepth {
+			return nil, errors.New("too many parent tokens")
+		}
+		perms, err := roleAssignmentPermissions(t.RoleAssignment, grain)
+		if err != nil {
+			return nil, err
+		}
+		if ret == nil {
+			ret = perms
+		} else {
+			for i := range ret {
+				ret[i] = ret[i] && perms[i]
+			}
+		}
+		if t.ParentToken == "" {
+			return ret, nil
+		}
+		parent, ok := byID[t.ParentToken]
+		if !ok || !parent.live() {
+			return nil, fmt.Errorf("parent token %s is missing, revoked or expired", t.ParentToken)
+		}
+		if parent.GrainID != t.GrainID {
+			return nil, fmt.Errorf("parent token %s is for a different grain", t.ParentToken)
+		}
+		t = parent
+	}
+}
+
+// roleAssignmentPermissions decodes a RoleAssignment: all access, one of the
+// grain's roles, or none, plus and minus individual permissions.
+func roleAssignmentPermissions(ra bson.Raw, grain legacyGrain) ([]bool, error) {
+	if ra == nil {
+		return nil, errors.New("no role assignment")
+	}
+	if !grain.hasViewInfo {
+		return nil, errors.New("the grain's permissions are unknown, since Sandstorm never recorded them")
+	}
+	return exn.Try(func(throw exn.Thrower) []bool {
+		ret := make([]bool, grain.permissions)
+		if _, err := ra.LookupErr("allAccess"); err == nil {
+			for i := range ret {
+				ret[i] = true
+			}
+		} else if v, err := ra.LookupErr("roleId"); err == nil {
+			roleID, ok := v.AsInt64OK()
+			if !ok || roleID < 0 || roleID >= int64(len(grain.roles)) {
+				throw(fmt.Errorf("no such role: %v", v))
+			}
+			copy(ret, grain.roles[roleID])
+		}
+		if v, err := ra.LookupErr("addPermissions"); err == nil {
+			add, err := decodePermissionSet(v)
+			throw(err)
+			for i := range ret {
+				ret[i] = ret[i] || (i < len(add) && add[i])
+			}
+		}
+		if v, err := ra.LookupErr("removePermissions"); err == nil {
+			remove, err := decodePermissionSet(v)
+			throw(err)
+			for i := range ret {
+				ret[i] = ret[i] && !(i < len(remove) && remove[i])
+			}
+		}
+		return ret
+	})
+}
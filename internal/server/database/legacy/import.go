@@ -3,7 +3,6 @@ package legacy
 import (
 	"database/sql"
 	"encoding/binary"
-	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -36,8 +35,11 @@ func (it iter) readValue() (bson.Raw, error) {
 	return bson.Raw(ret), err
 }
 
-func Import(sqlitePath, snapshotDir string) error {
-	return exn.Try0(func(throw exn.Thrower) {
+// Import reads a snapshot made by Export into the sqlite database at
+// sqlitePath. Records which can't be mapped to Tempest are skipped, and
+// listed in the returned report.
+func Import(sqlitePath, snapshotDir string) (*Report, error) {
+	return exn.Try(func(throw exn.Thrower) *Report {
 		sqliteDB, err := sql.Open("sqlite3", sqlitePath)
 		throw(err)
 		db, err := database.InitDB(sqliteDB)
@@ -45,16 +47,60 @@ func Import(sqlitePath, snapshotDir string) error {
 		tx, err := db.Begin()
 		throw(err)
 		defer tx.Rollback()
-		throw(importUsers(snapshotDir, tx))
-		throw(importPackages(snapshotDir, tx))
-		throw(importGrains(snapshotDir, tx))
+		im := &importer{
+			tx:          tx,
+			snapshotDir: snapshotDir,
+			report:      newReport(),
+			accounts:    make(map[types.AccountID]bool),
+			packages:    make(map[types.ID[database.Package]]bool),
+			grains:      make(map[types.GrainID]legacyGrain),
+			attached:    make(map[keyringEntry]bool),
+		}
+		throw(im.importUsers())
+		throw(im.importPackages())
+		throw(im.importGrains())
+		throw(im.importAPITokens())
 		throw(tx.Commit())
+		return im.report
 	})
 }
 
-func eachEntry(snapshotDir, collection string, tx database.Tx, fn func(bson.Raw)) error {
+// An importer holds the state of an Import: what has been imported so far,
+// for resolving references between collections.
+type importer struct {
+	tx          database.Tx
+	snapshotDir string
+	report      *Report
+
+	accounts map[types.AccountID]bool
+	packages map[types.ID[database.Package]]bool
+	grains   map[types.GrainID]legacyGrain
+
+	// Keyring entries added so far. An account can only have one entry
+	// for each grain.
+	attached map[keyringEntry]bool
+}
+
+type keyringEntry struct {
+	accountID types.AccountID
+	grainID   types.GrainID
+}
+
+// legacyGrain is what we need to know about an imported grain to import the
+// tokens which share it.
+type legacyGrain struct {
+	ownerID types.AccountID
+
+	// From the grain's cachedViewInfo; hasViewInfo is false if Sandstorm
+	// never recorded it, in which case the grain's permissions are unknown.
+	hasViewInfo bool
+	permissions int
+	roles       [][]bool
+}
+
+func (im *importer) eachEntry(collection string, fn func(bson.Raw)) error {
 	return exn.Try0(func(throw exn.Thrower) {
-		f, err := os.Open(filepath.Join(snapshotDir, collection))
+		f, err := os.Open(filepath.Join(im.snapshotDir, collection))
 		throw(err)
 		defer f.Close()
 		it := iter{r: f}
@@ -189,13 +235,13 @@ type credentialOwner struct {
 	login     bool
 }
 
-func importUsers(snapshotDir string, tx database.Tx) error {
+func (im *importer) importUsers() error {
 	// Mapping from credential ids to info about their owner:
 	credentialOwners := make(map[string]credentialOwner)
 
 	return exn.Try0(func(throw exn.Thrower) {
 		// We do this in two passes, accounts first, then credentials.
-		throw(eachEntry(snapshotDir, "users", tx, func(raw bson.Raw) {
+		throw(im.eachEntry("users", func(raw bson.Raw) {
 			u, err := decodeUser(raw)
 			throw(err)
 
@@ -209,11 +255,13 @@ func importUsers(snapshotDir string, tx database.Tx) error {
 			throw(err)
 			throw(displayName.SetDefaultText(u.Profile.DisplayName))
 			throw(profile.SetPreferredHandle(u.Profile.PreferredHandle))
-			throw(tx.AddAccount(database.NewAccount{
+			throw(im.tx.AddAccount(database.NewAccount{
 				ID:      types.AccountID(u.ID),
 				Role:    u.Role,
 				Profile: profile,
 			}))
+			im.accounts[types.AccountID(u.ID)] = true
+			im.report.imported("accounts")
 			// Store these for lookup during the second pass:
 			for _, credID := range u.LoginCredentials {
 				credentialOwners[credID] = credentialOwner{
@@ -228,14 +276,18 @@ func importUsers(snapshotDir string, tx database.Tx) error {
 				}
 			}
 		}))
-		throw(eachEntry(snapshotDir, "users", tx, func(raw bson.Raw) {
+		throw(im.eachEntry("users", func(raw bson.Raw) {
 			u, err := decodeUser(raw)
 			throw(err)
 
 			if u.Type != "credential" {
 				return
 			}
-			owner := credentialOwners[u.ID]
+			owner, ok := credentialOwners[u.ID]
+			if !ok {
+				im.report.skip("users", u.ID, "credential doesn't belong to any account")
+				return
+			}
 			var entry database.NewCredential
 			entry.AccountID = types.AccountID(owner.accountID)
 			entry.Login = owner.login
@@ -250,52 +302,63 @@ func importUsers(snapshotDir string, tx database.Tx) error {
 					ScopedID: u.Services.Email.Email,
 				}
 			} else {
-				fmt.Println("TODO: add support for other credential types (skipping)")
+				// TODO: handle github, google, etc.
+				im.report.skip("users", u.ID,
+					"unsupported credential type for account %s; only dev and email credentials are imported",
+					owner.accountID)
 				return
 			}
-			throw(tx.AddCredential(entry))
+			throw(im.tx.AddCredential(entry))
+			im.report.imported("credentials")
 		}))
 	})
 }
 
-func importPackages(snapshotDir string, tx database.Tx) error {
+func (im *importer) importPackages() error {
 	return exn.Try0(func(throw exn.Thrower) {
-		throw(eachEntry(snapshotDir, "packages", tx, func(raw bson.Raw) {
-			elts, err := raw.Elements()
+		throw(im.eachEntry("packages", func(raw bson.Raw) {
+			idVal, err := raw.LookupErr("_id")
 			throw(err)
-
-			for _, e := range elts {
-				if e.Key() == "_id" {
-					id := types.ID[database.Package](e.Value().StringValue())
-					path := config.Localstatedir +
-						"/sandstorm/apps/" +
-						string(id) +
-						"/sandstorm-manifest"
-					buf, err := os.ReadFile(path)
-					throw(err)
-					msg, err := capnp.Unmarshal(buf)
-					throw(err)
-					manifest, err := spk.ReadRootManifest(msg)
-					throw(err)
-					throw(tx.AddPackage(database.Package{
-						ID:       id,
-						Manifest: manifest,
-					}))
-					throw(tx.ReadyPackage(id))
-					break
-				}
+			id := types.ID[database.Package](idVal.StringValue())
+			path := filepath.Join(config.PackagesDir, string(id), "sandstorm-manifest")
+			buf, err := os.ReadFile(path)
+			if os.IsNotExist(err) {
+				im.report.skip("packages", string(id), "%s is missing", path)
+				return
+			}
+			throw(err)
+			msg, err := capnp.Unmarshal(buf)
+			throw(err)
+			manifest, err := spk.ReadRootManifest(msg)
+			throw(err)
+			// Sandstorm verified the package's signature when it
+			// was installed, and recorded the app ID:
+			var appID string
+			if v, err := raw.LookupErr("appId"); err == nil {
+				appID = v.StringValue()
 			}
+			throw(im.tx.AddPackage(database.Package{
+				ID:       id,
+				Manifest: manifest,
+				AppID:    appID,
+			}))
+			throw(im.tx.ReadyPackage(id))
+			im.packages[id] = true
+			im.report.imported("packages")
 		}))
 	})
 }
 
-func importGrains(snapshotDir string, tx database.Tx) error {
+func (im *importer) importGrains() error {
 	return exn.Try0(func(throw exn.Thrower) {
-		throw(eachEntry(snapshotDir, "grains", tx, func(raw bson.Raw) {
+		throw(im.eachEntry("grains", func(raw bson.Raw) {
 			elts, err := raw.Elements()
 			throw(err)
 
-			var grain database.NewGrain
+			var (
+				grain database.NewGrain
+				info  legacyGrain
+			)
 
 			for _, e := range elts {
 				switch e.Key() {
@@ -307,10 +370,73 @@ func importGrains(snapshotDir string, tx database.Tx) error {
 					grain.Title = e.Value().StringValue()
 				case "userId":
 					grain.OwnerID = types.AccountID(e.Value().StringValue())
+				case "cachedViewInfo":
+					info, err = decodeViewInfo(e.Value().Document())
+					throw(err)
 				}
 			}
 
-			throw(tx.AddGrain(grain))
+			id := string(grain.GrainID)
+			if !im.packages[grain.PkgID] {
+				im.report.skip("grains", id, "package %s was not imported", grain.PkgID)
+				return
+			}
+			if !im.accounts[grain.OwnerID] {
+				im.report.skip("grains", id, "owner %s was not imported", grain.OwnerID)
+				return
+			}
+			_, err = os.Stat(filepath.Join(config.GrainsDir, id))
+			if os.IsNotExist(err) {
+				im.report.skip("grains", id, "grain storage is missing from %s", config.GrainsDir)
+				return
+			}
+			throw(err)
+
+			throw(im.tx.AddGrain(grain))
+			info.ownerID = grain.OwnerID
+			im.grains[grain.GrainID] = info
+			im.attached[keyringEntry{grain.OwnerID, grain.GrainID}] = true
+			im.report.imported("grains")
 		}))
 	})
 }
+
+// decodeViewInfo decodes a grain's cachedViewInfo: the permissions and roles
+// which the app declared.
+func decodeViewInfo(doc bson.Raw) (legacyGrain, error) {
+	return exn.Try(func(throw exn.Thrower) (ret legacyGrain) {
+		ret.hasViewInfo = true
+		if perms, err := doc.LookupErr("permissions"); err == nil {
+			vals, err := perms.Array().Values()
+			throw(err)
+			ret.permissions = len(vals)
+		}
+		if roles, err := doc.LookupErr("roles"); err == nil {
+			vals, err := roles.Array().Values()
+			throw(err)
+			for _, v := range vals {
+				var perms []bool
+				if p, err := v.Document().LookupErr("permissions"); err == nil {
+					perms, err = decodePermissionSet(p)
+					throw(err)
+				}
+				ret.roles = append(ret.roles, perms)
+			}
+		}
+		return
+	})
+}
+
+// decodePermissionSet decodes a PermissionSet, which Sandstorm stores as an
+// array of booleans.
+func decodePermissionSet(v bson.RawValue) ([]bool, error) {
+	return exn.Try(func(throw exn.Thrower) []bool {
+		vals, err := v.Array().Values()
+		throw(err)
+		ret := make([]bool, len(vals))
+		for i, val := range vals {
+			ret[i] = val.Boolean()
+		}
+		return ret
+	})
+}
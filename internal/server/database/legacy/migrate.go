@@ -0,0 +1,145 @@
+package legacy
+
+import (
+	"bufio"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"zenhack.net/go/util/exn"
+)
+
+// MigrateOptions configures Migrate.
+type MigrateOptions struct {
+	// MongoPort and PasswdFile locate Sandstorm's Mongo database; see
+	// Export.
+	MongoPort  int
+	PasswdFile string
+
+	// SnapshotDir is where the database is exported to before import.
+	SnapshotDir string
+
+	// SandstormConf is the path to sandstorm.conf. Sandstorm's var
+	// directory, which holds its sandcats certificates, is expected next
+	// to it, as in a standard installation.
+	SandstormConf string
+
+	// SqlitePath is the path to Tempest's database.
+	SqlitePath string
+}
+
+// Migrate moves a Sandstorm server's data to Tempest: it exports Sandstorm's
+// Mongo database, imports it, and translates sandstorm.conf into Tempest
+// settings. Grains and apps are used in place, since Tempest stores them in
+// the same layout as Sandstorm.
+func Migrate(opts MigrateOptions) (*Report, error) {
+	return exn.Try(func(throw exn.Thrower) *Report {
+		throw(Export(opts.MongoPort, opts.PasswdFile, opts.SnapshotDir), "exporting mongo database")
+		report, err := Import(opts.SqlitePath, opts.SnapshotDir)
+		throw(err, "importing mongo snapshot")
+		conf, err := readSandstormConf(opts.SandstormConf)
+		if os.IsNotExist(err) {
+			report.note("%s not found; no settings were derived from it", opts.SandstormConf)
+			return report
+		}
+		throw(err)
+		report.addSandstormConf(conf)
+		sandcatsDir := filepath.Join(filepath.Dir(opts.SandstormConf), "var", "sandcats")
+		if _, ok := conf["SANDCATS_BASE_DOMAIN"]; ok {
+			report.note("This server used sandcats.io, whose certificates are in %s. "+
+				"Tempest doesn't update sandcats.io's DNS or renew its certificates; "+
+				"use a domain you control with ACME_EMAIL and ACME_DNS_PROVIDER, "+
+				"or set HTTPS_CERT_FILE and HTTPS_KEY_FILE", sandcatsDir)
+		}
+		return report
+	})
+}
+
+// readSandstormConf reads the KEY=value lines of sandstorm.conf.
+func readSandstormConf(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	ret := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		ret[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return ret, scanner.Err()
+}
+
+// sandstormConfIgnored are sandstorm.conf options which have no Tempest
+// setting, but don't need the administrator's attention either.
+var sandstormConfIgnored = map[string]bool{
+	"SERVER_USER":          true,
+	"MONGO_PORT":           true,
+	"BIND_IP":              true,
+	"UPDATE_CHANNEL":       true,
+	"SANDCATS_BASE_DOMAIN": true,
+}
+
+// addSandstormConf adds the Tempest settings equivalent to conf, and notes
+// about options which have none.
+func (r *Report) addSandstormConf(conf map[string]string) {
+	if baseURL, ok := conf["BASE_URL"]; ok {
+		r.Settings = append(r.Settings, "BASE_URL="+baseURL)
+		checkWildcardHost(r, baseURL, conf["WILDCARD_HOST"])
+	}
+	if port, ok := conf["PORT"]; ok {
+		// Sandstorm accepts a list of ports; Tempest listens on one.
+		first, rest, _ := strings.Cut(port, ",")
+		r.Settings = append(r.Settings, "HTTP_PORT="+first)
+		if rest != "" {
+			r.note("Sandstorm listened on ports %s; Tempest listens for HTTP on port %s only", port, first)
+		}
+	}
+	if port, ok := conf["HTTPS_PORT"]; ok {
+		r.Settings = append(r.Settings, "HTTPS_PORT="+port)
+	}
+	keys := make([]string, 0, len(conf))
+	for key := range conf {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		switch key {
+		case "BASE_URL", "WILDCARD_HOST", "PORT", "HTTPS_PORT":
+			continue
+		}
+		if !sandstormConfIgnored[key] {
+			r.note("sandstorm.conf option %s has no Tempest equivalent", key)
+		}
+	}
+}
+
+// checkWildcardHost notes if Sandstorm served grains from somewhere other than
+// subdomains of BASE_URL's host, as Tempest does.
+func checkWildcardHost(r *Report, baseURL, wildcardHost string) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		r.note("sandstorm.conf's BASE_URL %q is not a valid URL", baseURL)
+		return
+	}
+	want := "*." + u.Hostname()
+	got := wildcardHost
+	if host, _, err := net.SplitHostPort(wildcardHost); err == nil {
+		got = host
+	}
+	if got != want {
+		r.note("Sandstorm served grains from %s, but Tempest serves them from %s; "+
+			"update DNS and certificates to match", wildcardHost, want)
+	}
+}
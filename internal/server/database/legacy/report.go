@@ -0,0 +1,88 @@
+package legacy
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// A Report describes the result of a migration from Sandstorm: what was
+// imported, what couldn't be, and what the administrator should do next.
+type Report struct {
+	// Imported counts the imported records, by kind, e.g. "accounts".
+	Imported map[string]int
+
+	// Skipped lists the records which could not be mapped to Tempest.
+	Skipped []Skipped
+
+	// Settings are Tempest settings derived from sandstorm.conf, in the
+	// form NAME=value, suitable for the environment.
+	Settings []string
+
+	// Notes are anything else worth knowing, e.g. sandstorm.conf options
+	// which have no equivalent.
+	Notes []string
+}
+
+// Skipped describes a record which was not imported.
+type Skipped struct {
+	// Collection is the Mongo collection holding the record.
+	Collection string
+	ID         string
+	Reason     string
+}
+
+func newReport() *Report {
+	return &Report{Imported: make(map[string]int)}
+}
+
+func (r *Report) imported(kind string) {
+	r.Imported[kind]++
+}
+
+func (r *Report) skip(collection, id, format string, args ...any) {
+	r.Skipped = append(r.Skipped, Skipped{
+		Collection: collection,
+		ID:         id,
+		Reason:     fmt.Sprintf(format, args...),
+	})
+}
+
+func (r *Report) note(format string, args ...any) {
+	r.Notes = append(r.Notes, fmt.Sprintf(format, args...))
+}
+
+// WriteTo writes the report to w in a human-readable form.
+func (r *Report) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	b.WriteString("Imported:\n")
+	kinds := make([]string, 0, len(r.Imported))
+	for kind := range r.Imported {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		fmt.Fprintf(&b, "  %s: %d\n", kind, r.Imported[kind])
+	}
+	if len(r.Skipped) > 0 {
+		fmt.Fprintf(&b, "\nNot imported (%d):\n", len(r.Skipped))
+		for _, s := range r.Skipped {
+			fmt.Fprintf(&b, "  %s %s: %s\n", s.Collection, s.ID, s.Reason)
+		}
+	}
+	if len(r.Settings) > 0 {
+		b.WriteString("\nSuggested settings, from sandstorm.conf:\n")
+		for _, s := range r.Settings {
+			fmt.Fprintf(&b, "  %s\n", s)
+		}
+	}
+	if len(r.Notes) > 0 {
+		b.WriteString("\nNotes:\n")
+		for _, n := range r.Notes {
+			fmt.Fprintf(&b, "  - %s\n", n)
+		}
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
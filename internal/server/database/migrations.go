@@ -0,0 +1,332 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"zenhack.net/go/util/exn"
+)
+
+// Changes to the schema are made by migrations, which are applied in order,
+// each exactly once. The number of migrations a database has had is its
+// schema version, which is kept in SQLite's user_version pragma, so it is
+// updated in the same transaction as the schema.
+//
+// To change the schema, append a migration to the list; never edit or
+// reorder the ones which have been released, since servers' databases have
+// already had them.
+
+// A migration changes the schema from one version to the next.
+type migration struct {
+	// What the migration does, for errors.
+	name string
+
+	apply func(tx *sql.Tx) error
+}
+
+var migrations = []migration{
+	{
+		name:  "create the initial schema",
+		apply: createSchema,
+	},
+	{
+		name: "index grains by owner, and sturdyRefs by grain and owner",
+		apply: execAll(
+			`CREATE INDEX IF NOT EXISTS grainsByOwner
+			 ON grains (ownerId)`,
+			`CREATE INDEX IF NOT EXISTS sturdyRefsByGrain
+			 ON sturdyRefs (grainId)`,
+			`CREATE INDEX IF NOT EXISTS sturdyRefsByOwner
+			 ON sturdyRefs (ownerType, owner)`,
+			`CREATE INDEX IF NOT EXISTS keyringEntriesByAccount
+			 ON keyringEntries (accountId)`,
+		),
+	},
+	{
+		name: "add apiTokens",
+		apply: execAll(
+			`-- API tokens offered by grains; see types.APIToken. The
+			 -- token is a sharing token, whose sturdyRef this names,
+			 -- so that it can be listed and revoked.
+			 CREATE TABLE apiTokens (
+				id VARCHAR PRIMARY KEY NOT NULL,
+				sha256 BLOB UNIQUE NOT NULL REFERENCES sturdyRefs(sha256),
+				grainId VARCHAR NOT NULL REFERENCES grains(id),
+				-- The user who made the token:
+				accountId VARCHAR NOT NULL REFERENCES accounts(id),
+				petname VARCHAR NOT NULL,
+				-- Unix timestamp:
+				created INTEGER NOT NULL
+			)`,
+			`CREATE INDEX apiTokensByGrain ON apiTokens (grainId)`,
+		),
+	},
+	{
+		name: "add shareLinks and grainMembers",
+		apply: execAll(
+			`-- Links through which users share grains; see
+			 -- types.ShareLink. As with apiTokens, the token is a
+			 -- sharing token, whose sturdyRef this names; it expires
+			 -- with the sturdyRef.
+			 CREATE TABLE shareLinks (
+				id VARCHAR PRIMARY KEY NOT NULL,
+				sha256 BLOB UNIQUE NOT NULL REFERENCES sturdyRefs(sha256),
+				grainId VARCHAR NOT NULL REFERENCES grains(id),
+				-- The user who made the link:
+				accountId VARCHAR NOT NULL REFERENCES accounts(id),
+				-- Index of the role, among those the grain's app defines:
+				roleId INTEGER NOT NULL,
+				note VARCHAR NOT NULL,
+				singleUse BOOLEAN NOT NULL,
+				uses INTEGER NOT NULL,
+				-- Unix timestamp:
+				created INTEGER NOT NULL
+			)`,
+			`CREATE INDEX shareLinksByGrain ON shareLinks (grainId)`,
+			`-- How users joined the grains they got through share links;
+			 -- see types.GrainMember. Their access itself is their
+			 -- keyring entry for the grain.
+			 CREATE TABLE grainMembers (
+				grainId VARCHAR NOT NULL REFERENCES grains(id),
+				accountId VARCHAR NOT NULL REFERENCES accounts(id),
+				roleId INTEGER NOT NULL,
+				-- The link they last joined through, which may since
+				-- have been revoked, and the user who made it:
+				shareLinkId VARCHAR NOT NULL,
+				sharedBy VARCHAR NOT NULL,
+				-- Unix timestamp:
+				joined INTEGER NOT NULL,
+				PRIMARY KEY (grainId, accountId)
+			)`,
+		),
+	},
+	{
+		name: "add grainShares",
+		apply: execAll(
+			`-- The edges of grains' share graphs: who gave whom access;
+			 -- see types.GrainShare.
+			 CREATE TABLE grainShares (
+				grainId VARCHAR NOT NULL REFERENCES grains(id),
+				sharedBy VARCHAR NOT NULL REFERENCES accounts(id),
+				accountId VARCHAR NOT NULL REFERENCES accounts(id),
+				-- The link, if any, through which the user last got
+				-- access from sharedBy, and its role:
+				shareLinkId VARCHAR NOT NULL,
+				roleId INTEGER,
+				-- Permissions as in keyringEntries:
+				permissions VARCHAR NOT NULL,
+				-- Unix timestamp:
+				created INTEGER NOT NULL,
+				PRIMARY KEY (grainId, sharedBy, accountId)
+			)`,
+			`-- Members who joined before now have one known share:
+			 -- the last.
+			 INSERT INTO grainShares
+				(grainId, sharedBy, accountId, shareLinkId, roleId,
+					permissions, created)
+			 SELECT grainMembers.grainId, grainMembers.sharedBy,
+				grainMembers.accountId, grainMembers.shareLinkId,
+				grainMembers.roleId, keyringEntries.appPermissions,
+				grainMembers.joined
+			 FROM grainMembers, sturdyRefs, keyringEntries
+			 WHERE sturdyRefs.grainId = grainMembers.grainId
+				AND sturdyRefs.ownerType = 'userkeyring'
+				AND sturdyRefs.owner = grainMembers.accountId
+				AND sturdyRefs.objectId IS NULL
+				AND keyringEntries.sha256 = sturdyRefs.sha256
+				AND grainMembers.sharedBy != grainMembers.accountId`,
+		),
+	},
+	{
+		name: "add grains.trashed",
+		apply: execAll(
+			`-- Unix timestamp at which the grain was moved to the
+			 -- trash, or NULL if it isn't in the trash:
+			 ALTER TABLE grains ADD COLUMN trashed INTEGER`,
+		),
+	},
+	{
+		name: "add accounts.locale",
+		apply: execAll(
+			`-- BCP 47 language tag of the locale the user last used
+			 -- the shell in, for what the server writes to them, or
+			 -- NULL if unknown:
+			 ALTER TABLE accounts ADD COLUMN locale VARCHAR`,
+		),
+	},
+	{
+		name: "add powerboxGrants.httpAuthorization",
+		apply: execAll(
+			`-- Authorization header sent with requests through a
+			 -- granted HTTP API, or NULL for none; see
+			 -- PowerboxGrant.HTTPAPI:
+			 ALTER TABLE powerboxGrants ADD COLUMN httpAuthorization VARCHAR`,
+		),
+	},
+	{
+		name: "add grains.previousPackageId",
+		apply: execAll(
+			`-- While the grain is being upgraded to a newer version
+			 -- of its app, the package it ran before, to go back to
+			 -- if the new one fails to start; NULL otherwise. See
+			 -- Tx.StartGrainUpgrade:
+			 ALTER TABLE grains ADD COLUMN previousPackageId VARCHAR(32) REFERENCES packages(id)`,
+		),
+	},
+	{
+		name: "add packages.devDir",
+		apply: execAll(
+			`-- For packages served by tempest dev, the directory
+			 -- they are served from, which is their root filesystem;
+			 -- NULL for installed packages. See Tx.AddDevPackage:
+			 ALTER TABLE packages ADD COLUMN devDir VARCHAR`,
+		),
+	},
+	{
+		name: "add userSessions",
+		apply: execAll(
+			`-- The login sessions which have been started, so that
+			 -- an account's sessions can be revoked; their cookies
+			 -- hold the rest of their state. Rows are removed when
+			 -- the sessions are revoked:
+			 CREATE TABLE userSessions (
+				sessionId BLOB PRIMARY KEY,
+				credentialType VARCHAR NOT NULL,
+				scopedId VARCHAR NOT NULL,
+				-- Unix timestamp:
+				started INTEGER NOT NULL
+			 )`,
+			`CREATE INDEX userSessionsByCredential ON userSessions (credentialType, scopedId)`,
+		),
+	},
+	{
+		name: "add userSessions details",
+		apply: execAll(
+			`-- The User-Agent of the browser which logged in:
+			 ALTER TABLE userSessions ADD COLUMN userAgent VARCHAR NOT NULL DEFAULT ''`,
+			`-- The IP address the session was last used from:
+			 ALTER TABLE userSessions ADD COLUMN remoteAddr VARCHAR NOT NULL DEFAULT ''`,
+			`-- Unix timestamp of when the session was last used:
+			 ALTER TABLE userSessions ADD COLUMN lastSeen INTEGER NOT NULL DEFAULT 0`,
+			`UPDATE userSessions SET lastSeen = started`,
+		),
+	},
+	{
+		name: "add apiKeys",
+		apply: execAll(
+			`-- Keys through which users' scripts use the api host; see
+			 -- types.APIKey.
+			 CREATE TABLE apiKeys (
+				id VARCHAR PRIMARY KEY NOT NULL,
+				-- Of the key itself:
+				sha256 BLOB UNIQUE NOT NULL,
+				accountId VARCHAR NOT NULL REFERENCES accounts(id),
+				name VARCHAR NOT NULL,
+				accountRead BOOLEAN NOT NULL,
+				-- Unix timestamps; lastUsed is NULL if the key hasn't
+				-- been used:
+				created INTEGER NOT NULL,
+				lastUsed INTEGER
+			)`,
+			`CREATE INDEX apiKeysByAccount ON apiKeys (accountId)`,
+			`-- The grains each API key may use:
+			 CREATE TABLE apiKeyGrains (
+				keyId VARCHAR NOT NULL REFERENCES apiKeys(id),
+				grainId VARCHAR NOT NULL REFERENCES grains(id),
+				PRIMARY KEY (keyId, grainId)
+			)`,
+			`CREATE INDEX apiKeyGrainsByGrain ON apiKeyGrains (grainId)`,
+		),
+	},
+	{
+		name: "add serverSettings",
+		apply: execAll(
+			`-- Admin settings saved by the setup wizard, which apply where
+			 -- the environment doesn't set them; see settings.WithStored.
+			 -- Values are formatted as they would be in the environment.
+			 CREATE TABLE serverSettings (
+				name VARCHAR PRIMARY KEY NOT NULL,
+				value VARCHAR NOT NULL
+			)`,
+		),
+	},
+	{
+		name: "add demoAccounts",
+		apply: execAll(
+			`-- Temporary accounts made for visitors to try the server,
+			 -- which are deleted, with their grains, when they expire.
+			 -- See Tx.NewDemoAccount:
+			 CREATE TABLE demoAccounts (
+				accountId VARCHAR PRIMARY KEY NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+				-- Unix timestamp:
+				expires INTEGER NOT NULL
+			)`,
+			`CREATE INDEX demoAccountsByExpiry ON demoAccounts (expires)`,
+		),
+	},
+	{
+		name: "add grains.storageBytes",
+		apply: execAll(
+			`-- Disk space used by the grain, in bytes, as last
+			 -- measured; see Tx.SetGrainStorage:
+			 ALTER TABLE grains ADD COLUMN storageBytes INTEGER NOT NULL DEFAULT 0`,
+		),
+	},
+	{
+		name: "index grainStarts by grain",
+		apply: execAll(
+			`-- For trimming each grain's starts; see Tx.AddGrainStart:
+			 CREATE INDEX grainStartsByGrain ON grainStarts (grainId, startedAt)`,
+		),
+	},
+}
+
+// execAll returns a migration which executes each of the statements.
+func execAll(stmts ...string) func(*sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		for _, stmt := range stmts {
+			if _, err := tx.Exec(stmt); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// migrate applies the migrations which the database hasn't had yet. It
+// refuses to open a database with a newer schema, which was presumably
+// written by a newer version of Tempest, and which this version might
+// corrupt.
+func migrate(sqlDB *sql.DB) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		tx, err := sqlDB.Begin()
+		throw(err)
+		defer tx.Rollback()
+		version, err := schemaVersion(tx)
+		throw(err)
+		latest := len(migrations)
+		if version > latest {
+			throw(fmt.Errorf("database schema version %d is newer than this version of Tempest supports (%d)",
+				version, latest))
+		}
+		for i := version; i < latest; i++ {
+			if err := migrations[i].apply(tx); err != nil {
+				throw(fmt.Errorf("migrating database to schema version %d (%s): %w",
+					i+1, migrations[i].name, err))
+			}
+		}
+		if version < latest {
+			// Pragmas don't take parameters.
+			_, err = tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", latest))
+			throw(err)
+		}
+		throw(tx.Commit())
+	})
+}
+
+// schemaVersion returns the schema version of the database.
+func schemaVersion(tx *sql.Tx) (int, error) {
+	var version int
+	err := tx.QueryRow("PRAGMA user_version").Scan(&version)
+	return version, err
+}
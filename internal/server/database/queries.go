@@ -5,18 +5,25 @@ package database
 import (
 	"crypto/sha256"
 	"database/sql"
+	"encoding/base32"
+	"errors"
 	"fmt"
 	"math"
+	"slices"
+	"strings"
 	"time"
 
 	"capnproto.org/go/capnp/v3"
 	"capnproto.org/go/capnp/v3/exc"
 	"capnproto.org/go/capnp/v3/packed"
+	"sandstorm.org/go/tempest/capnp/activity"
 	"sandstorm.org/go/tempest/capnp/grain"
 	"sandstorm.org/go/tempest/capnp/identity"
 	spk "sandstorm.org/go/tempest/capnp/package"
 	"sandstorm.org/go/tempest/internal/capnp/system"
 	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/cgroup"
+	"sandstorm.org/go/tempest/internal/server/scheduler"
 	"sandstorm.org/go/tempest/internal/server/tokenutil"
 	"zenhack.net/go/util/exn"
 )
@@ -38,6 +45,14 @@ func (tx Tx) AddPackage(pkg Package) error {
 		manifestBlob,
 		false,
 	)
+	if err == nil && pkg.AppID != "" {
+		_, err = tx.sqlTx.Exec(
+			`INSERT INTO packageApps(packageId, appId, appVersion) VALUES (?, ?, ?)`,
+			pkg.ID,
+			pkg.AppID,
+			pkg.Manifest.AppVersion(),
+		)
+	}
 	return exc.WrapError("AddPackage", err)
 }
 
@@ -46,13 +61,79 @@ func (tx Tx) ReadyPackage(id types.ID[Package]) error {
 	return exc.WrapError("ReadyPackage", err)
 }
 
+// AddDevPackage records a package served by `tempest dev` from dir, which is
+// its root filesystem, or if it is already recorded, e.g. from an earlier
+// session, updates its manifest. Either way it is ready, since its files are
+// already in place. Installed packages can't be replaced this way.
+func (tx Tx) AddDevPackage(pkg Package, dir string) error {
+	manifestBlob, err := encodeCapnp(pkg.Manifest)
+	if err != nil {
+		return err
+	}
+	res, err := tx.sqlTx.Exec(
+		`INSERT INTO packages(id, manifest, ready, devDir) VALUES (?, ?, true, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			manifest = excluded.manifest,
+			ready = true
+		WHERE packages.devDir = excluded.devDir`,
+		pkg.ID,
+		manifestBlob,
+		dir,
+	)
+	if err != nil {
+		return exc.WrapError("AddDevPackage", err)
+	}
+	n, err := res.RowsAffected()
+	if err == nil && n == 0 {
+		err = fmt.Errorf("package %s is not a dev package for %s", pkg.ID, dir)
+	}
+	return exc.WrapError("AddDevPackage", err)
+}
+
+// RemoveDevPackage marks the dev package with the given ID as no longer
+// served. It stays recorded for its grains, which can start again once it is
+// served again.
+func (tx Tx) RemoveDevPackage(id types.ID[Package]) error {
+	_, err := tx.sqlTx.Exec(
+		`UPDATE packages SET ready = false WHERE id = ? AND devDir IS NOT NULL`,
+		id,
+	)
+	return exc.WrapError("RemoveDevPackage", err)
+}
+
+// ServedDevPackages returns the IDs of the dev packages which are marked as
+// served, mapped to their directories.
+func (tx Tx) ServedDevPackages() (map[types.ID[Package]]string, error) {
+	rows, err := tx.sqlTx.Query(
+		`SELECT id, devDir FROM packages WHERE ready AND devDir IS NOT NULL`)
+	if err != nil {
+		return nil, exc.WrapError("ServedDevPackages", err)
+	}
+	defer rows.Close()
+	ret := make(map[types.ID[Package]]string)
+	for rows.Next() {
+		var (
+			id  types.ID[Package]
+			dir string
+		)
+		if err := rows.Scan(&id, &dir); err != nil {
+			return nil, exc.WrapError("ServedDevPackages", err)
+		}
+		ret[id] = dir
+	}
+	return ret, exc.WrapError("ServedDevPackages", rows.Err())
+}
+
 // CredentialPackages returns a list of all packages installed for the user
 // associated with the credential.
 func (tx Tx) CredentialPackages(cred types.Credential) ([]Package, error) {
 	// Note: we don't yet handle app installation, so we behave as if all
 	// packages are installed for all users. When that changes, we will
 	// have to actually filter by account.
-	rows, err := tx.sqlTx.Query("SELECT id, manifest FROM packages")
+	rows, err := tx.sqlTx.Query(
+		`SELECT packages.id, packages.manifest, IFNULL(packageApps.appId, '')
+		FROM packages LEFT JOIN packageApps ON packageApps.packageId = packages.id
+		WHERE packages.ready`)
 	if err != nil {
 		return nil, exc.WrapError("CredentialPackages", err)
 	}
@@ -63,7 +144,7 @@ func (tx Tx) CredentialPackages(cred types.Credential) ([]Package, error) {
 			pkg           Package
 			manifestBytes []byte
 		)
-		err = rows.Scan(&pkg.ID, &manifestBytes)
+		err = rows.Scan(&pkg.ID, &manifestBytes, &pkg.AppID)
 		if err != nil {
 			return nil, err
 		}
@@ -76,6 +157,30 @@ func (tx Tx) CredentialPackages(cred types.Credential) ([]Package, error) {
 	return ret, nil
 }
 
+// InstalledPackage returns the package with the given ID, if it has finished
+// installing.
+func (tx Tx) InstalledPackage(id types.ID[Package]) (Package, error) {
+	var (
+		manifest []byte
+		appID    string
+	)
+	row := tx.sqlTx.QueryRow(
+		`SELECT packages.manifest, IFNULL(packageApps.appId, '')
+		FROM packages LEFT JOIN packageApps ON packageApps.packageId = packages.id
+		WHERE packages.id = ? AND packages.ready`,
+		id,
+	)
+	err := row.Scan(&manifest, &appID)
+	if err != nil {
+		return Package{}, exc.WrapError("InstalledPackage", err)
+	}
+	m, err := decodeCapnp[spk.Manifest](manifest)
+	if err != nil {
+		return Package{}, exc.WrapError("InstalledPackage", err)
+	}
+	return Package{ID: id, Manifest: m, AppID: appID}, nil
+}
+
 type NewGrain struct {
 	GrainID types.GrainID
 	PkgID   types.ID[Package]
@@ -150,14 +255,477 @@ func (tx Tx) GrainPackageID(grainID types.GrainID) (string, error) {
 	return result, exc.WrapError("GrainPackageID", err)
 }
 
+// PackageAppID returns the app ID of the package with the given ID, or the
+// empty string if it doesn't have one.
+func (tx Tx) PackageAppID(pkgID string) (string, error) {
+	var appID string
+	err := tx.sqlTx.QueryRow(
+		`SELECT appId FROM packageApps WHERE packageId = ?`,
+		pkgID,
+	).Scan(&appID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return appID, exc.WrapError("PackageAppID", err)
+}
+
+// GrainIDs returns the IDs of all grains.
+func (tx Tx) GrainIDs() ([]types.GrainID, error) {
+	rows, err := tx.sqlTx.Query("SELECT id FROM grains")
+	if err != nil {
+		return nil, exc.WrapError("GrainIDs", err)
+	}
+	defer rows.Close()
+	var ret []types.GrainID
+	for rows.Next() {
+		var id types.GrainID
+		if err = rows.Scan(&id); err != nil {
+			return nil, exc.WrapError("GrainIDs", err)
+		}
+		ret = append(ret, id)
+	}
+	return ret, exc.WrapError("GrainIDs", rows.Err())
+}
+
+// PackageGrainIDs returns the IDs of the grains which run the package with
+// the given ID.
+func (tx Tx) PackageGrainIDs(pkgID types.ID[Package]) ([]types.GrainID, error) {
+	rows, err := tx.sqlTx.Query("SELECT id FROM grains WHERE packageId = ? ORDER BY id", pkgID)
+	if err != nil {
+		return nil, exc.WrapError("PackageGrainIDs", err)
+	}
+	defer rows.Close()
+	var ret []types.GrainID
+	for rows.Next() {
+		var id types.GrainID
+		if err = rows.Scan(&id); err != nil {
+			return nil, exc.WrapError("PackageGrainIDs", err)
+		}
+		ret = append(ret, id)
+	}
+	return ret, exc.WrapError("PackageGrainIDs", rows.Err())
+}
+
+// AccountGrainIDs returns the IDs of the grains the account owns.
+func (tx Tx) AccountGrainIDs(accountID types.AccountID) ([]types.GrainID, error) {
+	rows, err := tx.sqlTx.Query("SELECT id FROM grains WHERE ownerId = ? ORDER BY id", accountID)
+	if err != nil {
+		return nil, exc.WrapError("AccountGrainIDs", err)
+	}
+	defer rows.Close()
+	var ret []types.GrainID
+	for rows.Next() {
+		var id types.GrainID
+		if err = rows.Scan(&id); err != nil {
+			return nil, exc.WrapError("AccountGrainIDs", err)
+		}
+		ret = append(ret, id)
+	}
+	return ret, exc.WrapError("AccountGrainIDs", rows.Err())
+}
+
 func (tx Tx) GrainInfo(grainID types.GrainID) (GrainInfo, error) {
-	var result GrainInfo
+	var (
+		result  GrainInfo
+		trashed sql.NullInt64
+	)
 	result.ID = grainID
-	row := tx.sqlTx.QueryRow("SELECT title, ownerId FROM grains WHERE id = ?", grainID)
-	err := row.Scan(&result.Title, &result.Owner)
+	row := tx.sqlTx.QueryRow(
+		"SELECT title, ownerId, trashed, storageBytes FROM grains WHERE id = ?",
+		grainID,
+	)
+	err := row.Scan(&result.Title, &result.Owner, &trashed, &result.StorageBytes)
+	if trashed.Valid {
+		result.Trashed = time.Unix(trashed.Int64, 0)
+	}
 	return result, exc.WrapError("GrainInfo", err)
 }
 
+// Grains returns every grain, ordered by ID.
+func (tx Tx) Grains() ([]GrainInfo, error) {
+	rows, err := tx.sqlTx.Query(
+		"SELECT id, title, ownerId, trashed, storageBytes FROM grains ORDER BY id",
+	)
+	if err != nil {
+		return nil, exc.WrapError("Grains", err)
+	}
+	defer rows.Close()
+	var ret []GrainInfo
+	for rows.Next() {
+		var (
+			g       GrainInfo
+			trashed sql.NullInt64
+		)
+		if err := rows.Scan(&g.ID, &g.Title, &g.Owner, &trashed, &g.StorageBytes); err != nil {
+			return nil, exc.WrapError("Grains", err)
+		}
+		if trashed.Valid {
+			g.Trashed = time.Unix(trashed.Int64, 0)
+		}
+		ret = append(ret, g)
+	}
+	return ret, exc.WrapError("Grains", rows.Err())
+}
+
+// SetGrainTitle renames the grain, returning sql.ErrNoRows if there is no
+// such grain.
+func (tx Tx) SetGrainTitle(grainID types.GrainID, title string) error {
+	res, err := tx.sqlTx.Exec(`UPDATE grains SET title = ? WHERE id = ?`, title, grainID)
+	return exc.WrapError("SetGrainTitle", requireRowsAffected(res, err))
+}
+
+// SetGrainTrashed moves the grain to the trash, recording that it was moved
+// at the given time, or takes it out of the trash if the time is zero. It
+// returns sql.ErrNoRows if there is no such grain.
+func (tx Tx) SetGrainTrashed(grainID types.GrainID, trashed time.Time) error {
+	var at sql.NullInt64
+	if !trashed.IsZero() {
+		at = sql.NullInt64{Int64: trashed.Unix(), Valid: true}
+	}
+	res, err := tx.sqlTx.Exec(`UPDATE grains SET trashed = ? WHERE id = ?`, at, grainID)
+	return exc.WrapError("SetGrainTrashed", requireRowsAffected(res, err))
+}
+
+// SetGrainStorage records the disk space used by each grain, in bytes.
+// Grains missing from usage are recorded as using none. Only grains whose
+// usage has changed are written.
+func (tx Tx) SetGrainStorage(usage map[types.GrainID]int64) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		rows, err := tx.sqlTx.Query(`SELECT id, storageBytes FROM grains WHERE storageBytes != 0`)
+		throw(exc.WrapError("SetGrainStorage", err))
+		defer rows.Close()
+		recorded := make(map[types.GrainID]int64)
+		for rows.Next() {
+			var (
+				id types.GrainID
+				n  int64
+			)
+			throw(exc.WrapError("SetGrainStorage", rows.Scan(&id, &n)))
+			recorded[id] = n
+		}
+		throw(exc.WrapError("SetGrainStorage", rows.Err()))
+		set := func(grainID types.GrainID, n int64) {
+			_, err := tx.sqlTx.Exec(
+				`UPDATE grains SET storageBytes = ? WHERE id = ?`,
+				n, grainID,
+			)
+			throw(exc.WrapError("SetGrainStorage", err))
+		}
+		for grainID := range recorded {
+			if _, ok := usage[grainID]; !ok {
+				set(grainID, 0)
+			}
+		}
+		for grainID, n := range usage {
+			if recorded[grainID] != n {
+				set(grainID, n)
+			}
+		}
+	})
+}
+
+// GrainsTrashedBefore returns the IDs of the grains which were moved to the
+// trash before cutoff.
+func (tx Tx) GrainsTrashedBefore(cutoff time.Time) ([]types.GrainID, error) {
+	rows, err := tx.sqlTx.Query(
+		`SELECT id FROM grains WHERE trashed < ? ORDER BY trashed, id`,
+		cutoff.Unix(),
+	)
+	if err != nil {
+		return nil, exc.WrapError("GrainsTrashedBefore", err)
+	}
+	defer rows.Close()
+	var ret []types.GrainID
+	for rows.Next() {
+		var id types.GrainID
+		if err = rows.Scan(&id); err != nil {
+			return nil, exc.WrapError("GrainsTrashedBefore", err)
+		}
+		ret = append(ret, id)
+	}
+	return ret, exc.WrapError("GrainsTrashedBefore", rows.Err())
+}
+
+// grainUpgradesQuery selects, for each grain matching the condition which
+// follows it, the packages of newer versions of the grain's app, newest
+// first. Grains in the trash, or already being upgraded, aren't included.
+const grainUpgradesQuery = `
+	SELECT grains.id, cur.appId, cur.appVersion, new.packageId, new.appVersion,
+		packages.manifest
+	FROM grains
+		JOIN packageApps cur ON cur.packageId = grains.packageId
+		JOIN packageApps new ON new.appId = cur.appId
+			AND new.appVersion > cur.appVersion
+		JOIN packages ON packages.id = new.packageId AND packages.ready
+	WHERE grains.trashed IS NULL
+		AND grains.previousPackageId IS NULL
+		AND `
+
+// grainUpgrades returns the newest upgrade for each grain matching cond, a
+// condition on grains, with the given args.
+func (tx Tx) grainUpgrades(cond string, args ...any) ([]types.GrainUpgrade, error) {
+	return exn.Try(func(throw exn.Thrower) []types.GrainUpgrade {
+		rows, err := tx.sqlTx.Query(
+			grainUpgradesQuery+cond+`
+			ORDER BY grains.id, new.appVersion DESC, new.packageId`,
+			args...,
+		)
+		throw(err)
+		defer rows.Close()
+		var ret []types.GrainUpgrade
+		for rows.Next() {
+			var (
+				u        types.GrainUpgrade
+				manifest []byte
+			)
+			throw(rows.Scan(&u.GrainID, &u.AppID, &u.FromVersion,
+				&u.ToPackageID, &u.ToVersion, &manifest))
+			if len(ret) > 0 && ret[len(ret)-1].GrainID == u.GrainID {
+				continue // An older version.
+			}
+			m, err := decodeCapnp[spk.Manifest](manifest)
+			throw(err)
+			if title, err := m.AppTitle(); err == nil {
+				u.AppTitle, _ = title.DefaultText()
+			}
+			if version, err := m.AppMarketingVersion(); err == nil {
+				u.ToMarketingVersion, _ = version.DefaultText()
+			}
+			ret = append(ret, u)
+		}
+		throw(rows.Err())
+		return ret
+	})
+}
+
+// AccountGrainUpgrades returns the upgrades available for the grains the
+// account owns, ordered by grain ID.
+func (tx Tx) AccountGrainUpgrades(accountID types.AccountID) ([]types.GrainUpgrade, error) {
+	ret, err := tx.grainUpgrades(`grains.ownerId = ?`, accountID)
+	return ret, exc.WrapError("AccountGrainUpgrades", err)
+}
+
+// GrainUpgrade returns the upgrade available for the grain, or sql.ErrNoRows
+// if there is none.
+func (tx Tx) GrainUpgrade(grainID types.GrainID) (types.GrainUpgrade, error) {
+	ret, err := tx.grainUpgrades(`grains.id = ?`, grainID)
+	if err == nil && len(ret) == 0 {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		return types.GrainUpgrade{}, exc.WrapError("GrainUpgrade", err)
+	}
+	return ret[0], nil
+}
+
+// StartGrainUpgrade switches the grain to the package with the given ID,
+// remembering the one it ran before, until FinishGrainUpgrade or
+// RollBackGrainUpgrade is called. It returns sql.ErrNoRows if there is no
+// such grain, or it is already being upgraded.
+func (tx Tx) StartGrainUpgrade(grainID types.GrainID, pkgID string) error {
+	res, err := tx.sqlTx.Exec(
+		`UPDATE grains SET previousPackageId = packageId, packageId = ?
+		WHERE id = ? AND previousPackageId IS NULL`,
+		pkgID,
+		grainID,
+	)
+	return exc.WrapError("StartGrainUpgrade", requireRowsAffected(res, err))
+}
+
+// PendingGrainUpgrade reports whether the grain is being upgraded, and if so,
+// the appVersion of the package it ran before.
+func (tx Tx) PendingGrainUpgrade(grainID types.GrainID) (previousVersion uint32, pending bool, err error) {
+	err = tx.sqlTx.QueryRow(
+		`SELECT packageApps.appVersion
+		FROM grains JOIN packageApps ON packageApps.packageId = grains.previousPackageId
+		WHERE grains.id = ?`,
+		grainID,
+	).Scan(&previousVersion)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	return previousVersion, err == nil, exc.WrapError("PendingGrainUpgrade", err)
+}
+
+// FinishGrainUpgrade forgets the package the grain ran before it was
+// upgraded, keeping it on the new one. It returns sql.ErrNoRows if the grain
+// isn't being upgraded.
+func (tx Tx) FinishGrainUpgrade(grainID types.GrainID) error {
+	res, err := tx.sqlTx.Exec(
+		`UPDATE grains SET previousPackageId = NULL
+		WHERE id = ? AND previousPackageId IS NOT NULL`,
+		grainID,
+	)
+	return exc.WrapError("FinishGrainUpgrade", requireRowsAffected(res, err))
+}
+
+// RollBackGrainUpgrade switches the grain back to the package it ran before
+// it was upgraded. It returns sql.ErrNoRows if the grain isn't being
+// upgraded.
+func (tx Tx) RollBackGrainUpgrade(grainID types.GrainID) error {
+	res, err := tx.sqlTx.Exec(
+		`UPDATE grains SET packageId = previousPackageId, previousPackageId = NULL
+		WHERE id = ? AND previousPackageId IS NOT NULL`,
+		grainID,
+	)
+	return exc.WrapError("RollBackGrainUpgrade", requireRowsAffected(res, err))
+}
+
+// PendingGrainUpgrades returns the IDs of the grains which are being
+// upgraded.
+func (tx Tx) PendingGrainUpgrades() ([]types.GrainID, error) {
+	rows, err := tx.sqlTx.Query(
+		`SELECT id FROM grains WHERE previousPackageId IS NOT NULL ORDER BY id`)
+	if err != nil {
+		return nil, exc.WrapError("PendingGrainUpgrades", err)
+	}
+	defer rows.Close()
+	var ret []types.GrainID
+	for rows.Next() {
+		var id types.GrainID
+		if err = rows.Scan(&id); err != nil {
+			return nil, exc.WrapError("PendingGrainUpgrades", err)
+		}
+		ret = append(ret, id)
+	}
+	return ret, exc.WrapError("PendingGrainUpgrades", rows.Err())
+}
+
+// grainSharingTokenHashes returns the hashes of the sharing tokens for the
+// grain's UiView. Their sturdyRefs only name the grain in their object IDs,
+// so this has to read every sharing token.
+func (tx Tx) grainSharingTokenHashes(grainID types.GrainID) ([][]byte, error) {
+	return exn.Try(func(throw exn.Thrower) [][]byte {
+		rows, err := tx.sqlTx.Query(
+			`SELECT sha256, objectId FROM sturdyRefs
+			WHERE ownerType = 'external-api'
+				AND grainId IS NULL
+				AND objectId IS NOT NULL`,
+		)
+		throw(err)
+		defer rows.Close()
+		var ret [][]byte
+		for rows.Next() {
+			var hash, objectID []byte
+			throw(rows.Scan(&hash, &objectID))
+			oid, err := decodeCapnp[capnp.Struct](objectID)
+			throw(err)
+			st, err := readSharingToken(SturdyRefValue{ObjectID: oid})
+			if err == nil && st.GrainID == grainID {
+				ret = append(ret, hash)
+			}
+		}
+		throw(rows.Err())
+		return ret
+	})
+}
+
+// requireRowsAffected returns err, or if there is none, sql.ErrNoRows if the
+// statement whose result is res changed no rows.
+func requireRowsAffected(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err == nil && n == 0 {
+		err = sql.ErrNoRows
+	}
+	return err
+}
+
+// DeleteGrain deletes everything the database records about the grain: the
+// grain itself, all access to it, including sharing tokens, the sturdyRefs it
+// hosts and those it holds, and its history. The grain's storage is the
+// caller's to remove.
+func (tx Tx) DeleteGrain(grainID types.GrainID) error {
+	return exc.WrapError("DeleteGrain", exn.Try0(func(throw exn.Thrower) {
+		// The sturdyRefs to the grain's objects, and those which it
+		// holds, or may claim:
+		rows, err := tx.sqlTx.Query(
+			`SELECT sha256 FROM sturdyRefs
+			WHERE grainId = ?
+				OR (ownerType IN ('grain', 'powerbox-claim') AND owner = ?)`,
+			grainID,
+			grainID,
+		)
+		throw(err)
+		defer rows.Close()
+		var hashes [][]byte
+		for rows.Next() {
+			var hash []byte
+			throw(rows.Scan(&hash))
+			hashes = append(hashes, hash)
+		}
+		throw(rows.Err())
+		rows.Close()
+		tokens, err := tx.grainSharingTokenHashes(grainID)
+		throw(err)
+		hashes = append(hashes, tokens...)
+		for _, hash := range hashes {
+			for _, table := range []string{
+				"keyringEntries",
+				"powerboxGrants",
+				"apiTokens",
+				"shareLinks",
+				"sturdyRefs",
+			} {
+				_, err := tx.sqlTx.Exec(`DELETE FROM `+table+` WHERE sha256 = ?`, hash)
+				throw(err)
+			}
+		}
+		for _, table := range []string{
+			"apiTokens",
+			"apiKeyGrains",
+			"shareLinks",
+			"grainMembers",
+			"grainShares",
+			"grainEmbeds",
+			"grainEvents",
+			"grainStarts",
+			"grainPublicIds",
+			"grainResourceLimits",
+			"turnCredentials",
+			"powerboxRequests",
+			"deadLetters",
+			"scheduledJobs",
+			"notifications",
+		} {
+			_, err := tx.sqlTx.Exec(`DELETE FROM `+table+` WHERE grainId = ?`, grainID)
+			throw(err)
+		}
+		res, err := tx.sqlTx.Exec(`DELETE FROM grains WHERE id = ?`, grainID)
+		throw(requireRowsAffected(res, err))
+	}))
+}
+
+// GrainBackupInfo returns what a backup of the grain records about it.
+func (tx Tx) GrainBackupInfo(grainID types.GrainID) (GrainBackupInfo, error) {
+	var (
+		result   GrainBackupInfo
+		manifest []byte
+	)
+	result.ID = grainID
+	row := tx.sqlTx.QueryRow(
+		`SELECT grains.title, grains.ownerId, grains.packageId, packages.manifest,
+			IFNULL(packageApps.appId, '')
+		FROM grains
+			JOIN packages ON grains.packageId = packages.id
+			LEFT JOIN packageApps ON packageApps.packageId = packages.id
+		WHERE grains.id = ?`,
+		grainID,
+	)
+	err := row.Scan(&result.Title, &result.Owner, &result.PackageID, &manifest, &result.AppID)
+	if err != nil {
+		return result, exc.WrapError("GrainBackupInfo", err)
+	}
+	m, err := decodeCapnp[spk.Manifest](manifest)
+	if err != nil {
+		return result, exc.WrapError("GrainBackupInfo", err)
+	}
+	result.AppVersion = m.AppVersion()
+	return result, nil
+}
+
 func (tx Tx) AccountProfile(accountID types.AccountID) (identity.Profile, error) {
 	var (
 		buf []byte
@@ -174,6 +742,23 @@ type GrainInfo struct {
 	ID    types.GrainID
 	Title string
 	Owner string
+
+	// When the grain was moved to the trash; the zero time if it isn't
+	// in the trash.
+	Trashed time.Time
+
+	// Disk space used by the grain, in bytes, as last recorded by
+	// SetGrainStorage.
+	StorageBytes int64
+}
+
+// GrainBackupInfo is the information about a grain which goes in the
+// metadata of its backups.
+type GrainBackupInfo struct {
+	GrainInfo
+	PackageID  string
+	AppID      string // Empty if unknown.
+	AppVersion uint32
 }
 
 type UiViewInfo struct {
@@ -194,6 +779,8 @@ func (tx Tx) AccountKeyring(id types.AccountID) Keyring {
 	}
 }
 
+// AttachGrain adds the grain to the keyring, with the given permissions. The
+// grain must not be in the keyring already; see GrantGrain.
 func (kr Keyring) AttachGrain(grainID types.GrainID, permissions []bool) error {
 	hash, err := kr.tx.SaveSturdyRef(
 		SturdyRefKey{
@@ -209,11 +796,6 @@ func (kr Keyring) AttachGrain(grainID types.GrainID, permissions []bool) error {
 	if err != nil {
 		return err
 	}
-	// FIXME: what happens if we share the same grain twice? id will conflict.
-	//
-	// One idea: drop the unique constraint on ID, and when querying OR together
-	// the permissions on all of the entries in the keyring. This mirros what
-	// sandstorm did, where your access to a grain was the union of all grants.
 	_, err = kr.tx.sqlTx.Exec(
 		`INSERT INTO keyringEntries
 			(id, accountId, sha256, appPermissions)
@@ -222,6 +804,45 @@ func (kr Keyring) AttachGrain(grainID types.GrainID, permissions []bool) error {
 	return err
 }
 
+// GrantGrain adds the permissions to those the keyring has on the grain,
+// attaching the grain if it isn't in the keyring yet. As in Sandstorm, a
+// user's access to a grain is the union of everything they have been granted.
+func (kr Keyring) GrantGrain(grainID types.GrainID, permissions []bool) error {
+	have, err := kr.tx.AccountGrainPermissions(kr.id, grainID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return kr.AttachGrain(grainID, permissions)
+	} else if err != nil {
+		return exc.WrapError("GrantGrain", err)
+	}
+	err = kr.setGrainPermissions(grainID, unionPermissions(have, permissions))
+	return exc.WrapError("GrantGrain", err)
+}
+
+// setGrainPermissions replaces the permissions the keyring has on the grain,
+// which must be in the keyring.
+func (kr Keyring) setGrainPermissions(grainID types.GrainID, permissions []bool) error {
+	_, err := kr.tx.sqlTx.Exec(
+		`UPDATE keyringEntries SET appPermissions = ?
+		WHERE id = ? AND accountId = ?`,
+		fmtPermissions(permissions),
+		grainID,
+		kr.id,
+	)
+	return err
+}
+
+// unionPermissions returns the permissions in either a or b. It may modify a.
+func unionPermissions(a, b []bool) []bool {
+	for i, p := range b {
+		if i < len(a) {
+			a[i] = a[i] || p
+		} else {
+			a = append(a, p)
+		}
+	}
+	return a
+}
+
 func (tx Tx) AccountGrainPermissions(accountID types.AccountID, grainID types.GrainID) (permissions []bool, err error) {
 	row := tx.sqlTx.QueryRow(
 		`SELECT
@@ -253,75 +874,309 @@ func (tx Tx) NewSharingToken(
 	perms []bool,
 	note string,
 ) (string, error) {
-	return exn.Try(func(throw exn.Thrower) string {
-		token := tokenutil.Gen128Base64()
+	token := tokenutil.Gen128Base64()
+	err := tx.AddSharingTokenHash(
+		sha256.Sum256([]byte(token)),
+		grainID,
+		perms,
+		note,
+		time.Unix(math.MaxInt64, 0), // never
+	)
+	return token, err
+}
 
-		_, seg := capnp.NewMultiSegmentMessage(nil)
-		oid, err := system.NewRootSystemObjectId(seg)
-		throw(err)
-		oid.SetSharingToken()
-		st := oid.SharingToken()
-		throw(st.SetGrainId(string(grainID)))
-		throw(st.SetNote(note))
-		dstPerms, err := st.NewPermissions(int32(len(perms)))
+// AddSharingTokenHash adds a sharing token for which only the hash is known,
+// e.g., one imported from Sandstorm, which stores the same hashes.
+func (tx Tx) AddSharingTokenHash(
+	hash [sha256.Size]byte,
+	grainID types.GrainID,
+	perms []bool,
+	note string,
+	expires time.Time,
+) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		oid, err := newSharingTokenObjectID(grainID, perms, note)
 		throw(err)
-		for i, p := range perms {
-			dstPerms.Set(i, p)
-		}
-
-		_, err = tx.SaveSturdyRef(
+		err = tx.saveSturdyRefHash(
+			hash,
 			SturdyRefKey{
-				Token:     []byte(token),
 				OwnerType: "external-api",
 			},
 			SturdyRefValue{
-				Expires:  time.Unix(math.MaxInt64, 0), // never
+				Expires:  expires,
 				ObjectID: capnp.Struct(oid),
 			},
 		)
 		throw(err, "saving sturdyRef")
-		return token
 	})
 }
 
-// CredentialAccount returns the account ID associated with the credential.
-// If there is no existing account, one is created with the visitor role.
-func (tx Tx) CredentialAccount(cred types.Credential) (types.AccountID, error) {
-	accountID, err := exn.Try(func(throw exn.Thrower) types.AccountID {
-		row := tx.sqlTx.QueryRow(
-			`SELECT accountId FROM credentials WHERE type = ? AND scopedId = ?`,
-			cred.Type, cred.ScopedID,
-		)
-		var accountID types.AccountID
-		err := row.Scan(&accountID)
-		if err == sql.ErrNoRows {
-			err = nil
-			// No account; create one and link it to the credential:
-			accountID = types.AccountID(tokenutil.Gen128Base64())
-			throw(tx.AddAccount(NewAccount{
-				ID:   accountID,
-				Role: types.RoleVisitor,
-			}))
-			throw(tx.AddCredential(NewCredential{
-				AccountID:  accountID,
-				Login:      true,
-				Credential: cred,
-			}))
-		}
+// A SharingToken is what a sharing token grants: access to a grain's UiView,
+// with some of its permissions.
+type SharingToken struct {
+	GrainID     types.GrainID
+	Permissions []bool
+	Note        string
+}
+
+// RestoreSharingToken looks up a sharing token made by NewSharingToken or
+// AddSharingTokenHash. It returns sql.ErrNoRows if there is no such token, or
+// it has expired.
+func (tx Tx) RestoreSharingToken(token string) (SharingToken, error) {
+	return exn.Try(func(throw exn.Thrower) SharingToken {
+		v, err := tx.RestoreSturdyRef(SturdyRefKey{
+			Token:     []byte(token),
+			OwnerType: "external-api",
+		})
 		throw(err)
-		return accountID
+		st, err := readSharingToken(v)
+		throw(err, "RestoreSharingToken")
+		return st
 	})
-	err = exc.WrapError("CredentialAccount", err)
-	return accountID, err
 }
 
-// AllUiViews returns all the UiViews in the keyring.
-func (kr Keyring) AllUiViews() ([]UiViewInfo, error) {
-	rows, err := kr.tx.sqlTx.Query(
-		`SELECT
+// readSharingToken decodes a sturdyRef whose objectId is a sharing token.
+func readSharingToken(v SturdyRefValue) (SharingToken, error) {
+	return exn.Try(func(throw exn.Thrower) SharingToken {
+		oid := system.SystemObjectId(v.ObjectID)
+		if v.GrainID != "" || oid.Which() != system.SystemObjectId_Which_sharingToken {
+			throw(errors.New("sturdyRef is not a UiView"))
+		}
+		st := oid.SharingToken()
+		grainID, err := st.GrainId()
+		throw(err)
+		ret := SharingToken{GrainID: types.GrainID(grainID)}
+		ret.Note, err = st.Note()
+		throw(err)
+		perms, err := st.Permissions()
+		throw(err)
+		ret.Permissions = make([]bool, perms.Len())
+		for i := range ret.Permissions {
+			ret.Permissions[i] = perms.At(i)
+		}
+		return ret
+	})
+}
+
+// newSharingTokenObjectID returns the SystemObjectId of a sharing token for
+// the grain's UiView, with the given permissions.
+func newSharingTokenObjectID(grainID types.GrainID, perms []bool, note string) (system.SystemObjectId, error) {
+	return exn.Try(func(throw exn.Thrower) system.SystemObjectId {
+		_, seg := capnp.NewMultiSegmentMessage(nil)
+		oid, err := system.NewRootSystemObjectId(seg)
+		throw(err)
+		oid.SetSharingToken()
+		st := oid.SharingToken()
+		throw(st.SetGrainId(string(grainID)))
+		throw(st.SetNote(note))
+		dstPerms, err := st.NewPermissions(int32(len(perms)))
+		throw(err)
+		for i, p := range perms {
+			dstPerms.Set(i, p)
+		}
+		return oid
+	})
+}
+
+// newIpNetworkObjectID returns the SystemObjectId of the IpNetwork.
+func newIpNetworkObjectID() (system.SystemObjectId, error) {
+	_, seg := capnp.NewMultiSegmentMessage(nil)
+	oid, err := system.NewRootSystemObjectId(seg)
+	if err != nil {
+		return system.SystemObjectId{}, err
+	}
+	oid.SetIpNetwork()
+	return oid, nil
+}
+
+// newIpInterfaceObjectID returns the SystemObjectId of the IpInterface.
+func newIpInterfaceObjectID() (system.SystemObjectId, error) {
+	_, seg := capnp.NewMultiSegmentMessage(nil)
+	oid, err := system.NewRootSystemObjectId(seg)
+	if err != nil {
+		return system.SystemObjectId{}, err
+	}
+	oid.SetIpInterface()
+	return oid, nil
+}
+
+// newThumbnailerObjectID returns the SystemObjectId of the Thumbnailer.
+func newThumbnailerObjectID() (system.SystemObjectId, error) {
+	_, seg := capnp.NewMultiSegmentMessage(nil)
+	oid, err := system.NewRootSystemObjectId(seg)
+	if err != nil {
+		return system.SystemObjectId{}, err
+	}
+	oid.SetThumbnailer()
+	return oid, nil
+}
+
+// newTURNObjectID returns the SystemObjectId of the TurnServer.
+func newTURNObjectID() (system.SystemObjectId, error) {
+	_, seg := capnp.NewMultiSegmentMessage(nil)
+	oid, err := system.NewRootSystemObjectId(seg)
+	if err != nil {
+		return system.SystemObjectId{}, err
+	}
+	oid.SetTurn()
+	return oid, nil
+}
+
+// newIdentityObjectID returns the SystemObjectId of the account's identity.
+func newIdentityObjectID(accountID types.AccountID) (system.SystemObjectId, error) {
+	_, seg := capnp.NewMultiSegmentMessage(nil)
+	oid, err := system.NewRootSystemObjectId(seg)
+	if err != nil {
+		return system.SystemObjectId{}, err
+	}
+	return oid, oid.SetIdentity(string(accountID))
+}
+
+func newHTTPAPIObjectID(url string) (system.SystemObjectId, error) {
+	_, seg := capnp.NewMultiSegmentMessage(nil)
+	oid, err := system.NewRootSystemObjectId(seg)
+	if err != nil {
+		return system.SystemObjectId{}, err
+	}
+	return oid, oid.SetHttpApi(url)
+}
+
+// NewRPCToken makes a token granting access to the external RPC listener as
+// the account with the credential, until expires; see ExternalRpcApi in
+// external.capnp. If there is no such account, the error wraps
+// sql.ErrNoRows.
+func (tx Tx) NewRPCToken(cred types.Credential, expires time.Time) (string, error) {
+	return exn.Try(func(throw exn.Thrower) string {
+		var accountID types.AccountID
+		err := tx.sqlTx.QueryRow(
+			`SELECT accountId FROM credentials WHERE type = ? AND scopedId = ?`,
+			cred.Type, cred.ScopedID,
+		).Scan(&accountID)
+		throw(exc.WrapError("NewRPCToken", err))
+		_, seg := capnp.NewMultiSegmentMessage(nil)
+		oid, err := system.NewRootSystemObjectId(seg)
+		throw(err)
+		throw(oid.SetRpcLogin(string(accountID)))
+		token := tokenutil.Gen128Base64()
+		_, err = tx.SaveSturdyRef(
+			SturdyRefKey{
+				Token:     []byte(token),
+				OwnerType: "external-rpc",
+			},
+			SturdyRefValue{
+				Expires:  expires,
+				ObjectID: capnp.Struct(oid),
+			},
+		)
+		throw(err, "saving sturdyRef")
+		return token
+	})
+}
+
+// RestoreRPCToken returns a login credential of the account which a token made
+// by NewRPCToken grants access to. It returns sql.ErrNoRows if there is no such
+// token, it has expired, or the account has no login credentials left.
+func (tx Tx) RestoreRPCToken(token []byte) (types.Credential, error) {
+	return exn.Try(func(throw exn.Thrower) types.Credential {
+		v, err := tx.RestoreSturdyRef(SturdyRefKey{
+			Token:     token,
+			OwnerType: "external-rpc",
+		})
+		throw(err)
+		oid := system.SystemObjectId(v.ObjectID)
+		if oid.Which() != system.SystemObjectId_Which_rpcLogin {
+			throw(fmt.Errorf("RPC token has object of type %v", oid.Which()))
+		}
+		accountID, err := oid.RpcLogin()
+		throw(err)
+		var cred types.Credential
+		err = tx.sqlTx.QueryRow(
+			`SELECT type, scopedId
+			FROM credentials
+			WHERE accountId = ? AND login
+			ORDER BY type, scopedId
+			LIMIT 1`,
+			accountID,
+		).Scan(&cred.Type, &cred.ScopedID)
+		throw(exc.WrapError("RestoreRPCToken", err))
+		return cred
+	})
+}
+
+// DeleteExpiredLoginTokens deletes the login tokens, sent by email or made by
+// NewRPCToken, which expired at or before now. It returns how many were
+// deleted.
+func (tx Tx) DeleteExpiredLoginTokens(now time.Time) (int64, error) {
+	res, err := tx.sqlTx.Exec(
+		`DELETE FROM sturdyRefs
+		WHERE ownerType IN ('external', 'external-rpc') AND expires <= ?`,
+		now.Unix(),
+	)
+	if err != nil {
+		return 0, exc.WrapError("DeleteExpiredLoginTokens", err)
+	}
+	n, err := res.RowsAffected()
+	return n, exc.WrapError("DeleteExpiredLoginTokens", err)
+}
+
+// DeleteRPCToken revokes a token made by NewRPCToken. It returns
+// sql.ErrNoRows if there is no such token.
+func (tx Tx) DeleteRPCToken(token []byte) error {
+	hash := sha256.Sum256(token)
+	res, err := tx.sqlTx.Exec(
+		`DELETE FROM sturdyRefs WHERE sha256 = ? AND ownerType = 'external-rpc'`,
+		hash[:],
+	)
+	if err != nil {
+		return exc.WrapError("DeleteRPCToken", err)
+	}
+	n, err := res.RowsAffected()
+	if err == nil && n == 0 {
+		err = sql.ErrNoRows
+	}
+	return err
+}
+
+// CredentialAccount returns the account ID associated with the credential.
+// If there is no existing account, one is created with the visitor role.
+func (tx Tx) CredentialAccount(cred types.Credential) (types.AccountID, error) {
+	accountID, err := exn.Try(func(throw exn.Thrower) types.AccountID {
+		row := tx.sqlTx.QueryRow(
+			`SELECT accountId FROM credentials WHERE type = ? AND scopedId = ?`,
+			cred.Type, cred.ScopedID,
+		)
+		var accountID types.AccountID
+		err := row.Scan(&accountID)
+		if err == sql.ErrNoRows {
+			err = nil
+			// No account; create one and link it to the credential:
+			accountID = types.AccountID(tokenutil.Gen128Base64())
+			throw(tx.AddAccount(NewAccount{
+				ID:   accountID,
+				Role: types.RoleVisitor,
+			}))
+			throw(tx.AddCredential(NewCredential{
+				AccountID:  accountID,
+				Login:      true,
+				Credential: cred,
+			}))
+		}
+		throw(err)
+		return accountID
+	})
+	err = exc.WrapError("CredentialAccount", err)
+	return accountID, err
+}
+
+// AllUiViews returns all the UiViews in the keyring.
+func (kr Keyring) AllUiViews() ([]UiViewInfo, error) {
+	rows, err := kr.tx.sqlTx.Query(
+		`SELECT
 			grains.id,
 			grains.title,
 			grains.ownerId,
+			grains.trashed,
+			grains.storageBytes,
 			keyringEntries.appPermissions
 		FROM
 			grains, sturdyRefs, keyringEntries
@@ -342,17 +1197,25 @@ func (kr Keyring) AllUiViews() ([]UiViewInfo, error) {
 	defer rows.Close()
 	var ret []UiViewInfo
 	for rows.Next() {
-		var item UiViewInfo
-		var perm string
+		var (
+			item    UiViewInfo
+			perm    string
+			trashed sql.NullInt64
+		)
 		err := rows.Scan(
 			&item.Grain.ID,
 			&item.Grain.Title,
 			&item.Grain.Owner,
+			&trashed,
+			&item.Grain.StorageBytes,
 			&perm,
 		)
 		if err != nil {
 			return nil, err
 		}
+		if trashed.Valid {
+			item.Grain.Trashed = time.Unix(trashed.Int64, 0)
+		}
 		item.Permissions, err = parsePermissions(perm)
 		if err != nil {
 			return nil, err
@@ -441,6 +1304,35 @@ func (tx Tx) SetGrainViewInfo(grainID string, viewInfo grain.UiView_ViewInfo) er
 	return err
 }
 
+// GrainPermissionTitles returns the titles of the permissions defined by the
+// grain's app, in the default language, as of the last time the grain's view
+// info was fetched. It returns nil if it hasn't been fetched yet.
+func (tx Tx) GrainPermissionTitles(grainID types.GrainID) ([]string, error) {
+	return exn.Try(func(throw exn.Thrower) []string {
+		var buf []byte
+		err := tx.sqlTx.QueryRow(
+			`SELECT cachedViewInfo FROM grains WHERE id = ?`,
+			grainID,
+		).Scan(&buf)
+		throw(exc.WrapError("GrainPermissionTitles", err))
+		if buf == nil {
+			return nil
+		}
+		viewInfo, err := decodeCapnp[grain.UiView_ViewInfo](buf)
+		throw(err)
+		defs, err := viewInfo.Permissions()
+		throw(err)
+		ret := make([]string, defs.Len())
+		for i := range ret {
+			title, err := defs.At(i).Title()
+			throw(err)
+			ret[i], err = title.DefaultText()
+			throw(err)
+		}
+		return ret
+	})
+}
+
 // A SturdyRefKey is the data by which a sturdyRef may be fetched from the database (using
 // RestoreSturdyRef).
 type SturdyRefKey struct {
@@ -464,6 +1356,12 @@ func (tx Tx) SaveSturdyRef(k SturdyRefKey, v SturdyRefValue) ([sha256.Size]byte,
 		panic("Called SaveSturdyRef with nil token")
 	}
 	hash := sha256.Sum256(k.Token)
+	return hash, tx.saveSturdyRefHash(hash, k, v)
+}
+
+// saveSturdyRefHash is like SaveSturdyRef, but takes the hash of the token,
+// and ignores k.Token.
+func (tx Tx) saveSturdyRefHash(hash [sha256.Size]byte, k SturdyRefKey, v SturdyRefValue) error {
 	var grainID *types.GrainID
 	if v.GrainID != "" {
 		grainID = &v.GrainID
@@ -476,7 +1374,7 @@ func (tx Tx) SaveSturdyRef(k SturdyRefKey, v SturdyRefValue) ([sha256.Size]byte,
 		objectID, err = encodeCapnp(v.ObjectID)
 	}
 	if err != nil {
-		return hash, err
+		return err
 	}
 	_, err = tx.sqlTx.Exec(
 		`INSERT INTO sturdyRefs
@@ -496,7 +1394,7 @@ func (tx Tx) SaveSturdyRef(k SturdyRefKey, v SturdyRefValue) ([sha256.Size]byte,
 		grainID,
 		objectID,
 	)
-	return hash, err
+	return err
 }
 
 // Restore a SturdyRef from the database.
@@ -542,11 +1440,15 @@ func (tx Tx) RestoreSturdyRef(k SturdyRefKey) (SturdyRefValue, error) {
 func (tx Tx) DeleteSturdyRef(k SturdyRefKey) error {
 	hash := sha256.Sum256(k.Token)
 	_, err := tx.sqlTx.Exec(`DELETE FROM sturdyRefs WHERE sha256 = ?`, hash[:])
+	if err != nil {
+		return err
+	}
+	_, err = tx.sqlTx.Exec(`DELETE FROM powerboxGrants WHERE sha256 = ?`, hash[:])
 	return err
 }
 
 // CredentialRole gets the role corresponding to the credential. Returns RoleVisitor for unknown
-// credentials.
+// credentials, and those of deactivated accounts.
 func (tx Tx) CredentialRole(cred types.Credential) (role types.Role, err error) {
 	row := tx.sqlTx.QueryRow(`
 		SELECT role
@@ -554,7 +1456,8 @@ func (tx Tx) CredentialRole(cred types.Credential) (role types.Role, err error)
 		WHERE
 			accounts.id = credentials.accountId
 			AND credentials.type = ?
-			AND credentials.scopedId = ?`,
+			AND credentials.scopedId = ?
+			AND accounts.id NOT IN (SELECT accountId FROM deactivatedAccounts)`,
 		cred.Type,
 		cred.ScopedID)
 	err = row.Scan(&role)
@@ -563,3 +1466,2988 @@ func (tx Tx) CredentialRole(cred types.Credential) (role types.Role, err error)
 	}
 	return role, exc.WrapError("CredentialRole", err)
 }
+
+// A GrainStart records how long it took to start a grain.
+type GrainStart struct {
+	GrainID   types.GrainID
+	PackageID string
+	StartedAt time.Time
+	Lookup    time.Duration
+	Sandbox   time.Duration
+	AppInit   time.Duration
+}
+
+// Total returns the total time taken to start the grain.
+func (s GrainStart) Total() time.Duration {
+	return s.Lookup + s.Sandbox + s.AppInit
+}
+
+// MaxGrainStarts is the number of starts recorded for each grain; older starts
+// are removed as new ones are added.
+const MaxGrainStarts = 100
+
+// AddGrainStart records the timing of a grain start.
+func (tx Tx) AddGrainStart(s GrainStart) error {
+	_, err := tx.sqlTx.Exec(
+		`INSERT INTO grainStarts
+			( grainId
+			, packageId
+			, startedAt
+			, lookupUs
+			, sandboxUs
+			, appInitUs
+			)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+		s.GrainID,
+		s.PackageID,
+		s.StartedAt.Unix(),
+		s.Lookup.Microseconds(),
+		s.Sandbox.Microseconds(),
+		s.AppInit.Microseconds(),
+	)
+	if err != nil {
+		return exc.WrapError("AddGrainStart", err)
+	}
+	_, err = tx.sqlTx.Exec(
+		`DELETE FROM grainStarts
+		WHERE grainId = ? AND rowid NOT IN (
+			SELECT rowid FROM grainStarts
+			WHERE grainId = ?
+			ORDER BY startedAt DESC, rowid DESC
+			LIMIT ?
+		)`,
+		s.GrainID,
+		s.GrainID,
+		MaxGrainStarts,
+	)
+	return exc.WrapError("AddGrainStart", err)
+}
+
+// PackageStartStats aggregates grain start timings for a single package.
+type PackageStartStats struct {
+	PackageID string
+	Manifest  spk.Manifest
+	Starts    int
+
+	// Mean duration of each phase:
+	Lookup  time.Duration
+	Sandbox time.Duration
+	AppInit time.Duration
+
+	// Slowest total start time:
+	Max time.Duration
+}
+
+// Mean returns the mean total time taken to start grains.
+func (s PackageStartStats) Mean() time.Duration {
+	return s.Lookup + s.Sandbox + s.AppInit
+}
+
+// PackageStartStats returns grain start timings for grains started since the
+// given time, aggregated by package, slowest packages first.
+func (tx Tx) PackageStartStats(since time.Time) ([]PackageStartStats, error) {
+	rows, err := tx.sqlTx.Query(
+		`SELECT
+			grainStarts.packageId,
+			packages.manifest,
+			count(*),
+			avg(lookupUs),
+			avg(sandboxUs),
+			avg(appInitUs),
+			max(lookupUs + sandboxUs + appInitUs)
+		FROM
+			grainStarts, packages
+		WHERE
+			grainStarts.packageId = packages.id
+			AND grainStarts.startedAt >= ?
+		GROUP BY grainStarts.packageId
+		ORDER BY avg(lookupUs + sandboxUs + appInitUs) DESC
+		`,
+		since.Unix(),
+	)
+	if err != nil {
+		return nil, exc.WrapError("PackageStartStats", err)
+	}
+	defer rows.Close()
+	var ret []PackageStartStats
+	for rows.Next() {
+		var (
+			item                     PackageStartStats
+			manifest                 []byte
+			lookup, sandbox, appInit float64
+			maxUs                    int64
+		)
+		err = rows.Scan(&item.PackageID, &manifest, &item.Starts, &lookup, &sandbox, &appInit, &maxUs)
+		if err != nil {
+			return nil, exc.WrapError("PackageStartStats", err)
+		}
+		item.Manifest, err = decodeCapnp[spk.Manifest](manifest)
+		if err != nil {
+			return nil, err
+		}
+		item.Lookup = time.Duration(lookup) * time.Microsecond
+		item.Sandbox = time.Duration(sandbox) * time.Microsecond
+		item.AppInit = time.Duration(appInit) * time.Microsecond
+		item.Max = time.Duration(maxUs) * time.Microsecond
+		ret = append(ret, item)
+	}
+	return ret, rows.Err()
+}
+
+// RecordDeprecatedAPIUse records a call to a deprecated API method by the
+// given grain, attributing it to the grain's package.
+func (tx Tx) RecordDeprecatedAPIUse(grainID types.GrainID, iface, method string) error {
+	_, err := tx.sqlTx.Exec(
+		`INSERT INTO deprecatedApiUsage
+			(packageId, interface, method, calls, lastUsed)
+			SELECT packageId, ?, ?, 1, ? FROM grains WHERE id = ?
+		ON CONFLICT (packageId, interface, method) DO UPDATE SET
+			calls = calls + 1,
+			lastUsed = excluded.lastUsed
+		`,
+		iface,
+		method,
+		time.Now().Unix(),
+		grainID,
+	)
+	return exc.WrapError("RecordDeprecatedAPIUse", err)
+}
+
+// DeprecatedAPIUsage records an installed package's use of a deprecated
+// API method.
+type DeprecatedAPIUsage struct {
+	PackageID string
+	Manifest  spk.Manifest
+	Interface string
+	Method    string
+	Calls     int64
+	LastUsed  time.Time
+}
+
+// DeprecatedAPIUsage returns all recorded uses of deprecated API methods by
+// installed packages.
+func (tx Tx) DeprecatedAPIUsage() ([]DeprecatedAPIUsage, error) {
+	rows, err := tx.sqlTx.Query(
+		`SELECT
+			deprecatedApiUsage.packageId,
+			packages.manifest,
+			deprecatedApiUsage.interface,
+			deprecatedApiUsage.method,
+			deprecatedApiUsage.calls,
+			deprecatedApiUsage.lastUsed
+		FROM
+			deprecatedApiUsage, packages
+		WHERE
+			deprecatedApiUsage.packageId = packages.id
+		ORDER BY
+			deprecatedApiUsage.packageId,
+			deprecatedApiUsage.interface,
+			deprecatedApiUsage.method
+		`,
+	)
+	if err != nil {
+		return nil, exc.WrapError("DeprecatedAPIUsage", err)
+	}
+	defer rows.Close()
+	var ret []DeprecatedAPIUsage
+	for rows.Next() {
+		var (
+			item     DeprecatedAPIUsage
+			manifest []byte
+			lastUsed int64
+		)
+		err = rows.Scan(
+			&item.PackageID,
+			&manifest,
+			&item.Interface,
+			&item.Method,
+			&item.Calls,
+			&lastUsed,
+		)
+		if err != nil {
+			return nil, exc.WrapError("DeprecatedAPIUsage", err)
+		}
+		item.Manifest, err = decodeCapnp[spk.Manifest](manifest)
+		if err != nil {
+			return nil, err
+		}
+		item.LastUsed = time.Unix(lastUsed, 0)
+		ret = append(ret, item)
+	}
+	return ret, rows.Err()
+}
+
+// RecordTURNCredential records that TURN credentials were issued to the grain.
+func (tx Tx) RecordTURNCredential(grainID types.GrainID) error {
+	_, err := tx.sqlTx.Exec(
+		`INSERT INTO turnCredentials (grainId, issued, lastIssued)
+			VALUES (?, 1, ?)
+		ON CONFLICT (grainId) DO UPDATE SET
+			issued = issued + 1,
+			lastIssued = excluded.lastIssued
+		`,
+		grainID,
+		time.Now().Unix(),
+	)
+	return exc.WrapError("RecordTURNCredential", err)
+}
+
+// TURNUsage summarizes the TURN credentials issued to grains of a package.
+type TURNUsage struct {
+	PackageID  string
+	Manifest   spk.Manifest
+	Grains     int64 // Number of grains which have been issued credentials
+	Issued     int64 // Total credentials issued
+	LastIssued time.Time
+}
+
+// TURNUsage returns the TURN credentials issued, by package.
+func (tx Tx) TURNUsage() ([]TURNUsage, error) {
+	rows, err := tx.sqlTx.Query(
+		`SELECT
+			grains.packageId,
+			packages.manifest,
+			count(*),
+			sum(turnCredentials.issued),
+			max(turnCredentials.lastIssued)
+		FROM
+			turnCredentials, grains, packages
+		WHERE
+			turnCredentials.grainId = grains.id
+			AND grains.packageId = packages.id
+		GROUP BY grains.packageId
+		ORDER BY grains.packageId
+		`,
+	)
+	if err != nil {
+		return nil, exc.WrapError("TURNUsage", err)
+	}
+	defer rows.Close()
+	var ret []TURNUsage
+	for rows.Next() {
+		var (
+			item       TURNUsage
+			manifest   []byte
+			lastIssued int64
+		)
+		err = rows.Scan(&item.PackageID, &manifest, &item.Grains, &item.Issued, &lastIssued)
+		if err != nil {
+			return nil, exc.WrapError("TURNUsage", err)
+		}
+		item.Manifest, err = decodeCapnp[spk.Manifest](manifest)
+		if err != nil {
+			return nil, err
+		}
+		item.LastIssued = time.Unix(lastIssued, 0)
+		ret = append(ret, item)
+	}
+	return ret, rows.Err()
+}
+
+// RevokeSession records that the session with the given ID has ended, so
+// that cookies for it are no longer accepted.
+func (tx Tx) RevokeSession(sessionID []byte) error {
+	_, err := tx.sqlTx.Exec(
+		`INSERT INTO revokedSessions (sessionId, revoked)
+			VALUES (?, ?)
+		ON CONFLICT (sessionId) DO NOTHING
+		`,
+		sessionID,
+		time.Now().Unix(),
+	)
+	return exc.WrapError("RevokeSession", err)
+}
+
+// A UserSessionInfo describes a login session.
+type UserSessionInfo struct {
+	SessionID  []byte
+	Credential types.Credential
+	UserAgent  string
+	RemoteAddr string // The IP address the session was last used from.
+	Started    time.Time
+	LastSeen   time.Time
+}
+
+// AddUserSession records that the login session was started. info.LastSeen
+// is ignored; it starts out as info.Started.
+func (tx Tx) AddUserSession(info UserSessionInfo) error {
+	_, err := tx.sqlTx.Exec(
+		`INSERT INTO userSessions
+			(sessionId, credentialType, scopedId, started, userAgent, remoteAddr, lastSeen)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		info.SessionID,
+		info.Credential.Type,
+		info.Credential.ScopedID,
+		info.Started.Unix(),
+		info.UserAgent,
+		info.RemoteAddr,
+		info.Started.Unix(),
+	)
+	return exc.WrapError("AddUserSession", err)
+}
+
+// TouchUserSession records that the login session with the given ID was
+// used from remoteAddr at the given time. It does nothing if the session
+// isn't recorded.
+func (tx Tx) TouchUserSession(sessionID []byte, remoteAddr string, now time.Time) error {
+	_, err := tx.sqlTx.Exec(
+		`UPDATE userSessions
+		SET lastSeen = ?, remoteAddr = ?
+		WHERE sessionId = ?`,
+		now.Unix(),
+		remoteAddr,
+		sessionID,
+	)
+	return exc.WrapError("TouchUserSession", err)
+}
+
+// RemoveUserSession forgets the login session with the given ID, once it has
+// been revoked.
+func (tx Tx) RemoveUserSession(sessionID []byte) error {
+	_, err := tx.sqlTx.Exec(`DELETE FROM userSessions WHERE sessionId = ?`, sessionID)
+	return exc.WrapError("RemoveUserSession", err)
+}
+
+// AccountUserSessions returns the IDs of the login sessions started with the
+// account's credentials which haven't been removed.
+func (tx Tx) AccountUserSessions(accountID types.AccountID) ([][]byte, error) {
+	rows, err := tx.sqlTx.Query(
+		`SELECT userSessions.sessionId
+		FROM userSessions
+		INNER JOIN credentials
+			ON credentials.type = userSessions.credentialType
+			AND credentials.scopedId = userSessions.scopedId
+		WHERE credentials.accountId = ?
+		ORDER BY userSessions.started`,
+		accountID,
+	)
+	if err != nil {
+		return nil, exc.WrapError("AccountUserSessions", err)
+	}
+	defer rows.Close()
+	var ret [][]byte
+	for rows.Next() {
+		var id []byte
+		if err := rows.Scan(&id); err != nil {
+			return nil, exc.WrapError("AccountUserSessions", err)
+		}
+		ret = append(ret, id)
+	}
+	return ret, exc.WrapError("AccountUserSessions", rows.Err())
+}
+
+// AccountUserSessionInfos returns the login sessions started with the
+// account's credentials which haven't been removed, most recently used first.
+func (tx Tx) AccountUserSessionInfos(accountID types.AccountID) ([]UserSessionInfo, error) {
+	rows, err := tx.sqlTx.Query(
+		`SELECT
+			userSessions.sessionId,
+			userSessions.credentialType,
+			userSessions.scopedId,
+			userSessions.userAgent,
+			userSessions.remoteAddr,
+			userSessions.started,
+			userSessions.lastSeen
+		FROM userSessions
+		INNER JOIN credentials
+			ON credentials.type = userSessions.credentialType
+			AND credentials.scopedId = userSessions.scopedId
+		WHERE credentials.accountId = ?
+		ORDER BY userSessions.lastSeen DESC, userSessions.started DESC`,
+		accountID,
+	)
+	if err != nil {
+		return nil, exc.WrapError("AccountUserSessionInfos", err)
+	}
+	defer rows.Close()
+	var ret []UserSessionInfo
+	for rows.Next() {
+		var (
+			info              UserSessionInfo
+			started, lastSeen int64
+		)
+		err = rows.Scan(
+			&info.SessionID,
+			&info.Credential.Type,
+			&info.Credential.ScopedID,
+			&info.UserAgent,
+			&info.RemoteAddr,
+			&started,
+			&lastSeen,
+		)
+		if err != nil {
+			return nil, exc.WrapError("AccountUserSessionInfos", err)
+		}
+		info.Started = time.Unix(started, 0)
+		info.LastSeen = time.Unix(lastSeen, 0)
+		ret = append(ret, info)
+	}
+	return ret, exc.WrapError("AccountUserSessionInfos", rows.Err())
+}
+
+// IsSessionRevoked returns true if RevokeSession has been called for the
+// session with the given ID.
+func (tx Tx) IsSessionRevoked(sessionID []byte) (bool, error) {
+	var revoked bool
+	err := tx.sqlTx.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM revokedSessions WHERE sessionId = ?)`,
+		sessionID,
+	).Scan(&revoked)
+	return revoked, exc.WrapError("IsSessionRevoked", err)
+}
+
+// DeleteSessionsStartedBefore forgets the login sessions started before
+// cutoff, and the revocations of sessions revoked before it, since their
+// cookies have expired anyway. It returns how many rows were deleted.
+func (tx Tx) DeleteSessionsStartedBefore(cutoff time.Time) (int64, error) {
+	return exn.Try(func(throw exn.Thrower) int64 {
+		var n int64
+		for _, stmt := range []string{
+			`DELETE FROM userSessions WHERE started < ?`,
+			// A session can't be revoked before it starts:
+			`DELETE FROM revokedSessions WHERE revoked < ?`,
+		} {
+			res, err := tx.sqlTx.Exec(stmt, cutoff.Unix())
+			throw(exc.WrapError("DeleteSessionsStartedBefore", err))
+			affected, err := res.RowsAffected()
+			throw(exc.WrapError("DeleteSessionsStartedBefore", err))
+			n += affected
+		}
+		return n
+	})
+}
+
+// MaxGrainEvents is the number of events kept for each grain; older events
+// are removed as new ones are added.
+const MaxGrainEvents = 500
+
+// AddGrainEvent adds an event to a grain's timeline.
+func (tx Tx) AddGrainEvent(e types.GrainEvent) error {
+	_, err := tx.sqlTx.Exec(
+		`INSERT INTO grainEvents (grainId, kind, time, detail)
+			VALUES (?, ?, ?, ?)`,
+		e.GrainID,
+		e.Kind,
+		e.Time.Unix(),
+		e.Detail,
+	)
+	if err != nil {
+		return exc.WrapError("AddGrainEvent", err)
+	}
+	_, err = tx.sqlTx.Exec(
+		`DELETE FROM grainEvents
+		WHERE grainId = ? AND rowid NOT IN (
+			SELECT rowid FROM grainEvents
+			WHERE grainId = ?
+			ORDER BY time DESC, rowid DESC
+			LIMIT ?
+		)`,
+		e.GrainID,
+		e.GrainID,
+		MaxGrainEvents,
+	)
+	return exc.WrapError("AddGrainEvent", err)
+}
+
+// GrainEvents returns the grain's timeline, most recent event first.
+func (tx Tx) GrainEvents(grainID types.GrainID) ([]types.GrainEvent, error) {
+	rows, err := tx.sqlTx.Query(
+		`SELECT kind, time, detail
+		FROM grainEvents
+		WHERE grainId = ?
+		ORDER BY time DESC, rowid DESC
+		`,
+		grainID,
+	)
+	if err != nil {
+		return nil, exc.WrapError("GrainEvents", err)
+	}
+	defer rows.Close()
+	var ret []types.GrainEvent
+	for rows.Next() {
+		var (
+			e    = types.GrainEvent{GrainID: grainID}
+			when int64
+		)
+		if err = rows.Scan(&e.Kind, &when, &e.Detail); err != nil {
+			return nil, exc.WrapError("GrainEvents", err)
+		}
+		e.Time = time.Unix(when, 0)
+		ret = append(ret, e)
+	}
+	return ret, rows.Err()
+}
+
+// CredentialAdminScopes returns the admin scopes of the account with the
+// credential; every scope if it has the admin role, and none for unknown
+// credentials or deactivated accounts.
+func (tx Tx) CredentialAdminScopes(cred types.Credential) (types.AdminScopes, error) {
+	var (
+		accountID types.AccountID
+		role      types.Role
+	)
+	err := tx.sqlTx.QueryRow(`
+		SELECT accounts.id, accounts.role
+		FROM accounts, credentials
+		WHERE
+			accounts.id = credentials.accountId
+			AND credentials.type = ?
+			AND credentials.scopedId = ?
+			AND accounts.id NOT IN (SELECT accountId FROM deactivatedAccounts)`,
+		cred.Type,
+		cred.ScopedID,
+	).Scan(&accountID, &role)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, exc.WrapError("CredentialAdminScopes", err)
+	}
+	return tx.accountAdminScopes(accountID, role)
+}
+
+// AccountRole returns the role of the account. If there is no such account,
+// the error wraps sql.ErrNoRows.
+func (tx Tx) AccountRole(accountID types.AccountID) (types.Role, error) {
+	var role types.Role
+	err := tx.sqlTx.QueryRow(
+		`SELECT role FROM accounts WHERE id = ?`,
+		accountID,
+	).Scan(&role)
+	return role, exc.WrapError("AccountRole", err)
+}
+
+// AccountLocale returns the locale the account's user last used the shell in,
+// or "" if it isn't known. If there is no such account, the error wraps
+// sql.ErrNoRows.
+func (tx Tx) AccountLocale(accountID types.AccountID) (string, error) {
+	var locale sql.NullString
+	err := tx.sqlTx.QueryRow(
+		`SELECT locale FROM accounts WHERE id = ?`,
+		accountID,
+	).Scan(&locale)
+	return locale.String, exc.WrapError("AccountLocale", err)
+}
+
+// SetAccountLocale records the locale the account's user uses the shell in.
+// If there is no such account, the error wraps sql.ErrNoRows.
+func (tx Tx) SetAccountLocale(accountID types.AccountID, locale string) error {
+	res, err := tx.sqlTx.Exec(
+		`UPDATE accounts SET locale = ? WHERE id = ?`,
+		locale,
+		accountID,
+	)
+	if err != nil {
+		return exc.WrapError("SetAccountLocale", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return exc.WrapError("SetAccountLocale", err)
+	}
+	if n == 0 {
+		return exc.WrapError("SetAccountLocale", sql.ErrNoRows)
+	}
+	return nil
+}
+
+// AccountAdminScopes returns the admin scopes of the account; every scope if
+// it has the admin role.
+func (tx Tx) AccountAdminScopes(accountID types.AccountID) (types.AdminScopes, error) {
+	role, err := tx.AccountRole(accountID)
+	if err != nil {
+		return nil, err
+	}
+	return tx.accountAdminScopes(accountID, role)
+}
+
+func (tx Tx) accountAdminScopes(accountID types.AccountID, role types.Role) (types.AdminScopes, error) {
+	if role == types.RoleAdmin {
+		return slices.Clone(types.AllAdminScopes), nil
+	}
+	rows, err := tx.sqlTx.Query(
+		`SELECT scope FROM adminScopes WHERE accountId = ?`,
+		accountID,
+	)
+	if err != nil {
+		return nil, exc.WrapError("accountAdminScopes", err)
+	}
+	defer rows.Close()
+	var granted types.AdminScopes
+	for rows.Next() {
+		var scope types.AdminScope
+		if err = rows.Scan(&scope); err != nil {
+			return nil, exc.WrapError("accountAdminScopes", err)
+		}
+		granted = append(granted, scope)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, exc.WrapError("accountAdminScopes", err)
+	}
+	// Return them in the canonical order, leaving out any which this
+	// version doesn't know about.
+	var ret types.AdminScopes
+	for _, scope := range types.AllAdminScopes {
+		if granted.Has(scope) {
+			ret = append(ret, scope)
+		}
+	}
+	return ret, nil
+}
+
+// SetAdminScopes replaces the admin scopes granted to an account. It has no
+// effect on accounts with the admin role, which have every scope.
+func (tx Tx) SetAdminScopes(accountID types.AccountID, scopes types.AdminScopes) error {
+	_, err := tx.sqlTx.Exec(`DELETE FROM adminScopes WHERE accountId = ?`, accountID)
+	if err != nil {
+		return exc.WrapError("SetAdminScopes", err)
+	}
+	for _, scope := range scopes {
+		_, err = tx.sqlTx.Exec(
+			`INSERT INTO adminScopes (accountId, scope)
+				VALUES (?, ?)
+			ON CONFLICT DO NOTHING
+			`,
+			accountID,
+			scope,
+		)
+		if err != nil {
+			return exc.WrapError("SetAdminScopes", err)
+		}
+	}
+	return nil
+}
+
+// An AdminAccount is an account with at least one admin scope.
+type AdminAccount struct {
+	ID     types.AccountID
+	Role   types.Role
+	Scopes types.AdminScopes
+}
+
+// AdminAccounts returns the accounts with the admin role or any admin scopes,
+// admins first.
+func (tx Tx) AdminAccounts() ([]AdminAccount, error) {
+	rows, err := tx.sqlTx.Query(
+		`SELECT id, role
+		FROM accounts
+		WHERE
+			role = ?
+			OR id IN (SELECT accountId FROM adminScopes)
+		ORDER BY role = ? DESC, id
+		`,
+		types.RoleAdmin,
+		types.RoleAdmin,
+	)
+	if err != nil {
+		return nil, exc.WrapError("AdminAccounts", err)
+	}
+	var ret []AdminAccount
+	for rows.Next() {
+		var a AdminAccount
+		if err = rows.Scan(&a.ID, &a.Role); err != nil {
+			rows.Close()
+			return nil, exc.WrapError("AdminAccounts", err)
+		}
+		ret = append(ret, a)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, exc.WrapError("AdminAccounts", err)
+	}
+	// Look up the scopes once the rows are closed, rather than nesting
+	// queries.
+	for i := range ret {
+		ret[i].Scopes, err = tx.accountAdminScopes(ret[i].ID, ret[i].Role)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// An Account summarizes an account, for administrators.
+type Account struct {
+	ID          types.AccountID
+	Role        types.Role
+	Deactivated time.Time // Zero if the account is active.
+	Credentials []types.Credential
+	Grains      []types.GrainID // The grains the account owns.
+}
+
+// Accounts returns every account, ordered by ID.
+func (tx Tx) Accounts() ([]Account, error) {
+	var ret []Account
+	err := exn.Try0(func(throw exn.Thrower) {
+		rows, err := tx.sqlTx.Query(
+			`SELECT accounts.id, accounts.role, deactivatedAccounts.deactivated
+			FROM accounts
+			LEFT JOIN deactivatedAccounts
+				ON deactivatedAccounts.accountId = accounts.id
+			ORDER BY accounts.id
+			`,
+		)
+		throw(err)
+		defer rows.Close()
+		byID := make(map[types.AccountID]int)
+		for rows.Next() {
+			var (
+				a           Account
+				deactivated sql.NullInt64
+			)
+			throw(rows.Scan(&a.ID, &a.Role, &deactivated))
+			if deactivated.Valid {
+				a.Deactivated = time.Unix(deactivated.Int64, 0)
+			}
+			byID[a.ID] = len(ret)
+			ret = append(ret, a)
+		}
+		throw(rows.Err())
+
+		rows, err = tx.sqlTx.Query(
+			`SELECT accountId, type, scopedId
+			FROM credentials
+			WHERE login
+			ORDER BY type, scopedId`,
+		)
+		throw(err)
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				accountID types.AccountID
+				cred      types.Credential
+			)
+			throw(rows.Scan(&accountID, &cred.Type, &cred.ScopedID))
+			if i, ok := byID[accountID]; ok {
+				ret[i].Credentials = append(ret[i].Credentials, cred)
+			}
+		}
+		throw(rows.Err())
+
+		rows, err = tx.sqlTx.Query(`SELECT ownerId, id FROM grains ORDER BY id`)
+		throw(err)
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				accountID types.AccountID
+				grainID   types.GrainID
+			)
+			throw(rows.Scan(&accountID, &grainID))
+			if i, ok := byID[accountID]; ok {
+				ret[i].Grains = append(ret[i].Grains, grainID)
+			}
+		}
+		throw(rows.Err())
+	})
+	return ret, exc.WrapError("Accounts", err)
+}
+
+// A Contact is an account as other users see it, e.g. when choosing one
+// through the powerbox.
+type Contact struct {
+	AccountID       types.AccountID
+	DisplayName     string
+	PreferredHandle string
+}
+
+// Contacts returns the accounts which aren't deactivated, ordered by ID.
+func (tx Tx) Contacts() ([]Contact, error) {
+	ret, err := tx.contacts(`accounts.id NOT IN (SELECT accountId FROM deactivatedAccounts)`)
+	return ret, exc.WrapError("Contacts", err)
+}
+
+// AccountContact returns the account's Contact, or sql.ErrNoRows if there is
+// no such account.
+func (tx Tx) AccountContact(accountID types.AccountID) (Contact, error) {
+	ret, err := tx.contacts(`accounts.id = ?`, accountID)
+	if err == nil && len(ret) == 0 {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		return Contact{}, exc.WrapError("AccountContact", err)
+	}
+	return ret[0], nil
+}
+
+// contacts returns the Contacts of the accounts matching the condition. As in
+// Sandstorm, accounts whose profiles have no display name go by the first
+// part of their login credential, e.g. of their email address.
+func (tx Tx) contacts(cond string, args ...any) ([]Contact, error) {
+	var ret []Contact
+	err := exn.Try0(func(throw exn.Thrower) {
+		rows, err := tx.sqlTx.Query(
+			`SELECT accounts.id, accounts.profile,
+				(SELECT scopedId FROM credentials
+				WHERE credentials.accountId = accounts.id AND login
+				ORDER BY type, scopedId
+				LIMIT 1)
+			FROM accounts
+			WHERE `+cond+`
+			ORDER BY accounts.id`,
+			args...,
+		)
+		throw(err)
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				c        Contact
+				buf      []byte
+				scopedID sql.NullString
+			)
+			throw(rows.Scan(&c.AccountID, &buf, &scopedID))
+			profile, err := decodeCapnp[identity.Profile](buf)
+			throw(err)
+			displayName, err := profile.DisplayName()
+			throw(err)
+			c.DisplayName, err = displayName.DefaultText()
+			throw(err)
+			c.PreferredHandle, err = profile.PreferredHandle()
+			throw(err)
+			if c.DisplayName == "" {
+				c.DisplayName, _, _ = strings.Cut(scopedID.String, "@")
+			}
+			ret = append(ret, c)
+		}
+		throw(rows.Err())
+	})
+	return ret, err
+}
+
+// SetAccountRole changes the role of the account. If there is no such
+// account, the error wraps sql.ErrNoRows.
+func (tx Tx) SetAccountRole(accountID types.AccountID, role types.Role) error {
+	if !role.IsValid() {
+		return fmt.Errorf("SetAccountRole: invalid role %q", role)
+	}
+	res, err := tx.sqlTx.Exec(
+		`UPDATE accounts SET role = ? WHERE id = ?`,
+		role,
+		accountID,
+	)
+	if err != nil {
+		return exc.WrapError("SetAccountRole", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return exc.WrapError("SetAccountRole", err)
+	}
+	if n == 0 {
+		return exc.WrapError("SetAccountRole", sql.ErrNoRows)
+	}
+	return nil
+}
+
+// SetAccountDeactivated deactivates or reactivates the account. A deactivated
+// account has no role beyond visitor, and no admin scopes; see CredentialRole
+// and CredentialAdminScopes. Deactivating an account which is already
+// deactivated keeps the original time. If there is no such account, the error
+// wraps sql.ErrNoRows.
+func (tx Tx) SetAccountDeactivated(accountID types.AccountID, deactivated bool, now time.Time) error {
+	err := exn.Try0(func(throw exn.Thrower) {
+		var exists bool
+		throw(tx.sqlTx.QueryRow(
+			`SELECT EXISTS (SELECT 1 FROM accounts WHERE id = ?)`,
+			accountID,
+		).Scan(&exists))
+		if !exists {
+			throw(sql.ErrNoRows)
+		}
+		if deactivated {
+			_, err := tx.sqlTx.Exec(
+				`INSERT INTO deactivatedAccounts (accountId, deactivated)
+					VALUES (?, ?)
+				ON CONFLICT DO NOTHING
+				`,
+				accountID,
+				now.Unix(),
+			)
+			throw(err)
+		} else {
+			_, err := tx.sqlTx.Exec(
+				`DELETE FROM deactivatedAccounts WHERE accountId = ?`,
+				accountID,
+			)
+			throw(err)
+		}
+	})
+	return exc.WrapError("SetAccountDeactivated", err)
+}
+
+// CredentialDeactivated returns whether the credential belongs to a
+// deactivated account.
+func (tx Tx) CredentialDeactivated(cred types.Credential) (bool, error) {
+	var deactivated bool
+	err := tx.sqlTx.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1
+			FROM credentials, deactivatedAccounts
+			WHERE
+				credentials.accountId = deactivatedAccounts.accountId
+				AND credentials.type = ?
+				AND credentials.scopedId = ?
+		)`,
+		cred.Type,
+		cred.ScopedID,
+	).Scan(&deactivated)
+	return deactivated, exc.WrapError("CredentialDeactivated", err)
+}
+
+// An Invite is a link through which people can join the server. Accounts
+// which redeem it get its role, unless they already have a higher one.
+type Invite struct {
+	ID        string
+	Role      types.Role
+	MaxUses   int // Zero if any number of accounts may redeem the invite.
+	Created   time.Time
+	Expires   time.Time // Zero if the invite doesn't expire.
+	CreatedBy types.AccountID
+	Note      string
+
+	Redemptions []InviteRedemption // Oldest first.
+}
+
+// An InviteRedemption records an account redeeming an invite.
+type InviteRedemption struct {
+	AccountID types.AccountID
+	Time      time.Time
+}
+
+// Usable returns whether the invite can still be redeemed at the given time.
+func (inv Invite) Usable(now time.Time) bool {
+	return (inv.Expires.IsZero() || now.Before(inv.Expires)) &&
+		(inv.MaxUses == 0 || len(inv.Redemptions) < inv.MaxUses)
+}
+
+// AddInvite records a new invite, which is redeemed with the given token. Its
+// Redemptions are ignored.
+func (tx Tx) AddInvite(inv Invite, token []byte) error {
+	var expires int64
+	if !inv.Expires.IsZero() {
+		expires = inv.Expires.Unix()
+	}
+	hash := sha256.Sum256(token)
+	_, err := tx.sqlTx.Exec(
+		`INSERT INTO invites
+			(id, sha256, role, maxUses, created, expires, createdBy, note)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		inv.ID,
+		hash[:],
+		inv.Role,
+		inv.MaxUses,
+		inv.Created.Unix(),
+		expires,
+		inv.CreatedBy,
+		inv.Note,
+	)
+	return exc.WrapError("AddInvite", err)
+}
+
+// Invites returns every invite, newest first.
+func (tx Tx) Invites() ([]Invite, error) {
+	var ret []Invite
+	err := exn.Try0(func(throw exn.Thrower) {
+		rows, err := tx.sqlTx.Query(
+			`SELECT id, role, maxUses, created, expires, createdBy, note
+			FROM invites
+			ORDER BY created DESC, id`,
+		)
+		throw(err)
+		defer rows.Close()
+		for rows.Next() {
+			inv, err := scanInvite(rows)
+			throw(err)
+			ret = append(ret, inv)
+		}
+		throw(rows.Err())
+		for i := range ret {
+			ret[i].Redemptions, err = tx.inviteRedemptions(ret[i].ID)
+			throw(err)
+		}
+	})
+	return ret, exc.WrapError("Invites", err)
+}
+
+// InviteByToken returns the invite which is redeemed with the given token. If
+// there is none, the error wraps sql.ErrNoRows.
+func (tx Tx) InviteByToken(token []byte) (Invite, error) {
+	hash := sha256.Sum256(token)
+	inv, err := scanInvite(tx.sqlTx.QueryRow(
+		`SELECT id, role, maxUses, created, expires, createdBy, note
+		FROM invites
+		WHERE sha256 = ?`,
+		hash[:],
+	))
+	if err == nil {
+		inv.Redemptions, err = tx.inviteRedemptions(inv.ID)
+	}
+	return inv, exc.WrapError("InviteByToken", err)
+}
+
+func (tx Tx) inviteRedemptions(inviteID string) ([]InviteRedemption, error) {
+	rows, err := tx.sqlTx.Query(
+		`SELECT accountId, redeemed
+		FROM inviteRedemptions
+		WHERE inviteId = ?
+		ORDER BY redeemed, accountId`,
+		inviteID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ret []InviteRedemption
+	for rows.Next() {
+		var (
+			r    InviteRedemption
+			when int64
+		)
+		if err = rows.Scan(&r.AccountID, &when); err != nil {
+			return nil, err
+		}
+		r.Time = time.Unix(when, 0)
+		ret = append(ret, r)
+	}
+	return ret, rows.Err()
+}
+
+func scanInvite(row interface{ Scan(...any) error }) (Invite, error) {
+	var (
+		inv              Invite
+		created, expires int64
+	)
+	err := row.Scan(
+		&inv.ID,
+		&inv.Role,
+		&inv.MaxUses,
+		&created,
+		&expires,
+		&inv.CreatedBy,
+		&inv.Note,
+	)
+	inv.Created = time.Unix(created, 0)
+	if expires != 0 {
+		inv.Expires = time.Unix(expires, 0)
+	}
+	return inv, err
+}
+
+// RedeemInvite redeems the invite with the given token for the account,
+// giving it the invite's role if it doesn't already have a higher one.
+// Redeeming an invite again with the same account has no further effect. If
+// there is no such invite, or it isn't usable, the error wraps sql.ErrNoRows.
+func (tx Tx) RedeemInvite(token []byte, accountID types.AccountID, now time.Time) (Invite, error) {
+	inv, err := exn.Try(func(throw exn.Thrower) Invite {
+		inv, err := tx.InviteByToken(token)
+		throw(err)
+		for _, r := range inv.Redemptions {
+			if r.AccountID == accountID {
+				return inv
+			}
+		}
+		if !inv.Usable(now) {
+			throw(sql.ErrNoRows)
+		}
+		_, err = tx.sqlTx.Exec(
+			`INSERT INTO inviteRedemptions (inviteId, accountId, redeemed)
+				VALUES (?, ?, ?)`,
+			inv.ID,
+			accountID,
+			now.Unix(),
+		)
+		throw(err)
+		inv.Redemptions = append(inv.Redemptions, InviteRedemption{
+			AccountID: accountID,
+			Time:      now,
+		})
+		role, err := tx.AccountRole(accountID)
+		throw(err)
+		if !role.Encompasses(inv.Role) {
+			throw(tx.SetAccountRole(accountID, inv.Role))
+		}
+		return inv
+	})
+	return inv, exc.WrapError("RedeemInvite", err)
+}
+
+// DeleteInvite deletes the invite with the given ID. If there is none, the
+// error wraps sql.ErrNoRows.
+func (tx Tx) DeleteInvite(id string) error {
+	_, err := tx.sqlTx.Exec(`DELETE FROM inviteRedemptions WHERE inviteId = ?`, id)
+	if err != nil {
+		return exc.WrapError("DeleteInvite", err)
+	}
+	res, err := tx.sqlTx.Exec(`DELETE FROM invites WHERE id = ?`, id)
+	if err != nil {
+		return exc.WrapError("DeleteInvite", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return exc.WrapError("DeleteInvite", err)
+	}
+	if n == 0 {
+		return exc.WrapError("DeleteInvite", sql.ErrNoRows)
+	}
+	return nil
+}
+
+// AddGrainEmbed records a new embed for a grain. e.ID must be unique.
+func (tx Tx) AddGrainEmbed(e types.GrainEmbed) error {
+	_, err := tx.sqlTx.Exec(
+		`INSERT INTO grainEmbeds
+			(id, grainId, permissions, frameAncestors, note, created, expires)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		e.ID,
+		e.GrainID,
+		fmtPermissions(e.Permissions),
+		strings.Join(e.FrameAncestors, " "),
+		e.Note,
+		e.Created.Unix(),
+		e.Expires.Unix(),
+	)
+	return exc.WrapError("AddGrainEmbed", err)
+}
+
+// GrainEmbed returns the embed with the given ID, or sql.ErrNoRows if there
+// is none. It may have expired; callers must check.
+func (tx Tx) GrainEmbed(id string) (types.GrainEmbed, error) {
+	row := tx.sqlTx.QueryRow(
+		`SELECT id, grainId, permissions, frameAncestors, note, created, expires
+		FROM grainEmbeds
+		WHERE id = ?`,
+		id,
+	)
+	e, err := scanGrainEmbed(row)
+	return e, exc.WrapError("GrainEmbed", err)
+}
+
+// GrainEmbeds returns the grain's embeds which have not expired as of now,
+// newest first.
+func (tx Tx) GrainEmbeds(grainID types.GrainID, now time.Time) ([]types.GrainEmbed, error) {
+	rows, err := tx.sqlTx.Query(
+		`SELECT id, grainId, permissions, frameAncestors, note, created, expires
+		FROM grainEmbeds
+		WHERE grainId = ? AND expires > ?
+		ORDER BY created DESC, id
+		`,
+		grainID,
+		now.Unix(),
+	)
+	if err != nil {
+		return nil, exc.WrapError("GrainEmbeds", err)
+	}
+	defer rows.Close()
+	var ret []types.GrainEmbed
+	for rows.Next() {
+		e, err := scanGrainEmbed(rows)
+		if err != nil {
+			return nil, exc.WrapError("GrainEmbeds", err)
+		}
+		ret = append(ret, e)
+	}
+	return ret, exc.WrapError("GrainEmbeds", rows.Err())
+}
+
+// DeleteGrainEmbed deletes one of the grain's embeds, returning sql.ErrNoRows
+// if the grain has no embed with that ID. Expired embeds are deleted as a
+// side effect.
+func (tx Tx) DeleteGrainEmbed(grainID types.GrainID, id string, now time.Time) error {
+	_, err := tx.sqlTx.Exec(`DELETE FROM grainEmbeds WHERE expires <= ?`, now.Unix())
+	if err != nil {
+		return exc.WrapError("DeleteGrainEmbed", err)
+	}
+	res, err := tx.sqlTx.Exec(
+		`DELETE FROM grainEmbeds WHERE grainId = ? AND id = ?`,
+		grainID,
+		id,
+	)
+	if err != nil {
+		return exc.WrapError("DeleteGrainEmbed", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return exc.WrapError("DeleteGrainEmbed", err)
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// scanGrainEmbed reads a row of grainEmbeds, selected in column order.
+func scanGrainEmbed(row interface{ Scan(...any) error }) (types.GrainEmbed, error) {
+	var (
+		e                types.GrainEmbed
+		perms, ancestors string
+		created, expires int64
+	)
+	err := row.Scan(&e.ID, &e.GrainID, &perms, &ancestors, &e.Note, &created, &expires)
+	if err != nil {
+		return e, err
+	}
+	e.Permissions, err = parsePermissions(perms)
+	if err != nil {
+		return e, err
+	}
+	e.FrameAncestors = strings.Fields(ancestors)
+	e.Created = time.Unix(created, 0)
+	e.Expires = time.Unix(expires, 0)
+	return e, nil
+}
+
+// NewAPIToken makes an API token for t's grain, with t's permissions, and
+// returns it; it is a sharing token, which never expires. t.ID must be
+// unique.
+func (tx Tx) NewAPIToken(t types.APIToken) (string, error) {
+	return exn.Try(func(throw exn.Thrower) string {
+		token := tokenutil.Gen128Base64()
+		hash := sha256.Sum256([]byte(token))
+		throw(tx.AddSharingTokenHash(hash, t.GrainID, t.Permissions, t.Petname,
+			time.Unix(math.MaxInt64, 0))) // never
+		_, err := tx.sqlTx.Exec(
+			`INSERT INTO apiTokens
+				(id, sha256, grainId, accountId, petname, created)
+				VALUES (?, ?, ?, ?, ?, ?)`,
+			t.ID,
+			hash[:],
+			t.GrainID,
+			t.AccountID,
+			t.Petname,
+			t.Created.Unix(),
+		)
+		throw(exc.WrapError("NewAPIToken", err))
+		return token
+	})
+}
+
+// GrainAPITokens returns the grain's API tokens, oldest first.
+func (tx Tx) GrainAPITokens(grainID types.GrainID) ([]types.APIToken, error) {
+	return exn.Try(func(throw exn.Thrower) []types.APIToken {
+		rows, err := tx.sqlTx.Query(
+			`SELECT apiTokens.id, apiTokens.accountId, apiTokens.petname,
+				apiTokens.created, sturdyRefs.objectId
+			FROM apiTokens, sturdyRefs
+			WHERE apiTokens.grainId = ?
+				AND sturdyRefs.sha256 = apiTokens.sha256
+			ORDER BY apiTokens.created, apiTokens.id`,
+			grainID,
+		)
+		throw(exc.WrapError("GrainAPITokens", err))
+		defer rows.Close()
+		var ret []types.APIToken
+		for rows.Next() {
+			var (
+				t        = types.APIToken{GrainID: grainID}
+				created  int64
+				objectID []byte
+			)
+			throw(rows.Scan(&t.ID, &t.AccountID, &t.Petname, &created, &objectID))
+			t.Created = time.Unix(created, 0)
+			oid, err := decodeCapnp[capnp.Struct](objectID)
+			throw(err)
+			st, err := readSharingToken(SturdyRefValue{ObjectID: oid})
+			throw(err)
+			t.Permissions = st.Permissions
+			ret = append(ret, t)
+		}
+		throw(exc.WrapError("GrainAPITokens", rows.Err()))
+		return ret
+	})
+}
+
+// DeleteAPIToken revokes one of the grain's API tokens, returning the deleted
+// token, or sql.ErrNoRows if the grain has no such token.
+func (tx Tx) DeleteAPIToken(grainID types.GrainID, id string) (types.APIToken, error) {
+	return exn.Try(func(throw exn.Thrower) types.APIToken {
+		t := types.APIToken{ID: id, GrainID: grainID}
+		var (
+			hash    []byte
+			created int64
+		)
+		err := tx.sqlTx.QueryRow(
+			`SELECT sha256, accountId, petname, created FROM apiTokens
+			WHERE grainId = ? AND id = ?`,
+			grainID,
+			id,
+		).Scan(&hash, &t.AccountID, &t.Petname, &created)
+		throw(err)
+		t.Created = time.Unix(created, 0)
+		_, err = tx.sqlTx.Exec(`DELETE FROM sturdyRefs WHERE sha256 = ?`, hash)
+		throw(exc.WrapError("DeleteAPIToken", err))
+		_, err = tx.sqlTx.Exec(`DELETE FROM apiTokens WHERE id = ?`, id)
+		throw(exc.WrapError("DeleteAPIToken", err))
+		return t
+	})
+}
+
+// APIKeyPrefix starts every API key, so that the api host can tell them
+// from API tokens, and so that they are easy to spot if they leak.
+const APIKeyPrefix = "tempest."
+
+// apiKeyTouchInterval is how often RestoreAPIKey records that a key is in
+// use, so that scripts which make many requests don't write to the database
+// for each.
+const apiKeyTouchInterval = time.Minute
+
+// NewAPIKey makes an API key for k's account, and returns it. k.ID must be
+// unique.
+func (tx Tx) NewAPIKey(k types.APIKey) (string, error) {
+	return exn.Try(func(throw exn.Thrower) string {
+		token := APIKeyPrefix + tokenutil.Gen128Base64()
+		hash := sha256.Sum256([]byte(token))
+		_, err := tx.sqlTx.Exec(
+			`INSERT INTO apiKeys
+				(id, sha256, accountId, name, accountRead, created)
+				VALUES (?, ?, ?, ?, ?, ?)`,
+			k.ID,
+			hash[:],
+			k.AccountID,
+			k.Name,
+			k.AccountRead,
+			k.Created.Unix(),
+		)
+		throw(exc.WrapError("NewAPIKey", err))
+		for _, grainID := range k.Grains {
+			_, err := tx.sqlTx.Exec(
+				`INSERT OR IGNORE INTO apiKeyGrains (keyId, grainId) VALUES (?, ?)`,
+				k.ID,
+				grainID,
+			)
+			throw(exc.WrapError("NewAPIKey", err))
+		}
+		return token
+	})
+}
+
+// AccountAPIKeys returns the account's API keys, oldest first.
+func (tx Tx) AccountAPIKeys(accountID types.AccountID) ([]types.APIKey, error) {
+	return exn.Try(func(throw exn.Thrower) []types.APIKey {
+		rows, err := tx.sqlTx.Query(
+			`SELECT id, accountId, name, accountRead, created, lastUsed
+			FROM apiKeys
+			WHERE accountId = ?
+			ORDER BY created, id`,
+			accountID,
+		)
+		throw(exc.WrapError("AccountAPIKeys", err))
+		defer rows.Close()
+		var ret []types.APIKey
+		for rows.Next() {
+			k, err := scanAPIKey(rows)
+			throw(exc.WrapError("AccountAPIKeys", err))
+			ret = append(ret, k)
+		}
+		throw(exc.WrapError("AccountAPIKeys", rows.Err()))
+		rows.Close()
+		for i := range ret {
+			ret[i].Grains, err = tx.apiKeyGrains(ret[i].ID)
+			throw(err)
+		}
+		return ret
+	})
+}
+
+// RestoreAPIKey returns the API key whose key is token, recording that it
+// was used at the given time, once the transaction is committed. It returns
+// sql.ErrNoRows if there is no such key, or its account has been deactivated.
+func (tx Tx) RestoreAPIKey(token string, now time.Time) (types.APIKey, error) {
+	return exn.Try(func(throw exn.Thrower) types.APIKey {
+		hash := sha256.Sum256([]byte(token))
+		k, err := scanAPIKey(tx.sqlTx.QueryRow(
+			`SELECT id, accountId, name, accountRead, created, lastUsed
+			FROM apiKeys
+			WHERE sha256 = ?
+				AND accountId NOT IN (SELECT accountId FROM deactivatedAccounts)`,
+			hash[:],
+		))
+		throw(exc.WrapError("RestoreAPIKey", err))
+		k.Grains, err = tx.apiKeyGrains(k.ID)
+		throw(err)
+		if now.Sub(k.LastUsed) >= apiKeyTouchInterval {
+			_, err = tx.sqlTx.Exec(
+				`UPDATE apiKeys SET lastUsed = ? WHERE id = ?`,
+				now.Unix(),
+				k.ID,
+			)
+			throw(exc.WrapError("RestoreAPIKey", err))
+			k.LastUsed = time.Unix(now.Unix(), 0)
+		}
+		return k
+	})
+}
+
+// DeleteAPIKey revokes one of the account's API keys. It returns
+// sql.ErrNoRows if the account has no such key.
+func (tx Tx) DeleteAPIKey(accountID types.AccountID, id string) error {
+	return exc.WrapError("DeleteAPIKey", exn.Try0(func(throw exn.Thrower) {
+		_, err := tx.sqlTx.Exec(
+			`DELETE FROM apiKeyGrains
+			WHERE keyId IN (SELECT id FROM apiKeys WHERE id = ? AND accountId = ?)`,
+			id,
+			accountID,
+		)
+		throw(err)
+		res, err := tx.sqlTx.Exec(
+			`DELETE FROM apiKeys WHERE id = ? AND accountId = ?`,
+			id,
+			accountID,
+		)
+		throw(requireRowsAffected(res, err))
+	}))
+}
+
+// apiKeyGrains returns the grains the API key may use, ordered by ID.
+func (tx Tx) apiKeyGrains(keyID string) ([]types.GrainID, error) {
+	rows, err := tx.sqlTx.Query(
+		`SELECT grainId FROM apiKeyGrains WHERE keyId = ? ORDER BY grainId`,
+		keyID,
+	)
+	if err != nil {
+		return nil, exc.WrapError("apiKeyGrains", err)
+	}
+	defer rows.Close()
+	var ret []types.GrainID
+	for rows.Next() {
+		var id types.GrainID
+		if err := rows.Scan(&id); err != nil {
+			return nil, exc.WrapError("apiKeyGrains", err)
+		}
+		ret = append(ret, id)
+	}
+	return ret, exc.WrapError("apiKeyGrains", rows.Err())
+}
+
+// scanAPIKey reads a row of apiKeys, without its grains, selected in the
+// order AccountAPIKeys does.
+func scanAPIKey(row interface{ Scan(...any) error }) (types.APIKey, error) {
+	var (
+		k        types.APIKey
+		created  int64
+		lastUsed sql.NullInt64
+	)
+	err := row.Scan(&k.ID, &k.AccountID, &k.Name, &k.AccountRead, &created, &lastUsed)
+	k.Created = time.Unix(created, 0)
+	if lastUsed.Valid {
+		k.LastUsed = time.Unix(lastUsed.Int64, 0)
+	}
+	return k, err
+}
+
+// HasAdmin reports whether any account which hasn't been deactivated has
+// the admin role.
+func (tx Tx) HasAdmin() (bool, error) {
+	var ok bool
+	err := tx.sqlTx.QueryRow(
+		`SELECT EXISTS (
+			SELECT 1 FROM accounts
+			WHERE
+				role = ?
+				AND id NOT IN (SELECT accountId FROM deactivatedAccounts)
+		)`,
+		types.RoleAdmin,
+	).Scan(&ok)
+	return ok, exc.WrapError("HasAdmin", err)
+}
+
+// ServerSettings returns the admin settings stored in the database, by name.
+func (tx Tx) ServerSettings() (map[string]string, error) {
+	rows, err := tx.sqlTx.Query(`SELECT name, value FROM serverSettings`)
+	if err != nil {
+		return nil, exc.WrapError("ServerSettings", err)
+	}
+	defer rows.Close()
+	ret := map[string]string{}
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, exc.WrapError("ServerSettings", err)
+		}
+		ret[name] = value
+	}
+	return ret, exc.WrapError("ServerSettings", rows.Err())
+}
+
+// SetServerSettings stores the given admin settings, by name, leaving any
+// others as they were. Settings set to "" are removed, so that their
+// defaults apply.
+func (tx Tx) SetServerSettings(values map[string]string) error {
+	return exc.WrapError("SetServerSettings", exn.Try0(func(throw exn.Thrower) {
+		for name, value := range values {
+			var err error
+			if value == "" {
+				_, err = tx.sqlTx.Exec(`DELETE FROM serverSettings WHERE name = ?`, name)
+			} else {
+				_, err = tx.sqlTx.Exec(
+					`INSERT INTO serverSettings (name, value) VALUES (?, ?)
+					ON CONFLICT (name) DO UPDATE SET value = excluded.value`,
+					name,
+					value,
+				)
+			}
+			throw(err)
+		}
+	}))
+}
+
+// NewDemoAccount makes a demo account, with the user role, which may log in
+// with cred, and returns its ID. The account expires at the given time; see
+// ExpiredDemoAccounts.
+func (tx Tx) NewDemoAccount(cred types.Credential, expires time.Time) (types.AccountID, error) {
+	accountID := types.AccountID(tokenutil.Gen128Base64())
+	err := exn.Try0(func(throw exn.Thrower) {
+		throw(tx.AddAccount(NewAccount{
+			ID:   accountID,
+			Role: types.RoleUser,
+		}))
+		throw(tx.AddCredential(NewCredential{
+			AccountID:  accountID,
+			Login:      true,
+			Credential: cred,
+		}))
+		_, err := tx.sqlTx.Exec(
+			`INSERT INTO demoAccounts (accountId, expires) VALUES (?, ?)`,
+			accountID,
+			expires.Unix(),
+		)
+		throw(err)
+	})
+	return accountID, exc.WrapError("NewDemoAccount", err)
+}
+
+// DemoAccountExpires returns when the account expires, if it is a demo
+// account, or else the zero time.
+func (tx Tx) DemoAccountExpires(accountID types.AccountID) (time.Time, error) {
+	var expires int64
+	err := tx.sqlTx.QueryRow(
+		`SELECT expires FROM demoAccounts WHERE accountId = ?`,
+		accountID,
+	).Scan(&expires)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, exc.WrapError("DemoAccountExpires", err)
+	}
+	return time.Unix(expires, 0), nil
+}
+
+// ExpiredDemoAccounts returns the demo accounts which expired at or before
+// now.
+func (tx Tx) ExpiredDemoAccounts(now time.Time) ([]types.AccountID, error) {
+	rows, err := tx.sqlTx.Query(
+		`SELECT accountId FROM demoAccounts WHERE expires <= ? ORDER BY expires`,
+		now.Unix(),
+	)
+	if err != nil {
+		return nil, exc.WrapError("ExpiredDemoAccounts", err)
+	}
+	defer rows.Close()
+	var ret []types.AccountID
+	for rows.Next() {
+		var id types.AccountID
+		if err := rows.Scan(&id); err != nil {
+			return nil, exc.WrapError("ExpiredDemoAccounts", err)
+		}
+		ret = append(ret, id)
+	}
+	return ret, exc.WrapError("ExpiredDemoAccounts", rows.Err())
+}
+
+// DeleteAccount deletes the account, which must not own any grains, with its
+// credentials, login sessions, keyring and notifications, and the sharing
+// tokens, API keys and invites it made. It returns sql.ErrNoRows if there is
+// no such account.
+func (tx Tx) DeleteAccount(accountID types.AccountID) error {
+	return exc.WrapError("DeleteAccount", exn.Try0(func(throw exn.Thrower) {
+		var grains int
+		throw(tx.sqlTx.QueryRow(
+			`SELECT COUNT(*) FROM grains WHERE ownerId = ?`,
+			accountID,
+		).Scan(&grains))
+		if grains > 0 {
+			throw(fmt.Errorf("account still owns %d grains", grains))
+		}
+		// The sturdyRefs in its keyring, and those of the sharing
+		// tokens it made:
+		rows, err := tx.sqlTx.Query(
+			`SELECT sha256 FROM sturdyRefs WHERE ownerType = 'userkeyring' AND owner = ?
+			UNION SELECT sha256 FROM apiTokens WHERE accountId = ?
+			UNION SELECT sha256 FROM shareLinks WHERE accountId = ?`,
+			accountID,
+			accountID,
+			accountID,
+		)
+		throw(err)
+		defer rows.Close()
+		var hashes [][]byte
+		for rows.Next() {
+			var hash []byte
+			throw(rows.Scan(&hash))
+			hashes = append(hashes, hash)
+		}
+		throw(rows.Err())
+		rows.Close()
+		for _, hash := range hashes {
+			for _, table := range []string{
+				"keyringEntries",
+				"powerboxGrants",
+				"apiTokens",
+				"shareLinks",
+				"sturdyRefs",
+			} {
+				_, err := tx.sqlTx.Exec(`DELETE FROM `+table+` WHERE sha256 = ?`, hash)
+				throw(err)
+			}
+		}
+		for _, stmt := range []string{
+			`DELETE FROM apiKeyGrains
+			WHERE keyId IN (SELECT id FROM apiKeys WHERE accountId = ?1)`,
+			`DELETE FROM inviteRedemptions
+			WHERE accountId = ?1
+				OR inviteId IN (SELECT id FROM invites WHERE createdBy = ?1)`,
+			`DELETE FROM userSessions
+			WHERE (credentialType, scopedId) IN
+				(SELECT type, scopedId FROM credentials WHERE accountId = ?1)`,
+			`DELETE FROM grainShares WHERE accountId = ?1 OR sharedBy = ?1`,
+			`DELETE FROM apiKeys WHERE accountId = ?1`,
+			`DELETE FROM invites WHERE createdBy = ?1`,
+			`DELETE FROM keyringEntries WHERE accountId = ?1`,
+			`DELETE FROM grainMembers WHERE accountId = ?1`,
+			`DELETE FROM powerboxRequests WHERE accountId = ?1`,
+			`DELETE FROM powerboxGrants WHERE accountId = ?1`,
+			`DELETE FROM notifications WHERE accountId = ?1`,
+			`DELETE FROM adminScopes WHERE accountId = ?1`,
+			`DELETE FROM deactivatedAccounts WHERE accountId = ?1`,
+			`DELETE FROM demoAccounts WHERE accountId = ?1`,
+			`DELETE FROM credentials WHERE accountId = ?1`,
+		} {
+			_, err := tx.sqlTx.Exec(stmt, accountID)
+			throw(err)
+		}
+		res, err := tx.sqlTx.Exec(`DELETE FROM accounts WHERE id = ?`, accountID)
+		throw(requireRowsAffected(res, err))
+	}))
+}
+
+// GrainRolePermissions returns the permissions of each of the roles defined
+// by the grain's app, as of the last time the grain's view info was fetched.
+// It returns nil if it hasn't been fetched yet.
+func (tx Tx) GrainRolePermissions(grainID types.GrainID) ([][]bool, error) {
+	return exn.Try(func(throw exn.Thrower) [][]bool {
+		var buf []byte
+		err := tx.sqlTx.QueryRow(
+			`SELECT cachedViewInfo FROM grains WHERE id = ?`,
+			grainID,
+		).Scan(&buf)
+		throw(exc.WrapError("GrainRolePermissions", err))
+		if buf == nil {
+			return nil
+		}
+		viewInfo, err := decodeCapnp[grain.UiView_ViewInfo](buf)
+		throw(err)
+		roles, err := viewInfo.Roles()
+		throw(err)
+		ret := make([][]bool, roles.Len())
+		for i := range ret {
+			perms, err := roles.At(i).Permissions()
+			throw(err)
+			ret[i] = make([]bool, perms.Len())
+			for j := range ret[i] {
+				ret[i][j] = perms.At(j)
+			}
+		}
+		return ret
+	})
+}
+
+// GrainRoleTitles returns the titles of the roles defined by the grain's app,
+// in the default language, as of the last time the grain's view info was
+// fetched. It returns nil if it hasn't been fetched yet.
+func (tx Tx) GrainRoleTitles(grainID types.GrainID) ([]string, error) {
+	return exn.Try(func(throw exn.Thrower) []string {
+		var buf []byte
+		err := tx.sqlTx.QueryRow(
+			`SELECT cachedViewInfo FROM grains WHERE id = ?`,
+			grainID,
+		).Scan(&buf)
+		throw(exc.WrapError("GrainRoleTitles", err))
+		if buf == nil {
+			return nil
+		}
+		viewInfo, err := decodeCapnp[grain.UiView_ViewInfo](buf)
+		throw(err)
+		roles, err := viewInfo.Roles()
+		throw(err)
+		ret := make([]string, roles.Len())
+		for i := range ret {
+			title, err := roles.At(i).Title()
+			throw(err)
+			ret[i], err = title.DefaultText()
+			throw(err)
+		}
+		return ret
+	})
+}
+
+// NewShareLink records a new share link, returning its token.
+func (tx Tx) NewShareLink(l types.ShareLink) (string, error) {
+	return exn.Try(func(throw exn.Thrower) string {
+		token := tokenutil.Gen128Base64()
+		hash := sha256.Sum256([]byte(token))
+		expires := l.Expires
+		if expires.IsZero() {
+			expires = time.Unix(math.MaxInt64, 0) // never
+		}
+		throw(tx.AddSharingTokenHash(hash, l.GrainID, l.Permissions, l.Note, expires))
+		_, err := tx.sqlTx.Exec(
+			`INSERT INTO shareLinks
+				(id, sha256, grainId, accountId, roleId, note,
+					singleUse, uses, created)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			l.ID,
+			hash[:],
+			l.GrainID,
+			l.AccountID,
+			l.RoleID,
+			l.Note,
+			l.SingleUse,
+			l.Uses,
+			l.Created.Unix(),
+		)
+		throw(exc.WrapError("NewShareLink", err))
+		return token
+	})
+}
+
+// GrainShareLinks returns the grain's share links which haven't expired as of
+// now, oldest first.
+func (tx Tx) GrainShareLinks(grainID types.GrainID, now time.Time) ([]types.ShareLink, error) {
+	return exn.Try(func(throw exn.Thrower) []types.ShareLink {
+		rows, err := tx.sqlTx.Query(
+			`SELECT shareLinks.id, shareLinks.accountId, shareLinks.roleId,
+				shareLinks.note, shareLinks.singleUse, shareLinks.uses,
+				shareLinks.created, sturdyRefs.expires, sturdyRefs.objectId
+			FROM shareLinks, sturdyRefs
+			WHERE shareLinks.grainId = ?
+				AND sturdyRefs.sha256 = shareLinks.sha256
+				AND sturdyRefs.expires > ?
+			ORDER BY shareLinks.created, shareLinks.id`,
+			grainID,
+			now.Unix(),
+		)
+		throw(exc.WrapError("GrainShareLinks", err))
+		defer rows.Close()
+		var ret []types.ShareLink
+		for rows.Next() {
+			var (
+				l                = types.ShareLink{GrainID: grainID}
+				created, expires int64
+				objectID         []byte
+			)
+			throw(rows.Scan(&l.ID, &l.AccountID, &l.RoleID, &l.Note,
+				&l.SingleUse, &l.Uses, &created, &expires, &objectID))
+			l.Created = time.Unix(created, 0)
+			if expires != math.MaxInt64 {
+				l.Expires = time.Unix(expires, 0)
+			}
+			oid, err := decodeCapnp[capnp.Struct](objectID)
+			throw(err)
+			st, err := readSharingToken(SturdyRefValue{ObjectID: oid})
+			throw(err)
+			l.Permissions = st.Permissions
+			ret = append(ret, l)
+		}
+		throw(exc.WrapError("GrainShareLinks", rows.Err()))
+		return ret
+	})
+}
+
+// DeleteShareLink revokes one of the grain's share links, returning the
+// deleted link, or sql.ErrNoRows if the grain has no such link. Users who
+// have joined through the link keep their access.
+func (tx Tx) DeleteShareLink(grainID types.GrainID, id string) (types.ShareLink, error) {
+	return exn.Try(func(throw exn.Thrower) types.ShareLink {
+		l := types.ShareLink{ID: id, GrainID: grainID}
+		var (
+			hash    []byte
+			created int64
+		)
+		err := tx.sqlTx.QueryRow(
+			`SELECT sha256, accountId, roleId, note, singleUse, uses, created
+			FROM shareLinks
+			WHERE grainId = ? AND id = ?`,
+			grainID,
+			id,
+		).Scan(&hash, &l.AccountID, &l.RoleID, &l.Note, &l.SingleUse, &l.Uses, &created)
+		throw(err)
+		l.Created = time.Unix(created, 0)
+		throw(tx.deleteShareLink(id, hash))
+		return l
+	})
+}
+
+func (tx Tx) deleteShareLink(id string, hash []byte) error {
+	_, err := tx.sqlTx.Exec(`DELETE FROM sturdyRefs WHERE sha256 = ?`, hash)
+	if err == nil {
+		_, err = tx.sqlTx.Exec(`DELETE FROM shareLinks WHERE id = ?`, id)
+	}
+	return exc.WrapError("deleteShareLink", err)
+}
+
+// RedeemSharingToken grants the account the permissions of the sharing token
+// on its grain, adding them to any it already has; see Keyring.GrantGrain. If
+// the token is a share link's, the account becomes a member of the grain
+// through the link, which counts the use, and is revoked if it is single-use.
+// It returns the grain and the membership, or sql.ErrNoRows if the token
+// doesn't exist or has expired as of now.
+//
+// The account must not own the grain.
+func (tx Tx) RedeemSharingToken(token string, accountID types.AccountID, now time.Time) (types.GrainID, types.GrainMember, error) {
+	var grainID types.GrainID
+	m, err := exn.Try(func(throw exn.Thrower) types.GrainMember {
+		hash := sha256.Sum256([]byte(token))
+		var objectID []byte
+		err := tx.sqlTx.QueryRow(
+			`SELECT objectId FROM sturdyRefs
+			WHERE sha256 = ?
+				AND ownerType = 'external-api'
+				AND owner = ''
+				AND expires > ?`,
+			hash[:],
+			now.Unix(),
+		).Scan(&objectID)
+		throw(err)
+		oid, err := decodeCapnp[capnp.Struct](objectID)
+		throw(err)
+		st, err := readSharingToken(SturdyRefValue{ObjectID: oid})
+		throw(err)
+		grainID = st.GrainID
+		throw(tx.AccountKeyring(accountID).GrantGrain(st.GrainID, st.Permissions))
+		m := types.GrainMember{
+			AccountID: accountID,
+			Joined:    now,
+		}
+
+		var (
+			roleID    int
+			singleUse bool
+		)
+		err = tx.sqlTx.QueryRow(
+			`SELECT id, accountId, roleId, singleUse FROM shareLinks
+			WHERE sha256 = ?`,
+			hash[:],
+		).Scan(&m.ShareLinkID, &m.SharedBy, &roleID, &singleUse)
+		if errors.Is(err, sql.ErrNoRows) {
+			// A plain sharing token, e.g. an API token, or one
+			// imported from Sandstorm.
+			m.Joined = time.Time{}
+			var sharedBy types.AccountID
+			err = tx.sqlTx.QueryRow(
+				`SELECT accountId FROM apiTokens WHERE sha256 = ?`,
+				hash[:],
+			).Scan(&sharedBy)
+			if !errors.Is(err, sql.ErrNoRows) {
+				throw(err)
+				throw(tx.addGrainShare(st.GrainID, types.GrainShare{
+					SharedBy:    sharedBy,
+					AccountID:   accountID,
+					Permissions: st.Permissions,
+					Created:     now,
+				}))
+			}
+		} else {
+			throw(err)
+			m.RoleID = &roleID
+			_, err = tx.sqlTx.Exec(
+				`INSERT OR REPLACE INTO grainMembers
+					(grainId, accountId, roleId, shareLinkId, sharedBy, joined)
+					VALUES (?, ?, ?, ?, ?, ?)`,
+				st.GrainID,
+				accountID,
+				roleID,
+				m.ShareLinkID,
+				m.SharedBy,
+				now.Unix(),
+			)
+			throw(err)
+			throw(tx.addGrainShare(st.GrainID, types.GrainShare{
+				SharedBy:    m.SharedBy,
+				AccountID:   accountID,
+				ShareLinkID: m.ShareLinkID,
+				RoleID:      &roleID,
+				Permissions: st.Permissions,
+				Created:     now,
+			}))
+			if singleUse {
+				throw(tx.deleteShareLink(m.ShareLinkID, hash[:]))
+			} else {
+				_, err = tx.sqlTx.Exec(
+					`UPDATE shareLinks SET uses = uses + 1 WHERE id = ?`,
+					m.ShareLinkID,
+				)
+				throw(err)
+			}
+		}
+		m.Permissions, err = tx.AccountGrainPermissions(accountID, st.GrainID)
+		throw(err)
+		return m
+	})
+	return grainID, m, exc.WrapError("RedeemSharingToken", err)
+}
+
+// GrainMembers returns the users who have access to the grain, other than its
+// owner, in the order they joined.
+func (tx Tx) GrainMembers(grainID types.GrainID) ([]types.GrainMember, error) {
+	ret, err := exn.Try(func(throw exn.Thrower) []types.GrainMember {
+		rows, err := tx.sqlTx.Query(
+			`SELECT keyringEntries.accountId, keyringEntries.appPermissions,
+				grainMembers.roleId, grainMembers.shareLinkId,
+				grainMembers.sharedBy, grainMembers.joined
+			FROM sturdyRefs
+			JOIN keyringEntries
+				ON keyringEntries.sha256 = sturdyRefs.sha256
+			JOIN grains
+				ON grains.id = sturdyRefs.grainId
+			LEFT JOIN grainMembers
+				ON grainMembers.grainId = sturdyRefs.grainId
+				AND grainMembers.accountId = keyringEntries.accountId
+			WHERE sturdyRefs.grainId = ?
+				AND sturdyRefs.ownerType = 'userkeyring'
+				AND sturdyRefs.objectId IS NULL
+				AND keyringEntries.accountId != grains.ownerId
+			ORDER BY grainMembers.joined, keyringEntries.accountId`,
+			grainID,
+		)
+		throw(err)
+		defer rows.Close()
+		var ret []types.GrainMember
+		for rows.Next() {
+			var (
+				m           types.GrainMember
+				perms       string
+				roleID      sql.NullInt64
+				shareLinkID sql.NullString
+				sharedBy    sql.NullString
+				joined      sql.NullInt64
+			)
+			throw(rows.Scan(&m.AccountID, &perms, &roleID, &shareLinkID, &sharedBy, &joined))
+			m.Permissions, err = parsePermissions(perms)
+			throw(err)
+			if roleID.Valid {
+				id := int(roleID.Int64)
+				m.RoleID = &id
+				m.ShareLinkID = shareLinkID.String
+				m.SharedBy = types.AccountID(sharedBy.String)
+				m.Joined = time.Unix(joined.Int64, 0)
+			}
+			ret = append(ret, m)
+		}
+		throw(rows.Err())
+		rows.Close()
+
+		for i := range ret {
+			ret[i].Credentials, err = tx.loginCredentials(ret[i].AccountID)
+			throw(err)
+		}
+		return ret
+	})
+	return ret, exc.WrapError("GrainMembers", err)
+}
+
+// loginCredentials returns the credentials the account may log in with.
+func (tx Tx) loginCredentials(accountID types.AccountID) ([]types.Credential, error) {
+	rows, err := tx.sqlTx.Query(
+		`SELECT type, scopedId
+		FROM credentials
+		WHERE accountId = ? AND login
+		ORDER BY type, scopedId`,
+		accountID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ret []types.Credential
+	for rows.Next() {
+		var cred types.Credential
+		if err = rows.Scan(&cred.Type, &cred.ScopedID); err != nil {
+			return nil, err
+		}
+		ret = append(ret, cred)
+	}
+	return ret, rows.Err()
+}
+
+// addGrainShare adds the share to the grain's share graph, adding its
+// permissions to those of any earlier share between the same users. Users
+// sharing with themselves, by opening their own links, are left out.
+func (tx Tx) addGrainShare(grainID types.GrainID, share types.GrainShare) error {
+	if share.SharedBy == share.AccountID {
+		return nil
+	}
+	var perms string
+	err := tx.sqlTx.QueryRow(
+		`SELECT permissions FROM grainShares
+		WHERE grainId = ? AND sharedBy = ? AND accountId = ?`,
+		grainID,
+		share.SharedBy,
+		share.AccountID,
+	).Scan(&perms)
+	if err == nil {
+		var have []bool
+		have, err = parsePermissions(perms)
+		if err != nil {
+			return err
+		}
+		share.Permissions = unionPermissions(have, share.Permissions)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	var roleID sql.NullInt64
+	if share.RoleID != nil {
+		roleID = sql.NullInt64{Int64: int64(*share.RoleID), Valid: true}
+	}
+	_, err = tx.sqlTx.Exec(
+		`INSERT OR REPLACE INTO grainShares
+			(grainId, sharedBy, accountId, shareLinkId, roleId,
+				permissions, created)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		grainID,
+		share.SharedBy,
+		share.AccountID,
+		share.ShareLinkID,
+		roleID,
+		fmtPermissions(share.Permissions),
+		share.Created.Unix(),
+	)
+	return err
+}
+
+// GrainShareGraph returns the grain's share graph. Members are in the order of
+// their account IDs, and shares in the order they were made.
+func (tx Tx) GrainShareGraph(grainID types.GrainID) (types.ShareGraph, error) {
+	g, err := exn.Try(func(throw exn.Thrower) types.ShareGraph {
+		owner, err := tx.getGrainOwner(grainID)
+		throw(err)
+		g := types.ShareGraph{
+			Owner:   owner,
+			Members: []types.AccountID{},
+			Shares:  []types.GrainShare{},
+		}
+		rows, err := tx.sqlTx.Query(
+			`SELECT owner FROM sturdyRefs
+			WHERE grainId = ?
+				AND ownerType = 'userkeyring'
+				AND objectId IS NULL
+				AND owner != ?
+			ORDER BY owner`,
+			grainID,
+			owner,
+		)
+		throw(err)
+		defer rows.Close()
+		for rows.Next() {
+			var id types.AccountID
+			throw(rows.Scan(&id))
+			g.Members = append(g.Members, id)
+		}
+		throw(rows.Err())
+		rows.Close()
+
+		rows, err = tx.sqlTx.Query(
+			`SELECT sharedBy, accountId, shareLinkId, roleId, permissions,
+				created
+			FROM grainShares
+			WHERE grainId = ?
+			ORDER BY created, sharedBy, accountId`,
+			grainID,
+		)
+		throw(err)
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				s       types.GrainShare
+				roleID  sql.NullInt64
+				perms   string
+				created int64
+			)
+			throw(rows.Scan(&s.SharedBy, &s.AccountID, &s.ShareLinkID,
+				&roleID, &perms, &created))
+			if roleID.Valid {
+				id := int(roleID.Int64)
+				s.RoleID = &id
+			}
+			s.Permissions, err = parsePermissions(perms)
+			throw(err)
+			s.Created = time.Unix(created, 0)
+			g.Shares = append(g.Shares, s)
+		}
+		throw(rows.Err())
+		return g
+	})
+	return g, exc.WrapError("GrainShareGraph", err)
+}
+
+// RemoveGrainMember revokes the account's access to the grain, and that of
+// everyone who had it only through them; see types.ShareGraph.Revoked. Those
+// users' share links and API tokens for the grain are revoked too. Members
+// who keep their access through others lose the permissions which the
+// revoked users gave them. It returns the users whose access was revoked, or
+// if the account has none, an error wrapping sql.ErrNoRows.
+//
+// The account must not own the grain.
+func (tx Tx) RemoveGrainMember(grainID types.GrainID, accountID types.AccountID) ([]types.AccountID, error) {
+	ret, err := exn.Try(func(throw exn.Thrower) []types.AccountID {
+		g, err := tx.GrainShareGraph(grainID)
+		throw(err)
+		revoked := g.Revoked(accountID)
+		if len(revoked) == 0 {
+			throw(sql.ErrNoRows)
+		}
+		for _, id := range revoked {
+			throw(tx.revokeGrainAccess(grainID, id))
+		}
+
+		// Recompute the permissions of those who lost shares from
+		// the revoked users, from the shares they have left.
+		isRevoked := map[types.AccountID]bool{}
+		for _, id := range revoked {
+			isRevoked[id] = true
+		}
+		kept := map[types.AccountID][]bool{}
+		lost := map[types.AccountID]bool{}
+		for _, s := range g.Shares {
+			switch {
+			case isRevoked[s.AccountID]:
+			case isRevoked[s.SharedBy]:
+				lost[s.AccountID] = true
+			default:
+				kept[s.AccountID] = unionPermissions(kept[s.AccountID], s.Permissions)
+			}
+		}
+		for id := range lost {
+			throw(tx.AccountKeyring(id).setGrainPermissions(grainID, kept[id]))
+		}
+		return revoked
+	})
+	return ret, exc.WrapError("RemoveGrainMember", err)
+}
+
+// revokeGrainAccess removes the account's access to the grain, its shares to
+// and from others, and its share links and API tokens for the grain.
+func (tx Tx) revokeGrainAccess(grainID types.GrainID, accountID types.AccountID) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		var hash []byte
+		err := tx.sqlTx.QueryRow(
+			`SELECT sturdyRefs.sha256
+			FROM sturdyRefs, keyringEntries
+			WHERE keyringEntries.sha256 = sturdyRefs.sha256
+				AND sturdyRefs.grainId = ?
+				AND sturdyRefs.ownerType = 'userkeyring'
+				AND sturdyRefs.owner = ?
+				AND sturdyRefs.objectId IS NULL`,
+			grainID,
+			accountID,
+		).Scan(&hash)
+		throw(err)
+		for _, stmt := range []string{
+			`DELETE FROM keyringEntries WHERE sha256 = ?`,
+			`DELETE FROM sturdyRefs WHERE sha256 = ?`,
+		} {
+			_, err = tx.sqlTx.Exec(stmt, hash)
+			throw(err)
+		}
+		_, err = tx.sqlTx.Exec(
+			`DELETE FROM grainMembers WHERE grainId = ? AND accountId = ?`,
+			grainID,
+			accountID,
+		)
+		throw(err)
+		_, err = tx.sqlTx.Exec(
+			`DELETE FROM grainShares
+			WHERE grainId = ? AND (accountId = ? OR sharedBy = ?)`,
+			grainID,
+			accountID,
+			accountID,
+		)
+		throw(err)
+
+		for _, table := range []string{"shareLinks", "apiTokens"} {
+			rows, err := tx.sqlTx.Query(
+				`SELECT sha256 FROM `+table+`
+				WHERE grainId = ? AND accountId = ?`,
+				grainID,
+				accountID,
+			)
+			throw(err)
+			defer rows.Close()
+			var hashes [][]byte
+			for rows.Next() {
+				var hash []byte
+				throw(rows.Scan(&hash))
+				hashes = append(hashes, hash)
+			}
+			throw(rows.Err())
+			rows.Close()
+			for _, hash := range hashes {
+				_, err = tx.sqlTx.Exec(`DELETE FROM `+table+` WHERE sha256 = ?`, hash)
+				throw(err)
+				_, err = tx.sqlTx.Exec(`DELETE FROM sturdyRefs WHERE sha256 = ?`, hash)
+				throw(err)
+			}
+		}
+	})
+}
+
+// A PowerboxRequest is a request, made by a grain through the browser, for the
+// user to choose a capability to grant the grain.
+type PowerboxRequest struct {
+	ID        string
+	GrainID   types.GrainID   // The grain making the request.
+	AccountID types.AccountID // The user asked to fulfill it.
+
+	// Query describes the capabilities the grain asked for: packed,
+	// base64-encoded PowerboxDescriptors, as passed to the postMessage API.
+	Query     []string
+	SaveLabel string
+	Expires   time.Time
+}
+
+// AddPowerboxRequest records a new powerbox request. r.ID must be unique.
+func (tx Tx) AddPowerboxRequest(r PowerboxRequest) error {
+	_, err := tx.sqlTx.Exec(
+		`INSERT INTO powerboxRequests
+			(id, grainId, accountId, query, saveLabel, expires)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+		r.ID,
+		r.GrainID,
+		r.AccountID,
+		strings.Join(r.Query, " "),
+		r.SaveLabel,
+		r.Expires.Unix(),
+	)
+	return exc.WrapError("AddPowerboxRequest", err)
+}
+
+// PowerboxRequest returns the powerbox request with the given ID, or
+// sql.ErrNoRows if there is none. It may have expired; callers must check.
+func (tx Tx) PowerboxRequest(id string) (PowerboxRequest, error) {
+	var (
+		r       PowerboxRequest
+		query   string
+		expires int64
+	)
+	err := tx.sqlTx.QueryRow(
+		`SELECT id, grainId, accountId, query, saveLabel, expires
+		FROM powerboxRequests
+		WHERE id = ?`,
+		id,
+	).Scan(&r.ID, &r.GrainID, &r.AccountID, &query, &r.SaveLabel, &expires)
+	if err != nil {
+		return r, exc.WrapError("PowerboxRequest", err)
+	}
+	r.Query = strings.Fields(query)
+	r.Expires = time.Unix(expires, 0)
+	return r, nil
+}
+
+// DeletePowerboxRequest deletes a powerbox request, once it is fulfilled or
+// canceled. Expired requests are deleted as a side effect.
+func (tx Tx) DeletePowerboxRequest(id string, now time.Time) error {
+	_, err := tx.sqlTx.Exec(
+		`DELETE FROM powerboxRequests WHERE id = ? OR expires <= ?`,
+		id,
+		now.Unix(),
+	)
+	return exc.WrapError("DeletePowerboxRequest", err)
+}
+
+// A PowerboxGrant is a capability which a user granted to another grain
+// through the powerbox: a grain's UiView, or if Network is true, access to
+// the network, or if IPInterface is true, permission to listen on it, or if
+// Thumbnailer is true, the thumbnail service, or if TURN is true, the TURN
+// relay, or if Identity is set, the identity of that account. Network,
+// interface, thumbnailer, TURN and identity grants have no GrainID,
+// Permissions or Note.
+type PowerboxGrant struct {
+	Network     bool
+	IPInterface bool
+	Thumbnailer bool
+	TURN        bool
+	Identity    types.AccountID
+
+	// HTTPAPI is the URL of an HTTP API outside of Tempest, which requests
+	// through the capability are sent to, with HTTPAuthorization, if any,
+	// as their Authorization header.
+	HTTPAPI           string
+	HTTPAuthorization string
+
+	// GrainID is the grain whose UiView the capability is, and Permissions
+	// are those it has on it.
+	GrainID     types.GrainID
+	Permissions []bool
+	Note        string // The label the requesting grain gave it.
+	Expires     time.Time
+
+	// AccountID is the user who granted the capability, who must still
+	// have RequiredPermissions on the grain holding it for it to work.
+	AccountID           types.AccountID
+	RequiredPermissions []bool
+}
+
+// SavePowerboxGrant saves a sturdyRef to the grant. k's token must not be nil.
+func (tx Tx) SavePowerboxGrant(k SturdyRefKey, g PowerboxGrant) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		var (
+			oid system.SystemObjectId
+			err error
+		)
+		if g.Network {
+			oid, err = newIpNetworkObjectID()
+		} else if g.IPInterface {
+			oid, err = newIpInterfaceObjectID()
+		} else if g.Thumbnailer {
+			oid, err = newThumbnailerObjectID()
+		} else if g.TURN {
+			oid, err = newTURNObjectID()
+		} else if g.Identity != "" {
+			oid, err = newIdentityObjectID(g.Identity)
+		} else if g.HTTPAPI != "" {
+			oid, err = newHTTPAPIObjectID(g.HTTPAPI)
+		} else {
+			oid, err = newSharingTokenObjectID(g.GrainID, g.Permissions, g.Note)
+		}
+		throw(err)
+		hash, err := tx.SaveSturdyRef(k, SturdyRefValue{
+			Expires:  g.Expires,
+			ObjectID: capnp.Struct(oid),
+		})
+		throw(err, "saving sturdyRef")
+		_, err = tx.sqlTx.Exec(
+			`INSERT INTO powerboxGrants
+				(sha256, accountId, requiredPermissions, httpAuthorization)
+				VALUES (?, ?, ?, ?)`,
+			hash[:],
+			g.AccountID,
+			fmtPermissions(g.RequiredPermissions),
+			sql.NullString{String: g.HTTPAuthorization, Valid: g.HTTPAuthorization != ""},
+		)
+		throw(exc.WrapError("SavePowerboxGrant", err))
+	})
+}
+
+// RestorePowerboxGrant restores a sturdyRef saved by SavePowerboxGrant. It
+// returns sql.ErrNoRows if there is no such sturdyRef, or it has expired.
+func (tx Tx) RestorePowerboxGrant(k SturdyRefKey) (PowerboxGrant, error) {
+	return exn.Try(func(throw exn.Thrower) PowerboxGrant {
+		v, err := tx.RestoreSturdyRef(k)
+		throw(err)
+		hash := sha256.Sum256(k.Token)
+		var (
+			required      string
+			authorization sql.NullString
+		)
+		g := PowerboxGrant{Expires: v.Expires}
+		err = tx.sqlTx.QueryRow(
+			`SELECT accountId, requiredPermissions, httpAuthorization
+				FROM powerboxGrants WHERE sha256 = ?`,
+			hash[:],
+		).Scan(&g.AccountID, &required, &authorization)
+		throw(exc.WrapError("RestorePowerboxGrant", err))
+		g.HTTPAuthorization = authorization.String
+		g.RequiredPermissions, err = parsePermissions(required)
+		throw(err)
+
+		if v.GrainID == "" {
+			switch oid := system.SystemObjectId(v.ObjectID); oid.Which() {
+			case system.SystemObjectId_Which_ipNetwork:
+				g.Network = true
+				return g
+			case system.SystemObjectId_Which_ipInterface:
+				g.IPInterface = true
+				return g
+			case system.SystemObjectId_Which_thumbnailer:
+				g.Thumbnailer = true
+				return g
+			case system.SystemObjectId_Which_turn:
+				g.TURN = true
+				return g
+			case system.SystemObjectId_Which_identity:
+				accountID, err := oid.Identity()
+				throw(err, "RestorePowerboxGrant")
+				g.Identity = types.AccountID(accountID)
+				return g
+			case system.SystemObjectId_Which_httpApi:
+				g.HTTPAPI, err = oid.HttpApi()
+				throw(err, "RestorePowerboxGrant")
+				return g
+			}
+		}
+		st, err := readSharingToken(v)
+		throw(err, "RestorePowerboxGrant")
+		g.GrainID = st.GrainID
+		g.Permissions = st.Permissions
+		g.Note = st.Note
+		return g
+	})
+}
+
+// GrainPublicID returns the grain's public ID, which is the local part of its
+// email address, assigning it one if it has none yet.
+func (tx Tx) GrainPublicID(grainID types.GrainID) (string, error) {
+	_, err := tx.sqlTx.Exec(
+		`INSERT INTO grainPublicIds (grainId, publicId)
+			VALUES (?, ?)
+		ON CONFLICT (grainId) DO NOTHING`,
+		grainID,
+		newPublicID(),
+	)
+	if err != nil {
+		return "", exc.WrapError("GrainPublicID", err)
+	}
+	var publicID string
+	err = tx.sqlTx.QueryRow(
+		`SELECT publicId FROM grainPublicIds WHERE grainId = ?`,
+		grainID,
+	).Scan(&publicID)
+	return publicID, exc.WrapError("GrainPublicID", err)
+}
+
+// PublicIDGrain returns the grain with the given public ID, or sql.ErrNoRows
+// if there is none.
+func (tx Tx) PublicIDGrain(publicID string) (types.GrainID, error) {
+	var grainID types.GrainID
+	err := tx.sqlTx.QueryRow(
+		`SELECT grainId FROM grainPublicIds WHERE publicId = ?`,
+		strings.ToLower(publicID),
+	).Scan(&grainID)
+	return grainID, exc.WrapError("PublicIDGrain", err)
+}
+
+// newPublicID generates a public ID. They are case-insensitive, since email
+// addresses in practice are, so only lowercase letters and digits are used.
+func newPublicID() string {
+	return strings.ToLower(base32.StdEncoding.EncodeToString(tokenutil.Gen128()[:10]))
+}
+
+// MaxDeadLetters is the number of dead letters kept; older ones are removed
+// as new ones are added.
+const MaxDeadLetters = 1000
+
+// A DeadLetter is an incoming email which could not be delivered to its
+// grain.
+type DeadLetter struct {
+	ID       int64
+	GrainID  types.GrainID
+	Sender   string // The envelope sender.
+	Data     []byte // The message, as received.
+	Error    string // Why delivery failed.
+	Received time.Time
+}
+
+// AddDeadLetter records an undeliverable message. d.ID is ignored; a new one
+// is assigned.
+func (tx Tx) AddDeadLetter(d DeadLetter) error {
+	_, err := tx.sqlTx.Exec(
+		`INSERT INTO deadLetters (grainId, sender, data, error, received)
+			VALUES (?, ?, ?, ?, ?)`,
+		d.GrainID,
+		d.Sender,
+		d.Data,
+		d.Error,
+		d.Received.Unix(),
+	)
+	if err != nil {
+		return exc.WrapError("AddDeadLetter", err)
+	}
+	_, err = tx.sqlTx.Exec(
+		`DELETE FROM deadLetters
+		WHERE id NOT IN (
+			SELECT id FROM deadLetters
+			ORDER BY received DESC, id DESC
+			LIMIT ?
+		)`,
+		MaxDeadLetters,
+	)
+	return exc.WrapError("AddDeadLetter", err)
+}
+
+// DeadLetters returns all dead letters, most recent first. Their Data is
+// left out, since it may be large; use DeadLetter to get it.
+func (tx Tx) DeadLetters() ([]DeadLetter, error) {
+	rows, err := tx.sqlTx.Query(
+		`SELECT id, grainId, sender, error, received
+		FROM deadLetters
+		ORDER BY received DESC, id DESC`,
+	)
+	if err != nil {
+		return nil, exc.WrapError("DeadLetters", err)
+	}
+	defer rows.Close()
+	var ret []DeadLetter
+	for rows.Next() {
+		var (
+			d        DeadLetter
+			received int64
+		)
+		if err = rows.Scan(&d.ID, &d.GrainID, &d.Sender, &d.Error, &received); err != nil {
+			return nil, exc.WrapError("DeadLetters", err)
+		}
+		d.Received = time.Unix(received, 0)
+		ret = append(ret, d)
+	}
+	return ret, rows.Err()
+}
+
+// DeadLetter returns the dead letter with the given ID, or sql.ErrNoRows if
+// there is none.
+func (tx Tx) DeadLetter(id int64) (DeadLetter, error) {
+	var (
+		d        DeadLetter
+		received int64
+	)
+	err := tx.sqlTx.QueryRow(
+		`SELECT id, grainId, sender, data, error, received
+		FROM deadLetters
+		WHERE id = ?`,
+		id,
+	).Scan(&d.ID, &d.GrainID, &d.Sender, &d.Data, &d.Error, &received)
+	d.Received = time.Unix(received, 0)
+	return d, exc.WrapError("DeadLetter", err)
+}
+
+// DeleteDeadLetter deletes a dead letter, once it has been delivered or
+// discarded.
+func (tx Tx) DeleteDeadLetter(id int64) error {
+	_, err := tx.sqlTx.Exec(`DELETE FROM deadLetters WHERE id = ?`, id)
+	return exc.WrapError("DeleteDeadLetter", err)
+}
+
+// AddScheduledJob saves a newly scheduled job. j.ID must be unique.
+func (tx Tx) AddScheduledJob(j scheduler.Job) error {
+	_, err := tx.sqlTx.Exec(
+		`INSERT INTO scheduledJobs
+			(id, grainId, name, objectId, period, next, failures, lastError, disabled)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		j.ID,
+		j.GrainID,
+		j.Name,
+		j.ObjectID,
+		j.Period,
+		j.Next.Unix(),
+		j.Failures,
+		j.LastError,
+		j.Disabled,
+	)
+	return exc.WrapError("AddScheduledJob", err)
+}
+
+// UpdateScheduledJob saves changes to a job's schedule and failures.
+func (tx Tx) UpdateScheduledJob(j scheduler.Job) error {
+	_, err := tx.sqlTx.Exec(
+		`UPDATE scheduledJobs
+		SET next = ?, failures = ?, lastError = ?, disabled = ?
+		WHERE id = ?`,
+		j.Next.Unix(),
+		j.Failures,
+		j.LastError,
+		j.Disabled,
+		j.ID,
+	)
+	return exc.WrapError("UpdateScheduledJob", err)
+}
+
+// DeleteScheduledJob deletes a job.
+func (tx Tx) DeleteScheduledJob(id string) error {
+	_, err := tx.sqlTx.Exec(`DELETE FROM scheduledJobs WHERE id = ?`, id)
+	return exc.WrapError("DeleteScheduledJob", err)
+}
+
+// DueScheduledJobs returns the jobs which are enabled and due at or before
+// now, earliest first.
+func (tx Tx) DueScheduledJobs(now time.Time) ([]scheduler.Job, error) {
+	rows, err := tx.sqlTx.Query(
+		`SELECT id, grainId, name, objectId, period, next, failures, lastError, disabled
+		FROM scheduledJobs
+		WHERE NOT disabled AND next <= ?
+		ORDER BY next, id`,
+		now.Unix(),
+	)
+	if err != nil {
+		return nil, exc.WrapError("DueScheduledJobs", err)
+	}
+	defer rows.Close()
+	var ret []scheduler.Job
+	for rows.Next() {
+		var (
+			j    scheduler.Job
+			next int64
+		)
+		err = rows.Scan(&j.ID, &j.GrainID, &j.Name, &j.ObjectID, &j.Period,
+			&next, &j.Failures, &j.LastError, &j.Disabled)
+		if err != nil {
+			return nil, exc.WrapError("DueScheduledJobs", err)
+		}
+		j.Next = time.Unix(next, 0)
+		ret = append(ret, j)
+	}
+	return ret, rows.Err()
+}
+
+// NextScheduledJobDue returns the earliest time at which an enabled job is
+// due, or false if there are no enabled jobs.
+func (tx Tx) NextScheduledJobDue() (time.Time, bool, error) {
+	var next sql.NullInt64
+	err := tx.sqlTx.QueryRow(
+		`SELECT MIN(next) FROM scheduledJobs WHERE NOT disabled`,
+	).Scan(&next)
+	if err != nil {
+		return time.Time{}, false, exc.WrapError("NextScheduledJobDue", err)
+	}
+	return time.Unix(next.Int64, 0), next.Valid, nil
+}
+
+// MaxNotifications is the number of notifications kept per account; older
+// ones are removed as new ones are added.
+const MaxNotifications = 200
+
+// A Notification tells a user about activity in a grain.
+type Notification struct {
+	ID         int64
+	AccountID  types.AccountID
+	GrainID    types.GrainID
+	GrainTitle string // Filled in by AccountNotifications; ignored by AddNotification.
+	Path       string // Path within the grain's UI.
+	Text       string
+	Time       time.Time
+	Read       bool
+}
+
+// AddNotification records a notification, returning its ID. n.ID is ignored.
+func (tx Tx) AddNotification(n Notification) (int64, error) {
+	res, err := tx.sqlTx.Exec(
+		`INSERT INTO notifications (accountId, grainId, path, text, time, read)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+		n.AccountID,
+		n.GrainID,
+		n.Path,
+		n.Text,
+		n.Time.UnixNano(),
+		n.Read,
+	)
+	if err != nil {
+		return 0, exc.WrapError("AddNotification", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, exc.WrapError("AddNotification", err)
+	}
+	_, err = tx.sqlTx.Exec(
+		`DELETE FROM notifications
+		WHERE accountId = ? AND id NOT IN (
+			SELECT id FROM notifications
+			WHERE accountId = ?
+			ORDER BY time DESC, id DESC
+			LIMIT ?
+		)`,
+		n.AccountID,
+		n.AccountID,
+		MaxNotifications,
+	)
+	return id, exc.WrapError("AddNotification", err)
+}
+
+// AccountNotifications returns the account's notifications, most recent
+// first.
+func (tx Tx) AccountNotifications(accountID types.AccountID) ([]Notification, error) {
+	rows, err := tx.sqlTx.Query(
+		`SELECT
+			notifications.id,
+			notifications.grainId,
+			grains.title,
+			notifications.path,
+			notifications.text,
+			notifications.time,
+			notifications.read
+		FROM notifications, grains
+		WHERE
+			notifications.grainId = grains.id
+			AND notifications.accountId = ?
+		ORDER BY notifications.time DESC, notifications.id DESC`,
+		accountID,
+	)
+	if err != nil {
+		return nil, exc.WrapError("AccountNotifications", err)
+	}
+	defer rows.Close()
+	var ret []Notification
+	for rows.Next() {
+		var (
+			n      Notification
+			timeNs int64
+		)
+		err = rows.Scan(&n.ID, &n.GrainID, &n.GrainTitle, &n.Path, &n.Text, &timeNs, &n.Read)
+		if err != nil {
+			return nil, exc.WrapError("AccountNotifications", err)
+		}
+		n.AccountID = accountID
+		n.Time = time.Unix(0, timeNs)
+		ret = append(ret, n)
+	}
+	return ret, rows.Err()
+}
+
+// UnreadNotificationCount returns the number of the account's notifications
+// which have not been read.
+func (tx Tx) UnreadNotificationCount(accountID types.AccountID) (int, error) {
+	var count int
+	err := tx.sqlTx.QueryRow(
+		`SELECT COUNT(*) FROM notifications WHERE accountId = ? AND NOT read`,
+		accountID,
+	).Scan(&count)
+	return count, exc.WrapError("UnreadNotificationCount", err)
+}
+
+// MarkNotificationsRead marks the account's notifications with the given IDs
+// as read, or all of its notifications if ids is empty. IDs of notifications
+// which belong to other accounts are ignored.
+func (tx Tx) MarkNotificationsRead(accountID types.AccountID, ids []int64) error {
+	if len(ids) == 0 {
+		_, err := tx.sqlTx.Exec(
+			`UPDATE notifications SET read = true WHERE accountId = ?`,
+			accountID,
+		)
+		return exc.WrapError("MarkNotificationsRead", err)
+	}
+	for _, id := range ids {
+		_, err := tx.sqlTx.Exec(
+			`UPDATE notifications SET read = true WHERE accountId = ? AND id = ?`,
+			accountID,
+			id,
+		)
+		if err != nil {
+			return exc.WrapError("MarkNotificationsRead", err)
+		}
+	}
+	return nil
+}
+
+// GrainAccounts returns the accounts which have access to the grain: its
+// owner, and those with it in their keyrings.
+func (tx Tx) GrainAccounts(grainID types.GrainID) ([]types.AccountID, error) {
+	rows, err := tx.sqlTx.Query(
+		`SELECT ownerId FROM grains WHERE id = ?
+		UNION
+		SELECT owner FROM sturdyRefs
+		WHERE
+			grainId = ?
+			AND objectId is null
+			AND ownerType = 'userkeyring'
+			AND expires > ?`,
+		grainID,
+		grainID,
+		time.Now().Unix(),
+	)
+	if err != nil {
+		return nil, exc.WrapError("GrainAccounts", err)
+	}
+	defer rows.Close()
+	var ret []types.AccountID
+	for rows.Next() {
+		var id types.AccountID
+		if err = rows.Scan(&id); err != nil {
+			return nil, exc.WrapError("GrainAccounts", err)
+		}
+		ret = append(ret, id)
+	}
+	return ret, exc.WrapError("GrainAccounts", rows.Err())
+}
+
+// GrainActivityType returns the definition of the activity type with the
+// given index, from the grain's view info as of the last time it was fetched.
+// It returns false if the view info hasn't been fetched, or doesn't define
+// the type.
+func (tx Tx) GrainActivityType(grainID types.GrainID, index uint16) (activity.ActivityTypeDef, bool, error) {
+	var (
+		def activity.ActivityTypeDef
+		ok  bool
+	)
+	err := exn.Try0(func(throw exn.Thrower) {
+		var buf []byte
+		err := tx.sqlTx.QueryRow(
+			`SELECT cachedViewInfo FROM grains WHERE id = ?`,
+			grainID,
+		).Scan(&buf)
+		throw(exc.WrapError("GrainActivityType", err))
+		if buf == nil {
+			return
+		}
+		viewInfo, err := decodeCapnp[grain.UiView_ViewInfo](buf)
+		throw(err)
+		defs, err := viewInfo.EventTypes()
+		throw(err)
+		if int(index) < defs.Len() {
+			def, ok = defs.At(int(index)), true
+		}
+	})
+	return def, ok, err
+}
+
+// MaxLoginAuditEntries is the number of login audit entries kept; older ones
+// are removed as new ones are added.
+const MaxLoginAuditEntries = 10000
+
+// Events recorded in the login audit log.
+const (
+	LoginSucceeded = "login"      // A user logged in.
+	LoginFailed    = "failed"     // A login attempt failed, e.g. with a bad token.
+	LoginLockedOut = "locked-out" // Too many failures; see loginlimit.
+)
+
+// A LoginAuditEntry records a login attempt, or something related to one.
+type LoginAuditEntry struct {
+	ID         int64
+	Time       time.Time
+	Event      string // One of the Login* constants.
+	RemoteAddr string // The client's IP address.
+	Credential types.Credential
+	Detail     string
+}
+
+// AddLoginAuditEntry records e. e.ID is ignored; a new one is assigned.
+func (tx Tx) AddLoginAuditEntry(e LoginAuditEntry) error {
+	_, err := tx.sqlTx.Exec(
+		`INSERT INTO loginAudit
+			(time, event, remoteAddr, credentialType, scopedId, detail)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+		e.Time.Unix(),
+		e.Event,
+		e.RemoteAddr,
+		e.Credential.Type,
+		e.Credential.ScopedID,
+		e.Detail,
+	)
+	if err != nil {
+		return exc.WrapError("AddLoginAuditEntry", err)
+	}
+	_, err = tx.sqlTx.Exec(
+		`DELETE FROM loginAudit
+		WHERE id NOT IN (
+			SELECT id FROM loginAudit
+			ORDER BY id DESC
+			LIMIT ?
+		)`,
+		MaxLoginAuditEntries,
+	)
+	return exc.WrapError("AddLoginAuditEntry", err)
+}
+
+// LoginAuditEntries returns up to limit login audit entries, most recent
+// first.
+func (tx Tx) LoginAuditEntries(limit int) ([]LoginAuditEntry, error) {
+	rows, err := tx.sqlTx.Query(
+		`SELECT id, time, event, remoteAddr, credentialType, scopedId, detail
+		FROM loginAudit
+		ORDER BY id DESC
+		LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, exc.WrapError("LoginAuditEntries", err)
+	}
+	defer rows.Close()
+	var ret []LoginAuditEntry
+	for rows.Next() {
+		var (
+			e  LoginAuditEntry
+			at int64
+		)
+		err = rows.Scan(&e.ID, &at, &e.Event, &e.RemoteAddr,
+			&e.Credential.Type, &e.Credential.ScopedID, &e.Detail)
+		if err != nil {
+			return nil, exc.WrapError("LoginAuditEntries", err)
+		}
+		e.Time = time.Unix(at, 0)
+		ret = append(ret, e)
+	}
+	return ret, exc.WrapError("LoginAuditEntries", rows.Err())
+}
+
+// DeleteLogsBefore deletes the login audit entries, grain events and grain
+// start timings recorded before cutoff. It returns how many were deleted.
+func (tx Tx) DeleteLogsBefore(cutoff time.Time) (int64, error) {
+	return exn.Try(func(throw exn.Thrower) int64 {
+		var n int64
+		for _, q := range []string{
+			`DELETE FROM loginAudit WHERE time < ?`,
+			`DELETE FROM grainEvents WHERE time < ?`,
+			`DELETE FROM grainStarts WHERE startedAt < ?`,
+		} {
+			res, err := tx.sqlTx.Exec(q, cutoff.Unix())
+			throw(exc.WrapError("DeleteLogsBefore", err))
+			affected, err := res.RowsAffected()
+			throw(exc.WrapError("DeleteLogsBefore", err))
+			n += affected
+		}
+		return n
+	})
+}
+
+// GrainResourceLimits returns the grain's own resource limits, which
+// override the server's defaults. Limits which aren't overridden are zero.
+func (tx Tx) GrainResourceLimits(grainID types.GrainID) (cgroup.Limits, error) {
+	var l cgroup.Limits
+	err := tx.sqlTx.QueryRow(
+		`SELECT memoryMax, cpuWeight, pidsMax
+		FROM grainResourceLimits
+		WHERE grainId = ?`,
+		grainID,
+	).Scan(&l.MemoryMax, &l.CPUWeight, &l.PidsMax)
+	if err == sql.ErrNoRows {
+		return cgroup.Limits{}, nil
+	}
+	return l, exc.WrapError("GrainResourceLimits", err)
+}
+
+// SetGrainResourceLimits sets the grain's own resource limits. If they are
+// all zero, the grain goes back to using the defaults. They take effect the
+// next time the grain starts.
+func (tx Tx) SetGrainResourceLimits(grainID types.GrainID, l cgroup.Limits) error {
+	if l == (cgroup.Limits{}) {
+		_, err := tx.sqlTx.Exec(
+			`DELETE FROM grainResourceLimits WHERE grainId = ?`,
+			grainID,
+		)
+		return exc.WrapError("SetGrainResourceLimits", err)
+	}
+	_, err := tx.sqlTx.Exec(
+		`INSERT INTO grainResourceLimits (grainId, memoryMax, cpuWeight, pidsMax)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (grainId) DO UPDATE SET
+			memoryMax = excluded.memoryMax,
+			cpuWeight = excluded.cpuWeight,
+			pidsMax = excluded.pidsMax`,
+		grainID, l.MemoryMax, l.CPUWeight, l.PidsMax,
+	)
+	return exc.WrapError("SetGrainResourceLimits", err)
+}
+
+// AllGrainResourceLimits returns the resource limits of every grain which
+// has its own.
+func (tx Tx) AllGrainResourceLimits() (map[types.GrainID]cgroup.Limits, error) {
+	rows, err := tx.sqlTx.Query(
+		`SELECT grainId, memoryMax, cpuWeight, pidsMax FROM grainResourceLimits`,
+	)
+	if err != nil {
+		return nil, exc.WrapError("AllGrainResourceLimits", err)
+	}
+	defer rows.Close()
+	ret := make(map[types.GrainID]cgroup.Limits)
+	for rows.Next() {
+		var (
+			grainID types.GrainID
+			l       cgroup.Limits
+		)
+		if err = rows.Scan(&grainID, &l.MemoryMax, &l.CPUWeight, &l.PidsMax); err != nil {
+			return nil, exc.WrapError("AllGrainResourceLimits", err)
+		}
+		ret[grainID] = l
+	}
+	return ret, exc.WrapError("AllGrainResourceLimits", rows.Err())
+}
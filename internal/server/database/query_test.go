@@ -2,13 +2,21 @@ package database
 
 import (
 	"database/sql"
+	"fmt"
 	"math"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
+	"capnproto.org/go/capnp/v3"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sandstorm.org/go/tempest/capnp/identity"
+	spk "sandstorm.org/go/tempest/capnp/package"
 	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/cgroup"
+	"sandstorm.org/go/tempest/internal/server/scheduler"
 )
 
 // Test the CredentialAccount method
@@ -88,6 +96,226 @@ func TestAccountGrainPermissionsRequiresMatchingKeyringEntry(t *testing.T) {
 	})
 }
 
+func TestGrainIDs(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		ids, err := tx.GrainIDs()
+		assert.NoError(t, err)
+		assert.Equal(t, []types.GrainID{"grain123"}, ids)
+	})
+}
+
+func TestGrains(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		grains, err := tx.Grains()
+		require.NoError(t, err)
+		require.Len(t, grains, 1)
+		info, err := tx.GrainInfo("grain123")
+		require.NoError(t, err)
+		assert.Equal(t, info, grains[0])
+	})
+}
+
+func TestAccountGrainIDs(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		ids, err := tx.AccountGrainIDs("id_alice")
+		assert.NoError(t, err)
+		assert.Equal(t, []types.GrainID{"grain123"}, ids)
+		ids, err = tx.AccountGrainIDs("id_bob")
+		assert.NoError(t, err)
+		assert.Empty(t, ids)
+	})
+}
+
+func TestGrainBackupInfo(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		info, err := tx.GrainBackupInfo("grain123")
+		require.NoError(t, err)
+		assert.Equal(t, GrainBackupInfo{
+			GrainInfo: GrainInfo{
+				ID:    "grain123",
+				Title: "Example Grain",
+				Owner: "id_alice",
+			},
+			PackageID: "abcdef",
+		}, info)
+
+		_, err = tx.GrainBackupInfo("nonexistent")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+func TestInstalledPackage(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		pkg, err := tx.InstalledPackage("abcdef")
+		require.NoError(t, err)
+		assert.Equal(t, types.ID[Package]("abcdef"), pkg.ID)
+
+		require.NoError(t, tx.AddPackage(Package{ID: "notready"}))
+		_, err = tx.InstalledPackage("notready")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+func TestDevPackages(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		const dir = "/home/alice/notes/.sandstorm-root"
+		pkg := Package{ID: "dev1", Manifest: testManifest(t, "Notes", 1, "1.0")}
+		require.NoError(t, tx.AddDevPackage(pkg, dir))
+		_, err := tx.InstalledPackage("dev1")
+		require.NoError(t, err)
+		served, err := tx.ServedDevPackages()
+		require.NoError(t, err)
+		assert.Equal(t, map[types.ID[Package]]string{"dev1": dir}, served)
+
+		require.NoError(t, tx.AddGrain(NewGrain{
+			GrainID: "devgrain",
+			PkgID:   "dev1",
+			OwnerID: "id_alice",
+			Title:   "Dev Grain",
+		}))
+		grainIDs, err := tx.PackageGrainIDs("dev1")
+		require.NoError(t, err)
+		assert.Equal(t, []types.GrainID{"devgrain"}, grainIDs)
+
+		// Unserved dev packages aren't listed, but keep their grains.
+		require.NoError(t, tx.RemoveDevPackage("dev1"))
+		_, err = tx.InstalledPackage("dev1")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		pkgs, err := tx.CredentialPackages(types.Credential{Type: "dev", ScopedID: "Alice Dev Admin"})
+		require.NoError(t, err)
+		for _, p := range pkgs {
+			assert.NotEqual(t, types.ID[Package]("dev1"), p.ID)
+		}
+		served, err = tx.ServedDevPackages()
+		require.NoError(t, err)
+		assert.Empty(t, served)
+
+		// Serving it again updates its manifest.
+		pkg.Manifest = testManifest(t, "Notes", 2, "2.0")
+		require.NoError(t, tx.AddDevPackage(pkg, dir))
+		got, err := tx.InstalledPackage("dev1")
+		require.NoError(t, err)
+		assert.Equal(t, uint32(2), got.Manifest.AppVersion())
+
+		// Neither installed packages nor other directories' dev
+		// packages can be replaced.
+		assert.Error(t, tx.AddDevPackage(Package{ID: "abcdef", Manifest: pkg.Manifest}, dir))
+		assert.Error(t, tx.AddDevPackage(pkg, "/home/bob/notes"))
+		require.NoError(t, tx.RemoveDevPackage("abcdef"))
+		_, err = tx.InstalledPackage("abcdef")
+		assert.NoError(t, err)
+	})
+}
+
+func TestPackageAppID(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		pkg, err := tx.InstalledPackage("abcdef")
+		require.NoError(t, err)
+		assert.Equal(t, "", pkg.AppID)
+
+		const appID = "vjnkphndcs0e8ye8jy4v1x6zcwf3x2k16pyqzy1dkzdhyr9zxtq0"
+		require.NoError(t, tx.AddPackage(Package{ID: "signed", AppID: appID}))
+		require.NoError(t, tx.ReadyPackage("signed"))
+		pkg, err = tx.InstalledPackage("signed")
+		require.NoError(t, err)
+		assert.Equal(t, appID, pkg.AppID)
+
+		require.NoError(t, tx.AddGrain(NewGrain{
+			GrainID: "grain456",
+			PkgID:   "signed",
+			OwnerID: "id_alice",
+			Title:   "Signed Grain",
+		}))
+		info, err := tx.GrainBackupInfo("grain456")
+		require.NoError(t, err)
+		assert.Equal(t, appID, info.AppID)
+
+		got, err := tx.PackageAppID("signed")
+		require.NoError(t, err)
+		assert.Equal(t, appID, got)
+		got, err = tx.PackageAppID("abcdef")
+		require.NoError(t, err)
+		assert.Equal(t, "", got)
+	})
+}
+
+func TestPowerboxRequest(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		now := time.Unix(time.Now().Unix(), 0)
+		req := PowerboxRequest{
+			ID:        "request1",
+			GrainID:   "grain123",
+			AccountID: "id_bob",
+			Query:     []string{"EAZQAQEAABEBF1EEAQH_5-Jn6pjXtNsAAAA"},
+			SaveLabel: "Linked grain",
+			Expires:   now.Add(time.Hour),
+		}
+		require.NoError(t, tx.AddPowerboxRequest(req))
+		require.NoError(t, tx.AddPowerboxRequest(PowerboxRequest{
+			ID:        "expired",
+			GrainID:   "grain123",
+			AccountID: "id_bob",
+			Expires:   now.Add(-time.Hour),
+		}))
+
+		got, err := tx.PowerboxRequest("request1")
+		require.NoError(t, err)
+		assert.Equal(t, req, got)
+
+		// Deleting one request cleans up expired ones too:
+		require.NoError(t, tx.DeletePowerboxRequest("request1", now))
+		_, err = tx.PowerboxRequest("request1")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		_, err = tx.PowerboxRequest("expired")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+// Contacts go by their profile's display name, or failing that their login
+// credential; deactivated accounts aren't listed.
+func TestContacts(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		_, seg := capnp.NewSingleSegmentMessage(nil)
+		profile, err := identity.NewRootProfile(seg)
+		require.NoError(t, err)
+		displayName, err := profile.NewDisplayName()
+		require.NoError(t, err)
+		require.NoError(t, displayName.SetDefaultText("Carol"))
+		require.NoError(t, profile.SetPreferredHandle("carol"))
+		require.NoError(t, tx.AddAccount(NewAccount{ID: "id_carol", Role: types.RoleUser, Profile: profile}))
+		require.NoError(t, tx.AddAccount(NewAccount{ID: "id_dave", Role: types.RoleUser}))
+		require.NoError(t, tx.AddCredential(NewCredential{
+			AccountID:  "id_dave",
+			Login:      true,
+			Credential: types.Credential{Type: types.EmailCredential, ScopedID: "dave@example.com"},
+		}))
+		require.NoError(t, tx.SetAccountDeactivated("id_bob", true, time.Now()))
+
+		contacts, err := tx.Contacts()
+		require.NoError(t, err)
+		assert.Equal(t, []Contact{
+			{AccountID: "id_alice", DisplayName: "Alice Dev Admin"},
+			{AccountID: "id_carol", DisplayName: "Carol", PreferredHandle: "carol"},
+			{AccountID: "id_dave", DisplayName: "dave"},
+		}, contacts)
+
+		bob, err := tx.AccountContact("id_bob")
+		require.NoError(t, err)
+		assert.Equal(t, Contact{AccountID: "id_bob", DisplayName: "Bob Dev User"}, bob)
+		_, err = tx.AccountContact("id_nobody")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
 // addTestData populates the database with some initial data.
 func addTestData(t *testing.T, tx Tx) {
 	accounts := []NewAccount{
@@ -180,3 +408,1360 @@ func openTestDB(t *testing.T) DB {
 	assert.NoError(t, err)
 	return db
 }
+
+func TestPackageStartStats(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+
+		now := time.Now()
+		for _, appInit := range []time.Duration{time.Second, 3 * time.Second} {
+			assert.NoError(t, tx.AddGrainStart(GrainStart{
+				GrainID:   "grain123",
+				PackageID: "abcdef",
+				StartedAt: now,
+				Lookup:    time.Millisecond,
+				Sandbox:   10 * time.Millisecond,
+				AppInit:   appInit,
+			}))
+		}
+
+		stats, err := tx.PackageStartStats(now.Add(-time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, 1, len(stats))
+		assert.Equal(t, "abcdef", stats[0].PackageID)
+		assert.Equal(t, 2, stats[0].Starts)
+		assert.Equal(t, 2*time.Second, stats[0].AppInit)
+		assert.Equal(t, 3011*time.Millisecond, stats[0].Max)
+
+		stats, err = tx.PackageStartStats(now.Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 0, len(stats))
+
+		for i := 0; i < MaxGrainStarts; i++ {
+			require.NoError(t, tx.AddGrainStart(GrainStart{
+				GrainID:   "grain123",
+				PackageID: "abcdef",
+				StartedAt: now.Add(time.Hour),
+			}))
+		}
+		stats, err = tx.PackageStartStats(now.Add(-time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, 1, len(stats))
+		assert.Equal(t, MaxGrainStarts, stats[0].Starts, "old starts are removed")
+		assert.Equal(t, time.Duration(0), stats[0].Max)
+	})
+}
+
+func TestDeprecatedAPIUsage(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, tx.RecordDeprecatedAPIUse("grain123", "SandstormApi", "deprecatedPublish"))
+		}
+		usage, err := tx.DeprecatedAPIUsage()
+		require.NoError(t, err)
+		require.Equal(t, 1, len(usage))
+		assert.Equal(t, "abcdef", usage[0].PackageID)
+		assert.Equal(t, "deprecatedPublish", usage[0].Method)
+		assert.Equal(t, int64(3), usage[0].Calls)
+	})
+}
+
+func TestTURNUsage(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+
+		for i := 0; i < 2; i++ {
+			require.NoError(t, tx.RecordTURNCredential("grain123"))
+		}
+		usage, err := tx.TURNUsage()
+		require.NoError(t, err)
+		require.Equal(t, 1, len(usage))
+		assert.Equal(t, "abcdef", usage[0].PackageID)
+		assert.Equal(t, int64(1), usage[0].Grains)
+		assert.Equal(t, int64(2), usage[0].Issued)
+	})
+}
+
+func TestRevokeSession(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		revoked, err := tx.IsSessionRevoked([]byte("session1"))
+		require.NoError(t, err)
+		assert.False(t, revoked)
+
+		// Revoking twice is fine:
+		for i := 0; i < 2; i++ {
+			require.NoError(t, tx.RevokeSession([]byte("session1")))
+		}
+		revoked, err = tx.IsSessionRevoked([]byte("session1"))
+		require.NoError(t, err)
+		assert.True(t, revoked)
+
+		revoked, err = tx.IsSessionRevoked([]byte("session2"))
+		require.NoError(t, err)
+		assert.False(t, revoked)
+	})
+}
+
+func TestUserSessions(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		alice := types.Credential{Type: "dev", ScopedID: "Alice Dev Admin"}
+		bob := types.Credential{Type: "dev", ScopedID: "Bob Dev User"}
+		now := time.Unix(1000, 0)
+		add := func(id string, cred types.Credential, started time.Time) {
+			require.NoError(t, tx.AddUserSession(UserSessionInfo{
+				SessionID:  []byte(id),
+				Credential: cred,
+				UserAgent:  "Browser/" + id,
+				RemoteAddr: "192.0.2.1",
+				Started:    started,
+			}))
+		}
+		add("alice1", alice, now)
+		add("bob1", bob, now)
+		add("alice2", alice, now.Add(time.Minute))
+
+		ids, err := tx.AccountUserSessions("id_alice")
+		require.NoError(t, err)
+		assert.Equal(t, [][]byte{[]byte("alice1"), []byte("alice2")}, ids)
+
+		infos, err := tx.AccountUserSessionInfos("id_alice")
+		require.NoError(t, err)
+		require.Equal(t, 2, len(infos))
+		assert.Equal(t, []byte("alice2"), infos[0].SessionID, "most recently used first")
+		assert.Equal(t, "Browser/alice2", infos[0].UserAgent)
+		assert.Equal(t, now.Add(time.Minute), infos[0].LastSeen)
+
+		require.NoError(t, tx.TouchUserSession([]byte("alice1"), "198.51.100.7", now.Add(time.Hour)))
+		infos, err = tx.AccountUserSessionInfos("id_alice")
+		require.NoError(t, err)
+		require.Equal(t, 2, len(infos))
+		assert.Equal(t, []byte("alice1"), infos[0].SessionID)
+		assert.Equal(t, "198.51.100.7", infos[0].RemoteAddr)
+		assert.Equal(t, now, infos[0].Started)
+		assert.Equal(t, now.Add(time.Hour), infos[0].LastSeen)
+
+		require.NoError(t, tx.RemoveUserSession([]byte("alice1")))
+		ids, err = tx.AccountUserSessions("id_alice")
+		require.NoError(t, err)
+		assert.Equal(t, [][]byte{[]byte("alice2")}, ids)
+		ids, err = tx.AccountUserSessions("id_bob")
+		require.NoError(t, err)
+		assert.Equal(t, [][]byte{[]byte("bob1")}, ids)
+	})
+}
+
+func TestGrainEvents(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+
+		start := time.Unix(1700000000, 0)
+		kinds := []types.GrainEventKind{
+			types.GrainCreated,
+			types.GrainStarted,
+			types.GrainCrashed,
+		}
+		for i, kind := range kinds {
+			require.NoError(t, tx.AddGrainEvent(types.GrainEvent{
+				GrainID: "grain123",
+				Kind:    kind,
+				Time:    start.Add(time.Duration(i) * time.Minute),
+			}))
+		}
+		events, err := tx.GrainEvents("grain123")
+		require.NoError(t, err)
+		require.Equal(t, 3, len(events))
+		assert.Equal(t, types.GrainCrashed, events[0].Kind, "most recent first")
+		assert.Equal(t, types.GrainCreated, events[2].Kind)
+		assert.Equal(t, start, events[2].Time)
+
+		for i := 0; i < MaxGrainEvents; i++ {
+			require.NoError(t, tx.AddGrainEvent(types.GrainEvent{
+				GrainID: "grain123",
+				Kind:    types.GrainStarted,
+				Time:    start.Add(time.Hour),
+			}))
+		}
+		events, err = tx.GrainEvents("grain123")
+		require.NoError(t, err)
+		assert.Equal(t, MaxGrainEvents, len(events), "old events are removed")
+		assert.Equal(t, types.GrainStarted, events[len(events)-1].Kind)
+	})
+}
+
+func TestAdminScopes(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		alice := types.Credential{Type: "dev", ScopedID: "Alice Dev Admin"}
+		bob := types.Credential{Type: "dev", ScopedID: "Bob Dev User"}
+
+		scopes, err := tx.CredentialAdminScopes(alice)
+		require.NoError(t, err)
+		assert.Equal(t, types.AdminScopes(types.AllAdminScopes), scopes,
+			"Admins should have every scope")
+
+		scopes, err = tx.CredentialAdminScopes(bob)
+		require.NoError(t, err)
+		assert.Empty(t, scopes)
+
+		accounts, err := tx.AdminAccounts()
+		require.NoError(t, err)
+		require.Equal(t, 1, len(accounts))
+		assert.Equal(t, types.AccountID("id_alice"), accounts[0].ID)
+
+		require.NoError(t, tx.SetAdminScopes("id_bob", types.AdminScopes{
+			types.AdminScopeAudit,
+			types.AdminScopeUsers,
+		}))
+		scopes, err = tx.CredentialAdminScopes(bob)
+		require.NoError(t, err)
+		assert.Equal(t, types.AdminScopes{types.AdminScopeUsers, types.AdminScopeAudit}, scopes)
+
+		accounts, err = tx.AdminAccounts()
+		require.NoError(t, err)
+		require.Equal(t, 2, len(accounts))
+		assert.Equal(t, types.AccountID("id_bob"), accounts[1].ID)
+		assert.Equal(t, scopes, accounts[1].Scopes)
+
+		require.NoError(t, tx.SetAdminScopes("id_bob", nil))
+		scopes, err = tx.CredentialAdminScopes(bob)
+		require.NoError(t, err)
+		assert.Empty(t, scopes)
+
+		scopes, err = tx.CredentialAdminScopes(types.Credential{Type: "dev", ScopedID: "Nobody"})
+		require.NoError(t, err)
+		assert.Empty(t, scopes)
+	})
+}
+
+func TestManageAccounts(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		bob := types.Credential{Type: "dev", ScopedID: "Bob Dev User"}
+
+		accounts, err := tx.Accounts()
+		require.NoError(t, err)
+		require.Equal(t, 2, len(accounts))
+		assert.Equal(t, Account{
+			ID:   "id_alice",
+			Role: types.RoleAdmin,
+			Credentials: []types.Credential{
+				{Type: "dev", ScopedID: "Alice Dev Admin"},
+			},
+			Grains: []types.GrainID{"grain123"},
+		}, accounts[0])
+		assert.Equal(t, types.AccountID("id_bob"), accounts[1].ID)
+		assert.Empty(t, accounts[1].Grains)
+
+		require.NoError(t, tx.SetAdminScopes("id_bob", types.AdminScopes{types.AdminScopeUsers}))
+		now := time.Unix(1700000000, 0)
+		require.NoError(t, tx.SetAccountDeactivated("id_bob", true, now))
+		require.NoError(t, tx.SetAccountDeactivated("id_bob", true, now.Add(time.Hour)))
+		deactivated, err := tx.CredentialDeactivated(bob)
+		require.NoError(t, err)
+		assert.True(t, deactivated)
+		role, err := tx.CredentialRole(bob)
+		require.NoError(t, err)
+		assert.Equal(t, types.RoleVisitor, role, "Deactivated accounts should have no role")
+		scopes, err := tx.CredentialAdminScopes(bob)
+		require.NoError(t, err)
+		assert.Empty(t, scopes, "Deactivated accounts should have no admin scopes")
+		accounts, err = tx.Accounts()
+		require.NoError(t, err)
+		assert.Equal(t, now, accounts[1].Deactivated, "Deactivating twice should keep the first time")
+
+		require.NoError(t, tx.SetAccountDeactivated("id_bob", false, now))
+		require.NoError(t, tx.SetAccountRole("id_bob", types.RoleAdmin))
+		role, err = tx.CredentialRole(bob)
+		require.NoError(t, err)
+		assert.Equal(t, types.RoleAdmin, role)
+
+		assert.ErrorIs(t, tx.SetAccountRole("id_nobody", types.RoleUser), sql.ErrNoRows)
+		assert.ErrorIs(t, tx.SetAccountDeactivated("id_nobody", true, now), sql.ErrNoRows)
+		assert.Error(t, tx.SetAccountRole("id_bob", "superuser"))
+	})
+}
+
+func TestInvites(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		now := time.Unix(1700000000, 0)
+		require.NoError(t, tx.AddAccount(NewAccount{ID: "id_carol", Role: types.RoleVisitor}))
+		require.NoError(t, tx.AddAccount(NewAccount{ID: "id_dave", Role: types.RoleVisitor}))
+		require.NoError(t, tx.AddInvite(Invite{
+			ID:        "invite1",
+			Role:      types.RoleUser,
+			MaxUses:   1,
+			Created:   now,
+			Expires:   now.Add(time.Hour),
+			CreatedBy: "id_alice",
+			Note:      "for Carol",
+		}, []byte("token1")))
+
+		_, err := tx.RedeemInvite([]byte("wrong"), "id_carol", now)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		_, err = tx.RedeemInvite([]byte("token1"), "id_carol", now.Add(2*time.Hour))
+		assert.ErrorIs(t, err, sql.ErrNoRows, "Expired invites should not be redeemable")
+
+		inv, err := tx.RedeemInvite([]byte("token1"), "id_carol", now)
+		require.NoError(t, err)
+		assert.Equal(t, "invite1", inv.ID)
+		role, err := tx.AccountRole("id_carol")
+		require.NoError(t, err)
+		assert.Equal(t, types.RoleUser, role)
+		_, err = tx.RedeemInvite([]byte("token1"), "id_carol", now)
+		assert.NoError(t, err, "Redeeming again should be a no-op")
+		_, err = tx.RedeemInvite([]byte("token1"), "id_dave", now)
+		assert.ErrorIs(t, err, sql.ErrNoRows, "Used up invites should not be redeemable")
+
+		// Redeeming an invite shouldn't lower anyone's role:
+		require.NoError(t, tx.AddInvite(Invite{
+			ID:        "invite2",
+			Role:      types.RoleVisitor,
+			Created:   now.Add(time.Minute),
+			CreatedBy: "id_alice",
+		}, []byte("token2")))
+		_, err = tx.RedeemInvite([]byte("token2"), "id_alice", now)
+		require.NoError(t, err)
+		role, err = tx.AccountRole("id_alice")
+		require.NoError(t, err)
+		assert.Equal(t, types.RoleAdmin, role)
+
+		invites, err := tx.Invites()
+		require.NoError(t, err)
+		require.Equal(t, 2, len(invites))
+		assert.Equal(t, "invite2", invites[0].ID)
+		assert.True(t, invites[0].Usable(now.Add(24*time.Hour)), "Invites without limits stay usable")
+		assert.Equal(t, Invite{
+			ID:        "invite1",
+			Role:      types.RoleUser,
+			MaxUses:   1,
+			Created:   now,
+			Expires:   now.Add(time.Hour),
+			CreatedBy: "id_alice",
+			Note:      "for Carol",
+			Redemptions: []InviteRedemption{
+				{AccountID: "id_carol", Time: now},
+			},
+		}, invites[1])
+		assert.False(t, invites[1].Usable(now))
+
+		require.NoError(t, tx.DeleteInvite("invite2"))
+		assert.ErrorIs(t, tx.DeleteInvite("invite2"), sql.ErrNoRows)
+		_, err = tx.InviteByToken([]byte("token2"))
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+func TestGrainEmbeds(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+
+		now := time.Unix(1700000000, 0)
+		embed := types.GrainEmbed{
+			ID:             "embed1",
+			GrainID:        "grain123",
+			Permissions:    []bool{true, false},
+			FrameAncestors: []string{"https://example.com", "https://example.org"},
+			Note:           "blog",
+			Created:        now,
+			Expires:        now.Add(time.Hour),
+		}
+		require.NoError(t, tx.AddGrainEmbed(embed))
+		require.NoError(t, tx.AddGrainEmbed(types.GrainEmbed{
+			ID:          "expired",
+			GrainID:     "grain123",
+			Permissions: []bool{true},
+			Created:     now.Add(-2 * time.Hour),
+			Expires:     now.Add(-time.Hour),
+		}))
+
+		got, err := tx.GrainEmbed("embed1")
+		require.NoError(t, err)
+		assert.Equal(t, embed, got)
+
+		embeds, err := tx.GrainEmbeds("grain123", now)
+		require.NoError(t, err)
+		assert.Equal(t, []types.GrainEmbed{embed}, embeds, "expired embeds are left out")
+
+		_, err = tx.GrainEmbed("nonexistent")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		err = tx.DeleteGrainEmbed("other-grain", "embed1", now)
+		assert.ErrorIs(t, err, sql.ErrNoRows, "embeds are only deleted from their own grain")
+		require.NoError(t, tx.DeleteGrainEmbed("grain123", "embed1", now))
+		_, err = tx.GrainEmbed("embed1")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		_, err = tx.GrainEmbed("expired")
+		assert.ErrorIs(t, err, sql.ErrNoRows, "expired embeds are cleaned up")
+	})
+}
+
+func TestAPITokens(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+
+		tokens, err := tx.GrainAPITokens("grain123")
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+
+		apiToken := types.APIToken{
+			ID:          "token1",
+			GrainID:     "grain123",
+			AccountID:   "id_bob",
+			Petname:     "git",
+			Permissions: []bool{true, false},
+			Created:     time.Unix(1700000000, 0),
+		}
+		token, err := tx.NewAPIToken(apiToken)
+		require.NoError(t, err)
+		st, err := tx.RestoreSharingToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, SharingToken{
+			GrainID:     "grain123",
+			Permissions: []bool{true, false},
+			Note:        "git",
+		}, st, "API tokens are sharing tokens")
+
+		tokens, err = tx.GrainAPITokens("grain123")
+		require.NoError(t, err)
+		assert.Equal(t, []types.APIToken{apiToken}, tokens)
+		tokens, err = tx.GrainAPITokens("other-grain")
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+
+		_, err = tx.DeleteAPIToken("other-grain", "token1")
+		assert.ErrorIs(t, err, sql.ErrNoRows, "tokens are only revoked from their own grain")
+		deleted, err := tx.DeleteAPIToken("grain123", "token1")
+		require.NoError(t, err)
+		apiToken.Permissions = nil
+		assert.Equal(t, apiToken, deleted)
+		_, err = tx.RestoreSharingToken(token)
+		assert.ErrorIs(t, err, sql.ErrNoRows, "revoked tokens can't be used")
+		tokens, err = tx.GrainAPITokens("grain123")
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+
+		roles, err := tx.GrainRolePermissions("grain123")
+		require.NoError(t, err)
+		assert.Nil(t, roles, "no view info yet")
+	})
+}
+
+func TestAPIKeys(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+
+		keys, err := tx.AccountAPIKeys("id_alice")
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+
+		apiKey := types.APIKey{
+			ID:          "key1",
+			AccountID:   "id_alice",
+			Name:        "CI",
+			Grains:      []types.GrainID{"grain123"},
+			AccountRead: true,
+			Created:     time.Unix(1700000000, 0),
+		}
+		token, err := tx.NewAPIKey(apiKey)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(token, APIKeyPrefix))
+		keys, err = tx.AccountAPIKeys("id_alice")
+		require.NoError(t, err)
+		assert.Equal(t, []types.APIKey{apiKey}, keys)
+		keys, err = tx.AccountAPIKeys("id_bob")
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+
+		_, err = tx.RestoreAPIKey(APIKeyPrefix+"bogus", time.Unix(1700000100, 0))
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		used, err := tx.RestoreAPIKey(token, time.Unix(1700000100, 0))
+		require.NoError(t, err)
+		apiKey.LastUsed = time.Unix(1700000100, 0)
+		assert.Equal(t, apiKey, used)
+		used, err = tx.RestoreAPIKey(token, time.Unix(1700000130, 0))
+		require.NoError(t, err)
+		assert.Equal(t, apiKey.LastUsed, used.LastUsed, "use is only recorded once a minute")
+		keys, err = tx.AccountAPIKeys("id_alice")
+		require.NoError(t, err)
+		assert.Equal(t, []types.APIKey{apiKey}, keys)
+
+		require.NoError(t, tx.SetAccountDeactivated("id_alice", true, time.Unix(1700000200, 0)))
+		_, err = tx.RestoreAPIKey(token, time.Unix(1700000300, 0))
+		assert.ErrorIs(t, err, sql.ErrNoRows, "keys of deactivated accounts can't be used")
+		require.NoError(t, tx.SetAccountDeactivated("id_alice", false, time.Unix(1700000400, 0)))
+
+		assert.ErrorIs(t, tx.DeleteAPIKey("id_bob", "key1"), sql.ErrNoRows,
+			"keys are only revoked by their own account")
+		require.NoError(t, tx.DeleteAPIKey("id_alice", "key1"))
+		_, err = tx.RestoreAPIKey(token, time.Unix(1700000500, 0))
+		assert.ErrorIs(t, err, sql.ErrNoRows, "revoked keys can't be used")
+		keys, err = tx.AccountAPIKeys("id_alice")
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+	})
+}
+
+func TestHasAdmin(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		ok, err := tx.HasAdmin()
+		require.NoError(t, err)
+		assert.False(t, ok, "a new database has no admin")
+
+		addTestData(t, tx)
+		ok, err = tx.HasAdmin()
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		require.NoError(t, tx.SetAccountDeactivated("id_alice", true, time.Unix(1700000000, 0)))
+		ok, err = tx.HasAdmin()
+		require.NoError(t, err)
+		assert.False(t, ok, "deactivated admins don't count")
+	})
+}
+
+func TestDemoAccounts(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+
+		cred := types.Credential{Type: types.DemoCredential, ScopedID: "demo1"}
+		accountID, err := tx.NewDemoAccount(cred, time.Unix(1700003600, 0))
+		require.NoError(t, err)
+		got, err := tx.CredentialAccount(cred)
+		require.NoError(t, err)
+		assert.Equal(t, accountID, got)
+		role, err := tx.AccountRole(accountID)
+		require.NoError(t, err)
+		assert.Equal(t, types.RoleUser, role)
+		expires, err := tx.DemoAccountExpires(accountID)
+		require.NoError(t, err)
+		assert.Equal(t, time.Unix(1700003600, 0), expires)
+		expires, err = tx.DemoAccountExpires("id_alice")
+		require.NoError(t, err)
+		assert.True(t, expires.IsZero(), "other accounts don't expire")
+
+		expired, err := tx.ExpiredDemoAccounts(time.Unix(1700003599, 0))
+		require.NoError(t, err)
+		assert.Empty(t, expired)
+		expired, err = tx.ExpiredDemoAccounts(time.Unix(1700003600, 0))
+		require.NoError(t, err)
+		assert.Equal(t, []types.AccountID{accountID}, expired)
+
+		_, err = tx.NewAPIKey(types.APIKey{
+			ID:          "key1",
+			AccountID:   accountID,
+			AccountRead: true,
+			Created:     time.Unix(1700000000, 0),
+		})
+		require.NoError(t, err)
+		assert.Error(t, tx.DeleteAccount("id_alice"), "accounts which own grains can't be deleted")
+		require.NoError(t, tx.DeleteAccount(accountID))
+		assert.ErrorIs(t, tx.DeleteAccount(accountID), sql.ErrNoRows)
+		_, err = tx.AccountRole(accountID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		keys, err := tx.AccountAPIKeys(accountID)
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+		expired, err = tx.ExpiredDemoAccounts(time.Unix(1700003600, 0))
+		require.NoError(t, err)
+		assert.Empty(t, expired)
+	})
+}
+
+func TestServerSettings(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		stored, err := tx.ServerSettings()
+		require.NoError(t, err)
+		assert.Empty(t, stored)
+
+		require.NoError(t, tx.SetServerSettings(map[string]string{
+			"BASE_URL":  "https://tempest.example",
+			"SMTP_HOST": "smtp.example",
+		}))
+		require.NoError(t, tx.SetServerSettings(map[string]string{
+			"BASE_URL":          "https://example.net",
+			"SMTP_HOST":         "",
+			"ACME_DNS_PROVIDER": "",
+		}))
+		stored, err = tx.ServerSettings()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"BASE_URL": "https://example.net"}, stored)
+	})
+}
+
+func TestShareLinks(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		require.NoError(t, tx.AddAccount(NewAccount{ID: "id_carol", Role: types.RoleUser}))
+
+		now := time.Unix(1700000000, 0)
+		editor := types.ShareLink{
+			ID:          "link1",
+			GrainID:     "grain123",
+			AccountID:   "id_alice",
+			RoleID:      1,
+			Permissions: []bool{true, true},
+			Note:        "for Bob",
+			Created:     now,
+		}
+		editorToken, err := tx.NewShareLink(editor)
+		require.NoError(t, err)
+		viewer := types.ShareLink{
+			ID:          "link2",
+			GrainID:     "grain123",
+			AccountID:   "id_alice",
+			RoleID:      0,
+			Permissions: []bool{true, false},
+			Created:     now.Add(time.Second),
+			Expires:     now.Add(time.Hour),
+			SingleUse:   true,
+		}
+		viewerToken, err := tx.NewShareLink(viewer)
+		require.NoError(t, err)
+
+		links, err := tx.GrainShareLinks("grain123", now)
+		require.NoError(t, err)
+		assert.Equal(t, []types.ShareLink{editor, viewer}, links)
+		links, err = tx.GrainShareLinks("grain123", now.Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, []types.ShareLink{editor}, links, "expired links are left out")
+		_, _, err = tx.RedeemSharingToken(viewerToken, "id_bob", now.Add(time.Hour))
+		assert.ErrorIs(t, err, sql.ErrNoRows, "expired links can't be used")
+
+		grainID, member, err := tx.RedeemSharingToken(viewerToken, "id_bob", now)
+		require.NoError(t, err)
+		assert.Equal(t, types.GrainID("grain123"), grainID)
+		roleID := 0
+		assert.Equal(t, types.GrainMember{
+			AccountID:   "id_bob",
+			Permissions: []bool{true, false},
+			RoleID:      &roleID,
+			ShareLinkID: "link2",
+			SharedBy:    "id_alice",
+			Joined:      now,
+		}, member)
+		_, _, err = tx.RedeemSharingToken(viewerToken, "id_carol", now)
+		assert.ErrorIs(t, err, sql.ErrNoRows, "single-use links are revoked when used")
+
+		// Joining again adds the new link's permissions:
+		later := now.Add(time.Minute)
+		_, member, err = tx.RedeemSharingToken(editorToken, "id_bob", later)
+		require.NoError(t, err)
+		assert.Equal(t, []bool{true, true}, member.Permissions)
+		_, _, err = tx.RedeemSharingToken(editorToken, "id_carol", later)
+		require.NoError(t, err)
+		perms, err := tx.AccountGrainPermissions("id_carol", "grain123")
+		require.NoError(t, err)
+		assert.Equal(t, []bool{true, true}, perms)
+
+		links, err = tx.GrainShareLinks("grain123", later)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(links))
+		assert.Equal(t, 2, links[0].Uses)
+
+		roleID = 1
+		members, err := tx.GrainMembers("grain123")
+		require.NoError(t, err)
+		assert.Equal(t, []types.GrainMember{
+			{
+				AccountID: "id_bob",
+				Credentials: []types.Credential{
+					{Type: "dev", ScopedID: "Bob Dev User"},
+				},
+				Permissions: []bool{true, true},
+				RoleID:      &roleID,
+				ShareLinkID: "link1",
+				SharedBy:    "id_alice",
+				Joined:      later,
+			},
+			{
+				AccountID:   "id_carol",
+				Permissions: []bool{true, true},
+				RoleID:      &roleID,
+				ShareLinkID: "link1",
+				SharedBy:    "id_alice",
+				Joined:      later,
+			},
+		}, members, "the owner isn't a member")
+
+		_, err = tx.DeleteShareLink("other-grain", "link1")
+		assert.ErrorIs(t, err, sql.ErrNoRows, "links are only revoked from their own grain")
+		deleted, err := tx.DeleteShareLink("grain123", "link1")
+		require.NoError(t, err)
+		assert.Equal(t, "for Bob", deleted.Note)
+		_, _, err = tx.RedeemSharingToken(editorToken, "id_carol", later)
+		assert.ErrorIs(t, err, sql.ErrNoRows, "revoked links can't be used")
+		members, err = tx.GrainMembers("grain123")
+		require.NoError(t, err)
+		assert.Equal(t, 2, len(members), "members keep access when the link is revoked")
+
+		revoked, err := tx.RemoveGrainMember("grain123", "id_bob")
+		require.NoError(t, err)
+		assert.Equal(t, []types.AccountID{"id_bob"}, revoked)
+		_, err = tx.RemoveGrainMember("grain123", "id_bob")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		_, err = tx.AccountGrainPermissions("id_bob", "grain123")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		members, err = tx.GrainMembers("grain123")
+		require.NoError(t, err)
+		require.Equal(t, 1, len(members))
+		assert.Equal(t, types.AccountID("id_carol"), members[0].AccountID)
+
+		// Plain sharing tokens grant access without a recorded membership:
+		token, err := tx.NewSharingToken("grain123", []bool{false, true}, "")
+		require.NoError(t, err)
+		_, member, err = tx.RedeemSharingToken(token, "id_bob", later)
+		require.NoError(t, err)
+		assert.Equal(t, types.GrainMember{
+			AccountID:   "id_bob",
+			Permissions: []bool{false, true},
+		}, member)
+		members, err = tx.GrainMembers("grain123")
+		require.NoError(t, err)
+		assert.Equal(t, 2, len(members))
+
+		titles, err := tx.GrainRoleTitles("grain123")
+		require.NoError(t, err)
+		assert.Nil(t, titles, "no view info yet")
+	})
+}
+
+func TestShareGraph(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		for _, id := range []types.AccountID{"id_carol", "id_dave"} {
+			require.NoError(t, tx.AddAccount(NewAccount{ID: id, Role: types.RoleUser}))
+		}
+		now := time.Unix(1700000000, 0)
+		share := func(from, to types.AccountID, perms []bool) {
+			token, err := tx.NewShareLink(types.ShareLink{
+				ID:          string(from) + "-" + string(to),
+				GrainID:     "grain123",
+				AccountID:   from,
+				Permissions: perms,
+				Created:     now,
+			})
+			require.NoError(t, err)
+			_, _, err = tx.RedeemSharingToken(token, to, now)
+			require.NoError(t, err)
+		}
+		// alice owns the grain, and shares it with bob, who shares it
+		// with carol and dave; alice shares it with dave too.
+		share("id_alice", "id_bob", []bool{true, true})
+		share("id_bob", "id_carol", []bool{true, false})
+		share("id_bob", "id_dave", []bool{false, true})
+		share("id_alice", "id_dave", []bool{true, false})
+		token, err := tx.NewAPIToken(types.APIToken{
+			ID:          "token1",
+			GrainID:     "grain123",
+			AccountID:   "id_carol",
+			Permissions: []bool{true, false},
+			Created:     now,
+		})
+		require.NoError(t, err)
+		_, _, err = tx.RedeemSharingToken(token, "id_dave", now)
+		require.NoError(t, err)
+
+		g, err := tx.GrainShareGraph("grain123")
+		require.NoError(t, err)
+		assert.Equal(t, types.AccountID("id_alice"), g.Owner)
+		assert.Equal(t, []types.AccountID{"id_bob", "id_carol", "id_dave"}, g.Members)
+		var edges []string
+		for _, s := range g.Shares {
+			edges = append(edges, string(s.SharedBy)+"->"+string(s.AccountID)+":"+s.ShareLinkID)
+		}
+		assert.ElementsMatch(t, []string{
+			"id_alice->id_bob:id_alice-id_bob",
+			"id_bob->id_carol:id_bob-id_carol",
+			"id_bob->id_dave:id_bob-id_dave",
+			"id_alice->id_dave:id_alice-id_dave",
+			"id_carol->id_dave:", // Through the API token
+		}, edges)
+
+		// Revoking bob's access revokes carol's, which came only
+		// through bob, and carol's API token; dave keeps the access
+		// from alice, but loses what bob gave.
+		revoked, err := tx.RemoveGrainMember("grain123", "id_bob")
+		require.NoError(t, err)
+		assert.Equal(t, []types.AccountID{"id_bob", "id_carol"}, revoked)
+		_, err = tx.AccountGrainPermissions("id_carol", "grain123")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		perms, err := tx.AccountGrainPermissions("id_dave", "grain123")
+		require.NoError(t, err)
+		assert.Equal(t, []bool{true, false}, perms)
+		tokens, err := tx.GrainAPITokens("grain123")
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+		links, err := tx.GrainShareLinks("grain123", now)
+		require.NoError(t, err)
+		for _, l := range links {
+			assert.Equal(t, types.AccountID("id_alice"), l.AccountID,
+				"the revoked users' links are revoked")
+		}
+
+		g, err = tx.GrainShareGraph("grain123")
+		require.NoError(t, err)
+		assert.Equal(t, []types.AccountID{"id_dave"}, g.Members)
+		require.Equal(t, 1, len(g.Shares))
+		assert.Equal(t, types.AccountID("id_alice"), g.Shares[0].SharedBy)
+	})
+}
+
+func TestGrainLifecycle(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		now := time.Unix(1700000000, 0)
+
+		require.NoError(t, tx.SetGrainTitle("grain123", "Renamed"))
+		assert.ErrorIs(t, tx.SetGrainTitle("nonexistent", "x"), sql.ErrNoRows)
+
+		require.NoError(t, tx.SetGrainTrashed("grain123", now))
+		info, err := tx.GrainInfo("grain123")
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed", info.Title)
+		assert.Equal(t, now, info.Trashed)
+		views, err := tx.AccountKeyring("id_alice").AllUiViews()
+		require.NoError(t, err)
+		require.Equal(t, 1, len(views))
+		assert.Equal(t, now, views[0].Grain.Trashed, "trashed grains are still listed")
+
+		require.NoError(t, tx.SetGrainTrashed("grain123", time.Time{}))
+		info, err = tx.GrainInfo("grain123")
+		require.NoError(t, err)
+		assert.True(t, info.Trashed.IsZero())
+
+		// Give the grain some history, and share it:
+		require.NoError(t, tx.AddGrainEvent(types.GrainEvent{
+			GrainID: "grain123",
+			Kind:    types.GrainCreated,
+			Time:    now,
+		}))
+		token, err := tx.NewShareLink(types.ShareLink{
+			ID:          "link1",
+			GrainID:     "grain123",
+			AccountID:   "id_alice",
+			Permissions: []bool{true},
+			Created:     now,
+		})
+		require.NoError(t, err)
+		_, _, err = tx.RedeemSharingToken(token, "id_bob", now)
+		require.NoError(t, err)
+		apiToken, err := tx.NewAPIToken(types.APIToken{
+			ID:        "token1",
+			GrainID:   "grain123",
+			AccountID: "id_bob",
+			Created:   now,
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, tx.DeleteGrain("grain123"))
+		assert.ErrorIs(t, tx.DeleteGrain("grain123"), sql.ErrNoRows)
+		_, err = tx.GrainInfo("grain123")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		for _, id := range []types.AccountID{"id_alice", "id_bob"} {
+			views, err := tx.AccountKeyring(id).AllUiViews()
+			require.NoError(t, err)
+			assert.Empty(t, views)
+		}
+		_, err = tx.RestoreSharingToken(apiToken)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		events, err := tx.GrainEvents("grain123")
+		require.NoError(t, err)
+		assert.Empty(t, events)
+		for _, table := range []string{"sturdyRefs", "keyringEntries", "shareLinks", "apiTokens", "grainMembers", "grainShares"} {
+			var n int
+			require.NoError(t, tx.sqlTx.QueryRow(`SELECT count(*) FROM `+table).Scan(&n))
+			assert.Equal(t, 0, n, table)
+		}
+	})
+}
+
+// testManifest returns a manifest for the given version of an app.
+func testManifest(t *testing.T, title string, version uint32, marketingVersion string) spk.Manifest {
+	_, seg := capnp.NewSingleSegmentMessage(nil)
+	m, err := spk.NewRootManifest(seg)
+	require.NoError(t, err)
+	m.SetAppVersion(version)
+	appTitle, err := m.NewAppTitle()
+	require.NoError(t, err)
+	require.NoError(t, appTitle.SetDefaultText(title))
+	mv, err := m.NewAppMarketingVersion()
+	require.NoError(t, err)
+	require.NoError(t, mv.SetDefaultText(marketingVersion))
+	return m
+}
+
+func TestGrainUpgrades(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		const appID = "vjnkphndcs0e8ye8jy4v1x6zcwf3x2k16pyqzy1dkzdhyr9zxtq0"
+		for _, pkg := range []Package{
+			{ID: "v1", AppID: appID, Manifest: testManifest(t, "Notes", 1, "1.0")},
+			{ID: "v2", AppID: appID, Manifest: testManifest(t, "Notes", 2, "2.0")},
+			{ID: "v3", AppID: appID, Manifest: testManifest(t, "Notes", 3, "3.0")},
+			{ID: "other", AppID: "other-app", Manifest: testManifest(t, "Other", 9, "9.0")},
+		} {
+			require.NoError(t, tx.AddPackage(pkg))
+			if pkg.ID != "v3" {
+				require.NoError(t, tx.ReadyPackage(pkg.ID))
+			}
+		}
+		for _, g := range []NewGrain{
+			{GrainID: "old", PkgID: "v1", OwnerID: "id_alice", Title: "Old"},
+			{GrainID: "new", PkgID: "v2", OwnerID: "id_alice", Title: "New"},
+			{GrainID: "bobs", PkgID: "v1", OwnerID: "id_bob", Title: "Bob's"},
+		} {
+			require.NoError(t, tx.AddGrain(g))
+		}
+
+		// v3 isn't ready, and grain123 has no app ID, so only the
+		// grains on v1 can be upgraded, to v2.
+		want := types.GrainUpgrade{
+			GrainID:            "old",
+			AppID:              appID,
+			AppTitle:           "Notes",
+			FromVersion:        1,
+			ToVersion:          2,
+			ToMarketingVersion: "2.0",
+			ToPackageID:        "v2",
+		}
+		upgrades, err := tx.AccountGrainUpgrades("id_alice")
+		require.NoError(t, err)
+		assert.Equal(t, []types.GrainUpgrade{want}, upgrades)
+		require.NoError(t, tx.ReadyPackage("v3"))
+		u, err := tx.GrainUpgrade("old")
+		require.NoError(t, err)
+		assert.Equal(t, "v3", u.ToPackageID, "the newest version is chosen")
+		_, err = tx.GrainUpgrade("grain123")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		_, pending, err := tx.PendingGrainUpgrade("old")
+		require.NoError(t, err)
+		assert.False(t, pending)
+		require.NoError(t, tx.StartGrainUpgrade("old", "v3"))
+		assert.ErrorIs(t, tx.StartGrainUpgrade("old", "v3"), sql.ErrNoRows,
+			"one upgrade at a time")
+		previous, pending, err := tx.PendingGrainUpgrade("old")
+		require.NoError(t, err)
+		assert.True(t, pending)
+		assert.Equal(t, uint32(1), previous)
+		pkgID, err := tx.GrainPackageID("old")
+		require.NoError(t, err)
+		assert.Equal(t, "v3", pkgID)
+		_, err = tx.GrainUpgrade("old")
+		assert.ErrorIs(t, err, sql.ErrNoRows, "pending upgrades aren't offered")
+		ids, err := tx.PendingGrainUpgrades()
+		require.NoError(t, err)
+		assert.Equal(t, []types.GrainID{"old"}, ids)
+
+		require.NoError(t, tx.RollBackGrainUpgrade("old"))
+		assert.ErrorIs(t, tx.RollBackGrainUpgrade("old"), sql.ErrNoRows)
+		pkgID, err = tx.GrainPackageID("old")
+		require.NoError(t, err)
+		assert.Equal(t, "v1", pkgID)
+
+		require.NoError(t, tx.StartGrainUpgrade("new", "v3"))
+		require.NoError(t, tx.FinishGrainUpgrade("new"))
+		assert.ErrorIs(t, tx.FinishGrainUpgrade("new"), sql.ErrNoRows)
+		pkgID, err = tx.GrainPackageID("new")
+		require.NoError(t, err)
+		assert.Equal(t, "v3", pkgID)
+		ids, err = tx.PendingGrainUpgrades()
+		require.NoError(t, err)
+		assert.Empty(t, ids)
+
+		require.NoError(t, tx.SetGrainTrashed("old", time.Unix(1700000000, 0)))
+		upgrades, err = tx.AccountGrainUpgrades("id_alice")
+		require.NoError(t, err)
+		assert.Empty(t, upgrades, "trashed grains aren't offered upgrades")
+		upgrades, err = tx.AccountGrainUpgrades("id_bob")
+		require.NoError(t, err)
+		require.Len(t, upgrades, 1)
+		assert.Equal(t, types.GrainID("bobs"), upgrades[0].GrainID)
+	})
+}
+
+func TestGrainPublicID(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+
+		publicID, err := tx.GrainPublicID("grain123")
+		require.NoError(t, err)
+		assert.Regexp(t, "^[a-z2-7]{16}$", publicID)
+		again, err := tx.GrainPublicID("grain123")
+		require.NoError(t, err)
+		assert.Equal(t, publicID, again, "the public ID doesn't change")
+
+		grainID, err := tx.PublicIDGrain(strings.ToUpper(publicID))
+		require.NoError(t, err)
+		assert.Equal(t, types.GrainID("grain123"), grainID)
+		_, err = tx.PublicIDGrain("nonexistent")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+func TestDeadLetters(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+
+		now := time.Unix(1700000000, 0)
+		for i := 0; i < 2; i++ {
+			require.NoError(t, tx.AddDeadLetter(DeadLetter{
+				GrainID:  "grain123",
+				Sender:   "bob@example.net",
+				Data:     []byte(fmt.Sprintf("Subject: %d\r\n\r\n", i)),
+				Error:    "grain crashed",
+				Received: now.Add(time.Duration(i) * time.Minute),
+			}))
+		}
+		letters, err := tx.DeadLetters()
+		require.NoError(t, err)
+		require.Equal(t, 2, len(letters))
+		assert.Equal(t, now.Add(time.Minute), letters[0].Received, "most recent first")
+		assert.Nil(t, letters[0].Data)
+
+		got, err := tx.DeadLetter(letters[1].ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Subject: 0\r\n\r\n", string(got.Data))
+		got.Data = nil
+		assert.Equal(t, letters[1], got)
+
+		require.NoError(t, tx.DeleteDeadLetter(letters[1].ID))
+		_, err = tx.DeadLetter(letters[1].ID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		letters, err = tx.DeadLetters()
+		require.NoError(t, err)
+		assert.Equal(t, 1, len(letters))
+	})
+}
+
+func TestLoginAudit(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		now := time.Unix(1700000000, 0)
+		alice := types.Credential{Type: types.DevCredential, ScopedID: "Alice"}
+		require.NoError(t, tx.AddLoginAuditEntry(LoginAuditEntry{
+			Time:       now,
+			Event:      LoginFailed,
+			RemoteAddr: "192.0.2.1",
+			Detail:     "invalid token",
+		}))
+		require.NoError(t, tx.AddLoginAuditEntry(LoginAuditEntry{
+			Time:       now.Add(time.Minute),
+			Event:      LoginSucceeded,
+			RemoteAddr: "192.0.2.1",
+			Credential: alice,
+		}))
+
+		entries, err := tx.LoginAuditEntries(10)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, LoginSucceeded, entries[0].Event, "most recent first")
+		assert.Equal(t, alice, entries[0].Credential)
+		assert.Equal(t, now.Add(time.Minute), entries[0].Time)
+		assert.Equal(t, "invalid token", entries[1].Detail)
+		assert.Equal(t, types.Credential{}, entries[1].Credential)
+
+		entries, err = tx.LoginAuditEntries(1)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+}
+
+func TestDeleteLogsBefore(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		now := time.Unix(1700000000, 0)
+		for _, at := range []time.Time{now.Add(-time.Hour), now} {
+			require.NoError(t, tx.AddLoginAuditEntry(LoginAuditEntry{
+				Time:  at,
+				Event: LoginFailed,
+			}))
+			require.NoError(t, tx.AddGrainEvent(types.GrainEvent{
+				GrainID: "grain123",
+				Kind:    types.GrainCreated,
+				Time:    at,
+			}))
+			require.NoError(t, tx.AddGrainStart(GrainStart{
+				GrainID:   "grain123",
+				PackageID: "abcdef",
+				StartedAt: at,
+			}))
+		}
+
+		n, err := tx.DeleteLogsBefore(now)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+		entries, err := tx.LoginAuditEntries(10)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, now, entries[0].Time)
+		events, err := tx.GrainEvents("grain123")
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, now, events[0].Time)
+		stats, err := tx.PackageStartStats(time.Time{})
+		require.NoError(t, err)
+		require.Len(t, stats, 1)
+		assert.Equal(t, 1, stats[0].Starts)
+	})
+}
+
+func TestDeleteSessionsStartedBefore(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		alice := types.Credential{Type: "dev", ScopedID: "Alice Dev Admin"}
+		now := time.Now().Truncate(time.Second)
+		for id, started := range map[string]time.Time{
+			"old": now.Add(-2 * time.Hour),
+			"new": now,
+		} {
+			require.NoError(t, tx.AddUserSession(UserSessionInfo{
+				SessionID:  []byte(id),
+				Credential: alice,
+				Started:    started,
+			}))
+		}
+		require.NoError(t, tx.RevokeSession([]byte("revoked")))
+
+		n, err := tx.DeleteSessionsStartedBefore(now.Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+		ids, err := tx.AccountUserSessions("id_alice")
+		require.NoError(t, err)
+		assert.Equal(t, [][]byte{[]byte("new")}, ids)
+		revoked, err := tx.IsSessionRevoked([]byte("revoked"))
+		require.NoError(t, err)
+		assert.True(t, revoked, "revoked after the cutoff")
+
+		_, err = tx.DeleteSessionsStartedBefore(now.Add(time.Hour))
+		require.NoError(t, err)
+		revoked, err = tx.IsSessionRevoked([]byte("revoked"))
+		require.NoError(t, err)
+		assert.False(t, revoked)
+	})
+}
+
+func TestDeleteExpiredLoginTokens(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		alice := types.Credential{Type: "dev", ScopedID: "Alice Dev Admin"}
+		now := time.Now()
+		_, err := tx.NewRPCToken(alice, now.Add(-time.Minute))
+		require.NoError(t, err)
+		live, err := tx.NewRPCToken(alice, now.Add(time.Hour))
+		require.NoError(t, err)
+		_, err = tx.NewShareLink(types.ShareLink{
+			ID:          "link1",
+			GrainID:     "grain123",
+			AccountID:   "id_alice",
+			Permissions: []bool{true},
+			Created:     now,
+			Expires:     now.Add(-time.Minute),
+		})
+		require.NoError(t, err)
+
+		n, err := tx.DeleteExpiredLoginTokens(now)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+		cred, err := tx.RestoreRPCToken([]byte(live))
+		require.NoError(t, err)
+		assert.Equal(t, alice, cred)
+		var shared int
+		require.NoError(t, tx.sqlTx.QueryRow(
+			`SELECT count(*) FROM sturdyRefs WHERE ownerType = 'external-api'`,
+		).Scan(&shared))
+		assert.Equal(t, 1, shared, "other tokens are left alone")
+	})
+}
+
+func TestGrainsTrashedBefore(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		now := time.Unix(1700000000, 0)
+		ids, err := tx.GrainsTrashedBefore(now)
+		require.NoError(t, err)
+		assert.Empty(t, ids)
+
+		require.NoError(t, tx.SetGrainTrashed("grain123", now.Add(-time.Hour)))
+		ids, err = tx.GrainsTrashedBefore(now.Add(-2 * time.Hour))
+		require.NoError(t, err)
+		assert.Empty(t, ids)
+		ids, err = tx.GrainsTrashedBefore(now)
+		require.NoError(t, err)
+		assert.Equal(t, []types.GrainID{"grain123"}, ids)
+	})
+}
+
+func TestGrainStorage(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		info, err := tx.GrainInfo("grain123")
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), info.StorageBytes, "not measured yet")
+
+		require.NoError(t, tx.SetGrainStorage(map[types.GrainID]int64{
+			"grain123":    4096,
+			"nonexistent": 512,
+		}))
+		info, err = tx.GrainInfo("grain123")
+		require.NoError(t, err)
+		assert.Equal(t, int64(4096), info.StorageBytes)
+		views, err := tx.AccountKeyring("id_alice").AllUiViews()
+		require.NoError(t, err)
+		require.Len(t, views, 1)
+		assert.Equal(t, int64(4096), views[0].Grain.StorageBytes)
+
+		require.NoError(t, tx.SetGrainStorage(nil))
+		info, err = tx.GrainInfo("grain123")
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), info.StorageBytes, "grains left out use nothing")
+	})
+}
+
+func TestGrainResourceLimits(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		limits, err := tx.GrainResourceLimits("grain123")
+		require.NoError(t, err)
+		assert.Equal(t, cgroup.Limits{}, limits, "no override")
+
+		want := cgroup.Limits{MemoryMax: 512 << 20, PidsMax: 64}
+		require.NoError(t, tx.SetGrainResourceLimits("grain123", want))
+		require.NoError(t, tx.SetGrainResourceLimits("grain123", want))
+		limits, err = tx.GrainResourceLimits("grain123")
+		require.NoError(t, err)
+		assert.Equal(t, want, limits)
+		all, err := tx.AllGrainResourceLimits()
+		require.NoError(t, err)
+		assert.Equal(t, map[types.GrainID]cgroup.Limits{"grain123": want}, all)
+
+		require.NoError(t, tx.SetGrainResourceLimits("grain123", cgroup.Limits{}))
+		all, err = tx.AllGrainResourceLimits()
+		require.NoError(t, err)
+		assert.Empty(t, all)
+	})
+}
+
+func TestScheduledJobs(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+
+		now := time.Unix(1700000000, 0)
+		_, ok, err := tx.NextScheduledJobDue()
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		daily := scheduler.Job{
+			ID:       "daily",
+			GrainID:  "grain123",
+			Name:     "Send digest",
+			ObjectID: []byte("object id"),
+			Period:   scheduler.Daily,
+			Next:     now,
+		}
+		once := scheduler.Job{
+			ID:       "once",
+			GrainID:  "grain123",
+			ObjectID: []byte("other object id"),
+			Next:     now.Add(time.Hour),
+		}
+		require.NoError(t, tx.AddScheduledJob(daily))
+		require.NoError(t, tx.AddScheduledJob(once))
+
+		next, ok, err := tx.NextScheduledJobDue()
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, now, next)
+		due, err := tx.DueScheduledJobs(now)
+		require.NoError(t, err)
+		assert.Equal(t, []scheduler.Job{daily}, due)
+
+		daily.Failures = 8
+		daily.LastError = "grain crashed"
+		daily.Disabled = true
+		require.NoError(t, tx.UpdateScheduledJob(daily))
+		due, err = tx.DueScheduledJobs(now.Add(2 * time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, []scheduler.Job{once}, due, "disabled jobs are not due")
+
+		require.NoError(t, tx.DeleteScheduledJob("once"))
+		_, ok, err = tx.NextScheduledJobDue()
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func TestNotifications(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+
+		accounts, err := tx.GrainAccounts("grain123")
+		require.NoError(t, err)
+		assert.Equal(t, []types.AccountID{"id_alice"}, accounts)
+
+		now := time.Unix(1700000000, 0)
+		var ids []int64
+		for i := 0; i < 3; i++ {
+			id, err := tx.AddNotification(Notification{
+				AccountID: "id_alice",
+				GrainID:   "grain123",
+				Path:      fmt.Sprintf("thread/%d", i),
+				Text:      "New message",
+				Time:      now.Add(time.Duration(i) * time.Minute),
+			})
+			require.NoError(t, err)
+			ids = append(ids, id)
+		}
+		notifications, err := tx.AccountNotifications("id_alice")
+		require.NoError(t, err)
+		require.Equal(t, 3, len(notifications))
+		assert.Equal(t, Notification{
+			ID:         ids[2],
+			AccountID:  "id_alice",
+			GrainID:    "grain123",
+			GrainTitle: "Example Grain",
+			Path:       "thread/2",
+			Text:       "New message",
+			Time:       now.Add(2 * time.Minute),
+		}, notifications[0], "most recent first")
+		notifications, err = tx.AccountNotifications("id_bob")
+		require.NoError(t, err)
+		assert.Equal(t, 0, len(notifications))
+
+		count, err := tx.UnreadNotificationCount("id_alice")
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+		require.NoError(t, tx.MarkNotificationsRead("id_bob", ids))
+		require.NoError(t, tx.MarkNotificationsRead("id_alice", ids[:1]))
+		count, err = tx.UnreadNotificationCount("id_alice")
+		require.NoError(t, err)
+		assert.Equal(t, 2, count, "only the owner's notifications are marked")
+		require.NoError(t, tx.MarkNotificationsRead("id_alice", nil))
+		count, err = tx.UnreadNotificationCount("id_alice")
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+}
+
+func TestAccountLocale(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		locale, err := tx.AccountLocale("id_bob")
+		require.NoError(t, err)
+		assert.Equal(t, "", locale, "accounts start without a locale")
+		require.NoError(t, tx.SetAccountLocale("id_bob", "de"))
+		locale, err = tx.AccountLocale("id_bob")
+		require.NoError(t, err)
+		assert.Equal(t, "de", locale)
+		locale, err = tx.AccountLocale("id_alice")
+		require.NoError(t, err)
+		assert.Equal(t, "", locale)
+
+		_, err = tx.AccountLocale("id_nobody")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.ErrorIs(t, tx.SetAccountLocale("id_nobody", "de"), sql.ErrNoRows)
+	})
+}
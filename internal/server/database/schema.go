@@ -12,20 +12,32 @@ import (
 type Package struct {
 	ID       types.ID[Package] // The package id.
 	Manifest spk.Manifest      // The manifest as encoded in the spk.
+
+	// The app ID, i.e. the public key which signed the package, as
+	// verified when it was unpacked. Empty if unknown.
+	AppID string
 }
 
-// Initializes the database schema if needed, and returns a DB object.
+// Initializes the database schema if needed, bringing it up to date with
+// migrations, and returns a DB object.
 func InitDB(sqlDB *sql.DB) (DB, error) {
-	return exn.Try(func(throw exn.Thrower) DB {
-		tx, err := sqlDB.Begin()
-		throw(err)
-		defer tx.Rollback()
+	if err := migrate(sqlDB); err != nil {
+		return DB{}, err
+	}
+	return DB{sqlDB: sqlDB}, nil
+}
 
-		// Some general notes about the schema:
-		//
-		// - Anywhere we store a capnp value in a column, it is stored as a single segment
-		//   (no headers) in packed encoding.
-		_, err = tx.Exec(
+// createSchema creates the tables of the first version of the schema. Tables
+// are created only if they don't exist, since databases from before schema
+// versions were recorded already have them.
+//
+// Some general notes about the schema:
+//
+//   - Anywhere we store a capnp value in a column, it is stored as a single
+//     segment (no headers) in packed encoding.
+func createSchema(tx *sql.Tx) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		_, err := tx.Exec(
 			`CREATE TABLE IF NOT EXISTS packages (
 				-- 128-bit prefix of the sha256 hash of the spk file, hex encoded:
 				id VARCHAR(32) PRIMARY KEY NOT NULL,
@@ -42,6 +54,22 @@ func InitDB(sqlDB *sql.DB) (DB, error) {
 				ready BOOLEAN NOT NULL
 			)`)
 		throw(err)
+		_, err = tx.Exec(
+			`-- The app which each package is a version of, from its verified
+			 -- signature. Packages installed before these were recorded have
+			 -- no row here.
+			 CREATE TABLE IF NOT EXISTS packageApps (
+				packageId VARCHAR(32) PRIMARY KEY REFERENCES packages(id) ON DELETE CASCADE,
+				-- The app ID, in Sandstorm's base32:
+				appId VARCHAR NOT NULL,
+				-- The manifest's appVersion:
+				appVersion INTEGER NOT NULL
+			)`)
+		throw(err)
+		_, err = tx.Exec(
+			`CREATE INDEX IF NOT EXISTS packageAppsByApp
+			 ON packageApps (appId, appVersion)`)
+		throw(err)
 		_, err = tx.Exec(
 			`CREATE TABLE IF NOT EXISTS accounts (
 				id VARCHAR PRIMARY KEY,
@@ -108,6 +136,10 @@ func InitDB(sqlDB *sql.DB) (DB, error) {
 				--   rather than keeping track of the token.
 				-- * 'external-api': "owner" is the empty string, and the sturdyRef
 				--   must be restored via ExternalApi.restore().
+				-- * 'powerbox-claim': "owner" is a grain ID; the token was
+				--   given to the grain by the browser when the user fulfilled
+				--   its powerbox request, and may be exchanged once for the
+				--   capability via SessionContext.claimRequest().
 				ownerType VARCHAR NOT NULL,
 				owner VARCHAR NOT NULL,
 
@@ -156,7 +188,267 @@ func InitDB(sqlDB *sql.DB) (DB, error) {
 				UNIQUE (id, accountId)
 			)`)
 		throw(err)
-		throw(tx.Commit())
-		return DB{sqlDB: sqlDB}
+		_, err = tx.Exec(
+			`-- Timing breakdowns for grain starts, used to find apps which are slow
+			 -- to start. See container.StartTiming for the meaning of each phase.
+			 CREATE TABLE IF NOT EXISTS grainStarts (
+				grainId VARCHAR(22) NOT NULL REFERENCES grains(id) ON DELETE CASCADE,
+
+				-- Package the grain was running when it started. We record this
+				-- rather than joining with grains, since the grain's package may
+				-- change when the app is upgraded.
+				packageId VARCHAR(32) NOT NULL,
+
+				-- Unix timestamp at which the start began.
+				startedAt INTEGER NOT NULL,
+
+				-- Duration of each phase, in microseconds:
+				lookupUs INTEGER NOT NULL,
+				sandboxUs INTEGER NOT NULL,
+				-- From the grain agent starting until the app's first response.
+				appInitUs INTEGER NOT NULL
+			)`)
+		throw(err)
+		_, err = tx.Exec(
+			`CREATE INDEX IF NOT EXISTS grainStartsByPackage
+			 ON grainStarts (packageId, startedAt)`)
+		throw(err)
+		_, err = tx.Exec(
+			`-- Counts of calls to deprecated API methods, by package. See the
+			 -- apiversion package.
+			 CREATE TABLE IF NOT EXISTS deprecatedApiUsage (
+				packageId VARCHAR(32) NOT NULL REFERENCES packages(id),
+				-- Name of the capnp interface and method, e.g. "SandstormApi"
+				-- and "deprecatedPublish".
+				interface VARCHAR NOT NULL,
+				method VARCHAR NOT NULL,
+				calls INTEGER NOT NULL,
+				-- Unix timestamp of the most recent call.
+				lastUsed INTEGER NOT NULL,
+				PRIMARY KEY (packageId, interface, method)
+			)`)
+		throw(err)
+		_, err = tx.Exec(
+			`-- Number of TURN credentials issued to each grain. See the turn
+			 -- package.
+			 CREATE TABLE IF NOT EXISTS turnCredentials (
+				grainId VARCHAR(22) PRIMARY KEY REFERENCES grains(id) ON DELETE CASCADE,
+				issued INTEGER NOT NULL,
+				-- Unix timestamp at which credentials were last issued.
+				lastIssued INTEGER NOT NULL
+			)`)
+		throw(err)
+		_, err = tx.Exec(
+			`-- Notable events in each grain's life, shown to its owner as a
+			 -- timeline. See types.GrainEvent.
+			 CREATE TABLE IF NOT EXISTS grainEvents (
+				grainId VARCHAR(22) NOT NULL REFERENCES grains(id) ON DELETE CASCADE,
+				-- A types.GrainEventKind:
+				kind VARCHAR NOT NULL,
+				-- Unix timestamp of the event:
+				time INTEGER NOT NULL,
+				detail VARCHAR NOT NULL
+			)`)
+		throw(err)
+		_, err = tx.Exec(
+			`CREATE INDEX IF NOT EXISTS grainEventsByGrain
+			 ON grainEvents (grainId, time)`)
+		throw(err)
+		_, err = tx.Exec(
+			`-- Sessions which have been ended before their cookies expire,
+			 -- e.g. by logging out. See the session package.
+			 CREATE TABLE IF NOT EXISTS revokedSessions (
+				sessionId BLOB PRIMARY KEY NOT NULL,
+				-- Unix timestamp at which the session was revoked.
+				revoked INTEGER NOT NULL
+			)`)
+		throw(err)
+		_, err = tx.Exec(
+			`-- Admin scopes granted to accounts which don't have the
+			 -- admin role; admins implicitly have every scope. See
+			 -- types.AdminScope.
+			 CREATE TABLE IF NOT EXISTS adminScopes (
+				accountId VARCHAR NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+				scope VARCHAR NOT NULL,
+				PRIMARY KEY (accountId, scope)
+			)`)
+		throw(err)
+		_, err = tx.Exec(
+			`-- Signed URLs through which external websites may show a
+			 -- grain in a frame. See types.GrainEmbed.
+			 CREATE TABLE IF NOT EXISTS grainEmbeds (
+				id VARCHAR PRIMARY KEY NOT NULL,
+				grainId VARCHAR NOT NULL REFERENCES grains(id) ON DELETE CASCADE,
+				-- Permissions, formatted as for keyringEntries:
+				permissions VARCHAR NOT NULL,
+				-- Space-separated origins allowed to frame the grain:
+				frameAncestors VARCHAR NOT NULL,
+				note VARCHAR NOT NULL,
+				-- Unix timestamps:
+				created INTEGER NOT NULL,
+				expires INTEGER NOT NULL
+			)`)
+		throw(err)
+		_, err = tx.Exec(
+			`CREATE INDEX IF NOT EXISTS grainEmbedsByGrain
+			 ON grainEmbeds (grainId)`)
+		throw(err)
+		_, err = tx.Exec(
+			`-- Powerbox requests which grains have made through the
+			 -- browser, and which the user has yet to fulfill. See
+			 -- PowerboxRequest.
+			 CREATE TABLE IF NOT EXISTS powerboxRequests (
+				id VARCHAR PRIMARY KEY NOT NULL,
+				-- The grain making the request:
+				grainId VARCHAR(22) NOT NULL REFERENCES grains(id) ON DELETE CASCADE,
+				-- The user who is asked to fulfill it:
+				accountId VARCHAR NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+				-- Space-separated, base64-encoded packed PowerboxDescriptors:
+				query VARCHAR NOT NULL,
+				saveLabel VARCHAR NOT NULL,
+				-- Unix timestamp after which the request is abandoned:
+				expires INTEGER NOT NULL
+			)`)
+		throw(err)
+		_, err = tx.Exec(
+			`-- Conditions on the sturdyRefs which grains hold to
+			 -- capabilities granted through the powerbox: the account
+			 -- which granted the capability must still have the required
+			 -- permissions on the grain holding it. See PowerboxGrant.
+			 CREATE TABLE IF NOT EXISTS powerboxGrants (
+				sha256 BLOB PRIMARY KEY NOT NULL REFERENCES sturdyRefs(sha256) ON DELETE CASCADE,
+				accountId VARCHAR NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+				-- Formatted as for keyringEntries:
+				requiredPermissions VARCHAR NOT NULL
+			)`)
+		throw(err)
+		_, err = tx.Exec(
+			`-- The public IDs of grains, which make up their email
+			 -- addresses. A grain gets one when it first asks for it.
+			 CREATE TABLE IF NOT EXISTS grainPublicIds (
+				grainId VARCHAR(22) PRIMARY KEY NOT NULL REFERENCES grains(id) ON DELETE CASCADE,
+				publicId VARCHAR UNIQUE NOT NULL
+			)`)
+		throw(err)
+		_, err = tx.Exec(
+			`-- Incoming email which could not be delivered to its grain,
+			 -- kept for an admin to retry or discard. See DeadLetter.
+			 CREATE TABLE IF NOT EXISTS deadLetters (
+				id INTEGER PRIMARY KEY,
+				grainId VARCHAR(22) NOT NULL REFERENCES grains(id) ON DELETE CASCADE,
+				-- The envelope sender, from MAIL FROM:
+				sender VARCHAR NOT NULL,
+				-- The message, as received:
+				data BLOB NOT NULL,
+				-- Why delivery failed:
+				error VARCHAR NOT NULL,
+				-- Unix timestamp:
+				received INTEGER NOT NULL
+			)`)
+		throw(err)
+		_, err = tx.Exec(
+			`-- Callbacks which grains have scheduled with
+			 -- SandstormApi.schedule(). See scheduler.Job.
+			 CREATE TABLE IF NOT EXISTS scheduledJobs (
+				id VARCHAR PRIMARY KEY NOT NULL,
+				grainId VARCHAR(22) NOT NULL REFERENCES grains(id) ON DELETE CASCADE,
+				name VARCHAR NOT NULL,
+				-- The callback's AppObjectId, as a capnp message:
+				objectId BLOB NOT NULL,
+				-- A scheduler.Period; empty for one-shot jobs:
+				period VARCHAR NOT NULL,
+				-- Unix timestamp at which the job is next due:
+				next INTEGER NOT NULL,
+				-- Failures in a row, and the last one's error:
+				failures INTEGER NOT NULL,
+				lastError VARCHAR NOT NULL,
+				disabled BOOLEAN NOT NULL
+			)`)
+		throw(err)
+		_, err = tx.Exec(
+			`CREATE INDEX IF NOT EXISTS scheduledJobsByNext
+			 ON scheduledJobs (disabled, next)`)
+		throw(err)
+		_, err = tx.Exec(
+			`-- Notifications about activity which grains have reported with
+			 -- SessionContext.activity() or SandstormApi.backgroundActivity().
+			 CREATE TABLE IF NOT EXISTS notifications (
+				id INTEGER PRIMARY KEY,
+				accountId VARCHAR NOT NULL REFERENCES accounts(id),
+				grainId VARCHAR(22) NOT NULL REFERENCES grains(id) ON DELETE CASCADE,
+				-- Path within the grain's UI; see ActivityEvent.path:
+				path VARCHAR NOT NULL,
+				-- Human readable description of the event:
+				text VARCHAR NOT NULL,
+				-- Unix timestamp, in nanoseconds:
+				time INTEGER NOT NULL,
+				read BOOLEAN NOT NULL
+			)`)
+		throw(err)
+		_, err = tx.Exec(
+			`CREATE INDEX IF NOT EXISTS notificationsByAccount
+			 ON notifications (accountId, time)`)
+		throw(err)
+		_, err = tx.Exec(
+			`-- Accounts which an admin has deactivated. Their users can't
+			 -- log in, but their grains are kept.
+			 CREATE TABLE IF NOT EXISTS deactivatedAccounts (
+				accountId VARCHAR PRIMARY KEY NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+				-- Unix timestamp at which the account was deactivated.
+				deactivated INTEGER NOT NULL
+			)`)
+		throw(err)
+		_, err = tx.Exec(
+			`-- Links through which admins have invited people to join the
+			 -- server. See Invite.
+			 CREATE TABLE IF NOT EXISTS invites (
+				id VARCHAR PRIMARY KEY NOT NULL,
+				-- raw sha256 hash of the token in the link.
+				sha256 BLOB UNIQUE NOT NULL,
+				-- The role to give accounts which redeem the invite:
+				role VARCHAR NOT NULL,
+				-- How many accounts may redeem the invite; 0 for any number:
+				maxUses INTEGER NOT NULL,
+				-- Unix timestamps; expires is 0 if the invite doesn't expire:
+				created INTEGER NOT NULL,
+				expires INTEGER NOT NULL,
+				createdBy VARCHAR NOT NULL REFERENCES accounts(id),
+				note VARCHAR NOT NULL
+			)`)
+		throw(err)
+		_, err = tx.Exec(
+			`CREATE TABLE IF NOT EXISTS inviteRedemptions (
+				inviteId VARCHAR NOT NULL REFERENCES invites(id) ON DELETE CASCADE,
+				accountId VARCHAR NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+				-- Unix timestamp:
+				redeemed INTEGER NOT NULL,
+				PRIMARY KEY (inviteId, accountId)
+			)`)
+		throw(err)
+		_, err = tx.Exec(
+			`-- Login attempts, for auditing. See LoginAuditEntry.
+			 CREATE TABLE IF NOT EXISTS loginAudit (
+				id INTEGER PRIMARY KEY,
+				-- Unix timestamp:
+				time INTEGER NOT NULL,
+				event VARCHAR NOT NULL,
+				remoteAddr VARCHAR NOT NULL,
+				-- The credential logged in with, or attempted; empty
+				-- if unknown:
+				credentialType VARCHAR NOT NULL,
+				scopedId VARCHAR NOT NULL,
+				detail VARCHAR NOT NULL
+			)`)
+		throw(err)
+		_, err = tx.Exec(
+			`-- Resource limits of grains, overriding the server's defaults.
+			 -- See cgroup.Limits; zero columns use the default.
+			 CREATE TABLE IF NOT EXISTS grainResourceLimits (
+				grainId VARCHAR(22) PRIMARY KEY REFERENCES grains(id) ON DELETE CASCADE,
+				memoryMax INTEGER NOT NULL,
+				cpuWeight INTEGER NOT NULL,
+				pidsMax INTEGER NOT NULL
+			)`)
+		throw(err)
 	})
 }
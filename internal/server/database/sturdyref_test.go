@@ -1,11 +1,14 @@
 package database
 
 import (
+	"crypto/sha256"
+	"database/sql"
 	"math"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"sandstorm.org/go/tempest/internal/common/types"
 	"sandstorm.org/go/tempest/internal/server/tokenutil"
 )
 
@@ -74,3 +77,250 @@ func TestSturdyRefRestoreExpired(t *testing.T) {
 		require.NotNil(t, err, "restoring expired sturdyRef should fail")
 	})
 }
+
+// Add a sharing token by its hash, and restore it with the token.
+func TestAddSharingTokenHash(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		token := tokenutil.GenToken()
+		expires := time.Unix(time.Now().Add(time.Hour).Unix(), 0)
+		err := tx.AddSharingTokenHash(sha256.Sum256(token), "grain123", []bool{true}, "Imported", expires)
+		require.NoError(t, err)
+
+		restored, err := tx.RestoreSturdyRef(SturdyRefKey{
+			Token:     token,
+			OwnerType: "external-api",
+		})
+		require.NoError(t, err)
+		require.Equal(t, expires, restored.Expires)
+		require.Equal(t, types.GrainID(""), restored.GrainID)
+		require.True(t, restored.ObjectID.IsValid())
+	})
+}
+
+// Restore a sharing token made by NewSharingToken.
+func TestRestoreSharingToken(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		token, err := tx.NewSharingToken("grain123", []bool{true, false}, "API")
+		require.NoError(t, err)
+
+		st, err := tx.RestoreSharingToken(token)
+		require.NoError(t, err)
+		require.Equal(t, SharingToken{
+			GrainID:     "grain123",
+			Permissions: []bool{true, false},
+			Note:        "API",
+		}, st)
+
+		_, err = tx.RestoreSharingToken("nonexistent")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+// Save and restore a powerbox grant; dropping it deletes its requirements too.
+func TestPowerboxGrant(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		key := SturdyRefKey{
+			Token:     tokenutil.GenToken(),
+			OwnerType: "grain",
+			Owner:     "grain123",
+		}
+		grant := PowerboxGrant{
+			GrainID:             "grain456",
+			Permissions:         []bool{true, false},
+			Note:                "Linked grain",
+			Expires:             time.Unix(math.MaxInt64, 0),
+			AccountID:           "id_bob",
+			RequiredPermissions: []bool{false, true},
+		}
+		require.NoError(t, tx.SavePowerboxGrant(key, grant))
+
+		restored, err := tx.RestorePowerboxGrant(key)
+		require.NoError(t, err)
+		require.Equal(t, grant, restored)
+
+		_, err = tx.RestorePowerboxGrant(SturdyRefKey{
+			Token:     key.Token,
+			OwnerType: "grain",
+			Owner:     "grain456",
+		})
+		require.ErrorIs(t, err, sql.ErrNoRows, "only the owner can restore it")
+
+		require.NoError(t, tx.DeleteSturdyRef(key))
+		_, err = tx.RestorePowerboxGrant(key)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		var n int
+		require.NoError(t, tx.sqlTx.QueryRow(`SELECT COUNT(*) FROM powerboxGrants`).Scan(&n))
+		require.Equal(t, 0, n)
+	})
+}
+
+// Save and restore a grant of network access.
+func TestPowerboxNetworkGrant(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		key := SturdyRefKey{
+			Token:     tokenutil.GenToken(),
+			OwnerType: "grain",
+			Owner:     "grain123",
+		}
+		grant := PowerboxGrant{
+			Network:             true,
+			Expires:             time.Unix(math.MaxInt64, 0),
+			AccountID:           "id_bob",
+			RequiredPermissions: []bool{true},
+		}
+		require.NoError(t, tx.SavePowerboxGrant(key, grant))
+
+		restored, err := tx.RestorePowerboxGrant(key)
+		require.NoError(t, err)
+		require.Equal(t, grant, restored)
+	})
+}
+
+// Save and restore a grant of permission to listen on the network.
+func TestPowerboxIPInterfaceGrant(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		key := SturdyRefKey{
+			Token:     tokenutil.GenToken(),
+			OwnerType: "grain",
+			Owner:     "grain123",
+		}
+		grant := PowerboxGrant{
+			IPInterface:         true,
+			Expires:             time.Unix(math.MaxInt64, 0),
+			AccountID:           "id_bob",
+			RequiredPermissions: []bool{true},
+		}
+		require.NoError(t, tx.SavePowerboxGrant(key, grant))
+
+		restored, err := tx.RestorePowerboxGrant(key)
+		require.NoError(t, err)
+		require.Equal(t, grant, restored)
+	})
+}
+
+// Save and restore a grant of the thumbnail service.
+func TestPowerboxThumbnailerGrant(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		key := SturdyRefKey{
+			Token:     tokenutil.GenToken(),
+			OwnerType: "grain",
+			Owner:     "grain123",
+		}
+		grant := PowerboxGrant{
+			Thumbnailer:         true,
+			Expires:             time.Unix(math.MaxInt64, 0),
+			AccountID:           "id_bob",
+			RequiredPermissions: []bool{true},
+		}
+		require.NoError(t, tx.SavePowerboxGrant(key, grant))
+
+		restored, err := tx.RestorePowerboxGrant(key)
+		require.NoError(t, err)
+		require.Equal(t, grant, restored)
+	})
+}
+
+// Save and restore a grant of the TURN relay.
+func TestPowerboxTURNGrant(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		key := SturdyRefKey{
+			Token:     tokenutil.GenToken(),
+			OwnerType: "grain",
+			Owner:     "grain123",
+		}
+		grant := PowerboxGrant{
+			TURN:                true,
+			Expires:             time.Unix(math.MaxInt64, 0),
+			AccountID:           "id_bob",
+			RequiredPermissions: []bool{true},
+		}
+		require.NoError(t, tx.SavePowerboxGrant(key, grant))
+
+		restored, err := tx.RestorePowerboxGrant(key)
+		require.NoError(t, err)
+		require.Equal(t, grant, restored)
+	})
+}
+
+// Save and restore a grant of a user's identity.
+func TestPowerboxIdentityGrant(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		key := SturdyRefKey{
+			Token:     tokenutil.GenToken(),
+			OwnerType: "grain",
+			Owner:     "grain123",
+		}
+		grant := PowerboxGrant{
+			Identity:            "id_alice",
+			Expires:             time.Unix(math.MaxInt64, 0),
+			AccountID:           "id_bob",
+			RequiredPermissions: []bool{true},
+		}
+		require.NoError(t, tx.SavePowerboxGrant(key, grant))
+
+		restored, err := tx.RestorePowerboxGrant(key)
+		require.NoError(t, err)
+		require.Equal(t, grant, restored)
+	})
+}
+
+func TestPowerboxHTTPAPIGrant(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		for _, authorization := range []string{"", "Bearer secret"} {
+			key := SturdyRefKey{
+				Token:     tokenutil.GenToken(),
+				OwnerType: "grain",
+				Owner:     "grain123",
+			}
+			grant := PowerboxGrant{
+				HTTPAPI:             "https://api.example.com/v1/",
+				HTTPAuthorization:   authorization,
+				Expires:             time.Unix(math.MaxInt64, 0),
+				AccountID:           "id_bob",
+				RequiredPermissions: []bool{true},
+			}
+			require.NoError(t, tx.SavePowerboxGrant(key, grant))
+
+			restored, err := tx.RestorePowerboxGrant(key)
+			require.NoError(t, err)
+			require.Equal(t, grant, restored)
+		}
+	})
+}
+
+// Make, restore and revoke an RPC token.
+func TestRPCToken(t *testing.T) {
+	testWithTx(t, func(tx Tx) {
+		addTestData(t, tx)
+		bob := types.Credential{Type: types.DevCredential, ScopedID: "Bob Dev User"}
+		token, err := tx.NewRPCToken(bob, time.Unix(math.MaxInt64, 0))
+		require.NoError(t, err)
+
+		cred, err := tx.RestoreRPCToken([]byte(token))
+		require.NoError(t, err)
+		require.Equal(t, bob, cred)
+
+		_, err = tx.RestoreSharingToken(token)
+		require.ErrorIs(t, err, sql.ErrNoRows, "it isn't a sharing token")
+
+		_, err = tx.NewRPCToken(types.Credential{Type: types.DevCredential, ScopedID: "Nobody"},
+			time.Unix(math.MaxInt64, 0))
+		require.ErrorIs(t, err, sql.ErrNoRows, "there must be an account already")
+
+		sharing, err := tx.NewSharingToken("grain123", []bool{true}, "")
+		require.NoError(t, err)
+		require.ErrorIs(t, tx.DeleteRPCToken([]byte(sharing)), sql.ErrNoRows,
+			"only RPC tokens can be revoked")
+
+		require.NoError(t, tx.DeleteRPCToken([]byte(token)))
+		_, err = tx.RestoreRPCToken([]byte(token))
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
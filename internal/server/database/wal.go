@@ -0,0 +1,451 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"golang.org/x/exp/slog"
+	"sandstorm.org/go/tempest/internal/server/tokenutil"
+)
+
+// Write-ahead log shipping
+//
+// A database opened with OpenReplicated uses SQLite's write-ahead log (WAL),
+// which is an append-only file of database pages, and ships it to standbys
+// as it grows; see https://www.sqlite.org/fileformat2.html#walformat.
+//
+// Once every page in the log has been copied back into the database file (a
+// "checkpoint"), the next write restarts the log from the beginning, with new
+// salts in its header. We call the stretch of log between restarts a
+// generation. A standby keeps a copy of the database file and of the current
+// generation; when the primary moves to the next generation, the standby
+// checkpoints its own copy, and then starts copying the new one.
+//
+// For this to work, no generation may end before we have seen all of it, so
+// automatic checkpoints are disabled, and only WAL runs checkpoints, while
+// holding the database's write lock. A standby which hasn't fetched the whole
+// of a generation when it ends must start again from a copy of the database
+// file.
+
+const (
+	walHeaderSize      = 32
+	walFrameHeaderSize = 24
+
+	// Run a checkpoint once the log has grown to walCheckpointSize, if a
+	// standby has fetched all of it. This is about the size at which SQLite
+	// runs them automatically.
+	walCheckpointSize = 4 << 20
+
+	// Run a checkpoint once the log has grown to walMaxSize, even if this
+	// means that standbys must start over.
+	walMaxSize = 64 << 20
+
+	// How often WAL.Serve checks the size of the log.
+	walCheckInterval = time.Minute
+)
+
+// ErrWALPositionGone is returned by WAL.Read if the log has moved on from the
+// requested position, e.g. because it was checkpointed and restarted.
+var ErrWALPositionGone = errors.New("database: write-ahead log has moved past position")
+
+func init() {
+	sql.Register("sqlite3-replicated", &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			_, err := conn.Exec(
+				`PRAGMA journal_mode = WAL; PRAGMA wal_autocheckpoint = 0`,
+				[]driver.Value{},
+			)
+			return err
+		},
+	})
+}
+
+// OpenReplicated is like Open, but prepares the database to be replicated to
+// standbys; see DB.WAL.
+func OpenReplicated() (DB, error) {
+	return OpenReplicatedPath(DBPath)
+}
+
+// OpenReplicatedPath is like OpenPath, but prepares the database to be
+// replicated to standbys; see DB.WAL.
+func OpenReplicatedPath(path string) (DB, error) {
+	sqlDB, err := sql.Open("sqlite3-replicated", path)
+	if err != nil {
+		return DB{}, err
+	}
+	db, err := InitDB(sqlDB)
+	if err != nil {
+		sqlDB.Close()
+		return DB{}, err
+	}
+	// SQLite deletes the log when the last connection to the database is
+	// closed, so keep one open for as long as we're using the log. It's also
+	// how WAL takes the write lock.
+	conn, err := sqlDB.Conn(context.Background())
+	if err != nil {
+		sqlDB.Close()
+		return DB{}, err
+	}
+	db.wal = &WAL{
+		sqlDB:          sqlDB,
+		conn:           conn,
+		dbPath:         path,
+		path:           path + "-wal",
+		checkpointSize: walCheckpointSize,
+		maxSize:        walMaxSize,
+		pos:            WALPosition{Log: tokenutil.Gen128Base64()},
+		checkpointed:   true,
+	}
+	return db, nil
+}
+
+// WAL returns the database's write-ahead log, or nil if the database wasn't
+// opened by OpenReplicated.
+func (db DB) WAL() *WAL {
+	return db.wal
+}
+
+// CheckpointFile copies the write-ahead log of the database at path, if any,
+// into the database file, and removes the log. The database must not be open.
+func CheckpointFile(path string) error {
+	sqlDB, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return err
+	}
+	_, err = sqlDB.Exec(`PRAGMA wal_checkpoint(TRUNCATE)`)
+	if closeErr := sqlDB.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		err := os.Remove(path + suffix)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// A WALPosition identifies a point in the write-ahead log of a database
+// opened by OpenReplicated.
+type WALPosition struct {
+	// Random identifier for the log, which changes each time the database
+	// is opened, and if a generation ends before WAL has read all of it.
+	Log string `json:"log"`
+
+	// The number of times the log has been restarted.
+	Generation int64 `json:"generation"`
+
+	// Offset in bytes from the start of the log file.
+	Offset int64 `json:"offset"`
+}
+
+// A WAL is the write-ahead log of a database, which can be shipped to
+// standbys.
+type WAL struct {
+	sqlDB          *sql.DB
+	conn           *sql.Conn
+	dbPath, path   string
+	checkpointSize int64
+	maxSize        int64
+
+	mu sync.Mutex
+
+	// The end of the last committed transaction in the log.
+	pos WALPosition
+
+	// Header of the current generation. Empty if the log file is empty.
+	hdr walHeader
+
+	// The log's checksum as of pos.
+	cksum [2]uint32
+
+	// Whether all of the current generation has been checkpointed, so that
+	// it may end at pos.
+	checkpointed bool
+
+	// Where the previous generation ended.
+	prevEnd int64
+}
+
+// ReadBase calls fn with the contents of the database file, and the position
+// in the log from which it must be brought up to date. The copy of the file
+// isn't consistent by itself, but it is once the log has been applied to it.
+func (w *WAL) ReadBase(ctx context.Context, fn func(WALPosition, io.Reader) error) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.lock(ctx); err != nil {
+		return err
+	}
+	pos := WALPosition{Log: w.pos.Log, Generation: w.pos.Generation}
+	// Only checkpoints write to the database file, and they can't happen
+	// while we hold w.mu, so writers can carry on while we copy it.
+	if err := w.unlock(); err != nil {
+		return err
+	}
+	f, err := os.Open(w.dbPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return fn(pos, f)
+}
+
+// Read returns up to max bytes of the log following pos, and the position at
+// which they belong. If this is in a later generation than pos, the reader
+// must checkpoint its copy of the log before writing them.
+//
+// Read runs a checkpoint if this leaves the reader with all of a long log.
+// It returns ErrWALPositionGone if pos is no longer in the log.
+func (w *WAL) Read(ctx context.Context, pos WALPosition, max int64) ([]byte, WALPosition, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.lock(ctx); err != nil {
+		return nil, pos, err
+	}
+	defer w.unlock()
+	switch {
+	case pos.Log != w.pos.Log:
+		return nil, pos, ErrWALPositionGone
+	case pos.Generation == w.pos.Generation && pos.Offset <= w.pos.Offset:
+	case pos.Generation == w.pos.Generation-1 && pos.Offset == w.prevEnd:
+		pos = WALPosition{Log: w.pos.Log, Generation: w.pos.Generation}
+	default:
+		return nil, pos, ErrWALPositionGone
+	}
+	n := w.pos.Offset - pos.Offset
+	if n > max {
+		n = max
+	}
+	buf := make([]byte, n)
+	if n > 0 {
+		f, err := os.Open(w.path)
+		if err != nil {
+			return nil, pos, err
+		}
+		defer f.Close()
+		if _, err = f.ReadAt(buf, pos.Offset); err != nil {
+			return nil, pos, err
+		}
+	}
+	if pos.Offset+n == w.pos.Offset && w.pos.Offset >= w.checkpointSize {
+		if err := w.checkpoint(); err != nil {
+			return nil, pos, err
+		}
+	}
+	return buf, pos, nil
+}
+
+// Serve runs a checkpoint whenever the log grows too long, until ctx is
+// canceled. Normally checkpoints are run by Read, but this keeps the log from
+// growing without bound if no standby is reading it.
+func (w *WAL) Serve(ctx context.Context, lg *slog.Logger) {
+	ticker := time.NewTicker(walCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := w.checkpointIfLong(ctx); err != nil && ctx.Err() == nil {
+			lg.Error("Checkpointing the write-ahead log", "error", err)
+		}
+	}
+}
+
+func (w *WAL) checkpointIfLong(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.lock(ctx); err != nil {
+		return err
+	}
+	defer w.unlock()
+	if w.pos.Offset < w.maxSize {
+		return nil
+	}
+	return w.checkpoint()
+}
+
+// lock takes the database's write lock, so that the log stops changing, and
+// then catches up with it. The caller must hold w.mu.
+func (w *WAL) lock(ctx context.Context) error {
+	if _, err := w.conn.ExecContext(ctx, `BEGIN IMMEDIATE`); err != nil {
+		return err
+	}
+	if err := w.refresh(); err != nil {
+		w.unlock()
+		return err
+	}
+	return nil
+}
+
+// unlock releases the write lock taken by lock.
+func (w *WAL) unlock() error {
+	_, err := w.conn.ExecContext(context.Background(), `ROLLBACK`)
+	return err
+}
+
+// checkpoint copies the log into the database file. The caller must hold the
+// write lock. If nothing keeps SQLite from copying all of it, the next write
+// starts a new generation.
+func (w *WAL) checkpoint() error {
+	var busy, frames, copied int
+	err := w.sqlDB.QueryRow(`PRAGMA wal_checkpoint(PASSIVE)`).Scan(&busy, &frames, &copied)
+	if err != nil {
+		return err
+	}
+	w.checkpointed = busy == 0 && frames == copied
+	return nil
+}
+
+// refresh brings w up to date with the log file. The caller must hold w.mu and
+// the write lock.
+func (w *WAL) refresh() error {
+	f, err := os.Open(w.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		w.startGeneration(walHeader{})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	buf := make([]byte, walHeaderSize)
+	if _, err := f.ReadAt(buf, 0); err != nil && err != io.EOF {
+		return err
+	}
+	hdr, _ := parseWALHeader(buf)
+	if hdr.salts != w.hdr.salts {
+		w.startGeneration(hdr)
+	}
+	if hdr == (walHeader{}) {
+		return nil
+	}
+	end, cksum, err := scanWAL(f, hdr, w.pos.Offset, w.cksum)
+	if err != nil {
+		return err
+	}
+	if end != w.pos.Offset {
+		w.checkpointed = false
+	}
+	w.pos.Offset, w.cksum = end, cksum
+	return nil
+}
+
+// startGeneration records that the log has been restarted with the header hdr,
+// which is empty if the log has been truncated.
+func (w *WAL) startGeneration(hdr walHeader) {
+	if hdr.salts == w.hdr.salts {
+		return
+	}
+	if w.checkpointed {
+		w.prevEnd = w.pos.Offset
+		w.pos.Generation++
+	} else {
+		// The log has been restarted without us seeing the end of the
+		// last generation, so nobody can pick up where they left off.
+		w.pos = WALPosition{Log: tokenutil.Gen128Base64()}
+		w.prevEnd = -1
+	}
+	w.hdr, w.cksum, w.pos.Offset, w.checkpointed = hdr, hdr.cksum, 0, true
+	if hdr != (walHeader{}) {
+		w.pos.Offset, w.checkpointed = walHeaderSize, false
+	}
+}
+
+// walHeader is the header of a write-ahead log.
+type walHeader struct {
+	bigEndian bool // Byte order of the words which are checksummed.
+	pageSize  int64
+	salts     [8]byte
+	cksum     [2]uint32
+}
+
+// parseWALHeader parses buf as a log header, and returns false if it isn't
+// one.
+func parseWALHeader(buf []byte) (walHeader, bool) {
+	if len(buf) < walHeaderSize {
+		return walHeader{}, false
+	}
+	magic := binary.BigEndian.Uint32(buf)
+	if magic&^1 != 0x377f0682 {
+		return walHeader{}, false
+	}
+	hdr := walHeader{
+		bigEndian: magic&1 == 1,
+		pageSize:  int64(binary.BigEndian.Uint32(buf[8:])),
+		cksum: [2]uint32{
+			binary.BigEndian.Uint32(buf[24:]),
+			binary.BigEndian.Uint32(buf[28:]),
+		},
+	}
+	if hdr.pageSize == 1 {
+		hdr.pageSize = 65536
+	}
+	copy(hdr.salts[:], buf[16:24])
+	if walChecksum(hdr.bigEndian, [2]uint32{}, buf[:24]) != hdr.cksum {
+		return walHeader{}, false
+	}
+	return hdr, true
+}
+
+// scanWAL reads the frames in f which follow off, where the log's checksum is
+// cksum, and returns the end of the last commit among them, with the
+// checksum there. It stops at the first frame which belongs to another
+// generation or hasn't been completely written, as SQLite does when it
+// recovers a log.
+func scanWAL(f io.ReaderAt, hdr walHeader, off int64, cksum [2]uint32) (int64, [2]uint32, error) {
+	end, endCksum := off, cksum
+	frame := make([]byte, walFrameHeaderSize+hdr.pageSize)
+	for {
+		_, err := f.ReadAt(frame, off)
+		if err == io.EOF {
+			return end, endCksum, nil
+		}
+		if err != nil {
+			return end, endCksum, err
+		}
+		if !bytes.Equal(frame[8:16], hdr.salts[:]) {
+			return end, endCksum, nil
+		}
+		cksum = walChecksum(hdr.bigEndian, cksum, frame[:8])
+		cksum = walChecksum(hdr.bigEndian, cksum, frame[walFrameHeaderSize:])
+		if cksum[0] != binary.BigEndian.Uint32(frame[16:]) ||
+			cksum[1] != binary.BigEndian.Uint32(frame[20:]) {
+			return end, endCksum, nil
+		}
+		off += int64(len(frame))
+		// A frame is the last of a commit if it records the size of the
+		// database afterwards.
+		if binary.BigEndian.Uint32(frame[4:]) != 0 {
+			end, endCksum = off, cksum
+		}
+	}
+}
+
+// walChecksum continues the checksum s over buf, whose length must be a
+// multiple of 8.
+func walChecksum(bigEndian bool, s [2]uint32, buf []byte) [2]uint32 {
+	var order binary.ByteOrder = binary.LittleEndian
+	if bigEndian {
+		order = binary.BigEndian
+	}
+	for i := 0; i+8 <= len(buf); i += 8 {
+		s[0] += order.Uint32(buf[i:]) + s[1]
+		s[1] += order.Uint32(buf[i+4:]) + s[0]
+	}
+	return s
+}
@@ -0,0 +1,173 @@
+package database
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStandby keeps a copy of a replicated database, the way a standby does.
+type testStandby struct {
+	t    *testing.T
+	path string
+	pos  WALPosition
+}
+
+func (s *testStandby) copyBase(w *WAL) {
+	require.NoError(s.t, w.ReadBase(context.Background(), func(pos WALPosition, r io.Reader) error {
+		os.Remove(s.path + "-wal")
+		data, err := io.ReadAll(r)
+		require.NoError(s.t, err)
+		s.pos = pos
+		return os.WriteFile(s.path, data, 0600)
+	}))
+}
+
+// catchUp copies the log until it has all of it, and returns the number of
+// times it had to checkpoint its copy.
+func (s *testStandby) catchUp(w *WAL) (int, error) {
+	checkpoints := 0
+	for {
+		data, at, err := w.Read(context.Background(), s.pos, 64<<10)
+		if err != nil {
+			return checkpoints, err
+		}
+		if at.Generation != s.pos.Generation {
+			require.NoError(s.t, CheckpointFile(s.path))
+			checkpoints++
+		}
+		f, err := os.OpenFile(s.path+"-wal", os.O_WRONLY|os.O_CREATE, 0600)
+		require.NoError(s.t, err)
+		_, err = f.WriteAt(data, at.Offset)
+		require.NoError(s.t, err)
+		require.NoError(s.t, f.Close())
+		s.pos = at
+		s.pos.Offset += int64(len(data))
+		if len(data) == 0 {
+			return checkpoints, nil
+		}
+	}
+}
+
+// settings opens a copy of the standby's database, and returns the server
+// settings stored in it.
+func (s *testStandby) settings() map[string]string {
+	dir := s.t.TempDir()
+	for _, suffix := range []string{"", "-wal"} {
+		data, err := os.ReadFile(s.path + suffix)
+		if os.IsNotExist(err) {
+			continue
+		}
+		require.NoError(s.t, err)
+		require.NoError(s.t, os.WriteFile(filepath.Join(dir, "db"+suffix), data, 0600))
+	}
+	db, err := OpenPath(filepath.Join(dir, "db"))
+	require.NoError(s.t, err)
+	defer db.Close()
+	tx, err := db.Begin()
+	require.NoError(s.t, err)
+	defer tx.Rollback()
+	ret, err := tx.ServerSettings()
+	require.NoError(s.t, err)
+	return ret
+}
+
+func setServerSetting(t *testing.T, db DB, name, value string) {
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	defer tx.Rollback()
+	require.NoError(t, tx.SetServerSettings(map[string]string{name: value}))
+	require.NoError(t, tx.Commit())
+}
+
+// Ship the log of a database to a standby as it is written, through a few
+// generations, and check that the standby's copy keeps up.
+func TestWAL(t *testing.T) {
+	dir := t.TempDir()
+	db, err := OpenReplicatedPath(filepath.Join(dir, "primary.sqlite3"))
+	require.NoError(t, err)
+	defer db.Close()
+	w := db.WAL()
+	require.NotNil(t, w)
+
+	setServerSetting(t, db, "A", "1")
+	standby := &testStandby{t: t, path: filepath.Join(dir, "standby.sqlite3")}
+	standby.copyBase(w)
+	n, err := standby.catchUp(w)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, map[string]string{"A": "1"}, standby.settings())
+
+	// Only the new transaction is shipped:
+	before := standby.pos
+	setServerSetting(t, db, "B", "2")
+	_, err = standby.catchUp(w)
+	require.NoError(t, err)
+	assert.Equal(t, before.Generation, standby.pos.Generation)
+	assert.Less(t, standby.pos.Offset-before.Offset, int64(64<<10))
+	assert.Equal(t, map[string]string{"A": "1", "B": "2"}, standby.settings())
+
+	// Once the standby has all of a long enough log, it is checkpointed, and
+	// the next write starts a new generation:
+	w.checkpointSize = 1
+	_, err = standby.catchUp(w)
+	require.NoError(t, err)
+	setServerSetting(t, db, "C", "3")
+	n, err = standby.catchUp(w)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, before.Generation+1, standby.pos.Generation)
+	assert.Equal(t, map[string]string{"A": "1", "B": "2", "C": "3"}, standby.settings())
+
+	// If the log ends a generation before a standby has all of it, the
+	// standby has to start over.
+	w.checkpointSize = walCheckpointSize
+	w.maxSize = 1
+	setServerSetting(t, db, "D", "4")
+	require.NoError(t, w.checkpointIfLong(context.Background()))
+	setServerSetting(t, db, "E", "5")
+	_, err = standby.catchUp(w)
+	assert.ErrorIs(t, err, ErrWALPositionGone)
+	standby.copyBase(w)
+	_, err = standby.catchUp(w)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"A": "1", "B": "2", "C": "3", "D": "4", "E": "5"},
+		standby.settings())
+}
+
+// Check that scanWAL stops at the last complete commit.
+func TestScanWAL(t *testing.T) {
+	dir := t.TempDir()
+	db, err := OpenReplicatedPath(filepath.Join(dir, "db.sqlite3"))
+	require.NoError(t, err)
+	defer db.Close()
+	setServerSetting(t, db, "A", "1")
+
+	data, err := os.ReadFile(filepath.Join(dir, "db.sqlite3-wal"))
+	require.NoError(t, err)
+	hdr, ok := parseWALHeader(data)
+	require.True(t, ok)
+	f, err := os.Open(filepath.Join(dir, "db.sqlite3-wal"))
+	require.NoError(t, err)
+	defer f.Close()
+	end, _, err := scanWAL(f, hdr, walHeaderSize, hdr.cksum)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(data)), end)
+
+	// Corrupt the last frame; everything after the previous commit should
+	// be ignored.
+	data[len(data)-1] ^= 0xff
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "corrupt-wal"), data, 0600))
+	corrupt, err := os.Open(filepath.Join(dir, "corrupt-wal"))
+	require.NoError(t, err)
+	defer corrupt.Close()
+	corruptEnd, _, err := scanWAL(corrupt, hdr, walHeaderSize, hdr.cksum)
+	require.NoError(t, err)
+	assert.Less(t, corruptEnd, end)
+	assert.Zero(t, (corruptEnd-walHeaderSize)%(walFrameHeaderSize+hdr.pageSize))
+}
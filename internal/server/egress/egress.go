@@ -0,0 +1,397 @@
+// Package egress implements the IpNetwork capability, through which grains
+// reach the network, and the IpInterface capability, through which the
+// network reaches them.
+//
+// As in Sandstorm, grains are confined: the sandbox gives each its own network
+// namespace, with nothing but a loopback interface, so a grain can't connect
+// anywhere by itself. A grain which needs the network, e.g. to fetch feeds or
+// talk to a mail server, must ask for an IpNetwork through the powerbox, and
+// an admin must grant it. Tempest then makes connections on the grain's
+// behalf, and relays their data over Cap'n Proto. Likewise, a grain which
+// serves the network, e.g. an IRC bouncer or a mail server, must be granted
+// an IpInterface, and Tempest listens on the grain's behalf; see
+// NewInterface.
+//
+// Connections to the server's own loopback, link-local and multicast
+// addresses are refused, so that a grain can't use the capability to reach
+// services on the server which aren't meant for it. The same goes for
+// PrivateHTTPClient, through which Tempest makes HTTP requests for grains.
+// HTTPClient, for requests which any user, rather than only an admin, may
+// allow, refuses private addresses and the server's own addresses too, so
+// that only the public internet can be reached through it, and not the
+// operator's network.
+package egress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"capnproto.org/go/capnp/v3/exc"
+	"golang.org/x/exp/slog"
+	"sandstorm.org/go/tempest/capnp/ip"
+	"sandstorm.org/go/tempest/capnp/util"
+	"sandstorm.org/go/tempest/pkg/exp/util/bytestream"
+	"zenhack.net/go/util/exn"
+	"zenhack.net/go/util/sync/mutex"
+)
+
+const (
+	// dialTimeout is how long connecting may take.
+	dialTimeout = 30 * time.Second
+
+	// httpTimeout is how long an HTTP request, including reading its
+	// response, may take.
+	httpTimeout = 2 * time.Minute
+
+	// maxDatagramSize is the largest UDP datagram.
+	maxDatagramSize = 65535
+)
+
+// errForbidden is returned when connecting to an address grains may not
+// reach.
+var errForbidden = errors.New("grains may not connect to this address")
+
+// New returns an IpNetwork which connects to hosts on the server's network,
+// logging connections to lg.
+func New(lg *slog.Logger) ip.IpNetwork {
+	return ip.IpNetwork_ServerToClient(newNetwork(lg, allowed))
+}
+
+// HTTPClient returns a client for making HTTP requests on grains' behalf,
+// which connects only to public addresses. It doesn't follow redirects, but
+// returns them, so that the grain may decide whether to.
+func HTTPClient() *http.Client {
+	return newHTTPClient(newNetwork(nil, allowedPublic))
+}
+
+// PrivateHTTPClient is like HTTPClient, but may also connect to private
+// addresses, as IpNetwork does, for requests which an admin allowed.
+func PrivateHTTPClient() *http.Client {
+	return newHTTPClient(newNetwork(nil, allowed))
+}
+
+func newHTTPClient(n *network) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy from the environment would make the connections instead,
+	// bypassing the dialer's checks:
+	transport.Proxy = nil
+	transport.DialContext = n.dialer.DialContext
+	return &http.Client{
+		Transport: transport,
+		Timeout:   httpTimeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// allowed reports whether grains may connect to addr.
+func allowed(addr netip.Addr) bool {
+	return !(addr.IsLoopback() ||
+		addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast())
+}
+
+// allowedPublic reports whether addr is on the public internet, rather than
+// a private network, e.g. 192.168.0.0/16 or fd00::/8, or one of the server's
+// own addresses, and allowed.
+func allowedPublic(addr netip.Addr) bool {
+	return allowed(addr) && !addr.IsPrivate() && !isServerAddress(addr)
+}
+
+// Public reports whether addr is one which HTTPClient may connect to.
+func Public(addr netip.Addr) bool {
+	return allowedPublic(addr)
+}
+
+// serverAddresses returns the addresses of the server's network interfaces.
+var serverAddresses = net.InterfaceAddrs
+
+// isServerAddress reports whether addr is one of the server's own. If the
+// server's addresses can't be listed, it reports true, to be safe.
+func isServerAddress(addr netip.Addr) bool {
+	addrs, err := serverAddresses()
+	if err != nil {
+		return true
+	}
+	for _, a := range addrs {
+		prefix, err := netip.ParsePrefix(a.String())
+		if err == nil && prefix.Addr().Unmap() == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// network implements ip.IpNetwork.
+type network struct {
+	log    *slog.Logger
+	dialer net.Dialer
+}
+
+// newNetwork returns a network which connects only to addresses for which
+// allow returns true.
+func newNetwork(lg *slog.Logger, allow func(netip.Addr) bool) *network {
+	return &network{
+		log: lg,
+		dialer: net.Dialer{
+			Timeout: dialTimeout,
+			// Check the address after resolving it, so that a
+			// name can't resolve to a forbidden address:
+			Control: func(_, address string, _ syscall.RawConn) error {
+				addrPort, err := netip.ParseAddrPort(address)
+				if err != nil {
+					return err
+				}
+				if !allow(addrPort.Addr().Unmap()) {
+					return errForbidden
+				}
+				return nil
+			},
+		},
+	}
+}
+
+func (n *network) GetRemoteHost(ctx context.Context, p ip.IpNetwork_getRemoteHost) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		addr, err := p.Args().Address()
+		throw(err)
+		var b [16]byte
+		for i := 0; i < 8; i++ {
+			b[i] = byte(addr.Upper64() >> (56 - 8*i))
+			b[8+i] = byte(addr.Lower64() >> (56 - 8*i))
+		}
+		results, err := p.AllocResults()
+		throw(err)
+		host := remoteHost{
+			network: n,
+			host:    netip.AddrFrom16(b).Unmap().String(),
+		}
+		throw(results.SetHost(ip.IpRemoteHost_ServerToClient(host)))
+	})
+}
+
+func (n *network) GetRemoteHostByName(ctx context.Context, p ip.IpNetwork_getRemoteHostByName) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		name, err := p.Args().Address()
+		throw(err)
+		if name == "" {
+			throw(exc.New(exc.Failed, "egress", "empty host name"))
+		}
+		results, err := p.AllocResults()
+		throw(err)
+		host := remoteHost{network: n, host: name}
+		throw(results.SetHost(ip.IpRemoteHost_ServerToClient(host)))
+	})
+}
+
+// remoteHost implements ip.IpRemoteHost. host is an IP address or a name,
+// which is resolved on each connection.
+type remoteHost struct {
+	network *network
+	host    string
+}
+
+func (h remoteHost) GetTcpPort(ctx context.Context, p ip.IpRemoteHost_getTcpPort) error {
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	port := tcpPort{
+		network: h.network,
+		address: net.JoinHostPort(h.host, strconv.Itoa(int(p.Args().PortNum()))),
+	}
+	return results.SetPort(ip.TcpPort_ServerToClient(port))
+}
+
+func (h remoteHost) GetUdpPort(ctx context.Context, p ip.IpRemoteHost_getUdpPort) error {
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	port := &udpPort{
+		network: h.network,
+		address: net.JoinHostPort(h.host, strconv.Itoa(int(p.Args().PortNum()))),
+	}
+	return results.SetPort(ip.UdpPort_ServerToClient(port))
+}
+
+// tcpPort implements ip.TcpPort.
+type tcpPort struct {
+	network *network
+	address string
+}
+
+func (port tcpPort) Connect(ctx context.Context, p ip.TcpPort_connect) error {
+	conn, err := port.network.dialer.DialContext(ctx, "tcp", port.address)
+	if err != nil {
+		return fmt.Errorf("connecting to %s: %w", port.address, err)
+	}
+	results, err := p.AllocResults()
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	port.network.log.Info("Grain connected to the network",
+		"address", port.address,
+		"remoteAddr", conn.RemoteAddr().String(),
+	)
+	stream := &connStream{conn: conn.(*net.TCPConn)}
+	go stream.relay(p.Args().Downstream().AddRef())
+	return results.SetUpstream(util.ByteStream_ServerToClient(stream))
+}
+
+// connStream implements util.ByteStream, writing to a TCP connection on the
+// grain's behalf: the upstream of a connection the grain made, or the
+// downstream of one it accepted.
+type connStream struct {
+	conn *net.TCPConn
+}
+
+// relay copies what the other end of the connection sends to dst, the
+// grain's side of the connection, until it closes its side.
+func (s *connStream) relay(dst util.ByteStream) {
+	defer dst.Release()
+	w := bytestream.ToWriteCloser(context.Background(), dst)
+	_, err := io.Copy(w, s.conn)
+	if err == nil {
+		w.Close()
+	}
+	s.conn.CloseRead()
+}
+
+func (s *connStream) Write(ctx context.Context, p util.ByteStream_write) error {
+	data, err := p.Args().Data()
+	if err != nil {
+		return err
+	}
+	_, err = s.conn.Write(data)
+	return err
+}
+
+func (s *connStream) Done(context.Context, util.ByteStream_done) error {
+	return s.conn.CloseWrite()
+}
+
+func (*connStream) ExpectSize(context.Context, util.ByteStream_expectSize) error {
+	return nil
+}
+
+// Shutdown closes the connection when the grain drops the stream, which it
+// does once it is finished with the connection in both directions.
+func (s *connStream) Shutdown() {
+	s.conn.Close()
+}
+
+// udpPort implements ip.UdpPort, sending datagrams to a remote port on the
+// grain's behalf, from a socket of its own. Replies are sent to the return
+// port of the latest datagram which had one.
+type udpPort struct {
+	network *network
+	address string
+	state   mutex.Mutex[udpPortState]
+}
+
+type udpPortState struct {
+	conn       net.Conn // nil until the first datagram is sent
+	returnPort ip.UdpPort
+	closed     bool
+}
+
+func (port *udpPort) Send(ctx context.Context, p ip.UdpPort_send) error {
+	msg, err := p.Args().Msg()
+	if err != nil {
+		return err
+	}
+	var conn net.Conn
+	port.state.With(func(state *udpPortState) {
+		if state.closed {
+			err = net.ErrClosed
+			return
+		}
+		if returnPort := p.Args().ReturnPort(); returnPort.IsValid() {
+			state.returnPort.Release()
+			state.returnPort = returnPort.AddRef()
+		}
+		if state.conn == nil {
+			state.conn, err = port.network.dialer.DialContext(ctx, "udp", port.address)
+			if err != nil {
+				err = fmt.Errorf("connecting to %s: %w", port.address, err)
+				return
+			}
+			port.network.log.Info("Grain sent a datagram to the network",
+				"address", port.address,
+				"remoteAddr", state.conn.RemoteAddr().String(),
+			)
+			go port.relayReplies(state.conn)
+		}
+		conn = state.conn
+	})
+	if err != nil {
+		return err
+	}
+	_, err = conn.Write(msg)
+	return err
+}
+
+// relayReplies sends the datagrams which conn receives to the return port,
+// until conn is closed.
+func (port *udpPort) relayReplies(conn net.Conn) {
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, err := conn.Read(buf)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		} else if err != nil {
+			// e.g. ICMP port unreachable; nothing to tell the grain.
+			continue
+		}
+		var returnPort ip.UdpPort
+		port.state.With(func(state *udpPortState) {
+			returnPort = state.returnPort.AddRef()
+		})
+		sendDatagram(returnPort, buf[:n], ip.UdpPort{})
+		returnPort.Release()
+	}
+}
+
+// Shutdown closes the socket when the grain drops the port.
+func (port *udpPort) Shutdown() {
+	port.state.With(func(state *udpPortState) {
+		state.closed = true
+		if state.conn != nil {
+			state.conn.Close()
+		}
+		state.returnPort.Release()
+	})
+}
+
+// sendDatagram sends msg to port, a grain's, waiting for it to be delivered
+// so that a grain which is slow to receive slows the sender, rather than
+// datagrams piling up. A port which is null or fails drops the datagram, as
+// the network might have.
+func sendDatagram(port ip.UdpPort, msg []byte, returnPort ip.UdpPort) {
+	if !port.IsValid() {
+		returnPort.Release()
+		return
+	}
+	fut, rel := port.Send(context.Background(), func(p ip.UdpPort_send_Params) error {
+		if err := p.SetMsg(msg); err != nil {
+			return err
+		}
+		return p.SetReturnPort(returnPort)
+	})
+	defer rel()
+	fut.Struct()
+}
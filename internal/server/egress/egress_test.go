@@ -0,0 +1,303 @@
+package egress
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+	"sandstorm.org/go/tempest/capnp/ip"
+	pkgip "sandstorm.org/go/tempest/pkg/exp/ip"
+)
+
+// echoServer listens on loopback, echoing what each client sends until it
+// closes its side of the connection.
+func echoServer(t *testing.T) *net.TCPAddr {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(conn, conn)
+				conn.Close()
+			}()
+		}
+	}()
+	return l.Addr().(*net.TCPAddr)
+}
+
+func tcpPortByName(t *testing.T, ctx context.Context, network ip.IpNetwork, host string, port int) ip.TcpPort {
+	res, rel := network.GetRemoteHostByName(ctx, func(p ip.IpNetwork_getRemoteHostByName_Params) error {
+		return p.SetAddress(host)
+	})
+	t.Cleanup(rel)
+	// Wait for each result, rather than pipelining, which local servers
+	// don't support well:
+	hostRes, err := res.Struct()
+	require.NoError(t, err)
+	portRes, rel := hostRes.Host().GetTcpPort(ctx, func(p ip.IpRemoteHost_getTcpPort_Params) error {
+		p.SetPortNum(uint16(port))
+		return nil
+	})
+	t.Cleanup(rel)
+	ret, err := portRes.Struct()
+	require.NoError(t, err)
+	return ret.Port()
+}
+
+func TestConnect(t *testing.T) {
+	ctx := context.Background()
+	addr := echoServer(t)
+	network := ip.IpNetwork_ServerToClient(newNetwork(slog.Default(), func(netip.Addr) bool {
+		return true
+	}))
+	defer network.Release()
+
+	conn := pkgip.ConnectTCP(ctx, tcpPortByName(t, ctx, network, "127.0.0.1", addr.Port))
+	_, err := conn.Write([]byte("hello"))
+	require.NoError(t, err)
+	buf := make([]byte, 5)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(buf))
+	require.NoError(t, conn.Close())
+}
+
+func TestConnectForbidden(t *testing.T) {
+	ctx := context.Background()
+	addr := echoServer(t)
+	network := New(slog.Default())
+	defer network.Release()
+
+	for _, host := range []string{"127.0.0.1", "localhost", "::1"} {
+		port := tcpPortByName(t, ctx, network, host, addr.Port)
+		res, rel := port.Connect(ctx, nil)
+		_, err := res.Struct()
+		rel()
+		assert.ErrorContains(t, err, errForbidden.Error(), host)
+	}
+}
+
+func TestHTTPClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Redirect(w, req, "/elsewhere", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	client := newHTTPClient(newNetwork(slog.Default(), func(netip.Addr) bool {
+		return true
+	}))
+	res, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusFound, res.StatusCode, "redirects aren't followed")
+
+	_, err = HTTPClient().Get(srv.URL)
+	assert.ErrorContains(t, err, errForbidden.Error())
+	_, err = PrivateHTTPClient().Get(srv.URL)
+	assert.ErrorContains(t, err, errForbidden.Error(), "loopback is refused even to admins' grants")
+}
+
+func TestAllowed(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"93.184.216.34": true,
+		"2606:2800::1":  true,
+		"10.0.0.1":      true,
+		"127.0.0.1":     false,
+		"::1":           false,
+		"0.0.0.0":       false,
+		"169.254.0.1":   false,
+		"fe80::1":       false,
+		"224.0.0.1":     false,
+		"::ffff:7f00:1": false,
+	} {
+		assert.Equal(t, want, allowed(netip.MustParseAddr(addr).Unmap()), addr)
+	}
+}
+
+func TestAllowedPublic(t *testing.T) {
+	defer func(f func() ([]net.Addr, error)) { serverAddresses = f }(serverAddresses)
+	serverAddresses = func() ([]net.Addr, error) {
+		return []net.Addr{
+			&net.IPNet{IP: net.ParseIP("127.0.0.1"), Mask: net.CIDRMask(8, 32)},
+			&net.IPNet{IP: net.ParseIP("203.0.113.7"), Mask: net.CIDRMask(24, 32)},
+			&net.IPNet{IP: net.ParseIP("2001:db8::7"), Mask: net.CIDRMask(64, 128)},
+		}, nil
+	}
+	for addr, want := range map[string]bool{
+		"93.184.216.34":  true,
+		"2606:2800::1":   true,
+		"203.0.113.8":    true,
+		"10.0.0.1":       false,
+		"172.16.5.4":     false,
+		"192.168.1.1":    false,
+		"fd12:3456::1":   false,
+		"127.0.0.1":      false,
+		"169.254.0.1":    false,
+		"203.0.113.7":    false,
+		"2001:db8::7":    false,
+		"::ffff:c0a8:11": false,
+	} {
+		assert.Equal(t, want, allowedPublic(netip.MustParseAddr(addr).Unmap()), addr)
+	}
+
+	serverAddresses = func() ([]net.Addr, error) {
+		return nil, errors.New("no interfaces")
+	}
+	assert.False(t, allowedPublic(netip.MustParseAddr("93.184.216.34")),
+		"without the server's addresses, nothing is public")
+}
+
+// chanPort implements ip.UdpPort, receiving datagrams on a channel.
+type chanPort chan []byte
+
+func (c chanPort) Send(ctx context.Context, p ip.UdpPort_send) error {
+	msg, err := p.Args().Msg()
+	if err != nil {
+		return err
+	}
+	c <- append([]byte(nil), msg...)
+	return nil
+}
+
+func TestUDP(t *testing.T) {
+	ctx := context.Background()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+	go func() {
+		buf := make([]byte, 100)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			conn.WriteTo(buf[:n], addr)
+		}
+	}()
+	network := ip.IpNetwork_ServerToClient(newNetwork(slog.Default(), func(netip.Addr) bool {
+		return true
+	}))
+	defer network.Release()
+	hostRes, rel := network.GetRemoteHostByName(ctx, func(p ip.IpNetwork_getRemoteHostByName_Params) error {
+		return p.SetAddress("127.0.0.1")
+	})
+	defer rel()
+	host, err := hostRes.Struct()
+	require.NoError(t, err)
+	portRes, rel := host.Host().GetUdpPort(ctx, func(p ip.IpRemoteHost_getUdpPort_Params) error {
+		p.SetPortNum(uint16(conn.LocalAddr().(*net.UDPAddr).Port))
+		return nil
+	})
+	defer rel()
+	port, err := portRes.Struct()
+	require.NoError(t, err)
+
+	replies := make(chanPort, 1)
+	sendRes, rel := port.Port().Send(ctx, func(p ip.UdpPort_send_Params) error {
+		if err := p.SetMsg([]byte("hello")); err != nil {
+			return err
+		}
+		return p.SetReturnPort(ip.UdpPort_ServerToClient(replies))
+	})
+	defer rel()
+	_, err = sendRes.Struct()
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(<-replies))
+}
+
+// echoPort implements ip.TcpPort, echoing what each client sends.
+type echoPort struct{}
+
+func (echoPort) Connect(ctx context.Context, p ip.TcpPort_connect) error {
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	return results.SetUpstream(p.Args().Downstream().AddRef())
+}
+
+// freePort returns a port on loopback which nothing is listening on.
+func freePort(t *testing.T, network string) uint16 {
+	if network == "udp" {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer conn.Close()
+		return uint16(conn.LocalAddr().(*net.UDPAddr).Port)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	return uint16(l.Addr().(*net.TCPAddr).Port)
+}
+
+func TestListenTcp(t *testing.T) {
+	ctx := context.Background()
+	iface := ip.IpInterface_ServerToClient(ipInterface{log: slog.Default(), host: "127.0.0.1"})
+	defer iface.Release()
+	portNum := freePort(t, "tcp")
+	res, rel := iface.ListenTcp(ctx, func(p ip.IpInterface_listenTcp_Params) error {
+		p.SetPortNum(portNum)
+		return p.SetPort(ip.TcpPort_ServerToClient(echoPort{}))
+	})
+	listening, err := res.Struct()
+	require.NoError(t, err)
+	handle := listening.Handle().AddRef()
+	rel()
+
+	conn, err := net.Dial("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(int(portNum))))
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("hello"))
+	require.NoError(t, err)
+	buf := make([]byte, 5)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(buf))
+	conn.Close()
+
+	// Dropping the handle stops listening:
+	handle.Release()
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(int(portNum))))
+		if err == nil {
+			conn.Close()
+		}
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestListenUdp(t *testing.T) {
+	ctx := context.Background()
+	iface := ip.IpInterface_ServerToClient(ipInterface{log: slog.Default(), host: "127.0.0.1"})
+	defer iface.Release()
+	portNum := freePort(t, "udp")
+	received := make(chanPort, 1)
+	res, rel := iface.ListenUdp(ctx, func(p ip.IpInterface_listenUdp_Params) error {
+		p.SetPortNum(portNum)
+		return p.SetPort(ip.UdpPort_ServerToClient(received))
+	})
+	defer rel()
+	_, err := res.Struct()
+	require.NoError(t, err)
+
+	conn, err := net.Dial("udp", net.JoinHostPort("127.0.0.1", strconv.Itoa(int(portNum))))
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(<-received))
+}
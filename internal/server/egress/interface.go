@@ -0,0 +1,150 @@
+package egress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"capnproto.org/go/capnp/v3/exc"
+	"golang.org/x/exp/slog"
+	"sandstorm.org/go/tempest/capnp/ip"
+	"sandstorm.org/go/tempest/capnp/util"
+	"sandstorm.org/go/tempest/pkg/exp/util/handle"
+)
+
+// acceptRetryDelay is how long to wait after failing to accept a connection,
+// e.g. for lack of file descriptors, before trying again.
+const acceptRetryDelay = 100 * time.Millisecond
+
+// NewInterface returns an IpInterface which listens on all of the server's
+// addresses, logging connections to lg. Listening stops when the grain drops
+// the handle it got for it.
+func NewInterface(lg *slog.Logger) ip.IpInterface {
+	return ip.IpInterface_ServerToClient(ipInterface{log: lg})
+}
+
+// ipInterface implements ip.IpInterface.
+type ipInterface struct {
+	log  *slog.Logger
+	host string // The address to listen on; empty for all of them.
+}
+
+func (i ipInterface) address(portNum uint16) string {
+	return net.JoinHostPort(i.host, strconv.Itoa(int(portNum)))
+}
+
+func (i ipInterface) ListenTcp(ctx context.Context, p ip.IpInterface_listenTcp) error {
+	port := p.Args().Port()
+	if !port.IsValid() {
+		return exc.New(exc.Failed, "egress", "no port to relay connections to")
+	}
+	l, err := net.Listen("tcp", i.address(p.Args().PortNum()))
+	if err != nil {
+		return fmt.Errorf("listening on TCP port %d: %w", p.Args().PortNum(), err)
+	}
+	results, err := p.AllocResults()
+	if err != nil {
+		l.Close()
+		return err
+	}
+	i.log.Info("Grain listening on the network",
+		"protocol", "tcp",
+		"address", l.Addr().String(),
+	)
+	go i.acceptTCP(l, port.AddRef())
+	return results.SetHandle(handle.CallbackHandle(func() { l.Close() }))
+}
+
+// acceptTCP relays the connections l accepts to port, until l is closed.
+func (i ipInterface) acceptTCP(l net.Listener, port ip.TcpPort) {
+	defer port.Release()
+	for {
+		conn, err := l.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		} else if err != nil {
+			i.log.Error("Accepting a connection for a grain", "error", err)
+			time.Sleep(acceptRetryDelay)
+			continue
+		}
+		i.log.Info("Grain accepted a connection from the network",
+			"address", l.Addr().String(),
+			"remoteAddr", conn.RemoteAddr().String(),
+		)
+		go serveTCP(conn.(*net.TCPConn), port.AddRef())
+	}
+}
+
+// serveTCP hands conn to the grain's port, and relays its data until the
+// remote host closes its side.
+func serveTCP(conn *net.TCPConn, port ip.TcpPort) {
+	defer port.Release()
+	stream := &connStream{conn: conn}
+	fut, rel := port.Connect(context.Background(), func(p ip.TcpPort_connect_Params) error {
+		return p.SetDownstream(util.ByteStream_ServerToClient(stream))
+	})
+	defer rel()
+	res, err := fut.Struct()
+	if err != nil {
+		conn.Close()
+		return
+	}
+	stream.relay(res.Upstream().AddRef())
+}
+
+func (i ipInterface) ListenUdp(ctx context.Context, p ip.IpInterface_listenUdp) error {
+	port := p.Args().Port()
+	if !port.IsValid() {
+		return exc.New(exc.Failed, "egress", "no port to relay datagrams to")
+	}
+	conn, err := net.ListenPacket("udp", i.address(p.Args().PortNum()))
+	if err != nil {
+		return fmt.Errorf("listening on UDP port %d: %w", p.Args().PortNum(), err)
+	}
+	results, err := p.AllocResults()
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	i.log.Info("Grain listening on the network",
+		"protocol", "udp",
+		"address", conn.LocalAddr().String(),
+	)
+	go receiveUDP(conn, port.AddRef())
+	return results.SetHandle(handle.CallbackHandle(func() { conn.Close() }))
+}
+
+// receiveUDP relays the datagrams conn receives to port, with a return port
+// for replying to their senders, until conn is closed.
+func receiveUDP(conn net.PacketConn, port ip.UdpPort) {
+	defer port.Release()
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		} else if err != nil {
+			continue
+		}
+		sendDatagram(port, buf[:n], ip.UdpPort_ServerToClient(replyPort{conn: conn, addr: addr}))
+	}
+}
+
+// replyPort implements ip.UdpPort, sending replies to the sender of a
+// datagram which a grain received, from the socket which received it.
+type replyPort struct {
+	conn net.PacketConn
+	addr net.Addr
+}
+
+func (r replyPort) Send(ctx context.Context, p ip.UdpPort_send) error {
+	msg, err := p.Args().Msg()
+	if err != nil {
+		return err
+	}
+	_, err = r.conn.WriteTo(msg, r.addr)
+	return err
+}
@@ -10,4 +10,5 @@ import (
 //go:embed *.css
 //go:embed _dev/*
 //go:embed icons/*
+//go:embed assets
 var Content embed.FS
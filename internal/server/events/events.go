@@ -0,0 +1,45 @@
+// Package events is a bus for notable things which happen to grains.
+//
+// Code where something happens, e.g. starting a grain, publishes an event on
+// the server's Bus, without needing to know who is interested; features like
+// the grain timeline subscribe to the events they care about.
+package events
+
+import (
+	"sync"
+	"time"
+
+	"sandstorm.org/go/tempest/internal/common/types"
+)
+
+// A Bus delivers published events to its subscribers. The zero value is an
+// empty Bus, ready to use.
+type Bus struct {
+	mu          sync.Mutex
+	subscribers []func(types.GrainEvent)
+}
+
+// Subscribe arranges for f to be called with every event published after
+// Subscribe returns.
+func (b *Bus) Subscribe(f func(types.GrainEvent)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers, f)
+}
+
+// Publish delivers e to each subscriber, in the order they subscribed,
+// setting e.Time to now if it is zero.
+//
+// Subscribers are called synchronously, so callers should not hold locks
+// which subscribers might need, and subscribers should not block for long.
+func (b *Bus) Publish(e types.GrainEvent) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	b.mu.Lock()
+	subscribers := b.subscribers
+	b.mu.Unlock()
+	for _, f := range subscribers {
+		f(e)
+	}
+}
@@ -0,0 +1,26 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"sandstorm.org/go/tempest/internal/common/types"
+)
+
+func TestPublish(t *testing.T) {
+	var bus Bus
+	var first, second []types.GrainEvent
+	bus.Subscribe(func(e types.GrainEvent) { first = append(first, e) })
+
+	when := time.Unix(1700000000, 0)
+	bus.Publish(types.GrainEvent{GrainID: "grain1", Kind: types.GrainCreated, Time: when})
+	bus.Subscribe(func(e types.GrainEvent) { second = append(second, e) })
+	bus.Publish(types.GrainEvent{GrainID: "grain1", Kind: types.GrainStarted})
+
+	require.Equal(t, 2, len(first))
+	require.Equal(t, 1, len(second), "subscribers only see events published after subscribing")
+	require.Equal(t, when, first[0].Time, "a set Time is kept")
+	require.False(t, first[1].Time.IsZero(), "a zero Time is set when published")
+	require.Equal(t, first[1], second[0])
+}
@@ -0,0 +1,126 @@
+// Package forwarded supports running behind a reverse proxy, such as nginx
+// or Caddy, by trusting the X-Forwarded-* headers it sets.
+//
+// Behind a proxy, a request's remote address is the proxy's, and it may
+// arrive over plain HTTP even though the client used HTTPS. The proxy passes
+// on the real values in the X-Forwarded-For, X-Forwarded-Proto and
+// X-Forwarded-Host headers. Since clients can set those headers too, they are
+// only believed in requests from addresses we are told to trust.
+package forwarded
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// ParseTrusted parses a comma-separated list of IP addresses and CIDR
+// prefixes, e.g. "127.0.0.1, 10.0.0.0/8". An empty string yields an empty
+// list, trusting no one.
+func ParseTrusted(s string) ([]netip.Prefix, error) {
+	var ret []netip.Prefix
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if strings.Contains(item, "/") {
+			prefix, err := netip.ParsePrefix(item)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(item)
+		if err != nil {
+			return nil, fmt.Errorf("%q is neither an IP address nor a CIDR prefix", item)
+		}
+		ret = append(ret, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return ret, nil
+}
+
+// Handler returns a handler which sets req.URL.Scheme, which the server
+// leaves empty, to "https" or "http", and passes requests on to next.
+//
+// In requests from a trusted address, the client's address, the scheme and
+// the host are taken from the X-Forwarded-For, X-Forwarded-Proto and
+// X-Forwarded-Host headers, where present, and replace req.RemoteAddr,
+// req.URL.Scheme and req.Host respectively.
+func Handler(trusted []netip.Prefix, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		req = req.Clone(req.Context())
+		req.URL.Scheme = "http"
+		if req.TLS != nil {
+			req.URL.Scheme = "https"
+		}
+		if isTrusted(trusted, req.RemoteAddr) {
+			if client := clientAddr(trusted, req.Header.Values("X-Forwarded-For")); client != "" {
+				req.RemoteAddr = client
+			}
+			switch proto := strings.ToLower(last(req.Header.Values("X-Forwarded-Proto"))); proto {
+			case "http", "https":
+				req.URL.Scheme = proto
+			}
+			if host := last(req.Header.Values("X-Forwarded-Host")); host != "" {
+				req.Host = host
+			}
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// isTrusted reports whether addr, a host and optional port, is in trusted.
+func isTrusted(trusted []netip.Prefix, addr string) bool {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return false
+	}
+	ip = ip.Unmap()
+	for _, prefix := range trusted {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientAddr returns the client's address from X-Forwarded-For headers.
+// Each proxy appends the address it received the request from, so the
+// client is the rightmost address which isn't a trusted proxy; anything to
+// its left may have been made up by the client. If every address is
+// trusted, the leftmost is the client. It returns "" if there are no valid
+// addresses.
+func clientAddr(trusted []netip.Prefix, headers []string) string {
+	var addrs []string
+	for _, h := range headers {
+		for _, addr := range strings.Split(h, ",") {
+			addrs = append(addrs, strings.TrimSpace(addr))
+		}
+	}
+	for i := len(addrs) - 1; i >= 0; i-- {
+		if _, err := netip.ParseAddr(addrs[i]); err != nil {
+			return ""
+		}
+		if i == 0 || !isTrusted(trusted, addrs[i]) {
+			return addrs[i]
+		}
+	}
+	return ""
+}
+
+// last returns the rightmost value of a comma-separated list header. Any
+// others were passed on from further away, i.e. are less trustworthy.
+func last(headers []string) string {
+	if len(headers) == 0 {
+		return ""
+	}
+	values := strings.Split(headers[len(headers)-1], ",")
+	return strings.TrimSpace(values[len(values)-1])
+}
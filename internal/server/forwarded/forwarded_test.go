@@ -0,0 +1,89 @@
+package forwarded
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTrusted(t *testing.T) {
+	trusted, err := ParseTrusted(" 127.0.0.1, 10.1.2.3/8,::1,")
+	require.NoError(t, err)
+	require.Len(t, trusted, 3)
+	assert.Equal(t, "127.0.0.1/32", trusted[0].String())
+	assert.Equal(t, "10.0.0.0/8", trusted[1].String())
+	assert.Equal(t, "::1/128", trusted[2].String())
+
+	trusted, err = ParseTrusted("")
+	require.NoError(t, err)
+	assert.Empty(t, trusted)
+
+	_, err = ParseTrusted("localhost")
+	assert.Error(t, err)
+	_, err = ParseTrusted("10.0.0.0/33")
+	assert.Error(t, err)
+}
+
+// serve passes req through Handler, returning the request next saw.
+func serve(t *testing.T, trusted string, req *http.Request) *http.Request {
+	prefixes, err := ParseTrusted(trusted)
+	require.NoError(t, err)
+	var got *http.Request
+	Handler(prefixes, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		got = req
+	})).ServeHTTP(httptest.NewRecorder(), req)
+	require.NotNil(t, got)
+	return got
+}
+
+func newRequest(remoteAddr string, headers ...string) *http.Request {
+	req := httptest.NewRequest("GET", "http://example.com/path", nil)
+	req.URL.Scheme = ""
+	req.RemoteAddr = remoteAddr
+	for i := 0; i < len(headers); i += 2 {
+		req.Header.Add(headers[i], headers[i+1])
+	}
+	return req
+}
+
+func TestHandler(t *testing.T) {
+	headers := []string{
+		"X-Forwarded-For", "203.0.113.7",
+		"X-Forwarded-Proto", "https",
+		"X-Forwarded-Host", "ui-abc.tempest.example",
+	}
+
+	got := serve(t, "", newRequest("127.0.0.1:1234"))
+	assert.Equal(t, "http", got.URL.Scheme)
+
+	got = serve(t, "", newRequest("127.0.0.1:1234", headers...))
+	assert.Equal(t, "127.0.0.1:1234", got.RemoteAddr, "untrusted headers are ignored")
+	assert.Equal(t, "http", got.URL.Scheme)
+	assert.Equal(t, "example.com", got.Host)
+
+	got = serve(t, "127.0.0.0/8", newRequest("127.0.0.1:1234", headers...))
+	assert.Equal(t, "203.0.113.7", got.RemoteAddr)
+	assert.Equal(t, "https", got.URL.Scheme)
+	assert.Equal(t, "ui-abc.tempest.example", got.Host)
+
+	got = serve(t, "127.0.0.0/8", newRequest("[::ffff:127.0.0.1]:1234", headers...))
+	assert.Equal(t, "203.0.113.7", got.RemoteAddr, "IPv4-mapped addresses are trusted")
+
+	got = serve(t, "127.0.0.1", newRequest("127.0.0.1:1234",
+		"X-Forwarded-Proto", "gopher"))
+	assert.Equal(t, "http", got.URL.Scheme, "unknown schemes are ignored")
+}
+
+func TestClientAddr(t *testing.T) {
+	trusted, err := ParseTrusted("10.0.0.0/8")
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.7", clientAddr(trusted, []string{"198.51.100.1, 203.0.113.7, 10.0.0.2"}),
+		"the client can't spoof addresses to the left of the proxies'")
+	assert.Equal(t, "203.0.113.7", clientAddr(trusted, []string{"198.51.100.1", "203.0.113.7, 10.0.0.2"}))
+	assert.Equal(t, "10.0.0.3", clientAddr(trusted, []string{"10.0.0.3, 10.0.0.2"}))
+	assert.Equal(t, "", clientAddr(trusted, []string{"junk, 10.0.0.2"}))
+	assert.Equal(t, "", clientAddr(trusted, nil))
+}
@@ -13,12 +13,14 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strconv"
 
 	"capnproto.org/go/capnp/v3"
 	"golang.org/x/exp/slog"
 	spk "sandstorm.org/go/tempest/capnp/package"
 	grainagent "sandstorm.org/go/tempest/internal/capnp/grain-agent"
 	"sandstorm.org/go/tempest/internal/server/logging"
+	"sandstorm.org/go/tempest/pkg/exp/appenv"
 	"zenhack.net/go/util"
 )
 
@@ -68,7 +70,7 @@ func parseCmd(cmd spk.Manifest_Command) (Command, error) {
 	}
 	return Command{
 		Args: args,
-		Env:  env,
+		Env:  appenv.Environ(env),
 	}, nil
 
 }
@@ -101,6 +103,15 @@ func Main() {
 		cmd, err := manifest.ContinueCommand()
 		util.Chkfatal(err)
 		spawnSpkCmd(lg, appTitleText, cmd)
+	case grainagent.LaunchCommand_Which_upgradeGrain:
+		cmd, err := manifest.ContinueCommand()
+		util.Chkfatal(err)
+		previous := strconv.FormatUint(uint64(launchCmd.UpgradeGrain()), 10)
+		lg.Info("Grain was upgraded; the app should migrate its data",
+			"previousAppVersion", previous,
+			"appVersion", manifest.AppVersion(),
+		)
+		spawnSpkCmd(lg, appTitleText, cmd, appenv.EnvPreviousAppVersion+"="+previous)
 	case grainagent.LaunchCommand_Which_initGrain:
 		index := launchCmd.InitGrain()
 		actions, err := manifest.Actions()
@@ -118,9 +129,12 @@ func Main() {
 	}
 }
 
-func spawnSpkCmd(lg *slog.Logger, appTitle string, spkCmd spk.Manifest_Command) {
+// spawnSpkCmd runs the app's command, with extraEnv added to its environment,
+// and exits when it does.
+func spawnSpkCmd(lg *slog.Logger, appTitle string, spkCmd spk.Manifest_Command, extraEnv ...string) {
 	cmd, err := parseCmd(spkCmd)
 	util.Chkfatal(err)
+	cmd.Env = append(cmd.Env, extraEnv...)
 
 	lg.Info("Starting up app",
 		"appTitle", appTitle,
@@ -0,0 +1,296 @@
+// Package grainlog keeps the output of grains' processes in their storage
+// directories, so that app developers can read it without access to the
+// server.
+//
+// Each grain's log is two files: FileName, to which output is appended, and
+// OldFileName, which holds the output before that. When FileName would grow
+// past half of the size cap, it replaces OldFileName, and a new FileName is
+// started. So a grain's log never takes more than the cap, and at least the
+// most recent half of the cap's worth of output is always kept. This is the
+// same FileName that Sandstorm uses, so grain backups include it.
+package grainlog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"sandstorm.org/go/tempest/internal/common/types"
+)
+
+const (
+	FileName    = "log"
+	OldFileName = "log.1"
+)
+
+// Logs keeps the logs of the grains stored in a directory, each in the
+// subdirectory named by the grain's ID, as in config.GrainsDir.
+type Logs struct {
+	dir     string
+	maxSize int64
+
+	mu     sync.Mutex
+	grains map[types.GrainID]*grainLog
+}
+
+// grainLog is the state of a grain's log which its writers and followers
+// share. It is kept while any of them use it.
+type grainLog struct {
+	refs    int
+	writers int
+
+	// FileName, open for appending while there are writers; otherwise
+	// nil.
+	f *os.File
+
+	// The size of FileName, while f is open.
+	size int64
+
+	// How many times FileName has been replaced, since the grainLog was
+	// made; followers use this to notice that what they were reading has
+	// moved to OldFileName.
+	rotations int
+
+	// Closed and replaced whenever the log changes.
+	changed chan struct{}
+}
+
+// New returns the Logs of the grains stored in dir, each of which is capped at
+// maxSize bytes.
+func New(dir string, maxSize int64) *Logs {
+	return &Logs{
+		dir:     dir,
+		maxSize: maxSize,
+		grains:  make(map[types.GrainID]*grainLog),
+	}
+}
+
+func (l *Logs) path(grainID types.GrainID, name string) string {
+	return filepath.Join(l.dir, string(grainID), name)
+}
+
+// acquire returns the grain's shared state, making it if need be. l.mu must be
+// held, and the state released when done with.
+func (l *Logs) acquire(grainID types.GrainID) *grainLog {
+	g, ok := l.grains[grainID]
+	if !ok {
+		g = &grainLog{changed: make(chan struct{})}
+		l.grains[grainID] = g
+	}
+	g.refs++
+	return g
+}
+
+// release undoes acquire. l.mu must be held.
+func (l *Logs) release(grainID types.GrainID, g *grainLog) {
+	g.refs--
+	if g.refs == 0 {
+		delete(l.grains, grainID)
+	}
+}
+
+// Writer returns a writer which appends to the grain's log, until it is
+// closed. Write never fails, so that a broken log doesn't stop the output
+// from reaching other writers; output which can't be written is dropped.
+func (l *Logs) Writer(grainID types.GrainID) io.WriteCloser {
+	return &writer{logs: l, grainID: grainID}
+}
+
+type writer struct {
+	logs    *Logs
+	grainID types.GrainID
+	g       *grainLog // Acquired by the first write.
+	closed  bool
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	l := w.logs
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if w.closed {
+		return 0, fs.ErrClosed
+	}
+	if w.g == nil {
+		w.g = l.acquire(w.grainID)
+		w.g.writers++
+	}
+	g := w.g
+	if g.f == nil && l.open(w.grainID, g) != nil {
+		return len(p), nil
+	}
+	half := l.maxSize / 2
+	data := p
+	if int64(len(data)) > half {
+		data = data[int64(len(data))-half:]
+	}
+	if g.size > 0 && g.size+int64(len(data)) > half {
+		g.f.Close()
+		g.f = nil
+		err := os.Rename(l.path(w.grainID, FileName), l.path(w.grainID, OldFileName))
+		g.rotations++
+		if err != nil || l.open(w.grainID, g) != nil {
+			return len(p), nil
+		}
+	}
+	n, _ := g.f.Write(data)
+	g.size += int64(n)
+	close(g.changed)
+	g.changed = make(chan struct{})
+	return len(p), nil
+}
+
+// open opens the grain's FileName for appending. l.mu must be held.
+func (l *Logs) open(grainID types.GrainID, g *grainLog) error {
+	f, err := os.OpenFile(l.path(grainID, FileName), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	g.f = f
+	g.size = fi.Size()
+	return nil
+}
+
+func (w *writer) Close() error {
+	l := w.logs
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if w.closed {
+		return fs.ErrClosed
+	}
+	w.closed = true
+	g := w.g
+	if g == nil {
+		return nil
+	}
+	var err error
+	g.writers--
+	if g.writers == 0 && g.f != nil {
+		err = g.f.Close()
+		g.f = nil
+	}
+	l.release(w.grainID, g)
+	return err
+}
+
+// Tail returns up to the last n bytes of the grain's log. A grain which has
+// no log yet has an empty one.
+func (l *Logs) Tail(grainID types.GrainID, n int64) ([]byte, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.tail(grainID, n)
+}
+
+// tail is like Tail, but l.mu must be held.
+func (l *Logs) tail(grainID types.GrainID, n int64) ([]byte, error) {
+	cur, err := readFrom(l.path(grainID, FileName), 0)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(cur)) >= n {
+		return cur[int64(len(cur))-n:], nil
+	}
+	old, err := readFrom(l.path(grainID, OldFileName), 0)
+	if err != nil {
+		return nil, err
+	}
+	if rest := n - int64(len(cur)); int64(len(old)) > rest {
+		old = old[int64(len(old))-rest:]
+	}
+	return append(old, cur...), nil
+}
+
+// Follow writes up to the last n bytes of the grain's log to w, and then the
+// grain's output as it comes, until ctx is done or writing fails.
+func (l *Logs) Follow(ctx context.Context, grainID types.GrainID, n int64, w io.Writer) error {
+	l.mu.Lock()
+	g := l.acquire(grainID)
+	buf, err := l.tail(grainID, n)
+	rotations := g.rotations
+	offset, sizeErr := l.size(grainID, g)
+	changed := g.changed
+	l.mu.Unlock()
+	defer func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.release(grainID, g)
+	}()
+	if err == nil {
+		err = sizeErr
+	}
+	for err == nil {
+		if _, err = w.Write(buf); err != nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		}
+		l.mu.Lock()
+		buf, err = l.readSince(grainID, g, rotations, offset)
+		rotations = g.rotations
+		offset = g.size
+		changed = g.changed
+		l.mu.Unlock()
+	}
+	return err
+}
+
+// size returns the size of the grain's FileName. l.mu must be held.
+func (l *Logs) size(grainID types.GrainID, g *grainLog) (int64, error) {
+	if g.f != nil {
+		return g.size, nil
+	}
+	fi, err := os.Stat(l.path(grainID, FileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+// readSince returns what was written to the grain's log since FileName had
+// been replaced the given number of times, and was offset bytes long. l.mu
+// must be held, and the log must have a writer, as it does when it changes.
+func (l *Logs) readSince(grainID types.GrainID, g *grainLog, rotations int, offset int64) ([]byte, error) {
+	if g.rotations == rotations {
+		return readFrom(l.path(grainID, FileName), offset)
+	}
+	if g.rotations > rotations+1 {
+		// What was at offset is gone; start from the oldest output
+		// there is.
+		offset = 0
+	}
+	old, err := readFrom(l.path(grainID, OldFileName), offset)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := readFrom(l.path(grainID, FileName), 0)
+	return append(old, cur...), err
+}
+
+// readFrom returns the contents of the file at path from offset onwards. A
+// missing file is empty.
+func readFrom(path string, offset int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(f)
+}
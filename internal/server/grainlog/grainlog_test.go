@@ -0,0 +1,128 @@
+package grainlog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogs(t *testing.T, maxSize int64) *Logs {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "grain1"), 0700))
+	return New(dir, maxSize)
+}
+
+// The log never takes more than the cap, and keeps the most recent output.
+func TestRotate(t *testing.T) {
+	logs := newTestLogs(t, 20)
+	w := logs.Writer("grain1")
+	for _, line := range []string{"one\n", "two\n", "three\n", "four\n", "five\n"} {
+		n, err := io.WriteString(w, line)
+		require.NoError(t, err)
+		require.Equal(t, len(line), n)
+	}
+	require.NoError(t, w.Close())
+
+	var total int64
+	for _, name := range []string{FileName, OldFileName} {
+		fi, err := os.Stat(filepath.Join(logs.dir, "grain1", name))
+		require.NoError(t, err)
+		assert.LessOrEqual(t, fi.Size(), int64(10), name)
+		total += fi.Size()
+	}
+	assert.LessOrEqual(t, total, int64(20))
+
+	tail, err := logs.Tail("grain1", 100)
+	require.NoError(t, err)
+	assert.Equal(t, "three\nfour\nfive\n", string(tail))
+	tail, err = logs.Tail("grain1", 7)
+	require.NoError(t, err)
+	assert.Equal(t, "r\nfive\n", string(tail))
+}
+
+// Output longer than half the cap keeps its end.
+func TestWriteTooLong(t *testing.T) {
+	logs := newTestLogs(t, 8)
+	w := logs.Writer("grain1")
+	defer w.Close()
+	n, err := io.WriteString(w, "0123456789")
+	require.NoError(t, err)
+	assert.Equal(t, 10, n)
+	tail, err := logs.Tail("grain1", 100)
+	require.NoError(t, err)
+	assert.Equal(t, "6789", string(tail))
+}
+
+// A grain without a log has an empty one, and writers which never write
+// don't make one.
+func TestNoLog(t *testing.T) {
+	logs := newTestLogs(t, 100)
+	require.NoError(t, logs.Writer("grain1").Close())
+	tail, err := logs.Tail("grain1", 100)
+	require.NoError(t, err)
+	assert.Empty(t, tail)
+	_, err = os.Stat(filepath.Join(logs.dir, "grain1", FileName))
+	assert.True(t, os.IsNotExist(err))
+	assert.Empty(t, logs.grains)
+}
+
+// safeBuffer is a bytes.Buffer which may be written and read concurrently.
+type safeBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *safeBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *safeBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// Following the log sends the tail, then new output, including across
+// rotations.
+func TestFollow(t *testing.T) {
+	logs := newTestLogs(t, 20)
+	w := logs.Writer("grain1")
+	defer w.Close()
+	io.WriteString(w, "before\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var out safeBuffer
+	done := make(chan error)
+	go func() {
+		done <- logs.Follow(ctx, "grain1", 3, &out)
+	}()
+	waitFor := func(want string) {
+		t.Helper()
+		require.Eventually(t, func() bool {
+			return out.String() == want
+		}, time.Second, time.Millisecond, "got %q", out.String())
+	}
+	waitFor("re\n")
+
+	io.WriteString(w, "one\n")
+	waitFor("re\none\n")
+	// Rotates:
+	io.WriteString(w, "two\n")
+	waitFor("re\none\ntwo\n")
+	io.WriteString(w, strings.Repeat("x", 3))
+	waitFor("re\none\ntwo\nxxx")
+
+	cancel()
+	require.NoError(t, <-done)
+}
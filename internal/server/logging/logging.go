@@ -2,17 +2,45 @@
 package logging
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
 	"os"
 
 	"golang.org/x/exp/slog"
 )
 
+// Log formats, as accepted by New.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
 // Create a new logger with our preferred settings.
 func NewLogger() *slog.Logger {
+	lg, _ := New(os.Stdout, FormatText)
+	return lg
+}
+
+// New creates a logger with our preferred settings, which writes to w in the
+// given format. Records logged with a context, e.g. by Logger.InfoCtx, include
+// the attributes added to the context by With.
+func New(w io.Writer, format string) (*slog.Logger, error) {
 	opts := slog.HandlerOptions{
 		Level: slog.LevelDebug,
 	}
-	return slog.New(opts.NewTextHandler(os.Stdout))
+	var h slog.Handler
+	switch format {
+	case FormatText:
+		h = opts.NewTextHandler(w)
+	case FormatJSON:
+		h = opts.NewJSONHandler(w)
+	default:
+		return nil, fmt.Errorf("unknown log format %q; must be %q or %q",
+			format, FormatText, FormatJSON)
+	}
+	return slog.New(contextHandler{h}), nil
 }
 
 // Log a message and then panic
@@ -21,3 +49,94 @@ func Panic(l *slog.Logger, msg string, args ...any) {
 	l.Error(msg, args...)
 	panic(msg)
 }
+
+type attrsKey struct{}
+
+// With returns a copy of ctx which adds args, which are key-value pairs as for
+// slog.Logger.With, to records logged with it. This is how records are
+// tagged with e.g. the ID of the request they're about, without threading a
+// logger through everything.
+func With(ctx context.Context, args ...any) context.Context {
+	prev, _ := ctx.Value(attrsKey{}).([]any)
+	attrs := make([]any, 0, len(prev)+len(args))
+	attrs = append(append(attrs, prev...), args...)
+	return context.WithValue(ctx, attrsKey{}, attrs)
+}
+
+// A contextHandler adds the attributes in records' contexts before passing
+// them on.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if attrs, ok := ctx.Value(attrsKey{}).([]any); ok {
+			r = r.Clone()
+			r.Add(attrs...)
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// Lines longer than this are logged in pieces by a LineWriter.
+const maxLineLength = 16 * 1024
+
+// A LineWriter logs each line written to it as a record, with the line as
+// its "output" attribute. It is for capturing the output of other programs.
+type LineWriter struct {
+	log   *slog.Logger
+	level slog.Level
+	msg   string
+	buf   []byte
+}
+
+// NewLineWriter returns a LineWriter which logs lines to lg, at the given
+// level and with the given message.
+func NewLineWriter(lg *slog.Logger, level slog.Level, msg string) *LineWriter {
+	return &LineWriter{
+		log:   lg,
+		level: level,
+		msg:   msg,
+	}
+}
+
+func (w *LineWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			w.buf = append(w.buf, p...)
+			if len(w.buf) >= maxLineLength {
+				w.flush()
+			}
+			break
+		}
+		w.buf = append(w.buf, p[:i]...)
+		w.flush()
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// Close logs the last line, if it wasn't terminated by a newline.
+func (w *LineWriter) Close() error {
+	if len(w.buf) > 0 {
+		w.flush()
+	}
+	return nil
+}
+
+func (w *LineWriter) flush() {
+	w.log.Log(context.Background(), w.level, w.msg,
+		"output", string(bytes.TrimSuffix(w.buf, []byte{'\r'})))
+	w.buf = w.buf[:0]
+}
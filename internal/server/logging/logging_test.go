@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+// decodeRecords parses the records written by a JSON logger.
+func decodeRecords(t *testing.T, out string) []map[string]any {
+	var ret []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		var rec map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &rec), line)
+		ret = append(ret, rec)
+	}
+	return ret
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(&strings.Builder{}, "xml")
+	assert.Error(t, err)
+
+	var out strings.Builder
+	lg, err := New(&out, FormatJSON)
+	require.NoError(t, err)
+	lg.Info("hello", "n", 1)
+	recs := decodeRecords(t, out.String())
+	require.Len(t, recs, 1)
+	assert.Equal(t, "hello", recs[0]["msg"])
+	assert.Equal(t, float64(1), recs[0]["n"])
+}
+
+func TestWith(t *testing.T) {
+	var out strings.Builder
+	lg, err := New(&out, FormatJSON)
+	require.NoError(t, err)
+
+	ctx := With(context.Background(), "requestID", "r1")
+	grainCtx := With(ctx, "grainID", "g1")
+	lg.InfoCtx(grainCtx, "in grain")
+	lg.With("a", "b").InfoCtx(ctx, "in request")
+	lg.Info("no context")
+
+	recs := decodeRecords(t, out.String())
+	require.Len(t, recs, 3)
+	assert.Equal(t, "r1", recs[0]["requestID"])
+	assert.Equal(t, "g1", recs[0]["grainID"])
+	assert.Equal(t, "r1", recs[1]["requestID"])
+	assert.Equal(t, "b", recs[1]["a"])
+	assert.NotContains(t, recs[1], "grainID", "the parent context is unchanged")
+	assert.NotContains(t, recs[2], "requestID")
+}
+
+func TestLineWriter(t *testing.T) {
+	var out strings.Builder
+	lg, err := New(&out, FormatJSON)
+	require.NoError(t, err)
+
+	w := NewLineWriter(lg.With("grainID", "g1"), slog.LevelInfo, "Grain output")
+	w.Write([]byte("first\nsec"))
+	w.Write([]byte("ond\r\nthird"))
+	require.NoError(t, w.Close())
+
+	recs := decodeRecords(t, out.String())
+	require.Len(t, recs, 3)
+	for i, want := range []string{"first", "second", "third"} {
+		assert.Equal(t, want, recs[i]["output"])
+		assert.Equal(t, "Grain output", recs[i]["msg"])
+		assert.Equal(t, "g1", recs[i]["grainID"])
+	}
+}
@@ -0,0 +1,185 @@
+// Package loginlimit protects logins from guessing and credential stuffing.
+//
+// A Limiter keeps track of login attempts by key, e.g. the client's IP
+// address, or the account being logged in to. Each key may make a limited
+// number of attempts an hour, in bursts of up to that many. Failed attempts,
+// such as presenting an invalid login token, count against a key, and once a
+// key has failed too many times in a row it is locked out for a while. The
+// lockout doubles with each further failure, up to MaxLockout, and is reset
+// by a successful login.
+package loginlimit
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrRateLimited is returned by Allow when a key has made too many
+	// attempts recently.
+	ErrRateLimited = errors.New("loginlimit: too many login attempts")
+
+	// ErrLockedOut is returned by Allow when a key is locked out after
+	// failing too many times.
+	ErrLockedOut = errors.New("loginlimit: locked out after too many failed logins")
+)
+
+// MaxLockout is the longest a key is locked out for.
+const MaxLockout = time.Hour
+
+// Failures are forgotten after this long without another.
+const forgetFailuresAfter = 24 * time.Hour
+
+// Config configures a Limiter.
+type Config struct {
+	// Maximum number of attempts by each key per hour. If this is not
+	// positive, attempts are not limited, but failures still are.
+	PerHour int
+
+	// Number of failures in a row after which a key is locked out. If
+	// this is not positive, keys are never locked out.
+	MaxFailures int
+
+	// How long a key is locked out after MaxFailures failures. Each
+	// further failure doubles it, up to MaxLockout.
+	Lockout time.Duration
+}
+
+// A Denied error is returned by Allow when an attempt is not allowed. It
+// wraps ErrRateLimited or ErrLockedOut.
+type Denied struct {
+	Err        error
+	Key        string        // The key which was denied.
+	RetryAfter time.Duration // How long until the key may try again.
+}
+
+func (e *Denied) Error() string {
+	return fmt.Sprintf("%v (%s; retry in %v)", e.Err, e.Key, e.RetryAfter.Round(time.Second))
+}
+
+func (e *Denied) Unwrap() error {
+	return e.Err
+}
+
+// A Limiter limits login attempts. It is safe for concurrent use.
+type Limiter struct {
+	cfg Config
+	now func() time.Time
+
+	mu   sync.Mutex
+	keys map[string]*state
+}
+
+// The state of a key.
+type state struct {
+	// A token bucket of attempts.
+	tokens float64
+	last   time.Time
+
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// New returns a Limiter using cfg.
+func New(cfg Config) *Limiter {
+	return &Limiter{
+		cfg:  cfg,
+		now:  time.Now,
+		keys: make(map[string]*state),
+	}
+}
+
+// Allow records an attempt by each of the keys, if all of them may make one.
+// Otherwise, it records nothing and returns a *Denied error for the first key
+// which may not.
+func (l *Limiter) Allow(keys ...string) error {
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.prune(now)
+	for _, key := range keys {
+		st := l.state(key, now)
+		if now.Before(st.lockedUntil) {
+			return &Denied{Err: ErrLockedOut, Key: key, RetryAfter: st.lockedUntil.Sub(now)}
+		}
+		if l.cfg.PerHour > 0 && st.tokens < 1 {
+			wait := time.Duration((1 - st.tokens) / float64(l.cfg.PerHour) * float64(time.Hour))
+			return &Denied{Err: ErrRateLimited, Key: key, RetryAfter: wait}
+		}
+	}
+	if l.cfg.PerHour > 0 {
+		for _, key := range keys {
+			l.keys[key].tokens--
+		}
+	}
+	return nil
+}
+
+// Failed records a failed attempt by each of the keys. It returns the keys
+// which are now locked out, because of this failure.
+func (l *Limiter) Failed(keys ...string) (lockedOut []string) {
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		st := l.state(key, now)
+		if now.Sub(st.lastFailure) > forgetFailuresAfter {
+			st.failures = 0
+		}
+		st.failures++
+		st.lastFailure = now
+		if l.cfg.MaxFailures <= 0 || st.failures < l.cfg.MaxFailures {
+			continue
+		}
+		lockout := l.cfg.Lockout
+		for i := l.cfg.MaxFailures; i < st.failures && lockout < MaxLockout; i++ {
+			lockout *= 2
+		}
+		st.lockedUntil = now.Add(min(lockout, MaxLockout))
+		lockedOut = append(lockedOut, key)
+	}
+	return lockedOut
+}
+
+// Succeeded records a successful login by each of the keys, which forgets
+// their failures.
+func (l *Limiter) Succeeded(keys ...string) {
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		st := l.state(key, now)
+		st.failures = 0
+		st.lockedUntil = time.Time{}
+	}
+}
+
+// state returns the key's state, with its bucket refilled up to now. l.mu
+// must be held.
+func (l *Limiter) state(key string, now time.Time) *state {
+	capacity := float64(l.cfg.PerHour)
+	st, ok := l.keys[key]
+	if !ok {
+		st = &state{tokens: capacity, last: now}
+		l.keys[key] = st
+	}
+	st.tokens = min(st.tokens+now.Sub(st.last).Hours()*capacity, capacity)
+	st.last = now
+	return st
+}
+
+// prune forgets keys whose state carries no information: full buckets, and
+// no failures worth remembering. l.mu must be held.
+func (l *Limiter) prune(now time.Time) {
+	capacity := float64(l.cfg.PerHour)
+	for key, st := range l.keys {
+		full := st.tokens+now.Sub(st.last).Hours()*capacity >= capacity
+		forgotten := st.failures == 0 || now.Sub(st.lastFailure) > forgetFailuresAfter
+		if full && forgotten && !now.Before(st.lockedUntil) {
+			delete(l.keys, key)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package loginlimit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLimiter(cfg Config) (*Limiter, *time.Time) {
+	l := New(cfg)
+	now := time.Unix(1700000000, 0)
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestRateLimit(t *testing.T) {
+	l, now := newTestLimiter(Config{PerHour: 2})
+	require.NoError(t, l.Allow("ip:1", "dev:alice"))
+	require.NoError(t, l.Allow("ip:1", "dev:bob"))
+
+	err := l.Allow("ip:1", "dev:carol")
+	assert.ErrorIs(t, err, ErrRateLimited)
+	var denied *Denied
+	require.True(t, errors.As(err, &denied))
+	assert.Equal(t, "ip:1", denied.Key)
+	assert.Equal(t, 30*time.Minute, denied.RetryAfter)
+
+	assert.NoError(t, l.Allow("ip:2", "dev:carol"),
+		"the denied attempt didn't count against dev:carol")
+
+	*now = now.Add(30 * time.Minute)
+	assert.NoError(t, l.Allow("ip:1"), "bucket refills")
+}
+
+func TestLockout(t *testing.T) {
+	l, now := newTestLimiter(Config{MaxFailures: 3, Lockout: time.Minute})
+	for i := 0; i < 2; i++ {
+		require.NoError(t, l.Allow("ip:1"))
+		assert.Empty(t, l.Failed("ip:1"))
+	}
+	require.NoError(t, l.Allow("ip:1"))
+	assert.Equal(t, []string{"ip:1"}, l.Failed("ip:1"))
+
+	err := l.Allow("ip:1")
+	assert.ErrorIs(t, err, ErrLockedOut)
+	var denied *Denied
+	require.True(t, errors.As(err, &denied))
+	assert.Equal(t, time.Minute, denied.RetryAfter)
+	assert.NoError(t, l.Allow("ip:2"), "other keys aren't affected")
+
+	// Each further failure doubles the lockout.
+	*now = now.Add(time.Minute)
+	require.NoError(t, l.Allow("ip:1"))
+	l.Failed("ip:1")
+	assert.ErrorIs(t, l.Allow("ip:1"), ErrLockedOut)
+	*now = now.Add(time.Minute)
+	assert.ErrorIs(t, l.Allow("ip:1"), ErrLockedOut)
+	*now = now.Add(time.Minute)
+	require.NoError(t, l.Allow("ip:1"))
+
+	for i := 0; i < 10; i++ {
+		l.Failed("ip:1")
+	}
+	err = l.Allow("ip:1")
+	require.True(t, errors.As(err, &denied))
+	assert.Equal(t, MaxLockout, denied.RetryAfter)
+
+	l.Succeeded("ip:1")
+	assert.NoError(t, l.Allow("ip:1"), "success resets")
+	assert.Empty(t, l.Failed("ip:1"))
+}
+
+func TestForgetFailures(t *testing.T) {
+	l, now := newTestLimiter(Config{MaxFailures: 2, Lockout: time.Minute})
+	l.Failed("ip:1")
+	*now = now.Add(forgetFailuresAfter + time.Second)
+	assert.Empty(t, l.Failed("ip:1"), "the earlier failure was forgotten")
+
+	require.NoError(t, l.Allow("ip:2"))
+	*now = now.Add(forgetFailuresAfter + time.Second)
+	require.NoError(t, l.Allow("ip:3"))
+	assert.NotContains(t, l.keys, "ip:1")
+	assert.NotContains(t, l.keys, "ip:2")
+}
@@ -0,0 +1,114 @@
+// Package mailer sends email from the server, e.g. login tokens.
+//
+// Delivery is pluggable: a Sender might talk to an SMTP server, or, in
+// development, where there is usually no mail server, just log messages.
+// Limit wraps a Sender to limit how often each address is sent mail, so the
+// server can't be used to flood someone's inbox.
+package mailer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// A Message is a plain text email.
+type Message struct {
+	To      string // A bare address, e.g. "alice@example.com".
+	Subject string
+	Body    string
+}
+
+// A Sender delivers messages.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// A LogSender logs messages instead of sending them.
+type LogSender struct {
+	Log *slog.Logger
+}
+
+func (s LogSender) Send(ctx context.Context, msg Message) error {
+	s.Log.Info("Logging email instead of sending it",
+		"to", msg.To,
+		"subject", msg.Subject,
+		"body", msg.Body,
+	)
+	return nil
+}
+
+// ErrRateLimited is returned by a Sender from Limit when an address has been
+// sent too many messages recently.
+var ErrRateLimited = errors.New("mailer: too many messages to this address")
+
+// Limit returns a Sender which sends with s, but fails with ErrRateLimited
+// rather than send more than perHour messages an hour to any one address.
+// Short bursts of up to perHour are allowed. If perHour is not positive,
+// Limit returns s.
+func Limit(s Sender, perHour int) Sender {
+	if perHour <= 0 {
+		return s
+	}
+	return &limiter{
+		sender:  s,
+		perHour: perHour,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+type limiter struct {
+	sender  Sender
+	perHour int
+	now     func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// A token bucket; see limiter.allow.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func (l *limiter) Send(ctx context.Context, msg Message) error {
+	if !l.allow(strings.ToLower(msg.To), l.now()) {
+		return ErrRateLimited
+	}
+	return l.sender.Send(ctx, msg)
+}
+
+// allow takes a token from the address's bucket, returning false if it is
+// empty. Buckets hold up to perHour tokens and refill continuously at that
+// rate.
+func (l *limiter) allow(addr string, now time.Time) bool {
+	capacity := float64(l.perHour)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[addr]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		l.buckets[addr] = b
+	}
+	b.tokens += now.Sub(b.last).Hours() * capacity
+	b.tokens = min(b.tokens, capacity)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	// Full buckets carry no information; drop them so the map doesn't
+	// grow without bound.
+	for other, ob := range l.buckets {
+		if ob.tokens+now.Sub(ob.last).Hours()*capacity >= capacity {
+			delete(l.buckets, other)
+		}
+	}
+	return true
+}
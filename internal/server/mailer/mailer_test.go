@@ -0,0 +1,113 @@
+package mailer
+
+import (
+	"context"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sandstorm.org/go/tempest/internal/server/smtpd"
+)
+
+type recordingSender struct {
+	sent []Message
+}
+
+func (s *recordingSender) Send(ctx context.Context, msg Message) error {
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func TestLimit(t *testing.T) {
+	rec := &recordingSender{}
+	s := Limit(rec, 2).(*limiter)
+	now := time.Unix(1700000000, 0)
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	require.NoError(t, s.Send(ctx, Message{To: "alice@example.com"}))
+	require.NoError(t, s.Send(ctx, Message{To: "Alice@Example.com"}))
+	assert.ErrorIs(t, s.Send(ctx, Message{To: "alice@example.com"}), ErrRateLimited)
+	assert.NoError(t, s.Send(ctx, Message{To: "bob@example.com"}), "limits are per address")
+	assert.Len(t, rec.sent, 3)
+
+	now = now.Add(30 * time.Minute)
+	assert.NoError(t, s.Send(ctx, Message{To: "alice@example.com"}), "bucket refills")
+
+	assert.Same(t, Sender(rec), Limit(rec, 0))
+}
+
+func TestFormatMessage(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	data, err := formatMessage("tempest@example.com", Message{
+		To:      "alice@example.com",
+		Subject: "Connexion à Tempest",
+		Body:    "Bonjour,\n\nVoici votre jeton.\n",
+	}, now)
+	require.NoError(t, err)
+	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	require.NoError(t, err)
+	assert.Equal(t, "tempest@example.com", msg.Header.Get("From"))
+	assert.Equal(t, "alice@example.com", msg.Header.Get("To"))
+	assert.Equal(t, "=?utf-8?q?Connexion_=C3=A0_Tempest?=", msg.Header.Get("Subject"))
+	assert.Equal(t, "Tue, 02 Jan 2024 03:04:05 +0000", msg.Header.Get("Date"))
+	assert.True(t, strings.HasSuffix(msg.Header.Get("Message-ID"), "@example.com>"))
+	assert.Contains(t, string(data), "\r\n\r\nBonjour,\r\n\r\nVoici votre jeton.\r\n")
+
+	for _, to := range []string{
+		"Alice <alice@example.com>",
+		"alice@example.com\r\nBcc: mallory@example.com",
+		"not an address",
+	} {
+		_, err := formatMessage("tempest@example.com", Message{To: to}, now)
+		assert.Error(t, err, "to: %q", to)
+	}
+}
+
+func TestSMTPSender(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	delivered := make(chan smtpd.Envelope, 1)
+	srv := &smtpd.Server{
+		Hostname: "mx.example.com",
+		Accept:   func(addr string) error { return nil },
+		Deliver: func(env smtpd.Envelope) error {
+			env.Data = append([]byte(nil), env.Data...)
+			delivered <- env
+			return nil
+		},
+	}
+	go srv.Serve(l)
+	host, port, err := net.SplitHostPort(l.Addr().String())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cfg := SMTPConfig{
+		Host:     host,
+		Port:     port,
+		From:     "tempest@example.com",
+		Security: SecurityNone,
+	}
+	err = SMTPSender{Config: cfg}.Send(ctx, Message{
+		To:      "alice@example.com",
+		Subject: "Hello",
+		Body:    "Hi Alice\n",
+	})
+	require.NoError(t, err)
+	env := <-delivered
+	assert.Equal(t, "tempest@example.com", env.From)
+	assert.Equal(t, []string{"alice@example.com"}, env.To)
+	assert.Contains(t, string(env.Data), "Subject: Hello\r\n")
+
+	// The server doesn't offer STARTTLS, so we mustn't send in the clear:
+	cfg.Security = SecurityStartTLS
+	err = SMTPSender{Config: cfg}.Send(ctx, Message{To: "alice@example.com"})
+	assert.Error(t, err)
+	assert.Empty(t, delivered)
+}
@@ -0,0 +1,149 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Values of SMTPConfig.Security.
+const (
+	// Upgrade the connection with STARTTLS, failing if the server doesn't
+	// offer it.
+	SecurityStartTLS = "starttls"
+
+	// Use TLS from the start ("implicit TLS"), usually on port 465.
+	SecurityTLS = "tls"
+
+	// Don't use TLS. Only suitable for a relay on the same host or a
+	// trusted network; net/smtp refuses to send a password in the clear
+	// to anything but localhost.
+	SecurityNone = "none"
+)
+
+// SMTPConfig configures an SMTPSender.
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string // If empty, don't authenticate.
+	Password string
+	From     string // Address to send mail from.
+	Security string // One of the Security* constants.
+}
+
+// An SMTPSender sends messages through an SMTP server.
+type SMTPSender struct {
+	Config SMTPConfig
+
+	// If non-nil, used to check the server's certificate, instead of the
+	// system's roots.
+	TLSConfig *tls.Config
+}
+
+func (s SMTPSender) Send(ctx context.Context, msg Message) error {
+	c := s.Config
+	data, err := formatMessage(c.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+	tlsConfig := s.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+	tlsConfig = tlsConfig.Clone()
+	tlsConfig.ServerName = c.Host
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(c.Host, c.Port))
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if c.Security == SecurityTLS {
+		conn = tls.Client(conn, tlsConfig)
+	}
+	client, err := smtp.NewClient(conn, c.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+	if c.Security == SecurityStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("mailer: SMTP server does not support STARTTLS")
+		}
+		if err = client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if c.Username != "" {
+		if err = client.Auth(smtp.PlainAuth("", c.Username, c.Password, c.Host)); err != nil {
+			return err
+		}
+	}
+	if err = client.Mail(c.From); err != nil {
+		return err
+	}
+	if err = client.Rcpt(msg.To); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(data); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// formatMessage returns msg as an RFC 5322 message from the given address.
+func formatMessage(from string, msg Message, now time.Time) ([]byte, error) {
+	// Addresses must be bare, which also rules out header injection:
+	for _, addr := range []string{from, msg.To} {
+		if parsed, err := mail.ParseAddress(addr); err != nil || parsed.Address != addr {
+			return nil, fmt.Errorf("mailer: invalid address %q", addr)
+		}
+	}
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+	domain := from[strings.LastIndex(from, "@")+1:]
+
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		buf.WriteString(name + ": " + value + "\r\n")
+	}
+	header("From", from)
+	header("To", msg.To)
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", "<"+hex.EncodeToString(id[:])+"@"+domain+">")
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+	w := quotedprintable.NewWriter(&buf)
+	w.Write([]byte(msg.Body))
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
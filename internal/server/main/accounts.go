@@ -0,0 +1,393 @@
+package servermain
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"capnproto.org/go/capnp/v3"
+	"github.com/gorilla/mux"
+	"sandstorm.org/go/tempest/capnp/external"
+	utilcp "sandstorm.org/go/tempest/capnp/util"
+	"sandstorm.org/go/tempest/internal/common/apierror"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"zenhack.net/go/util/exn"
+)
+
+// Account management, for users with the admin role or the users admin
+// scope, through both the admin UI and AdminSession. Only admins may grant or
+// revoke the admin role, or deactivate admins, and nobody may change their
+// own account, so an admin can't lock everyone out by accident.
+
+// An accountInfo is an account, with the disk space used by its grains, as
+// last measured.
+type accountInfo struct {
+	database.Account
+	StorageBytes int64
+}
+
+// listAccounts returns every account, on behalf of the user with the given
+// credential.
+func (s *server) listAccounts(by types.Credential) ([]accountInfo, error) {
+	accounts, err := exn.Try(func(throw exn.Thrower) []database.Account {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		scopes, err := tx.CredentialAdminScopes(by)
+		throw(err)
+		if !scopes.Allows(types.AdminScopeUsers, false) {
+			throw(apierror.New(apierror.CodePermissionDenied,
+				"you may not view accounts"))
+		}
+		accounts, err := tx.Accounts()
+		throw(err)
+		return accounts
+	})
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]accountInfo, len(accounts))
+	for i, a := range accounts {
+		ret[i].Account = a
+		ret[i].StorageBytes = s.quota.Total(a.Grains)
+	}
+	return ret, nil
+}
+
+// changeAccount calls change to modify the account, on behalf of the user with
+// the given credential, if they may do so. If adminRole is true, the change
+// grants or revokes the admin role.
+func (s *server) changeAccount(
+	by types.Credential,
+	accountID types.AccountID,
+	adminRole bool,
+	change func(tx database.Tx) error,
+) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		scopes, err := tx.CredentialAdminScopes(by)
+		throw(err)
+		if !scopes.Allows(types.AdminScopeUsers, true) {
+			throw(apierror.New(apierror.CodePermissionDenied,
+				"you may not change accounts"))
+		}
+		self, err := tx.CredentialAccount(by)
+		throw(err)
+		if self == accountID {
+			throw(apierror.New(apierror.CodePermissionDenied,
+				"you may not change your own account"))
+		}
+		role, err := tx.AccountRole(accountID)
+		if errors.Is(err, sql.ErrNoRows) {
+			throw(apierror.New(apierror.CodeNotFound,
+				"no such account: "+string(accountID), "account"))
+		}
+		throw(err)
+		if adminRole || role == types.RoleAdmin {
+			myRole, err := tx.CredentialRole(by)
+			throw(err)
+			if myRole != types.RoleAdmin {
+				throw(apierror.New(apierror.CodePermissionDenied,
+					"only admins may change admin accounts"))
+			}
+		}
+		throw(change(tx))
+		throw(tx.Commit())
+	})
+}
+
+// setAccountRole changes the role of an account, on behalf of the user with
+// the given credential.
+func (s *server) setAccountRole(by types.Credential, accountID types.AccountID, role types.Role) error {
+	if !role.IsValid() {
+		return apierror.New(apierror.CodeInvalidArgument,
+			"invalid role: "+string(role), "role")
+	}
+	err := s.changeAccount(by, accountID, role == types.RoleAdmin, func(tx database.Tx) error {
+		return changeAccountRole(tx, accountID, role)
+	})
+	if err == nil {
+		s.log.Info("Account role changed",
+			"accountID", accountID,
+			"role", role,
+			"changedBy", by,
+		)
+	}
+	return err
+}
+
+// changeAccountRole changes the role of an account, with no checks on who may
+// do so.
+func changeAccountRole(tx database.Tx, accountID types.AccountID, role types.Role) error {
+	current, err := tx.AccountRole(accountID)
+	if err != nil {
+		return err
+	}
+	if current == types.RoleAdmin && role != types.RoleAdmin {
+		// Don't let scopes granted before the account was made an
+		// admin come back:
+		if err = tx.SetAdminScopes(accountID, nil); err != nil {
+			return err
+		}
+	}
+	return tx.SetAccountRole(accountID, role)
+}
+
+// setAccountDeactivated deactivates or reactivates an account, on behalf of
+// the user with the given credential.
+func (s *server) setAccountDeactivated(by types.Credential, accountID types.AccountID, deactivated bool) error {
+	err := s.changeAccount(by, accountID, false, func(tx database.Tx) error {
+		return tx.SetAccountDeactivated(accountID, deactivated, time.Now())
+	})
+	if err == nil {
+		s.log.Info("Account deactivation changed",
+			"accountID", accountID,
+			"deactivated", deactivated,
+			"changedBy", by,
+		)
+	}
+	return err
+}
+
+type adminSessionImpl struct {
+	externalApiImpl
+}
+
+func (s adminSessionImpl) ListAccounts(ctx context.Context, p external.AdminSession_listAccounts) error {
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	p.Go()
+	into := p.Args().Into()
+	return withAPIError(results, func(throw exn.Thrower) {
+		accounts, err := s.server.listAccounts(s.userSession.Credential)
+		throw(err)
+
+		throw(into.Clear(ctx, nil))
+		for _, a := range accounts {
+			throw(into.Upsert(ctx, func(p utilcp.KeyValue) error {
+				key, err := capnp.NewText(p.Segment(), string(a.ID))
+				throw(err)
+				throw(p.SetKey(key.ToPtr()))
+				account, err := external.NewAccount(p.Segment())
+				throw(err)
+				throw(account.SetId(string(a.ID)))
+				throw(account.SetRole(string(a.Role)))
+				account.SetDeactivated(!a.Deactivated.IsZero())
+				creds, err := account.NewCredentials(int32(len(a.Credentials)))
+				throw(err)
+				for i, c := range a.Credentials {
+					throw(creds.At(i).SetType(string(c.Type)))
+					throw(creds.At(i).SetScopedId(c.ScopedID))
+				}
+				account.SetGrainCount(uint32(len(a.Grains)))
+				account.SetStorageBytes(uint64(a.StorageBytes))
+				return p.SetValue(account.ToPtr())
+			}))
+		}
+		fut, rel := into.Ready(ctx, nil)
+		defer rel()
+		throw(into.WaitStreaming())
+		_, err = fut.Struct()
+		throw(err)
+	})
+}
+
+func (s adminSessionImpl) SetRole(ctx context.Context, p external.AdminSession_setRole) error {
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	return withAPIError(results, func(throw exn.Thrower) {
+		accountID, err := p.Args().AccountId()
+		throw(err)
+		role, err := p.Args().Role()
+		throw(err)
+		throw(s.server.setAccountRole(s.userSession.Credential,
+			types.AccountID(accountID), types.Role(role)))
+	})
+}
+
+func (s adminSessionImpl) SetDeactivated(ctx context.Context, p external.AdminSession_setDeactivated) error {
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	return withAPIError(results, func(throw exn.Thrower) {
+		accountID, err := p.Args().AccountId()
+		throw(err)
+		throw(s.server.setAccountDeactivated(s.userSession.Credential,
+			types.AccountID(accountID), p.Args().Deactivated()))
+	})
+}
+
+// apiErrorStatus returns the HTTP status corresponding to an error from the
+// functions above.
+func apiErrorStatus(err error) int {
+	switch apierror.FromError(err).Code {
+	case apierror.CodeNotLoggedIn:
+		return http.StatusUnauthorized
+	case apierror.CodePermissionDenied:
+		return http.StatusForbidden
+	case apierror.CodeNotFound:
+		return http.StatusNotFound
+	case apierror.CodeInvalidArgument:
+		return http.StatusBadRequest
+	case apierror.CodeUnimplemented:
+		return http.StatusNotImplemented
+	case apierror.CodeUnavailable:
+		return http.StatusBadGateway
+	case apierror.CodeRateLimited:
+		return http.StatusTooManyRequests
+	case apierror.CodeQuotaExceeded:
+		return http.StatusInsufficientStorage
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeAPIError writes an error response for an error from one of the
+// functions above, or similar, in the user's locale, logging it with msg and
+// the given attributes if it was unexpected.
+func (s *server) writeAPIError(w http.ResponseWriter, req *http.Request, err error, msg string, args ...any) {
+	status := apiErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		s.log.ErrorCtx(req.Context(), msg, append([]any{"error", err}, args...)...)
+	}
+	s.writeLocalizedError(w, req, status, apierror.FromError(err))
+}
+
+var adminAccountsTemplate = parseAdminTemplate("admin-accounts", template.FuncMap{
+	"kib": func(n int64) string {
+		return strconv.FormatInt((n+1023)/1024, 10)
+	},
+}, `<!doctype html>
+<html>
+<head>
+<meta charset="utf-8" />
+<title>Accounts</title>
+</head>
+<body>
+{{template "admin-nav" .Nav}}
+<h1>Accounts</h1>
+<p>Deactivated accounts keep their grains, but can't use them.{{if .CanEdit}}
+Only admins can grant or revoke the admin role, or deactivate admins.{{end}}</p>
+<table>
+	<tr>
+		<th>Account</th>
+		<th>Credentials</th>
+		<th>Grains</th>
+		<th>Storage (KiB)</th>
+		<th>Role</th>
+		<th>Status</th>
+	</tr>
+	{{range .Accounts}}
+	<tr>
+		<td><code>{{.ID}}</code></td>
+		<td>{{range $i, $cred := .Credentials}}{{if $i}}, {{end}}{{$cred.Type}}: {{$cred.ScopedID}}{{end}}</td>
+		<td>{{len .Grains}}</td>
+		<td>{{kib .StorageBytes}}</td>
+		<td>
+		{{if $.CanEdit}}
+		{{$account := .}}
+		<form method="POST" action="/admin/accounts/{{.ID}}/role">
+			<select name="role">
+				{{range $.Roles}}
+				<option value="{{.}}" {{if eq . $account.Role}}selected{{end}}>{{.}}</option>
+				{{end}}
+			</select>
+			<button type="submit">Save</button>
+		</form>
+		{{else}}
+		{{.Role}}
+		{{end}}
+		</td>
+		<td>
+		{{if .Deactivated.IsZero}}active{{else}}deactivated {{.Deactivated.UTC.Format "2006-01-02 15:04"}}{{end}}
+		{{if $.CanEdit}}
+		<form method="POST" action="/admin/accounts/{{.ID}}/deactivated">
+			{{if .Deactivated.IsZero}}
+			<input type="hidden" name="deactivated" value="true" />
+			<button type="submit">Deactivate</button>
+			{{else}}
+			<input type="hidden" name="deactivated" value="false" />
+			<button type="submit">Reactivate</button>
+			{{end}}
+		</form>
+		{{end}}
+		</td>
+	</tr>
+	{{end}}
+</table>
+</body>
+</html>
+`)
+
+func (s *server) serveAdminAccounts(w http.ResponseWriter, req *http.Request) {
+	user, ok := s.requireAdminScope(w, req, types.AdminScopeUsers, false)
+	if !ok {
+		return
+	}
+	accounts, err := s.listAccounts(user.Credential)
+	if err != nil {
+		w.WriteHeader(apiErrorStatus(err))
+		s.log.ErrorCtx(req.Context(), "Listing accounts", "error", err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	adminAccountsTemplate.Execute(w, struct {
+		Nav      []adminSection
+		Accounts []accountInfo
+		Roles    []types.Role
+		CanEdit  bool
+	}{
+		Nav:      visibleAdminSections(user.Scopes),
+		Accounts: accounts,
+		Roles:    []types.Role{types.RoleVisitor, types.RoleUser, types.RoleAdmin},
+		CanEdit:  user.Scopes.Allows(types.AdminScopeUsers, true),
+	})
+}
+
+// serveSetAccountRole sets the role of the account named in the URL to the
+// form's role field.
+func (s *server) serveSetAccountRole(w http.ResponseWriter, req *http.Request) {
+	user, ok := s.requireAdminScope(w, req, types.AdminScopeUsers, true)
+	if !ok {
+		return
+	}
+	accountID := types.AccountID(mux.Vars(req)["accountID"])
+	role := types.Role(req.PostFormValue("role"))
+	if err := s.setAccountRole(user.Credential, accountID, role); err != nil {
+		s.writeAPIError(w, req, err, "Setting account role", "accountID", accountID)
+		return
+	}
+	http.Redirect(w, req, "/admin/accounts", http.StatusSeeOther)
+}
+
+// serveSetAccountDeactivated deactivates or reactivates the account named in
+// the URL, according to the form's deactivated field.
+func (s *server) serveSetAccountDeactivated(w http.ResponseWriter, req *http.Request) {
+	user, ok := s.requireAdminScope(w, req, types.AdminScopeUsers, true)
+	if !ok {
+		return
+	}
+	accountID := types.AccountID(mux.Vars(req)["accountID"])
+	deactivated, err := strconv.ParseBool(req.PostFormValue("deactivated"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if err := s.setAccountDeactivated(user.Credential, accountID, deactivated); err != nil {
+		s.writeAPIError(w, req, err, "Setting account deactivation", "accountID", accountID)
+		return
+	}
+	http.Redirect(w, req, "/admin/accounts", http.StatusSeeOther)
+}
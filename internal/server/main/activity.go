@@ -0,0 +1,342 @@
+package servermain
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"capnproto.org/go/capnp/v3"
+	"sandstorm.org/go/tempest/capnp/activity"
+	"sandstorm.org/go/tempest/capnp/collection"
+	"sandstorm.org/go/tempest/capnp/external"
+	"sandstorm.org/go/tempest/capnp/grain"
+	utilcp "sandstorm.org/go/tempest/capnp/util"
+	"sandstorm.org/go/tempest/internal/common/apierror"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/pkg/exp/util/handle"
+	"zenhack.net/go/util/exn"
+)
+
+func (sc sessionCtxImpl) Activity(ctx context.Context, p grain.SessionContext_activity) error {
+	sc.use("activity")
+	event, err := p.Args().Event()
+	if err != nil {
+		return err
+	}
+	return sc.server.postActivity(sc.grainID, event)
+}
+
+func (api sandstormApiImpl) BackgroundActivity(ctx context.Context, p grain.SandstormApi_backgroundActivity) error {
+	api.use("backgroundActivity")
+	event, err := p.Args().Event()
+	if err != nil {
+		return err
+	}
+	return api.server.postActivity(api.grainID, event)
+}
+
+// postActivity notifies the users of a grain about an event which it
+// reported, if the event has a caption, or its type says subscribers should
+// be notified.
+//
+// Every account with access to the grain counts as a subscriber. We don't yet
+// know which user an event is attributed to, so the actor is notified too,
+// and we can't resolve the identities listed in event.users, so mentions
+// don't notify anyone who isn't already.
+func (s *server) postActivity(grainID types.GrainID, event activity.ActivityEvent) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		var text string
+		notify := false
+		if event.HasNotification() {
+			info, err := event.Notification()
+			throw(err)
+			caption, err := info.Caption()
+			throw(err)
+			text, err = caption.DefaultText()
+			throw(err)
+			notify = true
+		}
+
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		def, ok, err := tx.GrainActivityType(grainID, event.Type())
+		throw(err)
+		if ok {
+			if def.Obsolete() {
+				return
+			}
+			notify = notify || def.NotifySubscribers()
+			if text == "" {
+				verbPhrase, err := def.VerbPhrase()
+				throw(err)
+				text, err = verbPhrase.DefaultText()
+				throw(err)
+			}
+		}
+		if !notify {
+			return
+		}
+
+		path, err := event.Path()
+		throw(err)
+		grainInfo, err := tx.GrainInfo(grainID)
+		throw(err)
+		accounts, err := tx.GrainAccounts(grainID)
+		throw(err)
+		now := time.Now()
+		var added []database.Notification
+		for _, accountID := range accounts {
+			n := database.Notification{
+				AccountID:  accountID,
+				GrainID:    grainID,
+				GrainTitle: grainInfo.Title,
+				Path:       path,
+				Text:       text,
+				Time:       now,
+			}
+			n.ID, err = tx.AddNotification(n)
+			throw(err)
+			added = append(added, n)
+		}
+		throw(tx.Commit())
+		for _, n := range added {
+			s.notifications.publish(n)
+		}
+	})
+}
+
+// A notificationHub delivers new and changed notifications to the browser
+// sessions of the accounts they belong to. The zero value is ready to use.
+type notificationHub struct {
+	mu   sync.Mutex
+	subs map[types.AccountID]map[*notificationSub]struct{}
+}
+
+// A notificationSub queues notifications for one subscriber, so publishing
+// never waits on a slow connection.
+type notificationSub struct {
+	mu      sync.Mutex
+	pending []database.Notification
+	ready   chan struct{} // Signalled when pending becomes non-empty.
+}
+
+// subscribe returns a subscription to the account's notifications. The
+// caller must call unsubscribe when done with it.
+func (h *notificationHub) subscribe(accountID types.AccountID) *notificationSub {
+	sub := &notificationSub{ready: make(chan struct{}, 1)}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subs == nil {
+		h.subs = make(map[types.AccountID]map[*notificationSub]struct{})
+	}
+	if h.subs[accountID] == nil {
+		h.subs[accountID] = make(map[*notificationSub]struct{})
+	}
+	h.subs[accountID][sub] = struct{}{}
+	return sub
+}
+
+func (h *notificationHub) unsubscribe(accountID types.AccountID, sub *notificationSub) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subs[accountID], sub)
+	if len(h.subs[accountID]) == 0 {
+		delete(h.subs, accountID)
+	}
+}
+
+// publish queues n for each of its account's subscribers.
+func (h *notificationHub) publish(n database.Notification) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs[n.AccountID] {
+		sub.mu.Lock()
+		sub.pending = append(sub.pending, n)
+		sub.mu.Unlock()
+		select {
+		case sub.ready <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// take removes and returns the queued notifications.
+func (sub *notificationSub) take() []database.Notification {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	ret := sub.pending
+	sub.pending = nil
+	return ret
+}
+
+// notificationKey returns the key under which a notification is pushed to
+// the browser.
+func notificationKey(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
+
+// accountID returns the ID of the session's account.
+func (s userSessionImpl) accountID() (types.AccountID, error) {
+	return exn.Try(func(throw exn.Thrower) types.AccountID {
+		tx, err := s.visitor.server.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		accountID, err := tx.CredentialAccount(s.visitor.userSession.Credential)
+		throw(err)
+		throw(tx.Commit())
+		return accountID
+	})
+}
+
+func (s userSessionImpl) Notifications(ctx context.Context, p external.UserSession_notifications) error {
+	hub := s.visitor.server.notifications
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	return withAPIError(results, func(throw exn.Thrower) {
+		accountID, err := s.accountID()
+		throw(err)
+		// Subscribe before listing, so nothing added in between is missed;
+		// pushing a notification twice is harmless.
+		sub := hub.subscribe(accountID)
+		pushing := false
+		defer func() {
+			if !pushing {
+				hub.unsubscribe(accountID, sub)
+			}
+		}()
+		tx, err := s.visitor.server.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		notifications, err := tx.AccountNotifications(accountID)
+		throw(err)
+		throw(tx.Commit())
+
+		subCtx, h := handle.WithCancel(context.Background())
+		throw(results.SetSubscription(h))
+		into := p.Args().Into().AddRef()
+		pushing = true
+		go func() {
+			defer hub.unsubscribe(accountID, sub)
+			defer into.Release()
+			err := pushNotifications(subCtx, into, sub, notifications)
+			if err != nil && subCtx.Err() == nil {
+				s.visitor.server.log.Debug("pushing notifications", "error", err)
+			}
+		}()
+	})
+}
+
+// pushNotifications pushes the initial notifications into the pusher, then
+// those queued on sub, until ctx is cancelled.
+func pushNotifications(
+	ctx context.Context,
+	into collection.Pusher,
+	sub *notificationSub,
+	initial []database.Notification,
+) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		throw(into.Clear(ctx, nil))
+		batch := initial
+		for {
+			for _, n := range batch {
+				throw(into.Upsert(ctx, func(kv utilcp.KeyValue) error {
+					return setNotification(kv, n)
+				}))
+			}
+			fut, rel := into.Ready(ctx, nil)
+			throw(into.WaitStreaming())
+			_, err := fut.Struct()
+			rel()
+			throw(err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-sub.ready:
+				batch = sub.take()
+			}
+		}
+	})
+}
+
+// setNotification sets kv to the key and value for n.
+func setNotification(kv utilcp.KeyValue, n database.Notification) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		key, err := capnp.NewText(kv.Segment(), notificationKey(n.ID))
+		throw(err)
+		throw(kv.SetKey(key.ToPtr()))
+		v, err := external.NewNotification(kv.Segment())
+		throw(err)
+		throw(v.SetGrainId(string(n.GrainID)))
+		throw(v.SetGrainTitle(n.GrainTitle))
+		throw(v.SetPath(n.Path))
+		throw(v.SetText(n.Text))
+		v.SetTime(n.Time.UnixNano())
+		v.SetRead(n.Read)
+		throw(kv.SetValue(v.ToPtr()))
+	})
+}
+
+// MarkNotificationsRead marks notifications read, and pushes the changes to
+// the account's sessions.
+func (s userSessionImpl) MarkNotificationsRead(ctx context.Context, p external.UserSession_markNotificationsRead) error {
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	return withAPIError(results, func(throw exn.Thrower) {
+		keys, err := p.Args().Keys()
+		throw(err)
+		ids := make([]int64, keys.Len())
+		for i := range ids {
+			key, err := keys.At(i)
+			throw(err)
+			ids[i], err = strconv.ParseInt(key, 10, 64)
+			if err != nil {
+				throw(apierror.New(apierror.CodeInvalidArgument,
+					"invalid notification key: "+key, "keys"))
+			}
+		}
+		accountID, err := s.accountID()
+		throw(err)
+		tx, err := s.visitor.server.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		throw(tx.MarkNotificationsRead(accountID, ids))
+		notifications, err := tx.AccountNotifications(accountID)
+		throw(err)
+		throw(tx.Commit())
+
+		marked := make(map[int64]bool, len(ids))
+		for _, id := range ids {
+			marked[id] = true
+		}
+		for _, n := range notifications {
+			if len(ids) == 0 || marked[n.ID] {
+				s.visitor.server.notifications.publish(n)
+			}
+		}
+	})
+}
+
+func (s userSessionImpl) UnreadNotificationCount(ctx context.Context, p external.UserSession_unreadNotificationCount) error {
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	return withAPIError(results, func(throw exn.Thrower) {
+		accountID, err := s.accountID()
+		throw(err)
+		tx, err := s.visitor.server.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		count, err := tx.UnreadNotificationCount(accountID)
+		throw(err)
+		throw(tx.Commit())
+		results.SetCount(uint32(count))
+	})
+}
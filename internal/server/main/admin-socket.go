@@ -0,0 +1,423 @@
+package servermain
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gorilla/mux"
+	"sandstorm.org/go/tempest/internal/common/apierror"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/config"
+	"zenhack.net/go/util/exn"
+)
+
+// The admin socket lets operators maintain the running server from scripts,
+// through `tempest admin`, rather than the web UI. Only root and the server's
+// own user may use it; the socket's permissions keep everyone else out, and
+// requests from anyone else are refused in case they change. Changes made
+// through it are logged as made by the admin socket.
+
+// cliAccount is an account, as `tempest admin list-users` shows it.
+type cliAccount struct {
+	ID           types.AccountID `json:"id"`
+	Role         types.Role      `json:"role"`
+	Deactivated  bool            `json:"deactivated"`
+	Credentials  []string        `json:"credentials"` // As "type:scopedId".
+	Grains       int             `json:"grains"`
+	StorageBytes int64           `json:"storageBytes"`
+}
+
+// cliGrain is a grain, as `tempest admin list-grains` shows it.
+type cliGrain struct {
+	ID           types.GrainID   `json:"id"`
+	Title        string          `json:"title"`
+	Owner        types.AccountID `json:"owner"`
+	Trashed      bool            `json:"trashed"`
+	Running      bool            `json:"running"`
+	StorageBytes int64           `json:"storageBytes"`
+}
+
+// cliUsage is the use of the server, as `tempest admin usage` shows it.
+type cliUsage struct {
+	Accounts      int   `json:"accounts"`
+	Grains        int   `json:"grains"`
+	RunningGrains int   `json:"runningGrains"`
+	StorageBytes  int64 `json:"storageBytes"`
+
+	// By account, heaviest first.
+	ByAccount []cliAccountUsage `json:"byAccount"`
+}
+
+// cliAccountUsage is an account's use of the server.
+type cliAccountUsage struct {
+	ID            types.AccountID `json:"id"`
+	Grains        int             `json:"grains"`
+	RunningGrains int             `json:"runningGrains"`
+	StorageBytes  int64           `json:"storageBytes"`
+}
+
+// cliRevokeResult is the result of `tempest admin revoke-sessions`.
+type cliRevokeResult struct {
+	Revoked int `json:"revoked"`
+}
+
+// cliStopResult is the result of `tempest admin stop-grain`.
+type cliStopResult struct {
+	WasRunning bool `json:"wasRunning"`
+}
+
+// serveAdmin serves requests from `tempest admin` on l, until it fails.
+func (s *server) serveAdmin(l net.Listener) error {
+	r := mux.NewRouter()
+	r.Path("/users").Methods("GET").HandlerFunc(s.adminListUsers)
+	r.Path("/users/{accountID}/make-admin").Methods("POST").HandlerFunc(s.adminMakeAdmin)
+	r.Path("/users/{accountID}/revoke-sessions").Methods("POST").HandlerFunc(s.adminRevokeSessions)
+	r.Path("/grains").Methods("GET").HandlerFunc(s.adminListGrains)
+	r.Path("/grains/{grainID}/stop").Methods("POST").HandlerFunc(s.adminStopGrain)
+	r.Path("/usage").Methods("GET").HandlerFunc(s.adminUsage)
+	return serveLocal(l, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		cred, ok := peerCred(req)
+		if !ok || (cred.Uid != 0 && int(cred.Uid) != os.Getuid()) {
+			http.Error(w, "only root and the server's user may use the admin socket",
+				http.StatusForbidden)
+			return
+		}
+		r.ServeHTTP(w, req)
+	}))
+}
+
+// writeAdminResult writes the response to a request to the admin socket: an
+// error, or result as JSON.
+func (s *server) writeAdminResult(w http.ResponseWriter, result any, err error, msg string) {
+	if err != nil {
+		s.writeLocalError(w, err, msg)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// runningGrains returns the set of grains which are running.
+func (s *server) runningGrains() map[types.GrainID]bool {
+	ret := make(map[types.GrainID]bool)
+	s.state.With(func(state *serverState) {
+		for grainID := range state.containers.containersByGrainID {
+			ret[grainID] = true
+		}
+	})
+	return ret
+}
+
+func (s *server) adminListUsers(w http.ResponseWriter, req *http.Request) {
+	users, err := exn.Try(func(throw exn.Thrower) []cliAccount {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		accounts, err := tx.Accounts()
+		throw(err)
+		users := make([]cliAccount, 0, len(accounts))
+		for _, a := range accounts {
+			u := cliAccount{
+				ID:           a.ID,
+				Role:         a.Role,
+				Deactivated:  !a.Deactivated.IsZero(),
+				Credentials:  []string{},
+				Grains:       len(a.Grains),
+				StorageBytes: s.quota.Total(a.Grains),
+			}
+			for _, c := range a.Credentials {
+				u.Credentials = append(u.Credentials, string(c.Type)+":"+c.ScopedID)
+			}
+			users = append(users, u)
+		}
+		return users
+	})
+	s.writeAdminResult(w, users, err, "Listing users")
+}
+
+func (s *server) adminMakeAdmin(w http.ResponseWriter, req *http.Request) {
+	accountID := types.AccountID(mux.Vars(req)["accountID"])
+	err := exn.Try0(func(throw exn.Thrower) {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		err = changeAccountRole(tx, accountID, types.RoleAdmin)
+		if errors.Is(err, sql.ErrNoRows) {
+			throw(apierror.New(apierror.CodeNotFound,
+				"no such account: "+string(accountID), "account"))
+		}
+		throw(err)
+		throw(tx.Commit())
+	})
+	if err == nil {
+		s.log.Info("Account role changed",
+			"accountID", accountID,
+			"role", types.RoleAdmin,
+			"changedBy", "admin socket",
+		)
+	}
+	s.writeAdminResult(w, struct{}{}, err, "Making account an admin")
+}
+
+func (s *server) adminRevokeSessions(w http.ResponseWriter, req *http.Request) {
+	n, err := s.revokeAccountSessions(types.AccountID(mux.Vars(req)["accountID"]))
+	s.writeAdminResult(w, cliRevokeResult{Revoked: n}, err, "Revoking sessions")
+}
+
+func (s *server) adminListGrains(w http.ResponseWriter, req *http.Request) {
+	grains, err := exn.Try(func(throw exn.Thrower) []cliGrain {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		infos, err := tx.Grains()
+		throw(err)
+		running := s.runningGrains()
+		grains := make([]cliGrain, 0, len(infos))
+		for _, g := range infos {
+			grains = append(grains, cliGrain{
+				ID:           g.ID,
+				Title:        g.Title,
+				Owner:        types.AccountID(g.Owner),
+				Trashed:      !g.Trashed.IsZero(),
+				Running:      running[g.ID],
+				StorageBytes: s.quota.Usage(g.ID),
+			})
+		}
+		return grains
+	})
+	s.writeAdminResult(w, grains, err, "Listing grains")
+}
+
+func (s *server) adminStopGrain(w http.ResponseWriter, req *http.Request) {
+	grainID := types.GrainID(mux.Vars(req)["grainID"])
+	var result cliStopResult
+	err := exn.Try0(func(throw exn.Thrower) {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		_, err = tx.GrainInfo(grainID)
+		if errors.Is(err, sql.ErrNoRows) {
+			throw(apierror.New(apierror.CodeNotFound,
+				"no such grain: "+string(grainID), "grain"))
+		}
+		throw(err)
+	})
+	if err == nil {
+		result.WasRunning = s.runningGrains()[grainID]
+		s.stopGrain(grainID)
+		if result.WasRunning {
+			s.log.Info("Grain stopped", "grainID", grainID, "stoppedBy", "admin socket")
+		}
+	}
+	s.writeAdminResult(w, result, err, "Stopping grain")
+}
+
+func (s *server) adminUsage(w http.ResponseWriter, req *http.Request) {
+	usage, err := exn.Try(func(throw exn.Thrower) cliUsage {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		accounts, err := tx.Accounts()
+		throw(err)
+		running := s.runningGrains()
+		usage := cliUsage{
+			Accounts:  len(accounts),
+			ByAccount: make([]cliAccountUsage, 0, len(accounts)),
+		}
+		for _, a := range accounts {
+			u := cliAccountUsage{
+				ID:           a.ID,
+				Grains:       len(a.Grains),
+				StorageBytes: s.quota.Total(a.Grains),
+			}
+			for _, grainID := range a.Grains {
+				if running[grainID] {
+					u.RunningGrains++
+				}
+			}
+			usage.Grains += u.Grains
+			usage.RunningGrains += u.RunningGrains
+			usage.StorageBytes += u.StorageBytes
+			usage.ByAccount = append(usage.ByAccount, u)
+		}
+		sort.SliceStable(usage.ByAccount, func(i, j int) bool {
+			return usage.ByAccount[i].StorageBytes > usage.ByAccount[j].StorageBytes
+		})
+		return usage
+	})
+	s.writeAdminResult(w, usage, err, "Measuring usage")
+}
+
+// Admin implements `tempest admin`, which maintains the running server
+// through its admin socket.
+func Admin(args []string) {
+	flags := flag.NewFlagSet("tempest admin", flag.ExitOnError)
+	socket := flags.String("socket", config.AdminSocket, "path to the server's admin socket")
+	asJSON := flags.Bool("json", false, "print the result as JSON, for scripts")
+	flags.Usage = func() {
+		out := flags.Output()
+		fmt.Fprintln(out, "Usage: tempest admin [-socket <path>] [-json] <command> [<arg>]")
+		fmt.Fprintln(out, "Commands:")
+		fmt.Fprintln(out, "  list-users                     list accounts, with their credentials and storage")
+		fmt.Fprintln(out, "  make-admin <account id>        give the account the admin role")
+		fmt.Fprintln(out, "  revoke-sessions <account id>   log the account out everywhere")
+		fmt.Fprintln(out, "  list-grains                    list grains, with their owners and storage")
+		fmt.Fprintln(out, "  stop-grain <grain id>          stop the grain, if it is running")
+		fmt.Fprintln(out, "  usage                          summarize the use of the server, by account")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() < 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	var (
+		command = flags.Arg(0)
+		arg     = flags.Arg(1)
+		method  = http.MethodGet
+		path    string
+		result  any
+		show    func(w io.Writer)
+	)
+	switch command {
+	case "list-users":
+		var users []cliAccount
+		path, result = "/users", &users
+		show = func(w io.Writer) {
+			tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "ID\tROLE\tGRAINS\tSTORAGE\tCREDENTIALS")
+			for _, u := range users {
+				role := string(u.Role)
+				if u.Deactivated {
+					role += " (deactivated)"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", u.ID, role, u.Grains,
+					formatBytes(u.StorageBytes), strings.Join(u.Credentials, ", "))
+			}
+			tw.Flush()
+		}
+	case "make-admin":
+		method, path, result = http.MethodPost, "/users/"+url.PathEscape(arg)+"/make-admin", &struct{}{}
+		show = func(w io.Writer) {
+			fmt.Fprintf(w, "%s is now an admin.\n", arg)
+		}
+	case "revoke-sessions":
+		var r cliRevokeResult
+		method, path, result = http.MethodPost, "/users/"+url.PathEscape(arg)+"/revoke-sessions", &r
+		show = func(w io.Writer) {
+			fmt.Fprintf(w, "Revoked %d sessions of %s.\n", r.Revoked, arg)
+		}
+	case "list-grains":
+		var grains []cliGrain
+		path, result = "/grains", &grains
+		show = func(w io.Writer) {
+			tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "ID\tOWNER\tSTATE\tSTORAGE\tTITLE")
+			for _, g := range grains {
+				state := "stopped"
+				if g.Running {
+					state = "running"
+				} else if g.Trashed {
+					state = "trashed"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", g.ID, g.Owner, state,
+					formatBytes(g.StorageBytes), g.Title)
+			}
+			tw.Flush()
+		}
+	case "stop-grain":
+		var r cliStopResult
+		method, path, result = http.MethodPost, "/grains/"+url.PathEscape(arg)+"/stop", &r
+		show = func(w io.Writer) {
+			if r.WasRunning {
+				fmt.Fprintf(w, "Stopped %s.\n", arg)
+			} else {
+				fmt.Fprintf(w, "%s wasn't running.\n", arg)
+			}
+		}
+	case "usage":
+		var u cliUsage
+		path, result = "/usage", &u
+		show = func(w io.Writer) {
+			fmt.Fprintf(w, "%d accounts, %d grains (%d running), %s stored\n\n",
+				u.Accounts, u.Grains, u.RunningGrains, formatBytes(u.StorageBytes))
+			tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "ACCOUNT\tGRAINS\tRUNNING\tSTORAGE")
+			for _, a := range u.ByAccount {
+				fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n", a.ID, a.Grains, a.RunningGrains,
+					formatBytes(a.StorageBytes))
+			}
+			tw.Flush()
+		}
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+	needsArg := method == http.MethodPost
+	if needsArg != (flags.NArg() == 2) || flags.NArg() > 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	body, err := adminRequest(localClient(*socket), method, path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *asJSON {
+		os.Stdout.Write(body)
+		return
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		fmt.Fprintln(os.Stderr, "reading the server's response:", err)
+		os.Exit(1)
+	}
+	show(os.Stdout)
+}
+
+// adminRequest makes a request to the admin socket, returning the body of a
+// successful response.
+func adminRequest(client *http.Client, method, path string) ([]byte, error) {
+	req, err := http.NewRequest(method, "http://tempest-admin"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to the server; is it running? %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+// formatBytes formats n bytes for people to read.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,637 @@
+package servermain
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	spk "sandstorm.org/go/tempest/capnp/package"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/apiversion"
+	"sandstorm.org/go/tempest/internal/server/container"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/session"
+	"zenhack.net/go/util/exn"
+)
+
+// How far back the grain start statistics go.
+const grainStartWindow = 24 * time.Hour
+
+// An adminSection is a page of the admin UI, which needs a scope to see.
+type adminSection struct {
+	Path  string
+	Title string
+	Scope types.AdminScope
+}
+
+// adminSections lists the pages of the admin UI, in the order they are shown
+// in its navigation.
+var adminSections = []adminSection{
+	{"/admin/accounts", "Accounts", types.AdminScopeUsers},
+	{"/admin/users", "Admin accounts", types.AdminScopeUsers},
+	{"/admin/invites", "Invites", types.AdminScopeUsers},
+	{"/admin/login-audit", "Login audit log", types.AdminScopeUsers},
+	{"/admin/deprecated-apis", "Deprecated API usage", types.AdminScopeApps},
+	{"/admin/grain-starts", "Grain start times", types.AdminScopeInfrastructure},
+	{"/admin/grain-limits", "Grain resource limits", types.AdminScopeInfrastructure},
+	{"/admin/metrics", "Metrics", types.AdminScopeInfrastructure},
+	{"/admin/dead-letters", "Dead letters", types.AdminScopeInfrastructure},
+}
+
+// visibleAdminSections returns the sections which scopes allow reading.
+func visibleAdminSections(scopes types.AdminScopes) []adminSection {
+	var ret []adminSection
+	for _, section := range adminSections {
+		if scopes.Allows(section.Scope, false) {
+			ret = append(ret, section)
+		}
+	}
+	return ret
+}
+
+// An adminUser is the logged in user making a request to the admin UI.
+type adminUser struct {
+	Credential types.Credential
+	Scopes     types.AdminScopes
+}
+
+// adminUserFor looks up the admin scopes of the user making req. If there is
+// no user, or looking them up fails, it writes an error response and returns
+// false.
+func (s *server) adminUserFor(w http.ResponseWriter, req *http.Request) (adminUser, bool) {
+	var sess session.UserSession
+	if err := session.ReadCookie(s.sessionStore, req, &sess); err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return adminUser{}, false
+	}
+	scopes, err := exn.Try(func(throw exn.Thrower) types.AdminScopes {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		scopes, err := tx.CredentialAdminScopes(sess.Credential)
+		throw(err)
+		return scopes
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Looking up user's admin scopes", "error", err)
+		return adminUser{}, false
+	}
+	return adminUser{Credential: sess.Credential, Scopes: scopes}, true
+}
+
+// requireAdminScope checks that req comes from a user whose admin scopes allow
+// access to scope, for changing things if write is true. If not, it writes an
+// error response and returns false.
+func (s *server) requireAdminScope(w http.ResponseWriter, req *http.Request, scope types.AdminScope, write bool) (adminUser, bool) {
+	user, ok := s.adminUserFor(w, req)
+	if !ok {
+		return user, false
+	}
+	if !user.Scopes.Allows(scope, write) {
+		w.WriteHeader(http.StatusForbidden)
+		return user, false
+	}
+	return user, true
+}
+
+// requireRole checks that req comes from a user with at least the given role.
+// If not, it writes an error response and returns false.
+func (s *server) requireRole(w http.ResponseWriter, req *http.Request, want types.Role) bool {
+	var sess session.UserSession
+	if err := session.ReadCookie(s.sessionStore, req, &sess); err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return false
+	}
+	role, err := exn.Try(func(throw exn.Thrower) types.Role {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		role, err := tx.CredentialRole(sess.Credential)
+		throw(err)
+		return role
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Looking up user's role", "error", err)
+		return false
+	}
+	if !role.Encompasses(want) {
+		w.WriteHeader(http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// adminNavTemplate is the navigation shown at the top of each page of the
+// admin UI, listing the sections the user may see.
+const adminNavTemplate = `{{define "admin-nav"}}<nav>
+<ul>
+	<li><a href="/admin/">Admin</a></li>
+	{{range .}}<li><a href="{{.Path}}">{{.Title}}</a></li>
+	{{end}}
+</ul>
+</nav>{{end}}`
+
+// parseAdminTemplate parses the template for a page of the admin UI, which
+// may use the "admin-nav" template.
+func parseAdminTemplate(name string, funcs template.FuncMap, text string) *template.Template {
+	t := template.Must(template.New(name).Funcs(funcs).Parse(adminNavTemplate))
+	return template.Must(t.Parse(text))
+}
+
+var adminIndexTemplate = parseAdminTemplate("admin-index", nil, `<!doctype html>
+<html>
+<head>
+<meta charset="utf-8" />
+<title>Admin</title>
+</head>
+<body>
+{{template "admin-nav" .Nav}}
+<h1>Admin</h1>
+<p>Your admin scopes: {{range $i, $scope := .Scopes}}{{if $i}}, {{end}}{{$scope}}{{end}}.</p>
+</body>
+</html>
+`)
+
+func (s *server) serveAdminIndex(w http.ResponseWriter, req *http.Request) {
+	user, ok := s.adminUserFor(w, req)
+	if !ok {
+		return
+	}
+	if len(user.Scopes) == 0 {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	adminIndexTemplate.Execute(w, struct {
+		Nav    []adminSection
+		Scopes types.AdminScopes
+	}{
+		Nav:    visibleAdminSections(user.Scopes),
+		Scopes: user.Scopes,
+	})
+}
+
+var adminUsersTemplate = parseAdminTemplate("admin-users", nil, `<!doctype html>
+<html>
+<head>
+<meta charset="utf-8" />
+<title>Admin accounts</title>
+</head>
+<body>
+{{template "admin-nav" .Nav}}
+<h1>Admin accounts</h1>
+<p>Accounts with the admin role have every scope. The audit scope gives
+read-only access to every section.{{if .CanEdit}} You can only grant and revoke
+scopes which you have yourself.{{end}}</p>
+<table>
+	<tr>
+		<th>Account</th>
+		<th>Role</th>
+		<th>Scopes</th>
+	</tr>
+	{{range .Accounts}}
+	<tr>
+		<td><code>{{.ID}}</code></td>
+		<td>{{.Role}}</td>
+		<td>
+		{{if and $.CanEdit (ne .Role "admin")}}
+		{{$account := .}}
+		<form method="POST" action="/admin/users/{{.ID}}/scopes">
+			{{range $.AllScopes}}
+			<label><input type="checkbox" name="scope" value="{{.}}"
+				{{if $account.Scopes.Has .}}checked{{end}}
+				{{if not ($.Mine.Has .)}}disabled{{end}} /> {{.}}</label>
+			{{end}}
+			<button type="submit">Save</button>
+		</form>
+		{{else}}
+		{{range $i, $scope := .Scopes}}{{if $i}}, {{end}}{{$scope}}{{end}}
+		{{end}}
+		</td>
+	</tr>
+	{{end}}
+</table>
+{{if .CanEdit}}
+<h2>Set scopes</h2>
+<form method="POST" action="/admin/users/scopes">
+	<label>Account ID <input type="text" name="account" required /></label>
+	{{range .AllScopes}}
+	<label><input type="checkbox" name="scope" value="{{.}}"
+		{{if not ($.Mine.Has .)}}disabled{{end}} /> {{.}}</label>
+	{{end}}
+	<button type="submit">Set</button>
+</form>
+{{end}}
+</body>
+</html>
+`)
+
+func (s *server) serveAdminUsers(w http.ResponseWriter, req *http.Request) {
+	user, ok := s.requireAdminScope(w, req, types.AdminScopeUsers, false)
+	if !ok {
+		return
+	}
+	accounts, err := exn.Try(func(throw exn.Thrower) []database.AdminAccount {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		accounts, err := tx.AdminAccounts()
+		throw(err)
+		return accounts
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Fetching admin accounts", "error", err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	adminUsersTemplate.Execute(w, struct {
+		Nav       []adminSection
+		Accounts  []database.AdminAccount
+		AllScopes []types.AdminScope
+		Mine      types.AdminScopes
+		CanEdit   bool
+	}{
+		Nav:       visibleAdminSections(user.Scopes),
+		Accounts:  accounts,
+		AllScopes: types.AllAdminScopes,
+		Mine:      user.Scopes,
+		CanEdit:   user.Scopes.Allows(types.AdminScopeUsers, true),
+	})
+}
+
+// serveSetAdminScopes sets the admin scopes of the account named in the URL,
+// or in the form's account field, to the scopes checked in the form. Users
+// may only grant or revoke scopes which they have themselves, so that e.g.
+// support staff can't make themselves infrastructure admins; the account
+// keeps or lacks any other scopes as before.
+func (s *server) serveSetAdminScopes(w http.ResponseWriter, req *http.Request) {
+	user, ok := s.requireAdminScope(w, req, types.AdminScopeUsers, true)
+	if !ok {
+		return
+	}
+	if err := req.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	accountID := types.AccountID(mux.Vars(req)["accountID"])
+	if accountID == "" {
+		accountID = types.AccountID(strings.TrimSpace(req.PostForm.Get("account")))
+	}
+	var scopes types.AdminScopes
+	for _, name := range req.PostForm["scope"] {
+		scope := types.AdminScope(name)
+		if !scope.IsValid() {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "Unknown admin scope %q\n", name)
+			return
+		}
+		if !scopes.Has(scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	status := http.StatusSeeOther
+	err := exn.Try0(func(throw exn.Thrower) {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		role, err := tx.AccountRole(accountID)
+		if errors.Is(err, sql.ErrNoRows) {
+			status = http.StatusNotFound
+			return
+		}
+		throw(err)
+		if role == types.RoleAdmin {
+			// Admins have every scope regardless.
+			status = http.StatusBadRequest
+			return
+		}
+		current, err := tx.AccountAdminScopes(accountID)
+		throw(err)
+		var updated types.AdminScopes
+		for _, scope := range types.AllAdminScopes {
+			if user.Scopes.Has(scope) && scopes.Has(scope) ||
+				!user.Scopes.Has(scope) && current.Has(scope) {
+				updated = append(updated, scope)
+			}
+		}
+		scopes = updated
+		throw(tx.SetAdminScopes(accountID, scopes))
+		throw(tx.Commit())
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Setting admin scopes",
+			"error", err,
+			"accountID", accountID,
+		)
+		return
+	}
+	if status != http.StatusSeeOther {
+		w.WriteHeader(status)
+		return
+	}
+	s.log.InfoCtx(req.Context(), "Admin scopes changed",
+		"accountID", accountID,
+		"scopes", scopes,
+		"changedBy", user.Credential,
+	)
+	http.Redirect(w, req, "/admin/users", http.StatusSeeOther)
+}
+
+// recordGrainStart saves the timing breakdown for a grain which has just
+// started and given its first response. Failures are logged, but otherwise
+// ignored.
+func (s *server) recordGrainStart(tx database.Tx, grainID types.GrainID, c container.Container) {
+	start := database.GrainStart{
+		GrainID:   grainID,
+		PackageID: c.PackageID,
+		StartedAt: c.Timing.Started,
+		Lookup:    c.Timing.Lookup,
+		Sandbox:   c.Timing.Sandbox,
+		AppInit:   time.Since(c.Timing.Ready()),
+	}
+	s.log.Info("Grain started",
+		"grainID", grainID,
+		"packageId", c.PackageID,
+		"lookup-time", start.Lookup,
+		"sandbox-time", start.Sandbox,
+		"warm-sandbox", c.Timing.Warm,
+		"app-init-time", start.AppInit,
+		"total-time", start.Total(),
+	)
+	if err := tx.AddGrainStart(start); err != nil {
+		s.log.Error("Failed to record grain start time",
+			"error", err,
+			"grainID", grainID,
+		)
+	}
+}
+
+func (s *server) packageStartStats() ([]database.PackageStartStats, error) {
+	return exn.Try(func(throw exn.Thrower) []database.PackageStartStats {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		stats, err := tx.PackageStartStats(time.Now().Add(-grainStartWindow))
+		throw(err)
+		return stats
+	})
+}
+
+// appTitle returns the default title of the app in the manifest, or "" if
+// it cannot be read.
+func appTitle(manifest spk.Manifest) string {
+	if !manifest.IsValid() {
+		return ""
+	}
+	title, err := manifest.AppTitle()
+	if err != nil {
+		return ""
+	}
+	text, err := title.DefaultText()
+	if err != nil {
+		return ""
+	}
+	return text
+}
+
+var grainStartsTemplate = parseAdminTemplate("grain-starts", template.FuncMap{
+	"appTitle": appTitle,
+}, `<!doctype html>
+<html>
+<head>
+<meta charset="utf-8" />
+<title>Grain start times</title>
+</head>
+<body>
+{{template "admin-nav" .Nav}}
+<h1>Grain start times</h1>
+<p>Mean time taken to start grains over the last {{.Window}}, by package, slowest first.</p>
+<table>
+	<tr>
+		<th>App</th>
+		<th>Package</th>
+		<th>Starts</th>
+		<th>Lookup</th>
+		<th>Sandbox setup</th>
+		<th>App init</th>
+		<th>Mean total</th>
+		<th>Slowest</th>
+	</tr>
+	{{range .Stats}}
+	<tr>
+		<td>{{appTitle .Manifest}}</td>
+		<td><code>{{.PackageID}}</code></td>
+		<td>{{.Starts}}</td>
+		<td>{{.Lookup}}</td>
+		<td>{{.Sandbox}}</td>
+		<td>{{.AppInit}}</td>
+		<td>{{.Mean}}</td>
+		<td>{{.Max}}</td>
+	</tr>
+	{{end}}
+</table>
+</body>
+</html>
+`)
+
+func (s *server) serveGrainStarts(w http.ResponseWriter, req *http.Request) {
+	user, ok := s.requireAdminScope(w, req, types.AdminScopeInfrastructure, false)
+	if !ok {
+		return
+	}
+	stats, err := s.packageStartStats()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Fetching grain start times", "error", err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	grainStartsTemplate.Execute(w, struct {
+		Nav    []adminSection
+		Window time.Duration
+		Stats  []database.PackageStartStats
+	}{
+		Nav:    visibleAdminSections(user.Scopes),
+		Window: grainStartWindow,
+		Stats:  stats,
+	})
+}
+
+// labelValue returns v quoted as a label value in the Prometheus text
+// exposition format, which escapes only backslashes, double quotes and
+// newlines; Go's %q would escape other characters, e.g. in apps' titles, in
+// ways Prometheus doesn't understand.
+func labelValue(v string) string {
+	return `"` + labelValueEscaper.Replace(v) + `"`
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// serveMetrics serves metrics in the Prometheus text exposition format. It
+// may be accessed either by a user with the infrastructure scope, or with the
+// bearer token in METRICS_TOKEN.
+func (s *server) serveMetrics(w http.ResponseWriter, req *http.Request) {
+	token, hasToken := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if hasToken && s.cfg.MetricsToken != "" {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.MetricsToken)) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	} else if _, ok := s.requireAdminScope(w, req, types.AdminScopeInfrastructure, false); !ok {
+		return
+	}
+	stats, err := s.packageStartStats()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Fetching grain start times", "error", err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprintln(w, "# HELP tempest_grain_starts Number of grain starts in the last 24 hours.")
+	fmt.Fprintln(w, "# TYPE tempest_grain_starts gauge")
+	for _, st := range stats {
+		fmt.Fprintf(w, "tempest_grain_starts{package=%s,app=%s} %d\n",
+			labelValue(st.PackageID), labelValue(appTitle(st.Manifest)), st.Starts)
+	}
+	fmt.Fprintln(w, "# HELP tempest_grain_start_seconds Mean time spent in each phase of starting grains in the last 24 hours.")
+	fmt.Fprintln(w, "# TYPE tempest_grain_start_seconds gauge")
+	for _, st := range stats {
+		phases := []struct {
+			name string
+			d    time.Duration
+		}{
+			{"lookup", st.Lookup},
+			{"sandbox", st.Sandbox},
+			{"app_init", st.AppInit},
+		}
+		for _, p := range phases {
+			fmt.Fprintf(w, "tempest_grain_start_seconds{package=%s,app=%s,phase=%s} %g\n",
+				labelValue(st.PackageID), labelValue(appTitle(st.Manifest)), labelValue(p.name), p.d.Seconds())
+		}
+	}
+	fmt.Fprintln(w, "# HELP tempest_grain_start_max_seconds Slowest grain start in the last 24 hours.")
+	fmt.Fprintln(w, "# TYPE tempest_grain_start_max_seconds gauge")
+	for _, st := range stats {
+		fmt.Fprintf(w, "tempest_grain_start_max_seconds{package=%s,app=%s} %g\n",
+			labelValue(st.PackageID), labelValue(appTitle(st.Manifest)), st.Max.Seconds())
+	}
+	if s.sandboxes != nil {
+		cold, warm := s.sandboxes.Stats()
+		fmt.Fprintln(w, "# HELP tempest_grain_sandbox_seconds Time spent setting up grains' sandboxes since the server started, by whether a warm sandbox was used.")
+		fmt.Fprintln(w, "# TYPE tempest_grain_sandbox_seconds summary")
+		for _, st := range []struct {
+			sandbox string
+			stats   container.StartStats
+		}{
+			{"cold", cold},
+			{"warm", warm},
+		} {
+			fmt.Fprintf(w, "tempest_grain_sandbox_seconds_sum{sandbox=%s} %g\n",
+				labelValue(st.sandbox), st.stats.Sandbox.Seconds())
+			fmt.Fprintf(w, "tempest_grain_sandbox_seconds_count{sandbox=%s} %d\n",
+				labelValue(st.sandbox), st.stats.Starts)
+		}
+	}
+	s.writeMaintenanceMetrics(w)
+	turnUsage, err := exn.Try(func(throw exn.Thrower) []database.TURNUsage {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		usage, err := tx.TURNUsage()
+		throw(err)
+		return usage
+	})
+	if err != nil {
+		s.log.ErrorCtx(req.Context(), "Fetching TURN usage", "error", err)
+		return
+	}
+	fmt.Fprintln(w, "# HELP tempest_turn_credentials_issued_total TURN credentials issued to grains.")
+	fmt.Fprintln(w, "# TYPE tempest_turn_credentials_issued_total counter")
+	for _, u := range turnUsage {
+		fmt.Fprintf(w, "tempest_turn_credentials_issued_total{package=%s,app=%s} %d\n",
+			labelValue(u.PackageID), labelValue(appTitle(u.Manifest)), u.Issued)
+	}
+}
+
+var deprecatedAPIsTemplate = parseAdminTemplate("deprecated-apis", template.FuncMap{
+	"appTitle": appTitle,
+	"lookup":   apiversion.Lookup,
+}, `<!doctype html>
+<html>
+<head>
+<meta charset="utf-8" />
+<title>Deprecated API usage</title>
+</head>
+<body>
+{{template "admin-nav" .Nav}}
+<h1>Deprecated API usage</h1>
+<p>Installed apps which have called deprecated API methods. Apps using methods
+slated for removal will stop working when the server is upgraded past that API
+level; this server implements API level {{.CurrentLevel}}.</p>
+<table>
+	<tr>
+		<th>App</th>
+		<th>Package</th>
+		<th>Method</th>
+		<th>Removed in level</th>
+		<th>Calls</th>
+		<th>Last used</th>
+		<th>Note</th>
+	</tr>
+	{{range .Usage}}
+	{{$info := lookup .Interface .Method}}
+	<tr>
+		<td>{{appTitle .Manifest}}</td>
+		<td><code>{{.PackageID}}</code></td>
+		<td><code>{{.Interface}}.{{.Method}}</code></td>
+		<td>{{if $info.SlatedForRemoval}}{{$info.RemovedIn}}{{else}}not planned{{end}}</td>
+		<td>{{.Calls}}</td>
+		<td>{{.LastUsed}}</td>
+		<td>{{$info.Note}}</td>
+	</tr>
+	{{end}}
+</table>
+</body>
+</html>
+`)
+
+func (s *server) serveDeprecatedAPIs(w http.ResponseWriter, req *http.Request) {
+	user, ok := s.requireAdminScope(w, req, types.AdminScopeApps, false)
+	if !ok {
+		return
+	}
+	usage, err := exn.Try(func(throw exn.Thrower) []database.DeprecatedAPIUsage {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		usage, err := tx.DeprecatedAPIUsage()
+		throw(err)
+		return usage
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Fetching deprecated API usage", "error", err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	deprecatedAPIsTemplate.Execute(w, struct {
+		Nav          []adminSection
+		CurrentLevel apiversion.Level
+		Usage        []database.DeprecatedAPIUsage
+	}{
+		Nav:          visibleAdminSections(user.Scopes),
+		CurrentLevel: apiversion.CurrentLevel,
+		Usage:        usage,
+	})
+}
@@ -0,0 +1,20 @@
+package servermain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabelValue(t *testing.T) {
+	for v, want := range map[string]string{
+		"purge-trash":     `"purge-trash"`,
+		`Say "hi"`:        `"Say \"hi\""`,
+		`C:\Temp`:         `"C:\\Temp"`,
+		"two\nlines":      `"two\nlines"`,
+		"Café\ttab\x00":   "\"Café\ttab\x00\"",
+		"Ünïcödé 日本語 app": `"Ünïcödé 日本語 app"`,
+	} {
+		assert.Equal(t, want, labelValue(v), "label value %q", v)
+	}
+}
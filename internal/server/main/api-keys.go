@@ -0,0 +1,314 @@
+package servermain
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"sandstorm.org/go/tempest/internal/common/apierror"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/session"
+	"sandstorm.org/go/tempest/internal/server/tokenutil"
+	"zenhack.net/go/util/exn"
+)
+
+// Users make API keys for their scripts, e.g. for CI, so that they can use
+// the api host without logging in. Unlike API tokens, which grains offer and
+// which each grant access to one grain, a key acts for the user who made it,
+// on the grains they chose when making it, and, if they allowed it, reads
+// their account. A key is sent like an API token, and the api host tells them
+// apart by database.APIKeyPrefix. The endpoints are:
+//
+//   - GET /account: the user's apiKeyAccount.
+//   - GET /account/grains: the user's grains, as []apiKeyGrain.
+//   - /grains/<grainID>/<path>: <path> on the grain's API, as with an API
+//     token, with the permissions the user has on the grain.
+//
+// Users list, make and revoke their keys with serveAPIKeys. Keys stop
+// working when their accounts are deactivated.
+
+// apiKeyAccount is what GET /account sends.
+type apiKeyAccount struct {
+	ID              types.AccountID `json:"id"`
+	DisplayName     string          `json:"displayName"`
+	PreferredHandle string          `json:"preferredHandle"`
+	Role            types.Role      `json:"role"`
+}
+
+// apiKeyGrain is one of the grains GET /account/grains sends.
+type apiKeyGrain struct {
+	ID      types.GrainID `json:"id"`
+	Title   string        `json:"title"`
+	Owned   bool          `json:"owned"`
+	Trashed bool          `json:"trashed"`
+
+	// Disk space used by the grain, in bytes, as last measured; only
+	// sent for grains the user owns.
+	StorageBytes int64 `json:"storageBytes,omitempty"`
+}
+
+// serveAPIKeys handles requests from the user's browser to list, make and
+// revoke their API keys. GET sends their []types.APIKey, POST makes a key
+// from a types.NewAPIKey and sends a types.APIKeyOffer, and DELETE, with the
+// key's ID in the URL, revokes it.
+func (s *server) serveAPIKeys(w http.ResponseWriter, req *http.Request) {
+	var sess session.UserSession
+	if err := session.ReadCookie(s.sessionStore, req, &sess); err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "failed to open database transaction",
+			"error", err)
+		return
+	}
+	defer tx.Rollback()
+	accountID, err := tx.CredentialAccount(sess.Credential)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch req.Method {
+	case http.MethodGet:
+		keys, err := tx.AccountAPIKeys(accountID)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			s.log.ErrorCtx(req.Context(), "Reading API keys",
+				"error", err,
+				"accountID", accountID,
+			)
+			return
+		}
+		if keys == nil {
+			keys = []types.APIKey{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(keys)
+	case http.MethodPost:
+		s.createAPIKey(w, req, tx, accountID)
+	case http.MethodDelete:
+		keyID := mux.Vars(req)["keyID"]
+		err := tx.DeleteAPIKey(accountID, keyID)
+		if err == nil {
+			err = tx.Commit()
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			w.WriteHeader(http.StatusNotFound)
+		} else if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			s.log.ErrorCtx(req.Context(), "Revoking API key",
+				"error", err,
+				"accountID", accountID,
+			)
+		} else {
+			s.log.InfoCtx(req.Context(), "API key revoked",
+				"accountID", accountID,
+				"keyID", keyID,
+			)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+}
+
+func (s *server) createAPIKey(w http.ResponseWriter, req *http.Request, tx database.Tx, accountID types.AccountID) {
+	var want types.NewAPIKey
+	err := json.NewDecoder(http.MaxBytesReader(w, req.Body, 64*1024)).Decode(&want)
+	if err != nil {
+		s.writeLocalizedError(w, req, http.StatusBadRequest, apierror.New(
+			apierror.CodeInvalidArgument, "malformed request: "+err.Error(), "request"))
+		return
+	}
+	if len(want.Grains) == 0 && !want.AccountRead {
+		s.writeLocalizedError(w, req, http.StatusBadRequest, apierror.New(
+			apierror.CodeInvalidArgument, "the key must be allowed to use something", "grains"))
+		return
+	}
+	for _, grainID := range want.Grains {
+		_, err := accountGrainPermissions(tx, accountID, grainID)
+		if errors.Is(err, sql.ErrNoRows) {
+			s.writeLocalizedError(w, req, http.StatusBadRequest, apierror.New(
+				apierror.CodeNotFound, "no such grain: "+string(grainID), "grain"))
+			return
+		} else if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			s.log.ErrorCtx(req.Context(), "Looking up grain",
+				"error", err,
+				"grainID", grainID,
+			)
+			return
+		}
+	}
+	apiKey := types.APIKey{
+		ID:          hex.EncodeToString(tokenutil.Gen128()),
+		AccountID:   accountID,
+		Name:        want.Name,
+		Grains:      want.Grains,
+		AccountRead: want.AccountRead,
+		Created:     time.Now(),
+	}
+	token, err := tx.NewAPIKey(apiKey)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Making API key",
+			"error", err,
+			"accountID", accountID,
+		)
+		return
+	}
+	s.log.InfoCtx(req.Context(), "API key made",
+		"accountID", accountID,
+		"keyID", apiKey.ID,
+		"grains", len(apiKey.Grains),
+		"accountRead", apiKey.AccountRead,
+	)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(types.APIKeyOffer{
+		Key:   apiKey,
+		Token: token,
+	})
+}
+
+// serveAPIKey serves a request to the api host made with an API key.
+func (s *server) serveAPIKey(w http.ResponseWriter, req *http.Request, token string) {
+	key, err := exn.Try(func(throw exn.Thrower) types.APIKey {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		key, err := tx.RestoreAPIKey(token, time.Now())
+		throw(err)
+		throw(tx.Commit())
+		return key
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "invalid or revoked API key", http.StatusForbidden)
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Restoring API key", "error", err)
+		return
+	}
+	switch path := req.URL.Path; {
+	case path == "/account" || path == "/account/grains":
+		s.serveAPIKeyAccount(w, req, key)
+	case strings.HasPrefix(path, "/grains/"):
+		s.serveAPIKeyGrain(w, req, token, key)
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
+
+// serveAPIKeyAccount serves the endpoints which read the key's account.
+func (s *server) serveAPIKeyAccount(w http.ResponseWriter, req *http.Request, key types.APIKey) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !key.AccountRead {
+		http.Error(w, "this API key may not read your account", http.StatusForbidden)
+		return
+	}
+	result, err := exn.Try(func(throw exn.Thrower) any {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		if req.URL.Path == "/account" {
+			c, err := tx.AccountContact(key.AccountID)
+			throw(err)
+			role, err := tx.AccountRole(key.AccountID)
+			throw(err)
+			return apiKeyAccount{
+				ID:              key.AccountID,
+				DisplayName:     c.DisplayName,
+				PreferredHandle: c.PreferredHandle,
+				Role:            role,
+			}
+		}
+		views, err := tx.AccountKeyring(key.AccountID).AllUiViews()
+		throw(err)
+		grains := []apiKeyGrain{}
+		for _, v := range views {
+			g := apiKeyGrain{
+				ID:      v.Grain.ID,
+				Title:   v.Grain.Title,
+				Owned:   v.Grain.Owner == string(key.AccountID),
+				Trashed: !v.Grain.Trashed.IsZero(),
+			}
+			if g.Owned {
+				g.StorageBytes = v.Grain.StorageBytes
+			}
+			grains = append(grains, g)
+		}
+		slices.SortFunc(grains, func(a, b apiKeyGrain) int {
+			return strings.Compare(string(a.ID), string(b.ID))
+		})
+		return grains
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Reading account for API key",
+			"error", err,
+			"accountID", key.AccountID,
+		)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(result)
+}
+
+// serveAPIKeyGrain passes a request for /grains/<grainID>/<path> to the
+// grain's API, as a request for /<path>, if the key may use the grain.
+func (s *server) serveAPIKeyGrain(w http.ResponseWriter, req *http.Request, token string, key types.APIKey) {
+	rest := strings.TrimPrefix(req.RequestURI, "/grains/")
+	i := strings.IndexAny(rest, "/?")
+	if i < 0 {
+		i = len(rest)
+	}
+	grainID := types.GrainID(rest[:i])
+	if !slices.Contains(key.Grains, grainID) {
+		http.Error(w, "this API key may not use that grain", http.StatusNotFound)
+		return
+	}
+	perms, err := exn.Try(func(throw exn.Thrower) []bool {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		perms, err := accountGrainPermissions(tx, key.AccountID, grainID)
+		throw(err)
+		return perms
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// The grain is gone, or the user no longer has access.
+		http.Error(w, "this API key may not use that grain", http.StatusNotFound)
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Looking up grain permissions",
+			"error", err,
+			"grainID", grainID,
+		)
+		return
+	}
+	req.RequestURI = "/" + strings.TrimPrefix(rest[i:], "/")
+	s.serveAPISession(w, req, token, database.SharingToken{
+		GrainID:     grainID,
+		Permissions: perms,
+		Note:        key.Name,
+	})
+}
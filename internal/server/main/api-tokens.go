@@ -0,0 +1,306 @@
+package servermain
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"sandstorm.org/go/tempest/internal/common/apierror"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/session"
+	"sandstorm.org/go/tempest/internal/server/tokenutil"
+	"zenhack.net/go/util/sync/mutex"
+)
+
+// Grains offer API tokens as in Sandstorm, from their client side, with the
+// postMessage API's renderTemplate:
+//
+//  1. The grain sends the browser a template, such as a command line which
+//     uses the API, with $API_TOKEN and $API_HOST where the token and the
+//     api host's origin go, and optionally the role to grant.
+//  2. The browser sends it to serveGrainAPITokens, which makes the token,
+//     renders the template, and replies with the URL of a page showing it.
+//  3. The browser passes the URL to the grain, which shows the page to the
+//     user in an iframe. The grain can't read the page, so it never learns
+//     the token.
+//
+// Clients then use the token on the api host; see serveAPI. Users may list
+// and revoke the tokens they have made for a grain, and its owner those of
+// everyone.
+
+const (
+	// offerTTL is how long the page showing a new token lasts.
+	offerTTL = time.Hour
+
+	// defaultOfferTemplate is rendered if the grain gives no template: a
+	// "webkey", as Sandstorm's clients accept.
+	defaultOfferTemplate = "$API_HOST#$API_TOKEN"
+)
+
+// A renderedOffer is a rendered template showing a new API token, which only
+// the user who made it may see.
+type renderedOffer struct {
+	accountID types.AccountID
+	text      string
+	expires   time.Time
+}
+
+// apiOrigin returns the origin of the api host, e.g. "https://api.example.com".
+func (s *server) apiOrigin() string {
+	scheme := "http"
+	if s.cfg.HTTP.DefaultTLS {
+		scheme = "https"
+	}
+	return scheme + "://" + apiHostPrefix + s.cfg.HTTP.RootDomain
+}
+
+// serveGrainAPITokens handles requests to list, make and revoke API tokens
+// for the grain named in the URL. GET sends the []types.APIToken the user may
+// see, POST makes a token from a types.NewAPIToken and sends a
+// types.APITokenOffer, and DELETE, with the token's ID in the URL, revokes it.
+func (s *server) serveGrainAPITokens(w http.ResponseWriter, req *http.Request) {
+	var sess session.UserSession
+	if err := session.ReadCookie(s.sessionStore, req, &sess); err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(req)
+	grainID := types.GrainID(vars["grainID"])
+	tx, err := s.db.Begin()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "failed to open database transaction",
+			"error", err)
+		return
+	}
+	defer tx.Rollback()
+	accountID, err := tx.CredentialAccount(sess.Credential)
+	var (
+		perms []bool
+		info  database.GrainInfo
+	)
+	if err == nil {
+		perms, err = accountGrainPermissions(tx, accountID, grainID)
+	}
+	if err == nil {
+		info, err = tx.GrainInfo(grainID)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		// No such grain, or the user can't see it.
+		w.WriteHeader(http.StatusNotFound)
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Looking up grain",
+			"error", err,
+			"grainID", grainID,
+		)
+		return
+	}
+	isOwner := info.Owner == string(accountID)
+	switch req.Method {
+	case http.MethodGet:
+		s.listGrainAPITokens(w, tx, grainID, accountID, isOwner)
+	case http.MethodPost:
+		s.createGrainAPIToken(w, req, tx, grainID, accountID, perms)
+	case http.MethodDelete:
+		t, err := tx.DeleteAPIToken(grainID, vars["tokenID"])
+		if err == nil && t.AccountID != accountID && !isOwner {
+			err = sql.ErrNoRows
+		}
+		if err == nil {
+			err = tx.Commit()
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			w.WriteHeader(http.StatusNotFound)
+		} else if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			s.log.ErrorCtx(req.Context(), "Revoking API token",
+				"error", err,
+				"grainID", grainID,
+			)
+		} else {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+}
+
+func (s *server) listGrainAPITokens(w http.ResponseWriter, tx database.Tx, grainID types.GrainID, accountID types.AccountID, isOwner bool) {
+	tokens, err := tx.GrainAPITokens(grainID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.Error("Reading API tokens",
+			"error", err,
+			"grainID", grainID,
+		)
+		return
+	}
+	list := []types.APIToken{}
+	for _, t := range tokens {
+		t.Mine = t.AccountID == accountID
+		if t.Mine || isOwner {
+			list = append(list, t)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(list)
+}
+
+func (s *server) createGrainAPIToken(w http.ResponseWriter, req *http.Request, tx database.Tx, grainID types.GrainID, accountID types.AccountID, perms []bool) {
+	var want types.NewAPIToken
+	err := json.NewDecoder(http.MaxBytesReader(w, req.Body, 64*1024)).Decode(&want)
+	if err != nil {
+		s.writeLocalizedError(w, req, http.StatusBadRequest, apierror.New(
+			apierror.CodeInvalidArgument, "malformed request: "+err.Error(), "request"))
+		return
+	}
+	if want.NoAccess {
+		perms = make([]bool, len(perms))
+	} else if want.RoleID != nil {
+		roles, err := tx.GrainRolePermissions(grainID)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			s.log.ErrorCtx(req.Context(), "Reading grain roles",
+				"error", err,
+				"grainID", grainID,
+			)
+			return
+		}
+		if *want.RoleID < 0 || *want.RoleID >= len(roles) {
+			s.writeLocalizedError(w, req, http.StatusBadRequest, apierror.New(
+				apierror.CodeInvalidArgument, "the grain's app defines no such role", "role"))
+			return
+		}
+		// The token grants no more than the user has.
+		role := roles[*want.RoleID]
+		for i := range perms {
+			perms[i] = perms[i] && i < len(role) && role[i]
+		}
+	}
+	if want.Template == "" {
+		want.Template = defaultOfferTemplate
+	}
+	now := time.Now()
+	apiToken := types.APIToken{
+		ID:          hex.EncodeToString(tokenutil.Gen128()),
+		GrainID:     grainID,
+		AccountID:   accountID,
+		Petname:     want.Petname,
+		Permissions: perms,
+		Created:     now,
+		Mine:        true,
+	}
+	token, err := tx.NewAPIToken(apiToken)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Making API token",
+			"error", err,
+			"grainID", grainID,
+		)
+		return
+	}
+	s.events.Publish(types.GrainEvent{
+		GrainID: grainID,
+		Kind:    types.GrainShared,
+		Detail:  want.Petname,
+	})
+
+	offerID := hex.EncodeToString(tokenutil.Gen128())
+	text := strings.NewReplacer(
+		"$API_TOKEN", token,
+		"$API_HOST", s.apiOrigin(),
+	).Replace(want.Template)
+	s.state.With(func(state *serverState) {
+		for id, o := range state.offers {
+			if !now.Before(o.expires) {
+				delete(state.offers, id)
+			}
+		}
+		state.offers[offerID] = renderedOffer{
+			accountID: accountID,
+			text:      text,
+			expires:   now.Add(offerTTL),
+		}
+	})
+	u := url.URL{
+		Scheme: "http",
+		Host:   s.cfg.HTTP.RootDomain,
+		Path:   "/offer-template/" + offerID,
+	}
+	if s.cfg.HTTP.DefaultTLS {
+		u.Scheme = "https"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(types.APITokenOffer{
+		Token: apiToken,
+		URL:   u.String(),
+	})
+}
+
+var offerPage = template.Must(template.New("offer").Parse(`<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<style>
+body { margin: 0; font-family: monospace; }
+pre { margin: 0; white-space: pre-wrap; word-break: break-all; user-select: all; }
+</style>
+</head>
+<body><pre>{{.}}</pre></body>
+</html>
+`))
+
+// serveOfferTemplate serves the page showing a new API token, which grains
+// show in an iframe. Only the user who made the token may see it.
+func (s *server) serveOfferTemplate(w http.ResponseWriter, req *http.Request) {
+	var sess session.UserSession
+	if err := session.ReadCookie(s.sessionStore, req, &sess); err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	id := mux.Vars(req)["offerID"]
+	o := mutex.With1(&s.state, func(state *serverState) renderedOffer {
+		return state.offers[id]
+	})
+	if !time.Now().Before(o.expires) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+	accountID, err := tx.CredentialAccount(sess.Credential)
+	if err != nil || accountID != o.accountID {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	scheme := "http"
+	if s.cfg.HTTP.DefaultTLS {
+		scheme = "https"
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	// The page is framed by the grain which offered the token.
+	w.Header().Set("Content-Security-Policy",
+		"default-src 'none'; style-src 'unsafe-inline'; frame-ancestors "+
+			scheme+"://*."+s.cfg.HTTP.RootDomain)
+	w.Header().Del("X-Frame-Options")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	offerPage.Execute(w, o.text)
+}
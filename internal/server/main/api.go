@@ -0,0 +1,196 @@
+package servermain
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	apisession "sandstorm.org/go/tempest/capnp/api-session"
+	"sandstorm.org/go/tempest/capnp/grain"
+	hacksession "sandstorm.org/go/tempest/capnp/hack-session"
+	websessioncp "sandstorm.org/go/tempest/capnp/web-session"
+	"sandstorm.org/go/tempest/internal/server/container"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/pkg/exp/websession"
+	"zenhack.net/go/util/exn"
+	"zenhack.net/go/util/orerr"
+	"zenhack.net/go/util/sync/mutex"
+	"zenhack.net/go/util/thunk"
+)
+
+// apiHostPrefix is prepended to the root domain to get the host serving
+// Sandstorm's HTTP API.
+const apiHostPrefix = "api."
+
+// apiCORSMethods are the methods browsers may use in cross-origin requests to
+// the API: those WebSession supports.
+const apiCORSMethods = "GET, HEAD, POST, PUT, DELETE, PATCH, OPTIONS, " +
+	"PROPFIND, PROPPATCH, MKCOL, COPY, MOVE"
+
+// serveAPI serves Sandstorm's HTTP API. Clients authenticate with an API
+// token, sent as "Authorization: Bearer <token>", or as the password for HTTP
+// basic auth, for clients which support nothing else, e.g. git. API tokens
+// are sharing tokens; the request is passed to an ApiSession on the token's
+// grain, with the token's permissions. Clients may instead authenticate with
+// a user's API key; see serveAPIKey.
+func (s *server) serveAPI(w http.ResponseWriter, req *http.Request) {
+	// Any site may use the API: the token is the only credential, and
+	// browsers never send it on their own.
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if req.Method == http.MethodOptions && req.Header.Get("Access-Control-Request-Method") != "" {
+		w.Header().Set("Access-Control-Allow-Methods", apiCORSMethods)
+		if headers := req.Header.Get("Access-Control-Request-Headers"); headers != "" {
+			w.Header().Set("Access-Control-Allow-Headers", headers)
+		}
+		w.Header().Set("Access-Control-Max-Age", "3600")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	token, ok := apiToken(req)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Basic realm="Sandstorm API"`)
+		http.Error(w, "missing API token", http.StatusUnauthorized)
+		return
+	}
+	if strings.HasPrefix(token, database.APIKeyPrefix) {
+		s.serveAPIKey(w, req, token)
+		return
+	}
+	st, err := exn.Try(func(throw exn.Thrower) database.SharingToken {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		st, err := tx.RestoreSharingToken(token)
+		throw(err)
+		return st
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "invalid or expired API token", http.StatusForbidden)
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Restoring API token", "error", err)
+		return
+	}
+	s.serveAPISession(w, req, token, st)
+}
+
+// serveAPISession passes an API request to an ApiSession on st's grain, with
+// st's permissions; see getAPISession.
+func (s *server) serveAPISession(w http.ResponseWriter, req *http.Request, token string, st database.SharingToken) {
+	endRequest, ok := s.beginGrainHTTPRequest(w, req, st.GrainID)
+	if !ok {
+		return
+	}
+	defer endRequest()
+	session, err := s.getAPISession(req.Context(), token, st)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Could not get API session",
+			"error", err,
+			"grainID", st.GrainID,
+		)
+		return
+	}
+	defer session.Release()
+
+	// The grain mustn't see the token, and cookies for the API host
+	// aren't the grain's.
+	req.Header.Del("Authorization")
+	req.Header.Del("Cookie")
+	// Responses are data for the client, not pages to render; the API
+	// host's Content-Security-Policy keeps them from running scripts on
+	// its origin.
+	websession.Handler{Session: session}.ServeHTTP(w, req)
+}
+
+// apiToken returns the API token sent with the request, if any.
+func apiToken(req *http.Request) (string, bool) {
+	if token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); ok {
+		token = strings.TrimSpace(token)
+		return token, token != ""
+	}
+	if _, password, ok := req.BasicAuth(); ok && password != "" {
+		return password, true
+	}
+	return "", false
+}
+
+// getAPISession returns an ApiSession on the token's grain, starting the
+// grain if need be. Requests with the same token share a session.
+func (s *server) getAPISession(ctx context.Context, token string, st database.SharingToken) (websessioncp.WebSession, error) {
+	c, err := s.startGrain(st.GrainID)
+	if err != nil {
+		return websessioncp.WebSession{}, err
+	}
+	hash := sha256.Sum256([]byte(token))
+	key := grainSessionKey{
+		grainID:      st.GrainID,
+		apiTokenHash: string(hash[:]),
+	}
+	webSessionThunk := mutex.With1(&s.state, func(state *serverState) *thunk.Thunk[orerr.OrErr[websessioncp.WebSession]] {
+		gs, ok := state.grainSessions[key]
+		if ok {
+			return gs.webSession
+		}
+		webSessionThunk := thunk.Go(func() orerr.OrErr[websessioncp.WebSession] {
+			return orerr.New(s.newAPISession(ctx, c, st, hash))
+		})
+		state.grainSessions[key] = grainSession{
+			webSession: webSessionThunk,
+		}
+		return webSessionThunk
+	})
+	webSession, err := webSessionThunk.Force().Get()
+	return webSession.AddRef(), err
+}
+
+// newAPISession opens an ApiSession on the grain running in c, with the
+// token's permissions. As in Sandstorm, the session's tab ID is derived from
+// the token, and the client's address is not revealed.
+func (s *server) newAPISession(ctx context.Context, c container.Container, st database.SharingToken, tokenHash [sha256.Size]byte) (websessioncp.WebSession, error) {
+	return exn.Try(func(throw exn.Thrower) websessioncp.WebSession {
+		mainView := grain.MainView(c.Bootstrap.AddRef())
+		defer mainView.Release()
+		sessionCtx := grain.SessionContext(hacksession.HackSessionContext_ServerToClient(sessionCtxImpl{
+			server:   s,
+			grainID:  st.GrainID,
+			apiUsage: s.apiUsage,
+		}))
+
+		viewInfoFut, rel := mainView.GetViewInfo(ctx, nil)
+		defer rel()
+		viewInfo, err := viewInfoFut.Struct()
+		throw(err)
+		viewInfoPermissions, err := viewInfo.Permissions()
+		throw(err)
+
+		newSessionFut, rel := mainView.NewSession(
+			ctx,
+			func(p grain.UiView_newSession_Params) error {
+				return exn.Try0(func(throw exn.Thrower) {
+					userInfo, err := p.NewUserInfo()
+					throw(err)
+					userPermissions, err := userInfo.NewPermissions(int32(viewInfoPermissions.Len()))
+					throw(err)
+					for i := 0; i < userPermissions.Len(); i++ {
+						userPermissions.Set(i, i < len(st.Permissions) && st.Permissions[i])
+					}
+					p.SetSessionType(apisession.ApiSession_TypeID)
+					p.SetContext(sessionCtx)
+					throw(p.SetTabId(tokenHash[:16]))
+					params, err := apisession.NewApiSession_Params(p.Segment())
+					throw(err)
+					throw(p.SetSessionParams(params.ToPtr()))
+				})
+			})
+		defer rel()
+		newSessionRes, err := newSessionFut.Struct()
+		throw(err)
+		return websessioncp.WebSession(newSessionRes.Session().AddRef())
+	})
+}
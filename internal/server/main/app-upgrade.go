@@ -0,0 +1,281 @@
+package servermain
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"sandstorm.org/go/tempest/capnp/grain"
+	"sandstorm.org/go/tempest/internal/common/apierror"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/session"
+	"zenhack.net/go/util/exn"
+)
+
+// When a newer version of an app is installed, the owners of its grains may
+// upgrade them to it, one at a time or all at once. Upgrading a grain stops
+// it, switches it to the new package, and starts it again, with the grain
+// agent telling the app which version it was upgraded from, so that it can
+// migrate the grain's data; see appenv.EnvPreviousAppVersion.
+//
+// The upgrade isn't final until the new version has started: if it doesn't
+// answer within upgradeStartTimeout, or stops within upgradeSettleTime, the
+// grain goes back to the package it ran before. Until then, any start of the
+// grain, e.g. by its scheduled jobs, runs the migration; upgrades cut short
+// by the server stopping are rolled back when it starts again.
+
+const (
+	// How long the new version of an upgraded grain's app has to start
+	// and answer.
+	upgradeStartTimeout = time.Minute
+
+	// How long the new version has to keep running once it has answered,
+	// since e.g. sandstorm-http-bridge answers before the app has
+	// started, and exits if the app does.
+	upgradeSettleTime = 5 * time.Second
+
+	// How many grains are upgraded at once when a user upgrades all of
+	// them.
+	maxConcurrentUpgrades = 4
+)
+
+var (
+	errUpgradeCrashed = errors.New("grain stopped unexpectedly after upgrading")
+	errUpgradeStopped = errors.New("grain was shut down while upgrading")
+)
+
+// upgradeGrain upgrades the grain to the newest installed version of its app,
+// on behalf of the user with the given credential, who must own it, and waits
+// for the new version to start. If it doesn't, the grain is rolled back, which
+// the result reports.
+func (s *server) upgradeGrain(by types.Credential, grainID types.GrainID) (types.GrainUpgradeResult, error) {
+	result := types.GrainUpgradeResult{GrainID: grainID}
+	var upgrade types.GrainUpgrade
+	err := s.changeOwnGrain(by, grainID, func(tx database.Tx, _ database.GrainInfo) error {
+		var err error
+		upgrade, err = tx.GrainUpgrade(grainID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return apierror.New(apierror.CodeNotFound,
+				"no newer version of the grain's app is installed",
+				"newer version of the app")
+		} else if err != nil {
+			return err
+		}
+		return tx.StartGrainUpgrade(grainID, upgrade.ToPackageID)
+	})
+	if err != nil {
+		return result, err
+	}
+	s.log.Info("Upgrading grain",
+		"grainID", grainID,
+		"appID", upgrade.AppID,
+		"fromVersion", upgrade.FromVersion,
+		"toVersion", upgrade.ToVersion,
+	)
+	// The grain starts again with the new package from here on.
+	s.stopGrain(grainID)
+	startErr := s.checkUpgradedGrain(grainID)
+	if startErr == nil {
+		err = s.finishGrainUpgrade(grainID)
+	} else {
+		s.log.Warn("Upgraded grain failed to start; rolling back",
+			"error", startErr,
+			"grainID", grainID,
+			"toVersion", upgrade.ToVersion,
+		)
+		err = s.rollBackGrainUpgrade(grainID)
+		result.RolledBack = true
+	}
+	if err != nil {
+		return result, err
+	}
+	kind := types.GrainMigrated
+	if result.RolledBack {
+		kind = types.GrainUpgradeRolledBack
+	}
+	s.events.Publish(types.GrainEvent{
+		GrainID: grainID,
+		Kind:    kind,
+		Detail:  upgrade.ToMarketingVersion,
+	})
+	return result, nil
+}
+
+// checkUpgradedGrain starts the grain, which is being upgraded, and returns
+// an error if its new version doesn't start and keep running.
+func (s *server) checkUpgradedGrain(grainID types.GrainID) error {
+	c, err := s.startGrain(grainID)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), upgradeStartTimeout)
+	defer cancel()
+	mainView := grain.MainView(c.Bootstrap.AddRef())
+	defer mainView.Release()
+	fut, rel := mainView.GetViewInfo(ctx, nil)
+	defer rel()
+	if _, err := fut.Struct(); err != nil {
+		return err
+	}
+	select {
+	case <-time.After(upgradeSettleTime):
+		return nil
+	case <-c.Crashed():
+		return errUpgradeCrashed
+	case <-c.Exited():
+		return errUpgradeStopped
+	}
+}
+
+// finishGrainUpgrade keeps the grain on the version of its app it was
+// upgraded to.
+func (s *server) finishGrainUpgrade(grainID types.GrainID) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		throw(tx.FinishGrainUpgrade(grainID))
+		throw(tx.Commit())
+	})
+}
+
+// rollBackGrainUpgrade returns the grain to the package it ran before it was
+// upgraded, and stops the new one.
+func (s *server) rollBackGrainUpgrade(grainID types.GrainID) error {
+	err := exn.Try0(func(throw exn.Thrower) {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		throw(tx.RollBackGrainUpgrade(grainID))
+		throw(tx.Commit())
+	})
+	// Stop the new version after switching back, so that it isn't started
+	// again in between.
+	s.stopGrain(grainID)
+	return err
+}
+
+// rollBackInterruptedUpgrades rolls back the upgrades which were cut short by
+// the server stopping. It is called on startup, before any grains start.
+func (s *server) rollBackInterruptedUpgrades() {
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.log.Error("Looking for interrupted grain upgrades", "error", err)
+		return
+	}
+	grainIDs, err := tx.PendingGrainUpgrades()
+	tx.Rollback()
+	if err != nil {
+		s.log.Error("Looking for interrupted grain upgrades", "error", err)
+		return
+	}
+	for _, grainID := range grainIDs {
+		if err := s.rollBackGrainUpgrade(grainID); err != nil {
+			s.log.Error("Rolling back interrupted grain upgrade",
+				"error", err,
+				"grainID", grainID,
+			)
+			continue
+		}
+		s.log.Warn("Rolled back interrupted grain upgrade", "grainID", grainID)
+		s.events.Publish(types.GrainEvent{
+			GrainID: grainID,
+			Kind:    types.GrainUpgradeRolledBack,
+		})
+	}
+}
+
+// upgradeAccountGrains upgrades all of the grains owned by the user with the
+// given credential which have a newer version of their app installed, or if
+// appID isn't empty, those of that app.
+func (s *server) upgradeAccountGrains(by types.Credential, appID string) ([]types.GrainUpgradeResult, error) {
+	upgrades, err := exn.Try(func(throw exn.Thrower) []types.GrainUpgrade {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		accountID, err := tx.CredentialAccount(by)
+		throw(err)
+		upgrades, err := tx.AccountGrainUpgrades(accountID)
+		throw(err)
+		return upgrades
+	})
+	if err != nil {
+		return nil, err
+	}
+	var grainIDs []types.GrainID
+	for _, u := range upgrades {
+		if appID == "" || u.AppID == appID {
+			grainIDs = append(grainIDs, u.GrainID)
+		}
+	}
+	var (
+		results = make([]types.GrainUpgradeResult, len(grainIDs))
+		errs    = make([]error, len(grainIDs))
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, maxConcurrentUpgrades)
+	)
+	for i, grainID := range grainIDs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, grainID types.GrainID) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i], errs[i] = s.upgradeGrain(by, grainID)
+		}(i, grainID)
+	}
+	wg.Wait()
+	return results, errors.Join(errs...)
+}
+
+// serveGrainUpgrades handles requests about upgrading the user's grains to
+// newer versions of their apps. GET sends the []types.GrainUpgrade available
+// for the grains they own. POST, with a grain's ID in the URL, upgrades it,
+// and sends a types.GrainUpgradeResult; without one, it upgrades all of them,
+// or with ?appId=, those of that app, and sends a []types.GrainUpgradeResult.
+func (s *server) serveGrainUpgrades(w http.ResponseWriter, req *http.Request) {
+	var sess session.UserSession
+	if err := session.ReadCookie(s.sessionStore, req, &sess); err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var result any
+	err := exn.Try0(func(throw exn.Thrower) {
+		if req.Method == http.MethodGet {
+			tx, err := s.db.Begin()
+			throw(err)
+			defer tx.Rollback()
+			accountID, err := tx.CredentialAccount(sess.Credential)
+			throw(err)
+			upgrades, err := tx.AccountGrainUpgrades(accountID)
+			throw(err)
+			if upgrades == nil {
+				upgrades = []types.GrainUpgrade{}
+			}
+			result = upgrades
+		} else if grainID, ok := mux.Vars(req)["grainID"]; ok {
+			r, err := s.upgradeGrain(sess.Credential, types.GrainID(grainID))
+			throw(err)
+			result = r
+		} else {
+			results, err := s.upgradeAccountGrains(sess.Credential, req.URL.Query().Get("appId"))
+			throw(err)
+			if results == nil {
+				results = []types.GrainUpgradeResult{}
+			}
+			result = results
+		}
+	})
+	if err != nil {
+		s.writeAPIError(w, req, err, "Upgrading grains")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(result)
+}
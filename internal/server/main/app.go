@@ -2,6 +2,7 @@ package servermain
 
 import (
 	"net/http"
+	"strings"
 
 	websessioncp "sandstorm.org/go/tempest/capnp/web-session"
 	"sandstorm.org/go/tempest/pkg/exp/websession"
@@ -12,14 +13,18 @@ func ServeApp(
 	w http.ResponseWriter,
 	req *http.Request,
 	rootHost string,
+	frameAncestors []string,
 ) {
-	w.Header().Set(
+	// Added, so that the grain profile's policy, if any, is enforced
+	// as well; see GRAIN_CONTENT_SECURITY_POLICY.
+	w.Header().Add(
 		"Content-Security-Policy",
 		// TODO(perf): refactor so we can call this once on startup,
 		// and not have to reconstruct the string on every request:
 		uiContentSecurityPolicy(
 			req.URL.Scheme == "https",
 			rootHost,
+			frameAncestors,
 		),
 	)
 
@@ -30,7 +35,9 @@ func ServeApp(
 
 // uiContentSecurityPolicy returns a content security policy that disallows
 // loading of remote resources. It accepts as arguments whether or not we're
-// using https, and the root domain name.
+// using https, the root domain name, and, for grains embedded in external
+// sites, the origins allowed to frame the grain; if frameAncestors is nil,
+// only Tempest's UI, and the grain itself, may frame it.
 //
 // Note the following:
 //
@@ -44,7 +51,7 @@ func ServeApp(
 // - In the future, we should provide a way for apps to opt-in to more
 //   restrictive policies, as a useful mitigation for things like XSS vulns.
 //   in the apps.
-func uiContentSecurityPolicy(isSecure bool, rootHost string) string {
+func uiContentSecurityPolicy(isSecure bool, rootHost string, frameAncestors []string) string {
 	const unsafe = "'unsafe-inline' 'unsafe-eval' data: blob:; "
 	rootHttpHost := "http"
 	wsHost := "ws"
@@ -54,6 +61,13 @@ func uiContentSecurityPolicy(isSecure bool, rootHost string) string {
 	}
 	rootHttpHost += "://" + rootHost
 	wsHost += "://" + rootHost
+	ancestors := " frame-ancestors 'self' " + rootHttpHost + ";"
+	if frameAncestors != nil {
+		ancestors = " frame-ancestors 'none';"
+		if len(frameAncestors) > 0 {
+			ancestors = " frame-ancestors " + strings.Join(frameAncestors, " ") + ";"
+		}
+	}
 	return "default-src 'none'; " +
 		"webrtc 'block'; " +
 
@@ -77,5 +91,6 @@ func uiContentSecurityPolicy(isSecure bool, rootHost string) string {
 
 		// 'self' alone does not allow websocket connections; see:
 		// https://github.com/w3c/webappsec-csp/issues/7
-		"connect-src 'self' " + wsHost + ";"
+		"connect-src 'self' " + wsHost + ";" +
+		ancestors
 }
@@ -0,0 +1,127 @@
+package servermain
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"sandstorm.org/go/tempest/internal/common/apierror"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/appindex"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/pkg/exp/spk"
+	"zenhack.net/go/util/exn"
+)
+
+// errPackageMismatch is returned when a package downloaded from the app index
+// isn't the one the index lists for the app.
+var errPackageMismatch = errors.New("package from the app index does not match its listing")
+
+// serveAppIndex lists the apps in the app index, as JSON. The q query
+// parameter, if present, is a search query; see appindex.Index.Search.
+func (s *server) serveAppIndex(w http.ResponseWriter, req *http.Request) {
+	if !s.requireRole(w, req, types.RoleUser) {
+		return
+	}
+	if s.appIndex == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	apps, err := s.appIndex.Search(req.Context(), req.URL.Query().Get("q"))
+	if err != nil {
+		s.log.ErrorCtx(req.Context(), "Fetching the app index", "error", err)
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	json.NewEncoder(w).Encode(apps)
+}
+
+// serveInstallFromAppIndex installs the current version of the app with the
+// given ID from the app index, unless it is already installed. The package
+// must be signed by the app's key, and have the package ID the index lists.
+func (s *server) serveInstallFromAppIndex(w http.ResponseWriter, req *http.Request) {
+	if !s.requireRole(w, req, types.RoleUser) {
+		return
+	}
+	if s.appIndex == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	ctx := req.Context()
+	app, err := s.appIndex.Lookup(ctx, mux.Vars(req)["appID"])
+	if errors.Is(err, appindex.ErrNotFound) {
+		s.writeLocalizedError(w, req, http.StatusNotFound, apierror.New(
+			apierror.CodeNotFound, "no such app in the app index", "app"))
+		return
+	} else if err != nil {
+		s.log.ErrorCtx(req.Context(), "Fetching the app index", "error", err)
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+
+	pkgID := types.ID[database.Package](app.PackageID)
+	_, err = exn.Try(func(throw exn.Thrower) database.Package {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		pkg, err := tx.InstalledPackage(pkgID)
+		throw(err)
+		return pkg
+	})
+	status := http.StatusOK
+	if errors.Is(err, sql.ErrNoRows) {
+		err = s.installFromAppIndex(req, app)
+		status = http.StatusCreated
+	}
+	if errors.Is(err, errPackageMismatch) {
+		s.log.WarnCtx(req.Context(), "Rejected package from the app index",
+			"error", err,
+			"appID", app.AppID,
+			"packageID", app.PackageID,
+		)
+		s.writeLocalizedError(w, req, http.StatusBadGateway, apierror.New(
+			apierror.CodeUnavailable, err.Error(), "app index"))
+		return
+	} else if err != nil {
+		s.log.ErrorCtx(req.Context(), "Installing app from the app index",
+			"error", err,
+			"appID", app.AppID,
+			"packageID", app.PackageID,
+		)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(types.InstalledApp{
+		AppID:     app.AppID,
+		PackageID: app.PackageID,
+	})
+}
+
+// installFromAppIndex downloads and installs the app's package.
+func (s *server) installFromAppIndex(req *http.Request, app appindex.App) error {
+	body, err := s.appIndex.FetchPackage(req.Context(), app)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	_, err = installSpkChecked(req.Context(), s.db, s.blobs, body, func(meta spk.ExtractedPackageMetadata) error {
+		if meta.AppID.String() != app.AppID {
+			return fmt.Errorf("%w: signed by app %s, not %s",
+				errPackageMismatch, meta.AppID, app.AppID)
+		}
+		if meta.Hash.ID() != app.PackageID {
+			return fmt.Errorf("%w: package ID is %s, not %s",
+				errPackageMismatch, meta.Hash.ID(), app.PackageID)
+		}
+		return nil
+	})
+	return err
+}
@@ -0,0 +1,624 @@
+package servermain
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"capnproto.org/go/capnp/v3"
+	"github.com/gorilla/mux"
+	"sandstorm.org/go/tempest/capnp/external"
+	"sandstorm.org/go/tempest/capnp/grain"
+	"sandstorm.org/go/tempest/internal/common/apierror"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/config"
+	"sandstorm.org/go/tempest/internal/server/blobstore"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/logging"
+	"sandstorm.org/go/tempest/internal/server/quota"
+	"sandstorm.org/go/tempest/internal/server/session"
+	"sandstorm.org/go/tempest/internal/server/settings"
+	"sandstorm.org/go/tempest/pkg/exp/util/bytestream"
+	"zenhack.net/go/util"
+	"zenhack.net/go/util/exn"
+)
+
+// Names of the entries in a grain backup, as in Sandstorm's backups: the
+// metadata is a grain.GrainInfo message, the log is the grain's debug log,
+// and the data directory holds the contents of the grain's sandbox
+// directory, i.e., its /var.
+const (
+	backupMetadataName = "metadata"
+	backupLogName      = "log"
+	backupDataDir      = "data"
+)
+
+// grainBackupMetadata encodes the metadata entry of a grain's backup.
+//
+// The app ID is unknown for packages installed before Tempest recorded it;
+// Sandstorm asks which app to use when restoring a backup without one. The
+// owner is recorded by account ID, since Tempest has no identities.
+func grainBackupMetadata(info database.GrainBackupInfo) ([]byte, error) {
+	return exn.Try(func(throw exn.Thrower) []byte {
+		msg, seg := capnp.NewSingleSegmentMessage(nil)
+		gi, err := grain.NewRootGrainInfo(seg)
+		throw(err)
+		if info.AppID != "" {
+			throw(gi.SetAppId(info.AppID))
+		}
+		gi.SetAppVersion(info.AppVersion)
+		throw(gi.SetTitle(info.Title))
+		throw(gi.SetOwnerIdentityId(info.Owner))
+		throw(gi.SetOriginalGrainId(string(info.ID)))
+		buf, err := msg.Marshal()
+		throw(err)
+		return buf
+	})
+}
+
+// writeGrainBackup writes a zip of the grain stored in grainDir to w, in the
+// format of Sandstorm's grain backups. Files are written to the zip one at a
+// time as they are read, so the archive is never held in memory.
+//
+// The grain may still be running, in which case files it changes during the
+// backup may be inconsistent with each other.
+func writeGrainBackup(w io.Writer, grainDir string, info database.GrainBackupInfo) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		metadata, err := grainBackupMetadata(info)
+		throw(err)
+		zw := zip.NewWriter(w)
+		entry, err := zw.Create(backupMetadataName)
+		throw(err)
+		_, err = entry.Write(metadata)
+		throw(err)
+
+		logPath := filepath.Join(grainDir, "log")
+		if fi, err := os.Lstat(logPath); err == nil && fi.Mode().IsRegular() {
+			throw(addFileToBackup(zw, logPath, backupLogName, fi))
+		}
+
+		sandboxDir := filepath.Join(grainDir, "sandbox")
+		throw(filepath.WalkDir(sandboxDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(sandboxDir, path)
+			if err != nil {
+				return err
+			}
+			name := backupDataDir
+			if rel != "." {
+				name += "/" + filepath.ToSlash(rel)
+			}
+			fi, err := d.Info()
+			if err != nil {
+				return err
+			}
+			return addFileToBackup(zw, path, name, fi)
+		}))
+		throw(zw.Close())
+	})
+}
+
+// addFileToBackup adds the file at path to zw under the given name.
+// Directories and symlinks are kept, and other special files, such as
+// sockets, are skipped, as Sandstorm does.
+func addFileToBackup(zw *zip.Writer, path, name string, fi fs.FileInfo) error {
+	header, err := zip.FileInfoHeader(fi)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	switch {
+	case fi.IsDir():
+		header.Name += "/"
+		_, err = zw.CreateHeader(header)
+		return err
+	case fi.Mode()&fs.ModeSymlink != 0:
+		target, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+		entry, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(entry, target)
+		return err
+	case fi.Mode().IsRegular():
+		header.Method = zip.Deflate
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		entry, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(entry, f)
+		return err
+	default:
+		return nil
+	}
+}
+
+// serveGrainBackup sends a backup of the grain named in the URL, as a zip
+// which Sandstorm can restore. Only the grain's owner may download it.
+func (s *server) serveGrainBackup(w http.ResponseWriter, req *http.Request) {
+	var sess session.UserSession
+	if err := session.ReadCookie(s.sessionStore, req, &sess); err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	grainID := types.GrainID(mux.Vars(req)["grainID"])
+	tx, err := s.db.Begin()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "failed to open database transaction",
+			"error", err)
+		return
+	}
+	defer tx.Rollback()
+	if !s.checkGrainOwner(w, tx, sess, grainID) {
+		return
+	}
+	info, err := tx.GrainBackupInfo(grainID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Reading grain backup info",
+			"error", err,
+			"grainID", grainID,
+		)
+		return
+	}
+	// Don't hold the transaction open while the backup is sent.
+	tx.Rollback()
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": info.Title + ".zip",
+	}))
+	w.Header().Set("Cache-Control", "no-store")
+	err = writeGrainBackup(w, filepath.Join(config.GrainsDir, string(grainID)), info)
+	if err != nil {
+		// The response has begun, so all we can do is cut it short,
+		// which leaves the client with an invalid zip.
+		s.log.ErrorCtx(req.Context(), "Writing grain backup",
+			"error", err,
+			"grainID", grainID,
+		)
+		return
+	}
+	s.events.Publish(types.GrainEvent{
+		GrainID: grainID,
+		Kind:    types.GrainBackedUp,
+	})
+}
+
+// checkOwnsGrain returns an error if the session's account doesn't own the
+// grain.
+func (s userSessionImpl) checkOwnsGrain(tx database.Tx, grainID types.GrainID) error {
+	accountID, err := tx.CredentialAccount(s.visitor.userSession.Credential)
+	if err != nil {
+		return err
+	}
+	info, err := tx.GrainInfo(grainID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && info.Owner != string(accountID)) {
+		return apierror.New(apierror.CodeNotFound, "no such grain", "grain")
+	}
+	return err
+}
+
+func (s userSessionImpl) BackupGrain(ctx context.Context, p external.UserSession_backupGrain) error {
+	srv := s.visitor.server
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	return withAPIError(results, func(throw exn.Thrower) {
+		id, err := p.Args().GrainId()
+		throw(err)
+		grainID := types.GrainID(id)
+		into := p.Args().Into()
+		tx, err := srv.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		throw(s.checkOwnsGrain(tx, grainID))
+		info, err := tx.GrainBackupInfo(grainID)
+		throw(err)
+		// Don't hold the transaction open while the backup is sent.
+		tx.Rollback()
+
+		p.Go()
+		w := bytestream.ToWriteCloser(ctx, into)
+		throw(writeGrainBackup(w, filepath.Join(config.GrainsDir, string(grainID)), info))
+		throw(w.Close())
+		srv.events.Publish(types.GrainEvent{
+			GrainID: grainID,
+			Kind:    types.GrainBackedUp,
+		})
+	})
+}
+
+// storeGrainBackup writes a backup of the grain stored in grainDir to blobs,
+// and returns its key.
+func storeGrainBackup(ctx context.Context, blobs blobstore.Store, grainDir string, info database.GrainBackupInfo) (string, error) {
+	key := blobstore.BackupKey(info.ID, time.Now())
+	r, w := io.Pipe()
+	go func() {
+		w.CloseWithError(writeGrainBackup(w, grainDir, info))
+	}()
+	err := blobs.Put(ctx, key, r)
+	// Stop the writer, if Put failed before reading everything.
+	r.CloseWithError(err)
+	return key, err
+}
+
+// ExportGrain writes a backup of a grain to a file, as
+// `tempest export-grain`, in the same format as the backups downloaded from
+// the server. With -store, the backup is written to the server's blob
+// storage instead, e.g. to keep nightly backups in an object store.
+func ExportGrain(args []string) {
+	flags := flag.NewFlagSet("tempest export-grain", flag.ExitOnError)
+	output := flags.String("o", "",
+		"file to write the backup to; defaults to <grain-id>.zip, and - is standard output")
+	store := flags.Bool("store", false,
+		"write the backup to the blob storage configured by BLOB_STORAGE, and print its key")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: tempest export-grain [-o file | -store] <grain-id>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 || (*store && *output != "") {
+		flags.Usage()
+		os.Exit(2)
+	}
+	grainID := types.GrainID(flags.Arg(0))
+
+	db := util.Must(database.Open())
+	defer db.Close()
+	info, err := exn.Try(func(throw exn.Thrower) database.GrainBackupInfo {
+		tx, err := db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		info, err := tx.GrainBackupInfo(grainID)
+		throw(err)
+		return info
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		fmt.Fprintf(os.Stderr, "No grain with ID %q\n", grainID)
+		os.Exit(1)
+	}
+	util.Chkfatal(err)
+	grainDir := filepath.Join(config.GrainsDir, string(grainID))
+
+	if *store {
+		lg := logging.NewLogger()
+		blobs := util.Must(BlobsConfigFromSettings(lg, settings.Environ).Open())
+		key, err := storeGrainBackup(context.Background(), blobs, grainDir, info)
+		util.Chkfatal(err)
+		fmt.Println(key)
+		return
+	}
+
+	path := *output
+	if path == "" {
+		path = string(grainID) + ".zip"
+	}
+	var w io.Writer = os.Stdout
+	if path != "-" {
+		f := util.Must(os.Create(path))
+		defer f.Close()
+		w = f
+	}
+	err = writeGrainBackup(w, grainDir, info)
+	if err != nil && path != "-" {
+		os.Remove(path)
+	}
+	util.Chkfatal(err)
+}
+
+// errInvalidBackup is wrapped by the errors for uploaded backups which can't
+// be restored, as opposed to failures of the server.
+var errInvalidBackup = errors.New("invalid grain backup")
+
+// maxBackupMetadataSize limits the size of the metadata entry, which is read
+// into memory.
+const maxBackupMetadataSize = 1 << 20
+
+// readBackupMetadata reads and checks the metadata of the backup in zr, and
+// returns the grain's title. If both are known, the backup's app ID must match
+// the package's. The backup's app version must not be newer than that of the
+// package it will run with, since apps can't be expected to understand data
+// from their future versions.
+func readBackupMetadata(zr *zip.Reader, pkg database.Package) (string, error) {
+	return exn.Try(func(throw exn.Thrower) string {
+		f, err := zr.Open(backupMetadataName)
+		if err != nil {
+			throw(fmt.Errorf("%w: no %s entry", errInvalidBackup, backupMetadataName))
+		}
+		defer f.Close()
+		buf, err := io.ReadAll(io.LimitReader(f, maxBackupMetadataSize+1))
+		throw(err)
+		if len(buf) > maxBackupMetadataSize {
+			throw(fmt.Errorf("%w: %s is too large", errInvalidBackup, backupMetadataName))
+		}
+		msg, err := capnp.Unmarshal(buf)
+		if err != nil {
+			throw(fmt.Errorf("%w: reading %s: %v", errInvalidBackup, backupMetadataName, err))
+		}
+		info, err := grain.ReadRootGrainInfo(msg)
+		if err != nil {
+			throw(fmt.Errorf("%w: reading %s: %v", errInvalidBackup, backupMetadataName, err))
+		}
+		title, err := info.Title()
+		if err != nil {
+			throw(fmt.Errorf("%w: reading title: %v", errInvalidBackup, err))
+		}
+		appID, err := info.AppId()
+		if err != nil {
+			throw(fmt.Errorf("%w: reading app ID: %v", errInvalidBackup, err))
+		}
+		if appID != "" && pkg.AppID != "" && appID != pkg.AppID {
+			throw(fmt.Errorf("%w: the grain is from app %s, but the package is from app %s",
+				errInvalidBackup, appID, pkg.AppID))
+		}
+		if info.AppVersion() > pkg.Manifest.AppVersion() {
+			throw(fmt.Errorf("%w: the grain is from app version %d, newer than the package's %d",
+				errInvalidBackup, info.AppVersion(), pkg.Manifest.AppVersion()))
+		}
+		if title == "" {
+			title = "Restored grain"
+		}
+		return title
+	})
+}
+
+// backupEntryPath returns where the entry with the given name in a backup is
+// extracted to, within grainDir, or false if it isn't part of the grain's
+// storage.
+func backupEntryPath(grainDir, name string) (string, bool) {
+	if name == backupLogName {
+		return filepath.Join(grainDir, "log"), true
+	}
+	rel, ok := strings.CutPrefix(name, backupDataDir+"/")
+	if !ok {
+		return "", false
+	}
+	rel = strings.TrimSuffix(rel, "/")
+	sandboxDir := filepath.Join(grainDir, "sandbox")
+	if rel == "" {
+		return sandboxDir, true
+	}
+	// Rejects .. and absolute paths, which would escape the grain.
+	if !fs.ValidPath(rel) {
+		return "", false
+	}
+	return filepath.Join(sandboxDir, filepath.FromSlash(rel)), true
+}
+
+// extractGrainBackup extracts the log and data of the backup in zr into
+// grainDir, which must not exist yet.
+//
+// Symlinks are created after everything else, so that no file is written
+// through one to somewhere outside the grain. Files are created exclusively,
+// so an entry can't replace one extracted earlier.
+func extractGrainBackup(zr *zip.Reader, grainDir string) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		throw(os.Mkdir(grainDir, 0770))
+		throw(os.Mkdir(filepath.Join(grainDir, "sandbox"), 0770))
+		var symlinks []*zip.File
+		for _, entry := range zr.File {
+			path, ok := backupEntryPath(grainDir, entry.Name)
+			if !ok {
+				if strings.HasPrefix(entry.Name, backupDataDir+"/") {
+					throw(fmt.Errorf("%w: invalid path %q", errInvalidBackup, entry.Name))
+				}
+				continue // Not part of the grain's storage, e.g., the metadata.
+			}
+			mode := entry.Mode()
+			switch {
+			case mode.IsDir():
+				throw(os.MkdirAll(path, 0770))
+			case mode&fs.ModeSymlink != 0:
+				symlinks = append(symlinks, entry)
+			case mode.IsRegular():
+				throw(os.MkdirAll(filepath.Dir(path), 0770))
+				err := extractBackupFile(entry, path, mode.Perm()|0600)
+				if errors.Is(err, fs.ErrExist) {
+					err = fmt.Errorf("%w: %q appears more than once", errInvalidBackup, entry.Name)
+				}
+				throw(err)
+			default:
+				// Sandstorm doesn't back up special files.
+			}
+		}
+		for _, entry := range symlinks {
+			path, _ := backupEntryPath(grainDir, entry.Name)
+			f, err := entry.Open()
+			throw(err)
+			target, err := io.ReadAll(io.LimitReader(f, 4096))
+			f.Close()
+			throw(err)
+			// An earlier symlink may be a parent of this one.
+			throw(checkNoSymlinkParents(grainDir, path))
+			throw(os.MkdirAll(filepath.Dir(path), 0770))
+			err = os.Symlink(string(target), path)
+			if errors.Is(err, fs.ErrExist) {
+				err = fmt.Errorf("%w: %q appears more than once", errInvalidBackup, entry.Name)
+			}
+			throw(err)
+		}
+	})
+}
+
+// checkNoSymlinkParents returns an error if any directory between grainDir
+// and path is a symlink.
+func checkNoSymlinkParents(grainDir, path string) error {
+	rel, err := filepath.Rel(grainDir, filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	dir := grainDir
+	for _, name := range strings.Split(rel, string(filepath.Separator)) {
+		dir = filepath.Join(dir, name)
+		fi, err := os.Lstat(dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if fi.Mode()&fs.ModeSymlink != 0 {
+			rel, _ := filepath.Rel(grainDir, path)
+			return fmt.Errorf("%w: %s is inside a symlink", errInvalidBackup, rel)
+		}
+	}
+	return nil
+}
+
+func extractBackupFile(entry *zip.File, path string, perm fs.FileMode) error {
+	r, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// restoreGrainBackup creates a new grain, owned by accountID and running the
+// package pkgID, from the backup read from r. It returns the new grain's ID.
+func restoreGrainBackup(db database.DB, accountID types.AccountID, pkgID types.ID[database.Package], r io.Reader) (types.GrainID, error) {
+	return exn.Try(func(throw exn.Thrower) types.GrainID {
+		// Zip files are read from the end, so the upload has to be
+		// stored before it can be checked.
+		throw(os.MkdirAll(config.TempDir, 0700))
+		tmp, err := os.CreateTemp(config.TempDir, "backup-*.zip")
+		throw(err)
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+		size, err := io.Copy(tmp, r)
+		throw(err)
+		zr, err := zip.NewReader(tmp, size)
+		if err != nil {
+			throw(fmt.Errorf("%w: %v", errInvalidBackup, err))
+		}
+
+		tx, err := db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		pkg, err := tx.InstalledPackage(pkgID)
+		if errors.Is(err, sql.ErrNoRows) {
+			throw(fmt.Errorf("%w: no package with ID %q", errInvalidBackup, pkgID))
+		}
+		throw(err)
+		title, err := readBackupMetadata(zr, pkg)
+		throw(err)
+
+		grainID := newGrainID()
+		grainDir := filepath.Join(config.GrainsDir, string(grainID))
+		err = extractGrainBackup(zr, grainDir)
+		if err == nil {
+			err = tx.AddGrain(database.NewGrain{
+				GrainID: grainID,
+				PkgID:   pkgID,
+				Title:   title,
+				OwnerID: accountID,
+			})
+		}
+		if err == nil {
+			err = tx.Commit()
+		}
+		if err != nil {
+			os.RemoveAll(grainDir)
+			throw(err)
+		}
+		return grainID
+	})
+}
+
+// serveRestoreGrainBackup creates a grain for the user from the Sandstorm
+// grain backup in the request body. The package query parameter is the ID of
+// the installed package the grain will run, since backups don't say. It
+// sends the new grain's ID as JSON.
+func (s *server) serveRestoreGrainBackup(w http.ResponseWriter, req *http.Request) {
+	var sess session.UserSession
+	if err := session.ReadCookie(s.sessionStore, req, &sess); err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	pkgID := types.ID[database.Package](req.URL.Query().Get("package"))
+	if pkgID == "" {
+		s.writeLocalizedError(w, req, http.StatusBadRequest, apierror.New(
+			apierror.CodeInvalidArgument, "missing package parameter", "package"))
+		return
+	}
+	var remaining int64
+	accountID, err := exn.Try(func(throw exn.Thrower) types.AccountID {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		accountID, err := tx.CredentialAccount(sess.Credential)
+		throw(err)
+		remaining, err = s.remainingStorage(tx, accountID)
+		throw(err)
+		return accountID
+	})
+	if errors.Is(err, errQuotaExceeded) {
+		s.writeAPIError(w, req, err, "Restoring grain backup")
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	grainID, err := restoreGrainBackup(s.db, accountID, pkgID,
+		quota.LimitReader(req.Body, remaining))
+	if errors.Is(err, errInvalidBackup) {
+		s.writeLocalizedError(w, req, http.StatusBadRequest, apierror.New(
+			apierror.CodeInvalidArgument, err.Error(), "backup"))
+		return
+	} else if errors.Is(err, quota.ErrExceeded) {
+		s.writeAPIError(w, req, quotaError(err), "Restoring grain backup")
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Restoring grain backup",
+			"error", err,
+			"accountID", accountID,
+		)
+		return
+	}
+	// Count the new grain now, so the user can't restore more backups
+	// before the tracker notices it.
+	if _, err := s.quota.Update(grainID); err != nil {
+		s.log.WarnCtx(req.Context(), "Measuring grain storage", "error", err, "grainID", grainID)
+	}
+	s.events.Publish(types.GrainEvent{
+		GrainID: grainID,
+		Kind:    types.GrainRestored,
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(types.RestoredGrain{GrainID: grainID})
+}
@@ -0,0 +1,216 @@
+package servermain
+
+import (
+	"encoding/hex"
+	"html/template"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"sandstorm.org/go/tempest/internal/browser/intl"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/session"
+	"sandstorm.org/go/tempest/internal/server/tokenutil"
+	"zenhack.net/go/util/exn"
+)
+
+// browserFeatures are the browser APIs the UI needs, which the shim in
+// index.html checks for before loading it, in the order they are listed on
+// the unsupported browser page.
+var browserFeatures = []string{
+	"WebAssembly",
+	"WebSocket",
+	"fetch",
+	"Promise",
+	"TextEncoder",
+}
+
+// minimumBrowsers are the oldest browser versions with everything in
+// browserFeatures.
+var minimumBrowsers = []string{
+	"Firefox 58",
+	"Chrome 61",
+	"Edge 79",
+	"Safari 15",
+}
+
+// unsupportedBrowserTemplate explains why the UI can't run. The "t" function
+// localizes its format string; see intl.L10N.Fmt.
+var unsupportedBrowserTemplate = template.Must(template.New("unsupported-browser").
+	Funcs(template.FuncMap{"t": intl.L10N{}.Fmt}).
+	Parse(`<!doctype html>
+<html>
+<head>
+<meta charset="utf-8" />
+<title>{{t "Unsupported browser"}}</title>
+<link rel="stylesheet" href="/style.css">
+</head>
+<body class="unsupported-browser">
+<h1>{{t "Your browser can't run Tempest"}}</h1>
+{{if .Missing}}
+<p>{{t "It is missing: %0." .Missing}}</p>
+{{else}}
+<p>{{t "It doesn't support JavaScript, or JavaScript is turned off."}}</p>
+{{end}}
+<p>{{t "Tempest needs one of these browsers, or a newer version:"}}</p>
+<ul>
+{{range .Browsers}}<li>{{.}}</li>
+{{end}}
+</ul>
+<p>{{t "Until then, you can still use the basic interface, though some of your grains may need a newer browser too."}}
+<a href="/basic">{{t "Open your grains with the basic interface"}}</a></p>
+</body>
+</html>
+`))
+
+// serveUnsupportedBrowser explains what the browser lacks, for the shim in
+// index.html to redirect to. The missing query parameter is a comma-separated
+// list of browserFeatures; anything else in it is ignored.
+func (s *server) serveUnsupportedBrowser(w http.ResponseWriter, req *http.Request) {
+	var missing []string
+	for _, feature := range strings.Split(req.URL.Query().Get("missing"), ",") {
+		if slices.Contains(browserFeatures, feature) && !slices.Contains(missing, feature) {
+			missing = append(missing, feature)
+		}
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	executeLocalized(w, unsupportedBrowserTemplate, s.requestL10N(req), struct {
+		Missing  string
+		Browsers []string
+	}{
+		Missing:  strings.Join(missing, ", "),
+		Browsers: minimumBrowsers,
+	})
+}
+
+var basicTemplate = template.Must(template.New("basic").Parse(`<!doctype html>
+<html>
+<head>
+<meta charset="utf-8" />
+<title>Tempest</title>
+<link rel="stylesheet" href="/style.css">
+</head>
+<body class="basic">
+<h1>Your grains</h1>
+{{if not .LoggedIn}}
+<p>You are not logged in. Logging in needs the full interface.</p>
+{{else if not .Grains}}
+<p>You have no grains.</p>
+{{else}}
+<ul>
+{{range .Grains}}<li><a href="/basic/grain/{{.ID}}">{{.Title}}</a></li>
+{{end}}
+</ul>
+{{end}}
+</body>
+</html>
+`))
+
+// userUiViews returns the account of the user with the session, and the
+// grains in its keyring.
+func (s *server) userUiViews(sess session.UserSession) (types.AccountID, []database.UiViewInfo, error) {
+	var accountID types.AccountID
+	views, err := exn.Try(func(throw exn.Thrower) []database.UiViewInfo {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		accountID, err = tx.CredentialAccount(sess.Credential)
+		throw(err)
+		views, err := tx.AccountKeyring(accountID).AllUiViews()
+		throw(err)
+		throw(tx.Commit())
+		return views
+	})
+	return accountID, views, err
+}
+
+// serveBasic lists the user's grains as plain HTML, for browsers which can't
+// run the UI. Each links to serveBasicGrain.
+func (s *server) serveBasic(w http.ResponseWriter, req *http.Request) {
+	var sess session.UserSession
+	loggedIn := session.ReadCookie(s.sessionStore, req, &sess) == nil
+	var grains []database.GrainInfo
+	if loggedIn {
+		_, views, err := s.userUiViews(sess)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			s.log.ErrorCtx(req.Context(), "Listing grains for the basic interface",
+				"error", err,
+			)
+			return
+		}
+		for _, view := range views {
+			grains = append(grains, view.Grain)
+		}
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	basicTemplate.Execute(w, struct {
+		LoggedIn bool
+		Grains   []database.GrainInfo
+	}{
+		LoggedIn: loggedIn,
+		Grains:   grains,
+	})
+}
+
+// serveBasicGrain opens the grain named in the URL in the whole window,
+// rather than in the UI's iframe, if it is in the user's keyring.
+func (s *server) serveBasicGrain(w http.ResponseWriter, req *http.Request) {
+	var sess session.UserSession
+	if err := session.ReadCookie(s.sessionStore, req, &sess); err != nil {
+		http.Redirect(w, req, "/basic", http.StatusSeeOther)
+		return
+	}
+	grainID := types.GrainID(mux.Vars(req)["grainID"])
+	accountID, views, err := s.userUiViews(sess)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Listing grains for the basic interface",
+			"error", err,
+		)
+		return
+	}
+	if !slices.ContainsFunc(views, func(view database.UiViewInfo) bool {
+		return view.Grain.ID == grainID
+	}) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	sessionToken, err := session.GrainSession{
+		GrainID:   grainID,
+		SessionID: sess.SessionID,
+		AccountID: accountID,
+	}.Seal(s.sessionStore)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Sealing grain session",
+			"error", err,
+			"grainID", grainID,
+		)
+		return
+	}
+	initURL := s.grainInitURL(sessionToken)
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, req, initURL.String(), http.StatusSeeOther)
+}
+
+// grainInitURL returns the URL which starts a grain session with the sealed
+// session token, on a new ui- subdomain, and then shows the grain's root.
+func (s *server) grainInitURL(sessionToken string) url.URL {
+	initURL := url.URL{
+		Scheme: "http",
+		Host:   "ui-" + hex.EncodeToString(tokenutil.Gen128()) + "." + s.cfg.HTTP.RootDomain,
+		Path:   "/_sandstorm-init",
+		RawQuery: url.Values{
+			"sandstorm-sid": {sessionToken},
+			"path":          {"/"},
+		}.Encode(),
+	}
+	if s.cfg.HTTP.DefaultTLS {
+		initURL.Scheme = "https"
+	}
+	return initURL
+}
@@ -1,19 +1,80 @@
 package servermain
 
 import (
+	"io"
 	"net"
-	"net/smtp"
+	"net/netip"
 	"net/url"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
 
 	"golang.org/x/exp/slog"
+	"sandstorm.org/go/tempest/internal/config"
+	"sandstorm.org/go/tempest/internal/server/acme"
+	"sandstorm.org/go/tempest/internal/server/blobstore"
+	"sandstorm.org/go/tempest/internal/server/cgroup"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/forwarded"
 	"sandstorm.org/go/tempest/internal/server/logging"
+	"sandstorm.org/go/tempest/internal/server/loginlimit"
+	"sandstorm.org/go/tempest/internal/server/mailer"
+	"sandstorm.org/go/tempest/internal/server/quota"
+	"sandstorm.org/go/tempest/internal/server/reqlimit"
+	"sandstorm.org/go/tempest/internal/server/saml"
+	"sandstorm.org/go/tempest/internal/server/seccomp"
+	"sandstorm.org/go/tempest/internal/server/secheaders"
+	"sandstorm.org/go/tempest/internal/server/session"
 	"sandstorm.org/go/tempest/internal/server/settings"
+	"sandstorm.org/go/tempest/internal/server/thumbnail"
+	"sandstorm.org/go/tempest/internal/server/turn"
 	"zenhack.net/go/util"
 )
 
 type Config struct {
-	HTTP HTTPConfig
-	SMTP SMTPConfig
+	HTTP        HTTPConfig
+	Email       EmailConfig
+	Mail        MailConfig
+	Replication ReplicationConfig
+	Thumbnail   ThumbnailConfig
+	TURN        turn.Config
+	Session     SessionConfig
+	SAML        saml.Config
+	Quota       quota.Config
+	LoginLimit  loginlimit.Config
+	Cgroups     cgroup.Config
+	Seccomp     seccomp.Policy
+	Blobs       blobstore.Config
+	Requests    reqlimit.Config
+	Headers     secheaders.Config
+	Demo        DemoConfig
+	Maintenance MaintenanceConfig
+
+	// How long a running grain may go unused before it is shut down;
+	// zero if grains are never shut down for being idle.
+	GrainIdleTimeout time.Duration
+
+	// How many warm sandboxes to keep ready for grains; zero if disabled.
+	SandboxPoolSize int
+
+	// Most bytes of output kept in each grain's log.
+	GrainLogMaxSize int64
+
+	// Format of the log; one of the logging.Format* constants.
+	LogFormat string
+
+	// Bearer token granting access to metrics.
+	MetricsToken string
+
+	// Base URL of the app index; empty if installing from an index is
+	// disabled.
+	AppIndexURL string
+
+	// ID of the group whose members may use the dev socket; empty if it
+	// is disabled.
+	DevGroupID string
 }
 
 type HTTPConfig struct {
@@ -22,41 +83,153 @@ type HTTPConfig struct {
 	TLSPort           string
 	CertFile, KeyFile string
 	DefaultTLS        bool
+
+	// Addresses of reverse proxies whose X-Forwarded-* headers we trust.
+	TrustedProxies []netip.Prefix
+
+	// Port for the external RPC listener; empty if disabled.
+	RPCPort string
+
+	// Obtaining certificates via ACME, instead of CertFile and KeyFile.
+	ACME acme.Config
 }
 
-type SMTPConfig struct {
-	Host     string
-	Port     string
-	Username string
-	Password string
+// Values of EMAIL_DELIVERY.
+const (
+	EmailDeliverySMTP = "smtp"
+	EmailDeliveryLog  = "log"
+)
+
+// EmailConfig configures outgoing email.
+type EmailConfig struct {
+	Delivery       string // One of the EmailDelivery* constants.
+	SMTP           mailer.SMTPConfig
+	LoginRateLimit int // Login tokens sent to each address per hour.
+}
+
+// MailConfig configures incoming email.
+type MailConfig struct {
+	ListenPort string // Port for the SMTP listener; empty if disabled.
+	Domain     string // Domain of grains' addresses.
+	MaxSize    int64  // Maximum message size, in bytes.
+}
+
+// DemoConfig configures demo accounts.
+type DemoConfig struct {
+	// How long demo accounts last; zero if they are disabled.
+	AccountLifetime time.Duration
+
+	// The most disk space each demo account's grains may use, in bytes.
+	StorageQuota int64
 }
 
-func (c SMTPConfig) getAuth() smtp.Auth {
-	return smtp.PlainAuth(
-		c.Username,
-		c.Username,
-		c.Password,
-		c.Host,
-	)
+// Enabled reports whether visitors may make demo accounts.
+func (c DemoConfig) Enabled() bool {
+	return c.AccountLifetime > 0
+}
+
+// MaintenanceConfig configures the background maintenance tasks; see
+// maintenance.go.
+type MaintenanceConfig struct {
+	// How long grains stay in the trash; zero if they stay until
+	// purged by hand.
+	TrashRetention time.Duration
+
+	// How long log entries are kept; zero if only the caps on their
+	// numbers apply.
+	LogRetention time.Duration
 }
 
-func (c SMTPConfig) SendMail(to []string, msg []byte) error {
-	return smtp.SendMail(
-		net.JoinHostPort(c.Host, c.Port),
-		c.getAuth(),
-		c.Username,
-		to,
-		msg,
-	)
+type ReplicationConfig struct {
+	PrimaryURL string // If non-empty, run as a standby for this server.
+	Secret     string
+	Interval   time.Duration
+}
+
+type ThumbnailConfig struct {
+	// Helper command for PDF thumbnails; empty if disabled.
+	PDFCommand []string
+}
+
+// Values of SESSION_BACKEND.
+const (
+	SessionBackendLocal = "local"
+	SessionBackendRedis = "redis"
+)
+
+type SessionConfig struct {
+	Backend   string // One of the SessionBackend* constants
+	RedisURL  string // Only used by the redis backend
+	Lifetimes session.Lifetimes
+}
+
+// IsStandby returns true if the server should run as a standby.
+func (c ReplicationConfig) IsStandby() bool {
+	return c.PrimaryURL != ""
+}
+
+// NewSender returns the mail sender chosen by the configuration.
+func (c EmailConfig) NewSender(lg *slog.Logger) mailer.Sender {
+	if c.Delivery == EmailDeliveryLog {
+		return mailer.LogSender{Log: lg}
+	}
+	return mailer.SMTPSender{Config: c.SMTP}
+}
+
+func EmailConfigFromSettings(lg *slog.Logger, src settings.Source) EmailConfig {
+	cfg := EmailConfig{
+		Delivery: src.GetString("EMAIL_DELIVERY"),
+		SMTP: mailer.SMTPConfig{
+			Host:     src.GetString("SMTP_HOST"),
+			Port:     src.GetString("SMTP_PORT"),
+			Username: src.GetString("SMTP_USERNAME"),
+			Password: src.GetString("SMTP_PASSWORD"),
+			From:     src.GetString("SMTP_FROM"),
+			Security: src.GetString("SMTP_SECURITY"),
+		},
+	}
+	if cfg.SMTP.From == "" {
+		cfg.SMTP.From = cfg.SMTP.Username
+	}
+	switch cfg.Delivery {
+	case EmailDeliverySMTP, EmailDeliveryLog:
+	default:
+		logging.Panic(lg, "parsing EMAIL_DELIVERY: must be smtp or log",
+			"value", cfg.Delivery)
+	}
+	switch cfg.SMTP.Security {
+	case mailer.SecurityStartTLS, mailer.SecurityTLS, mailer.SecurityNone:
+	default:
+		logging.Panic(lg, "parsing SMTP_SECURITY: must be starttls, tls or none",
+			"value", cfg.SMTP.Security)
+	}
+	perHour, err := strconv.Atoi(src.GetString("EMAIL_LOGIN_RATE_LIMIT"))
+	if err != nil || perHour <= 0 {
+		logging.Panic(lg, "parsing EMAIL_LOGIN_RATE_LIMIT: must be a positive integer",
+			"error", err)
+	}
+	cfg.LoginRateLimit = perHour
+	return cfg
 }
 
-func SMTPConfigFromSettings(src settings.Source) SMTPConfig {
-	return SMTPConfig{
-		Host:     src.GetString("SMTP_HOST"),
-		Port:     src.GetString("SMTP_PORT"),
-		Username: src.GetString("SMTP_USERNAME"),
-		Password: src.GetString("SMTP_PASSWORD"),
+func MailConfigFromSettings(lg *slog.Logger, src settings.Source, http HTTPConfig) MailConfig {
+	cfg := MailConfig{
+		ListenPort: src.GetString("SMTP_LISTEN_PORT"),
+		Domain:     src.GetString("MAIL_DOMAIN"),
 	}
+	if cfg.Domain == "" {
+		cfg.Domain = http.RootDomain
+		if host, _, err := net.SplitHostPort(cfg.Domain); err == nil {
+			cfg.Domain = host
+		}
+	}
+	maxSize, err := strconv.ParseInt(src.GetString("MAX_INCOMING_EMAIL_SIZE"), 10, 64)
+	if err != nil || maxSize <= 0 {
+		logging.Panic(lg, "parsing MAX_INCOMING_EMAIL_SIZE: must be a positive integer",
+			"error", err)
+	}
+	cfg.MaxSize = maxSize
+	return cfg
 }
 
 func HTTPConfigFromSettings(lg *slog.Logger, src settings.Source) HTTPConfig {
@@ -75,13 +248,470 @@ func HTTPConfigFromSettings(lg *slog.Logger, src settings.Source) HTTPConfig {
 		TLSPort:    src.GetString("HTTPS_PORT"),
 		CertFile:   src.GetString("HTTPS_CERT_FILE"),
 		KeyFile:    src.GetString("HTTPS_KEY_FILE"),
+		RPCPort:    src.GetString("RPC_LISTEN_PORT"),
+	}
+	trusted, err := forwarded.ParseTrusted(src.GetString("TRUSTED_PROXIES"))
+	if err != nil {
+		logging.Panic(lg, "parsing TRUSTED_PROXIES", "error", err)
+	}
+	cfg.TrustedProxies = trusted
+	cfg.ACME = ACMEConfigFromSettings(lg, src, cfg.RootDomain)
+	return cfg
+}
+
+func ACMEConfigFromSettings(lg *slog.Logger, src settings.Source, rootDomain string) acme.Config {
+	providerName := src.GetString("ACME_DNS_PROVIDER")
+	if providerName == "" {
+		return acme.Config{}
+	}
+	// XXX: this will always pull provider-specific options from the
+	// environment, even if src is not settings.Environ.
+	provider, err := acme.NewProvider(providerName)
+	if err != nil {
+		logging.Panic(lg, "creating ACME_DNS_PROVIDER", "error", err)
+	}
+	directory := src.GetString("ACME_DIRECTORY_URL")
+	if u, err := url.Parse(directory); err != nil || u.Scheme != "https" {
+		logging.Panic(lg, "parsing ACME_DIRECTORY_URL: must be an https URL")
+	}
+	host := rootDomain
+	if h, _, err := net.SplitHostPort(rootDomain); err == nil {
+		host = h
+	}
+	return acme.Config{
+		Email:     src.GetString("ACME_EMAIL"),
+		Directory: directory,
+		Provider:  provider,
+		Domains:   acme.Domains(host),
+	}
+}
+
+func ReplicationConfigFromSettings(lg *slog.Logger, src settings.Source) ReplicationConfig {
+	cfg := ReplicationConfig{
+		PrimaryURL: src.GetString("REPLICATION_PRIMARY_URL"),
+		Secret:     src.GetString("REPLICATION_SECRET"),
+	}
+	interval, err := time.ParseDuration(src.GetString("REPLICATION_INTERVAL"))
+	if err != nil || interval <= 0 {
+		logging.Panic(lg, "parsing REPLICATION_INTERVAL: must be a positive duration",
+			"error", err)
+	}
+	cfg.Interval = interval
+	if cfg.IsStandby() {
+		primaryURL, err := url.Parse(cfg.PrimaryURL)
+		if err != nil || (primaryURL.Scheme != "http" && primaryURL.Scheme != "https") {
+			logging.Panic(lg, "parsing REPLICATION_PRIMARY_URL: must be an http(s) URL")
+		}
+		if cfg.Secret == "" {
+			logging.Panic(lg, "REPLICATION_PRIMARY_URL is set, but REPLICATION_SECRET is not")
+		}
 	}
 	return cfg
 }
 
+func ThumbnailConfigFromSettings(src settings.Source) ThumbnailConfig {
+	return ThumbnailConfig{
+		PDFCommand: thumbnail.ParseCommand(src.GetString("THUMBNAIL_PDF_COMMAND")),
+	}
+}
+
+func TURNConfigFromSettings(lg *slog.Logger, src settings.Source) turn.Config {
+	cfg := turn.Config{
+		URLs:   strings.Fields(src.GetString("TURN_URLS")),
+		Secret: src.GetString("TURN_SECRET"),
+	}
+	ttl, err := time.ParseDuration(src.GetString("TURN_CREDENTIAL_TTL"))
+	if err != nil || ttl <= 0 {
+		logging.Panic(lg, "parsing TURN_CREDENTIAL_TTL: must be a positive duration",
+			"error", err)
+	}
+	cfg.TTL = ttl
+	perHour, err := strconv.Atoi(src.GetString("TURN_RATE_LIMIT"))
+	if err != nil || perHour <= 0 {
+		logging.Panic(lg, "parsing TURN_RATE_LIMIT: must be a positive integer",
+			"error", err)
+	}
+	cfg.PerHour = perHour
+	if len(cfg.URLs) > 0 && cfg.Secret == "" {
+		logging.Panic(lg, "TURN_URLS is set, but TURN_SECRET is not")
+	}
+	return cfg
+}
+
+func SessionConfigFromSettings(lg *slog.Logger, src settings.Source) SessionConfig {
+	cfg := SessionConfig{
+		Backend:  src.GetString("SESSION_BACKEND"),
+		RedisURL: src.GetString("SESSION_REDIS_URL"),
+	}
+	switch cfg.Backend {
+	case SessionBackendLocal:
+	case SessionBackendRedis:
+		if cfg.RedisURL == "" {
+			logging.Panic(lg, "SESSION_BACKEND is redis, but SESSION_REDIS_URL is not set")
+		}
+	default:
+		logging.Panic(lg, "parsing SESSION_BACKEND: must be local or redis",
+			"value", cfg.Backend)
+	}
+	duration := func(name string) time.Duration {
+		d, err := time.ParseDuration(src.GetString(name))
+		if err != nil || d < 0 {
+			logging.Panic(lg, "parsing "+name+": must be a non-negative duration",
+				"error", err)
+		}
+		return d
+	}
+	cfg.Lifetimes = session.Lifetimes{
+		MaxAge:      duration("SESSION_MAX_AGE"),
+		IdleTimeout: duration("SESSION_IDLE_TIMEOUT"),
+		GrainMaxAge: duration("GRAIN_SESSION_MAX_AGE"),
+	}
+	return cfg
+}
+
+// NewBackend returns the session backend chosen by the configuration.
+func (c SessionConfig) NewBackend(db database.DB) (session.Backend, error) {
+	if c.Backend == SessionBackendRedis {
+		return session.NewRedisBackend(c.RedisURL, c.Lifetimes.MaxAge)
+	}
+	return session.NewLocalBackend(db), nil
+}
+
+// SAMLConfigFromSettings reads the identity provider's metadata, if SAML login
+// is configured.
+func SAMLConfigFromSettings(lg *slog.Logger, src settings.Source) saml.Config {
+	baseURL := src.GetString("BASE_URL")
+	cfg := saml.Config{
+		EntityID:      src.GetString("SAML_SP_ENTITY_ID"),
+		ACSURL:        baseURL + samlACSPath,
+		NameAttribute: src.GetString("SAML_NAME_ATTRIBUTE"),
+	}
+	if cfg.EntityID == "" {
+		cfg.EntityID = baseURL + samlMetadataPath
+	}
+	skew, err := time.ParseDuration(src.GetString("SAML_CLOCK_SKEW"))
+	if err != nil || skew < 0 {
+		logging.Panic(lg, "parsing SAML_CLOCK_SKEW: must be a non-negative duration",
+			"error", err)
+	}
+	cfg.ClockSkew = skew
+	metadataFile := src.GetString("SAML_IDP_METADATA_FILE")
+	if metadataFile == "" {
+		return cfg
+	}
+	metadata, err := os.ReadFile(metadataFile)
+	if err != nil {
+		logging.Panic(lg, "reading SAML_IDP_METADATA_FILE", "error", err)
+	}
+	cfg.IdP, err = saml.ParseIdPMetadata(metadata)
+	if err != nil {
+		logging.Panic(lg, "parsing SAML_IDP_METADATA_FILE", "error", err)
+	}
+	return cfg
+}
+
+func QuotaConfigFromSettings(lg *slog.Logger, src settings.Source) quota.Config {
+	var cfg quota.Config
+	if limit := src.GetString("USER_STORAGE_QUOTA"); limit != "" {
+		n, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil || n <= 0 {
+			logging.Panic(lg, "parsing USER_STORAGE_QUOTA: must be a positive number of bytes",
+				"error", err)
+		}
+		cfg.UserLimit = n
+	}
+	interval, err := time.ParseDuration(src.GetString("STORAGE_SCAN_INTERVAL"))
+	if err != nil || interval <= 0 {
+		logging.Panic(lg, "parsing STORAGE_SCAN_INTERVAL: must be a positive duration",
+			"error", err)
+	}
+	cfg.ScanInterval = interval
+	return cfg
+}
+
+func DemoConfigFromSettings(lg *slog.Logger, src settings.Source) DemoConfig {
+	lifetime, err := time.ParseDuration(src.GetString("DEMO_ACCOUNT_LIFETIME"))
+	if err != nil || lifetime < 0 {
+		logging.Panic(lg, "parsing DEMO_ACCOUNT_LIFETIME: must be a non-negative duration",
+			"error", err)
+	}
+	quota, err := strconv.ParseInt(src.GetString("DEMO_STORAGE_QUOTA"), 10, 64)
+	if err != nil || quota <= 0 {
+		logging.Panic(lg, "parsing DEMO_STORAGE_QUOTA: must be a positive number of bytes",
+			"error", err)
+	}
+	return DemoConfig{
+		AccountLifetime: lifetime,
+		StorageQuota:    quota,
+	}
+}
+
+func MaintenanceConfigFromSettings(lg *slog.Logger, src settings.Source) MaintenanceConfig {
+	duration := func(name string) time.Duration {
+		d, err := time.ParseDuration(src.GetString(name))
+		if err != nil || d < 0 {
+			logging.Panic(lg, "parsing "+name+": must be a non-negative duration",
+				"error", err)
+		}
+		return d
+	}
+	return MaintenanceConfig{
+		TrashRetention: duration("TRASH_RETENTION"),
+		LogRetention:   duration("LOG_RETENTION"),
+	}
+}
+
+func LoginLimitConfigFromSettings(lg *slog.Logger, src settings.Source) loginlimit.Config {
+	perHour, err := strconv.Atoi(src.GetString("LOGIN_RATE_LIMIT"))
+	if err != nil || perHour < 0 {
+		logging.Panic(lg, "parsing LOGIN_RATE_LIMIT: must be a non-negative integer",
+			"error", err)
+	}
+	maxFailures, err := strconv.Atoi(src.GetString("LOGIN_MAX_FAILURES"))
+	if err != nil || maxFailures < 0 {
+		logging.Panic(lg, "parsing LOGIN_MAX_FAILURES: must be a non-negative integer",
+			"error", err)
+	}
+	lockout, err := time.ParseDuration(src.GetString("LOGIN_LOCKOUT"))
+	if err != nil || lockout <= 0 {
+		logging.Panic(lg, "parsing LOGIN_LOCKOUT: must be a positive duration",
+			"error", err)
+	}
+	return loginlimit.Config{
+		PerHour:     perHour,
+		MaxFailures: maxFailures,
+		Lockout:     lockout,
+	}
+}
+
+func CgroupConfigFromSettings(lg *slog.Logger, src settings.Source) cgroup.Config {
+	cfg := cgroup.Config{Root: src.GetString("GRAIN_CGROUP")}
+	if limit := src.GetString("GRAIN_MEMORY_LIMIT"); limit != "" {
+		n, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil || n <= 0 {
+			logging.Panic(lg, "parsing GRAIN_MEMORY_LIMIT: must be a positive number of bytes",
+				"error", err)
+		}
+		cfg.Defaults.MemoryMax = n
+	}
+	weight, err := strconv.Atoi(src.GetString("GRAIN_CPU_WEIGHT"))
+	if err != nil || weight < 1 || weight > 10000 {
+		logging.Panic(lg, "parsing GRAIN_CPU_WEIGHT: must be an integer from 1 to 10000",
+			"error", err)
+	}
+	cfg.Defaults.CPUWeight = weight
+	pids, err := strconv.ParseInt(src.GetString("GRAIN_PIDS_LIMIT"), 10, 64)
+	if err != nil || pids < 0 {
+		logging.Panic(lg, "parsing GRAIN_PIDS_LIMIT: must be a non-negative integer",
+			"error", err)
+	}
+	cfg.Defaults.PidsMax = pids
+	return cfg
+}
+
+func SeccompPolicyFromSettings(lg *slog.Logger, src settings.Source) seccomp.Policy {
+	policy, err := seccomp.LoadPolicy(src.GetString("SANDBOX_SYSCALL_POLICY"))
+	if err != nil {
+		logging.Panic(lg, "parsing SANDBOX_SYSCALL_POLICY", "error", err)
+	}
+	return policy
+}
+
+func GrainIdleTimeoutFromSettings(lg *slog.Logger, src settings.Source) time.Duration {
+	timeout, err := time.ParseDuration(src.GetString("GRAIN_IDLE_TIMEOUT"))
+	if err != nil || timeout < 0 {
+		logging.Panic(lg, "parsing GRAIN_IDLE_TIMEOUT: must be a non-negative duration",
+			"error", err)
+	}
+	return timeout
+}
+
+func SandboxPoolSizeFromSettings(lg *slog.Logger, src settings.Source) int {
+	size, err := strconv.Atoi(src.GetString("SANDBOX_POOL_SIZE"))
+	if err != nil || size < 0 {
+		logging.Panic(lg, "parsing SANDBOX_POOL_SIZE: must be a non-negative integer",
+			"error", err)
+	}
+	return size
+}
+
+func GrainLogMaxSizeFromSettings(lg *slog.Logger, src settings.Source) int64 {
+	size, err := strconv.ParseInt(src.GetString("MAX_GRAIN_LOG_SIZE"), 10, 64)
+	if err != nil || size <= 0 {
+		logging.Panic(lg, "parsing MAX_GRAIN_LOG_SIZE: must be a positive integer",
+			"error", err)
+	}
+	return size
+}
+
+func RequestLimitsFromSettings(lg *slog.Logger, src settings.Source) reqlimit.Config {
+	headerSize, err := strconv.Atoi(src.GetString("HTTP_MAX_HEADER_SIZE"))
+	if err != nil || headerSize <= 0 {
+		logging.Panic(lg, "parsing HTTP_MAX_HEADER_SIZE: must be a positive integer",
+			"error", err)
+	}
+	bodySize, err := strconv.ParseInt(src.GetString("HTTP_MAX_BODY_SIZE"), 10, 64)
+	if err != nil || bodySize < 0 {
+		logging.Panic(lg, "parsing HTTP_MAX_BODY_SIZE: must be a non-negative integer",
+			"error", err)
+	}
+	duration := func(name string) time.Duration {
+		d, err := time.ParseDuration(src.GetString(name))
+		if err != nil || d < 0 {
+			logging.Panic(lg, "parsing "+name+": must be a non-negative duration",
+				"error", err)
+		}
+		return d
+	}
+	maxGrainRequests, err := strconv.Atoi(src.GetString("MAX_GRAIN_REQUESTS"))
+	if err != nil || maxGrainRequests < 0 {
+		logging.Panic(lg, "parsing MAX_GRAIN_REQUESTS: must be a non-negative integer",
+			"error", err)
+	}
+	cfg := reqlimit.Config{
+		MaxHeaderSize: headerSize,
+		IdleTimeout:   duration("HTTP_IDLE_TIMEOUT"),
+		Default: reqlimit.Limits{
+			MaxBodySize:  bodySize,
+			ReadTimeout:  duration("HTTP_READ_TIMEOUT"),
+			WriteTimeout: duration("HTTP_WRITE_TIMEOUT"),
+		},
+		MaxGrainRequests: maxGrainRequests,
+	}
+	cfg.Hosts, err = reqlimit.ParseHosts(src.GetString("HTTP_HOST_LIMITS"), cfg.Default,
+		hostKindMain, hostKindGrain, hostKindAPI)
+	if err != nil {
+		logging.Panic(lg, "parsing HTTP_HOST_LIMITS", "error", err)
+	}
+	return cfg
+}
+
+func SecurityHeadersFromSettings(lg *slog.Logger, src settings.Source) secheaders.Config {
+	hstsMaxAge, err := time.ParseDuration(src.GetString("HSTS_MAX_AGE"))
+	if err != nil || hstsMaxAge < 0 {
+		logging.Panic(lg, "parsing HSTS_MAX_AGE: must be a non-negative duration",
+			"error", err)
+	}
+	referrerPolicy := src.GetString("REFERRER_POLICY")
+	permissionsPolicy := src.GetString("PERMISSIONS_POLICY")
+	return secheaders.Config{
+		Profiles: map[string]secheaders.Profile{
+			hostKindMain: {
+				ContentSecurityPolicy: src.GetString("MAIN_CONTENT_SECURITY_POLICY"),
+				FrameOptions:          "DENY",
+				ReferrerPolicy:        referrerPolicy,
+				PermissionsPolicy:     permissionsPolicy,
+			},
+			// Grains are framed by the main UI, or by the sites
+			// they are embedded in, which their own policy's
+			// frame-ancestors allows; see ServeApp.
+			hostKindGrain: {
+				ContentSecurityPolicy: src.GetString("GRAIN_CONTENT_SECURITY_POLICY"),
+				ReferrerPolicy:        referrerPolicy,
+				PermissionsPolicy:     permissionsPolicy,
+			},
+			hostKindAPI: {
+				ContentSecurityPolicy: src.GetString("API_CONTENT_SECURITY_POLICY"),
+				FrameOptions:          "DENY",
+				ReferrerPolicy:        referrerPolicy,
+				PermissionsPolicy:     permissionsPolicy,
+			},
+		},
+		HSTSMaxAge: hstsMaxAge,
+	}
+}
+
+func BlobsConfigFromSettings(lg *slog.Logger, src settings.Source) blobstore.Config {
+	cfg := blobstore.Config{
+		Backend: src.GetString("BLOB_STORAGE"),
+		Dir:     src.GetString("BLOB_STORAGE_DIR"),
+		S3: blobstore.S3Config{
+			Endpoint:        src.GetString("BLOB_S3_ENDPOINT"),
+			Region:          src.GetString("BLOB_S3_REGION"),
+			Bucket:          src.GetString("BLOB_S3_BUCKET"),
+			Prefix:          src.GetString("BLOB_S3_PREFIX"),
+			AccessKeyID:     src.GetString("BLOB_S3_ACCESS_KEY_ID"),
+			SecretAccessKey: src.GetString("BLOB_S3_SECRET_ACCESS_KEY"),
+		},
+	}
+	if cfg.Dir == "" {
+		cfg.Dir = config.BlobsDir
+	}
+	switch cfg.Backend {
+	case blobstore.BackendLocal:
+	case blobstore.BackendS3:
+		if cfg.S3.Bucket == "" {
+			logging.Panic(lg, "BLOB_STORAGE is s3, but BLOB_S3_BUCKET is not set")
+		}
+	default:
+		logging.Panic(lg, "parsing BLOB_STORAGE: must be local or s3",
+			"value", cfg.Backend)
+	}
+	return cfg
+}
+
+func LogFormatFromSettings(lg *slog.Logger, src settings.Source) string {
+	format := src.GetString("LOG_FORMAT")
+	if _, err := logging.New(io.Discard, format); err != nil {
+		logging.Panic(lg, "parsing LOG_FORMAT", "error", err)
+	}
+	return format
+}
+
+func AppIndexURLFromSettings(lg *slog.Logger, src settings.Source) string {
+	indexURL := src.GetString("APP_INDEX_URL")
+	if indexURL == "" {
+		return ""
+	}
+	u, err := url.Parse(indexURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		logging.Panic(lg, "parsing APP_INDEX_URL: must be an http(s) URL")
+	}
+	return indexURL
+}
+
+// DevGroupIDFromSettings returns the ID of the group named by DEV_GROUP, or
+// the empty string if it isn't set.
+func DevGroupIDFromSettings(lg *slog.Logger, src settings.Source) string {
+	name := src.GetString("DEV_GROUP")
+	if name == "" {
+		return ""
+	}
+	group, err := user.LookupGroup(name)
+	if err != nil {
+		logging.Panic(lg, "parsing DEV_GROUP: must be the name of a group", "error", err)
+	}
+	return group.Gid
+}
+
 func ConfigFromSettings(lg *slog.Logger, src settings.Source) Config {
+	httpCfg := HTTPConfigFromSettings(lg, src)
 	return Config{
-		HTTP: HTTPConfigFromSettings(lg, src),
-		SMTP: SMTPConfigFromSettings(src),
+		HTTP:        httpCfg,
+		Email:       EmailConfigFromSettings(lg, src),
+		Mail:        MailConfigFromSettings(lg, src, httpCfg),
+		Replication: ReplicationConfigFromSettings(lg, src),
+		Thumbnail:   ThumbnailConfigFromSettings(src),
+		TURN:        TURNConfigFromSettings(lg, src),
+		Session:     SessionConfigFromSettings(lg, src),
+		SAML:        SAMLConfigFromSettings(lg, src),
+		Quota:       QuotaConfigFromSettings(lg, src),
+		LoginLimit:  LoginLimitConfigFromSettings(lg, src),
+		Cgroups:     CgroupConfigFromSettings(lg, src),
+		Seccomp:     SeccompPolicyFromSettings(lg, src),
+		Blobs:       BlobsConfigFromSettings(lg, src),
+		Requests:    RequestLimitsFromSettings(lg, src),
+		Headers:     SecurityHeadersFromSettings(lg, src),
+		Demo:        DemoConfigFromSettings(lg, src),
+		Maintenance: MaintenanceConfigFromSettings(lg, src),
+
+		GrainIdleTimeout: GrainIdleTimeoutFromSettings(lg, src),
+		SandboxPoolSize:  SandboxPoolSizeFromSettings(lg, src),
+		GrainLogMaxSize:  GrainLogMaxSizeFromSettings(lg, src),
+
+		LogFormat:    LogFormatFromSettings(lg, src),
+		MetricsToken: src.GetString("METRICS_TOKEN"),
+		AppIndexURL:  AppIndexURLFromSettings(lg, src),
+		DevGroupID:   DevGroupIDFromSettings(lg, src),
 	}
 }
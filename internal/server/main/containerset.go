@@ -9,6 +9,7 @@ import (
 	"sandstorm.org/go/tempest/capnp/grain"
 	grainagent "sandstorm.org/go/tempest/internal/capnp/grain-agent"
 	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/apiversion"
 	"sandstorm.org/go/tempest/internal/server/container"
 	"sandstorm.org/go/tempest/internal/server/database"
 	"zenhack.net/go/util"
@@ -28,32 +29,128 @@ func init() {
 }
 
 type ContainerSet struct {
-	// map of grain id to already-running container. TODO:
-	//
-	// - We really want some kind of weakmap semantics here; this doesn't
-	//   give us a clear way to ever shut down containers.
-	// - We need to think about detecting containers shutting down on
-	//   their own.
+	// map of grain id to already-running container. Containers are
+	// removed when they crash (see watchContainer), or have been idle
+	// for a while (see shutDownIdleGrains).
 	containersByGrainID map[types.GrainID]container.Container
+
+	// Grains whose last container crashed, so that the next start is
+	// recorded as a restart.
+	crashed map[types.GrainID]bool
+
+	// When each running grain was last used; see idle.go.
+	activity map[types.GrainID]*grainActivity
+
+	// Exited channels of containers which have been shut down, but may
+	// not have exited yet. A grain isn't started again until its old
+	// container has exited, so that two never use its storage at once.
+	stopping map[types.GrainID]<-chan struct{}
+
+	// Passed to the SandstormApi of each container we start.
+	server   *server
+	apiUsage *apiversion.Recorder
 }
 
-func (cset *ContainerSet) Get(ctx context.Context, lg *slog.Logger, db database.DB, grainID types.GrainID) (container.Container, error) {
+// Get returns the container for the grain, starting it if it is not already
+// running. started reports whether the container was started by this call.
+//
+// If the grain's last container has been shut down, but hasn't exited yet,
+// Get instead returns a channel which is closed once it has; the caller
+// should wait for it without holding the server's lock, and call Get again.
+func (cset *ContainerSet) Get(ctx context.Context, lg *slog.Logger, db database.DB, grainID types.GrainID) (c container.Container, started bool, exiting <-chan struct{}, err error) {
 	c, ok := cset.containersByGrainID[grainID]
 	if ok {
-		return c, nil
+		cset.touch(grainID)
+		return c, false, nil, nil
+	}
+	if exited, ok := cset.stopping[grainID]; ok {
+		select {
+		case <-exited:
+			delete(cset.stopping, grainID)
+		default:
+			return c, false, exited, nil
+		}
+	}
+	trashed, err := grainTrashed(db, grainID)
+	if err != nil {
+		return c, false, nil, err
 	}
-	api := grain.SandstormApi_ServerToClient(sandstormApiImpl{})
-	c, err := container.Command{
-		Log:     lg,
-		DB:      db,
-		GrainID: grainID,
-		Api:     api,
-		Args:    []string{continueArg},
+	if trashed {
+		return c, false, nil, errGrainTrashed
+	}
+	launchArg, err := grainLaunchArg(db, grainID)
+	if err != nil {
+		return c, false, nil, err
+	}
+	api := grain.SandstormApi_ServerToClient(sandstormApiImpl{
+		server:   cset.server,
+		grainID:  grainID,
+		apiUsage: cset.apiUsage,
+	})
+	c, err = container.Command{
+		Log:           lg,
+		DB:            db,
+		GrainID:       grainID,
+		Api:           api,
+		Args:          []string{launchArg},
+		Cgroups:       cset.server.cfg.Cgroups,
+		Seccomp:       cset.server.cfg.Seccomp,
+		Pool:          cset.server.sandboxes,
+		EnsurePackage: cset.server.ensurePackageUnpacked,
+		Output:        cset.server.grainLogs.Writer(grainID),
 	}.Start(ctx)
 	if err == nil {
-		cset.containersByGrainID[grainID] = c
+		cset.add(grainID, c)
+	}
+	return c, err == nil, nil, err
+}
+
+// grainLaunchArg returns the first argument to pass to the grain agent to
+// start the grain: continueArg, unless the grain is being upgraded, in which
+// case the app is told which version it was upgraded from; see
+// app-upgrade.go.
+func grainLaunchArg(db database.DB, grainID types.GrainID) (string, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+	previousVersion, pending, err := tx.PendingGrainUpgrade(grainID)
+	if err != nil || !pending {
+		return continueArg, err
+	}
+	_, seg := capnp.NewSingleSegmentMessage(nil)
+	launchCmd, err := grainagent.NewRootLaunchCommand(seg)
+	if err != nil {
+		return "", err
+	}
+	launchCmd.SetUpgradeGrain(previousVersion)
+	return base64.StdEncoding.EncodeToString(seg.Data()), nil
+}
+
+// add records c as the grain's running container.
+func (cset *ContainerSet) add(grainID types.GrainID, c container.Container) {
+	cset.containersByGrainID[grainID] = c
+	cset.touch(grainID)
+}
+
+// stop kills the grain's container, c, and forgets it.
+func (cset *ContainerSet) stop(grainID types.GrainID, c container.Container) {
+	cset.remove(grainID, c)
+	c.Kill()
+	cset.stopping[grainID] = c.Exited()
+}
+
+// remove forgets the grain's container, if it is c.
+func (cset *ContainerSet) remove(grainID types.GrainID, c container.Container) {
+	current, ok := cset.containersByGrainID[grainID]
+	if !ok || current.Exited() != c.Exited() {
+		return
+	}
+	delete(cset.containersByGrainID, grainID)
+	if a, ok := cset.activity[grainID]; ok && a.inFlight == 0 {
+		delete(cset.activity, grainID)
 	}
-	return c, err
 }
 
 func (cset *ContainerSet) Release() {
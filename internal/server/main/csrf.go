@@ -0,0 +1,145 @@
+package servermain
+
+import (
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"sandstorm.org/go/tempest/internal/browser/intl"
+	"sandstorm.org/go/tempest/internal/common/apierror"
+	"sandstorm.org/go/tempest/internal/server/replication"
+)
+
+// The session cookie is SameSite=Strict, but that doesn't stop cross-site
+// request forgery from grains: their UI hosts are subdomains of the root
+// domain, and so the same site. So requests which change anything on the
+// main host must come from the main host's own pages, as their Origin, or
+// failing that their Referer, shows; see checkSameOrigin. This also keeps
+// other sites from logging users in to the attacker's account, e.g. through
+// the dev login form, which needs no cookie.
+//
+// Checking the headers is enough without CSRF tokens, because pages can't set
+// or remove Origin, and browsers send it with every request but GET and HEAD
+// which isn't to the page's own origin, and with every WebSocket handshake.
+// A page which suppresses Referer, e.g. with Referrer-Policy, gets an Origin
+// of "null", which is rejected, as are requests with neither header, e.g.
+// from old browsers behind privacy extensions, so stripped headers fail
+// closed. What tokens would add is protection from requests which the main
+// host's own pages, or XSS in them, are tricked into sending, which comes
+// from those pages not having unsafe GETs and escaping what they show.
+//
+// Login links in emails are followed from other sites, so they only log in
+// when the request comes from the main host; otherwise they show a page which
+// does so; see serveEmailLoginConfirm.
+
+// checkSameOrigin is middleware which rejects requests to the main host which
+// might change something, i.e. those with unsafe methods, and WebSocket
+// handshakes, unless they come from the main host's own pages.
+func (s *server) checkSameOrigin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Host != s.cfg.HTTP.RootDomain || csrfExempt(req) {
+			next.ServeHTTP(w, req)
+			return
+		}
+		origin := req.Header.Get("Origin")
+		switch {
+		case isWebSocketHandshake(req):
+			// Tools other than browsers needn't send Origin, but
+			// they don't have users' cookies either.
+			if origin == "" || origin == s.rootOrigin(req) {
+				next.ServeHTTP(w, req)
+				return
+			}
+		case safeMethod(req.Method) || s.fromRootOrigin(req):
+			next.ServeHTTP(w, req)
+			return
+		}
+		s.log.InfoCtx(req.Context(), "Rejected cross-origin request",
+			"method", req.Method,
+			"path", req.URL.Path,
+			"origin", origin,
+		)
+		s.writeLocalizedError(w, req, http.StatusForbidden, apierror.New(
+			apierror.CodePermissionDenied, "cross-origin request rejected"))
+	})
+}
+
+// csrfExempt reports whether req is for an endpoint which other sites are
+// meant to post to, and which checks the request some other way.
+func csrfExempt(req *http.Request) bool {
+	// The identity provider posts the signed SAML response, and the
+	// standby authenticates with the replication secret.
+	return req.URL.Path == samlACSPath ||
+		strings.HasPrefix(req.URL.Path, replication.PathPrefix+"/")
+}
+
+func safeMethod(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS":
+		return true
+	default:
+		return false
+	}
+}
+
+func isWebSocketHandshake(req *http.Request) bool {
+	return strings.EqualFold(req.Header.Get("Upgrade"), "websocket")
+}
+
+// rootOrigin returns the origin of the main host, with the scheme of req.
+func (s *server) rootOrigin(req *http.Request) string {
+	return req.URL.Scheme + "://" + s.cfg.HTTP.RootDomain
+}
+
+// fromRootOrigin reports whether req was sent by one of the main host's
+// pages, according to its Origin header, or if there is none, its Referer.
+// Requests with neither are rejected; browsers send at least one of them
+// with requests which aren't GET or HEAD, unless the page asked them not to
+// send Referer, which ours don't.
+func (s *server) fromRootOrigin(req *http.Request) bool {
+	if origin := req.Header.Get("Origin"); origin != "" {
+		return origin == s.rootOrigin(req)
+	}
+	referer, err := url.Parse(req.Header.Get("Referer"))
+	if err != nil || referer.Host == "" {
+		return false
+	}
+	return referer.Scheme+"://"+referer.Host == s.rootOrigin(req)
+}
+
+// emailLoginConfirmTemplate asks the user to confirm logging in by a login
+// link opened from another site, e.g. their email. The "t" function localizes
+// its format string; see intl.L10N.Fmt.
+var emailLoginConfirmTemplate = template.Must(template.New("email-login-confirm").
+	Funcs(template.FuncMap{"t": intl.L10N{}.Fmt}).
+	Parse(`<!doctype html>
+<html>
+<head>
+<meta charset="utf-8" />
+<title>{{t "Log in to Tempest"}}</title>
+<link rel="stylesheet" href="/style.css">
+</head>
+<body>
+<form method="post">
+	<p>{{t "You followed a login link. Log in to Tempest?"}}</p>
+	<button type="submit">{{t "Log in"}}</button>
+</form>
+</body>
+</html>
+`))
+
+// serveEmailLoginConfirm serves emailLoginConfirmTemplate, which posts back to
+// the login link, if the link was opened from somewhere other than the main
+// host, and reports whether it did. The token isn't used until then, so
+// that neither other sites nor email scanners which fetch links can use it.
+func (s *server) serveEmailLoginConfirm(w http.ResponseWriter, req *http.Request) bool {
+	if req.Method == "POST" || req.Header.Get("Sec-Fetch-Site") == "same-origin" {
+		// The POST has passed checkSameOrigin.
+		return false
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	executeLocalized(w, emailLoginConfirmTemplate, s.requestL10N(req), nil)
+	return true
+}
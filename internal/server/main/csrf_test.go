@@ -0,0 +1,270 @@
+package servermain
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/slog"
+	"sandstorm.org/go/tempest/internal/server/replication"
+)
+
+const testRootDomain = "tempest.example"
+
+func newCSRFTestServer() *server {
+	return &server{
+		cfg: Config{HTTP: HTTPConfig{RootDomain: testRootDomain}},
+		log: slog.New(slog.NewTextHandler(io.Discard)),
+	}
+}
+
+func TestFromRootOrigin(t *testing.T) {
+	s := newCSRFTestServer()
+	for _, tc := range []struct {
+		name    string
+		origin  string
+		referer string
+		want    bool
+	}{
+		{name: "same origin", origin: "https://tempest.example", want: true},
+		{name: "cross origin", origin: "https://evil.example"},
+		{name: "grain UI host", origin: "https://ui-abc123.tempest.example"},
+		{name: "other scheme", origin: "http://tempest.example"},
+		{name: "other port", origin: "https://tempest.example:8443"},
+		{name: "opaque origin", origin: "null"},
+		{
+			name:    "origin wins over referer",
+			origin:  "https://evil.example",
+			referer: "https://tempest.example/grain/abc",
+		},
+		{name: "same origin referer", referer: "https://tempest.example/grain/abc", want: true},
+		{name: "cross origin referer", referer: "https://evil.example/tempest.example"},
+		{name: "lookalike referer", referer: "https://tempest.example.evil.example/"},
+		{name: "relative referer", referer: "/grain/abc"},
+		{name: "malformed referer", referer: "https://tempest.example/%zz"},
+		{name: "neither"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "https://tempest.example/login/dev", nil)
+			if tc.origin != "" {
+				req.Header.Set("Origin", tc.origin)
+			}
+			if tc.referer != "" {
+				req.Header.Set("Referer", tc.referer)
+			}
+			assert.Equal(t, tc.want, s.fromRootOrigin(req))
+		})
+	}
+}
+
+func TestCSRFExempt(t *testing.T) {
+	for _, tc := range []struct {
+		path string
+		want bool
+	}{
+		{path: samlACSPath, want: true},
+		{path: replication.PathPrefix + "/db", want: true},
+		{path: replication.PathPrefix},
+		{path: replication.PathPrefix + "-evil/db"},
+		{path: samlACSPath + "/evil"},
+		{path: "/login/dev"},
+	} {
+		req := httptest.NewRequest("POST", "https://tempest.example"+tc.path, nil)
+		assert.Equal(t, tc.want, csrfExempt(req), tc.path)
+	}
+}
+
+func TestCheckSameOrigin(t *testing.T) {
+	s := newCSRFTestServer()
+	handler := s.checkSameOrigin(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	for _, tc := range []struct {
+		name    string
+		method  string
+		url     string
+		headers map[string]string
+		want    int
+	}{
+		{
+			name:   "cross-origin GET",
+			method: "GET",
+			url:    "https://tempest.example/",
+			headers: map[string]string{
+				"Origin": "https://evil.example",
+			},
+			want: http.StatusNoContent,
+		},
+		{
+			name:   "same-origin POST",
+			method: "POST",
+			url:    "https://tempest.example/login/dev",
+			headers: map[string]string{
+				"Origin": "https://tempest.example",
+			},
+			want: http.StatusNoContent,
+		},
+		{
+			name:   "cross-origin POST",
+			method: "POST",
+			url:    "https://tempest.example/login/dev",
+			headers: map[string]string{
+				"Origin":  "https://evil.example",
+				"Referer": "https://tempest.example/",
+			},
+			want: http.StatusForbidden,
+		},
+		{
+			name:   "POST from a grain",
+			method: "POST",
+			url:    "https://tempest.example/login/dev",
+			headers: map[string]string{
+				"Origin": "https://ui-abc123.tempest.example",
+			},
+			want: http.StatusForbidden,
+		},
+		{
+			name:   "cross-origin DELETE",
+			method: "DELETE",
+			url:    "https://tempest.example/powerbox/requests/abc",
+			headers: map[string]string{
+				"Origin": "https://evil.example",
+			},
+			want: http.StatusForbidden,
+		},
+		{
+			name:   "same-origin Referer-only POST",
+			method: "POST",
+			url:    "https://tempest.example/login/dev",
+			headers: map[string]string{
+				"Referer": "https://tempest.example/login",
+			},
+			want: http.StatusNoContent,
+		},
+		{
+			name:   "cross-origin Referer-only POST",
+			method: "POST",
+			url:    "https://tempest.example/login/dev",
+			headers: map[string]string{
+				"Referer": "https://evil.example/",
+			},
+			want: http.StatusForbidden,
+		},
+		{
+			name:   "POST with neither header",
+			method: "POST",
+			url:    "https://tempest.example/login/dev",
+			want:   http.StatusForbidden,
+		},
+		{
+			name:   "same-origin WebSocket",
+			method: "GET",
+			url:    "https://tempest.example/_capnp-api",
+			headers: map[string]string{
+				"Upgrade": "websocket",
+				"Origin":  "https://tempest.example",
+			},
+			want: http.StatusNoContent,
+		},
+		{
+			name:   "cross-origin WebSocket",
+			method: "GET",
+			url:    "https://tempest.example/_capnp-api",
+			headers: map[string]string{
+				"Upgrade": "WebSocket",
+				"Origin":  "https://evil.example",
+			},
+			want: http.StatusForbidden,
+		},
+		{
+			name:   "WebSocket from a grain",
+			method: "GET",
+			url:    "https://tempest.example/_capnp-api",
+			headers: map[string]string{
+				"Upgrade": "websocket",
+				"Origin":  "https://ui-abc123.tempest.example",
+			},
+			want: http.StatusForbidden,
+		},
+		{
+			// Tools other than browsers.
+			name:   "WebSocket without Origin",
+			method: "GET",
+			url:    "https://tempest.example/_capnp-api",
+			headers: map[string]string{
+				"Upgrade": "websocket",
+			},
+			want: http.StatusNoContent,
+		},
+		{
+			name:   "cross-origin SAML response",
+			method: "POST",
+			url:    "https://tempest.example" + samlACSPath,
+			headers: map[string]string{
+				"Origin": "https://idp.example",
+			},
+			want: http.StatusNoContent,
+		},
+		{
+			name:   "replication without Origin",
+			method: "POST",
+			url:    "https://tempest.example" + replication.PathPrefix + "/db",
+			want:   http.StatusNoContent,
+		},
+		{
+			name:   "cross-origin POST to another host",
+			method: "POST",
+			url:    "https://ui-abc123.tempest.example/",
+			headers: map[string]string{
+				"Origin": "https://evil.example",
+			},
+			want: http.StatusNoContent,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.url, nil)
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
+
+func TestServeEmailLoginConfirm(t *testing.T) {
+	s := newCSRFTestServer()
+	for _, tc := range []struct {
+		name         string
+		method       string
+		fetchSite    string
+		wantsConfirm bool
+	}{
+		{name: "link from email", method: "GET", wantsConfirm: true},
+		{name: "link from another site", method: "GET", fetchSite: "cross-site", wantsConfirm: true},
+		{name: "link from a grain", method: "GET", fetchSite: "same-site", wantsConfirm: true},
+		{name: "link from the main host", method: "GET", fetchSite: "same-origin"},
+		{name: "confirmed", method: "POST"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "https://tempest.example/login/email/token", nil)
+			if tc.fetchSite != "" {
+				req.Header.Set("Sec-Fetch-Site", tc.fetchSite)
+			}
+			w := httptest.NewRecorder()
+			assert.Equal(t, tc.wantsConfirm, s.serveEmailLoginConfirm(w, req))
+			if !tc.wantsConfirm {
+				assert.Equal(t, 0, w.Body.Len(), "wrote a response")
+				return
+			}
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+			// The form posts back to the link itself.
+			assert.Contains(t, w.Body.String(), `<form method="post">`)
+			assert.NotContains(t, w.Body.String(), "action=")
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package servermain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/config"
+	"sandstorm.org/go/tempest/internal/server/session"
+	"sandstorm.org/go/tempest/internal/server/tokenutil"
+	"zenhack.net/go/util/exn"
+)
+
+// Demo accounts let visitors try the server without signing up, as on
+// demo.sandstorm.io. If DEMO_ACCOUNT_LIFETIME is set, anyone may make one at
+// /demo, which logs them in to a new account with the user role. Its grains
+// are held to DEMO_STORAGE_QUOTA, rather than USER_STORAGE_QUOTA, and once
+// its lifetime is up, its sessions are revoked, and it is deleted, with its
+// grains. Making demo accounts counts against the login rate limit of the
+// client's address.
+//
+// These are unrelated to `tempest demo`, which runs a throwaway server; see
+// demo.go.
+
+// How often to look for demo accounts which have expired; see
+// maintenance.go.
+const demoExpiryInterval = time.Minute
+
+var demoTemplate = template.Must(template.New("demo").Parse(`<!doctype html>
+<html>
+<head>
+<meta charset="utf-8" />
+<title>Try Tempest</title>
+</head>
+<body>
+<h1>Try Tempest</h1>
+<p>Try Tempest with a demo account, without signing up. It lasts
+{{.Lifetime}}, and may store up to {{.Quota}} MiB; then it is deleted,
+with everything you made with it.</p>
+<form method="POST" action="/demo">
+	<button type="submit">Make a demo account</button>
+</form>
+</body>
+</html>
+`))
+
+// formatLifetime formats a demo account's lifetime for people.
+func formatLifetime(d time.Duration) string {
+	n, unit := int64(d/time.Minute), "minute"
+	switch {
+	case d%(24*time.Hour) == 0:
+		n, unit = int64(d/(24*time.Hour)), "day"
+	case d%time.Hour == 0:
+		n, unit = int64(d/time.Hour), "hour"
+	}
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// serveDemo serves the page from which visitors make demo accounts.
+func (s *server) serveDemo(w http.ResponseWriter, req *http.Request) {
+	if !s.cfg.Demo.Enabled() {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	demoTemplate.Execute(w, struct {
+		Lifetime string
+		Quota    int64
+	}{
+		Lifetime: formatLifetime(s.cfg.Demo.AccountLifetime),
+		Quota:    s.cfg.Demo.StorageQuota >> 20,
+	})
+}
+
+// serveNewDemoAccount makes a demo account, and logs the visitor in to it.
+func (s *server) serveNewDemoAccount(w http.ResponseWriter, req *http.Request) {
+	if !s.cfg.Demo.Enabled() {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if !s.allowLogin(w, req, loginKeys(clientIP(req), types.Credential{})...) {
+		return
+	}
+	cred := types.Credential{
+		Type:     types.DemoCredential,
+		ScopedID: tokenutil.Gen128Base64(),
+	}
+	expires := time.Now().Add(s.cfg.Demo.AccountLifetime)
+	accountID, err := exn.Try(func(throw exn.Thrower) types.AccountID {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		accountID, err := tx.NewDemoAccount(cred, expires)
+		throw(err)
+		throw(tx.Commit())
+		return accountID
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Making demo account", "error", err)
+		return
+	}
+	s.log.InfoCtx(req.Context(), "Demo account made",
+		"accountID", accountID,
+		"expires", expires,
+	)
+	s.logIn(w, req, cred)
+}
+
+// serveDemoAccount sends the user's browser a types.DemoAccount, describing
+// whether their account is a demo account.
+func (s *server) serveDemoAccount(w http.ResponseWriter, req *http.Request) {
+	var sess session.UserSession
+	if err := session.ReadCookie(s.sessionStore, req, &sess); err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	expires, err := exn.Try(func(throw exn.Thrower) time.Time {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		accountID, err := tx.CredentialAccount(sess.Credential)
+		throw(err)
+		expires, err := tx.DemoAccountExpires(accountID)
+		throw(err)
+		return expires
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Looking up demo account", "error", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(types.DemoAccount{Expires: expires})
+}
+
+// deleteExpiredDemoAccounts deletes the demo accounts which expired at or
+// before now. It runs as a maintenance task even if demo accounts are
+// disabled, so that those made before they were still expire.
+func (s *server) deleteExpiredDemoAccounts(ctx context.Context, now time.Time) error {
+	accountIDs, err := exn.Try(func(throw exn.Thrower) []types.AccountID {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		ids, err := tx.ExpiredDemoAccounts(now)
+		throw(err)
+		return ids
+	})
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, accountID := range accountIDs {
+		if err := s.deleteDemoAccount(accountID); err != nil {
+			errs = append(errs, fmt.Errorf("deleting demo account %v: %w", accountID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deleteDemoAccount logs the demo account out everywhere, and deletes it with
+// its grains.
+func (s *server) deleteDemoAccount(accountID types.AccountID) error {
+	// First, so that the user can't make more grains while we delete
+	// these:
+	if _, err := s.revokeAccountSessions(accountID); err != nil {
+		return err
+	}
+	grainIDs, err := exn.Try(func(throw exn.Thrower) []types.GrainID {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		ids, err := tx.AccountGrainIDs(accountID)
+		throw(err)
+		return ids
+	})
+	if err != nil {
+		return err
+	}
+	for _, grainID := range grainIDs {
+		s.stopGrain(grainID)
+	}
+	err = exn.Try0(func(throw exn.Thrower) {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		for _, grainID := range grainIDs {
+			throw(tx.DeleteGrain(grainID))
+		}
+		throw(tx.DeleteAccount(accountID))
+		throw(tx.Commit())
+	})
+	if err != nil {
+		return err
+	}
+	for _, grainID := range grainIDs {
+		if err := os.RemoveAll(filepath.Join(config.GrainsDir, string(grainID))); err != nil {
+			s.log.Error("Removing expired demo account's grain",
+				"error", err,
+				"grainID", grainID,
+			)
+		}
+	}
+	s.log.Info("Expired demo account deleted",
+		"accountID", accountID,
+		"grains", len(grainIDs),
+	)
+	return nil
+}
@@ -0,0 +1,158 @@
+package servermain
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/exp/slog"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/config"
+	"sandstorm.org/go/tempest/internal/server/blobstore"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/logging"
+	"sandstorm.org/go/tempest/internal/server/session"
+	"sandstorm.org/go/tempest/internal/server/tokenutil"
+	"zenhack.net/go/util"
+	"zenhack.net/go/util/exn"
+)
+
+// Name to enter on the dev login page to log in to the demo's admin account.
+const demoUserName = "demo"
+
+// Demo runs a throwaway server for trying out Tempest, as `tempest demo`.
+//
+// The demo ignores the usual settings: it listens only on localhost, uses a
+// fresh database and blob storage, which are deleted on exit, and has a single admin account
+// which can be logged into via the dev login page. Grains are served from
+// subdomains of localhost, which browsers resolve to the loopback address
+// without any DNS setup. Grains created during the demo are deleted when it
+// exits.
+//
+// Grain storage and the sandbox launcher are still the installed ones, so
+// Tempest must have been installed with `make install`.
+func Demo(args []string) {
+	flags := flag.NewFlagSet("tempest demo", flag.ExitOnError)
+	port := flags.String("port", "8000", "port to listen on")
+	appPath := flags.String("app",
+		config.Libexecdir+"/tempest/test-app.spk",
+		"spk file to install in the demo; skipped if it does not exist")
+	flags.Parse(args)
+
+	initStorage()
+	lg := logging.NewLogger()
+	stateDir := util.Must(os.MkdirTemp(config.TempDir, "demo-"))
+	defer os.RemoveAll(stateDir)
+	db := util.Must(database.OpenPath(filepath.Join(stateDir, "demo.sqlite3")))
+	util.Chkfatal(addDemoAccount(db))
+	blobsCfg := blobstore.Config{Dir: filepath.Join(stateDir, "blobs")}
+	pkgDir := installDemoApp(lg, db, util.Must(blobsCfg.Open()), *appPath)
+
+	rootDomain := "localhost:" + *port
+	cfg := Config{
+		HTTP: HTTPConfig{
+			RootDomain: rootDomain,
+			Port:       *port,
+		},
+		Blobs: blobsCfg,
+	}
+	srv := newServer(cfg, lg, db, session.NewStore(session.NewKeys()))
+
+	l, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", *port))
+	util.Chkfatal(err)
+	httpSrv := &http.Server{Handler: srv.Handler()}
+	go monitorSignals(httpSrv)
+	fmt.Printf("\nTempest demo is running. To try it out, visit:\n\n"+
+		"    http://%s/login/dev\n\n"+
+		"and log in with the name %q. Press Ctrl+C to stop the demo;\n"+
+		"everything you do will be deleted.\n\n",
+		rootDomain, demoUserName)
+	if err := httpSrv.Serve(l); err != http.ErrServerClosed {
+		panic(err)
+	}
+
+	lg.Info("Shutting down demo; removing its grains")
+	grainIDs, err := demoGrainIDs(db)
+	srv.Release()
+	if err != nil {
+		lg.Error("Failed to list demo grains; they have not been removed",
+			"error", err)
+	}
+	for _, id := range grainIDs {
+		if err := os.RemoveAll(filepath.Join(config.GrainsDir, string(id))); err != nil {
+			lg.Error("Failed to remove demo grain", "error", err, "grainID", id)
+		}
+	}
+	if pkgDir != "" {
+		if err := os.RemoveAll(pkgDir); err != nil {
+			lg.Error("Failed to remove demo app", "error", err, "path", pkgDir)
+		}
+	}
+}
+
+// addDemoAccount creates the demo's admin account, with a dev credential
+// for demoUserName.
+func addDemoAccount(db database.DB) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		tx, err := db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		accountID := types.AccountID(tokenutil.Gen128Base64())
+		throw(tx.AddAccount(database.NewAccount{
+			ID:   accountID,
+			Role: types.RoleAdmin,
+		}))
+		throw(tx.AddCredential(database.NewCredential{
+			AccountID: accountID,
+			Login:     true,
+			Credential: types.Credential{
+				Type:     "dev",
+				ScopedID: demoUserName,
+			},
+		}))
+		throw(tx.Commit())
+	})
+}
+
+// installDemoApp installs the spk at path, if it exists. If the package was
+// not already present in the packages directory, returns the directory it was
+// unpacked to, so it can be removed when the demo exits. Failures are logged,
+// but otherwise ignored; the demo is still usable without the app.
+func installDemoApp(lg *slog.Logger, db database.DB, blobs blobstore.Store, path string) (pkgDir string) {
+	f, err := os.Open(path)
+	if err != nil {
+		lg.Warn("Not installing a demo app", "error", err)
+		return ""
+	}
+	defer f.Close()
+	existing := make(map[string]bool)
+	entries, _ := os.ReadDir(config.PackagesDir)
+	for _, ent := range entries {
+		existing[ent.Name()] = true
+	}
+	pkg, err := installSpk(context.Background(), db, blobs, f)
+	if err != nil {
+		lg.Error("Failed to install demo app", "error", err, "path", path)
+		return ""
+	}
+	lg.Info("Installed demo app", "packageId", pkg.ID, "title", appTitle(pkg.Manifest))
+	if existing[string(pkg.ID)] {
+		return ""
+	}
+	return filepath.Join(config.PackagesDir, string(pkg.ID))
+}
+
+func demoGrainIDs(db database.DB) ([]types.GrainID, error) {
+	return exn.Try(func(throw exn.Thrower) []types.GrainID {
+		tx, err := db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		ids, err := tx.GrainIDs()
+		throw(err)
+		return ids
+	})
+}
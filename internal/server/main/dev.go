@@ -0,0 +1,451 @@
+package servermain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"capnproto.org/go/capnp/v3"
+	"github.com/fsnotify/fsnotify"
+	"github.com/gorilla/mux"
+	spk "sandstorm.org/go/tempest/capnp/package"
+	"sandstorm.org/go/tempest/internal/common/apierror"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/config"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"zenhack.net/go/util/exn"
+)
+
+// The dev socket lets members of DEV_GROUP try out apps they are developing
+// without packaging them, like Sandstorm's `spk dev`. `tempest dev <dir>`
+// asks the server to serve the app whose root filesystem is dir -- what its
+// spk would contain, including sandstorm-manifest -- for as long as it runs.
+// The app is listed with the installed ones, and its grains run from dir
+// itself, so they see changes to its files at once. When its files change,
+// `tempest dev` has the server reload its manifest and stop its running
+// grains, which start with the rebuilt app when next used.
+//
+// A dev package's ID is derived from its directory, so the grains created in
+// one session work again in the next. Its entry in the packages directory is
+// a symlink to the directory, which the sandbox launcher follows.
+//
+// Developers may only serve directories they own, since the app can read
+// everything in them. The server reads them as its own user, so they must be
+// readable by it, e.g. through DEV_GROUP.
+
+const (
+	// The file in an app's root filesystem which holds its manifest.
+	devManifestFile = "sandstorm-manifest"
+
+	// How long to wait after the last change to a dev app's files before
+	// reloading it, since a build changes many files.
+	devReloadSettleTime = 500 * time.Millisecond
+)
+
+// devRequest is the body of requests to the dev socket.
+type devRequest struct {
+	// Absolute path to the app's root filesystem.
+	Dir string `json:"dir"`
+}
+
+// serveDev serves requests from `tempest dev` on l, until it fails.
+func (s *server) serveDev(l net.Listener) error {
+	if fi, err := os.Stat(filepath.Dir(config.DevSocket)); err == nil && fi.Mode()&0010 == 0 {
+		s.log.Warn("DEV_GROUP can't reach the dev socket, since its directory isn't searchable by group",
+			"dir", filepath.Dir(config.DevSocket),
+		)
+	}
+	r := mux.NewRouter()
+	r.Path("/serve").Methods("POST").HandlerFunc(s.serveDevApp)
+	r.Path("/reload").Methods("POST").HandlerFunc(s.reloadDevApp)
+	return serveLocal(l, r)
+}
+
+// serveDevApp handles POST /serve on the dev socket, which serves the app in
+// the requested directory until the client disconnects. The response is a
+// line of text once it is being served, and is kept open until then.
+func (s *server) serveDevApp(w http.ResponseWriter, req *http.Request) {
+	dir, err := devRequestDir(req)
+	if err != nil {
+		s.writeLocalError(w, err, "Serving dev app")
+		return
+	}
+	var busy bool
+	s.state.With(func(state *serverState) {
+		busy = state.devDirs[dir]
+		state.devDirs[dir] = true
+	})
+	if busy {
+		http.Error(w, dir+" is already being served", http.StatusConflict)
+		return
+	}
+	defer s.state.With(func(state *serverState) {
+		delete(state.devDirs, dir)
+	})
+	pkgID := devPackageID(dir)
+	manifest, err := s.addDevPackage(pkgID, dir)
+	if err != nil {
+		s.writeLocalError(w, err, "Serving dev app", "dir", dir)
+		return
+	}
+	defer s.removeDevPackage(pkgID)
+	cred, _ := peerCred(req)
+	s.log.Info("Serving dev app",
+		"dir", dir,
+		"packageID", pkgID,
+		"uid", cred.Uid,
+	)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "Serving %s from %s as package %s.\n", appTitle(manifest), dir, pkgID)
+	w.(http.Flusher).Flush()
+	<-req.Context().Done()
+	s.log.Info("Stopped serving dev app", "dir", dir, "packageID", pkgID)
+}
+
+// reloadDevApp handles POST /reload on the dev socket, which reloads the
+// manifest of the app being served from the requested directory, and stops
+// its running grains, so that they start with the rebuilt app. The response
+// is a line of text saying what was done.
+func (s *server) reloadDevApp(w http.ResponseWriter, req *http.Request) {
+	dir, err := devRequestDir(req)
+	if err != nil {
+		s.writeLocalError(w, err, "Reloading dev app")
+		return
+	}
+	var served bool
+	s.state.With(func(state *serverState) {
+		served = state.devDirs[dir]
+	})
+	if !served {
+		http.Error(w, dir+" isn't being served", http.StatusNotFound)
+		return
+	}
+	pkgID := devPackageID(dir)
+	manifest, err := s.addDevPackage(pkgID, dir)
+	if err != nil {
+		s.writeLocalError(w, err, "Reloading dev app", "dir", dir)
+		return
+	}
+	stopped, err := s.stopPackageGrains(pkgID)
+	if err != nil {
+		s.writeLocalError(w, err, "Reloading dev app", "dir", dir)
+		return
+	}
+	s.log.Info("Reloaded dev app", "dir", dir, "packageID", pkgID, "stoppedGrains", stopped)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "Reloaded %s; stopped %d running grains.\n", appTitle(manifest), stopped)
+}
+
+// devRequestDir returns the directory requested by a request to the dev
+// socket, with symlinks resolved, checking that the client owns it.
+func devRequestDir(req *http.Request) (string, error) {
+	cred, ok := peerCred(req)
+	if !ok {
+		return "", apierror.New(apierror.CodePermissionDenied,
+			"couldn't tell which user is connecting")
+	}
+	var body devRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		return "", apierror.New(apierror.CodeInvalidArgument, "malformed request: "+err.Error())
+	}
+	return checkDevDir(body.Dir, cred.Uid)
+}
+
+// checkDevDir checks that dir is a directory which the user with the given ID
+// may serve as an app's root filesystem, and returns it with symlinks
+// resolved.
+func checkDevDir(dir string, uid uint32) (string, error) {
+	if !filepath.IsAbs(dir) {
+		return "", apierror.New(apierror.CodeInvalidArgument,
+			"the app's directory must be an absolute path")
+	}
+	dir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", apierror.New(apierror.CodeInvalidArgument, err.Error())
+	}
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return "", apierror.New(apierror.CodeInvalidArgument, err.Error())
+	}
+	if !fi.IsDir() {
+		return "", apierror.New(apierror.CodeInvalidArgument, dir+" is not a directory")
+	}
+	if uid != 0 && fi.Sys().(*syscall.Stat_t).Uid != uid {
+		return "", apierror.New(apierror.CodePermissionDenied, "you don't own "+dir)
+	}
+	return dir, nil
+}
+
+// devPackageID returns the ID of the dev package served from dir. Like those
+// of installed packages, it is 128 bits, hex-encoded.
+func devPackageID(dir string) types.ID[database.Package] {
+	sum := sha256.Sum256([]byte("tempest-dev:" + dir))
+	return types.ID[database.Package](hex.EncodeToString(sum[:16]))
+}
+
+// readDevManifest reads the manifest of the app whose root filesystem is dir.
+func readDevManifest(dir string) (spk.Manifest, error) {
+	buf, err := os.ReadFile(filepath.Join(dir, devManifestFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return spk.Manifest{}, apierror.New(apierror.CodeInvalidArgument,
+			dir+" has no "+devManifestFile+"; is it the app's root filesystem?")
+	} else if err != nil {
+		return spk.Manifest{}, apierror.New(apierror.CodeInvalidArgument, err.Error())
+	}
+	msg, err := capnp.Unmarshal(buf)
+	if err != nil {
+		return spk.Manifest{}, apierror.New(apierror.CodeInvalidArgument,
+			"reading "+devManifestFile+": "+err.Error())
+	}
+	manifest, err := spk.ReadRootManifest(msg)
+	if err != nil {
+		return spk.Manifest{}, apierror.New(apierror.CodeInvalidArgument,
+			"reading "+devManifestFile+": "+err.Error())
+	}
+	return manifest, nil
+}
+
+// addDevPackage records the dev package with the given ID, served from dir,
+// with the manifest it has now, and links it into the packages directory.
+func (s *server) addDevPackage(pkgID types.ID[database.Package], dir string) (spk.Manifest, error) {
+	return exn.Try(func(throw exn.Thrower) spk.Manifest {
+		manifest, err := readDevManifest(dir)
+		throw(err)
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		throw(tx.AddDevPackage(database.Package{ID: pkgID, Manifest: manifest}, dir))
+		throw(tx.Commit())
+		link := filepath.Join(config.PackagesDir, string(pkgID))
+		if target, err := os.Readlink(link); err == nil && target == dir {
+			return manifest
+		}
+		throw(removeDevPackageLink(pkgID))
+		if err := os.Symlink(dir, link); err != nil {
+			s.removeDevPackage(pkgID)
+			throw(err)
+		}
+		return manifest
+	})
+}
+
+// removeDevPackage stops serving the dev package with the given ID, stopping
+// its grains, and removes it from the packages directory.
+func (s *server) removeDevPackage(pkgID types.ID[database.Package]) {
+	err := exn.Try0(func(throw exn.Thrower) {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		throw(tx.RemoveDevPackage(pkgID))
+		throw(tx.Commit())
+	})
+	if err != nil {
+		s.log.Error("Removing dev package", "error", err, "packageID", pkgID)
+	}
+	if _, err := s.stopPackageGrains(pkgID); err != nil {
+		s.log.Error("Stopping dev package's grains", "error", err, "packageID", pkgID)
+	}
+	if err := removeDevPackageLink(pkgID); err != nil {
+		s.log.Error("Removing dev package", "error", err, "packageID", pkgID)
+	}
+}
+
+// removeDevPackageLink removes the dev package with the given ID from the
+// packages directory, if it's there. Anything but a symlink is left alone.
+func removeDevPackageLink(pkgID types.ID[database.Package]) error {
+	link := filepath.Join(config.PackagesDir, string(pkgID))
+	fi, err := os.Lstat(link)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if fi.Mode()&fs.ModeSymlink == 0 {
+		return fmt.Errorf("%s is not a dev package", link)
+	}
+	return os.Remove(link)
+}
+
+// stopPackageGrains stops the running grains of the package with the given
+// ID, and returns how many there were.
+func (s *server) stopPackageGrains(pkgID types.ID[database.Package]) (int, error) {
+	grainIDs, err := exn.Try(func(throw exn.Thrower) []types.GrainID {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		grainIDs, err := tx.PackageGrainIDs(pkgID)
+		throw(err)
+		return grainIDs
+	})
+	if err != nil {
+		return 0, err
+	}
+	var running []types.GrainID
+	s.state.With(func(state *serverState) {
+		for _, grainID := range grainIDs {
+			if _, ok := state.containers.containersByGrainID[grainID]; ok {
+				running = append(running, grainID)
+			}
+		}
+	})
+	for _, grainID := range running {
+		s.stopGrain(grainID)
+	}
+	return len(running), nil
+}
+
+// removeServedDevPackages removes the dev packages which were being served
+// when the server last stopped. It is called on startup.
+func (s *server) removeServedDevPackages() {
+	pkgs, err := exn.Try(func(throw exn.Thrower) map[types.ID[database.Package]]string {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		pkgs, err := tx.ServedDevPackages()
+		throw(err)
+		return pkgs
+	})
+	if err != nil {
+		s.log.Error("Looking for dev packages", "error", err)
+		return
+	}
+	for pkgID, dir := range pkgs {
+		s.removeDevPackage(pkgID)
+		s.log.Info("Stopped serving dev app left from before the server restarted",
+			"dir", dir,
+			"packageID", pkgID,
+		)
+	}
+}
+
+// Dev implements `tempest dev`, which has the running server serve the app
+// whose root filesystem is the given directory, for as long as it runs, and
+// reload it whenever its files change.
+func Dev(args []string) {
+	flags := flag.NewFlagSet("tempest dev", flag.ExitOnError)
+	socket := flags.String("socket", config.DevSocket, "path to the server's dev socket")
+	watch := flags.Bool("watch", true, "reload the app whenever its files change")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: tempest dev [-socket <path>] [-watch=false] <dir>")
+		fmt.Fprintln(flags.Output(),
+			"Serves the app whose root filesystem, including sandstorm-manifest, is dir, until interrupted.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	dir, err := filepath.Abs(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if _, err := os.Stat(filepath.Join(dir, devManifestFile)); err != nil {
+		fmt.Fprintf(os.Stderr, "%s has no %s; is it the app's root filesystem?\n", dir, devManifestFile)
+		os.Exit(1)
+	}
+
+	client := localClient(*socket)
+	resp, err := postDev(client, "/serve", dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer resp.Body.Close()
+	if *watch {
+		go func() {
+			err := watchDevDir(dir, func() {
+				resp, err := postDev(client, "/reload", dir)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					return
+				}
+				io.Copy(os.Stdout, resp.Body)
+				resp.Body.Close()
+			})
+			fmt.Fprintln(os.Stderr, "Not watching for changes:", err)
+		}()
+	}
+	io.Copy(os.Stdout, resp.Body)
+	fmt.Fprintln(os.Stderr, "The server stopped serving the app.")
+	os.Exit(1)
+}
+
+// postDev makes a request to the dev socket about the app in dir, returning
+// an error if it fails.
+func postDev(client *http.Client, path, dir string) (*http.Response, error) {
+	body, err := json.Marshal(devRequest{Dir: dir})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Post("http://tempest-dev"+path, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("connecting to the server; is DEV_GROUP set? %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, errors.New(strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
+
+// watchDevDir calls reload whenever the files in dir change, once they have
+// stopped changing for devReloadSettleTime. It only returns if watching
+// fails.
+func watchDevDir(dir string, reload func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		return watcher.Add(path)
+	})
+	if err != nil {
+		return err
+	}
+	settle := time.NewTimer(devReloadSettleTime)
+	settle.Stop()
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			if event.Op&fsnotify.Create != 0 {
+				if fi, err := os.Lstat(event.Name); err == nil && fi.IsDir() {
+					watcher.Add(event.Name)
+				}
+			}
+			settle.Reset(devReloadSettleTime)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return err
+		case <-settle.C:
+			reload()
+		}
+	}
+}
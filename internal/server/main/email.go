@@ -0,0 +1,322 @@
+package servermain
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/pogs"
+	"github.com/gorilla/mux"
+	"sandstorm.org/go/tempest/capnp/email"
+	"sandstorm.org/go/tempest/capnp/grain"
+	hacksession "sandstorm.org/go/tempest/capnp/hack-session"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/smtpd"
+	"sandstorm.org/go/tempest/internal/server/tokenutil"
+	"zenhack.net/go/util/exn"
+)
+
+// How long to wait for a grain to accept a message.
+const mailDeliveryTimeout = 2 * time.Minute
+
+// errRelayDenied rejects recipients outside the mail domain.
+var errRelayDenied = &smtpd.Error{Code: 550, Message: "5.7.1 Relaying denied"}
+
+// serveMail accepts incoming email over SMTP on l, and delivers it to grains.
+// As in Sandstorm, a grain's address is <public id>@<mail domain>, and it
+// receives mail through a HackEmailSession. It returns when l is closed.
+func (s *server) serveMail(l net.Listener) error {
+	srv := &smtpd.Server{
+		Hostname: s.cfg.Mail.Domain,
+		MaxSize:  s.cfg.Mail.MaxSize,
+		Accept: func(addr string) error {
+			_, err := s.mailGrain(addr)
+			return err
+		},
+		Deliver: s.deliverMail,
+		Log:     s.log,
+	}
+	return srv.Serve(l)
+}
+
+// mailGrain returns the grain to which mail for addr goes.
+func (s *server) mailGrain(addr string) (types.GrainID, error) {
+	i := strings.LastIndexByte(addr, '@')
+	if i < 0 || !strings.EqualFold(addr[i+1:], s.cfg.Mail.Domain) {
+		return "", errRelayDenied
+	}
+	grainID, err := exn.Try(func(throw exn.Thrower) types.GrainID {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		grainID, err := tx.PublicIDGrain(addr[:i])
+		throw(err)
+		return grainID
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", smtpd.ErrNoSuchMailbox
+	}
+	return grainID, err
+}
+
+// deliverMail delivers a message to each grain it is addressed to. If a grain
+// can't take the message, it is kept as a dead letter rather than bounced,
+// since the grain may only be broken for now; an admin can retry it later.
+func (s *server) deliverMail(env smtpd.Envelope) error {
+	msg, err := smtpd.Parse(env.Data)
+	if err != nil {
+		return &smtpd.Error{Code: 554, Message: "5.6.0 Malformed message"}
+	}
+	received := time.Now()
+	if msg.Date == 0 {
+		msg.Date = received.UnixNano()
+	}
+	delivered := make(map[types.GrainID]bool)
+	for _, to := range env.To {
+		grainID, err := s.mailGrain(to)
+		if err != nil {
+			return err
+		}
+		if delivered[grainID] {
+			continue
+		}
+		delivered[grainID] = true
+		sendErr := s.sendMailToGrain(grainID, msg)
+		if sendErr == nil {
+			continue
+		}
+		s.log.Warn("Could not deliver email to grain",
+			"error", sendErr,
+			"grainID", grainID,
+		)
+		err = exn.Try0(func(throw exn.Thrower) {
+			tx, err := s.db.Begin()
+			throw(err)
+			defer tx.Rollback()
+			throw(tx.AddDeadLetter(database.DeadLetter{
+				GrainID:  grainID,
+				Sender:   env.From,
+				Data:     env.Data,
+				Error:    sendErr.Error(),
+				Received: received,
+			}))
+			throw(tx.Commit())
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendMailToGrain starts the grain if need be, and passes it the message
+// through a HackEmailSession.
+func (s *server) sendMailToGrain(grainID types.GrainID, msg smtpd.Message) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mailDeliveryTimeout)
+	defer cancel()
+	defer s.beginGrainRequest(grainID)()
+	return exn.Try0(func(throw exn.Thrower) {
+		c, err := s.startGrain(grainID)
+		throw(err)
+		mainView := grain.MainView(c.Bootstrap.AddRef())
+		defer mainView.Release()
+		sessionCtx := grain.SessionContext(hacksession.HackSessionContext_ServerToClient(sessionCtxImpl{
+			server:   s,
+			grainID:  grainID,
+			apiUsage: s.apiUsage,
+		}))
+
+		newSessionFut, rel := mainView.NewSession(
+			ctx,
+			func(p grain.UiView_newSession_Params) error {
+				return exn.Try0(func(throw exn.Thrower) {
+					// The sender isn't a user of the grain, so has
+					// no permissions.
+					_, err := p.NewUserInfo()
+					throw(err)
+					p.SetSessionType(hacksession.HackEmailSession_TypeID)
+					p.SetContext(sessionCtx)
+					throw(p.SetTabId(tokenutil.Gen128()))
+				})
+			})
+		defer rel()
+		newSessionRes, err := newSessionFut.Struct()
+		throw(err)
+		port := email.EmailSendPort(newSessionRes.Session().AddRef())
+		defer port.Release()
+
+		sendFut, rel := port.Send(ctx, func(p email.EmailSendPort_send_Params) error {
+			m, err := p.NewEmail()
+			if err != nil {
+				return err
+			}
+			return pogs.Insert(email.EmailMessage_TypeID, capnp.Struct(m), &msg)
+		})
+		defer rel()
+		_, err = sendFut.Struct()
+		throw(err)
+	})
+}
+
+var deadLettersTemplate = parseAdminTemplate("dead-letters", nil, `<!doctype html>
+<html>
+<head>
+<meta charset="utf-8" />
+<title>Dead letters</title>
+</head>
+<body>
+{{template "admin-nav" .Nav}}
+<h1>Dead letters</h1>
+<p>Incoming email which could not be delivered to its grain. The most recent
+{{.Max}} messages are kept.</p>
+{{if .Letters}}
+<table>
+	<tr>
+		<th>Received</th>
+		<th>Grain</th>
+		<th>Sender</th>
+		<th>Error</th>
+		{{if .CanEdit}}<th></th>{{end}}
+	</tr>
+	{{range .Letters}}
+	<tr>
+		<td>{{.Received.UTC.Format "2006-01-02 15:04:05"}}</td>
+		<td><code>{{.GrainID}}</code></td>
+		<td>{{.Sender}}</td>
+		<td>{{.Error}}</td>
+		{{if $.CanEdit}}
+		<td>
+			<form method="POST" action="/admin/dead-letters/{{.ID}}/retry">
+				<button type="submit">Retry</button>
+			</form>
+			<form method="POST" action="/admin/dead-letters/{{.ID}}/discard">
+				<button type="submit">Discard</button>
+			</form>
+		</td>
+		{{end}}
+	</tr>
+	{{end}}
+</table>
+{{else}}
+<p>There are no dead letters.</p>
+{{end}}
+</body>
+</html>
+`)
+
+func (s *server) serveDeadLetters(w http.ResponseWriter, req *http.Request) {
+	user, ok := s.requireAdminScope(w, req, types.AdminScopeInfrastructure, false)
+	if !ok {
+		return
+	}
+	letters, err := exn.Try(func(throw exn.Thrower) []database.DeadLetter {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		letters, err := tx.DeadLetters()
+		throw(err)
+		return letters
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Fetching dead letters", "error", err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	deadLettersTemplate.Execute(w, struct {
+		Nav     []adminSection
+		Letters []database.DeadLetter
+		Max     int
+		CanEdit bool
+	}{
+		Nav:     visibleAdminSections(user.Scopes),
+		Letters: letters,
+		Max:     database.MaxDeadLetters,
+		CanEdit: user.Scopes.Allows(types.AdminScopeInfrastructure, true),
+	})
+}
+
+// serveRetryDeadLetter tries again to deliver a dead letter to its grain. On
+// success, the dead letter is deleted; otherwise it is kept, with the new
+// error.
+func (s *server) serveRetryDeadLetter(w http.ResponseWriter, req *http.Request) {
+	s.handleDeadLetter(w, req, func(d database.DeadLetter) error {
+		msg, err := smtpd.Parse(d.Data)
+		if err != nil {
+			return err
+		}
+		if msg.Date == 0 {
+			msg.Date = d.Received.UnixNano()
+		}
+		return s.sendMailToGrain(d.GrainID, msg)
+	})
+}
+
+// serveDiscardDeadLetter deletes a dead letter without delivering it.
+func (s *server) serveDiscardDeadLetter(w http.ResponseWriter, req *http.Request) {
+	s.handleDeadLetter(w, req, func(database.DeadLetter) error {
+		return nil
+	})
+}
+
+// handleDeadLetter calls f with the dead letter named in the URL, deleting it
+// if f succeeds, or else recording f's error, and then redirects back to the
+// list of dead letters.
+func (s *server) handleDeadLetter(w http.ResponseWriter, req *http.Request, f func(database.DeadLetter) error) {
+	user, ok := s.requireAdminScope(w, req, types.AdminScopeInfrastructure, true)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(mux.Vars(req)["id"], 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	d, err := exn.Try(func(throw exn.Thrower) database.DeadLetter {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		d, err := tx.DeadLetter(id)
+		throw(err)
+		return d
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Fetching dead letter", "error", err, "id", id)
+		return
+	}
+	fErr := f(d)
+	err = exn.Try0(func(throw exn.Thrower) {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		throw(tx.DeleteDeadLetter(id))
+		if fErr != nil {
+			d.Error = fErr.Error()
+			throw(tx.AddDeadLetter(d))
+		}
+		throw(tx.Commit())
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Updating dead letter", "error", err, "id", id)
+		return
+	}
+	s.log.InfoCtx(req.Context(), "Dead letter handled",
+		"id", id,
+		"grainID", d.GrainID,
+		"error", fErr,
+		"handledBy", user.Credential,
+	)
+	http.Redirect(w, req, "/admin/dead-letters", http.StatusSeeOther)
+}
@@ -0,0 +1,346 @@
+package servermain
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"sandstorm.org/go/tempest/internal/common/apierror"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/session"
+	"sandstorm.org/go/tempest/internal/server/tokenutil"
+)
+
+// Grain sessions opened through an embed have session IDs made of this prefix
+// and the embed's ID, so the ui-host handler can tell them apart from those
+// of logged-in users.
+const embedSessionPrefix = "embed:"
+
+const (
+	defaultEmbedDays = 30
+	maxEmbedDays     = 365
+
+	// maxFrameAncestors limits the size of the Content-Security-Policy
+	// header, which browsers and proxies may refuse if it is too large.
+	maxFrameAncestors = 16
+)
+
+// embedIDFromSession returns the ID of the embed a grain session was opened
+// through, if any.
+func embedIDFromSession(sess session.GrainSession) (string, bool) {
+	id, ok := strings.CutPrefix(string(sess.SessionID), embedSessionPrefix)
+	return id, ok && id != ""
+}
+
+// embedSignedData returns the data signed in the URL of an embed which
+// expires at the given time, so the expiry can't be extended.
+func embedSignedData(id string, expires time.Time) []byte {
+	return []byte(id + "\x00" + strconv.FormatInt(expires.Unix(), 10))
+}
+
+// embedURL returns the signed URL through which an external site can show
+// the grain of the embed in an iframe.
+func (s *server) embedURL(e types.GrainEmbed) string {
+	u := url.URL{
+		Scheme: "http",
+		Host:   s.cfg.HTTP.RootDomain,
+		Path:   "/embed/" + e.ID,
+		RawQuery: url.Values{
+			"expires": {strconv.FormatInt(e.Expires.Unix(), 10)},
+			"sig": {base64.RawURLEncoding.EncodeToString(
+				s.sessionStore.Sign(embedSignedData(e.ID, e.Expires)),
+			)},
+		}.Encode(),
+	}
+	if s.cfg.HTTP.DefaultTLS {
+		u.Scheme = "https"
+	}
+	return u.String()
+}
+
+// normalizeOrigin checks that s is a web origin, such as
+// "https://example.com:8443", suitable for a frame-ancestors directive, and
+// returns it in canonical form.
+func normalizeOrigin(s string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return "", fmt.Errorf("%q is not an http or https origin", s)
+	}
+	if u.Host == "" || u.User != nil || (u.Path != "" && u.Path != "/") ||
+		u.RawQuery != "" || u.Fragment != "" ||
+		strings.ContainsAny(u.Host, "*;, '") {
+		return "", fmt.Errorf("%q is not an origin", s)
+	}
+	return u.Scheme + "://" + strings.ToLower(u.Host), nil
+}
+
+// checkGrainOwner checks that the account with the user session owns the
+// grain, writing an error response and returning false if it doesn't. For
+// anyone but the owner, the grain doesn't exist.
+func (s *server) checkGrainOwner(w http.ResponseWriter, tx database.Tx, sess session.UserSession, grainID types.GrainID) bool {
+	info, err := tx.GrainInfo(grainID)
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		return false
+	} else if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.Error("Looking up grain",
+			"error", err,
+			"grainID", grainID,
+		)
+		return false
+	}
+	accountID, err := tx.CredentialAccount(sess.Credential)
+	if err != nil || string(accountID) != info.Owner {
+		w.WriteHeader(http.StatusNotFound)
+		return false
+	}
+	return true
+}
+
+// serveGrainEmbeds handles requests to list, create and delete the embeds of
+// the grain named in the URL; only its owner may manage them. GET sends a
+// types.GrainEmbedList, POST creates an embed from a types.NewGrainEmbed and
+// sends it back, and DELETE, with the embed's ID in the URL, revokes it.
+func (s *server) serveGrainEmbeds(w http.ResponseWriter, req *http.Request) {
+	var sess session.UserSession
+	if err := session.ReadCookie(s.sessionStore, req, &sess); err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(req)
+	grainID := types.GrainID(vars["grainID"])
+	tx, err := s.db.Begin()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "failed to open database transaction",
+			"error", err)
+		return
+	}
+	defer tx.Rollback()
+	if !s.checkGrainOwner(w, tx, sess, grainID) {
+		return
+	}
+	now := time.Now()
+	switch req.Method {
+	case http.MethodGet:
+		s.listGrainEmbeds(w, tx, grainID, now)
+	case http.MethodPost:
+		s.createGrainEmbed(w, req, tx, grainID, now)
+	case http.MethodDelete:
+		err := tx.DeleteGrainEmbed(grainID, vars["embedID"], now)
+		if err == nil {
+			err = tx.Commit()
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			w.WriteHeader(http.StatusNotFound)
+		} else if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			s.log.ErrorCtx(req.Context(), "Deleting grain embed",
+				"error", err,
+				"grainID", grainID,
+			)
+		} else {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+}
+
+func (s *server) listGrainEmbeds(w http.ResponseWriter, tx database.Tx, grainID types.GrainID, now time.Time) {
+	embeds, err := tx.GrainEmbeds(grainID, now)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.Error("Reading grain embeds",
+			"error", err,
+			"grainID", grainID,
+		)
+		return
+	}
+	titles, err := tx.GrainPermissionTitles(grainID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.Error("Reading grain permissions",
+			"error", err,
+			"grainID", grainID,
+		)
+		return
+	}
+	list := types.GrainEmbedList{
+		Embeds:           []types.GrainEmbed{},
+		PermissionTitles: titles,
+	}
+	for _, e := range embeds {
+		e.URL = s.embedURL(e)
+		list.Embeds = append(list.Embeds, e)
+	}
+	if list.PermissionTitles == nil {
+		list.PermissionTitles = []string{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(list)
+}
+
+func (s *server) createGrainEmbed(w http.ResponseWriter, req *http.Request, tx database.Tx, grainID types.GrainID, now time.Time) {
+	var want types.NewGrainEmbed
+	err := json.NewDecoder(http.MaxBytesReader(w, req.Body, 64*1024)).Decode(&want)
+	if err != nil {
+		s.writeLocalizedError(w, req, http.StatusBadRequest, apierror.New(
+			apierror.CodeInvalidArgument, "malformed request: "+err.Error(), "request"))
+		return
+	}
+	if want.Days == 0 {
+		want.Days = defaultEmbedDays
+	}
+	if want.Days < 0 || want.Days > maxEmbedDays {
+		s.writeLocalizedError(w, req, http.StatusBadRequest, apierror.New(
+			apierror.CodeInvalidArgument,
+			fmt.Sprintf("embeds must last between 1 and %d days", maxEmbedDays),
+			"number of days"))
+		return
+	}
+	if len(want.FrameAncestors) == 0 || len(want.FrameAncestors) > maxFrameAncestors {
+		s.writeLocalizedError(w, req, http.StatusBadRequest, apierror.New(
+			apierror.CodeInvalidArgument,
+			fmt.Sprintf("embeds must allow between 1 and %d sites", maxFrameAncestors),
+			"list of sites"))
+		return
+	}
+	embed := types.GrainEmbed{
+		ID:          hex.EncodeToString(tokenutil.Gen128()),
+		GrainID:     grainID,
+		Permissions: want.Permissions,
+		Note:        want.Note,
+		Created:     now,
+		Expires:     now.AddDate(0, 0, want.Days),
+	}
+	for _, origin := range want.FrameAncestors {
+		origin, err = normalizeOrigin(origin)
+		if err != nil {
+			s.writeLocalizedError(w, req, http.StatusBadRequest, apierror.New(
+				apierror.CodeInvalidArgument, err.Error(), "origin"))
+			return
+		}
+		embed.FrameAncestors = append(embed.FrameAncestors, origin)
+	}
+	titles, err := tx.GrainPermissionTitles(grainID)
+	if err == nil && titles != nil && len(embed.Permissions) > len(titles) {
+		// The owner has every permission, but there's no point
+		// storing ones the app doesn't define.
+		embed.Permissions = embed.Permissions[:len(titles)]
+	}
+	if err == nil {
+		err = tx.AddGrainEmbed(embed)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Creating grain embed",
+			"error", err,
+			"grainID", grainID,
+		)
+		return
+	}
+	s.events.Publish(types.GrainEvent{
+		GrainID: grainID,
+		Kind:    types.GrainEmbedded,
+		Detail:  strings.Join(embed.FrameAncestors, " "),
+	})
+	embed.URL = s.embedURL(embed)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(embed)
+}
+
+// validEmbed looks up the embed with the given ID, returning an error if it
+// doesn't exist or has expired.
+func (s *server) validEmbed(id string) (types.GrainEmbed, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return types.GrainEmbed{}, err
+	}
+	defer tx.Rollback()
+	embed, err := tx.GrainEmbed(id)
+	if err != nil {
+		return embed, err
+	}
+	if !time.Now().Before(embed.Expires) {
+		return embed, errors.New("embed has expired")
+	}
+	return embed, nil
+}
+
+// writeEmbedCookie sets the grain session cookie for a session opened through
+// an embed. Unlike other grain session cookies, it must be sent in frames on
+// other sites, which browsers only allow over https.
+func (s *server) writeEmbedCookie(w http.ResponseWriter, req *http.Request, sess session.GrainSession) {
+	cookie, err := session.NewCookie(s.sessionStore, req, sess)
+	if err != nil {
+		s.log.ErrorCtx(req.Context(), "Sealing embed grain session", "error", err)
+		return
+	}
+	if req.URL.Scheme == "https" {
+		cookie.SameSite = http.SameSiteNoneMode
+	}
+	http.SetCookie(w, cookie)
+}
+
+// serveEmbed handles the signed URLs of embeds, which external sites put in
+// iframes. Like the shell's grain iframes, it hands the frame a sealed grain
+// session on a fresh ui- subdomain, but the session belongs to the embed
+// rather than to a user, and only lasts as long as the embed.
+func (s *server) serveEmbed(w http.ResponseWriter, req *http.Request) {
+	id := mux.Vars(req)["embedID"]
+	query := req.URL.Query()
+	expiresUnix, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	var sig []byte
+	if err == nil {
+		sig, err = base64.RawURLEncoding.DecodeString(query.Get("sig"))
+	}
+	if err == nil {
+		err = s.sessionStore.Verify(embedSignedData(id, time.Unix(expiresUnix, 0)), sig)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	embed, err := s.validEmbed(id)
+	if err != nil || embed.Expires.Unix() != expiresUnix {
+		s.log.DebugCtx(req.Context(), "Access to embedded grain denied",
+			"error", err,
+			"embedID", id,
+		)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	sessionToken, err := session.GrainSession{
+		GrainID:   embed.GrainID,
+		SessionID: []byte(embedSessionPrefix + embed.ID),
+	}.Seal(s.sessionStore)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Sealing embed grain session",
+			"error", err,
+			"embedID", id,
+		)
+		return
+	}
+	initURL := s.grainInitURL(sessionToken)
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, req, initURL.String(), http.StatusSeeOther)
+}
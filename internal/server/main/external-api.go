@@ -3,12 +3,13 @@ package servermain
 import (
 	"context"
 	"crypto/rand"
+	"database/sql"
 	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/mail"
 	"os"
-	"strings"
 	"time"
 
 	"capnproto.org/go/capnp/v3"
@@ -19,10 +20,12 @@ import (
 	utilcp "sandstorm.org/go/tempest/capnp/util"
 	grainagent "sandstorm.org/go/tempest/internal/capnp/grain-agent"
 	"sandstorm.org/go/tempest/internal/capnp/system"
+	"sandstorm.org/go/tempest/internal/common/apierror"
 	"sandstorm.org/go/tempest/internal/common/types"
 	"sandstorm.org/go/tempest/internal/config"
 	"sandstorm.org/go/tempest/internal/server/container"
 	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/loginlimit"
 	"sandstorm.org/go/tempest/internal/server/session"
 	"sandstorm.org/go/tempest/internal/server/tokenutil"
 	"sandstorm.org/go/tempest/pkg/exp/util/assign"
@@ -30,28 +33,68 @@ import (
 	"zenhack.net/go/util/exn"
 )
 
-var ErrNotLoggedIn = errors.New("you are not logged in")
+// Errors returned by the shell-facing APIs should be *apierror.Error, so the
+// browser can show a localized message. Their methods return them in an
+// `error` result, with withAPIError; other errors are raised as exceptions,
+// and shown as internal errors.
+
+var ErrNotLoggedIn = apierror.New(apierror.CodeNotLoggedIn, "you are not logged in")
+
+// apiResults are the results of a shell-facing method.
+type apiResults interface {
+	NewError() (external.ApiError, error)
+}
+
+// withAPIError runs f, like exn.Try0, but if f throws an *apierror.Error, it
+// is stored in res's `error` result rather than returned.
+func withAPIError(res apiResults, f func(exn.Thrower)) error {
+	return setAPIError(res, exn.Try0(f))
+}
+
+// setAPIError stores err in res's `error` result, if it is an
+// *apierror.Error, and otherwise returns it.
+func setAPIError(res apiResults, err error) error {
+	var apiErr *apierror.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	to, err := res.NewError()
+	if err != nil {
+		return err
+	}
+	return apiErr.Encode(to)
+}
 
 type externalApiImpl struct {
 	server       *server
 	userSession  session.UserSession
 	sessionStore session.Store
+	remoteAddr   string // The client's IP address.
 }
 
 func (api externalApiImpl) GetSessions(ctx context.Context, p external.ExternalApi_getSessions) error {
-	if api.userSession.Credential.Type == "" {
-		return ErrNotLoggedIn
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
 	}
-	return exn.Try0(func(throw exn.Thrower) {
-		results, err := p.AllocResults()
-		throw(err)
-		p.Go()
-
+	p.Go()
+	return withAPIError(results, func(throw exn.Thrower) {
+		if api.userSession.Credential.Type == "" {
+			throw(ErrNotLoggedIn)
+		}
 		tx, err := api.server.db.Begin()
 		throw(err)
 		defer tx.Rollback()
+		deactivated, err := tx.CredentialDeactivated(api.userSession.Credential)
+		throw(err)
+		if deactivated {
+			throw(apierror.New(apierror.CodePermissionDenied,
+				"your account has been deactivated"))
+		}
 		role, err := tx.CredentialRole(api.userSession.Credential)
 		throw(err)
+		scopes, err := tx.CredentialAdminScopes(api.userSession.Credential)
+		throw(err)
 
 		visitor := visitorSessionImpl{
 			externalApiImpl: api,
@@ -63,18 +106,22 @@ func (api externalApiImpl) GetSessions(ctx context.Context, p external.ExternalA
 			results.SetUser(external.UserSession_ServerToClient(user))
 		}
 
-		// TODO: admin
+		if scopes.Allows(types.AdminScopeUsers, false) {
+			admin := adminSessionImpl{externalApiImpl: api}
+			results.SetAdmin(external.AdminSession_ServerToClient(admin))
+		}
 	})
-	return nil
 }
 
 func (api externalApiImpl) Restore(ctx context.Context, p external.ExternalApi_restore) error {
 	p.Go()
-	return exn.Try0(func(throw exn.Thrower) {
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	return withAPIError(results, func(throw exn.Thrower) {
 		token, err := p.Args().SturdyRef()
 		throw(err)
-		results, err := p.AllocResults()
-		throw(err)
 		tx, err := api.server.db.Begin()
 		throw(err)
 		defer tx.Rollback()
@@ -82,14 +129,25 @@ func (api externalApiImpl) Restore(ctx context.Context, p external.ExternalApi_r
 			Token:     token,
 			OwnerType: "external-api",
 		})
+		if errors.Is(err, sql.ErrNoRows) {
+			throw(apierror.New(apierror.CodeNotFound,
+				"no such sturdyref, or it has expired", "sharing link"))
+		}
 		throw(err)
 		if v.GrainID == "" {
 			oid := system.SystemObjectId(v.ObjectID)
 			switch oid.Which() {
 			case system.SystemObjectId_Which_sharingToken:
+				// Opening a sharing link grants its permissions,
+				// which the user needs an account to hold.
+				if api.userSession.Credential.Type == "" {
+					throw(ErrNotLoggedIn)
+				}
 				s := oid.SharingToken()
 				id, err := s.GrainId()
 				throw(err)
+				accountID, err := tx.CredentialAccount(api.userSession.Credential)
+				throw(err)
 				_, seg := capnp.NewMultiSegmentMessage(nil)
 				kv, err := utilcp.NewKeyValue(seg)
 				throw(err)
@@ -100,10 +158,22 @@ func (api externalApiImpl) Restore(ctx context.Context, p external.ExternalApi_r
 				throw(err)
 				info, err := tx.GrainInfo(types.GrainID(id))
 				throw(err)
+				joined := info.Owner != string(accountID)
+				if joined {
+					_, _, err = tx.RedeemSharingToken(string(token), accountID, time.Now())
+					if errors.Is(err, sql.ErrNoRows) {
+						// e.g. another user just used a single-use link.
+						throw(apierror.New(apierror.CodeNotFound,
+							"no such sturdyref, or it has expired", "sharing link"))
+					}
+					throw(err)
+				}
 				throw(view.SetTitle(info.Title))
+				view.SetTrashed(!info.Trashed.IsZero())
 				sessionToken, err := session.GrainSession{
 					GrainID:   info.ID,
 					SessionID: api.userSession.SessionID,
+					AccountID: accountID,
 				}.Seal(api.sessionStore)
 				throw(err)
 				throw(view.SetSessionToken(sessionToken))
@@ -112,14 +182,27 @@ func (api externalApiImpl) Restore(ctx context.Context, p external.ExternalApi_r
 					GrainID: info.ID,
 					Session: api.userSession,
 					DB:      api.server.db,
+					Events:  api.server.events,
+					Server:  api.server,
 				})))
 				throw(kv.SetValue(view.ToPtr()))
+				throw(tx.Commit())
+				if joined {
+					api.server.events.Publish(types.GrainEvent{
+						GrainID: info.ID,
+						Kind:    types.GrainJoined,
+					})
+				}
 				throw(results.SetCap(capnp.Client(assign.FixedGetter(kv.ToPtr()))))
 			default:
-				throw(fmt.Errorf("Restore not supported on system objects of type %v", oid.Which()))
+				throw(apierror.New(apierror.CodeUnimplemented,
+					fmt.Sprintf("links to %v objects can't be opened here", oid.Which())))
 			}
 		} else {
-			throw(errors.New("TODO: implement ExternalApi.restore() for non-system objects"))
+			// e.g. a capability which a grain saved for the
+			// user, which the shell has no way to present yet.
+			throw(apierror.New(apierror.CodeUnimplemented,
+				"links to grains' capabilities can't be opened here yet"))
 		}
 	})
 }
@@ -140,16 +223,34 @@ type authenticatorImpl struct {
 }
 
 func (a authenticatorImpl) SendEmailAuthToken(ctx context.Context, p external.Authenticator_sendEmailAuthToken) error {
-	return exn.Try0(func(throw exn.Thrower) {
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	return withAPIError(results, func(throw exn.Thrower) {
 		addr, err := p.Args().Address()
 		throw(err)
-		db := a.api.server.db
-		tx, err := db.Begin()
+		locale, err := p.Args().Locale()
+		throw(err)
+		if parsed, err := mail.ParseAddress(addr); err != nil || parsed.Address != addr {
+			throw(apierror.New(apierror.CodeInvalidArgument,
+				"invalid email address: "+addr, "email address"))
+		}
+		srv := a.api.server
+		cred := types.Credential{Type: types.EmailCredential, ScopedID: addr}
+		err = srv.loginLimit.Allow(loginKeys(a.api.remoteAddr, cred)...)
+		var denied *loginlimit.Denied
+		if errors.As(err, &denied) {
+			srv.log.WarnCtx(ctx, "Login email denied", "error", err)
+			throw(apierror.New(apierror.CodeRateLimited,
+				"too many login attempts; try again in "+
+					retryAfterSeconds(denied.RetryAfter)+" seconds").WithRetry())
+		}
+		throw(err)
+		tx, err := srv.db.Begin()
 		throw(err)
 		defer tx.Rollback()
 
-		// FIXME: sanitize addr?
-
 		_, seg := capnp.NewSingleSegmentMessage(nil)
 		oid, err := system.NewRootSystemObjectId(seg)
 		throw(err)
@@ -164,28 +265,13 @@ func (a authenticatorImpl) SendEmailAuthToken(ctx context.Context, p external.Au
 				Owner:     "",
 			},
 			database.SturdyRefValue{
-				Expires:  time.Now().Add(10 * time.Minute),
+				Expires:  time.Now().Add(emailLoginTokenTTL),
 				ObjectID: capnp.Struct(oid),
 			},
 		)
 		throw(err)
 		throw(tx.Commit())
-
-		cfg := a.api.server.cfg
-		throw(cfg.SMTP.SendMail(
-			[]string{addr},
-			[]byte(strings.Join([]string{
-				"To: " + addr,
-				"From: " + cfg.SMTP.Username,
-				"Subject: Email Login Token",
-				"",
-				"Login in as " + addr + " by visiting:",
-				"",
-				cfg.HTTP.RootDomain + "/login/email/" + token,
-				"",
-				"Or entering " + token + " at the login prompt.",
-			}, "\r\n")),
-		))
+		throw(srv.sendLoginEmail(ctx, addr, locale, token))
 	})
 }
 
@@ -235,9 +321,14 @@ func (vp viewsPuller) Sync(ctx context.Context, p collection.Puller_sync) error
 				g, err := external.NewUiView(p.Segment())
 				throw(err)
 				g.SetTitle(uiViewInfo.Grain.Title)
+				g.SetTrashed(!uiViewInfo.Grain.Trashed.IsZero())
+				if uiViewInfo.Grain.Owner == string(accountID) {
+					g.SetStorageBytes(uint64(uiViewInfo.Grain.StorageBytes))
+				}
 				sessionToken, err := session.GrainSession{
 					GrainID:   uiViewInfo.Grain.ID,
 					SessionID: vp.userSession.SessionID,
+					AccountID: accountID,
 				}.Seal(vp.sessionStore)
 				throw(err)
 				g.SetSessionToken(sessionToken)
@@ -246,6 +337,8 @@ func (vp viewsPuller) Sync(ctx context.Context, p collection.Puller_sync) error
 					GrainID: uiViewInfo.Grain.ID,
 					Session: vp.userSession,
 					DB:      vp.server.db,
+					Events:  vp.server.events,
+					Server:  vp.server,
 				}))
 				p.SetValue(g.ToPtr())
 				return nil
@@ -260,35 +353,56 @@ func (vp viewsPuller) Sync(ctx context.Context, p collection.Puller_sync) error
 }
 
 func (vp viewsPuller) Attach(ctx context.Context, p external.UiView_Keyring_attach) error {
-	arg := p.Args().Controller()
-	snapshot := capnp.Client(arg).Snapshot()
-	defer snapshot.Release()
-	brand := snapshot.Brand()
-	srv, ok := cpserver.IsServer(brand)
-	if !ok {
-		return fmt.Errorf("not a server-side view controller (brand is type %T)", brand.Value)
-	}
-	ctrl, ok := srv.(uiViewControllerImpl)
-	if !ok {
-		return fmt.Errorf("not a view controller impl (type %T)", srv)
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
 	}
-	return exn.Try0(func(throw exn.Thrower) {
+	return withAPIError(results, func(throw exn.Thrower) {
+		arg := p.Args().Controller()
+		snapshot := capnp.Client(arg).Snapshot()
+		defer snapshot.Release()
+		brand := snapshot.Brand()
+		srv, ok := cpserver.IsServer(brand)
+		if !ok {
+			throw(apierror.New(apierror.CodeInvalidArgument,
+				fmt.Sprintf("not a server-side view controller (brand is type %T)", brand.Value),
+				"controller"))
+		}
+		ctrl, ok := srv.(uiViewControllerImpl)
+		if !ok {
+			throw(apierror.New(apierror.CodeInvalidArgument,
+				fmt.Sprintf("not a view controller impl (type %T)", srv),
+				"controller"))
+		}
 		tx, err := vp.server.db.Begin()
 		throw(err)
 		defer tx.Rollback()
 		accountID, err := tx.CredentialAccount(ctrl.Session.Credential)
 		throw(err)
+		_, err = accountGrainPermissions(tx, accountID, ctrl.GrainID)
+		if err == nil {
+			// Views shared with the user are in their keyring
+			// already, with the permissions they were shared
+			// with; see Restore.
+			return
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			throw(err)
+		}
 		keyring := tx.AccountKeyring(accountID)
-		throw(keyring.AttachGrain(ctrl.GrainID, nil)) // FIXME: fill in permissions.
+		throw(keyring.AttachGrain(ctrl.GrainID, nil))
 		throw(tx.Commit())
 	})
 }
 
 func (s userSessionImpl) ListPackages(ctx context.Context, p external.UserSession_listPackages) error {
 	// TODO: too much boilerplate in common with ListGrains; factor some of this out.
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
 	p.Go()
 	into := p.Args().Into()
-	return exn.Try0(func(throw exn.Thrower) {
+	return withAPIError(results, func(throw exn.Thrower) {
 		tx, err := s.visitor.server.db.Begin()
 		throw(err)
 		defer tx.Rollback()
@@ -337,7 +451,11 @@ func newGrainID() types.GrainID {
 }
 
 func (pc pkgController) Create(ctx context.Context, p external.Package_Controller_create) error {
-	return exn.Try0(func(th exn.Thrower) {
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	return withAPIError(results, func(th exn.Thrower) {
 		args := p.Args()
 		actionIndex := args.ActionIndex()
 		title, err := args.Title()
@@ -346,7 +464,8 @@ func (pc pkgController) Create(ctx context.Context, p external.Package_Controlle
 		actions, err := pc.pkg.Manifest.Actions()
 		exn.WrapThrow(th, "getting actions", err)
 		if actionIndex >= uint32(actions.Len()) {
-			th(errors.New("actionIndex out of bounds"))
+			th(apierror.New(apierror.CodeInvalidArgument,
+				"actionIndex out of bounds", "actionIndex"))
 		}
 		grainID := newGrainID()
 
@@ -356,6 +475,8 @@ func (pc pkgController) Create(ctx context.Context, p external.Package_Controlle
 		defer tx.Rollback()
 		accountID, err := tx.CredentialAccount(pc.userSession.Credential)
 		exn.WrapThrow(th, "getting account id", err)
+		_, err = pc.server.remainingStorage(tx, accountID)
+		th(err)
 
 		err = os.MkdirAll(
 			config.Localstatedir+"/sandstorm/grains/"+string(grainID)+"/sandbox",
@@ -379,9 +500,6 @@ func (pc pkgController) Create(ctx context.Context, p external.Package_Controlle
 		})
 		exn.WrapThrow(th, "encoding LaunchCommand", err)
 
-		results, err := p.AllocResults()
-		th(err)
-
 		results.SetId(string(grainID))
 		v, err := results.NewView()
 		th(err)
@@ -389,6 +507,7 @@ func (pc pkgController) Create(ctx context.Context, p external.Package_Controlle
 		sessionToken, err := session.GrainSession{
 			GrainID:   grainID,
 			SessionID: pc.userSession.SessionID,
+			AccountID: accountID,
 		}.Seal(pc.sessionStore)
 		exn.WrapThrow(th, "creating grain session token", err)
 		th(v.SetSessionToken(sessionToken))
@@ -396,22 +515,39 @@ func (pc pkgController) Create(ctx context.Context, p external.Package_Controlle
 			GrainID: grainID,
 			Session: pc.userSession,
 			DB:      pc.server.db,
+			Events:  pc.server.events,
+			Server:  pc.server,
 		})))
 		exn.WrapThrow(th, "commiting database transaction", tx.Commit())
+		pc.server.events.Publish(types.GrainEvent{
+			GrainID: grainID,
+			Kind:    types.GrainCreated,
+		})
 
 		// TODO: maybe change container.Command so it can take tx instead of a DB?
 		// But probably we shouldn't do the actual spawning in a tx anyway.
+		api := grain.SandstormApi_ServerToClient(sandstormApiImpl{
+			server:   pc.server,
+			grainID:  grainID,
+			apiUsage: pc.server.apiUsage,
+		})
 		c, err := container.Command{
-			Log:     pc.server.log,
-			DB:      pc.server.db,
-			GrainID: grainID,
-			Api:     grain.SandstormApi_ServerToClient(sandstormApiImpl{}),
-			Args:    []string{startArg},
+			Log:           pc.server.log,
+			DB:            pc.server.db,
+			GrainID:       grainID,
+			Api:           api,
+			Args:          []string{startArg},
+			Cgroups:       pc.server.cfg.Cgroups,
+			Seccomp:       pc.server.cfg.Seccomp,
+			Pool:          pc.server.sandboxes,
+			EnsurePackage: pc.server.ensurePackageUnpacked,
+			Output:        pc.server.grainLogs.Writer(grainID),
 		}.Start(context.TODO())
 		exn.WrapThrow(th, "starting container", err)
 		pc.server.state.With(func(state *serverState) {
-			state.containers.containersByGrainID[grainID] = c
+			state.containers.add(grainID, c)
 		})
+		go pc.server.watchContainer(grainID, c)
 	})
 
 }
@@ -0,0 +1,39 @@
+package servermain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"capnproto.org/go/capnp/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sandstorm.org/go/tempest/capnp/external"
+	"sandstorm.org/go/tempest/internal/common/apierror"
+)
+
+func newSetRoleResults(t *testing.T) external.AdminSession_setRole_Results {
+	_, seg := capnp.NewSingleSegmentMessage(nil)
+	res, err := external.NewRootAdminSession_setRole_Results(seg)
+	require.NoError(t, err)
+	return res
+}
+
+func TestSetAPIError(t *testing.T) {
+	// Errors for the shell are returned in the results, even if wrapped.
+	res := newSetRoleResults(t)
+	notFound := apierror.New(apierror.CodeNotFound, "no such account", "account")
+	require.NoError(t, setAPIError(res, fmt.Errorf("changing role: %w", notFound)))
+	_, err := apierror.Check(res, nil)
+	assert.Equal(t, notFound, err)
+
+	// Others are raised as exceptions.
+	res = newSetRoleResults(t)
+	broken := errors.New("database is locked")
+	assert.Same(t, broken, setAPIError(res, broken))
+	assert.False(t, res.HasError())
+
+	res = newSetRoleResults(t)
+	require.NoError(t, setAPIError(res, nil))
+	assert.False(t, res.HasError())
+}
@@ -0,0 +1,160 @@
+package servermain
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"sandstorm.org/go/tempest/internal/common/apierror"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/config"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"zenhack.net/go/util/exn"
+)
+
+// Owners delete grains in two steps, as in Sandstorm: moving a grain to the
+// trash stops it, and keeps it from being opened or started, e.g. by its
+// scheduled jobs, but it can still be restored; purging a trashed grain
+// deletes it for good. The operations are methods of UiView.Controller; see
+// viewcontroller.go.
+
+// errGrainTrashed is returned when something tries to start a grain which is
+// in the trash.
+var errGrainTrashed = errors.New("grain is in the trash")
+
+// changeOwnGrain calls change to modify the grain, on behalf of the user with
+// the given credential, if they own it, and commits the change.
+func (s *server) changeOwnGrain(
+	by types.Credential,
+	grainID types.GrainID,
+	change func(tx database.Tx, info database.GrainInfo) error,
+) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		accountID, err := tx.CredentialAccount(by)
+		throw(err)
+		info, err := tx.GrainInfo(grainID)
+		if errors.Is(err, sql.ErrNoRows) {
+			throw(apierror.New(apierror.CodeNotFound,
+				"no such grain: "+string(grainID), "grain"))
+		}
+		throw(err)
+		if info.Owner != string(accountID) {
+			throw(apierror.New(apierror.CodePermissionDenied,
+				"only the grain's owner may do this"))
+		}
+		throw(change(tx, info))
+		throw(tx.Commit())
+	})
+}
+
+// renameGrain sets the grain's title, on behalf of the user with the given
+// credential.
+func (s *server) renameGrain(by types.Credential, grainID types.GrainID, title string) error {
+	if title == "" {
+		return apierror.New(apierror.CodeInvalidArgument,
+			"grain titles may not be empty", "title")
+	}
+	return s.changeOwnGrain(by, grainID, func(tx database.Tx, _ database.GrainInfo) error {
+		return tx.SetGrainTitle(grainID, title)
+	})
+}
+
+// trashGrain moves the grain to the trash, and stops it, or if trashed is
+// false, takes it out of the trash, on behalf of the user with the given
+// credential.
+func (s *server) trashGrain(by types.Credential, grainID types.GrainID, trashed bool) error {
+	var at time.Time
+	if trashed {
+		at = time.Now()
+	}
+	err := s.changeOwnGrain(by, grainID, func(tx database.Tx, _ database.GrainInfo) error {
+		return tx.SetGrainTrashed(grainID, at)
+	})
+	if err == nil && trashed {
+		// Now that it's in the trash, it won't be started again.
+		s.stopGrain(grainID)
+	}
+	return err
+}
+
+// purgeGrain permanently deletes the grain, which must be in the trash, on
+// behalf of the user with the given credential.
+func (s *server) purgeGrain(by types.Credential, grainID types.GrainID) error {
+	checkTrashed := func(info database.GrainInfo) error {
+		if info.Trashed.IsZero() {
+			return apierror.New(apierror.CodeInvalidArgument,
+				"only grains in the trash may be purged", "grain")
+		}
+		return nil
+	}
+	err := s.changeOwnGrain(by, grainID, func(_ database.Tx, info database.GrainInfo) error {
+		return checkTrashed(info)
+	})
+	if err != nil {
+		return err
+	}
+	// Trashed grains aren't started, but may still be shutting down; wait
+	// for that outside of the transaction.
+	s.stopGrain(grainID)
+	err = s.changeOwnGrain(by, grainID, func(tx database.Tx, info database.GrainInfo) error {
+		// It may have been restored in the meantime.
+		if err := checkTrashed(info); err != nil {
+			return err
+		}
+		return tx.DeleteGrain(grainID)
+	})
+	if err != nil {
+		return err
+	}
+	// Only remove its storage once the deletion has been committed, so a
+	// failed commit doesn't leave a grain record without its data.
+	if err := os.RemoveAll(filepath.Join(config.GrainsDir, string(grainID))); err != nil {
+		return err
+	}
+	s.log.Info("Purged grain", "grainID", grainID)
+	return nil
+}
+
+// stopGrain shuts the grain down, if it is running, and closes its web
+// sessions, returning once its container has exited.
+func (s *server) stopGrain(grainID types.GrainID) {
+	var (
+		exited <-chan struct{}
+		stale  []grainSession
+	)
+	s.state.With(func(state *serverState) {
+		if c, ok := state.containers.containersByGrainID[grainID]; ok {
+			state.containers.stop(grainID, c)
+		}
+		exited = state.containers.stopping[grainID]
+		for key, gs := range state.grainSessions {
+			if key.grainID == grainID {
+				stale = append(stale, gs)
+				delete(state.grainSessions, key)
+			}
+		}
+	})
+	// As in watchContainer, release sessions outside the lock.
+	for _, gs := range stale {
+		gs.Release()
+	}
+	if exited != nil {
+		<-exited
+	}
+}
+
+// grainTrashed reports whether the grain is in the trash.
+func grainTrashed(db database.DB, grainID types.GrainID) (bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	info, err := tx.GrainInfo(grainID)
+	return !info.Trashed.IsZero(), err
+}
@@ -0,0 +1,165 @@
+package servermain
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/cgroup"
+	"zenhack.net/go/util/exn"
+)
+
+var grainLimitsTemplate = parseAdminTemplate("grain-limits", nil, `<!doctype html>
+<html>
+<head>
+<meta charset="utf-8" />
+<title>Grain resource limits</title>
+</head>
+<body>
+{{template "admin-nav" .Nav}}
+<h1>Grain resource limits</h1>
+{{if .Enabled}}
+<p>By default, each running grain may use {{with .Defaults.MemoryMax}}up to {{.}} bytes
+of{{else}}any amount of{{end}} memory and {{with .Defaults.PidsMax}}up to {{.}}{{else}}any
+number of{{end}} processes, with a CPU weight of {{.Defaults.CPUWeight}}. Grains listed below
+have limits of their own. Changes take effect when the grain next starts.</p>
+{{else}}
+<p>Grains' resources are not limited, since GRAIN_CGROUP is not set. Limits set here
+take effect once it is.</p>
+{{end}}
+{{if .Grains}}
+<table>
+	<tr>
+		<th>Grain</th>
+		<th>Memory (bytes)</th>
+		<th>CPU weight</th>
+		<th>Processes</th>
+	</tr>
+	{{range $id, $l := .Grains}}
+	<tr>
+		<td>{{$id}}</td>
+		<td>{{or $l.MemoryMax "default"}}</td>
+		<td>{{or $l.CPUWeight "default"}}</td>
+		<td>{{or $l.PidsMax "default"}}</td>
+	</tr>
+	{{end}}
+</table>
+{{else}}
+<p>No grains have limits of their own.</p>
+{{end}}
+{{if .CanEdit}}
+<h2>Set a grain's limits</h2>
+<p>Leave a limit empty, or 0, to use the default; leave them all empty to go back to the
+defaults entirely.</p>
+<form method="POST" action="/admin/grain-limits">
+	<label>Grain ID <input type="text" name="grainID" required /></label>
+	<label>Memory (bytes) <input type="number" name="memoryMax" min="0" /></label>
+	<label>CPU weight <input type="number" name="cpuWeight" min="0" max="10000" /></label>
+	<label>Processes <input type="number" name="pidsMax" min="0" /></label>
+	<button type="submit">Set</button>
+</form>
+{{end}}
+</body>
+</html>
+`)
+
+func (s *server) serveGrainLimits(w http.ResponseWriter, req *http.Request) {
+	user, ok := s.requireAdminScope(w, req, types.AdminScopeInfrastructure, false)
+	if !ok {
+		return
+	}
+	grains, err := exn.Try(func(throw exn.Thrower) map[types.GrainID]cgroup.Limits {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		grains, err := tx.AllGrainResourceLimits()
+		throw(err)
+		return grains
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Fetching grain resource limits", "error", err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	grainLimitsTemplate.Execute(w, struct {
+		Nav      []adminSection
+		Enabled  bool
+		Defaults cgroup.Limits
+		Grains   map[types.GrainID]cgroup.Limits
+		CanEdit  bool
+	}{
+		Nav:      visibleAdminSections(user.Scopes),
+		Enabled:  s.cfg.Cgroups.Enabled(),
+		Defaults: s.cfg.Cgroups.Defaults,
+		Grains:   grains,
+		CanEdit:  user.Scopes.Allows(types.AdminScopeInfrastructure, true),
+	})
+}
+
+func (s *server) serveSetGrainLimits(w http.ResponseWriter, req *http.Request) {
+	user, ok := s.requireAdminScope(w, req, types.AdminScopeInfrastructure, true)
+	if !ok {
+		return
+	}
+	grainID := types.GrainID(strings.TrimSpace(req.PostFormValue("grainID")))
+	var (
+		limits cgroup.Limits
+		err    error
+	)
+	limits.MemoryMax, err = parseLimit(req.PostFormValue("memoryMax"))
+	if err == nil {
+		var weight int64
+		weight, err = parseLimit(req.PostFormValue("cpuWeight"))
+		limits.CPUWeight = int(weight)
+	}
+	if err == nil {
+		limits.PidsMax, err = parseLimit(req.PostFormValue("pidsMax"))
+	}
+	if err == nil {
+		err = limits.Validate()
+	}
+	if grainID == "" || err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	err = exn.Try0(func(throw exn.Thrower) {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		// Check the grain exists:
+		_, err = tx.GrainPackageID(grainID)
+		throw(err)
+		throw(tx.SetGrainResourceLimits(grainID, limits))
+		throw(tx.Commit())
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Setting grain resource limits", "error", err, "grainID", grainID)
+		return
+	}
+	s.log.InfoCtx(req.Context(), "Grain resource limits set",
+		"grainID", grainID,
+		"memoryMax", limits.MemoryMax,
+		"cpuWeight", limits.CPUWeight,
+		"pidsMax", limits.PidsMax,
+		"setBy", user.Credential,
+	)
+	http.Redirect(w, req, "/admin/grain-limits", http.StatusSeeOther)
+}
+
+// parseLimit parses a limit from a form, in which an empty field is zero, i.e.
+// the default.
+func parseLimit(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
@@ -0,0 +1,123 @@
+package servermain
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"sandstorm.org/go/tempest/capnp/external"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/session"
+	"sandstorm.org/go/tempest/pkg/exp/util/bytestream"
+	"sandstorm.org/go/tempest/pkg/exp/util/handle"
+	"zenhack.net/go/util/exn"
+)
+
+// serveGrainLog sends the recent output of the grain named in the URL, as
+// plain text; see the grainlog package. With ?follow=1, it then sends the
+// grain's output as it comes, until the client goes away. Only the grain's
+// owner may read it.
+func (s *server) serveGrainLog(w http.ResponseWriter, req *http.Request) {
+	var sess session.UserSession
+	if err := session.ReadCookie(s.sessionStore, req, &sess); err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	grainID := types.GrainID(mux.Vars(req)["grainID"])
+	tx, err := s.db.Begin()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "failed to open database transaction",
+			"error", err)
+		return
+	}
+	defer tx.Rollback()
+	if !s.checkGrainOwner(w, tx, sess, grainID) {
+		return
+	}
+	tx.Rollback()
+
+	// The output is the app's, so make sure browsers don't take it for
+	// anything but text.
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	w.Header().Set("Cache-Control", "no-store")
+	if req.URL.Query().Get("follow") == "" {
+		buf, err := s.grainLogs.Tail(grainID, s.cfg.GrainLogMaxSize)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			s.log.ErrorCtx(req.Context(), "Reading grain log",
+				"error", err,
+				"grainID", grainID,
+			)
+			return
+		}
+		w.Write(buf)
+		return
+	}
+	err = s.grainLogs.Follow(req.Context(), grainID, s.cfg.GrainLogMaxSize, flushWriter{w})
+	if err != nil && req.Context().Err() == nil {
+		s.log.DebugCtx(req.Context(), "Following grain log",
+			"error", err,
+			"grainID", grainID,
+		)
+	}
+}
+
+// flushWriter is an http.ResponseWriter which flushes after each write, so
+// the client sees output as soon as it comes.
+type flushWriter struct {
+	w http.ResponseWriter
+}
+
+func (fw flushWriter) Write(p []byte) (int, error) {
+	n, err := fw.w.Write(p)
+	if err == nil {
+		err = http.NewResponseController(fw.w).Flush()
+	}
+	return n, err
+}
+
+func (s userSessionImpl) GrainLog(ctx context.Context, p external.UserSession_grainLog) error {
+	srv := s.visitor.server
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	return withAPIError(results, func(throw exn.Thrower) {
+		id, err := p.Args().GrainId()
+		throw(err)
+		grainID := types.GrainID(id)
+		tx, err := srv.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		throw(s.checkOwnsGrain(tx, grainID))
+		tx.Rollback()
+
+		if !p.Args().Follow() {
+			buf, err := srv.grainLogs.Tail(grainID, srv.cfg.GrainLogMaxSize)
+			throw(err)
+			p.Go()
+			w := bytestream.ToWriteCloser(ctx, p.Args().Into())
+			_, err = w.Write(buf)
+			throw(err)
+			throw(w.Close())
+			return
+		}
+		subCtx, h := handle.WithCancel(context.Background())
+		throw(results.SetSubscription(h))
+		into := p.Args().Into().AddRef()
+		go func() {
+			defer into.Release()
+			w := bytestream.ToWriteCloser(subCtx, into)
+			err := srv.grainLogs.Follow(subCtx, grainID, srv.cfg.GrainLogMaxSize, w)
+			if err != nil && subCtx.Err() == nil {
+				srv.log.Debug("Following grain log",
+					"error", err,
+					"grainID", grainID,
+				)
+			}
+		}()
+	})
+}
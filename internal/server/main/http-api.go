@@ -0,0 +1,506 @@
+package servermain
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/exc"
+	websession "sandstorm.org/go/tempest/capnp/web-session"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/egress"
+	pkgwebsession "sandstorm.org/go/tempest/pkg/exp/websession"
+	"zenhack.net/go/util/exn"
+)
+
+// A grain may ask through the powerbox for an ApiSession on an HTTP API
+// outside of Tempest, e.g. https://api.github.com. The user approves the URL
+// the grain suggests, or another, and may give a credential, which Tempest
+// adds to each request as its Authorization header, so that the grain never
+// learns it. Tempest makes the requests itself, with egress.HTTPClient, so
+// the grain needs no access to the rest of the network.
+//
+// Any user may grant an HTTP API on the public internet, but only
+// infrastructure admins, who may grant the network too, may grant one on a private network or the
+// server itself, e.g. http://192.168.1.10; otherwise users could reach into
+// the operator's network through their grains. This is checked as each
+// request connects, after the API's host name is resolved.
+
+// maxHTTPAPIResponseSize is the largest response body we relay to a grain.
+const maxHTTPAPIResponseSize = 16 << 20
+
+var (
+	// httpAPIClient makes the requests to HTTP APIs granted by users.
+	httpAPIClient = egress.HTTPClient()
+
+	// privateHTTPAPIClient makes the requests to HTTP APIs granted by
+	// infrastructure admins, which may be on private networks.
+	privateHTTPAPIClient = egress.PrivateHTTPClient()
+)
+
+// parseHTTPAPIURL checks that u is a URL which may be granted as an HTTP API,
+// and returns it without a trailing '/', as canonicalUrl in api-session.capnp
+// is written.
+func parseHTTPAPIURL(u string) (string, error) {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", fmt.Errorf("not an http or https URL: %q", u)
+	}
+	if parsed.Host == "" || parsed.User != nil || parsed.RawQuery != "" || parsed.Fragment != "" {
+		return "", fmt.Errorf("URL must have a host, and no user, query or fragment: %q", u)
+	}
+	return strings.TrimSuffix(parsed.String(), "/"), nil
+}
+
+// canGrantHTTPAPI reports whether the account may grant grains access to an
+// HTTP API, which visitors may not.
+func canGrantHTTPAPI(tx database.Tx, accountID types.AccountID) (bool, error) {
+	role, err := tx.AccountRole(accountID)
+	return role.Encompasses(types.RoleUser), err
+}
+
+// isPrivateHTTPAPI reports whether u, as returned by parseHTTPAPIURL, names
+// its host by an address on a private network or of the server, which only
+// admins may grant. Hosts named otherwise are checked once resolved, as the
+// API's requests connect.
+func isPrivateHTTPAPI(u string) bool {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return true
+	}
+	addr, err := netip.ParseAddr(parsed.Hostname())
+	return err == nil && !egress.Public(addr.Unmap())
+}
+
+// powerboxHTTPAPI is an HTTP API, as granted to a grain through the powerbox.
+// It works only while the user who granted it may still grant it, and has
+// the required permissions on the grain holding it.
+type powerboxHTTPAPI struct {
+	server *server
+	holder types.GrainID
+	grant  database.PowerboxGrant
+}
+
+// check returns an error if the grant has been revoked, and otherwise the
+// client to make the API's requests with: one which reaches private networks
+// only if the user who granted the API may grant the network.
+func (a powerboxHTTPAPI) check(tx database.Tx) (*http.Client, error) {
+	if err := checkHolder(tx, a.holder, a.grant); err != nil {
+		return nil, err
+	}
+	ok, err := canGrantHTTPAPI(tx, a.grant.AccountID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !ok) {
+		return nil, errRevoked
+	} else if err != nil {
+		return nil, err
+	}
+	admin, err := canGrantNetwork(tx, a.grant.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if admin {
+		return privateHTTPAPIClient, nil
+	}
+	return httpAPIClient, nil
+}
+
+// url returns the URL of the resource at path, relative to the API's URL, or
+// an error if it would be outside of the API.
+func (a powerboxHTTPAPI) url(path string) (string, error) {
+	p, _, _ := strings.Cut(path, "?")
+	for _, segment := range strings.Split(p, "/") {
+		segment, err := url.PathUnescape(segment)
+		if err != nil || segment == ".." || segment == "." {
+			return "", exc.New(exc.Failed, "powerboxHTTPAPI", "invalid path: "+path)
+		}
+	}
+	return a.grant.HTTPAPI + "/" + path, nil
+}
+
+// do makes a request to the API on the grain's behalf, and fills in resp
+// from the reply. content is the request's body, if it has one.
+func (a powerboxHTTPAPI) do(
+	ctx context.Context,
+	method, path string,
+	content websession.RequestContent,
+	wsCtx websession.Context,
+	resp websession.Response,
+) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		client, err := exn.Try(func(throw exn.Thrower) *http.Client {
+			tx, err := a.server.db.Begin()
+			throw(err)
+			defer tx.Rollback()
+			client, err := a.check(tx)
+			throw(err)
+			return client
+		})
+		throw(err)
+		u, err := a.url(path)
+		throw(err)
+		var body []byte
+		if content.IsValid() {
+			body, err = content.Content()
+			throw(err)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+		throw(err)
+		throw(placeHTTPAPIRequestHeaders(req.Header, content, wsCtx))
+		if a.grant.HTTPAuthorization != "" {
+			req.Header.Set("Authorization", a.grant.HTTPAuthorization)
+		}
+		res, err := client.Do(req)
+		throw(err)
+		defer res.Body.Close()
+		resBody, err := io.ReadAll(io.LimitReader(res.Body, maxHTTPAPIResponseSize+1))
+		throw(err)
+		if len(resBody) > maxHTTPAPIResponseSize {
+			throw(exc.New(exc.Failed, "powerboxHTTPAPI", "response too large"))
+		}
+		throw(relayHTTPAPIResponse(resp, res, resBody))
+	})
+}
+
+// placeHTTPAPIRequestHeaders sets the headers of a request to an HTTP API
+// from the request's content and context. Of the additional headers, only
+// those allowed by web-session.capnp are sent.
+func placeHTTPAPIRequestHeaders(h http.Header, content websession.RequestContent, wsCtx websession.Context) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		if content.IsValid() {
+			mimeType, err := content.MimeType()
+			throw(err)
+			if mimeType != "" {
+				h.Set("Content-Type", mimeType)
+			}
+			encoding, err := content.Encoding()
+			throw(err)
+			if encoding != "" {
+				h.Set("Content-Encoding", encoding)
+			}
+		}
+		accept, err := wsCtx.Accept()
+		throw(err)
+		var accepted []string
+		for i := 0; i < accept.Len(); i++ {
+			mimeType, err := accept.At(i).MimeType()
+			throw(err)
+			q := accept.At(i).QValue()
+			if q == 1 {
+				accepted = append(accepted, mimeType)
+			} else {
+				accepted = append(accepted, mimeType+";q="+strconv.FormatFloat(float64(q), 'g', 3, 32))
+			}
+		}
+		if len(accepted) > 0 {
+			h.Set("Accept", strings.Join(accepted, ", "))
+		}
+		precondition := wsCtx.ETagPrecondition()
+		switch precondition.Which() {
+		case websession.Context_eTagPrecondition_Which_exists:
+			h.Set("If-Match", "*")
+		case websession.Context_eTagPrecondition_Which_doesntExist:
+			h.Set("If-None-Match", "*")
+		case websession.Context_eTagPrecondition_Which_matchesOneOf:
+			tags, err := precondition.MatchesOneOf()
+			throw(err)
+			h.Set("If-Match", eTagList(tags))
+		case websession.Context_eTagPrecondition_Which_matchesNoneOf:
+			tags, err := precondition.MatchesNoneOf()
+			throw(err)
+			h.Set("If-None-Match", eTagList(tags))
+		}
+		headers, err := wsCtx.AdditionalHeaders()
+		throw(err)
+		for i := 0; i < headers.Len(); i++ {
+			key, err := headers.At(i).Key()
+			throw(err)
+			value, err := headers.At(i).Value()
+			throw(err)
+			name := http.CanonicalHeaderKey(key.Text())
+			if pkgwebsession.ContextHeaderFilter.Allows(name) {
+				h.Add(name, value.Text())
+			}
+		}
+	})
+}
+
+// eTagList formats tags for an If-Match or If-None-Match header.
+func eTagList(tags websession.ETag_List) string {
+	var ret []string
+	for i := 0; i < tags.Len(); i++ {
+		value, _ := tags.At(i).Value()
+		tag := `"` + value + `"`
+		if tags.At(i).Weak() {
+			tag = "W/" + tag
+		}
+		ret = append(ret, tag)
+	}
+	return strings.Join(ret, ", ")
+}
+
+// setETag copies the response's ETag header, if it has one, to the ETag
+// allocated by newETag.
+func setETag(res *http.Response, newETag func() (websession.ETag, error)) error {
+	tag := res.Header.Get("ETag")
+	if tag == "" {
+		return nil
+	}
+	weak := strings.HasPrefix(tag, "W/")
+	value := strings.Trim(strings.TrimPrefix(tag, "W/"), `"`)
+	dst, err := newETag()
+	if err != nil {
+		return err
+	}
+	dst.SetWeak(weak)
+	return dst.SetValue(value)
+}
+
+// relayHTTPAPIResponse fills in resp from an HTTP API's response res, whose
+// body has been read. Statuses which web-session.capnp can't express are
+// mapped as it describes: unknown 4xx statuses to 400, and others to 500.
+func relayHTTPAPIResponse(resp websession.Response, res *http.Response, body []byte) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		status := res.StatusCode
+		location, _ := res.Location()
+		switch {
+		case status == http.StatusNoContent || status == http.StatusResetContent:
+			resp.SetNoContent()
+			noContent := resp.NoContent()
+			noContent.SetShouldResetForm(status == http.StatusResetContent)
+			throw(setETag(res, noContent.NewETag))
+		case status >= 200 && status < 300 || status == http.StatusNotModified:
+			resp.SetContent()
+			content := resp.Content()
+			code, ok := httpSuccessCodes[status]
+			if !ok {
+				code = websession.SuccessCode_ok
+			}
+			content.SetStatusCode(code)
+			throw(content.SetMimeType(res.Header.Get("Content-Type")))
+			throw(content.SetEncoding(res.Header.Get("Content-Encoding")))
+			throw(content.SetLanguage(res.Header.Get("Content-Language")))
+			throw(setETag(res, content.NewETag))
+			throw(content.Body().SetBytes(body))
+		case status >= 300 && status < 400 && location != nil:
+			resp.SetRedirect()
+			redirect := resp.Redirect()
+			redirect.SetIsPermanent(status == http.StatusMovedPermanently || status == http.StatusPermanentRedirect)
+			redirect.SetSwitchToGet(status != http.StatusTemporaryRedirect && status != http.StatusPermanentRedirect)
+			throw(redirect.SetLocation(location.String()))
+		case status == http.StatusPreconditionFailed:
+			resp.SetPreconditionFailed()
+			throw(setETag(res, resp.PreconditionFailed().NewMatchingETag))
+		case status >= 400 && status < 500:
+			resp.SetClientError()
+			clientError := resp.ClientError()
+			code, ok := httpClientErrorCodes[status]
+			if !ok {
+				code = websession.ClientErrorCode_badRequest
+			}
+			clientError.SetStatusCode(code)
+			errorBody, err := clientError.NewNonHtmlBody()
+			throw(err)
+			throw(placeErrorBody(errorBody, res, body))
+		default:
+			resp.SetServerError()
+			errorBody, err := resp.ServerError().NewNonHtmlBody()
+			throw(err)
+			throw(placeErrorBody(errorBody, res, body))
+		}
+		var headers []string
+		for name := range res.Header {
+			if pkgwebsession.ResponseHeaderFilter.Allows(name) {
+				headers = append(headers, name)
+			}
+		}
+		additional, err := resp.NewAdditionalHeaders(int32(len(headers)))
+		throw(err)
+		for i, name := range headers {
+			key, err := capnp.NewText(additional.Segment(), name)
+			throw(err)
+			throw(additional.At(i).SetKey(key.ToPtr()))
+			value, err := capnp.NewText(additional.Segment(), res.Header.Get(name))
+			throw(err)
+			throw(additional.At(i).SetValue(value.ToPtr()))
+		}
+	})
+}
+
+// placeErrorBody copies an error response's body and its headers to dst.
+func placeErrorBody(dst websession.ErrorBody, res *http.Response, body []byte) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		throw(dst.SetData(body))
+		throw(dst.SetMimeType(res.Header.Get("Content-Type")))
+		throw(dst.SetEncoding(res.Header.Get("Content-Encoding")))
+		throw(dst.SetLanguage(res.Header.Get("Content-Language")))
+	})
+}
+
+// httpSuccessCodes and httpClientErrorCodes map HTTP statuses to those in
+// web-session.capnp.
+var (
+	httpSuccessCodes = map[int]websession.SuccessCode{
+		http.StatusOK:             websession.SuccessCode_ok,
+		http.StatusCreated:        websession.SuccessCode_created,
+		http.StatusAccepted:       websession.SuccessCode_accepted,
+		http.StatusPartialContent: websession.SuccessCode_partialContent,
+		http.StatusMultiStatus:    websession.SuccessCode_multiStatus,
+		http.StatusNotModified:    websession.SuccessCode_notModified,
+	}
+	httpClientErrorCodes = map[int]websession.ClientErrorCode{
+		http.StatusBadRequest:            websession.ClientErrorCode_badRequest,
+		http.StatusForbidden:             websession.ClientErrorCode_forbidden,
+		http.StatusNotFound:              websession.ClientErrorCode_notFound,
+		http.StatusMethodNotAllowed:      websession.ClientErrorCode_methodNotAllowed,
+		http.StatusNotAcceptable:         websession.ClientErrorCode_notAcceptable,
+		http.StatusConflict:              websession.ClientErrorCode_conflict,
+		http.StatusGone:                  websession.ClientErrorCode_gone,
+		http.StatusRequestEntityTooLarge: websession.ClientErrorCode_requestEntityTooLarge,
+		http.StatusRequestURITooLong:     websession.ClientErrorCode_requestUriTooLong,
+		http.StatusUnsupportedMediaType:  websession.ClientErrorCode_unsupportedMediaType,
+		http.StatusTeapot:                websession.ClientErrorCode_imATeapot,
+		http.StatusUnprocessableEntity:   websession.ClientErrorCode_unprocessableEntity,
+	}
+)
+
+func (a powerboxHTTPAPI) Get(ctx context.Context, p websession.WebSession_get) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		path, err := p.Args().Path()
+		throw(err)
+		wsCtx, err := p.Args().Context()
+		throw(err)
+		resp, err := p.AllocResults()
+		throw(err)
+		method := http.MethodGet
+		if p.Args().IgnoreBody() {
+			method = http.MethodHead
+		}
+		throw(a.do(ctx, method, path, websession.RequestContent{}, wsCtx, resp))
+	})
+}
+
+func (a powerboxHTTPAPI) Delete(ctx context.Context, p websession.WebSession_delete) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		path, err := p.Args().Path()
+		throw(err)
+		wsCtx, err := p.Args().Context()
+		throw(err)
+		resp, err := p.AllocResults()
+		throw(err)
+		throw(a.do(ctx, http.MethodDelete, path, websession.RequestContent{}, wsCtx, resp))
+	})
+}
+
+// postLikeParams are the params of the methods which send a body.
+type postLikeParams interface {
+	Path() (string, error)
+	Content() (websession.RequestContent, error)
+	Context() (websession.Context, error)
+}
+
+// doPostLike makes a request with a body, from the params of Post, Put or
+// Patch.
+func (a powerboxHTTPAPI) doPostLike(ctx context.Context, method string, args postLikeParams, resp websession.Response) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		path, err := args.Path()
+		throw(err)
+		content, err := args.Content()
+		throw(err)
+		wsCtx, err := args.Context()
+		throw(err)
+		throw(a.do(ctx, method, path, content, wsCtx, resp))
+	})
+}
+
+func (a powerboxHTTPAPI) Post(ctx context.Context, p websession.WebSession_post) error {
+	resp, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	return a.doPostLike(ctx, http.MethodPost, p.Args(), resp)
+}
+
+func (a powerboxHTTPAPI) Put(ctx context.Context, p websession.WebSession_put) error {
+	resp, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	return a.doPostLike(ctx, http.MethodPut, p.Args(), resp)
+}
+
+func (a powerboxHTTPAPI) Patch(ctx context.Context, p websession.WebSession_patch) error {
+	resp, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	return a.doPostLike(ctx, http.MethodPatch, p.Args(), resp)
+}
+
+// errHTTPAPIUnimplemented is returned by the methods of WebSession which
+// HTTP APIs don't support.
+var errHTTPAPIUnimplemented = exc.New(exc.Unimplemented, "powerboxHTTPAPI", "not supported by HTTP APIs")
+
+func (powerboxHTTPAPI) OpenWebSocket(context.Context, websession.WebSession_openWebSocket) error {
+	return errHTTPAPIUnimplemented
+}
+
+func (powerboxHTTPAPI) PostStreaming(context.Context, websession.WebSession_postStreaming) error {
+	return errHTTPAPIUnimplemented
+}
+
+func (powerboxHTTPAPI) PutStreaming(context.Context, websession.WebSession_putStreaming) error {
+	return errHTTPAPIUnimplemented
+}
+
+func (powerboxHTTPAPI) Propfind(context.Context, websession.WebSession_propfind) error {
+	return errHTTPAPIUnimplemented
+}
+
+func (powerboxHTTPAPI) Proppatch(context.Context, websession.WebSession_proppatch) error {
+	return errHTTPAPIUnimplemented
+}
+
+func (powerboxHTTPAPI) Mkcol(context.Context, websession.WebSession_mkcol) error {
+	return errHTTPAPIUnimplemented
+}
+
+func (powerboxHTTPAPI) Copy(context.Context, websession.WebSession_copy) error {
+	return errHTTPAPIUnimplemented
+}
+
+func (powerboxHTTPAPI) Move(context.Context, websession.WebSession_move) error {
+	return errHTTPAPIUnimplemented
+}
+
+func (powerboxHTTPAPI) Lock(context.Context, websession.WebSession_lock) error {
+	return errHTTPAPIUnimplemented
+}
+
+func (powerboxHTTPAPI) Unlock(context.Context, websession.WebSession_unlock) error {
+	return errHTTPAPIUnimplemented
+}
+
+func (powerboxHTTPAPI) Acl(context.Context, websession.WebSession_acl) error {
+	return errHTTPAPIUnimplemented
+}
+
+func (powerboxHTTPAPI) Report(context.Context, websession.WebSession_report) error {
+	return errHTTPAPIUnimplemented
+}
+
+func (powerboxHTTPAPI) Options(context.Context, websession.WebSession_options) error {
+	return errHTTPAPIUnimplemented
+}
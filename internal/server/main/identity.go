@@ -2,8 +2,20 @@ package servermain
 
 import (
 	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
 
+	"github.com/gorilla/mux"
 	"sandstorm.org/go/tempest/capnp/identity"
+	"sandstorm.org/go/tempest/capnp/util"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"zenhack.net/go/util/exn"
 )
 
 // Implementation of identity.Identity. getProfile() just returns
@@ -19,3 +31,129 @@ func (id identityImpl) GetProfile(ctx context.Context, p identity.Identity_getPr
 	}
 	return res.SetProfile(id.profile)
 }
+
+// identityID returns the ID of the account's identity, as grains see it in
+// UserInfo.identityId and from SandstormApi.getIdentityId. Hashing the
+// account ID keeps it from leaking to apps.
+func identityID(accountID types.AccountID) []byte {
+	id := sha256.Sum256([]byte("tempest-identity:" + accountID))
+	return id[:]
+}
+
+// setUserInfoIdentity fills in the user's identity ID and profile, which lets
+// apps match them with identities from the powerbox.
+func (s *server) setUserInfoIdentity(userInfo identity.UserInfo, c database.Contact) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		throw(userInfo.SetIdentityId(identityID(c.AccountID)))
+		displayName, err := userInfo.NewDisplayName()
+		throw(err)
+		throw(displayName.SetDefaultText(c.DisplayName))
+		throw(userInfo.SetPreferredHandle(c.PreferredHandle))
+		throw(userInfo.SetPictureUrl(s.identiconURL(c.AccountID)))
+	})
+}
+
+// powerboxIdentity is a user's identity, as granted to a grain through the
+// powerbox. Its profile is read when asked for, so it follows changes to the
+// user's account, and it works only while the user who granted it has the
+// required permissions on the grain holding it.
+type powerboxIdentity struct {
+	server *server
+	holder types.GrainID
+	grant  database.PowerboxGrant
+}
+
+// contact returns the Contact of the identity's account, or an error if the
+// grant has been revoked.
+func (i powerboxIdentity) contact(tx database.Tx) (database.Contact, error) {
+	if err := checkHolder(tx, i.holder, i.grant); err != nil {
+		return database.Contact{}, err
+	}
+	c, err := tx.AccountContact(i.grant.Identity)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.Contact{}, errRevoked
+	}
+	return c, err
+}
+
+func (i powerboxIdentity) GetProfile(ctx context.Context, p identity.Identity_getProfile) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		tx, err := i.server.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		c, err := i.contact(tx)
+		throw(err)
+		results, err := p.AllocResults()
+		throw(err)
+		profile, err := results.NewProfile()
+		throw(err)
+		displayName, err := profile.NewDisplayName()
+		throw(err)
+		throw(displayName.SetDefaultText(c.DisplayName))
+		throw(profile.SetPreferredHandle(c.PreferredHandle))
+		throw(profile.SetPicture(util.StaticAsset_ServerToClient(staticAsset{
+			url: i.server.identiconURL(c.AccountID),
+		})))
+	})
+}
+
+// staticAsset is a util.StaticAsset served from url.
+type staticAsset struct {
+	url string
+}
+
+func (a staticAsset) GetUrl(ctx context.Context, p util.StaticAsset_getUrl) error {
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	protocol, hostPath, _ := strings.Cut(a.url, "://")
+	if protocol == "http" {
+		results.SetProtocol(util.StaticAsset_Protocol_http)
+	} else {
+		results.SetProtocol(util.StaticAsset_Protocol_https)
+	}
+	return results.SetHostPath(hostPath)
+}
+
+// identiconURL returns the URL of the account's avatar. Users can't upload
+// pictures yet, so as in Sandstorm, everyone gets an identicon derived from
+// their identity ID.
+func (s *server) identiconURL(accountID types.AccountID) string {
+	scheme := "http"
+	if s.cfg.HTTP.DefaultTLS {
+		scheme = "https"
+	}
+	return scheme + "://" + s.cfg.HTTP.RootDomain +
+		"/identicon/" + hex.EncodeToString(identityID(accountID)) + ".svg"
+}
+
+// serveIdenticon renders the identicon for the identity ID in the URL, as an
+// SVG image: a symmetric 5x5 pattern, in a color taken from the ID. It
+// depends only on the ID, so needs no login.
+func (s *server) serveIdenticon(w http.ResponseWriter, req *http.Request) {
+	id, err := hex.DecodeString(mux.Vars(req)["identityID"])
+	if err != nil || len(id) != sha256.Size {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var b strings.Builder
+	b.WriteString(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 5 5">`)
+	b.WriteString(`<rect width="5" height="5" fill="#f0f0f0"/>`)
+	fmt.Fprintf(&b, `<g fill="hsl(%d, 60%%, 45%%)">`, int(id[0])*360/256)
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 3; x++ {
+			if id[1+y*3+x]&1 == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, `<rect x="%d" y="%d" width="1" height="1"/>`, x, y)
+			if x < 2 {
+				fmt.Fprintf(&b, `<rect x="%d" y="%d" width="1" height="1"/>`, 4-x, y)
+			}
+		}
+	}
+	b.WriteString(`</g></svg>`)
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Write([]byte(b.String()))
+}
@@ -0,0 +1,129 @@
+package servermain
+
+import (
+	"context"
+	"time"
+
+	"sandstorm.org/go/tempest/internal/common/types"
+)
+
+// Running grains are shut down once nothing has used them for
+// cfg.GrainIdleTimeout, to reclaim their memory. Since apps are crash-only
+// software, this is just a Kill; the next request to the grain, through a
+// web session or a capability, starts it again, and waits for it to start
+// before being delivered.
+//
+// A grain isn't shut down while it has requests in flight, e.g. an open
+// WebSocket, and requests which arrive once it has been picked for shutdown
+// find it gone, and wait for it to exit before starting it again, so no
+// request is lost to a grain being shut down under it.
+
+// The longest time between checks for idle grains.
+const maxIdleCheckInterval = time.Minute
+
+// grainActivity tracks the use of a running grain.
+type grainActivity struct {
+	last     time.Time // When the grain was last used.
+	inFlight int       // How many requests to the grain are in progress.
+}
+
+// touch records that the grain is being used now.
+func (cset *ContainerSet) touch(grainID types.GrainID) {
+	a, ok := cset.activity[grainID]
+	if !ok {
+		a = &grainActivity{}
+		cset.activity[grainID] = a
+	}
+	a.last = time.Now()
+}
+
+// idle returns the running grains which have had no requests in flight, and
+// haven't been used, since before cutoff.
+func (cset *ContainerSet) idle(cutoff time.Time) []types.GrainID {
+	var ret []types.GrainID
+	for grainID := range cset.containersByGrainID {
+		a, ok := cset.activity[grainID]
+		if !ok || (a.inFlight == 0 && a.last.Before(cutoff)) {
+			ret = append(ret, grainID)
+		}
+	}
+	return ret
+}
+
+// beginGrainRequest records a request to the grain, which keeps it from being
+// shut down until the returned function is called.
+func (s *server) beginGrainRequest(grainID types.GrainID) (end func()) {
+	s.state.With(func(state *serverState) {
+		state.containers.touch(grainID)
+		state.containers.activity[grainID].inFlight++
+	})
+	return func() {
+		s.state.With(func(state *serverState) {
+			a := state.containers.activity[grainID]
+			a.inFlight--
+			a.last = time.Now()
+			if _, running := state.containers.containersByGrainID[grainID]; !running && a.inFlight == 0 {
+				delete(state.containers.activity, grainID)
+			}
+		})
+	}
+}
+
+// shutDownIdleGrains shuts down grains which have been idle for
+// cfg.GrainIdleTimeout, until ctx is canceled. It does nothing if the timeout
+// is zero.
+func (s *server) shutDownIdleGrains(ctx context.Context) {
+	timeout := s.cfg.GrainIdleTimeout
+	if timeout <= 0 {
+		return
+	}
+	interval := timeout / 2
+	if interval > maxIdleCheckInterval {
+		interval = maxIdleCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.shutDownGrainsIdleSince(now.Add(-timeout))
+		}
+	}
+}
+
+// shutDownGrainsIdleSince shuts down grains which haven't been used since
+// cutoff.
+func (s *server) shutDownGrainsIdleSince(cutoff time.Time) {
+	var (
+		idle  = map[types.GrainID]bool{}
+		stale []grainSession
+	)
+	s.state.With(func(state *serverState) {
+		for grainID, exited := range state.containers.stopping {
+			select {
+			case <-exited:
+				delete(state.containers.stopping, grainID)
+			default:
+			}
+		}
+		for _, grainID := range state.containers.idle(cutoff) {
+			state.containers.stop(grainID, state.containers.containersByGrainID[grainID])
+			idle[grainID] = true
+		}
+		for key, gs := range state.grainSessions {
+			if idle[key.grainID] {
+				stale = append(stale, gs)
+				delete(state.grainSessions, key)
+			}
+		}
+	})
+	// As in watchContainer, release sessions outside the lock.
+	for _, gs := range stale {
+		gs.Release()
+	}
+	for grainID := range idle {
+		s.log.Info("Shut down idle grain", "grainID", grainID)
+	}
+}
@@ -0,0 +1,86 @@
+package servermain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/container"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"zenhack.net/go/util/sync/mutex"
+)
+
+func newTestContainerSet() ContainerSet {
+	return ContainerSet{
+		containersByGrainID: make(map[types.GrainID]container.Container),
+		crashed:             make(map[types.GrainID]bool),
+		activity:            make(map[types.GrainID]*grainActivity),
+		stopping:            make(map[types.GrainID]<-chan struct{}),
+	}
+}
+
+func TestIdle(t *testing.T) {
+	now := time.Now()
+	cutoff := now.Add(-time.Minute)
+	cset := newTestContainerSet()
+	for _, grainID := range []types.GrainID{"recent", "old", "busy", "untracked"} {
+		cset.containersByGrainID[grainID] = container.Container{}
+	}
+	cset.activity["recent"] = &grainActivity{last: now}
+	cset.activity["old"] = &grainActivity{last: now.Add(-time.Hour)}
+	cset.activity["busy"] = &grainActivity{last: now.Add(-time.Hour), inFlight: 1}
+	// Activity of grains which aren't running doesn't make them idle.
+	cset.activity["stopped"] = &grainActivity{last: now.Add(-time.Hour)}
+
+	assert.ElementsMatch(t, []types.GrainID{"old", "untracked"}, cset.idle(cutoff))
+}
+
+func TestBeginGrainRequest(t *testing.T) {
+	s := &server{state: mutex.New(serverState{containers: newTestContainerSet()})}
+	s.state.With(func(state *serverState) {
+		state.containers.add("grain", container.Container{})
+	})
+	future := time.Now().Add(time.Hour)
+	idle := func() []types.GrainID {
+		return mutex.With1(&s.state, func(state *serverState) []types.GrainID {
+			return state.containers.idle(future)
+		})
+	}
+	require.Equal(t, []types.GrainID{"grain"}, idle())
+
+	endFirst := s.beginGrainRequest("grain")
+	endSecond := s.beginGrainRequest("grain")
+	assert.Empty(t, idle(), "grain with requests in flight is idle")
+	endFirst()
+	assert.Empty(t, idle(), "grain with a request in flight is idle")
+	endSecond()
+	assert.Equal(t, []types.GrainID{"grain"}, idle())
+
+	// Once the grain has been shut down, its activity is forgotten when
+	// its last request ends.
+	end := s.beginGrainRequest("grain")
+	s.state.With(func(state *serverState) {
+		state.containers.remove("grain", container.Container{})
+		assert.Contains(t, state.containers.activity, types.GrainID("grain"))
+	})
+	end()
+	s.state.With(func(state *serverState) {
+		assert.NotContains(t, state.containers.activity, types.GrainID("grain"))
+	})
+}
+
+func TestGetWaitsForExitingContainer(t *testing.T) {
+	cset := newTestContainerSet()
+	exited := make(chan struct{})
+	cset.stopping["grain"] = exited
+
+	// Get mustn't block, since its callers hold the server's lock.
+	_, started, exiting, err := cset.Get(context.Background(), nil, database.DB{}, "grain")
+	require.NoError(t, err)
+	assert.False(t, started)
+	assert.Equal(t, (<-chan struct{})(exited), exiting)
+	assert.Contains(t, cset.stopping, types.GrainID("grain"))
+}
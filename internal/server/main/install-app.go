@@ -2,7 +2,10 @@ package servermain
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 
@@ -11,7 +14,9 @@ import (
 	"sandstorm.org/go/tempest/capnp/util"
 	"sandstorm.org/go/tempest/internal/common/types"
 	"sandstorm.org/go/tempest/internal/config"
+	"sandstorm.org/go/tempest/internal/server/blobstore"
 	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/quota"
 	"sandstorm.org/go/tempest/pkg/exp/spk"
 	"sandstorm.org/go/tempest/pkg/exp/util/bytestream"
 	"zenhack.net/go/util/exn"
@@ -22,7 +27,10 @@ type installStream struct {
 	cancel      context.CancelFunc
 	userSession userSessionImpl
 
+	// ready is closed once installation is finished; then either err is
+	// set, or pkgID and pkg are.
 	ready chan struct{}
+	err   error
 	pkgID types.ID[external.Package]
 	pkg   external.Package
 }
@@ -49,42 +57,138 @@ func newInstallStream(userSession userSessionImpl) *installStream {
 
 func (s *installStream) install(ctx context.Context, r *io.PipeReader) {
 	err := exn.Try0(func(throw exn.Thrower) {
-		db := s.userSession.visitor.server.db
-		meta, err := spk.Unpack(config.TempDir, r)
+		// Packages aren't counted against anyone's quota, since they're
+		// shared, but users who are out of space can't upload more.
+		remaining, err := s.userSession.remainingStorage()
+		throw(err)
+		server := s.userSession.visitor.server
+		dbPkg, err := installSpk(ctx, server.db, server.blobs,
+			quota.LimitReader(r, remaining))
+		throw(quotaError(err))
+
+		pkg, err := external.NewPackage(dbPkg.Manifest.Segment())
 		throw(err)
+		throw(pkg.SetManifest(dbPkg.Manifest))
+
+		pkg.SetController(external.Package_Controller_ServerToClient(pkgController{
+			visitorSessionImpl: s.userSession.visitor,
+			pkg:                dbPkg,
+		}))
+		s.pkg = pkg
+		s.pkgID = types.ID[external.Package](dbPkg.ID)
+	})
+	if err != nil {
+		s.err = err
+		r.CloseWithError(err)
+		// TODO: delete temporary files & package directory.
+	}
+	close(s.ready)
+}
+
+// installSpk unpacks the spk read from r into the packages directory and
+// records it in the database. spk.Unpack rejects packages whose signature
+// doesn't verify, so the recorded app ID is the key which signed it. The spk
+// itself is kept in blobs, from which server.ensurePackageUnpacked can unpack
+// it again.
+func installSpk(ctx context.Context, db database.DB, blobs blobstore.Store, r io.Reader) (database.Package, error) {
+	return installSpkChecked(ctx, db, blobs, r, nil)
+}
+
+// installSpkChecked is like installSpk, but if check is not nil, it is called
+// with the unpacked package before installing it, and may reject it by
+// returning an error.
+func installSpkChecked(
+	ctx context.Context,
+	db database.DB,
+	blobs blobstore.Store,
+	r io.Reader,
+	check func(spk.ExtractedPackageMetadata) error,
+) (database.Package, error) {
+	return exn.Try(func(throw exn.Thrower) database.Package {
+		// The spk is read twice, to unpack and to store it, so it has
+		// to be stored temporarily first.
+		throw(os.MkdirAll(config.TempDir, 0700))
+		archive, err := os.CreateTemp(config.TempDir, "install-*.spk")
+		throw(err)
+		defer os.Remove(archive.Name())
+		defer archive.Close()
+		_, err = io.Copy(archive, r)
+		throw(err)
+		_, err = archive.Seek(0, io.SeekStart)
+		throw(err)
+		meta, err := spk.Unpack(config.TempDir, archive)
+		throw(err)
+		if check != nil {
+			if err := check(meta); err != nil {
+				os.RemoveAll(filepath.Dir(meta.Dir))
+				throw(err)
+			}
+		}
 		tx, err := db.Begin()
 		throw(err)
 		defer tx.Rollback()
 		dbPkg := database.Package{
 			ID:       types.ID[database.Package](meta.Hash.ID()),
 			Manifest: meta.Manifest,
+			AppID:    meta.AppID.String(),
 		}
 		throw(tx.AddPackage(dbPkg))
 		throw(tx.Commit())
-		throw(os.Rename(meta.Dir, filepath.Join(config.PackagesDir, string(dbPkg.ID))))
+		_, err = archive.Seek(0, io.SeekStart)
+		throw(err)
+		throw(blobs.Put(ctx, blobstore.PackageKey(string(dbPkg.ID)), archive))
+		throw(movePackageDir(meta.Dir, dbPkg.ID))
 		tx, err = db.Begin()
 		throw(err)
 		defer tx.Rollback()
 		throw(tx.ReadyPackage(dbPkg.ID))
 		throw(tx.Commit())
+		return dbPkg
+	})
+}
 
-		pkg, err := external.NewPackage(meta.Manifest.Segment())
-		throw(err)
-		throw(pkg.SetManifest(meta.Manifest))
+// movePackageDir moves a package unpacked to dir into the packages directory.
+func movePackageDir(dir string, pkgID types.ID[database.Package]) error {
+	pkgDir := filepath.Join(config.PackagesDir, string(pkgID))
+	err := os.Rename(dir, pkgDir)
+	if err == nil {
+		return nil
+	}
+	if _, statErr := os.Stat(pkgDir); statErr == nil {
+		// Already unpacked, by a server using a different database
+		// (e.g. `tempest demo`), or for another grain starting at the
+		// same time. Packages are named by their hash, so the contents
+		// are the same.
+		return os.RemoveAll(dir)
+	}
+	return err
+}
 
-		pkg.SetController(external.Package_Controller_ServerToClient(pkgController{
-			visitorSessionImpl: s.userSession.visitor,
-			pkg:                dbPkg,
-		}))
-		s.pkg = pkg
-		s.pkgID = types.ID[external.Package](meta.Hash.ID())
-		close(s.ready)
-	})
-	if err != nil {
-		r.CloseWithError(err)
-		// TODO: delete temporary files & package directory.
-		return
+// ensurePackageUnpacked unpacks the package with the given ID from its stored
+// spk, if it isn't in the packages directory, which may be the case if the
+// directory was lost, or the package was installed by another server sharing
+// the blob storage.
+func (s *server) ensurePackageUnpacked(ctx context.Context, pkgID string) error {
+	if _, err := os.Stat(filepath.Join(config.PackagesDir, pkgID)); err == nil {
+		return nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
 	}
+	return exn.Try0(func(throw exn.Thrower) {
+		r, err := s.blobs.Get(ctx, blobstore.PackageKey(pkgID))
+		if errors.Is(err, blobstore.ErrNotFound) {
+			throw(fmt.Errorf("package %s is not unpacked, and its spk isn't stored", pkgID))
+		}
+		throw(err)
+		defer r.Close()
+		meta, err := spk.Unpack(config.TempDir, r)
+		throw(err)
+		if meta.Hash.ID() != pkgID {
+			os.RemoveAll(filepath.Dir(meta.Dir))
+			throw(fmt.Errorf("stored spk for package %s has ID %s", pkgID, meta.Hash.ID()))
+		}
+		throw(movePackageDir(meta.Dir, types.ID[database.Package](pkgID)))
+	})
 }
 
 func (s *installStream) GetPackage(ctx context.Context, p external.Package_InstallStream_getPackage) error {
@@ -93,9 +197,12 @@ func (s *installStream) GetPackage(ctx context.Context, p external.Package_Insta
 	case <-ctx.Done():
 		return ctx.Err()
 	case <-s.ready:
-		return exn.Try0(func(throw exn.Thrower) {
-			results, err := p.AllocResults()
-			throw(err)
+		results, err := p.AllocResults()
+		if err != nil {
+			return err
+		}
+		return withAPIError(results, func(throw exn.Thrower) {
+			throw(s.err)
 			throw(results.SetId(string(s.pkgID)))
 			throw(results.SetPackage(s.pkg))
 		})
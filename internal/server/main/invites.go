@@ -0,0 +1,510 @@
+package servermain
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"capnproto.org/go/capnp/v3"
+	"github.com/gorilla/mux"
+	"sandstorm.org/go/tempest/capnp/external"
+	utilcp "sandstorm.org/go/tempest/capnp/util"
+	"sandstorm.org/go/tempest/internal/common/apierror"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/session"
+	"sandstorm.org/go/tempest/internal/server/tokenutil"
+	"zenhack.net/go/util/exn"
+)
+
+// Invites are links through which people can join the server. Following one
+// stores its token in a cookie, and the invite is redeemed when the user
+// next logs in; see logIn. A user who is already logged in redeems it
+// straight away. Managing invites needs write access to the users admin
+// scope, and only admins can make invites which grant the admin role.
+
+// inviteCookieName is the name of the cookie holding the token of an invite
+// to redeem on login.
+const inviteCookieName = "invite"
+
+// How long after following an invite link the user has to log in.
+const inviteCookieTTL = time.Hour
+
+// inviteURL returns the link through which the invite with the given token
+// is redeemed.
+func (s *server) inviteURL(token string) string {
+	u := url.URL{
+		Scheme: "http",
+		Host:   s.cfg.HTTP.RootDomain,
+		Path:   "/invite/" + token,
+	}
+	if s.cfg.HTTP.DefaultTLS {
+		u.Scheme = "https"
+	}
+	return u.String()
+}
+
+// createInvite records a new invite, made by the user with the given
+// credential, and returns it with its link. inv's ID, Created and CreatedBy
+// are filled in, and an empty Role means RoleUser.
+func (s *server) createInvite(by types.Credential, inv database.Invite) (database.Invite, string, error) {
+	if inv.Role == "" {
+		inv.Role = types.RoleUser
+	}
+	if !inv.Role.IsValid() {
+		return inv, "", apierror.New(apierror.CodeInvalidArgument,
+			"invalid role: "+string(inv.Role), "role")
+	}
+	if inv.MaxUses < 0 {
+		return inv, "", apierror.New(apierror.CodeInvalidArgument,
+			"maxUses must not be negative", "maxUses")
+	}
+	token := tokenutil.Gen128Base64()
+	err := exn.Try0(func(throw exn.Thrower) {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		scopes, err := tx.CredentialAdminScopes(by)
+		throw(err)
+		if !scopes.Allows(types.AdminScopeUsers, true) {
+			throw(apierror.New(apierror.CodePermissionDenied,
+				"you may not invite users"))
+		}
+		if inv.Role == types.RoleAdmin {
+			role, err := tx.CredentialRole(by)
+			throw(err)
+			if role != types.RoleAdmin {
+				throw(apierror.New(apierror.CodePermissionDenied,
+					"only admins may invite admins"))
+			}
+		}
+		inv.CreatedBy, err = tx.CredentialAccount(by)
+		throw(err)
+		inv.ID = tokenutil.Gen128Base64()
+		inv.Created = time.Now()
+		inv.Redemptions = nil
+		throw(tx.AddInvite(inv, []byte(token)))
+		throw(tx.Commit())
+	})
+	if err != nil {
+		return inv, "", err
+	}
+	s.log.Info("Invite created",
+		"inviteID", inv.ID,
+		"role", inv.Role,
+		"maxUses", inv.MaxUses,
+		"expires", inv.Expires,
+		"createdBy", by,
+	)
+	return inv, s.inviteURL(token), nil
+}
+
+// listInvites returns every invite, on behalf of the user with the given
+// credential.
+func (s *server) listInvites(by types.Credential) ([]database.Invite, error) {
+	return exn.Try(func(throw exn.Thrower) []database.Invite {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		scopes, err := tx.CredentialAdminScopes(by)
+		throw(err)
+		if !scopes.Allows(types.AdminScopeUsers, false) {
+			throw(apierror.New(apierror.CodePermissionDenied,
+				"you may not view invites"))
+		}
+		invites, err := tx.Invites()
+		throw(err)
+		return invites
+	})
+}
+
+// deleteInvite deletes an invite, on behalf of the user with the given
+// credential.
+func (s *server) deleteInvite(by types.Credential, id string) error {
+	err := exn.Try0(func(throw exn.Thrower) {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		scopes, err := tx.CredentialAdminScopes(by)
+		throw(err)
+		if !scopes.Allows(types.AdminScopeUsers, true) {
+			throw(apierror.New(apierror.CodePermissionDenied,
+				"you may not delete invites"))
+		}
+		err = tx.DeleteInvite(id)
+		if errors.Is(err, sql.ErrNoRows) {
+			throw(apierror.New(apierror.CodeNotFound,
+				"no such invite: "+id, "invite"))
+		}
+		throw(err)
+		throw(tx.Commit())
+	})
+	if err == nil {
+		s.log.Info("Invite deleted", "inviteID", id, "deletedBy", by)
+	}
+	return err
+}
+
+// redeemInvite redeems the invite with the given token for the account with
+// the credential, creating the account if need be. If the invite is unknown
+// or unusable, the error wraps sql.ErrNoRows.
+func (s *server) redeemInvite(cred types.Credential, token string) error {
+	inv, err := exn.Try(func(throw exn.Thrower) database.Invite {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		accountID, err := tx.CredentialAccount(cred)
+		throw(err)
+		inv, err := tx.RedeemInvite([]byte(token), accountID, time.Now())
+		throw(err)
+		throw(tx.Commit())
+		return inv
+	})
+	if err == nil {
+		s.log.Info("Invite redeemed",
+			"inviteID", inv.ID,
+			"role", inv.Role,
+			"credential", cred,
+		)
+	}
+	return err
+}
+
+// logIn starts a session for a user who has authenticated with the given
+// credential, redeeming any invite they followed first, and sends them to
+// the shell, or to the setup wizard if they claimed the setup token. The
+// login is recorded in the login audit log.
+func (s *server) logIn(w http.ResponseWriter, req *http.Request, cred types.Credential) {
+	s.loginSucceeded(req.Context(), clientIP(req), cred)
+	redirect := "/"
+	if c, err := req.Cookie(setupCookieName); err == nil {
+		claimed, err := s.claimSetup(req.Context(), cred, c.Value)
+		if err != nil {
+			s.log.ErrorCtx(req.Context(), "Claiming setup token on login", "error", err)
+		} else if claimed {
+			redirect = "/admin/setup"
+		}
+		http.SetCookie(w, s.setupCookie("", -1))
+	}
+	if c, err := req.Cookie(inviteCookieName); err == nil {
+		err = s.redeemInvite(cred, c.Value)
+		if errors.Is(err, sql.ErrNoRows) {
+			s.log.InfoCtx(req.Context(), "Ignoring unusable invite on login", "credential", cred)
+		} else if err != nil {
+			s.log.ErrorCtx(req.Context(), "Redeeming invite on login", "error", err)
+		}
+		http.SetCookie(w, s.inviteCookie("", -1))
+	}
+	sess := session.NewUserSession(cred, time.Now())
+	s.recordUserSession(req, sess)
+	if err := session.WriteCookie(s.sessionStore, req, w, sess); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Writing session cookie", "error", err)
+		return
+	}
+	http.Redirect(w, req, redirect, http.StatusSeeOther)
+}
+
+// inviteCookie returns the cookie holding an invite's token, which expires
+// after maxAge seconds.
+//
+// Unlike the session cookie, it must be sent along with logins which start on
+// other sites: following the link in a login email, or the identity
+// provider posting a SAML response. So it is SameSite=None where browsers
+// allow it, which is only over HTTPS, and Lax, which covers the former,
+// otherwise. Sending it cross-site is harmless, since it only affects the
+// account of whoever logs in with it.
+func (s *server) inviteCookie(token string, maxAge int) *http.Cookie {
+	c := &http.Cookie{
+		Name:     inviteCookieName,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if s.cfg.HTTP.DefaultTLS {
+		c.Secure = true
+		c.SameSite = http.SameSiteNoneMode
+	}
+	return c
+}
+
+// serveInvite handles a user following an invite link.
+func (s *server) serveInvite(w http.ResponseWriter, req *http.Request) {
+	token := mux.Vars(req)["token"]
+	usable, err := exn.Try(func(throw exn.Thrower) bool {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		inv, err := tx.InviteByToken([]byte(token))
+		if errors.Is(err, sql.ErrNoRows) {
+			return false
+		}
+		throw(err)
+		return inv.Usable(time.Now())
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Looking up invite", "error", err)
+		return
+	}
+	if !usable {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("This invite link is invalid, has expired, or has been used up.\n"))
+		return
+	}
+	var sess session.UserSession
+	if session.ReadCookie(s.sessionStore, req, &sess) == nil {
+		err := s.redeemInvite(sess.Credential, token)
+		if errors.Is(err, sql.ErrNoRows) {
+			// Used up since we checked.
+			w.WriteHeader(http.StatusNotFound)
+			return
+		} else if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			s.log.ErrorCtx(req.Context(), "Redeeming invite", "error", err)
+			return
+		}
+	} else {
+		http.SetCookie(w, s.inviteCookie(token, int(inviteCookieTTL.Seconds())))
+	}
+	http.Redirect(w, req, "/", http.StatusSeeOther)
+}
+
+func (s adminSessionImpl) CreateInvite(ctx context.Context, p external.AdminSession_createInvite) error {
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	return withAPIError(results, func(throw exn.Thrower) {
+		args := p.Args()
+		role, err := args.Role()
+		throw(err)
+		note, err := args.Note()
+		throw(err)
+		inv := database.Invite{
+			Role:    types.Role(role),
+			MaxUses: int(args.MaxUses()),
+			Note:    note,
+		}
+		if expires := args.Expires(); expires != 0 {
+			inv.Expires = time.Unix(0, expires)
+		}
+		inv, link, err := s.server.createInvite(s.userSession.Credential, inv)
+		throw(err)
+		throw(results.SetId(inv.ID))
+		throw(results.SetUrl(link))
+	})
+}
+
+func (s adminSessionImpl) ListInvites(ctx context.Context, p external.AdminSession_listInvites) error {
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	p.Go()
+	into := p.Args().Into()
+	return withAPIError(results, func(throw exn.Thrower) {
+		invites, err := s.server.listInvites(s.userSession.Credential)
+		throw(err)
+		now := time.Now()
+
+		throw(into.Clear(ctx, nil))
+		for _, inv := range invites {
+			throw(into.Upsert(ctx, func(p utilcp.KeyValue) error {
+				key, err := capnp.NewText(p.Segment(), inv.ID)
+				throw(err)
+				throw(p.SetKey(key.ToPtr()))
+				invite, err := external.NewInvite(p.Segment())
+				throw(err)
+				throw(invite.SetId(inv.ID))
+				throw(invite.SetRole(string(inv.Role)))
+				invite.SetMaxUses(uint32(inv.MaxUses))
+				if !inv.Expires.IsZero() {
+					invite.SetExpires(inv.Expires.UnixNano())
+				}
+				throw(invite.SetNote(inv.Note))
+				invite.SetCreated(inv.Created.UnixNano())
+				throw(invite.SetCreatedBy(string(inv.CreatedBy)))
+				redemptions, err := invite.NewRedemptions(int32(len(inv.Redemptions)))
+				throw(err)
+				for i, r := range inv.Redemptions {
+					throw(redemptions.At(i).SetAccountId(string(r.AccountID)))
+					redemptions.At(i).SetTime(r.Time.UnixNano())
+				}
+				invite.SetUsable(inv.Usable(now))
+				return p.SetValue(invite.ToPtr())
+			}))
+		}
+		fut, rel := into.Ready(ctx, nil)
+		defer rel()
+		throw(into.WaitStreaming())
+		_, err = fut.Struct()
+		throw(err)
+	})
+}
+
+func (s adminSessionImpl) DeleteInvite(ctx context.Context, p external.AdminSession_deleteInvite) error {
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	return withAPIError(results, func(throw exn.Thrower) {
+		id, err := p.Args().Id()
+		throw(err)
+		throw(s.server.deleteInvite(s.userSession.Credential, id))
+	})
+}
+
+var adminInvitesTemplate = parseAdminTemplate("admin-invites", template.FuncMap{
+	"usable": func(inv database.Invite) bool {
+		return inv.Usable(time.Now())
+	},
+}, `<!doctype html>
+<html>
+<head>
+<meta charset="utf-8" />
+<title>Invites</title>
+</head>
+<body>
+{{template "admin-nav" .Nav}}
+<h1>Invites</h1>
+{{if .NewURL}}
+<p>Send this link to the people you are inviting. It won't be shown again:</p>
+<p><code>{{.NewURL}}</code></p>
+{{end}}
+<p>People who log in after following an invite link get its role, unless they
+already have a higher one.</p>
+<table>
+	<tr>
+		<th>Note</th>
+		<th>Role</th>
+		<th>Uses</th>
+		<th>Created</th>
+		<th>Expires</th>
+		<th>Redeemed by</th>
+		<th>Status</th>
+		{{if .CanEdit}}<th></th>{{end}}
+	</tr>
+	{{range .Invites}}
+	<tr>
+		<td>{{.Note}}</td>
+		<td>{{.Role}}</td>
+		<td>{{len .Redemptions}}{{if .MaxUses}} of {{.MaxUses}}{{end}}</td>
+		<td>{{.Created.UTC.Format "2006-01-02 15:04"}}</td>
+		<td>{{if .Expires.IsZero}}never{{else}}{{.Expires.UTC.Format "2006-01-02 15:04"}}{{end}}</td>
+		<td>{{range $i, $r := .Redemptions}}{{if $i}}, {{end}}<code>{{$r.AccountID}}</code>{{end}}</td>
+		<td>{{if usable .}}usable{{else}}expired or used up{{end}}</td>
+		{{if $.CanEdit}}
+		<td>
+		<form method="POST" action="/admin/invites/{{.ID}}/delete">
+			<button type="submit">Delete</button>
+		</form>
+		</td>
+		{{end}}
+	</tr>
+	{{end}}
+</table>
+{{if .CanEdit}}
+<h2>New invite</h2>
+<form method="POST" action="/admin/invites">
+	<label>Note <input type="text" name="note" /></label>
+	<label>Role <select name="role">
+		<option value="user">user</option>
+		<option value="visitor">visitor</option>
+		<option value="admin">admin</option>
+	</select></label>
+	<label>Uses (0 for unlimited) <input type="number" name="maxUses" min="0" value="1" /></label>
+	<label>Valid for (days, 0 for ever) <input type="number" name="days" min="0" value="7" /></label>
+	<button type="submit">Create</button>
+</form>
+{{end}}
+</body>
+</html>
+`)
+
+func (s *server) serveAdminInvites(w http.ResponseWriter, req *http.Request) {
+	user, ok := s.requireAdminScope(w, req, types.AdminScopeUsers, false)
+	if !ok {
+		return
+	}
+	s.writeAdminInvites(w, user, "")
+}
+
+// writeAdminInvites writes the invites page, showing newURL if it's not
+// empty.
+func (s *server) writeAdminInvites(w http.ResponseWriter, user adminUser, newURL string) {
+	invites, err := s.listInvites(user.Credential)
+	if err != nil {
+		w.WriteHeader(apiErrorStatus(err))
+		s.log.Error("Listing invites", "error", err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	adminInvitesTemplate.Execute(w, struct {
+		Nav     []adminSection
+		Invites []database.Invite
+		NewURL  string
+		CanEdit bool
+	}{
+		Nav:     visibleAdminSections(user.Scopes),
+		Invites: invites,
+		NewURL:  newURL,
+		CanEdit: user.Scopes.Allows(types.AdminScopeUsers, true),
+	})
+}
+
+// serveCreateInvite creates an invite from the form, and shows its link.
+func (s *server) serveCreateInvite(w http.ResponseWriter, req *http.Request) {
+	user, ok := s.requireAdminScope(w, req, types.AdminScopeUsers, true)
+	if !ok {
+		return
+	}
+	maxUses, err := strconv.Atoi(strings.TrimSpace(req.PostFormValue("maxUses")))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	days, err := strconv.Atoi(strings.TrimSpace(req.PostFormValue("days")))
+	if err != nil || days < 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	inv := database.Invite{
+		Role:    types.Role(req.PostFormValue("role")),
+		MaxUses: maxUses,
+		Note:    req.PostFormValue("note"),
+	}
+	if days > 0 {
+		inv.Expires = time.Now().AddDate(0, 0, days)
+	}
+	_, link, err := s.createInvite(user.Credential, inv)
+	if err != nil {
+		s.writeAPIError(w, req, err, "Creating invite")
+		return
+	}
+	// Show the page directly, rather than redirecting, so the link isn't
+	// stored anywhere else.
+	w.Header().Set("Cache-Control", "no-store")
+	s.writeAdminInvites(w, user, link)
+}
+
+func (s *server) serveDeleteInvite(w http.ResponseWriter, req *http.Request) {
+	user, ok := s.requireAdminScope(w, req, types.AdminScopeUsers, true)
+	if !ok {
+		return
+	}
+	id := mux.Vars(req)["inviteID"]
+	if err := s.deleteInvite(user.Credential, id); err != nil {
+		s.writeAPIError(w, req, err, "Deleting invite", "inviteID", id)
+		return
+	}
+	http.Redirect(w, req, "/admin/invites", http.StatusSeeOther)
+}
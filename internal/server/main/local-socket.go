@@ -0,0 +1,97 @@
+package servermain
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+
+	"golang.org/x/sys/unix"
+	"sandstorm.org/go/tempest/internal/common/apierror"
+)
+
+// The server takes requests from command line tools, such as `tempest dev`
+// and `tempest admin`, over HTTP on unix sockets, which only local users can
+// reach, and which tell the server who they are.
+
+// peerKey is the context key for the *unix.Ucred of the process at the other
+// end of a connection to a local socket.
+type peerKey struct{}
+
+// listenLocal opens a local socket at path, which members of the group with
+// the given ID may connect to if mode allows. If gid is -1, the group is left
+// as it is.
+func listenLocal(path string, gid int, mode os.FileMode) (net.Listener, error) {
+	// Left by a server which didn't stop cleanly:
+	os.Remove(path)
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chown(path, -1, gid); err != nil {
+		l.Close()
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// serveLocal serves requests on l, a local socket, with h, until it fails.
+// Requests whose peer can't be identified have no peerCred.
+func serveLocal(l net.Listener, h http.Handler) error {
+	srv := &http.Server{
+		Handler: h,
+		ConnContext: func(ctx context.Context, c net.Conn) context.Context {
+			uc, ok := c.(*net.UnixConn)
+			if !ok {
+				return ctx
+			}
+			raw, err := uc.SyscallConn()
+			if err != nil {
+				return ctx
+			}
+			var cred *unix.Ucred
+			raw.Control(func(fd uintptr) {
+				cred, err = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+			})
+			if err != nil {
+				return ctx
+			}
+			return context.WithValue(ctx, peerKey{}, cred)
+		},
+	}
+	return srv.Serve(l)
+}
+
+// peerCred returns the credentials of the process which made a request to a
+// local socket, if they are known.
+func peerCred(req *http.Request) (*unix.Ucred, bool) {
+	cred, ok := req.Context().Value(peerKey{}).(*unix.Ucred)
+	return cred, ok
+}
+
+// localClient returns an http.Client which makes requests to the local socket
+// at path, whatever their URLs' hosts.
+func localClient(path string) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", path)
+			},
+		},
+	}
+}
+
+// writeLocalError writes an error response to a request to a local socket,
+// logging it with msg and the given attributes if it was unexpected.
+func (s *server) writeLocalError(w http.ResponseWriter, err error, msg string, args ...any) {
+	status := apiErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		s.log.Error(msg, append([]any{"error", err}, args...)...)
+	}
+	http.Error(w, apierror.FromError(err).Message, status)
+}
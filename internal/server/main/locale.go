@@ -0,0 +1,148 @@
+package servermain
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"html/template"
+	"io"
+	"net/http"
+	"strings"
+
+	"sandstorm.org/go/tempest/internal/browser/intl"
+	"sandstorm.org/go/tempest/internal/common/apierror"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/session"
+)
+
+// The server writes what users read, i.e. emails, error pages and API errors,
+// in the same locale as the shell, using the shell's localizations; see
+// internal/browser/intl.
+
+// localeCookieName is the cookie on the main host which holds the shell's
+// locale. The shell sets it when it starts.
+const localeCookieName = "tempest-locale"
+
+// localization returns the localization for the available locale which best
+// matches the preferred ones, most preferred first.
+func localization(preferred []string) intl.L10N {
+	return intl.Localizations[intl.Negotiate(
+		preferred, intl.AvailableLocales(), intl.DefaultLocale)]
+}
+
+// requestL10N returns the localization for the user making req. The shell's
+// locale comes first, then, if the user is logged in, the locale they last
+// used the shell in, e.g. on another device, then the browser's
+// Accept-Language.
+func (s *server) requestL10N(req *http.Request) intl.L10N {
+	var preferred []string
+	if cookie, err := req.Cookie(localeCookieName); err == nil && isLanguageTag(cookie.Value) {
+		preferred = append(preferred, cookie.Value)
+	}
+	var sess session.UserSession
+	if session.ReadCookie(s.sessionStore, req, &sess) == nil {
+		if locale, err := s.accountLocale(sess.Credential); err == nil && locale != "" {
+			preferred = append(preferred, locale)
+		}
+	}
+	preferred = append(preferred, parseAcceptLanguage(req.Header.Get("Accept-Language"))...)
+	return localization(preferred)
+}
+
+// accountLocale returns the locale the user with the credential last used the
+// shell in, or "" if it isn't known.
+func (s *server) accountLocale(cred types.Credential) (string, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+	accountID, err := tx.CredentialAccount(cred)
+	if err != nil {
+		return "", err
+	}
+	return tx.AccountLocale(accountID)
+}
+
+// rememberLocale records the locale of the shell which sent req, if the user
+// is logged in, for when the server writes to them from elsewhere.
+func (s *server) rememberLocale(ctx context.Context, req *http.Request, sess session.UserSession) {
+	cookie, err := req.Cookie(localeCookieName)
+	if err != nil || !isLanguageTag(cookie.Value) || sess.Credential.Type == "" {
+		return
+	}
+	err = func() error {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		accountID, err := tx.CredentialAccount(sess.Credential)
+		if err != nil {
+			return err
+		}
+		locale, err := tx.AccountLocale(accountID)
+		if err != nil || locale == cookie.Value {
+			return err
+		}
+		if err = tx.SetAccountLocale(accountID, cookie.Value); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}()
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		s.log.ErrorCtx(ctx, "Recording the shell's locale", "error", err)
+	}
+}
+
+// errorPageTemplate is the page showing an error to users who navigated to
+// a URL, rather than to scripts. The "t" function localizes its format
+// string; see intl.L10N.Fmt.
+var errorPageTemplate = template.Must(template.New("error-page").
+	Funcs(template.FuncMap{"t": intl.L10N{}.Fmt}).
+	Parse(`<!doctype html>
+<html>
+<head>
+<meta charset="utf-8" />
+<title>{{t "Tempest: error"}}</title>
+<link rel="stylesheet" href="/style.css">
+</head>
+<body class="error-page">
+<p>{{.Message}}</p>
+<p><a href="/">{{t "Back to Tempest"}}</a></p>
+</body>
+</html>
+`))
+
+// writeLocalizedError writes err's message for users, in their locale, with
+// the given status: as a page if the browser navigated to the URL, and as
+// text otherwise.
+func (s *server) writeLocalizedError(w http.ResponseWriter, req *http.Request, status int, err *apierror.Error) {
+	l10n := s.requestL10N(req)
+	message := err.Localize(l10n)
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if !strings.Contains(req.Header.Get("Accept"), "text/html") {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(status)
+		w.Write([]byte(message + "\n"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	executeLocalized(w, errorPageTemplate, l10n, struct {
+		Message string
+	}{
+		Message: message,
+	})
+}
+
+// executeLocalized executes tmpl, whose "t" function localizes its format
+// string, for l10n's locale.
+func executeLocalized(w io.Writer, tmpl *template.Template, l10n intl.L10N, data any) error {
+	tmpl, err := tmpl.Clone()
+	if err != nil {
+		return err
+	}
+	return tmpl.Funcs(template.FuncMap{"t": l10n.Fmt}).Execute(w, data)
+}
@@ -0,0 +1,85 @@
+package servermain
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"sandstorm.org/go/tempest/internal/browser/intl"
+	"sandstorm.org/go/tempest/internal/common/apierror"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/mailer"
+)
+
+// How long email login tokens are valid for.
+const emailLoginTokenTTL = 10 * time.Minute
+
+// loginEmailTemplate is the body of the email carrying a login token. The
+// "t" function localizes its format string; see intl.L10N.Fmt.
+var loginEmailTemplate = template.Must(template.New("login-email").
+	Funcs(template.FuncMap{"t": intl.L10N{}.Fmt}).
+	Parse(`{{t "Log in to Tempest as %0 by visiting:" .Address}}
+
+{{.Link}}
+
+{{t "Or enter this token at the login prompt:"}}
+
+{{.Token}}
+
+{{t "The link and token expire in %0 minutes. If you didn't try to log in, you can ignore this email." .Minutes}}
+`))
+
+// sendLoginEmail emails a login token to addr, in the available locale
+// closest to the given one, or if none is given, to the one the address's
+// account last used the shell in.
+func (s *server) sendLoginEmail(ctx context.Context, addr, locale, token string) error {
+	link := url.URL{
+		Scheme: "http",
+		Host:   s.cfg.HTTP.RootDomain,
+		Path:   "/login/email/" + token,
+	}
+	if s.cfg.HTTP.DefaultTLS {
+		link.Scheme = "https"
+	}
+	preferred := []string{locale}
+	cred := types.Credential{Type: types.EmailCredential, ScopedID: addr}
+	if accountLocale, err := s.accountLocale(cred); err == nil {
+		preferred = append(preferred, accountLocale)
+	}
+	l10n := localization(preferred)
+	tmpl, err := loginEmailTemplate.Clone()
+	if err != nil {
+		return err
+	}
+	var body strings.Builder
+	err = tmpl.Funcs(template.FuncMap{"t": l10n.Fmt}).Execute(&body, map[string]string{
+		"Address": addr,
+		"Link":    link.String(),
+		"Token":   token,
+		"Minutes": strconv.Itoa(int(emailLoginTokenTTL.Minutes())),
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	err = s.loginMailer.Send(ctx, mailer.Message{
+		To:      addr,
+		Subject: l10n.Fmt("Your Tempest login token"),
+		Body:    body.String(),
+	})
+	if errors.Is(err, mailer.ErrRateLimited) {
+		return apierror.New(apierror.CodeRateLimited,
+			"too many login tokens sent to "+addr).WithRetry()
+	} else if err != nil {
+		s.log.ErrorCtx(ctx, "Sending login email", "error", err)
+		return apierror.New(apierror.CodeUnavailable,
+			"sending login email: "+err.Error(), "mail").WithRetry()
+	}
+	return nil
+}
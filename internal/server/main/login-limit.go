@@ -0,0 +1,190 @@
+package servermain
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/exp/slog"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/loginlimit"
+	"zenhack.net/go/util/exn"
+)
+
+// How many entries of the login audit log the admin UI shows.
+const loginAuditPageSize = 500
+
+// clientIP returns the IP address of the client making req. Behind a reverse
+// proxy, this is the proxy's address, unless it is trusted; see
+// forwarded.Handler.
+func clientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// loginKeys returns the keys by which s.loginLimit tracks login attempts from
+// ip with cred. cred may be the zero value, if it isn't known yet.
+func loginKeys(ip string, cred types.Credential) []string {
+	keys := []string{"ip:" + ip}
+	if cred.Type != "" {
+		keys = append(keys, string(cred.Type)+":"+cred.ScopedID)
+	}
+	return keys
+}
+
+// allowLogin records a login attempt by keys, if s.loginLimit allows it.
+// Otherwise, it writes an error response and returns false.
+func (s *server) allowLogin(w http.ResponseWriter, req *http.Request, keys ...string) bool {
+	err := s.loginLimit.Allow(keys...)
+	if err == nil {
+		return true
+	}
+	s.log.WarnCtx(req.Context(), "Login attempt denied", "error", err)
+	var denied *loginlimit.Denied
+	if errors.As(err, &denied) {
+		w.Header().Set("Retry-After", retryAfterSeconds(denied.RetryAfter))
+	}
+	w.WriteHeader(http.StatusTooManyRequests)
+	w.Write([]byte("Too many login attempts; try again later."))
+	return false
+}
+
+// retryAfterSeconds formats d for a Retry-After header.
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// loginFailed records a failed login attempt from ip with cred, which may be
+// the zero value if it isn't known, locking them out if they have failed too
+// often.
+func (s *server) loginFailed(ctx context.Context, ip string, cred types.Credential, detail string) {
+	s.auditLogin(ctx, database.LoginAuditEntry{
+		Event:      database.LoginFailed,
+		RemoteAddr: ip,
+		Credential: cred,
+		Detail:     detail,
+	})
+	for _, key := range s.loginLimit.Failed(loginKeys(ip, cred)...) {
+		s.auditLogin(ctx, database.LoginAuditEntry{
+			Event:      database.LoginLockedOut,
+			RemoteAddr: ip,
+			Credential: cred,
+			Detail:     key,
+		})
+	}
+}
+
+// loginSucceeded records a successful login from ip with cred, which forgets
+// earlier failures.
+func (s *server) loginSucceeded(ctx context.Context, ip string, cred types.Credential) {
+	s.loginLimit.Succeeded(loginKeys(ip, cred)...)
+	s.auditLogin(ctx, database.LoginAuditEntry{
+		Event:      database.LoginSucceeded,
+		RemoteAddr: ip,
+		Credential: cred,
+	})
+}
+
+// auditLogin records e, as of now, in the login audit log, and in the server
+// log. Errors are logged, rather than returned, since they shouldn't stop the
+// login.
+func (s *server) auditLogin(ctx context.Context, e database.LoginAuditEntry) {
+	e.Time = time.Now()
+	level := slog.LevelInfo
+	if e.Event != database.LoginSucceeded {
+		level = slog.LevelWarn
+	}
+	s.log.Log(ctx, level, "Login audit",
+		"event", e.Event,
+		"remoteAddr", e.RemoteAddr,
+		"credential", e.Credential,
+		"detail", e.Detail,
+	)
+	err := exn.Try0(func(throw exn.Thrower) {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		throw(tx.AddLoginAuditEntry(e))
+		throw(tx.Commit())
+	})
+	if err != nil {
+		s.log.ErrorCtx(ctx, "Recording login audit entry", "error", err)
+	}
+}
+
+var loginAuditTemplate = parseAdminTemplate("login-audit", nil, `<!doctype html>
+<html>
+<head>
+<meta charset="utf-8" />
+<title>Login audit log</title>
+</head>
+<body>
+{{template "admin-nav" .Nav}}
+<h1>Login audit log</h1>
+<p>Logins, failed login attempts, and clients locked out after too many
+failures. The most recent {{.Shown}} of up to {{.Max}} entries are shown.</p>
+{{if .Entries}}
+<table>
+	<tr>
+		<th>Time</th>
+		<th>Event</th>
+		<th>Address</th>
+		<th>Credential</th>
+		<th>Detail</th>
+	</tr>
+	{{range .Entries}}
+	<tr>
+		<td>{{.Time.UTC.Format "2006-01-02 15:04:05"}}</td>
+		<td>{{.Event}}</td>
+		<td>{{.RemoteAddr}}</td>
+		<td>{{if .Credential.Type}}{{.Credential.Type}}: {{.Credential.ScopedID}}{{end}}</td>
+		<td>{{.Detail}}</td>
+	</tr>
+	{{end}}
+</table>
+{{else}}
+<p>There have been no login attempts.</p>
+{{end}}
+</body>
+</html>
+`)
+
+func (s *server) serveLoginAudit(w http.ResponseWriter, req *http.Request) {
+	user, ok := s.requireAdminScope(w, req, types.AdminScopeUsers, false)
+	if !ok {
+		return
+	}
+	entries, err := exn.Try(func(throw exn.Thrower) []database.LoginAuditEntry {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		entries, err := tx.LoginAuditEntries(loginAuditPageSize)
+		throw(err)
+		return entries
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Fetching login audit log", "error", err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	loginAuditTemplate.Execute(w, struct {
+		Nav     []adminSection
+		Entries []database.LoginAuditEntry
+		Shown   int
+		Max     int
+	}{
+		Nav:     visibleAdminSections(user.Scopes),
+		Entries: entries,
+		Shown:   loginAuditPageSize,
+		Max:     database.MaxLoginAuditEntries,
+	})
+}
@@ -0,0 +1,284 @@
+package servermain
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	"sandstorm.org/go/tempest/capnp/external"
+	"sandstorm.org/go/tempest/internal/common/apierror"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/session"
+	"zenhack.net/go/util/exn"
+)
+
+// Login sessions are kept in sealed cookies, which hold all of their state,
+// but each one is also recorded in the database when it starts, with the
+// browser it was started in, so that users can see where they are logged in
+// and log out sessions they no longer trust, or all of them at once; admins
+// can do the latter too, with tempest admin. Logging a session out revokes
+// it, so its cookie stops working, and forgets it.
+//
+// Sessions also expire, SESSION_MAX_AGE after logging in, or once they have
+// gone SESSION_IDLE_TIMEOUT without use; cookies in use are renewed as
+// requests come in. Expired sessions aren't listed, but are left in the
+// database until their accounts log out everywhere.
+
+// maxUserAgentLen is the longest User-Agent recorded for a login session;
+// longer ones are cut short.
+const maxUserAgentLen = 256
+
+// loginSessionID returns the identifier by which a login session is shown to
+// its user. Session IDs only leave the server sealed in cookies, so this is a
+// hash of one rather than the ID itself.
+func loginSessionID(sessionID []byte) string {
+	sum := sha256.Sum256(sessionID)
+	return hex.EncodeToString(sum[:16])
+}
+
+// recordUserSession records that sess was started by req, so that it can be
+// listed and revoked. Errors are logged, rather than returned, since they
+// shouldn't stop the login.
+func (s *server) recordUserSession(req *http.Request, sess session.UserSession) {
+	userAgent := req.UserAgent()
+	if len(userAgent) > maxUserAgentLen {
+		userAgent = userAgent[:maxUserAgentLen]
+	}
+	err := exn.Try0(func(throw exn.Thrower) {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		throw(tx.AddUserSession(database.UserSessionInfo{
+			SessionID:  sess.SessionID,
+			Credential: sess.Credential,
+			UserAgent:  userAgent,
+			RemoteAddr: clientIP(req),
+			Started:    time.Now(),
+		}))
+		throw(tx.Commit())
+	})
+	if err != nil {
+		s.log.ErrorCtx(req.Context(), "Recording login session", "error", err)
+	}
+}
+
+// touchUserSession records that sess was used for req, a request to load the
+// shell.
+func (s *server) touchUserSession(req *http.Request, sess session.UserSession) {
+	if len(sess.SessionID) == 0 {
+		return
+	}
+	err := exn.Try0(func(throw exn.Thrower) {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		throw(tx.TouchUserSession(sess.SessionID, clientIP(req), time.Now()))
+		throw(tx.Commit())
+	})
+	if err != nil {
+		s.log.ErrorCtx(req.Context(), "Recording use of login session", "error", err)
+	}
+}
+
+// renewUserSessions wraps h, renewing the session cookies sent with requests
+// to the shell's domain when they are due, so that sessions in use don't
+// reach SESSION_IDLE_TIMEOUT.
+func (s *server) renewUserSessions(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Host == s.cfg.HTTP.RootDomain {
+			s.renewUserSession(w, req)
+		}
+		h.ServeHTTP(w, req)
+	})
+}
+
+// renewUserSession issues the request's session cookie again, if it is due;
+// see session.UserSession.NeedsRenewal.
+func (s *server) renewUserSession(w http.ResponseWriter, req *http.Request) {
+	var sess session.UserSession
+	c, err := req.Cookie(sess.CookieName())
+	if err != nil {
+		return
+	}
+	// Most requests aren't due, so check that before looking for the
+	// session in the backend:
+	err = sess.Unseal(s.sessionStore, session.Payload{CookieName: c.Name, Data: c.Value})
+	now := time.Now()
+	if err != nil || !sess.NeedsRenewal(s.sessionStore, now) {
+		return
+	}
+	if err := s.sessionStore.Check(&sess); err != nil {
+		return
+	}
+	if err := session.WriteCookie(s.sessionStore, req, w, sess.Renew(now)); err != nil {
+		s.log.ErrorCtx(req.Context(), "Renewing session cookie", "error", err)
+	}
+}
+
+// forgetUserSession forgets the session with the given ID, once it has been
+// revoked.
+func (s *server) forgetUserSession(ctx context.Context, sessionID []byte) {
+	err := exn.Try0(func(throw exn.Thrower) {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		throw(tx.RemoveUserSession(sessionID))
+		throw(tx.Commit())
+	})
+	if err != nil {
+		s.log.ErrorCtx(ctx, "Forgetting revoked login session", "error", err)
+	}
+}
+
+// revokeUserSession revokes the session with the given ID, so that its
+// cookies stop working, and forgets it.
+func (s *server) revokeUserSession(ctx context.Context, sessionID []byte) error {
+	if err := s.sessionStore.Revoke(sessionID); err != nil {
+		return err
+	}
+	s.forgetUserSession(ctx, sessionID)
+	return nil
+}
+
+// revokeAccountSessions revokes all of the account's login sessions, logging
+// it out everywhere, and returns how many there were.
+func (s *server) revokeAccountSessions(accountID types.AccountID) (int, error) {
+	sessionIDs, err := exn.Try(func(throw exn.Thrower) [][]byte {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		_, err = tx.AccountRole(accountID)
+		if errors.Is(err, sql.ErrNoRows) {
+			throw(apierror.New(apierror.CodeNotFound,
+				"no such account: "+string(accountID), "account"))
+		}
+		throw(err)
+		ids, err := tx.AccountUserSessions(accountID)
+		throw(err)
+		return ids
+	})
+	if err != nil {
+		return 0, err
+	}
+	for i, id := range sessionIDs {
+		if err := s.revokeUserSession(context.Background(), id); err != nil {
+			return i, err
+		}
+	}
+	s.log.Info("Account's sessions revoked",
+		"accountID", accountID,
+		"sessions", len(sessionIDs),
+	)
+	return len(sessionIDs), nil
+}
+
+// accountLoginSessions returns the login sessions of the account of the user
+// with the given credential which haven't expired, most recently used first.
+func (s *server) accountLoginSessions(cred types.Credential) (types.AccountID, []database.UserSessionInfo, error) {
+	var accountID types.AccountID
+	infos, err := exn.Try(func(throw exn.Thrower) []database.UserSessionInfo {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		accountID, err = tx.CredentialAccount(cred)
+		throw(err)
+		infos, err := tx.AccountUserSessionInfos(accountID)
+		throw(err)
+		return infos
+	})
+	if err != nil {
+		return accountID, nil, err
+	}
+	var (
+		lifetimes = s.cfg.Session.Lifetimes
+		now       = time.Now()
+		live      []database.UserSessionInfo
+	)
+	for _, info := range infos {
+		// Cookies are renewed whenever the shell is loaded, which is
+		// also when LastSeen is updated, so this is close enough:
+		if lifetimes.MaxAge > 0 && now.Sub(info.Started) > lifetimes.MaxAge ||
+			lifetimes.IdleTimeout > 0 && now.Sub(info.LastSeen) > lifetimes.IdleTimeout {
+			continue
+		}
+		live = append(live, info)
+	}
+	return accountID, live, nil
+}
+
+// revokeLoginSession logs out the login session of the user with the given
+// credential which is shown to them with the given ID; see loginSessionID.
+func (s *server) revokeLoginSession(ctx context.Context, by types.Credential, id string) error {
+	accountID, infos, err := s.accountLoginSessions(by)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		if loginSessionID(info.SessionID) != id {
+			continue
+		}
+		if err := s.revokeUserSession(ctx, info.SessionID); err != nil {
+			return err
+		}
+		s.log.InfoCtx(ctx, "Login session revoked by its user", "accountID", accountID)
+		return nil
+	}
+	return apierror.New(apierror.CodeNotFound, "no such login session", "login session")
+}
+
+func (s userSessionImpl) LoginSessions(ctx context.Context, p external.UserSession_loginSessions) error {
+	srv := s.visitor.server
+	current := s.visitor.userSession.SessionID
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	return withAPIError(results, func(throw exn.Thrower) {
+		_, infos, err := srv.accountLoginSessions(s.visitor.userSession.Credential)
+		throw(err)
+		list, err := results.NewSessions(int32(len(infos)))
+		throw(err)
+		for i, info := range infos {
+			item := list.At(i)
+			throw(item.SetId(loginSessionID(info.SessionID)))
+			throw(item.SetUserAgent(info.UserAgent))
+			throw(item.SetRemoteAddr(info.RemoteAddr))
+			item.SetStarted(info.Started.UnixNano())
+			item.SetLastSeen(info.LastSeen.UnixNano())
+			item.SetCurrent(bytes.Equal(info.SessionID, current))
+		}
+	})
+}
+
+func (s userSessionImpl) RevokeLoginSession(ctx context.Context, p external.UserSession_revokeLoginSession) error {
+	id, err := p.Args().Id()
+	if err != nil {
+		return err
+	}
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	return setAPIError(results,
+		s.visitor.server.revokeLoginSession(ctx, s.visitor.userSession.Credential, id))
+}
+
+func (s userSessionImpl) RevokeAllLoginSessions(ctx context.Context, p external.UserSession_revokeAllLoginSessions) error {
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	return withAPIError(results, func(throw exn.Thrower) {
+		accountID, err := s.accountID()
+		throw(err)
+		n, err := s.visitor.server.revokeAccountSessions(accountID)
+		throw(err)
+		results.SetCount(uint32(n))
+	})
+}
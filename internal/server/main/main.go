@@ -1,15 +1,22 @@
 package servermain
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 
+	"sandstorm.org/go/tempest/internal/config"
+	"sandstorm.org/go/tempest/internal/server/acme"
+	"sandstorm.org/go/tempest/internal/server/cgroup"
 	"sandstorm.org/go/tempest/internal/server/database"
 	"sandstorm.org/go/tempest/internal/server/logging"
+	"sandstorm.org/go/tempest/internal/server/replication"
 	"sandstorm.org/go/tempest/internal/server/session"
 	"sandstorm.org/go/tempest/internal/server/settings"
 	"zenhack.net/go/util"
@@ -19,12 +26,50 @@ func Main() {
 	initStorage()
 	lg := logging.NewLogger()
 	cfg := ConfigFromSettings(lg, settings.Environ)
+	lg = util.Must(logging.New(os.Stdout, cfg.LogFormat))
+	if cfg.Replication.IsStandby() {
+		if !replication.IsPromoted() {
+			runStandby(cfg, lg)
+			return
+		}
+		lg.Info("This server has been promoted; ignoring REPLICATION_PRIMARY_URL")
+	}
+	openDB := database.Open
+	if cfg.Replication.Secret != "" {
+		// Standbys copy the database's write-ahead log; see database.WAL.
+		openDB = database.OpenReplicated
+	}
+	db := util.Must(openDB())
+	// Settings saved by the setup wizard apply where the environment doesn't
+	// set them:
+	cfg = ConfigFromSettings(lg, settings.WithStored(util.Must(storedSettings(db))))
+	if cfg.Cgroups.Enabled() {
+		if err := cgroup.Setup(cfg.Cgroups.Root); err != nil {
+			logging.Panic(lg, "setting up GRAIN_CGROUP", "error", err)
+		}
+	}
 	httpAddr := ":" + cfg.HTTP.Port
 	httpsAddr := ":" + cfg.HTTP.TLSPort
-	db := util.Must(database.Open())
-	sessionStore := session.NewStore(util.Must(session.GetKeys()))
+	sessionBackend := util.Must(cfg.Session.NewBackend(db))
+	sessionStore := util.Must(session.NewBackendStore(sessionBackend)).
+		WithLifetimes(cfg.Session.Lifetimes)
 	srv := newServer(cfg, lg, db, sessionStore)
 	defer srv.Release()
+	srv.rollBackInterruptedUpgrades()
+	srv.removeServedDevPackages()
+	if err := srv.startSetup(); err != nil {
+		lg.Error("Checking for an admin account", "error", err)
+	}
+	go srv.jobs.Serve(context.Background())
+	go srv.quota.Serve(context.Background())
+	if wal := db.WAL(); wal != nil {
+		go wal.Serve(context.Background(), lg)
+	}
+	go srv.shutDownIdleGrains(context.Background())
+	srv.runMaintenance(context.Background())
+	if srv.sandboxes != nil {
+		go srv.sandboxes.Serve(context.Background())
+	}
 
 	if cfg.HTTP.KeyFile != "" {
 		fi, err := os.Lstat(cfg.HTTP.KeyFile)
@@ -45,6 +90,7 @@ func Main() {
 		"https-addr", httpsAddr,
 	)
 	httpSrv := &http.Server{Addr: httpAddr}
+	cfg.Requests.ApplyTo(httpSrv)
 	go monitorSignals(httpSrv)
 
 	// We can't just use util.Chkfatal for the below, becasue
@@ -56,7 +102,33 @@ func Main() {
 		}
 	}
 
-	if cfg.HTTP.CertFile != "" && cfg.HTTP.KeyFile != "" {
+	if cfg.Mail.ListenPort != "" {
+		smtpAddr := ":" + cfg.Mail.ListenPort
+		l, err := net.Listen("tcp", smtpAddr)
+		util.Chkfatal(err)
+		lg.Info("Accepting email",
+			"mail-domain", cfg.Mail.Domain,
+			"smtp-addr", smtpAddr,
+		)
+		go func() {
+			util.Chkfatal(srv.serveMail(l))
+		}()
+	}
+
+	// The RPC listener uses the same certificate as HTTPS, if any.
+	var rpcTLSConfig *tls.Config
+	if cfg.HTTP.ACME.Enabled() {
+		certs := acme.NewManager(lg, cfg.HTTP.ACME, acme.DirStore(config.ACMEDir))
+		go certs.Serve(context.Background())
+		httpSrv.TLSConfig = &tls.Config{GetCertificate: certs.GetCertificate}
+		rpcTLSConfig = &tls.Config{GetCertificate: certs.GetCertificate}
+		l, err := net.Listen("tcp", httpsAddr)
+		util.Chkfatal(err)
+		go func() {
+			// The certificate comes from TLSConfig, rather than files.
+			checkServerError(httpSrv.ServeTLS(l, "", ""))
+		}()
+	} else if cfg.HTTP.CertFile != "" && cfg.HTTP.KeyFile != "" {
 		l, err := net.Listen("tcp", httpsAddr)
 		util.Chkfatal(err)
 		go func() {
@@ -66,6 +138,46 @@ func Main() {
 				cfg.HTTP.KeyFile,
 			))
 		}()
+		if cfg.HTTP.RPCPort != "" {
+			cert, err := tls.LoadX509KeyPair(cfg.HTTP.CertFile, cfg.HTTP.KeyFile)
+			util.Chkfatal(err)
+			rpcTLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+		}
+	}
+
+	if cfg.HTTP.RPCPort != "" {
+		rpcAddr := ":" + cfg.HTTP.RPCPort
+		l, err := net.Listen("tcp", rpcAddr)
+		util.Chkfatal(err)
+		if rpcTLSConfig != nil {
+			l = tls.NewListener(l, rpcTLSConfig)
+		} else {
+			lg.Warn("Tempest has no HTTPS certificate, so RPC connections are unencrypted")
+		}
+		lg.Info("Accepting RPC connections",
+			"rpc-addr", rpcAddr,
+			"tls", rpcTLSConfig != nil,
+		)
+		go func() {
+			util.Chkfatal(srv.serveRPC(l))
+		}()
+	}
+
+	// Only root and the server's own user may use the admin socket.
+	adminListener, err := listenLocal(config.AdminSocket, -1, 0600)
+	util.Chkfatal(err)
+	go func() {
+		util.Chkfatal(srv.serveAdmin(adminListener))
+	}()
+	if cfg.DevGroupID != "" {
+		gid, err := strconv.Atoi(cfg.DevGroupID)
+		util.Chkfatal(err)
+		l, err := listenLocal(config.DevSocket, gid, 0660)
+		util.Chkfatal(err)
+		lg.Info("Accepting `tempest dev` connections", "dev-socket", config.DevSocket)
+		go func() {
+			util.Chkfatal(srv.serveDev(l))
+		}()
 	}
 	checkServerError(httpSrv.ListenAndServe())
 }
@@ -0,0 +1,277 @@
+package servermain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/config"
+	"zenhack.net/go/util/exn"
+)
+
+// The server does its housekeeping in maintenance tasks, each of which runs
+// in the background every so often:
+//
+//   - purge-trash deletes grains which have been in the trash for
+//     TRASH_RETENTION, as their owners could with "delete forever".
+//   - expire-logins deletes login tokens which have expired, and the
+//     records of login sessions which are older than SESSION_MAX_AGE, and
+//     so can no longer be used, whether or not they were revoked.
+//   - compact-logs deletes log entries, and the timings of grain starts,
+//     older than LOG_RETENTION.
+//   - expire-demo-accounts deletes demo accounts as they expire; see
+//     demo-accounts.go.
+//   - record-grain-storage saves the grains' storage usage, as measured by
+//     s.quota, in the database; see quota.go.
+//
+// Each task's runs are spread out by a random delay, so that tasks, and the
+// servers sharing a database, don't all do their work at once. How each
+// task's runs went is exported by serveMetrics. A task which fails is logged,
+// and tried again at its next run.
+
+// The longest a task waits for its first run after the server starts.
+const maxMaintenanceStartDelay = 5 * time.Minute
+
+// A maintenanceTask is a job run in the background every interval.
+type maintenanceTask struct {
+	name     string
+	interval time.Duration
+
+	// run does the task's work, as of now.
+	run func(ctx context.Context, now time.Time) error
+}
+
+// maintenanceStats records a task's runs since the server started.
+type maintenanceStats struct {
+	runs        int64
+	failures    int64
+	duration    time.Duration // Total time spent running.
+	lastRun     time.Time
+	lastSuccess time.Time
+}
+
+// maintenanceTasks returns the tasks the server runs, per its configuration.
+func (s *server) maintenanceTasks() []maintenanceTask {
+	tasks := []maintenanceTask{
+		{name: "expire-logins", interval: time.Hour, run: s.expireLogins},
+		{name: "expire-demo-accounts", interval: demoExpiryInterval, run: s.deleteExpiredDemoAccounts},
+		{name: "record-grain-storage", interval: grainStorageRecordInterval, run: s.recordGrainStorage},
+	}
+	if s.cfg.Maintenance.TrashRetention > 0 {
+		tasks = append(tasks, maintenanceTask{
+			name: "purge-trash", interval: time.Hour, run: s.purgeOldTrash,
+		})
+	}
+	if s.cfg.Maintenance.LogRetention > 0 {
+		tasks = append(tasks, maintenanceTask{
+			name: "compact-logs", interval: 24 * time.Hour, run: s.compactLogs,
+		})
+	}
+	return tasks
+}
+
+// runMaintenance runs the maintenance tasks until ctx is canceled.
+func (s *server) runMaintenance(ctx context.Context) {
+	for _, task := range s.maintenanceTasks() {
+		go s.runMaintenanceTask(ctx, task)
+	}
+}
+
+// runMaintenanceTask runs the task every interval, give or take a tenth,
+// until ctx is canceled.
+func (s *server) runMaintenanceTask(ctx context.Context, task maintenanceTask) {
+	delay := rand.N(min(task.interval, maxMaintenanceStartDelay))
+	for {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.runMaintenanceOnce(ctx, task)
+		delay = task.interval - task.interval/10 + rand.N(task.interval/5+1)
+	}
+}
+
+// runMaintenanceOnce runs the task, and records how it went.
+func (s *server) runMaintenanceOnce(ctx context.Context, task maintenanceTask) {
+	start := time.Now()
+	err := task.run(ctx, start)
+	took := time.Since(start)
+	s.state.With(func(state *serverState) {
+		st := state.maintenance[task.name]
+		st.runs++
+		st.duration += took
+		st.lastRun = start
+		if err != nil {
+			st.failures++
+		} else {
+			st.lastSuccess = start
+		}
+		state.maintenance[task.name] = st
+	})
+	if err != nil {
+		s.log.ErrorCtx(ctx, "Maintenance task failed",
+			"task", task.name,
+			"error", err,
+		)
+	} else {
+		s.log.DebugCtx(ctx, "Maintenance task done",
+			"task", task.name,
+			"duration", took,
+		)
+	}
+}
+
+// writeMaintenanceMetrics writes metrics about the runs of maintenance tasks,
+// in the Prometheus text exposition format.
+func (s *server) writeMaintenanceMetrics(w io.Writer) {
+	var (
+		names []string
+		stats map[string]maintenanceStats
+	)
+	s.state.With(func(state *serverState) {
+		stats = make(map[string]maintenanceStats, len(state.maintenance))
+		for name, st := range state.maintenance {
+			names = append(names, name)
+			stats[name] = st
+		}
+	})
+	slices.Sort(names)
+	fmt.Fprintln(w, "# HELP tempest_maintenance_runs_total Runs of each maintenance task since the server started.")
+	fmt.Fprintln(w, "# TYPE tempest_maintenance_runs_total counter")
+	for _, name := range names {
+		fmt.Fprintf(w, "tempest_maintenance_runs_total{task=%s} %d\n", labelValue(name), stats[name].runs)
+	}
+	fmt.Fprintln(w, "# HELP tempest_maintenance_failures_total Runs of each maintenance task which failed since the server started.")
+	fmt.Fprintln(w, "# TYPE tempest_maintenance_failures_total counter")
+	for _, name := range names {
+		fmt.Fprintf(w, "tempest_maintenance_failures_total{task=%s} %d\n", labelValue(name), stats[name].failures)
+	}
+	fmt.Fprintln(w, "# HELP tempest_maintenance_seconds Time spent running each maintenance task since the server started.")
+	fmt.Fprintln(w, "# TYPE tempest_maintenance_seconds summary")
+	for _, name := range names {
+		fmt.Fprintf(w, "tempest_maintenance_seconds_sum{task=%s} %g\n", labelValue(name), stats[name].duration.Seconds())
+		fmt.Fprintf(w, "tempest_maintenance_seconds_count{task=%s} %d\n", labelValue(name), stats[name].runs)
+	}
+	fmt.Fprintln(w, "# HELP tempest_maintenance_last_run_timestamp_seconds When each maintenance task last ran.")
+	fmt.Fprintln(w, "# TYPE tempest_maintenance_last_run_timestamp_seconds gauge")
+	for _, name := range names {
+		fmt.Fprintf(w, "tempest_maintenance_last_run_timestamp_seconds{task=%s} %d\n", labelValue(name), stats[name].lastRun.Unix())
+	}
+	fmt.Fprintln(w, "# HELP tempest_maintenance_last_success_timestamp_seconds When each maintenance task last succeeded, or 0 if it hasn't.")
+	fmt.Fprintln(w, "# TYPE tempest_maintenance_last_success_timestamp_seconds gauge")
+	for _, name := range names {
+		var at int64
+		if !stats[name].lastSuccess.IsZero() {
+			at = stats[name].lastSuccess.Unix()
+		}
+		fmt.Fprintf(w, "tempest_maintenance_last_success_timestamp_seconds{task=%s} %d\n", labelValue(name), at)
+	}
+}
+
+// purgeOldTrash deletes the grains which were moved to the trash longer than
+// TRASH_RETENTION before now.
+func (s *server) purgeOldTrash(ctx context.Context, now time.Time) error {
+	cutoff := now.Add(-s.cfg.Maintenance.TrashRetention)
+	grainIDs, err := exn.Try(func(throw exn.Thrower) []types.GrainID {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		ids, err := tx.GrainsTrashedBefore(cutoff)
+		throw(err)
+		return ids
+	})
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, grainID := range grainIDs {
+		if err := s.purgeTrashedBefore(ctx, grainID, cutoff); err != nil {
+			errs = append(errs, fmt.Errorf("purging grain %v: %w", grainID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// purgeTrashedBefore deletes the grain, if it is still in the trash, and was
+// moved there before cutoff.
+func (s *server) purgeTrashedBefore(ctx context.Context, grainID types.GrainID, cutoff time.Time) error {
+	// As in purgeGrain, wait for it to shut down outside of the
+	// transaction.
+	s.stopGrain(grainID)
+	purged, err := exn.Try(func(throw exn.Thrower) bool {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		info, err := tx.GrainInfo(grainID)
+		throw(err)
+		// It may have been restored in the meantime.
+		if info.Trashed.IsZero() || !info.Trashed.Before(cutoff) {
+			return false
+		}
+		throw(tx.DeleteGrain(grainID))
+		throw(tx.Commit())
+		return true
+	})
+	if err != nil || !purged {
+		return err
+	}
+	if err := os.RemoveAll(filepath.Join(config.GrainsDir, string(grainID))); err != nil {
+		return err
+	}
+	s.log.InfoCtx(ctx, "Purged grain from the trash",
+		"grainID", grainID,
+		"retention", s.cfg.Maintenance.TrashRetention,
+	)
+	return nil
+}
+
+// expireLogins deletes the login tokens which have expired by now, and the
+// records of login sessions which have reached SESSION_MAX_AGE.
+func (s *server) expireLogins(ctx context.Context, now time.Time) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		tokens, err := tx.DeleteExpiredLoginTokens(now)
+		throw(err)
+		var sessions int64
+		// Without a maximum age, any session might still be in use.
+		if maxAge := s.cfg.Session.Lifetimes.MaxAge; maxAge > 0 {
+			sessions, err = tx.DeleteSessionsStartedBefore(now.Add(-maxAge))
+			throw(err)
+		}
+		throw(tx.Commit())
+		if tokens > 0 || sessions > 0 {
+			s.log.InfoCtx(ctx, "Expired logins deleted",
+				"tokens", tokens,
+				"sessions", sessions,
+			)
+		}
+	})
+}
+
+// compactLogs deletes the log entries recorded longer than LOG_RETENTION
+// before now.
+func (s *server) compactLogs(ctx context.Context, now time.Time) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		n, err := tx.DeleteLogsBefore(now.Add(-s.cfg.Maintenance.LogRetention))
+		throw(err)
+		throw(tx.Commit())
+		if n > 0 {
+			s.log.InfoCtx(ctx, "Old log entries deleted", "entries", n)
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package servermain
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/database/legacy"
+	"zenhack.net/go/util"
+)
+
+// MigrateFromSandstorm implements `tempest migrate-from-sandstorm`, which
+// imports a Sandstorm server's accounts, grains, apps and sharing into
+// Tempest's database, and prints a report of anything it couldn't.
+func MigrateFromSandstorm(args []string) {
+	flags := flag.NewFlagSet("tempest migrate-from-sandstorm", flag.ExitOnError)
+	var opts legacy.MigrateOptions
+	flags.IntVar(&opts.MongoPort, "mongo-port", 6081, "port on which Sandstorm's mongo is listening")
+	flags.StringVar(&opts.PasswdFile, "passwd-file", "/opt/sandstorm/var/mongo/passwd",
+		"file storing the mongo user password")
+	flags.StringVar(&opts.SnapshotDir, "snapshot-dir", "./mongo-snapshot",
+		"directory in which to store a snapshot of the mongo database")
+	flags.StringVar(&opts.SandstormConf, "sandstorm-conf", "/opt/sandstorm/sandstorm.conf",
+		"path to Sandstorm's configuration file")
+	flags.StringVar(&opts.SqlitePath, "sqlite-path", database.DBPath, "path to Tempest's database")
+	reportPath := flags.String("report", "", "file to write the report to, in addition to standard output")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: tempest migrate-from-sandstorm [flags]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	initStorage()
+	report, err := legacy.Migrate(opts)
+	util.Chkfatal(err)
+	_, err = report.WriteTo(os.Stdout)
+	util.Chkfatal(err)
+	if *reportPath != "" {
+		f := util.Must(os.Create(*reportPath))
+		defer f.Close()
+		_, err = report.WriteTo(f)
+		util.Chkfatal(err)
+	}
+}
@@ -2,9 +2,11 @@ package servermain
 
 import (
 	"capnproto.org/go/capnp/v3/schemas"
+	"sandstorm.org/go/tempest/capnp/email"
 	websession "sandstorm.org/go/tempest/capnp/web-session"
 )
 
 func init() {
 	websession.RegisterSchema(schemas.DefaultRegistry)
+	email.RegisterSchema(schemas.DefaultRegistry)
 }
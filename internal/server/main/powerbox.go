@@ -0,0 +1,910 @@
+package servermain
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/exc"
+	cpserver "capnproto.org/go/capnp/v3/server"
+	"github.com/gorilla/mux"
+	apisession "sandstorm.org/go/tempest/capnp/api-session"
+	"sandstorm.org/go/tempest/capnp/grain"
+	"sandstorm.org/go/tempest/capnp/identity"
+	"sandstorm.org/go/tempest/capnp/ip"
+	"sandstorm.org/go/tempest/capnp/powerbox"
+	thumbnailer "sandstorm.org/go/tempest/capnp/thumbnail"
+	turnserver "sandstorm.org/go/tempest/capnp/turn"
+	"sandstorm.org/go/tempest/internal/common/apierror"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/container"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/session"
+	"sandstorm.org/go/tempest/internal/server/tokenutil"
+	"zenhack.net/go/util/exn"
+)
+
+// The powerbox lets a grain ask the user for a capability, without the grain
+// learning anything about what the user could have chosen. As in Sandstorm,
+// the grain makes the request from its client side, with the postMessage API
+// described at SessionContext.claimRequest in grain.capnp:
+//
+//  1. The browser sends the request to servePowerboxRequests, which records
+//     it and replies with the grains the user may choose from.
+//  2. The browser shows the user a picker, and sends their choice to
+//     servePowerboxRequest, which replies with a claim token.
+//  3. The browser passes the token to the grain, whose server side exchanges
+//     it for the capability with SessionContext.claimRequest.
+//  4. The grain may save the capability with SandstormApi.save, and restore
+//     it later with the resulting sturdyRef.
+//
+// The capabilities offered are the UiViews of the user's grains, the
+// identities of the users on this server, which apps use to tell users apart,
+// e.g. to give them their own permissions (see identity.go), HTTP APIs
+// outside of Tempest (see http-api.go), the thumbnail service (see
+// thumbnail.go), the TURN relay, if there is one (see turn.go), and, to
+// infrastructure admins, access to the network, which grains otherwise lack, and permission
+// to listen on it; see the egress package.
+
+const (
+	// powerboxRequestTTL is how long the user has to fulfill a request.
+	powerboxRequestTTL = time.Hour
+
+	// powerboxClaimTTL is how long the grain has to claim the capability
+	// once the user has chosen it.
+	powerboxClaimTTL = 5 * time.Minute
+
+	// The ownerType of the sturdyRefs behind claim tokens.
+	powerboxClaimOwnerType = "powerbox-claim"
+)
+
+var (
+	// errNetworkForbidden is returned when a user who may not grant access
+	// to the network tries to.
+	errNetworkForbidden = errors.New("only infrastructure admins may grant access to the network")
+
+	// errHTTPAPIForbidden is returned when a user who may not grant access
+	// to an HTTP API tries to, or the request didn't ask for one.
+	errHTTPAPIForbidden = errors.New("may not grant access to an HTTP API")
+
+	// errPrivateHTTPAPIForbidden is returned when a user who may not grant
+	// access to the network tries to grant access to an HTTP API on a private network.
+	errPrivateHTTPAPIForbidden = errors.New("only infrastructure admins may grant access to HTTP APIs on private networks")
+
+	// errThumbnailerForbidden is returned when a user who may not grant
+	// the thumbnail service tries to, or the request didn't ask for it.
+	errThumbnailerForbidden = errors.New("may not grant the thumbnail service")
+
+	// errTURNForbidden is returned when a user who may not grant the TURN
+	// relay tries to, or the request didn't ask for it.
+	errTURNForbidden = errors.New("may not grant the TURN relay")
+)
+
+// servePowerboxRequests records a powerbox request made by a grain which the
+// user has open, as a types.NewPowerboxRequest, and replies with a
+// types.PowerboxRequest.
+func (s *server) servePowerboxRequests(w http.ResponseWriter, req *http.Request) {
+	var sess session.UserSession
+	if err := session.ReadCookie(s.sessionStore, req, &sess); err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var want types.NewPowerboxRequest
+	err := json.NewDecoder(http.MaxBytesReader(w, req.Body, 64*1024)).Decode(&want)
+	if err != nil {
+		s.writeLocalizedError(w, req, http.StatusBadRequest, apierror.New(
+			apierror.CodeInvalidArgument, "malformed request: "+err.Error(), "request"))
+		return
+	}
+	wants, err := queryMatches(want.Query)
+	if err != nil {
+		s.writeLocalizedError(w, req, http.StatusBadRequest, apierror.New(
+			apierror.CodeInvalidArgument, "malformed powerbox query: "+err.Error(), "powerbox query"))
+		return
+	}
+	resp, err := exn.Try(func(throw exn.Thrower) types.PowerboxRequest {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		accountID, err := tx.CredentialAccount(sess.Credential)
+		throw(err)
+		_, err = accountGrainPermissions(tx, accountID, want.GrainID)
+		throw(err)
+
+		resp := types.PowerboxRequest{
+			ID:      tokenutil.Gen128Base64(),
+			Options: []types.PowerboxOption{},
+		}
+		if wants.uiView {
+			views, err := tx.AccountKeyring(accountID).AllUiViews()
+			throw(err)
+			for _, view := range views {
+				if view.Grain.ID == want.GrainID {
+					continue
+				}
+				resp.Options = append(resp.Options, types.PowerboxOption{
+					GrainID: view.Grain.ID,
+					Title:   view.Grain.Title,
+				})
+			}
+		}
+		if wants.identity {
+			contacts, err := tx.Contacts()
+			throw(err)
+			for _, c := range contacts {
+				resp.Options = append(resp.Options, types.PowerboxOption{
+					AccountID: c.AccountID,
+					Title:     c.DisplayName,
+					Picture:   s.identiconURL(c.AccountID),
+				})
+			}
+		}
+		if wants.network || wants.ipInterface {
+			ok, err := canGrantNetwork(tx, accountID)
+			throw(err)
+			if ok && wants.network {
+				resp.Options = append(resp.Options, types.PowerboxOption{Network: true})
+			}
+			if ok && wants.ipInterface {
+				resp.Options = append(resp.Options, types.PowerboxOption{IPInterface: true})
+			}
+		}
+		if wants.thumbnailer {
+			ok, err := canGrantThumbnailer(tx, accountID)
+			throw(err)
+			if ok {
+				resp.Options = append(resp.Options, types.PowerboxOption{Thumbnailer: true})
+			}
+		}
+		if wants.turn && s.cfg.TURN.Enabled() {
+			ok, err := canGrantTURN(tx, accountID)
+			throw(err)
+			if ok {
+				resp.Options = append(resp.Options, types.PowerboxOption{TURN: true})
+			}
+		}
+		if len(wants.httpAPIs) > 0 {
+			ok, err := canGrantHTTPAPI(tx, accountID)
+			throw(err)
+			if ok {
+				for _, u := range wants.httpAPIs {
+					resp.Options = append(resp.Options, types.PowerboxOption{HTTPAPI: u})
+				}
+			}
+		}
+		throw(tx.AddPowerboxRequest(database.PowerboxRequest{
+			ID:        resp.ID,
+			GrainID:   want.GrainID,
+			AccountID: accountID,
+			Query:     want.Query,
+			SaveLabel: want.SaveLabel,
+			Expires:   time.Now().Add(powerboxRequestTTL),
+		}))
+		throw(tx.Commit())
+		return resp
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// No such grain, or the user can't see it.
+		w.WriteHeader(http.StatusNotFound)
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Recording powerbox request",
+			"error", err,
+			"grainID", want.GrainID,
+		)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// servePowerboxRequest handles the user's answer to the powerbox request
+// named in the URL: POST fulfills it with the option in a
+// types.PowerboxChoice, replying with a types.PowerboxClaim, and DELETE
+// cancels it.
+func (s *server) servePowerboxRequest(w http.ResponseWriter, req *http.Request) {
+	var sess session.UserSession
+	if err := session.ReadCookie(s.sessionStore, req, &sess); err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var choice types.PowerboxChoice
+	if req.Method == http.MethodPost {
+		err := json.NewDecoder(http.MaxBytesReader(w, req.Body, 64*1024)).Decode(&choice)
+		if err != nil {
+			s.writeLocalizedError(w, req, http.StatusBadRequest, apierror.New(
+				apierror.CodeInvalidArgument, "malformed request: "+err.Error(), "request"))
+			return
+		}
+		if choice.HTTPAPI != "" {
+			choice.HTTPAPI, err = parseHTTPAPIURL(choice.HTTPAPI)
+			if err != nil {
+				s.writeLocalizedError(w, req, http.StatusBadRequest, apierror.New(
+					apierror.CodeInvalidArgument, err.Error(), "URL"))
+				return
+			}
+		}
+	}
+	requestID := mux.Vars(req)["requestID"]
+	claim, err := exn.Try(func(throw exn.Thrower) types.PowerboxClaim {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		accountID, err := tx.CredentialAccount(sess.Credential)
+		throw(err)
+		now := time.Now()
+		r, err := tx.PowerboxRequest(requestID)
+		throw(err)
+		if r.AccountID != accountID || !r.Expires.After(now) {
+			throw(sql.ErrNoRows)
+		}
+		throw(tx.DeletePowerboxRequest(requestID, now))
+
+		var claim types.PowerboxClaim
+		if req.Method == http.MethodPost {
+			grant := database.PowerboxGrant{
+				Note:      r.SaveLabel,
+				Expires:   now.Add(powerboxClaimTTL),
+				AccountID: accountID,
+			}
+			if choice.Network || choice.IPInterface {
+				ok, err := canGrantNetwork(tx, accountID)
+				throw(err)
+				if !ok {
+					throw(errNetworkForbidden)
+				}
+				grant.Network = choice.Network
+				grant.IPInterface = !choice.Network
+				s.log.InfoCtx(req.Context(), "Network access granted to grain",
+					"grainID", r.GrainID,
+					"accountID", accountID,
+					"listen", grant.IPInterface,
+				)
+			} else if choice.HTTPAPI != "" {
+				ok, err := canGrantHTTPAPI(tx, accountID)
+				throw(err)
+				wants, err := queryMatches(r.Query)
+				throw(err)
+				if !ok || len(wants.httpAPIs) == 0 {
+					throw(errHTTPAPIForbidden)
+				}
+				if isPrivateHTTPAPI(choice.HTTPAPI) {
+					admin, err := canGrantNetwork(tx, accountID)
+					throw(err)
+					if !admin {
+						throw(errPrivateHTTPAPIForbidden)
+					}
+				}
+				grant.HTTPAPI = choice.HTTPAPI
+				grant.HTTPAuthorization = choice.HTTPAuthorization
+				s.log.InfoCtx(req.Context(), "HTTP API granted to grain",
+					"grainID", r.GrainID,
+					"accountID", accountID,
+					"url", choice.HTTPAPI,
+				)
+			} else if choice.Thumbnailer {
+				ok, err := canGrantThumbnailer(tx, accountID)
+				throw(err)
+				wants, err := queryMatches(r.Query)
+				throw(err)
+				if !ok || !wants.thumbnailer {
+					throw(errThumbnailerForbidden)
+				}
+				grant.Thumbnailer = true
+			} else if choice.TURN {
+				ok, err := canGrantTURN(tx, accountID)
+				throw(err)
+				wants, err := queryMatches(r.Query)
+				throw(err)
+				if !ok || !wants.turn || !s.cfg.TURN.Enabled() {
+					throw(errTURNForbidden)
+				}
+				grant.TURN = true
+			} else if choice.AccountID != "" {
+				// The user must exist.
+				_, err := tx.AccountContact(choice.AccountID)
+				throw(err)
+				grant.Identity = choice.AccountID
+			} else {
+				grant.GrainID = choice.GrainID
+				grant.Permissions, err = accountGrainPermissions(tx, accountID, choice.GrainID)
+				throw(err)
+			}
+			claim.Token = tokenutil.Gen128Base64()
+			throw(tx.SavePowerboxGrant(
+				database.SturdyRefKey{
+					Token:     []byte(claim.Token),
+					OwnerType: powerboxClaimOwnerType,
+					Owner:     types.AccountID(r.GrainID),
+				},
+				grant,
+			))
+		}
+		throw(tx.Commit())
+		return claim
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	} else if errors.Is(err, errNetworkForbidden) || errors.Is(err, errHTTPAPIForbidden) ||
+		errors.Is(err, errPrivateHTTPAPIForbidden) || errors.Is(err, errThumbnailerForbidden) ||
+		errors.Is(err, errTURNForbidden) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Answering powerbox request",
+			"error", err,
+			"requestID", requestID,
+		)
+		return
+	}
+	if req.Method == http.MethodDelete {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(claim)
+}
+
+// powerboxQuery is what a powerbox query asks for; see queryMatches.
+type powerboxQuery struct {
+	uiView      bool     // A grain's UiView.
+	network     bool     // Access to the network.
+	ipInterface bool     // Permission to listen on the network.
+	thumbnailer bool     // The thumbnail service.
+	turn        bool     // The TURN relay.
+	identity    bool     // A user's identity.
+	httpAPIs    []string // The canonical URLs of HTTP APIs.
+}
+
+// queryMatches returns what a powerbox query, as passed to the postMessage
+// API, asks for. A query asks for a UiView if it has a descriptor whose tags
+// are all UiView tags; a descriptor with no tags matches anything. It asks
+// for the network only if it has a descriptor whose tags are all IpNetwork
+// tags, since a grain should never be offered the network unless it asks for
+// it explicitly, and likewise for IpInterfaces, Thumbnailers, TurnServers,
+// identities and HTTP APIs, whose ApiSession tags must name a canonicalUrl.
+func queryMatches(query []string) (powerboxQuery, error) {
+	return exn.Try(func(throw exn.Thrower) powerboxQuery {
+		var ret powerboxQuery
+		for _, q := range query {
+			buf, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(q, "="))
+			throw(err)
+			msg, err := capnp.UnmarshalPacked(buf)
+			throw(err)
+			desc, err := powerbox.ReadRootPowerboxDescriptor(msg)
+			throw(err)
+			tags, err := desc.Tags()
+			throw(err)
+			allViews := true
+			allNetworks, allInterfaces, allIdentities := tags.Len() > 0, tags.Len() > 0, tags.Len() > 0
+			allThumbnailers, allTURNServers := tags.Len() > 0, tags.Len() > 0
+			var urls []string
+			for i := 0; i < tags.Len(); i++ {
+				id := tags.At(i).Id()
+				allViews = allViews && id == grain.UiView_TypeID
+				allNetworks = allNetworks && id == ip.IpNetwork_TypeID
+				allInterfaces = allInterfaces && id == ip.IpInterface_TypeID
+				allThumbnailers = allThumbnailers && id == thumbnailer.Thumbnailer_TypeID
+				allTURNServers = allTURNServers && id == turnserver.TurnServer_TypeID
+				allIdentities = allIdentities && id == identity.Identity_TypeID
+				if id != apisession.ApiSession_TypeID {
+					continue
+				}
+				value, err := tags.At(i).Value()
+				throw(err)
+				u, err := apisession.ApiSession_PowerboxTag(value.Struct()).CanonicalUrl()
+				throw(err)
+				if u, err = parseHTTPAPIURL(u); err == nil {
+					urls = append(urls, u)
+				}
+			}
+			ret.uiView = ret.uiView || allViews
+			ret.network = ret.network || allNetworks
+			ret.ipInterface = ret.ipInterface || allInterfaces
+			ret.thumbnailer = ret.thumbnailer || allThumbnailers
+			ret.turn = ret.turn || allTURNServers
+			ret.identity = ret.identity || allIdentities
+			if len(urls) == tags.Len() {
+				ret.httpAPIs = append(ret.httpAPIs, urls...)
+			}
+		}
+		return ret
+	})
+}
+
+// canGrantNetwork reports whether the account may grant grains access to the
+// network, which only admins with write access to infrastructure may.
+func canGrantNetwork(tx database.Tx, accountID types.AccountID) (bool, error) {
+	scopes, err := tx.AccountAdminScopes(accountID)
+	if err != nil {
+		return false, err
+	}
+	return scopes.Allows(types.AdminScopeInfrastructure, true), nil
+}
+
+// accountGrainPermissions returns the permissions the account has on the
+// grain: all of them if it owns the grain, otherwise those its keyring
+// grants. It returns sql.ErrNoRows if the account has no access.
+func accountGrainPermissions(tx database.Tx, accountID types.AccountID, grainID types.GrainID) ([]bool, error) {
+	return exn.Try(func(throw exn.Thrower) []bool {
+		info, err := tx.GrainInfo(grainID)
+		throw(err)
+		if info.Owner != string(accountID) {
+			perms, err := tx.AccountGrainPermissions(accountID, grainID)
+			throw(err)
+			return perms
+		}
+		titles, err := tx.GrainPermissionTitles(grainID)
+		throw(err)
+		perms := make([]bool, len(titles))
+		for i := range perms {
+			perms[i] = true
+		}
+		return perms
+	})
+}
+
+// hasPermissions reports whether have includes every permission in want.
+func hasPermissions(have, want []bool) bool {
+	for i, w := range want {
+		if w && (i >= len(have) || !have[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// bitListToBools converts a PermissionSet to the []bool we use elsewhere.
+func bitListToBools(bits capnp.BitList) []bool {
+	ret := make([]bool, bits.Len())
+	for i := range ret {
+		ret[i] = bits.At(i)
+	}
+	return ret
+}
+
+// startGrain returns the grain's container, starting it if it isn't running.
+func (s *server) startGrain(grainID types.GrainID) (container.Container, error) {
+	var (
+		c           container.Container
+		started     bool
+		startedKind = types.GrainStarted
+		exiting     <-chan struct{}
+		err         error
+	)
+	for {
+		s.state.With(func(state *serverState) {
+			c, started, exiting, err = state.containers.Get(context.Background(), s.log, s.db, grainID)
+			if started && state.containers.crashed[grainID] {
+				startedKind = types.GrainRestarted
+				delete(state.containers.crashed, grainID)
+			}
+		})
+		if exiting == nil {
+			break
+		}
+		<-exiting
+	}
+	if started {
+		go s.watchContainer(grainID, c)
+		s.events.Publish(types.GrainEvent{
+			GrainID: grainID,
+			Kind:    startedKind,
+		})
+	}
+	return c, err
+}
+
+// powerboxView is a grain's UiView, as granted to another grain through the
+// powerbox. Its sessions have at most the granted permissions, and it works
+// only while the user who granted it still has access to the grain, and the
+// required permissions on the grain holding it.
+type powerboxView struct {
+	server *server
+	holder types.GrainID
+	grant  database.PowerboxGrant
+}
+
+// powerboxCap returns the capability behind a grant to holder, after checking
+// that the grant is still valid.
+func (s *server) powerboxCap(tx database.Tx, holder types.GrainID, grant database.PowerboxGrant) (capnp.Client, error) {
+	if grant.Network {
+		n := powerboxNetwork{server: s, holder: holder, grant: grant}
+		if err := n.check(tx); err != nil {
+			return capnp.Client{}, err
+		}
+		return capnp.Client(ip.IpNetwork_ServerToClient(n)), nil
+	}
+	if grant.IPInterface {
+		i := powerboxIPInterface{server: s, holder: holder, grant: grant}
+		if err := i.check(tx); err != nil {
+			return capnp.Client{}, err
+		}
+		return capnp.Client(ip.IpInterface_ServerToClient(i)), nil
+	}
+	if grant.Thumbnailer {
+		t := powerboxThumbnailer{server: s, holder: holder, grant: grant}
+		if err := t.check(tx); err != nil {
+			return capnp.Client{}, err
+		}
+		return capnp.Client(thumbnailer.Thumbnailer_ServerToClient(t)), nil
+	}
+	if grant.TURN {
+		t := powerboxTURN{server: s, holder: holder, grant: grant}
+		if err := t.check(tx); err != nil {
+			return capnp.Client{}, err
+		}
+		return capnp.Client(turnserver.TurnServer_ServerToClient(t)), nil
+	}
+	if grant.HTTPAPI != "" {
+		a := powerboxHTTPAPI{server: s, holder: holder, grant: grant}
+		if _, err := a.check(tx); err != nil {
+			return capnp.Client{}, err
+		}
+		return capnp.Client(apisession.ApiSession_ServerToClient(a)), nil
+	}
+	if grant.Identity != "" {
+		i := powerboxIdentity{server: s, holder: holder, grant: grant}
+		if _, err := i.contact(tx); err != nil {
+			return capnp.Client{}, err
+		}
+		return capnp.Client(identity.Identity_ServerToClient(i)), nil
+	}
+	view := powerboxView{server: s, holder: holder, grant: grant}
+	if _, err := view.permissions(tx); err != nil {
+		return capnp.Client{}, err
+	}
+	return capnp.Client(grain.UiView_ServerToClient(view)), nil
+}
+
+// powerboxGrantFromClient returns the grant behind c, and the grain it was
+// granted to, if c is a capability from the powerbox.
+func powerboxGrantFromClient(c capnp.Client) (types.GrainID, database.PowerboxGrant, bool) {
+	snapshot := c.Snapshot()
+	defer snapshot.Release()
+	srv, ok := cpserver.IsServer(snapshot.Brand())
+	if !ok {
+		return "", database.PowerboxGrant{}, false
+	}
+	switch v := srv.(type) {
+	case powerboxView:
+		return v.holder, v.grant, true
+	case powerboxNetwork:
+		return v.holder, v.grant, true
+	case powerboxIPInterface:
+		return v.holder, v.grant, true
+	case powerboxThumbnailer:
+		return v.holder, v.grant, true
+	case powerboxTURN:
+		return v.holder, v.grant, true
+	case powerboxIdentity:
+		return v.holder, v.grant, true
+	case powerboxHTTPAPI:
+		return v.holder, v.grant, true
+	}
+	return "", database.PowerboxGrant{}, false
+}
+
+// errRevoked is returned when using a capability from the powerbox whose
+// grant is no longer valid.
+var errRevoked = exc.New(exc.Failed, "powerbox", "the user who granted this capability no longer has access")
+
+// checkHolder checks that the account which made a grant to holder still has
+// the required permissions on it.
+func checkHolder(tx database.Tx, holder types.GrainID, grant database.PowerboxGrant) error {
+	held, err := accountGrainPermissions(tx, grant.AccountID, holder)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errRevoked
+	} else if err != nil {
+		return err
+	}
+	if !hasPermissions(held, grant.RequiredPermissions) {
+		return errRevoked
+	}
+	return nil
+}
+
+// permissions returns the permissions the view currently has, or an error if
+// the grant has been revoked.
+func (v powerboxView) permissions(tx database.Tx) ([]bool, error) {
+	if err := checkHolder(tx, v.holder, v.grant); err != nil {
+		return nil, err
+	}
+	have, err := accountGrainPermissions(tx, v.grant.AccountID, v.grant.GrainID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errRevoked
+	} else if err != nil {
+		return nil, err
+	}
+	ret := make([]bool, len(v.grant.Permissions))
+	for i := range ret {
+		ret[i] = v.grant.Permissions[i] && i < len(have) && have[i]
+	}
+	return ret, nil
+}
+
+// mainView checks that the grant is still valid, and returns the grain's main
+// UiView and the permissions sessions may have.
+func (v powerboxView) mainView() (grain.MainView, []bool, error) {
+	perms, err := exn.Try(func(throw exn.Thrower) []bool {
+		tx, err := v.server.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		perms, err := v.permissions(tx)
+		throw(err)
+		return perms
+	})
+	if err != nil {
+		return grain.MainView{}, nil, err
+	}
+	c, err := v.server.startGrain(v.grant.GrainID)
+	if err != nil {
+		return grain.MainView{}, nil, err
+	}
+	return grain.MainView(c.Bootstrap.AddRef()), perms, nil
+}
+
+func (v powerboxView) GetViewInfo(ctx context.Context, p grain.UiView_getViewInfo) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		mainView, _, err := v.mainView()
+		throw(err)
+		defer mainView.Release()
+		fut, rel := mainView.GetViewInfo(ctx, nil)
+		defer rel()
+		viewInfo, err := fut.Struct()
+		throw(err)
+		results, err := p.AllocResults()
+		throw(err)
+		throw(capnp.Struct(results).CopyFrom(capnp.Struct(viewInfo)))
+	})
+}
+
+func (v powerboxView) NewSession(ctx context.Context, p grain.UiView_newSession) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		mainView, perms, err := v.mainView()
+		throw(err)
+		defer mainView.Release()
+		fut, rel := mainView.NewSession(ctx, func(dst grain.UiView_newSession_Params) error {
+			if err := capnp.Struct(dst).CopyFrom(capnp.Struct(p.Args())); err != nil {
+				return err
+			}
+			userInfo, err := dst.UserInfo()
+			if err != nil {
+				return err
+			}
+			userPerms, err := userInfo.Permissions()
+			if err != nil {
+				return err
+			}
+			for i := 0; i < userPerms.Len(); i++ {
+				userPerms.Set(i, userPerms.At(i) && i < len(perms) && perms[i])
+			}
+			return nil
+		})
+		defer rel()
+		res, err := fut.Struct()
+		throw(err)
+		results, err := p.AllocResults()
+		throw(err)
+		throw(results.SetSession(res.Session().AddRef()))
+	})
+}
+
+func (v powerboxView) NewRequestSession(context.Context, grain.UiView_newRequestSession) error {
+	return exc.New(exc.Unimplemented, "powerboxView", "powerbox requests through granted views are not supported")
+}
+
+func (v powerboxView) NewOfferSession(context.Context, grain.UiView_newOfferSession) error {
+	return exc.New(exc.Unimplemented, "powerboxView", "powerbox offers through granted views are not supported")
+}
+
+// powerboxNetwork is access to the network, as granted to a grain through the
+// powerbox. It works only while the admin who granted it may still grant the
+// network, and has the required permissions on the grain holding it.
+type powerboxNetwork struct {
+	server *server
+	holder types.GrainID
+	grant  database.PowerboxGrant
+}
+
+// check returns an error if the grant has been revoked.
+func (n powerboxNetwork) check(tx database.Tx) error {
+	return checkNetworkGrant(tx, n.holder, n.grant)
+}
+
+// checkNetworkGrant returns an error if a grant of access to the network, or
+// of permission to listen on it, has been revoked.
+func checkNetworkGrant(tx database.Tx, holder types.GrainID, grant database.PowerboxGrant) error {
+	if err := checkHolder(tx, holder, grant); err != nil {
+		return err
+	}
+	ok, err := canGrantNetwork(tx, grant.AccountID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !ok) {
+		return errRevoked
+	}
+	return err
+}
+
+// network checks that the grant is still valid, and returns the server's
+// IpNetwork.
+func (n powerboxNetwork) network() (ip.IpNetwork, error) {
+	err := exn.Try0(func(throw exn.Thrower) {
+		tx, err := n.server.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		throw(n.check(tx))
+	})
+	if err != nil {
+		return ip.IpNetwork{}, err
+	}
+	return n.server.network.AddRef(), nil
+}
+
+func (n powerboxNetwork) GetRemoteHost(ctx context.Context, p ip.IpNetwork_getRemoteHost) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		network, err := n.network()
+		throw(err)
+		defer network.Release()
+		fut, rel := network.GetRemoteHost(ctx, func(dst ip.IpNetwork_getRemoteHost_Params) error {
+			return capnp.Struct(dst).CopyFrom(capnp.Struct(p.Args()))
+		})
+		defer rel()
+		res, err := fut.Struct()
+		throw(err)
+		results, err := p.AllocResults()
+		throw(err)
+		throw(results.SetHost(res.Host().AddRef()))
+	})
+}
+
+func (n powerboxNetwork) GetRemoteHostByName(ctx context.Context, p ip.IpNetwork_getRemoteHostByName) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		network, err := n.network()
+		throw(err)
+		defer network.Release()
+		fut, rel := network.GetRemoteHostByName(ctx, func(dst ip.IpNetwork_getRemoteHostByName_Params) error {
+			return capnp.Struct(dst).CopyFrom(capnp.Struct(p.Args()))
+		})
+		defer rel()
+		res, err := fut.Struct()
+		throw(err)
+		results, err := p.AllocResults()
+		throw(err)
+		throw(results.SetHost(res.Host().AddRef()))
+	})
+}
+
+// powerboxIPInterface is permission to listen on the network, as granted to
+// a grain through the powerbox. Like powerboxNetwork, it works only while the
+// admin who granted it may still grant the network, and has the required
+// permissions on the grain holding it; this is checked again for each
+// connection or datagram received, so revoking it takes effect on ports
+// already listened on.
+type powerboxIPInterface struct {
+	server *server
+	holder types.GrainID
+	grant  database.PowerboxGrant
+}
+
+// check returns an error if the grant has been revoked.
+func (i powerboxIPInterface) check(tx database.Tx) error {
+	return checkNetworkGrant(tx, i.holder, i.grant)
+}
+
+// checkNow checks that the grant is still valid.
+func (i powerboxIPInterface) checkNow() error {
+	return exn.Try0(func(throw exn.Thrower) {
+		tx, err := i.server.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		throw(i.check(tx))
+	})
+}
+
+func (i powerboxIPInterface) ListenTcp(ctx context.Context, p ip.IpInterface_listenTcp) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		throw(i.checkNow())
+		port := revocableTcpPort{iface: i, port: p.Args().Port().AddRef()}
+		fut, rel := i.server.ipInterface.ListenTcp(ctx, func(dst ip.IpInterface_listenTcp_Params) error {
+			dst.SetPortNum(p.Args().PortNum())
+			return dst.SetPort(ip.TcpPort_ServerToClient(port))
+		})
+		defer rel()
+		res, err := fut.Struct()
+		throw(err)
+		results, err := p.AllocResults()
+		throw(err)
+		throw(results.SetHandle(res.Handle().AddRef()))
+		i.server.log.InfoCtx(ctx, "Grain listening on TCP port",
+			"grainID", i.holder,
+			"port", p.Args().PortNum(),
+		)
+	})
+}
+
+func (i powerboxIPInterface) ListenUdp(ctx context.Context, p ip.IpInterface_listenUdp) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		throw(i.checkNow())
+		port := revocableUdpPort{iface: i, port: p.Args().Port().AddRef()}
+		fut, rel := i.server.ipInterface.ListenUdp(ctx, func(dst ip.IpInterface_listenUdp_Params) error {
+			dst.SetPortNum(p.Args().PortNum())
+			return dst.SetPort(ip.UdpPort_ServerToClient(port))
+		})
+		defer rel()
+		res, err := fut.Struct()
+		throw(err)
+		results, err := p.AllocResults()
+		throw(err)
+		throw(results.SetHandle(res.Handle().AddRef()))
+		i.server.log.InfoCtx(ctx, "Grain listening on UDP port",
+			"grainID", i.holder,
+			"port", p.Args().PortNum(),
+		)
+	})
+}
+
+// revocableTcpPort passes connections to a grain's port while its
+// powerboxIPInterface is still valid.
+type revocableTcpPort struct {
+	iface powerboxIPInterface
+	port  ip.TcpPort
+}
+
+func (r revocableTcpPort) Connect(ctx context.Context, p ip.TcpPort_connect) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		throw(r.iface.checkNow())
+		fut, rel := r.port.Connect(ctx, func(dst ip.TcpPort_connect_Params) error {
+			return dst.SetDownstream(p.Args().Downstream().AddRef())
+		})
+		defer rel()
+		res, err := fut.Struct()
+		throw(err)
+		results, err := p.AllocResults()
+		throw(err)
+		throw(results.SetUpstream(res.Upstream().AddRef()))
+	})
+}
+
+func (r revocableTcpPort) Shutdown() {
+	r.port.Release()
+}
+
+// revocableUdpPort passes datagrams to a grain's port while its
+// powerboxIPInterface is still valid.
+type revocableUdpPort struct {
+	iface powerboxIPInterface
+	port  ip.UdpPort
+}
+
+func (r revocableUdpPort) Send(ctx context.Context, p ip.UdpPort_send) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		throw(r.iface.checkNow())
+		msg, err := p.Args().Msg()
+		throw(err)
+		fut, rel := r.port.Send(ctx, func(dst ip.UdpPort_send_Params) error {
+			if err := dst.SetMsg(msg); err != nil {
+				return err
+			}
+			return dst.SetReturnPort(p.Args().ReturnPort().AddRef())
+		})
+		defer rel()
+		_, err = fut.Struct()
+		throw(err)
+	})
+}
+
+func (r revocableUdpPort) Shutdown() {
+	r.port.Release()
+}
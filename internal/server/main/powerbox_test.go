@@ -0,0 +1,41 @@
+package servermain
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/database"
+)
+
+func TestCanGrantNetwork(t *testing.T) {
+	db, err := database.OpenPath(filepath.Join(t.TempDir(), "sandstorm.sqlite3"))
+	require.NoError(t, err)
+	defer db.Close()
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	defer tx.Rollback()
+	for _, a := range []database.NewAccount{
+		{ID: "admin", Role: types.RoleAdmin},
+		{ID: "infra", Role: types.RoleUser},
+		{ID: "support", Role: types.RoleUser},
+		{ID: "user", Role: types.RoleUser},
+	} {
+		require.NoError(t, tx.AddAccount(a))
+	}
+	require.NoError(t, tx.SetAdminScopes("infra", types.AdminScopes{types.AdminScopeInfrastructure}))
+	require.NoError(t, tx.SetAdminScopes("support", types.AdminScopes{types.AdminScopeUsers, types.AdminScopeAudit}))
+
+	for accountID, want := range map[types.AccountID]bool{
+		"admin":   true,
+		"infra":   true,
+		"support": false,
+		"user":    false,
+	} {
+		ok, err := canGrantNetwork(tx, accountID)
+		require.NoError(t, err)
+		assert.Equal(t, want, ok, accountID)
+	}
+}
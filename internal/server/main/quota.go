@@ -0,0 +1,158 @@
+package servermain
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"sandstorm.org/go/tempest/capnp/external"
+	"sandstorm.org/go/tempest/internal/common/apierror"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/quota"
+	"zenhack.net/go/util/exn"
+)
+
+// errQuotaExceeded is returned when a user's grains already use all of
+// their storage quota, or an upload would take them over it.
+var errQuotaExceeded = apierror.New(apierror.CodeQuotaExceeded, "storage quota exceeded")
+
+// How often to save the grains' storage usage in the database, where the
+// grain list reads it; see recordGrainStorage.
+const grainStorageRecordInterval = 5 * time.Minute
+
+// A grainStorage is the disk space used by a grain.
+type grainStorage struct {
+	GrainID types.GrainID
+	Bytes   int64
+}
+
+// accountStorage returns the disk space used by each of the account's
+// grains, largest first, as last measured.
+func (s *server) accountStorage(tx database.Tx, accountID types.AccountID) ([]grainStorage, error) {
+	grainIDs, err := tx.AccountGrainIDs(accountID)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]grainStorage, len(grainIDs))
+	for i, grainID := range grainIDs {
+		ret[i] = grainStorage{
+			GrainID: grainID,
+			Bytes:   s.quota.Usage(grainID),
+		}
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].Bytes > ret[j].Bytes
+	})
+	return ret, nil
+}
+
+// storageQuota returns the most disk space the account's grains may use, or
+// zero if there is no limit. Demo accounts have a quota of their own.
+func (s *server) storageQuota(tx database.Tx, accountID types.AccountID) (int64, error) {
+	expires, err := tx.DemoAccountExpires(accountID)
+	if err != nil {
+		return 0, err
+	}
+	if !expires.IsZero() {
+		return s.cfg.Demo.StorageQuota, nil
+	}
+	return s.cfg.Quota.UserLimit, nil
+}
+
+// remainingStorage returns the number of bytes the account's grains may use
+// on top of what they already do, or -1 if there is no limit. If the quota
+// is used up, it returns errQuotaExceeded.
+func (s *server) remainingStorage(tx database.Tx, accountID types.AccountID) (int64, error) {
+	limit, err := s.storageQuota(tx, accountID)
+	if err != nil {
+		return 0, err
+	}
+	if limit == 0 {
+		return -1, nil
+	}
+	grainIDs, err := tx.AccountGrainIDs(accountID)
+	if err != nil {
+		return 0, err
+	}
+	used := s.quota.Total(grainIDs)
+	if used >= limit {
+		return 0, errQuotaExceeded
+	}
+	return limit - used, nil
+}
+
+// recordGrainStorage saves the disk space used by each grain, as s.quota last
+// measured it, in the database. It does nothing until every grain has been
+// measured, so that grains aren't recorded as empty just after startup.
+func (s *server) recordGrainStorage(ctx context.Context, now time.Time) error {
+	usage, ok := s.quota.Usages()
+	if !ok {
+		return nil
+	}
+	return exn.Try0(func(throw exn.Thrower) {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		throw(tx.SetGrainStorage(usage))
+		throw(tx.Commit())
+	})
+}
+
+// quotaError replaces quota.ErrExceeded, from a reader limited by
+// remainingStorage, with errQuotaExceeded.
+func quotaError(err error) error {
+	if errors.Is(err, quota.ErrExceeded) {
+		return errQuotaExceeded
+	}
+	return err
+}
+
+// remainingStorage is like server.remainingStorage, for the session's
+// account.
+func (s userSessionImpl) remainingStorage() (int64, error) {
+	srv := s.visitor.server
+	return exn.Try(func(throw exn.Thrower) int64 {
+		tx, err := srv.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		accountID, err := tx.CredentialAccount(s.visitor.userSession.Credential)
+		throw(err)
+		remaining, err := srv.remainingStorage(tx, accountID)
+		throw(err)
+		return remaining
+	})
+}
+
+func (s userSessionImpl) StorageUsage(ctx context.Context, p external.UserSession_storageUsage) error {
+	srv := s.visitor.server
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	return withAPIError(results, func(throw exn.Thrower) {
+		tx, err := srv.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		accountID, err := tx.CredentialAccount(s.visitor.userSession.Credential)
+		throw(err)
+		grains, err := srv.accountStorage(tx, accountID)
+		throw(err)
+		limit, err := srv.storageQuota(tx, accountID)
+		throw(err)
+		throw(tx.Commit())
+
+		results.SetQuota(uint64(limit))
+		list, err := results.NewGrains(int32(len(grains)))
+		throw(err)
+		var used int64
+		for i, g := range grains {
+			used += g.Bytes
+			item := list.At(i)
+			throw(item.SetGrainId(string(g.GrainID)))
+			item.SetBytes(uint64(g.Bytes))
+		}
+		results.SetUsed(uint64(used))
+	})
+}
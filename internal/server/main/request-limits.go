@@ -0,0 +1,52 @@
+package servermain
+
+import (
+	"net/http"
+	"strings"
+
+	"sandstorm.org/go/tempest/internal/common/types"
+)
+
+// Kinds of hosts, which may have their own limits on requests; see
+// HTTP_HOST_LIMITS and reqlimit.Config.Hosts.
+const (
+	hostKindMain  = "main"  // The root domain, and anything else.
+	hostKindGrain = "grain" // Grains' UI hosts.
+	hostKindAPI   = "api"   // The API host.
+)
+
+// hostKind returns the kind of host req is for.
+func (s *server) hostKind(req *http.Request) string {
+	rootDomain := s.cfg.HTTP.RootDomain
+	switch {
+	case req.Host == apiHostPrefix+rootDomain:
+		return hostKindAPI
+	case strings.HasPrefix(req.Host, "ui-") && strings.HasSuffix(req.Host, "."+rootDomain):
+		return hostKindGrain
+	default:
+		return hostKindMain
+	}
+}
+
+// beginGrainHTTPRequest is like beginGrainRequest, for an HTTP request to the
+// grain, except that if the grain is already serving as many as
+// MAX_GRAIN_REQUESTS allows, it responds with a 503 instead, and returns
+// false.
+func (s *server) beginGrainHTTPRequest(w http.ResponseWriter, req *http.Request, grainID types.GrainID) (end func(), ok bool) {
+	endLimit, ok := s.grainRequests.Begin(string(grainID))
+	if !ok {
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "too many requests to this grain at once",
+			http.StatusServiceUnavailable)
+		s.log.DebugCtx(req.Context(), "Grain request limit reached",
+			"grainID", grainID,
+			"limit", s.cfg.Requests.MaxGrainRequests,
+		)
+		return nil, false
+	}
+	endRequest := s.beginGrainRequest(grainID)
+	return func() {
+		endRequest()
+		endLimit()
+	}, true
+}
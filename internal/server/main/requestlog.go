@@ -0,0 +1,83 @@
+package servermain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"sandstorm.org/go/tempest/internal/server/logging"
+	"sandstorm.org/go/tempest/internal/server/session"
+	"zenhack.net/go/util"
+)
+
+// requestIDHeader carries the ID of a request. If a reverse proxy in front
+// of us sets it, we use its ID, so log records can be matched up with the
+// proxy's.
+const requestIDHeader = "X-Request-ID"
+
+// tagRequest is middleware which tags the log records of each request with
+// its ID, and with the user's session and the grain, where known. The ID is
+// also sent back in the response, so users can quote it when reporting
+// problems.
+func (s *server) tagRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		id := req.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+		args := []any{"requestID", id}
+		var sess session.UserSession
+		if session.ReadCookie(s.sessionStore, req, &sess) == nil {
+			args = append(args, "sessionID", sessionLogID(sess.SessionID))
+		}
+		if grainID, ok := mux.Vars(req)["grainID"]; ok {
+			args = append(args, "grainID", grainID)
+		}
+		next.ServeHTTP(w, req.WithContext(logging.With(req.Context(), args...)))
+	})
+}
+
+// tagGrainSession tags the log records of req with the grain session's
+// grain and user session.
+func tagGrainSession(req *http.Request, sess session.GrainSession) *http.Request {
+	return req.WithContext(logging.With(req.Context(),
+		"sessionID", sessionLogID(sess.SessionID),
+		"grainID", sess.GrainID,
+	))
+}
+
+func newRequestID() string {
+	var buf [8]byte
+	_, err := rand.Read(buf[:])
+	util.Chkfatal(err)
+	return hex.EncodeToString(buf[:])
+}
+
+// validRequestID reports whether id is a plausible request ID from a proxy:
+// not too long, and without characters which could confuse log parsers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		isAlnum := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+		if !isAlnum && c != '-' && c != '_' && c != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// sessionLogID identifies a session in the log, by a short hash of its ID.
+// That is enough to tell which records are about the same session, without
+// putting the IDs themselves in the log.
+func sessionLogID(sessionID []byte) string {
+	if len(sessionID) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(sessionID)
+	return hex.EncodeToString(sum[:6])
+}
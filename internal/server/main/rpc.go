@@ -0,0 +1,135 @@
+package servermain
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/rpc"
+	"sandstorm.org/go/tempest/capnp/external"
+	"sandstorm.org/go/tempest/internal/common/apierror"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/session"
+	"zenhack.net/go/util"
+	"zenhack.net/go/util/exn"
+)
+
+// serveRPC accepts connections to the external RPC listener from l, until it
+// fails. Each connection's bootstrap interface is an ExternalRpcApi.
+func (s *server) serveRPC(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go s.serveRPCConn(conn)
+	}
+}
+
+func (s *server) serveRPCConn(conn net.Conn) {
+	defer conn.Close()
+	remoteAddr := conn.RemoteAddr().String()
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	bootstrap := rpcApiImpl{
+		server:     s,
+		remoteAddr: host,
+	}
+	rpcConn := rpc.NewConn(rpc.NewStreamTransport(conn), &rpc.Options{
+		BootstrapClient: capnp.Client(external.ExternalRpcApi_ServerToClient(bootstrap)),
+		Logger:          s.log.With("capnp-client-addr", remoteAddr),
+	})
+	<-rpcConn.Done()
+}
+
+type rpcApiImpl struct {
+	server     *server
+	remoteAddr string // The client's IP address.
+}
+
+func (api rpcApiImpl) Login(ctx context.Context, p external.ExternalRpcApi_login) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		token, err := p.Args().Token()
+		throw(err)
+		results, err := p.AllocResults()
+		throw(err)
+		tx, err := api.server.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		cred, err := tx.RestoreRPCToken(token)
+		if errors.Is(err, sql.ErrNoRows) {
+			throw(apierror.New(apierror.CodeNotFound,
+				"no such token, or it has expired", "RPC token"))
+		}
+		throw(err)
+		// The session is like a web session's, so everything which
+		// checks the user's account, role and deactivation applies.
+		throw(results.SetApi(external.ExternalApi_ServerToClient(externalApiImpl{
+			server: api.server,
+			userSession: session.UserSession{
+				SessionID:  session.GenSessionID(),
+				Credential: cred,
+			},
+			sessionStore: api.server.sessionStore,
+			remoteAddr:   api.remoteAddr,
+		})))
+	})
+}
+
+// RPCToken is the entry point for `tempest rpc-token`, which makes tokens for
+// the external RPC listener, and revokes them.
+func RPCToken(args []string) {
+	flags := flag.NewFlagSet("tempest rpc-token", flag.ExitOnError)
+	typ := flags.String("type", string(types.EmailCredential),
+		"type of the credential of the account to make a token for")
+	days := flags.Int("days", 365, "days until the token expires")
+	revoke := flags.String("revoke", "", "revoke this token, rather than making one")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: tempest rpc-token [-type <type>] [-days <days>] <credential id>")
+		fmt.Fprintln(flags.Output(), "       tempest rpc-token -revoke <token>")
+		fmt.Fprintln(flags.Output(),
+			"Prints a token which external tools can pass to ExternalRpcApi.login to act as the account.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if (*revoke == "") != (flags.NArg() == 1) || *days <= 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	db := util.Must(database.Open())
+	defer db.Close()
+	tx := util.Must(db.Begin())
+	defer tx.Rollback()
+	if *revoke != "" {
+		err := tx.DeleteRPCToken([]byte(*revoke))
+		if errors.Is(err, sql.ErrNoRows) {
+			fmt.Fprintln(os.Stderr, "No such token.")
+			os.Exit(1)
+		}
+		util.Chkfatal(err)
+		util.Chkfatal(tx.Commit())
+		return
+	}
+	cred := types.Credential{
+		Type:     types.CredentialType(*typ),
+		ScopedID: flags.Arg(0),
+	}
+	token, err := tx.NewRPCToken(cred, time.Now().AddDate(0, 0, *days))
+	if errors.Is(err, sql.ErrNoRows) {
+		fmt.Fprintf(os.Stderr, "No account has the %s credential %q.\n", cred.Type, cred.ScopedID)
+		os.Exit(1)
+	}
+	util.Chkfatal(err)
+	util.Chkfatal(tx.Commit())
+	fmt.Println(token)
+}
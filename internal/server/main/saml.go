@@ -0,0 +1,53 @@
+package servermain
+
+import (
+	"net/http"
+
+	"sandstorm.org/go/tempest/internal/common/types"
+)
+
+// Paths of our SAML endpoints, as given in our metadata.
+const (
+	samlMetadataPath = "/saml/metadata"
+	samlACSPath      = "/saml/acs"
+)
+
+// serveSAMLMetadata serves our metadata, for configuring the identity
+// provider.
+func (s *server) serveSAMLMetadata(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/samlmetadata+xml")
+	w.Write(s.saml.Metadata())
+}
+
+// serveSAMLLogin starts single sign-on, by sending the browser to the identity
+// provider with an authentication request.
+func (s *server) serveSAMLLogin(w http.ResponseWriter, req *http.Request) {
+	u, err := s.saml.AuthnRequestURL()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Making SAML authentication request", "error", err)
+		return
+	}
+	http.Redirect(w, req, u, http.StatusSeeOther)
+}
+
+// serveSAMLACS is our assertion consumer service, to which the browser posts
+// the identity provider's response. If the response checks out, the user is
+// logged in with a SAML credential.
+func (s *server) serveSAMLACS(w http.ResponseWriter, req *http.Request) {
+	assertion, err := s.saml.ParseResponse(req.PostFormValue("SAMLResponse"))
+	var userID string
+	if err == nil {
+		userID, err = s.saml.UserID(assertion)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("Single sign-on failed; try logging in again."))
+		s.log.WarnCtx(req.Context(), "Rejected SAML response", "error", err)
+		return
+	}
+	s.logIn(w, req, types.Credential{
+		Type:     types.SAMLCredential,
+		ScopedID: userID,
+	})
+}
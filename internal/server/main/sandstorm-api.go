@@ -2,49 +2,155 @@ package servermain
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"time"
 
+	"capnproto.org/go/capnp/v3"
 	"capnproto.org/go/capnp/v3/exc"
 	"sandstorm.org/go/tempest/capnp/grain"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/apiversion"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/tokenutil"
+	"zenhack.net/go/util/exn"
 )
 
-type sandstormApiImpl struct{}
+type sandstormApiImpl struct {
+	server   *server
+	grainID  types.GrainID
+	apiUsage *apiversion.Recorder
+}
 
-func (sandstormApiImpl) DeprecatedPublish(context.Context, grain.SandstormApi_deprecatedPublish) error {
-	return exc.New(exc.Unimplemented, "SandstormApi", "unimplemented")
+// use records a call to the named method; see apiversion.Recorder.Record.
+func (api sandstormApiImpl) use(method string) {
+	api.apiUsage.Record(api.grainID, "SandstormApi", method)
 }
 
-func (sandstormApiImpl) DeprecatedRegisterAction(context.Context, grain.SandstormApi_deprecatedRegisterAction) error {
+func (api sandstormApiImpl) DeprecatedPublish(context.Context, grain.SandstormApi_deprecatedPublish) error {
+	api.use("deprecatedPublish")
 	return exc.New(exc.Unimplemented, "SandstormApi", "unimplemented")
 }
 
-func (sandstormApiImpl) ShareCap(context.Context, grain.SandstormApi_shareCap) error {
-	return exc.New(exc.Unimplemented, "SandstormApi", "TODO")
+func (api sandstormApiImpl) DeprecatedRegisterAction(context.Context, grain.SandstormApi_deprecatedRegisterAction) error {
+	api.use("deprecatedRegisterAction")
+	return exc.New(exc.Unimplemented, "SandstormApi", "unimplemented")
 }
 
-func (sandstormApiImpl) ShareView(context.Context, grain.SandstormApi_shareView) error {
+func (api sandstormApiImpl) ShareCap(context.Context, grain.SandstormApi_shareCap) error {
+	api.use("shareCap")
 	return exc.New(exc.Unimplemented, "SandstormApi", "TODO")
 }
-func (sandstormApiImpl) Save(context.Context, grain.SandstormApi_save) error {
+
+func (api sandstormApiImpl) ShareView(context.Context, grain.SandstormApi_shareView) error {
+	api.use("shareView")
 	return exc.New(exc.Unimplemented, "SandstormApi", "TODO")
 }
-func (sandstormApiImpl) Restore(context.Context, grain.SandstormApi_restore) error {
-	return exc.New(exc.Unimplemented, "SandstormApi", "TODO")
+
+// Save saves a capability which the grain got from the powerbox; other
+// capabilities are not yet persistent.
+func (api sandstormApiImpl) Save(ctx context.Context, p grain.SandstormApi_save) error {
+	api.use("save")
+	holder, grant, ok := powerboxGrantFromClient(capnp.Client(p.Args().Cap()))
+	if !ok {
+		return exc.New(exc.Unimplemented, "SandstormApi", "only capabilities from the powerbox can be saved")
+	}
+	if holder != api.grainID {
+		return exc.New(exc.Failed, "SandstormApi", "capability was granted to a different grain")
+	}
+	return exn.Try0(func(throw exn.Thrower) {
+		label, err := p.Args().Label()
+		throw(err)
+		note, err := label.DefaultText()
+		throw(err)
+		results, err := p.AllocResults()
+		throw(err)
+		grant.Expires = time.Unix(math.MaxInt64, 0) // never
+		if note != "" {
+			grant.Note = note
+		}
+		token := tokenutil.GenToken()
+		tx, err := api.server.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		throw(tx.SavePowerboxGrant(api.sturdyRefKey(token), grant))
+		throw(tx.Commit())
+		throw(results.SetToken(token))
+	})
 }
-func (sandstormApiImpl) Drop(context.Context, grain.SandstormApi_drop) error {
-	return exc.New(exc.Unimplemented, "SandstormApi", "TODO")
+
+func (api sandstormApiImpl) Restore(ctx context.Context, p grain.SandstormApi_restore) error {
+	api.use("restore")
+	return exn.Try0(func(throw exn.Thrower) {
+		token, err := p.Args().Token()
+		throw(err)
+		results, err := p.AllocResults()
+		throw(err)
+		tx, err := api.server.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		grant, err := tx.RestorePowerboxGrant(api.sturdyRefKey(token))
+		if errors.Is(err, sql.ErrNoRows) {
+			throw(exc.New(exc.Failed, "SandstormApi", "no such token, or it has expired"))
+		}
+		throw(err)
+		c, err := api.server.powerboxCap(tx, api.grainID, grant)
+		throw(err)
+		throw(results.SetCap(c))
+	})
 }
-func (sandstormApiImpl) Deleted(context.Context, grain.SandstormApi_deleted) error {
-	return exc.New(exc.Unimplemented, "SandstormApi", "TODO")
+
+func (api sandstormApiImpl) Drop(ctx context.Context, p grain.SandstormApi_drop) error {
+	api.use("drop")
+	return exn.Try0(func(throw exn.Thrower) {
+		token, err := p.Args().Token()
+		throw(err)
+		tx, err := api.server.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		// Make sure the token is the grain's before deleting it:
+		key := api.sturdyRefKey(token)
+		_, err = tx.RestoreSturdyRef(key)
+		if errors.Is(err, sql.ErrNoRows) {
+			return // Already dropped.
+		}
+		throw(err)
+		throw(tx.DeleteSturdyRef(key))
+		throw(tx.Commit())
+	})
 }
-func (sandstormApiImpl) StayAwake(context.Context, grain.SandstormApi_stayAwake) error {
-	return exc.New(exc.Unimplemented, "SandstormApi", "TODO")
+
+// sturdyRefKey returns the key of the grain's sturdyRef with the given token.
+func (api sandstormApiImpl) sturdyRefKey(token []byte) database.SturdyRefKey {
+	return database.SturdyRefKey{
+		Token:     token,
+		OwnerType: "grain",
+		Owner:     types.AccountID(api.grainID),
+	}
 }
-func (sandstormApiImpl) BackgroundActivity(context.Context, grain.SandstormApi_backgroundActivity) error {
+
+func (api sandstormApiImpl) Deleted(context.Context, grain.SandstormApi_deleted) error {
+	api.use("deleted")
 	return exc.New(exc.Unimplemented, "SandstormApi", "TODO")
 }
-func (sandstormApiImpl) GetIdentityId(context.Context, grain.SandstormApi_getIdentityId) error {
+func (api sandstormApiImpl) StayAwake(context.Context, grain.SandstormApi_stayAwake) error {
+	api.use("stayAwake")
 	return exc.New(exc.Unimplemented, "SandstormApi", "TODO")
 }
-func (sandstormApiImpl) Schedule(context.Context, grain.SandstormApi_schedule) error {
-	return exc.New(exc.Unimplemented, "SandstormApi", "TODO")
+
+// GetIdentityId returns the ID of an identity which the grain got from the
+// powerbox, which is how apps tell whether it's that of a user of one of their
+// sessions.
+func (api sandstormApiImpl) GetIdentityId(ctx context.Context, p grain.SandstormApi_getIdentityId) error {
+	api.use("getIdentityId")
+	_, grant, ok := powerboxGrantFromClient(capnp.Client(p.Args().Identity()))
+	if !ok || grant.Identity == "" {
+		return exc.New(exc.Unimplemented, "SandstormApi", "only identities from the powerbox are supported")
+	}
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	return results.SetId(identityID(grant.Identity))
 }
@@ -0,0 +1,183 @@
+package servermain
+
+import (
+	"context"
+	"time"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/exc"
+	"sandstorm.org/go/tempest/capnp/grain"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/scheduler"
+	"sandstorm.org/go/tempest/internal/server/tokenutil"
+	"zenhack.net/go/util/exn"
+)
+
+// newScheduler returns the runner for jobs scheduled by grains through
+// SandstormApi.schedule().
+func (s *server) newScheduler() *scheduler.Runner {
+	return scheduler.NewRunner(scheduler.Config{
+		Store: jobStore{db: s.db},
+		Run:   s.runScheduledJob,
+		OnDisable: func(j scheduler.Job) {
+			s.events.Publish(types.GrainEvent{
+				GrainID: j.GrainID,
+				Kind:    types.GrainJobDisabled,
+				Detail:  j.Name,
+			})
+		},
+		Log: s.log,
+	})
+}
+
+// Schedule saves the job's callback, which must implement AppPersistent, and
+// records the job, to be run by the server's scheduler.
+func (api sandstormApiImpl) Schedule(ctx context.Context, p grain.SandstormApi_schedule) error {
+	api.use("schedule")
+	return exn.Try0(func(throw exn.Thrower) {
+		args := p.Args()
+		name, err := args.Name()
+		throw(err)
+		job := scheduler.Job{
+			ID:      tokenutil.Gen128Base64(),
+			GrainID: api.grainID,
+		}
+		job.Name, err = name.DefaultText()
+		throw(err)
+
+		schedule := args.Schedule()
+		switch schedule.Which() {
+		case grain.ScheduledJob_schedule_Which_oneShot:
+			oneShot := schedule.OneShot()
+			if err := scheduler.CheckSlack(time.Duration(oneShot.Slack())); err != nil {
+				throw(exc.New(exc.Failed, "SandstormApi", err.Error()))
+			}
+			job.Next = time.Unix(0, oneShot.When())
+		case grain.ScheduledJob_schedule_Which_periodic:
+			job.Period = scheduler.Period(schedule.Periodic().String())
+			if job.Period == scheduler.OneShot || !job.Period.IsValid() {
+				throw(exc.New(exc.Failed, "SandstormApi", "unknown scheduling period"))
+			}
+			job.Next = job.Period.After(time.Now())
+		default:
+			throw(exc.New(exc.Unimplemented, "SandstormApi", "unknown kind of schedule"))
+		}
+
+		if !args.HasCallback() {
+			throw(exc.New(exc.Failed, "SandstormApi", "missing callback"))
+		}
+		persistent := grain.AppPersistent(args.Callback().AddRef())
+		defer persistent.Release()
+		saveFut, rel := persistent.Save(ctx, nil)
+		defer rel()
+		saveRes, err := saveFut.Struct()
+		throw(err, "saving callback")
+		objectID, err := saveRes.ObjectId()
+		throw(err)
+		job.ObjectID, err = encodeObjectID(objectID)
+		throw(err)
+
+		tx, err := api.server.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		throw(tx.AddScheduledJob(job))
+		throw(tx.Commit())
+		api.server.jobs.Wake()
+	})
+}
+
+// runScheduledJob starts the job's grain if need be, restores its callback,
+// and calls it.
+func (s *server) runScheduledJob(ctx context.Context, j scheduler.Job) (cancel bool, err error) {
+	defer s.beginGrainRequest(j.GrainID)()
+	err = exn.Try0(func(throw exn.Thrower) {
+		c, err := s.startGrain(j.GrainID)
+		throw(err)
+		mainView := grain.MainView(c.Bootstrap.AddRef())
+		defer mainView.Release()
+
+		restoreFut, rel := mainView.Restore(ctx, func(p grain.MainView_restore_Params) error {
+			msg, err := capnp.Unmarshal(j.ObjectID)
+			if err != nil {
+				return err
+			}
+			objectID, err := msg.Root()
+			if err != nil {
+				return err
+			}
+			return p.SetObjectId(objectID)
+		})
+		defer rel()
+		restoreRes, err := restoreFut.Struct()
+		throw(err, "restoring callback")
+		callback := grain.ScheduledJob_Callback(restoreRes.Cap().AddRef())
+		defer callback.Release()
+
+		runFut, rel := callback.Run(ctx, nil)
+		defer rel()
+		runRes, err := runFut.Struct()
+		throw(err)
+		cancel = runRes.CancelFutureRuns()
+	})
+	return cancel, err
+}
+
+// encodeObjectID encodes an AppObjectId as a capnp message, for storage.
+func encodeObjectID(objectID capnp.Ptr) ([]byte, error) {
+	msg, _, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	if err != nil {
+		return nil, err
+	}
+	if err = msg.SetRoot(objectID); err != nil {
+		return nil, err
+	}
+	return msg.Marshal()
+}
+
+// jobStore is a scheduler.Store which keeps jobs in the database.
+type jobStore struct {
+	db database.DB
+}
+
+func (js jobStore) DueJobs(now time.Time) ([]scheduler.Job, error) {
+	return exn.Try(func(throw exn.Thrower) []scheduler.Job {
+		tx, err := js.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		jobs, err := tx.DueScheduledJobs(now)
+		throw(err)
+		return jobs
+	})
+}
+
+func (js jobStore) NextDue() (next time.Time, ok bool, err error) {
+	err = exn.Try0(func(throw exn.Thrower) {
+		tx, err := js.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		next, ok, err = tx.NextScheduledJobDue()
+		throw(err)
+	})
+	return next, ok, err
+}
+
+func (js jobStore) UpdateJob(j scheduler.Job) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		tx, err := js.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		throw(tx.UpdateScheduledJob(j))
+		throw(tx.Commit())
+	})
+}
+
+func (js jobStore) DeleteJob(id string) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		tx, err := js.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		throw(tx.DeleteScheduledJob(id))
+		throw(tx.Commit())
+	})
+}
@@ -0,0 +1,336 @@
+package servermain
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"sandstorm.org/go/tempest/internal/server/settings"
+	"sandstorm.org/go/tempest/internal/server/tokenutil"
+)
+
+// `tempest self-test` checks, from outside, that grains' subdomains reach
+// Tempest: that they resolve, that requests to them are routed to Tempest
+// with their Host intact, and that cookies set on one aren't sent to
+// another. It requests selfTestPath on random ui- subdomains, which the
+// server answers without a grain session.
+
+const (
+	selfTestPath       = "/_tempest-self-test"
+	selfTestCookieName = "tempest-self-test"
+)
+
+// validSelfTestNonce matches the nonces the self-test sends, which are the
+// only values the server echoes back.
+var validSelfTestNonce = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// selfTestResponse is the server's answer to a self-test request.
+type selfTestResponse struct {
+	// The nonce from the request, showing that Tempest answered it, rather
+	// than some other server.
+	Nonce string `json:"nonce"`
+
+	// Whether the request carried the self-test cookie.
+	Cookie bool `json:"cookie"`
+}
+
+// serveSelfTest answers a self-test request on a ui- subdomain, setting a
+// cookie with the same attributes as grain sessions' cookies.
+func serveSelfTest(w http.ResponseWriter, req *http.Request) {
+	nonce := req.URL.Query().Get("nonce")
+	if !validSelfTestNonce.MatchString(nonce) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	_, err := req.Cookie(selfTestCookieName)
+	http.SetCookie(w, &http.Cookie{
+		Name:     selfTestCookieName,
+		Value:    nonce,
+		Path:     "/",
+		MaxAge:   60,
+		Secure:   req.URL.Scheme == "https",
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(selfTestResponse{
+		Nonce:  nonce,
+		Cookie: err == nil,
+	})
+}
+
+// SelfTest implements `tempest self-test`, which checks that the wildcard DNS
+// record, TLS certificate and any reverse proxy in front of Tempest let
+// browsers reach grains' subdomains. Tempest must be running. Each failure
+// is printed with advice on fixing it, and makes the command exit with a
+// non-zero status.
+func SelfTest(args []string) {
+	flags := flag.NewFlagSet("tempest self-test", flag.ExitOnError)
+	timeout := flags.Duration("timeout", 10*time.Second, "timeout for each request")
+	flags.Parse(args)
+	if flags.NArg() != 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	cfg, err := loadConfig(settings.Environ)
+	if err != nil {
+		fmt.Println("error:", err)
+		fmt.Println("Run `tempest --validate-config` for details.")
+		os.Exit(1)
+	}
+	jar, _ := cookiejar.New(nil)
+	t := selfTest{
+		cfg: cfg.HTTP,
+		client: &http.Client{
+			Timeout: *timeout,
+			Jar:     jar,
+			// Redirects are diagnosed, not followed.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+	if !t.run(context.Background()) {
+		os.Exit(1)
+	}
+	fmt.Println("All checks passed.")
+}
+
+// selfTest is the state of `tempest self-test`.
+type selfTest struct {
+	cfg    HTTPConfig
+	client *http.Client
+}
+
+// run runs the checks in order, stopping at the first failure, since the
+// later ones depend on the earlier. It reports whether they all passed.
+func (t selfTest) run(ctx context.Context) bool {
+	host := hostOnly(t.cfg.RootDomain)
+	_, port, _ := net.SplitHostPort(t.cfg.RootDomain)
+	sub1 := t.subdomain(host)
+	sub2 := t.subdomain(host)
+
+	if !t.checkDNS(ctx, host, sub1) {
+		return false
+	}
+	if port != "" {
+		sub1 = net.JoinHostPort(sub1, port)
+		sub2 = net.JoinHostPort(sub2, port)
+	}
+	first, ok := t.checkRequest(sub1)
+	if !ok {
+		return false
+	}
+	return t.checkCookies(sub1, sub2, first)
+}
+
+// subdomain returns a new random ui- subdomain of host, like those grains
+// are served from.
+func (selfTest) subdomain(host string) string {
+	return "ui-" + hex.EncodeToString(tokenutil.Gen128()) + "." + host
+}
+
+func passCheck(format string, args ...any) {
+	fmt.Printf("ok:   "+format+"\n", args...)
+}
+
+// fail prints a failed check, and advice on fixing it.
+func failCheck(advice, format string, args ...any) {
+	fmt.Printf("FAIL: "+format+"\n", args...)
+	if advice == "" {
+		return
+	}
+	for _, line := range strings.Split(advice, "\n") {
+		fmt.Println("      " + line)
+	}
+}
+
+// checkDNS checks that the base domain and the subdomain resolve, and to the
+// same addresses.
+func (t selfTest) checkDNS(ctx context.Context, host, sub string) bool {
+	hostAddrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		failCheck(fmt.Sprintf("Add an A (and/or AAAA) record for %s, pointing at this server.", host),
+			"resolving %s: %v", host, err)
+		return false
+	}
+	subAddrs, err := net.DefaultResolver.LookupHost(ctx, sub)
+	if err != nil {
+		failCheck(fmt.Sprintf("Grains are served from random subdomains, so Tempest needs a wildcard record:\n"+
+			"add a record for *.%s -- a CNAME to %s, or the same A/AAAA records.\n"+
+			"New records can take a while to be seen, depending on their TTL.", host, host),
+			"resolving a random subdomain, %s: %v", sub, err)
+		return false
+	}
+	passCheck("%s and its subdomains resolve", host)
+	if !overlaps(hostAddrs, subAddrs) {
+		// Not necessarily wrong, e.g. behind a CDN, so not a failure.
+		fmt.Printf("note: %s resolves to %s, but its subdomains to %s;\n"+
+			"      unless that's deliberate, the wildcard record should match %s's.\n",
+			host, strings.Join(hostAddrs, ", "), strings.Join(subAddrs, ", "), host)
+	}
+	return true
+}
+
+func overlaps(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// selfTestURL returns the URL of the self-test endpoint on host.
+func (t selfTest) selfTestURL(host, nonce string) string {
+	u := url.URL{
+		Scheme:   "http",
+		Host:     host,
+		Path:     selfTestPath,
+		RawQuery: url.Values{"nonce": {nonce}}.Encode(),
+	}
+	if t.cfg.DefaultTLS {
+		u.Scheme = "https"
+	}
+	return u.String()
+}
+
+// get requests the self-test endpoint on host, returning the response, and
+// its decoded body if it is from Tempest.
+func (t selfTest) get(host string) (*http.Response, *selfTestResponse, error) {
+	nonce := hex.EncodeToString(tokenutil.Gen128())
+	resp, err := t.client.Get(t.selfTestURL(host, nonce))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil, nil
+	}
+	var body selfTestResponse
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil || json.Unmarshal(data, &body) != nil || body.Nonce != nonce {
+		return resp, nil, nil
+	}
+	return resp, &body, nil
+}
+
+// checkRequest checks that a request to the subdomain, through the public
+// origin, reaches Tempest, returning the response.
+func (t selfTest) checkRequest(sub string) (*http.Response, bool) {
+	resp, body, err := t.get(sub)
+	var (
+		hostErr    x509.HostnameError
+		unknownErr x509.UnknownAuthorityError
+		certErr    *tls.CertificateVerificationError
+		netErr     net.Error
+	)
+	switch {
+	case errors.As(err, &hostErr):
+		failCheck(fmt.Sprintf("The certificate must also be valid for *.%s, e.g. a wildcard certificate.\n"+
+			"Setting ACME_DNS_PROVIDER makes Tempest obtain one itself.", hostOnly(t.cfg.RootDomain)),
+			"the TLS certificate for %s doesn't cover it: %v", sub, err)
+	case errors.As(err, &unknownErr), errors.As(err, &certErr):
+		failCheck("Use a certificate from a CA browsers trust, with its full chain, e.g.\n"+
+			"by setting ACME_DNS_PROVIDER.",
+			"the TLS certificate for %s isn't trusted: %v", sub, err)
+	case errors.As(err, &netErr) && netErr.Timeout():
+		failCheck("Check that the firewall allows connections to Tempest's ports, and that\n"+
+			"the DNS records point at this server.",
+			"requesting %s timed out: %v", sub, err)
+	case err != nil:
+		failCheck("Check that Tempest, and any reverse proxy in front of it, are running and\n"+
+			"listening on the port in BASE_URL.",
+			"requesting %s: %v", sub, err)
+	case resp.StatusCode == http.StatusMovedPermanently &&
+		strings.HasPrefix(resp.Header.Get("Location"), "https:") && t.cfg.DefaultTLS:
+		failCheck("Tempest saw an HTTPS request as plain HTTP, so it is behind a proxy which\n"+
+			"terminates TLS. List the proxy's address in TRUSTED_PROXIES, and have it\n"+
+			"set X-Forwarded-Proto.",
+			"%s redirected to HTTPS, though the request used HTTPS", sub)
+	case body == nil:
+		failCheck("The request reached something other than Tempest's grain subdomains; if\n"+
+			"there is a reverse proxy, it must forward every subdomain of the base\n"+
+			"domain to Tempest, keeping the Host header (or setting X-Forwarded-Host).",
+			"%s answered with %s, not as Tempest", sub, resp.Status)
+	default:
+		passCheck("requests to %s reach Tempest", sub)
+		return resp, true
+	}
+	return nil, false
+}
+
+// checkCookies checks that a cookie set on one subdomain is sent back to it,
+// but not to others.
+func (t selfTest) checkCookies(sub1, sub2 string, first *http.Response) bool {
+	var cookie *http.Cookie
+	for _, c := range first.Cookies() {
+		if c.Name == selfTestCookieName {
+			cookie = c
+		}
+	}
+	if cookie == nil {
+		failCheck("A reverse proxy is probably removing Set-Cookie headers; grain sessions\n"+
+			"need them.",
+			"%s didn't set a cookie", sub1)
+		return false
+	}
+	if cookie.Domain != "" {
+		failCheck("A reverse proxy is probably rewriting the cookies' domains; configure it\n"+
+			"to leave them alone, so that grains can't read each other's cookies.",
+			"the cookie set by %s is scoped to the domain %s", sub1, cookie.Domain)
+		return false
+	}
+	ok := true
+	_, again, err := t.get(sub1)
+	switch {
+	case err != nil || again == nil:
+		failCheck("", "requesting %s a second time failed: %v", sub1, err)
+		return false
+	case !again.Cookie:
+		failCheck("A reverse proxy is probably removing Cookie headers; grain sessions\n"+
+			"need them.",
+			"the cookie set by %s wasn't sent back to it", sub1)
+		ok = false
+	}
+	_, other, err := t.get(sub2)
+	switch {
+	case err != nil || other == nil:
+		failCheck("", "requesting %s failed: %v", sub2, err)
+		return false
+	case other.Cookie:
+		failCheck("Grains could read each other's cookies. Check that nothing between\n"+
+			"browsers and Tempest shares cookies across subdomains.",
+			"the cookie set by %s was sent to %s", sub1, sub2)
+		ok = false
+	}
+	if ok {
+		passCheck("cookies are scoped to each subdomain")
+	}
+	return ok
+}
+
+// hostOnly returns hostport without its port, if any.
+func hostOnly(hostport string) string {
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		return h
+	}
+	return hostport
+}
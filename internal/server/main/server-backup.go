@@ -0,0 +1,123 @@
+package servermain
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/exp/slog"
+	"sandstorm.org/go/tempest/internal/config"
+	"sandstorm.org/go/tempest/internal/server/blobstore"
+	"sandstorm.org/go/tempest/internal/server/cgroup"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/logging"
+	"sandstorm.org/go/tempest/internal/server/serverbackup"
+	"sandstorm.org/go/tempest/internal/server/settings"
+	"zenhack.net/go/util"
+)
+
+// backupTrees returns the directories which `tempest backup` backs up, and
+// `tempest restore` restores. Blobs are included only if they are kept on
+// the local disk; an object store is expected to look after its own.
+func backupTrees(lg *slog.Logger) []serverbackup.Tree {
+	trees := []serverbackup.Tree{
+		{Name: "grains", Dir: config.GrainsDir, Grains: true},
+		{Name: "packages", Dir: config.PackagesDir},
+	}
+	blobs := BlobsConfigFromSettings(lg, settings.Environ)
+	if blobs.Backend == "" || blobs.Backend == blobstore.BackendLocal {
+		trees = append(trees, serverbackup.Tree{Name: "blobs", Dir: blobs.Dir})
+	}
+	return trees
+}
+
+// Backup implements `tempest backup`, which backs up the whole server -- its
+// database, grains, packages and blobs -- to a new directory in dest. It may
+// be run while Tempest is running; see package serverbackup for how the
+// backup is kept consistent.
+func Backup(args []string) {
+	flags := flag.NewFlagSet("tempest backup", flag.ExitOnError)
+	incremental := flags.Bool("incremental", false,
+		"copy only the files which changed since the latest backup in dest")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: tempest backup [-incremental] <dest>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	dest := flags.Arg(0)
+
+	lg := logging.NewLogger()
+	db := util.Must(database.Open())
+	defer db.Close()
+	opts := serverbackup.Options{
+		Log:         lg,
+		SnapshotDB:  db.Snapshot,
+		Trees:       backupTrees(lg),
+		Incremental: *incremental,
+	}
+	if cgroups := CgroupConfigFromSettings(lg, settings.Environ); cgroups.Enabled() {
+		opts.Freeze = func(grainID string) (func() error, error) {
+			return cgroup.Freeze(cgroups.Root, grainID)
+		}
+	} else {
+		lg.Warn("GRAIN_CGROUP is not set, so running grains can't be frozen; " +
+			"their files may be inconsistent in the backup unless they are on btrfs")
+	}
+	name, err := serverbackup.Create(dest, opts)
+	util.Chkfatal(err)
+	fmt.Println(filepath.Join(dest, name))
+}
+
+// Restore implements `tempest restore`, which restores a backup taken by
+// `tempest backup`. Tempest must be stopped while it runs.
+func Restore(args []string) {
+	flags := flag.NewFlagSet("tempest restore", flag.ExitOnError)
+	force := flags.Bool("force", false,
+		"restore over an existing database and data, rather than refusing to")
+	check := flags.Bool("check", false,
+		"only check the backup against its manifest, without restoring it")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: tempest restore [-force | -check] <backup>")
+		fmt.Fprintln(flags.Output(),
+			"<backup> may be a backup's directory, or a destination given to `tempest backup`, to use its latest backup.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 || (*force && *check) {
+		flags.Usage()
+		os.Exit(2)
+	}
+	dir := flags.Arg(0)
+	if _, err := serverbackup.ReadManifest(dir); errors.Is(err, fs.ErrNotExist) {
+		name, err := serverbackup.Latest(dir)
+		util.Chkfatal(err)
+		dir = filepath.Join(dir, name)
+	}
+
+	lg := logging.NewLogger()
+	// Check the whole backup before touching anything, so that a corrupt
+	// backup isn't half restored.
+	if err := serverbackup.Verify(dir); err != nil {
+		fmt.Fprintf(os.Stderr, "Backup %s is damaged:\n%v\n", dir, err)
+		os.Exit(1)
+	}
+	if *check {
+		fmt.Printf("Backup %s is intact.\n", dir)
+		return
+	}
+	initStorage()
+	util.Chkfatal(serverbackup.Restore(dir, serverbackup.RestoreOptions{
+		Log:    lg,
+		DBPath: database.DBPath,
+		Trees:  backupTrees(lg),
+		Force:  *force,
+	}))
+	lg.Info("Restored backup", "backup", dir)
+}
@@ -2,7 +2,11 @@ package servermain
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 
 	"capnproto.org/go/capnp/v3"
@@ -14,13 +18,34 @@ import (
 	"golang.org/x/exp/slog"
 	"sandstorm.org/go/tempest/capnp/external"
 	"sandstorm.org/go/tempest/capnp/grain"
+	hacksession "sandstorm.org/go/tempest/capnp/hack-session"
+	"sandstorm.org/go/tempest/capnp/ip"
 	websession "sandstorm.org/go/tempest/capnp/web-session"
 	"sandstorm.org/go/tempest/internal/capnp/system"
+	"sandstorm.org/go/tempest/internal/common/apierror"
 	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/config"
+	"sandstorm.org/go/tempest/internal/server/apiversion"
+	"sandstorm.org/go/tempest/internal/server/appindex"
+	"sandstorm.org/go/tempest/internal/server/blobstore"
 	"sandstorm.org/go/tempest/internal/server/container"
 	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/egress"
 	"sandstorm.org/go/tempest/internal/server/embed"
+	"sandstorm.org/go/tempest/internal/server/events"
+	"sandstorm.org/go/tempest/internal/server/forwarded"
+	"sandstorm.org/go/tempest/internal/server/grainlog"
+	"sandstorm.org/go/tempest/internal/server/logging"
+	"sandstorm.org/go/tempest/internal/server/loginlimit"
+	"sandstorm.org/go/tempest/internal/server/mailer"
+	"sandstorm.org/go/tempest/internal/server/quota"
+	"sandstorm.org/go/tempest/internal/server/replication"
+	"sandstorm.org/go/tempest/internal/server/reqlimit"
+	"sandstorm.org/go/tempest/internal/server/saml"
+	"sandstorm.org/go/tempest/internal/server/scheduler"
 	"sandstorm.org/go/tempest/internal/server/session"
+	"sandstorm.org/go/tempest/internal/server/thumbnail"
+	"sandstorm.org/go/tempest/internal/server/turn"
 	"zenhack.net/go/util/orerr"
 	"zenhack.net/go/util/sync/mutex"
 	"zenhack.net/go/util/thunk"
@@ -33,17 +58,84 @@ type webSessionParams struct {
 	AcceptableLanguages []string
 }
 
+// grainLocaleCookieName is the cookie on a grain's domain which holds the
+// locale of the shell the grain was opened from.
+const grainLocaleCookieName = "sandstorm-grain-locale"
+
 func (p *webSessionParams) FromRequest(req *http.Request) {
-	p.BasePath = "http"
-	if req.TLS != nil {
-		p.BasePath += "s"
-	}
-	p.BasePath += "://" + req.Host
+	p.BasePath = req.URL.Scheme + "://" + req.Host
 	p.UserAgent = req.Header.Get("User-Agent")
-	p.AcceptableLanguages = strings.Split(
-		req.Header.Get("Accept-Language"),
-		",",
-	)
+	p.AcceptableLanguages = parseAcceptLanguage(req.Header.Get("Accept-Language"))
+	// The shell's locale comes first, so that the grain matches the rest
+	// of the UI even if the user chose it rather than the browser.
+	if cookie, err := req.Cookie(grainLocaleCookieName); err == nil && isLanguageTag(cookie.Value) {
+		languages := []string{cookie.Value}
+		for _, language := range p.AcceptableLanguages {
+			if !strings.EqualFold(language, cookie.Value) {
+				languages = append(languages, language)
+			}
+		}
+		p.AcceptableLanguages = languages
+	}
+}
+
+// parseAcceptLanguage returns the language tags in an Accept-Language header,
+// most preferred first. Tags with a quality of zero, the "*" wildcard and
+// malformed entries are left out.
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag     string
+		quality float64
+	}
+	var entries []weighted
+	for _, entry := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(entry, ";")
+		tag = strings.TrimSpace(tag)
+		if !isLanguageTag(tag) {
+			continue
+		}
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			q, err := strconv.ParseFloat(value, 64)
+			if err != nil || q < 0 || q > 1 {
+				continue
+			}
+			quality = q
+		}
+		if quality > 0 {
+			entries = append(entries, weighted{tag, quality})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].quality > entries[j].quality
+	})
+	tags := make([]string, len(entries))
+	for i, e := range entries {
+		tags[i] = e.tag
+	}
+	return tags
+}
+
+// isLanguageTag reports whether tag looks like a BCP 47 language tag: 1 to 8
+// letters, followed by subtags of 1 to 8 letters or digits, separated by
+// hyphens. It doesn't check that the subtags are registered.
+func isLanguageTag(tag string) bool {
+	if tag == "" || len(tag) > 35 {
+		return false
+	}
+	for i, subtag := range strings.Split(tag, "-") {
+		if len(subtag) == 0 || len(subtag) > 8 {
+			return false
+		}
+		for _, c := range subtag {
+			isLetter := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+			isDigit := c >= '0' && c <= '9'
+			if !isLetter && !(isDigit && i > 0) {
+				return false
+			}
+		}
+	}
+	return true
 }
 
 func (p *webSessionParams) Insert(into websession.Params) error {
@@ -52,11 +144,30 @@ func (p *webSessionParams) Insert(into websession.Params) error {
 
 // A server encapsulates the state of a running server.
 type server struct {
-	cfg          Config
-	log          *slog.Logger
-	db           database.DB
-	sessionStore session.Store
-	state        mutex.Mutex[serverState]
+	cfg           Config
+	log           *slog.Logger
+	db            database.DB
+	sessionStore  session.Store
+	replication   *replication.Primary
+	apiUsage      *apiversion.Recorder
+	thumbnails    *thumbnail.Service
+	turn          *turn.Service
+	loginMailer   mailer.Sender // Rate limited, for login tokens.
+	events        *events.Bus
+	appIndex      *appindex.Index       // nil if disabled
+	saml          *saml.ServiceProvider // nil if disabled
+	jobs          *scheduler.Runner
+	notifications *notificationHub
+	quota         *quota.Tracker
+	loginLimit    *loginlimit.Limiter
+	network       ip.IpNetwork    // Granted to grains through the powerbox.
+	ipInterface   ip.IpInterface  // Granted to grains through the powerbox.
+	sandboxes     *container.Pool // nil if disabled
+	blobs         blobstore.Store // Packages' spks and grains' backups.
+	grainLogs     *grainlog.Logs
+	grainRequests *reqlimit.Counter // HTTP requests in progress, by grain.
+	assets        *staticAssets
+	state         mutex.Mutex[serverState]
 }
 
 // Server state that requires synchronization when accessed by multiple goroutines;
@@ -64,21 +175,98 @@ type server struct {
 type serverState struct {
 	grainSessions map[grainSessionKey]grainSession
 	containers    ContainerSet
+
+	// Pages showing new API tokens, by ID; see api-tokens.go.
+	offers map[string]renderedOffer
+
+	// Directories being served by `tempest dev`; see dev.go.
+	devDirs map[string]bool
+
+	// The token which makes whoever claims it an admin, or "" if there
+	// is none; see setup.go.
+	setupToken string
+
+	// How the maintenance tasks' runs went, by task name; see
+	// maintenance.go.
+	maintenance map[string]maintenanceStats
 }
 
 func newServer(cfg Config, lg *slog.Logger, db database.DB, sessionStore session.Store) *server {
-	return &server{
+	apiUsage := &apiversion.Recorder{
+		Log: lg,
+		Save: func(grainID types.GrainID, m apiversion.MethodInfo) error {
+			tx, err := db.Begin()
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback()
+			if err = tx.RecordDeprecatedAPIUse(grainID, m.Interface, m.Method); err != nil {
+				return err
+			}
+			return tx.Commit()
+		},
+	}
+	s := &server{
 		cfg:          cfg,
 		log:          lg,
 		db:           db,
 		sessionStore: sessionStore,
+		replication: &replication.Primary{
+			Log:       lg,
+			Secret:    cfg.Replication.Secret,
+			GrainsDir: config.GrainsDir,
+			WAL:       db.WAL(),
+		},
+		apiUsage:      apiUsage,
+		thumbnails:    newThumbnailService(cfg.Thumbnail),
+		turn:          turn.NewService(cfg.TURN),
+		loginMailer:   mailer.Limit(cfg.Email.NewSender(lg), cfg.Email.LoginRateLimit),
+		events:        &events.Bus{},
+		notifications: &notificationHub{},
+		quota:         quota.NewTracker(lg, config.GrainsDir, cfg.Quota.ScanInterval),
+		grainLogs:     grainlog.New(config.GrainsDir, cfg.GrainLogMaxSize),
+		grainRequests: reqlimit.NewCounter(cfg.Requests.MaxGrainRequests),
+		loginLimit:    loginlimit.New(cfg.LoginLimit),
+		network:       egress.New(lg),
+		ipInterface:   egress.NewInterface(lg),
 		state: mutex.New[serverState](serverState{
 			containers: ContainerSet{
 				containersByGrainID: make(map[types.GrainID]container.Container),
+				crashed:             make(map[types.GrainID]bool),
+				activity:            make(map[types.GrainID]*grainActivity),
+				stopping:            make(map[types.GrainID]<-chan struct{}),
+				apiUsage:            apiUsage,
 			},
 			grainSessions: make(map[grainSessionKey]grainSession),
+			offers:        make(map[string]renderedOffer),
+			devDirs:       make(map[string]bool),
+			maintenance:   make(map[string]maintenanceStats),
 		}),
 	}
+	blobs, err := cfg.Blobs.Open()
+	if err != nil {
+		logging.Panic(lg, "opening blob storage", "error", err)
+	}
+	s.blobs = blobs
+	s.assets, err = loadStaticAssets(embed.Content)
+	if err != nil {
+		logging.Panic(lg, "loading static assets", "error", err)
+	}
+	if cfg.SandboxPoolSize > 0 {
+		s.sandboxes = container.NewPool(lg, cfg.SandboxPoolSize)
+	}
+	if cfg.AppIndexURL != "" {
+		s.appIndex = appindex.New(cfg.AppIndexURL)
+	}
+	if cfg.SAML.Enabled() {
+		s.saml = saml.NewServiceProvider(cfg.SAML)
+	}
+	s.jobs = s.newScheduler()
+	s.state.With(func(state *serverState) {
+		state.containers.server = s
+	})
+	s.events.Subscribe(s.recordGrainEvent)
+	return s
 }
 
 type grainSessionKey struct {
@@ -89,6 +277,29 @@ type grainSessionKey struct {
 	basePath            string
 	userAgent           string
 	acceptableLanguages string
+
+	// For API sessions, the SHA-256 hash of the API token; see getAPISession.
+	apiTokenHash string
+
+	// The session's permissions, so that it isn't used once they change;
+	// see permissionsKey.
+	permissions string
+}
+
+// permissionsKey formats the permissions of a web session for its
+// grainSessionKey. nil, meaning all permissions, is distinct from none.
+func permissionsKey(permissions []bool) string {
+	if permissions == nil {
+		return "*"
+	}
+	buf := make([]byte, len(permissions))
+	for i, p := range permissions {
+		buf[i] = 'f'
+		if p {
+			buf[i] = 't'
+		}
+	}
+	return string(buf)
 }
 
 type grainSession struct {
@@ -102,8 +313,13 @@ func (s grainSession) Release() {
 	}
 }
 
+// Handler returns the handler for all of our HTTP requests. Requests' URLs
+// have their Scheme set, taking reverse proxies into account; see
+// forwarded.Handler.
 func (s *server) Handler() http.Handler {
 	r := mux.NewRouter()
+	r.Use(s.tagRequest)
+	r.Use(s.checkSameOrigin)
 
 	if s.cfg.HTTP.DefaultTLS {
 		r.Schemes("http").
@@ -121,28 +337,46 @@ func (s *server) Handler() http.Handler {
 			query := req.URL.Query()
 			querySid := query.Has("sandstorm-sid")
 			readCookieErr := session.ReadCookie(s.sessionStore, req, &sess)
+			if readCookieErr == nil {
+				req = tagGrainSession(req, sess)
+			}
 
 			switch {
+			case req.URL.Path == selfTestPath:
+				serveSelfTest(w, req)
 			case querySid && req.URL.Path == "/_sandstorm-init":
 				// Transfer the token from query params to cookie:
 				err := sess.Unseal(s.sessionStore, session.Payload{
 					CookieName: sess.CookieName(),
 					Data:       query.Get("sandstorm-sid"),
 				})
+				if err == nil {
+					err = s.sessionStore.Check(&sess)
+				}
 				if err != nil {
 					w.WriteHeader(http.StatusUnauthorized)
-					s.log.Debug("Access to grain UI denied.",
+					s.log.DebugCtx(req.Context(), "Access to grain UI denied.",
 						"error", err,
 						"reason", "unsealing sandstorm-sid failed",
 					)
 				}
-				session.WriteCookie(s.sessionStore, req, w, sess)
+				if _, ok := embedIDFromSession(sess); ok {
+					s.writeEmbedCookie(w, req, sess)
+				} else {
+					session.WriteCookie(s.sessionStore, req, w, sess)
+				}
+				if lang := query.Get("lang"); isLanguageTag(lang) {
+					http.SetCookie(w, session.Payload{
+						CookieName: grainLocaleCookieName,
+						Data:       lang,
+					}.ToCookie(req.URL.Scheme == "https"))
+				}
 				http.Redirect(w, req, query.Get("path"), http.StatusSeeOther)
 				// TODO(perf): when doing the redirect,
 				// Use http/2 push to avoid a round trip.
 			case querySid:
 				w.WriteHeader(http.StatusUnauthorized)
-				s.log.Debug("Access to grain UI denied",
+				s.log.DebugCtx(req.Context(), "Access to grain UI denied",
 					"url path", req.URL.Path,
 					"reason", []string{
 						"sandstorm-sid query parameter is present",
@@ -151,7 +385,7 @@ func (s *server) Handler() http.Handler {
 				)
 			case readCookieErr != nil:
 				w.WriteHeader(http.StatusUnauthorized)
-				s.log.Debug("Access to grain UI denied",
+				s.log.DebugCtx(req.Context(), "Access to grain UI denied",
 					"error", readCookieErr,
 					"url", req.URL,
 					"reason", []string{
@@ -160,12 +394,56 @@ func (s *server) Handler() http.Handler {
 					},
 				)
 			default:
+				// Sessions opened through an embed only have the
+				// embed's permissions, may only be framed by the
+				// embedding sites, and end when the embed does.
+				var (
+					permissions    []bool
+					frameAncestors []string
+				)
+				if embedID, ok := embedIDFromSession(sess); ok {
+					grainEmbed, err := s.validEmbed(embedID)
+					if err != nil || grainEmbed.GrainID != sess.GrainID {
+						w.WriteHeader(http.StatusUnauthorized)
+						s.log.DebugCtx(req.Context(), "Access to grain UI denied",
+							"error", err,
+							"embedID", embedID,
+							"reason", "embed deleted or expired",
+						)
+						return
+					}
+					permissions = grainEmbed.Permissions
+					frameAncestors = grainEmbed.FrameAncestors
+				} else {
+					var err error
+					permissions, err = s.grainSessionPermissions(sess)
+					if errors.Is(err, sql.ErrNoRows) {
+						w.WriteHeader(http.StatusForbidden)
+						s.log.DebugCtx(req.Context(), "Access to grain UI denied",
+							"error", err,
+							"reason", "user has no access to the grain",
+						)
+						return
+					} else if err != nil {
+						w.WriteHeader(http.StatusInternalServerError)
+						s.log.ErrorCtx(req.Context(), "Checking grain permissions",
+							"error", err,
+							"grainID", sess.GrainID,
+						)
+						return
+					}
+				}
 				var wsp webSessionParams
 				wsp.FromRequest(req)
-				session, err := s.getWebSession(req.Context(), wsp, sess)
+				endRequest, ok := s.beginGrainHTTPRequest(w, req, sess.GrainID)
+				if !ok {
+					return
+				}
+				defer endRequest()
+				session, err := s.getWebSession(req.Context(), wsp, sess, permissions)
 				if err != nil {
 					w.WriteHeader(http.StatusInternalServerError)
-					s.log.Error(
+					s.log.ErrorCtx(req.Context(),
 						"Could not get web session reference",
 						"error", err,
 						"grainID", sess.GrainID,
@@ -174,10 +452,13 @@ func (s *server) Handler() http.Handler {
 					return
 				}
 				defer session.Release()
-				ServeApp(session, w, req, s.cfg.HTTP.RootDomain)
+				ServeApp(session, w, req, s.cfg.HTTP.RootDomain, frameAncestors)
 			}
 		})
 
+	r.Host(apiHostPrefix + s.cfg.HTTP.RootDomain).
+		HandlerFunc(s.serveAPI)
+
 	r.Host(s.cfg.HTTP.RootDomain).Path("/login/dev").Methods("GET").
 		HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 			w.Write([]byte(`<!doctype html>
@@ -198,12 +479,14 @@ func (s *server) Handler() http.Handler {
 
 	r.Host(s.cfg.HTTP.RootDomain).Path("/login/dev").Methods("POST").
 		HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-			var sess session.UserSession
-			sess.Credential.Type = "dev"
-			sess.Credential.ScopedID = req.FormValue("name")
-			sess.SessionID = session.GenSessionID()
-			session.WriteCookie(s.sessionStore, req, w, sess)
-			http.Redirect(w, req, "/", http.StatusSeeOther)
+			cred := types.Credential{
+				Type:     types.DevCredential,
+				ScopedID: req.FormValue("name"),
+			}
+			if !s.allowLogin(w, req, loginKeys(clientIP(req), cred)...) {
+				return
+			}
+			s.logIn(w, req, cred)
 			// TODO:
 			// - Check if the credential is already linked to
 			//   an account.
@@ -211,13 +494,54 @@ func (s *server) Handler() http.Handler {
 			//   - If not, create one.
 		})
 
-	r.Host(s.cfg.HTTP.RootDomain).Path("/login/email/{token}").
+	r.Host(s.cfg.HTTP.RootDomain).Path("/logout").Methods("POST").
+		HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			var sess session.UserSession
+			err := session.ReadCookie(s.sessionStore, req, &sess)
+			if err == nil && len(sess.SessionID) > 0 {
+				// Revoke the session, rather than just clearing the
+				// cookie, so that copies of the cookie, and grain
+				// sessions derived from it, stop working too.
+				if err = s.sessionStore.Revoke(sess.SessionID); err != nil {
+					s.log.ErrorCtx(req.Context(), "revoking session on logout",
+						"error", err)
+				} else {
+					s.forgetUserSession(req.Context(), sess.SessionID)
+				}
+			}
+			cookie := session.Payload{CookieName: sess.CookieName()}.
+				ToCookie(req.URL.Scheme == "https")
+			cookie.MaxAge = -1
+			http.SetCookie(w, cookie)
+			http.Redirect(w, req, "/", http.StatusSeeOther)
+		})
+
+	r.Host(s.cfg.HTTP.RootDomain).Path("/invite/{token}").Methods("GET").
+		HandlerFunc(s.serveInvite)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/setup/{token}").Methods("GET").
+		HandlerFunc(s.serveSetupLink)
+
+	r.Host(s.cfg.HTTP.RootDomain).Path("/demo").Methods("GET").
+		HandlerFunc(s.serveDemo)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/demo").Methods("POST").
+		HandlerFunc(s.serveNewDemoAccount)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/demo-account").Methods("GET").
+		HandlerFunc(s.serveDemoAccount)
+
+	r.Host(s.cfg.HTTP.RootDomain).Path("/login/email/{token}").Methods("GET", "POST").
 		HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if s.serveEmailLoginConfirm(w, req) {
+				return
+			}
 			token := mux.Vars(req)["token"]
+			ip := clientIP(req)
+			if !s.allowLogin(w, req, loginKeys(ip, types.Credential{})...) {
+				return
+			}
 			tx, err := s.db.Begin()
 			if err != nil {
 				w.WriteHeader(http.StatusInternalServerError)
-				s.log.Error("failed to open database transaction",
+				s.log.ErrorCtx(req.Context(), "failed to open database transaction",
 					"error", err)
 				return
 			}
@@ -229,60 +553,71 @@ func (s *server) Handler() http.Handler {
 			}
 			ref, err := tx.RestoreSturdyRef(key)
 			if err != nil {
-				w.WriteHeader(http.StatusBadRequest)
-				w.Write([]byte("No such token (maybe expired?)"))
-				s.log.Debug("failed to restore token",
+				s.writeLocalizedError(w, req, http.StatusBadRequest, apierror.New(
+					apierror.CodeNotFound, "no such login token", "login token"))
+				s.log.DebugCtx(req.Context(), "failed to restore token",
 					"error", err,
 				)
+				s.loginFailed(req.Context(), ip, types.Credential{},
+					"invalid or expired login token")
 				return
 			}
 			if err = tx.DeleteSturdyRef(key); err != nil {
 				w.WriteHeader(http.StatusInternalServerError)
-				s.log.Error("deleting sturdyref",
+				s.log.ErrorCtx(req.Context(), "deleting sturdyref",
 					"error", err)
 				return
 			}
 			if err = tx.Commit(); err != nil {
 				w.WriteHeader(http.StatusInternalServerError)
-				s.log.Error("restoring email token: commit",
+				s.log.ErrorCtx(req.Context(), "restoring email token: commit",
 					"error", err)
 				return
 			}
 			oid := system.SystemObjectId(ref.ObjectID)
 			if oid.Which() != system.SystemObjectId_Which_emailLoginToken {
-				w.WriteHeader(http.StatusBadRequest)
-				w.Write([]byte("token has the wrong type"))
+				s.writeLocalizedError(w, req, http.StatusBadRequest, apierror.New(
+					apierror.CodeInvalidArgument, "login token has the wrong type", "login token"))
+				s.loginFailed(req.Context(), ip, types.Credential{},
+					"login token has the wrong type")
 				return
 			}
 			addr, err := oid.EmailLoginToken()
 			if err != nil {
 				w.WriteHeader(http.StatusInternalServerError)
-				s.log.Error("reading email address from token",
+				s.log.ErrorCtx(req.Context(), "reading email address from token",
 					"error", err)
 				return
 			}
 
-			sess := session.UserSession{
-				SessionID: session.GenSessionID(),
-				Credential: types.Credential{
-					Type:     types.EmailCredential,
-					ScopedID: addr,
-				},
-			}
-			session.WriteCookie(s.sessionStore, req, w, sess)
-			http.Redirect(w, req, "/", http.StatusSeeOther)
+			s.logIn(w, req, types.Credential{
+				Type:     types.EmailCredential,
+				ScopedID: addr,
+			})
 		})
 
+	if s.saml != nil {
+		r.Host(s.cfg.HTTP.RootDomain).Path(samlMetadataPath).Methods("GET").
+			HandlerFunc(s.serveSAMLMetadata)
+		r.Host(s.cfg.HTTP.RootDomain).Path("/login/saml").Methods("GET").
+			HandlerFunc(s.serveSAMLLogin)
+		r.Host(s.cfg.HTTP.RootDomain).Path(samlACSPath).Methods("POST").
+			HandlerFunc(s.serveSAMLACS)
+	}
+
 	r.Host(s.cfg.HTTP.RootDomain).Path("/_capnp-api").
 		HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 			var sess session.UserSession
 			err := session.ReadCookie(s.sessionStore, req, &sess)
 			if err != nil {
-				s.log.Debug("Failed to read session cookie; treating as anonymous",
+				s.log.DebugCtx(req.Context(), "Failed to read session cookie; treating as anonymous",
 					"error", err,
 				)
 				// Don't rely on ReadCookie leaving the zero value in place:
 				sess = session.UserSession{}
+			} else {
+				s.rememberLocale(req.Context(), req, sess)
+				s.touchUserSession(req, sess)
 			}
 			codec, err := websocketcapnp.UpgradeHTTP(
 				ws.HTTPUpgrader{
@@ -291,7 +626,7 @@ func (s *server) Handler() http.Handler {
 					},
 				}, req, w)
 			if err != nil {
-				s.log.Error("Failed to upgrade http connection",
+				s.log.ErrorCtx(req.Context(), "Failed to upgrade http connection",
 					"error", err)
 				return
 			}
@@ -301,6 +636,7 @@ func (s *server) Handler() http.Handler {
 				server:       s,
 				userSession:  sess,
 				sessionStore: s.sessionStore,
+				remoteAddr:   clientIP(req),
 			}
 			rpcConn := rpc.NewConn(transport, &rpc.Options{
 				BootstrapClient: capnp.Client(external.ExternalApi_ServerToClient(bootstrap)),
@@ -309,12 +645,138 @@ func (s *server) Handler() http.Handler {
 			<-rpcConn.Done()
 		})
 
+	r.Host(s.cfg.HTTP.RootDomain).Path("/grain-timeline/{grainID}").Methods("GET").
+		HandlerFunc(s.serveGrainTimeline)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/grain-backup/{grainID}").Methods("GET").
+		HandlerFunc(s.serveGrainBackup)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/grain-backup").Methods("POST").
+		HandlerFunc(s.serveRestoreGrainBackup)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/grain-log/{grainID}").Methods("GET").
+		HandlerFunc(s.serveGrainLog)
+
+	r.Host(s.cfg.HTTP.RootDomain).Path("/grain-embeds/{grainID}").Methods("GET", "POST").
+		HandlerFunc(s.serveGrainEmbeds)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/grain-embeds/{grainID}/{embedID}").Methods("DELETE").
+		HandlerFunc(s.serveGrainEmbeds)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/embed/{embedID}").Methods("GET").
+		HandlerFunc(s.serveEmbed)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/grain-api-tokens/{grainID}").Methods("GET", "POST").
+		HandlerFunc(s.serveGrainAPITokens)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/grain-api-tokens/{grainID}/{tokenID}").Methods("DELETE").
+		HandlerFunc(s.serveGrainAPITokens)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/offer-template/{offerID}").Methods("GET").
+		HandlerFunc(s.serveOfferTemplate)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/api-keys").Methods("GET", "POST").
+		HandlerFunc(s.serveAPIKeys)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/api-keys/{keyID}").Methods("DELETE").
+		HandlerFunc(s.serveAPIKeys)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/grain-share-links/{grainID}").Methods("GET", "POST").
+		HandlerFunc(s.serveGrainShareLinks)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/grain-share-links/{grainID}/{linkID}").Methods("DELETE").
+		HandlerFunc(s.serveGrainShareLinks)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/grain-members/{grainID}").Methods("GET").
+		HandlerFunc(s.serveGrainMembers)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/grain-members/{grainID}/{accountID}").Methods("DELETE").
+		HandlerFunc(s.serveGrainMembers)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/grain-share-graph/{grainID}").Methods("GET").
+		HandlerFunc(s.serveGrainShareGraph)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/grain-upgrades").Methods("GET", "POST").
+		HandlerFunc(s.serveGrainUpgrades)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/grain-upgrades/{grainID}").Methods("POST").
+		HandlerFunc(s.serveGrainUpgrades)
+
+	r.Host(s.cfg.HTTP.RootDomain).Path("/unsupported-browser").Methods("GET").
+		HandlerFunc(s.serveUnsupportedBrowser)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/basic").Methods("GET").
+		HandlerFunc(s.serveBasic)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/basic/grain/{grainID}").Methods("GET").
+		HandlerFunc(s.serveBasicGrain)
+
+	r.Host(s.cfg.HTTP.RootDomain).Path("/thumbnail").Methods("POST").
+		HandlerFunc(s.serveThumbnail)
+
+	r.Host(s.cfg.HTTP.RootDomain).Path("/identicon/{identityID}.svg").Methods("GET").
+		HandlerFunc(s.serveIdenticon)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/powerbox/requests").Methods("POST").
+		HandlerFunc(s.servePowerboxRequests)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/powerbox/requests/{requestID}").Methods("POST", "DELETE").
+		HandlerFunc(s.servePowerboxRequest)
+
+	r.Host(s.cfg.HTTP.RootDomain).Path("/app-index").Methods("GET").
+		HandlerFunc(s.serveAppIndex)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/app-index/install/{appID}").Methods("POST").
+		HandlerFunc(s.serveInstallFromAppIndex)
+
+	r.Host(s.cfg.HTTP.RootDomain).Path("/admin/").Methods("GET").
+		HandlerFunc(s.serveAdminIndex)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/admin").Methods("GET").
+		Handler(http.RedirectHandler("/admin/", http.StatusMovedPermanently))
+	r.Host(s.cfg.HTTP.RootDomain).Path("/admin/accounts").Methods("GET").
+		HandlerFunc(s.serveAdminAccounts)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/admin/accounts/{accountID}/role").Methods("POST").
+		HandlerFunc(s.serveSetAccountRole)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/admin/accounts/{accountID}/deactivated").Methods("POST").
+		HandlerFunc(s.serveSetAccountDeactivated)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/admin/users").Methods("GET").
+		HandlerFunc(s.serveAdminUsers)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/admin/users/scopes").Methods("POST").
+		HandlerFunc(s.serveSetAdminScopes)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/admin/users/{accountID}/scopes").Methods("POST").
+		HandlerFunc(s.serveSetAdminScopes)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/admin/invites").Methods("GET").
+		HandlerFunc(s.serveAdminInvites)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/admin/invites").Methods("POST").
+		HandlerFunc(s.serveCreateInvite)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/admin/invites/{inviteID}/delete").Methods("POST").
+		HandlerFunc(s.serveDeleteInvite)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/admin/setup").Methods("GET").
+		HandlerFunc(s.serveSetup)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/admin/setup").Methods("POST").
+		HandlerFunc(s.serveSaveSetup)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/admin/grain-starts").Methods("GET").
+		HandlerFunc(s.serveGrainStarts)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/admin/grain-limits").Methods("GET").
+		HandlerFunc(s.serveGrainLimits)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/admin/grain-limits").Methods("POST").
+		HandlerFunc(s.serveSetGrainLimits)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/admin/deprecated-apis").Methods("GET").
+		HandlerFunc(s.serveDeprecatedAPIs)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/admin/metrics").Methods("GET").
+		HandlerFunc(s.serveMetrics)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/admin/dead-letters").Methods("GET").
+		HandlerFunc(s.serveDeadLetters)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/admin/dead-letters/{id}/retry").Methods("POST").
+		HandlerFunc(s.serveRetryDeadLetter)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/admin/dead-letters/{id}/discard").Methods("POST").
+		HandlerFunc(s.serveDiscardDeadLetter)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/admin/login-audit").Methods("GET").
+		HandlerFunc(s.serveLoginAudit)
+
+	if s.replication.WAL != nil {
+		r.Host(s.cfg.HTTP.RootDomain).PathPrefix(replication.PathPrefix + "/").
+			Handler(s.replication)
+	}
+
+	r.Host(s.cfg.HTTP.RootDomain).Path("/").Methods("GET", "HEAD").
+		HandlerFunc(s.assets.serveIndex)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/index.html").Methods("GET", "HEAD").
+		HandlerFunc(s.assets.serveIndex)
+	r.Host(s.cfg.HTTP.RootDomain).Path("/assets/{name}").Methods("GET", "HEAD").
+		HandlerFunc(s.assets.serveHashed)
 	r.Host(s.cfg.HTTP.RootDomain).Handler(http.FileServer(http.FS(embed.Content)))
 
-	return r
+	// Limits and headers depend on the host, so are applied after the
+	// host is taken from the proxy, if any.
+	return forwarded.Handler(s.cfg.HTTP.TrustedProxies,
+		s.cfg.Requests.Handler(
+			s.cfg.Headers.Handler(s.renewUserSessions(r), s.hostKind),
+			s.hostKind))
 }
 
-func (s *server) getWebSession(ctx context.Context, wsp webSessionParams, sess session.GrainSession) (websession.WebSession, error) {
+// getWebSession returns a web session for the grain session, starting the
+// grain if need be. permissions restricts the session to a subset of the
+// grain's permissions; if it is nil, the session has all of them.
+func (s *server) getWebSession(ctx context.Context, wsp webSessionParams, sess session.GrainSession, permissions []bool) (websession.WebSession, error) {
 
 	key := grainSessionKey{
 		userSessionID: string(sess.SessionID),
@@ -323,20 +785,39 @@ func (s *server) getWebSession(ctx context.Context, wsp webSessionParams, sess s
 		basePath:            wsp.BasePath,
 		userAgent:           wsp.UserAgent,
 		acceptableLanguages: strings.Join(wsp.AcceptableLanguages, ","),
+
+		permissions: permissionsKey(permissions),
 	}
+	var exiting <-chan struct{}
 	webSessionThunk := mutex.With1(&s.state, func(state *serverState) *thunk.Thunk[orerr.OrErr[websession.WebSession]] {
 		gs, ok := state.grainSessions[key]
 		if ok {
 			return gs.webSession
 		}
-		c, err := state.containers.Get(context.Background(), s.log, s.db, sess.GrainID)
+		c, started, exited, err := state.containers.Get(context.Background(), s.log, s.db, sess.GrainID)
 		if err != nil {
 			return thunk.Ready(orerr.New(websession.WebSession{}, err))
 		}
+		if exited != nil {
+			exiting = exited
+			return nil
+		}
+		startedKind := types.GrainStarted
+		if started {
+			if state.containers.crashed[sess.GrainID] {
+				startedKind = types.GrainRestarted
+				delete(state.containers.crashed, sess.GrainID)
+			}
+			go s.watchContainer(sess.GrainID, c)
+		}
 		webSessionThunk := thunk.Go(func() orerr.OrErr[websession.WebSession] {
 			mainView := grain.MainView(c.Bootstrap.AddRef())
 			defer mainView.Release()
-			sessionCtx := grain.SessionContext_ServerToClient(sessionCtxImpl{})
+			sessionCtx := grain.SessionContext(hacksession.HackSessionContext_ServerToClient(sessionCtxImpl{
+				server:   s,
+				grainID:  sess.GrainID,
+				apiUsage: s.apiUsage,
+			}))
 			// TODO: we shouldn't need to do this for every session we get, only on
 			// grain boot.
 			viewInfoFut, rel := mainView.GetViewInfo(ctx, nil)
@@ -354,9 +835,26 @@ func (s *server) getWebSession(ctx context.Context, wsp webSessionParams, sess s
 			if err = tx.SetGrainViewInfo(string(sess.GrainID), viewInfo); err != nil {
 				return orerr.New(websession.WebSession{}, err)
 			}
+			var user database.Contact // Zero for anonymous users.
+			if sess.AccountID != "" {
+				user, err = tx.AccountContact(sess.AccountID)
+				if err != nil {
+					return orerr.New(websession.WebSession{}, err)
+				}
+			}
+			if started {
+				// This is the app's first response since the grain started.
+				s.recordGrainStart(tx, sess.GrainID, c)
+			}
 			if err = tx.Commit(); err != nil {
 				return orerr.New(websession.WebSession{}, err)
 			}
+			if started {
+				s.events.Publish(types.GrainEvent{
+					GrainID: sess.GrainID,
+					Kind:    startedKind,
+				})
+			}
 
 			viewInfoPermissions, err := viewInfo.Permissions()
 			if err != nil {
@@ -371,15 +869,20 @@ func (s *server) getWebSession(ctx context.Context, wsp webSessionParams, sess s
 						return err
 					}
 
-					// For now, just give the user all permissions.
-					// we'll store & retrieve this info properly
-					// later on.
-					permissions, err := userInfo.NewPermissions(int32(viewInfoPermissions.Len()))
+					// nil means all permissions, e.g. for the
+					// grain's owner.
+					userPermissions, err := userInfo.NewPermissions(int32(viewInfoPermissions.Len()))
 					if err != nil {
 						return err
 					}
-					for i := 0; i < permissions.Len(); i++ {
-						permissions.Set(i, true)
+					for i := 0; i < userPermissions.Len(); i++ {
+						userPermissions.Set(i, permissions == nil ||
+							i < len(permissions) && permissions[i])
+					}
+					if user.AccountID != "" {
+						if err := s.setUserInfoIdentity(userInfo, user); err != nil {
+							return err
+						}
 					}
 
 					p.SetSessionType(websession.WebSession_TypeID)
@@ -408,6 +911,16 @@ func (s *server) getWebSession(ctx context.Context, wsp webSessionParams, sess s
 		}
 		return webSessionThunk
 	})
+	if exiting != nil {
+		// The grain's last container is still exiting; wait for it
+		// outside of the lock, and try again.
+		select {
+		case <-exiting:
+			return s.getWebSession(ctx, wsp, sess, permissions)
+		case <-ctx.Done():
+			return websession.WebSession{}, ctx.Err()
+		}
+	}
 	webSession, err := webSessionThunk.Force().Get()
 	return webSession.AddRef(), err
 }
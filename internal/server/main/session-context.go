@@ -2,45 +2,180 @@ package servermain
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 
 	"capnproto.org/go/capnp/v3/exc"
+	"sandstorm.org/go/tempest/capnp/email"
 	"sandstorm.org/go/tempest/capnp/grain"
+	hacksession "sandstorm.org/go/tempest/capnp/hack-session"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/apiversion"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"zenhack.net/go/util/exn"
 )
 
-type sessionCtxImpl struct{}
+type sessionCtxImpl struct {
+	server   *server
+	grainID  types.GrainID
+	apiUsage *apiversion.Recorder
+}
 
-func (sessionCtxImpl) GetSharedPermissions(context.Context, grain.SessionContext_getSharedPermissions) error {
-	return exc.New(exc.Unimplemented, "sessionCtxImpl", "TODO")
+// use records a call to the named method; see apiversion.Recorder.Record.
+func (sc sessionCtxImpl) use(method string) {
+	sc.apiUsage.Record(sc.grainID, "SessionContext", method)
 }
 
-func (sessionCtxImpl) TieToUser(context.Context, grain.SessionContext_tieToUser) error {
+func (sc sessionCtxImpl) GetSharedPermissions(context.Context, grain.SessionContext_getSharedPermissions) error {
+	sc.use("getSharedPermissions")
 	return exc.New(exc.Unimplemented, "sessionCtxImpl", "TODO")
 }
 
-func (sessionCtxImpl) Offer(context.Context, grain.SessionContext_offer) error {
+func (sc sessionCtxImpl) TieToUser(context.Context, grain.SessionContext_tieToUser) error {
+	sc.use("tieToUser")
 	return exc.New(exc.Unimplemented, "sessionCtxImpl", "TODO")
 }
 
-func (sessionCtxImpl) Request(context.Context, grain.SessionContext_request) error {
+func (sc sessionCtxImpl) Offer(context.Context, grain.SessionContext_offer) error {
+	sc.use("offer")
 	return exc.New(exc.Unimplemented, "sessionCtxImpl", "TODO")
 }
 
-func (sessionCtxImpl) ClaimRequest(context.Context, grain.SessionContext_claimRequest) error {
+func (sc sessionCtxImpl) Request(context.Context, grain.SessionContext_request) error {
+	sc.use("request")
+	// As in Sandstorm; see the comment on request in grain.capnp.
+	return exc.New(exc.Unimplemented, "sessionCtxImpl",
+		"make powerbox requests with the postMessage API, then call claimRequest()")
+}
+
+// ClaimRequest exchanges the token which the browser gave the grain, when the
+// user fulfilled its powerbox request, for the capability they chose. See
+// powerbox.go.
+func (sc sessionCtxImpl) ClaimRequest(ctx context.Context, p grain.SessionContext_claimRequest) error {
+	sc.use("claimRequest")
+	return exn.Try0(func(throw exn.Thrower) {
+		token, err := p.Args().RequestToken()
+		throw(err)
+		required, err := p.Args().RequiredPermissions()
+		throw(err)
+		results, err := p.AllocResults()
+		throw(err)
+		tx, err := sc.server.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		key := database.SturdyRefKey{
+			Token:     []byte(token),
+			OwnerType: powerboxClaimOwnerType,
+			Owner:     types.AccountID(sc.grainID),
+		}
+		grant, err := tx.RestorePowerboxGrant(key)
+		if errors.Is(err, sql.ErrNoRows) {
+			throw(exc.New(exc.Failed, "sessionCtxImpl", "no such request token, or it has expired"))
+		}
+		throw(err)
+		// Tokens may only be claimed once:
+		throw(tx.DeleteSturdyRef(key))
+		grant.RequiredPermissions = bitListToBools(required)
+		c, err := sc.server.powerboxCap(tx, sc.grainID, grant)
+		throw(err)
+		throw(tx.Commit())
+		throw(results.SetCap(c))
+	})
+}
+
+func (sc sessionCtxImpl) FulfillRequest(context.Context, grain.SessionContext_fulfillRequest) error {
+	sc.use("fulfillRequest")
 	return exc.New(exc.Unimplemented, "sessionCtxImpl", "TODO")
 }
 
-func (sessionCtxImpl) FulfillRequest(context.Context, grain.SessionContext_fulfillRequest) error {
+func (sc sessionCtxImpl) Close(context.Context, grain.SessionContext_close) error {
+	sc.use("close")
 	return exc.New(exc.Unimplemented, "sessionCtxImpl", "TODO")
 }
 
-func (sessionCtxImpl) Close(context.Context, grain.SessionContext_close) error {
+func (sc sessionCtxImpl) OpenView(context.Context, grain.SessionContext_openView) error {
+	sc.use("openView")
 	return exc.New(exc.Unimplemented, "sessionCtxImpl", "TODO")
 }
 
-func (sessionCtxImpl) OpenView(context.Context, grain.SessionContext_openView) error {
+// The methods below implement HackSessionContext, which the session context
+// also is, as in Sandstorm; apps check for it by casting.
+
+// useHack records a call to the named method of HackSessionContext.
+func (sc sessionCtxImpl) useHack(method string) {
+	sc.apiUsage.Record(sc.grainID, "HackSessionContext", method)
+}
+
+// GetPublicId returns the grain's public ID, which is the local part of its
+// email address; see email.go.
+func (sc sessionCtxImpl) GetPublicId(ctx context.Context, p hacksession.HackSessionContext_getPublicId) error {
+	sc.useHack("getPublicId")
+	return exn.Try0(func(throw exn.Thrower) {
+		tx, err := sc.server.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		publicID, err := tx.GrainPublicID(sc.grainID)
+		throw(err)
+		throw(tx.Commit())
+		results, err := p.AllocResults()
+		throw(err)
+		throw(results.SetPublicId(publicID))
+		throw(results.SetHostname(sc.server.cfg.Mail.Domain))
+	})
+}
+
+func (sc sessionCtxImpl) GetUserAddress(context.Context, hacksession.HackSessionContext_getUserAddress) error {
+	sc.useHack("getUserAddress")
 	return exc.New(exc.Unimplemented, "sessionCtxImpl", "TODO")
 }
 
-func (sessionCtxImpl) Activity(context.Context, grain.SessionContext_activity) error {
+// Send would send email from the grain's address.
+func (sc sessionCtxImpl) Send(context.Context, email.EmailSendPort_send) error {
+	sc.useHack("send")
 	return exc.New(exc.Unimplemented, "sessionCtxImpl", "TODO")
 }
+
+// HintAddress is optional for EmailSendPorts, and we have no use for the hint.
+func (sc sessionCtxImpl) HintAddress(context.Context, email.EmailSendPort_hintAddress) error {
+	sc.useHack("hintAddress")
+	return nil
+}
+
+// errObsolete is returned by the methods which Sandstorm has removed from
+// HackSessionContext.
+var errObsolete = exc.New(exc.Unimplemented, "sessionCtxImpl", "this method is obsolete")
+
+func (sc sessionCtxImpl) ObsoleteHttpGet(context.Context, hacksession.HackSessionContext_obsoleteHttpGet) error {
+	sc.useHack("obsoleteHttpGet")
+	return errObsolete
+}
+
+func (sc sessionCtxImpl) ObsoleteGenerateApiToken(context.Context, hacksession.HackSessionContext_obsoleteGenerateApiToken) error {
+	sc.useHack("obsoleteGenerateApiToken")
+	return errObsolete
+}
+
+func (sc sessionCtxImpl) ObsoleteListApiTokens(context.Context, hacksession.HackSessionContext_obsoleteListApiTokens) error {
+	sc.useHack("obsoleteListApiTokens")
+	return errObsolete
+}
+
+func (sc sessionCtxImpl) ObsoleteRevokeApiToken(context.Context, hacksession.HackSessionContext_obsoleteRevokeApiToken) error {
+	sc.useHack("obsoleteRevokeApiToken")
+	return errObsolete
+}
+
+func (sc sessionCtxImpl) ObsoleteGetIpNetwork(context.Context, hacksession.HackSessionContext_obsoleteGetIpNetwork) error {
+	sc.useHack("obsoleteGetIpNetwork")
+	return errObsolete
+}
+
+func (sc sessionCtxImpl) ObsoleteGetIpInterface(context.Context, hacksession.HackSessionContext_obsoleteGetIpInterface) error {
+	sc.useHack("obsoleteGetIpInterface")
+	return errObsolete
+}
+
+func (sc sessionCtxImpl) ObsoleteGetUiViewForEndpoint(context.Context, hacksession.HackSessionContext_obsoleteGetUiViewForEndpoint) error {
+	sc.useHack("obsoleteGetUiViewForEndpoint")
+	return errObsolete
+}
@@ -0,0 +1,490 @@
+package servermain
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/session"
+	"sandstorm.org/go/tempest/internal/server/settings"
+	"sandstorm.org/go/tempest/internal/server/tokenutil"
+	"zenhack.net/go/util/exn"
+)
+
+// A new server has nobody to administer it, so when it starts without an
+// admin account, e.g. with an empty database, it makes a one-time setup
+// token, and prints a link with it to the console. Like an invite link,
+// following it stores the token in a cookie, and whoever next logs in with
+// it becomes an admin; a user who is already logged in does so straight
+// away. The token then stops working, and the new admin is sent to the setup
+// wizard, at /admin/setup, which walks them through the settings a new
+// server needs most: its base URL, TLS, and how users log in.
+//
+// The wizard saves settings to the database, where they apply unless the
+// environment sets them (see settings.WithStored) once the server is
+// restarted. Admins may come back to it to change them later.
+
+// setupCookieName is the name of the cookie holding the setup token, to
+// claim on login.
+const setupCookieName = "setup"
+
+// A setupStep is a page of the setup wizard.
+type setupStep struct {
+	Name     string // In the URL.
+	Title    string
+	Help     string
+	Settings []string
+}
+
+// setupSteps lists the pages of the setup wizard, in order.
+var setupSteps = []setupStep{
+	{
+		Name:  "base-url",
+		Title: "Base URL",
+		Help: "The URL of Tempest's web interface. Grains are served from " +
+			"subdomains of its host, so it must be a domain name, and DNS " +
+			"should point both it and its subdomains at this server.",
+		Settings: []string{"BASE_URL"},
+	},
+	{
+		Name:  "tls",
+		Title: "TLS",
+		Help: "To serve HTTPS, give Tempest a wildcard certificate for the " +
+			"base URL's host, or the DNS provider through which it can obtain " +
+			"one itself with ACME; the provider's credentials must be set in " +
+			"the environment. Leave these empty to serve plain HTTP, e.g. " +
+			"behind a reverse proxy which handles TLS.",
+		Settings: []string{"HTTPS_CERT_FILE", "HTTPS_KEY_FILE", "ACME_DNS_PROVIDER", "ACME_EMAIL"},
+	},
+	{
+		Name:  "login",
+		Title: "Logging in",
+		Help: "Users log in by email, which needs an SMTP server to send " +
+			"from, and through a SAML identity provider, if you give its " +
+			"metadata file.",
+		Settings: []string{
+			"SMTP_HOST", "SMTP_PORT", "SMTP_SECURITY", "SMTP_USERNAME",
+			"SMTP_PASSWORD", "SMTP_FROM", "EMAIL_DELIVERY",
+			"SAML_IDP_METADATA_FILE", "SAML_SP_ENTITY_ID", "SAML_NAME_ATTRIBUTE",
+		},
+	},
+}
+
+// secretSettings are the settings the wizard never shows the values of.
+var secretSettings = map[string]bool{
+	"SMTP_PASSWORD": true,
+}
+
+// setupStepNamed returns the step with the given name; "" names the first.
+func setupStepNamed(name string) (setupStep, bool) {
+	if name == "" {
+		return setupSteps[0], true
+	}
+	i := slices.IndexFunc(setupSteps, func(step setupStep) bool {
+		return step.Name == name
+	})
+	if i < 0 {
+		return setupStep{}, false
+	}
+	return setupSteps[i], true
+}
+
+// storedSettings returns the settings stored in db, by name.
+func storedSettings(db database.DB) (map[string]string, error) {
+	return exn.Try(func(throw exn.Thrower) map[string]string {
+		tx, err := db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		stored, err := tx.ServerSettings()
+		throw(err)
+		return stored
+	})
+}
+
+// setupURL returns the link through which the setup token is claimed.
+func (s *server) setupURL(token string) string {
+	u := url.URL{
+		Scheme: "http",
+		Host:   s.cfg.HTTP.RootDomain,
+		Path:   "/setup/" + token,
+	}
+	if s.cfg.HTTP.DefaultTLS {
+		u.Scheme = "https"
+	}
+	return u.String()
+}
+
+// startSetup makes a setup token and prints the link with it, if there is no
+// admin account.
+func (s *server) startSetup() error {
+	hasAdmin, err := exn.Try(func(throw exn.Thrower) bool {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		ok, err := tx.HasAdmin()
+		throw(err)
+		return ok
+	})
+	if err != nil || hasAdmin {
+		return err
+	}
+	token := tokenutil.Gen128Base64()
+	s.state.With(func(state *serverState) {
+		state.setupToken = token
+	})
+	s.log.Info("No admin account yet; printing the setup link")
+	fmt.Printf("\nThis server has no admin yet. To become its admin and set it up, visit:\n\n"+
+		"    %s\n\n"+
+		"and log in. The link works once, until the server is restarted.\n\n",
+		s.setupURL(token))
+	return nil
+}
+
+// isSetupToken reports whether token is the setup token, which hasn't been
+// claimed yet.
+func (s *server) isSetupToken(token string) bool {
+	var ok bool
+	s.state.With(func(state *serverState) {
+		ok = state.setupToken != "" &&
+			subtle.ConstantTimeCompare([]byte(token), []byte(state.setupToken)) == 1
+	})
+	return ok
+}
+
+// claimSetup makes the account with the credential an admin, creating the
+// account if need be, if token is the setup token, which then stops working.
+// It reports whether it did.
+func (s *server) claimSetup(ctx context.Context, cred types.Credential, token string) (bool, error) {
+	var claimed bool
+	s.state.With(func(state *serverState) {
+		if state.setupToken != "" &&
+			subtle.ConstantTimeCompare([]byte(token), []byte(state.setupToken)) == 1 {
+			state.setupToken = ""
+			claimed = true
+		}
+	})
+	if !claimed {
+		return false, nil
+	}
+	accountID, err := exn.Try(func(throw exn.Thrower) types.AccountID {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		accountID, err := tx.CredentialAccount(cred)
+		throw(err)
+		throw(tx.SetAccountRole(accountID, types.RoleAdmin))
+		throw(tx.Commit())
+		return accountID
+	})
+	if err != nil {
+		// Let them try again.
+		s.state.With(func(state *serverState) {
+			state.setupToken = token
+		})
+		return false, err
+	}
+	s.log.InfoCtx(ctx, "Setup token claimed; account is now an admin",
+		"accountID", accountID,
+		"credential", cred,
+	)
+	return true, nil
+}
+
+// setupCookie returns the cookie holding the setup token, which expires after
+// maxAge seconds. Like the invite cookie, it must be sent along with logins
+// which start on other sites; see inviteCookie.
+func (s *server) setupCookie(token string, maxAge int) *http.Cookie {
+	c := s.inviteCookie(token, maxAge)
+	c.Name = setupCookieName
+	return c
+}
+
+// serveSetupLink handles a user following the setup link.
+func (s *server) serveSetupLink(w http.ResponseWriter, req *http.Request) {
+	token := mux.Vars(req)["token"]
+	if !s.isSetupToken(token) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("This setup link is invalid, or has already been used.\n"))
+		return
+	}
+	var sess session.UserSession
+	if session.ReadCookie(s.sessionStore, req, &sess) != nil {
+		// Claimed on login; see logIn.
+		http.SetCookie(w, s.setupCookie(token, int(inviteCookieTTL.Seconds())))
+		http.Redirect(w, req, "/", http.StatusSeeOther)
+		return
+	}
+	claimed, err := s.claimSetup(req.Context(), sess.Credential, token)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Claiming setup token", "error", err)
+		return
+	}
+	if !claimed {
+		// Claimed by someone else since we checked.
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	http.Redirect(w, req, "/admin/setup", http.StatusSeeOther)
+}
+
+// A setupField is an input of the setup wizard.
+type setupField struct {
+	Name    string
+	Value   string
+	Default string
+	Secret  bool
+
+	// Whether the setting is set in the environment, so can't be changed
+	// here; Value is then what it is set to.
+	InEnviron bool
+}
+
+var setupTemplate = parseAdminTemplate("setup", nil, `<!doctype html>
+<html>
+<head>
+<meta charset="utf-8" />
+<title>Set up Tempest</title>
+</head>
+<body>
+{{template "admin-nav" .Nav}}
+<h1>Set up Tempest</h1>
+<ol>
+	{{range .Steps}}
+	<li>{{if eq .Name $.Step.Name}}<strong>{{.Title}}</strong>{{else}}<a href="/admin/setup?step={{.Name}}">{{.Title}}</a>{{end}}</li>
+	{{end}}
+	<li>{{if .Done}}<strong>Done</strong>{{else}}<a href="/admin/setup?step=done">Done</a>{{end}}</li>
+</ol>
+{{if .Done}}
+<h2>Done</h2>
+<p>Your settings are saved. Restart Tempest for them to take effect; then
+log in at the new base URL if you changed it. You can come back here to
+change them at any time.</p>
+{{else}}
+<h2>{{.Step.Title}}</h2>
+<p>{{.Step.Help}}</p>
+<p>Settings set in the environment can't be changed here; empty settings
+take their defaults. See <code>capnp/settings.capnp</code> for what each
+setting means.</p>
+<form method="POST" action="/admin/setup">
+	<input type="hidden" name="step" value="{{.Step.Name}}" />
+	{{range .Fields}}
+	<p><label>{{.Name}}
+		{{if .Secret}}
+		<input type="password" name="{{.Name}}" autocomplete="off"
+			placeholder="{{if .Value}}unchanged{{end}}" {{if .InEnviron}}disabled{{end}} />
+		{{else}}
+		<input type="text" name="{{.Name}}" value="{{.Value}}" placeholder="{{.Default}}"
+			{{if .InEnviron}}disabled{{end}} />
+		{{end}}
+	</label>
+	{{if .InEnviron}}(set in the environment){{else if and .Secret .Value}}
+	<label><input type="checkbox" name="clear" value="{{.Name}}" /> Clear</label>{{end}}</p>
+	{{end}}
+	<button type="submit">Save and continue</button>
+</form>
+{{end}}
+{{if .Problems}}
+<h2>Problems</h2>
+<ul>
+	{{range .Problems}}<li>{{if .IsError}}Error{{else}}Warning{{end}}: {{.Message}}</li>
+	{{end}}
+</ul>
+{{end}}
+</body>
+</html>
+`)
+
+// setupPage is what setupTemplate shows.
+type setupPage struct {
+	Nav      []adminSection
+	Steps    []setupStep
+	Step     setupStep
+	Done     bool
+	Fields   []setupField
+	Problems []setupProblem
+}
+
+// setupProblem is a configProblem, with fields the template can see.
+type setupProblem struct {
+	IsError bool
+	Message string
+}
+
+// setupProblems converts problems for the setup template.
+func setupProblems(problems []configProblem) []setupProblem {
+	ret := make([]setupProblem, len(problems))
+	for i, p := range problems {
+		ret[i] = setupProblem{IsError: p.isError, Message: p.message}
+	}
+	return ret
+}
+
+// setupFields returns the inputs of the step, with the values in stored, or
+// in the environment.
+func setupFields(step setupStep, stored map[string]string) []setupField {
+	env := settings.WithStored(nil)
+	defaults := map[string]string{}
+	for _, info := range settings.All() {
+		if info.HasDefault {
+			defaults[info.Name] = info.Default
+		}
+	}
+	fields := make([]setupField, len(step.Settings))
+	for i, name := range step.Settings {
+		f := setupField{
+			Name:      name,
+			Value:     stored[name],
+			Default:   defaults[name],
+			Secret:    secretSettings[name],
+			InEnviron: settings.InEnviron(name),
+		}
+		if f.InEnviron {
+			f.Value = env.GetString(name)
+		}
+		if f.Secret && f.InEnviron {
+			f.Value = ""
+		}
+		fields[i] = f
+	}
+	return fields
+}
+
+// serveSetup serves the setup wizard's pages; the step query parameter names
+// the page.
+func (s *server) serveSetup(w http.ResponseWriter, req *http.Request) {
+	user, ok := s.requireAdminScope(w, req, types.AdminScopeInfrastructure, false)
+	if !ok {
+		return
+	}
+	page := setupPage{
+		Nav:   visibleAdminSections(user.Scopes),
+		Steps: setupSteps,
+	}
+	stepName := req.URL.Query().Get("step")
+	if stepName == "done" {
+		page.Done = true
+	} else {
+		step, ok := setupStepNamed(stepName)
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		page.Step = step
+	}
+	stored, err := storedSettings(s.db)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Reading stored settings", "error", err)
+		return
+	}
+	page.Fields = setupFields(page.Step, stored)
+	page.Problems = setupProblems(validateConfig(settings.WithStored(stored), os.LookupEnv))
+	s.writeSetupPage(w, http.StatusOK, page)
+}
+
+// serveSaveSetup saves the settings of a step of the setup wizard, and goes
+// on to the next, unless they have errors.
+func (s *server) serveSaveSetup(w http.ResponseWriter, req *http.Request) {
+	user, ok := s.requireAdminScope(w, req, types.AdminScopeInfrastructure, true)
+	if !ok {
+		return
+	}
+	if err := req.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	step, ok := setupStepNamed(req.PostForm.Get("step"))
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	stored, err := storedSettings(s.db)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Reading stored settings", "error", err)
+		return
+	}
+	changes := map[string]string{}
+	for _, name := range step.Settings {
+		if settings.InEnviron(name) {
+			continue
+		}
+		value := strings.TrimSpace(req.PostForm.Get(name))
+		if secretSettings[name] && value == "" && !slices.Contains(req.PostForm["clear"], name) {
+			// Left unchanged.
+			continue
+		}
+		changes[name] = value
+	}
+	proposed := maps.Clone(stored)
+	for name, value := range changes {
+		if value == "" {
+			delete(proposed, name)
+		} else {
+			proposed[name] = value
+		}
+	}
+	problems := validateConfig(settings.WithStored(proposed), os.LookupEnv)
+	if slices.ContainsFunc(problems, func(p configProblem) bool { return p.isError }) {
+		// Show the form again, as it was sent:
+		fields := setupFields(step, proposed)
+		for i := range fields {
+			if fields[i].Secret {
+				fields[i].Value = stored[fields[i].Name]
+			}
+		}
+		s.writeSetupPage(w, http.StatusBadRequest, setupPage{
+			Nav:      visibleAdminSections(user.Scopes),
+			Steps:    setupSteps,
+			Step:     step,
+			Fields:   fields,
+			Problems: setupProblems(problems),
+		})
+		return
+	}
+	err = exn.Try0(func(throw exn.Thrower) {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		throw(tx.SetServerSettings(changes))
+		throw(tx.Commit())
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Saving settings", "error", err)
+		return
+	}
+	names := make([]string, 0, len(changes))
+	for name := range changes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	s.log.InfoCtx(req.Context(), "Settings saved by setup wizard",
+		"step", step.Name,
+		"settings", names,
+	)
+	next := "done"
+	if i := slices.IndexFunc(setupSteps, func(st setupStep) bool {
+		return st.Name == step.Name
+	}); i+1 < len(setupSteps) {
+		next = setupSteps[i+1].Name
+	}
+	http.Redirect(w, req, "/admin/setup?step="+next, http.StatusSeeOther)
+}
+
+func (s *server) writeSetupPage(w http.ResponseWriter, status int, page setupPage) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	setupTemplate.Execute(w, page)
+}
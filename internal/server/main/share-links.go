@@ -0,0 +1,356 @@
+package servermain
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"sandstorm.org/go/tempest/internal/common/apierror"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/session"
+	"sandstorm.org/go/tempest/internal/server/tokenutil"
+)
+
+// Users share grains through share links, each of which grants one of the
+// roles the grain's app defines, e.g. "viewer" or "editor", and which may
+// expire or be single-use. Opening a link makes the user a member of the
+// grain: the role's permissions go in their keyring, and last until the
+// grain's owner removes them; see Restore. Grain sessions check the user's
+// permissions on each request, so removing a member takes effect at once.
+//
+// As with API tokens, anyone with access to a grain may make links to it,
+// granting no more than they have, and may list and revoke their own; the
+// owner may list and revoke everyone's, and manage the members.
+//
+// Who shared with whom is recorded in the grain's share graph. Removing a
+// member also removes those who had access only through them, so that access
+// the owner revokes doesn't live on in what the member passed on; see
+// types.ShareGraph.
+
+// serveGrainShareLinks handles requests to list, make and revoke the share
+// links of the grain named in the URL. GET sends the types.ShareLinkList the
+// user may see, POST makes a link from a types.NewShareLink and sends a
+// types.ShareLinkOffer, and DELETE, with the link's ID in the URL, revokes it.
+func (s *server) serveGrainShareLinks(w http.ResponseWriter, req *http.Request) {
+	var sess session.UserSession
+	if err := session.ReadCookie(s.sessionStore, req, &sess); err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(req)
+	grainID := types.GrainID(vars["grainID"])
+	tx, err := s.db.Begin()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "failed to open database transaction",
+			"error", err)
+		return
+	}
+	defer tx.Rollback()
+	accountID, err := tx.CredentialAccount(sess.Credential)
+	var (
+		perms []bool
+		info  database.GrainInfo
+	)
+	if err == nil {
+		perms, err = accountGrainPermissions(tx, accountID, grainID)
+	}
+	if err == nil {
+		info, err = tx.GrainInfo(grainID)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		// No such grain, or the user can't see it.
+		w.WriteHeader(http.StatusNotFound)
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Looking up grain",
+			"error", err,
+			"grainID", grainID,
+		)
+		return
+	}
+	isOwner := info.Owner == string(accountID)
+	now := time.Now()
+	switch req.Method {
+	case http.MethodGet:
+		s.listGrainShareLinks(w, tx, grainID, accountID, isOwner, now)
+	case http.MethodPost:
+		s.createGrainShareLink(w, req, tx, grainID, accountID, perms, now)
+	case http.MethodDelete:
+		l, err := tx.DeleteShareLink(grainID, vars["linkID"])
+		if err == nil && l.AccountID != accountID && !isOwner {
+			err = sql.ErrNoRows
+		}
+		if err == nil {
+			err = tx.Commit()
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			w.WriteHeader(http.StatusNotFound)
+		} else if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			s.log.ErrorCtx(req.Context(), "Revoking share link",
+				"error", err,
+				"grainID", grainID,
+			)
+		} else {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+}
+
+func (s *server) listGrainShareLinks(w http.ResponseWriter, tx database.Tx, grainID types.GrainID, accountID types.AccountID, isOwner bool, now time.Time) {
+	links, err := tx.GrainShareLinks(grainID, now)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.Error("Reading share links",
+			"error", err,
+			"grainID", grainID,
+		)
+		return
+	}
+	titles, err := tx.GrainRoleTitles(grainID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.Error("Reading grain roles",
+			"error", err,
+			"grainID", grainID,
+		)
+		return
+	}
+	list := types.ShareLinkList{
+		Links:      []types.ShareLink{},
+		RoleTitles: titles,
+	}
+	for _, l := range links {
+		l.Mine = l.AccountID == accountID
+		if l.Mine || isOwner {
+			list.Links = append(list.Links, l)
+		}
+	}
+	if list.RoleTitles == nil {
+		list.RoleTitles = []string{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(list)
+}
+
+func (s *server) createGrainShareLink(w http.ResponseWriter, req *http.Request, tx database.Tx, grainID types.GrainID, accountID types.AccountID, perms []bool, now time.Time) {
+	var want types.NewShareLink
+	err := json.NewDecoder(http.MaxBytesReader(w, req.Body, 64*1024)).Decode(&want)
+	if err != nil {
+		s.writeLocalizedError(w, req, http.StatusBadRequest, apierror.New(
+			apierror.CodeInvalidArgument, "malformed request: "+err.Error(), "request"))
+		return
+	}
+	if want.Days < 0 {
+		s.writeLocalizedError(w, req, http.StatusBadRequest, apierror.New(
+			apierror.CodeInvalidArgument, "links can't last a negative number of days", "number of days"))
+		return
+	}
+	roles, err := tx.GrainRolePermissions(grainID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Reading grain roles",
+			"error", err,
+			"grainID", grainID,
+		)
+		return
+	}
+	if want.RoleID < 0 || want.RoleID >= len(roles) {
+		s.writeLocalizedError(w, req, http.StatusBadRequest, apierror.New(
+			apierror.CodeInvalidArgument, "the grain's app defines no such role", "role"))
+		return
+	}
+	// The link grants no more than the user has.
+	role := roles[want.RoleID]
+	link := types.ShareLink{
+		ID:          hex.EncodeToString(tokenutil.Gen128()),
+		GrainID:     grainID,
+		AccountID:   accountID,
+		RoleID:      want.RoleID,
+		Permissions: make([]bool, len(role)),
+		Note:        want.Note,
+		Created:     now,
+		SingleUse:   want.SingleUse,
+		Mine:        true,
+	}
+	for i := range link.Permissions {
+		link.Permissions[i] = role[i] && i < len(perms) && perms[i]
+	}
+	if want.Days > 0 {
+		link.Expires = now.AddDate(0, 0, want.Days)
+	}
+	token, err := tx.NewShareLink(link)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Making share link",
+			"error", err,
+			"grainID", grainID,
+		)
+		return
+	}
+	s.events.Publish(types.GrainEvent{
+		GrainID: grainID,
+		Kind:    types.GrainShared,
+		Detail:  want.Note,
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(types.ShareLinkOffer{
+		Link:  link,
+		Token: token,
+	})
+}
+
+// serveGrainMembers handles requests to list and remove the members of the
+// grain named in the URL; only its owner may manage them. GET sends the
+// []types.GrainMember, and DELETE, with the member's account ID in the URL,
+// revokes their access, and that of those who had it only through them, and
+// sends the []types.AccountID whose access was revoked.
+func (s *server) serveGrainMembers(w http.ResponseWriter, req *http.Request) {
+	var sess session.UserSession
+	if err := session.ReadCookie(s.sessionStore, req, &sess); err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(req)
+	grainID := types.GrainID(vars["grainID"])
+	tx, err := s.db.Begin()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "failed to open database transaction",
+			"error", err)
+		return
+	}
+	defer tx.Rollback()
+	if !s.checkGrainOwner(w, tx, sess, grainID) {
+		return
+	}
+	switch req.Method {
+	case http.MethodGet:
+		members, err := tx.GrainMembers(grainID)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			s.log.ErrorCtx(req.Context(), "Reading grain members",
+				"error", err,
+				"grainID", grainID,
+			)
+			return
+		}
+		if members == nil {
+			members = []types.GrainMember{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(members)
+	case http.MethodDelete:
+		memberID := types.AccountID(vars["accountID"])
+		ownerID, err := tx.CredentialAccount(sess.Credential)
+		if err == nil && memberID == ownerID {
+			// The owner's keyring entry isn't a membership.
+			err = sql.ErrNoRows
+		}
+		var revoked []types.AccountID
+		if err == nil {
+			revoked, err = tx.RemoveGrainMember(grainID, memberID)
+		}
+		if err == nil {
+			err = tx.Commit()
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			w.WriteHeader(http.StatusNotFound)
+		} else if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			s.log.ErrorCtx(req.Context(), "Removing grain member",
+				"error", err,
+				"grainID", grainID,
+			)
+		} else {
+			for range revoked {
+				s.events.Publish(types.GrainEvent{
+					GrainID: grainID,
+					Kind:    types.GrainMemberRemoved,
+				})
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Cache-Control", "no-store")
+			json.NewEncoder(w).Encode(revoked)
+		}
+	}
+}
+
+// serveGrainShareGraph sends the types.ShareGraph of the grain named in the
+// URL; only its owner may see it.
+func (s *server) serveGrainShareGraph(w http.ResponseWriter, req *http.Request) {
+	var sess session.UserSession
+	if err := session.ReadCookie(s.sessionStore, req, &sess); err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	grainID := types.GrainID(mux.Vars(req)["grainID"])
+	tx, err := s.db.Begin()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "failed to open database transaction",
+			"error", err)
+		return
+	}
+	defer tx.Rollback()
+	if !s.checkGrainOwner(w, tx, sess, grainID) {
+		return
+	}
+	g, err := tx.GrainShareGraph(grainID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Reading share graph",
+			"error", err,
+			"grainID", grainID,
+		)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(g)
+}
+
+// grainSessionPermissions returns the permissions of the user of a grain
+// session which wasn't opened through an embed: nil, meaning all of them, if
+// they own the grain, and those in their keyring otherwise. If they have no
+// access to the grain, e.g. because its owner removed them, the error wraps
+// sql.ErrNoRows.
+func (s *server) grainSessionPermissions(sess session.GrainSession) ([]bool, error) {
+	if sess.AccountID == "" {
+		// The session was opened before grain sessions recorded
+		// the user's account; the user must open the grain again.
+		return nil, fmt.Errorf("grain session has no account: %w", sql.ErrNoRows)
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	info, err := tx.GrainInfo(sess.GrainID)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Trashed.IsZero() {
+		// Nobody may use a grain in the trash, not even its owner.
+		return nil, fmt.Errorf("%w: %w", errGrainTrashed, sql.ErrNoRows)
+	}
+	if info.Owner == string(sess.AccountID) {
+		return nil, nil
+	}
+	return tx.AccountGrainPermissions(sess.AccountID, sess.GrainID)
+}
@@ -0,0 +1,41 @@
+package servermain
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"golang.org/x/exp/slog"
+	"sandstorm.org/go/tempest/internal/config"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/replication"
+)
+
+// runStandby runs the server as a warm standby for cfg.Replication.PrimaryURL,
+// until it receives a signal telling it to shut down. A standby serves only its
+// replication status page; it does not open the database or start grains.
+func runStandby(cfg Config, lg *slog.Logger) {
+	standby := &replication.Standby{
+		Log:        lg,
+		PrimaryURL: strings.TrimSuffix(cfg.Replication.PrimaryURL, "/"),
+		Secret:     cfg.Replication.Secret,
+		Interval:   cfg.Replication.Interval,
+		GrainsDir:  config.GrainsDir,
+		DBPath:     database.DBPath,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go standby.Run(ctx)
+
+	httpAddr := ":" + cfg.HTTP.Port
+	lg.Info("Running as a standby",
+		"primary-url", standby.PrimaryURL,
+		"interval", cfg.Replication.Interval,
+		"http-addr", httpAddr,
+	)
+	httpSrv := &http.Server{Addr: httpAddr, Handler: standby}
+	go monitorSignals(httpSrv)
+	if err := httpSrv.ListenAndServe(); err != http.ErrServerClosed {
+		panic(err)
+	}
+}
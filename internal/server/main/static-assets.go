@@ -0,0 +1,155 @@
+package servermain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// The build tool writes copies of the web UI's bundle, JavaScript and CSS to
+// assets/ in the embedded files, named with a hash of their contents, and
+// precompressed with gzip and maybe Brotli. Since their names change whenever
+// they do, browsers may cache them for good; only the page which refers to
+// them, index.html, must be fetched again.
+const (
+	hashedAssetsDir      = "assets"
+	hashedAssetsManifest = hashedAssetsDir + "/manifest.json"
+)
+
+// staticAssets serves the web UI's page and its hashed assets.
+type staticAssets struct {
+	content fs.FS
+	hashed  map[string]string // by the assets' usual names
+	served  map[string]bool   // hashed names
+	index   *template.Template
+}
+
+// loadStaticAssets reads the hashed assets' manifest from content, and parses
+// index.html, which refers to assets as {{asset "name"}}. Without a manifest,
+// e.g. from an older build, assets are referred to by their usual names.
+func loadStaticAssets(content fs.FS) (*staticAssets, error) {
+	a := &staticAssets{
+		content: content,
+		hashed:  make(map[string]string),
+		served:  make(map[string]bool),
+	}
+	manifest, err := fs.ReadFile(content, hashedAssetsManifest)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(manifest, &a.hashed); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range a.hashed {
+		a.served[name] = true
+	}
+	a.index, err = template.New("index.html").
+		Funcs(template.FuncMap{"asset": a.url}).
+		ParseFS(content, "index.html")
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// url returns the path of the asset with the given usual name.
+func (a *staticAssets) url(name string) string {
+	if hashed, ok := a.hashed[name]; ok {
+		return "/" + hashedAssetsDir + "/" + hashed
+	}
+	return "/" + name
+}
+
+// serveIndex serves the web UI's page. Browsers must check for a new one each
+// time, since it names the current assets.
+func (a *staticAssets) serveIndex(w http.ResponseWriter, req *http.Request) {
+	var buf bytes.Buffer
+	if err := a.index.Execute(&buf, nil); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(buf.Bytes())
+}
+
+// serveHashed serves a hashed asset, in the best encoding which both it and
+// the browser have.
+func (a *staticAssets) serveHashed(w http.ResponseWriter, req *http.Request) {
+	name := mux.Vars(req)["name"]
+	if !a.served[name] {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	accepted := req.Header.Get("Accept-Encoding")
+	var (
+		content  []byte
+		encoding string
+	)
+	for _, precompressed := range []struct{ encoding, suffix string }{
+		{"br", ".br"},
+		{"gzip", ".gz"},
+	} {
+		if !acceptsEncoding(accepted, precompressed.encoding) {
+			continue
+		}
+		compressed, err := fs.ReadFile(a.content, hashedAssetsDir+"/"+name+precompressed.suffix)
+		if err == nil {
+			content, encoding = compressed, precompressed.encoding
+			break
+		}
+	}
+	if encoding == "" {
+		var err error
+		content, err = fs.ReadFile(a.content, hashedAssetsDir+"/"+name)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+	}
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if path.Ext(name) == ".wasm" {
+		// Needed by WebAssembly.instantiateStreaming, and not in every
+		// system's MIME types.
+		contentType = "application/wasm"
+	}
+	header := w.Header()
+	header.Set("Cache-Control", "public, max-age=31536000, immutable")
+	header.Set("Vary", "Accept-Encoding")
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	if encoding != "" {
+		header.Set("Content-Encoding", encoding)
+	}
+	w.Write(content)
+}
+
+// acceptsEncoding reports whether an Accept-Encoding header allows the given
+// content coding: it must be listed, or else "*" must be, without q=0.
+func acceptsEncoding(header, coding string) bool {
+	accepted, wildcard := false, false
+	for _, item := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(item, ";")
+		name = strings.TrimSpace(name)
+		q, ok := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q=")
+		allowed := !ok || strings.Trim(q, "0.") != ""
+		switch name {
+		case coding:
+			return allowed
+		case "*":
+			accepted, wildcard = allowed, true
+		}
+	}
+	return wildcard && accepted
+}
@@ -0,0 +1,146 @@
+package servermain
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"runtime"
+	"strconv"
+
+	"capnproto.org/go/capnp/v3/exc"
+	thumbnailer "sandstorm.org/go/tempest/capnp/thumbnail"
+	"sandstorm.org/go/tempest/internal/common/apierror"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/config"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/thumbnail"
+	"zenhack.net/go/util/exn"
+)
+
+// Thumbnails are made by s.thumbnails, for the shell at /thumbnail, and for
+// grains through a Thumbnailer (see thumbnail.capnp), which they get from the
+// powerbox. Helper programs, e.g. for PDFs, run in a sandbox set up by the
+// sandbox launcher, much like a grain's.
+
+func newThumbnailService(cfg ThumbnailConfig) *thumbnail.Service {
+	transcoders := []thumbnail.Transcoder{thumbnail.Image{}}
+	if len(cfg.PDFCommand) > 0 {
+		transcoders = append(transcoders, thumbnail.Command{
+			MimeTypes: []string{"application/pdf"},
+			Args:      cfg.PDFCommand,
+			Launcher:  config.Libexecdir + "/tempest/tempest-sandbox-launcher",
+		})
+	}
+	return thumbnail.NewService(thumbnail.DefaultLimits, runtime.NumCPU(), transcoders...)
+}
+
+// serveThumbnail renders the request body, whose type is given by the
+// Content-Type header, as a PNG thumbnail. The size query parameter sets the
+// thumbnail's maximum width & height.
+func (s *server) serveThumbnail(w http.ResponseWriter, req *http.Request) {
+	if !s.requireRole(w, req, types.RoleUser) {
+		return
+	}
+	size := thumbnail.DefaultSize
+	if sizeStr := req.URL.Query().Get("size"); sizeStr != "" {
+		var err error
+		size, err = strconv.Atoi(sizeStr)
+		if err != nil || size <= 0 || size > thumbnail.MaxSize {
+			s.writeLocalizedError(w, req, http.StatusBadRequest, apierror.New(
+				apierror.CodeInvalidArgument, "invalid size", "thumbnail size"))
+			return
+		}
+	}
+	mimeType := req.Header.Get("Content-Type")
+	png, err := s.thumbnails.Thumbnail(req.Context(), mimeType, req.Body, size)
+	switch {
+	case errors.Is(err, thumbnail.ErrUnsupported):
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	case errors.Is(err, thumbnail.ErrTooLarge):
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		return
+	case err != nil:
+		s.log.InfoCtx(req.Context(), "Generating thumbnail failed",
+			"error", err,
+			"content-type", mimeType,
+		)
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	w.Write(png)
+}
+
+// canGrantThumbnailer reports whether the account may grant grains the
+// thumbnail service, which, like /thumbnail, visitors may not use.
+func canGrantThumbnailer(tx database.Tx, accountID types.AccountID) (bool, error) {
+	role, err := tx.AccountRole(accountID)
+	return role.Encompasses(types.RoleUser), err
+}
+
+// powerboxThumbnailer is the thumbnail service, as granted to a grain through
+// the powerbox. It works only while the user who granted it may still use
+// it, and has the required permissions on the grain holding it.
+type powerboxThumbnailer struct {
+	server *server
+	holder types.GrainID
+	grant  database.PowerboxGrant
+}
+
+// check returns an error if the grant has been revoked.
+func (t powerboxThumbnailer) check(tx database.Tx) error {
+	if err := checkHolder(tx, t.holder, t.grant); err != nil {
+		return err
+	}
+	ok, err := canGrantThumbnailer(tx, t.grant.AccountID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !ok) {
+		return errRevoked
+	}
+	return err
+}
+
+// checkNow is check, in a transaction of its own.
+func (t powerboxThumbnailer) checkNow() error {
+	tx, err := t.server.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	return t.check(tx)
+}
+
+func (t powerboxThumbnailer) Accepts(ctx context.Context, p thumbnailer.Thumbnailer_accepts) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		throw(t.checkNow())
+		mimeType, err := p.Args().MimeType()
+		throw(err)
+		res, err := p.AllocResults()
+		throw(err)
+		res.SetAccepted(t.server.thumbnails.Accepts(mimeType))
+	})
+}
+
+func (t powerboxThumbnailer) Thumbnail(ctx context.Context, p thumbnailer.Thumbnailer_thumbnail) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		throw(t.checkNow())
+		mimeType, err := p.Args().MimeType()
+		throw(err)
+		content, err := p.Args().Content()
+		throw(err)
+		size := int(p.Args().Size())
+		if size == 0 {
+			size = thumbnail.DefaultSize
+		}
+		png, err := t.server.thumbnails.Thumbnail(ctx, mimeType, bytes.NewReader(content), size)
+		if err != nil {
+			throw(exc.New(exc.Failed, "powerboxThumbnailer", err.Error()))
+		}
+		res, err := p.AllocResults()
+		throw(err)
+		throw(res.SetPng(png))
+	})
+}
@@ -0,0 +1,101 @@
+package servermain
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/container"
+	"sandstorm.org/go/tempest/internal/server/session"
+	"zenhack.net/go/util/exn"
+)
+
+// recordGrainEvent adds an event from the event bus to the grain's timeline.
+// Failures are logged, but otherwise ignored.
+func (s *server) recordGrainEvent(e types.GrainEvent) {
+	err := exn.Try0(func(throw exn.Thrower) {
+		tx, err := s.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		throw(tx.AddGrainEvent(e))
+		throw(tx.Commit())
+	})
+	if err != nil {
+		s.log.Error("Failed to record grain event",
+			"error", err,
+			"grainID", e.GrainID,
+			"kind", e.Kind,
+		)
+	}
+}
+
+// watchContainer waits for a grain's container to shut down. If the grain
+// crashed, it forgets the container and the grain's web sessions, so that the
+// grain is restarted the next time it is used, and publishes GrainCrashed.
+func (s *server) watchContainer(grainID types.GrainID, c container.Container) {
+	<-c.Exited()
+	select {
+	case <-c.Crashed():
+	default:
+		return // Killed deliberately.
+	}
+	var stale []grainSession
+	s.state.With(func(state *serverState) {
+		state.containers.remove(grainID, c)
+		state.containers.crashed[grainID] = true
+		for key, gs := range state.grainSessions {
+			if key.grainID == grainID {
+				stale = append(stale, gs)
+				delete(state.grainSessions, key)
+			}
+		}
+	})
+	// Release outside the lock, since it may wait for a session which is
+	// still being set up.
+	for _, gs := range stale {
+		gs.Release()
+	}
+	s.events.Publish(types.GrainEvent{
+		GrainID: grainID,
+		Kind:    types.GrainCrashed,
+	})
+}
+
+// serveGrainTimeline sends the timeline of the grain named in the URL, as a
+// JSON array of types.GrainEvent, most recent first. Only the grain's owner
+// may see it; for anyone else, the grain doesn't exist.
+func (s *server) serveGrainTimeline(w http.ResponseWriter, req *http.Request) {
+	var sess session.UserSession
+	if err := session.ReadCookie(s.sessionStore, req, &sess); err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	grainID := types.GrainID(mux.Vars(req)["grainID"])
+	tx, err := s.db.Begin()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "failed to open database transaction",
+			"error", err)
+		return
+	}
+	defer tx.Rollback()
+	if !s.checkGrainOwner(w, tx, sess, grainID) {
+		return
+	}
+	timeline, err := tx.GrainEvents(grainID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.log.ErrorCtx(req.Context(), "Reading grain timeline",
+			"error", err,
+			"grainID", grainID,
+		)
+		return
+	}
+	if timeline == nil {
+		timeline = []types.GrainEvent{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(timeline)
+}
@@ -0,0 +1,77 @@
+package servermain
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"capnproto.org/go/capnp/v3/exc"
+	turnserver "sandstorm.org/go/tempest/capnp/turn"
+	"sandstorm.org/go/tempest/internal/common/types"
+	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/turn"
+	"zenhack.net/go/util/exn"
+)
+
+// Grains get credentials for the TURN relay, if the server has one, through
+// a TurnServer (see turn.capnp), which they get from the powerbox, so that a
+// user must approve an app's use of the relay before it can have any.
+
+// canGrantTURN reports whether the account may grant grains the TURN relay,
+// which visitors may not.
+func canGrantTURN(tx database.Tx, accountID types.AccountID) (bool, error) {
+	role, err := tx.AccountRole(accountID)
+	return role.Encompasses(types.RoleUser), err
+}
+
+// powerboxTURN is the TURN relay, as granted to a grain through the powerbox.
+// It works only while the user who granted it may still grant it, and has the
+// required permissions on the grain holding it.
+type powerboxTURN struct {
+	server *server
+	holder types.GrainID
+	grant  database.PowerboxGrant
+}
+
+// check returns an error if the grant has been revoked.
+func (t powerboxTURN) check(tx database.Tx) error {
+	if err := checkHolder(tx, t.holder, t.grant); err != nil {
+		return err
+	}
+	ok, err := canGrantTURN(tx, t.grant.AccountID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !ok) {
+		return errRevoked
+	}
+	return err
+}
+
+func (t powerboxTURN) Credentials(ctx context.Context, p turnserver.TurnServer_credentials) error {
+	return exn.Try0(func(throw exn.Thrower) {
+		tx, err := t.server.db.Begin()
+		throw(err)
+		defer tx.Rollback()
+		throw(t.check(tx))
+		if !t.server.cfg.TURN.Enabled() {
+			throw(exc.New(exc.Failed, "powerboxTURN", "no TURN relay is configured"))
+		}
+		creds, err := t.server.turn.Issue(string(t.holder))
+		if errors.Is(err, turn.ErrRateLimited) {
+			t.server.log.WarnCtx(ctx, "Grain exceeded TURN credential rate limit", "grainID", t.holder)
+			throw(exc.New(exc.Overloaded, "powerboxTURN", err.Error()))
+		}
+		throw(err)
+		throw(tx.RecordTURNCredential(t.holder))
+		throw(tx.Commit())
+
+		res, err := p.AllocResults()
+		throw(err)
+		urls, err := res.NewUrls(int32(len(creds.URLs)))
+		throw(err)
+		for i, u := range creds.URLs {
+			throw(urls.Set(i, u))
+		}
+		throw(res.SetUsername(creds.Username))
+		throw(res.SetCredential(creds.Credential))
+		res.SetExpires(creds.Expires.Unix())
+	})
+}
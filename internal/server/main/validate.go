@@ -0,0 +1,228 @@
+package servermain
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+
+	"golang.org/x/exp/slog"
+	"sandstorm.org/go/tempest/internal/server/settings"
+)
+
+// A configProblem is something wrong with the settings found by
+// ValidateConfig. Errors would stop Tempest from starting, or leave it
+// unusable; warnings are likely, but not certainly, mistakes.
+type configProblem struct {
+	isError bool
+	message string
+}
+
+// ValidateConfig checks the settings in the environment, as
+// `tempest --validate-config`, without starting the server. It prints any
+// problems, and exits with a non-zero status if any of them are errors.
+//
+// With -reference, it instead prints every setting and its default, in the
+// format of an environment file.
+func ValidateConfig(args []string) {
+	flags := flag.NewFlagSet("tempest --validate-config", flag.ExitOnError)
+	reference := flags.Bool("reference", false,
+		"print every setting with its default, instead of validating")
+	flags.Parse(args)
+
+	if *reference {
+		writeSettingsReference(os.Stdout)
+		return
+	}
+	problems := validateConfig(settings.Environ, os.LookupEnv)
+	failed := false
+	for _, p := range problems {
+		if p.isError {
+			failed = true
+			fmt.Println("error:", p.message)
+		} else {
+			fmt.Println("warning:", p.message)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+	fmt.Println("Configuration is valid.")
+}
+
+// validateConfig checks the settings in src. lookupEnv is used to find
+// deprecated settings, which src doesn't distinguish.
+func validateConfig(src settings.Source, lookupEnv func(string) (string, bool)) []configProblem {
+	var problems []configProblem
+	problemf := func(isError bool, format string, args ...any) {
+		problems = append(problems, configProblem{
+			isError: isError,
+			message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	for oldName, newName := range settings.Deprecated {
+		if _, ok := lookupEnv(oldName); !ok {
+			continue
+		}
+		if _, ok := lookupEnv(newName); ok {
+			problemf(false, "%s is deprecated, and ignored because %s is set", oldName, newName)
+		} else {
+			problemf(false, "%s is deprecated; use %s instead", oldName, newName)
+		}
+	}
+
+	cfg, err := loadConfig(src)
+	if err != nil {
+		// The remaining checks need the parsed configuration.
+		problemf(true, "%v", err)
+		return problems
+	}
+
+	// The base domain: grains and the UI are served from its subdomains,
+	// so it must be a name which can have them.
+	host := cfg.HTTP.RootDomain
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if net.ParseIP(strings.Trim(host, "[]")) != nil {
+		problemf(true, "BASE_URL's host %q is an IP address, but Tempest serves "+
+			"grains from subdomains of BASE_URL, which need a domain name", host)
+	}
+
+	// TLS: BASE_URL's scheme vs. the HTTPS listener.
+	hasCert := cfg.HTTP.CertFile != ""
+	hasKey := cfg.HTTP.KeyFile != ""
+	hasACME := cfg.HTTP.ACME.Enabled()
+	listensTLS := hasCert && hasKey || hasACME
+	switch {
+	case hasACME && (hasCert || hasKey):
+		problemf(true, "ACME_DNS_PROVIDER is set, so Tempest obtains its own "+
+			"certificate, but so is HTTPS_CERT_FILE or HTTPS_KEY_FILE; "+
+			"unset one or the other")
+	case hasCert && !hasKey:
+		problemf(true, "HTTPS_CERT_FILE is set, but HTTPS_KEY_FILE is not; "+
+			"Tempest will not listen for HTTPS")
+	case hasKey && !hasCert:
+		problemf(true, "HTTPS_KEY_FILE is set, but HTTPS_CERT_FILE is not; "+
+			"Tempest will not listen for HTTPS")
+	case cfg.HTTP.DefaultTLS && !listensTLS && len(cfg.HTTP.TrustedProxies) == 0:
+		problemf(false, "BASE_URL uses https, but neither HTTPS_CERT_FILE and "+
+			"HTTPS_KEY_FILE nor ACME_DNS_PROVIDER are set, so Tempest will only "+
+			"listen for HTTP; this is only right behind a reverse proxy which "+
+			"handles TLS, which should then be listed in TRUSTED_PROXIES")
+	case !cfg.HTTP.DefaultTLS && listensTLS:
+		problemf(false, "Tempest will listen for HTTPS, but BASE_URL uses http, "+
+			"so links and redirects will use plain HTTP")
+	}
+	if listensTLS && cfg.HTTP.Port == cfg.HTTP.TLSPort {
+		problemf(true, "HTTP_PORT and HTTPS_PORT are both %s", cfg.HTTP.Port)
+	}
+	if hasKey {
+		fi, err := os.Lstat(cfg.HTTP.KeyFile)
+		if err != nil {
+			problemf(true, "HTTPS_KEY_FILE: %v", err)
+		} else if fi.Mode()&0077 != 0 {
+			problemf(true, "HTTPS_KEY_FILE has permissions %o, but must have no "+
+				"access by group or other", fi.Mode()&0777)
+		}
+	}
+	if hasACME && cfg.HTTP.ACME.Email == "" {
+		problemf(false, "ACME_EMAIL is not set, so the CA can't warn you if "+
+			"Tempest fails to renew its certificate")
+	}
+	if hasCert && hasKey && !hasACME {
+		problems = append(problems, checkCertificate(cfg.HTTP.CertFile, cfg.HTTP.KeyFile, host)...)
+	}
+
+	// Outgoing email, which email login depends on:
+	switch {
+	case cfg.Email.Delivery == EmailDeliveryLog:
+		problemf(false, "EMAIL_DELIVERY is log, so login tokens are written to the "+
+			"log; this is only safe for development")
+	case cfg.Email.SMTP.Host == "":
+		problemf(false, "SMTP_HOST is not set, so Tempest cannot send email, "+
+			"and users cannot log in by email")
+	case cfg.Email.SMTP.From == "":
+		problemf(true, "neither SMTP_FROM nor SMTP_USERNAME is set, so Tempest "+
+			"has no address to send email from")
+	}
+	return problems
+}
+
+// loadConfig is ConfigFromSettings, but returns an error instead of
+// panicking if a setting is invalid.
+func loadConfig(src settings.Source) (cfg Config, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = errors.New(strings.TrimPrefix(fmt.Sprint(e), "FATAL: "))
+		}
+	}()
+	// Don't log the failure; it is returned instead.
+	lg := slog.New(slog.HandlerOptions{}.NewTextHandler(io.Discard))
+	return ConfigFromSettings(lg, src), nil
+}
+
+// checkCertificate checks that the HTTPS certificate can be loaded, and
+// covers both the base domain and its subdomains, i.e., is valid for a
+// wildcard.
+func checkCertificate(certFile, keyFile, host string) []configProblem {
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return []configProblem{{
+			isError: true,
+			message: fmt.Sprintf("loading HTTPS_CERT_FILE and HTTPS_KEY_FILE: %v", err),
+		}}
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return []configProblem{{
+			isError: true,
+			message: fmt.Sprintf("parsing HTTPS_CERT_FILE: %v", err),
+		}}
+	}
+	var problems []configProblem
+	if err := cert.VerifyHostname(host); err != nil {
+		problems = append(problems, configProblem{
+			isError: true,
+			message: fmt.Sprintf("HTTPS_CERT_FILE is not valid for BASE_URL's host %q", host),
+		})
+	}
+	// Any single label will do; the certificate needs *.host.
+	if err := cert.VerifyHostname("ui-check." + host); err != nil {
+		problems = append(problems, configProblem{
+			isError: true,
+			message: fmt.Sprintf("HTTPS_CERT_FILE is not valid for subdomains of %q; "+
+				"it needs a wildcard name, *.%s", host, host),
+		})
+	}
+	return problems
+}
+
+// writeSettingsReference writes every setting and its default, in the format
+// of an environment file; settings without a default are commented out.
+func writeSettingsReference(w io.Writer) {
+	fmt.Fprintln(w, "# Tempest settings, with their defaults. See capnp/settings.capnp")
+	fmt.Fprintln(w, "# for what each one does.")
+	deprecatedNames := make(map[string]string, len(settings.Deprecated))
+	for oldName, newName := range settings.Deprecated {
+		deprecatedNames[newName] = oldName
+	}
+	for _, info := range settings.All() {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "# %s (%s)\n", info.Name, info.Type)
+		if oldName, ok := deprecatedNames[info.Name]; ok {
+			fmt.Fprintf(w, "# Formerly %s, which is deprecated.\n", oldName)
+		}
+		if info.HasDefault {
+			fmt.Fprintf(w, "%s=%s\n", info.Name, info.Default)
+		} else {
+			fmt.Fprintf(w, "#%s=\n", info.Name)
+		}
+	}
+}
@@ -2,10 +2,14 @@ package servermain
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 
 	"sandstorm.org/go/tempest/capnp/external"
+	"sandstorm.org/go/tempest/internal/common/apierror"
 	"sandstorm.org/go/tempest/internal/common/types"
 	"sandstorm.org/go/tempest/internal/server/database"
+	"sandstorm.org/go/tempest/internal/server/events"
 	"sandstorm.org/go/tempest/internal/server/session"
 	"zenhack.net/go/util/exn"
 )
@@ -14,24 +18,33 @@ type uiViewControllerImpl struct {
 	GrainID types.GrainID
 	Session session.UserSession
 	DB      database.DB
+	Events  *events.Bus
+	Server  *server
 }
 
 func (c uiViewControllerImpl) MakeSharingToken(ctx context.Context, p external.UiView_Controller_makeSharingToken) error {
-	return exn.Try0(func(throw exn.Thrower) {
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	return withAPIError(results, func(throw exn.Thrower) {
 		wantPerms, err := p.Args().Permissions()
 		throw(err)
 		note, err := p.Args().Note()
 		throw(err)
-		results, err := p.AllocResults()
-		throw(err)
 		tx, err := c.DB.Begin()
 		throw(err)
 		defer tx.Rollback()
 		accountID, err := tx.CredentialAccount(c.Session.Credential)
 		throw(err, "no account for credential")
 		perms, err := tx.AccountGrainPermissions(accountID, c.GrainID)
+		if errors.Is(err, sql.ErrNoRows) {
+			throw(apierror.New(apierror.CodeNotFound,
+				"no such grain: "+string(c.GrainID), "grain"))
+		}
 		throw(err, "failed to fetch permissions")
-		if len(perms) < wantPerms.Len() {
+		// Only the permissions the user holds can be shared.
+		if wantPerms.Len() < len(perms) {
 			perms = perms[:wantPerms.Len()]
 		}
 		for i := range perms {
@@ -40,6 +53,47 @@ func (c uiViewControllerImpl) MakeSharingToken(ctx context.Context, p external.U
 		token, err := tx.NewSharingToken(c.GrainID, perms, note)
 		throw(err)
 		throw(tx.Commit())
+		c.Events.Publish(types.GrainEvent{
+			GrainID: c.GrainID,
+			Kind:    types.GrainShared,
+			Detail:  note,
+		})
 		throw(results.SetToken(token))
 	})
 }
+
+func (c uiViewControllerImpl) SetTitle(ctx context.Context, p external.UiView_Controller_setTitle) error {
+	title, err := p.Args().Title()
+	if err != nil {
+		return err
+	}
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	return setAPIError(results, c.Server.renameGrain(c.Session.Credential, c.GrainID, title))
+}
+
+func (c uiViewControllerImpl) MoveToTrash(ctx context.Context, p external.UiView_Controller_moveToTrash) error {
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	return setAPIError(results, c.Server.trashGrain(c.Session.Credential, c.GrainID, true))
+}
+
+func (c uiViewControllerImpl) RestoreFromTrash(ctx context.Context, p external.UiView_Controller_restoreFromTrash) error {
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	return setAPIError(results, c.Server.trashGrain(c.Session.Credential, c.GrainID, false))
+}
+
+func (c uiViewControllerImpl) Purge(ctx context.Context, p external.UiView_Controller_purge) error {
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	return setAPIError(results, c.Server.purgeGrain(c.Session.Credential, c.GrainID))
+}
@@ -0,0 +1,344 @@
+// Package quota keeps track of how much disk space grains use, so that users
+// can be held to storage quotas.
+//
+// A Tracker measures each grain's directory periodically, as du does. Between
+// these full scans, it watches grains' directories with inotify, and measures
+// a grain again shortly after it changes, so usage stays fresh without
+// walking every grain all the time. The watches are only hints: inotify
+// doesn't watch subdirectories recursively, so each directory needs its own
+// watch, and the number of watches is capped. Changes which the watches miss
+// are picked up by the next full scan.
+package quota
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"golang.org/x/exp/slog"
+	"sandstorm.org/go/tempest/internal/common/types"
+)
+
+// ErrExceeded is returned by readers from LimitReader which reach their
+// limit.
+var ErrExceeded = errors.New("quota: storage quota exceeded")
+
+// Config configures storage quotas.
+type Config struct {
+	// The most disk space each user's grains may use, in bytes. If this
+	// is zero, there is no limit.
+	UserLimit int64
+
+	// How often to measure every grain.
+	ScanInterval time.Duration
+}
+
+// LimitReader returns a Reader which reads from r, but fails with ErrExceeded
+// if r has more than n bytes. If n is negative, there is no limit.
+func LimitReader(r io.Reader, n int64) io.Reader {
+	if n < 0 {
+		return r
+	}
+	return &limitedReader{r: r, n: n}
+}
+
+type limitedReader struct {
+	r io.Reader
+	n int64 // Bytes remaining.
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+	n, err := l.r.Read(p)
+	if int64(n) > l.n {
+		l.n = 0
+		return 0, ErrExceeded
+	}
+	l.n -= int64(n)
+	return n, err
+}
+
+// Measure returns the disk space used by the files under dir, as du does:
+// counting the blocks allocated to each file rather than its size, and files
+// with several hard links once. If dir doesn't exist, it uses no space.
+func Measure(dir string) (int64, error) {
+	type inode struct{ dev, ino uint64 }
+	var (
+		total int64
+		seen  = make(map[inode]bool)
+	)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			// Missing, or removed while we were walking.
+			return nil
+		} else if err != nil {
+			return err
+		}
+		fi, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		st, ok := fi.Sys().(*syscall.Stat_t)
+		if !ok {
+			total += fi.Size()
+			return nil
+		}
+		if st.Nlink > 1 && !fi.IsDir() {
+			key := inode{uint64(st.Dev), st.Ino}
+			if seen[key] {
+				return nil
+			}
+			seen[key] = true
+		}
+		total += st.Blocks * 512
+		return nil
+	})
+	return total, err
+}
+
+// DefaultMaxWatches is the default limit on the number of directories a
+// Tracker watches. Linux's default per-user limit on inotify watches has
+// long been 8192, which is shared with everything else the user runs.
+const DefaultMaxWatches = 4096
+
+// How long after a grain changes a Tracker measures it again. Changes tend
+// to come in bursts, e.g. while a file is written, so this should be long
+// enough to measure once per burst.
+const settleTime = 5 * time.Second
+
+// A Tracker keeps track of the disk space used by the grains in a directory,
+// each of which has a subdirectory named by its ID.
+type Tracker struct {
+	dir          string
+	log          *slog.Logger
+	scanInterval time.Duration
+
+	// MaxWatches limits the number of directories which are watched for
+	// changes. It must not be changed after calling Serve.
+	MaxWatches int
+
+	mu      sync.Mutex
+	usage   map[types.GrainID]int64
+	scanned bool // Whether every grain has been measured.
+}
+
+// NewTracker returns a Tracker for the grains in dir, which are all measured
+// every scanInterval. Call Serve to start measuring them.
+func NewTracker(lg *slog.Logger, dir string, scanInterval time.Duration) *Tracker {
+	return &Tracker{
+		dir:          dir,
+		log:          lg,
+		scanInterval: scanInterval,
+		MaxWatches:   DefaultMaxWatches,
+		usage:        make(map[types.GrainID]int64),
+	}
+}
+
+// Usage returns the disk space used by the grain, as of when it was last
+// measured, or zero if it hasn't been.
+func (t *Tracker) Usage(grainID types.GrainID) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.usage[grainID]
+}
+
+// Usages returns the disk space used by each grain which uses any, as for
+// Usage. ok is false if not every grain has been measured yet, in which case
+// the usage of those which haven't is missing.
+func (t *Tracker) Usages() (usage map[types.GrainID]int64, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return maps.Clone(t.usage), t.scanned
+}
+
+// Total returns the disk space used by all of the grains, as for Usage.
+func (t *Tracker) Total(grainIDs []types.GrainID) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var total int64
+	for _, id := range grainIDs {
+		total += t.usage[id]
+	}
+	return total
+}
+
+// Update measures the grain now, and returns its usage.
+func (t *Tracker) Update(grainID types.GrainID) (int64, error) {
+	n, err := Measure(filepath.Join(t.dir, string(grainID)))
+	if err != nil {
+		return 0, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if n == 0 {
+		delete(t.usage, grainID)
+	} else {
+		t.usage[grainID] = n
+	}
+	return n, nil
+}
+
+// Serve measures the grains, at startup, every scan interval, and after they
+// change, until ctx is canceled. It then returns ctx.Err().
+func (t *Tracker) Serve(ctx context.Context) error {
+	w := &watches{max: t.MaxWatches}
+	var (
+		events <-chan fsnotify.Event
+		errs   <-chan error
+	)
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		t.log.Warn("Can't watch grains for changes; storage usage is only measured periodically",
+			"error", err)
+	} else {
+		defer watcher.Close()
+		w.watcher = watcher
+		events, errs = watcher.Events, watcher.Errors
+	}
+	t.scan(w)
+
+	scan := time.NewTicker(t.scanInterval)
+	defer scan.Stop()
+	settle := time.NewTicker(settleTime / 2)
+	defer settle.Stop()
+	changed := make(map[types.GrainID]time.Time)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-scan.C:
+			t.scan(w)
+			clear(changed)
+		case <-settle.C:
+			now := time.Now()
+			for grainID, when := range changed {
+				if now.Sub(when) >= settleTime {
+					delete(changed, grainID)
+					t.update(grainID)
+				}
+			}
+		case event := <-events:
+			grainID, ok := t.grainFor(event.Name)
+			if !ok {
+				continue
+			}
+			if _, pending := changed[grainID]; !pending {
+				changed[grainID] = time.Now()
+			}
+			if event.Op&fsnotify.Create != 0 {
+				if fi, err := os.Lstat(event.Name); err == nil && fi.IsDir() {
+					w.addTree(event.Name)
+				}
+			}
+		case err := <-errs:
+			// Most likely the event queue overflowed, so we've missed
+			// changes; the next scan will catch up.
+			t.log.Warn("Watching grains for changes", "error", err)
+		}
+	}
+}
+
+// scan measures every grain, and watches any of their directories which
+// aren't yet watched.
+func (t *Tracker) scan(w *watches) {
+	entries, err := os.ReadDir(t.dir)
+	if err != nil {
+		t.log.Error("Listing grains to measure their storage", "error", err)
+		return
+	}
+	w.sync()
+	present := make(map[types.GrainID]bool, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		grainID := types.GrainID(e.Name())
+		present[grainID] = true
+		t.update(grainID)
+	}
+	// Watching the directory itself catches new grains.
+	w.addTree(t.dir)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for grainID := range t.usage {
+		if !present[grainID] {
+			delete(t.usage, grainID)
+		}
+	}
+	t.scanned = true
+}
+
+// update measures the grain, logging failures.
+func (t *Tracker) update(grainID types.GrainID) {
+	if _, err := t.Update(grainID); err != nil {
+		t.log.Warn("Measuring grain storage",
+			"error", err,
+			"grainID", grainID,
+		)
+	}
+}
+
+// watches keeps track of which directories a Tracker is watching. It is only
+// used by the Tracker's Serve goroutine.
+type watches struct {
+	watcher *fsnotify.Watcher // nil if watching isn't possible.
+	max     int
+	paths   map[string]bool
+}
+
+// sync forgets about directories which have been deleted, whose watches go
+// away by themselves.
+func (w *watches) sync() {
+	if w.watcher == nil {
+		return
+	}
+	w.paths = make(map[string]bool)
+	for _, path := range w.watcher.WatchList() {
+		w.paths[path] = true
+	}
+}
+
+// addTree watches dir and the directories under it, until the limit is
+// reached.
+func (w *watches) addTree(dir string) {
+	if w.watcher == nil {
+		return
+	}
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || w.paths[path] {
+			return nil
+		}
+		if len(w.paths) >= w.max {
+			return filepath.SkipAll
+		}
+		if err := w.watcher.Add(path); err != nil {
+			// e.g. the system-wide limit has been reached.
+			return filepath.SkipAll
+		}
+		w.paths[path] = true
+		return nil
+	})
+}
+
+// grainFor returns the grain whose directory contains path.
+func (t *Tracker) grainFor(path string) (types.GrainID, bool) {
+	rel, err := filepath.Rel(t.dir, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	name, _, _ := strings.Cut(rel, string(filepath.Separator))
+	return types.GrainID(name), true
+}
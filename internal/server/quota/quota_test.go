@@ -0,0 +1,91 @@
+package quota
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+	"sandstorm.org/go/tempest/internal/common/types"
+)
+
+func TestLimitReader(t *testing.T) {
+	data, err := io.ReadAll(LimitReader(strings.NewReader("hello"), 5))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	_, err = io.ReadAll(LimitReader(strings.NewReader("hello!"), 5))
+	assert.ErrorIs(t, err, ErrExceeded)
+
+	data, err = io.ReadAll(LimitReader(strings.NewReader("hello!"), -1))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello!", string(data))
+}
+
+func TestMeasure(t *testing.T) {
+	dir := t.TempDir()
+	n, err := Measure(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+
+	data := make([]byte, 64*1024)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "a"), data, 0600))
+	withOne, err := Measure(dir)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, withOne, int64(len(data)))
+
+	require.NoError(t, os.Link(filepath.Join(dir, "sub", "a"), filepath.Join(dir, "b")))
+	withLink, err := Measure(dir)
+	require.NoError(t, err)
+	assert.Equal(t, withOne, withLink, "hard links are counted once")
+}
+
+func TestTracker(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "grain1"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "grain1", "f"), make([]byte, 8192), 0600))
+
+	tr := NewTracker(slog.New(slog.NewTextHandler(io.Discard)), dir, 50*time.Millisecond)
+	_, ok := tr.Usages()
+	assert.False(t, ok, "not scanned yet")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- tr.Serve(ctx) }()
+	defer func() {
+		cancel()
+		assert.ErrorIs(t, <-done, context.Canceled)
+	}()
+
+	require.Eventually(t, func() bool {
+		return tr.Usage("grain1") > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	before := tr.Usage("grain1")
+	require.Eventually(t, func() bool {
+		_, ok := tr.Usages()
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+	usage, _ := tr.Usages()
+	assert.Equal(t, map[types.GrainID]int64{"grain1": before}, usage)
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "grain2"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "grain2", "f"), make([]byte, 8192), 0600))
+	require.Eventually(t, func() bool {
+		return tr.Usage("grain2") > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, before+tr.Usage("grain2"), tr.Total([]types.GrainID{"grain1", "grain2"}))
+
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "grain1")))
+	require.Eventually(t, func() bool {
+		return tr.Usage("grain1") == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
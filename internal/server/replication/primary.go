@@ -0,0 +1,200 @@
+package replication
+
+import (
+	"encoding/json"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slog"
+	"golang.org/x/sys/unix"
+	"sandstorm.org/go/tempest/internal/server/database"
+)
+
+// A Primary serves replication requests from standbys. It implements
+// http.Handler, and should be mounted at PathPrefix.
+type Primary struct {
+	Log *slog.Logger
+
+	// Shared secret which standbys must present.
+	Secret string
+
+	// Root of grain storage.
+	GrainsDir string
+
+	// Write-ahead log of the database; see database.OpenReplicated.
+	WAL *database.WAL
+
+	mu       sync.Mutex
+	lastSync time.Time
+}
+
+// Status returns the replication status of the primary.
+func (p *Primary) Status() Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return Status{
+		Role:     "primary",
+		LastSync: p.lastSync,
+	}
+}
+
+// ServeHTTP implements http.Handler.ServeHTTP
+func (p *Primary) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if !authorized(req, p.Secret) {
+		denyAccess(w)
+		return
+	}
+	switch req.URL.Path {
+	case PathPrefix + "/db":
+		p.serveDB(w, req)
+	case PathPrefix + "/wal":
+		p.serveWAL(w, req)
+	case PathPrefix + "/manifest":
+		p.serveManifest(w, req)
+	case PathPrefix + "/file":
+		p.serveFile(w, req)
+	case PathPrefix + "/status":
+		serveStatus(w, req, p.Status())
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// serveDB responds with the contents of the database file, and the position
+// in the log from which a standby should continue.
+func (p *Primary) serveDB(w http.ResponseWriter, req *http.Request) {
+	err := p.WAL.ReadBase(req.Context(), func(pos database.WALPosition, r io.Reader) error {
+		setPosition(w.Header(), pos)
+		w.Header().Set("Content-Type", "application/vnd.sqlite3")
+		_, err := io.Copy(w, r)
+		return err
+	})
+	if err != nil {
+		p.Log.Error("Replication: copying database file", "error", err)
+	}
+}
+
+// serveWAL responds with the part of the database's log following the
+// position given in the query, and the position at which it belongs. If the
+// log no longer has that position, it responds with 410 Gone.
+func (p *Primary) serveWAL(w http.ResponseWriter, req *http.Request) {
+	pos, err := parsePosition(req.URL.Query().Get)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	data, at, err := p.WAL.Read(req.Context(), pos, maxWALChunk)
+	if err == database.ErrWALPositionGone {
+		w.WriteHeader(http.StatusGone)
+		return
+	}
+	if err != nil {
+		p.replyErr(w, "reading database log", err)
+		return
+	}
+	p.mu.Lock()
+	p.lastSync = time.Now()
+	p.mu.Unlock()
+	setPosition(w.Header(), at)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(data)
+}
+
+// serveManifest responds with a JSON encoded Manifest of grain storage.
+func (p *Primary) serveManifest(w http.ResponseWriter, req *http.Request) {
+	manifest, err := readManifest(p.GrainsDir)
+	if err != nil {
+		p.replyErr(w, "reading grain storage", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(manifest)
+}
+
+// serveFile responds with the contents of the grain file named by the "path"
+// query parameter.
+func (p *Primary) serveFile(w http.ResponseWriter, req *http.Request) {
+	path := req.URL.Query().Get("path")
+	if !filepath.IsLocal(filepath.FromSlash(path)) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	f, err := openBeneath(p.GrainsDir, filepath.FromSlash(path))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil || !fi.Mode().IsRegular() {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	http.ServeContent(w, req, "", fi.ModTime(), f)
+}
+
+// openBeneath opens the file at path, relative to root, for reading, without
+// following symlinks. Grain storage is writable by apps, so a grain could
+// otherwise replace a file with a link to one of the host's, after
+// readManifest has listed it, and have it copied to the standby. The file is
+// opened non-blocking, in case it has been replaced by a FIFO; callers must
+// still check that it is a regular file.
+func openBeneath(root, path string) (*os.File, error) {
+	dir, err := os.Open(root)
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+	fd, err := unix.Openat2(int(dir.Fd()), path, &unix.OpenHow{
+		Flags:   unix.O_RDONLY | unix.O_CLOEXEC | unix.O_NOFOLLOW | unix.O_NONBLOCK,
+		Resolve: unix.RESOLVE_BENEATH | unix.RESOLVE_NO_SYMLINKS,
+	})
+	if err != nil {
+		return nil, &os.PathError{Op: "openat2", Path: path, Err: err}
+	}
+	return os.NewFile(uintptr(fd), filepath.Join(root, path)), nil
+}
+
+func (p *Primary) replyErr(w http.ResponseWriter, msg string, err error) {
+	p.Log.Error("Replication: "+msg, "error", err)
+	w.WriteHeader(http.StatusInternalServerError)
+}
+
+// readManifest lists the regular files and directories under root. Other kinds
+// of files (e.g. symlinks, device nodes) are not replicated.
+func readManifest(root string) (Manifest, error) {
+	var m Manifest
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == root {
+			return nil
+		}
+		if !d.Type().IsRegular() && !d.IsDir() {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		m.Files = append(m.Files, FileInfo{
+			Path:    filepath.ToSlash(rel),
+			Size:    fi.Size(),
+			ModTime: fi.ModTime(),
+			Mode:    fi.Mode(),
+		})
+		return nil
+	})
+	return m, err
+}
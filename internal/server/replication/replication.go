@@ -0,0 +1,171 @@
+// Package replication implements warm standby replication between Tempest
+// servers.
+//
+// A primary serves its database's write-ahead log, along with the contents of
+// its grain storage, to standbys that authenticate using a shared secret. A
+// standby periodically pulls from the primary: it appends whatever has been
+// added to the log since its last sync to its own copy (see database.WAL),
+// and then transfers only those grain files whose size or modification time
+// differ from its local copy, similar to rsync. The whole database file is
+// only copied when a standby starts, or when it has fallen too far behind to
+// continue from the log. If the primary is lost, an admin can promote the
+// standby (see Promote) and restart it as a normal server.
+//
+// Replication is asynchronous, so anything written to the primary after the
+// standby's last successful sync is lost on failover; Status reports how far
+// behind the standby is.
+package replication
+
+import (
+	"crypto/subtle"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"sandstorm.org/go/tempest/internal/config"
+	"sandstorm.org/go/tempest/internal/server/database"
+)
+
+const (
+	// PathPrefix is the URL path under which replication requests are served.
+	PathPrefix = "/_replication"
+
+	// PromotedPath is the path to a marker file which records that this server
+	// has been promoted from standby to primary.
+	PromotedPath = config.Localstatedir + "/sandstorm/promoted"
+)
+
+// Most of the database's log that the primary sends in one response.
+const maxWALChunk = 16 << 20
+
+// Names of the query parameters and response headers which hold a
+// database.WALPosition.
+const (
+	logParam        = "Tempest-Wal-Log"
+	generationParam = "Tempest-Wal-Generation"
+	offsetParam     = "Tempest-Wal-Offset"
+)
+
+// setPosition sets the headers in h which describe pos.
+func setPosition(h http.Header, pos database.WALPosition) {
+	h.Set(logParam, pos.Log)
+	h.Set(generationParam, strconv.FormatInt(pos.Generation, 10))
+	h.Set(offsetParam, strconv.FormatInt(pos.Offset, 10))
+}
+
+// positionQuery returns the query parameters which describe pos.
+func positionQuery(pos database.WALPosition) url.Values {
+	return url.Values{
+		logParam:        {pos.Log},
+		generationParam: {strconv.FormatInt(pos.Generation, 10)},
+		offsetParam:     {strconv.FormatInt(pos.Offset, 10)},
+	}
+}
+
+// parsePosition parses a position from the headers or query parameters set by
+// setPosition or positionQuery; get is their Get method.
+func parsePosition(get func(string) string) (database.WALPosition, error) {
+	pos := database.WALPosition{Log: get(logParam)}
+	var err error
+	if pos.Log == "" {
+		return pos, errors.New("missing log ID")
+	}
+	if pos.Generation, err = strconv.ParseInt(get(generationParam), 10, 64); err != nil {
+		return pos, err
+	}
+	if pos.Offset, err = strconv.ParseInt(get(offsetParam), 10, 64); err != nil {
+		return pos, err
+	}
+	if pos.Generation < 0 || pos.Offset < 0 {
+		return pos, errors.New("negative position")
+	}
+	return pos, nil
+}
+
+// A FileInfo describes a file or directory in grain storage.
+type FileInfo struct {
+	// Slash-separated path, relative to the root of grain storage.
+	Path    string      `json:"path"`
+	Size    int64       `json:"size"`
+	ModTime time.Time   `json:"modTime"`
+	Mode    fs.FileMode `json:"mode"`
+}
+
+// A Manifest lists the contents of grain storage on the primary.
+type Manifest struct {
+	Files []FileInfo `json:"files"`
+}
+
+// Status summarizes the state of replication, as seen by one server.
+type Status struct {
+	// Either "primary" or "standby".
+	Role string `json:"role"`
+
+	// On a standby, the time at which the last successful sync started;
+	// everything written to the primary before this time has been copied.
+	// On a primary, the last time a standby fetched from the database's log.
+	LastSync time.Time `json:"lastSync"`
+
+	// On a standby, how long ago LastSync was; this is an upper bound on
+	// the amount of data that would be lost by promoting the standby now.
+	Lag time.Duration `json:"lag"`
+
+	// Number of grain files transferred during the last sync.
+	FilesCopied int `json:"filesCopied"`
+
+	// Error from the last sync attempt, if it failed.
+	LastError string `json:"lastError,omitempty"`
+}
+
+// Promote marks this server as promoted, so that it will start as a primary
+// even if REPLICATION_PRIMARY_URL is set. The standby must be restarted for
+// this to take effect.
+func Promote() error {
+	f, err := os.OpenFile(PromotedPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, fs.ErrExist) {
+		return errors.New("this server has already been promoted")
+	}
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteString(time.Now().UTC().Format(time.RFC3339) + "\n")
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// IsPromoted reports whether Promote has been called on this server.
+func IsPromoted() bool {
+	_, err := os.Stat(PromotedPath)
+	return err == nil
+}
+
+// authorized reports whether req carries the shared secret, either as a bearer
+// token or as the password for HTTP basic auth (so that the status page can be
+// viewed in a browser).
+func authorized(req *http.Request, secret string) bool {
+	if secret == "" {
+		return false
+	}
+	given := ""
+	if _, password, ok := req.BasicAuth(); ok {
+		given = password
+	} else if token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); ok {
+		given = token
+	}
+	return subtle.ConstantTimeCompare([]byte(given), []byte(secret)) == 1
+}
+
+// denyAccess responds with 401 Unauthorized, prompting browsers for
+// credentials.
+func denyAccess(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Basic realm="Tempest replication"`)
+	w.WriteHeader(http.StatusUnauthorized)
+}
@@ -0,0 +1,172 @@
+package replication
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+	"golang.org/x/sys/unix"
+	"sandstorm.org/go/tempest/internal/server/database"
+)
+
+// Sync a standby from a primary, and check that the grain storage and database
+// are copied, including deletions.
+func TestSync(t *testing.T) {
+	lg := slog.New(slog.HandlerOptions{}.NewTextHandler(os.Stderr))
+	primaryDir := t.TempDir()
+	standbyDir := t.TempDir()
+
+	db, err := database.OpenReplicatedPath(filepath.Join(primaryDir, "db.sqlite3"))
+	require.NoError(t, err)
+	defer db.Close()
+	setServerSetting(t, db, "WELCOME", "hello")
+	primary := &Primary{
+		Log:       lg,
+		Secret:    "hunter2",
+		GrainsDir: filepath.Join(primaryDir, "grains"),
+		WAL:       db.WAL(),
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(primary.GrainsDir, "grain1/sandbox"), 0700))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(primary.GrainsDir, "grain1/sandbox/data.txt"),
+		[]byte("hello"),
+		0600,
+	))
+	srv := httptest.NewServer(primary)
+	defer srv.Close()
+
+	standby := &Standby{
+		Log:        lg,
+		PrimaryURL: srv.URL,
+		Secret:     "hunter2",
+		Interval:   time.Minute,
+		GrainsDir:  filepath.Join(standbyDir, "grains"),
+		DBPath:     filepath.Join(standbyDir, "db.sqlite3"),
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(standby.GrainsDir, "stale/dir"), 0700))
+
+	n, err := standby.SyncOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	data, err := os.ReadFile(filepath.Join(standby.GrainsDir, "grain1/sandbox/data.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+	assert.Equal(t, map[string]string{"WELCOME": "hello"}, standbySettings(t, standby))
+	_, err = os.Stat(filepath.Join(standby.GrainsDir, "stale"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Nothing changed, so a second sync shouldn't copy anything:
+	n, err = standby.SyncOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	// Later changes to the database are shipped in the log, without copying
+	// the database file again.
+	fi, err := os.Stat(standby.DBPath)
+	require.NoError(t, err)
+	setServerSetting(t, db, "GOODBYE", "bye")
+	_, err = standby.SyncOnce(context.Background())
+	require.NoError(t, err)
+	newFi, err := os.Stat(standby.DBPath)
+	require.NoError(t, err)
+	assert.True(t, os.SameFile(fi, newFi), "database file was replaced")
+	assert.Equal(t, map[string]string{"WELCOME": "hello", "GOODBYE": "bye"},
+		standbySettings(t, standby))
+
+	// If the standby's position is lost, it starts again from a copy of the
+	// database file.
+	require.NoError(t, os.Remove(standby.positionPath()))
+	_, err = standby.SyncOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"WELCOME": "hello", "GOODBYE": "bye"},
+		standbySettings(t, standby))
+}
+
+func setServerSetting(t *testing.T, db database.DB, name, value string) {
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	defer tx.Rollback()
+	require.NoError(t, tx.SetServerSettings(map[string]string{name: value}))
+	require.NoError(t, tx.Commit())
+}
+
+// standbySettings returns the server settings in a copy of the standby's
+// database, as it would be opened if the standby were promoted.
+func standbySettings(t *testing.T, standby *Standby) map[string]string {
+	path := filepath.Join(t.TempDir(), "db.sqlite3")
+	for _, suffix := range []string{"", "-wal"} {
+		data, err := os.ReadFile(standby.DBPath + suffix)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path+suffix, data, 0600))
+	}
+	db, err := database.OpenPath(path)
+	require.NoError(t, err)
+	defer db.Close()
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	defer tx.Rollback()
+	settings, err := tx.ServerSettings()
+	require.NoError(t, err)
+	return settings
+}
+
+// Make sure the primary refuses requests without the shared secret.
+func TestPrimaryRequiresSecret(t *testing.T) {
+	primary := &Primary{Secret: "hunter2"}
+	for _, auth := range []string{"", "Bearer wrong", "hunter2"} {
+		req := httptest.NewRequest("GET", PathPrefix+"/manifest", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		primary.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "auth: %q", auth)
+	}
+}
+
+// Make sure the primary won't follow symlinks out of grain storage, which
+// apps can write, or serve anything but regular files.
+func TestServeFileRefusesSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	primary := &Primary{
+		Log:       slog.New(slog.HandlerOptions{}.NewTextHandler(os.Stderr)),
+		Secret:    "hunter2",
+		GrainsDir: filepath.Join(dir, "grains"),
+	}
+	sandbox := filepath.Join(primary.GrainsDir, "grain1/sandbox")
+	require.NoError(t, os.MkdirAll(sandbox, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(sandbox, "data.txt"), []byte("hello"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "host-secret"), []byte("secret"), 0600))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "host-secret"), filepath.Join(sandbox, "link")))
+	require.NoError(t, os.Symlink(dir, filepath.Join(sandbox, "linkdir")))
+	require.NoError(t, os.Symlink("data.txt", filepath.Join(sandbox, "relative-link")))
+	require.NoError(t, unix.Mkfifo(filepath.Join(sandbox, "fifo"), 0600))
+
+	for path, want := range map[string]int{
+		"grain1/sandbox/data.txt":            http.StatusOK,
+		"grain1/sandbox/link":                http.StatusNotFound,
+		"grain1/sandbox/linkdir/host-secret": http.StatusNotFound,
+		"grain1/sandbox/relative-link":       http.StatusNotFound,
+		"grain1/sandbox/fifo":                http.StatusNotFound,
+		"grain1/sandbox":                     http.StatusNotFound,
+		"../host-secret":                     http.StatusBadRequest,
+	} {
+		req := httptest.NewRequest("GET", PathPrefix+"/file?path="+url.QueryEscape(path), nil)
+		req.Header.Set("Authorization", "Bearer hunter2")
+		w := httptest.NewRecorder()
+		primary.ServeHTTP(w, req)
+		assert.Equal(t, want, w.Code, "path: %q", path)
+		if want == http.StatusOK {
+			assert.Equal(t, "hello", w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,397 @@
+package replication
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slog"
+	"sandstorm.org/go/tempest/internal/server/database"
+)
+
+// A Standby periodically copies state from a primary server.
+type Standby struct {
+	Log *slog.Logger
+
+	// Base URL of the primary, e.g. "https://primary.example.com".
+	PrimaryURL string
+
+	// Shared secret to present to the primary.
+	Secret string
+
+	// How often to sync.
+	Interval time.Duration
+
+	// Local root of grain storage.
+	GrainsDir string
+
+	// Local path at which to store the database.
+	DBPath string
+
+	// HTTP client to use; if nil, http.DefaultClient is used.
+	Client *http.Client
+
+	mu     sync.Mutex
+	status Status
+}
+
+// Status returns the replication status of the standby.
+func (s *Standby) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ret := s.status
+	ret.Role = "standby"
+	if !ret.LastSync.IsZero() {
+		ret.Lag = time.Since(ret.LastSync)
+	}
+	return ret
+}
+
+// ServeHTTP serves the standby's status page, at PathPrefix + "/status".
+func (s *Standby) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path != PathPrefix+"/status" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintln(w, "This server is a standby; it does not serve users until promoted.")
+		return
+	}
+	if !authorized(req, s.Secret) {
+		denyAccess(w)
+		return
+	}
+	serveStatus(w, req, s.Status())
+}
+
+// Run syncs from the primary every s.Interval, until ctx is canceled.
+func (s *Standby) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		start := time.Now()
+		n, err := s.SyncOnce(ctx)
+		s.mu.Lock()
+		s.status.FilesCopied = n
+		if err != nil {
+			s.status.LastError = err.Error()
+			s.Log.Error("Replication: sync from primary failed", "error", err)
+		} else {
+			s.status.LastError = ""
+			s.status.LastSync = start
+			s.Log.Debug("Replication: synced from primary",
+				"files-copied", n,
+				"duration", time.Since(start),
+			)
+		}
+		s.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncOnce copies the database's log and any changed grain files from the
+// primary.
+// It returns the number of grain files which were transferred.
+//
+// The database is copied first, so that grain storage on the standby is never
+// older than the database which refers to it.
+func (s *Standby) SyncOnce(ctx context.Context) (int, error) {
+	if err := s.syncDB(ctx); err != nil {
+		return 0, fmt.Errorf("copying database: %w", err)
+	}
+	n, err := s.syncGrains(ctx)
+	if err != nil {
+		return n, fmt.Errorf("copying grain storage: %w", err)
+	}
+	return n, nil
+}
+
+// syncDB brings the standby's copy of the database up to date, by appending
+// to its copy of the database's log. The standby's position in the log is
+// stored alongside the database, at s.positionPath().
+func (s *Standby) syncDB(ctx context.Context) error {
+	pos, err := s.readPosition()
+	if errors.Is(err, fs.ErrNotExist) {
+		pos, err = s.copyBase(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	startedOver := false
+	for {
+		data, at, err := s.fetchWAL(ctx, pos)
+		if err == errWALGone && !startedOver {
+			s.Log.Info("Replication: standby fell behind the primary's log; copying the whole database")
+			startedOver = true
+			if pos, err = s.copyBase(ctx); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if at.Generation != pos.Generation {
+			// The primary has checkpointed its log and started a new
+			// one; do likewise.
+			if err := database.CheckpointFile(s.DBPath); err != nil {
+				return fmt.Errorf("checkpointing: %w", err)
+			}
+		}
+		if err := s.writeWAL(data, at.Offset); err != nil {
+			return err
+		}
+		pos = at
+		pos.Offset += int64(len(data))
+		if err := s.writePosition(pos); err != nil {
+			return err
+		}
+		if len(data) < maxWALChunk {
+			return nil
+		}
+	}
+}
+
+// copyBase replaces the standby's copy of the database with the primary's
+// database file, and returns the position in the log from which it must be
+// brought up to date.
+func (s *Standby) copyBase(ctx context.Context) (database.WALPosition, error) {
+	// If we're interrupted, we must start over; the old position doesn't
+	// apply to the new file.
+	err := os.Remove(s.positionPath())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return database.WALPosition{}, err
+	}
+	resp, err := s.do(ctx, "/db", nil)
+	if err != nil {
+		return database.WALPosition{}, err
+	}
+	defer resp.Body.Close()
+	pos, err := parsePosition(resp.Header.Get)
+	if err != nil {
+		return pos, fmt.Errorf("primary sent invalid log position: %w", err)
+	}
+	if err = writeAtomically(s.DBPath, resp.Body, 0600, time.Now()); err != nil {
+		return pos, err
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		err := os.Remove(s.DBPath + suffix)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return pos, err
+		}
+	}
+	return pos, s.writePosition(pos)
+}
+
+// errWALGone is returned by fetchWAL if the primary's log no longer has the
+// requested position.
+var errWALGone = errors.New("primary's log has moved past the standby's position")
+
+// fetchWAL fetches the part of the primary's log following pos, and returns it
+// along with the position at which it belongs.
+func (s *Standby) fetchWAL(ctx context.Context, pos database.WALPosition) ([]byte, database.WALPosition, error) {
+	resp, err := s.do(ctx, "/wal", positionQuery(pos))
+	if err != nil {
+		return nil, pos, err
+	}
+	defer resp.Body.Close()
+	at, err := parsePosition(resp.Header.Get)
+	if err != nil {
+		return nil, pos, fmt.Errorf("primary sent invalid log position: %w", err)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxWALChunk+1))
+	if err != nil {
+		return nil, pos, err
+	}
+	if len(data) > maxWALChunk {
+		return nil, pos, errors.New("primary sent too much of its log")
+	}
+	return data, at, nil
+}
+
+// writeWAL writes data to the standby's copy of the log, at the given offset.
+func (s *Standby) writeWAL(data []byte, offset int64) error {
+	f, err := os.OpenFile(s.DBPath+"-wal", os.O_WRONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteAt(data, offset)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (s *Standby) positionPath() string {
+	return s.DBPath + "-position"
+}
+
+func (s *Standby) readPosition() (database.WALPosition, error) {
+	var pos database.WALPosition
+	data, err := os.ReadFile(s.positionPath())
+	if err != nil {
+		return pos, err
+	}
+	return pos, json.Unmarshal(data, &pos)
+}
+
+func (s *Standby) writePosition(pos database.WALPosition) error {
+	data, err := json.Marshal(pos)
+	if err != nil {
+		return err
+	}
+	return writeAtomically(s.positionPath(), bytes.NewReader(data), 0600, time.Now())
+}
+
+func (s *Standby) syncGrains(ctx context.Context) (int, error) {
+	body, err := s.get(ctx, "/manifest", nil)
+	if err != nil {
+		return 0, err
+	}
+	var remote Manifest
+	err = json.NewDecoder(body).Decode(&remote)
+	body.Close()
+	if err != nil {
+		return 0, err
+	}
+	local, err := readManifest(s.GrainsDir)
+	if err != nil {
+		return 0, err
+	}
+	localFiles := make(map[string]FileInfo, len(local.Files))
+	for _, fi := range local.Files {
+		localFiles[fi.Path] = fi
+	}
+
+	copied := 0
+	for _, fi := range remote.Files {
+		if !filepath.IsLocal(filepath.FromSlash(fi.Path)) {
+			return copied, fmt.Errorf("primary sent invalid path: %q", fi.Path)
+		}
+		path := filepath.Join(s.GrainsDir, filepath.FromSlash(fi.Path))
+		old, ok := localFiles[fi.Path]
+		delete(localFiles, fi.Path)
+		if fi.Mode.IsDir() {
+			if !ok || !old.Mode.IsDir() {
+				os.RemoveAll(path)
+				if err := os.MkdirAll(path, fi.Mode.Perm()); err != nil {
+					return copied, err
+				}
+			}
+			continue
+		}
+		if ok && old.Mode == fi.Mode && old.Size == fi.Size && old.ModTime.Equal(fi.ModTime) {
+			continue
+		}
+		if ok && old.Mode.IsDir() {
+			os.RemoveAll(path)
+		}
+		if err := s.copyFile(ctx, fi, path); err != nil {
+			return copied, err
+		}
+		copied++
+	}
+
+	// Whatever is left was deleted on the primary. Remove the longest paths
+	// first, so that directories are emptied before they are removed.
+	var stale []string
+	for path := range localFiles {
+		stale = append(stale, path)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(stale)))
+	for _, path := range stale {
+		err := os.RemoveAll(filepath.Join(s.GrainsDir, filepath.FromSlash(path)))
+		if err != nil {
+			return copied, err
+		}
+	}
+	return copied, nil
+}
+
+func (s *Standby) copyFile(ctx context.Context, fi FileInfo, dest string) error {
+	body, err := s.get(ctx, "/file", url.Values{"path": {fi.Path}})
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return writeAtomically(dest, body, fi.Mode.Perm(), fi.ModTime)
+}
+
+// writeAtomically writes the contents of r to a temporary file, and then renames
+// it to path, so that readers never see a partially written file.
+func writeAtomically(path string, r io.Reader, perm os.FileMode, modTime time.Time) error {
+	f, err := os.CreateTemp(filepath.Dir(path), ".replication-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = io.Copy(f, r)
+	if err == nil {
+		err = f.Chmod(perm)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err = os.Chtimes(f.Name(), modTime, modTime); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// get fetches path (relative to PathPrefix) from the primary, returning the
+// response body.
+func (s *Standby) get(ctx context.Context, path string, query url.Values) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, path, query)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// do is like get, but returns the whole response. It returns errWALGone if the
+// primary responds with 410 Gone.
+func (s *Standby) do(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	u := s.PrimaryURL + PathPrefix + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.Secret)
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusGone {
+			return nil, errWALGone
+		}
+		return nil, fmt.Errorf("GET %s: %s", path, resp.Status)
+	}
+	return resp, nil
+}
@@ -0,0 +1,43 @@
+package replication
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+var statusTemplate = template.Must(template.New("status").Parse(`<!doctype html>
+<html>
+<head>
+<meta charset="utf-8" />
+<title>Replication status</title>
+</head>
+<body>
+<h1>Replication status</h1>
+<dl>
+	<dt>Role</dt><dd>{{.Role}}</dd>
+	{{if eq .Role "standby"}}
+	<dt>Last successful sync</dt><dd>{{if .LastSync.IsZero}}never{{else}}{{.LastSync}}{{end}}</dd>
+	<dt>Replication lag</dt><dd>{{.Lag}}</dd>
+	<dt>Grain files copied in last sync</dt><dd>{{.FilesCopied}}</dd>
+	{{if .LastError}}<dt>Last error</dt><dd>{{.LastError}}</dd>{{end}}
+	{{else}}
+	<dt>Last fetched by a standby</dt><dd>{{if .LastSync.IsZero}}never{{else}}{{.LastSync}}{{end}}</dd>
+	{{end}}
+</dl>
+</body>
+</html>
+`))
+
+// serveStatus responds with status, formatted as an HTML page for browsers
+// and as JSON otherwise.
+func serveStatus(w http.ResponseWriter, req *http.Request, status Status) {
+	if strings.Contains(req.Header.Get("Accept"), "text/html") {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		statusTemplate.Execute(w, status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
@@ -0,0 +1,207 @@
+// Package reqlimit limits how large HTTP requests may be and how long they may
+// take, so that slow or oversized requests are cut off before they tie up a
+// grain, and how many requests each grain may serve at once.
+//
+// The limits on headers, and on idle connections, are the http.Server's, and
+// so apply to the whole server; the others are enforced for each request by
+// Config.Handler, and may differ for each kind of host, e.g. grains' UI
+// hosts.
+package reqlimit
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limits are the limits on each request to a host. Zero means no limit.
+type Limits struct {
+	MaxBodySize int64 // In bytes.
+
+	// How long reading the request's body may take.
+	ReadTimeout time.Duration
+
+	// How long writing the response may take. Handlers which stream
+	// responses of unbounded length may lift this once they start.
+	WriteTimeout time.Duration
+}
+
+// Config configures the limits.
+type Config struct {
+	// Limits on the size of requests' headers, in bytes, and on how long
+	// idle connections are kept open. These apply to the whole server;
+	// see ApplyTo.
+	MaxHeaderSize int
+	IdleTimeout   time.Duration
+
+	// Limits on requests to each kind of host, and on requests to hosts
+	// of kinds which have none of their own.
+	Hosts   map[string]Limits
+	Default Limits
+
+	// How many requests each grain may be serving at once; zero for no
+	// limit. See Counter.
+	MaxGrainRequests int
+}
+
+// ApplyTo sets srv's limits to those of the config.
+func (c Config) ApplyTo(srv *http.Server) {
+	srv.MaxHeaderBytes = c.MaxHeaderSize
+	srv.IdleTimeout = c.IdleTimeout
+}
+
+// Limits returns the limits on requests to hosts of the given kind.
+func (c Config) Limits(kind string) Limits {
+	if l, ok := c.Hosts[kind]; ok {
+		return l
+	}
+	return c.Default
+}
+
+// Handler returns a handler which enforces the limits on each request before
+// passing it to h. kind returns the kind of host the request is for.
+func (c Config) Handler(h http.Handler, kind func(*http.Request) string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		l := c.Limits(kind(req))
+		if l.MaxBodySize > 0 {
+			if req.ContentLength > l.MaxBodySize {
+				w.Header().Set("Connection", "close")
+				http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			req.Body = http.MaxBytesReader(w, req.Body, l.MaxBodySize)
+		}
+		// The errors are only that the connection doesn't support
+		// deadlines, as with HTTP/2 in some cases, in which case the
+		// server's own timeouts will have to do.
+		rc := http.NewResponseController(w)
+		now := time.Now()
+		if l.ReadTimeout > 0 {
+			rc.SetReadDeadline(now.Add(l.ReadTimeout))
+		}
+		if l.WriteTimeout > 0 {
+			rc.SetWriteDeadline(now.Add(l.WriteTimeout))
+			// Otherwise it would still apply to the next request
+			// on the connection.
+			defer rc.SetWriteDeadline(time.Time{})
+		}
+		h.ServeHTTP(w, req)
+	})
+}
+
+// ParseHosts parses limits on requests to each kind of host, as in the
+// HTTP_HOST_LIMITS setting: a list separated by semicolons of a kind, a
+// colon, and then fields separated by spaces, each of which is "body=" and a
+// number of bytes, or "read=" or "write=" and a duration in the format
+// accepted by time.ParseDuration, e.g.
+//
+//	grain: body=1073741824 read=1h; api: body=10485760 write=1m
+//
+// Limits left out are those of defaults. kinds are the valid kinds of hosts.
+func ParseHosts(s string, defaults Limits, kinds ...string) (map[string]Limits, error) {
+	ret := make(map[string]Limits)
+	for _, item := range strings.Split(s, ";") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		kind, fields, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, fmt.Errorf("missing ':' in %q", item)
+		}
+		kind = strings.TrimSpace(kind)
+		if !slices.Contains(kinds, kind) {
+			return nil, fmt.Errorf("unknown kind of host %q; must be one of %s",
+				kind, strings.Join(kinds, ", "))
+		}
+		if _, dup := ret[kind]; dup {
+			return nil, fmt.Errorf("limits for %q given twice", kind)
+		}
+		l := defaults
+		for _, field := range strings.Fields(fields) {
+			if err := l.set(field); err != nil {
+				return nil, fmt.Errorf("limits for %q: %w", kind, err)
+			}
+		}
+		ret[kind] = l
+	}
+	return ret, nil
+}
+
+// set sets one of l's limits from a field of a HTTP_HOST_LIMITS item.
+func (l *Limits) set(field string) error {
+	name, value, _ := strings.Cut(field, "=")
+	var err error
+	switch name {
+	case "body":
+		l.MaxBodySize, err = strconv.ParseInt(value, 10, 64)
+		if err == nil && l.MaxBodySize < 0 {
+			err = fmt.Errorf("must not be negative")
+		}
+	case "read":
+		l.ReadTimeout, err = parseTimeout(value)
+	case "write":
+		l.WriteTimeout, err = parseTimeout(value)
+	default:
+		return fmt.Errorf("unknown limit %q; must be body, read or write", name)
+	}
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", name, err)
+	}
+	return nil
+}
+
+func parseTimeout(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err == nil && d < 0 {
+		err = fmt.Errorf("must not be negative")
+	}
+	return d, err
+}
+
+// A Counter limits how many requests may be in progress for each key, e.g.
+// each grain, at once. It is safe for concurrent use.
+type Counter struct {
+	max int
+
+	mu       sync.Mutex
+	inFlight map[string]int
+}
+
+// NewCounter returns a Counter which allows up to max requests for each key
+// at once, or any number if max is zero.
+func NewCounter(max int) *Counter {
+	return &Counter{
+		max:      max,
+		inFlight: make(map[string]int),
+	}
+}
+
+// Begin records the start of a request for key. If key already has the
+// maximum number of requests in progress, it returns false; otherwise, it
+// returns a function to call when the request is done.
+func (c *Counter) Begin(key string) (end func(), ok bool) {
+	if c.max <= 0 {
+		return func() {}, true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.inFlight[key] >= c.max {
+		return nil, false
+	}
+	c.inFlight[key]++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.inFlight[key]--
+			if c.inFlight[key] == 0 {
+				delete(c.inFlight, key)
+			}
+		})
+	}, true
+}
@@ -0,0 +1,108 @@
+package reqlimit
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHosts(t *testing.T) {
+	defaults := Limits{MaxBodySize: 100, ReadTimeout: time.Minute}
+	hosts, err := ParseHosts(" grain: body=0 read=1h write=5m;api:body=10 ; ", defaults,
+		"main", "grain", "api")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Limits{
+		"grain": {MaxBodySize: 0, ReadTimeout: time.Hour, WriteTimeout: 5 * time.Minute},
+		"api":   {MaxBodySize: 10, ReadTimeout: time.Minute},
+	}, hosts)
+
+	hosts, err = ParseHosts("", defaults, "main")
+	require.NoError(t, err)
+	assert.Empty(t, hosts)
+
+	for _, bad := range []string{
+		"grain body=1",
+		"other: body=1",
+		"grain: body=1; grain: read=1s",
+		"grain: body=-1",
+		"grain: read=soon",
+		"grain: size=1",
+	} {
+		_, err := ParseHosts(bad, defaults, "main", "grain")
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestHandlerBodySize(t *testing.T) {
+	cfg := Config{
+		Default: Limits{MaxBodySize: 4},
+		Hosts:   map[string]Limits{"big": {MaxBodySize: 100}},
+	}
+	h := cfg.Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.Write(body)
+	}), func(req *http.Request) string {
+		return req.Host
+	})
+
+	cases := []struct {
+		host, body string
+		chunked    bool
+		want       int
+	}{
+		{"small", "abcd", false, http.StatusOK},
+		{"small", "abcde", false, http.StatusRequestEntityTooLarge},
+		// Without a Content-Length, the body is cut off as it is read:
+		{"small", "abcde", true, http.StatusRequestEntityTooLarge},
+		{"big", "abcde", false, http.StatusOK},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(c.body))
+		req.Host = c.host
+		if c.chunked {
+			req.ContentLength = -1
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		assert.Equal(t, c.want, rec.Code, "%+v", c)
+	}
+}
+
+func TestCounter(t *testing.T) {
+	c := NewCounter(2)
+	end1, ok := c.Begin("a")
+	require.True(t, ok)
+	end2, ok := c.Begin("a")
+	require.True(t, ok)
+	_, ok = c.Begin("a")
+	assert.False(t, ok)
+	endB, ok := c.Begin("b")
+	assert.True(t, ok)
+	endB()
+
+	end1()
+	end1() // Calling end again does nothing.
+	end3, ok := c.Begin("a")
+	assert.True(t, ok)
+	_, ok = c.Begin("a")
+	assert.False(t, ok)
+	end2()
+	end3()
+	assert.Empty(t, c.inFlight)
+
+	unlimited := NewCounter(0)
+	for i := 0; i < 10; i++ {
+		_, ok := unlimited.Begin("a")
+		assert.True(t, ok)
+	}
+}
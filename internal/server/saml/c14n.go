@@ -0,0 +1,361 @@
+package saml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Namespaces of the elements we look at.
+const (
+	nsAssertion = "urn:oasis:names:tc:SAML:2.0:assertion"
+	nsProtocol  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	nsMetadata  = "urn:oasis:names:tc:SAML:2.0:metadata"
+	nsDSig      = "http://www.w3.org/2000/09/xmldsig#"
+	nsExcC14N   = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	nsXML       = "http://www.w3.org/XML/1998/namespace"
+)
+
+// An element in a parsed XML document.
+//
+// We need our own tree, rather than encoding/xml's unmarshalling, because
+// checking a signature means reproducing the signed bytes exactly, which
+// depends on the namespace prefixes and declarations in the document.
+type element struct {
+	Prefix, Local string
+	Space         string      // The namespace, resolved from Prefix.
+	NSDecls       []xml.Attr  // Namespace declarations, e.g. xmlns:p="...".
+	Attrs         []attribute // Other attributes.
+	Children      []any       // *element, charData or xml.ProcInst.
+	Parent        *element
+}
+
+type attribute struct {
+	Prefix, Local string
+	Space         string // The namespace; empty if Prefix is.
+	Value         string
+}
+
+type charData string
+
+// parseXML parses a document, returning its root element. Documents with a
+// DTD are rejected. Comments are dropped, since the canonical form which
+// signatures cover leaves them out.
+func parseXML(data []byte) (*element, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	var (
+		root *element
+		cur  *element
+	)
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if cur == nil && root != nil {
+				return nil, errors.New("more than one root element")
+			}
+			el := &element{
+				Prefix: tok.Name.Space,
+				Local:  tok.Name.Local,
+				Parent: cur,
+			}
+			for _, attr := range tok.Attr {
+				switch {
+				case attr.Name.Space == "xmlns":
+					el.NSDecls = append(el.NSDecls, attr)
+				case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+					el.NSDecls = append(el.NSDecls, attr)
+				default:
+					el.Attrs = append(el.Attrs, attribute{
+						Prefix: attr.Name.Space,
+						Local:  attr.Name.Local,
+						Value:  attr.Value,
+					})
+				}
+			}
+			var ok bool
+			if el.Space, ok = el.lookupNS(el.Prefix); !ok {
+				return nil, fmt.Errorf("undeclared namespace prefix %q", el.Prefix)
+			}
+			for i := range el.Attrs {
+				if el.Attrs[i].Prefix == "" {
+					continue
+				}
+				if el.Attrs[i].Space, ok = el.lookupNS(el.Attrs[i].Prefix); !ok {
+					return nil, fmt.Errorf("undeclared namespace prefix %q", el.Attrs[i].Prefix)
+				}
+			}
+			if cur == nil {
+				root = el
+			} else {
+				cur.Children = append(cur.Children, el)
+			}
+			cur = el
+		case xml.EndElement:
+			if cur == nil || tok.Name.Space != cur.Prefix || tok.Name.Local != cur.Local {
+				return nil, fmt.Errorf("unexpected end tag %q", tok.Name.Local)
+			}
+			cur = cur.Parent
+		case xml.CharData:
+			if cur != nil {
+				cur.Children = append(cur.Children, charData(tok))
+			} else if len(bytes.TrimSpace(tok)) > 0 {
+				return nil, errors.New("text outside of the root element")
+			}
+		case xml.ProcInst:
+			if cur != nil {
+				cur.Children = append(cur.Children, tok.Copy())
+			}
+		case xml.Directive:
+			return nil, errors.New("DTDs and other directives are not allowed")
+		case xml.Comment:
+		}
+	}
+	if root == nil || cur != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return root, nil
+}
+
+// lookupNS returns the namespace bound to prefix in el's scope.
+func (el *element) lookupNS(prefix string) (string, bool) {
+	if prefix == "xml" {
+		return nsXML, true
+	}
+	for e := el; e != nil; e = e.Parent {
+		for _, decl := range e.NSDecls {
+			if nsDeclPrefix(decl) == prefix {
+				return decl.Value, true
+			}
+		}
+	}
+	// The default namespace is empty unless declared:
+	return "", prefix == ""
+}
+
+// nsDeclPrefix returns the prefix declared by a namespace declaration; empty
+// for the default namespace.
+func nsDeclPrefix(decl xml.Attr) string {
+	if decl.Name.Space == "xmlns" {
+		return decl.Name.Local
+	}
+	return ""
+}
+
+// is returns true if el has the given namespace and local name.
+func (el *element) is(space, local string) bool {
+	return el.Space == space && el.Local == local
+}
+
+// attr returns the value of el's unqualified attribute with the given name.
+func (el *element) attr(name string) (string, bool) {
+	for _, a := range el.Attrs {
+		if a.Prefix == "" && a.Local == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// children returns el's child elements with the given namespace and local
+// name.
+func (el *element) children(space, local string) []*element {
+	var ret []*element
+	for _, c := range el.Children {
+		if c, ok := c.(*element); ok && c.is(space, local) {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+// child returns el's only child with the given name, or nil if there are
+// none or several.
+func (el *element) child(space, local string) *element {
+	kids := el.children(space, local)
+	if len(kids) != 1 {
+		return nil
+	}
+	return kids[0]
+}
+
+// text returns the text directly inside el.
+func (el *element) text() string {
+	var sb strings.Builder
+	for _, c := range el.Children {
+		if c, ok := c.(charData); ok {
+			sb.WriteString(string(c))
+		}
+	}
+	return sb.String()
+}
+
+// canonicalize returns the exclusive canonical form
+// (https://www.w3.org/TR/xml-exc-c14n/, without comments) of the subtree
+// rooted at el, leaving out exclude and its descendants, as the enveloped
+// signature transform requires. inclusive lists the prefixes which are to
+// be treated as in inclusive canonicalization ("#default" for the default
+// namespace), as given by an InclusiveNamespaces element.
+func (el *element) canonicalize(exclude *element, inclusive []string) []byte {
+	c := canonicalizer{
+		exclude:   exclude,
+		inclusive: make(map[string]bool, len(inclusive)),
+	}
+	for _, prefix := range inclusive {
+		if prefix == "#default" {
+			prefix = ""
+		}
+		c.inclusive[prefix] = true
+	}
+	c.element(el, map[string]string{"": ""})
+	return c.buf.Bytes()
+}
+
+type canonicalizer struct {
+	buf       bytes.Buffer
+	exclude   *element
+	inclusive map[string]bool
+}
+
+// element writes out el. rendered maps each prefix to the namespace which
+// the nearest output ancestor bound it to.
+func (c *canonicalizer) element(el *element, rendered map[string]string) {
+	if el == c.exclude {
+		return
+	}
+
+	// Which prefixes need declarations: those visibly used by the element
+	// or its attributes, and those in the inclusive list.
+	used := map[string]bool{el.Prefix: true}
+	for _, a := range el.Attrs {
+		if a.Prefix != "" {
+			used[a.Prefix] = true
+		}
+	}
+	for prefix := range c.inclusive {
+		if _, ok := el.lookupNS(prefix); ok {
+			used[prefix] = true
+		}
+	}
+	type decl struct{ prefix, space string }
+	var decls []decl
+	for prefix := range used {
+		if prefix == "xml" {
+			continue
+		}
+		space, _ := el.lookupNS(prefix)
+		if prev, ok := rendered[prefix]; ok && prev == space {
+			continue
+		}
+		decls = append(decls, decl{prefix, space})
+	}
+	sort.Slice(decls, func(i, j int) bool {
+		return decls[i].prefix < decls[j].prefix
+	})
+	if len(decls) > 0 {
+		next := make(map[string]string, len(rendered)+len(decls))
+		for k, v := range rendered {
+			next[k] = v
+		}
+		for _, d := range decls {
+			next[d.prefix] = d.space
+		}
+		rendered = next
+	}
+
+	attrs := append([]attribute(nil), el.Attrs...)
+	sort.Slice(attrs, func(i, j int) bool {
+		if attrs[i].Space != attrs[j].Space {
+			return attrs[i].Space < attrs[j].Space
+		}
+		return attrs[i].Local < attrs[j].Local
+	})
+
+	name := qname(el.Prefix, el.Local)
+	c.buf.WriteString("<" + name)
+	for _, d := range decls {
+		if d.prefix == "" {
+			c.buf.WriteString(` xmlns="`)
+		} else {
+			c.buf.WriteString(" xmlns:" + d.prefix + `="`)
+		}
+		escapeAttr(&c.buf, d.space)
+		c.buf.WriteString(`"`)
+	}
+	for _, a := range attrs {
+		c.buf.WriteString(" " + qname(a.Prefix, a.Local) + `="`)
+		escapeAttr(&c.buf, a.Value)
+		c.buf.WriteString(`"`)
+	}
+	c.buf.WriteString(">")
+	for _, child := range el.Children {
+		switch child := child.(type) {
+		case *element:
+			c.element(child, rendered)
+		case charData:
+			escapeText(&c.buf, string(child))
+		case xml.ProcInst:
+			c.buf.WriteString("<?" + child.Target)
+			if len(child.Inst) > 0 {
+				c.buf.WriteString(" ")
+				c.buf.Write(child.Inst)
+			}
+			c.buf.WriteString("?>")
+		}
+	}
+	c.buf.WriteString("</" + name + ">")
+}
+
+func qname(prefix, local string) string {
+	if prefix == "" {
+		return local
+	}
+	return prefix + ":" + local
+}
+
+func escapeText(buf *bytes.Buffer, s string) {
+	for _, r := range s {
+		switch r {
+		case '&':
+			buf.WriteString("&amp;")
+		case '<':
+			buf.WriteString("&lt;")
+		case '>':
+			buf.WriteString("&gt;")
+		case '\r':
+			buf.WriteString("&#xD;")
+		default:
+			buf.WriteRune(r)
+		}
+	}
+}
+
+func escapeAttr(buf *bytes.Buffer, s string) {
+	for _, r := range s {
+		switch r {
+		case '&':
+			buf.WriteString("&amp;")
+		case '<':
+			buf.WriteString("&lt;")
+		case '"':
+			buf.WriteString("&quot;")
+		case '\t':
+			buf.WriteString("&#x9;")
+		case '\n':
+			buf.WriteString("&#xA;")
+		case '\r':
+			buf.WriteString("&#xD;")
+		default:
+			buf.WriteRune(r)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package saml
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// Algorithm identifiers from https://www.w3.org/TR/xmldsig-core1/. SHA-1 is
+// deliberately not supported.
+const (
+	algEnveloped = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+	algRSASHA256 = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	algRSASHA512 = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha512"
+	algSHA256    = "http://www.w3.org/2001/04/xmlenc#sha256"
+	algSHA512    = "http://www.w3.org/2001/04/xmlenc#sha512"
+)
+
+var signatureHashes = map[string]crypto.Hash{
+	algRSASHA256: crypto.SHA256,
+	algRSASHA512: crypto.SHA512,
+}
+
+var digestHashes = map[string]crypto.Hash{
+	algSHA256: crypto.SHA256,
+	algSHA512: crypto.SHA512,
+}
+
+// errNotSigned is returned by verifySignature if the element has no
+// signature.
+var errNotSigned = errors.New("not signed")
+
+// verifySignature checks el's enveloped signature against certs, and returns
+// el as it was signed: parsed from its canonical form, so nothing which the
+// signature doesn't cover can be read from the result. This is what defeats
+// signature wrapping attacks, which rely on the verifier and the code using
+// the document looking at different elements.
+func verifySignature(el *element, certs []*x509.Certificate) (*element, error) {
+	sigs := el.children(nsDSig, "Signature")
+	switch len(sigs) {
+	case 0:
+		return nil, errNotSigned
+	case 1:
+	default:
+		return nil, errors.New("more than one signature")
+	}
+	sig := sigs[0]
+	signedInfo := sig.child(nsDSig, "SignedInfo")
+	sigValue := sig.child(nsDSig, "SignatureValue")
+	if signedInfo == nil || sigValue == nil {
+		return nil, errors.New("malformed signature")
+	}
+
+	// Check the signature over SignedInfo:
+	inclusive, err := c14nPrefixes(signedInfo.child(nsDSig, "CanonicalizationMethod"))
+	if err != nil {
+		return nil, err
+	}
+	signedInfoBytes := signedInfo.canonicalize(nil, inclusive)
+	hash, err := algorithm(signedInfo.child(nsDSig, "SignatureMethod"), signatureHashes)
+	if err != nil {
+		return nil, fmt.Errorf("signature method: %w", err)
+	}
+	sigBytes, err := decodeBase64(sigValue.text())
+	if err != nil {
+		return nil, fmt.Errorf("signature value: %w", err)
+	}
+	h := hash.New()
+	h.Write(signedInfoBytes)
+	if !verifyRSA(certs, hash, h.Sum(nil), sigBytes) {
+		return nil, errors.New("signature does not match any of the identity provider's certificates")
+	}
+
+	// SignedInfo is authentic; read the reference from its signed form:
+	signedInfo, err = parseXML(signedInfoBytes)
+	if err != nil {
+		return nil, err
+	}
+	ref := signedInfo.child(nsDSig, "Reference")
+	if ref == nil {
+		return nil, errors.New("signature must have exactly one reference")
+	}
+	id, _ := el.attr("ID")
+	if uri, _ := ref.attr("URI"); id == "" || uri != "#"+id {
+		return nil, errors.New("signature does not refer to the signed element")
+	}
+	inclusive, err = referenceTransforms(ref)
+	if err != nil {
+		return nil, err
+	}
+	hash, err = algorithm(ref.child(nsDSig, "DigestMethod"), digestHashes)
+	if err != nil {
+		return nil, fmt.Errorf("digest method: %w", err)
+	}
+	digestValue := ref.child(nsDSig, "DigestValue")
+	if digestValue == nil {
+		return nil, errors.New("malformed signature")
+	}
+	want, err := decodeBase64(digestValue.text())
+	if err != nil {
+		return nil, fmt.Errorf("digest value: %w", err)
+	}
+	signed := el.canonicalize(sig, inclusive)
+	h = hash.New()
+	h.Write(signed)
+	if subtle.ConstantTimeCompare(h.Sum(nil), want) != 1 {
+		return nil, errors.New("digest does not match signed element")
+	}
+	return parseXML(signed)
+}
+
+// verifyRSA returns true if sig is a valid signature of digest by the key of
+// one of the certificates.
+func verifyRSA(certs []*x509.Certificate, hash crypto.Hash, digest, sig []byte) bool {
+	for _, cert := range certs {
+		pub, ok := cert.PublicKey.(*rsa.PublicKey)
+		if ok && rsa.VerifyPKCS1v15(pub, hash, digest, sig) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// referenceTransforms checks that ref's transforms are the enveloped
+// signature transform followed by exclusive canonicalization, which is what
+// SAML signatures use, and returns the latter's inclusive prefixes.
+func referenceTransforms(ref *element) ([]string, error) {
+	var transforms []*element
+	if t := ref.child(nsDSig, "Transforms"); t != nil {
+		transforms = t.children(nsDSig, "Transform")
+	}
+	if len(transforms) != 2 {
+		return nil, errors.New("unsupported reference transforms")
+	}
+	if alg, _ := transforms[0].attr("Algorithm"); alg != algEnveloped {
+		return nil, fmt.Errorf("unsupported reference transform %q", alg)
+	}
+	return c14nPrefixes(transforms[1])
+}
+
+// c14nPrefixes checks that method (a CanonicalizationMethod or Transform
+// element) specifies exclusive canonicalization, and returns the prefixes
+// listed in its InclusiveNamespaces element, if any.
+func c14nPrefixes(method *element) ([]string, error) {
+	if method == nil {
+		return nil, errors.New("missing canonicalization method")
+	}
+	if alg, _ := method.attr("Algorithm"); alg != nsExcC14N {
+		return nil, fmt.Errorf("unsupported canonicalization method %q", alg)
+	}
+	inclusive := method.child(nsExcC14N, "InclusiveNamespaces")
+	if inclusive == nil {
+		return nil, nil
+	}
+	prefixes, _ := inclusive.attr("PrefixList")
+	return strings.Fields(prefixes), nil
+}
+
+// algorithm returns the hash for method's Algorithm attribute, which must be
+// one of those in supported.
+func algorithm(method *element, supported map[string]crypto.Hash) (crypto.Hash, error) {
+	if method == nil {
+		return 0, errors.New("missing")
+	}
+	alg, _ := method.attr("Algorithm")
+	hash, ok := supported[alg]
+	if !ok {
+		return 0, fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	return hash, nil
+}
+
+// decodeBase64 decodes s, ignoring whitespace, which XML documents often
+// break base64 text up with.
+func decodeBase64(s string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+}
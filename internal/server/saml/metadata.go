@@ -0,0 +1,127 @@
+package saml
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/xml"
+	"errors"
+	"fmt"
+)
+
+const (
+	bindingRedirect = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect"
+	bindingPOST     = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+)
+
+// An IdentityProvider is what we need to know about the identity provider.
+type IdentityProvider struct {
+	EntityID string
+
+	// URL of the single sign-on service, using the HTTP-Redirect binding.
+	SSOURL string
+
+	// Certificates whose keys may sign assertions. Only the keys matter;
+	// as is usual for SAML, the certificates' expiry and issuer are not
+	// checked, since they are trusted by virtue of being in the metadata.
+	Certs []*x509.Certificate
+}
+
+// The parts of an identity provider's metadata which we look at.
+type idpMetadata struct {
+	XMLName  xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntityDescriptor"`
+	EntityID string   `xml:"entityID,attr"`
+
+	IDPSSODescriptors []struct {
+		KeyDescriptors []struct {
+			Use   string   `xml:"use,attr"`
+			Certs []string `xml:"http://www.w3.org/2000/09/xmldsig# KeyInfo>X509Data>X509Certificate"`
+		} `xml:"urn:oasis:names:tc:SAML:2.0:metadata KeyDescriptor"`
+		SSOServices []struct {
+			Binding  string `xml:",attr"`
+			Location string `xml:",attr"`
+		} `xml:"urn:oasis:names:tc:SAML:2.0:metadata SingleSignOnService"`
+	} `xml:"urn:oasis:names:tc:SAML:2.0:metadata IDPSSODescriptor"`
+}
+
+// ParseIdPMetadata parses an identity provider's metadata: an
+// EntityDescriptor with an IDPSSODescriptor, which must offer the
+// HTTP-Redirect binding and at least one RSA signing key.
+func ParseIdPMetadata(data []byte) (IdentityProvider, error) {
+	var md idpMetadata
+	if err := xml.Unmarshal(data, &md); err != nil {
+		return IdentityProvider{}, fmt.Errorf("parsing identity provider metadata: %w", err)
+	}
+	idp := IdentityProvider{EntityID: md.EntityID}
+	if idp.EntityID == "" {
+		return idp, errors.New("identity provider metadata has no entityID")
+	}
+	for _, desc := range md.IDPSSODescriptors {
+		for _, svc := range desc.SSOServices {
+			if svc.Binding == bindingRedirect && idp.SSOURL == "" {
+				idp.SSOURL = svc.Location
+			}
+		}
+		for _, kd := range desc.KeyDescriptors {
+			if kd.Use != "" && kd.Use != "signing" {
+				continue
+			}
+			for _, text := range kd.Certs {
+				der, err := decodeBase64(text)
+				if err != nil {
+					return idp, fmt.Errorf("decoding identity provider certificate: %w", err)
+				}
+				cert, err := x509.ParseCertificate(der)
+				if err != nil {
+					return idp, fmt.Errorf("parsing identity provider certificate: %w", err)
+				}
+				if _, ok := cert.PublicKey.(*rsa.PublicKey); !ok {
+					return idp, errors.New("identity provider certificate does not have an RSA key")
+				}
+				idp.Certs = append(idp.Certs, cert)
+			}
+		}
+	}
+	if idp.SSOURL == "" {
+		return idp, errors.New("identity provider metadata has no single sign-on " +
+			"service with the HTTP-Redirect binding")
+	}
+	if len(idp.Certs) == 0 {
+		return idp, errors.New("identity provider metadata has no signing certificate")
+	}
+	return idp, nil
+}
+
+// Our metadata, as marshalled by ServiceProvider.Metadata.
+type spMetadata struct {
+	XMLName         xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntityDescriptor"`
+	EntityID        string   `xml:"entityID,attr"`
+	SPSSODescriptor struct {
+		AuthnRequestsSigned        bool   `xml:",attr"`
+		WantAssertionsSigned       bool   `xml:",attr"`
+		ProtocolSupportEnumeration string `xml:"protocolSupportEnumeration,attr"`
+		AssertionConsumerService   struct {
+			Binding   string `xml:",attr"`
+			Location  string `xml:",attr"`
+			Index     int    `xml:"index,attr"`
+			IsDefault bool   `xml:"isDefault,attr"`
+		}
+	}
+}
+
+// Metadata returns our metadata, for configuring the identity provider.
+func (sp *ServiceProvider) Metadata() []byte {
+	var md spMetadata
+	md.EntityID = sp.cfg.EntityID
+	md.SPSSODescriptor.WantAssertionsSigned = true
+	md.SPSSODescriptor.ProtocolSupportEnumeration = nsProtocol
+	acs := &md.SPSSODescriptor.AssertionConsumerService
+	acs.Binding = bindingPOST
+	acs.Location = sp.cfg.ACSURL
+	acs.IsDefault = true
+	data, err := xml.MarshalIndent(md, "", "  ")
+	if err != nil {
+		// Can't happen; the struct is fixed.
+		panic(err)
+	}
+	return append([]byte(xml.Header), data...)
+}
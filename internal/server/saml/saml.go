@@ -0,0 +1,404 @@
+// Package saml implements the service provider side of SAML 2.0 web browser
+// single sign-on, for organizations whose identity provider speaks SAML
+// rather than OpenID Connect.
+//
+// Only what that profile needs is supported: requests are sent with the
+// HTTP-Redirect binding, unsigned; responses are received with the HTTP-POST
+// binding; and assertions must be signed with RSA and SHA-256 or SHA-512,
+// either directly or by signing the whole response. Encrypted assertions are
+// not supported. Nor is IdP-initiated login: every response must answer a
+// request which we sent, which is also what stops responses being replayed.
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	statusSuccess     = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	methodBearer      = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+	nameIDTransient   = "urn:oasis:names:tc:SAML:2.0:nameid-format:transient"
+	requestTTL        = 10 * time.Minute
+	maxPendingRequest = 10000
+)
+
+// Config configures a ServiceProvider.
+type Config struct {
+	// Our entity ID, by which the identity provider knows us.
+	EntityID string
+
+	// URL of our assertion consumer service, to which the identity
+	// provider posts responses.
+	ACSURL string
+
+	// The identity provider; see ParseIdPMetadata. If its SSOURL is
+	// empty, SAML login is disabled.
+	IdP IdentityProvider
+
+	// How far our clock may be from the identity provider's.
+	ClockSkew time.Duration
+
+	// Name of the attribute which identifies the user. If empty, the
+	// subject's NameID is used.
+	NameAttribute string
+}
+
+// Enabled returns true if SAML login is configured.
+func (c Config) Enabled() bool {
+	return c.IdP.SSOURL != ""
+}
+
+// An Assertion is what we learned about a user from a valid response.
+type Assertion struct {
+	NameID       string
+	NameIDFormat string
+	Attributes   map[string][]string
+}
+
+// A ServiceProvider makes authentication requests, and checks the identity
+// provider's responses to them.
+//
+// Outstanding requests are remembered in memory, so in a deployment with
+// several servers, the response must reach the server which sent the
+// request.
+type ServiceProvider struct {
+	cfg Config
+	now func() time.Time
+
+	mu      sync.Mutex
+	pending map[string]time.Time // Expiry times of outstanding requests.
+	order   []string             // Outstanding request IDs, oldest first.
+}
+
+// NewServiceProvider returns a ServiceProvider using cfg.
+func NewServiceProvider(cfg Config) *ServiceProvider {
+	return &ServiceProvider{
+		cfg:     cfg,
+		now:     time.Now,
+		pending: make(map[string]time.Time),
+	}
+}
+
+type authnRequest struct {
+	XMLName                     xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:protocol AuthnRequest"`
+	ID                          string   `xml:",attr"`
+	Version                     string   `xml:",attr"`
+	IssueInstant                string   `xml:",attr"`
+	Destination                 string   `xml:",attr"`
+	AssertionConsumerServiceURL string   `xml:",attr"`
+	ProtocolBinding             string   `xml:",attr"`
+	Issuer                      struct {
+		XMLName xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:assertion Issuer"`
+		Value   string   `xml:",chardata"`
+	}
+	NameIDPolicy struct {
+		XMLName     xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:protocol NameIDPolicy"`
+		AllowCreate bool     `xml:",attr"`
+	}
+}
+
+// AuthnRequestURL returns the URL to which to redirect the user's browser, to
+// ask the identity provider to authenticate them.
+func (sp *ServiceProvider) AuthnRequestURL() (string, error) {
+	now := sp.now()
+	req := authnRequest{
+		ID:                          newID(),
+		Version:                     "2.0",
+		IssueInstant:                now.UTC().Format(time.RFC3339),
+		Destination:                 sp.cfg.IdP.SSOURL,
+		AssertionConsumerServiceURL: sp.cfg.ACSURL,
+		ProtocolBinding:             bindingPOST,
+	}
+	req.Issuer.Value = sp.cfg.EntityID
+	req.NameIDPolicy.AllowCreate = true
+	data, err := xml.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	// The HTTP-Redirect binding: DEFLATE, then base64, then a query
+	// parameter.
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	w.Write(data)
+	if err = w.Close(); err != nil {
+		return "", err
+	}
+	u, err := url.Parse(sp.cfg.IdP.SSOURL)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("SAMLRequest", base64.StdEncoding.EncodeToString(buf.Bytes()))
+	u.RawQuery = q.Encode()
+
+	sp.addPending(req.ID, now)
+	return u.String(), nil
+}
+
+// newID returns a random ID for a request. IDs must not start with a digit,
+// hence the underscore.
+func newID() string {
+	var buf [20]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		panic(err)
+	}
+	return "_" + hex.EncodeToString(buf[:])
+}
+
+// addPending records an outstanding request, forgetting those which have
+// expired, or the oldest if there are too many.
+func (sp *ServiceProvider) addPending(id string, now time.Time) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	for len(sp.order) > 0 {
+		oldest := sp.order[0]
+		expires, ok := sp.pending[oldest]
+		if ok && now.Before(expires) && len(sp.order) < maxPendingRequest {
+			break
+		}
+		delete(sp.pending, oldest)
+		sp.order = sp.order[1:]
+	}
+	sp.pending[id] = now.Add(requestTTL)
+	sp.order = append(sp.order, id)
+}
+
+// takePending returns true if id is an outstanding request, which it then no
+// longer is.
+func (sp *ServiceProvider) takePending(id string, now time.Time) bool {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	expires, ok := sp.pending[id]
+	delete(sp.pending, id)
+	return ok && now.Before(expires)
+}
+
+// ParseResponse checks a response from the identity provider, given as the
+// value of the SAMLResponse form field, and returns its assertion.
+func (sp *ServiceProvider) ParseResponse(samlResponse string) (*Assertion, error) {
+	data, err := decodeBase64(samlResponse)
+	if err != nil {
+		return nil, fmt.Errorf("saml: decoding response: %w", err)
+	}
+	resp, err := parseXML(data)
+	if err != nil {
+		return nil, fmt.Errorf("saml: parsing response: %w", err)
+	}
+	if !resp.is(nsProtocol, "Response") {
+		return nil, errors.New("saml: not a Response")
+	}
+
+	// Find the signed assertion, either inside a signed response, or
+	// signed itself:
+	var assertion *element
+	signedResp, err := verifySignature(resp, sp.cfg.IdP.Certs)
+	switch {
+	case err == nil:
+		resp = signedResp
+		assertion = resp.child(nsAssertion, "Assertion")
+	case errors.Is(err, errNotSigned):
+		assertion = resp.child(nsAssertion, "Assertion")
+		if assertion != nil {
+			assertion, err = verifySignature(assertion, sp.cfg.IdP.Certs)
+			if errors.Is(err, errNotSigned) {
+				return nil, errors.New("saml: neither the response nor the assertion is signed")
+			} else if err != nil {
+				return nil, fmt.Errorf("saml: assertion signature: %w", err)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("saml: response signature: %w", err)
+	}
+
+	if err := sp.checkStatus(resp); err != nil {
+		return nil, err
+	}
+	if assertion == nil {
+		if len(resp.children(nsAssertion, "EncryptedAssertion")) > 0 {
+			return nil, errors.New("saml: encrypted assertions are not supported")
+		}
+		return nil, errors.New("saml: response must contain exactly one assertion")
+	}
+	if dest, ok := resp.attr("Destination"); ok && dest != sp.cfg.ACSURL {
+		return nil, fmt.Errorf("saml: response is for %q, not us", dest)
+	}
+	requestID, err := sp.checkAssertion(assertion)
+	if err != nil {
+		return nil, fmt.Errorf("saml: %w", err)
+	}
+	if id, ok := resp.attr("InResponseTo"); ok && id != requestID {
+		return nil, errors.New("saml: response and assertion answer different requests")
+	}
+	if !sp.takePending(requestID, sp.now()) {
+		return nil, errors.New("saml: response does not answer an outstanding request")
+	}
+
+	a := &Assertion{Attributes: make(map[string][]string)}
+	nameID := assertion.child(nsAssertion, "Subject").child(nsAssertion, "NameID")
+	if nameID != nil {
+		a.NameID = nameID.text()
+		a.NameIDFormat, _ = nameID.attr("Format")
+	}
+	for _, stmt := range assertion.children(nsAssertion, "AttributeStatement") {
+		for _, attr := range stmt.children(nsAssertion, "Attribute") {
+			name, _ := attr.attr("Name")
+			for _, value := range attr.children(nsAssertion, "AttributeValue") {
+				a.Attributes[name] = append(a.Attributes[name], value.text())
+			}
+		}
+	}
+	return a, nil
+}
+
+// checkStatus returns an error unless the response says authentication
+// succeeded.
+func (sp *ServiceProvider) checkStatus(resp *element) error {
+	status := resp.child(nsProtocol, "Status")
+	if status == nil {
+		return errors.New("saml: response has no status")
+	}
+	var code string
+	if codeEl := status.child(nsProtocol, "StatusCode"); codeEl != nil {
+		code, _ = codeEl.attr("Value")
+	}
+	if code == statusSuccess {
+		return nil
+	}
+	msg := ""
+	if msgEl := status.child(nsProtocol, "StatusMessage"); msgEl != nil {
+		msg = msgEl.text()
+	}
+	return fmt.Errorf("saml: identity provider returned status %q: %s", code, msg)
+}
+
+// checkAssertion checks that a signed assertion is from the identity
+// provider, is for us and is currently valid, and returns the ID of the
+// request it answers.
+func (sp *ServiceProvider) checkAssertion(assertion *element) (string, error) {
+	now := sp.now()
+	issuer := assertion.child(nsAssertion, "Issuer")
+	if issuer == nil || issuer.text() != sp.cfg.IdP.EntityID {
+		return "", errors.New("assertion is not from the identity provider")
+	}
+
+	conditions := assertion.child(nsAssertion, "Conditions")
+	if conditions == nil {
+		return "", errors.New("assertion has no conditions")
+	}
+	if err := sp.checkTimes(conditions, now); err != nil {
+		return "", fmt.Errorf("assertion %w", err)
+	}
+	restrictions := conditions.children(nsAssertion, "AudienceRestriction")
+	if len(restrictions) == 0 {
+		return "", errors.New("assertion has no audience restriction")
+	}
+	for _, r := range restrictions {
+		ok := false
+		for _, audience := range r.children(nsAssertion, "Audience") {
+			ok = ok || audience.text() == sp.cfg.EntityID
+		}
+		if !ok {
+			return "", errors.New("assertion is not for us")
+		}
+	}
+
+	if len(assertion.children(nsAssertion, "AuthnStatement")) == 0 {
+		return "", errors.New("assertion has no authentication statement")
+	}
+
+	// A bearer subject confirmation says the assertion may be used by
+	// whoever presents it, here, for a limited time:
+	subject := assertion.child(nsAssertion, "Subject")
+	if subject == nil {
+		return "", errors.New("assertion has no subject")
+	}
+	var lastErr error = errors.New("assertion has no bearer subject confirmation")
+	for _, sc := range subject.children(nsAssertion, "SubjectConfirmation") {
+		if method, _ := sc.attr("Method"); method != methodBearer {
+			continue
+		}
+		data := sc.child(nsAssertion, "SubjectConfirmationData")
+		if data == nil {
+			lastErr = errors.New("subject confirmation has no data")
+			continue
+		}
+		if recipient, _ := data.attr("Recipient"); recipient != sp.cfg.ACSURL {
+			lastErr = fmt.Errorf("subject confirmation is for %q, not us", recipient)
+			continue
+		}
+		if _, ok := data.attr("NotOnOrAfter"); !ok {
+			lastErr = errors.New("subject confirmation has no expiry")
+			continue
+		}
+		if err := sp.checkTimes(data, now); err != nil {
+			lastErr = fmt.Errorf("subject confirmation %w", err)
+			continue
+		}
+		requestID, _ := data.attr("InResponseTo")
+		if requestID == "" {
+			lastErr = errors.New("unsolicited responses are not supported")
+			continue
+		}
+		return requestID, nil
+	}
+	return "", lastErr
+}
+
+// checkTimes checks that now is within the NotBefore and NotOnOrAfter
+// attributes of el, if it has them, give or take the allowed clock skew.
+func (sp *ServiceProvider) checkTimes(el *element, now time.Time) error {
+	if s, ok := el.attr("NotBefore"); ok {
+		notBefore, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return fmt.Errorf("has invalid NotBefore: %w", err)
+		}
+		if now.Add(sp.cfg.ClockSkew).Before(notBefore) {
+			return errors.New("is not valid yet")
+		}
+	}
+	if s, ok := el.attr("NotOnOrAfter"); ok {
+		notOnOrAfter, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return fmt.Errorf("has invalid NotOnOrAfter: %w", err)
+		}
+		if !now.Add(-sp.cfg.ClockSkew).Before(notOnOrAfter) {
+			return errors.New("has expired")
+		}
+	}
+	return nil
+}
+
+// UserID returns the string identifying the user an assertion is about: the
+// value of the configured attribute, or else the NameID.
+func (sp *ServiceProvider) UserID(a *Assertion) (string, error) {
+	if sp.cfg.NameAttribute != "" {
+		values := a.Attributes[sp.cfg.NameAttribute]
+		if len(values) == 0 || values[0] == "" {
+			return "", fmt.Errorf("saml: assertion has no %q attribute", sp.cfg.NameAttribute)
+		}
+		return values[0], nil
+	}
+	if a.NameIDFormat == nameIDTransient {
+		return "", errors.New("saml: the identity provider sent a transient NameID, " +
+			"which can't identify a user from one login to the next; configure it " +
+			"to send a persistent one, or use an attribute instead")
+	}
+	if a.NameID == "" {
+		return "", errors.New("saml: assertion has no NameID")
+	}
+	return a.NameID, nil
+}
@@ -0,0 +1,315 @@
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"math/big"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testdata/response.xml was signed with openssl, over canonical forms
+// produced by xmllint --exc-c14n, so it checks our canonicalization against
+// an independent implementation.
+
+const (
+	testEntityID = "https://tempest.example.com/saml/metadata"
+	testACSURL   = "https://tempest.example.com/saml/acs"
+	testIssued   = "2024-01-01T00:00:00Z"
+)
+
+func readTestdata(t *testing.T, name string) string {
+	data, err := os.ReadFile("testdata/" + name)
+	require.NoError(t, err)
+	return string(data)
+}
+
+// newTestSP returns a ServiceProvider for the identity provider in testdata,
+// with its clock set to the given offset from when the test response was
+// issued.
+func newTestSP(t *testing.T, offset time.Duration) *ServiceProvider {
+	idp, err := ParseIdPMetadata([]byte(readTestdata(t, "idp-metadata.xml")))
+	require.NoError(t, err)
+	sp := NewServiceProvider(Config{
+		EntityID:  testEntityID,
+		ACSURL:    testACSURL,
+		IdP:       idp,
+		ClockSkew: 3 * time.Minute,
+	})
+	issued, err := time.Parse(time.RFC3339, testIssued)
+	require.NoError(t, err)
+	now := issued.Add(offset)
+	sp.now = func() time.Time { return now }
+	return sp
+}
+
+func encodeResponse(resp string) string {
+	return base64.StdEncoding.EncodeToString([]byte(resp))
+}
+
+func TestParseIdPMetadata(t *testing.T) {
+	idp, err := ParseIdPMetadata([]byte(readTestdata(t, "idp-metadata.xml")))
+	require.NoError(t, err)
+	assert.Equal(t, "https://idp.example.com/metadata", idp.EntityID)
+	assert.Equal(t, "https://idp.example.com/sso", idp.SSOURL)
+	require.Len(t, idp.Certs, 1)
+	assert.Equal(t, "idp.example.com", idp.Certs[0].Subject.CommonName)
+
+	_, err = ParseIdPMetadata([]byte(strings.Replace(readTestdata(t, "idp-metadata.xml"),
+		"HTTP-Redirect", "SOAP", 1)))
+	assert.Error(t, err, "no redirect binding")
+}
+
+func TestParseResponse(t *testing.T) {
+	sp := newTestSP(t, time.Minute)
+	sp.addPending("_request1", sp.now())
+	resp := encodeResponse(readTestdata(t, "response.xml"))
+
+	a, err := sp.ParseResponse(resp)
+	require.NoError(t, err)
+	assert.Equal(t, "alice-1234", a.NameID)
+	assert.Equal(t, "urn:oasis:names:tc:SAML:2.0:nameid-format:persistent", a.NameIDFormat)
+	assert.Equal(t, map[string][]string{
+		"email":       {"alice@example.com"},
+		"displayName": {"Alice & co."},
+	}, a.Attributes)
+
+	id, err := sp.UserID(a)
+	require.NoError(t, err)
+	assert.Equal(t, "alice-1234", id)
+	sp.cfg.NameAttribute = "email"
+	id, err = sp.UserID(a)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", id)
+	sp.cfg.NameAttribute = "phone"
+	_, err = sp.UserID(a)
+	assert.Error(t, err)
+
+	_, err = sp.ParseResponse(resp)
+	assert.Error(t, err, "responses can't be replayed")
+}
+
+func TestParseResponseRejects(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "evil.example.com"},
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &otherKey.PublicKey, otherKey)
+	require.NoError(t, err)
+	otherCert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		offset  time.Duration
+		edit    func(resp string) string
+		editSP  func(sp *ServiceProvider)
+		pending bool
+	}{
+		{
+			name:    "tampered assertion",
+			edit:    replace("alice-1234", "mallory-1234"),
+			pending: true,
+		},
+		{
+			name:    "tampered signed info",
+			edit:    replace(`URI="#_assertion1"`, `URI="#_response1"`),
+			pending: true,
+		},
+		{
+			name:    "unsigned",
+			edit:    removeSignature,
+			pending: true,
+		},
+		{
+			name: "wrapped assertion",
+			edit: func(resp string) string {
+				// An unsigned assertion alongside the signed one:
+				evil := strings.Replace(removeSignature(resp), "alice-1234", "mallory-1234", 1)
+				start := strings.Index(evil, "<saml:Assertion")
+				end := strings.Index(evil, "</saml:Assertion>") + len("</saml:Assertion>")
+				return strings.Replace(resp, "<saml:Assertion", evil[start:end]+"<saml:Assertion", 1)
+			},
+			pending: true,
+		},
+		{
+			name:    "wrong key",
+			editSP:  func(sp *ServiceProvider) { sp.cfg.IdP.Certs = []*x509.Certificate{otherCert} },
+			pending: true,
+		},
+		{
+			name:    "wrong issuer",
+			editSP:  func(sp *ServiceProvider) { sp.cfg.IdP.EntityID = "https://other.example.com" },
+			pending: true,
+		},
+		{
+			name:    "wrong audience",
+			editSP:  func(sp *ServiceProvider) { sp.cfg.EntityID = "https://other.example.com" },
+			pending: true,
+		},
+		{
+			name:    "wrong recipient",
+			editSP:  func(sp *ServiceProvider) { sp.cfg.ACSURL = "https://other.example.com/acs" },
+			pending: true,
+		},
+		{
+			name:    "expired",
+			offset:  9 * time.Minute,
+			pending: true,
+		},
+		{
+			name:    "not yet valid",
+			offset:  -4 * time.Minute,
+			pending: true,
+		},
+		{
+			name:    "unsolicited",
+			pending: false,
+		},
+		{
+			name:    "failed",
+			edit:    replace("status:Success", "status:Responder"),
+			pending: true,
+		},
+		{
+			name:    "doctype",
+			edit:    replace("<samlp:Response", `<!DOCTYPE x [<!ENTITY e "e">]><samlp:Response`),
+			pending: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sp := newTestSP(t, c.offset)
+			if c.editSP != nil {
+				c.editSP(sp)
+			}
+			if c.pending {
+				sp.addPending("_request1", sp.now())
+			}
+			resp := readTestdata(t, "response.xml")
+			if c.edit != nil {
+				resp = c.edit(resp)
+			}
+			_, err := sp.ParseResponse(encodeResponse(resp))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestClockSkew(t *testing.T) {
+	// Within the allowed skew either side of the validity period:
+	for _, offset := range []time.Duration{-2 * time.Minute, 7 * time.Minute} {
+		sp := newTestSP(t, offset)
+		sp.addPending("_request1", sp.now())
+		_, err := sp.ParseResponse(encodeResponse(readTestdata(t, "response.xml")))
+		assert.NoError(t, err, "offset %v", offset)
+	}
+}
+
+func replace(old, new string) func(string) string {
+	return func(s string) string {
+		return strings.Replace(s, old, new, 1)
+	}
+}
+
+func removeSignature(resp string) string {
+	start := strings.Index(resp, "<ds:Signature")
+	end := strings.Index(resp, "</ds:Signature>") + len("</ds:Signature>")
+	return resp[:start] + resp[end:]
+}
+
+func TestAuthnRequestURL(t *testing.T) {
+	sp := newTestSP(t, 0)
+	u, err := sp.AuthnRequestURL()
+	require.NoError(t, err)
+	parsed, err := url.Parse(u)
+	require.NoError(t, err)
+	assert.Equal(t, "idp.example.com", parsed.Host)
+	assert.Equal(t, "/sso", parsed.Path)
+
+	deflated, err := base64.StdEncoding.DecodeString(parsed.Query().Get("SAMLRequest"))
+	require.NoError(t, err)
+	data, err := io.ReadAll(flate.NewReader(bytes.NewReader(deflated)))
+	require.NoError(t, err)
+	var req authnRequest
+	require.NoError(t, xml.Unmarshal(data, &req))
+	assert.Equal(t, "https://idp.example.com/sso", req.Destination)
+	assert.Equal(t, testACSURL, req.AssertionConsumerServiceURL)
+	assert.Equal(t, testEntityID, req.Issuer.Value)
+	assert.Equal(t, testIssued, req.IssueInstant)
+
+	assert.True(t, sp.takePending(req.ID, sp.now()))
+	assert.False(t, sp.takePending(req.ID, sp.now()), "requests are answered once")
+}
+
+func TestPendingExpiry(t *testing.T) {
+	sp := newTestSP(t, 0)
+	now := sp.now()
+	sp.addPending("_old", now)
+	assert.False(t, sp.takePending("_old", now.Add(requestTTL)))
+
+	sp.addPending("_old", now)
+	sp.addPending("_new", now.Add(requestTTL))
+	assert.Equal(t, []string{"_new"}, sp.order, "expired requests are forgotten")
+}
+
+func TestMetadata(t *testing.T) {
+	sp := newTestSP(t, 0)
+	var md spMetadata
+	require.NoError(t, xml.Unmarshal(sp.Metadata(), &md))
+	assert.Equal(t, testEntityID, md.EntityID)
+	assert.Equal(t, testACSURL, md.SPSSODescriptor.AssertionConsumerService.Location)
+	assert.Equal(t, bindingPOST, md.SPSSODescriptor.AssertionConsumerService.Binding)
+	assert.True(t, md.SPSSODescriptor.WantAssertionsSigned)
+}
+
+func TestCanonicalize(t *testing.T) {
+	doc := `<?xml version="1.0"?>
+<a:root xmlns:a="urn:a" xmlns:b="urn:b" xmlns:unused="urn:unused" xmlns="urn:default">
+  <a:signed ID="x" z="1" b:y="2" a="&lt;&quot;&#9;&#10;"><!-- dropped -->
+    <plain xmlns="">text &amp; &gt; <![CDATA[<cdata>]]></plain><b:skip/>
+    <a:kept xsi:type="b:thing" xmlns:xsi="urn:xsi">x</a:kept>
+    <defaulted/>
+  </a:signed>
+</a:root>`
+	root, err := parseXML([]byte(doc))
+	require.NoError(t, err)
+	signed := root.child("urn:a", "signed")
+	require.NotNil(t, signed)
+	skip := signed.child("urn:b", "skip")
+	require.NotNil(t, skip)
+
+	assert.Equal(t,
+		`<a:signed xmlns:a="urn:a" xmlns:b="urn:b" ID="x" a="&lt;&quot;&#x9;&#xA;" z="1" b:y="2">
+    <plain>text &amp; &gt; &lt;cdata&gt;</plain>
+    <a:kept xmlns:xsi="urn:xsi" xsi:type="b:thing">x</a:kept>
+    <defaulted xmlns="urn:default"></defaulted>
+  </a:signed>`,
+		string(signed.canonicalize(skip, nil)))
+
+	// With b and the default namespace listed as inclusive, they are
+	// declared where they are first in scope, whether or not they are used:
+	assert.Equal(t,
+		`<a:signed xmlns="urn:default" xmlns:a="urn:a" xmlns:b="urn:b" ID="x" a="&lt;&quot;&#x9;&#xA;" z="1" b:y="2">
+    <plain xmlns="">text &amp; &gt; &lt;cdata&gt;</plain>
+    <a:kept xmlns:xsi="urn:xsi" xsi:type="b:thing">x</a:kept>
+    <defaulted></defaulted>
+  </a:signed>`,
+		string(signed.canonicalize(skip, []string{"b", "#default"})))
+}
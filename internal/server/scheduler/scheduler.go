@@ -0,0 +1,317 @@
+// Package scheduler runs the jobs which grains schedule with Sandstorm's
+// SandstormApi.schedule(): callbacks to be called once at a given time, or
+// periodically.
+//
+// Jobs are kept in a Store, so that they survive restarts. A Runner wakes up
+// when the next job is due, and runs it; what running a job means (starting
+// the grain, restoring its callback and calling it) is up to the Runner's
+// Run function. Jobs whose callbacks fail are retried with exponential
+// backoff, and disabled after too many failures in a row.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slog"
+	"sandstorm.org/go/tempest/internal/common/types"
+)
+
+// MinimumSlack is the least slack a one-shot job may ask for; see
+// minimumSchedulingSlack in grain.capnp.
+const MinimumSlack = 5 * time.Minute
+
+// ErrSlackTooSmall is returned by CheckSlack for slack less than MinimumSlack.
+var ErrSlackTooSmall = fmt.Errorf("scheduler: slack must be at least %v", MinimumSlack)
+
+// A Period is how often a periodic job runs. The values are the names of the
+// SchedulingPeriod enumerants in grain.capnp.
+type Period string
+
+const (
+	OneShot  Period = "" // Not periodic.
+	Hourly   Period = "hourly"
+	Daily    Period = "daily"
+	Weekly   Period = "weekly"
+	Monthly  Period = "monthly"
+	Annually Period = "annually"
+)
+
+// IsValid returns true if p is one of the Period constants.
+func (p Period) IsValid() bool {
+	switch p {
+	case OneShot, Hourly, Daily, Weekly, Monthly, Annually:
+		return true
+	default:
+		return false
+	}
+}
+
+// After returns the time one period after t. It panics for OneShot.
+func (p Period) After(t time.Time) time.Time {
+	switch p {
+	case Hourly:
+		return t.Add(time.Hour)
+	case Daily:
+		return t.AddDate(0, 0, 1)
+	case Weekly:
+		return t.AddDate(0, 0, 7)
+	case Monthly:
+		return t.AddDate(0, 1, 0)
+	case Annually:
+		return t.AddDate(1, 0, 0)
+	default:
+		panic(fmt.Sprintf("scheduler: not a periodic job: %q", p))
+	}
+}
+
+// CheckSlack checks the slack of a one-shot job. Zero is allowed, and in
+// Sandstorm means an eighth of the time until the job is due. We don't yet
+// make use of slack to spread out load; jobs run as soon as they are due.
+func CheckSlack(slack time.Duration) error {
+	if slack != 0 && slack < MinimumSlack {
+		return ErrSlackTooSmall
+	}
+	return nil
+}
+
+// A Job is a callback scheduled by a grain.
+type Job struct {
+	ID      string
+	GrainID types.GrainID
+	Name    string // Describes the job to the user.
+
+	// The AppObjectId with which to restore the callback from the grain,
+	// encoded by the caller; it is opaque to this package.
+	ObjectID []byte
+
+	Period Period
+	Next   time.Time // When the job is next due.
+
+	// The number of times in a row the job has failed, and the last
+	// error. Disabled jobs are not run again.
+	Failures  int
+	LastError string
+	Disabled  bool
+}
+
+// A Policy says how failing jobs are retried.
+type Policy struct {
+	// How long to wait before retrying a job the first time it fails; the
+	// wait doubles with each further failure, up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// The number of failures in a row after which a job is disabled.
+	MaxFailures int
+
+	// How long a job may run. A job which is still running after this
+	// long, e.g. because the server restarted, is run again.
+	Timeout time.Duration
+}
+
+// DefaultPolicy is the Policy used if none is configured.
+var DefaultPolicy = Policy{
+	Backoff:     time.Minute,
+	MaxBackoff:  6 * time.Hour,
+	MaxFailures: 8,
+	Timeout:     10 * time.Minute,
+}
+
+// Finish updates j after it has run at time now. cancel is the callback's
+// request not to be run again, and err its error, if it failed. It returns
+// true if the job is finished for good, and should be removed.
+func (p Policy) Finish(j *Job, now time.Time, cancel bool, err error) (remove bool) {
+	if err != nil {
+		j.Failures++
+		j.LastError = err.Error()
+		if j.Failures >= p.MaxFailures {
+			j.Disabled = true
+			return false
+		}
+		backoff := p.Backoff << (j.Failures - 1)
+		if backoff > p.MaxBackoff || backoff <= 0 {
+			backoff = p.MaxBackoff
+		}
+		j.Next = now.Add(backoff)
+		return false
+	}
+	if cancel || j.Period == OneShot {
+		return true
+	}
+	j.Failures = 0
+	j.LastError = ""
+	j.Next = j.Period.After(now)
+	return false
+}
+
+// A Store keeps jobs persistently.
+type Store interface {
+	// DueJobs returns the jobs which are not disabled, and are due at
+	// or before now.
+	DueJobs(now time.Time) ([]Job, error)
+
+	// NextDue returns the earliest time at which a job which is not
+	// disabled is due, or false if there is none.
+	NextDue() (time.Time, bool, error)
+
+	// UpdateJob saves changes to a job.
+	UpdateJob(j Job) error
+
+	// DeleteJob removes a job.
+	DeleteJob(id string) error
+}
+
+// Config configures a Runner.
+type Config struct {
+	Store Store
+
+	// Run runs a job, returning its callback's results.
+	Run func(ctx context.Context, j Job) (cancel bool, err error)
+
+	// OnDisable, if not nil, is called when a job is disabled.
+	OnDisable func(j Job)
+
+	// If zero, DefaultPolicy is used.
+	Policy Policy
+
+	// Records failures; must not be nil.
+	Log *slog.Logger
+}
+
+// How long to wait before trying again if the store fails.
+const storeRetryInterval = time.Minute
+
+// A Runner runs jobs when they are due.
+type Runner struct {
+	cfg  Config
+	now  func() time.Time
+	wake chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewRunner returns a Runner using cfg. Call Serve to start running jobs.
+func NewRunner(cfg Config) *Runner {
+	if cfg.Policy == (Policy{}) {
+		cfg.Policy = DefaultPolicy
+	}
+	return &Runner{
+		cfg:  cfg,
+		now:  time.Now,
+		wake: make(chan struct{}, 1),
+	}
+}
+
+// Wake tells the runner that jobs have been added or changed, so that it
+// rechecks when the next one is due.
+func (r *Runner) Wake() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Serve runs jobs as they come due, until ctx is canceled. It then waits for
+// running jobs to return, and returns ctx.Err().
+func (r *Runner) Serve(ctx context.Context) error {
+	defer r.wg.Wait()
+	for {
+		wait := storeRetryInterval
+		next, ok, err := r.startDue(ctx)
+		if err != nil {
+			r.cfg.Log.Error("Checking for scheduled jobs", "error", err)
+		} else if ok {
+			wait = next.Sub(r.now())
+		} else {
+			wait = -1
+		}
+
+		var (
+			timer   *time.Timer
+			timeout <-chan time.Time
+		)
+		if wait >= 0 {
+			timer = time.NewTimer(wait)
+			timeout = timer.C
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-r.wake:
+		case <-timeout:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// startDue starts each job which is due, and returns when the next job is
+// due after that.
+func (r *Runner) startDue(ctx context.Context) (next time.Time, ok bool, err error) {
+	now := r.now()
+	jobs, err := r.cfg.Store.DueJobs(now)
+	if err != nil {
+		return next, false, err
+	}
+	for _, j := range jobs {
+		// Push back the job's due time first, so it isn't started again
+		// while it is running, but is if the server stops before it
+		// finishes.
+		due := j
+		due.Next = now.Add(r.cfg.Policy.Timeout)
+		if err := r.cfg.Store.UpdateJob(due); err != nil {
+			return next, false, err
+		}
+		r.wg.Add(1)
+		go func(j Job) {
+			defer r.wg.Done()
+			r.runJob(ctx, j)
+		}(due)
+	}
+	return r.cfg.Store.NextDue()
+}
+
+// runJob runs j, and records the outcome.
+func (r *Runner) runJob(ctx context.Context, j Job) {
+	runCtx, cancel := context.WithTimeout(ctx, r.cfg.Policy.Timeout)
+	defer cancel()
+	cancelFuture, err := r.cfg.Run(runCtx, j)
+	if errors.Is(ctx.Err(), context.Canceled) {
+		// Shutting down; the job will be run again after restarting.
+		return
+	}
+	if err != nil {
+		r.cfg.Log.Warn("Scheduled job failed",
+			"error", err,
+			"grainID", j.GrainID,
+			"jobID", j.ID,
+		)
+	}
+	if r.cfg.Policy.Finish(&j, r.now(), cancelFuture, err) {
+		err = r.cfg.Store.DeleteJob(j.ID)
+	} else {
+		err = r.cfg.Store.UpdateJob(j)
+	}
+	if err != nil {
+		r.cfg.Log.Error("Saving scheduled job",
+			"error", err,
+			"grainID", j.GrainID,
+			"jobID", j.ID,
+		)
+		return
+	}
+	if j.Disabled {
+		r.cfg.Log.Warn("Scheduled job disabled after repeated failures",
+			"grainID", j.GrainID,
+			"jobID", j.ID,
+		)
+		if r.cfg.OnDisable != nil {
+			r.cfg.OnDisable(j)
+		}
+	}
+	r.Wake()
+}
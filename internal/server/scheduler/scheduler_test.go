@@ -0,0 +1,182 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestPeriodAfter(t *testing.T) {
+	t0 := time.Date(2024, time.January, 31, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, t0.Add(time.Hour), Hourly.After(t0))
+	assert.Equal(t, time.Date(2024, time.February, 1, 12, 0, 0, 0, time.UTC), Daily.After(t0))
+	assert.Equal(t, time.Date(2024, time.February, 7, 12, 0, 0, 0, time.UTC), Weekly.After(t0))
+	assert.Equal(t, time.Date(2025, time.January, 31, 12, 0, 0, 0, time.UTC), Annually.After(t0))
+	assert.True(t, Monthly.IsValid())
+	assert.False(t, Period("fortnightly").IsValid())
+	assert.Panics(t, func() { OneShot.After(t0) })
+}
+
+func TestCheckSlack(t *testing.T) {
+	assert.NoError(t, CheckSlack(0))
+	assert.NoError(t, CheckSlack(MinimumSlack))
+	assert.ErrorIs(t, CheckSlack(time.Minute), ErrSlackTooSmall)
+}
+
+func TestFinish(t *testing.T) {
+	p := Policy{
+		Backoff:     time.Minute,
+		MaxBackoff:  3 * time.Minute,
+		MaxFailures: 4,
+	}
+	now := time.Unix(1700000000, 0)
+	errBoom := errors.New("boom")
+
+	j := Job{Period: Daily}
+	for i, backoff := range []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute} {
+		assert.False(t, p.Finish(&j, now, false, errBoom))
+		assert.Equal(t, i+1, j.Failures)
+		assert.Equal(t, now.Add(backoff), j.Next)
+		assert.Equal(t, "boom", j.LastError)
+		assert.False(t, j.Disabled)
+	}
+	// Success resets the count of failures:
+	assert.False(t, p.Finish(&j, now, false, nil))
+	assert.Equal(t, 0, j.Failures)
+	assert.Equal(t, "", j.LastError)
+	assert.Equal(t, now.AddDate(0, 0, 1), j.Next)
+
+	for i := 0; i < p.MaxFailures; i++ {
+		assert.False(t, p.Finish(&j, now, false, errBoom))
+	}
+	assert.True(t, j.Disabled)
+
+	assert.True(t, p.Finish(&Job{Period: Daily}, now, true, nil), "canceled jobs are removed")
+	assert.True(t, p.Finish(&Job{}, now, false, nil), "one-shot jobs are removed after they run")
+	assert.False(t, p.Finish(&Job{}, now, false, errBoom), "one-shot jobs are retried")
+}
+
+// memStore is a Store which keeps jobs in memory.
+type memStore struct {
+	mu   sync.Mutex
+	jobs map[string]Job
+}
+
+func (s *memStore) DueJobs(now time.Time) ([]Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ret []Job
+	for _, j := range s.jobs {
+		if !j.Disabled && !j.Next.After(now) {
+			ret = append(ret, j)
+		}
+	}
+	sort.Slice(ret, func(i, k int) bool { return ret[i].ID < ret[k].ID })
+	return ret, nil
+}
+
+func (s *memStore) NextDue() (next time.Time, ok bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, j := range s.jobs {
+		if !j.Disabled && (!ok || j.Next.Before(next)) {
+			next, ok = j.Next, true
+		}
+	}
+	return next, ok, nil
+}
+
+func (s *memStore) UpdateJob(j Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[j.ID] = j
+	return nil
+}
+
+func (s *memStore) DeleteJob(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.jobs, id)
+	return nil
+}
+
+func (s *memStore) get(id string) (Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	return j, ok
+}
+
+func TestRunner(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	store := &memStore{jobs: map[string]Job{
+		"once":     {ID: "once", Next: past},
+		"periodic": {ID: "periodic", Period: Hourly, Next: past},
+		"failing":  {ID: "failing", Period: Daily, Next: past, Failures: 1},
+		"later":    {ID: "later", Next: time.Now().Add(time.Hour)},
+	}}
+	ran := make(chan string, 10)
+	disabled := make(chan Job, 1)
+	r := NewRunner(Config{
+		Store: store,
+		Run: func(ctx context.Context, j Job) (bool, error) {
+			ran <- j.ID
+			if j.ID == "failing" {
+				return false, errors.New("grain is broken")
+			}
+			return false, nil
+		},
+		OnDisable: func(j Job) { disabled <- j },
+		Policy: Policy{
+			Backoff:     time.Millisecond,
+			MaxBackoff:  time.Millisecond,
+			MaxFailures: 3,
+			Timeout:     time.Minute,
+		},
+		Log: slog.New(slog.NewTextHandler(io.Discard)),
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- r.Serve(ctx) }()
+
+	select {
+	case j := <-disabled:
+		assert.Equal(t, "failing", j.ID)
+		assert.Equal(t, 3, j.Failures)
+		assert.Equal(t, "grain is broken", j.LastError)
+	case <-time.After(5 * time.Second):
+		t.Fatal("failing job was not disabled")
+	}
+	require.Eventually(t, func() bool {
+		_, onceLeft := store.get("once")
+		j, _ := store.get("periodic")
+		return !onceLeft && j.Next.After(time.Now())
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	close(ran)
+
+	counts := map[string]int{}
+	for id := range ran {
+		counts[id]++
+	}
+	assert.Equal(t, map[string]int{"once": 1, "periodic": 1, "failing": 2}, counts)
+
+	_, ok := store.get("once")
+	assert.False(t, ok, "one-shot jobs are removed after they run")
+	j, ok := store.get("periodic")
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), j.Next, time.Minute)
+	j, _ = store.get("failing")
+	assert.True(t, j.Disabled)
+	j, _ = store.get("later")
+	assert.Equal(t, 0, j.Failures, "jobs which aren't due don't run")
+}
@@ -0,0 +1,207 @@
+// Package seccomp compiles extra system call rules for grains, which are
+// layered on top of the sandbox's built-in seccomp filter (c/filter.s).
+//
+// The built-in filter is assembled along with Tempest, so accommodating an app
+// which needs a system call it denies, e.g. io_uring_setup, would otherwise
+// mean rebuilding Tempest. Instead, the admin may write a policy granting (or
+// denying) system calls to particular apps. When a grain starts, its rules
+// are compiled into a short BPF program, which the sandbox launcher runs
+// ahead of the built-in filter: a system call matching a rule is allowed or
+// denied as the rule says, and any other falls through to the built-in
+// filter.
+//
+// Rules come only from the admin, not from apps' packages: a package which
+// could grant itself system calls could undo the sandbox.
+package seccomp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+
+	"golang.org/x/net/bpf"
+	"golang.org/x/sys/unix"
+)
+
+// MaxRules is the most rules which may apply to a grain. The kernel limits
+// a filter to 4096 instructions, which the layer shares with the built-in
+// filter.
+const MaxRules = 1024
+
+// An Action is what to do with a system call which matches a rule.
+type Action int
+
+const (
+	Allow Action = iota
+	Deny
+)
+
+// A Rule allows or denies a system call.
+type Rule struct {
+	Syscall string
+	Action  Action
+
+	// The error a denied system call fails with. Zero means EPERM.
+	Errno syscall.Errno
+}
+
+// An entry is a line of a Policy.
+type entry struct {
+	match string // App ID, package ID, or "*".
+	rules []Rule
+}
+
+// A Policy says which extra rules apply to which apps' grains.
+type Policy struct {
+	entries []entry
+}
+
+// LoadPolicy reads a policy from the named file; see ParsePolicy. An empty
+// path yields an empty policy.
+func LoadPolicy(path string) (Policy, error) {
+	if path == "" {
+		return Policy{}, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return Policy{}, err
+	}
+	defer f.Close()
+	return ParsePolicy(f)
+}
+
+// ParsePolicy parses a policy. Each line has the form:
+//
+//	<app-id|package-id|*> <action> <syscall>...
+//
+// where action is "allow", or "deny", which fails the system calls with
+// EPERM, or e.g. "deny=ENOSYS" to fail them with another error. "*" matches
+// every app. Blank lines and lines starting with '#' are ignored.
+//
+// A grain is subject to the rules of every line matching its app or
+// package; where more than one rule names a system call, the first wins.
+func ParsePolicy(r io.Reader) (Policy, error) {
+	var p Policy
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			return Policy{}, fmt.Errorf("line %d: expected an app, an action and system calls", n)
+		}
+		action, errno, err := parseAction(fields[1])
+		if err != nil {
+			return Policy{}, fmt.Errorf("line %d: %w", n, err)
+		}
+		e := entry{match: fields[0]}
+		for _, name := range fields[2:] {
+			if _, ok := syscalls[name]; !ok {
+				return Policy{}, fmt.Errorf("line %d: unknown system call %q", n, name)
+			}
+			e.rules = append(e.rules, Rule{Syscall: name, Action: action, Errno: errno})
+		}
+		p.entries = append(p.entries, e)
+	}
+	return p, scanner.Err()
+}
+
+// parseAction parses the action of a line of a policy.
+func parseAction(s string) (Action, syscall.Errno, error) {
+	name, errnoName, hasErrno := strings.Cut(s, "=")
+	switch {
+	case name == "allow" && !hasErrno:
+		return Allow, 0, nil
+	case name == "deny" && !hasErrno:
+		return Deny, 0, nil
+	case name == "deny":
+		// Seccomp can return errors up to SECCOMP_RET_DATA, but there
+		// are no names beyond these.
+		for e := syscall.Errno(1); e < 4096; e++ {
+			if unix.ErrnoName(e) == errnoName {
+				return Deny, e, nil
+			}
+		}
+		return 0, 0, fmt.Errorf("unknown error %q", errnoName)
+	}
+	return 0, 0, fmt.Errorf("unknown action %q", s)
+}
+
+// Empty reports whether the policy has no rules.
+func (p Policy) Empty() bool {
+	return len(p.entries) == 0
+}
+
+// Rules returns the rules which apply to grains of the given app and package.
+// appID may be empty, if the package has no app ID.
+func (p Policy) Rules(appID, pkgID string) []Rule {
+	var ret []Rule
+	for _, e := range p.entries {
+		if e.match == "*" || e.match == pkgID || (appID != "" && e.match == appID) {
+			ret = append(ret, e.rules...)
+		}
+	}
+	return ret
+}
+
+// Compile compiles rules into a BPF program to run ahead of the built-in
+// filter, returning it as an array of struct sock_filter, for the sandbox
+// launcher. The program doesn't end with a return; it falls through to the
+// built-in filter instead.
+func Compile(rules []Rule) ([]byte, error) {
+	if len(rules) > MaxRules {
+		return nil, fmt.Errorf("%d system call rules given; the most allowed is %d",
+			len(rules), MaxRules)
+	}
+	insns := []bpf.Instruction{
+		// Leave system calls of other architectures, whose numbers
+		// mean something else, to the built-in filter, which denies
+		// them:
+		bpf.LoadAbsolute{Off: offArch, Size: 4},
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: unix.AUDIT_ARCH_X86_64, SkipTrue: 1},
+		bpf.Jump{Skip: uint32(2*len(rules) + 1)},
+		bpf.LoadAbsolute{Off: offNr, Size: 4},
+	}
+	for _, r := range rules {
+		nr, ok := syscalls[r.Syscall]
+		if !ok {
+			return nil, fmt.Errorf("unknown system call %q", r.Syscall)
+		}
+		ret := uint32(unix.SECCOMP_RET_ALLOW)
+		if r.Action == Deny {
+			errno := r.Errno
+			if errno == 0 {
+				errno = syscall.EPERM
+			}
+			ret = unix.SECCOMP_RET_ERRNO | (uint32(errno) & unix.SECCOMP_RET_DATA)
+		}
+		insns = append(insns,
+			bpf.JumpIf{Cond: bpf.JumpEqual, Val: nr, SkipFalse: 1},
+			bpf.RetConstant{Val: ret},
+		)
+	}
+	raw, err := bpf.Assemble(insns)
+	if err != nil {
+		return nil, err
+	}
+	// struct sock_filter, in the host's byte order.
+	buf := make([]byte, 0, 8*len(raw))
+	for _, insn := range raw {
+		buf = binary.NativeEndian.AppendUint16(buf, insn.Op)
+		buf = append(buf, insn.Jt, insn.Jf)
+		buf = binary.NativeEndian.AppendUint32(buf, insn.K)
+	}
+	return buf, nil
+}
+
+// Offsets of fields of struct seccomp_data.
+const (
+	offNr   = 0
+	offArch = 4
+)
@@ -0,0 +1,100 @@
+package seccomp
+
+import (
+	"encoding/binary"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/bpf"
+	"golang.org/x/sys/unix"
+)
+
+const testPolicy = `
+# io_uring for one app:
+6vp6kzpwu3ggs9ruy8dh3uvztqzz35uv4vxctp62gu6k0wvr1s9h allow io_uring_setup io_uring_enter
+0123456789abcdef0123456789abcdef deny=ENOSYS getrandom
+*   deny  ptrace
+`
+
+func TestParsePolicy(t *testing.T) {
+	p, err := ParsePolicy(strings.NewReader(testPolicy))
+	require.NoError(t, err)
+
+	assert.Equal(t, []Rule{
+		{Syscall: "io_uring_setup", Action: Allow},
+		{Syscall: "io_uring_enter", Action: Allow},
+		{Syscall: "ptrace", Action: Deny},
+	}, p.Rules("6vp6kzpwu3ggs9ruy8dh3uvztqzz35uv4vxctp62gu6k0wvr1s9h", "ffffffffffffffffffffffffffffffff"))
+	assert.Equal(t, []Rule{
+		{Syscall: "getrandom", Action: Deny, Errno: syscall.ENOSYS},
+		{Syscall: "ptrace", Action: Deny},
+	}, p.Rules("", "0123456789abcdef0123456789abcdef"))
+
+	for _, bad := range []string{
+		"* allow",
+		"* permit read",
+		"* deny=EWHATEVER read",
+		"* allow=EPERM read",
+		"* allow not_a_syscall",
+	} {
+		_, err := ParsePolicy(strings.NewReader(bad))
+		assert.Error(t, err, bad)
+	}
+}
+
+// run runs prog, followed by a stand-in for the built-in filter which
+// returns fallthrough, on a system call.
+func run(t *testing.T, prog []byte, arch, nr uint32) uint32 {
+	require.Zero(t, len(prog)%8)
+	var insns []bpf.Instruction
+	for i := 0; i < len(prog); i += 8 {
+		insns = append(insns, bpf.RawInstruction{
+			Op: binary.NativeEndian.Uint16(prog[i:]),
+			Jt: prog[i+2],
+			Jf: prog[i+3],
+			K:  binary.NativeEndian.Uint32(prog[i+4:]),
+		}.Disassemble())
+	}
+	insns = append(insns, bpf.RetConstant{Val: fellThrough})
+	vm, err := bpf.NewVM(insns)
+	require.NoError(t, err)
+	// The VM loads words big-endian, as in network packets, where the
+	// kernel loads struct seccomp_data's fields in the host's byte order.
+	data := make([]byte, 64)
+	binary.BigEndian.PutUint32(data[offNr:], nr)
+	binary.BigEndian.PutUint32(data[offArch:], arch)
+	ret, err := vm.Run(data)
+	require.NoError(t, err)
+	return uint32(ret)
+}
+
+const fellThrough = 0x12345
+
+func TestCompile(t *testing.T) {
+	prog, err := Compile([]Rule{
+		{Syscall: "io_uring_setup", Action: Allow},
+		{Syscall: "ptrace", Action: Deny},
+		{Syscall: "getrandom", Action: Deny, Errno: syscall.ENOSYS},
+		{Syscall: "ptrace", Action: Allow},
+	})
+	require.NoError(t, err)
+
+	const x86_64 = unix.AUDIT_ARCH_X86_64
+	assert.Equal(t, uint32(unix.SECCOMP_RET_ALLOW), run(t, prog, x86_64, syscalls["io_uring_setup"]))
+	assert.Equal(t, uint32(unix.SECCOMP_RET_ERRNO|uint32(syscall.EPERM)), run(t, prog, x86_64, syscalls["ptrace"]),
+		"the first matching rule wins")
+	assert.Equal(t, uint32(unix.SECCOMP_RET_ERRNO|uint32(syscall.ENOSYS)), run(t, prog, x86_64, syscalls["getrandom"]))
+	assert.Equal(t, uint32(fellThrough), run(t, prog, x86_64, syscalls["read"]))
+	assert.Equal(t, uint32(fellThrough), run(t, prog, unix.AUDIT_ARCH_I386, syscalls["io_uring_setup"]),
+		"other architectures are left to the built-in filter")
+
+	prog, err = Compile(nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(fellThrough), run(t, prog, x86_64, syscalls["read"]))
+
+	_, err = Compile(make([]Rule, MaxRules+1))
+	assert.Error(t, err)
+}
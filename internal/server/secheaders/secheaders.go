@@ -0,0 +1,72 @@
+// Package secheaders sets the security headers of HTTP responses, e.g.
+// Content-Security-Policy and Strict-Transport-Security, according to a
+// profile for each kind of host, e.g. grains' UI hosts.
+//
+// The headers are set before the request is handled, so they are defaults:
+// a handler may replace them for its own responses, e.g. to let a page be
+// framed, or add further Content-Security-Policy headers, each of which
+// browsers enforce as well as the profile's.
+package secheaders
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// A Profile is the security headers of responses from a kind of host. Empty
+// headers are not sent.
+type Profile struct {
+	ContentSecurityPolicy string
+	FrameOptions          string // X-Frame-Options
+	ReferrerPolicy        string
+	PermissionsPolicy     string
+}
+
+// Config configures the headers.
+type Config struct {
+	// Profiles of each kind of host, and of hosts of kinds which have
+	// none of their own.
+	Profiles map[string]Profile
+	Default  Profile
+
+	// If not zero, responses over HTTPS tell browsers to only use HTTPS
+	// for this long, for the host and its subdomains.
+	HSTSMaxAge time.Duration
+}
+
+// Profile returns the profile of hosts of the given kind.
+func (c Config) Profile(kind string) Profile {
+	if p, ok := c.Profiles[kind]; ok {
+		return p
+	}
+	return c.Default
+}
+
+// Handler returns a handler which sets the headers of each response before
+// passing the request to h. kind returns the kind of host the request is for.
+// The request's URL must have its Scheme set; see forwarded.Handler.
+func (c Config) Handler(h http.Handler, kind func(*http.Request) string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		c.Profile(kind(req)).set(w.Header())
+		if c.HSTSMaxAge > 0 && req.URL.Scheme == "https" {
+			w.Header().Set("Strict-Transport-Security",
+				"max-age="+strconv.FormatInt(int64(c.HSTSMaxAge/time.Second), 10)+
+					"; includeSubDomains")
+		}
+		h.ServeHTTP(w, req)
+	})
+}
+
+func (p Profile) set(h http.Header) {
+	for name, value := range map[string]string{
+		"Content-Security-Policy": p.ContentSecurityPolicy,
+		"X-Frame-Options":         p.FrameOptions,
+		"Referrer-Policy":         p.ReferrerPolicy,
+		"Permissions-Policy":      p.PermissionsPolicy,
+	} {
+		if value != "" {
+			h.Set(name, value)
+		}
+	}
+}
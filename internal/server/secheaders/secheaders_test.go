@@ -0,0 +1,59 @@
+package secheaders
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	cfg := Config{
+		Profiles: map[string]Profile{
+			"main": {
+				ContentSecurityPolicy: "frame-ancestors 'none'",
+				FrameOptions:          "DENY",
+				ReferrerPolicy:        "same-origin",
+			},
+		},
+		Default:    Profile{ReferrerPolicy: "no-referrer"},
+		HSTSMaxAge: 24 * time.Hour,
+	}
+	h := cfg.Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/framed" {
+			w.Header().Set("Content-Security-Policy", "frame-ancestors *")
+			w.Header().Del("X-Frame-Options")
+		}
+	}), func(req *http.Request) string {
+		return req.Host
+	})
+	do := func(url, host string) http.Header {
+		req := httptest.NewRequest("GET", url, nil)
+		req.Host = host
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Result().Header
+	}
+
+	hdr := do("https://main/", "main")
+	assert.Equal(t, "frame-ancestors 'none'", hdr.Get("Content-Security-Policy"))
+	assert.Equal(t, "DENY", hdr.Get("X-Frame-Options"))
+	assert.Equal(t, "same-origin", hdr.Get("Referrer-Policy"))
+	assert.Equal(t, "max-age=86400; includeSubDomains", hdr.Get("Strict-Transport-Security"))
+	_, ok := hdr["Permissions-Policy"]
+	assert.False(t, ok)
+
+	// Handlers may replace the profile's headers:
+	hdr = do("https://main/framed", "main")
+	assert.Equal(t, []string{"frame-ancestors *"}, hdr.Values("Content-Security-Policy"))
+	assert.Equal(t, "", hdr.Get("X-Frame-Options"))
+
+	// Other hosts get the default profile, and HSTS is only sent over
+	// HTTPS:
+	hdr = do("http://other/", "other")
+	assert.Equal(t, "no-referrer", hdr.Get("Referrer-Policy"))
+	assert.Equal(t, "", hdr.Get("Content-Security-Policy"))
+	assert.Equal(t, "", hdr.Get("Strict-Transport-Security"))
+}
@@ -0,0 +1,258 @@
+package serverbackup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// RestoreOptions configure a restore.
+type RestoreOptions struct {
+	Log *slog.Logger
+
+	// Where to restore the database.
+	DBPath string
+
+	// Where to restore each tree. Trees in the backup which aren't listed
+	// are skipped.
+	Trees []Tree
+
+	// Whether to restore over existing data. Otherwise, the database
+	// mustn't exist, and trees' directories must be empty, if they exist.
+	Force bool
+}
+
+// Restore restores the backup in dir, checking the contents of each file
+// against the manifest as it goes. Tempest must not be running.
+func Restore(dir string, opts RestoreOptions) error {
+	m, err := ReadManifest(dir)
+	if err != nil {
+		return err
+	}
+	if !opts.Force {
+		if err := checkEmpty(opts); err != nil {
+			return err
+		}
+	}
+	trees := make(map[string]Tree, len(opts.Trees))
+	for _, t := range opts.Trees {
+		trees[t.Name] = t
+	}
+	r := restorer{dest: filepath.Dir(dir), name: filepath.Base(dir)}
+
+	// Directories' modification times are set last, since restoring
+	// their contents changes them.
+	var dirs []File
+	skipped := make(map[string]bool)
+	for _, f := range m.Files {
+		treeName, rel, err := splitPath(f.Path)
+		if err != nil {
+			return err
+		}
+		tree, ok := trees[treeName]
+		if !ok {
+			if !skipped[treeName] {
+				opts.Log.Info("Skipping tree with nowhere to restore it", "tree", treeName)
+				skipped[treeName] = true
+			}
+			continue
+		}
+		target := filepath.Join(tree.Dir, filepath.FromSlash(rel))
+		if err := r.restoreFile(f, target); err != nil {
+			return fmt.Errorf("restoring %s: %w", f.Path, err)
+		}
+		if f.Mode.IsDir() {
+			f.Path = target
+			dirs = append(dirs, f)
+		}
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := os.Chtimes(dirs[i].Path, dirs[i].ModTime, dirs[i].ModTime); err != nil {
+			return err
+		}
+	}
+	return r.restoreDB(m.DB, opts.DBPath)
+}
+
+// checkEmpty returns an error if restoring would overwrite existing data.
+func checkEmpty(opts RestoreOptions) error {
+	if _, err := os.Lstat(opts.DBPath); err == nil {
+		return fmt.Errorf("%s exists; not restoring over it", opts.DBPath)
+	}
+	for _, t := range opts.Trees {
+		entries, err := os.ReadDir(t.Dir)
+		if err == nil && len(entries) > 0 {
+			return fmt.Errorf("%s isn't empty; not restoring over it", t.Dir)
+		} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitPath splits the path of a file in a manifest into the name of its
+// tree and its path within that, checking that it stays inside the tree.
+func splitPath(p string) (tree, rel string, err error) {
+	if !fs.ValidPath(p) || p == "." {
+		return "", "", fmt.Errorf("invalid path in manifest: %q", p)
+	}
+	tree, rel, _ = strings.Cut(p, "/")
+	if rel == "" {
+		rel = "."
+	}
+	return tree, rel, nil
+}
+
+// restorer restores the files of a backup.
+type restorer struct {
+	dest string // The directory holding the backup and its bases.
+	name string // The backup's name.
+}
+
+// source returns the path of the copy of f in the backups.
+func (r restorer) source(f File) (string, error) {
+	in := r.name
+	if f.In != "" {
+		if _, err := time.Parse(nameFormat, f.In); err != nil {
+			return "", fmt.Errorf("invalid backup name in manifest: %q", f.In)
+		}
+		in = f.In
+	}
+	return filepath.Join(r.dest, in, filesDir, filepath.FromSlash(f.Path)), nil
+}
+
+func (r restorer) restoreFile(f File, target string) error {
+	switch {
+	case f.Mode.IsDir():
+		if err := os.MkdirAll(target, 0700); err != nil {
+			return err
+		}
+		return os.Chmod(target, f.Mode.Perm())
+	case f.Mode&fs.ModeSymlink != 0:
+		if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return os.Symlink(f.Target, target)
+	case f.Mode.IsRegular():
+		src, err := r.source(f)
+		if err != nil {
+			return err
+		}
+		if err := copyVerified(src, target, f); err != nil {
+			return err
+		}
+		return os.Chtimes(target, f.ModTime, f.ModTime)
+	default:
+		return fmt.Errorf("unsupported file mode %v", f.Mode)
+	}
+}
+
+// restoreDB restores the database to path. It is written beside path and
+// renamed into place, so that a failed restore doesn't leave a truncated
+// database.
+func (r restorer) restoreDB(f File, path string) error {
+	src := filepath.Join(r.dest, r.name, dbName)
+	tmp := path + ".restore"
+	if err := copyVerified(src, tmp, f); err != nil {
+		return fmt.Errorf("restoring database: %w", err)
+	}
+	// A journal left by the old database would be applied to the
+	// restored one, corrupting it.
+	for _, ext := range []string{"-wal", "-shm", "-journal"} {
+		if err := os.Remove(path + ext); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			os.Remove(tmp)
+			return err
+		}
+	}
+	return os.Rename(tmp, path)
+}
+
+// copyVerified copies the file at src to dest, with the permissions in f,
+// and returns an error if its contents don't match the hash in f.
+func copyVerified(src, dest string, f File) error {
+	r, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	w, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(w, h), r)
+	if err == nil {
+		err = w.Sync()
+	}
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = checkHash(h.Sum(nil), f)
+	}
+	if err == nil {
+		err = os.Chmod(dest, f.Mode.Perm())
+	}
+	if err != nil {
+		os.Remove(dest)
+	}
+	return err
+}
+
+// checkHash returns an error if sum isn't the hash of f's contents.
+func checkHash(sum []byte, f File) error {
+	if got := hex.EncodeToString(sum); got != f.SHA256 {
+		return fmt.Errorf("%s is corrupt: SHA-256 is %s; expected %s", f.Path, got, f.SHA256)
+	}
+	return nil
+}
+
+// Verify checks the contents of the backup in dir, including the files it
+// shares with earlier backups, against its manifest, returning an error for
+// each file which is missing or corrupt.
+func Verify(dir string) error {
+	m, err := ReadManifest(dir)
+	if err != nil {
+		return err
+	}
+	r := restorer{dest: filepath.Dir(dir), name: filepath.Base(dir)}
+	errs := []error{verifyFile(filepath.Join(dir, dbName), m.DB)}
+	for _, f := range m.Files {
+		if !f.Mode.IsRegular() {
+			continue
+		}
+		if _, _, err := splitPath(f.Path); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		src, err := r.source(f)
+		if err == nil {
+			err = verifyFile(src, f)
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// verifyFile checks the contents of the file at path against f.
+func verifyFile(path string, f File) error {
+	r, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return err
+	}
+	return checkHash(h.Sum(nil), f)
+}
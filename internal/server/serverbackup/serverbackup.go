@@ -0,0 +1,421 @@
+// Package serverbackup backs up a whole Tempest server -- its database, and
+// the directories holding grains, packages and blobs -- and restores it, as
+// `tempest backup` and `tempest restore`.
+//
+// Backups are kept in a destination directory, each in a directory named for
+// the time it was taken:
+//
+//	<dest>/<name>/manifest.json
+//	<dest>/<name>/sandstorm.sqlite3
+//	<dest>/<name>/files/<tree>/<path>
+//
+// The manifest lists every file in the backed-up trees, with the SHA-256 hash
+// of each regular file's contents, and of the database, so that a backup can
+// be checked before it is relied on, and a restore can check that it gets
+// back what was backed up. An incremental backup copies only those files
+// whose size, modification time or mode differ from the latest backup in the
+// destination, as replication does; its manifest names the earlier backup
+// which holds each of the others. Backups which later ones refer to must be
+// kept as long as those are.
+//
+// The database is copied with a snapshot, which is consistent. Grains may be
+// running, and changing their files, during a backup. Where a tree is a btrfs
+// subvolume, a read-only snapshot of it is backed up instead, so that all of
+// its files are from the same moment. Otherwise, each running grain is frozen
+// while its files are copied, so that they are at least consistent with each
+// other.
+package serverbackup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// Names within a backup:
+const (
+	manifestName = "manifest.json"
+	dbName       = "sandstorm.sqlite3"
+	filesDir     = "files"
+	partialExt   = ".partial" // Suffix of backups still being written.
+)
+
+// Format of backups' names, which sort in the order they were taken.
+const nameFormat = "20060102T150405.000000Z"
+
+// A Tree is a directory which is backed up.
+type Tree struct {
+	// Name of the tree in backups, e.g. "grains".
+	Name string
+
+	Dir string
+
+	// Whether the entries of Dir are grains' directories, named by their
+	// IDs, which are frozen while they are copied.
+	Grains bool
+}
+
+// A Manifest describes a backup.
+type Manifest struct {
+	Created time.Time `json:"created"`
+
+	// The backup this one was taken incrementally to, if any.
+	Base string `json:"base,omitempty"`
+
+	DB    File   `json:"db"`
+	Files []File `json:"files"`
+}
+
+// A File is a file in a backup.
+type File struct {
+	// Slash-separated path, starting with the name of its tree.
+	Path    string      `json:"path"`
+	Mode    fs.FileMode `json:"mode"`
+	Size    int64       `json:"size,omitempty"`
+	ModTime time.Time   `json:"modTime"`
+
+	// Hex-encoded hash of the contents of a regular file.
+	SHA256 string `json:"sha256,omitempty"`
+
+	// Target of a symlink.
+	Target string `json:"target,omitempty"`
+
+	// Name of the earlier backup which holds the contents of the file,
+	// if not this one.
+	In string `json:"in,omitempty"`
+}
+
+// unchanged reports whether f appears to be the same as old, which was backed
+// up earlier.
+func (f File) unchanged(old File) bool {
+	return old.SHA256 != "" &&
+		f.Mode == old.Mode &&
+		f.Size == old.Size &&
+		f.ModTime.Equal(old.ModTime)
+}
+
+// Options configure a backup.
+type Options struct {
+	Log *slog.Logger
+
+	// SnapshotDB writes a consistent copy of the database to path.
+	SnapshotDB func(path string) error
+
+	Trees []Tree
+
+	// Whether to copy only the files which changed since the latest
+	// backup. If there isn't one, a full backup is taken.
+	Incremental bool
+
+	// Freeze, if not nil, freezes the grain with the given ID, if it is
+	// running, until thaw is called. thaw is nil if the grain isn't
+	// running.
+	Freeze func(grainID string) (thaw func() error, err error)
+}
+
+// Create takes a backup in dest, which is created if need be, and returns
+// its name.
+func Create(dest string, opts Options) (string, error) {
+	if err := os.MkdirAll(dest, 0700); err != nil {
+		return "", err
+	}
+	b := &backup{
+		opts:     opts,
+		dest:     dest,
+		name:     time.Now().UTC().Format(nameFormat),
+		baseFile: make(map[string]File),
+	}
+	b.manifest.Created = time.Now()
+	if opts.Incremental {
+		if err := b.loadBase(); err != nil {
+			return "", err
+		}
+	}
+	// Write the backup under a temporary name, so that a backup which
+	// doesn't finish is never mistaken for a complete one.
+	b.dir = filepath.Join(dest, b.name+partialExt)
+	if err := os.Mkdir(b.dir, 0700); err != nil {
+		return "", err
+	}
+	if err := b.write(); err != nil {
+		os.RemoveAll(b.dir)
+		return "", err
+	}
+	if err := os.Rename(b.dir, filepath.Join(dest, b.name)); err != nil {
+		os.RemoveAll(b.dir)
+		return "", err
+	}
+	return b.name, nil
+}
+
+// backup is the state of a backup being created.
+type backup struct {
+	opts     Options
+	dest     string
+	name     string
+	dir      string
+	manifest Manifest
+
+	// Files in the base backup, by path, with In set to the backup which
+	// holds them.
+	baseFile map[string]File
+}
+
+// loadBase loads the manifest of the latest backup in dest, if there is one,
+// for an incremental backup.
+func (b *backup) loadBase() error {
+	base, err := Latest(b.dest)
+	if errors.Is(err, fs.ErrNotExist) {
+		b.opts.Log.Info("No earlier backup; taking a full backup")
+		return nil
+	} else if err != nil {
+		return err
+	}
+	m, err := ReadManifest(filepath.Join(b.dest, base))
+	if err != nil {
+		return fmt.Errorf("reading base backup %s: %w", base, err)
+	}
+	b.manifest.Base = base
+	for _, f := range m.Files {
+		if f.In == "" {
+			f.In = base
+		}
+		b.baseFile[f.Path] = f
+	}
+	return nil
+}
+
+// write writes the database and trees to b.dir.
+func (b *backup) write() error {
+	dbPath := filepath.Join(b.dir, dbName)
+	if err := b.opts.SnapshotDB(dbPath); err != nil {
+		return fmt.Errorf("snapshotting database: %w", err)
+	}
+	db, err := hashFile(dbPath)
+	if err != nil {
+		return err
+	}
+	b.manifest.DB = db
+	for _, tree := range b.opts.Trees {
+		if err := b.writeTree(tree); err != nil {
+			return fmt.Errorf("backing up %s: %w", tree.Name, err)
+		}
+	}
+	data, err := json.MarshalIndent(b.manifest, "", "\t")
+	if err != nil {
+		return err
+	}
+	return writeFileSync(filepath.Join(b.dir, manifestName), data)
+}
+
+// writeTree backs up the files of tree.
+func (b *backup) writeTree(tree Tree) error {
+	root := tree.Dir
+	if _, err := os.Stat(root); errors.Is(err, fs.ErrNotExist) {
+		b.opts.Log.Info("Skipping missing directory", "tree", tree.Name, "dir", root)
+		return nil
+	}
+	frozen := false
+	if snap, remove, ok := snapshot(b.opts.Log, root); ok {
+		defer remove()
+		root = snap
+		frozen = true
+	}
+	if !tree.Grains || frozen || b.opts.Freeze == nil {
+		return b.walk(tree.Name, root, "")
+	}
+	fi, err := os.Stat(root)
+	if err != nil {
+		return err
+	}
+	b.manifest.Files = append(b.manifest.Files, File{
+		Path:    tree.Name,
+		Mode:    fi.Mode(),
+		ModTime: fi.ModTime(),
+	})
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return err
+	}
+	for _, ent := range entries {
+		if err := b.writeGrain(tree.Name, root, ent.Name()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeGrain backs up the grain stored in root/grainID, frozen if it is
+// running.
+func (b *backup) writeGrain(treeName, root, grainID string) error {
+	thaw, err := b.opts.Freeze(grainID)
+	if err != nil {
+		return fmt.Errorf("freezing grain %s: %w", grainID, err)
+	}
+	if thaw != nil {
+		defer func() {
+			if err := thaw(); err != nil {
+				b.opts.Log.Error("Thawing grain after backing it up",
+					"error", err,
+					"grainID", grainID,
+				)
+			}
+		}()
+	}
+	return b.walk(treeName, filepath.Join(root, grainID), grainID)
+}
+
+// walk backs up the files under dir, which is rel within the tree.
+func (b *backup) walk(treeName, dir, rel string) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		r, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		name := path.Join(treeName, rel, filepath.ToSlash(r))
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		f := File{Path: name, Mode: fi.Mode(), ModTime: fi.ModTime()}
+		switch {
+		case fi.IsDir():
+		case fi.Mode()&fs.ModeSymlink != 0:
+			f.Target, err = os.Readlink(p)
+			if err != nil {
+				return err
+			}
+		case fi.Mode().IsRegular():
+			f.Size = fi.Size()
+			if old, ok := b.baseFile[name]; ok && f.unchanged(old) {
+				f.SHA256 = old.SHA256
+				f.In = old.In
+				break
+			}
+			f.SHA256, err = b.copyFile(p, name)
+			if err != nil {
+				return err
+			}
+		default:
+			// Special files, such as sockets, can't be backed up.
+			return nil
+		}
+		b.manifest.Files = append(b.manifest.Files, f)
+		return nil
+	})
+}
+
+// copyFile copies the file at src into the backup, as name, returning the
+// hash of its contents.
+func (b *backup) copyFile(src, name string) (string, error) {
+	r, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	dest := filepath.Join(b.dir, filesDir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(dest), 0700); err != nil {
+		return "", err
+	}
+	w, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(w, h), r)
+	if err == nil {
+		err = w.Sync()
+	}
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	return hex.EncodeToString(h.Sum(nil)), err
+}
+
+// writeFileSync writes data to a new file at path, and syncs it.
+func writeFileSync(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// hashFile returns a File describing the regular file at path, including
+// the hash of its contents.
+func hashFile(path string) (File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return File{}, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return File{}, err
+	}
+	return File{
+		Path:   filepath.Base(path),
+		Mode:   0600,
+		Size:   size,
+		SHA256: hex.EncodeToString(h.Sum(nil)),
+	}, nil
+}
+
+// Latest returns the name of the latest complete backup in dest, or an error
+// wrapping fs.ErrNotExist if there are none.
+func Latest(dest string) (string, error) {
+	entries, err := os.ReadDir(dest)
+	if err != nil {
+		return "", err
+	}
+	var names []string
+	for _, ent := range entries {
+		if !ent.IsDir() || strings.HasSuffix(ent.Name(), partialExt) {
+			continue
+		}
+		if _, err := time.Parse(nameFormat, ent.Name()); err == nil {
+			names = append(names, ent.Name())
+		}
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("no backups in %s: %w", dest, fs.ErrNotExist)
+	}
+	sort.Strings(names)
+	return names[len(names)-1], nil
+}
+
+// ReadManifest reads the manifest of the backup in dir.
+func ReadManifest(dir string) (Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, manifestName))
+	if err != nil {
+		return Manifest{}, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return Manifest{}, fmt.Errorf("parsing %s: %w", manifestName, err)
+	}
+	return m, nil
+}
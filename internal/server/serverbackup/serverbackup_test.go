@@ -0,0 +1,170 @@
+package serverbackup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+// testServer is a server's data, for backing up.
+type testServer struct {
+	db     string
+	grains string
+	pkgs   string
+}
+
+func newTestServer(t *testing.T) testServer {
+	dir := t.TempDir()
+	s := testServer{
+		db:     filepath.Join(dir, "sandstorm.sqlite3"),
+		grains: filepath.Join(dir, "grains"),
+		pkgs:   filepath.Join(dir, "packages"),
+	}
+	writeFile(t, s.db, "database")
+	writeFile(t, filepath.Join(s.grains, "g1", "sandbox", "notes.txt"), "hello")
+	writeFile(t, filepath.Join(s.grains, "g2", "sandbox", "data"), "g2 data")
+	writeFile(t, filepath.Join(s.pkgs, "p1", "bin", "app"), "#!/bin/sh")
+	require.NoError(t, os.Symlink("bin/app", filepath.Join(s.pkgs, "p1", "start")))
+	return s
+}
+
+func (s testServer) trees() []Tree {
+	return []Tree{
+		{Name: "grains", Dir: s.grains, Grains: true},
+		{Name: "packages", Dir: s.pkgs},
+	}
+}
+
+func writeFile(t *testing.T, path, data string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+}
+
+func readFile(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func (s testServer) backup(t *testing.T, dest string, incremental bool, frozen *[]string) string {
+	name, err := Create(dest, Options{
+		Log: slog.Default(),
+		SnapshotDB: func(path string) error {
+			data, err := os.ReadFile(s.db)
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(path, data, 0600)
+		},
+		Trees:       s.trees(),
+		Incremental: incremental,
+		Freeze: func(grainID string) (func() error, error) {
+			*frozen = append(*frozen, grainID)
+			return func() error { return nil }, nil
+		},
+	})
+	require.NoError(t, err)
+	return name
+}
+
+func TestBackupRestore(t *testing.T) {
+	s := newTestServer(t)
+	dest := t.TempDir()
+
+	var frozen []string
+	full := s.backup(t, dest, false, &frozen)
+	assert.Equal(t, []string{"g1", "g2"}, frozen)
+	require.NoError(t, Verify(filepath.Join(dest, full)))
+
+	// Change some things, and take an incremental backup.
+	later := time.Now().Add(time.Hour)
+	writeFile(t, filepath.Join(s.grains, "g1", "sandbox", "notes.txt"), "goodbye")
+	require.NoError(t, os.Chtimes(filepath.Join(s.grains, "g1", "sandbox", "notes.txt"), later, later))
+	writeFile(t, filepath.Join(s.grains, "g3", "sandbox", "new"), "g3")
+	writeFile(t, s.db, "database 2")
+	incr := s.backup(t, dest, true, &frozen)
+	assert.NotEqual(t, full, incr)
+
+	latest, err := Latest(dest)
+	require.NoError(t, err)
+	assert.Equal(t, incr, latest)
+
+	m, err := ReadManifest(filepath.Join(dest, incr))
+	require.NoError(t, err)
+	assert.Equal(t, full, m.Base)
+	in := make(map[string]string)
+	for _, f := range m.Files {
+		in[f.Path] = f.In
+	}
+	assert.Equal(t, "", in["grains/g1/sandbox/notes.txt"], "changed files are copied")
+	assert.Equal(t, "", in["grains/g3/sandbox/new"], "new files are copied")
+	assert.Equal(t, full, in["grains/g2/sandbox/data"], "unchanged files aren't")
+	assert.Equal(t, full, in["packages/p1/bin/app"])
+	_, err = os.Stat(filepath.Join(dest, incr, filesDir, "packages", "p1", "bin", "app"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	require.NoError(t, Verify(filepath.Join(dest, incr)))
+
+	// Restore the incremental backup somewhere new.
+	r := newTestServer(t)
+	require.NoError(t, os.RemoveAll(r.db))
+	require.NoError(t, os.RemoveAll(r.grains))
+	require.NoError(t, os.RemoveAll(r.pkgs))
+	opts := RestoreOptions{Log: slog.Default(), DBPath: r.db, Trees: r.trees()}
+	require.NoError(t, Restore(filepath.Join(dest, incr), opts))
+	assert.Equal(t, "database 2", readFile(t, r.db))
+	assert.Equal(t, "goodbye", readFile(t, filepath.Join(r.grains, "g1", "sandbox", "notes.txt")))
+	assert.Equal(t, "g2 data", readFile(t, filepath.Join(r.grains, "g2", "sandbox", "data")))
+	assert.Equal(t, "g3", readFile(t, filepath.Join(r.grains, "g3", "sandbox", "new")))
+	target, err := os.Readlink(filepath.Join(r.pkgs, "p1", "start"))
+	require.NoError(t, err)
+	assert.Equal(t, "bin/app", target)
+	fi, err := os.Stat(filepath.Join(r.grains, "g1", "sandbox", "notes.txt"))
+	require.NoError(t, err)
+	assert.True(t, fi.ModTime().Equal(later), "modification times are restored")
+
+	// Restoring over the restored server requires force.
+	assert.Error(t, Restore(filepath.Join(dest, incr), opts))
+	opts.Force = true
+	require.NoError(t, Restore(filepath.Join(dest, full), opts))
+	assert.Equal(t, "database", readFile(t, r.db))
+	assert.Equal(t, "hello", readFile(t, filepath.Join(r.grains, "g1", "sandbox", "notes.txt")))
+}
+
+func TestVerifyCorrupt(t *testing.T) {
+	s := newTestServer(t)
+	dest := t.TempDir()
+	var frozen []string
+	full := s.backup(t, dest, false, &frozen)
+	incr := s.backup(t, dest, true, &frozen)
+
+	// Corrupting the full backup breaks the incremental one too.
+	writeFile(t, filepath.Join(dest, full, filesDir, "grains", "g2", "sandbox", "data"), "bit rot")
+	assert.Error(t, Verify(filepath.Join(dest, full)))
+	assert.Error(t, Verify(filepath.Join(dest, incr)))
+
+	r := newTestServer(t)
+	err := Restore(filepath.Join(dest, incr), RestoreOptions{
+		Log:    slog.Default(),
+		DBPath: r.db,
+		Trees:  r.trees(),
+		Force:  true,
+	})
+	assert.ErrorContains(t, err, "corrupt")
+}
+
+func TestLatestSkipsPartial(t *testing.T) {
+	dest := t.TempDir()
+	_, err := Latest(dest)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	require.NoError(t, os.Mkdir(filepath.Join(dest, "20240101T000000.000000Z"), 0700))
+	require.NoError(t, os.Mkdir(filepath.Join(dest, "20250101T000000.000000Z"+partialExt), 0700))
+	require.NoError(t, os.Mkdir(filepath.Join(dest, "notes"), 0700))
+	latest, err := Latest(dest)
+	require.NoError(t, err)
+	assert.Equal(t, "20240101T000000.000000Z", latest)
+}
@@ -0,0 +1,63 @@
+package serverbackup
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+
+	"golang.org/x/exp/slog"
+	"golang.org/x/sys/unix"
+)
+
+// The inode number of the root of every btrfs subvolume.
+const btrfsSubvolumeIno = 256
+
+// snapshot takes a read-only snapshot of dir, if it is the root of a btrfs
+// subvolume, returning the path of the snapshot and a function which
+// deletes it. ok is false if dir can't be snapshotted, in which case it
+// should be backed up directly.
+func snapshot(lg *slog.Logger, dir string) (snap string, remove func(), ok bool) {
+	if !isSubvolume(dir) {
+		return "", nil, false
+	}
+	// The snapshot must be on the same file system; put it beside dir,
+	// hidden, so nothing which lists dir's parent mistakes it for data.
+	snap = filepath.Join(filepath.Dir(dir),
+		fmt.Sprintf(".%s.backup-%d", filepath.Base(dir), os.Getpid()))
+	out, err := exec.Command("btrfs", "subvolume", "snapshot", "-r", dir, snap).CombinedOutput()
+	if err != nil {
+		lg.Warn("Couldn't snapshot btrfs subvolume; copying it directly",
+			"dir", dir,
+			"error", err,
+			"output", string(out),
+		)
+		return "", nil, false
+	}
+	lg.Info("Backing up btrfs snapshot", "dir", dir, "snapshot", snap)
+	return snap, func() {
+		out, err := exec.Command("btrfs", "subvolume", "delete", snap).CombinedOutput()
+		if err != nil {
+			lg.Error("Deleting btrfs snapshot",
+				"snapshot", snap,
+				"error", err,
+				"output", string(out),
+			)
+		}
+	}, true
+}
+
+// isSubvolume reports whether dir is the root of a btrfs subvolume.
+func isSubvolume(dir string) bool {
+	var fsStat unix.Statfs_t
+	if err := unix.Statfs(dir, &fsStat); err != nil || fsStat.Type != unix.BTRFS_SUPER_MAGIC {
+		return false
+	}
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return false
+	}
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	return ok && st.Ino == btrfsSubvolumeIno
+}
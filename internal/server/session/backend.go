@@ -0,0 +1,66 @@
+package session
+
+import (
+	"errors"
+
+	"sandstorm.org/go/tempest/internal/server/database"
+)
+
+// ErrRevoked is returned when reading a cookie for a session which has been
+// revoked.
+var ErrRevoked = errors.New("session has been revoked")
+
+// A Backend holds the state of sessions which can't live in their cookies:
+// the keys used to seal the cookies, and which sessions have been revoked.
+//
+// Servers sharing a Backend accept each other's cookies, and see each
+// other's revocations immediately, so a deployment with more than one server
+// needs a shared backend, such as Redis.
+type Backend interface {
+	// Keys returns the keys used to seal session cookies, generating and
+	// saving them if there are none yet.
+	Keys() ([][32]byte, error)
+
+	// Revoke ends the session with the given ID, so that cookies for it
+	// are no longer accepted, even though they have not expired.
+	Revoke(sessionID []byte) error
+
+	// IsRevoked returns true if Revoke has been called for the session.
+	IsRevoked(sessionID []byte) (bool, error)
+}
+
+// NewLocalBackend returns a Backend which keeps the keys in a file in the
+// local state directory (see GetKeys), and revocations in db. It is only
+// suitable for deployments with a single server.
+func NewLocalBackend(db database.DB) Backend {
+	return localBackend{db: db}
+}
+
+type localBackend struct {
+	db database.DB
+}
+
+func (b localBackend) Keys() ([][32]byte, error) {
+	return GetKeys()
+}
+
+func (b localBackend) Revoke(sessionID []byte) error {
+	tx, err := b.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err = tx.RevokeSession(sessionID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (b localBackend) IsRevoked(sessionID []byte) (bool, error) {
+	tx, err := b.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	return tx.IsSessionRevoked(sessionID)
+}
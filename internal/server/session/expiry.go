@@ -0,0 +1,61 @@
+package session
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrExpired is returned when reading a cookie for a session which has
+// expired.
+var ErrExpired = errors.New("session has expired")
+
+// Lifetimes limits how long sessions last. Zero fields mean no limit.
+type Lifetimes struct {
+	// How long a user session lasts after logging in, however much it
+	// is used.
+	MaxAge time.Duration
+
+	// How long a user session lasts after its cookie was last renewed;
+	// see UserSession.NeedsRenewal.
+	IdleTimeout time.Duration
+
+	// How long a grain session lasts after it is first sealed,
+	// independently of the user session it belongs to, so a leaked
+	// token is only good for so long.
+	GrainMaxAge time.Duration
+}
+
+// WithLifetimes returns a copy of the store which limits how long sessions
+// last, rejecting cookies for expired ones.
+func (s Store) WithLifetimes(l Lifetimes) Store {
+	s.lifetimes = l
+	return s
+}
+
+// checkExpired returns ErrExpired if val has expired.
+func (s Store) checkExpired(val interface{ Expires(Store) time.Time }, now time.Time) error {
+	if exp := val.Expires(s); !exp.IsZero() && !now.Before(exp) {
+		return ErrExpired
+	}
+	return nil
+}
+
+// cookieMaxAge returns the Max-Age of a cookie holding a session which
+// expires at exp, or 0, for a cookie the browser keeps until it exits, if
+// exp is the zero time.
+func cookieMaxAge(exp time.Time, now time.Time) int {
+	if exp.IsZero() {
+		return 0
+	}
+	// A Max-Age of 0 or less deletes the cookie right away; the session
+	// is checked anyway.
+	return max(1, int(exp.Sub(now)/time.Second))
+}
+
+// earliest returns the earlier of a and b, ignoring zero times.
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,70 @@
+package session
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sandstorm.org/go/tempest/internal/common/types"
+)
+
+// readBack writes val to a cookie, and reads it back into out.
+func readBack[T CookieWriter, U CookieReader](t *testing.T, store Store, val T, out U) error {
+	req := httptest.NewRequest("GET", "/", nil)
+	cookie, err := NewCookie(store, req, val)
+	require.NoError(t, err)
+	req.AddCookie(cookie)
+	return ReadCookie(store, req, out)
+}
+
+func TestUserSessionExpiry(t *testing.T) {
+	store := randomStore().WithLifetimes(Lifetimes{
+		MaxAge:      30 * 24 * time.Hour,
+		IdleTimeout: 24 * time.Hour,
+	})
+	cred := types.Credential{Type: "dev", ScopedID: "Alice Dev Admin"}
+	now := time.Now()
+
+	var out UserSession
+	sess := NewUserSession(cred, now)
+	assert.NoError(t, readBack(t, store, sess, &out))
+	assert.Equal(t, sess, out)
+	assert.False(t, sess.NeedsRenewal(store, now))
+	assert.True(t, sess.NeedsRenewal(store, now.Add(3*time.Hour)))
+
+	idle := NewUserSession(cred, now.Add(-25*time.Hour))
+	assert.ErrorIs(t, readBack(t, store, idle, &out), ErrExpired)
+	assert.NoError(t, readBack(t, store, idle.Renew(now), &out), "renewing extends the session")
+
+	old := NewUserSession(cred, now.Add(-31*24*time.Hour)).Renew(now)
+	assert.ErrorIs(t, readBack(t, store, old, &out), ErrExpired, "renewing doesn't extend MaxAge")
+
+	legacy := UserSession{SessionID: GenSessionID(), Credential: cred}
+	assert.NoError(t, readBack(t, store, legacy, &out))
+	assert.True(t, legacy.NeedsRenewal(store, now))
+	renewed := legacy.Renew(now)
+	assert.Equal(t, now.Unix(), renewed.Started)
+
+	unlimited := randomStore()
+	assert.NoError(t, readBack(t, unlimited, idle, &out))
+	assert.False(t, idle.NeedsRenewal(unlimited, now))
+}
+
+func TestGrainSessionExpiry(t *testing.T) {
+	store := randomStore().WithLifetimes(Lifetimes{GrainMaxAge: time.Hour})
+	sess := GrainSession{
+		GrainID:   "RfLg3jgaJMXq4cqMpGonwL",
+		SessionID: []byte("1234"),
+	}
+	data, err := sess.Seal(store)
+	require.NoError(t, err)
+	var out GrainSession
+	require.NoError(t, out.Unseal(store, Payload{Data: data}))
+	assert.NotZero(t, out.ExpiresAt, "Seal sets the expiry")
+	assert.NoError(t, store.Check(&out))
+
+	sess.ExpiresAt = time.Now().Add(-time.Minute).Unix()
+	assert.ErrorIs(t, readBack(t, store, sess, &out), ErrExpired)
+}
@@ -1,13 +1,20 @@
 package session
 
 import (
+	"time"
+
 	"sandstorm.org/go/tempest/internal/capnp/cookie"
 	"sandstorm.org/go/tempest/internal/common/types"
 )
 
 type GrainSession struct {
-	GrainID   types.GrainID `capnp:"grainId"`
-	SessionID []byte        `capnp:"sessionId"`
+	GrainID   types.GrainID   `capnp:"grainId"`
+	SessionID []byte          `capnp:"sessionId"`
+	AccountID types.AccountID `capnp:"accountId"`
+
+	// Unix timestamp of when the session expires; zero if it doesn't.
+	// Seal sets it, if it is zero, according to the store's lifetimes.
+	ExpiresAt int64 `capnp:"expires"`
 }
 
 func (sess *GrainSession) Unseal(store Store, payload Payload) error {
@@ -15,6 +22,9 @@ func (sess *GrainSession) Unseal(store Store, payload Payload) error {
 }
 
 func (sess GrainSession) Seal(store Store) (string, error) {
+	if sess.ExpiresAt == 0 && store.lifetimes.GrainMaxAge > 0 {
+		sess.ExpiresAt = time.Now().Add(store.lifetimes.GrainMaxAge).Unix()
+	}
 	return seal(
 		sess,
 		cookie.GrainSession_TypeID,
@@ -26,3 +36,17 @@ func (sess GrainSession) Seal(store Store) (string, error) {
 func (sess GrainSession) CookieName() string {
 	return "sandstorm-grain-session"
 }
+
+// ID returns the ID of the user session the grain session belongs to, so
+// revoking that revokes this too.
+func (sess *GrainSession) ID() []byte {
+	return sess.SessionID
+}
+
+// Expires returns when the session expires, or the zero time if it doesn't.
+func (sess GrainSession) Expires(store Store) time.Time {
+	if sess.ExpiresAt == 0 {
+		return time.Time{}
+	}
+	return time.Unix(sess.ExpiresAt, 0)
+}
@@ -14,6 +14,11 @@ func TestGrainSealUnseal(t *testing.T) {
 			GrainID:   "RfLg3jgaJMXq4cqMpGonwL",
 			SessionID: []byte("1234"),
 		},
+		{
+			GrainID:   "RfLg3jgaJMXq4cqMpGonwL",
+			SessionID: []byte("1234"),
+			AccountID: "id_alice",
+		},
 	}
 	for i, c := range cases {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
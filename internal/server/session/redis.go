@@ -0,0 +1,300 @@
+package session
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	redisKeysKey          = "tempest:session-keys"
+	redisRevokedKeyPrefix = "tempest:revoked-session:"
+
+	// How long to wait for the Redis server before giving up on a
+	// command.
+	redisTimeout = 10 * time.Second
+
+	// The most connections to the Redis server a backend has open at once,
+	// and the most it keeps open while they are unused.
+	redisMaxConns = 16
+	redisMaxIdle  = 4
+)
+
+// NewRedisBackend returns a Backend which keeps the keys and revocations in
+// the Redis server at rawURL, which has the form
+// redis://[[username]:password@]host[:port][/db], or rediss:// for TLS.
+// Revocations are kept for maxAge, the maximum lifetime of a session, after
+// which cookies for the session are rejected anyway, or forever if maxAge is
+// zero.
+//
+// It connects lazily, so an unreachable server is only reported when the
+// backend is first used.
+func NewRedisBackend(rawURL string, maxAge time.Duration) (Backend, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "redis" && u.Scheme != "rediss" {
+		return nil, fmt.Errorf("redis URL %q must use the redis or rediss scheme", rawURL)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("redis URL %q has no host", rawURL)
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	b := &redisBackend{
+		maxAge: maxAge,
+		conns:  make(chan struct{}, redisMaxConns),
+		idle:   make(chan *redisConn, redisMaxIdle),
+	}
+	if u.User != nil {
+		b.username = u.User.Username()
+		b.password, _ = u.User.Password()
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		b.db, err = strconv.Atoi(db)
+		if err != nil || b.db < 0 {
+			return nil, fmt.Errorf("redis URL %q: database must be a number", rawURL)
+		}
+	}
+	dialer := &net.Dialer{Timeout: redisTimeout}
+	if u.Scheme == "rediss" {
+		tlsConfig := &tls.Config{ServerName: u.Hostname()}
+		b.dial = func() (net.Conn, error) {
+			return tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+		}
+	} else {
+		b.dial = func() (net.Conn, error) {
+			return dialer.Dial("tcp", addr)
+		}
+	}
+	return b, nil
+}
+
+type redisBackend struct {
+	dial     func() (net.Conn, error)
+	username string
+	password string
+	db       int
+	maxAge   time.Duration
+
+	// A pool of connections: conns holds a token for each one open or
+	// being opened, and idle those not in use.
+	conns chan struct{}
+	idle  chan *redisConn
+}
+
+// A redisConn is a connection to the Redis server, set up by connect.
+type redisConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// A redisError is an error reply from the server. Unlike other errors, it
+// leaves the connection usable.
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+func (b *redisBackend) Keys() ([][32]byte, error) {
+	data := make([]byte, 64)
+	rand.Read(data)
+	// Only the first server to get here saves its keys; everyone uses
+	// whatever was saved.
+	if _, err := b.do("SET", redisKeysKey, string(data), "NX"); err != nil {
+		return nil, err
+	}
+	reply, err := b.do("GET", redisKeysKey)
+	if err != nil {
+		return nil, err
+	}
+	saved, ok := reply.([]byte)
+	if !ok || len(saved) != 64 {
+		return nil, fmt.Errorf("redis: %s is not 64 bytes", redisKeysKey)
+	}
+	ret := make([][32]byte, 2)
+	copy(ret[0][:], saved[:32])
+	copy(ret[1][:], saved[32:])
+	return ret, nil
+}
+
+func (b *redisBackend) Revoke(sessionID []byte) error {
+	args := []string{"SET", redisRevokedKey(sessionID), strconv.FormatInt(time.Now().Unix(), 10)}
+	if b.maxAge > 0 {
+		args = append(args, "EX", strconv.FormatInt(int64((b.maxAge+time.Second-1)/time.Second), 10))
+	}
+	_, err := b.do(args...)
+	return err
+}
+
+func (b *redisBackend) IsRevoked(sessionID []byte) (bool, error) {
+	reply, err := b.do("EXISTS", redisRevokedKey(sessionID))
+	if err != nil {
+		return false, err
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return false, fmt.Errorf("redis: unexpected reply to EXISTS: %v", reply)
+	}
+	return n > 0, nil
+}
+
+func redisRevokedKey(sessionID []byte) string {
+	return redisRevokedKeyPrefix + hex.EncodeToString(sessionID)
+}
+
+// do sends a command to the server on a connection from the pool, connecting
+// first if none is idle, and returns the reply.
+func (b *redisBackend) do(args ...string) (any, error) {
+	c, err := b.get()
+	if err != nil {
+		return nil, err
+	}
+	reply, err := c.roundTrip(args)
+	var rerr redisError
+	if err != nil && !errors.As(err, &rerr) {
+		// We can't tell where we are in the stream; don't reuse it.
+		c.conn.Close()
+		<-b.conns
+		return reply, err
+	}
+	b.put(c)
+	return reply, err
+}
+
+// get takes an idle connection from the pool, or opens a new one if there
+// are none and there is room for more, waiting for one to be returned
+// otherwise.
+func (b *redisBackend) get() (*redisConn, error) {
+	select {
+	case c := <-b.idle:
+		return c, nil
+	default:
+	}
+	select {
+	case c := <-b.idle:
+		return c, nil
+	case b.conns <- struct{}{}:
+		c, err := b.connect()
+		if err != nil {
+			<-b.conns
+		}
+		return c, err
+	}
+}
+
+// put returns c to the pool, closing it if enough are idle already.
+func (b *redisBackend) put(c *redisConn) {
+	select {
+	case b.idle <- c:
+	default:
+		c.conn.Close()
+		<-b.conns
+	}
+}
+
+func (b *redisBackend) connect() (*redisConn, error) {
+	conn, err := b.dial()
+	if err != nil {
+		return nil, err
+	}
+	c := &redisConn{conn: conn, r: bufio.NewReader(conn)}
+	var setup [][]string
+	if b.password != "" {
+		if b.username != "" {
+			setup = append(setup, []string{"AUTH", b.username, b.password})
+		} else {
+			setup = append(setup, []string{"AUTH", b.password})
+		}
+	}
+	if b.db != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(b.db)})
+	}
+	for _, args := range setup {
+		if _, err := c.roundTrip(args); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *redisConn) roundTrip(args []string) (any, error) {
+	c.conn.SetDeadline(time.Now().Add(redisTimeout))
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&sb, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.conn, sb.String()); err != nil {
+		return nil, err
+	}
+	return readRedisReply(c.r)
+}
+
+// readRedisReply reads a reply in the Redis protocol (RESP2). Simple strings
+// are returned as strings, bulk strings as []byte, integers as int64 and
+// arrays as []any; null bulk strings and arrays are returned as nil.
+func readRedisReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(line, "\r\n") || len(line) < 3 {
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+	kind, rest := line[0], line[1:len(line)-2]
+	switch kind {
+	case '+':
+		return rest, nil
+	case '-':
+		return nil, redisError(rest)
+	case ':':
+		return strconv.ParseInt(rest, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(rest)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(rest)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		ret := make([]any, n)
+		for i := range ret {
+			ret[i], err = readRedisReply(r)
+			var rerr redisError
+			if err != nil && !errors.As(err, &rerr) {
+				return nil, err
+			}
+		}
+		return ret, nil
+	default:
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+}
@@ -0,0 +1,39 @@
+package session
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+)
+
+// ErrBadSignature is returned by Verify when a signature doesn't match.
+var ErrBadSignature = errors.New("bad signature")
+
+// deriveSigningKey derives the key for Sign from the session encryption key,
+// so that signatures don't need a key of their own, but also can't be
+// confused with anything encrypted.
+func deriveSigningKey(key [32]byte) [32]byte {
+	mac := hmac.New(sha256.New, key[:])
+	mac.Write([]byte("tempest session store signing key"))
+	var ret [32]byte
+	copy(ret[:], mac.Sum(nil))
+	return ret
+}
+
+// Sign returns a signature for data, which Verify will accept as long as the
+// store's keys don't change. Unlike sealed cookies, the data is not secret;
+// this is for things like URLs, which must stay readable.
+func (s Store) Sign(data []byte) []byte {
+	mac := hmac.New(sha256.New, s.signingKey[:])
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// Verify checks a signature returned by Sign, returning ErrBadSignature if it
+// doesn't match data.
+func (s Store) Verify(data, signature []byte) error {
+	if !hmac.Equal(s.Sign(data), signature) {
+		return ErrBadSignature
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignVerify(t *testing.T) {
+	store := NewStore(NewKeys())
+	data := []byte("embed1\x001700000000")
+	sig := store.Sign(data)
+	assert.NoError(t, store.Verify(data, sig))
+
+	assert.ErrorIs(t, store.Verify([]byte("embed1\x001800000000"), sig), ErrBadSignature,
+		"Signatures should not verify other data")
+	assert.ErrorIs(t, store.Verify(data, sig[:len(sig)-1]), ErrBadSignature,
+		"Truncated signatures should not verify")
+
+	other := NewStore(NewKeys())
+	assert.ErrorIs(t, other.Verify(data, sig), ErrBadSignature,
+		"Signatures should not verify with other keys")
+}
@@ -3,8 +3,10 @@ package session
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"net/http"
 	"os"
+	"time"
 
 	"capnproto.org/go/capnp/v3"
 	"capnproto.org/go/capnp/v3/pogs"
@@ -13,7 +15,10 @@ import (
 )
 
 type Store struct {
-	aead capnpAEAD
+	aead       capnpAEAD
+	signingKey [32]byte // See Sign.
+	backend    Backend  // May be nil, in which case nothing is revoked.
+	lifetimes  Lifetimes
 }
 
 func GetKeys() (keys [][32]byte, err error) {
@@ -35,9 +40,57 @@ func GetKeys() (keys [][32]byte, err error) {
 	return ret, nil
 }
 
+// NewKeys generates fresh random keys, for use by servers whose sessions
+// need not outlive the process.
+func NewKeys() [][32]byte {
+	ret := make([][32]byte, 2)
+	rand.Read(ret[0][:])
+	rand.Read(ret[1][:])
+	return ret
+}
+
 func NewStore(keys [][32]byte) Store {
 	// TODO: use other keys for decryption, to allow rotation.
-	return Store{aead: newCapnpAEAD(keys[0])}
+	return Store{
+		aead:       newCapnpAEAD(keys[0]),
+		signingKey: deriveSigningKey(keys[0]),
+	}
+}
+
+// NewBackendStore returns a Store using the keys from backend, which also
+// records revoked sessions.
+func NewBackendStore(backend Backend) (Store, error) {
+	keys, err := backend.Keys()
+	if err != nil {
+		return Store{}, err
+	}
+	store := NewStore(keys)
+	store.backend = backend
+	return store, nil
+}
+
+// Revoke ends the session with the given ID; see Backend.Revoke.
+func (s Store) Revoke(sessionID []byte) error {
+	if s.backend == nil {
+		return errors.New("session store has no backend; cannot revoke sessions")
+	}
+	return s.backend.Revoke(sessionID)
+}
+
+// CheckRevoked returns ErrRevoked if the session with the given ID has been
+// revoked.
+func (s Store) CheckRevoked(sessionID []byte) error {
+	if s.backend == nil || len(sessionID) == 0 {
+		return nil
+	}
+	revoked, err := s.backend.IsRevoked(sessionID)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return ErrRevoked
+	}
+	return nil
 }
 
 type Payload struct {
@@ -87,11 +140,23 @@ func seal[T ~capnp.StructKind, U any](
 type CookieReader interface {
 	Unseal(Store, Payload) error
 	CookieName() string
+	ID() []byte
+	Expires(Store) time.Time
 }
 
 type CookieWriter interface {
 	Seal(Store) (string, error)
 	CookieName() string
+	Expires(Store) time.Time
+}
+
+// Check returns an error if the session val, which has been unsealed, has
+// expired or been revoked.
+func (s Store) Check(val CookieReader) error {
+	if err := s.checkExpired(val, time.Now()); err != nil {
+		return err
+	}
+	return s.CheckRevoked(val.ID())
 }
 
 func ReadCookie[T CookieReader](store Store, req *http.Request, val T) error {
@@ -99,20 +164,36 @@ func ReadCookie[T CookieReader](store Store, req *http.Request, val T) error {
 	if err != nil {
 		return err
 	}
-	return val.Unseal(store, Payload{
+	err = val.Unseal(store, Payload{
 		CookieName: c.Name,
 		Data:       c.Value,
 	})
+	if err != nil {
+		return err
+	}
+	return store.Check(val)
 }
 
-func WriteCookie[T CookieWriter](store Store, req *http.Request, w http.ResponseWriter, val T) error {
+// NewCookie returns a cookie holding val, sealed, which the browser keeps
+// until the session expires.
+func NewCookie[T CookieWriter](store Store, req *http.Request, val T) (*http.Cookie, error) {
 	data, err := val.Seal(store)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	http.SetCookie(w, Payload{
+	cookie := Payload{
 		CookieName: val.CookieName(),
 		Data:       data,
-	}.ToCookie(req.URL.Scheme == "https"))
+	}.ToCookie(req.URL.Scheme == "https")
+	cookie.MaxAge = cookieMaxAge(val.Expires(store), time.Now())
+	return cookie, nil
+}
+
+func WriteCookie[T CookieWriter](store Store, req *http.Request, w http.ResponseWriter, val T) error {
+	cookie, err := NewCookie(store, req, val)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, cookie)
 	return nil
 }
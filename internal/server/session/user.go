@@ -1,6 +1,8 @@
 package session
 
 import (
+	"time"
+
 	"sandstorm.org/go/tempest/internal/capnp/cookie"
 	"sandstorm.org/go/tempest/internal/common/types"
 	"sandstorm.org/go/tempest/internal/server/tokenutil"
@@ -9,6 +11,22 @@ import (
 type UserSession struct {
 	SessionID  []byte `capnp:"sessionId"`
 	Credential types.Credential
+
+	// Unix timestamps of when the user logged in, and when the cookie was
+	// last issued; see cookie.capnp.
+	Started int64 `capnp:"started"`
+	Renewed int64 `capnp:"renewed"`
+}
+
+// NewUserSession returns a new session for a user who logged in with the
+// given credential at the given time.
+func NewUserSession(cred types.Credential, now time.Time) UserSession {
+	return UserSession{
+		SessionID:  GenSessionID(),
+		Credential: cred,
+		Started:    now.Unix(),
+		Renewed:    now.Unix(),
+	}
 }
 
 func GenSessionID() []byte {
@@ -31,3 +49,50 @@ func (sess UserSession) Seal(store Store) (string, error) {
 func (sess UserSession) CookieName() string {
 	return "sandstorm-user-session"
 }
+
+// ID returns the session's ID, or nil for the zero value.
+func (sess *UserSession) ID() []byte {
+	return sess.SessionID
+}
+
+// Expires returns when the session expires, under the store's lifetimes, or
+// the zero time if it doesn't.
+func (sess UserSession) Expires(store Store) time.Time {
+	l := store.lifetimes
+	var exp time.Time
+	if l.MaxAge > 0 && sess.Started != 0 {
+		exp = time.Unix(sess.Started, 0).Add(l.MaxAge)
+	}
+	if l.IdleTimeout > 0 && sess.Renewed != 0 {
+		exp = earliest(exp, time.Unix(sess.Renewed, 0).Add(l.IdleTimeout))
+	}
+	return exp
+}
+
+// NeedsRenewal returns true if the session's cookie should be issued again,
+// with Renew, to keep it from expiring while it is in use. Cookies are only
+// renewed every so often, so most requests don't set one.
+func (sess UserSession) NeedsRenewal(store Store, now time.Time) bool {
+	l := store.lifetimes
+	if l.MaxAge == 0 && l.IdleTimeout == 0 {
+		return false
+	}
+	if sess.Started == 0 || sess.Renewed == 0 {
+		// From before expiry was recorded.
+		return true
+	}
+	if l.IdleTimeout == 0 {
+		return false
+	}
+	interval := min(l.IdleTimeout/10, time.Hour)
+	return now.Sub(time.Unix(sess.Renewed, 0)) >= interval
+}
+
+// Renew returns the session, renewed at the given time.
+func (sess UserSession) Renew(now time.Time) UserSession {
+	if sess.Started == 0 {
+		sess.Started = now.Unix()
+	}
+	sess.Renewed = now.Unix()
+	return sess
+}
@@ -14,16 +14,68 @@ import (
 
 var settingsInfo map[string]settings.Setting
 
+// Names of the settings, in the order of settings.capnp.
+var settingNames []string
+
+// Deprecated maps the former names of renamed settings to their current
+// names. If a setting isn't set in the environment, Environ falls back to
+// its former name.
+var Deprecated = map[string]string{
+	"ACME_DNS_PROIVDER": "ACME_DNS_PROVIDER",
+}
+
 func init() {
 	settings.AdminSettings.Message().ResetReadLimit(math.MaxUint64)
 	size := settings.AdminSettings.Len()
 	settingsInfo = make(map[string]settings.Setting, size)
+	settingNames = make([]string, 0, size)
 	for i := 0; i < size; i++ {
 		setting := settings.AdminSettings.At(i)
 		name, err := setting.Name()
 		util.Chkfatal(err)
 		settingsInfo[name] = setting
+		settingNames = append(settingNames, name)
+	}
+}
+
+// Info describes a setting.
+type Info struct {
+	Name string
+	Type schema.Type_Which
+
+	// The default value, formatted as it would be in the environment;
+	// only meaningful if HasDefault is true.
+	Default    string
+	HasDefault bool
+}
+
+// All returns information about every setting, in the order of
+// settings.capnp.
+func All() []Info {
+	ret := make([]Info, 0, len(settingNames))
+	for _, name := range settingNames {
+		setting := settingsInfo[name]
+		typ, err := setting.Type()
+		util.Chkfatal(err)
+		info := Info{
+			Name:       name,
+			Type:       typ.Which(),
+			HasDefault: setting.HasDefault(),
+		}
+		if info.HasDefault {
+			def, err := setting.Default()
+			util.Chkfatal(err)
+			switch info.Type {
+			case schema.Type_Which_text:
+				info.Default, err = def.Text()
+				util.Chkfatal(err)
+			case schema.Type_Which_uint16:
+				info.Default = strconv.FormatUint(uint64(def.Uint16()), 10)
+			}
+		}
+		ret = append(ret, info)
 	}
+	return ret
 }
 
 // A Source can be queried for settings.
@@ -38,11 +90,30 @@ type Source interface {
 // Environ is a Source that pulls settings from environment variables
 var Environ Source = envSource{}
 
-type envSource struct{}
+// WithStored returns a Source that pulls settings from environment variables,
+// falling back to stored, which maps the names of settings to values
+// formatted as they would be in the environment, and then to the defaults.
+func WithStored(stored map[string]string) Source {
+	return envSource{stored: stored}
+}
+
+// InEnviron reports whether the named setting is set in the environment,
+// under its current or deprecated name, so that stored values are ignored.
+func InEnviron(name string) bool {
+	setting, ok := settingsInfo[name]
+	if !ok {
+		panic("No such setting: " + name)
+	}
+	return getFromEnv(setting) != ""
+}
+
+type envSource struct {
+	stored map[string]string
+}
 
-func (envSource) GetString(name string) string {
+func (src envSource) GetString(name string) string {
 	s := getSettingInfo(name, schema.Type_Which_text)
-	val := getFromEnv(s)
+	val := src.get(s)
 	if val == "" && s.HasDefault() {
 		def, err := s.Default()
 		util.Chkfatal(err)
@@ -52,9 +123,9 @@ func (envSource) GetString(name string) string {
 	return val
 }
 
-func (envSource) GetUint16(name string) uint16 {
+func (src envSource) GetUint16(name string) uint16 {
 	s := getSettingInfo(name, schema.Type_Which_uint16)
-	str := getFromEnv(s)
+	str := src.get(s)
 	if str == "" && s.HasDefault() {
 		var err error
 		val, err := s.Default()
@@ -66,11 +137,30 @@ func (envSource) GetUint16(name string) uint16 {
 	return uint16(u64)
 }
 
-// Read the environment variable specified in s.name
+// Read the setting from the environment, or else from the stored settings
+func (src envSource) get(s settings.Setting) string {
+	val := getFromEnv(s)
+	if val == "" {
+		name, err := s.Name()
+		util.Chkfatal(err)
+		val = src.stored[name]
+	}
+	return val
+}
+
+// Read the environment variable specified in s.name, or its deprecated name
 func getFromEnv(s settings.Setting) string {
 	varName, err := s.Name()
 	util.Chkfatal(err)
-	return os.Getenv(varName)
+	val := os.Getenv(varName)
+	if val == "" {
+		for oldName, newName := range Deprecated {
+			if newName == varName {
+				val = os.Getenv(oldName)
+			}
+		}
+	}
+	return val
 }
 
 // Get the setting struct for the named setting, panicking if it does not have the
@@ -0,0 +1,204 @@
+package smtpd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxPartDepth limits how deeply multipart bodies may nest.
+const maxPartDepth = 10
+
+// A Message is a parsed email. Its fields mirror the EmailMessage struct in
+// email.capnp, so it can be converted with pogs.
+type Message struct {
+	Date        int64 // Nanoseconds since the unix epoch; zero if unknown.
+	From        Address
+	To          []Address
+	Cc          []Address
+	Bcc         []Address
+	ReplyTo     Address
+	MessageID   string   `capnp:"messageId"`
+	References  []string // Message IDs, without angle brackets.
+	InReplyTo   []string
+	Subject     string
+	Text        string
+	HTML        string `capnp:"html"`
+	Attachments []Attachment
+}
+
+// An Address is an email address, and the name which goes with it, if any.
+type Address struct {
+	Address string
+	Name    string
+}
+
+// An Attachment is a part of a message other than its text and HTML bodies.
+type Attachment struct {
+	ContentType        string
+	ContentDisposition string
+	ContentID          string `capnp:"contentId"`
+	Content            []byte
+}
+
+// Parse parses a message as received by the server. Headers which cannot be
+// parsed are left out, rather than failing, since mail in the wild is often
+// malformed; the only errors are for messages with no recognizable headers.
+func Parse(data []byte) (Message, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return Message{}, err
+	}
+	h := msg.Header
+	m := Message{
+		From:       firstAddress(h, "From"),
+		To:         addresses(h, "To"),
+		Cc:         addresses(h, "Cc"),
+		Bcc:        addresses(h, "Bcc"),
+		ReplyTo:    firstAddress(h, "Reply-To"),
+		MessageID:  trimAngles(strings.TrimSpace(h.Get("Message-Id"))),
+		References: messageIDs(h.Get("References")),
+		InReplyTo:  messageIDs(h.Get("In-Reply-To")),
+		Subject:    decodeHeader(h.Get("Subject")),
+	}
+	if date, err := h.Date(); err == nil {
+		m.Date = date.UnixNano()
+	}
+	err = m.addPart(textproto.MIMEHeader(h), msg.Body, 0)
+	return m, err
+}
+
+// addPart adds the body of a (sub)part of the message to m: as its text or
+// HTML, as attachments, or, for multipart bodies, each of its parts in turn.
+func (m *Message) addPart(h textproto.MIMEHeader, body io.Reader, depth int) error {
+	contentType := h.Get("Content-Type")
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, params = "text/plain", nil
+	}
+	if strings.HasPrefix(mediaType, "multipart/") && params["boundary"] != "" {
+		if depth >= maxPartDepth {
+			return errors.New("message parts nested too deeply")
+		}
+		r := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := r.NextRawPart()
+			if err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			if err := m.addPart(part.Header, part, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	content, err := io.ReadAll(decodeTransfer(h.Get("Content-Transfer-Encoding"), body))
+	if err != nil {
+		return err
+	}
+	disposition, _, _ := mime.ParseMediaType(h.Get("Content-Disposition"))
+	if disposition != "attachment" {
+		switch {
+		case mediaType == "text/plain" && m.Text == "":
+			m.Text = decodeCharset(params["charset"], content)
+			return nil
+		case mediaType == "text/html" && m.HTML == "":
+			m.HTML = decodeCharset(params["charset"], content)
+			return nil
+		}
+	}
+	if contentType == "" {
+		contentType = "text/plain"
+	}
+	m.Attachments = append(m.Attachments, Attachment{
+		ContentType:        contentType,
+		ContentDisposition: h.Get("Content-Disposition"),
+		ContentID:          trimAngles(strings.TrimSpace(h.Get("Content-Id"))),
+		Content:            content,
+	})
+	return nil
+}
+
+// decodeTransfer undoes the Content-Transfer-Encoding of a part's body.
+func decodeTransfer(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		// The decoder skips the line breaks.
+		return base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	default:
+		return body
+	}
+}
+
+// decodeCharset converts text in the given charset to UTF-8. Only UTF-8 and
+// its subsets, and ISO-8859-1, are understood; other text is passed through,
+// with any invalid UTF-8 replaced.
+func decodeCharset(charset string, text []byte) string {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1":
+		var buf strings.Builder
+		for _, b := range text {
+			buf.WriteRune(rune(b))
+		}
+		return buf.String()
+	default:
+		if utf8.Valid(text) {
+			return string(text)
+		}
+		return strings.ToValidUTF8(string(text), "�")
+	}
+}
+
+// decodeHeader decodes RFC 2047 encoded-words in a header's value.
+func decodeHeader(value string) string {
+	decoded, err := new(mime.WordDecoder).DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
+
+func addresses(h mail.Header, key string) []Address {
+	list, err := h.AddressList(key)
+	if err != nil {
+		return nil
+	}
+	ret := make([]Address, len(list))
+	for i, addr := range list {
+		ret[i] = Address{Address: addr.Address, Name: addr.Name}
+	}
+	return ret
+}
+
+func firstAddress(h mail.Header, key string) Address {
+	list := addresses(h, key)
+	if len(list) == 0 {
+		return Address{}
+	}
+	return list[0]
+}
+
+// messageIDs parses the value of a References or In-Reply-To header.
+func messageIDs(value string) []string {
+	var ids []string
+	for _, id := range strings.Fields(value) {
+		ids = append(ids, trimAngles(id))
+	}
+	return ids
+}
+
+func trimAngles(id string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(id, "<"), ">")
+}
@@ -0,0 +1,88 @@
+package smtpd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSimple(t *testing.T) {
+	data := strings.ReplaceAll(`From: Bob <bob@example.net>
+To: Alice <alice@example.com>, carol@example.com
+Reply-To: bob+replies@example.net
+Date: Mon, 02 Jan 2006 15:04:05 -0700
+Message-ID: <1234@example.net>
+In-Reply-To: <1233@example.com>
+References: <1232@example.net> <1233@example.com>
+Subject: =?UTF-8?q?Caf=C3=A9?=
+Content-Type: text/plain; charset=iso-8859-1
+Content-Transfer-Encoding: quoted-printable
+
+Caf=E9 at noon?
+`, "\n", "\r\n")
+	m, err := Parse([]byte(data))
+	require.NoError(t, err)
+
+	date, _ := time.Parse(time.RFC1123Z, "Mon, 02 Jan 2006 15:04:05 -0700")
+	assert.Equal(t, Message{
+		Date: date.UnixNano(),
+		From: Address{Address: "bob@example.net", Name: "Bob"},
+		To: []Address{
+			{Address: "alice@example.com", Name: "Alice"},
+			{Address: "carol@example.com"},
+		},
+		ReplyTo:    Address{Address: "bob+replies@example.net"},
+		MessageID:  "1234@example.net",
+		InReplyTo:  []string{"1233@example.com"},
+		References: []string{"1232@example.net", "1233@example.com"},
+		Subject:    "Café",
+		Text:       "Café at noon?\r\n",
+	}, m)
+}
+
+func TestParseMultipart(t *testing.T) {
+	data := strings.ReplaceAll(`From: bob@example.net
+Subject: Photos
+Content-Type: multipart/mixed; boundary=outer
+
+--outer
+Content-Type: multipart/alternative; boundary=inner
+
+--inner
+Content-Type: text/plain
+
+Look!
+--inner
+Content-Type: text/html
+
+<p>Look!</p>
+--inner--
+--outer
+Content-Type: image/png; name=cat.png
+Content-Disposition: attachment; filename=cat.png
+Content-ID: <cat@example.net>
+Content-Transfer-Encoding: base64
+
+bWVv
+d3c=
+--outer--
+`, "\n", "\r\n")
+	m, err := Parse([]byte(data))
+	require.NoError(t, err)
+	assert.Equal(t, "Look!", m.Text)
+	assert.Equal(t, "<p>Look!</p>", m.HTML)
+	assert.Equal(t, []Attachment{{
+		ContentType:        "image/png; name=cat.png",
+		ContentDisposition: "attachment; filename=cat.png",
+		ContentID:          "cat@example.net",
+		Content:            []byte("meoww"),
+	}}, m.Attachments)
+}
+
+func TestParseInvalid(t *testing.T) {
+	_, err := Parse([]byte("not an email"))
+	assert.Error(t, err)
+}
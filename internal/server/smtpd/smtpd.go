@@ -0,0 +1,414 @@
+// Package smtpd implements a minimal SMTP server (RFC 5321), for receiving
+// mail addressed to grains from a mail transfer agent.
+//
+// Only what's needed to accept mail is supported: there is no authentication,
+// relaying or TLS, and the only extensions are SIZE, 8BITMIME and PIPELINING.
+// Deciding which recipients exist, and what to do with the messages, is up to
+// the Server's Accept and Deliver functions.
+package smtpd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// Defaults for the Server's limits.
+const (
+	DefaultMaxSize       = 25 << 20
+	DefaultMaxRecipients = 100
+	DefaultTimeout       = 5 * time.Minute
+)
+
+// Maximum length of a command line, including the CRLF; see RFC 5321
+// section 4.5.3.1.4.
+const maxCommandLength = 512
+
+// An Envelope is a message received by the server.
+type Envelope struct {
+	// The reverse path given with MAIL FROM, which is empty for bounces.
+	From string
+
+	// The recipients which were accepted, from RCPT TO.
+	To []string
+
+	// The message itself, with its headers, as sent; dot-stuffing has
+	// been undone.
+	Data []byte
+}
+
+// An Error is an SMTP error reply. Accept and Deliver may return one to choose
+// the reply; for other errors, the client is told to try again later.
+type Error struct {
+	Code    int    // The reply code, e.g. 550.
+	Message string // The text of the reply, e.g. "5.1.1 No such mailbox".
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("smtp: %d %s", e.Code, e.Message)
+}
+
+// ErrNoSuchMailbox is a rejection of a recipient who doesn't exist.
+var ErrNoSuchMailbox = &Error{550, "5.1.1 No such mailbox"}
+
+// errTemporary is the reply for errors other than *Error.
+var errTemporary = &Error{451, "4.3.0 Temporary failure; try again later"}
+
+// errLineTooLong is returned when a command exceeds maxCommandLength.
+var errLineTooLong = errors.New("smtp: line too long")
+
+// A Server accepts mail over SMTP.
+type Server struct {
+	// Hostname announces the server in its greeting, and in replies
+	// to HELO and EHLO.
+	Hostname string
+
+	// The maximum size of a message, in bytes. Larger messages are
+	// rejected. If zero, DefaultMaxSize is used.
+	MaxSize int64
+
+	// The maximum number of recipients of a message. If zero,
+	// DefaultMaxRecipients is used.
+	MaxRecipients int
+
+	// How long to wait for the client to send each command, or the
+	// message. If zero, DefaultTimeout is used.
+	Timeout time.Duration
+
+	// Accept checks whether mail for the address given by RCPT TO is
+	// accepted, returning an error, e.g. ErrNoSuchMailbox, if not.
+	Accept func(addr string) error
+
+	// Deliver is called with each message which is accepted. It must
+	// not retain env.Data after it returns. If it returns an error, the
+	// client is told that the message was not delivered.
+	Deliver func(env Envelope) error
+
+	// Log records connections which fail unexpectedly; if nil,
+	// nothing is logged.
+	Log *slog.Logger
+}
+
+// Serve accepts connections on l, and serves each in its own goroutine. It
+// returns when l.Accept fails, e.g. because l was closed.
+func (s *Server) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go s.ServeConn(conn)
+	}
+}
+
+// ServeConn holds an SMTP conversation with the client on conn, and then
+// closes it.
+func (s *Server) ServeConn(conn net.Conn) {
+	defer conn.Close()
+	c := &session{
+		srv:  s,
+		conn: conn,
+		r:    bufio.NewReader(conn),
+		w:    bufio.NewWriter(conn),
+	}
+	if err := c.serve(); err != nil && s.Log != nil {
+		s.Log.Debug("SMTP connection failed",
+			"error", err,
+			"remoteAddr", conn.RemoteAddr().String(),
+		)
+	}
+}
+
+func (s *Server) maxSize() int64 {
+	if s.MaxSize > 0 {
+		return s.MaxSize
+	}
+	return DefaultMaxSize
+}
+
+func (s *Server) maxRecipients() int {
+	if s.MaxRecipients > 0 {
+		return s.MaxRecipients
+	}
+	return DefaultMaxRecipients
+}
+
+func (s *Server) timeout() time.Duration {
+	if s.Timeout > 0 {
+		return s.Timeout
+	}
+	return DefaultTimeout
+}
+
+// A session is the state of one SMTP connection.
+type session struct {
+	srv  *Server
+	conn net.Conn
+	r    *bufio.Reader
+	w    *bufio.Writer
+
+	greeted bool
+	inMail  bool // Whether MAIL FROM has started a transaction.
+	env     Envelope
+}
+
+func (c *session) serve() error {
+	c.reply(220, c.srv.Hostname+" ESMTP ready")
+	for {
+		if err := c.w.Flush(); err != nil {
+			return err
+		}
+		c.conn.SetDeadline(time.Now().Add(c.srv.timeout()))
+		line, err := readCommand(c.r)
+		if errors.Is(err, errLineTooLong) {
+			c.reply(500, "5.5.2 Line too long")
+			continue
+		} else if err != nil {
+			return err
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		verb = strings.ToUpper(verb)
+		arg = strings.TrimSpace(arg)
+		switch verb {
+		case "HELO", "EHLO":
+			c.hello(verb, arg)
+		case "MAIL":
+			c.mail(arg)
+		case "RCPT":
+			c.rcpt(arg)
+		case "DATA":
+			if err := c.data(); err != nil {
+				return err
+			}
+		case "RSET":
+			c.reset()
+			c.reply(250, "2.0.0 OK")
+		case "NOOP":
+			c.reply(250, "2.0.0 OK")
+		case "VRFY":
+			c.reply(252, "2.5.0 Cannot verify; send some mail and find out")
+		case "QUIT":
+			c.reply(221, "2.0.0 Bye")
+			return c.w.Flush()
+		default:
+			c.reply(502, "5.5.1 Command not implemented")
+		}
+	}
+}
+
+func (c *session) reply(code int, msg string) {
+	fmt.Fprintf(c.w, "%d %s\r\n", code, msg)
+}
+
+func (c *session) replyErr(err error) {
+	var smtpErr *Error
+	if !errors.As(err, &smtpErr) {
+		if c.srv.Log != nil {
+			c.srv.Log.Error("Handling SMTP transaction", "error", err)
+		}
+		smtpErr = errTemporary
+	}
+	c.reply(smtpErr.Code, smtpErr.Message)
+}
+
+// reset abandons the current mail transaction, if any.
+func (c *session) reset() {
+	c.inMail = false
+	c.env = Envelope{}
+}
+
+func (c *session) hello(verb, domain string) {
+	if domain == "" {
+		c.reply(501, "5.5.4 Domain name required")
+		return
+	}
+	c.reset()
+	c.greeted = true
+	if verb == "HELO" {
+		c.reply(250, c.srv.Hostname)
+		return
+	}
+	fmt.Fprintf(c.w, "250-%s\r\n", c.srv.Hostname)
+	fmt.Fprintf(c.w, "250-SIZE %d\r\n", c.srv.maxSize())
+	fmt.Fprintf(c.w, "250-8BITMIME\r\n")
+	c.reply(250, "PIPELINING")
+}
+
+func (c *session) mail(arg string) {
+	switch {
+	case !c.greeted:
+		c.reply(503, "5.5.1 Say hello first")
+		return
+	case c.inMail:
+		c.reply(503, "5.5.1 Nested MAIL command")
+		return
+	}
+	from, params, ok := parsePath(arg, "FROM:")
+	if !ok {
+		c.reply(501, "5.5.4 Syntax: MAIL FROM:<address>")
+		return
+	}
+	for _, param := range params {
+		key, value, _ := strings.Cut(param, "=")
+		if !strings.EqualFold(key, "SIZE") {
+			continue
+		}
+		size, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			c.reply(501, "5.5.4 Invalid SIZE")
+			return
+		}
+		if size > c.srv.maxSize() {
+			c.reply(552, "5.3.4 Message too big")
+			return
+		}
+	}
+	c.inMail = true
+	c.env.From = from
+	c.reply(250, "2.1.0 OK")
+}
+
+func (c *session) rcpt(arg string) {
+	if !c.inMail {
+		c.reply(503, "5.5.1 Need MAIL first")
+		return
+	}
+	to, _, ok := parsePath(arg, "TO:")
+	if !ok || to == "" {
+		c.reply(501, "5.5.4 Syntax: RCPT TO:<address>")
+		return
+	}
+	if len(c.env.To) >= c.srv.maxRecipients() {
+		c.reply(452, "4.5.3 Too many recipients")
+		return
+	}
+	if err := c.srv.Accept(to); err != nil {
+		c.replyErr(err)
+		return
+	}
+	c.env.To = append(c.env.To, to)
+	c.reply(250, "2.1.5 OK")
+}
+
+func (c *session) data() error {
+	if !c.inMail {
+		c.reply(503, "5.5.1 Need MAIL first")
+		return nil
+	}
+	if len(c.env.To) == 0 {
+		c.reply(554, "5.5.1 No valid recipients")
+		return nil
+	}
+	c.reply(354, "Send the message, ending with <CRLF>.<CRLF>")
+	if err := c.w.Flush(); err != nil {
+		return err
+	}
+	c.conn.SetDeadline(time.Now().Add(c.srv.timeout()))
+	data, err := readData(c.r, c.srv.maxSize())
+	env := c.env
+	c.reset()
+	switch {
+	case errors.Is(err, errTooBig):
+		c.reply(552, "5.3.4 Message too big")
+	case err != nil:
+		return err
+	default:
+		env.Data = data
+		if err := c.srv.Deliver(env); err != nil {
+			c.replyErr(err)
+		} else {
+			c.reply(250, "2.0.0 OK")
+		}
+	}
+	return nil
+}
+
+// parsePath parses the argument of MAIL or RCPT: the prefix, e.g. "FROM:",
+// followed by an address in angle brackets, and then optional parameters.
+func parsePath(arg, prefix string) (addr string, params []string, ok bool) {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", nil, false
+	}
+	arg = strings.TrimSpace(arg[len(prefix):])
+	if !strings.HasPrefix(arg, "<") {
+		return "", nil, false
+	}
+	end := strings.IndexByte(arg, '>')
+	if end < 0 {
+		return "", nil, false
+	}
+	addr = arg[1:end]
+	// Source routes (RFC 5321 appendix C) are obsolete; ignore them.
+	if strings.HasPrefix(addr, "@") {
+		if _, rest, found := strings.Cut(addr, ":"); found {
+			addr = rest
+		}
+	}
+	return addr, strings.Fields(arg[end+1:]), true
+}
+
+// readCommand reads a command line, without its line ending.
+func readCommand(r *bufio.Reader) (string, error) {
+	var line []byte
+	tooLong := false
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if len(line)+len(chunk) > maxCommandLength {
+			tooLong = true
+		} else {
+			line = append(line, chunk...)
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		} else if err != nil {
+			return "", err
+		}
+		break
+	}
+	if tooLong {
+		return "", errLineTooLong
+	}
+	return string(bytes.TrimRight(line, "\r\n")), nil
+}
+
+// errTooBig is returned by readData if the message is larger than allowed.
+var errTooBig = errors.New("smtp: message too big")
+
+// readData reads the message sent after DATA, up to the line with a single
+// dot, and undoes dot-stuffing. If the message is longer than maxSize, it
+// is read to the end, but discarded, and errTooBig is returned.
+func readData(r *bufio.Reader, maxSize int64) ([]byte, error) {
+	var buf bytes.Buffer
+	tooBig := false
+	atLineStart := true
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if err != nil && err != bufio.ErrBufferFull {
+			return nil, err
+		}
+		if atLineStart {
+			if string(chunk) == ".\r\n" || string(chunk) == ".\n" {
+				break
+			}
+			chunk = bytes.TrimPrefix(chunk, []byte("."))
+		}
+		atLineStart = err == nil
+		if int64(buf.Len()+len(chunk)) > maxSize {
+			tooBig = true
+		}
+		if !tooBig {
+			buf.Write(chunk)
+		}
+	}
+	if tooBig {
+		return nil, errTooBig
+	}
+	return buf.Bytes(), nil
+}
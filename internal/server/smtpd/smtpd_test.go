@@ -0,0 +1,153 @@
+package smtpd
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testServer returns a server accepting mail for alice@example.com, which
+// records delivered messages, and a client connected to it.
+func testServer(t *testing.T, maxSize int64) (*smtp.Client, *[]Envelope) {
+	var delivered []Envelope
+	s := &Server{
+		Hostname: "mx.example.com",
+		MaxSize:  maxSize,
+		Accept: func(addr string) error {
+			if addr != "alice@example.com" {
+				return ErrNoSuchMailbox
+			}
+			return nil
+		},
+		Deliver: func(env Envelope) error {
+			env.Data = append([]byte(nil), env.Data...)
+			delivered = append(delivered, env)
+			return nil
+		},
+	}
+	serverConn, clientConn := net.Pipe()
+	go s.ServeConn(serverConn)
+	c, err := smtp.NewClient(clientConn, "mx.example.com")
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+	return c, &delivered
+}
+
+func sendData(c *smtp.Client, data string) error {
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte(data)); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+func TestDeliver(t *testing.T) {
+	c, delivered := testServer(t, 0)
+	require.NoError(t, c.Hello("client.example.net"))
+	ok, size := c.Extension("SIZE")
+	assert.True(t, ok)
+	assert.Equal(t, fmt.Sprint(DefaultMaxSize), size)
+
+	require.NoError(t, c.Mail("bob@example.net"))
+	require.NoError(t, c.Rcpt("alice@example.com"))
+	err := c.Rcpt("mallory@example.com")
+	var tpErr *textproto.Error
+	require.ErrorAs(t, err, &tpErr)
+	assert.Equal(t, 550, tpErr.Code)
+
+	// A line starting with a dot survives dot-stuffing:
+	data := "Subject: hi\r\n\r\nHello\r\n.signature\r\n"
+	require.NoError(t, sendData(c, data))
+	require.NoError(t, c.Quit())
+
+	require.Len(t, *delivered, 1)
+	env := (*delivered)[0]
+	assert.Equal(t, "bob@example.net", env.From)
+	assert.Equal(t, []string{"alice@example.com"}, env.To)
+	assert.Equal(t, data, string(env.Data))
+}
+
+func TestTooBig(t *testing.T) {
+	c, delivered := testServer(t, 100)
+	require.NoError(t, c.Hello("client.example.net"))
+
+	// Rejected up front, if the client says how big the message is:
+	id, err := c.Text.Cmd("MAIL FROM:<bob@example.net> SIZE=1000")
+	require.NoError(t, err)
+	c.Text.StartResponse(id)
+	code, _, _ := c.Text.ReadResponse(250)
+	c.Text.EndResponse(id)
+	assert.Equal(t, 552, code)
+
+	// ...or after it has been sent:
+	require.NoError(t, c.Mail("bob@example.net"))
+	require.NoError(t, c.Rcpt("alice@example.com"))
+	err = sendData(c, "Subject: hi\r\n\r\n"+strings.Repeat("spam\r\n", 100))
+	var tpErr *textproto.Error
+	require.ErrorAs(t, err, &tpErr)
+	assert.Equal(t, 552, tpErr.Code)
+
+	// The connection can still be used:
+	require.NoError(t, c.Mail("bob@example.net"))
+	require.NoError(t, c.Rcpt("alice@example.com"))
+	require.NoError(t, sendData(c, "Subject: hi\r\n\r\nHello\r\n"))
+	assert.Len(t, *delivered, 1)
+}
+
+func TestCommandSequence(t *testing.T) {
+	c, _ := testServer(t, 0)
+	expectCode := func(cmd string, want int) {
+		t.Helper()
+		id, err := c.Text.Cmd("%s", cmd)
+		require.NoError(t, err)
+		c.Text.StartResponse(id)
+		defer c.Text.EndResponse(id)
+		code, _, _ := c.Text.ReadResponse(0)
+		assert.Equal(t, want, code, cmd)
+	}
+	expectCode("MAIL FROM:<bob@example.net>", 503)
+	expectCode("HELO client.example.net", 250)
+	expectCode("RCPT TO:<alice@example.com>", 503)
+	expectCode("DATA", 503)
+	expectCode("MAIL FROM:bob@example.net", 501)
+	expectCode("MAIL FROM:<bob@example.net>", 250)
+	expectCode("MAIL FROM:<bob@example.net>", 503)
+	expectCode("DATA", 554)
+	expectCode("RSET", 250)
+	expectCode("RCPT TO:<alice@example.com>", 503)
+	expectCode("EXPN staff", 502)
+	expectCode("NOOP "+strings.Repeat("x", maxCommandLength), 500)
+	expectCode("NOOP", 250)
+}
+
+func TestReadData(t *testing.T) {
+	cases := []struct {
+		in, out string
+		err     error
+	}{
+		{"Hello\r\n.\r\n", "Hello\r\n", nil},
+		{"..\r\n...x\r\n.\r\n", ".\r\n..x\r\n", nil},
+		{"Bare\nnewlines\n.\n", "Bare\nnewlines\n", nil},
+		{"a.\r\n.b\r\n.\r\n", "a.\r\nb\r\n", nil},
+		{strings.Repeat("x", 20) + "\r\n.\r\n", "", errTooBig},
+	}
+	for _, c := range cases {
+		r := bufio.NewReaderSize(strings.NewReader(c.in+"QUIT\r\n"), 16)
+		data, err := readData(r, 16)
+		assert.Equal(t, c.err, err, "%q", c.in)
+		assert.Equal(t, c.out, string(data), "%q", c.in)
+		// The rest of the input is untouched:
+		rest, _ := r.ReadString('\n')
+		assert.Equal(t, "QUIT\r\n", rest, "%q", c.in)
+	}
+}
@@ -0,0 +1,147 @@
+package thumbnail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Command is a Transcoder which runs a helper program, which reads the media
+// on stdin and writes a PNG (or other format supported by Image) to stdout.
+//
+// The helper runs with an empty environment in an empty temporary directory,
+// limited to Limits.MaxMemoryBytes of address space and Limits.Timeout of CPU
+// time; it is killed if it runs for longer than the timeout or writes more
+// than Limits.MaxOutputBytes.
+//
+// If Launcher is set, the helper also runs in a sandbox set up by the sandbox
+// launcher, as described at --helper in sandbox-launcher.c: in namespaces of
+// its own, so it cannot reach the network or other processes, with a root
+// directory holding only the system's programs and libraries, read-only, and
+// under the same seccomp filter as grains.
+type Command struct {
+	MimeTypes []string
+
+	// The program and its arguments. The string "{size}" in an argument is
+	// replaced with the requested thumbnail size, in pixels.
+	Args []string
+
+	// The path of tempest-sandbox-launcher, or "" to run the helper
+	// without a sandbox, e.g. in tests.
+	Launcher string
+}
+
+// ParseCommand splits a space-separated command line, as found in settings,
+// into a Command's Args.
+func ParseCommand(s string) []string {
+	return strings.Fields(s)
+}
+
+func (c Command) Accepts(mimeType string) bool {
+	for _, t := range c.MimeTypes {
+		if t == mimeType {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Command) Render(ctx context.Context, r io.Reader, size int, limits Limits) (image.Image, error) {
+	if len(c.Args) == 0 {
+		return nil, fmt.Errorf("thumbnail: no command configured")
+	}
+	dir, err := os.MkdirTemp("", "thumbnail-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	// Apply resource limits using the shell's ulimit, which then execs the
+	// helper; Go has no way to set rlimits for a child process directly.
+	script := "ulimit -c 0"
+	if limits.MaxMemoryBytes > 0 {
+		script += " && ulimit -v " + strconv.FormatInt(limits.MaxMemoryBytes>>10, 10)
+	}
+	if limits.Timeout > 0 {
+		script += " && ulimit -t " + strconv.Itoa(max(1, int(limits.Timeout.Seconds())))
+	}
+	script += ` && exec "$@"`
+	args := []string{"-c", script, "thumbnail-helper"}
+	if c.Launcher != "" {
+		// The launcher doesn't search $PATH, and the program must be
+		// under one of the directories it exposes in the sandbox.
+		program, err := exec.LookPath(c.Args[0])
+		if err != nil {
+			return nil, fmt.Errorf("thumbnail: %w", err)
+		}
+		if program, err = filepath.Abs(program); err != nil {
+			return nil, fmt.Errorf("thumbnail: %w", err)
+		}
+		args = append(args, c.Launcher, "--helper", program)
+	} else {
+		args = append(args, c.Args[0])
+	}
+	for _, arg := range c.Args[1:] {
+		args = append(args, strings.ReplaceAll(arg, "{size}", strconv.Itoa(size)))
+	}
+
+	cmd := exec.CommandContext(ctx, "/bin/sh", args...)
+	cmd.Dir = dir
+	cmd.Env = []string{"PATH=/usr/local/bin:/usr/bin:/bin", "HOME=" + dir}
+	cmd.Stdin = r
+	out := &limitedBuffer{max: limits.MaxOutputBytes}
+	cmd.Stdout = out
+	stderr := &limitedBuffer{max: 4096, truncate: true}
+	cmd.Stderr = stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Pdeathsig: syscall.SIGKILL,
+		Setpgid:   true,
+	}
+	// Kill the whole process group, which includes the sandboxed helper
+	// as well as the launcher.
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	err = cmd.Run()
+	if out.exceeded {
+		return nil, ErrTooLarge
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("thumbnail: running %s: %w: %s",
+			c.Args[0], err, bytes.TrimSpace(stderr.buf.Bytes()))
+	}
+	return decodeImage(&out.buf, limits)
+}
+
+// limitedBuffer is a bytes.Buffer which holds at most max bytes; a zero max
+// means no limit. Writes beyond the limit fail, or if truncate is set, are
+// silently discarded.
+type limitedBuffer struct {
+	buf      bytes.Buffer
+	max      int64
+	truncate bool
+	exceeded bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.max > 0 && int64(b.buf.Len()+len(p)) > b.max {
+		b.exceeded = !b.truncate
+		if b.exceeded {
+			return 0, ErrTooLarge
+		}
+		b.buf.Write(p[:b.max-int64(b.buf.Len())])
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
@@ -0,0 +1,109 @@
+package thumbnail
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"io"
+
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// Image is a Transcoder for the image formats supported by the standard
+// library.
+type Image struct{}
+
+func (Image) Accepts(mimeType string) bool {
+	switch mimeType {
+	case "image/png", "image/jpeg", "image/gif":
+		return true
+	}
+	return false
+}
+
+func (Image) Render(ctx context.Context, r io.Reader, size int, limits Limits) (image.Image, error) {
+	return decodeImage(r, limits)
+}
+
+// decodeImage decodes an image, first checking its dimensions so that we
+// don't allocate huge amounts of memory for a small, malicious file.
+func decodeImage(r io.Reader, limits Limits) (image.Image, error) {
+	br := bufio.NewReaderSize(r, 64<<10)
+	// image.DecodeConfig only needs the header, which will be much smaller
+	// than the buffer; peeking lets us decode the whole image afterwards.
+	header, _ := br.Peek(br.Size())
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(header))
+	if err != nil {
+		return nil, err
+	}
+	if limits.MaxPixels > 0 && cfg.Width*cfg.Height > limits.MaxPixels {
+		return nil, ErrTooLarge
+	}
+	img, _, err := image.Decode(br)
+	return img, err
+}
+
+// Fit scales img down, preserving its aspect ratio, so that it fits within
+// size x size pixels. Images which already fit are returned unchanged.
+//
+// Each destination pixel is the average of the source pixels it covers,
+// which gives acceptable results for the large reductions typical of
+// thumbnails. The source's pixels are read where they are, rather than
+// converted first, which for the largest images we accept would take
+// hundreds of megabytes.
+func Fit(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= size && h <= size {
+		return img
+	}
+	dw, dh := size, size
+	if w > h {
+		dh = max(1, h*size/w)
+	} else {
+		dw = max(1, w*size/h)
+	}
+	at := rgba64At(img)
+	dst := image.NewRGBA64(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*h/dh, max((y+1)*h/dh, y*h/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*w/dw, max((x+1)*w/dw, x*w/dw+1)
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := at(b.Min.X+sx, b.Min.Y+sy)
+					r += uint64(c.R)
+					g += uint64(c.G)
+					bl += uint64(c.B)
+					a += uint64(c.A)
+					n++
+				}
+			}
+			dst.SetRGBA64(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(bl / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+	return dst
+}
+
+// rgba64At returns a function which returns the color of img's pixel at (x,
+// y). The images returned by the standard library's decoders implement
+// image.RGBA64Image, which does so without allocating.
+func rgba64At(img image.Image) func(x, y int) color.RGBA64 {
+	if img, ok := img.(image.RGBA64Image); ok {
+		return img.RGBA64At
+	}
+	return func(x, y int) color.RGBA64 {
+		r, g, b, a := img.At(x, y).RGBA()
+		return color.RGBA64{R: uint16(r), G: uint16(g), B: uint16(b), A: uint16(a)}
+	}
+}
@@ -0,0 +1,172 @@
+// Package thumbnail generates preview images for media, for display in the
+// grain list and by apps.
+//
+// A Service holds a list of Transcoders, each of which knows how to render
+// some set of MIME types as an image. Images are decoded in-process; other
+// formats (e.g. PDF) are handled by running an external helper program with
+// strict resource limits (see Command). Whatever the source, the result is
+// scaled down to fit the requested size and encoded as a PNG.
+package thumbnail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrUnsupported is returned when no transcoder accepts the input's
+	// MIME type.
+	ErrUnsupported = errors.New("thumbnail: unsupported media type")
+
+	// ErrTooLarge is returned when the input exceeds the configured limits.
+	ErrTooLarge = errors.New("thumbnail: input too large")
+)
+
+// A Transcoder renders media of some types as an image.
+type Transcoder interface {
+	// Accepts returns true if the transcoder can handle the MIME type,
+	// which has had any parameters stripped.
+	Accepts(mimeType string) bool
+
+	// Render decodes the media read from r. size is the size of the
+	// requested thumbnail, which the transcoder may use as a hint; the
+	// result need not fit within it.
+	Render(ctx context.Context, r io.Reader, size int, limits Limits) (image.Image, error)
+}
+
+// Limits bounds the resources used to generate a thumbnail.
+type Limits struct {
+	MaxInputBytes int64         // Largest input accepted; 0 means no limit.
+	MaxPixels     int           // Largest decoded image, in pixels; 0 means no limit.
+	Timeout       time.Duration // Time allowed per thumbnail; 0 means no limit.
+
+	// Limits for helper processes; see Command.
+	MaxMemoryBytes int64
+	MaxOutputBytes int64
+}
+
+// DefaultLimits are suitable for a typical server.
+var DefaultLimits = Limits{
+	MaxInputBytes:  32 << 20,
+	MaxPixels:      50 * 1000 * 1000,
+	Timeout:        10 * time.Second,
+	MaxMemoryBytes: 512 << 20,
+	MaxOutputBytes: 32 << 20,
+}
+
+const (
+	DefaultSize = 256  // Default thumbnail width & height
+	MaxSize     = 1024 // Largest thumbnail we will generate.
+)
+
+// A Service generates thumbnails using its Transcoders.
+type Service struct {
+	Transcoders []Transcoder
+	Limits      Limits
+
+	// Bounds the number of thumbnails generated concurrently. If nil,
+	// there is no bound.
+	sem chan struct{}
+}
+
+// NewService returns a service using the given transcoders, which generates
+// at most concurrency thumbnails at once.
+func NewService(limits Limits, concurrency int, transcoders ...Transcoder) *Service {
+	return &Service{
+		Transcoders: transcoders,
+		Limits:      limits,
+		sem:         make(chan struct{}, concurrency),
+	}
+}
+
+// Accepts returns true if some transcoder accepts mimeType.
+func (s *Service) Accepts(mimeType string) bool {
+	return s.transcoder(mimeType) != nil
+}
+
+func (s *Service) transcoder(mimeType string) Transcoder {
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+	mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+	for _, t := range s.Transcoders {
+		if t.Accepts(mimeType) {
+			return t
+		}
+	}
+	return nil
+}
+
+// Thumbnail renders the media read from r, which has the given MIME type,
+// and returns a PNG fitting within size x size pixels.
+func (s *Service) Thumbnail(ctx context.Context, mimeType string, r io.Reader, size int) ([]byte, error) {
+	if size <= 0 || size > MaxSize {
+		return nil, fmt.Errorf("thumbnail: size must be between 1 and %d", MaxSize)
+	}
+	t := s.transcoder(mimeType)
+	if t == nil {
+		return nil, ErrUnsupported
+	}
+	if s.sem != nil {
+		select {
+		case s.sem <- struct{}{}:
+			defer func() { <-s.sem }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if s.Limits.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Limits.Timeout)
+		defer cancel()
+	}
+	lr := &limitedReader{R: r, N: s.Limits.MaxInputBytes}
+	if lr.N <= 0 {
+		lr.N = math.MaxInt64
+	}
+	img, err := t.Render(ctx, lr, size, s.Limits)
+	if lr.exceeded {
+		return nil, ErrTooLarge
+	}
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, Fit(img, size)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// limitedReader is like io.LimitedReader, but records whether the limit was
+// hit, so we can report that rather than a confusing decoding error.
+type limitedReader struct {
+	R        io.Reader
+	N        int64
+	exceeded bool
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.N <= 0 {
+		// Check whether there is more input:
+		var b [1]byte
+		n, err := l.R.Read(b[:])
+		if n > 0 {
+			l.exceeded = true
+			return 0, ErrTooLarge
+		}
+		return 0, err
+	}
+	if int64(len(p)) > l.N {
+		p = p[:l.N]
+	}
+	n, err := l.R.Read(p)
+	l.N -= int64(n)
+	return n, err
+}
@@ -0,0 +1,136 @@
+package thumbnail
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPNG(t *testing.T, w, h int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestFit(t *testing.T) {
+	cases := []struct {
+		w, h, size, wantW, wantH int
+	}{
+		{100, 50, 200, 100, 50},
+		{400, 200, 100, 100, 50},
+		{200, 400, 100, 50, 100},
+		{1000, 1, 100, 100, 1},
+	}
+	for _, c := range cases {
+		img := Fit(image.NewRGBA(image.Rect(0, 0, c.w, c.h)), c.size)
+		assert.Equal(t, c.wantW, img.Bounds().Dx(), "%+v", c)
+		assert.Equal(t, c.wantH, img.Bounds().Dy(), "%+v", c)
+	}
+}
+
+// Fit averages the pixels each destination pixel covers, reading them from
+// wherever the source image's bounds are.
+func TestFitAverages(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 8, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 8; x++ {
+			if x < 4 {
+				src.Set(x, y, color.White)
+			} else {
+				src.Set(x, y, color.Black)
+			}
+		}
+	}
+	for _, img := range []image.Image{
+		src.SubImage(image.Rect(2, 0, 6, 4)),
+		// Doesn't implement image.RGBA64Image:
+		struct{ image.Image }{src.SubImage(image.Rect(2, 0, 6, 4))},
+	} {
+		out := Fit(img, 2)
+		require.Equal(t, image.Rect(0, 0, 2, 2), out.Bounds())
+		for y := 0; y < 2; y++ {
+			assert.Equal(t, color.RGBA64{0xffff, 0xffff, 0xffff, 0xffff}, out.At(0, y))
+			assert.Equal(t, color.RGBA64{0, 0, 0, 0xffff}, out.At(1, y))
+		}
+	}
+}
+
+func TestServiceImage(t *testing.T) {
+	s := NewService(DefaultLimits, 1, Image{})
+	assert.True(t, s.Accepts("image/PNG; charset=binary"))
+	assert.False(t, s.Accepts("application/pdf"))
+
+	out, err := s.Thumbnail(context.Background(), "image/png", bytes.NewReader(testPNG(t, 300, 150)), 100)
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(out))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 100, 50), img.Bounds())
+
+	_, err = s.Thumbnail(context.Background(), "application/pdf", strings.NewReader("%PDF"), 100)
+	assert.ErrorIs(t, err, ErrUnsupported)
+
+	_, err = s.Thumbnail(context.Background(), "image/png", bytes.NewReader(nil), MaxSize+1)
+	assert.Error(t, err)
+}
+
+func TestServiceLimits(t *testing.T) {
+	input := testPNG(t, 300, 150)
+
+	limits := DefaultLimits
+	limits.MaxInputBytes = int64(len(input) - 1)
+	s := NewService(limits, 1, Image{})
+	_, err := s.Thumbnail(context.Background(), "image/png", bytes.NewReader(input), 100)
+	assert.ErrorIs(t, err, ErrTooLarge)
+
+	limits = DefaultLimits
+	limits.MaxPixels = 300*150 - 1
+	s = NewService(limits, 1, Image{})
+	_, err = s.Thumbnail(context.Background(), "image/png", bytes.NewReader(input), 100)
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestCommand(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat not available")
+	}
+	// cat passes the PNG through unchanged, standing in for a real helper.
+	s := NewService(DefaultLimits, 1, Command{
+		MimeTypes: []string{"image/x-test"},
+		Args:      []string{"cat"},
+	})
+	out, err := s.Thumbnail(context.Background(), "image/x-test", bytes.NewReader(testPNG(t, 20, 40)), 10)
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(out))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 5, 10), img.Bounds())
+
+	limits := DefaultLimits
+	limits.MaxOutputBytes = 10
+	s = NewService(limits, 1, Command{
+		MimeTypes: []string{"image/x-test"},
+		Args:      []string{"cat"},
+	})
+	_, err = s.Thumbnail(context.Background(), "image/x-test", bytes.NewReader(testPNG(t, 20, 40)), 10)
+	assert.ErrorIs(t, err, ErrTooLarge)
+
+	s = NewService(DefaultLimits, 1, Command{
+		MimeTypes: []string{"image/x-test"},
+		Args:      []string{"false"},
+	})
+	_, err = s.Thumbnail(context.Background(), "image/x-test", strings.NewReader(""), 10)
+	assert.Error(t, err)
+}
@@ -0,0 +1,129 @@
+// Package turn issues time-limited credentials for a TURN server, so that
+// apps doing WebRTC (e.g. video calls) can relay media without the server's
+// secret ever being given to the app.
+//
+// Credentials follow the "TURN REST API" scheme supported by coturn's
+// use-auth-secret option: the username is "<expiry>:<id>", and the password is
+// the base64-encoded HMAC-SHA1 of the username, keyed with the secret shared
+// with the TURN server. The TURN server can then check credentials itself,
+// without talking to Tempest.
+package turn
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned when a grain asks for credentials more often
+// than Config.PerHour allows.
+var ErrRateLimited = errors.New("turn: too many requests for credentials")
+
+// Config configures a Service.
+type Config struct {
+	// URLs of the TURN server, e.g. "turn:turn.example.com:3478". If this
+	// is empty, credentials are not available.
+	URLs []string
+
+	// Secret shared with the TURN server (coturn's static-auth-secret).
+	Secret string
+
+	// How long credentials are valid for.
+	TTL time.Duration
+
+	// Maximum number of credentials issued to each grain per hour. Short
+	// bursts of up to this many are allowed.
+	PerHour int
+}
+
+// Enabled returns true if TURN credentials are configured.
+func (c Config) Enabled() bool {
+	return len(c.URLs) > 0 && c.Secret != ""
+}
+
+// Credentials for a TURN server. The JSON encoding is an RTCIceServer, as
+// accepted by the RTCPeerConnection constructor.
+type Credentials struct {
+	URLs       []string  `json:"urls"`
+	Username   string    `json:"username"`
+	Credential string    `json:"credential"`
+	Expires    time.Time `json:"expires"`
+}
+
+// A Service issues credentials, applying per-grain rate limits.
+type Service struct {
+	cfg Config
+	now func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// A token bucket; see Service.allow.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewService returns a Service using cfg.
+func NewService(cfg Config) *Service {
+	return &Service{
+		cfg:     cfg,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Issue returns new credentials for the grain with the given ID.
+func (s *Service) Issue(grainID string) (Credentials, error) {
+	now := s.now()
+	if !s.allow(grainID, now) {
+		return Credentials{}, ErrRateLimited
+	}
+	expires := now.Add(s.cfg.TTL).Truncate(time.Second)
+	username := strconv.FormatInt(expires.Unix(), 10) + ":" + grainID
+	mac := hmac.New(sha1.New, []byte(s.cfg.Secret))
+	mac.Write([]byte(username))
+	return Credentials{
+		URLs:       s.cfg.URLs,
+		Username:   username,
+		Credential: base64.StdEncoding.EncodeToString(mac.Sum(nil)),
+		Expires:    expires,
+	}, nil
+}
+
+// allow takes a token from the grain's bucket, returning false if it is
+// empty. Buckets hold up to PerHour tokens and refill continuously at that
+// rate.
+func (s *Service) allow(grainID string, now time.Time) bool {
+	if s.cfg.PerHour <= 0 {
+		return true
+	}
+	capacity := float64(s.cfg.PerHour)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[grainID]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		s.buckets[grainID] = b
+	}
+	b.tokens += now.Sub(b.last).Hours() * capacity
+	b.tokens = min(b.tokens, capacity)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	// Full buckets carry no information; drop them so the map doesn't
+	// grow without bound.
+	for id, other := range s.buckets {
+		if other.tokens+now.Sub(other.last).Hours()*capacity >= capacity {
+			delete(s.buckets, id)
+		}
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package turn
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssue(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	s := NewService(Config{
+		URLs:   []string{"turn:turn.example.com:3478"},
+		Secret: "sekrit",
+		TTL:    time.Hour,
+	})
+	s.now = func() time.Time { return now }
+
+	creds, err := s.Issue("grain123")
+	require.NoError(t, err)
+	assert.Equal(t, "1700003600:grain123", creds.Username)
+	assert.Equal(t, now.Add(time.Hour), creds.Expires)
+	assert.Equal(t, []string{"turn:turn.example.com:3478"}, creds.URLs)
+
+	// This is how coturn checks the password:
+	mac := hmac.New(sha1.New, []byte("sekrit"))
+	mac.Write([]byte(creds.Username))
+	assert.Equal(t, base64.StdEncoding.EncodeToString(mac.Sum(nil)), creds.Credential)
+}
+
+func TestRateLimit(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	s := NewService(Config{
+		URLs:    []string{"turn:turn.example.com"},
+		Secret:  "sekrit",
+		TTL:     time.Hour,
+		PerHour: 2,
+	})
+	s.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		_, err := s.Issue("grain123")
+		require.NoError(t, err)
+	}
+	_, err := s.Issue("grain123")
+	assert.ErrorIs(t, err, ErrRateLimited)
+
+	// Other grains have their own limits:
+	_, err = s.Issue("grain456")
+	assert.NoError(t, err)
+
+	// After half an hour, one more token has accumulated:
+	now = now.Add(30 * time.Minute)
+	_, err = s.Issue("grain123")
+	assert.NoError(t, err)
+	_, err = s.Issue("grain123")
+	assert.ErrorIs(t, err, ErrRateLimited)
+}
+
+func TestEnabled(t *testing.T) {
+	assert.False(t, Config{}.Enabled())
+	assert.False(t, Config{URLs: []string{"turn:example.com"}}.Enabled())
+	assert.True(t, Config{URLs: []string{"turn:example.com"}, Secret: "x"}.Enabled())
+}
@@ -0,0 +1,163 @@
+// Package appenv defines the environment which Tempest guarantees to apps,
+// so that they have a stable target across Tempest versions.
+//
+// The grain agent runs the app's command with the environment variables
+// below, in addition to those in the app's manifest. Per-user information
+// arrives with each web session instead, and sandstorm-http-bridge passes it
+// on to the app as request headers:
+//
+//   - the user's locales, most preferred first, in Accept-Language. The
+//     first is the locale of the Tempest UI the grain was opened from.
+//   - the user's permissions, as a comma-separated list of the names from
+//     the app's viewInfo, in X-Sandstorm-Permissions.
+//   - the URL at which the grain is being served, in X-Sandstorm-Base-Path.
+//
+// Grains always run in UTC, since one grain may be shared by users in
+// different timezones; apps should convert times for display in the
+// browser. Apps should seed random number generators from RandomSource.
+//
+// When a grain is upgraded to a newer version of its app, the first run of
+// the new version also gets EnvPreviousAppVersion, and should migrate the
+// grain's data. If it fails to start, the grain goes back to the old version,
+// so migrations should leave the data usable by it until they have finished.
+//
+// CheckEnviron and CheckRequest check that an app was given everything in
+// the contract; cmd/test-app serves their results.
+package appenv
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Version is the version of the contract. It is increased when anything is
+// added to it, and is in the EnvVersion environment variable.
+const Version = 2
+
+const (
+	// EnvVersion is the environment variable holding Version.
+	EnvVersion = "TEMPEST_APP_ENV_VERSION"
+
+	// EnvRandomSource is the environment variable holding RandomSource.
+	EnvRandomSource = "TEMPEST_RANDOM_SOURCE"
+
+	// EnvPreviousAppVersion is the environment variable holding the
+	// appVersion, from the manifest, of the app version which last ran
+	// the grain, when that was older than the one now running it. It is
+	// unset otherwise.
+	EnvPreviousAppVersion = "TEMPEST_PREVIOUS_APP_VERSION"
+)
+
+// RandomSource is the device from which apps should read random seeds. It
+// is always present in the sandbox.
+const RandomSource = "/dev/urandom"
+
+// Defaults are the environment variables set for every app unless its
+// manifest sets them itself.
+var Defaults = []string{
+	"HOME=/var",
+	"LANG=C.UTF-8",
+	"PATH=/usr/local/bin:/usr/bin:/bin",
+	"TZ=UTC",
+}
+
+// The request headers in which sandstorm-http-bridge passes the per-user
+// information.
+const (
+	HeaderAcceptLanguage = "Accept-Language"
+	HeaderBasePath       = "X-Sandstorm-Base-Path"
+	HeaderPermissions    = "X-Sandstorm-Permissions"
+)
+
+// Environ returns the environment for an app's command, given the
+// environment from its manifest, as "key=value" strings. The manifest's
+// variables override Defaults, but not the variables which identify the
+// contract.
+func Environ(manifest []string) []string {
+	env := make([]string, 0, len(Defaults)+len(manifest)+2)
+	set := make(map[string]bool, len(manifest))
+	for _, kv := range manifest {
+		key, _, _ := strings.Cut(kv, "=")
+		if key == EnvVersion || key == EnvRandomSource || key == EnvPreviousAppVersion {
+			continue
+		}
+		set[key] = true
+		env = append(env, kv)
+	}
+	for _, kv := range Defaults {
+		key, _, _ := strings.Cut(kv, "=")
+		if !set[key] {
+			env = append(env, kv)
+		}
+	}
+	return append(env,
+		EnvVersion+"="+strconv.Itoa(Version),
+		EnvRandomSource+"="+RandomSource,
+	)
+}
+
+// CheckEnviron checks the app's environment variables, looked up with
+// lookupEnv, e.g. os.LookupEnv, and reads from the random source. It returns
+// a problem for each part of the contract which isn't met.
+func CheckEnviron(lookupEnv func(string) (string, bool)) []error {
+	var problems []error
+	if v, _ := lookupEnv(EnvVersion); v != strconv.Itoa(Version) {
+		problems = append(problems, fmt.Errorf("%s is %q, not %d", EnvVersion, v, Version))
+	}
+	for _, kv := range Defaults {
+		key, _, _ := strings.Cut(kv, "=")
+		if v, ok := lookupEnv(key); !ok || v == "" {
+			problems = append(problems, fmt.Errorf("%s is not set", key))
+		}
+	}
+	randomSource, _ := lookupEnv(EnvRandomSource)
+	if randomSource != RandomSource {
+		problems = append(problems, fmt.Errorf("%s is %q, not %q", EnvRandomSource, randomSource, RandomSource))
+	} else if err := readRandom(randomSource); err != nil {
+		problems = append(problems, fmt.Errorf("reading %s: %w", randomSource, err))
+	}
+	return problems
+}
+
+func readRandom(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var seed [16]byte
+	_, err = io.ReadFull(f, seed[:])
+	return err
+}
+
+// CheckRequest checks the per-user information in a request forwarded by
+// sandstorm-http-bridge. permissions are the names of the permissions in the
+// app's viewInfo. It returns a problem for each part of the contract which
+// isn't met.
+func CheckRequest(req *http.Request, permissions []string) []error {
+	var problems []error
+	languages := req.Header.Get(HeaderAcceptLanguage)
+	if first, _, _ := strings.Cut(languages, ","); strings.TrimSpace(first) == "" {
+		problems = append(problems, fmt.Errorf("%s is empty", HeaderAcceptLanguage))
+	}
+	basePath := req.Header.Get(HeaderBasePath)
+	if u, err := url.Parse(basePath); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		problems = append(problems, fmt.Errorf("%s is %q, not an http or https URL", HeaderBasePath, basePath))
+	}
+	if _, ok := req.Header[http.CanonicalHeaderKey(HeaderPermissions)]; !ok {
+		problems = append(problems, fmt.Errorf("%s is missing", HeaderPermissions))
+	}
+	for _, name := range strings.Split(req.Header.Get(HeaderPermissions), ",") {
+		name = strings.TrimSpace(name)
+		if name != "" && !slices.Contains(permissions, name) {
+			problems = append(problems, fmt.Errorf("%s contains unknown permission %q", HeaderPermissions, name))
+		}
+	}
+	return problems
+}
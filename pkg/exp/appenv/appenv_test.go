@@ -0,0 +1,79 @@
+package appenv
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnviron(t *testing.T) {
+	env := Environ([]string{
+		"PATH=/opt/app/bin:/usr/bin:/bin",
+		"APP_MODE=production",
+		EnvVersion + "=0",
+		EnvPreviousAppVersion + "=3",
+	})
+	assert.Equal(t, []string{
+		"PATH=/opt/app/bin:/usr/bin:/bin",
+		"APP_MODE=production",
+		"HOME=/var",
+		"LANG=C.UTF-8",
+		"TZ=UTC",
+		EnvVersion + "=2",
+		EnvRandomSource + "=/dev/urandom",
+	}, env)
+}
+
+func lookupIn(env []string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		for _, kv := range env {
+			if k, v, _ := strings.Cut(kv, "="); k == key {
+				return v, true
+			}
+		}
+		return "", false
+	}
+}
+
+func TestCheckEnviron(t *testing.T) {
+	assert.Empty(t, CheckEnviron(lookupIn(Environ(nil))))
+
+	problems := CheckEnviron(lookupIn([]string{"HOME=/var", "TZ="}))
+	require.Len(t, problems, 5)
+	assert.EqualError(t, problems[0], `TEMPEST_APP_ENV_VERSION is "", not 2`)
+	assert.EqualError(t, problems[1], "LANG is not set")
+	assert.EqualError(t, problems[2], "PATH is not set")
+	assert.EqualError(t, problems[3], "TZ is not set")
+	assert.EqualError(t, problems[4], `TEMPEST_RANDOM_SOURCE is "", not "/dev/urandom"`)
+}
+
+func TestCheckRequest(t *testing.T) {
+	permissions := []string{"editor", "commenter"}
+	req, err := http.NewRequest("GET", "http://localhost:8000/", nil)
+	require.NoError(t, err)
+	req.Header.Set(HeaderAcceptLanguage, "de-CH,de;q=0.9,en")
+	req.Header.Set(HeaderBasePath, "https://ui-0123.example.com")
+	req.Header.Set(HeaderPermissions, "editor,commenter")
+	assert.Empty(t, CheckRequest(req, permissions))
+
+	// A user may have no permissions at all, but the header is still sent.
+	req.Header.Set(HeaderPermissions, "")
+	assert.Empty(t, CheckRequest(req, permissions))
+
+	req.Header.Del(HeaderAcceptLanguage)
+	req.Header.Set(HeaderBasePath, "/grain")
+	req.Header.Set(HeaderPermissions, "editor,owner")
+	problems := CheckRequest(req, permissions)
+	require.Len(t, problems, 3)
+	assert.EqualError(t, problems[0], "Accept-Language is empty")
+	assert.EqualError(t, problems[1], `X-Sandstorm-Base-Path is "/grain", not an http or https URL`)
+	assert.EqualError(t, problems[2], `X-Sandstorm-Permissions contains unknown permission "owner"`)
+
+	req.Header.Del(HeaderPermissions)
+	problems = CheckRequest(req, permissions)
+	require.Len(t, problems, 3)
+	assert.EqualError(t, problems[2], "X-Sandstorm-Permissions is missing")
+}
@@ -49,7 +49,9 @@ func parseETag(s string) (result etag, rest string, err error) {
 	}
 	for i := 1; i < len(s); i++ {
 		if s[i] == '"' {
-			result.Value = strings.TrimSpace(s[:i+1])
+			// The value doesn't include the quotes; see the ETag
+			// struct in web-session.capnp.
+			result.Value = s[1:i]
 			return result, s[i+1:], nil
 		}
 		if s[i] == '\\' {
@@ -36,7 +36,7 @@ const maxNonStreamingBodySize = 1 << 16
 func (h Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	switch req.Method {
 	case "GET":
-		if req.Header.Get("Upgrade") == "websocket" {
+		if isWebsocketUpgrade(req) {
 			h.doWebsocket(w, req)
 		} else {
 			h.doGet(w, req, false)
@@ -73,14 +73,19 @@ func (h Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// doWebsocket handles a WebSocket upgrade: it opens a WebSocket on the session,
+// completes the handshake with a protocol the session accepts, and then relays
+// the raw WebSocket traffic in both directions until either side closes it.
+// Since the traffic includes close frames, closing the WebSocket from either
+// side closes it on the other.
 func (h Handler) doWebsocket(w http.ResponseWriter, req *http.Request) {
-	clientProtos := strings.Split(req.Header.Get("Sec-WebSocket-Protocol"), ",")
-	for i, p := range clientProtos {
-		clientProtos[i] = strings.TrimSpace(p)
-	}
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+
+	clientProtos := websocketProtocols(req.Header)
 	streamPromise, streamResolver := capnp.NewLocalPromise[websession.WebSocketStream]()
 	fut, rel := h.Session.OpenWebSocket(
-		req.Context(),
+		ctx,
 		func(p websession.WebSession_openWebSocket_Params) error {
 			// NOTE: we leave responseStream null, since it isn't actually
 			// used by openWebSocket.
@@ -98,34 +103,22 @@ func (h Handler) doWebsocket(w http.ResponseWriter, req *http.Request) {
 			return p.SetClientStream(streamPromise)
 		})
 	defer rel()
-	replyErr := func(err error) {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte(err.Error()))
-		streamResolver.Reject(err)
-	}
 	res, err := fut.Struct()
 	if err != nil {
-		replyErr(err)
+		streamResolver.Reject(err)
+		replyErr(w, err)
 		return
 	}
-	retProto, err := res.Protocol()
+	srvProtos, err := res.Protocol()
 	if err != nil {
-		replyErr(err)
+		streamResolver.Reject(err)
+		replyErr(w, err)
 		return
 	}
-	srvProto := make([]string, 0, retProto.Len())
-	for i := 0; i < retProto.Len(); i++ {
-		p, err := retProto.At(i)
-		if err != nil {
-			replyErr(err)
-			return
-		}
-		srvProto = append(srvProto, p)
-	}
 	conn, bufRw, _, err := ws.HTTPUpgrader{
 		Protocol: func(s string) bool {
-			for _, p := range srvProto {
-				if s == p {
+			for i := 0; i < srvProtos.Len(); i++ {
+				if p, err := srvProtos.At(i); err == nil && p == s {
 					return true
 				}
 			}
@@ -133,21 +126,63 @@ func (h Handler) doWebsocket(w http.ResponseWriter, req *http.Request) {
 		},
 	}.Upgrade(req, w)
 	if err != nil {
-		replyErr(err)
-		return
-	}
-	if err = bufRw.Flush(); err != nil {
-		replyErr(err)
+		// The upgrader has already sent the client an error response.
+		streamResolver.Reject(err)
+		if conn != nil {
+			conn.Close()
+		}
 		return
 	}
-	stream := websession.WebSocketStream_ServerToClient(websocket.WriterStream{W: conn})
-	streamResolver.Fulfill(stream)
+	defer conn.Close()
+	// The WebSocket lasts as long as either side likes, so it mustn't be
+	// cut off by any deadlines set for the request.
+	conn.SetDeadline(time.Time{})
+
+	// The session drops the stream when it is done with the WebSocket;
+	// WriterStream then closes conn, which also ends the copy below.
+	streamResolver.Fulfill(websession.WebSocketStream_ServerToClient(websocket.WriterStream{W: conn}))
+
+	srvStream := res.ServerStream().AddRef()
+	defer srvStream.Release()
+	srvStream.SetFlowLimiter(flowcontrol.NewFixedLimiter(64 * 1024)) // arbitrary
 	srvW := websocket.StreamWriter{
-		Context: req.Context(),
-		Stream:  res.ServerStream(),
+		Context: ctx,
+		Stream:  srvStream,
+	}
+	// Read through bufRw, in case the client sent anything along with the
+	// handshake. When the client goes away, releasing srvStream tells the
+	// session.
+	io.Copy(srvW, bufRw.Reader)
+	srvStream.WaitStreaming()
+}
+
+// websocketProtocols returns the subprotocols offered in the request's
+// Sec-WebSocket-Protocol headers, in order of preference.
+func websocketProtocols(h http.Header) []string {
+	var protos []string
+	for _, v := range h.Values("Sec-WebSocket-Protocol") {
+		for _, p := range strings.Split(v, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				protos = append(protos, p)
+			}
+		}
+	}
+	return protos
+}
+
+// isWebsocketUpgrade reports whether req asks to be upgraded to a WebSocket.
+func isWebsocketUpgrade(req *http.Request) bool {
+	if !strings.EqualFold(req.Header.Get("Upgrade"), "websocket") {
+		return false
+	}
+	for _, v := range req.Header.Values("Connection") {
+		for _, token := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), "upgrade") {
+				return true
+			}
+		}
 	}
-	io.Copy(srvW, conn)
-	<-req.Context().Done()
+	return false
 }
 
 func (h Handler) doPropfind(w http.ResponseWriter, req *http.Request) {
@@ -499,6 +534,13 @@ func relayResponse(
 		close(responseStream.ready)
 		return
 	}
+	if status == http.StatusOK && notModified(req, resp) {
+		status = http.StatusNotModified
+	}
+	if status == http.StatusNotModified {
+		relayNotModified(w, req, resp, responseStream)
+		return
+	}
 
 	if resp.Which() == websession.Response_Which_content {
 		content := resp.Content()
@@ -507,11 +549,15 @@ func relayResponse(
 			defer body.Stream().Release()
 			responseStream.used = true
 
+			var (
+				size  uint64
+				sized bool
+			)
 			select {
 			case <-req.Context().Done():
 				return
-			case size, ok := <-responseStream.size:
-				if ok {
+			case size, sized = <-responseStream.size:
+				if sized {
 					w.Header().Set("Content-Length", strconv.FormatUint(size, 10))
 				}
 			}
@@ -519,12 +565,39 @@ func relayResponse(
 				replyErr(w, err)
 				return
 			}
+			// Ranges can only be served if we know the size up
+			// front.
+			if sized && status == http.StatusOK && (req.Method == "GET" || req.Method == "HEAD") {
+				w.Header().Set("Accept-Ranges", "bytes")
+				r, err := requestedRange(req, resp, status, int64(size))
+				switch err {
+				case nil:
+					status = http.StatusPartialContent
+					w.Header().Set("Content-Range", r.contentRange(int64(size)))
+					w.Header().Set("Content-Length", strconv.FormatInt(r.length, 10))
+					responseStream.offset = r.start
+					responseStream.length = r.length
+				case errUnsatisfiableRange:
+					responseStream.used = false
+					close(responseStream.ready)
+					replyRangeNotSatisfiable(w, int64(size))
+					return
+				}
+			}
 			w.WriteHeader(status)
+			// The body may take arbitrarily long, so it mustn't be cut
+			// off by a write deadline. Send the headers now, so the
+			// client knows the response has started.
+			responseStream.rc.SetWriteDeadline(time.Time{})
+			responseStream.rc.Flush()
 			close(responseStream.ready)
 			select {
 			case <-req.Context().Done():
 			case <-responseStream.done:
 			case <-responseStream.shutdown:
+			case <-responseStream.rangeDone:
+				// Releasing the stream's handle stops the
+				// grain from sending the rest.
 			}
 			return
 		}
@@ -540,11 +613,131 @@ func relayResponse(
 		replyErr(w, err)
 		return
 	}
+	if resp.Which() == websession.Response_Which_content && status == http.StatusOK &&
+		(req.Method == "GET" || req.Method == "HEAD") {
+
+		w.Header().Set("Accept-Ranges", "bytes")
+		size := int64(len(data))
+		r, err := requestedRange(req, resp, status, size)
+		switch err {
+		case nil:
+			status = http.StatusPartialContent
+			w.Header().Set("Content-Range", r.contentRange(size))
+			data = data[r.start : r.start+r.length]
+		case errUnsatisfiableRange:
+			replyRangeNotSatisfiable(w, size)
+			return
+		}
+	}
 	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
 	w.WriteHeader(status)
 	w.Write(data)
 }
 
+// relayNotModified relays resp as a 304 response, which has no body; if resp
+// has one, it is dropped unread.
+func relayNotModified(
+	w http.ResponseWriter,
+	req *http.Request,
+	resp websession.Response,
+	responseStream *responseStreamImpl,
+) {
+	if resp.Which() == websession.Response_Which_content {
+		body := resp.Content().Body()
+		if body.Which() == websession.Response_content_body_Which_stream {
+			// Leaving responseStream unused makes the grain's
+			// writes to it fail, so it stops sending the body.
+			body.Stream().Release()
+		}
+	}
+	close(responseStream.ready)
+	if err := populateResponseHeaders(w, req, resp); err != nil {
+		replyErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNotModified)
+}
+
+// notModified reports whether the client already has the content of resp,
+// according to the conditional headers of req, a GET or HEAD request. Grains
+// may check these themselves (see WebSession.Context.eTagPrecondition), but
+// many just send the content along with its ETag or Last-Modified header, in
+// which case the check is done here, so that the content needn't be sent to
+// the client again.
+func notModified(req *http.Request, resp websession.Response) bool {
+	if (req.Method != "GET" && req.Method != "HEAD") ||
+		resp.Which() != websession.Response_Which_content {
+
+		return false
+	}
+	content := resp.Content()
+	// As per RFC 9110 section 13.2.2, If-None-Match takes precedence over
+	// If-Modified-Since.
+	if ifNoneMatch := req.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		if ifNoneMatch == "*" {
+			return true
+		}
+		if !content.HasETag() {
+			return false
+		}
+		etag, err := content.ETag()
+		if err != nil {
+			return false
+		}
+		value, err := etag.Value()
+		if err != nil {
+			return false
+		}
+		tags, err := parseETagList(ifNoneMatch)
+		if err != nil {
+			return false
+		}
+		for _, tag := range tags {
+			// If-None-Match uses the weak comparison, which ignores
+			// whether either tag is weak.
+			if tag.Value == value {
+				return true
+			}
+		}
+		return false
+	}
+	ifModifiedSince, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	lastModified, err := responseHeader(resp, "Last-Modified")
+	if err != nil {
+		return false
+	}
+	t, err := http.ParseTime(lastModified)
+	return err == nil && !t.After(ifModifiedSince)
+}
+
+// responseHeader returns the value of the named header among resp's
+// additionalHeaders, or "" if there is none.
+func responseHeader(resp websession.Response, name string) (string, error) {
+	headers, err := resp.AdditionalHeaders()
+	if err != nil {
+		return "", err
+	}
+	for i := 0; i < headers.Len(); i++ {
+		item := headers.At(i)
+		k, err := item.Key()
+		if err != nil {
+			return "", err
+		}
+		if http.CanonicalHeaderKey(k.Text()) != name {
+			continue
+		}
+		v, err := item.Value()
+		if err != nil {
+			return "", err
+		}
+		return v.Text(), nil
+	}
+	return "", nil
+}
+
 // responseStatus returns the correct HTTP status code for the the response.
 // req is the original request that this is a response to.
 func responseStatus(req *http.Request, resp websession.Response) (int, error) {
@@ -564,7 +757,8 @@ func responseStatus(req *http.Request, resp websession.Response) (int, error) {
 		}
 	case websession.Response_Which_preconditionFailed:
 		if (req.Method == "GET" || req.Method == "HEAD") &&
-			req.Header.Get("If-None-Match") != "" {
+			(req.Header.Get("If-None-Match") != "" ||
+				req.Header.Get("If-Modified-Since") != "") {
 
 			return http.StatusNotModified, nil
 		}
@@ -1,15 +1,21 @@
 package websession
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"capnproto.org/go/capnp/v3"
+	"github.com/gobwas/ws"
 	"github.com/tj/assert"
 	utilcp "sandstorm.org/go/tempest/capnp/util"
 	websession "sandstorm.org/go/tempest/capnp/web-session"
@@ -65,6 +71,130 @@ func TestGetBody(t *testing.T) {
 
 }
 
+// Conditional requests for content which the client already has get 304
+// responses, without the content, even if the session doesn't check them.
+func TestGetNotModified(t *testing.T) {
+	t.Parallel()
+
+	const lastModified = "Mon, 02 Jan 2006 15:04:05 GMT"
+	cases := []struct {
+		header, value string
+		want          int
+	}{
+		{"If-None-Match", `"v1"`, http.StatusNotModified},
+		{"If-None-Match", `"v0", W/"v1"`, http.StatusNotModified},
+		{"If-None-Match", "*", http.StatusNotModified},
+		{"If-None-Match", `"v2"`, http.StatusOK},
+		{"If-Modified-Since", lastModified, http.StatusNotModified},
+		{"If-Modified-Since", "Tue, 03 Jan 2006 15:04:05 GMT", http.StatusNotModified},
+		{"If-Modified-Since", "Sun, 01 Jan 2006 15:04:05 GMT", http.StatusOK},
+		{"If-Modified-Since", "not a date", http.StatusOK},
+	}
+	for _, stream := range []bool{false, true} {
+		for _, c := range cases {
+			sess := testWebSessionImpl{
+				expectedBody: "Expected Body",
+				stream:       stream,
+				eTag:         "v1",
+				lastModified: lastModified,
+			}
+			req := httptest.NewRequest("GET", "/expected-body", nil)
+			req.Header.Set(c.header, c.value)
+			rec := doRequest(sess, req)
+			assert.Equal(t, c.want, rec.Code, "stream=%v %+v", stream, c)
+			assert.Equal(t, `"v1"`, rec.Result().Header.Get("ETag"))
+			if c.want == http.StatusNotModified {
+				assert.Equal(t, "", rec.Body.String())
+			} else {
+				assert.Equal(t, "Expected Body", rec.Body.String())
+			}
+		}
+	}
+}
+
+// Range requests get the part of the content they ask for, if the size of the
+// content is known up front.
+func TestGetRange(t *testing.T) {
+	t.Parallel()
+
+	const body = "Expected Body"
+	cases := []struct {
+		rangeHeader, ifRange string
+		want                 int
+		wantBody             string
+		wantContentRange     string
+	}{
+		{"bytes=0-3", "", http.StatusPartialContent, "Expe", "bytes 0-3/13"},
+		{"bytes=9-", "", http.StatusPartialContent, "Body", "bytes 9-12/13"},
+		{"bytes=9-100", "", http.StatusPartialContent, "Body", "bytes 9-12/13"},
+		{"bytes=-4", "", http.StatusPartialContent, "Body", "bytes 9-12/13"},
+		{"bytes=13-", "", http.StatusRequestedRangeNotSatisfiable, "", "bytes */13"},
+		{"bytes=0-1,3-4", "", http.StatusOK, body, ""},
+		{"lines=1-2", "", http.StatusOK, body, ""},
+		{"bytes=0-3", `"v1"`, http.StatusPartialContent, "Expe", "bytes 0-3/13"},
+		{"bytes=0-3", `"v2"`, http.StatusOK, body, ""},
+		{"bytes=0-3", `W/"v1"`, http.StatusOK, body, ""},
+	}
+	for _, stream := range []bool{false, true} {
+		for _, c := range cases {
+			sess := testWebSessionImpl{
+				expectedBody:   body,
+				stream:         stream,
+				callExpectSize: true,
+				eTag:           "v1",
+			}
+			req := httptest.NewRequest("GET", "/expected-body", nil)
+			req.Header.Set("Range", c.rangeHeader)
+			if c.ifRange != "" {
+				req.Header.Set("If-Range", c.ifRange)
+			}
+			rec := doRequest(sess, req)
+			assert.Equal(t, c.want, rec.Code, "stream=%v %+v", stream, c)
+			assert.Equal(t, c.wantBody, rec.Body.String(), "stream=%v %+v", stream, c)
+			assert.Equal(t, c.wantContentRange, rec.Result().Header.Get("Content-Range"))
+			assert.Equal(t, "bytes", rec.Result().Header.Get("Accept-Ranges"))
+		}
+	}
+
+	// Without the size, the whole content is sent.
+	req := httptest.NewRequest("GET", "/expected-body", nil)
+	req.Header.Set("Range", "bytes=0-3")
+	rec := doRequest(testWebSessionImpl{expectedBody: body, stream: true}, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, body, rec.Body.String())
+	assert.Equal(t, "", rec.Result().Header.Get("Accept-Ranges"))
+}
+
+// The response stream writes only the range, however the content is split
+// into writes.
+func TestResponseStreamClip(t *testing.T) {
+	t.Parallel()
+
+	r := newResponseStreamImpl(httptest.NewRecorder())
+	r.offset = 3
+	r.length = 5
+	var got []string
+	for _, data := range []string{"ab", "cde", "fg", "hij", "k"} {
+		got = append(got, string(r.clip([]byte(data))))
+	}
+	assert.Equal(t, []string{"", "de", "fg", "h", ""}, got)
+}
+
+func TestParseETagList(t *testing.T) {
+	t.Parallel()
+
+	tags, err := parseETagList(`"a", W/"b" ,"c\"d"`)
+	assert.Nil(t, err)
+	assert.Equal(t, []etag{
+		{Value: "a"},
+		{Value: "b", Weak: true},
+		{Value: `c\"d`},
+	}, tags)
+
+	_, err = parseETagList(`"a", b`)
+	assert.NotNil(t, err)
+}
+
 func TestGetPath(t *testing.T) {
 	t.Parallel()
 	expected := "path-body/example"
@@ -76,6 +206,107 @@ func TestGetPath(t *testing.T) {
 	assert.Equal(t, expected, rec.Body.String())
 }
 
+// Each server-sent event reaches the client as soon as the session sends it,
+// rather than when the response ends.
+func TestServerSentEvents(t *testing.T) {
+	t.Parallel()
+
+	events := make(chan string, 1)
+	client := websession.WebSession_ServerToClient(testWebSessionImpl{events: events})
+	defer client.Release()
+	srv := httptest.NewServer(Handler{Session: client})
+	defer srv.Close()
+
+	// The headers are sent along with the first write, since until then
+	// the session may still call expectSize():
+	events <- "tick 1"
+	resp, err := http.Get(srv.URL + "/events")
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	body := bufio.NewReader(resp.Body)
+	for i, event := range []string{"tick 1", "tick 2", "tick 3"} {
+		if i > 0 {
+			events <- event
+		}
+		received := make(chan string, 1)
+		go func() {
+			line, _ := body.ReadString('\n')
+			body.ReadString('\n') // The blank line ending the event.
+			received <- line
+		}()
+		select {
+		case line := <-received:
+			assert.Equal(t, "data: "+event+"\n", line)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("event %q was not delivered", event)
+		}
+	}
+	close(events)
+	rest, err := io.ReadAll(body)
+	assert.Nil(t, err)
+	assert.Equal(t, "", string(rest))
+}
+
+func TestWebsocket(t *testing.T) {
+	t.Parallel()
+
+	client := websession.WebSession_ServerToClient(testWebSessionImpl{})
+	defer client.Release()
+	srv := httptest.NewServer(Handler{Session: client})
+	defer srv.Close()
+
+	ctx := context.Background()
+	conn, br, hs, err := ws.Dialer{
+		Protocols: []string{"chat", "echo"},
+	}.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http")+"/socket")
+	assert.Nil(t, err)
+	defer conn.Close()
+	assert.Equal(t, "echo", hs.Protocol)
+	var r io.Reader = conn
+	if br != nil {
+		r = br
+	}
+
+	for _, msg := range []string{"hello", "world"} {
+		err = ws.WriteFrame(conn, ws.MaskFrame(ws.NewTextFrame([]byte(msg))))
+		assert.Nil(t, err)
+		frame, err := ws.ReadFrame(r)
+		assert.Nil(t, err)
+		assert.Equal(t, ws.OpText, frame.Header.OpCode)
+		assert.Equal(t, msg, string(frame.Payload))
+	}
+
+	// Closing the WebSocket closes the connection:
+	err = ws.WriteFrame(conn, ws.MaskFrame(ws.NewCloseFrame(nil)))
+	assert.Nil(t, err)
+	_, err = ws.ReadFrame(r)
+	assert.NotNil(t, err)
+}
+
+func TestIsWebsocketUpgrade(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		upgrade, connection string
+		want                bool
+	}{
+		{"websocket", "Upgrade", true},
+		{"WebSocket", "keep-alive, upgrade", true},
+		{"websocket", "keep-alive", false},
+		{"h2c", "Upgrade", false},
+		{"", "", false},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Upgrade", c.upgrade)
+		req.Header.Set("Connection", c.connection)
+		assert.Equal(t, c.want, isWebsocketUpgrade(req), "%+v", c)
+	}
+}
+
 func doRequest(t testWebSessionImpl, req *http.Request) *httptest.ResponseRecorder {
 	client := websession.WebSession_ServerToClient(t)
 	defer client.Release()
@@ -90,6 +321,14 @@ type testWebSessionImpl struct {
 
 	stream         bool
 	callExpectSize bool
+
+	// Server-sent events to send in response to GET /events; the
+	// response ends when this is closed.
+	events chan string
+
+	// If not empty, the ETag and Last-Modified header of the content.
+	eTag         string
+	lastModified string
 }
 
 func (t testWebSessionImpl) Get(ctx context.Context, p websession.WebSession_get) error {
@@ -101,6 +340,10 @@ func (t testWebSessionImpl) Get(ctx context.Context, p websession.WebSession_get
 	response, err := p.AllocResults()
 	util.Chkfatal(err)
 
+	if path == "events" {
+		return t.getEvents(wsCtx, response)
+	}
+
 	var actualBody string
 	switch {
 	case path == "expected-body":
@@ -115,6 +358,21 @@ func (t testWebSessionImpl) Get(ctx context.Context, p websession.WebSession_get
 	content := response.Content()
 	content.SetStatusCode(websession.SuccessCode_ok)
 	content.SetMimeType("text/plain")
+	if t.eTag != "" {
+		etag, err := content.NewETag()
+		util.Chkfatal(err)
+		util.Chkfatal(etag.SetValue(t.eTag))
+	}
+	if t.lastModified != "" {
+		headers, err := response.NewAdditionalHeaders(1)
+		util.Chkfatal(err)
+		k, err := capnp.NewText(headers.Segment(), "Last-Modified")
+		util.Chkfatal(err)
+		util.Chkfatal(headers.At(0).SetKey(k.ToPtr()))
+		v, err := capnp.NewText(headers.Segment(), t.lastModified)
+		util.Chkfatal(err)
+		util.Chkfatal(headers.At(0).SetValue(v.ToPtr()))
+	}
 
 	body := content.Body()
 	if t.stream {
@@ -143,6 +401,32 @@ func (t testWebSessionImpl) Get(ctx context.Context, p websession.WebSession_get
 	return nil
 }
 
+// getEvents responds with a stream of server-sent events, one for each
+// string received on t.events.
+func (t testWebSessionImpl) getEvents(wsCtx websession.Context, response websession.Response) error {
+	response.SetContent()
+	content := response.Content()
+	content.SetStatusCode(websession.SuccessCode_ok)
+	content.SetMimeType("text/event-stream")
+	ctx, hndl := handle.WithCancel(context.Background())
+	content.Body().SetStream(hndl)
+	responseStream := wsCtx.ResponseStream().AddRef()
+	go func() {
+		defer responseStream.Release()
+		for event := range t.events {
+			err := responseStream.Write(ctx, func(p utilcp.ByteStream_write_Params) error {
+				return p.SetData([]byte("data: " + event + "\n\n"))
+			})
+			if err != nil {
+				return
+			}
+		}
+		_, rel := responseStream.Done(ctx, nil)
+		defer rel()
+	}()
+	return nil
+}
+
 // TODO: implement these
 func (testWebSessionImpl) Post(context.Context, websession.WebSession_post) error {
 	return errUnimplemented
@@ -162,9 +446,32 @@ func (testWebSessionImpl) PostStreaming(context.Context, websession.WebSession_p
 func (testWebSessionImpl) PutStreaming(context.Context, websession.WebSession_putStreaming) error {
 	return errUnimplemented
 }
-func (testWebSessionImpl) OpenWebSocket(context.Context, websession.WebSession_openWebSocket) error {
-	return errUnimplemented
+
+// OpenWebSocket opens an "echo" WebSocket, which sends each message back to
+// the client, until the client closes it.
+func (testWebSessionImpl) OpenWebSocket(ctx context.Context, p websession.WebSession_openWebSocket) error {
+	protos, err := p.Args().Protocol()
+	if err != nil {
+		return err
+	}
+	results, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < protos.Len(); i++ {
+		if proto, _ := protos.At(i); proto == "echo" {
+			resultProtos, err := results.NewProtocol(1)
+			if err != nil {
+				return err
+			}
+			resultProtos.Set(0, proto)
+			break
+		}
+	}
+	client := p.Args().ClientStream().AddRef()
+	return results.SetServerStream(websession.WebSocketStream_ServerToClient(echoStream{client: client}))
 }
+
 func (testWebSessionImpl) Propfind(context.Context, websession.WebSession_propfind) error {
 	return errUnimplemented
 }
@@ -197,3 +504,40 @@ func (testWebSessionImpl) Options(context.Context, websession.WebSession_options
 }
 
 var errUnimplemented = errors.New("Unimplemented")
+
+// echoStream is the server stream of testWebSessionImpl's WebSockets. It
+// assumes that each call to SendBytes carries exactly one frame.
+type echoStream struct {
+	client websession.WebSocketStream
+}
+
+func (s echoStream) SendBytes(ctx context.Context, p websession.WebSocketStream_sendBytes) error {
+	msg, err := p.Args().Msg()
+	if err != nil {
+		return err
+	}
+	frame, err := ws.ReadFrame(bytes.NewReader(msg))
+	if err != nil {
+		return err
+	}
+	if frame.Header.OpCode == ws.OpClose {
+		// Dropping the client stream closes the connection.
+		s.client.Release()
+		return nil
+	}
+	if frame.Header.Masked {
+		ws.Cipher(frame.Payload, frame.Header.Mask, 0)
+		frame.Header.Masked = false
+	}
+	reply, err := ws.CompileFrame(frame)
+	if err != nil {
+		return err
+	}
+	return s.client.SendBytes(ctx, func(p websession.WebSocketStream_sendBytes_Params) error {
+		return p.SetMsg(reply)
+	})
+}
+
+func (s echoStream) Shutdown() {
+	s.client.Release()
+}
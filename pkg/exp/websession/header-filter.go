@@ -18,6 +18,13 @@ var (
 	ResponseHeaderFilter = util.Must(ParseHeaderWhitelist(websession.Response_headerWhitelist))
 )
 
+func init() {
+	// Conditional requests by ETag have their own fields in the schema, but
+	// those by date don't, so pass their headers through as well.
+	ContextHeaderFilter.AddAllow("If-Modified-Since")
+	ResponseHeaderFilter.AddAllow("Last-Modified")
+}
+
 // A HeaderFilter filters headers based on an allow list.
 type HeaderFilter struct {
 	// Headers matching keys in exact are allowed.
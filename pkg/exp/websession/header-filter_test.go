@@ -13,4 +13,6 @@ func TestHeaderFilter(t *testing.T) {
 	assert.True(t, ContextHeaderFilter.Allows("X-Csrf-Token"))
 	assert.False(t, ContextHeaderFilter.Allows("X-Csrf-Tokens"))
 	assert.False(t, ContextHeaderFilter.Allows("Authorization"))
+	assert.True(t, ContextHeaderFilter.Allows("If-Modified-Since"))
+	assert.True(t, ResponseHeaderFilter.Allows("Last-Modified"))
 }
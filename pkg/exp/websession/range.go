@@ -0,0 +1,127 @@
+package websession
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	websession "sandstorm.org/go/tempest/capnp/web-session"
+)
+
+// The WebSession protocol has no way to ask a grain for part of some content,
+// so grains always send all of it, and the handler sends the client the range
+// it asked for, if any; this is enough for media players to seek and for
+// downloads to resume.
+
+var (
+	// errNoRange means that the whole content should be sent, either
+	// because no range was asked for, or because the range can't be
+	// served, as with multiple ranges, which we don't support.
+	errNoRange = errors.New("no range to serve")
+
+	// errUnsatisfiableRange means that the range asked for is outside the
+	// content; the response should be a 416.
+	errUnsatisfiableRange = errors.New("range not satisfiable")
+)
+
+// A byteRange is a range of the bytes of some content.
+type byteRange struct {
+	start, length int64
+}
+
+// contentRange returns the value of the Content-Range header for the range,
+// in content of the given size.
+func (r byteRange) contentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.start, r.start+r.length-1, size)
+}
+
+// requestedRange returns the range of the content of resp which req asks for,
+// given that the content is size bytes long, and that resp would otherwise
+// have the status code status. If the whole content should be sent, it
+// returns errNoRange.
+func requestedRange(req *http.Request, resp websession.Response, status int, size int64) (byteRange, error) {
+	if (req.Method != "GET" && req.Method != "HEAD") || status != http.StatusOK {
+		return byteRange{}, errNoRange
+	}
+	header := req.Header.Get("Range")
+	if header == "" || !ifRangeMatches(req, resp) {
+		return byteRange{}, errNoRange
+	}
+	return parseRange(header, size)
+}
+
+// ifRangeMatches reports whether the If-Range header of req, if any, matches
+// the content of resp, so that a range of it may be sent. As per RFC 9110
+// section 13.1.5, an ETag must match strongly, and a date exactly.
+func ifRangeMatches(req *http.Request, resp websession.Response) bool {
+	ifRange := req.Header.Get("If-Range")
+	if ifRange == "" {
+		return true
+	}
+	if strings.HasPrefix(ifRange, "\"") || strings.HasPrefix(ifRange, "W/") {
+		tag, _, err := parseETag(ifRange)
+		if err != nil || tag.Weak || !resp.Content().HasETag() {
+			return false
+		}
+		etag, err := resp.Content().ETag()
+		if err != nil || etag.Weak() {
+			return false
+		}
+		value, err := etag.Value()
+		return err == nil && value == tag.Value
+	}
+	lastModified, err := responseHeader(resp, "Last-Modified")
+	return err == nil && lastModified != "" && lastModified == ifRange
+}
+
+// parseRange parses the value of a Range header, for content of the given
+// size.
+func parseRange(header string, size int64) (byteRange, error) {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return byteRange{}, errNoRange
+	}
+	first, last, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return byteRange{}, errNoRange
+	}
+	if first == "" {
+		// A suffix: the last n bytes.
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < 0 {
+			return byteRange{}, errNoRange
+		}
+		if n == 0 || size == 0 {
+			return byteRange{}, errUnsatisfiableRange
+		}
+		n = min(n, size)
+		return byteRange{start: size - n, length: n}, nil
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return byteRange{}, errNoRange
+	}
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return byteRange{}, errNoRange
+		}
+		end = min(end, size-1)
+	}
+	if start >= size {
+		return byteRange{}, errUnsatisfiableRange
+	}
+	return byteRange{start: start, length: end - start + 1}, nil
+}
+
+// replyRangeNotSatisfiable responds to a request for a range outside of
+// content of the given size.
+func replyRangeNotSatisfiable(w http.ResponseWriter, size int64) {
+	h := w.Header()
+	h.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+	h.Set("Content-Length", "0")
+	w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+}
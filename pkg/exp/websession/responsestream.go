@@ -34,8 +34,17 @@ type responseStreamImpl struct {
 	// the stream; otherwise methods will return errors.
 	used bool
 
-	// The ResponseWriter for the request.
-	w http.ResponseWriter
+	// The ResponseWriter for the request, and its controller, with which
+	// each write is flushed.
+	w  http.ResponseWriter
+	rc *http.ResponseController
+
+	// If the client asked for a range of the content, the number of bytes
+	// before it, which are dropped, and the number of bytes of it still to
+	// be written; rangeDone is closed once they have all been written, and
+	// the rest is dropped. Otherwise, length is -1.
+	offset, length int64
+	rangeDone      chan struct{}
 
 	done     chan struct{} // Closed when Done() is called
 	shutdown chan struct{} // Closed when all clients are dropped.
@@ -48,7 +57,11 @@ type responseStreamImpl struct {
 
 func newResponseStreamImpl(w http.ResponseWriter) *responseStreamImpl {
 	return &responseStreamImpl{
-		w: w,
+		w:  w,
+		rc: http.NewResponseController(w),
+
+		length:    -1,
+		rangeDone: make(chan struct{}),
 
 		size:     make(chan uint64, 1),
 		ready:    make(chan struct{}),
@@ -98,10 +111,50 @@ func (r *responseStreamImpl) Write(ctx context.Context, p util.ByteStream_write)
 	if err != nil {
 		return err
 	}
-	_, err = r.w.Write(data)
+	if r.length >= 0 {
+		data = r.clip(data)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	if _, err = r.w.Write(data); err != nil {
+		return err
+	}
+	// Send each write to the client right away, rather than when the
+	// buffer fills up; streaming responses, e.g. server-sent events or
+	// long polling, depend on it.
+	if err = r.rc.Flush(); errors.Is(err, http.ErrNotSupported) {
+		err = nil
+	}
+	if err == nil && r.length == 0 {
+		// That was the end of the range.
+		close(r.rangeDone)
+	}
 	return err
 }
 
+// clip returns the part of data, the next bytes of the content, which is in
+// the range the client asked for. Once all of the range has been
+// written, it returns nil.
+func (r *responseStreamImpl) clip(data []byte) []byte {
+	if r.length == 0 {
+		return nil
+	}
+	if n := int64(len(data)); r.offset >= n {
+		r.offset -= n
+		return nil
+	}
+	data = data[r.offset:]
+	r.offset = 0
+	if int64(len(data)) < r.length {
+		r.length -= int64(len(data))
+		return data
+	}
+	data = data[:r.length]
+	r.length = 0
+	return data
+}
+
 func (r *responseStreamImpl) Done(ctx context.Context, _ util.ByteStream_done) error {
 	r.commitSize()
 	if err := r.waitReady(ctx); err != nil {
@@ -10,10 +10,19 @@ import (
 // WriterStream implements websession.WebSocketStream_Server by writing data to W.
 // Note: the raw websocket traffic is written, rather than the high-level websocket
 // messages (i.e. we do not interpret headers & individual message boundaries).
+//
+// If W is also an io.Closer, it is closed when the stream is shut down, i.e.
+// when the last reference to it is dropped.
 type WriterStream struct {
 	W io.Writer
 }
 
+func (w WriterStream) Shutdown() {
+	if c, ok := w.W.(io.Closer); ok {
+		c.Close()
+	}
+}
+
 func (w WriterStream) SendBytes(ctx context.Context, p websession.WebSocketStream_sendBytes) error {
 	if err := ctx.Err(); err != nil {
 		return err